@@ -0,0 +1,997 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func adminCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "admin",
+		Usage: "Maintenance operations for stored scan data",
+		Commands: []*cli.Command{
+			adminPruneCommand(),
+			adminAcknowledgeCommand(),
+			adminMetadataCommand(),
+			adminSeverityOverrideCommand(),
+			adminDeleteCommand(),
+			adminArchiveCommand(),
+			adminRestoreCommand(),
+			adminBackfillCommand(),
+			adminMigrateTargetIDsCommand(),
+		},
+	}
+}
+
+func adminPruneCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		owner     string
+		keep      string
+	)
+
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Delete scan history and fixed vulnerabilities older than the retention period from Firestore",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "keep",
+				Usage:       "Retention period, in days (e.g. 90d) or as Go duration (e.g. 720h)",
+				Sources:     cli.EnvVars("OCTOVY_PRUNE_KEEP"),
+				Destination: &keep,
+				Value:       "90d",
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			retention, err := parseRetention(keep)
+			if err != nil {
+				return err
+			}
+
+			logging.Default().Info("Starting scan history pruning",
+				slog.String("github_owner", owner),
+				slog.Duration("keep", retention),
+				slog.Any("firestore", &firestore),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+			if _, err := uc.PruneScanHistory(ctx, &model.PruneScanHistoryInput{
+				Owner: owner,
+				Keep:  retention,
+			}); err != nil {
+				return goerr.Wrap(err, "failed to prune scan history")
+			}
+
+			return nil
+		},
+	}
+}
+
+func adminAcknowledgeCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.AcknowledgeVulnerabilityInput
+		branch    string
+		expiresIn string
+	)
+
+	return &cli.Command{
+		Name:  "acknowledge",
+		Usage: "Accept the risk of a detected vulnerability, or revoke the acceptance with --revoke",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "branch",
+				Usage:       "Branch name (default: default branch of the repository)",
+				Destination: &branch,
+			},
+			&cli.StringFlag{
+				Name:        "target",
+				Usage:       "Scan target, e.g. go.mod (default: all targets where the vulnerability is found)",
+				Destination: &input.Target,
+			},
+			&cli.StringFlag{
+				Name:        "vuln-id",
+				Usage:       "Vulnerability ID, e.g. CVE-2024-0001 (required)",
+				Destination: &input.VulnID,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "by",
+				Usage:       "Who accepts the risk (required unless --revoke)",
+				Sources:     cli.EnvVars("OCTOVY_ACKNOWLEDGE_BY"),
+				Destination: &input.By,
+			},
+			&cli.StringFlag{
+				Name:        "comment",
+				Usage:       "Why the risk is accepted (required unless --revoke)",
+				Destination: &input.Comment,
+			},
+			&cli.StringFlag{
+				Name:        "expires-in",
+				Usage:       "Period until the vulnerability becomes active again, in days (e.g. 90d) or as Go duration (default: no expiration)",
+				Destination: &expiresIn,
+			},
+			&cli.BoolFlag{
+				Name:        "revoke",
+				Usage:       "Make the acknowledged vulnerability active again",
+				Destination: &input.Revoke,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			input.Branch = types.NewBranchName(branch)
+			if expiresIn != "" {
+				period, err := parseRetention(expiresIn)
+				if err != nil {
+					return goerr.Wrap(err, "invalid --expires-in")
+				}
+				input.ExpiresAt = logging.CtxTime(ctx).Add(period)
+			}
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+			updated, err := uc.AcknowledgeVulnerability(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to acknowledge vulnerability")
+			}
+
+			logging.Default().Info("Completed vulnerability acknowledgement",
+				slog.String("vuln_id", input.VulnID),
+				slog.Bool("revoke", input.Revoke),
+				slog.Int("updated", updated),
+			)
+
+			return nil
+		},
+	}
+}
+
+func adminMetadataCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		owner     string
+		repoName  string
+		tags      []string
+		team      string
+		clearTags bool
+
+		scanPaths       []string
+		scanExclude     []string
+		clearScanConfig bool
+
+		prCheckBaseline string
+	)
+
+	return &cli.Command{
+		Name:  "metadata",
+		Usage: "Set tags, owning team, scan config and pull request check of a repository",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &repoName,
+				Required:    true,
+			},
+			&cli.StringSliceFlag{
+				Name:        "tag",
+				Usage:       "Tag of the repository (can be repeated). Replaces existing tags",
+				Destination: &tags,
+			},
+			&cli.BoolFlag{
+				Name:        "clear-tags",
+				Usage:       "Remove all tags of the repository",
+				Destination: &clearTags,
+			},
+			&cli.StringFlag{
+				Name:        "team",
+				Usage:       "Slug of the GitHub team owning the repository. Empty string removes the team",
+				Destination: &team,
+			},
+			&cli.StringSliceFlag{
+				Name:        "scan-path",
+				Usage:       "Directory or file scanned, relative to the repository root (can be repeated). Replaces the scan config together with --scan-exclude",
+				Destination: &scanPaths,
+			},
+			&cli.StringSliceFlag{
+				Name:        "scan-exclude",
+				Usage:       "Directory skipped by scans, e.g. vendor (can be repeated). Replaces the scan config together with --scan-path",
+				Destination: &scanExclude,
+			},
+			&cli.BoolFlag{
+				Name:        "clear-scan-config",
+				Usage:       "Remove the scan config of the repository",
+				Destination: &clearScanConfig,
+			},
+			&cli.StringFlag{
+				Name:        "pr-check-baseline",
+				Usage:       "Vulnerabilities failing the check run of pull requests of the repository [base|none], overriding --github-vuln-check-baseline of the server. Empty string removes the override",
+				Destination: &prCheckBaseline,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			input := &model.UpdateRepositoryMetadataInput{
+				Owner: owner,
+				Repo:  repoName,
+			}
+			switch {
+			case clearTags && c.IsSet("tag"):
+				return goerr.Wrap(types.ErrInvalidOption, "--tag and --clear-tags are mutually exclusive")
+			case clearTags:
+				input.Tags = &[]string{}
+			case c.IsSet("tag"):
+				input.Tags = &tags
+			}
+			if c.IsSet("team") {
+				input.Team = &team
+			}
+			setScanConfig := c.IsSet("scan-path") || c.IsSet("scan-exclude")
+			switch {
+			case clearScanConfig && setScanConfig:
+				return goerr.Wrap(types.ErrInvalidOption, "--scan-path and --scan-exclude can not be used with --clear-scan-config")
+			case clearScanConfig:
+				input.ScanConfig = &model.ScanConfig{}
+			case setScanConfig:
+				input.ScanConfig = &model.ScanConfig{Paths: scanPaths, Exclude: scanExclude}
+			}
+			if c.IsSet("pr-check-baseline") {
+				input.PullRequestCheck = &model.PullRequestCheckConfig{Baseline: types.CheckBaseline(prCheckBaseline)}
+			}
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			if _, err := usecase.New(infra.New(infra.WithScanRepository(repo))).UpdateRepositoryMetadata(ctx, input); err != nil {
+				return goerr.Wrap(err, "failed to update repository metadata")
+			}
+
+			return nil
+		},
+	}
+}
+
+func adminSeverityOverrideCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "severity-override",
+		Usage: "Manage severity overrides of vulnerabilities by CVE ID or package for an organization",
+		Commands: []*cli.Command{
+			adminSeverityOverrideSetCommand(),
+			adminSeverityOverrideListCommand(),
+			adminSeverityOverrideDeleteCommand(),
+		},
+	}
+}
+
+func adminSeverityOverrideSetCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		override  model.SeverityOverride
+		severity  string
+	)
+
+	return &cli.Command{
+		Name:  "set",
+		Usage: "Override severity of vulnerabilities matching --vuln-id and/or --pkg-name. Stored vulnerabilities are updated at the next scan of each repository",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &override.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "vuln-id",
+				Usage:       "Vulnerability ID, e.g. CVE-2024-0001 (required unless --pkg-name)",
+				Destination: &override.VulnID,
+			},
+			&cli.StringFlag{
+				Name:        "pkg-name",
+				Usage:       "Package name, e.g. github.com/example/lib (required unless --vuln-id)",
+				Destination: &override.PkgName,
+			},
+			&cli.StringFlag{
+				Name:        "severity",
+				Usage:       "Severity applied to matching vulnerabilities (CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN)",
+				Destination: &severity,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "reason",
+				Usage:       "Why the severity is overridden",
+				Destination: &override.Reason,
+			},
+			&cli.StringFlag{
+				Name:        "by",
+				Usage:       "Who overrides the severity",
+				Destination: &override.By,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			override.Severity = types.Severity(severity)
+
+			uc, err := newFirestoreUseCase(ctx, &firestore)
+			if err != nil {
+				return err
+			}
+
+			stored, err := uc.PutSeverityOverride(ctx, &override)
+			if err != nil {
+				return goerr.Wrap(err, "failed to set severity override")
+			}
+			return printSeverityOverrides(os.Stdout, []*model.SeverityOverride{stored})
+		},
+	}
+}
+
+func adminSeverityOverrideListCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		owner     string
+	)
+
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List severity overrides of an organization",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &owner,
+				Required:    true,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			uc, err := newFirestoreUseCase(ctx, &firestore)
+			if err != nil {
+				return err
+			}
+
+			overrides, err := uc.ListSeverityOverrides(ctx, owner)
+			if err != nil {
+				return goerr.Wrap(err, "failed to list severity overrides")
+			}
+			return printSeverityOverrides(os.Stdout, overrides)
+		},
+	}
+}
+
+func adminSeverityOverrideDeleteCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.DeleteSeverityOverrideInput
+	)
+
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "Delete the severity override set with the same --vuln-id and --pkg-name",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "vuln-id",
+				Usage:       "Vulnerability ID of the override",
+				Destination: &input.VulnID,
+			},
+			&cli.StringFlag{
+				Name:        "pkg-name",
+				Usage:       "Package name of the override",
+				Destination: &input.PkgName,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			uc, err := newFirestoreUseCase(ctx, &firestore)
+			if err != nil {
+				return err
+			}
+
+			if err := uc.DeleteSeverityOverride(ctx, &input); err != nil {
+				return goerr.Wrap(err, "failed to delete severity override")
+			}
+			return nil
+		},
+	}
+}
+
+// newFirestoreUseCase creates a usecase with the Firestore repository only, for admin commands managing stored data
+func newFirestoreUseCase(ctx context.Context, firestore *config.Firestore) (*usecase.UseCase, error) {
+	if !firestore.Enabled() {
+		return nil, goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+	}
+
+	repo, err := firestore.NewRepository(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create Firestore repository")
+	}
+	return usecase.New(infra.New(infra.WithScanRepository(repo))), nil
+}
+
+// printSeverityOverrides writes overrides as a table
+func printSeverityOverrides(w io.Writer, overrides []*model.SeverityOverride) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "VULN ID\tPACKAGE\tSEVERITY\tREASON\tBY\tUPDATED AT")
+	for _, o := range overrides {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			orDash(o.VulnID), orDash(o.PkgName), o.Severity, orDash(o.Reason), orDash(o.By), o.UpdatedAt.Format(time.RFC3339))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write severity overrides")
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func adminDeleteCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		bigQuery  config.BigQuery
+		input     model.DeleteRepositoryDataInput
+		confirm   bool
+	)
+
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "Delete all stored data of an owner or a repository, e.g. to clean up staging. Without --confirm, only lists the data to be deleted",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Aliases:     []string{"owner"},
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Aliases:     []string{"repo"},
+				Usage:       "GitHub repository name (default: all repositories of the owner)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.BoolFlag{
+				Name:        "bigquery",
+				Usage:       "Also delete rows of the owner or repository from BigQuery tables",
+				Destination: &input.BigQuery,
+			},
+			&cli.BoolFlag{
+				Name:        "confirm",
+				Usage:       "Actually delete the data. Without it, the command runs as dry run",
+				Destination: &confirm,
+			},
+		}, firestore.Flags(), bigQuery.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			input.DryRun = !confirm
+
+			logging.Default().Info("Starting repository data deletion",
+				slog.String("github_owner", input.Owner),
+				slog.String("github_repo", input.Repo),
+				slog.Bool("bigquery", input.BigQuery),
+				slog.Bool("dry_run", input.DryRun),
+				slog.Any("firestore", &firestore),
+				slog.Any("bigquery_config", &bigQuery),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+			infraOptions := []infra.Option{infra.WithScanRepository(repo)}
+
+			var ucOptions []usecase.Option
+			if input.BigQuery {
+				bqClient, err := bigQuery.NewClient(ctx)
+				if err != nil {
+					return err
+				}
+				if err := requireBigQuery(bqClient); err != nil {
+					return err
+				}
+				infraOptions = append(infraOptions, infra.WithBigQuery(bqClient))
+
+				// Normalized tables are included only with --bigquery-insert-mode normalized
+				if ucOptions, err = bigQuery.UseCaseOptions(); err != nil {
+					return err
+				}
+			}
+
+			uc := usecase.New(infra.New(infraOptions...), ucOptions...)
+			result, err := uc.DeleteRepositoryData(ctx, &input)
+			if result != nil {
+				if printErr := printDeleteResult(os.Stdout, result); printErr != nil && err == nil {
+					err = printErr
+				}
+			}
+			if err != nil {
+				return goerr.Wrap(err, "failed to delete repository data")
+			}
+
+			return nil
+		},
+	}
+}
+
+// printDeleteResult writes deleted data, or data to be deleted in dry run, as a table
+func printDeleteResult(w io.Writer, result *model.DeleteRepositoryDataResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if result.DryRun {
+		fmt.Fprintln(tw, "Dry run: the following data will be deleted. Run again with --confirm to delete it.")
+	} else {
+		fmt.Fprintln(tw, "Deleted the following data.")
+	}
+
+	fmt.Fprintln(tw, "\nREPOSITORY\tBRANCHES\tTARGETS\tVULNERABILITIES\tSCAN RECORDS")
+	for _, repo := range result.Repositories {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", repo.ID, repo.Branches, repo.Targets, repo.Vulnerabilities, repo.ScanRecords)
+	}
+	fmt.Fprintf(tw, "\nTotal repositories: %d\n", len(result.Repositories))
+
+	if len(result.BigQuery) > 0 {
+		fmt.Fprintln(tw, "\nBIGQUERY TABLE\tROWS")
+		for _, rows := range result.BigQuery {
+			fmt.Fprintf(tw, "%s\t%d\n", rows.Table, rows.Rows)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write deletion result")
+	}
+	return nil
+}
+
+func adminArchiveCommand() *cli.Command {
+	var (
+		bigQuery config.BigQuery
+		input    model.ArchiveBigQueryInput
+		keep     string
+		confirm  bool
+	)
+
+	return &cli.Command{
+		Name:  "archive",
+		Usage: "Export rows older than the retention period from BigQuery to Parquet files on Cloud Storage and delete them from BigQuery. Without --confirm, only lists the days to be archived",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "keep",
+				Usage:       "Retention period of rows in BigQuery, in days (e.g. 365d) or as Go duration",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_KEEP"),
+				Destination: &keep,
+				Value:       "365d",
+			},
+			&cli.StringFlag{
+				Name:        "archive-bucket",
+				Usage:       "Cloud Storage bucket to store Parquet files (required)",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_BUCKET"),
+				Destination: &input.Bucket,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "archive-prefix",
+				Usage:       "Object name prefix of Parquet files",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_PREFIX"),
+				Destination: &input.Prefix,
+				Value:       "octovy/archive",
+			},
+			&cli.StringFlag{
+				Name:        "state-file",
+				Usage:       "Local file to record archived days. It is required to resume an interrupted archival and to restore days (required)",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_STATE_FILE"),
+				Destination: &input.StatePath,
+				Required:    true,
+			},
+			&cli.DurationFlag{
+				Name:        "interval",
+				Usage:       "Wait between days to limit the rate of export and delete jobs",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_INTERVAL"),
+				Destination: &input.Interval,
+				Value:       10 * time.Second,
+			},
+			&cli.IntFlag{
+				Name:        "max-days",
+				Usage:       "Maximum number of days of each table to archive in a run (0 means no limit)",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_MAX_DAYS"),
+				Destination: &input.MaxDays,
+			},
+			&cli.BoolFlag{
+				Name:        "confirm",
+				Usage:       "Actually archive the rows. Without it, the command runs as dry run",
+				Destination: &confirm,
+			},
+		}, bigQuery.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			retention, err := parseRetention(keep)
+			if err != nil {
+				return err
+			}
+			input.Before = time.Now().Add(-retention)
+			input.DryRun = !confirm
+
+			logging.Default().Info("Starting BigQuery archival",
+				slog.Duration("keep", retention),
+				slog.String("archive_bucket", input.Bucket),
+				slog.String("archive_prefix", input.Prefix),
+				slog.String("state_file", input.StatePath),
+				slog.Duration("interval", input.Interval),
+				slog.Int("max_days", input.MaxDays),
+				slog.Bool("dry_run", input.DryRun),
+				slog.Any("bigquery", &bigQuery),
+			)
+
+			uc, err := newArchiveUseCase(ctx, &bigQuery)
+			if err != nil {
+				return err
+			}
+
+			result, err := uc.ArchiveBigQuery(ctx, &input)
+			if result != nil {
+				if printErr := printArchiveResult(os.Stdout, result, "archived"); printErr != nil && err == nil {
+					err = printErr
+				}
+			}
+			if err != nil {
+				return goerr.Wrap(err, "failed to archive BigQuery rows")
+			}
+			return nil
+		},
+	}
+}
+
+func adminRestoreCommand() *cli.Command {
+	var (
+		bigQuery config.BigQuery
+		input    model.RestoreBigQueryInput
+		from, to string
+		confirm  bool
+	)
+
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Load archived days from Parquet files on Cloud Storage back to BigQuery. Without --confirm, only lists the days to be restored",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "from",
+				Usage:       "First UTC day to restore in YYYY-MM-DD (required)",
+				Destination: &from,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "to",
+				Usage:       "Last UTC day to restore in YYYY-MM-DD (default: same as --from)",
+				Destination: &to,
+			},
+			&cli.StringFlag{
+				Name:        "state-file",
+				Usage:       "State file written by admin archive (required)",
+				Sources:     cli.EnvVars("OCTOVY_ARCHIVE_STATE_FILE"),
+				Destination: &input.StatePath,
+				Required:    true,
+			},
+			&cli.BoolFlag{
+				Name:        "confirm",
+				Usage:       "Actually restore the rows. Without it, the command runs as dry run",
+				Destination: &confirm,
+			},
+		}, bigQuery.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if to == "" {
+				to = from
+			}
+			var err error
+			if input.From, err = time.Parse(time.DateOnly, from); err != nil {
+				return goerr.Wrap(types.ErrInvalidOption, "invalid --from, YYYY-MM-DD is expected", goerr.V("from", from))
+			}
+			if input.To, err = time.Parse(time.DateOnly, to); err != nil {
+				return goerr.Wrap(types.ErrInvalidOption, "invalid --to, YYYY-MM-DD is expected", goerr.V("to", to))
+			}
+			input.DryRun = !confirm
+
+			logging.Default().Info("Starting BigQuery restore",
+				slog.String("from", from),
+				slog.String("to", to),
+				slog.String("state_file", input.StatePath),
+				slog.Bool("dry_run", input.DryRun),
+				slog.Any("bigquery", &bigQuery),
+			)
+
+			uc, err := newArchiveUseCase(ctx, &bigQuery)
+			if err != nil {
+				return err
+			}
+
+			result, err := uc.RestoreBigQuery(ctx, &input)
+			if result != nil {
+				if printErr := printArchiveResult(os.Stdout, result, "restored"); printErr != nil && err == nil {
+					err = printErr
+				}
+			}
+			if err != nil {
+				return goerr.Wrap(err, "failed to restore BigQuery rows")
+			}
+			return nil
+		},
+	}
+}
+
+// newArchiveUseCase creates a use case with BigQuery. Normalized tables are included only with --bigquery-insert-mode normalized.
+func newArchiveUseCase(ctx context.Context, bigQuery *config.BigQuery) (*usecase.UseCase, error) {
+	bqClient, err := bigQuery.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBigQuery(bqClient); err != nil {
+		return nil, err
+	}
+
+	ucOptions, err := bigQuery.UseCaseOptions()
+	if err != nil {
+		return nil, err
+	}
+	return usecase.New(infra.New(infra.WithBigQuery(bqClient)), ucOptions...), nil
+}
+
+// printArchiveResult writes days archived or restored, or to be processed in dry run, as a table
+func printArchiveResult(w io.Writer, result *model.ArchiveBigQueryResult, verb string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if result.DryRun {
+		fmt.Fprintf(tw, "Dry run: the following days will be %s. Run again with --confirm to proceed.\n", verb)
+	} else {
+		fmt.Fprintf(tw, "The following days were %s.\n", verb)
+	}
+
+	fmt.Fprintln(tw, "\nTABLE\tDAY\tROWS\tURI")
+	for _, day := range result.Days {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", day.Table, day.Day, day.Rows, day.URI)
+	}
+	fmt.Fprintf(tw, "\nTotal days: %d\n", len(result.Days))
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write archive result")
+	}
+	return nil
+}
+
+func adminBackfillCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		bigQuery  config.BigQuery
+		input     model.BackfillScanRepositoryInput
+		confirm   bool
+	)
+
+	return &cli.Command{
+		Name:  "backfill",
+		Usage: "Rebuild Firestore data by replaying scans stored in the BigQuery scan table, e.g. to migrate from BigQuery only deployment. Without --confirm, only counts the scans to be replayed",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Aliases:     []string{"owner"},
+				Usage:       "GitHub repository owner (default: all owners)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Aliases:     []string{"repo"},
+				Usage:       "GitHub repository name, requires --github-owner (default: all repositories of the owner)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.BoolFlag{
+				Name:        "confirm",
+				Usage:       "Actually write the data to Firestore. Without it, the command runs as dry run",
+				Destination: &confirm,
+			},
+		}, firestore.Flags(), bigQuery.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			input.DryRun = !confirm
+
+			logging.Default().Info("Starting backfill of Firestore from BigQuery",
+				slog.String("github_owner", input.Owner),
+				slog.String("github_repo", input.Repo),
+				slog.Bool("dry_run", input.DryRun),
+				slog.Any("firestore", &firestore),
+				slog.Any("bigquery", &bigQuery),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			bqClient, err := bigQuery.NewClient(ctx)
+			if err != nil {
+				return err
+			}
+			if err := requireBigQuery(bqClient); err != nil {
+				return err
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo), infra.WithBigQuery(bqClient)))
+			result, err := uc.BackfillScanRepository(ctx, &input)
+			if result != nil {
+				if printErr := printBackfillResult(os.Stdout, result); printErr != nil && err == nil {
+					err = printErr
+				}
+			}
+			if err != nil {
+				return goerr.Wrap(err, "failed to backfill Firestore")
+			}
+			return nil
+		},
+	}
+}
+
+func adminMigrateTargetIDsCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		owner     string
+	)
+
+	return &cli.Command{
+		Name:  "migrate-target-ids",
+		Usage: "Move targets stored with the legacy ID, the hash of the target path only, to the ID derived from path, class and type",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &owner,
+				Required:    true,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			logging.Default().Info("Starting target ID migration",
+				slog.String("github_owner", owner),
+				slog.Any("firestore", &firestore),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+			if _, err := uc.MigrateTargetIDs(ctx, &model.MigrateTargetIDsInput{Owner: owner}); err != nil {
+				return goerr.Wrap(err, "failed to migrate target IDs")
+			}
+
+			return nil
+		},
+	}
+}
+
+// printBackfillResult writes the number of replayed scans, or scans to be replayed in dry run, as a table
+func printBackfillResult(w io.Writer, result *model.BackfillScanRepositoryResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if result.DryRun {
+		fmt.Fprintln(tw, "Dry run: the following scans will be replayed. Run again with --confirm to write them to Firestore.")
+	} else {
+		fmt.Fprintln(tw, "Replayed the following scans.")
+	}
+
+	fmt.Fprintln(tw, "\nREPOSITORY\tSCANS\tSKIPPED")
+	for _, repo := range result.Repositories {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", repo.ID, repo.Scans, repo.Skipped)
+	}
+	fmt.Fprintf(tw, "\nTotal repositories: %d\n", len(result.Repositories))
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write backfill result")
+	}
+	return nil
+}
+
+// parseRetention parses a retention period. In addition to Go durations, a number of days
+// with "d" suffix (e.g. "90d") is accepted because time.ParseDuration has no day unit.
+func parseRetention(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, goerr.Wrap(types.ErrInvalidOption, "invalid retention period", goerr.V("keep", s))
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "invalid retention period", goerr.V("keep", s))
+	}
+	return d, nil
+}
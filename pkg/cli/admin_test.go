@@ -0,0 +1,76 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseRetention(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "90d", want: 90 * 24 * time.Hour},
+		{input: "1d", want: 24 * time.Hour},
+		{input: "720h", want: 720 * time.Hour},
+		{input: "0d", wantErr: true},
+		{input: "-1d", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "0s", wantErr: true},
+		{input: "ninety", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := cli.ParseRetentionForTest(tc.input)
+			if tc.wantErr {
+				gt.Error(t, err)
+				return
+			}
+			gt.NoError(t, err)
+			gt.V(t, got).Equal(tc.want)
+		})
+	}
+}
+
+func TestPrintDeleteResult(t *testing.T) {
+	var buf bytes.Buffer
+	gt.NoError(t, cli.PrintDeleteResultForTest(&buf, &model.DeleteRepositoryDataResult{
+		DryRun: true,
+		Repositories: []*model.DeletedRepository{
+			{ID: "test-org/app", Branches: 2, Targets: 3, Vulnerabilities: 10, ScanRecords: 5},
+		},
+		BigQuery: []*model.DeletedBigQueryRows{{Table: "scan", Rows: 42}},
+	}))
+
+	out := buf.String()
+	gt.S(t, out).Contains("Dry run")
+	gt.S(t, out).Contains("--confirm")
+	gt.S(t, out).Contains("test-org/app")
+	gt.S(t, out).Contains("Total repositories: 1")
+	gt.S(t, out).Contains("BIGQUERY TABLE")
+	gt.S(t, out).Contains("42")
+}
+
+func TestPrintSeverityOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	gt.NoError(t, cli.PrintSeverityOverridesForTest(&buf, []*model.SeverityOverride{
+		{Owner: "test-org", VulnID: "CVE-2024-0001", Severity: types.SeverityLow, Reason: "not reachable", By: "alice", UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Owner: "test-org", PkgName: "github.com/example/payment", Severity: types.SeverityCritical},
+	}))
+
+	out := buf.String()
+	gt.S(t, out).Contains("VULN ID")
+	gt.S(t, out).Contains("CVE-2024-0001")
+	gt.S(t, out).Contains("not reachable")
+	gt.S(t, out).Contains("2025-01-02T03:04:05Z")
+	gt.S(t, out).Contains("github.com/example/payment")
+	gt.S(t, out).Contains("CRITICAL")
+}
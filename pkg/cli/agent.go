@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/controller/agent"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func agentCommand() *cli.Command {
+	var (
+		serverURL    string
+		agentToken   string
+		agentName    string
+		pollInterval time.Duration
+		dbRefresh    time.Duration
+
+		trivy   config.Trivy
+		scanner config.Scanner
+		archive config.Archive
+		proxy   config.Proxy
+	)
+
+	return &cli.Command{
+		Name:  "agent",
+		Usage: "Scan agent mode: claim scans delegated by an octovy server and scan source code in this network",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "server-url",
+				Usage:       "Base URL of the octovy server (required)",
+				Sources:     cli.EnvVars("OCTOVY_SERVER_URL"),
+				Destination: &serverURL,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "agent-token",
+				Usage:       "Bearer token set to --scan-agent-token of the server (required)",
+				Sources:     cli.EnvVars("OCTOVY_AGENT_TOKEN"),
+				Destination: &agentToken,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "agent-name",
+				Usage:       "Name of the agent shown in the server logs and /health/scan-agents (default: hostname)",
+				Sources:     cli.EnvVars("OCTOVY_AGENT_NAME"),
+				Destination: &agentName,
+			},
+			&cli.DurationFlag{
+				Name:        "poll-interval",
+				Usage:       "Interval to poll the server when there is no job",
+				Value:       10 * time.Second,
+				Sources:     cli.EnvVars("OCTOVY_AGENT_POLL_INTERVAL"),
+				Destination: &pollInterval,
+			},
+			&cli.DurationFlag{
+				Name:        "trivy-db-refresh-interval",
+				Usage:       "Interval to refresh the Trivy vulnerability DB downloaded at startup (0 disables the refresh)",
+				Category:    "Trivy",
+				Value:       6 * time.Hour,
+				Sources:     cli.EnvVars("OCTOVY_TRIVY_DB_REFRESH_INTERVAL"),
+				Destination: &dbRefresh,
+			},
+		}, scanner.Flags(), archive.Flags(), trivy.Flags(), proxy.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if agentName == "" {
+				hostname, err := os.Hostname()
+				if err != nil {
+					return goerr.Wrap(err, "failed to get hostname for agent name, set --agent-name")
+				}
+				agentName = hostname
+			}
+
+			logging.Default().Info("starting scan agent",
+				slog.String("ServerURL", serverURL),
+				slog.String("AgentName", agentName),
+				slog.Duration("PollInterval", pollInterval),
+				slog.Duration("TrivyDBRefreshInterval", dbRefresh),
+				slog.Any("Trivy", &trivy),
+				slog.Any("Scanner", &scanner),
+				slog.Any("Archive", &archive),
+				slog.Any("Proxy", &proxy),
+			)
+
+			outbound, err := proxy.Outbound()
+			if err != nil {
+				return err
+			}
+			trivyOptions, err := trivy.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			scannerOptions, err := scanner.UseCaseOptions(outbound.Grype...)
+			if err != nil {
+				return err
+			}
+			archiveOptions, err := archive.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			scannerOptions = append(scannerOptions, archiveOptions...)
+
+			ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			trivyClient := trivy.NewClient(outbound.Trivy...)
+			if scanner.UsesTrivy() {
+				if err := prepareTrivyDB(ctx, trivyClient, dbRefresh); err != nil {
+					return err
+				}
+			}
+
+			infraOptions := append([]infra.Option{infra.WithTrivy(trivyClient)}, outbound.Infra...)
+			uc := usecase.New(infra.New(infraOptions...), append(scannerOptions, trivyOptions...)...)
+
+			agentOptions := append([]agent.Option{agent.WithPollInterval(pollInterval)}, outbound.Agent...)
+			a, err := agent.New(uc, serverURL, agentToken, agentName, agentOptions...)
+			if err != nil {
+				return err
+			}
+
+			if err := a.Run(ctx); err != nil {
+				return err
+			}
+			logging.Default().Info("scan agent stopped")
+			return nil
+		},
+	}
+}
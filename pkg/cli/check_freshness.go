@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func checkFreshnessCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.CheckScanFreshnessInput
+	)
+
+	return &cli.Command{
+		Name:  "check-freshness",
+		Usage: "Detect default branches that have not been scanned within the allowed window",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.DurationFlag{
+				Name:        "max-age",
+				Usage:       "Maximum allowed age of the last scan of a default branch",
+				Sources:     cli.EnvVars("OCTOVY_FRESHNESS_MAX_AGE"),
+				Destination: &input.MaxAge,
+				Value:       24 * time.Hour,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			logging.Default().Info("Starting scan freshness check",
+				slog.String("github_owner", input.Owner),
+				slog.Duration("max_age", input.MaxAge),
+				slog.Any("firestore", &firestore),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+			report, err := uc.CheckScanFreshness(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to check scan freshness")
+			}
+
+			if len(report.Stale) > 0 {
+				return goerr.New("stale branch scans detected",
+					goerr.V("owner", report.Owner),
+					goerr.V("checked_branches", report.Checked),
+					goerr.V("stale_branches", len(report.Stale)),
+				)
+			}
+
+			return nil
+		},
+	}
+}
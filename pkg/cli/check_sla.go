@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func checkSLACommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		sla       config.SLA
+		input     model.CheckSLAInput
+		branch    string
+	)
+
+	return &cli.Command{
+		Name:  "check-sla",
+		Usage: "Detect active vulnerabilities that are not fixed within the SLA of their severity",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name. Default branches of all repositories of the owner are checked if omitted",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.StringFlag{
+				Name:        "branch",
+				Usage:       "Branch to check with --github-repo (default: the default branch)",
+				Destination: &branch,
+			},
+		}, sla.Flags(), firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			logging.Default().Info("Starting SLA check",
+				slog.String("github_owner", input.Owner),
+				slog.String("github_repo", input.Repo),
+				slog.String("branch", branch),
+				slog.Any("sla", &sla),
+				slog.Any("firestore", &firestore),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+			if !sla.Enabled() {
+				return goerr.New("SLA policy is required (--sla-policy must be set)")
+			}
+			if branch != "" && input.Repo == "" {
+				return goerr.Wrap(types.ErrInvalidOption, "--branch requires --github-repo")
+			}
+			input.Branch = types.NewBranchName(branch)
+
+			slaOptions, err := sla.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)), slaOptions...)
+
+			report, err := uc.CheckSLA(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to check SLA")
+			}
+
+			if len(report.Violations) > 0 {
+				return goerr.New("SLA breaches detected",
+					goerr.V("owner", report.Owner),
+					goerr.V("policy", report.Policy),
+					goerr.V("checked_branches", report.Checked),
+					goerr.V("violations", len(report.Violations)),
+				)
+			}
+
+			return nil
+		},
+	}
+}
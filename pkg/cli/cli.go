@@ -2,8 +2,13 @@ package cli
 
 import (
 	"context"
+	"os"
+	"strings"
 
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
 	"github.com/urfave/cli/v3"
 )
 
@@ -19,9 +24,15 @@ func New() *CLI {
 
 func (x *CLI) Run(argv []string) error {
 	var (
-		logLevel  string
-		logFormat string
-		logOutput string
+		logLevel   string
+		logFormat  string
+		logOutput  string
+		quiet      bool
+		verbose    bool
+		summary    string
+		configPath string
+
+		prog *progress.Progress
 	)
 
 	app := &cli.Command{
@@ -52,24 +63,131 @@ func (x *CLI) Run(argv []string) error {
 				Destination: &logOutput,
 				Value:       "-",
 			},
+			&cli.BoolFlag{
+				Name:        "quiet",
+				Usage:       "Suppress progress bars and informational logs, e.g. for CI",
+				Aliases:     []string{"q"},
+				Sources:     cli.EnvVars("OCTOVY_QUIET"),
+				Destination: &quiet,
+			},
+			&cli.BoolFlag{
+				Name:        "verbose",
+				Usage:       "Output debug logs (same as --log-level debug)",
+				Aliases:     []string{"v"},
+				Sources:     cli.EnvVars("OCTOVY_VERBOSE"),
+				Destination: &verbose,
+			},
+			&cli.StringFlag{
+				Name:        "summary-json",
+				Usage:       "Write a machine-readable summary of the command to the file when it finishes",
+				Sources:     cli.EnvVars("OCTOVY_SUMMARY_JSON"),
+				Destination: &summary,
+			},
+			&cli.StringFlag{
+				Name:        "config",
+				Usage:       "YAML file of options of commands, used for options which are not given by flags or environment variables. Environment variables in values, e.g. ${GITHUB_APP_SECRET}, are expanded",
+				Sources:     cli.EnvVars("OCTOVY_CONFIG"),
+				Destination: &configPath,
+			},
 		},
 		Commands: []*cli.Command{
 			serveCommand(),
 			scanCommand(),
 			insertCommand(),
+			syncAlertsCommand(),
+			checkFreshnessCommand(),
+			checkSLACommand(),
+			adminCommand(),
+			vulnCommand(),
+			reportCommand(),
+			exportCommand(),
+			diffCommand(),
+			agentCommand(),
+			configCommand(),
 		},
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
-			if err := ConfigureLogging(logFormat, logLevel, logOutput); err != nil {
+			lineage := invokedLineage(c, argv)
+			if configPath != "" {
+				file, err := loadConfigFile(configPath)
+				if err != nil {
+					return ctx, err
+				}
+				if err := file.apply(lineage); err != nil {
+					return ctx, goerr.Wrap(err, "failed to apply config file", goerr.V("path", configPath))
+				}
+			}
+
+			level, err := outputLogLevel(logLevel, quiet, verbose)
+			if err != nil {
+				return ctx, err
+			}
+			if err := ConfigureLogging(logFormat, level, logOutput); err != nil {
 				return ctx, err
 			}
-			return ctx, nil
+
+			var options []progress.Option
+			if !quiet {
+				options = append(options, progress.WithTerminal(os.Stderr))
+			}
+			prog = progress.New(commandName(lineage), options...)
+			return progress.With(ctx, prog), nil
 		},
 	}
 
-	if err := app.Run(context.Background(), argv); err != nil {
+	err := app.Run(context.Background(), argv)
+	if summary != "" && prog != nil {
+		// The summary is written also on failure, because CI needs to know which items failed
+		if writeErr := prog.WriteSummary(summary, err); writeErr != nil {
+			logging.Default().Error("failed to write summary", "error", writeErr)
+			if err == nil {
+				err = writeErr
+			}
+		}
+	}
+
+	if err != nil {
 		logging.Default().Error("fatal error", "error", err)
 		return err
 	}
 
 	return nil
 }
+
+// outputLogLevel applies --quiet and --verbose to the log level. --quiet only raises the level, so that --log-level error is kept.
+func outputLogLevel(logLevel string, quiet, verbose bool) (string, error) {
+	switch {
+	case quiet && verbose:
+		return "", goerr.Wrap(types.ErrInvalidOption, "--quiet and --verbose are mutually exclusive")
+	case verbose:
+		return "debug", nil
+	case quiet && (logLevel == "debug" || logLevel == "info"):
+		return "warn", nil
+	default:
+		return logLevel, nil
+	}
+}
+
+// invokedLineage returns the root command and the subcommands in argv, e.g. octovy, scan and remote of "octovy scan remote". Arguments which are not a subcommand, such as flags and their values, are skipped.
+func invokedLineage(root *cli.Command, argv []string) []*cli.Command {
+	lineage := []*cli.Command{root}
+	cmd := root
+	for _, arg := range argv[min(1, len(argv)):] {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if sub := cmd.Command(arg); sub != nil {
+			lineage = append(lineage, sub)
+			cmd = sub
+		}
+	}
+	return lineage
+}
+
+// commandName returns the full name of the lineage, e.g. "octovy scan remote"
+func commandName(lineage []*cli.Command) string {
+	names := make([]string, len(lineage))
+	for i, cmd := range lineage {
+		names[i] = cmd.Name
+	}
+	return strings.Join(names, " ")
+}
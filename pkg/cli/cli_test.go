@@ -0,0 +1,68 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+)
+
+func TestOutputLogLevel(t *testing.T) {
+	testCases := map[string]struct {
+		logLevel string
+		quiet    bool
+		verbose  bool
+		want     string
+		wantErr  bool
+	}{
+		"no option":                   {logLevel: "info", want: "info"},
+		"quiet raises info":           {logLevel: "info", quiet: true, want: "warn"},
+		"quiet keeps error":           {logLevel: "error", quiet: true, want: "error"},
+		"verbose":                     {logLevel: "info", verbose: true, want: "debug"},
+		"quiet and verbose conflicts": {logLevel: "info", quiet: true, verbose: true, wantErr: true},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			got, err := cli.OutputLogLevelForTest(tc.logLevel, tc.quiet, tc.verbose)
+			if tc.wantErr {
+				gt.Error(t, err)
+				return
+			}
+			gt.NoError(t, err)
+			gt.V(t, got).Equal(tc.want)
+		})
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	logPath := filepath.Join(t.TempDir(), "octovy.log")
+
+	t.Run("success", func(t *testing.T) {
+		gt.NoError(t, cli.New().Run([]string{
+			"octovy", "--quiet", "--log-output", logPath, "--summary-json", path,
+			"report", "--file", "testdata/trivy-result.json", "--format", "json",
+		}))
+
+		var summary map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(os.ReadFile(path)).NoError(t), &summary))
+		gt.V(t, summary["command"]).Equal("octovy report")
+		gt.V(t, summary["status"]).Equal("success")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		gt.Error(t, cli.New().Run([]string{
+			"octovy", "--quiet", "--log-output", logPath, "--summary-json", path,
+			"report", "--file", "testdata/not-found.json",
+		}))
+
+		var summary map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(os.ReadFile(path)).NoError(t), &summary))
+		gt.V(t, summary["status"]).Equal("failure")
+		gt.V(t, summary["error"]).NotEqual(nil)
+	})
+}
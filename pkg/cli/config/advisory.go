@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+type Advisory struct {
+	feed string
+}
+
+func (x *Advisory) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "advisory-feed",
+			Usage:       "File path or http(s) URL of a JSON feed of internal vulnerability advisories matched against scanned packages",
+			Category:    "Advisory",
+			Destination: &x.feed,
+			Sources:     cli.EnvVars("OCTOVY_ADVISORY_FEED"),
+		},
+	}
+}
+
+func (x *Advisory) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Feed", x.feed),
+	)
+}
+
+// UseCaseOptions loads the feed of --advisory-feed and returns usecase options to match it against scan results. The feed is loaded only once.
+func (x *Advisory) UseCaseOptions(ctx context.Context) ([]usecase.Option, error) {
+	if x.feed == "" {
+		return nil, nil
+	}
+
+	advisories, err := usecase.LoadInternalAdvisories(ctx, x.feed)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to load --advisory-feed")
+	}
+	return []usecase.Option{usecase.WithInternalAdvisories(advisories)}, nil
+}
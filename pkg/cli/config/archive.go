@@ -0,0 +1,97 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+// Archive configures download and extraction of zip archives of source code, e.g. GitHub zipballs. Limits protect the disk against zip bombs and giant repositories.
+type Archive struct {
+	maxSizeMB         int64
+	maxFileSizeMB     int64
+	maxFiles          int64
+	maxDownloadSizeMB int64
+	checksum          bool
+}
+
+func (x *Archive) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name:        "archive-max-size",
+			Usage:       "Limit of total uncompressed size of a zip archive of source code in MiB (0 for no limit)",
+			Category:    "Archive",
+			Value:       model.DefaultMaxArchiveSize >> 20,
+			Sources:     cli.EnvVars("OCTOVY_ARCHIVE_MAX_SIZE"),
+			Destination: &x.maxSizeMB,
+		},
+		&cli.Int64Flag{
+			Name:        "archive-max-file-size",
+			Usage:       "Limit of uncompressed size of each file in a zip archive of source code in MiB (0 for no limit)",
+			Category:    "Archive",
+			Value:       model.DefaultMaxArchiveFileSize >> 20,
+			Sources:     cli.EnvVars("OCTOVY_ARCHIVE_MAX_FILE_SIZE"),
+			Destination: &x.maxFileSizeMB,
+		},
+		&cli.Int64Flag{
+			Name:        "archive-max-files",
+			Usage:       "Limit of number of files in a zip archive of source code (0 for no limit)",
+			Category:    "Archive",
+			Value:       model.DefaultMaxArchiveFiles,
+			Sources:     cli.EnvVars("OCTOVY_ARCHIVE_MAX_FILES"),
+			Destination: &x.maxFiles,
+		},
+		&cli.Int64Flag{
+			Name:        "archive-max-download-size",
+			Usage:       "Limit of size of a zip archive of source code to download in MiB (0 for no limit)",
+			Category:    "Archive",
+			Value:       model.DefaultMaxArchiveDownloadSize >> 20,
+			Sources:     cli.EnvVars("OCTOVY_ARCHIVE_MAX_DOWNLOAD_SIZE"),
+			Destination: &x.maxDownloadSizeMB,
+		},
+		&cli.BoolFlag{
+			Name:        "archive-checksum",
+			Usage:       "Record SHA-256 digest of a zip archive of source code in the scan",
+			Category:    "Archive",
+			Sources:     cli.EnvVars("OCTOVY_ARCHIVE_CHECKSUM"),
+			Destination: &x.checksum,
+		},
+	}
+}
+
+// UseCaseOptions returns an option to apply the limits, and one to record the digest if --archive-checksum is set
+func (x *Archive) UseCaseOptions() ([]usecase.Option, error) {
+	if x.maxSizeMB < 0 || x.maxFileSizeMB < 0 || x.maxFiles < 0 || x.maxDownloadSizeMB < 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "archive limits must not be negative",
+			goerr.V("archive-max-size", x.maxSizeMB),
+			goerr.V("archive-max-file-size", x.maxFileSizeMB),
+			goerr.V("archive-max-files", x.maxFiles),
+			goerr.V("archive-max-download-size", x.maxDownloadSizeMB),
+		)
+	}
+
+	options := []usecase.Option{usecase.WithArchiveLimits(model.ArchiveLimits{
+		MaxTotalSize:    x.maxSizeMB << 20,
+		MaxFileSize:     x.maxFileSizeMB << 20,
+		MaxFiles:        int(x.maxFiles),
+		MaxDownloadSize: x.maxDownloadSizeMB << 20,
+	})}
+	if x.checksum {
+		options = append(options, usecase.WithArchiveChecksum())
+	}
+	return options, nil
+}
+
+func (x *Archive) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("MaxSizeMB", x.maxSizeMB),
+		slog.Int64("MaxFileSizeMB", x.maxFileSizeMB),
+		slog.Int64("MaxFiles", x.maxFiles),
+		slog.Int64("MaxDownloadSizeMB", x.maxDownloadSizeMB),
+		slog.Bool("Checksum", x.checksum),
+	)
+}
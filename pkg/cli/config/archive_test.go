@@ -0,0 +1,45 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func TestArchive(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.Archive {
+		var archive config.Archive
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  archive.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+		return &archive
+	}
+
+	t.Run("limits are applied by default", func(t *testing.T) {
+		options := gt.R1(parse(t).UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("zero disables limits", func(t *testing.T) {
+		options := gt.R1(parse(t, "--archive-max-size", "0", "--archive-max-file-size", "0", "--archive-max-files", "0").UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		options := gt.R1(parse(t, "--archive-checksum").UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(2)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		_, err := parse(t, "--archive-max-files", "-1").UseCaseOptions()
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
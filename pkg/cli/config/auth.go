@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/ghoauth"
+	"github.com/m-mizutani/octovy/pkg/infra/oidc"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// minSessionSecretLen is the length of a HMAC-SHA256 key with full strength
+const minSessionSecretLen = 32
+
+// Auth configures authentication of the read API, the dashboard and admin endpoints of the server
+type Auth struct {
+	apiTokens          []string `masq:"secret"`
+	oidcIssuer         string
+	oidcAudience       string
+	oidcSubjects       []string
+	githubClientID     string
+	githubClientSecret string `masq:"secret"`
+	sessionSecret      string `masq:"secret"`
+	allowedOrgs        []string
+	allowedTeams       []string
+	adminTeams         []string
+	tenantsFile        string
+}
+
+// tenantsFile is the format of --auth-tenants-file
+type tenantsFile struct {
+	Tenants []*model.TenantDefinition `yaml:"tenants"`
+}
+
+func (x *Auth) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "auth-api-token",
+			Usage:       "Static bearer token of the read API (can be repeated)",
+			Category:    "Authentication",
+			Destination: &x.apiTokens,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_API_TOKEN"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-oidc-issuer",
+			Usage:       "Issuer of OIDC tokens accepted as bearer tokens of the read API, e.g. " + oidc.GitHubActionsIssuer,
+			Category:    "Authentication",
+			Destination: &x.oidcIssuer,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_OIDC_ISSUER"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-oidc-audience",
+			Usage:       "Audience which OIDC tokens must have",
+			Category:    "Authentication",
+			Destination: &x.oidcAudience,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_OIDC_AUDIENCE"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "auth-oidc-subject",
+			Usage:       "Subject of OIDC tokens allowed regardless of repository_owner claim, e.g. a service account (can be repeated)",
+			Category:    "Authentication",
+			Destination: &x.oidcSubjects,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_OIDC_SUBJECT"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-github-client-id",
+			Usage:       "Client ID of GitHub OAuth App to sign in to the dashboard",
+			Category:    "Authentication",
+			Destination: &x.githubClientID,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_GITHUB_CLIENT_ID"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-github-client-secret",
+			Usage:       "Client secret of GitHub OAuth App",
+			Category:    "Authentication",
+			Destination: &x.githubClientSecret,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_GITHUB_CLIENT_SECRET"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-session-secret",
+			Usage:       "Secret of at least 32 characters to sign session cookies of GitHub OAuth",
+			Category:    "Authentication",
+			Destination: &x.sessionSecret,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_SESSION_SECRET"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "auth-allowed-org",
+			Usage:       "GitHub organization whose members (GitHub OAuth) and workflows (repository_owner claim of OIDC tokens) are allowed (can be repeated)",
+			Category:    "Authentication",
+			Destination: &x.allowedOrgs,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_ALLOWED_ORG"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "auth-allowed-team",
+			Usage:       "GitHub team in org/team-slug form whose members are allowed to sign in with GitHub OAuth (can be repeated)",
+			Category:    "Authentication",
+			Destination: &x.allowedTeams,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_ALLOWED_TEAM"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "auth-admin-team",
+			Usage:       "GitHub team in org/team-slug form whose members signed in with GitHub OAuth can call admin endpoints (can be repeated)",
+			Category:    "Authentication",
+			Destination: &x.adminTeams,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_ADMIN_TEAM"),
+		},
+		&cli.StringFlag{
+			Name:        "auth-tenants-file",
+			Usage:       "YAML file of tenants binding API tokens, GitHub organizations, teams and OIDC subjects to repositories of specific owners and installations",
+			Category:    "Authentication",
+			Destination: &x.tenantsFile,
+			Sources:     cli.EnvVars("OCTOVY_AUTH_TENANTS_FILE"),
+		},
+	}
+}
+
+// ServerOptions returns server options of configured authentication methods. baseURL is the public URL of the server, required to build the callback URL of GitHub OAuth. OIDC discovery of the issuer is done here, so that a wrong issuer fails at startup.
+func (x *Auth) ServerOptions(ctx context.Context, baseURL string) ([]server.Option, error) {
+	tenants, err := x.loadTenants()
+	if err != nil {
+		return nil, err
+	}
+	policy := &model.AuthPolicy{
+		AllowedOrgs:  x.allowedOrgs,
+		AllowedTeams: x.allowedTeams,
+		AdminTeams:   x.adminTeams,
+		OIDCSubjects: x.oidcSubjects,
+		Tenants:      tenants,
+	}
+	options := []server.Option{
+		server.WithAuthPolicy(policy),
+	}
+
+	if len(x.apiTokens) > 0 {
+		options = append(options, server.WithAPITokens(x.apiTokens))
+	}
+
+	if x.oidcIssuer != "" || x.oidcAudience != "" {
+		// Anyone can get a token of GitHub Actions with any audience, so that tokens must be limited by the owner or the subject
+		if len(x.allowedOrgs) == 0 && len(x.oidcSubjects) == 0 && !slices.ContainsFunc(tenants, func(t *model.TenantDefinition) bool {
+			return len(t.GitHubOrgs) > 0 || len(t.OIDCSubjects) > 0
+		}) {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--auth-allowed-org, --auth-oidc-subject or a tenant with github_orgs or oidc_subjects is required to accept OIDC tokens")
+		}
+		verifier, err := oidc.New(ctx, x.oidcIssuer, x.oidcAudience)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, server.WithOIDC(verifier))
+	}
+
+	if x.githubClientID != "" || x.githubClientSecret != "" {
+		if len(x.allowedOrgs) == 0 && len(x.allowedTeams) == 0 && len(x.adminTeams) == 0 && !slices.ContainsFunc(tenants, func(t *model.TenantDefinition) bool {
+			return len(t.GitHubOrgs) > 0 || len(t.GitHubTeams) > 0
+		}) {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--auth-allowed-org, --auth-allowed-team, --auth-admin-team or a tenant with github_orgs or github_teams is required to sign in with GitHub OAuth")
+		}
+		if len(x.sessionSecret) < minSessionSecretLen {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--auth-session-secret must have at least 32 characters to sign in with GitHub OAuth",
+				goerr.V("length", len(x.sessionSecret)),
+			)
+		}
+		if baseURL == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--base-url is required to sign in with GitHub OAuth")
+		}
+		redirectURL, err := url.JoinPath(baseURL, "auth", "callback")
+		if err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "invalid --base-url", goerr.V("base-url", baseURL))
+		}
+
+		client, err := ghoauth.New(x.githubClientID, x.githubClientSecret, redirectURL)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, server.WithGitHubOAuth(client, []byte(x.sessionSecret)))
+	} else if len(x.adminTeams) > 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--auth-admin-team requires GitHub OAuth")
+	}
+
+	return options, nil
+}
+
+// loadTenants reads --auth-tenants-file. A tenant must have a unique name and at least one owner or installation, and an API token must not be shared with other tenants or --auth-api-token, so that a token always identifies one boundary.
+func (x *Auth) loadTenants() ([]*model.TenantDefinition, error) {
+	if x.tenantsFile == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(x.tenantsFile))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read tenants file", goerr.V("path", x.tenantsFile))
+	}
+	var file tenantsFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid tenants file", goerr.V("path", x.tenantsFile), goerr.V("error", err.Error()))
+	}
+
+	names := map[string]bool{}
+	tokens := map[string]bool{}
+	for _, token := range x.apiTokens {
+		tokens[token] = true
+	}
+	for i, tenant := range file.Tenants {
+		if tenant.Name == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "tenant name is required", goerr.V("index", i))
+		}
+		if names[tenant.Name] {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "duplicated tenant name", goerr.V("name", tenant.Name))
+		}
+		names[tenant.Name] = true
+
+		if len(tenant.Owners) == 0 && len(tenant.InstallationIDs) == 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "tenant requires owners or installation_ids", goerr.V("name", tenant.Name))
+		}
+		for _, token := range tenant.APITokens {
+			if token == "" || tokens[token] {
+				return nil, goerr.Wrap(types.ErrInvalidOption, "API token of tenant is empty or used by another tenant", goerr.V("name", tenant.Name))
+			}
+			tokens[token] = true
+		}
+	}
+
+	return file.Tenants, nil
+}
+
+func (x *Auth) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("APITokens.len", len(x.apiTokens)),
+		slog.String("OIDCIssuer", x.oidcIssuer),
+		slog.String("OIDCAudience", x.oidcAudience),
+		slog.Any("OIDCSubjects", x.oidcSubjects),
+		slog.String("GitHubClientID", x.githubClientID),
+		slog.Int("GitHubClientSecret.len", len(x.githubClientSecret)),
+		slog.Int("SessionSecret.len", len(x.sessionSecret)),
+		slog.Any("AllowedOrgs", x.allowedOrgs),
+		slog.Any("AllowedTeams", x.allowedTeams),
+		slog.Any("AdminTeams", x.adminTeams),
+		slog.String("TenantsFile", x.tenantsFile),
+	)
+}
@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func parseAuthFlags(t *testing.T, args ...string) *config.Auth {
+	var auth config.Auth
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  auth.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &auth
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+	const baseURL = "https://octovy.example.com"
+	const sessionSecret = "0123456789abcdef0123456789abcdef"
+
+	t.Run("only policy without authentication methods", func(t *testing.T) {
+		options := gt.R1(parseAuthFlags(t).ServerOptions(ctx, baseURL)).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("API tokens and GitHub OAuth", func(t *testing.T) {
+		auth := parseAuthFlags(t,
+			"--auth-api-token", "token-1",
+			"--auth-github-client-id", "client-id",
+			"--auth-github-client-secret", "client-secret",
+			"--auth-session-secret", sessionSecret,
+			"--auth-allowed-org", "myorg",
+		)
+		options := gt.R1(auth.ServerOptions(ctx, baseURL)).NoError(t)
+		gt.A(t, options).Length(3)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		testCases := map[string][]string{
+			"OAuth without allowed orgs or teams": {
+				"--auth-github-client-id", "client-id", "--auth-github-client-secret", "client-secret", "--auth-session-secret", sessionSecret,
+			},
+			"OAuth with short session secret": {
+				"--auth-github-client-id", "client-id", "--auth-github-client-secret", "client-secret", "--auth-session-secret", "short", "--auth-allowed-org", "myorg",
+			},
+			"OAuth without client secret": {
+				"--auth-github-client-id", "client-id", "--auth-session-secret", sessionSecret, "--auth-allowed-org", "myorg",
+			},
+			"OIDC without allowed orgs or subjects": {
+				"--auth-oidc-issuer", "https://token.actions.githubusercontent.com", "--auth-oidc-audience", "octovy", "--auth-allowed-team", "myorg/security",
+			},
+			"admin teams without OAuth": {
+				"--auth-admin-team", "myorg/admins",
+			},
+		}
+		for name, args := range testCases {
+			t.Run(name, func(t *testing.T) {
+				_, err := parseAuthFlags(t, args...).ServerOptions(ctx, baseURL)
+				gt.Error(t, err)
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+			})
+		}
+	})
+
+	t.Run("OAuth requires base URL", func(t *testing.T) {
+		auth := parseAuthFlags(t,
+			"--auth-github-client-id", "client-id",
+			"--auth-github-client-secret", "client-secret",
+			"--auth-session-secret", sessionSecret,
+			"--auth-allowed-team", "myorg/security",
+		)
+		_, err := auth.ServerOptions(ctx, "")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("tenants file", func(t *testing.T) {
+		writeTenants := func(t *testing.T, body string) string {
+			path := filepath.Join(t.TempDir(), "tenants.yaml")
+			gt.NoError(t, os.WriteFile(path, []byte(body), 0600))
+			return path
+		}
+
+		t.Run("tenants bound to GitHub orgs enable GitHub OAuth", func(t *testing.T) {
+			path := writeTenants(t, `tenants:
+  - name: alpha
+    owners: [alpha-org]
+    api_tokens: [alpha-token]
+    github_orgs: [alpha-org]
+  - name: beta
+    installation_ids: [12345]
+    api_tokens: [beta-token]
+`)
+			auth := parseAuthFlags(t,
+				"--auth-tenants-file", path,
+				"--auth-github-client-id", "client-id",
+				"--auth-github-client-secret", "client-secret",
+				"--auth-session-secret", sessionSecret,
+			)
+			options := gt.R1(auth.ServerOptions(ctx, baseURL)).NoError(t)
+			gt.A(t, options).Length(2)
+		})
+
+		testCases := map[string]string{
+			"without name":                 "tenants:\n  - owners: [alpha-org]\n",
+			"duplicated name":              "tenants:\n  - name: alpha\n    owners: [a]\n  - name: alpha\n    owners: [b]\n",
+			"without owners or installs":   "tenants:\n  - name: alpha\n    api_tokens: [alpha-token]\n",
+			"token shared by tenants":      "tenants:\n  - name: alpha\n    owners: [a]\n    api_tokens: [token]\n  - name: beta\n    owners: [b]\n    api_tokens: [token]\n",
+			"token shared with global one": "tenants:\n  - name: alpha\n    owners: [a]\n    api_tokens: [token-1]\n",
+			"malformed":                    "tenants: {name: alpha",
+		}
+		for name, body := range testCases {
+			t.Run(name, func(t *testing.T) {
+				auth := parseAuthFlags(t, "--auth-api-token", "token-1", "--auth-tenants-file", writeTenants(t, body))
+				_, err := auth.ServerOptions(ctx, baseURL)
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+			})
+		}
+	})
+}
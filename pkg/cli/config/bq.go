@@ -3,11 +3,13 @@ package config
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra/bq"
+	"github.com/m-mizutani/octovy/pkg/usecase"
 	"github.com/urfave/cli/v3"
 	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
@@ -18,6 +20,15 @@ type BigQuery struct {
 	datasetID                 types.BQDatasetID
 	tableID                   types.BQTableID
 	impersonateServiceAccount string
+	insertMode                string
+	partitioning              string
+	clustering                bool
+	tableRouting              string
+	createDataset             bool
+	datasetLocation           string
+	insertMaxRetries          int
+	insertInitialBackoff      time.Duration
+	insertMaxBackoff          time.Duration
 }
 
 func (x *BigQuery) Flags() []cli.Flag {
@@ -45,6 +56,76 @@ func (x *BigQuery) Flags() []cli.Flag {
 			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_TABLE_ID"),
 			Value:       "scans",
 		},
+		&cli.StringFlag{
+			Name:        "bigquery-insert-mode",
+			Usage:       "Layout of scan results in BigQuery [raw|normalized]. normalized also writes {table}_vulnerabilities and {table}_packages tables",
+			Category:    "BigQuery",
+			Destination: &x.insertMode,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_INSERT_MODE"),
+			Value:       string(types.BQInsertModeRaw),
+		},
+		&cli.StringFlag{
+			Name:        "bigquery-partitioning",
+			Usage:       "Time-partitioning of new BigQuery tables by the timestamp column [none|hour|day|month|year]. Partitioning of existing tables is not changed",
+			Category:    "BigQuery",
+			Destination: &x.partitioning,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_PARTITIONING"),
+			Value:       string(types.BQPartitioningDay),
+		},
+		&cli.BoolFlag{
+			Name:        "bigquery-clustering",
+			Usage:       "Cluster BigQuery tables by owner and repository name. Clustering of existing tables is updated",
+			Category:    "BigQuery",
+			Destination: &x.clustering,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_CLUSTERING"),
+			Value:       true,
+		},
+		&cli.StringFlag{
+			Name:        "bigquery-table-routing",
+			Usage:       "Store scans in tables per month (e.g. {table}_202501) or per owner (e.g. {table}_my_org) [none|month|owner]",
+			Category:    "BigQuery",
+			Destination: &x.tableRouting,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_TABLE_ROUTING"),
+			Value:       string(types.BQTableRoutingNone),
+		},
+		&cli.BoolFlag{
+			Name:        "bigquery-create-dataset",
+			Usage:       "Create the BigQuery dataset if it does not exist",
+			Category:    "BigQuery",
+			Destination: &x.createDataset,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_CREATE_DATASET"),
+		},
+		&cli.StringFlag{
+			Name:        "bigquery-dataset-location",
+			Usage:       "Location of the BigQuery dataset created by --bigquery-create-dataset, e.g. US or asia-northeast1",
+			Category:    "BigQuery",
+			Destination: &x.datasetLocation,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_DATASET_LOCATION"),
+		},
+		&cli.IntFlag{
+			Name:        "bq-insert-max-retries",
+			Usage:       "Max number of retries of an insert failed because an updated table schema is not propagated yet",
+			Category:    "BigQuery",
+			Destination: &x.insertMaxRetries,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_INSERT_MAX_RETRIES"),
+			Value:       bq.DefaultInsertMaxRetries,
+		},
+		&cli.DurationFlag{
+			Name:        "bq-insert-initial-backoff",
+			Usage:       "Wait before the first retry of an insert. It grows by 1.5 times up to --bq-insert-max-backoff",
+			Category:    "BigQuery",
+			Destination: &x.insertInitialBackoff,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_INSERT_INITIAL_BACKOFF"),
+			Value:       bq.DefaultInsertInitialBackoff,
+		},
+		&cli.DurationFlag{
+			Name:        "bq-insert-max-backoff",
+			Usage:       "Max wait between retries of an insert",
+			Category:    "BigQuery",
+			Destination: &x.insertMaxBackoff,
+			Sources:     cli.EnvVars("OCTOVY_BIGQUERY_INSERT_MAX_BACKOFF"),
+			Value:       bq.DefaultInsertMaxBackoff,
+		},
 		&cli.StringFlag{
 			Name:        "bq-impersonate-service-account",
 			Usage:       "Impersonate service account for BigQuery",
@@ -60,14 +141,71 @@ func (x *BigQuery) LogValue() slog.Value {
 		slog.Any("DatasetID", x.datasetID),
 		slog.Any("TableID", x.tableID),
 		slog.Any("ImpersonateServiceAccount", x.impersonateServiceAccount),
+		slog.String("InsertMode", x.insertMode),
+		slog.String("Partitioning", x.partitioning),
+		slog.Bool("Clustering", x.clustering),
+		slog.String("TableRouting", x.tableRouting),
+		slog.Bool("CreateDataset", x.createDataset),
+		slog.String("DatasetLocation", x.datasetLocation),
+		slog.Int("InsertMaxRetries", x.insertMaxRetries),
+		slog.Duration("InsertInitialBackoff", x.insertInitialBackoff),
+		slog.Duration("InsertMaxBackoff", x.insertMaxBackoff),
 	)
 }
 
+// UseCaseOptions returns usecase options to store scan results in the layout of --bigquery-insert-mode and in tables of --bigquery-table-routing, and to partition and cluster BigQuery tables.
+func (x *BigQuery) UseCaseOptions() ([]usecase.Option, error) {
+	mode := types.BQInsertMode(x.insertMode)
+	if mode == "" {
+		mode = types.BQInsertModeRaw
+	}
+	if err := mode.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid --bigquery-insert-mode option")
+	}
+
+	partitioning := types.BQPartitioning(x.partitioning)
+	if partitioning == "" {
+		partitioning = types.BQPartitioningNone
+	}
+	if err := partitioning.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid --bigquery-partitioning option")
+	}
+
+	routing := types.BQTableRouting(x.tableRouting)
+	if routing == "" {
+		routing = types.BQTableRoutingNone
+	}
+	if err := routing.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid --bigquery-table-routing option")
+	}
+
+	var options []usecase.Option
+	if routing != types.BQTableRoutingNone {
+		options = append(options, usecase.WithBigQueryTableRouting(x.tableID, routing))
+	}
+	if mode == types.BQInsertModeNormalized {
+		options = append(options, usecase.WithBigQueryNormalizedInsert(x.tableID+"_vulnerabilities", x.tableID+"_packages"))
+	}
+	if partitioning != types.BQPartitioningNone || x.clustering {
+		options = append(options, usecase.WithBigQueryTableLayout(partitioning, x.clustering))
+	}
+	return options, nil
+}
+
 func (x *BigQuery) NewClient(ctx context.Context) (interfaces.BigQuery, error) {
+	if x.insertMaxRetries < 0 || x.insertInitialBackoff <= 0 || x.insertMaxBackoff < x.insertInitialBackoff {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid BigQuery insert retry options",
+			goerr.V("max_retries", x.insertMaxRetries),
+			goerr.V("initial_backoff", x.insertInitialBackoff),
+			goerr.V("max_backoff", x.insertMaxBackoff),
+		)
+	}
 	if x.projectID == "" && x.datasetID == "" {
 		return nil, nil
 	}
-	var options []option.ClientOption
+	options := []bq.Option{
+		bq.WithInsertRetry(x.insertMaxRetries, x.insertInitialBackoff, x.insertMaxBackoff),
+	}
 	if x.impersonateServiceAccount != "" {
 		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
 			TargetPrincipal: x.impersonateServiceAccount,
@@ -80,8 +218,17 @@ func (x *BigQuery) NewClient(ctx context.Context) (interfaces.BigQuery, error) {
 			return nil, goerr.Wrap(err, "failed to create token source for impersonate")
 		}
 
-		options = append(options, option.WithTokenSource(ts))
+		options = append(options, bq.WithClientOptions(option.WithTokenSource(ts)))
 	}
 
-	return bq.New(ctx, x.projectID, x.datasetID, x.tableID, options...)
+	client, err := bq.New(ctx, x.projectID, x.datasetID, x.tableID, options...)
+	if err != nil {
+		return nil, err
+	}
+	if x.createDataset {
+		if err := client.CreateDatasetIfNotExists(ctx, x.datasetLocation); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
 }
@@ -0,0 +1,70 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func parseBigQueryFlags(t *testing.T, args ...string) *config.BigQuery {
+	var bq config.BigQuery
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  bq.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &bq
+}
+
+func TestBigQueryUseCaseOptions(t *testing.T) {
+	t.Run("raw mode with partitioning and clustering by default", func(t *testing.T) {
+		opts, err := parseBigQueryFlags(t).UseCaseOptions()
+		gt.NoError(t, err)
+		gt.A(t, opts).Length(1)
+	})
+
+	t.Run("raw mode without partitioning and clustering", func(t *testing.T) {
+		opts, err := parseBigQueryFlags(t, "--bigquery-partitioning", "none", "--bigquery-clustering=false").UseCaseOptions()
+		gt.NoError(t, err)
+		gt.A(t, opts).Length(0)
+	})
+
+	t.Run("normalized mode", func(t *testing.T) {
+		opts, err := parseBigQueryFlags(t, "--bigquery-insert-mode", "normalized").UseCaseOptions()
+		gt.NoError(t, err)
+		gt.A(t, opts).Length(2)
+	})
+
+	t.Run("unsupported mode", func(t *testing.T) {
+		_, err := parseBigQueryFlags(t, "--bigquery-insert-mode", "columnar").UseCaseOptions()
+		gt.Error(t, err)
+	})
+
+	t.Run("table routing", func(t *testing.T) {
+		opts, err := parseBigQueryFlags(t, "--bigquery-table-routing", "month").UseCaseOptions()
+		gt.NoError(t, err)
+		gt.A(t, opts).Length(2)
+
+		_, err = parseBigQueryFlags(t, "--bigquery-table-routing", "repo").UseCaseOptions()
+		gt.Error(t, err)
+	})
+
+	t.Run("unsupported partitioning", func(t *testing.T) {
+		_, err := parseBigQueryFlags(t, "--bigquery-partitioning", "week").UseCaseOptions()
+		gt.Error(t, err)
+	})
+}
+
+func TestBigQueryInsertRetryOptions(t *testing.T) {
+	_, err := parseBigQueryFlags(t, "--bq-insert-initial-backoff", "10s", "--bq-insert-max-backoff", "5s").NewClient(context.Background())
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+
+	_, err = parseBigQueryFlags(t, "--bq-insert-max-retries", "-1").NewClient(context.Background())
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/octovy/pkg/infra/exploitability"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+type Exploitability struct {
+	enabled  bool
+	cacheDir string
+	epssURL  string
+	kevURL   string
+}
+
+func (x *Exploitability) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "exploitability",
+			Usage:       "Store EPSS scores and CISA KEV flags of CVEs with vulnerabilities in Firestore. Data is downloaded daily from --epss-url and --kev-url",
+			Category:    "Exploitability",
+			Destination: &x.enabled,
+			Sources:     cli.EnvVars("OCTOVY_EXPLOITABILITY"),
+		},
+		&cli.StringFlag{
+			Name:        "exploitability-cache-dir",
+			Usage:       "Directory to cache downloaded EPSS and KEV data across runs (default: memory only)",
+			Category:    "Exploitability",
+			Destination: &x.cacheDir,
+			Sources:     cli.EnvVars("OCTOVY_EXPLOITABILITY_CACHE_DIR"),
+		},
+		&cli.StringFlag{
+			Name:        "epss-url",
+			Usage:       "URL of the EPSS scores CSV file, optionally gzip compressed",
+			Category:    "Exploitability",
+			Value:       exploitability.DefaultEPSSURL,
+			Destination: &x.epssURL,
+			Sources:     cli.EnvVars("OCTOVY_EPSS_URL"),
+		},
+		&cli.StringFlag{
+			Name:        "kev-url",
+			Usage:       "URL of the CISA Known Exploited Vulnerabilities catalog JSON file",
+			Category:    "Exploitability",
+			Value:       exploitability.DefaultKEVURL,
+			Destination: &x.kevURL,
+			Sources:     cli.EnvVars("OCTOVY_KEV_URL"),
+		},
+	}
+}
+
+func (x *Exploitability) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("Enabled", x.enabled),
+		slog.String("CacheDir", x.cacheDir),
+		slog.String("EPSSURL", x.epssURL),
+		slog.String("KEVURL", x.kevURL),
+	)
+}
+
+// UseCaseOptions returns usecase options to enrich vulnerabilities if --exploitability is set. Data is downloaded at the first scan, not here, so that a server starts without access to the data sources.
+func (x *Exploitability) UseCaseOptions(options ...exploitability.Option) []usecase.Option {
+	if !x.enabled {
+		return nil
+	}
+
+	options = append(options, exploitability.WithURLs(x.epssURL, x.kevURL))
+	if x.cacheDir != "" {
+		options = append(options, exploitability.WithCacheDir(x.cacheDir))
+	}
+	return []usecase.Option{usecase.WithExploitability(exploitability.New(options...))}
+}
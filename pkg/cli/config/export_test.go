@@ -0,0 +1,5 @@
+package config
+
+func (x *Trivy) RegistryEnvForTest() []string {
+	return x.registry.Env()
+}
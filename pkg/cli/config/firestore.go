@@ -3,19 +3,56 @@ package config
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/gcs"
+	"github.com/m-mizutani/octovy/pkg/infra/scanlock"
+	"github.com/m-mizutani/octovy/pkg/repository/cached"
 	"github.com/m-mizutani/octovy/pkg/repository/firestore"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/repository/sqlite"
 	"github.com/urfave/cli/v3"
 )
 
+const (
+	// dbFirestore stores scans in Firestore of --firestore-project-id
+	dbFirestore = "firestore"
+	// dbLocal stores scans in memory and a JSON file of --db-path, for local development
+	dbLocal = "local"
+	// dbSQLite stores scans in a SQLite database file of --db-path
+	dbSQLite = "sqlite"
+)
+
+// Firestore configures the scan repository. It is Firestore by default, a local JSON file with --db local, or a SQLite database with --db sqlite.
 type Firestore struct {
-	projectID  string
-	databaseID string
+	db             string
+	dbPath         string
+	projectID      string
+	databaseID     string
+	overflowBucket string
+	overflowPrefix string
+	cacheSize      int
+	cacheTTL       time.Duration
 }
 
 func (x *Firestore) Flags() []cli.Flag {
 	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "db",
+			Usage:       "Database of scan results, repositories and vulnerability statuses [firestore|local|sqlite]. local keeps them in memory and a JSON file of --db-path for local development, and sqlite stores them in a SQLite database file of --db-path, both without Firestore",
+			Sources:     cli.EnvVars("OCTOVY_DB"),
+			Value:       dbFirestore,
+			Destination: &x.db,
+		},
+		&cli.StringFlag{
+			Name:        "db-path",
+			Usage:       "File of --db local or sqlite, octovy.json or octovy.db by default",
+			Sources:     cli.EnvVars("OCTOVY_DB_PATH"),
+			Destination: &x.dbPath,
+		},
 		&cli.StringFlag{
 			Name:        "firestore-project-id",
 			Usage:       "Firestore project ID (optional)",
@@ -29,20 +66,94 @@ func (x *Firestore) Flags() []cli.Flag {
 			Value:       "(default)",
 			Destination: &x.databaseID,
 		},
+		&cli.StringFlag{
+			Name:        "firestore-overflow-bucket",
+			Usage:       "Cloud Storage bucket to store vulnerabilities in full when they are truncated to fit in the Firestore document size limit (optional)",
+			Sources:     cli.EnvVars("OCTOVY_FIRESTORE_OVERFLOW_BUCKET"),
+			Destination: &x.overflowBucket,
+		},
+		&cli.StringFlag{
+			Name:        "firestore-overflow-prefix",
+			Usage:       "Object name prefix in --firestore-overflow-bucket",
+			Sources:     cli.EnvVars("OCTOVY_FIRESTORE_OVERFLOW_PREFIX"),
+			Value:       "octovy/overflow",
+			Destination: &x.overflowPrefix,
+		},
+		&cli.IntFlag{
+			Name:        "firestore-cache-size",
+			Usage:       "Maximum number of repositories, branches and vulnerability lists of targets cached in memory to reduce Firestore reads. 0 disables the cache",
+			Sources:     cli.EnvVars("OCTOVY_FIRESTORE_CACHE_SIZE"),
+			Destination: &x.cacheSize,
+		},
+		&cli.DurationFlag{
+			Name:        "firestore-cache-ttl",
+			Usage:       "How long an entry is served from the cache of --firestore-cache-size. Changes by other instances may not be seen during this time",
+			Sources:     cli.EnvVars("OCTOVY_FIRESTORE_CACHE_TTL"),
+			Value:       cached.DefaultTTL,
+			Destination: &x.cacheTTL,
+		},
 	}
 }
 
+// Enabled returns true if a scan repository is configured, Firestore by --firestore-project-id or another database by --db
 func (x *Firestore) Enabled() bool {
-	return x.projectID != ""
+	return (x.db != "" && x.db != dbFirestore) || x.projectID != ""
 }
 
 func (x *Firestore) LogValue() slog.Value {
 	return slog.GroupValue(
+		slog.String("db", x.db),
+		slog.String("dbPath", x.dbPath),
 		slog.Any("projectID", x.projectID),
 		slog.Any("databaseID", x.databaseID),
+		slog.String("overflowBucket", x.overflowBucket),
+		slog.String("overflowPrefix", x.overflowPrefix),
+		slog.Int("cacheSize", x.cacheSize),
+		slog.Duration("cacheTTL", x.cacheTTL),
 	)
 }
 
+func (x *Firestore) pathOr(defaultPath string) string {
+	if x.dbPath == "" {
+		return defaultPath
+	}
+	return x.dbPath
+}
+
 func (x *Firestore) NewRepository(ctx context.Context) (interfaces.ScanRepository, error) {
-	return firestore.New(ctx, x.projectID, x.databaseID)
+	switch x.db {
+	case dbLocal:
+		return memory.NewFile(x.pathOr("octovy.json"))
+	case dbSQLite:
+		return sqlite.New(ctx, x.pathOr("octovy.db"))
+	case dbFirestore, "":
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid --db option", goerr.V("db", x.db))
+	}
+
+	var options []firestore.Option
+	if x.overflowBucket != "" {
+		storage, err := gcs.New(ctx, x.overflowBucket, x.overflowPrefix)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, firestore.WithOverflowStorage(storage))
+	}
+
+	repo, err := firestore.New(ctx, x.projectID, x.databaseID, options...)
+	if err != nil {
+		return nil, err
+	}
+	if x.cacheSize > 0 {
+		return cached.New(repo, cached.WithSize(x.cacheSize), cached.WithTTL(x.cacheTTL)), nil
+	}
+	return repo, nil
+}
+
+// NewScanLock returns a lock of scans shared by instances with the Firestore database, or nil with --db local or sqlite, which are used by a single instance and excluded by the lock in memory.
+func (x *Firestore) NewScanLock(ctx context.Context) (interfaces.ScanLock, error) {
+	if x.db != dbFirestore && x.db != "" {
+		return nil, nil
+	}
+	return scanlock.NewFirestore(ctx, x.projectID, x.databaseID)
 }
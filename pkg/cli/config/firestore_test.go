@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func TestFirestoreLocalDB(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.Firestore {
+		var firestore config.Firestore
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  firestore.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+		return &firestore
+	}
+	ctx := context.Background()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		gt.False(t, parse(t).Enabled())
+	})
+
+	t.Run("local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "octovy.json")
+		firestore := parse(t, "--db", "local", "--db-path", path)
+		gt.True(t, firestore.Enabled())
+
+		repo := gt.R1(firestore.NewRepository(ctx)).NoError(t)
+		repoID := types.NewGitHubRepoID("octo", "app")
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "octo", Name: "app"}))
+
+		reopened := gt.R1(firestore.NewRepository(ctx)).NoError(t)
+		gt.R1(reopened.GetRepository(ctx, repoID)).NoError(t)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "octovy.db")
+		firestore := parse(t, "--db", "sqlite", "--db-path", path)
+		gt.True(t, firestore.Enabled())
+
+		repo := gt.R1(firestore.NewRepository(ctx)).NoError(t)
+		gt.NoError(t, repo.Ping(ctx))
+	})
+
+	t.Run("unsupported database", func(t *testing.T) {
+		firestore := parse(t, "--db", "mysql")
+		gt.True(t, firestore.Enabled())
+		_, err := firestore.NewRepository(ctx)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
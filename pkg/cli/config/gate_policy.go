@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/infra/wasmpolicy"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+// GatePolicy configures WASM policy plugins of the deployment gate
+type GatePolicy struct {
+	plugins []string
+	timeout time.Duration
+}
+
+func (x *GatePolicy) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "gate-policy",
+			Usage:       "File path of a WASM policy plugin of the deployment gate, or its OCI artifact reference prefixed with oci://, e.g. oci://ghcr.io/myorg/gate-policy:v1 (can be repeated)",
+			Category:    "Gate Policy",
+			Destination: &x.plugins,
+			Sources:     cli.EnvVars("OCTOVY_GATE_POLICY"),
+		},
+		&cli.DurationFlag{
+			Name:        "gate-policy-timeout",
+			Usage:       "Time limit of an evaluation of a WASM policy plugin",
+			Category:    "Gate Policy",
+			Value:       wasmpolicy.DefaultTimeout,
+			Destination: &x.timeout,
+			Sources:     cli.EnvVars("OCTOVY_GATE_POLICY_TIMEOUT"),
+		},
+	}
+}
+
+func (x *GatePolicy) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("Plugins", x.plugins),
+		slog.Duration("Timeout", x.timeout),
+	)
+}
+
+// UseCaseOptions loads and compiles plugins of --gate-policy and returns usecase options to evaluate them in deployment gates. Plugins are loaded only once, so that a server does not start with a broken plugin.
+func (x *GatePolicy) UseCaseOptions(ctx context.Context, options ...wasmpolicy.Option) ([]usecase.Option, error) {
+	if len(x.plugins) == 0 {
+		return nil, nil
+	}
+
+	options = append(options, wasmpolicy.WithTimeout(x.timeout))
+	policies := make([]interfaces.GatePolicy, 0, len(x.plugins))
+	for _, source := range x.plugins {
+		plugin, err := wasmpolicy.Load(ctx, source, options...)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to load --gate-policy", goerr.V("source", source))
+		}
+		policies = append(policies, plugin)
+	}
+	return []usecase.Option{usecase.WithGatePolicies(policies...)}, nil
+}
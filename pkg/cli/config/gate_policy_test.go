@@ -0,0 +1,45 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func TestGatePolicy(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.GatePolicy {
+		var policy config.GatePolicy
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  policy.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+		return &policy
+	}
+
+	t.Run("no policy by default", func(t *testing.T) {
+		options := gt.R1(parse(t).UseCaseOptions(context.Background())).NoError(t)
+		gt.A(t, options).Length(0)
+	})
+
+	t.Run("missing plugin", func(t *testing.T) {
+		_, err := parse(t, "--gate-policy", filepath.Join(t.TempDir(), "not-found.wasm")).UseCaseOptions(context.Background())
+		gt.Error(t, err)
+	})
+
+	t.Run("invalid plugin", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.wasm")
+		gt.NoError(t, os.WriteFile(path, []byte("not wasm"), 0600))
+
+		_, err := parse(t, "--gate-policy", path).UseCaseOptions(context.Background())
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
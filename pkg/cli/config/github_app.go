@@ -2,16 +2,53 @@ package config
 
 import (
 	"log/slog"
+	"os"
+	"path/filepath"
 
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
+	"github.com/m-mizutani/octovy/pkg/infra/gitclone"
+	"github.com/m-mizutani/octovy/pkg/infra/upgrader"
+	"github.com/m-mizutani/octovy/pkg/usecase"
 	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
 )
 
 type GitHubApp struct {
-	id         types.GitHubAppID
-	secret     types.GitHubAppSecret     `masq:"secret"`
-	privateKey types.GitHubAppPrivateKey `masq:"secret"`
+	id               types.GitHubAppID
+	secret           types.GitHubAppSecret     `masq:"secret"`
+	privateKey       types.GitHubAppPrivateKey `masq:"secret"`
+	rateLimitReserve int
+	dependencyLabel  string
+	incrementalPR    bool
+	misconfigCheck   bool
+	vulnCheck        bool
+	vulnCheckSev     string
+	vulnCheckBase    string
+	fetchMode        string
+	gitPath          string
+	upgradePR        bool
+	upgradeMaxOpen   int
+	upgradeLabels    []string
+	goPath           string
+	npmPath          string
+	appsFile         string
+}
+
+// githubAppsFile is the format of --github-apps-file
+type githubAppsFile struct {
+	Apps []*githubAppDefinition `yaml:"apps"`
+}
+
+// githubAppDefinition is a GitHub App served in addition to --github-app-id. The private key is given inline or by a file.
+type githubAppDefinition struct {
+	ID             types.GitHubAppID         `yaml:"id"`
+	PrivateKey     types.GitHubAppPrivateKey `yaml:"private_key" masq:"secret"`
+	PrivateKeyFile string                    `yaml:"private_key_file"`
+	Secret         types.GitHubAppSecret     `yaml:"secret" masq:"secret"`
 }
 
 func (x *GitHubApp) Flags() []cli.Flag {
@@ -39,11 +76,278 @@ func (x *GitHubApp) Flags() []cli.Flag {
 			Destination: (*string)(&x.secret),
 			Sources:     cli.EnvVars("OCTOVY_GITHUB_APP_SECRET"),
 		},
+		&cli.StringFlag{
+			Name:        "github-apps-file",
+			Usage:       "YAML file of GitHub Apps served in addition to --github-app-id, e.g. of other organizations or environments. Each app has id, private_key or private_key_file, and secret",
+			Category:    "GitHub App",
+			Destination: &x.appsFile,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_APPS_FILE"),
+		},
+		&cli.IntFlag{
+			Name:        "github-rate-limit-reserve",
+			Usage:       "Number of GitHub API requests per installation kept in reserve. Owner-wide scans wait for the rate limit reset when the remaining quota is below it",
+			Category:    "GitHub App",
+			Destination: &x.rateLimitReserve,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_RATE_LIMIT_RESERVE"),
+			Value:       100,
+		},
+		&cli.StringFlag{
+			Name:        "github-dependency-update-label",
+			Usage:       "Label added to Dependabot and Renovate pull requests which fix vulnerabilities without introducing new ones. Requires write permission of pull requests",
+			Category:    "GitHub App",
+			Destination: &x.dependencyLabel,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_DEPENDENCY_UPDATE_LABEL"),
+		},
+		&cli.BoolFlag{
+			Name:        "github-incremental-pr-scan",
+			Usage:       "Scan only directories of files changed by a pull request instead of the whole repository. Results are stored only in BigQuery",
+			Category:    "GitHub App",
+			Destination: &x.incrementalPR,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_INCREMENTAL_PR_SCAN"),
+		},
+		&cli.BoolFlag{
+			Name:        "github-misconfig-check-run",
+			Usage:       "Report misconfigurations of a pull request as a check run with inline annotations. Requires --trivy-scanners misconfig and write permission of checks",
+			Category:    "GitHub App",
+			Destination: &x.misconfigCheck,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_MISCONFIG_CHECK_RUN"),
+		},
+		&cli.BoolFlag{
+			Name:        "github-vuln-check-run",
+			Usage:       "Report vulnerabilities of a pull request as a check run which fails on them. Requires write permission of checks",
+			Category:    "GitHub App",
+			Destination: &x.vulnCheck,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_VULN_CHECK_RUN"),
+		},
+		&cli.StringFlag{
+			Name:        "github-vuln-check-severity",
+			Usage:       "Comma separated severities of vulnerabilities failing the check run of --github-vuln-check-run, e.g. CRITICAL,HIGH. The lowest one is the threshold",
+			Category:    "GitHub App",
+			Value:       string(types.SeverityHigh),
+			Destination: &x.vulnCheckSev,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_VULN_CHECK_SEVERITY"),
+		},
+		&cli.StringFlag{
+			Name:        "github-vuln-check-baseline",
+			Usage:       "Vulnerabilities failing the check run of --github-vuln-check-run [base|none]. base fails only on vulnerabilities not in the last scan of the base branch. It can be overridden per repository by admin metadata",
+			Category:    "GitHub App",
+			Value:       string(types.CheckBaselineBase),
+			Destination: &x.vulnCheckBase,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_VULN_CHECK_BASELINE"),
+		},
+		&cli.BoolFlag{
+			Name:        "github-upgrade-pr",
+			Usage:       "Open pull requests upgrading packages with active vulnerabilities of the default branch to fixed versions after its scan. Supports Go modules and npm. Requires write permission of contents and pull requests",
+			Category:    "GitHub App",
+			Destination: &x.upgradePR,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_UPGRADE_PR"),
+		},
+		&cli.IntFlag{
+			Name:        "github-upgrade-pr-max-open",
+			Usage:       "Max number of open pull requests of --github-upgrade-pr per repository",
+			Category:    "GitHub App",
+			Value:       5,
+			Destination: &x.upgradeMaxOpen,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_UPGRADE_PR_MAX_OPEN"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "github-upgrade-pr-label",
+			Usage:       "Label added to pull requests of --github-upgrade-pr (can be specified multiple times)",
+			Category:    "GitHub App",
+			Destination: &x.upgradeLabels,
+			Sources:     cli.EnvVars("OCTOVY_GITHUB_UPGRADE_PR_LABEL"),
+		},
+		&cli.StringFlag{
+			Name:        "go-path",
+			Usage:       "Path to go binary, used with --github-upgrade-pr",
+			Category:    "GitHub App",
+			Value:       "go",
+			Destination: &x.goPath,
+			Sources:     cli.EnvVars("OCTOVY_GO_PATH"),
+		},
+		&cli.StringFlag{
+			Name:        "npm-path",
+			Usage:       "Path to npm binary, used with --github-upgrade-pr",
+			Category:    "GitHub App",
+			Value:       "npm",
+			Destination: &x.npmPath,
+			Sources:     cli.EnvVars("OCTOVY_NPM_PATH"),
+		},
+		&cli.StringFlag{
+			Name:        "fetch-mode",
+			Usage:       "How to fetch source code of a repository [archive|clone]. clone runs a shallow clone with git for repositories too large for the zipball API",
+			Category:    "GitHub App",
+			Value:       string(types.FetchModeArchive),
+			Destination: &x.fetchMode,
+			Sources:     cli.EnvVars("OCTOVY_FETCH_MODE"),
+		},
+		&cli.StringFlag{
+			Name:        "git-path",
+			Usage:       "Path to git binary, used with --fetch-mode clone",
+			Category:    "GitHub App",
+			Value:       "git",
+			Destination: &x.gitPath,
+			Sources:     cli.EnvVars("OCTOVY_GIT_PATH"),
+		},
 	}
 }
 
-func (x GitHubApp) New() (*ghapp.Client, error) {
-	return ghapp.New(x.id, x.privateKey)
+// UseCaseOptions returns usecase options to keep GitHub API quota of --github-rate-limit-reserve in owner-wide scans, to label risk reducing dependency update pull requests, to scan pull requests incrementally and to report their misconfigurations and vulnerabilities as check runs.
+func (x *GitHubApp) UseCaseOptions() ([]usecase.Option, error) {
+	options := []usecase.Option{
+		usecase.WithGitHubRateLimitReserve(x.rateLimitReserve),
+	}
+	if x.dependencyLabel != "" {
+		options = append(options, usecase.WithDependencyUpdateLabel(x.dependencyLabel))
+	}
+	if x.incrementalPR {
+		options = append(options, usecase.WithIncrementalPullRequestScan())
+	}
+	if x.misconfigCheck {
+		options = append(options, usecase.WithMisconfigCheckRun())
+	}
+	if x.vulnCheck {
+		threshold, err := types.ParseSeverityThreshold(x.vulnCheckSev)
+		if err != nil {
+			return nil, goerr.Wrap(err, "invalid --github-vuln-check-severity option")
+		}
+		baseline := types.CheckBaseline(x.vulnCheckBase)
+		if err := baseline.Validate(); err != nil {
+			return nil, goerr.Wrap(err, "invalid --github-vuln-check-baseline option")
+		}
+		options = append(options, usecase.WithVulnerabilityCheckRun(threshold, baseline))
+	}
+	return options, nil
+}
+
+// MisconfigCheckRun returns true if misconfigurations of pull requests are reported as check runs
+func (x *GitHubApp) MisconfigCheckRun() bool {
+	return x.misconfigCheck
+}
+
+// FetcherOptions returns an option to fetch source code by the app with git clone if --fetch-mode is clone. It returns no option for archive, the default fetcher.
+func (x *GitHubApp) FetcherOptions(app interfaces.GitHubApp, options ...gitclone.Option) ([]usecase.Option, error) {
+	mode := types.FetchMode(x.fetchMode)
+	if mode == "" {
+		mode = types.FetchModeArchive
+	}
+	if err := mode.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid --fetch-mode option")
+	}
+
+	switch mode {
+	case types.FetchModeClone:
+		return []usecase.Option{usecase.WithFetcher(types.SourceGitHub, gitclone.New(app, x.gitPath, options...))}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// UpgraderOptions returns an option to open pull requests upgrading vulnerable packages with go and npm if --github-upgrade-pr is set
+func (x *GitHubApp) UpgraderOptions(options ...upgrader.Option) ([]usecase.Option, error) {
+	if !x.upgradePR {
+		return nil, nil
+	}
+	if x.upgradeMaxOpen <= 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--github-upgrade-pr-max-open must be positive", goerr.V("max_open", x.upgradeMaxOpen))
+	}
+
+	return []usecase.Option{
+		usecase.WithUpgradePullRequests(upgrader.New(x.goPath, x.npmPath, options...), model.UpgradePullRequestConfig{
+			MaxOpen: x.upgradeMaxOpen,
+			Labels:  x.upgradeLabels,
+		}),
+	}, nil
+}
+
+func (x GitHubApp) New(options ...ghapp.Option) (*ghapp.Client, error) {
+	return ghapp.New(x.id, x.privateKey, options...)
+}
+
+// NewApps returns a client of the app of --github-app-id and apps of --github-apps-file. Requests of an installation are sent by the app which the installation belongs to. It returns the client of the app of --github-app-id if no file is given.
+func (x GitHubApp) NewApps(options ...ghapp.Option) (interfaces.GitHubApp, error) {
+	primary, err := x.New(options...)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := x.loadApps()
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 {
+		return primary, nil
+	}
+
+	clients := []*ghapp.Client{primary}
+	for _, def := range defs {
+		client, err := ghapp.New(def.ID, def.PrivateKey, options...)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create client of GitHub App", goerr.V("id", def.ID))
+		}
+		clients = append(clients, client)
+	}
+	return ghapp.NewApps(clients...)
+}
+
+// WebhookSecrets returns webhook secrets of apps of --github-apps-file by app ID. The secret of --github-app-id is included if it is set.
+func (x GitHubApp) WebhookSecrets() (map[types.GitHubAppID]types.GitHubAppSecret, error) {
+	defs, err := x.loadApps()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[types.GitHubAppID]types.GitHubAppSecret)
+	if x.secret != "" {
+		secrets[x.id] = x.secret
+	}
+	for _, def := range defs {
+		secrets[def.ID] = def.Secret
+	}
+	return secrets, nil
+}
+
+// loadApps reads --github-apps-file. An app must have an ID different from others and --github-app-id, a secret, and exactly one of private_key and private_key_file. A private key file is read relative to the working directory.
+func (x GitHubApp) loadApps() ([]*githubAppDefinition, error) {
+	if x.appsFile == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(x.appsFile))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read GitHub Apps file", goerr.V("path", x.appsFile))
+	}
+	var file githubAppsFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid GitHub Apps file", goerr.V("path", x.appsFile), goerr.V("error", err.Error()))
+	}
+
+	ids := map[types.GitHubAppID]bool{x.id: true}
+	for i, def := range file.Apps {
+		if def.ID <= 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App ID is required", goerr.V("index", i))
+		}
+		if ids[def.ID] {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "duplicated GitHub App ID", goerr.V("id", def.ID))
+		}
+		ids[def.ID] = true
+
+		// An app without a secret would accept unsigned webhooks sent with its ID
+		if def.Secret == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "webhook secret of GitHub App is required", goerr.V("id", def.ID))
+		}
+		if (def.PrivateKey == "") == (def.PrivateKeyFile == "") {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App requires either private_key or private_key_file", goerr.V("id", def.ID))
+		}
+		if def.PrivateKeyFile != "" {
+			pem, err := os.ReadFile(filepath.Clean(def.PrivateKeyFile))
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to read private key of GitHub App", goerr.V("id", def.ID), goerr.V("path", def.PrivateKeyFile))
+			}
+			def.PrivateKey = types.GitHubAppPrivateKey(pem)
+		}
+	}
+
+	return file.Apps, nil
 }
 
 func (x GitHubApp) LogValue() slog.Value {
@@ -51,6 +355,21 @@ func (x GitHubApp) LogValue() slog.Value {
 		slog.Int64("ID", int64(x.id)),
 		slog.Int("Secret.len", len(x.secret)),
 		slog.Int("privateKey.len", len(x.privateKey)),
+		slog.Int("RateLimitReserve", x.rateLimitReserve),
+		slog.String("DependencyUpdateLabel", x.dependencyLabel),
+		slog.Bool("IncrementalPRScan", x.incrementalPR),
+		slog.Bool("MisconfigCheckRun", x.misconfigCheck),
+		slog.Bool("VulnCheckRun", x.vulnCheck),
+		slog.String("VulnCheckSeverity", x.vulnCheckSev),
+		slog.String("VulnCheckBaseline", x.vulnCheckBase),
+		slog.String("FetchMode", x.fetchMode),
+		slog.String("GitPath", x.gitPath),
+		slog.Bool("UpgradePR", x.upgradePR),
+		slog.Int("UpgradePRMaxOpen", x.upgradeMaxOpen),
+		slog.Any("UpgradePRLabels", x.upgradeLabels),
+		slog.String("GoPath", x.goPath),
+		slog.String("NpmPath", x.npmPath),
+		slog.String("AppsFile", x.appsFile),
 	)
 }
 
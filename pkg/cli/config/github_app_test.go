@@ -0,0 +1,203 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
+	"github.com/urfave/cli/v3"
+)
+
+func TestGitHubAppFetcherOptions(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.GitHubApp {
+		var githubApp config.GitHubApp
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  githubApp.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		args = append([]string{"test", "--github-app-id", "1", "--github-app-private-key", "dummy"}, args...)
+		gt.NoError(t, cmd.Run(context.Background(), args))
+		return &githubApp
+	}
+	app := &mock.GitHubAppMock{}
+
+	t.Run("archive is used by default", func(t *testing.T) {
+		options := gt.R1(parse(t).FetcherOptions(app)).NoError(t)
+		gt.A(t, options).Length(0)
+	})
+
+	t.Run("clone replaces archive", func(t *testing.T) {
+		options := gt.R1(parse(t, "--fetch-mode", "clone").FetcherOptions(app)).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("unsupported fetch mode", func(t *testing.T) {
+		_, err := parse(t, "--fetch-mode", "svn").FetcherOptions(app)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+func TestGitHubAppVulnCheckRunOptions(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.GitHubApp {
+		var githubApp config.GitHubApp
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  githubApp.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		args = append([]string{"test", "--github-app-id", "1", "--github-app-private-key", "dummy"}, args...)
+		gt.NoError(t, cmd.Run(context.Background(), args))
+		return &githubApp
+	}
+
+	t.Run("check run is disabled by default", func(t *testing.T) {
+		options := gt.R1(parse(t).UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("check run is enabled", func(t *testing.T) {
+		options := gt.R1(parse(t, "--github-vuln-check-run", "--github-vuln-check-severity", "CRITICAL,HIGH").UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(2)
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		_, err := parse(t, "--github-vuln-check-run", "--github-vuln-check-severity", "SEVERE").UseCaseOptions()
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("invalid baseline", func(t *testing.T) {
+		_, err := parse(t, "--github-vuln-check-run", "--github-vuln-check-baseline", "latest").UseCaseOptions()
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+func TestGitHubAppUpgraderOptions(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.GitHubApp {
+		var githubApp config.GitHubApp
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  githubApp.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		args = append([]string{"test", "--github-app-id", "1", "--github-app-private-key", "dummy"}, args...)
+		gt.NoError(t, cmd.Run(context.Background(), args))
+		return &githubApp
+	}
+
+	t.Run("upgrade pull requests are disabled by default", func(t *testing.T) {
+		options := gt.R1(parse(t).UpgraderOptions()).NoError(t)
+		gt.A(t, options).Length(0)
+	})
+
+	t.Run("upgrade pull requests are enabled", func(t *testing.T) {
+		options := gt.R1(parse(t, "--github-upgrade-pr", "--github-upgrade-pr-label", "security").UpgraderOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("invalid max open", func(t *testing.T) {
+		_, err := parse(t, "--github-upgrade-pr", "--github-upgrade-pr-max-open", "0").UpgraderOptions()
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+func TestGitHubAppsFile(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *config.GitHubApp {
+		var githubApp config.GitHubApp
+		cmd := &cli.Command{
+			Name:   "test",
+			Flags:  githubApp.Flags(),
+			Action: func(ctx context.Context, c *cli.Command) error { return nil },
+		}
+		args = append([]string{"test", "--github-app-id", "1", "--github-app-private-key", "dummy", "--github-app-secret", "secret-1"}, args...)
+		gt.NoError(t, cmd.Run(context.Background(), args))
+		return &githubApp
+	}
+	writeFile := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		gt.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("single app without file", func(t *testing.T) {
+		githubApp := parse(t)
+		app := gt.R1(githubApp.NewApps()).NoError(t)
+		_, ok := app.(*ghapp.Client)
+		gt.True(t, ok)
+
+		secrets := gt.R1(githubApp.WebhookSecrets()).NoError(t)
+		gt.V(t, secrets).Equal(map[types.GitHubAppID]types.GitHubAppSecret{1: "secret-1"})
+	})
+
+	t.Run("apps of file", func(t *testing.T) {
+		keyFile := writeFile(t, "app3.pem", "dummy-key-3")
+		appsFile := writeFile(t, "apps.yaml", `apps:
+  - id: 2
+    private_key: dummy-key-2
+    secret: secret-2
+  - id: 3
+    private_key_file: `+keyFile+`
+    secret: secret-3
+`)
+		githubApp := parse(t, "--github-apps-file", appsFile)
+		app := gt.R1(githubApp.NewApps()).NoError(t)
+		_, ok := app.(*ghapp.Apps)
+		gt.True(t, ok)
+
+		secrets := gt.R1(githubApp.WebhookSecrets()).NoError(t)
+		gt.V(t, secrets).Equal(map[types.GitHubAppID]types.GitHubAppSecret{
+			1: "secret-1",
+			2: "secret-2",
+			3: "secret-3",
+		})
+	})
+
+	testCases := map[string]string{
+		"duplicated with primary app": `apps:
+  - id: 1
+    private_key: dummy
+    secret: secret-1
+`,
+		"duplicated app": `apps:
+  - id: 2
+    private_key: dummy
+    secret: secret-2
+  - id: 2
+    private_key: dummy
+    secret: secret-2
+`,
+		"no ID": `apps:
+  - private_key: dummy
+    secret: secret-2
+`,
+		"no private key": `apps:
+  - id: 2
+    secret: secret-2
+`,
+		"both private key and file": `apps:
+  - id: 2
+    private_key: dummy
+    private_key_file: dummy.pem
+    secret: secret-2
+`,
+		"no secret": `apps:
+  - id: 2
+    private_key: dummy
+`,
+		"not YAML": `apps: [`,
+	}
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			githubApp := parse(t, "--github-apps-file", writeFile(t, "apps.yaml", content))
+			_, err := githubApp.NewApps()
+			gt.True(t, errors.Is(err, types.ErrInvalidOption))
+		})
+	}
+}
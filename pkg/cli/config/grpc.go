@@ -0,0 +1,136 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/controller/rpc"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+// GRPC configures the gRPC API served alongside the HTTP server. Clients must be authenticated by a token or a client certificate.
+type GRPC struct {
+	addr     string
+	tokens   []string `masq:"secret"`
+	certFile string
+	keyFile  string
+	clientCA string
+}
+
+func (x *GRPC) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "grpc-addr",
+			Usage:       "Binding address of the gRPC API, e.g. :9090. The gRPC API is disabled if empty",
+			Category:    "gRPC",
+			Destination: &x.addr,
+			Sources:     cli.EnvVars("OCTOVY_GRPC_ADDR"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "grpc-token",
+			Usage:       "Bearer token of the gRPC API (can be repeated)",
+			Category:    "gRPC",
+			Destination: &x.tokens,
+			Sources:     cli.EnvVars("OCTOVY_GRPC_TOKEN"),
+		},
+		&cli.StringFlag{
+			Name:        "grpc-tls-cert",
+			Usage:       "Certificate file of the gRPC server in PEM format",
+			Category:    "gRPC",
+			Destination: &x.certFile,
+			Sources:     cli.EnvVars("OCTOVY_GRPC_TLS_CERT"),
+		},
+		&cli.StringFlag{
+			Name:        "grpc-tls-key",
+			Usage:       "Private key file of the gRPC server in PEM format",
+			Category:    "gRPC",
+			Destination: &x.keyFile,
+			Sources:     cli.EnvVars("OCTOVY_GRPC_TLS_KEY"),
+		},
+		&cli.StringFlag{
+			Name:        "grpc-client-ca",
+			Usage:       "CA certificate file in PEM format to verify client certificates (mTLS)",
+			Category:    "gRPC",
+			Destination: &x.clientCA,
+			Sources:     cli.EnvVars("OCTOVY_GRPC_CLIENT_CA"),
+		},
+	}
+}
+
+// Enabled returns true if the gRPC API is configured
+func (x *GRPC) Enabled() bool {
+	return x.addr != ""
+}
+
+func (x *GRPC) Addr() string {
+	return x.addr
+}
+
+// ServerOptions returns options of the gRPC server. Certificates are loaded here so that a wrong file fails at startup. The API is not served without authentication, i.e. either --grpc-token or --grpc-client-ca is required.
+func (x *GRPC) ServerOptions() ([]rpc.Option, error) {
+	if len(x.tokens) == 0 && x.clientCA == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--grpc-token or --grpc-client-ca is required to serve the gRPC API")
+	}
+	if (x.certFile == "") != (x.keyFile == "") {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--grpc-tls-cert and --grpc-tls-key must be set together")
+	}
+	if x.clientCA != "" && x.certFile == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--grpc-tls-cert and --grpc-tls-key are required to verify client certificates")
+	}
+	for _, token := range x.tokens {
+		if token == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--grpc-token must not be empty")
+		}
+	}
+
+	var options []rpc.Option
+	if len(x.tokens) > 0 {
+		options = append(options, rpc.WithTokens(x.tokens))
+	}
+
+	if x.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(x.certFile, x.keyFile)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to load gRPC server certificate",
+				goerr.V("cert", x.certFile),
+				goerr.V("key", x.keyFile),
+			)
+		}
+		tlsConfig := &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+
+		if x.clientCA != "" {
+			raw, err := os.ReadFile(filepath.Clean(x.clientCA))
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to read gRPC client CA", goerr.V("path", x.clientCA))
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(raw) {
+				return nil, goerr.Wrap(types.ErrInvalidOption, "no certificate in gRPC client CA", goerr.V("path", x.clientCA))
+			}
+			tlsConfig.ClientCAs = pool
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+
+		options = append(options, rpc.WithTLS(tlsConfig))
+	}
+
+	return options, nil
+}
+
+func (x *GRPC) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Addr", x.addr),
+		slog.Int("Tokens.len", len(x.tokens)),
+		slog.String("TLSCert", x.certFile),
+		slog.String("TLSKey", x.keyFile),
+		slog.String("ClientCA", x.clientCA),
+	)
+}
@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func parseGRPCFlags(t *testing.T, args ...string) *config.GRPC {
+	var grpc config.GRPC
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  grpc.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &grpc
+}
+
+func TestGRPC(t *testing.T) {
+	t.Run("disabled without address", func(t *testing.T) {
+		gt.False(t, parseGRPCFlags(t, "--grpc-token", "token-1").Enabled())
+	})
+
+	t.Run("token authentication", func(t *testing.T) {
+		grpc := parseGRPCFlags(t, "--grpc-addr", ":9090", "--grpc-token", "token-1")
+		gt.True(t, grpc.Enabled())
+		options := gt.R1(grpc.ServerOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		testCases := map[string][]string{
+			"no authentication": {
+				"--grpc-addr", ":9090",
+			},
+			"certificate without key": {
+				"--grpc-addr", ":9090", "--grpc-token", "token-1", "--grpc-tls-cert", "server.pem",
+			},
+			"client CA without server certificate": {
+				"--grpc-addr", ":9090", "--grpc-client-ca", "ca.pem",
+			},
+		}
+		for name, args := range testCases {
+			t.Run(name, func(t *testing.T) {
+				_, err := parseGRPCFlags(t, args...).ServerOptions()
+				gt.Error(t, err)
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+			})
+		}
+	})
+}
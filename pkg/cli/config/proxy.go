@@ -0,0 +1,129 @@
+package config
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/controller/agent"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/infra/exploitability"
+	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
+	"github.com/m-mizutani/octovy/pkg/infra/gitclone"
+	"github.com/m-mizutani/octovy/pkg/infra/grype"
+	"github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra/upgrader"
+	"github.com/m-mizutani/octovy/pkg/infra/wasmpolicy"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Proxy configures an HTTP proxy of outbound traffic, for deployments behind an egress proxy
+type Proxy struct {
+	url string
+}
+
+func (x *Proxy) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "proxy-url",
+			Usage:       "URL of the HTTP proxy for outbound traffic, e.g. http://proxy.example.com:3128. Hosts in NO_PROXY are accessed directly",
+			Category:    "Network",
+			Destination: &x.url,
+			Sources:     cli.EnvVars("OCTOVY_PROXY_URL", "HTTPS_PROXY", "https_proxy"),
+		},
+	}
+}
+
+// Outbound is a set of options to route outbound traffic of clients through the proxy
+type Outbound struct {
+	// GitHubApp is for GitHub API requests of the app, including the archive link
+	GitHubApp []ghapp.Option
+	// Infra is for downloads of source code archives and of scan targets by URL
+	Infra []infra.Option
+	// Trivy, Grype, GitClone and Upgrader inject the proxy into environment variables of the external tools, e.g. for the DB download
+	Trivy    []trivy.Option
+	Grype    []grype.Option
+	GitClone []gitclone.Option
+	Upgrader []upgrader.Option
+	// Exploitability is for downloads of EPSS and KEV data
+	Exploitability []exploitability.Option
+	// Agent is for API calls of a scan agent to the server
+	Agent []agent.Option
+	// GatePolicy is for pulls of WASM policy plugins from OCI registries
+	GatePolicy []wasmpolicy.Option
+}
+
+// Outbound returns options to route outbound traffic through the proxy. All options are empty if no proxy is configured, so that clients keep the default behavior.
+func (x *Proxy) Outbound() (*Outbound, error) {
+	if x.url == "" {
+		return &Outbound{}, nil
+	}
+
+	// The URL is not put in errors because it may have credentials of the proxy
+	proxyURL, err := url.Parse(x.url)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid --proxy-url")
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "scheme of --proxy-url must be http, https or socks5", goerr.V("scheme", proxyURL.Scheme))
+	}
+	if proxyURL.Host == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "host of --proxy-url is required")
+	}
+
+	// NO_PROXY is still honored because internal endpoints, e.g. a GitHub Enterprise Server, may not be reachable through the proxy
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    noProxy(),
+	}).ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	httpClient := &http.Client{Transport: transport}
+
+	env := []string{
+		"HTTPS_PROXY=" + proxyURL.String(),
+		"HTTP_PROXY=" + proxyURL.String(),
+		"https_proxy=" + proxyURL.String(),
+		"http_proxy=" + proxyURL.String(),
+	}
+
+	return &Outbound{
+		GitHubApp:      []ghapp.Option{ghapp.WithTransport(transport)},
+		Infra:          []infra.Option{infra.WithHTTPClient(httpClient)},
+		Trivy:          []trivy.Option{trivy.WithEnv(env...)},
+		Grype:          []grype.Option{grype.WithEnv(env...)},
+		GitClone:       []gitclone.Option{gitclone.WithEnv(env...)},
+		Upgrader:       []upgrader.Option{upgrader.WithEnv(env...)},
+		Exploitability: []exploitability.Option{exploitability.WithHTTPClient(httpClient)},
+		Agent:          []agent.Option{agent.WithHTTPClient(httpClient)},
+		GatePolicy:     []wasmpolicy.Option{wasmpolicy.WithHTTPClient(httpClient)},
+	}, nil
+}
+
+func noProxy() string {
+	if v := os.Getenv("NO_PROXY"); v != "" {
+		return v
+	}
+	return os.Getenv("no_proxy")
+}
+
+func (x *Proxy) LogValue() slog.Value {
+	// Credentials of the proxy are not logged
+	var redacted string
+	if u, err := url.Parse(x.url); err == nil {
+		redacted = u.Redacted()
+	}
+	return slog.GroupValue(
+		slog.String("URL", redacted),
+	)
+}
@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/urfave/cli/v3"
+)
+
+func parseProxyFlags(t *testing.T, args ...string) *config.Proxy {
+	var proxy config.Proxy
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  proxy.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &proxy
+}
+
+func TestProxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+
+	t.Run("no option without proxy", func(t *testing.T) {
+		outbound := gt.R1(parseProxyFlags(t).Outbound()).NoError(t)
+		gt.V(t, outbound).Equal(&config.Outbound{})
+	})
+
+	t.Run("requests are sent to the proxy", func(t *testing.T) {
+		var requested atomic.Value
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A proxy receives the absolute URL of the destination
+			requested.Store(r.URL.String())
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		outbound := gt.R1(parseProxyFlags(t, "--proxy-url", proxy.URL).Outbound()).NoError(t)
+		gt.A(t, outbound.GitHubApp).Length(1)
+		gt.A(t, outbound.Trivy).Length(1)
+		gt.A(t, outbound.Grype).Length(1)
+		gt.A(t, outbound.GitClone).Length(1)
+		gt.A(t, outbound.Upgrader).Length(1)
+		gt.A(t, outbound.Exploitability).Length(1)
+		gt.A(t, outbound.Agent).Length(1)
+
+		req := gt.R1(http.NewRequest(http.MethodGet, "http://octovy.example/archive.zip", nil)).NoError(t)
+		resp := gt.R1(infra.New(outbound.Infra...).HTTPClient().Do(req)).NoError(t)
+		gt.NoError(t, resp.Body.Close())
+		gt.V(t, requested.Load()).Equal("http://octovy.example/archive.zip")
+	})
+
+	t.Run("HTTPS_PROXY is used as default", func(t *testing.T) {
+		t.Setenv("HTTPS_PROXY", "http://proxy.example:3128")
+		outbound := gt.R1(parseProxyFlags(t).Outbound()).NoError(t)
+		gt.A(t, outbound.Trivy).Length(1)
+	})
+
+	t.Run("hosts in NO_PROXY are accessed directly", func(t *testing.T) {
+		var proxied atomic.Bool
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied.Store(true)
+		}))
+		defer proxy.Close()
+
+		t.Setenv("NO_PROXY", "octovy.invalid")
+		outbound := gt.R1(parseProxyFlags(t, "--proxy-url", proxy.URL).Outbound()).NoError(t)
+
+		req := gt.R1(http.NewRequest(http.MethodGet, "http://octovy.invalid/", nil)).NoError(t)
+		_, err := infra.New(outbound.Infra...).HTTPClient().Do(req)
+		gt.Error(t, err)
+		gt.False(t, proxied.Load())
+	})
+
+	t.Run("invalid proxy URL", func(t *testing.T) {
+		for _, proxyURL := range []string{"ftp://proxy.example", "http://", "proxy.example:3128"} {
+			t.Run(proxyURL, func(t *testing.T) {
+				_, err := parseProxyFlags(t, "--proxy-url", proxyURL).Outbound()
+				gt.Error(t, err)
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+			})
+		}
+	})
+}
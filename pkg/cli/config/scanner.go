@@ -0,0 +1,67 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/grype"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+type Scanner struct {
+	name      string
+	grypePath string
+}
+
+func (x *Scanner) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "scanner",
+			Usage:       "Vulnerability scanner of source code [trivy|grype]",
+			Category:    "Scanner",
+			Value:       string(types.ScannerTrivy),
+			Sources:     cli.EnvVars("OCTOVY_SCANNER"),
+			Destination: &x.name,
+		},
+		&cli.StringFlag{
+			Name:        "grype-path",
+			Usage:       "Path to grype binary, used with --scanner grype",
+			Category:    "Scanner",
+			Value:       "grype",
+			Sources:     cli.EnvVars("OCTOVY_GRYPE_PATH"),
+			Destination: &x.grypePath,
+		},
+	}
+}
+
+// UsesTrivy returns true if Trivy is selected, so that the Trivy DB needs to be prepared
+func (x *Scanner) UsesTrivy() bool {
+	return x.name == "" || types.ScannerName(x.name) == types.ScannerTrivy
+}
+
+// UseCaseOptions returns an option to replace Trivy with the selected scanner. It returns no option for Trivy.
+func (x *Scanner) UseCaseOptions(options ...grype.Option) ([]usecase.Option, error) {
+	name := types.ScannerName(x.name)
+	if name == "" {
+		name = types.ScannerTrivy
+	}
+	if err := name.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid --scanner option")
+	}
+
+	switch name {
+	case types.ScannerGrype:
+		return []usecase.Option{usecase.WithScanner(grype.New(x.grypePath, options...))}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (x *Scanner) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Name", x.name),
+		slog.String("GrypePath", x.grypePath),
+	)
+}
@@ -0,0 +1,45 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func parseScannerFlags(t *testing.T, args ...string) *config.Scanner {
+	var scanner config.Scanner
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  scanner.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &scanner
+}
+
+func TestScanner(t *testing.T) {
+	t.Run("trivy is used by default", func(t *testing.T) {
+		scanner := parseScannerFlags(t)
+		gt.True(t, scanner.UsesTrivy())
+		options := gt.R1(scanner.UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(0)
+	})
+
+	t.Run("grype replaces trivy", func(t *testing.T) {
+		scanner := parseScannerFlags(t, "--scanner", "grype", "--grype-path", "/usr/local/bin/grype")
+		gt.False(t, scanner.UsesTrivy())
+		options := gt.R1(scanner.UseCaseOptions()).NoError(t)
+		gt.A(t, options).Length(1)
+	})
+
+	t.Run("unsupported scanner", func(t *testing.T) {
+		_, err := parseScannerFlags(t, "--scanner", "osv").UseCaseOptions()
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+type SLA struct {
+	policy string
+}
+
+func (x *SLA) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "sla-policy",
+			Usage:       "Time to fix active vulnerabilities per severity from their first detection, e.g. CRITICAL=7d,HIGH=30d. Severities not listed have no SLA",
+			Category:    "SLA",
+			Destination: &x.policy,
+			Sources:     cli.EnvVars("OCTOVY_SLA_POLICY"),
+		},
+	}
+}
+
+// Enabled returns true if the SLA policy is configured
+func (x *SLA) Enabled() bool {
+	return x.policy != ""
+}
+
+func (x *SLA) UseCaseOptions() ([]usecase.Option, error) {
+	if x.policy == "" {
+		return nil, nil
+	}
+	policy, err := model.ParseSLAPolicy(x.policy)
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid --sla-policy option")
+	}
+	return []usecase.Option{usecase.WithSLAPolicy(policy)}, nil
+}
+
+func (x *SLA) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Policy", x.policy),
+	)
+}
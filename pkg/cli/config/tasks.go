@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/oidc"
+	"github.com/urfave/cli/v3"
+)
+
+// GoogleIssuer is the issuer of OIDC tokens which Cloud Scheduler and Pub/Sub push subscriptions attach to requests
+const GoogleIssuer = "https://accounts.google.com"
+
+// Tasks configures task endpoints under /tasks, called by Cloud Scheduler or Pub/Sub push subscriptions of serverless deployments
+type Tasks struct {
+	oidcIssuer      string
+	oidcAudience    string
+	serviceAccounts []string
+}
+
+func (x *Tasks) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "task-oidc-audience",
+			Usage:       "Audience which OIDC tokens of task requests must have, e.g. the URL of the endpoint. Task endpoints are disabled if empty",
+			Category:    "Tasks",
+			Destination: &x.oidcAudience,
+			Sources:     cli.EnvVars("OCTOVY_TASK_OIDC_AUDIENCE"),
+		},
+		&cli.StringFlag{
+			Name:        "task-oidc-issuer",
+			Usage:       "Issuer of OIDC tokens of task requests",
+			Category:    "Tasks",
+			Value:       GoogleIssuer,
+			Destination: &x.oidcIssuer,
+			Sources:     cli.EnvVars("OCTOVY_TASK_OIDC_ISSUER"),
+		},
+		&cli.StringSliceFlag{
+			Name:        "task-service-account",
+			Usage:       "Email or subject of the service account allowed to call task endpoints (can be repeated)",
+			Category:    "Tasks",
+			Destination: &x.serviceAccounts,
+			Sources:     cli.EnvVars("OCTOVY_TASK_SERVICE_ACCOUNT"),
+		},
+	}
+}
+
+// Enabled returns true if task endpoints are configured
+func (x *Tasks) Enabled() bool {
+	return x.oidcAudience != ""
+}
+
+// ServerOptions returns a server option to enable task endpoints. OIDC discovery of the issuer is done here, so that a wrong issuer fails at startup. Anyone can get a Google ID token with any audience, so that allowed service accounts are required.
+func (x *Tasks) ServerOptions(ctx context.Context) ([]server.Option, error) {
+	if !x.Enabled() {
+		if len(x.serviceAccounts) > 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--task-oidc-audience is required to enable task endpoints")
+		}
+		return nil, nil
+	}
+	if len(x.serviceAccounts) == 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--task-service-account is required to enable task endpoints")
+	}
+
+	verifier, err := oidc.New(ctx, x.oidcIssuer, x.oidcAudience)
+	if err != nil {
+		return nil, err
+	}
+	return []server.Option{server.WithTasks(verifier, x.serviceAccounts)}, nil
+}
+
+func (x *Tasks) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("OIDCIssuer", x.oidcIssuer),
+		slog.String("OIDCAudience", x.oidcAudience),
+		slog.Any("ServiceAccounts", x.serviceAccounts),
+	)
+}
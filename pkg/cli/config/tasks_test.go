@@ -0,0 +1,45 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+)
+
+func parseTasksFlags(t *testing.T, args ...string) *config.Tasks {
+	var tasks config.Tasks
+	cmd := &cli.Command{
+		Name:   "test",
+		Flags:  tasks.Flags(),
+		Action: func(ctx context.Context, c *cli.Command) error { return nil },
+	}
+	gt.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+	return &tasks
+}
+
+func TestTasks(t *testing.T) {
+	t.Run("disabled without audience", func(t *testing.T) {
+		tasks := parseTasksFlags(t)
+		gt.False(t, tasks.Enabled())
+		options := gt.R1(tasks.ServerOptions(context.Background())).NoError(t)
+		gt.A(t, options).Length(0)
+	})
+
+	t.Run("service account without audience", func(t *testing.T) {
+		tasks := parseTasksFlags(t, "--task-service-account", "scheduler@example.iam.gserviceaccount.com")
+		_, err := tasks.ServerOptions(context.Background())
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("audience without service account", func(t *testing.T) {
+		tasks := parseTasksFlags(t, "--task-oidc-audience", "https://octovy.example.com/tasks/scan-owner")
+		gt.True(t, tasks.Enabled())
+		_, err := tasks.ServerOptions(context.Background())
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
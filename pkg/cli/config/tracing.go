@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	traceExporterNone     = "none"
+	traceExporterOTLPGRPC = "otlp-grpc"
+	traceExporterOTLPHTTP = "otlp-http"
+)
+
+// Tracing configures the exporter of OpenTelemetry spans of the scan pipeline
+type Tracing struct {
+	exporter    string
+	endpoint    string
+	insecure    bool
+	sampleRatio float64
+}
+
+func (x *Tracing) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "trace-exporter",
+			Usage:       "Exporter of OpenTelemetry spans [none|otlp-grpc|otlp-http]",
+			Category:    "Tracing",
+			Value:       traceExporterNone,
+			Destination: &x.exporter,
+			Sources:     cli.EnvVars("OCTOVY_TRACE_EXPORTER"),
+		},
+		&cli.StringFlag{
+			Name:        "trace-endpoint",
+			Usage:       "OTLP endpoint as host:port. OTEL_EXPORTER_OTLP_ENDPOINT or the default of the exporter (localhost:4317 for gRPC, localhost:4318 for HTTP) is used if not set",
+			Category:    "Tracing",
+			Destination: &x.endpoint,
+			Sources:     cli.EnvVars("OCTOVY_TRACE_ENDPOINT"),
+		},
+		&cli.BoolFlag{
+			Name:        "trace-insecure",
+			Usage:       "Send spans to the OTLP endpoint without TLS, e.g. to a collector sidecar",
+			Category:    "Tracing",
+			Destination: &x.insecure,
+			Sources:     cli.EnvVars("OCTOVY_TRACE_INSECURE"),
+		},
+		&cli.FloatFlag{
+			Name:        "trace-sample-ratio",
+			Usage:       "Ratio of traces to record, from 0 to 1. A trace started by a caller with a sampled traceparent header is always recorded",
+			Category:    "Tracing",
+			Value:       1,
+			Destination: &x.sampleRatio,
+			Sources:     cli.EnvVars("OCTOVY_TRACE_SAMPLE_RATIO"),
+		},
+	}
+}
+
+// Configure sets the tracer provider exporting spans by the exporter. It returns a function to flush and stop exporting, which should be called on exit.
+func (x *Tracing) Configure(ctx context.Context) (func(context.Context) error, error) {
+	if x.sampleRatio < 0 || x.sampleRatio > 1 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--trace-sample-ratio must be from 0 to 1", goerr.V("trace-sample-ratio", x.sampleRatio))
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch x.exporter {
+	case traceExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+
+	case traceExporterOTLPGRPC:
+		var options []otlptracegrpc.Option
+		if x.endpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(x.endpoint))
+		}
+		if x.insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+
+	case traceExporterOTLPHTTP:
+		var options []otlptracehttp.Option
+		if x.endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(x.endpoint))
+		}
+		if x.insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid --trace-exporter", goerr.V("trace-exporter", x.exporter))
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create trace exporter", goerr.V("exporter", x.exporter))
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "octovy")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create trace resource")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(x.sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	logging.From(ctx).Info("tracing is configured",
+		slog.String("exporter", x.exporter),
+		slog.String("endpoint", x.endpoint),
+		slog.Float64("sample_ratio", x.sampleRatio),
+	)
+	return provider.Shutdown, nil
+}
+
+func (x *Tracing) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Exporter", x.exporter),
+		slog.String("Endpoint", x.endpoint),
+		slog.Bool("Insecure", x.insecure),
+		slog.Float64("SampleRatio", x.sampleRatio),
+	)
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+type Trivy struct {
+	path              string
+	scanners          []string
+	cacheDir          string
+	args              trivyArgs
+	minVersion        string
+	enforceMinVersion bool
+	registry          trivyRegistry
+}
+
+// trivyArgs is a repeatable flag value. Each value is split by white spaces, not by comma unlike slice flags, so that a value such as "--severity HIGH,CRITICAL" is kept.
+type trivyArgs []string
+
+func (x *trivyArgs) Set(value string) error {
+	*x = append(*x, strings.Fields(value)...)
+	return nil
+}
+
+func (x *trivyArgs) String() string {
+	return strings.Join(*x, " ")
+}
+
+func (x *trivyArgs) Get() any {
+	return []string(*x)
+}
+
+// reservedTrivyArgs are options set by octovy to read the result of Trivy, then they can not be overridden by --trivy-arg
+var reservedTrivyArgs = map[string]bool{
+	"-f":          true,
+	"--format":    true,
+	"-o":          true,
+	"--output":    true,
+	"--exit-code": true,
+	"--template":  true,
+	"-t":          true,
+}
+
+func (x *Trivy) Flags() []cli.Flag {
+	return append([]cli.Flag{
+		&cli.StringFlag{
+			Name:        "trivy-path",
+			Usage:       "Path to trivy binary",
+			Category:    "Trivy",
+			Value:       "trivy",
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_PATH"),
+			Destination: &x.path,
+		},
+		&cli.StringSliceFlag{
+			Name:        "trivy-scanners",
+			Usage:       "Trivy scanners to enable [vuln|secret|misconfig|license] (default: Trivy's default scanners)",
+			Category:    "Trivy",
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_SCANNERS"),
+			Destination: &x.scanners,
+		},
+		&cli.StringFlag{
+			Name:        "trivy-cache-dir",
+			Usage:       "Cache directory of Trivy for the vulnerability DB (default: Trivy's default cache directory)",
+			Category:    "Trivy",
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_CACHE_DIR"),
+			Destination: &x.cacheDir,
+		},
+		&cli.GenericFlag{
+			Name:     "trivy-arg",
+			Usage:    "Extra argument of Trivy, e.g. --ignore-unfixed or \"--severity HIGH,CRITICAL\". Repeatable, and a value is split by white spaces",
+			Category: "Trivy",
+			Sources:  cli.EnvVars("OCTOVY_TRIVY_ARGS"),
+			Value:    &x.args,
+		},
+		&cli.StringFlag{
+			Name:        "trivy-min-version",
+			Usage:       "Minimum version of Trivy. An older Trivy is warned because its report may not be read correctly. Empty disables the check",
+			Category:    "Trivy",
+			Value:       trivy.DefaultMinVersion,
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_MIN_VERSION"),
+			Destination: &x.minVersion,
+		},
+		&cli.BoolFlag{
+			Name:        "trivy-enforce-min-version",
+			Usage:       "Refuse to scan with Trivy older than --trivy-min-version instead of warning",
+			Category:    "Trivy",
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_ENFORCE_MIN_VERSION"),
+			Destination: &x.enforceMinVersion,
+		},
+	}, x.registry.Flags()...)
+}
+
+func (x *Trivy) NewClient(options ...trivy.Option) trivy.Client {
+	if x.cacheDir != "" {
+		options = append(options, trivy.WithCacheDir(x.cacheDir))
+	}
+	if env := x.registry.Env(); len(env) > 0 {
+		options = append(options, trivy.WithEnv(env...))
+	}
+	options = append(options, trivy.WithMinVersion(x.minVersion, x.enforceMinVersion))
+	return trivy.New(x.path, options...)
+}
+
+// Scanners returns validated scanners. Each flag value may also be a comma separated list.
+func (x *Trivy) Scanners() ([]types.TrivyScanner, error) {
+	var scanners []types.TrivyScanner
+	for _, v := range x.scanners {
+		for _, s := range strings.Split(v, ",") {
+			scanner := types.TrivyScanner(strings.TrimSpace(s))
+			if scanner == "" {
+				continue
+			}
+			if err := scanner.Validate(); err != nil {
+				return nil, goerr.Wrap(err, "invalid --trivy-scanners option")
+			}
+			scanners = append(scanners, scanner)
+		}
+	}
+	return scanners, nil
+}
+
+// ScansMisconfigurations returns true if the misconfig scanner is enabled. Trivy does not scan misconfigurations by default.
+func (x *Trivy) ScansMisconfigurations() bool {
+	scanners, err := x.Scanners()
+	return err == nil && slices.Contains(scanners, types.TrivyScannerMisconf)
+}
+
+// Args returns extra arguments of Trivy. Options which octovy sets to read the result, e.g. --format, are rejected.
+func (x *Trivy) Args() ([]string, error) {
+	for _, arg := range x.args {
+		name, _, _ := strings.Cut(arg, "=")
+		if reservedTrivyArgs[name] {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "--trivy-arg can not override the option set by octovy", goerr.V("arg", arg))
+		}
+	}
+	return x.args, nil
+}
+
+// UseCaseOptions returns options of usecase to run Trivy with the scanners and extra arguments
+func (x *Trivy) UseCaseOptions() ([]usecase.Option, error) {
+	scanners, err := x.Scanners()
+	if err != nil {
+		return nil, err
+	}
+	args, err := x.Args()
+	if err != nil {
+		return nil, err
+	}
+	return []usecase.Option{
+		usecase.WithTrivyScanners(scanners...),
+		usecase.WithTrivyArgs(args...),
+	}, nil
+}
+
+func (x *Trivy) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Path", x.path),
+		slog.Any("Scanners", x.scanners),
+		slog.String("CacheDir", x.cacheDir),
+		slog.Any("Args", []string(x.args)),
+		slog.String("MinVersion", x.minVersion),
+		slog.Bool("EnforceMinVersion", x.enforceMinVersion),
+		slog.Any("Registry", &x.registry),
+	)
+}
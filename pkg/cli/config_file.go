@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Manage the config file of --config",
+		Commands: []*cli.Command{
+			{
+				Name:  "validate",
+				Usage: "Check that all options of the config file are options of the commands and have valid values",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					path := c.String("config")
+					if path == "" {
+						return goerr.Wrap(types.ErrInvalidOption, "--config or OCTOVY_CONFIG is required")
+					}
+
+					file, err := loadConfigFile(path)
+					if err != nil {
+						return err
+					}
+					if err := file.validate([]*cli.Command{c.Root()}, ""); err != nil {
+						return goerr.Wrap(err, "invalid config file", goerr.V("path", path))
+					}
+
+					fmt.Fprintf(os.Stdout, "%s is valid\n", path)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// configSection is a section of the config file of --config. Values are options by flag names without leading dashes, and sections are options of subcommands by command names. Options of a section take precedence over the same options of outer sections.
+type configSection struct {
+	values   map[string][]string
+	sections map[string]*configSection
+}
+
+// loadConfigFile reads the config file and expands environment variables in values, e.g. ${GITHUB_APP_SECRET}. An undefined variable is an error so that a missing secret is not given as an empty value. "$$" is a literal "$".
+func loadConfigFile(path string) (*configSection, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read config file", goerr.V("path", path))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse config file", goerr.V("path", path))
+	}
+	if len(doc.Content) == 0 {
+		return &configSection{}, nil
+	}
+
+	section, err := parseConfigSection(doc.Content[0], "")
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid config file", goerr.V("path", path))
+	}
+	return section, nil
+}
+
+func parseConfigSection(node *yaml.Node, prefix string) (*configSection, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "config section must be a mapping", goerr.V("section", strings.TrimSuffix(prefix, ".")), goerr.V("line", node.Line))
+	}
+
+	section := &configSection{
+		values:   make(map[string][]string),
+		sections: make(map[string]*configSection),
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i].Value, node.Content[i+1]
+		key := prefix + name
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			sub, err := parseConfigSection(value, key+".")
+			if err != nil {
+				return nil, err
+			}
+			section.sections[name] = sub
+
+		case yaml.SequenceNode:
+			values := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, goerr.Wrap(types.ErrInvalidOption, "list option must have scalar values", goerr.V("key", key), goerr.V("line", item.Line))
+				}
+				expanded, err := expandConfigValue(item.Value)
+				if err != nil {
+					return nil, goerr.Wrap(err, "failed to expand option", goerr.V("key", key))
+				}
+				values = append(values, expanded)
+			}
+			section.values[name] = values
+
+		case yaml.ScalarNode:
+			if value.Tag == "!!null" {
+				continue
+			}
+			expanded, err := expandConfigValue(value.Value)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to expand option", goerr.V("key", key))
+			}
+			section.values[name] = []string{expanded}
+
+		default:
+			return nil, goerr.Wrap(types.ErrInvalidOption, "unsupported option value", goerr.V("key", key), goerr.V("line", value.Line))
+		}
+	}
+	return section, nil
+}
+
+func expandConfigValue(value string) (string, error) {
+	var missing []string
+	expanded := os.Expand(value, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", goerr.Wrap(types.ErrInvalidOption, "undefined environment variable in config file", goerr.V("variables", missing))
+	}
+	return expanded, nil
+}
+
+// apply sets options of the invoked command, given as the lineage from the root command, which are not set by flags or environment variables. Options of other commands are ignored because a config file is shared by commands.
+func (x *configSection) apply(lineage []*cli.Command) error {
+	values := make(map[string][]string)
+	section := x
+	for i, cmd := range lineage {
+		if i > 0 {
+			if section = section.sections[cmd.Name]; section == nil {
+				break
+			}
+		}
+		maps.Copy(values, section.values)
+	}
+
+	leaf := lineage[len(lineage)-1]
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		flag := lookupConfigFlag(lineage, name)
+		if flag == nil || flag.IsSet() {
+			continue
+		}
+		for _, value := range values[name] {
+			// The value is not logged because it may be a secret
+			if err := leaf.Set(name, value); err != nil {
+				return goerr.Wrap(err, "invalid option in config file", goerr.V("key", name))
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks options of the section of the last command of the lineage. An option must be a flag of the command, its ancestors or its subcommands, and a section must be named after a subcommand.
+func (x *configSection) validate(lineage []*cli.Command, prefix string) error {
+	cmd := lineage[len(lineage)-1]
+
+	for _, name := range slices.Sorted(maps.Keys(x.values)) {
+		var flags []cli.Flag
+		if flag := lookupConfigFlag(lineage, name); flag != nil {
+			flags = append(flags, flag)
+		}
+		flags = append(flags, subcommandFlags(cmd, name)...)
+		if len(flags) == 0 {
+			return goerr.Wrap(types.ErrInvalidOption, "unknown option in config file", goerr.V("key", prefix+name))
+		}
+
+		for _, flag := range flags {
+			for _, value := range x.values[name] {
+				if err := flag.Set(name, value); err != nil {
+					return goerr.Wrap(err, "invalid option in config file", goerr.V("key", prefix+name))
+				}
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(x.sections)) {
+		sub := subcommandOf(cmd, name)
+		if sub == nil {
+			return goerr.Wrap(types.ErrInvalidOption, "unknown command section in config file", goerr.V("key", prefix+name))
+		}
+		if err := x.sections[name].validate(append(slices.Clone(lineage), sub), prefix+name+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupConfigFlag returns the flag of the name of the last command of the lineage or its ancestors
+func lookupConfigFlag(lineage []*cli.Command, name string) cli.Flag {
+	for i := len(lineage) - 1; i >= 0; i-- {
+		for _, flag := range lineage[i].Flags {
+			if slices.Contains(flag.Names(), name) {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// subcommandFlags returns flags of the name of all subcommands of cmd recursively
+func subcommandFlags(cmd *cli.Command, name string) []cli.Flag {
+	var flags []cli.Flag
+	for _, sub := range cmd.Commands {
+		for _, flag := range sub.Flags {
+			if slices.Contains(flag.Names(), name) {
+				flags = append(flags, flag)
+			}
+		}
+		flags = append(flags, subcommandFlags(sub, name)...)
+	}
+	return flags
+}
+
+func subcommandOf(cmd *cli.Command, name string) *cli.Command {
+	for _, sub := range cmd.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
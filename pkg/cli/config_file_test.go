@@ -0,0 +1,114 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "octovy.yaml")
+	gt.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	summaryPath := filepath.Join(dir, "summary.json")
+	t.Setenv("TEST_OCTOVY_LOG_OUTPUT", filepath.Join(dir, "octovy.log"))
+
+	configPath := writeConfigFile(t, `
+quiet: true
+log-output: ${TEST_OCTOVY_LOG_OUTPUT}
+summary-json: `+summaryPath+`
+report:
+  file: testdata/trivy-result.json
+  format: json
+`)
+
+	t.Run("options of the command are read from the file", func(t *testing.T) {
+		gt.NoError(t, cli.New().Run([]string{"octovy", "--config", configPath, "report"}))
+
+		var summary map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(os.ReadFile(summaryPath)).NoError(t), &summary))
+		gt.V(t, summary["command"]).Equal("octovy report")
+		gt.V(t, summary["status"]).Equal("success")
+	})
+
+	t.Run("flag takes precedence over the file", func(t *testing.T) {
+		gt.Error(t, cli.New().Run([]string{"octovy", "--config", configPath, "report", "--file", "testdata/not-found.json"}))
+	})
+
+	t.Run("environment variable takes precedence over the file", func(t *testing.T) {
+		t.Setenv("OCTOVY_CONFIG", configPath)
+		envSummaryPath := filepath.Join(dir, "env-summary.json")
+		t.Setenv("OCTOVY_SUMMARY_JSON", envSummaryPath)
+
+		gt.NoError(t, cli.New().Run([]string{"octovy", "report"}))
+		gt.R1(os.Stat(envSummaryPath)).NoError(t)
+	})
+
+	t.Run("undefined environment variable is an error", func(t *testing.T) {
+		path := writeConfigFile(t, "log-output: ${TEST_OCTOVY_UNDEFINED}\n")
+		gt.Error(t, cli.New().Run([]string{"octovy", "--config", path, "report", "--file", "testdata/trivy-result.json"}))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	testCases := map[string]struct {
+		content string
+		wantErr bool
+	}{
+		"valid": {
+			content: `
+log-format: json
+bigquery-project-id: my-project
+github-app-id: 12345
+serve:
+  addr: 0.0.0.0:8080
+  scan-workers: 4
+scan:
+  remote:
+    github-owner: myorg
+`,
+		},
+		"option of a subcommand at the top level": {
+			content: "github-owner: myorg\n",
+		},
+		"list option": {
+			content: "serve:\n  scan-owner: [myorg, other-org]\n",
+		},
+		"unknown option": {
+			content: "bigquery-project: my-project\n",
+			wantErr: true,
+		},
+		"option of another command": {
+			content: "report:\n  addr: 0.0.0.0:8080\n",
+			wantErr: true,
+		},
+		"unknown command section": {
+			content: "server:\n  addr: 0.0.0.0:8080\n",
+			wantErr: true,
+		},
+		"invalid value": {
+			content: "serve:\n  scan-workers: many\n",
+			wantErr: true,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			path := writeConfigFile(t, tc.content)
+			err := cli.New().Run([]string{"octovy", "--log-output", filepath.Join(t.TempDir(), "octovy.log"), "config", "validate", "--config", path})
+			if tc.wantErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
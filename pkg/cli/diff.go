@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+func diffCommand() *cli.Command {
+	var (
+		bigQuery  config.BigQuery
+		firestore config.Firestore
+		input     model.DiffScansInput
+		format    string
+	)
+
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "Compare vulnerabilities of two scans stored in BigQuery and print added, removed and unchanged ones per target",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "base",
+				Usage:       "Scan ID or commit SHA of the base scan",
+				Destination: &input.Base,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "head",
+				Usage:       "Scan ID or commit SHA of the head scan",
+				Destination: &input.Head,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required to select a scan by commit SHA)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (required to select a scan by commit SHA)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format [table|markdown|json]",
+				Sources:     cli.EnvVars("OCTOVY_DIFF_FORMAT"),
+				Destination: &format,
+				Value:       reportFormatTable,
+			},
+		}, bigQuery.Flags(), firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			switch format {
+			case reportFormatTable, reportFormatMarkdown, reportFormatJSON:
+			default:
+				return goerr.Wrap(types.ErrInvalidOption, "invalid --format", goerr.V("format", format))
+			}
+
+			bqClient, err := bigQuery.NewClient(ctx)
+			if err != nil {
+				return err
+			}
+			if err := requireBigQuery(bqClient); err != nil {
+				return err
+			}
+			clientOpts := []infra.Option{infra.WithBigQuery(bqClient)}
+
+			// Firestore is optional and used only to find the scan of a commit
+			if firestore.Enabled() {
+				repo, err := firestore.NewRepository(ctx)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create Firestore repository")
+				}
+				clientOpts = append(clientOpts, infra.WithScanRepository(repo))
+			}
+
+			diff, err := usecase.New(infra.New(clientOpts...)).DiffScans(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to compare scans")
+			}
+
+			return renderScanDiff(os.Stdout, format, diff)
+		},
+	}
+}
+
+func renderScanDiff(w io.Writer, format string, diff *model.ScanDiff) error {
+	switch format {
+	case reportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return goerr.Wrap(err, "failed to write diff")
+		}
+		return nil
+
+	case reportFormatMarkdown:
+		return renderScanDiffMarkdown(w, diff)
+
+	default:
+		return renderScanDiffTable(w, diff)
+	}
+}
+
+func diffRefLine(ref *model.ScanDiffRef) string {
+	return fmt.Sprintf("%s/%s@%s (branch: %s, scan: %s)", ref.Owner, ref.Repo, ref.CommitID, ref.Branch, ref.ScanID)
+}
+
+func diffSummaryLine(diff *model.ScanDiff) string {
+	added, removed, unchanged := diff.Counts()
+	return fmt.Sprintf("Added: %d, Removed: %d, Unchanged: %d", added, removed, unchanged)
+}
+
+// diffRows returns changes of all targets as rows of [target, change, vulnerability...]. Unchanged vulnerabilities are included only if withUnchanged is true.
+func diffRows(diff *model.ScanDiff, withUnchanged bool) [][]string {
+	var rows [][]string
+	add := func(target, change string, vulns []*model.DiffVulnerability) {
+		for _, v := range vulns {
+			rows = append(rows, []string{target, change, v.VulnID, v.Severity, v.PkgName, v.InstalledVersion, v.FixedVersion, v.Title})
+		}
+	}
+	for _, t := range diff.Targets {
+		add(t.Target, "added", t.Added)
+		add(t.Target, "removed", t.Removed)
+		if withUnchanged {
+			add(t.Target, "unchanged", t.Unchanged)
+		}
+	}
+	return rows
+}
+
+func renderScanDiffTable(w io.Writer, diff *model.ScanDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Base: "+diffRefLine(diff.Base))
+	fmt.Fprintln(tw, "Head: "+diffRefLine(diff.Head))
+	fmt.Fprintln(tw)
+
+	if rows := diffRows(diff, true); len(rows) > 0 {
+		fmt.Fprintln(tw, "TARGET\tCHANGE\tID\tSEVERITY\tPACKAGE\tINSTALLED\tFIXED\tTITLE")
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintln(tw, diffSummaryLine(diff))
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write diff")
+	}
+	return nil
+}
+
+// renderScanDiffMarkdown lists only added and removed vulnerabilities to keep CI annotations and pull request comments short
+func renderScanDiffMarkdown(w io.Writer, diff *model.ScanDiff) error {
+	var b strings.Builder
+	b.WriteString("## Octovy Scan Diff\n\n")
+	b.WriteString("- Base: " + escapeMarkdownCell(diffRefLine(diff.Base)) + "\n")
+	b.WriteString("- Head: " + escapeMarkdownCell(diffRefLine(diff.Head)) + "\n\n")
+	b.WriteString(diffSummaryLine(diff) + "\n")
+
+	if rows := diffRows(diff, false); len(rows) > 0 {
+		b.WriteString("\n| Target | Change | ID | Severity | Package | Installed | Fixed | Title |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, row := range rows {
+			for i := range row {
+				row[i] = escapeMarkdownCell(row[i])
+			}
+			b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return goerr.Wrap(err, "failed to write diff")
+	}
+	return nil
+}
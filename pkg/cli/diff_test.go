@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestRenderScanDiff(t *testing.T) {
+	diff := &model.ScanDiff{
+		Base: &model.ScanDiffRef{ScanID: "scan-base", Owner: "myorg", Repo: "myrepo", Branch: "main", CommitID: "1111111"},
+		Head: &model.ScanDiffRef{ScanID: "scan-head", Owner: "myorg", Repo: "myrepo", Branch: "feature", CommitID: "2222222"},
+		Targets: []*model.TargetDiff{
+			{
+				Target:    "go.mod",
+				Added:     []*model.DiffVulnerability{{VulnID: "CVE-2024-0003", PkgName: "golang.org/x/net", InstalledVersion: "v0.15.0", FixedVersion: "v0.17.0", Severity: "HIGH", Title: "a | b"}},
+				Removed:   []*model.DiffVulnerability{{VulnID: "CVE-2024-0002", PkgName: "golang.org/x/text", Severity: "LOW"}},
+				Unchanged: []*model.DiffVulnerability{{VulnID: "CVE-2024-0001", PkgName: "golang.org/x/net", Severity: "MEDIUM"}},
+			},
+		},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderScanDiffForTest(&buf, "table", diff))
+		gt.S(t, buf.String()).Contains("Head: myorg/myrepo@2222222 (branch: feature, scan: scan-head)")
+		gt.S(t, buf.String()).Contains("CVE-2024-0001")
+		gt.S(t, buf.String()).Contains("Added: 1, Removed: 1, Unchanged: 1")
+	})
+
+	t.Run("markdown lists only changes", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderScanDiffForTest(&buf, "markdown", diff))
+		gt.S(t, buf.String()).Contains("| go.mod | added | CVE-2024-0003 | HIGH | golang.org/x/net | v0.15.0 | v0.17.0 | a \\| b |")
+		gt.S(t, buf.String()).Contains("| go.mod | removed | CVE-2024-0002 |")
+		gt.S(t, buf.String()).NotContains("CVE-2024-0001")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderScanDiffForTest(&buf, "json", diff))
+		var out model.ScanDiff
+		gt.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		gt.V(t, out.Head.ScanID).Equal(diff.Head.ScanID)
+		gt.A(t, out.Targets[0].Added).Length(1)
+	})
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+	"github.com/m-mizutani/octovy/pkg/utils/xlsx"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatXLSX = "xlsx"
+
+	exportSheetName = "Findings"
+)
+
+// exportTitleColumn is the index of the title in model.ExportColumns
+var exportTitleColumn = slices.Index(model.ExportColumns, "Title")
+
+func exportCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.ExportFindingsInput
+		branch    string
+		since     string
+		severity  string
+		format    string
+		output    string
+		baseURL   string
+	)
+
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export findings stored in Firestore to a CSV or Excel spreadsheet for compliance reporting",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Aliases:     []string{"owner"},
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (default: all repositories of the owner)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.StringFlag{
+				Name:        "branch",
+				Usage:       "Branch name (default: all branches of each repository)",
+				Destination: &branch,
+			},
+			&cli.StringFlag{
+				Name:        "since",
+				Usage:       "Export findings open at any time since the UTC day in YYYY-MM-DD, dropping findings fixed before it (default: all findings)",
+				Destination: &since,
+			},
+			&cli.StringFlag{
+				Name:        "severity",
+				Usage:       "Export findings at or above the severity [LOW|MEDIUM|HIGH|CRITICAL]",
+				Destination: &severity,
+			},
+			&cli.StringFlag{
+				Name:        "tag",
+				Usage:       "Export findings of repositories with the tag",
+				Destination: &input.Tag,
+			},
+			&cli.StringFlag{
+				Name:        "team",
+				Usage:       "Export findings of repositories owned by the team",
+				Destination: &input.Team,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format [csv|xlsx] (default: by the extension of --output, or csv)",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "File to write. Standard output if not set, which is available only for csv",
+				Destination: &output,
+			},
+			&cli.StringFlag{
+				Name:        "base-url",
+				Usage:       "Public URL of the octovy server to link findings to their permalinks",
+				Sources:     cli.EnvVars("OCTOVY_BASE_URL"),
+				Destination: &baseURL,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			format, err := exportFormat(format, output)
+			if err != nil {
+				return err
+			}
+
+			input.Branch = types.NewBranchName(branch)
+			if since != "" {
+				if input.Since, err = time.Parse(time.DateOnly, since); err != nil {
+					return goerr.Wrap(types.ErrInvalidOption, "invalid --since, YYYY-MM-DD is expected", goerr.V("since", since))
+				}
+			}
+			if severity != "" {
+				if input.MinSeverity, err = types.ParseSeverity(severity); err != nil {
+					return err
+				}
+			}
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)), usecase.WithPermalinkBaseURL(baseURL))
+			findings, err := uc.ExportFindings(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to export findings")
+			}
+
+			if output == "" {
+				return writeExport(os.Stdout, format, findings)
+			}
+			return writeExportFile(output, format, findings)
+		},
+	}
+}
+
+// exportFormat returns the format of the flag, or of the extension of the output file if the flag is not set. xlsx is not written to standard output because it is binary.
+func exportFormat(format, output string) (string, error) {
+	if format == "" {
+		format = exportFormatCSV
+		if strings.EqualFold(filepath.Ext(output), ".xlsx") {
+			format = exportFormatXLSX
+		}
+	}
+
+	switch format {
+	case exportFormatCSV:
+	case exportFormatXLSX:
+		if output == "" {
+			return "", goerr.Wrap(types.ErrInvalidOption, "--output is required with --format xlsx")
+		}
+	default:
+		return "", goerr.Wrap(types.ErrInvalidOption, "invalid --format", goerr.V("format", format))
+	}
+	return format, nil
+}
+
+// writeExportFile writes findings into the file. The file is removed on failure so that an incomplete spreadsheet is not left.
+func writeExportFile(path, format string, findings []*model.ExportedFinding) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return goerr.Wrap(err, "failed to create export file", goerr.V("path", path))
+	}
+
+	if err := writeExport(f, format, findings); err != nil {
+		safe.Close(f)
+		safe.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		safe.Remove(path)
+		return goerr.Wrap(err, "failed to close export file", goerr.V("path", path))
+	}
+	return nil
+}
+
+func writeExport(w io.Writer, format string, findings []*model.ExportedFinding) error {
+	rows := make([][]string, len(findings))
+	for i, f := range findings {
+		rows[i] = f.Row()
+	}
+
+	if format == exportFormatXLSX {
+		if err := xlsx.Write(w, exportSheetName, model.ExportColumns, rows); err != nil {
+			return goerr.Wrap(err, "failed to write xlsx")
+		}
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(model.ExportColumns); err != nil {
+		return goerr.Wrap(err, "failed to write csv")
+	}
+	for i, row := range rows {
+		row[exportTitleColumn] = escapeCSVFormula(findings[i].Title)
+		if err := cw.Write(row); err != nil {
+			return goerr.Wrap(err, "failed to write csv")
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return goerr.Wrap(err, "failed to write csv")
+	}
+	return nil
+}
+
+// escapeCSVFormula prefixes a title which a spreadsheet would evaluate as a formula with a single quote, because titles come from external advisories. Other columns are kept as is, e.g. an npm package name starting with "@".
+func escapeCSVFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package cli_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestWriteExport(t *testing.T) {
+	findings := []*model.ExportedFinding{
+		{
+			Repository:    "myorg/myrepo",
+			Branch:        "main",
+			DefaultBranch: true,
+			Target:        "package-lock.json",
+			Type:          types.FindingTypeVulnerability,
+			ID:            "CVE-2024-0001",
+			Severity:      "HIGH",
+			Status:        types.VulnStatusFixed,
+			PkgName:       "@babel/core",
+			Title:         "=cmd|' /C calc'!A0",
+			DetectedAt:    time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			FixedAt:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.WriteExportForTest(&buf, "csv", findings))
+
+		records := gt.R1(csv.NewReader(&buf).ReadAll()).NoError(t)
+		gt.A(t, records).Length(2)
+		gt.V(t, records[0]).Equal(model.ExportColumns)
+		gt.V(t, records[1][0]).Equal("myorg/myrepo")
+		gt.V(t, records[1][2]).Equal("true")
+		gt.V(t, records[1][9]).Equal("@babel/core")
+		gt.V(t, records[1][12]).Equal("'=cmd|' /C calc'!A0")
+		gt.V(t, records[1][13]).Equal("2024-01-10T00:00:00Z")
+		gt.V(t, records[1][14]).Equal("2024-02-01T00:00:00Z")
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.WriteExportForTest(&buf, "xlsx", findings))
+		zr := gt.R1(zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))).NoError(t)
+		gt.A(t, zr.File).Longer(0)
+	})
+}
+
+func TestExportFormat(t *testing.T) {
+	gt.V(t, gt.R1(cli.ExportFormatForTest("", "")).NoError(t)).Equal("csv")
+	gt.V(t, gt.R1(cli.ExportFormatForTest("", "report.XLSX")).NoError(t)).Equal("xlsx")
+	gt.V(t, gt.R1(cli.ExportFormatForTest("csv", "report.xlsx")).NoError(t)).Equal("csv")
+
+	_, err := cli.ExportFormatForTest("xlsx", "")
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	_, err = cli.ExportFormatForTest("pdf", "report.pdf")
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+}
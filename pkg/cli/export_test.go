@@ -1,4 +1,18 @@
 package cli
 
 // Export functions for testing
-var AutoDetectGitMetadataForTest = AutoDetectGitMetadata
\ No newline at end of file
+var AutoDetectGitMetadataForTest = AutoDetectGitMetadata
+var PrintScanReportForTest = printScanReport
+var ParseRetentionForTest = parseRetention
+var ParseFailOnSeverityForTest = parseFailOnSeverity
+var RenderFindingsForTest = renderFindings
+var ParseScanScheduleForTest = parseScanSchedule
+var ParseScanPriorityWeightsForTest = parseScanPriorityWeights
+var OutputLogLevelForTest = outputLogLevel
+var PrintDeleteResultForTest = printDeleteResult
+var AnonymizeFindingsForTest = anonymizeFindings
+var RenderScanDiffForTest = renderScanDiff
+var PrintSeverityOverridesForTest = printSeverityOverrides
+var PrintOwnerScanRunForTest = printOwnerScanRun
+var WriteExportForTest = writeExport
+var ExportFormatForTest = exportFormat
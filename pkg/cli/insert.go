@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"log/slog"
+	"os"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gots/slice"
@@ -19,6 +20,8 @@ func insertCommand() *cli.Command {
 	var (
 		bigQuery   config.BigQuery
 		firestore  config.Firestore
+		advisory   config.Advisory
+		exploit    config.Exploitability
 		resultFile string
 		meta       model.GitHubMetadata
 	)
@@ -31,7 +34,7 @@ func insertCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:        "result-file",
 				Aliases:     []string{"f"},
-				Usage:       "Path to Trivy scan result JSON file (required)",
+				Usage:       "Path to Trivy scan result JSON file, e.g. output of trivy-action with format: json (required)",
 				Sources:     cli.EnvVars("OCTOVY_RESULT_FILE"),
 				Destination: &resultFile,
 			},
@@ -71,28 +74,34 @@ func insertCommand() *cli.Command {
 				Sources:     cli.EnvVars("OCTOVY_GITHUB_INSTALLATION_ID"),
 				Destination: &meta.InstallationID,
 			},
-		}, bigQuery.Flags(), firestore.Flags()),
+		}, bigQuery.Flags(), firestore.Flags(), advisory.Flags(), exploit.Flags()),
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if resultFile == "" {
 				return goerr.New("result file is required")
 			}
 
-			// Auto-detect GitHub metadata from git if not specified
-			if err := AutoDetectGitMetadata(ctx, &meta); err != nil {
+			// Auto-detect GitHub metadata from GitHub Actions context, or from git if not specified
+			inActions, err := DetectGitHubActionsMetadata(&meta, os.Getenv)
+			if err != nil {
 				return err
 			}
+			if !inActions {
+				if err := AutoDetectGitMetadata(ctx, &meta); err != nil {
+					return err
+				}
+			}
 
 			// Validate required GitHub metadata
 			if err := meta.ValidateBasic(); err != nil {
 				return err
 			}
 
-			return runInsert(ctx, resultFile, meta, &bigQuery, &firestore)
+			return runInsert(ctx, resultFile, meta, &bigQuery, &firestore, &advisory, &exploit)
 		},
 	}
 }
 
-func runInsert(ctx context.Context, resultFile string, meta model.GitHubMetadata, bigQuery *config.BigQuery, firestoreConfig *config.Firestore) error {
+func runInsert(ctx context.Context, resultFile string, meta model.GitHubMetadata, bigQuery *config.BigQuery, firestoreConfig *config.Firestore, advisory *config.Advisory, exploit *config.Exploitability) error {
 	// Log insert configuration
 	logging.Default().Info("Starting insert",
 		slog.String("result_file", resultFile),
@@ -102,6 +111,8 @@ func runInsert(ctx context.Context, resultFile string, meta model.GitHubMetadata
 		slog.String("github_commit", meta.CommitID),
 		slog.Any("bigquery", bigQuery),
 		slog.Bool("firestore_enabled", firestoreConfig.Enabled()),
+		slog.Any("advisory", advisory),
+		slog.Any("exploitability", exploit),
 	)
 
 	// Load Trivy report from file
@@ -138,7 +149,17 @@ func runInsert(ctx context.Context, resultFile string, meta model.GitHubMetadata
 	}
 	clients := infra.New(clientOpts...)
 
-	uc := usecase.New(clients)
+	ucOptions, err := bigQuery.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	advisoryOptions, err := advisory.UseCaseOptions(ctx)
+	if err != nil {
+		return err
+	}
+	ucOptions = append(ucOptions, advisoryOptions...)
+	ucOptions = append(ucOptions, exploit.UseCaseOptions()...)
+	uc := usecase.New(clients, ucOptions...)
 
 	// Insert scan result to BigQuery and Firestore
 	scanID, err := uc.InsertScanResult(ctx, meta, *report)
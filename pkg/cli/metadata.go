@@ -2,11 +2,17 @@ package cli
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/google/go-github/v53/github"
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
 
 // AutoDetectGitMetadata auto-detects GitHub metadata from git repository if not already set
@@ -81,3 +87,166 @@ func AutoDetectGitMetadata(ctx context.Context, meta *model.GitHubMetadata) erro
 
 	return nil
 }
+
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// DetectGitHubActionsMetadata fills GitHub metadata not already set from the environment variables and the event payload of a GitHub Actions run. It returns false if not running in GitHub Actions. An inconsistent run context, e.g. a malformed GITHUB_REPOSITORY or an event payload of another repository, is an error.
+func DetectGitHubActionsMetadata(meta *model.GitHubMetadata, getenv func(string) string) (bool, error) {
+	if getenv("GITHUB_ACTIONS") != "true" {
+		return false, nil
+	}
+
+	fullName := getenv("GITHUB_REPOSITORY")
+	owner, repoName, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || repoName == "" || strings.Contains(repoName, "/") {
+		return true, goerr.Wrap(types.ErrInvalidOption, "invalid GITHUB_REPOSITORY", goerr.V("GITHUB_REPOSITORY", fullName))
+	}
+
+	detected := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    owner,
+				RepoName: repoName,
+			},
+			CommitID: getenv("GITHUB_SHA"),
+			Ref:      getenv("GITHUB_REF"),
+		},
+	}
+	if strings.HasPrefix(detected.Ref, "refs/heads/") {
+		detected.Branch = string(types.NewBranchName(detected.Ref))
+	}
+	if tag, ok := strings.CutPrefix(detected.Ref, "refs/tags/"); ok {
+		detected.Tag = tag
+	}
+	if v := getenv("GITHUB_REPOSITORY_ID"); v != "" {
+		repoID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return true, goerr.Wrap(types.ErrInvalidOption, "invalid GITHUB_REPOSITORY_ID", goerr.V("GITHUB_REPOSITORY_ID", v))
+		}
+		detected.RepoID = repoID
+	}
+
+	if path := getenv("GITHUB_EVENT_PATH"); path != "" {
+		if err := applyGitHubActionsEvent(&detected, getenv("GITHUB_EVENT_NAME"), path); err != nil {
+			return true, err
+		}
+	}
+
+	if !commitSHAPattern.MatchString(detected.CommitID) {
+		return true, goerr.Wrap(types.ErrInvalidOption, "invalid commit SHA in GitHub Actions context", goerr.V("commit", detected.CommitID))
+	}
+
+	mergeGitHubMetadata(meta, &detected)
+	return true, nil
+}
+
+// applyGitHubActionsEvent overrides the metadata with the event payload. For pull_request events, the head commit of the pull request is used instead of GITHUB_SHA, which is a temporary merge commit, in the same way as the webhook server.
+func applyGitHubActionsEvent(meta *model.GitHubMetadata, eventName, path string) error {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return goerr.Wrap(err, "failed to read GitHub Actions event payload", goerr.V("path", path))
+	}
+
+	event, err := github.ParseWebHook(eventName, raw)
+	if err != nil {
+		// Events which go-github does not know, e.g. workflow_dispatch in old versions, have no additional metadata
+		return nil
+	}
+
+	var repo *github.Repository
+	switch ev := event.(type) {
+	case *github.PushEvent:
+		if ev.Repo != nil {
+			repo = &github.Repository{
+				FullName:      ev.Repo.FullName,
+				DefaultBranch: ev.Repo.DefaultBranch,
+			}
+		}
+		meta.Committer = model.GitHubUser{
+			Login: ev.GetHeadCommit().GetCommitter().GetLogin(),
+			Email: ev.GetHeadCommit().GetCommitter().GetEmail(),
+		}
+
+	case *github.PullRequestEvent:
+		repo = ev.GetRepo()
+		if eventName != "pull_request" {
+			// pull_request_target runs on the base branch, so GITHUB_SHA and GITHUB_REF are already correct
+			break
+		}
+
+		pr := ev.GetPullRequest()
+		meta.CommitID = pr.GetHead().GetSHA()
+		meta.Ref = pr.GetHead().GetRef()
+		meta.Branch = pr.GetHead().GetRef()
+		meta.Committer = model.GitHubUser{
+			ID:    pr.GetHead().GetUser().GetID(),
+			Login: pr.GetHead().GetUser().GetLogin(),
+			Email: pr.GetHead().GetUser().GetEmail(),
+		}
+		meta.PullRequest = &model.GitHubPullRequest{
+			ID:           pr.GetID(),
+			Number:       pr.GetNumber(),
+			BaseBranch:   pr.GetBase().GetRef(),
+			BaseCommitID: pr.GetBase().GetSHA(),
+			User: model.GitHubUser{
+				ID:    pr.GetBase().GetUser().GetID(),
+				Login: pr.GetBase().GetUser().GetLogin(),
+				Email: pr.GetBase().GetUser().GetEmail(),
+			},
+		}
+
+	case *github.WorkflowDispatchEvent:
+		repo = ev.GetRepo()
+	}
+
+	if repo != nil {
+		fullName := meta.Owner + "/" + meta.RepoName
+		if repo.GetFullName() != "" && !strings.EqualFold(repo.GetFullName(), fullName) {
+			return goerr.Wrap(types.ErrInvalidOption, "GitHub Actions event payload does not match GITHUB_REPOSITORY",
+				goerr.V("GITHUB_REPOSITORY", fullName),
+				goerr.V("payload", repo.GetFullName()),
+			)
+		}
+		meta.DefaultBranch = repo.GetDefaultBranch()
+	}
+
+	return nil
+}
+
+// mergeGitHubMetadata sets fields of dst which are empty from src. Pull request metadata is taken only if dst has no explicit commit, because it describes the commit of src.
+func mergeGitHubMetadata(dst, src *model.GitHubMetadata) {
+	explicitCommit := dst.CommitID != ""
+
+	if dst.Owner == "" {
+		dst.Owner = src.Owner
+	}
+	if dst.RepoName == "" {
+		dst.RepoName = src.RepoName
+	}
+	if dst.RepoID == 0 && dst.Owner == src.Owner && dst.RepoName == src.RepoName {
+		dst.RepoID = src.RepoID
+	}
+	if dst.CommitID == "" {
+		dst.CommitID = src.CommitID
+	}
+	if dst.Branch == "" {
+		dst.Branch = src.Branch
+	}
+	if dst.Ref == "" {
+		dst.Ref = src.Ref
+	}
+	if dst.Tag == "" {
+		dst.Tag = src.Tag
+	}
+	if dst.DefaultBranch == "" {
+		dst.DefaultBranch = src.DefaultBranch
+	}
+	if !explicitCommit {
+		if dst.Committer == (model.GitHubUser{}) {
+			dst.Committer = src.Committer
+		}
+		if dst.PullRequest == nil {
+			dst.PullRequest = src.PullRequest
+		}
+	}
+}
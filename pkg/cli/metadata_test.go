@@ -2,11 +2,15 @@ package cli_test
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/cli"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
 
 func TestAutoDetectGitMetadata(t *testing.T) {
@@ -46,3 +50,144 @@ func TestAutoDetectGitMetadata(t *testing.T) {
 		gt.V(t, meta.CommitID).Equal("custom-commit")
 	})
 }
+
+func TestDetectGitHubActionsMetadata(t *testing.T) {
+	const (
+		sha     = "0123456789abcdef0123456789abcdef01234567"
+		headSHA = "89abcdef0123456789abcdef0123456789abcdef"
+	)
+
+	writeEvent := func(t *testing.T, payload string) string {
+		path := filepath.Join(t.TempDir(), "event.json")
+		gt.NoError(t, os.WriteFile(path, []byte(payload), 0600))
+		return path
+	}
+	newEnv := func(kv map[string]string) func(string) string {
+		env := map[string]string{
+			"GITHUB_ACTIONS":       "true",
+			"GITHUB_REPOSITORY":    "octo-org/octo-repo",
+			"GITHUB_REPOSITORY_ID": "1234",
+			"GITHUB_SHA":           sha,
+			"GITHUB_REF":           "refs/heads/main",
+		}
+		for k, v := range kv {
+			env[k] = v
+		}
+		return func(key string) string { return env[key] }
+	}
+
+	t.Run("not in GitHub Actions", func(t *testing.T) {
+		var meta model.GitHubMetadata
+		inActions, err := cli.DetectGitHubActionsMetadata(&meta, func(string) string { return "" })
+		gt.NoError(t, err)
+		gt.False(t, inActions)
+		gt.V(t, meta).Equal(model.GitHubMetadata{})
+	})
+
+	t.Run("push event", func(t *testing.T) {
+		path := writeEvent(t, `{
+			"ref": "refs/heads/main",
+			"repository": {"full_name": "octo-org/octo-repo", "default_branch": "main"},
+			"head_commit": {"id": "`+sha+`", "committer": {"username": "alice", "email": "alice@example.com"}}
+		}`)
+
+		var meta model.GitHubMetadata
+		inActions, err := cli.DetectGitHubActionsMetadata(&meta, newEnv(map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_EVENT_PATH": path,
+		}))
+		gt.NoError(t, err)
+		gt.True(t, inActions)
+		gt.V(t, meta.Owner).Equal("octo-org")
+		gt.V(t, meta.RepoName).Equal("octo-repo")
+		gt.V(t, meta.RepoID).Equal(int64(1234))
+		gt.V(t, meta.CommitID).Equal(sha)
+		gt.V(t, meta.Branch).Equal("main")
+		gt.V(t, meta.DefaultBranch).Equal("main")
+		gt.V(t, meta.Committer.Login).Equal("alice")
+		gt.Nil(t, meta.PullRequest)
+	})
+
+	t.Run("pull_request event uses head commit", func(t *testing.T) {
+		path := writeEvent(t, `{
+			"action": "synchronize",
+			"number": 42,
+			"repository": {"full_name": "octo-org/octo-repo", "default_branch": "main"},
+			"pull_request": {
+				"id": 100,
+				"number": 42,
+				"head": {"ref": "feature", "sha": "`+headSHA+`", "user": {"login": "bob"}},
+				"base": {"ref": "main", "sha": "`+sha+`"}
+			}
+		}`)
+
+		var meta model.GitHubMetadata
+		_, err := cli.DetectGitHubActionsMetadata(&meta, newEnv(map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_EVENT_PATH": path,
+			"GITHUB_REF":        "refs/pull/42/merge",
+		}))
+		gt.NoError(t, err)
+		gt.V(t, meta.CommitID).Equal(headSHA)
+		gt.V(t, meta.Branch).Equal("feature")
+		gt.V(t, meta.DefaultBranch).Equal("main")
+		gt.NotNil(t, meta.PullRequest)
+		gt.V(t, meta.PullRequest.Number).Equal(42)
+		gt.V(t, meta.PullRequest.BaseBranch).Equal("main")
+		gt.V(t, meta.PullRequest.BaseCommitID).Equal(sha)
+	})
+
+	t.Run("explicit metadata takes precedence", func(t *testing.T) {
+		meta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "other-org", RepoName: "other-repo"},
+				Branch:     "release",
+			},
+		}
+		_, err := cli.DetectGitHubActionsMetadata(&meta, newEnv(nil))
+		gt.NoError(t, err)
+		gt.V(t, meta.Owner).Equal("other-org")
+		gt.V(t, meta.RepoName).Equal("other-repo")
+		gt.V(t, meta.RepoID).Equal(int64(0))
+		gt.V(t, meta.Branch).Equal("release")
+		gt.V(t, meta.CommitID).Equal(sha)
+	})
+
+	t.Run("tag ref has no branch", func(t *testing.T) {
+		var meta model.GitHubMetadata
+		_, err := cli.DetectGitHubActionsMetadata(&meta, newEnv(map[string]string{
+			"GITHUB_REF": "refs/tags/v1.0.0",
+		}))
+		gt.NoError(t, err)
+		gt.V(t, meta.Branch).Equal("")
+		gt.V(t, meta.Ref).Equal("refs/tags/v1.0.0")
+		gt.V(t, meta.Tag).Equal("v1.0.0")
+	})
+
+	t.Run("invalid run context", func(t *testing.T) {
+		mismatched := writeEvent(t, `{
+			"ref": "refs/heads/main",
+			"repository": {"full_name": "someone/fork"},
+			"head_commit": {"id": "`+sha+`"}
+		}`)
+
+		testCases := map[string]map[string]string{
+			"malformed repository": {"GITHUB_REPOSITORY": "octo-repo"},
+			"malformed SHA":        {"GITHUB_SHA": "main"},
+			"missing SHA":          {"GITHUB_SHA": ""},
+			"malformed repo ID":    {"GITHUB_REPOSITORY_ID": "abc"},
+			"payload of other repository": {
+				"GITHUB_EVENT_NAME": "push",
+				"GITHUB_EVENT_PATH": mismatched,
+			},
+		}
+		for name, env := range testCases {
+			t.Run(name, func(t *testing.T) {
+				var meta model.GitHubMetadata
+				inActions, err := cli.DetectGitHubActionsMetadata(&meta, newEnv(env))
+				gt.True(t, inActions)
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+			})
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// renderOwnerScanRun writes the result of each repository of an owner-wide scan, so that CI can tell which repositories failed
+func renderOwnerScanRun(w io.Writer, format string, run *model.OwnerScanRun) error {
+	switch format {
+	case reportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(run); err != nil {
+			return goerr.Wrap(err, "failed to write owner scan result")
+		}
+		return nil
+
+	case reportFormatMarkdown:
+		return renderOwnerScanRunMarkdown(w, run)
+
+	default:
+		return renderOwnerScanRunTable(w, run)
+	}
+}
+
+func ownerScanSummaryLine(run *model.OwnerScanRun) string {
+	return fmt.Sprintf("Owner: %s, Status: %s, Succeeded: %d, Skipped: %d, Failed: %d", run.Owner, run.Status, run.Succeeded, run.Skipped, run.Failed)
+}
+
+// ownerScanRows returns rows of [repository, branch, status, findings, duration, error]
+func ownerScanRows(run *model.OwnerScanRun) [][]string {
+	rows := make([][]string, 0, len(run.Repositories))
+	for _, r := range run.Repositories {
+		status := string(r.Status)
+		if r.Skipped {
+			status = "skipped"
+		}
+
+		findings := "-"
+		if r.Findings != nil {
+			v := r.Findings.Vulnerabilities
+			findings = fmt.Sprintf("C:%d H:%d M:%d L:%d", v.Critical, v.High, v.Medium, v.Low)
+		}
+
+		duration := (time.Duration(r.DurationMS) * time.Millisecond).String()
+		rows = append(rows, []string{r.Owner + "/" + r.Repo, r.Branch, status, findings, duration, r.Error})
+	}
+	return rows
+}
+
+func renderOwnerScanRunTable(w io.Writer, run *model.OwnerScanRun) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if rows := ownerScanRows(run); len(rows) > 0 {
+		fmt.Fprintln(tw, "REPOSITORY\tBRANCH\tSTATUS\tFINDINGS\tDURATION\tERROR")
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintln(tw, ownerScanSummaryLine(run))
+	if run.Error != "" {
+		fmt.Fprintln(tw, "Interrupted: "+run.Error)
+	}
+	fmt.Fprintln(tw, "Run ID: "+run.ID.String())
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write owner scan result")
+	}
+	return nil
+}
+
+func renderOwnerScanRunMarkdown(w io.Writer, run *model.OwnerScanRun) error {
+	var b strings.Builder
+	b.WriteString("## Octovy Owner Scan\n\n")
+	b.WriteString(escapeMarkdownCell(ownerScanSummaryLine(run)) + "\n")
+	if run.Error != "" {
+		b.WriteString("\nInterrupted: " + escapeMarkdownCell(run.Error) + "\n")
+	}
+
+	if rows := ownerScanRows(run); len(rows) > 0 {
+		b.WriteString("\n| Repository | Branch | Status | Findings | Duration | Error |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, row := range rows {
+			for i := range row {
+				row[i] = escapeMarkdownCell(row[i])
+			}
+			b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	}
+	b.WriteString("\nRun ID: `" + run.ID.String() + "`\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return goerr.Wrap(err, "failed to write owner scan result")
+	}
+	return nil
+}
+
+// printOwnerScanRun renders the run if the format is given. The run is printed even if some repositories failed, and nothing is printed if no repository was scanned.
+func printOwnerScanRun(w io.Writer, format string, run *model.OwnerScanRun) error {
+	if format == "" || run == nil {
+		return nil
+	}
+	return renderOwnerScanRun(w, format, run)
+}
+
+// validateOwnerScanOutput accepts an empty format, which disables the report
+func validateOwnerScanOutput(format string) error {
+	switch format {
+	case "", reportFormatTable, reportFormatMarkdown, reportFormatJSON:
+		return nil
+	default:
+		return goerr.Wrap(types.ErrInvalidOption, "invalid --output", goerr.V("output", format))
+	}
+}
@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestPrintOwnerScanRun(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceGitHub, "", "", now)
+	run.Add(&model.OwnerScanRepoResult{
+		Owner: "myorg", Repo: "ok", Branch: "main", Status: types.ScanRunSucceeded, ScanID: "scan-1", DurationMS: 1500,
+		Findings: &model.FindingSummary{Vulnerabilities: model.SeverityCounts{Critical: 1, High: 2, Total: 3}},
+	})
+	run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "cached", Branch: "main", Status: types.ScanRunSucceeded, Skipped: true})
+	run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "ng", Branch: "main", Status: types.ScanRunFailed, Error: "a | b"})
+	run.Finish(nil, now.Add(time.Minute))
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.PrintOwnerScanRunForTest(&buf, "table", run))
+		gt.S(t, buf.String()).Contains("myorg/ok")
+		gt.S(t, buf.String()).Contains("C:1 H:2 M:0 L:0")
+		gt.S(t, buf.String()).Contains("1.5s")
+		gt.S(t, buf.String()).Contains("skipped")
+		gt.S(t, buf.String()).Contains("Owner: myorg, Status: failed, Succeeded: 1, Skipped: 1, Failed: 1")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.PrintOwnerScanRunForTest(&buf, "markdown", run))
+		gt.S(t, buf.String()).Contains("| myorg/ng | main | failed | - | 0s | a \\| b |")
+		gt.S(t, buf.String()).Contains("Run ID: `" + run.ID.String() + "`")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.PrintOwnerScanRunForTest(&buf, "json", run))
+		var out model.OwnerScanRun
+		gt.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		gt.V(t, out.ID).Equal(run.ID)
+		gt.V(t, out.Failed).Equal(1)
+		gt.A(t, out.Repositories).Length(3)
+		gt.V(t, out.Repositories[0].Findings.Vulnerabilities.Critical).Equal(1)
+	})
+
+	t.Run("nothing is printed without format", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.PrintOwnerScanRunForTest(&buf, "", run))
+		gt.V(t, buf.Len()).Equal(0)
+	})
+
+	t.Run("nothing is printed without run", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.PrintOwnerScanRunForTest(&buf, "json", nil))
+		gt.V(t, buf.Len()).Equal(0)
+	})
+}
@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	reportFormatTable    = "table"
+	reportFormatMarkdown = "markdown"
+	reportFormatJSON     = "json"
+
+	reportGroupByTeam = "team"
+	reportGroupByTag  = "tag"
+
+	// reportGroupNone is the group of findings of repositories without team or tags
+	reportGroupNone = "(none)"
+)
+
+func reportCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.ListFindingsInput
+		branch    string
+		severity  string
+		statuses  []string
+		filePath  string
+		format    string
+		groupBy   string
+		anonymize bool
+		mapping   string
+		baseURL   string
+	)
+
+	return &cli.Command{
+		Name:  "report",
+		Usage: "Render findings stored in Firestore or in a local Trivy JSON report, optionally anonymized for sharing",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required unless --file)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (default: all repositories of the owner)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+			&cli.StringFlag{
+				Name:        "branch",
+				Usage:       "Branch name (default: default branch of each repository)",
+				Destination: &branch,
+			},
+			&cli.StringFlag{
+				Name:        "file",
+				Usage:       "Trivy JSON report to render instead of Firestore",
+				Destination: &filePath,
+			},
+			&cli.StringFlag{
+				Name:        "severity",
+				Usage:       "Show findings at or above the severity [LOW|MEDIUM|HIGH|CRITICAL]",
+				Destination: &severity,
+			},
+			&cli.StringSliceFlag{
+				Name:        "status",
+				Usage:       "Show findings with the status [active|acknowledged|fixed] (default: active)",
+				Destination: &statuses,
+			},
+			&cli.StringFlag{
+				Name:        "tag",
+				Usage:       "Show findings of repositories with the tag",
+				Destination: &input.Tag,
+			},
+			&cli.StringFlag{
+				Name:        "team",
+				Usage:       "Show findings of repositories owned by the team",
+				Destination: &input.Team,
+			},
+			&cli.StringFlag{
+				Name:        "group-by",
+				Usage:       "Summarize findings per [team|tag] in addition to the total",
+				Destination: &groupBy,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output format [table|markdown|json]",
+				Sources:     cli.EnvVars("OCTOVY_REPORT_FORMAT"),
+				Destination: &format,
+				Value:       reportFormatTable,
+			},
+			&cli.BoolFlag{
+				Name:        "anonymize",
+				Usage:       "Replace owner, repository, team and tags with stable pseudonyms to share the report outside of the organization. Requires --anonymize-mapping",
+				Destination: &anonymize,
+			},
+			&cli.StringFlag{
+				Name:        "anonymize-mapping",
+				Usage:       "Local JSON file of real names and pseudonyms. Read to keep pseudonyms stable and updated with new names. Keep it private to re-identify shared results",
+				Sources:     cli.EnvVars("OCTOVY_ANONYMIZE_MAPPING"),
+				Destination: &mapping,
+			},
+			&cli.StringFlag{
+				Name:        "base-url",
+				Usage:       "Public URL of the octovy server to link findings to their permalinks",
+				Sources:     cli.EnvVars("OCTOVY_BASE_URL"),
+				Destination: &baseURL,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			switch format {
+			case reportFormatTable, reportFormatMarkdown, reportFormatJSON:
+			default:
+				return goerr.Wrap(types.ErrInvalidOption, "invalid --format", goerr.V("format", format))
+			}
+			switch groupBy {
+			case "", reportGroupByTeam, reportGroupByTag:
+			default:
+				return goerr.Wrap(types.ErrInvalidOption, "invalid --group-by", goerr.V("group-by", groupBy))
+			}
+			if anonymize && mapping == "" {
+				return goerr.Wrap(types.ErrInvalidOption, "--anonymize-mapping is required with --anonymize")
+			}
+
+			input.Branch = types.NewBranchName(branch)
+			if severity != "" {
+				sev, err := types.ParseSeverity(severity)
+				if err != nil {
+					return err
+				}
+				input.MinSeverity = sev
+			}
+			for _, s := range statuses {
+				status, err := types.ParseVulnStatus(s)
+				if err != nil {
+					return err
+				}
+				input.Statuses = append(input.Statuses, status)
+			}
+
+			clients := infra.New()
+			if filePath != "" {
+				report, err := usecase.LoadTrivyReportFromFile(ctx, filePath)
+				if err != nil {
+					return err
+				}
+				input.Report = report
+			} else {
+				if input.Owner == "" {
+					return goerr.Wrap(types.ErrInvalidOption, "--github-owner is required unless --file is set")
+				}
+				if !firestore.Enabled() {
+					return goerr.New("Firestore is required (--firestore-project-id or --db must be set) unless --file is set")
+				}
+				repo, err := firestore.NewRepository(ctx)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create Firestore repository")
+				}
+				clients = infra.New(infra.WithScanRepository(repo))
+			}
+
+			findings, err := usecase.New(clients, usecase.WithPermalinkBaseURL(baseURL)).ListFindings(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to list findings")
+			}
+
+			if anonymize {
+				if findings, err = anonymizeFindings(mapping, findings); err != nil {
+					return err
+				}
+			}
+
+			return renderFindings(os.Stdout, format, groupBy, findings)
+		},
+	}
+}
+
+// anonymizeFindings replaces identifying names of findings with pseudonyms of the mapping file, and saves the mapping with names seen for the first time. The file is created if it does not exist.
+func anonymizeFindings(mappingPath string, findings []*model.Finding) ([]*model.Finding, error) {
+	pseudonyms := model.NewPseudonyms()
+	raw, err := os.ReadFile(filepath.Clean(mappingPath))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(raw, pseudonyms); err != nil {
+			return nil, goerr.Wrap(err, "failed to parse anonymize mapping", goerr.V("path", mappingPath))
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, goerr.Wrap(err, "failed to read anonymize mapping", goerr.V("path", mappingPath))
+	}
+
+	anonymized := make([]*model.Finding, len(findings))
+	for i, f := range findings {
+		anonymized[i] = pseudonyms.AnonymizeFinding(f)
+	}
+
+	raw, err = json.MarshalIndent(pseudonyms, "", "  ")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to encode anonymize mapping")
+	}
+	if err := os.WriteFile(filepath.Clean(mappingPath), append(raw, '\n'), 0600); err != nil {
+		return nil, goerr.Wrap(err, "failed to write anonymize mapping", goerr.V("path", mappingPath))
+	}
+
+	return anonymized, nil
+}
+
+type findingsReport struct {
+	Summary  model.SeverityCounts `json:"summary"`
+	Groups   []*findingGroup      `json:"groups,omitempty"`
+	Findings []*model.Finding     `json:"findings"`
+}
+
+// findingGroup is the summary of findings of a team or a tag
+type findingGroup struct {
+	Name    string               `json:"name"`
+	Summary model.SeverityCounts `json:"summary"`
+}
+
+func renderFindings(w io.Writer, format, groupBy string, findings []*model.Finding) error {
+	report := findingsReport{Findings: findings}
+	if report.Findings == nil {
+		report.Findings = []*model.Finding{}
+	}
+	for _, f := range findings {
+		report.Summary.Add(f.Severity)
+	}
+	report.Groups = groupFindings(groupBy, findings)
+
+	switch format {
+	case reportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return goerr.Wrap(err, "failed to write report")
+		}
+		return nil
+
+	case reportFormatMarkdown:
+		return renderFindingsMarkdown(w, &report)
+
+	default:
+		return renderFindingsTable(w, &report)
+	}
+}
+
+// groupFindings summarizes findings per team or tag, ordered by name. A finding of a repository with multiple tags is counted in each tag.
+func groupFindings(groupBy string, findings []*model.Finding) []*findingGroup {
+	if groupBy == "" {
+		return nil
+	}
+
+	groups := make(map[string]*findingGroup)
+	add := func(name string, severity string) {
+		if name == "" {
+			name = reportGroupNone
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = &findingGroup{Name: name}
+			groups[name] = group
+		}
+		group.Summary.Add(severity)
+	}
+
+	for _, f := range findings {
+		switch groupBy {
+		case reportGroupByTeam:
+			add(f.Team, f.Severity)
+		case reportGroupByTag:
+			if len(f.Tags) == 0 {
+				add("", f.Severity)
+			}
+			for _, tag := range f.Tags {
+				add(tag, f.Severity)
+			}
+		}
+	}
+
+	result := make([]*findingGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func summaryLine(s model.SeverityCounts) string {
+	return fmt.Sprintf("Total: %d (CRITICAL: %d, HIGH: %d, MEDIUM: %d, LOW: %d, UNKNOWN: %d)",
+		s.Total, s.Critical, s.High, s.Medium, s.Low, s.Unknown)
+}
+
+func renderFindingsTable(w io.Writer, report *findingsReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(report.Findings) > 0 {
+		fmt.Fprintln(tw, "REPOSITORY\tBRANCH\tTARGET\tTYPE\tID\tSEVERITY\tSTATUS\tPACKAGE\tINSTALLED\tFIXED\tTITLE")
+		for _, f := range report.Findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				f.Repository, f.Branch, f.Target, f.Type, f.ID, f.Severity, f.Status, f.PkgName, f.InstalledVersion, f.FixedVersion, f.Title)
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintln(tw, summaryLine(report.Summary))
+	for _, group := range report.Groups {
+		fmt.Fprintf(tw, "  %s\t%s\n", group.Name, summaryLine(group.Summary))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return goerr.Wrap(err, "failed to write report")
+	}
+	return nil
+}
+
+func renderFindingsMarkdown(w io.Writer, report *findingsReport) error {
+	var b strings.Builder
+	b.WriteString("## Octovy Report\n\n")
+	b.WriteString(summaryLine(report.Summary) + "\n")
+
+	if len(report.Groups) > 0 {
+		b.WriteString("\n| Group | Total | Critical | High | Medium | Low | Unknown |\n")
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, group := range report.Groups {
+			c := group.Summary
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %d |\n", escapeMarkdownCell(group.Name), c.Total, c.Critical, c.High, c.Medium, c.Low, c.Unknown)
+		}
+	}
+
+	if len(report.Findings) > 0 {
+		b.WriteString("\n| Repository | Branch | Target | Type | ID | Severity | Status | Package | Installed | Fixed | Title |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|---|---|---|\n")
+		for _, f := range report.Findings {
+			cells := []string{f.Repository, f.Branch, f.Target, string(f.Type), f.ID, f.Severity, string(f.Status), f.PkgName, f.InstalledVersion, f.FixedVersion, f.Title}
+			for i := range cells {
+				cells[i] = escapeMarkdownCell(cells[i])
+			}
+			if f.Permalink != "" {
+				cells[4] = "[" + cells[4] + "](" + f.Permalink + ")"
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return goerr.Wrap(err, "failed to write report")
+	}
+	return nil
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,144 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestRenderFindings(t *testing.T) {
+	findings := []*model.Finding{
+		{Repository: "myorg/myrepo", Branch: "main", Target: "go.mod", Type: types.FindingTypeVulnerability, ID: "CVE-2024-0001", Severity: "CRITICAL", Status: types.VulnStatusActive, PkgName: "pkg-a", Title: "a | b"},
+		{Repository: "myorg/myrepo", Branch: "main", Target: "Dockerfile", Type: types.FindingTypeMisconfiguration, ID: "AVD-DS-0002", Severity: "HIGH", Status: types.VulnStatusActive},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "table", "", findings))
+		gt.S(t, buf.String()).Contains("CVE-2024-0001")
+		gt.S(t, buf.String()).Contains("Total: 2 (CRITICAL: 1, HIGH: 1, MEDIUM: 0, LOW: 0, UNKNOWN: 0)")
+	})
+
+	t.Run("markdown escapes cells", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "markdown", "", findings))
+		gt.S(t, buf.String()).Contains("| myorg/myrepo | main | go.mod | vulnerability | CVE-2024-0001 | CRITICAL | active | pkg-a |  |  | a \\| b |")
+	})
+
+	t.Run("markdown links finding to permalink", func(t *testing.T) {
+		linked := *findings[0]
+		linked.Permalink = "https://octovy.example.com/r/myorg/myrepo/b/main/v/CVE-2024-0001"
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "markdown", "", []*model.Finding{&linked}))
+		gt.S(t, buf.String()).Contains("| [CVE-2024-0001](https://octovy.example.com/r/myorg/myrepo/b/main/v/CVE-2024-0001) |")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "json", "", findings))
+
+		var out struct {
+			Summary  model.SeverityCounts `json:"summary"`
+			Findings []*model.Finding     `json:"findings"`
+		}
+		gt.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		gt.V(t, out.Summary.Total).Equal(2)
+		gt.A(t, out.Findings).Length(2)
+	})
+
+	t.Run("no findings", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "json", "", nil))
+		gt.S(t, buf.String()).Contains(`"findings": []`)
+	})
+}
+
+func TestRenderFindingsGroupBy(t *testing.T) {
+	findings := []*model.Finding{
+		{Repository: "myorg/api", Team: "backend", Tags: []string{"payments", "tier-1"}, ID: "CVE-2024-0001", Severity: "CRITICAL"},
+		{Repository: "myorg/api", Team: "backend", Tags: []string{"payments", "tier-1"}, ID: "CVE-2024-0002", Severity: "LOW"},
+		{Repository: "myorg/web", Team: "frontend", Tags: []string{"tier-1"}, ID: "CVE-2024-0003", Severity: "HIGH"},
+		{Repository: "myorg/tool", ID: "CVE-2024-0004", Severity: "MEDIUM"},
+	}
+
+	decode := func(t *testing.T, groupBy string) map[string]model.SeverityCounts {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "json", groupBy, findings))
+
+		var out struct {
+			Summary model.SeverityCounts `json:"summary"`
+			Groups  []struct {
+				Name    string               `json:"name"`
+				Summary model.SeverityCounts `json:"summary"`
+			} `json:"groups"`
+		}
+		gt.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		gt.V(t, out.Summary.Total).Equal(4)
+
+		groups := make(map[string]model.SeverityCounts)
+		for _, g := range out.Groups {
+			groups[g.Name] = g.Summary
+		}
+		return groups
+	}
+
+	t.Run("team", func(t *testing.T) {
+		groups := decode(t, "team")
+		gt.V(t, len(groups)).Equal(3)
+		gt.V(t, groups["backend"].Total).Equal(2)
+		gt.V(t, groups["backend"].Critical).Equal(1)
+		gt.V(t, groups["frontend"].High).Equal(1)
+		gt.V(t, groups["(none)"].Medium).Equal(1)
+	})
+
+	t.Run("tag counts finding in each tag", func(t *testing.T) {
+		groups := decode(t, "tag")
+		gt.V(t, groups["tier-1"].Total).Equal(3)
+		gt.V(t, groups["payments"].Total).Equal(2)
+		gt.V(t, groups["(none)"].Total).Equal(1)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "markdown", "team", findings))
+		gt.S(t, buf.String()).Contains("| backend | 2 | 1 | 0 | 0 | 1 | 0 |")
+	})
+
+	t.Run("no groups without group-by", func(t *testing.T) {
+		var buf bytes.Buffer
+		gt.NoError(t, cli.RenderFindingsForTest(&buf, "json", "", findings))
+		gt.S(t, buf.String()).NotContains(`"groups"`)
+	})
+}
+
+func TestAnonymizeFindings(t *testing.T) {
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	findings := []*model.Finding{
+		{Repository: "myorg/api", Team: "backend", Tags: []string{"payments"}, Target: "go.mod", ID: "CVE-2024-0001", PkgName: "github.com/myorg/lib"},
+	}
+
+	first := gt.R1(cli.AnonymizeFindingsForTest(mappingPath, findings)).NoError(t)
+	gt.V(t, first[0].Repository).Equal("owner-1/repo-1")
+	gt.V(t, first[0].PkgName).Equal("github.com/owner-1/lib")
+	gt.V(t, findings[0].Repository).Equal("myorg/api")
+
+	// Pseudonyms are kept across runs by the mapping file, and new names are appended
+	second := gt.R1(cli.AnonymizeFindingsForTest(mappingPath, []*model.Finding{
+		{Repository: "myorg/web", ID: "CVE-2024-0002"},
+		{Repository: "myorg/api", ID: "CVE-2024-0003"},
+	})).NoError(t)
+	gt.V(t, second[0].Repository).Equal("owner-1/repo-2")
+	gt.V(t, second[1].Repository).Equal("owner-1/repo-1")
+
+	var mapping model.Pseudonyms
+	gt.NoError(t, json.Unmarshal(gt.R1(os.ReadFile(mappingPath)).NoError(t), &mapping))
+	gt.V(t, mapping.Repositories).Equal(map[string]string{"myorg/api": "repo-1", "myorg/web": "repo-2"})
+	gt.V(t, mapping.Teams).Equal(map[string]string{"backend": "team-1"})
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"log/slog"
+	"os"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gots/slice"
@@ -11,7 +12,7 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra"
-	trivyInfra "github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra/gcs"
 	"github.com/m-mizutani/octovy/pkg/usecase"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
 	"github.com/urfave/cli/v3"
@@ -30,11 +31,18 @@ func scanCommand() *cli.Command {
 
 func scanLocalCommand() *cli.Command {
 	var (
-		bigQuery  config.BigQuery
-		firestore config.Firestore
-		dir       string
-		trivyPath string
-		meta      model.GitHubMetadata
+		bigQuery       config.BigQuery
+		firestore      config.Firestore
+		trivy          config.Trivy
+		scanner        config.Scanner
+		archive        config.Archive
+		advisory       config.Advisory
+		exploit        config.Exploitability
+		proxy          config.Proxy
+		dir            string
+		source         string
+		meta           model.GitHubMetadata
+		failOnSeverity string
 	)
 
 	return &cli.Command{
@@ -49,11 +57,10 @@ func scanLocalCommand() *cli.Command {
 				Destination: &dir,
 			},
 			&cli.StringFlag{
-				Name:        "trivy-path",
-				Usage:       "Path to trivy binary",
-				Value:       "trivy",
-				Sources:     cli.EnvVars("OCTOVY_TRIVY_PATH"),
-				Destination: &trivyPath,
+				Name:        "source",
+				Usage:       "Source code to scan instead of --dir: local path, gs://bucket/object.zip or https:// URL of zip archive",
+				Sources:     cli.EnvVars("OCTOVY_SCAN_SOURCE"),
+				Destination: &source,
 			},
 			&cli.StringFlag{
 				Name:        "github-owner",
@@ -73,35 +80,66 @@ func scanLocalCommand() *cli.Command {
 				Sources:     cli.EnvVars("OCTOVY_GITHUB_COMMIT_ID"),
 				Destination: &meta.CommitID,
 			},
-		}, bigQuery.Flags(), firestore.Flags()),
+			&cli.StringFlag{
+				Name:        "fail-on-severity",
+				Usage:       "Exit with non-zero status if findings at or above the severity exist, e.g. CRITICAL,HIGH (the lowest listed severity is the threshold)",
+				Sources:     cli.EnvVars("OCTOVY_FAIL_ON_SEVERITY"),
+				Destination: &failOnSeverity,
+			},
+		}, scanner.Flags(), archive.Flags(), trivy.Flags(), bigQuery.Flags(), firestore.Flags(), advisory.Flags(), exploit.Flags(), proxy.Flags()),
 		Action: func(ctx context.Context, c *cli.Command) error {
-			// Auto-detect GitHub metadata from git if not specified
-			if err := AutoDetectGitMetadata(ctx, &meta); err != nil {
+			if source == "" {
+				source = dir
+			}
+			src, err := model.ParseCodeSource(source)
+			if err != nil {
 				return err
 			}
 
+			// Auto-detect GitHub metadata from git if not specified. An archive from outside can be scanned without git repository if the metadata is given.
+			if src.Kind == types.SourceLocal || meta.Owner == "" || meta.RepoName == "" || meta.CommitID == "" {
+				if err := AutoDetectGitMetadata(ctx, &meta); err != nil {
+					return err
+				}
+			}
+
 			// Validate required GitHub metadata
 			if err := meta.ValidateBasic(); err != nil {
 				return err
 			}
 
-			return runScanLocal(ctx, dir, trivyPath, meta, &bigQuery, &firestore)
+			threshold, err := parseFailOnSeverity(failOnSeverity)
+			if err != nil {
+				return err
+			}
+
+			return runScanLocal(ctx, src, &trivy, &scanner, &archive, meta, &bigQuery, &firestore, &advisory, &exploit, &proxy, threshold)
 		},
 	}
 }
 
 func scanRemoteCommand() *cli.Command {
 	var (
-		bigQuery     config.BigQuery
-		firestore    config.Firestore
-		githubApp    config.GitHubApp
-		trivyPath    string
-		owner        string
-		repo         string
-		commit       string
-		branch       string
-		installIDRaw int64
-		scanAll      bool
+		bigQuery       config.BigQuery
+		firestore      config.Firestore
+		githubApp      config.GitHubApp
+		trivy          config.Trivy
+		scanner        config.Scanner
+		archive        config.Archive
+		advisory       config.Advisory
+		exploit        config.Exploitability
+		proxy          config.Proxy
+		owner          string
+		repo           string
+		commit         string
+		branch         string
+		tag            string
+		installIDRaw   int64
+		scanAll        bool
+		force          bool
+		stateless      bool
+		failOnSeverity string
+		output         string
 	)
 
 	return &cli.Command{
@@ -123,29 +161,28 @@ func scanRemoteCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        "github-commit",
-				Usage:       "GitHub commit ID (mutually exclusive with --github-branch)",
+				Usage:       "GitHub commit ID (mutually exclusive with --github-branch and --github-tag)",
 				Sources:     cli.EnvVars("OCTOVY_GITHUB_COMMIT"),
 				Destination: &commit,
 			},
 			&cli.StringFlag{
 				Name:        "github-branch",
-				Usage:       "GitHub branch name (mutually exclusive with --github-commit)",
+				Usage:       "GitHub branch name (mutually exclusive with --github-commit and --github-tag)",
 				Sources:     cli.EnvVars("OCTOVY_GITHUB_BRANCH"),
 				Destination: &branch,
 			},
+			&cli.StringFlag{
+				Name:        "github-tag",
+				Usage:       "Git tag name to scan, e.g. v1.2.0. A release is specified by its tag name (mutually exclusive with --github-commit and --github-branch)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_TAG"),
+				Destination: &tag,
+			},
 			&cli.Int64Flag{
 				Name:        "github-app-installation-id",
 				Usage:       "GitHub App Installation ID (required for full specification mode)",
 				Sources:     cli.EnvVars("OCTOVY_GITHUB_APP_INSTALLATION_ID"),
 				Destination: &installIDRaw,
 			},
-			&cli.StringFlag{
-				Name:        "trivy-path",
-				Usage:       "Path to trivy binary",
-				Value:       "trivy",
-				Sources:     cli.EnvVars("OCTOVY_TRIVY_PATH"),
-				Destination: &trivyPath,
-			},
 			&cli.BoolFlag{
 				Name:        "all",
 				Aliases:     []string{"a"},
@@ -153,19 +190,61 @@ func scanRemoteCommand() *cli.Command {
 				Sources:     cli.EnvVars("OCTOVY_SCAN_ALL"),
 				Destination: &scanAll,
 			},
-		}, bigQuery.Flags(), firestore.Flags(), githubApp.Flags()),
+			&cli.BoolFlag{
+				Name:        "force",
+				Usage:       "Scan even if the commit has already been scanned successfully",
+				Sources:     cli.EnvVars("OCTOVY_SCAN_FORCE"),
+				Destination: &force,
+			},
+			&cli.BoolFlag{
+				Name:        "stateless",
+				Usage:       "Print scan results without storing them (BigQuery and Firestore are not used); exit with non-zero status if findings are detected",
+				Sources:     cli.EnvVars("OCTOVY_SCAN_STATELESS"),
+				Destination: &stateless,
+			},
+			&cli.StringFlag{
+				Name:        "fail-on-severity",
+				Usage:       "Exit with non-zero status if findings at or above the severity exist, e.g. CRITICAL,HIGH (the lowest listed severity is the threshold)",
+				Sources:     cli.EnvVars("OCTOVY_FAIL_ON_SEVERITY"),
+				Destination: &failOnSeverity,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "Print the result of each repository when scanning all repositories of the owner [table|markdown|json]",
+				Sources:     cli.EnvVars("OCTOVY_SCAN_OUTPUT"),
+				Destination: &output,
+			},
+		}, scanner.Flags(), archive.Flags(), trivy.Flags(), bigQuery.Flags(), firestore.Flags(), githubApp.Flags(), advisory.Flags(), exploit.Flags(), proxy.Flags()),
 		Action: func(ctx context.Context, c *cli.Command) error {
+			threshold, err := parseFailOnSeverity(failOnSeverity)
+			if err != nil {
+				return err
+			}
+			if err := validateOwnerScanOutput(output); err != nil {
+				return err
+			}
+
 			return runScanRemote(ctx, &scanRemoteParams{
 				owner:        owner,
 				repo:         repo,
 				commit:       commit,
 				branch:       branch,
+				tag:          tag,
 				installIDRaw: installIDRaw,
-				trivyPath:    trivyPath,
+				trivy:        &trivy,
+				scanner:      &scanner,
+				archive:      &archive,
 				scanAll:      scanAll,
+				force:        force,
+				stateless:    stateless,
+				failOn:       threshold,
+				output:       output,
 				bigQuery:     &bigQuery,
 				firestore:    &firestore,
 				githubApp:    &githubApp,
+				advisory:     &advisory,
+				exploit:      &exploit,
+				proxy:        &proxy,
 			})
 		},
 	}
@@ -176,12 +255,23 @@ type scanRemoteParams struct {
 	repo         string
 	commit       string
 	branch       string
+	tag          string
 	installIDRaw int64
-	trivyPath    string
+	trivy        *config.Trivy
+	scanner      *config.Scanner
+	archive      *config.Archive
 	scanAll      bool
-	bigQuery     *config.BigQuery
-	firestore    *config.Firestore
-	githubApp    *config.GitHubApp
+	force        bool
+	stateless    bool
+	failOn       types.Severity
+	// output is the format of the result of an owner-wide scan. It is not printed if empty.
+	output    string
+	bigQuery  *config.BigQuery
+	firestore *config.Firestore
+	githubApp *config.GitHubApp
+	advisory  *config.Advisory
+	exploit   *config.Exploitability
+	proxy     *config.Proxy
 }
 
 func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
@@ -191,19 +281,67 @@ func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
 		slog.String("github_repo", params.repo),
 		slog.String("github_commit", params.commit),
 		slog.String("github_branch", params.branch),
+		slog.String("github_tag", params.tag),
 		slog.Int64("github_app_installation_id", params.installIDRaw),
-		slog.String("trivy_path", params.trivyPath),
+		slog.Any("trivy", params.trivy),
+		slog.Any("scanner", params.scanner),
+		slog.Any("archive", params.archive),
 		slog.Bool("scan_all", params.scanAll),
+		slog.Bool("force", params.force),
+		slog.Bool("stateless", params.stateless),
+		slog.Any("fail_on_severity", params.failOn),
+		slog.String("output", params.output),
 		slog.Any("bigquery", params.bigQuery),
 		slog.Any("github_app", params.githubApp),
 		slog.Bool("firestore_enabled", params.firestore.Enabled()),
+		slog.Any("advisory", params.advisory),
+		slog.Any("exploitability", params.exploit),
+		slog.Any("proxy", params.proxy),
 	)
 
+	outbound, err := params.proxy.Outbound()
+	if err != nil {
+		return err
+	}
+	trivyOptions, err := params.trivy.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	scannerOptions, err := params.scanner.UseCaseOptions(outbound.Grype...)
+	if err != nil {
+		return err
+	}
+	archiveOptions, err := params.archive.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	scannerOptions = append(scannerOptions, archiveOptions...)
+	scannerOptions = append(scannerOptions, trivyOptions...)
+
 	// Create GitHub App client
-	ghClient, err := params.githubApp.New()
+	ghClient, err := params.githubApp.New(outbound.GitHubApp...)
 	if err != nil {
 		return goerr.Wrap(err, "failed to create GitHub App client")
 	}
+	fetcherOptions, err := params.githubApp.FetcherOptions(ghClient, outbound.GitClone...)
+	if err != nil {
+		return err
+	}
+	scannerOptions = append(scannerOptions, fetcherOptions...)
+	upgraderOptions, err := params.githubApp.UpgraderOptions(outbound.Upgrader...)
+	if err != nil {
+		return err
+	}
+	scannerOptions = append(scannerOptions, upgraderOptions...)
+	trivyClient := params.trivy.NewClient(outbound.Trivy...)
+
+	if params.stateless {
+		clients := infra.New(append([]infra.Option{
+			infra.WithGitHubApp(ghClient),
+			infra.WithTrivy(trivyClient),
+		}, outbound.Infra...)...)
+		return runScanRemoteStateless(ctx, params, clients, scannerOptions, os.Stdout)
+	}
 
 	// Create BigQuery client
 	bqClient, err := params.bigQuery.NewClient(ctx)
@@ -225,30 +363,56 @@ func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
 	}
 
 	// Create clients
-	trivyClient := trivyInfra.New(params.trivyPath)
 	clientOpts := []infra.Option{
 		infra.WithGitHubApp(ghClient),
 		infra.WithTrivy(trivyClient),
 		infra.WithBigQuery(bqClient),
 	}
+	clientOpts = append(clientOpts, outbound.Infra...)
 	if firestoreRepo != nil {
 		clientOpts = append(clientOpts, infra.WithScanRepository(firestoreRepo))
 	}
 	clients := infra.New(clientOpts...)
 
 	// Execute scan using usecase
-	uc := usecase.New(clients)
+	ucOptions, err := params.bigQuery.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	ucOptions = append(ucOptions, scannerOptions...)
+	ucOptions = append(ucOptions, usecase.WithFailOnSeverity(params.failOn))
+	githubAppOptions, err := params.githubApp.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	ucOptions = append(ucOptions, githubAppOptions...)
+	advisoryOptions, err := params.advisory.UseCaseOptions(ctx)
+	if err != nil {
+		return err
+	}
+	ucOptions = append(ucOptions, advisoryOptions...)
+	ucOptions = append(ucOptions, params.exploit.UseCaseOptions(outbound.Exploitability...)...)
+	uc := usecase.New(clients, ucOptions...)
 
 	// Check if this is owner-only mode (repo not specified)
 	if params.repo == "" {
+		if params.tag != "" {
+			return goerr.Wrap(types.ErrInvalidOption, "--github-tag requires --github-repo")
+		}
+
 		// Use --all mode (GitHub API) or Firestore mode
 		if params.scanAll {
 			apiInput := &model.ScanGitHubReposByOwnerFromAPIInput{
 				Owner:     params.owner,
 				InstallID: types.GitHubAppInstallID(params.installIDRaw),
+				Force:     params.force,
+			}
+			run, scanErr := uc.ScanGitHubReposByOwnerFromAPI(ctx, apiInput)
+			if err := printOwnerScanRun(os.Stdout, params.output, run); err != nil {
+				return err
 			}
-			if err := uc.ScanGitHubReposByOwnerFromAPI(ctx, apiInput); err != nil {
-				return goerr.Wrap(err, "failed to scan repositories by owner using GitHub API")
+			if scanErr != nil {
+				return goerr.Wrap(scanErr, "failed to scan repositories by owner using GitHub API")
 			}
 			return nil
 		}
@@ -256,9 +420,14 @@ func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
 		// Firestore mode (default when --all is not specified)
 		ownerInput := &model.ScanGitHubReposByOwnerInput{
 			Owner: params.owner,
+			Force: params.force,
 		}
-		if err := uc.ScanGitHubReposByOwner(ctx, ownerInput); err != nil {
-			return goerr.Wrap(err, "failed to scan repositories by owner")
+		run, scanErr := uc.ScanGitHubReposByOwner(ctx, ownerInput)
+		if err := printOwnerScanRun(os.Stdout, params.output, run); err != nil {
+			return err
+		}
+		if scanErr != nil {
+			return goerr.Wrap(scanErr, "failed to scan repositories by owner")
 		}
 		return nil
 	}
@@ -269,7 +438,9 @@ func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
 		Repo:      params.repo,
 		Commit:    params.commit,
 		Branch:    params.branch,
+		Tag:       params.tag,
 		InstallID: types.GitHubAppInstallID(params.installIDRaw),
+		Force:     params.force,
 	}
 
 	if err := uc.ScanGitHubRepoRemote(ctx, input); err != nil {
@@ -279,19 +450,43 @@ func runScanRemote(ctx context.Context, params *scanRemoteParams) error {
 	return nil
 }
 
-func runScanLocal(ctx context.Context, dir, trivyPath string, meta model.GitHubMetadata, bigQuery *config.BigQuery, firestoreConfig *config.Firestore) error {
+func runScanLocal(ctx context.Context, src *model.CodeSource, trivyConfig *config.Trivy, scannerConfig *config.Scanner, archiveConfig *config.Archive, meta model.GitHubMetadata, bigQuery *config.BigQuery, firestoreConfig *config.Firestore, advisory *config.Advisory, exploit *config.Exploitability, proxy *config.Proxy, failOn types.Severity) error {
 	// Log scan configuration
 	logging.Default().Info("Starting scan",
-		slog.String("dir", dir),
-		slog.String("trivy_path", trivyPath),
+		slog.String("source", src.String()),
+		slog.Any("trivy", trivyConfig),
+		slog.Any("scanner", scannerConfig),
+		slog.Any("archive", archiveConfig),
 		slog.String("github_owner", meta.Owner),
 		slog.String("github_repo", meta.RepoName),
 		slog.String("github_branch", meta.Branch),
 		slog.String("github_commit", meta.CommitID),
 		slog.Any("bigquery", bigQuery),
 		slog.Bool("firestore_enabled", firestoreConfig.Enabled()),
+		slog.Any("advisory", advisory),
+		slog.Any("exploitability", exploit),
+		slog.Any("proxy", proxy),
+		slog.Any("fail_on_severity", failOn),
 	)
 
+	outbound, err := proxy.Outbound()
+	if err != nil {
+		return err
+	}
+	trivyOptions, err := trivyConfig.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	scannerOptions, err := scannerConfig.UseCaseOptions(outbound.Grype...)
+	if err != nil {
+		return err
+	}
+	archiveOptions, err := archiveConfig.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	scannerOptions = append(scannerOptions, archiveOptions...)
+
 	// Create BigQuery client if configured
 	bqClient, err := bigQuery.NewClient(ctx)
 	if err != nil {
@@ -312,22 +507,55 @@ func runScanLocal(ctx context.Context, dir, trivyPath string, meta model.GitHubM
 	}
 
 	// Create clients and usecase
-	trivyClient := trivyInfra.New(trivyPath)
+	trivyClient := trivyConfig.NewClient(outbound.Trivy...)
 	clientOpts := []infra.Option{
 		infra.WithTrivy(trivyClient),
 		infra.WithBigQuery(bqClient),
 	}
+	clientOpts = append(clientOpts, outbound.Infra...)
 	if firestoreRepo != nil {
 		clientOpts = append(clientOpts, infra.WithScanRepository(firestoreRepo))
 	}
+	if src.Kind == types.SourceGCS {
+		reader, err := gcs.NewReader(ctx)
+		if err != nil {
+			return err
+		}
+		clientOpts = append(clientOpts, infra.WithObjectReader(reader))
+	}
 	clients := infra.New(clientOpts...)
 
-	uc := usecase.New(clients)
+	ucOptions, err := bigQuery.UseCaseOptions()
+	if err != nil {
+		return err
+	}
+	advisoryOptions, err := advisory.UseCaseOptions(ctx)
+	if err != nil {
+		return err
+	}
+	ucOptions = append(ucOptions, advisoryOptions...)
+	ucOptions = append(ucOptions, exploit.UseCaseOptions(outbound.Exploitability...)...)
+	ucOptions = append(ucOptions, scannerOptions...)
+	ucOptions = append(ucOptions, trivyOptions...)
+	ucOptions = append(ucOptions, usecase.WithFailOnSeverity(failOn))
+	uc := usecase.New(clients, ucOptions...)
 
-	// Scan directory and insert to BigQuery
-	if err := uc.ScanAndInsert(ctx, dir, meta); err != nil {
-		return goerr.Wrap(err, "failed to scan local directory")
+	// Scan the source and insert to BigQuery. A local scan is always performed even if the commit is already scanned.
+	if err := uc.ScanGitHubRepo(ctx, &model.ScanGitHubRepoInput{
+		GitHubMetadata: meta,
+		Source:         src,
+		Force:          true,
+	}); err != nil {
+		return goerr.Wrap(err, "failed to scan local directory", goerr.V("source", src.String()))
 	}
 
 	return nil
 }
+
+// parseFailOnSeverity parses --fail-on-severity. An empty value disables the threshold.
+func parseFailOnSeverity(s string) (types.Severity, error) {
+	if s == "" {
+		return "", nil
+	}
+	return types.ParseSeverityThreshold(s)
+}
@@ -0,0 +1,22 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseFailOnSeverity(t *testing.T) {
+	sev, err := cli.ParseFailOnSeverityForTest("")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.Severity(""))
+
+	sev, err = cli.ParseFailOnSeverityForTest("CRITICAL,HIGH")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.SeverityHigh)
+
+	_, err = cli.ParseFailOnSeverityForTest("CRITICAL,SEVERE")
+	gt.Error(t, err)
+}
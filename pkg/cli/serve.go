@@ -3,19 +3,25 @@ package cli
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gots/slice"
 	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/controller/rpc"
+	"github.com/m-mizutani/octovy/pkg/controller/scheduler"
 	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra"
-	"github.com/m-mizutani/octovy/pkg/infra/trivy"
 	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/cron"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
 
 	"github.com/urfave/cli/v3"
@@ -25,13 +31,46 @@ import (
 
 func serveCommand() *cli.Command {
 	var (
-		addr      string
-		trivyPath string
+		addr           string
+		gateMaxScanAge time.Duration
+		scanWorkers    int
+		scanQueueSize  int
+		scanWeights    string
+		shutdownTO     time.Duration
+		scanSchedule   string
+		scanOwners     []string
+		adminToken     string
+		uiUser         string
+		uiPassword     string
+		dbRefresh      time.Duration
+		agentToken     string
+		agentRepos     []string
+		agentLease     time.Duration
+		baseURL        string
+		onWorkflowRun  bool
+		workflowNames  []string
+		onRelease      bool
+		dedupeTTL      time.Duration
+		webhookMaxMB   int64
+		webhookTimeout time.Duration
+		webhookPath    string
 
+		trivy     config.Trivy
+		scanner   config.Scanner
+		archive   config.Archive
 		githubApp config.GitHubApp
 		bigQuery  config.BigQuery
 		firestore config.Firestore
 		sentry    config.Sentry
+		tracing   config.Tracing
+		advisory  config.Advisory
+		exploit   config.Exploitability
+		sla       config.SLA
+		gate      config.GatePolicy
+		auth      config.Auth
+		tasks     config.Tasks
+		grpc      config.GRPC
+		proxy     config.Proxy
 	)
 	serveFlags := []cli.Flag{
 		&cli.StringFlag{
@@ -42,11 +81,149 @@ func serveCommand() *cli.Command {
 			Destination: &addr,
 		},
 		&cli.StringFlag{
-			Name:        "trivy-path",
-			Usage:       "Path to trivy binary",
-			Value:       "trivy",
-			Sources:     cli.EnvVars("OCTOVY_TRIVY_PATH"),
-			Destination: &trivyPath,
+			Name:        "base-url",
+			Usage:       "Public URL of the server (e.g. https://octovy.example.com) to build permalinks in API responses and notifications",
+			Sources:     cli.EnvVars("OCTOVY_BASE_URL"),
+			Destination: &baseURL,
+		},
+		&cli.DurationFlag{
+			Name:        "gate-max-scan-age",
+			Usage:       "Default maximum age of the last scan for the deployment gate API (0 disables the check)",
+			Value:       24 * time.Hour,
+			Sources:     cli.EnvVars("OCTOVY_GATE_MAX_SCAN_AGE"),
+			Destination: &gateMaxScanAge,
+		},
+		&cli.IntFlag{
+			Name:        "scan-workers",
+			Usage:       "Number of concurrent background scans triggered by webhooks",
+			Value:       4,
+			Sources:     cli.EnvVars("OCTOVY_SCAN_WORKERS"),
+			Destination: &scanWorkers,
+		},
+		&cli.IntFlag{
+			Name:        "scan-queue-size",
+			Usage:       "Maximum number of pending webhook scans. Webhooks beyond this are rejected with 503",
+			Value:       100,
+			Sources:     cli.EnvVars("OCTOVY_SCAN_QUEUE_SIZE"),
+			Destination: &scanQueueSize,
+		},
+		&cli.StringFlag{
+			Name:        "scan-priority-weights",
+			Usage:       "Shares of scan workers given to high (pull requests and default branch pushes), normal and low (seeding, owner and scheduled scans) priority scans while the queue is busy, as high,normal,low",
+			Value:       "6,3,1",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_PRIORITY_WEIGHTS"),
+			Destination: &scanWeights,
+		},
+		&cli.DurationFlag{
+			Name:        "shutdown-timeout",
+			Usage:       "Time to wait for in-flight requests and background scans at shutdown. Scans not finished by then are logged and abandoned",
+			Value:       30 * time.Second,
+			Sources:     cli.EnvVars("OCTOVY_SHUTDOWN_TIMEOUT"),
+			Destination: &shutdownTO,
+		},
+		&cli.StringFlag{
+			Name:        "scan-schedule",
+			Usage:       "Cron expression (e.g. \"0 3 * * *\") to periodically scan all repositories of --scan-owner, in server local time",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_SCHEDULE"),
+			Destination: &scanSchedule,
+		},
+		&cli.StringSliceFlag{
+			Name:        "scan-owner",
+			Usage:       "GitHub owner whose repositories are scanned by --scan-schedule (can be repeated)",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_OWNER"),
+			Destination: &scanOwners,
+		},
+		&cli.BoolFlag{
+			Name:        "scan-on-workflow-run",
+			Usage:       "Scan the head commit of a workflow run completed on the default branch (workflow_run event)",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_ON_WORKFLOW_RUN"),
+			Destination: &onWorkflowRun,
+		},
+		&cli.StringSliceFlag{
+			Name:        "scan-workflow",
+			Usage:       "Name of a workflow whose runs trigger scans with --scan-on-workflow-run (can be repeated). Runs of all workflows trigger scans if not set",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_WORKFLOW"),
+			Destination: &workflowNames,
+		},
+		&cli.BoolFlag{
+			Name:        "scan-on-release",
+			Usage:       "Scan the tag of a published release (release event)",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_ON_RELEASE"),
+			Destination: &onRelease,
+		},
+		&cli.DurationFlag{
+			Name:        "webhook-dedupe-ttl",
+			Usage:       "Time to remember X-GitHub-Delivery of accepted webhooks to ignore their redeliveries (0 disables deduplication)",
+			Value:       time.Hour,
+			Sources:     cli.EnvVars("OCTOVY_WEBHOOK_DEDUPE_TTL"),
+			Destination: &dedupeTTL,
+		},
+		&cli.Int64Flag{
+			Name:        "webhook-max-body-size",
+			Usage:       "Maximum body size of webhooks in MiB. Larger webhooks are rejected with 413 (0 for no limit)",
+			Value:       server.DefaultWebhookMaxBodySize >> 20,
+			Sources:     cli.EnvVars("OCTOVY_WEBHOOK_MAX_BODY_SIZE"),
+			Destination: &webhookMaxMB,
+		},
+		&cli.DurationFlag{
+			Name:        "webhook-timeout",
+			Usage:       "Time to validate and enqueue a webhook before responding 503. Scans are not limited by it (0 for no limit)",
+			Value:       server.DefaultWebhookTimeout,
+			Sources:     cli.EnvVars("OCTOVY_WEBHOOK_TIMEOUT"),
+			Destination: &webhookTimeout,
+		},
+		&cli.StringFlag{
+			Name:        "webhook-path",
+			Usage:       "Path of webhooks of GitHub Apps. Webhooks of an app of --github-apps-file can also be sent to the path followed by /{app_id}",
+			Value:       server.DefaultWebhookPath,
+			Sources:     cli.EnvVars("OCTOVY_WEBHOOK_PATH"),
+			Destination: &webhookPath,
+		},
+		&cli.StringFlag{
+			Name:        "api-admin-token",
+			Usage:       "Bearer token required by API endpoints which modify data. The endpoints are disabled if not set",
+			Sources:     cli.EnvVars("OCTOVY_API_ADMIN_TOKEN"),
+			Destination: &adminToken,
+		},
+		&cli.StringFlag{
+			Name:        "ui-basic-auth-user",
+			Usage:       "User name of HTTP basic authentication for the dashboard and read API",
+			Value:       "octovy",
+			Sources:     cli.EnvVars("OCTOVY_UI_BASIC_AUTH_USER"),
+			Destination: &uiUser,
+		},
+		&cli.StringFlag{
+			Name:        "ui-basic-auth-password",
+			Usage:       "Password of HTTP basic authentication for the dashboard and read API. They are public if neither this nor other authentication methods are set",
+			Sources:     cli.EnvVars("OCTOVY_UI_BASIC_AUTH_PASSWORD"),
+			Destination: &uiPassword,
+		},
+		&cli.StringFlag{
+			Name:        "scan-agent-token",
+			Usage:       "Bearer token for scan agents. The agent API is disabled if not set",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_AGENT_TOKEN"),
+			Destination: &agentToken,
+		},
+		&cli.StringSliceFlag{
+			Name:        "scan-agent-repo",
+			Usage:       "GitHub owner or owner/repo whose webhook scans are delegated to scan agents (can be repeated)",
+			Sources:     cli.EnvVars("OCTOVY_SCAN_AGENT_REPO"),
+			Destination: &agentRepos,
+		},
+		&cli.DurationFlag{
+			Name:        "scan-agent-lease",
+			Usage:       "Time for a scan agent to report the result of a claimed job before the scan is recorded as failed",
+			Value:       30 * time.Minute,
+			Sources:     cli.EnvVars("OCTOVY_SCAN_AGENT_LEASE"),
+			Destination: &agentLease,
+		},
+		&cli.DurationFlag{
+			Name:        "trivy-db-refresh-interval",
+			Usage:       "Interval to refresh the Trivy vulnerability DB downloaded at startup (0 disables the refresh)",
+			Category:    "Trivy",
+			Value:       6 * time.Hour,
+			Sources:     cli.EnvVars("OCTOVY_TRIVY_DB_REFRESH_INTERVAL"),
+			Destination: &dbRefresh,
 		},
 	}
 
@@ -56,34 +233,164 @@ func serveCommand() *cli.Command {
 		Usage:   "Server mode",
 		Flags: slice.Flatten(
 			serveFlags,
+			scanner.Flags(),
+			archive.Flags(),
+			trivy.Flags(),
 			githubApp.Flags(),
 			bigQuery.Flags(),
 			firestore.Flags(),
 			sentry.Flags(),
+			tracing.Flags(),
+			advisory.Flags(),
+			exploit.Flags(),
+			sla.Flags(),
+			gate.Flags(),
+			auth.Flags(),
+			tasks.Flags(),
+			grpc.Flags(),
+			proxy.Flags(),
 		),
 		Action: func(ctx context.Context, c *cli.Command) error {
 			logging.Default().Info("starting serve",
 				slog.Any("Addr", addr),
-				slog.Any("TrivyPath", trivyPath),
+				slog.String("BaseURL", baseURL),
+				slog.Duration("GateMaxScanAge", gateMaxScanAge),
+				slog.Int("ScanWorkers", scanWorkers),
+				slog.Int("ScanQueueSize", scanQueueSize),
+				slog.String("ScanPriorityWeights", scanWeights),
+				slog.Duration("ShutdownTimeout", shutdownTO),
+				slog.String("ScanSchedule", scanSchedule),
+				slog.Any("ScanOwners", scanOwners),
+				slog.Bool("ScanOnWorkflowRun", onWorkflowRun),
+				slog.Any("ScanWorkflows", workflowNames),
+				slog.Bool("ScanOnRelease", onRelease),
+				slog.Duration("WebhookDedupeTTL", dedupeTTL),
+				slog.Int64("WebhookMaxBodySizeMB", webhookMaxMB),
+				slog.Duration("WebhookTimeout", webhookTimeout),
+				slog.String("WebhookPath", webhookPath),
+				slog.Bool("AdminAPIEnabled", adminToken != ""),
+				slog.Bool("UIAuthEnabled", uiPassword != ""),
+				slog.Bool("ScanAgentAPIEnabled", agentToken != ""),
+				slog.Any("ScanAgentRepos", agentRepos),
+				slog.Duration("ScanAgentLease", agentLease),
+				slog.Duration("TrivyDBRefreshInterval", dbRefresh),
+				slog.Any("Trivy", &trivy),
+				slog.Any("Scanner", &scanner),
+				slog.Any("Archive", &archive),
 				slog.Any("GitHubApp", githubApp),
 				slog.Any("BigQuery", bigQuery),
 				slog.Any("Firestore", firestore),
 				slog.Any("Sentry", sentry),
+				slog.Any("Tracing", &tracing),
+				slog.Any("Advisory", &advisory),
+				slog.Any("Exploitability", &exploit),
+				slog.Any("SLA", &sla),
+				slog.Any("GatePolicy", &gate),
+				slog.Any("Auth", &auth),
+				slog.Any("Tasks", &tasks),
+				slog.Any("GRPC", &grpc),
+				slog.Any("Proxy", &proxy),
 			)
 
+			schedule, err := parseScanSchedule(scanSchedule, scanOwners)
+			if err != nil {
+				return err
+			}
+			weights, err := parseScanPriorityWeights(scanWeights)
+			if err != nil {
+				return err
+			}
+			if webhookMaxMB < 0 || webhookTimeout < 0 {
+				return goerr.Wrap(types.ErrInvalidOption, "--webhook-max-body-size and --webhook-timeout must not be negative",
+					goerr.V("webhook-max-body-size", webhookMaxMB),
+					goerr.V("webhook-timeout", webhookTimeout),
+				)
+			}
+			if !strings.HasPrefix(webhookPath, "/") || strings.HasSuffix(webhookPath, "/") {
+				return goerr.Wrap(types.ErrInvalidOption, "--webhook-path must start with / and must not end with /", goerr.V("webhook-path", webhookPath))
+			}
+			if len(agentRepos) > 0 && agentToken == "" {
+				return goerr.Wrap(types.ErrInvalidOption, "--scan-agent-token is required to delegate scans by --scan-agent-repo",
+					goerr.V("scan-agent-repo", agentRepos),
+				)
+			}
+
 			if err := sentry.Configure(ctx); err != nil {
 				return err
 			}
+			shutdownTracing, err := tracing.Configure(ctx)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				// Spans of scans drained at shutdown are flushed after the server stops
+				ctx, cancel := context.WithTimeout(context.Background(), shutdownTO)
+				defer cancel()
+				if err := shutdownTracing(ctx); err != nil {
+					logging.Default().Warn("failed to flush trace spans", slog.Any("error", err))
+				}
+			}()
+
+			outbound, err := proxy.Outbound()
+			if err != nil {
+				return err
+			}
 
-			ghApp, err := githubApp.New()
+			trivyOptions, err := trivy.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			scannerOptions, err := scanner.UseCaseOptions(outbound.Grype...)
+			if err != nil {
+				return err
+			}
+			archiveOptions, err := archive.UseCaseOptions()
 			if err != nil {
 				return err
 			}
+			scannerOptions = append(scannerOptions, archiveOptions...)
+			if githubApp.MisconfigCheckRun() && (!scanner.UsesTrivy() || !trivy.ScansMisconfigurations()) {
+				return goerr.Wrap(types.ErrInvalidOption, "--github-misconfig-check-run requires Trivy with --trivy-scanners misconfig")
+			}
+
+			authOptions, err := auth.ServerOptions(ctx, baseURL)
+			if err != nil {
+				return err
+			}
+			taskOptions, err := tasks.ServerOptions(ctx)
+			if err != nil {
+				return err
+			}
+			var rpcOptions []rpc.Option
+			if grpc.Enabled() {
+				if rpcOptions, err = grpc.ServerOptions(); err != nil {
+					return err
+				}
+			}
+
+			ghApp, err := githubApp.NewApps(outbound.GitHubApp...)
+			if err != nil {
+				return err
+			}
+			webhookSecrets, err := githubApp.WebhookSecrets()
+			if err != nil {
+				return err
+			}
+
+			trivyClient := trivy.NewClient(outbound.Trivy...)
+			dbCtx, stopDBRefresh := context.WithCancel(ctx)
+			defer stopDBRefresh()
+			if scanner.UsesTrivy() {
+				if err := prepareTrivyDB(dbCtx, trivyClient, dbRefresh); err != nil {
+					return err
+				}
+			}
 
 			infraOptions := []infra.Option{
 				infra.WithGitHubApp(ghApp),
-				infra.WithTrivy(trivy.New(trivyPath)),
+				infra.WithTrivy(trivyClient),
 			}
+			infraOptions = append(infraOptions, outbound.Infra...)
 
 			bqClient, err := bigQuery.NewClient(ctx)
 			if err != nil {
@@ -100,12 +407,85 @@ func serveCommand() *cli.Command {
 					return goerr.Wrap(err, "failed to create Firestore repository")
 				}
 				infraOptions = append(infraOptions, infra.WithScanRepository(repo))
+
+				lock, err := firestore.NewScanLock(ctx)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create scan lock")
+				}
+				if lock != nil {
+					infraOptions = append(infraOptions, infra.WithScanLock(lock))
+				}
 			}
 
 			clients := infra.New(infraOptions...)
 
-			uc := usecase.New(clients)
-			s := server.New(uc, server.WithGitHubSecret(githubApp.Secret()))
+			ucOptions, err := bigQuery.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, scannerOptions...)
+			ucOptions = append(ucOptions, trivyOptions...)
+			ucOptions = append(ucOptions, usecase.WithPermalinkBaseURL(baseURL))
+			githubAppOptions, err := githubApp.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, githubAppOptions...)
+			fetcherOptions, err := githubApp.FetcherOptions(ghApp, outbound.GitClone...)
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, fetcherOptions...)
+			upgraderOptions, err := githubApp.UpgraderOptions(outbound.Upgrader...)
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, upgraderOptions...)
+			advisoryOptions, err := advisory.UseCaseOptions(ctx)
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, advisoryOptions...)
+			ucOptions = append(ucOptions, exploit.UseCaseOptions(outbound.Exploitability...)...)
+			slaOptions, err := sla.UseCaseOptions()
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, slaOptions...)
+			gateOptions, err := gate.UseCaseOptions(ctx, outbound.GatePolicy...)
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, gateOptions...)
+			uc := usecase.New(clients, ucOptions...)
+			serverOptions := []server.Option{
+				server.WithGitHubSecret(githubApp.Secret()),
+				server.WithGitHubAppSecrets(webhookSecrets),
+				server.WithWebhookPath(webhookPath),
+				server.WithGateMaxScanAge(gateMaxScanAge),
+				server.WithScanQueue(scanWorkers, scanQueueSize),
+				server.WithScanPriorityWeights(weights),
+				server.WithWebhookDedupe(dedupeTTL),
+				server.WithWebhookLimits(webhookMaxMB<<20, webhookTimeout),
+				server.WithAdminToken(adminToken),
+				server.WithUIBasicAuth(uiUser, uiPassword),
+				server.WithScanAgents(agentToken, agentRepos, agentLease),
+			}
+			if onWorkflowRun {
+				serverOptions = append(serverOptions, server.WithWorkflowRunTrigger(workflowNames))
+			}
+			if onRelease {
+				serverOptions = append(serverOptions, server.WithReleaseTrigger())
+			}
+			serverOptions = append(serverOptions, authOptions...)
+			serverOptions = append(serverOptions, taskOptions...)
+			s := server.New(uc, serverOptions...)
+
+			var sched *scheduler.Scheduler
+			if schedule != nil {
+				sched = scheduler.New(uc, schedule, scanOwners)
+				sched.Start()
+			}
 
 			serverErr := make(chan error, 1)
 			httpServer := &http.Server{
@@ -124,6 +504,21 @@ func serveCommand() *cli.Command {
 				}
 			}()
 
+			var rpcServer *rpc.Server
+			if grpc.Enabled() {
+				lis, err := net.Listen("tcp", grpc.Addr())
+				if err != nil {
+					return goerr.Wrap(err, "failed to listen gRPC address", goerr.V("addr", grpc.Addr()))
+				}
+				rpcServer = rpc.New(uc, s, rpcOptions...)
+				go func() {
+					logging.Default().Info("starting grpc server", "addr", grpc.Addr())
+					if err := rpcServer.Serve(lis); err != nil {
+						serverErr <- goerr.Wrap(err, "failed to serve gRPC")
+					}
+				}()
+			}
+
 			quit := make(chan os.Signal, 1)
 			signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -134,11 +529,23 @@ func serveCommand() *cli.Command {
 			case sig := <-quit:
 				logging.Default().Info("shutting down server", "signal", sig)
 
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				ctx, cancel := context.WithTimeout(context.Background(), shutdownTO)
 				defer cancel()
 
+				// Background scans are drained even if some requests are not finished, to log abandoned scans
 				if err := httpServer.Shutdown(ctx); err != nil {
-					return goerr.Wrap(err, "failed to shutdown server")
+					logging.Default().Warn("failed to shutdown http server gracefully", slog.Any("error", err))
+				}
+				if rpcServer != nil {
+					rpcServer.Shutdown(ctx)
+				}
+				if err := s.Shutdown(ctx); err != nil {
+					return goerr.Wrap(err, "failed to drain background scans")
+				}
+				if sched != nil {
+					if err := sched.Shutdown(ctx); err != nil {
+						return goerr.Wrap(err, "failed to stop scan scheduler")
+					}
 				}
 			}
 
@@ -146,3 +553,36 @@ func serveCommand() *cli.Command {
 		},
 	}
 }
+
+// parseScanSchedule returns nil if scheduled scanning is not configured. The schedule and owners must be set together.
+func parseScanSchedule(expr string, owners []string) (*cron.Schedule, error) {
+	if expr == "" && len(owners) == 0 {
+		return nil, nil
+	}
+	if expr == "" || len(owners) == 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "--scan-schedule and --scan-owner must be set together",
+			goerr.V("scan-schedule", expr),
+			goerr.V("scan-owner", owners),
+		)
+	}
+	return cron.Parse(expr)
+}
+
+// parseScanPriorityWeights parses weights of high, normal and low priority scans separated by commas. Each weight must be a positive integer.
+func parseScanPriorityWeights(s string) (server.ScanPriorityWeights, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return server.ScanPriorityWeights{}, goerr.Wrap(types.ErrInvalidOption, "--scan-priority-weights must be three weights of high, normal and low priority", goerr.V("scan-priority-weights", s))
+	}
+
+	var weights [3]int
+	for i, part := range parts {
+		w, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || w <= 0 {
+			return server.ScanPriorityWeights{}, goerr.Wrap(types.ErrInvalidOption, "weight of --scan-priority-weights must be a positive integer", goerr.V("scan-priority-weights", s))
+		}
+		weights[i] = w
+	}
+
+	return server.ScanPriorityWeights{High: weights[0], Normal: weights[1], Low: weights[2]}, nil
+}
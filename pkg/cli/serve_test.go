@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseScanSchedule(t *testing.T) {
+	schedule, err := cli.ParseScanScheduleForTest("", nil)
+	gt.NoError(t, err)
+	gt.Nil(t, schedule)
+
+	schedule, err = cli.ParseScanScheduleForTest("0 3 * * *", []string{"octo-org"})
+	gt.NoError(t, err)
+	gt.NotNil(t, schedule)
+
+	_, err = cli.ParseScanScheduleForTest("0 3 * * *", nil)
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+
+	_, err = cli.ParseScanScheduleForTest("", []string{"octo-org"})
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+
+	_, err = cli.ParseScanScheduleForTest("0 25 * * *", []string{"octo-org"})
+	gt.Error(t, err)
+}
+
+func TestParseScanPriorityWeights(t *testing.T) {
+	weights, err := cli.ParseScanPriorityWeightsForTest("6,3,1")
+	gt.NoError(t, err)
+	gt.V(t, weights).Equal(server.ScanPriorityWeights{High: 6, Normal: 3, Low: 1})
+
+	weights, err = cli.ParseScanPriorityWeightsForTest(" 10, 2 ,1")
+	gt.NoError(t, err)
+	gt.V(t, weights).Equal(server.ScanPriorityWeights{High: 10, Normal: 2, Low: 1})
+
+	for _, s := range []string{"", "6,3", "6,3,1,1", "6,0,1", "6,-1,1", "high,3,1"} {
+		_, err := cli.ParseScanPriorityWeightsForTest(s)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	}
+}
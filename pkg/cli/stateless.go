@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+// runScanRemoteStateless scans a repository without BigQuery and Firestore, prints the
+// findings and returns an error if any finding is detected so that the exit code is non-zero.
+// With --fail-on-severity, only findings at or above the threshold cause the error.
+func runScanRemoteStateless(ctx context.Context, params *scanRemoteParams, clients *infra.Clients, scannerOptions []usecase.Option, w io.Writer) error {
+	if params.repo == "" {
+		return goerr.Wrap(types.ErrInvalidOption, "--github-repo is required in stateless mode")
+	}
+
+	uc := usecase.New(clients, scannerOptions...)
+
+	report, err := uc.ScanGitHubRepoStateless(ctx, &model.ScanGitHubRepoRemoteInput{
+		Owner:     params.owner,
+		Repo:      params.repo,
+		Commit:    params.commit,
+		Branch:    params.branch,
+		Tag:       params.tag,
+		InstallID: types.GitHubAppInstallID(params.installIDRaw),
+	})
+	if err != nil {
+		return goerr.Wrap(err, "failed to scan GitHub repository")
+	}
+
+	count, err := printScanReport(w, report)
+	if err != nil {
+		return err
+	}
+	if params.failOn != "" {
+		if exceeded := model.CountFindingsAtOrAbove(report, params.failOn); exceeded > 0 {
+			return goerr.New("findings at or above severity threshold detected",
+				goerr.V("threshold", params.failOn),
+				goerr.V("count", exceeded),
+			)
+		}
+		return nil
+	}
+	if count > 0 {
+		return goerr.New("findings detected", goerr.V("count", count))
+	}
+
+	return nil
+}
+
+// printScanReport writes findings of the report as a table and returns the number of findings
+func printScanReport(w io.Writer, report *trivy.Report) (int, error) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	var count int
+	for _, result := range report.Results {
+		findings := len(result.Vulnerabilities) + len(result.Secrets)
+		for _, misconf := range result.Misconfigurations {
+			if misconf.Status == "" || misconf.Status == trivy.MisconfStatusFailure {
+				findings++
+			}
+		}
+		if findings == 0 {
+			continue
+		}
+		count += findings
+
+		fmt.Fprintf(tw, "\n%s (%s)\n", result.Target, result.Type)
+		fmt.Fprintln(tw, "TYPE\tID\tSEVERITY\tPACKAGE\tINSTALLED\tFIXED\tTITLE")
+		for _, v := range result.Vulnerabilities {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				types.FindingTypeVulnerability, v.VulnerabilityID, v.Severity, v.PkgName, v.InstalledVersion, v.FixedVersion, v.Title)
+		}
+		for _, m := range result.Misconfigurations {
+			if m.Status != "" && m.Status != trivy.MisconfStatusFailure {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\t\t\t%s\n", types.FindingTypeMisconfiguration, m.AVDID, m.Severity, m.Title)
+		}
+		for _, sec := range result.Secrets {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\t\t\t%s (line %d)\n", types.FindingTypeSecret, sec.RuleID, sec.Severity, sec.Title, sec.StartLine)
+		}
+	}
+	fmt.Fprintf(tw, "\nTotal findings: %d\n", count)
+
+	if err := tw.Flush(); err != nil {
+		return 0, goerr.Wrap(err, "failed to write scan result")
+	}
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/cli"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestPrintScanReport(t *testing.T) {
+	t.Run("prints findings of each target", func(t *testing.T) {
+		report := &trivy.Report{
+			Results: []trivy.Result{
+				{
+					Target: "go.mod",
+					Type:   "gomod",
+					Vulnerabilities: []trivy.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2024-0001",
+							PkgName:          "golang.org/x/net",
+							InstalledVersion: "0.1.0",
+							FixedVersion:     "0.2.0",
+							Vulnerability:    trivy.Vulnerability{Severity: "HIGH", Title: "HTTP/2 rapid reset"},
+						},
+					},
+				},
+				{
+					Target: "Dockerfile",
+					Type:   "dockerfile",
+					Misconfigurations: []trivy.DetectedMisconfiguration{
+						{AVDID: "AVD-DS-0002", Severity: "HIGH", Title: "root user", Status: trivy.MisconfStatusFailure},
+						{AVDID: "AVD-DS-0001", Severity: "MEDIUM", Title: "latest tag", Status: trivy.MisconfStatusPassed},
+					},
+				},
+				{
+					Target: "README.md",
+					Type:   "",
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		count, err := cli.PrintScanReportForTest(&buf, report)
+		gt.NoError(t, err)
+		gt.V(t, count).Equal(2)
+
+		out := buf.String()
+		gt.S(t, out).Contains("go.mod (gomod)")
+		gt.S(t, out).Contains("CVE-2024-0001")
+		gt.S(t, out).Contains("AVD-DS-0002")
+		gt.S(t, out).NotContains("AVD-DS-0001")
+		gt.S(t, out).NotContains("README.md")
+		gt.S(t, out).Contains("Total findings: 2")
+	})
+
+	t.Run("no findings", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := cli.PrintScanReportForTest(&buf, &trivy.Report{})
+		gt.NoError(t, err)
+		gt.V(t, count).Equal(0)
+		gt.S(t, buf.String()).Contains("Total findings: 0")
+	})
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func syncAlertsCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		githubApp config.GitHubApp
+		proxy     config.Proxy
+		input     model.SyncCodeScanningAlertsInput
+	)
+
+	return &cli.Command{
+		Name:  "sync-alerts",
+		Usage: "Reflect code scanning alerts dismissed in GitHub as acknowledged vulnerabilities",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "github-owner",
+				Usage:       "GitHub repository owner (required)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_OWNER"),
+				Destination: &input.Owner,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "github-repo",
+				Usage:       "GitHub repository name (optional; if not specified, syncs all repositories for the owner)",
+				Sources:     cli.EnvVars("OCTOVY_GITHUB_REPO"),
+				Destination: &input.Repo,
+			},
+		}, firestore.Flags(), githubApp.Flags(), proxy.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			logging.Default().Info("Starting code scanning alert sync",
+				slog.String("github_owner", input.Owner),
+				slog.String("github_repo", input.Repo),
+				slog.Any("github_app", &githubApp),
+				slog.Any("firestore", &firestore),
+				slog.Any("proxy", &proxy),
+			)
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			outbound, err := proxy.Outbound()
+			if err != nil {
+				return err
+			}
+			ghClient, err := githubApp.New(outbound.GitHubApp...)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create GitHub App client")
+			}
+
+			repo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(
+				infra.WithGitHubApp(ghClient),
+				infra.WithScanRepository(repo),
+			))
+
+			if err := uc.SyncCodeScanningAlerts(ctx, &input); err != nil {
+				return goerr.Wrap(err, "failed to sync code scanning alerts")
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// prepareTrivyDB checks the version of Trivy, downloads the vulnerability DB once so that each scan skips the DB update, and starts refreshing it in background until ctx is cancelled. If the download fails, scans fall back to updating the DB by themselves. It returns an error only if Trivy is older than the enforced minimum version, so that the process does not start only to fail every scan.
+func prepareTrivyDB(ctx context.Context, client trivy.Client, refreshInterval time.Duration) error {
+	if _, err := client.CheckVersion(ctx); err != nil {
+		if errors.Is(err, types.ErrIncompatibleTrivy) {
+			return err
+		}
+		logging.From(ctx).Error("failed to check Trivy version at startup, each scan checks it instead", "error", err)
+	}
+
+	if err := client.DownloadDB(ctx); err != nil {
+		logging.From(ctx).Error("failed to download Trivy DB at startup, each scan updates the DB instead", "error", err)
+	}
+
+	if refreshInterval > 0 {
+		go trivy.RefreshDB(ctx, client, refreshInterval)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gots/slice"
+	"github.com/m-mizutani/octovy/pkg/cli/config"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/urfave/cli/v3"
+)
+
+func vulnCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "vuln",
+		Usage: "Triage vulnerabilities stored in Firestore",
+		Commands: []*cli.Command{
+			vulnSetStatusCommand(),
+		},
+	}
+}
+
+func vulnSetStatusCommand() *cli.Command {
+	var (
+		firestore config.Firestore
+		input     model.SetVulnerabilityStatusInput
+		repo      string
+		branch    string
+		status    string
+		expiresIn string
+	)
+
+	return &cli.Command{
+		Name:  "set-status",
+		Usage: "Change the status of a vulnerability manually",
+		Flags: slice.Flatten([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "repo",
+				Usage:       "GitHub repository as owner/name (required)",
+				Destination: &repo,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "branch",
+				Usage:       "Branch name (default: default branch of the repository)",
+				Destination: &branch,
+			},
+			&cli.StringFlag{
+				Name:        "target",
+				Usage:       "Scan target, e.g. go.mod (default: all targets where the vulnerability is found)",
+				Destination: &input.Target,
+			},
+			&cli.StringFlag{
+				Name:        "id",
+				Usage:       "Vulnerability ID, e.g. CVE-2024-0001 (required)",
+				Destination: &input.VulnID,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "status",
+				Usage:       "New status: active, acknowledged or fixed (required)",
+				Destination: &status,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "reason",
+				Usage:       "Why the status is changed (required)",
+				Destination: &input.Reason,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "by",
+				Usage:       "Who changes the status (required)",
+				Sources:     cli.EnvVars("OCTOVY_ACKNOWLEDGE_BY"),
+				Destination: &input.By,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "expires-in",
+				Usage:       "Period until an acknowledged vulnerability becomes active again, in days (e.g. 90d) or as Go duration (default: no expiration)",
+				Destination: &expiresIn,
+			},
+		}, firestore.Flags()),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			repoID := types.GitHubRepoID(repo)
+			if err := repoID.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid --repo, it must be owner/name")
+			}
+			input.Owner, input.Repo = repoID.Split()
+			input.Branch = types.NewBranchName(branch)
+
+			parsed, err := types.ParseVulnStatus(status)
+			if err != nil {
+				return goerr.Wrap(err, "invalid --status")
+			}
+			input.Status = parsed
+
+			if expiresIn != "" {
+				period, err := parseRetention(expiresIn)
+				if err != nil {
+					return goerr.Wrap(err, "invalid --expires-in")
+				}
+				input.ExpiresAt = logging.CtxTime(ctx).Add(period)
+			}
+
+			if !firestore.Enabled() {
+				return goerr.New("Firestore is required (--firestore-project-id or --db must be set)")
+			}
+
+			scanRepo, err := firestore.NewRepository(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create Firestore repository")
+			}
+
+			uc := usecase.New(infra.New(infra.WithScanRepository(scanRepo)))
+
+			updated, err := uc.SetVulnerabilityStatus(ctx, &input)
+			if err != nil {
+				return goerr.Wrap(err, "failed to set vulnerability status")
+			}
+
+			logging.Default().Info("Completed vulnerability status change",
+				slog.String("repo", repo),
+				slog.String("vuln_id", input.VulnID),
+				slog.Any("status", input.Status),
+				slog.Int("updated", updated),
+			)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,200 @@
+// Package agent runs scans delegated by an octovy server. An agent polls the server for jobs, downloads and scans the source code in its own network and posts only the report back, so that source code of sensitive repositories never leaves the network of the owning team.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+type Agent struct {
+	uc        interfaces.UseCase
+	serverURL string
+	token     string
+	name      string
+	interval  time.Duration
+	client    *http.Client
+}
+
+type Option func(*Agent)
+
+// WithPollInterval sets the wait before polling again when the server has no job or is unreachable
+func WithPollInterval(d time.Duration) Option {
+	return func(x *Agent) {
+		x.interval = d
+	}
+}
+
+// WithHTTPClient sets the HTTP client to call the server API
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Agent) {
+		x.client = client
+	}
+}
+
+// New creates an agent which claims jobs from the octovy server at serverURL as name, authenticated by token
+func New(uc interfaces.UseCase, serverURL, token, name string, options ...Option) (*Agent, error) {
+	if _, err := url.Parse(serverURL); err != nil || serverURL == "" {
+		return nil, goerr.New("invalid server URL", goerr.V("server_url", serverURL))
+	}
+	if token == "" {
+		return nil, goerr.New("agent token is required")
+	}
+	if name == "" {
+		return nil, goerr.New("agent name is required")
+	}
+
+	x := &Agent{
+		uc:        uc,
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		token:     token,
+		name:      name,
+		interval:  defaultPollInterval,
+		client:    http.DefaultClient,
+	}
+	for _, opt := range options {
+		opt(x)
+	}
+	return x, nil
+}
+
+// Run polls the server and processes jobs until ctx is cancelled. Jobs are processed one by one, and the next job is claimed without waiting while the server has jobs.
+func (x *Agent) Run(ctx context.Context) error {
+	logging.From(ctx).Info("scan agent started", slog.String("server_url", x.serverURL), slog.String("agent", x.name))
+
+	for {
+		processed, err := x.RunOnce(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			errutil.HandleError(ctx, "fail to process agent scan job", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(x.interval):
+		}
+	}
+}
+
+// RunOnce claims a job and processes it. It returns false if the server has no job. A failed scan is reported to the server, and only a failure to talk to the server is returned.
+func (x *Agent) RunOnce(ctx context.Context) (bool, error) {
+	job, err := x.claim(ctx)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	logger := logging.From(ctx).With(
+		slog.String("job_id", job.ID),
+		slog.String("owner", job.Metadata.Owner),
+		slog.String("repo", job.Metadata.RepoName),
+		slog.String("commit", job.Metadata.CommitID),
+	)
+	logger.Info("agent scan job claimed")
+
+	result, err := x.uc.ScanAgentJob(ctx, job)
+	if err != nil {
+		logger.Error("agent scan failed", slog.Any("error", err))
+		result = &model.AgentScanResult{Error: err.Error()}
+	}
+
+	if err := x.report(ctx, job.ID, result); err != nil {
+		return true, err
+	}
+	logger.Info("agent scan job reported", slog.Bool("failed", result.Report == nil))
+	return true, nil
+}
+
+func (x *Agent) claim(ctx context.Context) (*model.AgentScanJob, error) {
+	body, err := json.Marshal(map[string]string{"agent": x.name})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to encode claim request")
+	}
+
+	resp, err := x.post(ctx, "/api/v1/agent/jobs/claim", body)
+	if err != nil {
+		return nil, err
+	}
+	defer safe.Close(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, responseError("failed to claim agent scan job", resp)
+	}
+
+	var claimed struct {
+		Job *model.AgentScanJob `json:"job"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&claimed); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode claimed job")
+	}
+	if claimed.Job == nil {
+		return nil, goerr.New("server returned no job")
+	}
+	return claimed.Job, nil
+}
+
+func (x *Agent) report(ctx context.Context, jobID string, result *model.AgentScanResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return goerr.Wrap(err, "failed to encode scan result", goerr.V("job_id", jobID))
+	}
+
+	resp, err := x.post(ctx, "/api/v1/agent/jobs/"+url.PathEscape(jobID)+"/result", body)
+	if err != nil {
+		return err
+	}
+	defer safe.Close(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError("failed to report scan result", resp, goerr.V("job_id", jobID))
+	}
+	return nil
+}
+
+func (x *Agent) post(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.serverURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create request", goerr.V("path", path))
+	}
+	req.Header.Set("Authorization", "Bearer "+x.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to call octovy server", goerr.V("path", path))
+	}
+	return resp, nil
+}
+
+func responseError(msg string, resp *http.Response, options ...goerr.Option) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	options = append(options, goerr.V("status", resp.StatusCode), goerr.V("body", string(body)))
+	return goerr.New(msg, options...)
+}
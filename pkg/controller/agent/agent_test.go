@@ -0,0 +1,96 @@
+package agent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/agent"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestAgentRunOnce(t *testing.T) {
+	newServer := func(t *testing.T, jobs []*model.AgentScanJob, results map[string]*model.AgentScanResult) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/v1/agent/jobs/claim", func(w http.ResponseWriter, r *http.Request) {
+			gt.V(t, r.Header.Get("Authorization")).Equal("Bearer agent-token")
+			var req struct {
+				Agent string `json:"agent"`
+			}
+			gt.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			gt.V(t, req.Agent).Equal("team-a")
+
+			if len(jobs) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			job := jobs[0]
+			jobs = jobs[1:]
+			gt.NoError(t, json.NewEncoder(w).Encode(map[string]any{"job": job}))
+		})
+		mux.HandleFunc("POST /api/v1/agent/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+			var result model.AgentScanResult
+			gt.NoError(t, json.NewDecoder(r.Body).Decode(&result))
+			results[r.PathValue("id")] = &result
+			w.WriteHeader(http.StatusOK)
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	t.Run("scan result and failure are reported", func(t *testing.T) {
+		results := map[string]*model.AgentScanResult{}
+		srv := newServer(t, []*model.AgentScanJob{
+			{ID: "job-1", Metadata: model.GitHubMetadata{GitHubCommit: model.GitHubCommit{GitHubRepo: model.GitHubRepo{Owner: "org", RepoName: "ok"}}}},
+			{ID: "job-2", Metadata: model.GitHubMetadata{GitHubCommit: model.GitHubCommit{GitHubRepo: model.GitHubRepo{Owner: "org", RepoName: "broken"}}}},
+		}, results)
+
+		mockUC := &mock.UseCaseMock{
+			ScanAgentJobFunc: func(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error) {
+				if job.Metadata.RepoName == "broken" {
+					return nil, errors.New("trivy failed")
+				}
+				return &model.AgentScanResult{
+					Report:   &trivy.Report{SchemaVersion: 2},
+					Coverage: &model.ScanCoverage{Manifests: 2, ScannedManifests: 1, Unscanned: []string{"yarn.lock"}},
+				}, nil
+			},
+		}
+		a := gt.R1(agent.New(mockUC, srv.URL+"/", "agent-token", "team-a")).NoError(t)
+		ctx := context.Background()
+
+		gt.True(t, gt.R1(a.RunOnce(ctx)).NoError(t))
+		gt.True(t, gt.R1(a.RunOnce(ctx)).NoError(t))
+		gt.False(t, gt.R1(a.RunOnce(ctx)).NoError(t))
+
+		gt.A(t, mockUC.ScanAgentJobCalls()).Length(2)
+		gt.V(t, results["job-1"].Report.SchemaVersion).Equal(2)
+		gt.V(t, results["job-1"].Error).Equal("")
+		gt.A(t, results["job-1"].Coverage.Unscanned).Equal([]string{"yarn.lock"})
+		gt.V(t, results["job-2"].Report).Nil()
+		gt.S(t, results["job-2"].Error).Contains("trivy failed")
+	})
+
+	t.Run("rejected token is returned as error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		t.Cleanup(srv.Close)
+
+		a := gt.R1(agent.New(&mock.UseCaseMock{}, srv.URL, "wrong", "team-a")).NoError(t)
+		_, err := a.RunOnce(context.Background())
+		gt.Error(t, err)
+	})
+
+	t.Run("token and name are required", func(t *testing.T) {
+		gt.R1(agent.New(&mock.UseCaseMock{}, "http://localhost", "", "team-a")).Error(t)
+		gt.R1(agent.New(&mock.UseCaseMock{}, "http://localhost", "token", "")).Error(t)
+	})
+}
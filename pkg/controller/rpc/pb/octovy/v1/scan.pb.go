@@ -0,0 +1,890 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: octovy/v1/scan.proto
+
+package octovyv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ScanRunStatus int32
+
+const (
+	ScanRunStatus_SCAN_RUN_STATUS_UNSPECIFIED ScanRunStatus = 0
+	ScanRunStatus_SCAN_RUN_STATUS_QUEUED      ScanRunStatus = 1
+	ScanRunStatus_SCAN_RUN_STATUS_RUNNING     ScanRunStatus = 2
+	ScanRunStatus_SCAN_RUN_STATUS_SUCCEEDED   ScanRunStatus = 3
+	ScanRunStatus_SCAN_RUN_STATUS_FAILED      ScanRunStatus = 4
+)
+
+// Enum value maps for ScanRunStatus.
+var (
+	ScanRunStatus_name = map[int32]string{
+		0: "SCAN_RUN_STATUS_UNSPECIFIED",
+		1: "SCAN_RUN_STATUS_QUEUED",
+		2: "SCAN_RUN_STATUS_RUNNING",
+		3: "SCAN_RUN_STATUS_SUCCEEDED",
+		4: "SCAN_RUN_STATUS_FAILED",
+	}
+	ScanRunStatus_value = map[string]int32{
+		"SCAN_RUN_STATUS_UNSPECIFIED": 0,
+		"SCAN_RUN_STATUS_QUEUED":      1,
+		"SCAN_RUN_STATUS_RUNNING":     2,
+		"SCAN_RUN_STATUS_SUCCEEDED":   3,
+		"SCAN_RUN_STATUS_FAILED":      4,
+	}
+)
+
+func (x ScanRunStatus) Enum() *ScanRunStatus {
+	p := new(ScanRunStatus)
+	*p = x
+	return p
+}
+
+func (x ScanRunStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ScanRunStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_octovy_v1_scan_proto_enumTypes[0].Descriptor()
+}
+
+func (ScanRunStatus) Type() protoreflect.EnumType {
+	return &file_octovy_v1_scan_proto_enumTypes[0]
+}
+
+func (x ScanRunStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ScanRunStatus.Descriptor instead.
+func (ScanRunStatus) EnumDescriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{0}
+}
+
+type ScanRepoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Owner string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Repo  string                 `protobuf:"bytes,2,opt,name=repo,proto3" json:"repo,omitempty"`
+	// At most one of commit, branch and tag can be set. The default branch is scanned if none is set.
+	Commit string `protobuf:"bytes,3,opt,name=commit,proto3" json:"commit,omitempty"`
+	Branch string `protobuf:"bytes,4,opt,name=branch,proto3" json:"branch,omitempty"`
+	Tag    string `protobuf:"bytes,5,opt,name=tag,proto3" json:"tag,omitempty"`
+	// install_id is the GitHub App installation. It is taken from the stored repository if zero.
+	InstallId int64 `protobuf:"varint,6,opt,name=install_id,json=installId,proto3" json:"install_id,omitempty"`
+	// force scans the commit even if it has already been scanned.
+	Force         bool `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanRepoRequest) Reset() {
+	*x = ScanRepoRequest{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRepoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRepoRequest) ProtoMessage() {}
+
+func (x *ScanRepoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRepoRequest.ProtoReflect.Descriptor instead.
+func (*ScanRepoRequest) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ScanRepoRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ScanRepoRequest) GetRepo() string {
+	if x != nil {
+		return x.Repo
+	}
+	return ""
+}
+
+func (x *ScanRepoRequest) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ScanRepoRequest) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *ScanRepoRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ScanRepoRequest) GetInstallId() int64 {
+	if x != nil {
+		return x.InstallId
+	}
+	return 0
+}
+
+func (x *ScanRepoRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ScanRepoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *ScanRun               `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanRepoResponse) Reset() {
+	*x = ScanRepoResponse{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRepoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRepoResponse) ProtoMessage() {}
+
+func (x *ScanRepoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRepoResponse.ProtoReflect.Descriptor instead.
+func (*ScanRepoResponse) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScanRepoResponse) GetRun() *ScanRun {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type GetScanStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetScanStatusRequest) Reset() {
+	*x = GetScanStatusRequest{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetScanStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScanStatusRequest) ProtoMessage() {}
+
+func (x *GetScanStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScanStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetScanStatusRequest) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetScanStatusRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type GetScanStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *ScanRun               `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetScanStatusResponse) Reset() {
+	*x = GetScanStatusResponse{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetScanStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScanStatusResponse) ProtoMessage() {}
+
+func (x *GetScanStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScanStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetScanStatusResponse) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetScanStatusResponse) GetRun() *ScanRun {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type ScanRun struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is empty if runs are not recorded, i.e. Firestore is not configured.
+	Id      string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status  ScanRunStatus `protobuf:"varint,2,opt,name=status,proto3,enum=octovy.v1.ScanRunStatus" json:"status,omitempty"`
+	Trigger string        `protobuf:"bytes,3,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	Owner   string        `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	Repo    string        `protobuf:"bytes,5,opt,name=repo,proto3" json:"repo,omitempty"`
+	Branch  string        `protobuf:"bytes,6,opt,name=branch,proto3" json:"branch,omitempty"`
+	Tag     string        `protobuf:"bytes,7,opt,name=tag,proto3" json:"tag,omitempty"`
+	Commit  string        `protobuf:"bytes,8,opt,name=commit,proto3" json:"commit,omitempty"`
+	// scan_id is the stored scan, set when the run succeeded. skipped is true instead if the commit had already been scanned.
+	ScanId  string `protobuf:"bytes,9,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	Skipped bool   `protobuf:"varint,10,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// error is a summary of the error if the run failed.
+	Error         string                 `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanRun) Reset() {
+	*x = ScanRun{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRun) ProtoMessage() {}
+
+func (x *ScanRun) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRun.ProtoReflect.Descriptor instead.
+func (*ScanRun) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScanRun) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ScanRun) GetStatus() ScanRunStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ScanRunStatus_SCAN_RUN_STATUS_UNSPECIFIED
+}
+
+func (x *ScanRun) GetTrigger() string {
+	if x != nil {
+		return x.Trigger
+	}
+	return ""
+}
+
+func (x *ScanRun) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ScanRun) GetRepo() string {
+	if x != nil {
+		return x.Repo
+	}
+	return ""
+}
+
+func (x *ScanRun) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *ScanRun) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ScanRun) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ScanRun) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+func (x *ScanRun) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *ScanRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScanRun) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ScanRun) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ScanRun) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type ListVulnerabilitiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Owner string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Repo  string                 `protobuf:"bytes,2,opt,name=repo,proto3" json:"repo,omitempty"`
+	// branch is the default branch of the repository if empty.
+	Branch string `protobuf:"bytes,3,opt,name=branch,proto3" json:"branch,omitempty"`
+	// Empty filters match all findings, e.g. ["CRITICAL", "HIGH"] for severities, ["active"] for statuses and ["vulnerability"] for types.
+	Severities    []string `protobuf:"bytes,4,rep,name=severities,proto3" json:"severities,omitempty"`
+	Statuses      []string `protobuf:"bytes,5,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	Types         []string `protobuf:"bytes,6,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVulnerabilitiesRequest) Reset() {
+	*x = ListVulnerabilitiesRequest{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVulnerabilitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVulnerabilitiesRequest) ProtoMessage() {}
+
+func (x *ListVulnerabilitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVulnerabilitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListVulnerabilitiesRequest) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListVulnerabilitiesRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ListVulnerabilitiesRequest) GetRepo() string {
+	if x != nil {
+		return x.Repo
+	}
+	return ""
+}
+
+func (x *ListVulnerabilitiesRequest) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *ListVulnerabilitiesRequest) GetSeverities() []string {
+	if x != nil {
+		return x.Severities
+	}
+	return nil
+}
+
+func (x *ListVulnerabilitiesRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListVulnerabilitiesRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type ListVulnerabilitiesResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Branch          string                 `protobuf:"bytes,1,opt,name=branch,proto3" json:"branch,omitempty"`
+	Vulnerabilities []*Vulnerability       `protobuf:"bytes,2,rep,name=vulnerabilities,proto3" json:"vulnerabilities,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListVulnerabilitiesResponse) Reset() {
+	*x = ListVulnerabilitiesResponse{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVulnerabilitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVulnerabilitiesResponse) ProtoMessage() {}
+
+func (x *ListVulnerabilitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVulnerabilitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListVulnerabilitiesResponse) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListVulnerabilitiesResponse) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *ListVulnerabilitiesResponse) GetVulnerabilities() []*Vulnerability {
+	if x != nil {
+		return x.Vulnerabilities
+	}
+	return nil
+}
+
+type Vulnerability struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type  string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// target is the scanned file, e.g. go.mod, which has the finding.
+	Target           string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	PkgName          string `protobuf:"bytes,4,opt,name=pkg_name,json=pkgName,proto3" json:"pkg_name,omitempty"`
+	PkgPath          string `protobuf:"bytes,5,opt,name=pkg_path,json=pkgPath,proto3" json:"pkg_path,omitempty"`
+	InstalledVersion string `protobuf:"bytes,6,opt,name=installed_version,json=installedVersion,proto3" json:"installed_version,omitempty"`
+	FixedVersion     string `protobuf:"bytes,7,opt,name=fixed_version,json=fixedVersion,proto3" json:"fixed_version,omitempty"`
+	Severity         string `protobuf:"bytes,8,opt,name=severity,proto3" json:"severity,omitempty"`
+	Status           string `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	Title            string `protobuf:"bytes,10,opt,name=title,proto3" json:"title,omitempty"`
+	PrimaryUrl       string `protobuf:"bytes,11,opt,name=primary_url,json=primaryUrl,proto3" json:"primary_url,omitempty"`
+	// created_at is when the finding was first detected on the target.
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vulnerability) Reset() {
+	*x = Vulnerability{}
+	mi := &file_octovy_v1_scan_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vulnerability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vulnerability) ProtoMessage() {}
+
+func (x *Vulnerability) ProtoReflect() protoreflect.Message {
+	mi := &file_octovy_v1_scan_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vulnerability.ProtoReflect.Descriptor instead.
+func (*Vulnerability) Descriptor() ([]byte, []int) {
+	return file_octovy_v1_scan_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Vulnerability) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetPkgName() string {
+	if x != nil {
+		return x.PkgName
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetPkgPath() string {
+	if x != nil {
+		return x.PkgPath
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetInstalledVersion() string {
+	if x != nil {
+		return x.InstalledVersion
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetFixedVersion() string {
+	if x != nil {
+		return x.FixedVersion
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetPrimaryUrl() string {
+	if x != nil {
+		return x.PrimaryUrl
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_octovy_v1_scan_proto protoreflect.FileDescriptor
+
+const file_octovy_v1_scan_proto_rawDesc = "" +
+	"\n" +
+	"\x14octovy/v1/scan.proto\x12\toctovy.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb2\x01\n" +
+	"\x0fScanRepoRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04repo\x18\x02 \x01(\tR\x04repo\x12\x16\n" +
+	"\x06commit\x18\x03 \x01(\tR\x06commit\x12\x16\n" +
+	"\x06branch\x18\x04 \x01(\tR\x06branch\x12\x10\n" +
+	"\x03tag\x18\x05 \x01(\tR\x03tag\x12\x1d\n" +
+	"\n" +
+	"install_id\x18\x06 \x01(\x03R\tinstallId\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\"8\n" +
+	"\x10ScanRepoResponse\x12$\n" +
+	"\x03run\x18\x01 \x01(\v2\x12.octovy.v1.ScanRunR\x03run\"-\n" +
+	"\x14GetScanStatusRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\"=\n" +
+	"\x15GetScanStatusResponse\x12$\n" +
+	"\x03run\x18\x01 \x01(\v2\x12.octovy.v1.ScanRunR\x03run\"\xcd\x03\n" +
+	"\aScanRun\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x120\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x18.octovy.v1.ScanRunStatusR\x06status\x12\x18\n" +
+	"\atrigger\x18\x03 \x01(\tR\atrigger\x12\x14\n" +
+	"\x05owner\x18\x04 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04repo\x18\x05 \x01(\tR\x04repo\x12\x16\n" +
+	"\x06branch\x18\x06 \x01(\tR\x06branch\x12\x10\n" +
+	"\x03tag\x18\a \x01(\tR\x03tag\x12\x16\n" +
+	"\x06commit\x18\b \x01(\tR\x06commit\x12\x17\n" +
+	"\ascan_id\x18\t \x01(\tR\x06scanId\x12\x18\n" +
+	"\askipped\x18\n" +
+	" \x01(\bR\askipped\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"\xb0\x01\n" +
+	"\x1aListVulnerabilitiesRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04repo\x18\x02 \x01(\tR\x04repo\x12\x16\n" +
+	"\x06branch\x18\x03 \x01(\tR\x06branch\x12\x1e\n" +
+	"\n" +
+	"severities\x18\x04 \x03(\tR\n" +
+	"severities\x12\x1a\n" +
+	"\bstatuses\x18\x05 \x03(\tR\bstatuses\x12\x14\n" +
+	"\x05types\x18\x06 \x03(\tR\x05types\"y\n" +
+	"\x1bListVulnerabilitiesResponse\x12\x16\n" +
+	"\x06branch\x18\x01 \x01(\tR\x06branch\x12B\n" +
+	"\x0fvulnerabilities\x18\x02 \x03(\v2\x18.octovy.v1.VulnerabilityR\x0fvulnerabilities\"\xb4\x03\n" +
+	"\rVulnerability\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x19\n" +
+	"\bpkg_name\x18\x04 \x01(\tR\apkgName\x12\x19\n" +
+	"\bpkg_path\x18\x05 \x01(\tR\apkgPath\x12+\n" +
+	"\x11installed_version\x18\x06 \x01(\tR\x10installedVersion\x12#\n" +
+	"\rfixed_version\x18\a \x01(\tR\ffixedVersion\x12\x1a\n" +
+	"\bseverity\x18\b \x01(\tR\bseverity\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x14\n" +
+	"\x05title\x18\n" +
+	" \x01(\tR\x05title\x12\x1f\n" +
+	"\vprimary_url\x18\v \x01(\tR\n" +
+	"primaryUrl\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt*\xa4\x01\n" +
+	"\rScanRunStatus\x12\x1f\n" +
+	"\x1bSCAN_RUN_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16SCAN_RUN_STATUS_QUEUED\x10\x01\x12\x1b\n" +
+	"\x17SCAN_RUN_STATUS_RUNNING\x10\x02\x12\x1d\n" +
+	"\x19SCAN_RUN_STATUS_SUCCEEDED\x10\x03\x12\x1a\n" +
+	"\x16SCAN_RUN_STATUS_FAILED\x10\x042\x8c\x02\n" +
+	"\vScanService\x12C\n" +
+	"\bScanRepo\x12\x1a.octovy.v1.ScanRepoRequest\x1a\x1b.octovy.v1.ScanRepoResponse\x12R\n" +
+	"\rGetScanStatus\x12\x1f.octovy.v1.GetScanStatusRequest\x1a .octovy.v1.GetScanStatusResponse\x12d\n" +
+	"\x13ListVulnerabilities\x12%.octovy.v1.ListVulnerabilitiesRequest\x1a&.octovy.v1.ListVulnerabilitiesResponseBGZEgithub.com/m-mizutani/octovy/pkg/controller/rpc/pb/octovy/v1;octovyv1b\x06proto3"
+
+var (
+	file_octovy_v1_scan_proto_rawDescOnce sync.Once
+	file_octovy_v1_scan_proto_rawDescData []byte
+)
+
+func file_octovy_v1_scan_proto_rawDescGZIP() []byte {
+	file_octovy_v1_scan_proto_rawDescOnce.Do(func() {
+		file_octovy_v1_scan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_octovy_v1_scan_proto_rawDesc), len(file_octovy_v1_scan_proto_rawDesc)))
+	})
+	return file_octovy_v1_scan_proto_rawDescData
+}
+
+var file_octovy_v1_scan_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_octovy_v1_scan_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_octovy_v1_scan_proto_goTypes = []any{
+	(ScanRunStatus)(0),                  // 0: octovy.v1.ScanRunStatus
+	(*ScanRepoRequest)(nil),             // 1: octovy.v1.ScanRepoRequest
+	(*ScanRepoResponse)(nil),            // 2: octovy.v1.ScanRepoResponse
+	(*GetScanStatusRequest)(nil),        // 3: octovy.v1.GetScanStatusRequest
+	(*GetScanStatusResponse)(nil),       // 4: octovy.v1.GetScanStatusResponse
+	(*ScanRun)(nil),                     // 5: octovy.v1.ScanRun
+	(*ListVulnerabilitiesRequest)(nil),  // 6: octovy.v1.ListVulnerabilitiesRequest
+	(*ListVulnerabilitiesResponse)(nil), // 7: octovy.v1.ListVulnerabilitiesResponse
+	(*Vulnerability)(nil),               // 8: octovy.v1.Vulnerability
+	(*timestamppb.Timestamp)(nil),       // 9: google.protobuf.Timestamp
+}
+var file_octovy_v1_scan_proto_depIdxs = []int32{
+	5,  // 0: octovy.v1.ScanRepoResponse.run:type_name -> octovy.v1.ScanRun
+	5,  // 1: octovy.v1.GetScanStatusResponse.run:type_name -> octovy.v1.ScanRun
+	0,  // 2: octovy.v1.ScanRun.status:type_name -> octovy.v1.ScanRunStatus
+	9,  // 3: octovy.v1.ScanRun.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 4: octovy.v1.ScanRun.started_at:type_name -> google.protobuf.Timestamp
+	9,  // 5: octovy.v1.ScanRun.finished_at:type_name -> google.protobuf.Timestamp
+	8,  // 6: octovy.v1.ListVulnerabilitiesResponse.vulnerabilities:type_name -> octovy.v1.Vulnerability
+	9,  // 7: octovy.v1.Vulnerability.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 8: octovy.v1.Vulnerability.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 9: octovy.v1.ScanService.ScanRepo:input_type -> octovy.v1.ScanRepoRequest
+	3,  // 10: octovy.v1.ScanService.GetScanStatus:input_type -> octovy.v1.GetScanStatusRequest
+	6,  // 11: octovy.v1.ScanService.ListVulnerabilities:input_type -> octovy.v1.ListVulnerabilitiesRequest
+	2,  // 12: octovy.v1.ScanService.ScanRepo:output_type -> octovy.v1.ScanRepoResponse
+	4,  // 13: octovy.v1.ScanService.GetScanStatus:output_type -> octovy.v1.GetScanStatusResponse
+	7,  // 14: octovy.v1.ScanService.ListVulnerabilities:output_type -> octovy.v1.ListVulnerabilitiesResponse
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_octovy_v1_scan_proto_init() }
+func file_octovy_v1_scan_proto_init() {
+	if File_octovy_v1_scan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_octovy_v1_scan_proto_rawDesc), len(file_octovy_v1_scan_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_octovy_v1_scan_proto_goTypes,
+		DependencyIndexes: file_octovy_v1_scan_proto_depIdxs,
+		EnumInfos:         file_octovy_v1_scan_proto_enumTypes,
+		MessageInfos:      file_octovy_v1_scan_proto_msgTypes,
+	}.Build()
+	File_octovy_v1_scan_proto = out.File
+	file_octovy_v1_scan_proto_goTypes = nil
+	file_octovy_v1_scan_proto_depIdxs = nil
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	octovyv1 "github.com/m-mizutani/octovy/pkg/controller/rpc/pb/octovy/v1"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ScanQueue runs scans in background, e.g. *server.Server which shares workers with webhook scans. EnqueueScan returns false if the queue is full.
+type ScanQueue interface {
+	EnqueueScan(ctx context.Context, input *model.ScanGitHubRepoInput) bool
+}
+
+// Server is the gRPC API server for other services to trigger scans and query their status and findings
+type Server struct {
+	grpc *grpc.Server
+}
+
+type config struct {
+	tokens []string
+	tls    *tls.Config
+}
+
+type Option func(*config)
+
+// WithTokens requires one of the tokens as a bearer token in the "authorization" metadata of every call
+func WithTokens(tokens []string) Option {
+	return func(cfg *config) {
+		cfg.tokens = tokens
+	}
+}
+
+// WithTLS serves the API over TLS. Client certificates are verified if ClientAuth of the config requires them, i.e. mTLS.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(cfg *config) {
+		cfg.tls = tlsConfig
+	}
+}
+
+func New(uc interfaces.UseCase, queue ScanQueue, options ...Option) *Server {
+	cfg := &config{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	var serverOptions []grpc.ServerOption
+	if cfg.tls != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(cfg.tls)))
+	}
+	serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(
+		preProcess,
+		authenticate(cfg.tokens),
+	))
+
+	s := grpc.NewServer(serverOptions...)
+	octovyv1.RegisterScanServiceServer(s, &scanService{uc: uc, queue: queue})
+
+	return &Server{grpc: s}
+}
+
+// Serve accepts connections on lis until Shutdown is called
+func (x *Server) Serve(lis net.Listener) error {
+	return x.grpc.Serve(lis)
+}
+
+// Shutdown stops accepting calls and waits for running calls to finish until ctx is done. Remaining calls are cancelled then.
+func (x *Server) Shutdown(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		x.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		x.grpc.Stop()
+	}
+}
+
+// preProcess sets a logger with the request ID to the context and logs the call in the same way as HTTP access logs
+func preProcess(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	requestID, ctx := logging.CtxRequestID(ctx)
+	logger := logging.Default().With(slog.String("request_id", requestID.String()))
+	ctx = logging.With(ctx, logger)
+
+	requestedAt := time.Now()
+	resp, err := handler(ctx, req)
+
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	logger.Info("grpc access",
+		slog.String("method", info.FullMethod),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("elapsed", time.Since(requestedAt)),
+	)
+
+	return resp, err
+}
+
+// authenticate accepts calls with one of the tokens. Every call is accepted if no token is configured, in which case clients are authenticated by their certificates.
+func authenticate(tokens []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if len(tokens) == 0 {
+			return handler(ctx, req)
+		}
+
+		var token string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				token, _ = strings.CutPrefix(values[0], "Bearer ")
+			}
+		}
+
+		// Every token is compared not to leak which one partially matched by timing
+		matched := false
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				matched = true
+			}
+		}
+		if token == "" || !matched {
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// toStatus converts err to a gRPC status. Details of internal errors are reported to logs and Sentry, not to clients.
+func toStatus(ctx context.Context, msg string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, types.ErrInvalidOption), errors.Is(err, types.ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		errutil.HandleError(ctx, msg, err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,253 @@
+package rpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/rpc"
+	octovyv1 "github.com/m-mizutani/octovy/pkg/controller/rpc/pb/octovy/v1"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeQueue struct {
+	mutex  sync.Mutex
+	inputs []*model.ScanGitHubRepoInput
+	full   bool
+}
+
+func (x *fakeQueue) EnqueueScan(ctx context.Context, input *model.ScanGitHubRepoInput) bool {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	if x.full {
+		return false
+	}
+	x.inputs = append(x.inputs, input)
+	return true
+}
+
+// newClient serves the API on an in-memory listener and returns a client connected to it
+func newClient(t *testing.T, srv *rpc.Server, opts ...grpc.DialOption) octovyv1.ScanServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	conn := gt.R1(grpc.NewClient("passthrough:///bufnet", opts...)).NoError(t)
+	t.Cleanup(func() { _ = conn.Close() })
+	return octovyv1.NewScanServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestScanRepo(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC := &mock.UseCaseMock{
+		PrepareScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+			gt.V(t, input.Trigger).Equal(types.ScanTriggerRPC)
+			if input.Branch == "unknown" {
+				return nil, goerr.Wrap(repository.ErrNotFound, "branch not found")
+			}
+			return &model.ScanGitHubRepoInput{
+				GitHubMetadata: model.GitHubMetadata{
+					GitHubCommit: model.GitHubCommit{
+						GitHubRepo: model.GitHubRepo{Owner: input.Owner, RepoName: input.Repo},
+						Branch:     input.Branch,
+						CommitID:   "1234567890123456789012345678901234567890",
+					},
+				},
+				InstallID: 42,
+				Trigger:   input.Trigger,
+			}, nil
+		},
+		CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+			input.RunID = "run-1"
+			return &model.ScanRun{
+				ID:        "run-1",
+				Status:    types.ScanRunQueued,
+				Trigger:   input.Trigger,
+				Owner:     input.Owner,
+				RepoName:  input.RepoName,
+				Branch:    input.Branch,
+				CommitID:  input.CommitID,
+				CreatedAt: createdAt,
+			}, nil
+		},
+	}
+	queue := &fakeQueue{}
+	client := newClient(t, rpc.New(mockUC, queue, rpc.WithTokens([]string{"secret"})))
+
+	t.Run("scan is enqueued with its run", func(t *testing.T) {
+		resp := gt.R1(client.ScanRepo(withToken("secret"), &octovyv1.ScanRepoRequest{
+			Owner:  "test-owner",
+			Repo:   "test-repo",
+			Branch: "main",
+		})).NoError(t)
+
+		gt.V(t, resp.Run.Id).Equal("run-1")
+		gt.V(t, resp.Run.Status).Equal(octovyv1.ScanRunStatus_SCAN_RUN_STATUS_QUEUED)
+		gt.V(t, resp.Run.Trigger).Equal("rpc")
+		gt.V(t, resp.Run.Commit).Equal("1234567890123456789012345678901234567890")
+		gt.V(t, resp.Run.CreatedAt.AsTime()).Equal(createdAt)
+		gt.Nil(t, resp.Run.StartedAt)
+
+		gt.A(t, queue.inputs).Length(1).At(0, func(t testing.TB, v *model.ScanGitHubRepoInput) {
+			gt.V(t, v.RunID).Equal("run-1")
+			gt.V(t, v.Owner).Equal("test-owner")
+		})
+	})
+
+	t.Run("unknown ref is not found", func(t *testing.T) {
+		_, err := client.ScanRepo(withToken("secret"), &octovyv1.ScanRepoRequest{Owner: "test-owner", Repo: "test-repo", Branch: "unknown"})
+		gt.V(t, status.Code(err)).Equal(codes.NotFound)
+	})
+
+	t.Run("repo is required", func(t *testing.T) {
+		_, err := client.ScanRepo(withToken("secret"), &octovyv1.ScanRepoRequest{Owner: "test-owner"})
+		gt.V(t, status.Code(err)).Equal(codes.InvalidArgument)
+	})
+
+	t.Run("call without valid token is rejected", func(t *testing.T) {
+		_, err := client.ScanRepo(context.Background(), &octovyv1.ScanRepoRequest{Owner: "test-owner", Repo: "test-repo"})
+		gt.V(t, status.Code(err)).Equal(codes.Unauthenticated)
+
+		_, err = client.ScanRepo(withToken("wrong"), &octovyv1.ScanRepoRequest{Owner: "test-owner", Repo: "test-repo"})
+		gt.V(t, status.Code(err)).Equal(codes.Unauthenticated)
+	})
+}
+
+func TestScanRepoWithServerQueue(t *testing.T) {
+	scanned := make(chan *model.ScanGitHubRepoInput, 1)
+	mockUC := &mock.UseCaseMock{
+		PrepareScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+			return &model.ScanGitHubRepoInput{
+				GitHubMetadata: model.GitHubMetadata{
+					GitHubCommit: model.GitHubCommit{
+						GitHubRepo: model.GitHubRepo{Owner: input.Owner, RepoName: input.Repo},
+						CommitID:   "1234567890123456789012345678901234567890",
+					},
+				},
+				Trigger: input.Trigger,
+			}, nil
+		},
+		CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+			return nil, nil
+		},
+		ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+			scanned <- input
+			return nil
+		},
+	}
+	srv := server.New(mockUC)
+	t.Cleanup(func() { gt.NoError(t, srv.Shutdown(context.Background())) })
+	client := newClient(t, rpc.New(mockUC, srv, rpc.WithTokens([]string{"secret"})))
+
+	resp := gt.R1(client.ScanRepo(withToken("secret"), &octovyv1.ScanRepoRequest{Owner: "test-owner", Repo: "test-repo"})).NoError(t)
+	// Runs are not recorded without Firestore
+	gt.V(t, resp.Run.Id).Equal("")
+	gt.V(t, resp.Run.Status).Equal(octovyv1.ScanRunStatus_SCAN_RUN_STATUS_QUEUED)
+
+	select {
+	case input := <-scanned:
+		gt.V(t, input.Trigger).Equal(types.ScanTriggerRPC)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the scan")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newCertificate(t, nil, "test-ca")
+	serverCert := newCertificate(t, ca, "localhost")
+	clientCert := newCertificate(t, ca, "client")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	mockUC := &mock.UseCaseMock{
+		GetScanRunFunc: func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+			return &model.ScanRun{ID: runID, Status: types.ScanRunRunning}, nil
+		},
+	}
+	srv := rpc.New(mockUC, &fakeQueue{}, rpc.WithTLS(&tls.Config{
+		Certificates: []tls.Certificate{*serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}))
+
+	dial := func(t *testing.T, certs ...tls.Certificate) octovyv1.ScanServiceClient {
+		return newClient(t, srv, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			RootCAs:      pool,
+			Certificates: certs,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS12,
+		})))
+	}
+
+	t.Run("client with certificate is accepted", func(t *testing.T) {
+		resp := gt.R1(dial(t, *clientCert).GetScanStatus(context.Background(), &octovyv1.GetScanStatusRequest{RunId: "run-1"})).NoError(t)
+		gt.V(t, resp.Run.Status).Equal(octovyv1.ScanRunStatus_SCAN_RUN_STATUS_RUNNING)
+	})
+
+	t.Run("client without certificate is rejected", func(t *testing.T) {
+		_, err := dial(t).GetScanStatus(context.Background(), &octovyv1.GetScanStatusRequest{RunId: "run-1"})
+		gt.V(t, status.Code(err)).Equal(codes.Unavailable)
+	})
+}
+
+// newCertificate issues a certificate signed by parent, or a self-signed CA certificate if parent is nil
+func newCertificate(t *testing.T, parent *tls.Certificate, name string) *tls.Certificate {
+	t.Helper()
+	key := gt.R1(ecdsa.GenerateKey(elliptic.P256(), rand.Reader)).NoError(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	issuer, signer := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+
+	der := gt.R1(x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)).NoError(t)
+	leaf := gt.R1(x509.ParseCertificate(der)).NoError(t)
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
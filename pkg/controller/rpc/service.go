@@ -0,0 +1,200 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	octovyv1 "github.com/m-mizutani/octovy/pkg/controller/rpc/pb/octovy/v1"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type scanService struct {
+	octovyv1.UnimplementedScanServiceServer
+	uc    interfaces.UseCase
+	queue ScanQueue
+}
+
+func (x *scanService) ScanRepo(ctx context.Context, req *octovyv1.ScanRepoRequest) (*octovyv1.ScanRepoResponse, error) {
+	if req.GetOwner() == "" || req.GetRepo() == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner and repo are required")
+	}
+
+	// The commit is resolved before the call returns so that clients get an error of a wrong ref
+	input, err := x.uc.PrepareScanGitHubRepo(ctx, &model.ScanGitHubRepoRemoteInput{
+		Owner:     req.GetOwner(),
+		Repo:      req.GetRepo(),
+		Commit:    req.GetCommit(),
+		Branch:    req.GetBranch(),
+		Tag:       req.GetTag(),
+		InstallID: types.GitHubAppInstallID(req.GetInstallId()),
+		Force:     req.GetForce(),
+		Trigger:   types.ScanTriggerRPC,
+	})
+	if err != nil {
+		return nil, toStatus(ctx, "fail to prepare scan", err)
+	}
+
+	// The run is recorded before the scan is enqueued in the same way as webhook scans
+	run, err := x.uc.CreateScanRun(ctx, input)
+	if err != nil {
+		errutil.HandleError(ctx, "fail to create scan run, continue without tracking", err)
+	}
+
+	if !x.queue.EnqueueScan(detachContext(ctx), input) {
+		return nil, status.Error(codes.ResourceExhausted, "scan queue is full")
+	}
+
+	if run == nil {
+		// Runs are not recorded, e.g. without Firestore
+		run = &model.ScanRun{
+			Status:   types.ScanRunQueued,
+			Trigger:  input.Trigger,
+			Owner:    input.Owner,
+			RepoName: input.RepoName,
+			Branch:   input.Branch,
+			Tag:      input.Tag,
+			CommitID: input.CommitID,
+		}
+	}
+	return &octovyv1.ScanRepoResponse{Run: newScanRun(run)}, nil
+}
+
+func (x *scanService) GetScanStatus(ctx context.Context, req *octovyv1.GetScanStatusRequest) (*octovyv1.GetScanStatusResponse, error) {
+	if req.GetRunId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "run_id is required")
+	}
+
+	run, err := x.uc.GetScanRun(ctx, types.ScanRunID(req.GetRunId()))
+	if err != nil {
+		return nil, toStatus(ctx, "fail to get scan run", err)
+	}
+	return &octovyv1.GetScanStatusResponse{Run: newScanRun(run)}, nil
+}
+
+func (x *scanService) ListVulnerabilities(ctx context.Context, req *octovyv1.ListVulnerabilitiesRequest) (*octovyv1.ListVulnerabilitiesResponse, error) {
+	if req.GetOwner() == "" || req.GetRepo() == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner and repo are required")
+	}
+
+	repoID := types.NewGitHubRepoID(req.GetOwner(), req.GetRepo())
+	branch := types.NewBranchName(req.GetBranch())
+	if branch == "" {
+		repo, err := x.uc.GetRepository(ctx, &model.GetRepositoryInput{Owner: req.GetOwner(), Repo: req.GetRepo()})
+		if err != nil {
+			return nil, toStatus(ctx, "fail to get repository", err)
+		}
+		if repo.DefaultBranch == "" {
+			return nil, status.Error(codes.FailedPrecondition, "default branch of the repository is unknown, branch is required")
+		}
+		branch = repo.DefaultBranch
+	}
+
+	targets, err := x.uc.ListTargets(ctx, &model.ListTargetsInput{RepoID: repoID, Branch: branch})
+	if err != nil {
+		return nil, toStatus(ctx, "fail to list targets", err)
+	}
+
+	resp := &octovyv1.ListVulnerabilitiesResponse{Branch: string(branch)}
+	for _, target := range targets {
+		// Findings of a removed target are not in the latest scan
+		if !target.RemovedAt.IsZero() {
+			continue
+		}
+
+		input := &model.ListVulnerabilitiesInput{
+			RepoID:   repoID,
+			Branch:   branch,
+			TargetID: target.ID,
+		}
+		for _, s := range req.GetSeverities() {
+			input.Severities = append(input.Severities, types.Severity(s))
+		}
+		for _, s := range req.GetStatuses() {
+			input.Statuses = append(input.Statuses, types.VulnStatus(s))
+		}
+		for _, t := range req.GetTypes() {
+			input.Types = append(input.Types, types.FindingType(t))
+		}
+
+		vulns, err := x.uc.ListVulnerabilities(ctx, input)
+		if err != nil {
+			return nil, toStatus(ctx, "fail to list vulnerabilities",
+				goerr.Wrap(err, "failed to list vulnerabilities of target", goerr.V("target", target.Target)))
+		}
+		for _, vuln := range vulns {
+			resp.Vulnerabilities = append(resp.Vulnerabilities, newVulnerability(target, vuln))
+		}
+	}
+
+	return resp, nil
+}
+
+// detachContext keeps the logger, the request ID and the time function of ctx for a background scan which outlives the call
+func detachContext(ctx context.Context) context.Context {
+	bgCtx := logging.With(context.Background(), logging.From(ctx))
+	return logging.InheritContextValues(bgCtx, ctx)
+}
+
+var scanRunStatuses = map[types.ScanRunStatus]octovyv1.ScanRunStatus{
+	types.ScanRunQueued:    octovyv1.ScanRunStatus_SCAN_RUN_STATUS_QUEUED,
+	types.ScanRunRunning:   octovyv1.ScanRunStatus_SCAN_RUN_STATUS_RUNNING,
+	types.ScanRunSucceeded: octovyv1.ScanRunStatus_SCAN_RUN_STATUS_SUCCEEDED,
+	types.ScanRunFailed:    octovyv1.ScanRunStatus_SCAN_RUN_STATUS_FAILED,
+}
+
+func newScanRun(run *model.ScanRun) *octovyv1.ScanRun {
+	return &octovyv1.ScanRun{
+		Id:         string(run.ID),
+		Status:     scanRunStatuses[run.Status],
+		Trigger:    string(run.Trigger),
+		Owner:      run.Owner,
+		Repo:       run.RepoName,
+		Branch:     run.Branch,
+		Tag:        run.Tag,
+		Commit:     run.CommitID,
+		ScanId:     string(run.ScanID),
+		Skipped:    run.Skipped,
+		Error:      run.Error,
+		CreatedAt:  newTimestamp(run.CreatedAt),
+		StartedAt:  newTimestamp(run.StartedAt),
+		FinishedAt: newTimestamp(run.FinishedAt),
+	}
+}
+
+func newVulnerability(target *model.Target, vuln *model.Vulnerability) *octovyv1.Vulnerability {
+	findingType := vuln.Type
+	if findingType == "" {
+		findingType = types.FindingTypeVulnerability
+	}
+	return &octovyv1.Vulnerability{
+		Id:               vuln.ID,
+		Type:             string(findingType),
+		Target:           target.Target,
+		PkgName:          vuln.PkgName,
+		PkgPath:          vuln.PkgPath,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Severity:         vuln.Severity,
+		Status:           string(vuln.Status),
+		Title:            vuln.Title,
+		PrimaryUrl:       vuln.PrimaryURL,
+		CreatedAt:        newTimestamp(vuln.CreatedAt),
+		UpdatedAt:        newTimestamp(vuln.UpdatedAt),
+	}
+}
+
+// newTimestamp returns nil for zero time, e.g. started_at of a queued run
+func newTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
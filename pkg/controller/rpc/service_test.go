@@ -0,0 +1,193 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/rpc"
+	octovyv1 "github.com/m-mizutani/octovy/pkg/controller/rpc/pb/octovy/v1"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScanRepoWithFullQueue(t *testing.T) {
+	var created int
+	mockUC := &mock.UseCaseMock{
+		PrepareScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+			return &model.ScanGitHubRepoInput{
+				GitHubMetadata: model.GitHubMetadata{
+					GitHubCommit: model.GitHubCommit{
+						GitHubRepo: model.GitHubRepo{Owner: input.Owner, RepoName: input.Repo},
+						CommitID:   "1234567890123456789012345678901234567890",
+					},
+				},
+				Trigger: input.Trigger,
+			}, nil
+		},
+		CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+			created++
+			return &model.ScanRun{ID: "run-1", Status: types.ScanRunQueued}, nil
+		},
+	}
+	queue := &fakeQueue{full: true}
+	client := newClient(t, rpc.New(mockUC, queue, rpc.WithTokens([]string{"secret"})))
+
+	resp, err := client.ScanRepo(withToken("secret"), &octovyv1.ScanRepoRequest{Owner: "test-owner", Repo: "test-repo"})
+	gt.V(t, status.Code(err)).Equal(codes.ResourceExhausted)
+	gt.Nil(t, resp)
+	gt.A(t, queue.inputs).Length(0)
+	// The run is recorded before the scan is enqueued
+	gt.V(t, created).Equal(1)
+}
+
+func TestGetScanStatus(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC := &mock.UseCaseMock{
+		GetScanRunFunc: func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+			switch runID {
+			case "run-1":
+				return &model.ScanRun{
+					ID:         "run-1",
+					Status:     types.ScanRunSucceeded,
+					Owner:      "test-owner",
+					RepoName:   "test-repo",
+					ScanID:     "scan-1",
+					StartedAt:  startedAt,
+					FinishedAt: startedAt.Add(time.Minute),
+				}, nil
+			case "run-broken":
+				return nil, goerr.New("connection refused")
+			default:
+				return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found")
+			}
+		},
+	}
+	client := newClient(t, rpc.New(mockUC, &fakeQueue{}, rpc.WithTokens([]string{"secret"})))
+
+	t.Run("status of run is returned", func(t *testing.T) {
+		resp := gt.R1(client.GetScanStatus(withToken("secret"), &octovyv1.GetScanStatusRequest{RunId: "run-1"})).NoError(t)
+		gt.V(t, resp.Run.Status).Equal(octovyv1.ScanRunStatus_SCAN_RUN_STATUS_SUCCEEDED)
+		gt.V(t, resp.Run.ScanId).Equal("scan-1")
+		gt.V(t, resp.Run.FinishedAt.AsTime()).Equal(startedAt.Add(time.Minute))
+		gt.Nil(t, resp.Run.CreatedAt)
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		_, err := client.GetScanStatus(withToken("secret"), &octovyv1.GetScanStatusRequest{RunId: "run-2"})
+		gt.V(t, status.Code(err)).Equal(codes.NotFound)
+	})
+
+	t.Run("run_id is required", func(t *testing.T) {
+		_, err := client.GetScanStatus(withToken("secret"), &octovyv1.GetScanStatusRequest{})
+		gt.V(t, status.Code(err)).Equal(codes.InvalidArgument)
+	})
+
+	t.Run("details of internal error are not returned", func(t *testing.T) {
+		_, err := client.GetScanStatus(withToken("secret"), &octovyv1.GetScanStatusRequest{RunId: "run-broken"})
+		gt.V(t, status.Code(err)).Equal(codes.Internal)
+		gt.V(t, status.Convert(err).Message()).Equal("internal server error")
+	})
+}
+
+func TestListVulnerabilities(t *testing.T) {
+	mockUC := &mock.UseCaseMock{
+		GetRepositoryFunc: func(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error) {
+			switch input.Repo {
+			case "test-repo":
+				return &model.Repository{DefaultBranch: "main"}, nil
+			case "new-repo":
+				return &model.Repository{}, nil
+			default:
+				return nil, goerr.Wrap(repository.ErrNotFound, "repository not found")
+			}
+		},
+		ListTargetsFunc: func(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error) {
+			gt.V(t, input.RepoID).Equal(types.NewGitHubRepoID("test-owner", "test-repo"))
+			return []*model.Target{
+				{ID: "t1", Target: "go.mod"},
+				{ID: "t2", Target: "old/go.mod", RemovedAt: time.Now()},
+			}, nil
+		},
+	}
+	client := newClient(t, rpc.New(mockUC, &fakeQueue{}, rpc.WithTokens([]string{"secret"})))
+
+	t.Run("findings of default branch are filtered", func(t *testing.T) {
+		mockUC.ListVulnerabilitiesFunc = func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+			gt.V(t, input.Branch).Equal("main")
+			gt.V(t, input.TargetID).Equal("t1")
+			gt.V(t, input.Severities).Equal([]types.Severity{"CRITICAL"})
+			gt.V(t, input.Statuses).Equal([]types.VulnStatus{types.VulnStatusActive})
+			gt.V(t, input.Types).Equal([]types.FindingType{types.FindingTypeVulnerability})
+			return []*model.Vulnerability{
+				{ID: "CVE-2024-0001", PkgName: "example.com/pkg", Severity: "CRITICAL", Status: types.VulnStatusActive},
+			}, nil
+		}
+
+		resp := gt.R1(client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{
+			Owner:      "test-owner",
+			Repo:       "test-repo",
+			Severities: []string{"CRITICAL"},
+			Statuses:   []string{"active"},
+			Types:      []string{"vulnerability"},
+		})).NoError(t)
+
+		gt.V(t, resp.Branch).Equal("main")
+		gt.A(t, resp.Vulnerabilities).Length(1).At(0, func(t testing.TB, v *octovyv1.Vulnerability) {
+			gt.V(t, v.Id).Equal("CVE-2024-0001")
+			gt.V(t, v.Target).Equal("go.mod")
+			gt.V(t, v.Type).Equal("vulnerability")
+			gt.V(t, v.Status).Equal("active")
+		})
+	})
+
+	t.Run("findings of given branch are returned", func(t *testing.T) {
+		mockUC.ListVulnerabilitiesFunc = func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+			gt.V(t, input.Branch).Equal("develop")
+			return []*model.Vulnerability{
+				{ID: "aws-access-key-id", Type: types.FindingTypeSecret, Severity: "CRITICAL", Status: types.VulnStatusActive},
+			}, nil
+		}
+
+		resp := gt.R1(client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{
+			Owner:  "test-owner",
+			Repo:   "test-repo",
+			Branch: "develop",
+		})).NoError(t)
+
+		gt.V(t, resp.Branch).Equal("develop")
+		gt.A(t, resp.Vulnerabilities).Length(1).At(0, func(t testing.TB, v *octovyv1.Vulnerability) {
+			gt.V(t, v.Type).Equal("secret")
+		})
+	})
+
+	t.Run("failure of target is internal error", func(t *testing.T) {
+		mockUC.ListVulnerabilitiesFunc = func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+			return nil, goerr.New("connection refused")
+		}
+
+		_, err := client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{Owner: "test-owner", Repo: "test-repo"})
+		gt.V(t, status.Code(err)).Equal(codes.Internal)
+	})
+
+	t.Run("unknown repository is not found", func(t *testing.T) {
+		_, err := client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{Owner: "test-owner", Repo: "unknown"})
+		gt.V(t, status.Code(err)).Equal(codes.NotFound)
+	})
+
+	t.Run("branch is required if default branch is unknown", func(t *testing.T) {
+		_, err := client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{Owner: "test-owner", Repo: "new-repo"})
+		gt.V(t, status.Code(err)).Equal(codes.FailedPrecondition)
+	})
+
+	t.Run("owner and repo are required", func(t *testing.T) {
+		_, err := client.ListVulnerabilities(withToken("secret"), &octovyv1.ListVulnerabilitiesRequest{Owner: "test-owner"})
+		gt.V(t, status.Code(err)).Equal(codes.InvalidArgument)
+	})
+}
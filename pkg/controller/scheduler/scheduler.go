@@ -0,0 +1,120 @@
+// Package scheduler periodically scans all repositories of GitHub owners in server mode, so that default branches get fresh vulnerability data without push events.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/cron"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+type Scheduler struct {
+	uc       interfaces.UseCase
+	schedule *cron.Schedule
+	owners   []string
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	once    sync.Once
+	started atomic.Bool
+}
+
+// New creates a scheduler that scans repositories of the owners at activation times of the schedule
+func New(uc interfaces.UseCase, schedule *cron.Schedule, owners []string) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		uc:       uc,
+		schedule: schedule,
+		owners:   owners,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in background until Shutdown is called
+func (x *Scheduler) Start() {
+	if x.started.CompareAndSwap(false, true) {
+		go x.loop()
+	}
+}
+
+func (x *Scheduler) loop() {
+	defer close(x.done)
+	logger := logging.Default().With(slog.String("schedule", x.schedule.String()))
+
+	for {
+		next := x.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Warn("Scan schedule never activates, scheduler stopped")
+			return
+		}
+		logger.Info("Next scheduled scan", slog.Time("at", next), slog.Any("owners", x.owners))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-x.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Scans run in this goroutine, so an activation during a long run is skipped rather than overlapping
+		x.RunOnce(x.ctx)
+	}
+}
+
+// RunOnce scans repositories of all owners. A failure of an owner does not stop scans of the other owners.
+func (x *Scheduler) RunOnce(ctx context.Context) {
+	logger := logging.From(ctx)
+	started := time.Now()
+
+	var failures int
+	for _, owner := range x.owners {
+		if ctx.Err() != nil {
+			break
+		}
+
+		logger.Info("Starting scheduled scan", slog.String("owner", owner))
+		if _, err := x.uc.ScanGitHubReposByOwnerFromAPI(ctx, &model.ScanGitHubReposByOwnerFromAPIInput{
+			Owner: owner,
+			// Rescan unchanged commits as well to pick up newly disclosed vulnerabilities
+			Force:   true,
+			Trigger: types.ScanTriggerSchedule,
+		}); err != nil {
+			failures++
+			errutil.HandleError(ctx, "scheduled scan failed", goerr.Wrap(err, "failed to scan repositories of owner", goerr.V("owner", owner)))
+		}
+	}
+
+	logger.Info("Completed scheduled scan",
+		slog.Int("owners", len(x.owners)),
+		slog.Int("failures", failures),
+		slog.Duration("duration", time.Since(started)),
+	)
+}
+
+// Shutdown stops the scheduler, cancelling the running scan if any, and waits for it to finish
+func (x *Scheduler) Shutdown(ctx context.Context) error {
+	x.once.Do(x.cancel)
+	if !x.started.Load() {
+		return nil
+	}
+
+	select {
+	case <-x.done:
+		return nil
+	case <-ctx.Done():
+		return goerr.Wrap(ctx.Err(), "scheduled scan did not finish before shutdown timeout")
+	}
+}
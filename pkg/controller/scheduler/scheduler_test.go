@@ -0,0 +1,56 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/scheduler"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/cron"
+)
+
+func TestSchedulerRunOnce(t *testing.T) {
+	mockUC := &mock.UseCaseMock{
+		ScanGitHubReposByOwnerFromAPIFunc: func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+			gt.True(t, input.Force)
+			if input.Owner == "org-a" {
+				return nil, errors.New("installation not found")
+			}
+			return &model.OwnerScanRun{Owner: input.Owner}, nil
+		},
+	}
+	schedule := gt.R1(cron.Parse("0 3 * * *")).NoError(t)
+
+	s := scheduler.New(mockUC, schedule, []string{"org-a", "org-b"})
+	s.RunOnce(context.Background())
+
+	// Failure of an owner does not stop scans of the other owners
+	calls := mockUC.ScanGitHubReposByOwnerFromAPICalls()
+	gt.A(t, calls).Length(2)
+	gt.V(t, calls[0].Input.Owner).Equal("org-a")
+	gt.V(t, calls[1].Input.Owner).Equal("org-b")
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	mockUC := &mock.UseCaseMock{}
+	schedule := gt.R1(cron.Parse("0 3 * * *")).NoError(t)
+
+	t.Run("stops waiting loop", func(t *testing.T) {
+		s := scheduler.New(mockUC, schedule, []string{"org-a"})
+		s.Start()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		gt.NoError(t, s.Shutdown(ctx))
+		gt.A(t, mockUC.ScanGitHubReposByOwnerFromAPICalls()).Length(0)
+	})
+
+	t.Run("not started", func(t *testing.T) {
+		s := scheduler.New(mockUC, schedule, []string{"org-a"})
+		gt.NoError(t, s.Shutdown(context.Background()))
+	})
+}
@@ -0,0 +1,456 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+type apiErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		logging.Default().Error("fail to marshal response", slog.Any("error", err))
+		safeWrite(w, http.StatusInternalServerError, []byte(`{"error":"internal server error"}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	safeWrite(w, code, body)
+}
+
+// handleAPIError maps errors from usecase to HTTP status codes. Only server side errors are reported to Sentry.
+func handleAPIError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, apiErrorResponse{Error: "not found"})
+	case errors.Is(err, types.ErrInvalidOption), errors.Is(err, types.ErrInvalidRequest):
+		writeJSON(w, http.StatusBadRequest, apiErrorResponse{Error: err.Error()})
+	default:
+		errutil.HandleError(r.Context(), msg, err)
+		writeJSON(w, http.StatusInternalServerError, apiErrorResponse{Error: "internal server error"})
+	}
+}
+
+func apiRoutes(uc interfaces.UseCase, cfg *config) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(requireAuth(cfg, false))
+
+		r.Get("/repos/{owner}/{repo}/gate", func(w http.ResponseWriter, r *http.Request) {
+			input, err := parseGateRequest(r, cfg)
+			if err != nil {
+				handleAPIError(w, r, "invalid gate request", err)
+				return
+			}
+
+			result, err := uc.EvaluateGate(r.Context(), input)
+			if err != nil {
+				handleAPIError(w, r, "fail to evaluate gate", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, result)
+		})
+
+		r.Get("/repos/{owner}/{repo}/sla", func(w http.ResponseWriter, r *http.Request) {
+			report, err := uc.CheckSLA(r.Context(), &model.CheckSLAInput{
+				Owner:  chi.URLParam(r, "owner"),
+				Repo:   chi.URLParam(r, "repo"),
+				Branch: types.NewBranchName(r.URL.Query().Get("branch")),
+			})
+			if err != nil {
+				handleAPIError(w, r, "fail to check SLA", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, report)
+		})
+
+		r.Get("/repos/{owner}/{repo}/branches", func(w http.ResponseWriter, r *http.Request) {
+			status, err := uc.ListBranchStatus(r.Context(), &model.ListBranchStatusInput{
+				Owner: chi.URLParam(r, "owner"),
+				Repo:  chi.URLParam(r, "repo"),
+			})
+			if err != nil {
+				handleAPIError(w, r, "fail to list branch status", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, status)
+		})
+
+		// Branch and target IDs with a slash must be escaped as "%2F"
+		r.Get("/repos/{owner}/{repo}/branches/{branch}/targets/{target}/vulns/{id}", func(w http.ResponseWriter, r *http.Request) {
+			input, err := parseVulnerabilityDetailRequest(r)
+			if err != nil {
+				handleAPIError(w, r, "invalid vulnerability detail request", err)
+				return
+			}
+
+			detail, err := uc.GetVulnerabilityDetail(r.Context(), input)
+			if err != nil {
+				handleAPIError(w, r, "fail to get vulnerability detail", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, detail)
+		})
+
+		r.Get("/repos/{owner}/{repo}/vulnerabilities/rollup", func(w http.ResponseWriter, r *http.Request) {
+			input := &model.GetVulnerabilityRollupInput{
+				Owner: chi.URLParam(r, "owner"),
+				Repo:  chi.URLParam(r, "repo"),
+			}
+			if v := r.URL.Query().Get("include_fixed"); v != "" {
+				includeFixed, err := strconv.ParseBool(v)
+				if err != nil {
+					handleAPIError(w, r, "invalid rollup request", goerr.Wrap(types.ErrInvalidRequest, "invalid include_fixed", goerr.V("include_fixed", v)))
+					return
+				}
+				input.IncludeFixed = includeFixed
+			}
+
+			rollup, err := uc.GetVulnerabilityRollup(r.Context(), input)
+			if err != nil {
+				handleAPIError(w, r, "fail to get vulnerability rollup", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, rollup)
+		})
+
+		r.Get("/owners/{owner}/severity-overrides", func(w http.ResponseWriter, r *http.Request) {
+			overrides, err := uc.ListSeverityOverrides(r.Context(), chi.URLParam(r, "owner"))
+			if err != nil {
+				handleAPIError(w, r, "fail to list severity overrides", err)
+				return
+			}
+
+			resp := severityOverridesResponse{SeverityOverrides: []*severityOverrideResponse{}}
+			for _, o := range overrides {
+				resp.SeverityOverrides = append(resp.SeverityOverrides, newSeverityOverrideResponse(o))
+			}
+			writeJSON(w, http.StatusOK, resp)
+		})
+
+		r.Get("/scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+			run, err := uc.GetScanRun(r.Context(), types.ScanRunID(chi.URLParam(r, "id")))
+			if err != nil {
+				handleAPIError(w, r, "fail to get scan run", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, newScanRunResponse(run))
+		})
+
+		r.Get("/owner-scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+			run, err := uc.GetOwnerScanRun(r.Context(), types.OwnerScanRunID(chi.URLParam(r, "id")))
+			if err != nil {
+				handleAPIError(w, r, "fail to get owner scan run", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, run)
+		})
+
+		r.Get("/users/{login}/repos", func(w http.ResponseWriter, r *http.Request) {
+			input, err := parseDeveloperSummaryRequest(r)
+			if err != nil {
+				handleAPIError(w, r, "invalid developer summary request", err)
+				return
+			}
+
+			summary, err := uc.GetDeveloperSummary(r.Context(), input)
+			if err != nil {
+				handleAPIError(w, r, "fail to get developer summary", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, summary)
+		})
+
+		// Endpoints modifying data are available only when the admin token or admin teams are configured
+		if cfg.adminEnabled() {
+			r.With(requireAuth(cfg, true)).Put("/repos/{owner}/{repo}/metadata", func(w http.ResponseWriter, r *http.Request) {
+				var req repositoryMetadataRequest
+				if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRepositoryMetadataRequestSize)).Decode(&req); err != nil {
+					handleAPIError(w, r, "invalid repository metadata request", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+					return
+				}
+
+				repo, err := uc.UpdateRepositoryMetadata(r.Context(), &model.UpdateRepositoryMetadataInput{
+					Owner:            chi.URLParam(r, "owner"),
+					Repo:             chi.URLParam(r, "repo"),
+					Tags:             req.Tags,
+					Team:             req.Team,
+					PullRequestCheck: req.PullRequestCheck,
+				})
+				if err != nil {
+					handleAPIError(w, r, "fail to update repository metadata", err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, repositoryMetadataResponse{
+					Repository:       string(repo.ID),
+					Tags:             repo.Tags,
+					Team:             repo.Team,
+					PullRequestCheck: repo.PullRequestCheck,
+				})
+			})
+
+			r.With(requireAuth(cfg, true)).Put("/owners/{owner}/severity-overrides", func(w http.ResponseWriter, r *http.Request) {
+				var req severityOverrideRequest
+				if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSeverityOverrideRequestSize)).Decode(&req); err != nil {
+					handleAPIError(w, r, "invalid severity override request", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+					return
+				}
+
+				override := &model.SeverityOverride{
+					Owner:    chi.URLParam(r, "owner"),
+					VulnID:   req.VulnID,
+					PkgName:  req.PkgName,
+					Severity: types.Severity(req.Severity),
+					Reason:   req.Reason,
+				}
+				if p := principalFrom(r.Context()); p != nil {
+					override.By = p.name
+				}
+
+				stored, err := uc.PutSeverityOverride(r.Context(), override)
+				if err != nil {
+					handleAPIError(w, r, "fail to put severity override", err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, newSeverityOverrideResponse(stored))
+			})
+
+			// The override is selected by query parameters vuln_id and pkg_name, as it was put
+			r.With(requireAuth(cfg, true)).Delete("/owners/{owner}/severity-overrides", func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if err := uc.DeleteSeverityOverride(r.Context(), &model.DeleteSeverityOverrideInput{
+					Owner:   chi.URLParam(r, "owner"),
+					VulnID:  query.Get("vuln_id"),
+					PkgName: query.Get("pkg_name"),
+				}); err != nil {
+					handleAPIError(w, r, "fail to delete severity override", err)
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			})
+		}
+
+		r.Post("/backstage/entities", func(w http.ResponseWriter, r *http.Request) {
+			var req backstageEntitiesRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBackstageRequestSize)).Decode(&req); err != nil {
+				handleAPIError(w, r, "invalid backstage request", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+				return
+			}
+
+			postures, err := uc.GetBackstagePosture(r.Context(), &model.BackstagePostureInput{
+				Entities:   req.Entities,
+				MaxScanAge: cfg.gateMaxScanAge,
+			})
+			if err != nil {
+				handleAPIError(w, r, "fail to get backstage posture", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, backstageEntitiesResponse{Entities: postures})
+		})
+	}
+}
+
+const maxBackstageRequestSize = 1 << 20
+
+const maxRepositoryMetadataRequestSize = 64 << 10
+
+// repositoryMetadataRequest updates only fields given in the request
+type repositoryMetadataRequest struct {
+	Tags *[]string `json:"tags"`
+	Team *string   `json:"team"`
+	// PullRequestCheck with an empty baseline removes the override of the repository
+	PullRequestCheck *model.PullRequestCheckConfig `json:"pull_request_check"`
+}
+
+type repositoryMetadataResponse struct {
+	Repository       string                        `json:"repository"`
+	Tags             []string                      `json:"tags"`
+	Team             string                        `json:"team"`
+	PullRequestCheck *model.PullRequestCheckConfig `json:"pull_request_check,omitempty"`
+}
+
+const maxSeverityOverrideRequestSize = 64 << 10
+
+type severityOverrideRequest struct {
+	VulnID   string `json:"vuln_id"`
+	PkgName  string `json:"pkg_name"`
+	Severity string `json:"severity"`
+	Reason   string `json:"reason"`
+}
+
+type severityOverrideResponse struct {
+	VulnID    string         `json:"vuln_id,omitempty"`
+	PkgName   string         `json:"pkg_name,omitempty"`
+	Severity  types.Severity `json:"severity"`
+	Reason    string         `json:"reason,omitempty"`
+	By        string         `json:"by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func newSeverityOverrideResponse(o *model.SeverityOverride) *severityOverrideResponse {
+	return &severityOverrideResponse{
+		VulnID:    o.VulnID,
+		PkgName:   o.PkgName,
+		Severity:  o.Severity,
+		Reason:    o.Reason,
+		By:        o.By,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+type severityOverridesResponse struct {
+	SeverityOverrides []*severityOverrideResponse `json:"severity_overrides"`
+}
+
+// scanRunResponse omits times and results which are not set yet, e.g. finished_at of a running scan
+type scanRunResponse struct {
+	ID         types.ScanRunID     `json:"id"`
+	Status     types.ScanRunStatus `json:"status"`
+	Trigger    types.ScanTrigger   `json:"trigger"`
+	RequestID  types.RequestID     `json:"request_id,omitempty"`
+	Repository string              `json:"repository"`
+	Branch     string              `json:"branch,omitempty"`
+	Tag        string              `json:"tag,omitempty"`
+	CommitID   string              `json:"commit_id,omitempty"`
+	ScanID     types.ScanID        `json:"scan_id,omitempty"`
+	Skipped    bool                `json:"skipped,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+}
+
+func newScanRunResponse(run *model.ScanRun) *scanRunResponse {
+	resp := &scanRunResponse{
+		ID:         run.ID,
+		Status:     run.Status,
+		Trigger:    run.Trigger,
+		RequestID:  run.RequestID,
+		Repository: run.Owner + "/" + run.RepoName,
+		Branch:     run.Branch,
+		Tag:        run.Tag,
+		CommitID:   run.CommitID,
+		ScanID:     run.ScanID,
+		Skipped:    run.Skipped,
+		Error:      run.Error,
+		CreatedAt:  run.CreatedAt,
+	}
+	if !run.StartedAt.IsZero() {
+		resp.StartedAt = &run.StartedAt
+	}
+	if !run.FinishedAt.IsZero() {
+		resp.FinishedAt = &run.FinishedAt
+	}
+	return resp
+}
+
+type backstageEntitiesRequest struct {
+	Entities []*model.BackstageEntity `json:"entities"`
+}
+
+type backstageEntitiesResponse struct {
+	Entities []*model.BackstagePosture `json:"entities"`
+}
+
+func parseGateRequest(r *http.Request, cfg *config) (*model.EvaluateGateInput, error) {
+	query := r.URL.Query()
+	input := &model.EvaluateGateInput{
+		Owner:      chi.URLParam(r, "owner"),
+		Repo:       chi.URLParam(r, "repo"),
+		Branch:     types.NewBranchName(query.Get("branch")),
+		MaxScanAge: cfg.gateMaxScanAge,
+	}
+
+	if v := query.Get("max-severity"); v != "" {
+		severity, err := types.ParseSeverity(v)
+		if err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid max-severity", goerr.V("max-severity", v))
+		}
+		input.MaxSeverity = severity
+	}
+
+	if v := query.Get("max-age-hours"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 0 {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid max-age-hours", goerr.V("max-age-hours", v))
+		}
+		input.MaxScanAge = time.Duration(hours) * time.Hour
+	}
+
+	if v := query.Get("sla"); v != "" {
+		enforce, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid sla", goerr.V("sla", v))
+		}
+		input.EnforceSLA = enforce
+	}
+
+	return input, nil
+}
+
+func parseDeveloperSummaryRequest(r *http.Request) (*model.DeveloperSummaryInput, error) {
+	query := r.URL.Query()
+	input := &model.DeveloperSummaryInput{
+		Login: chi.URLParam(r, "login"),
+		Tag:   query.Get("tag"),
+		Team:  query.Get("team"),
+	}
+
+	if v := query.Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid days", goerr.V("days", v))
+		}
+		input.Period = time.Duration(days) * 24 * time.Hour
+	}
+
+	return input, nil
+}
+
+func parseVulnerabilityDetailRequest(r *http.Request) (*model.GetVulnerabilityDetailInput, error) {
+	var params [5]string
+	for i, key := range []string{"owner", "repo", "branch", "target", "id"} {
+		v, err := pathParam(r, key)
+		if err != nil {
+			return nil, err
+		}
+		params[i] = v
+	}
+
+	return &model.GetVulnerabilityDetailInput{
+		Owner:    params[0],
+		Repo:     params[1],
+		Branch:   types.BranchName(params[2]),
+		TargetID: types.TargetID(params[3]),
+		VulnID:   params[4],
+	}, nil
+}
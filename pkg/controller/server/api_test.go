@@ -0,0 +1,654 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+func TestGateAPI(t *testing.T) {
+	t.Run("returns gate result", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			EvaluateGateFunc: func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+				gt.V(t, input.Owner).Equal("myorg")
+				gt.V(t, input.Repo).Equal("myrepo")
+				gt.V(t, input.Branch).Equal(types.BranchName("main"))
+				gt.V(t, input.MaxSeverity).Equal(types.SeverityHigh)
+				gt.V(t, input.MaxScanAge).Equal(12 * time.Hour)
+				return &model.GateResult{
+					Pass:          false,
+					Repository:    "myorg/myrepo",
+					Branch:        "main",
+					Justification: []string{"1 active findings exceed severity HIGH"},
+					Violations:    []model.GateViolation{{Target: "go.mod", ID: "CVE-2024-0001", Severity: "CRITICAL"}},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithGateMaxScanAge(12*time.Hour))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/gate?branch=main&max-severity=high", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.V(t, rec.Header().Get("Content-Type")).Equal("application/json")
+
+		var result model.GateResult
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.False(t, result.Pass)
+		gt.A(t, result.Violations).Length(1)
+	})
+
+	t.Run("max-age-hours overrides default", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			EvaluateGateFunc: func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+				gt.V(t, input.MaxScanAge).Equal(3 * time.Hour)
+				gt.V(t, input.MaxSeverity).Equal(types.Severity(""))
+				return &model.GateResult{Pass: true}, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithGateMaxScanAge(24*time.Hour))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/gate?max-age-hours=3", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+	})
+
+	t.Run("invalid severity is bad request", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/gate?max-severity=urgent", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+		gt.A(t, mockUC.EvaluateGateCalls()).Length(0)
+	})
+
+	t.Run("unknown repository is not found", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			EvaluateGateFunc: func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+				return nil, goerr.Wrap(repository.ErrNotFound, "repository not found")
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/unknown/gate", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+	})
+
+	t.Run("sla enforces SLA", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			EvaluateGateFunc: func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+				gt.True(t, input.EnforceSLA)
+				return &model.GateResult{Pass: true}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/gate?sla=true", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+	})
+}
+
+func TestSLAAPI(t *testing.T) {
+	t.Run("returns SLA violations", func(t *testing.T) {
+		dueAt := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+		mockUC := &mock.UseCaseMock{
+			CheckSLAFunc: func(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error) {
+				gt.V(t, input.Owner).Equal("myorg")
+				gt.V(t, input.Repo).Equal("myrepo")
+				gt.V(t, input.Branch).Equal(types.BranchName("main"))
+				return &model.SLAReport{
+					Owner:   "myorg",
+					Policy:  "CRITICAL=7d",
+					Checked: 1,
+					Violations: []model.SLAViolation{{
+						Repository: "myorg/myrepo",
+						Branch:     "main",
+						ID:         "CVE-2024-0001",
+						Severity:   "CRITICAL",
+						VulnerabilityAge: model.VulnerabilityAge{
+							FirstDetectedAt: dueAt.Add(-7 * 24 * time.Hour),
+							DaysOpen:        10,
+							DueAt:           &dueAt,
+							Overdue:         true,
+						},
+						DaysOverdue: 3,
+					}},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/sla?branch=main", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var resp map[string]any
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		violations := resp["violations"].([]any)
+		gt.A(t, violations).Length(1)
+		violation := violations[0].(map[string]any)
+		gt.V(t, violation["days_open"]).Equal(float64(10))
+		gt.V(t, violation["days_overdue"]).Equal(float64(3))
+		gt.V(t, violation["due_at"]).Equal("2024-01-08T00:00:00Z")
+	})
+
+	t.Run("SLA not configured is bad request", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			CheckSLAFunc: func(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error) {
+				return nil, goerr.Wrap(types.ErrInvalidOption, "SLA policy is not configured")
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/sla", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+	})
+}
+
+func TestBranchStatusAPI(t *testing.T) {
+	t.Run("returns branch status", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			ListBranchStatusFunc: func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+				gt.V(t, input.Owner).Equal("myorg")
+				gt.V(t, input.Repo).Equal("myrepo")
+				findings := &model.FindingSummary{}
+				findings.Vulnerabilities.Add("HIGH")
+				return &model.RepositoryBranchStatus{
+					Repository:    "myorg/myrepo",
+					DefaultBranch: "main",
+					Branches: []*model.BranchStatus{
+						{Name: "main", Default: true, Status: types.ScanStatusSuccess, Findings: findings},
+					},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var result model.RepositoryBranchStatus
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.A(t, result.Branches).Length(1)
+		gt.V(t, result.Branches[0].Findings.Vulnerabilities.High).Equal(1)
+	})
+
+	t.Run("unknown repository is not found", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			ListBranchStatusFunc: func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+				return nil, goerr.Wrap(repository.ErrNotFound, "repository not found")
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/unknown/branches", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+	})
+}
+
+func TestVulnerabilityRollupAPI(t *testing.T) {
+	t.Run("returns rollup", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetVulnerabilityRollupFunc: func(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error) {
+				gt.V(t, input.Owner).Equal("myorg")
+				gt.V(t, input.Repo).Equal("myrepo")
+				gt.True(t, input.IncludeFixed)
+				return &model.VulnerabilityRollup{
+					Repository: "myorg/myrepo",
+					Findings: []*model.RolledUpFinding{
+						{ID: "CVE-2024-0001", Branches: []*model.FindingPresence{{Branch: "main"}, {Branch: "dev"}}},
+					},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/vulnerabilities/rollup?include_fixed=true", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var result model.VulnerabilityRollup
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.A(t, result.Findings).Length(1)
+		gt.A(t, result.Findings[0].Branches).Length(2)
+	})
+
+	t.Run("invalid include_fixed", func(t *testing.T) {
+		srv := server.New(&mock.UseCaseMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/vulnerabilities/rollup?include_fixed=maybe", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+	})
+}
+
+func TestDeveloperSummaryAPI(t *testing.T) {
+	t.Run("returns developer summary", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetDeveloperSummaryFunc: func(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error) {
+				gt.V(t, input.Login).Equal("alice")
+				gt.V(t, input.Period).Equal(7 * 24 * time.Hour)
+				gt.V(t, input.Team).Equal("backend")
+				gt.V(t, input.Tag).Equal("tier-1")
+				return &model.DeveloperSummary{
+					Login: "alice",
+					Repositories: []*model.DeveloperRepoSummary{
+						{Repository: "myorg/myrepo", Owner: "myorg", Name: "myrepo", Branches: []string{"main"}},
+					},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/alice/repos?days=7&team=backend&tag=tier-1", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+
+		var result model.DeveloperSummary
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.A(t, result.Repositories).Length(1)
+		gt.V(t, result.Repositories[0].Repository).Equal("myorg/myrepo")
+	})
+
+	t.Run("invalid days is bad request", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/alice/repos?days=0", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+		gt.A(t, mockUC.GetDeveloperSummaryCalls()).Length(0)
+	})
+}
+
+func TestBackstageAPI(t *testing.T) {
+	t.Run("returns postures of entities", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetBackstagePostureFunc: func(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error) {
+				gt.A(t, input.Entities).Length(1)
+				gt.V(t, input.Entities[0].Ref()).Equal("component:default/my-service")
+				gt.V(t, input.MaxScanAge).Equal(12 * time.Hour)
+				return []*model.BackstagePosture{
+					{EntityRef: "component:default/my-service", Status: model.BackstagePostureOK, Repository: "myorg/my-service"},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithGateMaxScanAge(12*time.Hour))
+
+		body := `{"entities":[{"kind":"Component","metadata":{"name":"my-service","annotations":{"github.com/project-slug":"myorg/my-service"}}}]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/backstage/entities", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var resp struct {
+			Entities []*model.BackstagePosture `json:"entities"`
+		}
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.A(t, resp.Entities).Length(1)
+		gt.V(t, resp.Entities[0].Status).Equal(model.BackstagePostureOK)
+	})
+
+	t.Run("invalid body is bad request", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/backstage/entities", strings.NewReader("{"))
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+		gt.A(t, mockUC.GetBackstagePostureCalls()).Length(0)
+	})
+}
+
+func TestRepositoryMetadataAPI(t *testing.T) {
+	const token = "admin-secret"
+	newRequest := func(body, auth string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/repos/myorg/myrepo/metadata", strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		return req
+	}
+
+	t.Run("updates given fields", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			UpdateRepositoryMetadataFunc: func(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error) {
+				gt.V(t, input.Owner).Equal("myorg")
+				gt.V(t, input.Repo).Equal("myrepo")
+				gt.V(t, *input.Team).Equal("backend")
+				gt.Nil(t, input.Tags)
+				return &model.Repository{ID: "myorg/myrepo", Team: "backend", Tags: []string{"tier-1"}}, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithAdminToken(token))
+
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, newRequest(`{"team":"backend"}`, "Bearer "+token))
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Body.String()).Equal(`{"repository":"myorg/myrepo","tags":["tier-1"],"team":"backend"}`)
+	})
+
+	t.Run("wrong token is unauthorized", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC, server.WithAdminToken(token))
+
+		for _, auth := range []string{"", "Bearer wrong", token} {
+			rec := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(rec, newRequest(`{"team":"backend"}`, auth))
+			gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		}
+		gt.A(t, mockUC.UpdateRepositoryMetadataCalls()).Length(0)
+	})
+
+	t.Run("disabled without admin token", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC)
+
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, newRequest(`{"team":"backend"}`, "Bearer "))
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+		gt.A(t, mockUC.UpdateRepositoryMetadataCalls()).Length(0)
+	})
+
+	t.Run("invalid body is bad request", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC, server.WithAdminToken(token))
+
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, newRequest(`{"tags":"not-a-list"}`, "Bearer "+token))
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+	})
+}
+
+func TestSeverityOverrideAPI(t *testing.T) {
+	const token = "admin-secret"
+	const path = "/api/v1/owners/myorg/severity-overrides"
+	serve := func(srv *server.Server, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		return rec
+	}
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("puts override by the admin", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			PutSeverityOverrideFunc: func(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error) {
+				gt.V(t, override).Equal(&model.SeverityOverride{
+					Owner:    "myorg",
+					VulnID:   "CVE-2024-0001",
+					Severity: "low",
+					Reason:   "not reachable",
+					By:       "admin",
+				})
+				stored := *override
+				stored.Severity = types.SeverityLow
+				stored.CreatedAt, stored.UpdatedAt = createdAt, createdAt
+				return &stored, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithAdminToken(token))
+
+		rec := serve(srv, http.MethodPut, path, `{"vuln_id":"CVE-2024-0001","severity":"low","reason":"not reachable"}`)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Body.String()).Equal(`{"vuln_id":"CVE-2024-0001","severity":"LOW","reason":"not reachable","by":"admin","created_at":"2025-01-02T03:04:05Z","updated_at":"2025-01-02T03:04:05Z"}`)
+	})
+
+	t.Run("lists overrides", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			ListSeverityOverridesFunc: func(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+				gt.V(t, owner).Equal("myorg")
+				return []*model.SeverityOverride{
+					{Owner: owner, PkgName: "payment-sdk", Severity: types.SeverityCritical, CreatedAt: createdAt, UpdatedAt: createdAt},
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		rec := serve(srv, http.MethodGet, path, "")
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Body.String()).Equal(`{"severity_overrides":[{"pkg_name":"payment-sdk","severity":"CRITICAL","created_at":"2025-01-02T03:04:05Z","updated_at":"2025-01-02T03:04:05Z"}]}`)
+	})
+
+	t.Run("deletes override selected by query", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			DeleteSeverityOverrideFunc: func(ctx context.Context, input *model.DeleteSeverityOverrideInput) error {
+				gt.V(t, input).Equal(&model.DeleteSeverityOverrideInput{Owner: "myorg", VulnID: "CVE-2024-0001", PkgName: "github.com/example/lib"})
+				return nil
+			},
+		}
+		srv := server.New(mockUC, server.WithAdminToken(token))
+
+		rec := serve(srv, http.MethodDelete, path+"?vuln_id=CVE-2024-0001&pkg_name=github.com%2Fexample%2Flib", "")
+		gt.V(t, rec.Code).Equal(http.StatusNoContent)
+		gt.A(t, mockUC.DeleteSeverityOverrideCalls()).Length(1)
+	})
+
+	t.Run("disabled without admin token", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC)
+
+		gt.V(t, serve(srv, http.MethodPut, path, `{"vuln_id":"CVE-2024-0001","severity":"low"}`).Code).Equal(http.StatusMethodNotAllowed)
+		gt.A(t, mockUC.PutSeverityOverrideCalls()).Length(0)
+	})
+}
+
+func TestVulnerabilityDetailAPI(t *testing.T) {
+	var inputs []*model.GetVulnerabilityDetailInput
+	mockUC := &mock.UseCaseMock{
+		GetVulnerabilityDetailFunc: func(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error) {
+			inputs = append(inputs, input)
+			if input.VulnID == "CVE-0000-0000" {
+				return nil, goerr.Wrap(repository.ErrNotFound, "vulnerability not found")
+			}
+			return &model.VulnerabilityDetail{
+				ID:   input.VulnID,
+				CVSS: map[string]*model.CVSSDetail{"nvd": {V3Score: 9.8}},
+			}, nil
+		},
+	}
+	srv := server.New(mockUC)
+
+	t.Run("returns detail", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/branches/feature%2Fx/targets/abcdef/vulns/CVE-2024-0001", nil))
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var result model.VulnerabilityDetail
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.V(t, result.ID).Equal("CVE-2024-0001")
+		gt.V(t, result.CVSS["nvd"].V3Score).Equal(9.8)
+
+		input := inputs[len(inputs)-1]
+		gt.V(t, *input).Equal(model.GetVulnerabilityDetailInput{
+			Owner:    "myorg",
+			Repo:     "myrepo",
+			Branch:   "feature/x",
+			TargetID: "abcdef",
+			VulnID:   "CVE-2024-0001",
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/repos/myorg/myrepo/branches/main/targets/abcdef/vulns/CVE-0000-0000", nil))
+
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+	})
+}
+
+func TestPermalinkAPI(t *testing.T) {
+	var inputs []*model.GetPermalinkViewInput
+	mockUC := &mock.UseCaseMock{
+		GetPermalinkViewFunc: func(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error) {
+			inputs = append(inputs, input)
+			if input.VulnID == "CVE-0000-0000" {
+				return nil, goerr.Wrap(repository.ErrNotFound, "finding not found")
+			}
+			return &model.PermalinkView{Repository: "myorg/myrepo"}, nil
+		},
+	}
+	srv := server.New(mockUC)
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	gt.V(t, get("/r/myorg/myrepo")).Equal(http.StatusOK)
+	gt.V(t, get("/r/myorg/myrepo/b/feature%2Fx")).Equal(http.StatusOK)
+	gt.V(t, get("/r/myorg/myrepo/b/main/t/abcdef")).Equal(http.StatusOK)
+	gt.V(t, get("/r/myorg/myrepo/b/main/v/CVE-2024-0001")).Equal(http.StatusOK)
+	gt.V(t, get("/r/myorg/myrepo/b/main/v/CVE-0000-0000")).Equal(http.StatusNotFound)
+
+	gt.A(t, inputs).Length(5)
+	gt.V(t, *inputs[0]).Equal(model.GetPermalinkViewInput{Owner: "myorg", Repo: "myrepo"})
+	gt.V(t, inputs[1].Branch).Equal(types.BranchName("feature/x"))
+	gt.V(t, inputs[2].TargetID).Equal(types.TargetID("abcdef"))
+	gt.V(t, inputs[3].Branch).Equal(types.BranchName("main"))
+	gt.V(t, inputs[3].VulnID).Equal("CVE-2024-0001")
+}
+
+func TestScanRunAPI(t *testing.T) {
+	t.Run("returns the run", func(t *testing.T) {
+		createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockUC := &mock.UseCaseMock{
+			GetScanRunFunc: func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+				gt.V(t, runID).Equal(types.ScanRunID("run-1"))
+				return &model.ScanRun{
+					ID:        runID,
+					Status:    types.ScanRunRunning,
+					Trigger:   types.ScanTriggerPush,
+					RequestID: "req-1",
+					Owner:     "myorg",
+					RepoName:  "myrepo",
+					Branch:    "main",
+					CommitID:  "1234567890abcdef1234567890abcdef12345678",
+					CreatedAt: createdAt,
+					StartedAt: createdAt.Add(time.Second),
+				}, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scans/run-1", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var result map[string]any
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.V(t, result["id"]).Equal("run-1")
+		gt.V(t, result["status"]).Equal("running")
+		gt.V(t, result["trigger"]).Equal("push")
+		gt.V(t, result["request_id"]).Equal("req-1")
+		gt.V(t, result["repository"]).Equal("myorg/myrepo")
+		gt.V(t, result["started_at"]).Equal("2024-01-02T03:04:06Z")
+		_, finished := result["finished_at"]
+		gt.False(t, finished)
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetScanRunFunc: func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+				return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found")
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scans/unknown", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+	})
+}
+
+func TestOwnerScanRunAPI(t *testing.T) {
+	t.Run("returns the result of each repository", func(t *testing.T) {
+		startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockUC := &mock.UseCaseMock{
+			GetOwnerScanRunFunc: func(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+				gt.V(t, runID).Equal(types.OwnerScanRunID("run-1"))
+				run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceGitHub, types.ScanTriggerSchedule, "req-1", startedAt)
+				run.ID = runID
+				run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "ok", Branch: "main", Status: types.ScanRunSucceeded, ScanID: "scan-1", DurationMS: 1500})
+				run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "ng", Branch: "main", Status: types.ScanRunFailed, Error: "trivy failed"})
+				run.Finish(nil, startedAt.Add(time.Minute))
+				return run, nil
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/owner-scans/run-1", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var result model.OwnerScanRun
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		gt.V(t, result.ID).Equal(types.OwnerScanRunID("run-1"))
+		gt.V(t, result.Status).Equal(types.ScanRunFailed)
+		gt.V(t, result.Succeeded).Equal(1)
+		gt.V(t, result.Failed).Equal(1)
+		gt.V(t, result.FailedRepos()).Equal([]string{"myorg/ng"})
+		gt.V(t, result.Repositories[0].DurationMS).Equal(int64(1500))
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetOwnerScanRunFunc: func(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+				return nil, goerr.Wrap(repository.ErrNotFound, "owner scan run not found")
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/owner-scans/unknown", nil)
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusNotFound)
+	})
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+const (
+	sessionCookieName     = "octovy_session"
+	oauthStateCookieName  = "octovy_oauth_state"
+	oauthReturnCookieName = "octovy_oauth_return"
+
+	// sessionTTL limits how long a user keeps access after leaving allowed organizations and teams, because memberships are checked only at sign-in
+	sessionTTL    = 8 * time.Hour
+	oauthStateTTL = 10 * time.Minute
+)
+
+// principal is an authenticated user or API client. A principal bound to a tenant can access only data of the tenant.
+type principal struct {
+	name   string
+	method string
+	admin  bool
+	tenant *model.Tenant
+}
+
+type principalKey struct{}
+
+// principalFrom returns the principal authenticated by requireAuth, or nil if the request passed through without authentication
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+// authEnabled returns true if any authentication method for the read API and the dashboard is configured. They are public otherwise.
+func (x *config) authEnabled() bool {
+	return x.uiPassword != "" || len(x.apiTokens) > 0 || x.oidc != nil || x.oauth != nil || len(x.authPolicy.Tenants) > 0
+}
+
+// adminEnabled returns true if anyone can call admin endpoints
+func (x *config) adminEnabled() bool {
+	return x.adminToken != "" || (x.oauth != nil && len(x.authPolicy.AdminTeams) > 0)
+}
+
+// authenticate returns the principal of credentials of the request, or nil if the request has no valid credentials
+func (x *config) authenticate(r *http.Request) *principal {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return x.authenticateBearer(r, token)
+	}
+
+	if user, password, ok := r.BasicAuth(); ok && x.uiPassword != "" {
+		// Both are compared to take the same time regardless of which is wrong
+		userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(x.uiUser))
+		passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(x.uiPassword))
+		if userMatch&passwordMatch == 1 {
+			return &principal{name: user, method: "basic"}
+		}
+		return nil
+	}
+
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && x.oauth != nil {
+		if s := verifySession(x.sessionKey, cookie.Value, time.Now()); s != nil {
+			return &principal{name: s.Login, method: "github_oauth", admin: s.Admin, tenant: s.Tenant}
+		}
+	}
+
+	return nil
+}
+
+func (x *config) authenticateBearer(r *http.Request, token string) *principal {
+	if x.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(x.adminToken)) == 1 {
+		return &principal{name: "admin", method: "admin_token", admin: true}
+	}
+
+	// Every token is compared not to leak which one partially matched by timing
+	matched := false
+	for _, t := range x.apiTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			matched = true
+		}
+	}
+	if matched {
+		return &principal{name: "api_token", method: "api_token"}
+	}
+	if tenant, name, ok := x.authPolicy.TenantOfAPIToken(token); ok {
+		return &principal{name: "tenant:" + name, method: "api_token", tenant: tenant}
+	}
+
+	if x.oidc != nil {
+		claims, err := x.oidc.Verify(r.Context(), token)
+		if err != nil {
+			logging.From(r.Context()).Info("rejected OIDC token", slog.Any("error", err))
+			return nil
+		}
+		tenant, ok := x.authPolicy.TenantOfOIDC(claims)
+		if !ok {
+			logging.From(r.Context()).Warn("OIDC token is not allowed by policy",
+				slog.String("issuer", claims.Issuer),
+				slog.String("subject", claims.Subject),
+				slog.String("repository_owner", claims.RepositoryOwner),
+			)
+			return nil
+		}
+		return &principal{name: claims.Subject, method: "oidc", tenant: tenant}
+	}
+
+	return nil
+}
+
+// requireAuth rejects requests without valid credentials. Requests to read endpoints pass through if no authentication method is configured, but admin endpoints always require an admin principal, i.e. the admin token or a member of admin teams.
+func requireAuth(cfg *config, admin bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !admin && !cfg.authEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p := cfg.authenticate(r)
+			if p == nil {
+				writeUnauthorized(w, r, cfg)
+				return
+			}
+			if admin && !p.admin {
+				writeJSON(w, http.StatusForbidden, apiErrorResponse{Error: "forbidden"})
+				return
+			}
+
+			logger := logging.From(r.Context()).With(
+				slog.String("principal", p.name),
+				slog.String("auth_method", p.method),
+				slog.Any("tenant", p.tenant),
+			)
+			ctx := context.WithValue(logging.With(r.Context(), logger), principalKey{}, p)
+			if p.tenant != nil {
+				ctx = tenancy.With(ctx, p.tenant)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, cfg *config) {
+	// A browser opening the dashboard is sent to sign in with GitHub, and so is a browser opening a permalink, which comes back to the permalink after signing in
+	if cfg.oauth != nil && r.Method == http.MethodGet {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/auth/login", http.StatusFound)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/r/") && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(w, r, "/auth/login?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+	}
+
+	if cfg.uiPassword != "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="octovy", charset="UTF-8"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+}
+
+// oauthRoutes serves sign-in of the dashboard with GitHub OAuth. A signed-in user gets a session cookie signed by the session key, so that the server keeps no session state.
+func oauthRoutes(cfg *config) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/login", func(w http.ResponseWriter, r *http.Request) {
+			buf := make([]byte, 32)
+			if _, err := rand.Read(buf); err != nil {
+				handleAPIError(w, r, "fail to generate OAuth state", err)
+				return
+			}
+			state := base64.RawURLEncoding.EncodeToString(buf)
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     oauthStateCookieName,
+				Value:    state,
+				Path:     "/auth",
+				MaxAge:   int(oauthStateTTL.Seconds()),
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+			if returnTo := r.URL.Query().Get("return_to"); isLocalPath(returnTo) {
+				http.SetCookie(w, &http.Cookie{
+					Name:     oauthReturnCookieName,
+					Value:    url.QueryEscape(returnTo),
+					Path:     "/auth",
+					MaxAge:   int(oauthStateTTL.Seconds()),
+					HttpOnly: true,
+					Secure:   isSecureRequest(r),
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			http.Redirect(w, r, cfg.oauth.AuthCodeURL(state), http.StatusFound)
+		})
+
+		r.Get("/callback", func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			cookie, err := r.Cookie(oauthStateCookieName)
+			if err != nil || query.Get("state") == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+				writeJSON(w, http.StatusBadRequest, apiErrorResponse{Error: "invalid OAuth state"})
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     oauthStateCookieName,
+				Path:     "/auth",
+				MaxAge:   -1,
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+
+			// GitHub redirects with error parameter if the user denied the authorization
+			if query.Get("error") != "" || query.Get("code") == "" {
+				writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+				return
+			}
+
+			user, err := cfg.oauth.Exchange(r.Context(), query.Get("code"))
+			if err != nil {
+				if errors.Is(err, types.ErrUnauthorized) {
+					logging.From(r.Context()).Info("fail to exchange OAuth code", slog.Any("error", err))
+					writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+					return
+				}
+				handleAPIError(w, r, "fail to get GitHub user", err)
+				return
+			}
+
+			tenant, ok := cfg.authPolicy.TenantOfUser(user)
+			if !ok {
+				logging.From(r.Context()).Warn("GitHub user is not allowed by policy",
+					slog.String("login", user.Login),
+					slog.Any("orgs", user.Orgs),
+					slog.Any("teams", user.Teams),
+				)
+				writeJSON(w, http.StatusForbidden, apiErrorResponse{Error: "forbidden"})
+				return
+			}
+
+			expiresAt := time.Now().Add(sessionTTL)
+			value, err := signSession(cfg.sessionKey, &session{
+				Login:     user.Login,
+				Admin:     tenant == nil && cfg.authPolicy.IsAdmin(user),
+				Tenant:    tenant,
+				ExpiresAt: expiresAt.Unix(),
+			})
+			if err != nil {
+				handleAPIError(w, r, "fail to sign session", err)
+				return
+			}
+
+			logging.From(r.Context()).Info("GitHub user signed in", slog.String("login", user.Login))
+			http.SetCookie(w, &http.Cookie{
+				Name:     sessionCookieName,
+				Value:    value,
+				Path:     "/",
+				Expires:  expiresAt,
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+			http.Redirect(w, r, oauthReturnPath(w, r), http.StatusFound)
+		})
+
+		r.Get("/logout", func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     sessionCookieName,
+				Path:     "/",
+				MaxAge:   -1,
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+			writeJSON(w, http.StatusOK, map[string]string{"status": "signed out"})
+		})
+	}
+}
+
+// isSecureRequest returns true if the client connects with HTTPS, directly or through a TLS terminating proxy such as a load balancer
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// isLocalPath returns true if the path is of this server, so that signing in never redirects to another site
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.ContainsAny(path, "\\\r\n")
+}
+
+// oauthReturnPath returns the page to go back to after signing in, which was opened before signing in, or the dashboard. The cookie of the page is removed.
+func oauthReturnPath(w http.ResponseWriter, r *http.Request) string {
+	cookie, err := r.Cookie(oauthReturnCookieName)
+	if err != nil {
+		return "/"
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthReturnCookieName,
+		Path:     "/auth",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	path, err := url.QueryUnescape(cookie.Value)
+	if err != nil || !isLocalPath(path) {
+		return "/"
+	}
+	return path
+}
+
+type session struct {
+	Login     string        `json:"login"`
+	Admin     bool          `json:"admin"`
+	Tenant    *model.Tenant `json:"tenant,omitempty"`
+	ExpiresAt int64         `json:"exp"`
+}
+
+// signSession encodes the session as base64url JSON followed by its HMAC-SHA256 signature
+func signSession(key []byte, s *session) (string, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(sessionMAC(key, payload)), nil
+}
+
+// verifySession returns nil if the value is not signed by the key or the session is expired
+func verifySession(key []byte, value string, now time.Time) *session {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil
+	}
+	givenMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(givenMAC, sessionMAC(key, payload)) {
+		return nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var s session
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil
+	}
+	if s.Login == "" || !now.Before(time.Unix(s.ExpiresAt, 0)) {
+		return nil
+	}
+	return &s
+}
+
+func sessionMAC(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
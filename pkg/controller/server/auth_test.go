@@ -0,0 +1,340 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+func newAuthTestUseCase() *mock.UseCaseMock {
+	return &mock.UseCaseMock{
+		ListBranchStatusFunc: func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+			return &model.RepositoryBranchStatus{Repository: "myorg/myrepo"}, nil
+		},
+		UpdateRepositoryMetadataFunc: func(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error) {
+			return &model.Repository{ID: "myorg/myrepo", Team: *input.Team}, nil
+		},
+	}
+}
+
+func serveWith(srv *server.Server, method, path, body string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if setup != nil {
+		setup(req)
+	}
+	rec := httptest.NewRecorder()
+	srv.Mux().ServeHTTP(rec, req)
+	return rec
+}
+
+func bearer(token string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func TestAuthAPITokens(t *testing.T) {
+	srv := server.New(newAuthTestUseCase(),
+		server.WithAPITokens([]string{"token-1", "token-2"}),
+		server.WithAdminToken("admin-token"),
+	)
+	const path = "/api/v1/repos/myorg/myrepo/branches"
+
+	t.Run("read API requires a token", func(t *testing.T) {
+		rec := serveWith(srv, http.MethodGet, path, "", nil)
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		gt.V(t, rec.Header().Get("WWW-Authenticate")).Equal("Bearer")
+
+		gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("wrong")).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("token-1")).Code).Equal(http.StatusOK)
+		gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("token-2")).Code).Equal(http.StatusOK)
+		gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("admin-token")).Code).Equal(http.StatusOK)
+	})
+
+	t.Run("GraphQL API, permalinks and dashboard require a token", func(t *testing.T) {
+		gt.V(t, serveWith(srv, http.MethodPost, "/graphql", `{"query":"{ __typename }"}`, nil).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, serveWith(srv, http.MethodGet, "/r/myorg/myrepo", "", nil).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, serveWith(srv, http.MethodGet, "/", "", nil).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, serveWith(srv, http.MethodPost, "/graphql", `{"query":"{ __typename }"}`, bearer("token-1")).Code).Equal(http.StatusOK)
+	})
+
+	t.Run("admin endpoints require the admin token", func(t *testing.T) {
+		const metadataPath = "/api/v1/repos/myorg/myrepo/metadata"
+		gt.V(t, serveWith(srv, http.MethodPut, metadataPath, `{"team":"backend"}`, bearer("token-1")).Code).Equal(http.StatusForbidden)
+		gt.V(t, serveWith(srv, http.MethodPut, metadataPath, `{"team":"backend"}`, nil).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, serveWith(srv, http.MethodPut, metadataPath, `{"team":"backend"}`, bearer("admin-token")).Code).Equal(http.StatusOK)
+	})
+
+	t.Run("health check is public", func(t *testing.T) {
+		gt.V(t, serveWith(srv, http.MethodGet, "/health", "", nil).Code).Equal(http.StatusOK)
+	})
+}
+
+func TestAuthOIDC(t *testing.T) {
+	verifier := &mock.OIDCVerifierMock{
+		VerifyFunc: func(ctx context.Context, token string) (*model.OIDCClaims, error) {
+			switch token {
+			case "myorg-token":
+				return &model.OIDCClaims{Subject: "repo:myorg/app:ref:refs/heads/main", RepositoryOwner: "myorg"}, nil
+			case "other-token":
+				return &model.OIDCClaims{Subject: "repo:other/app:ref:refs/heads/main", RepositoryOwner: "other"}, nil
+			case "sa-token":
+				return &model.OIDCClaims{Subject: "deployer"}, nil
+			default:
+				return nil, goerr.Wrap(types.ErrUnauthorized, "invalid token")
+			}
+		},
+	}
+	srv := server.New(newAuthTestUseCase(),
+		server.WithOIDC(verifier),
+		server.WithAuthPolicy(&model.AuthPolicy{AllowedOrgs: []string{"myorg"}, OIDCSubjects: []string{"deployer"}}),
+	)
+	const path = "/api/v1/repos/myorg/myrepo/branches"
+
+	gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("myorg-token")).Code).Equal(http.StatusOK)
+	gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("sa-token")).Code).Equal(http.StatusOK)
+	gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("other-token")).Code).Equal(http.StatusUnauthorized)
+	gt.V(t, serveWith(srv, http.MethodGet, path, "", bearer("invalid")).Code).Equal(http.StatusUnauthorized)
+	gt.V(t, serveWith(srv, http.MethodGet, path, "", nil).Code).Equal(http.StatusUnauthorized)
+	gt.A(t, verifier.VerifyCalls()).Length(4)
+
+	// OIDC tokens never grant admin access
+	gt.V(t, serveWith(srv, http.MethodPut, "/api/v1/repos/myorg/myrepo/metadata", `{"team":"backend"}`, bearer("myorg-token")).Code).Equal(http.StatusNotFound)
+}
+
+func TestAuthGitHubOAuth(t *testing.T) {
+	oauth := &mock.GitHubOAuthMock{
+		AuthCodeURLFunc: func(state string) string {
+			return "https://github.com/login/oauth/authorize?state=" + url.QueryEscape(state)
+		},
+		ExchangeFunc: func(ctx context.Context, code string) (*model.GitHubOAuthUser, error) {
+			switch code {
+			case "member":
+				return &model.GitHubOAuthUser{Login: "alice", Orgs: []string{"myorg"}}, nil
+			case "admin":
+				return &model.GitHubOAuthUser{Login: "carol", Orgs: []string{"myorg"}, Teams: []string{"myorg/admins"}}, nil
+			case "outsider":
+				return &model.GitHubOAuthUser{Login: "mallory", Orgs: []string{"other"}}, nil
+			default:
+				return nil, goerr.Wrap(types.ErrUnauthorized, "bad verification code")
+			}
+		},
+	}
+	srv := server.New(newAuthTestUseCase(),
+		server.WithGitHubOAuth(oauth, []byte("0123456789abcdef0123456789abcdef")),
+		server.WithAuthPolicy(&model.AuthPolicy{AllowedOrgs: []string{"myorg"}, AdminTeams: []string{"myorg/admins"}}),
+	)
+
+	findCookie := func(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+	withCookies := func(cookies ...*http.Cookie) func(r *http.Request) {
+		return func(r *http.Request) {
+			for _, c := range cookies {
+				r.AddCookie(c)
+			}
+		}
+	}
+	signIn := func(t *testing.T, code string) *httptest.ResponseRecorder {
+		rec := serveWith(srv, http.MethodGet, "/auth/login", "", nil)
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		stateCookie := findCookie(rec, "octovy_oauth_state")
+		gt.NotNil(t, stateCookie)
+		gt.True(t, stateCookie.HttpOnly)
+		location, err := url.Parse(rec.Header().Get("Location"))
+		gt.NoError(t, err)
+		gt.V(t, location.Query().Get("state")).Equal(stateCookie.Value)
+
+		callback := "/auth/callback?code=" + code + "&state=" + url.QueryEscape(stateCookie.Value)
+		return serveWith(srv, http.MethodGet, callback, "", withCookies(stateCookie))
+	}
+
+	t.Run("dashboard redirects to sign-in", func(t *testing.T) {
+		rec := serveWith(srv, http.MethodGet, "/", "", nil)
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		gt.V(t, rec.Header().Get("Location")).Equal("/auth/login")
+
+		// API calls are not redirected
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", "", nil).Code).Equal(http.StatusUnauthorized)
+	})
+
+	t.Run("member signs in and reads data", func(t *testing.T) {
+		rec := signIn(t, "member")
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		gt.V(t, rec.Header().Get("Location")).Equal("/")
+		session := findCookie(rec, "octovy_session")
+		gt.NotNil(t, session)
+		gt.True(t, session.HttpOnly)
+		gt.V(t, session.SameSite).Equal(http.SameSiteLaxMode)
+
+		gt.V(t, serveWith(srv, http.MethodGet, "/", "", withCookies(session)).Code).Equal(http.StatusOK)
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", "", withCookies(session)).Code).Equal(http.StatusOK)
+
+		// Members of allowed organizations are not admins
+		gt.V(t, serveWith(srv, http.MethodPut, "/api/v1/repos/myorg/myrepo/metadata", `{"team":"backend"}`, withCookies(session)).Code).Equal(http.StatusForbidden)
+	})
+
+	t.Run("admin team member calls admin endpoints", func(t *testing.T) {
+		session := findCookie(signIn(t, "admin"), "octovy_session")
+		gt.NotNil(t, session)
+		gt.V(t, serveWith(srv, http.MethodPut, "/api/v1/repos/myorg/myrepo/metadata", `{"team":"backend"}`, withCookies(session)).Code).Equal(http.StatusOK)
+	})
+
+	t.Run("user out of allowed organizations is forbidden", func(t *testing.T) {
+		rec := signIn(t, "outsider")
+		gt.V(t, rec.Code).Equal(http.StatusForbidden)
+		gt.Nil(t, findCookie(rec, "octovy_session"))
+	})
+
+	t.Run("invalid code is unauthorized", func(t *testing.T) {
+		rec := signIn(t, "invalid")
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		gt.Nil(t, findCookie(rec, "octovy_session"))
+	})
+
+	t.Run("state must match", func(t *testing.T) {
+		rec := serveWith(srv, http.MethodGet, "/auth/callback?code=member&state=forged", "", withCookies(&http.Cookie{Name: "octovy_oauth_state", Value: "original"}))
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+		gt.V(t, serveWith(srv, http.MethodGet, "/auth/callback?code=member&state=forged", "", nil).Code).Equal(http.StatusBadRequest)
+	})
+
+	t.Run("tampered session is rejected", func(t *testing.T) {
+		session := findCookie(signIn(t, "member"), "octovy_session")
+		gt.NotNil(t, session)
+		payload, sig, _ := strings.Cut(session.Value, ".")
+		tampered := &http.Cookie{Name: "octovy_session", Value: payload + "x." + sig}
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", "", withCookies(tampered)).Code).Equal(http.StatusUnauthorized)
+
+		other := server.New(newAuthTestUseCase(),
+			server.WithGitHubOAuth(oauth, []byte("another-session-key-of-32-bytes!")),
+			server.WithAuthPolicy(&model.AuthPolicy{AllowedOrgs: []string{"myorg"}}),
+		)
+		gt.V(t, serveWith(other, http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", "", withCookies(session)).Code).Equal(http.StatusUnauthorized)
+	})
+
+	t.Run("logout clears session", func(t *testing.T) {
+		rec := serveWith(srv, http.MethodGet, "/auth/logout", "", nil)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		session := findCookie(rec, "octovy_session")
+		gt.NotNil(t, session)
+		gt.V(t, session.MaxAge).Equal(-1)
+	})
+}
+
+func TestAuthDisabled(t *testing.T) {
+	srv := server.New(newAuthTestUseCase())
+
+	gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/myorg/myrepo/branches", "", nil).Code).Equal(http.StatusOK)
+	gt.V(t, serveWith(srv, http.MethodGet, "/", "", nil).Code).Equal(http.StatusOK)
+	gt.V(t, serveWith(srv, http.MethodGet, "/auth/login", "", nil).Code).Equal(http.StatusNotFound)
+}
+
+func TestAuthTenants(t *testing.T) {
+	ctx := context.Background()
+	scanRepo := memory.New()
+	for _, r := range []*model.Repository{
+		{ID: "alpha-org/app", Owner: "alpha-org", Name: "app", DefaultBranch: "main"},
+		{ID: "beta-org/app", Owner: "beta-org", Name: "app", DefaultBranch: "main"},
+	} {
+		gt.NoError(t, scanRepo.CreateOrUpdateRepository(ctx, r))
+	}
+	uc := usecase.New(infra.New(infra.WithScanRepository(scanRepo)))
+
+	srv := server.New(uc,
+		server.WithAPITokens([]string{"operator-token"}),
+		server.WithAdminToken("admin-token"),
+		server.WithAuthPolicy(&model.AuthPolicy{
+			Tenants: []*model.TenantDefinition{
+				{Name: "alpha", Owners: []string{"alpha-org"}, APITokens: []string{"alpha-token"}},
+			},
+		}),
+	)
+
+	t.Run("tenant token reads only its repositories", func(t *testing.T) {
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/alpha-org/app/branches", "", bearer("alpha-token")).Code).Equal(http.StatusOK)
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/beta-org/app/branches", "", bearer("alpha-token")).Code).Equal(http.StatusNotFound)
+
+		rec := serveWith(srv, http.MethodPost, "/graphql", `{"query":"{ repositories(owner: \"beta-org\") { id } }"}`, bearer("alpha-token"))
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Body.String()).NotContains("beta-org/app")
+	})
+
+	t.Run("operator token reads all repositories", func(t *testing.T) {
+		gt.V(t, serveWith(srv, http.MethodGet, "/api/v1/repos/beta-org/app/branches", "", bearer("operator-token")).Code).Equal(http.StatusOK)
+	})
+
+	t.Run("tenant token is not admin", func(t *testing.T) {
+		gt.V(t, serveWith(srv, http.MethodPut, "/api/v1/repos/alpha-org/app/metadata", `{"team":"backend"}`, bearer("alpha-token")).Code).Equal(http.StatusForbidden)
+	})
+
+	t.Run("tenants enable authentication", func(t *testing.T) {
+		tenantOnly := server.New(uc, server.WithAuthPolicy(&model.AuthPolicy{
+			Tenants: []*model.TenantDefinition{
+				{Name: "alpha", Owners: []string{"alpha-org"}, APITokens: []string{"alpha-token"}},
+			},
+		}))
+		gt.V(t, serveWith(tenantOnly, http.MethodGet, "/api/v1/repos/beta-org/app/branches", "", nil).Code).Equal(http.StatusUnauthorized)
+	})
+}
+
+func TestAuthTenantSession(t *testing.T) {
+	var tenants []*model.Tenant
+	mockUC := &mock.UseCaseMock{
+		ListBranchStatusFunc: func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+			tenants = append(tenants, tenancy.From(ctx))
+			return &model.RepositoryBranchStatus{}, nil
+		},
+	}
+	oauth := &mock.GitHubOAuthMock{
+		AuthCodeURLFunc: func(state string) string { return "https://github.com/login/oauth/authorize" },
+		ExchangeFunc: func(ctx context.Context, code string) (*model.GitHubOAuthUser, error) {
+			return &model.GitHubOAuthUser{Login: "alice", Orgs: []string{"alpha-org"}, Teams: []string{"alpha-org/admins"}}, nil
+		},
+	}
+	srv := server.New(mockUC,
+		server.WithGitHubOAuth(oauth, []byte("0123456789abcdef0123456789abcdef")),
+		server.WithAuthPolicy(&model.AuthPolicy{
+			// Admin teams of the policy do not make tenant users admins
+			AdminTeams: []string{"operator/admins"},
+			Tenants: []*model.TenantDefinition{
+				{Name: "alpha", Owners: []string{"alpha-org"}, GitHubOrgs: []string{"alpha-org"}},
+			},
+		}),
+	)
+
+	state := &http.Cookie{Name: "octovy_oauth_state", Value: "state-1"}
+	rec := serveWith(srv, http.MethodGet, "/auth/callback?code=c&state=state-1", "", func(r *http.Request) { r.AddCookie(state) })
+	gt.V(t, rec.Code).Equal(http.StatusFound)
+	var session *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "octovy_session" {
+			session = c
+		}
+	}
+	gt.NotNil(t, session)
+
+	rec = serveWith(srv, http.MethodGet, "/api/v1/repos/alpha-org/app/branches", "", func(r *http.Request) { r.AddCookie(session) })
+	gt.V(t, rec.Code).Equal(http.StatusOK)
+	gt.A(t, tenants).Length(1).At(0, func(t testing.TB, v *model.Tenant) {
+		gt.A(t, v.Owners).Equal([]string{"alpha-org"})
+	})
+}
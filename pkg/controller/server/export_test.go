@@ -0,0 +1,19 @@
+package server
+
+import "time"
+
+type DeliveryCacheForTest = deliveryCache
+
+func NewDeliveryCacheForTest(ttl time.Duration, now func() time.Time) *DeliveryCacheForTest {
+	cache := newDeliveryCache(ttl)
+	cache.now = now
+	return cache
+}
+
+func (x *deliveryCache) ClaimForTest(id string) bool {
+	return x.claim(id)
+}
+
+func (x *deliveryCache) ReleaseForTest(id string) {
+	x.release(id)
+}
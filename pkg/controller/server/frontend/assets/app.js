@@ -0,0 +1,152 @@
+// Dashboard of repositories of an owner. Repositories are listed by the GraphQL API, and the last scan and active finding counts of the default branch are read from the branch status API.
+"use strict";
+
+const repositoriesQuery = `query($owner: String!, $tag: String, $team: String) {
+  repositories(owner: $owner, tag: $tag, team: $team) { owner name defaultBranch }
+}`;
+
+// A default branch not scanned for a week is highlighted as stale
+const staleAge = 7 * 24 * 60 * 60 * 1000;
+
+const severities = ["critical", "high", "medium", "low", "unknown"];
+
+async function fetchRepositories(filter) {
+  const resp = await fetch("/graphql", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({
+      query: repositoriesQuery,
+      variables: { owner: filter.owner, tag: filter.tag || null, team: filter.team || null },
+    }),
+  });
+  // A session of GitHub OAuth expires while the page is open. Reloading the page signs in again.
+  if (resp.status === 401) {
+    throw new Error("Not signed in or the session expired. Reload the page to sign in.");
+  }
+  if (!resp.ok) {
+    throw new Error(`failed to list repositories: ${resp.status}`);
+  }
+  const body = await resp.json();
+  if (body.errors && body.errors.length > 0) {
+    throw new Error(body.errors[0].message);
+  }
+  return body.data.repositories;
+}
+
+// fetchDefaultBranch returns status of the default branch, or the most recently scanned branch if the default branch has not been scanned
+async function fetchDefaultBranch(repo) {
+  const path = `/api/v1/repos/${encodeURIComponent(repo.owner)}/${encodeURIComponent(repo.name)}/branches`;
+  const resp = await fetch(path);
+  if (!resp.ok) {
+    throw new Error(`failed to get branches of ${repo.owner}/${repo.name}: ${resp.status}`);
+  }
+  const status = await resp.json();
+  return status.branches.length > 0 ? status.branches[0] : null;
+}
+
+function cell(row, text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  row.appendChild(td);
+  return td;
+}
+
+function renderRow(tbody, repo, branch) {
+  const row = document.createElement("tr");
+  const name = cell(row, "");
+  const link = document.createElement("a");
+  link.href = `https://github.com/${encodeURIComponent(repo.owner)}/${encodeURIComponent(repo.name)}`;
+  link.textContent = `${repo.owner}/${repo.name}`;
+  name.appendChild(link);
+
+  if (!branch) {
+    cell(row, repo.defaultBranch || "-");
+    cell(row, "not scanned");
+    for (let i = 0; i < 8; i++) {
+      cell(row, "", "count");
+    }
+    tbody.appendChild(row);
+    return;
+  }
+
+  cell(row, branch.name);
+  // last_scan_at is zero time if every scan of the branch failed before storing its result
+  const scannedAt = new Date(branch.last_scan_at);
+  const scanned = scannedAt.getUTCFullYear() > 1;
+  const stale = !scanned || Date.now() - scannedAt.getTime() > staleAge;
+  cell(row, scanned ? scannedAt.toLocaleString() : "-", stale ? "stale" : "");
+  const status = cell(row, branch.status, `status-${branch.status}`);
+  if (branch.error) {
+    status.title = branch.error;
+  }
+
+  const findings = branch.findings || { vulnerabilities: {} };
+  for (const severity of severities) {
+    const count = findings.vulnerabilities[severity] || 0;
+    cell(row, String(count), count > 0 && (severity === "critical" || severity === "high") ? `count ${severity}` : "count");
+  }
+  cell(row, String(findings.misconfigurations || 0), "count");
+  cell(row, String(findings.secrets || 0), "count");
+  tbody.appendChild(row);
+}
+
+function showMessage(text, isError) {
+  const message = document.getElementById("message");
+  message.textContent = text;
+  message.className = isError ? "error" : "";
+  message.hidden = text === "";
+}
+
+async function render(filter) {
+  const table = document.getElementById("repositories");
+  const tbody = table.querySelector("tbody");
+  tbody.replaceChildren();
+  table.hidden = true;
+  showMessage("Loading...", false);
+
+  try {
+    const repos = await fetchRepositories(filter);
+    if (repos.length === 0) {
+      showMessage(`No scanned repository of ${filter.owner}.`, false);
+      return;
+    }
+
+    const branches = await Promise.all(repos.map((repo) => fetchDefaultBranch(repo).catch(() => null)));
+    repos.forEach((repo, i) => renderRow(tbody, repo, branches[i]));
+    table.hidden = false;
+    showMessage("", false);
+  } catch (err) {
+    showMessage(err.message, true);
+  }
+}
+
+function main() {
+  const form = document.getElementById("filter");
+  const params = new URLSearchParams(window.location.search);
+  for (const key of ["owner", "tag", "team"]) {
+    form.elements[key].value = params.get(key) || "";
+  }
+
+  form.addEventListener("submit", (ev) => {
+    ev.preventDefault();
+    const query = new URLSearchParams();
+    for (const key of ["owner", "tag", "team"]) {
+      const value = form.elements[key].value.trim();
+      if (value !== "") {
+        query.set(key, value);
+      }
+    }
+    // Keep the filter in the URL so that the view can be bookmarked and shared
+    window.history.replaceState(null, "", `?${query.toString()}`);
+    render(Object.fromEntries(query));
+  });
+
+  if (params.get("owner")) {
+    render(Object.fromEntries(params));
+  }
+}
+
+main();
@@ -2,44 +2,146 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
+	"slices"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/go-github/v53/github"
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // handleGitHubAppEventResult represents the result of validating and parsing a GitHub App event.
-// If ScanInput is nil, no scan is required (either no scan needed or validation failed).
+// If ScanInput is nil, no scan is required (either no scan needed or validation failed). SeedInput is set for installation events.
 type handleGitHubAppEventResult struct {
 	ScanInput *model.ScanGitHubRepoInput
+	SeedInput *model.SeedInstallationReposInput
 }
 
 // validateGitHubAppEvent validates and parses a GitHub App webhook event.
 // It returns the scan input if a scan is required, or nil if no scan is needed.
 // This function is synchronous and should be called before starting background processing.
-func validateGitHubAppEvent(r *http.Request, key types.GitHubAppSecret) (*handleGitHubAppEventResult, error) {
-	ctx := r.Context()
+func validateGitHubAppEvent(r *http.Request, key types.GitHubAppSecret, triggers eventTriggers) (_ *handleGitHubAppEventResult, err error) {
+	ctx, span := tracing.Start(r.Context(), "github.webhook", trace.WithAttributes(
+		attribute.String("github.event", github.WebHookType(r)),
+		attribute.String("github.delivery", r.Header.Get("X-GitHub-Delivery")),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	payload, err := github.ValidatePayload(r, []byte(key))
 	if err != nil {
-		return nil, goerr.Wrap(err, "validating payload")
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, goerr.Wrap(types.ErrPayloadTooLarge, "reading payload", goerr.V("limit", maxErr.Limit))
+		}
+		return nil, goerr.Wrap(types.ErrUnauthorized, "validating payload", goerr.V("error", err.Error()))
 	}
 
 	event, err := github.ParseWebHook(github.WebHookType(r), payload)
 	if err != nil {
-		return nil, goerr.Wrap(err, "parsing webhook")
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "parsing webhook", goerr.V("error", err.Error()))
 	}
 
 	logging.From(ctx).With(slog.Any("event", event)).Info("Received GitHub App event")
 
-	scanInput := githubEventToScanInput(event)
-	return &handleGitHubAppEventResult{ScanInput: scanInput}, nil
+	result := &handleGitHubAppEventResult{
+		ScanInput: githubEventToScanInput(event, triggers),
+		SeedInput: githubEventToSeedInput(event),
+	}
+	if input := result.ScanInput; input != nil {
+		span.SetAttributes(
+			attribute.String("octovy.repository", input.Owner+"/"+input.RepoName),
+			attribute.String("octovy.commit", input.CommitID),
+			attribute.String("octovy.trigger", string(input.Trigger)),
+		)
+	}
+	return result, nil
+}
+
+// webhookSecret returns the secret of the GitHub App which sent the webhook, selected by the app ID in the path or in the X-GitHub-Hook-Installation-Target-ID header. An app ID in the path must be of a configured app, and the default secret is used for webhooks of other apps.
+func (cfg *config) webhookSecret(r *http.Request) (types.GitHubAppSecret, error) {
+	if param := chi.URLParam(r, "app_id"); param != "" {
+		appID, err := strconv.ParseInt(param, 10, 64)
+		secret, ok := cfg.ghSecrets[types.GitHubAppID(appID)]
+		if err != nil || !ok {
+			return "", goerr.Wrap(types.ErrUnauthorized, "GitHub App of path is not configured", goerr.V("app_id", param))
+		}
+		if secret == "" {
+			return "", goerr.Wrap(types.ErrUnauthorized, "GitHub App of path has no webhook secret", goerr.V("app_id", param))
+		}
+		return secret, nil
+	}
+
+	// GitHub sets the ID of the app as the target of the hook
+	if header := r.Header.Get("X-GitHub-Hook-Installation-Target-ID"); header != "" {
+		if appID, err := strconv.ParseInt(header, 10, 64); err == nil {
+			if secret, ok := cfg.ghSecrets[types.GitHubAppID(appID)]; ok {
+				// An empty secret would skip verification of the signature
+				if secret == "" {
+					return "", goerr.Wrap(types.ErrUnauthorized, "GitHub App of hook target has no webhook secret", goerr.V("app_id", header))
+				}
+				return secret, nil
+			}
+		}
+	}
+	return cfg.ghSecret, nil
+}
+
+type webhookErrorResponse struct {
+	Status  string `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// handleWebhookError responds with the type of the error without its details, which may include internals of the server. Only internal errors are reported to Sentry; rejected requests are logged as warnings.
+func handleWebhookError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	resp := webhookErrorResponse{Status: "error"}
+	code := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, types.ErrUnauthorized):
+		code, resp.Error, resp.Message = http.StatusUnauthorized, "unauthorized", "invalid webhook signature"
+	case errors.Is(err, types.ErrInvalidRequest):
+		code, resp.Error, resp.Message = http.StatusBadRequest, "invalid_request", "invalid webhook payload"
+	case errors.Is(err, types.ErrPayloadTooLarge):
+		code, resp.Error, resp.Message = http.StatusRequestEntityTooLarge, "payload_too_large", "webhook payload is too large"
+	default:
+		resp.Error, resp.Message = "internal", "internal server error"
+	}
+
+	if code == http.StatusInternalServerError {
+		errutil.HandleError(r.Context(), msg, err)
+	} else {
+		logging.From(r.Context()).Warn(msg, slog.Any("error", err), slog.Int("status_code", code))
+	}
+	writeJSON(w, code, resp)
+}
+
+type webhookAcceptedResponse struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	ScanRunID string `json:"scan_run_id,omitempty"`
+}
+
+// writeWebhookAccepted responds 202 Accepted with ID of the run, which can be tracked by GET /api/v1/scans/{id}, if the run is recorded
+func writeWebhookAccepted(w http.ResponseWriter, msg string, run *model.ScanRun) {
+	resp := webhookAcceptedResponse{Status: "accepted", Message: msg}
+	if run != nil {
+		resp.ScanRunID = run.ID.String()
+		w.Header().Set("Location", "/api/v1/scans/"+resp.ScanRunID)
+	}
+	writeJSON(w, http.StatusAccepted, resp)
 }
 
 // runGitHubRepoScan executes the GitHub repository scan in the provided context.
@@ -49,20 +151,39 @@ func runGitHubRepoScan(ctx context.Context, uc interfaces.UseCase, scanInput *mo
 	logger.Info("Starting GitHub repository scan")
 
 	if err := uc.ScanGitHubRepo(ctx, scanInput); err != nil {
-		logger.Error("Background scan failed", slog.Any("error", err))
+		errutil.HandleErrorWithTags(ctx, "Background scan failed", err, scanErrorTags(scanInput))
 	} else {
 		logger.Info("GitHub repository scan completed successfully")
 	}
 }
 
-func refToBranch(v string) string {
-	if ref := strings.SplitN(v, "/", 3); len(ref) == 3 && ref[0] == "refs" && ref[1] == "heads" {
-		return ref[2]
+// scanErrorTags returns metadata of the scan to tag its error report with
+func scanErrorTags(input *model.ScanGitHubRepoInput) map[string]string {
+	return map[string]string{
+		"owner":     input.Owner,
+		"repo":      input.RepoName,
+		"branch":    input.Branch,
+		"tag":       input.Tag,
+		"commit_id": input.CommitID,
+		"trigger":   string(input.Trigger),
+		"run_id":    string(input.RunID),
 	}
-	return v
 }
 
-func githubEventToScanInput(event interface{}) *model.ScanGitHubRepoInput {
+func refToBranch(v string) string {
+	return string(types.NewBranchName(v))
+}
+
+// eventTriggers enables events other than push and pull_request as scan triggers
+type eventTriggers struct {
+	// workflowRun enables workflow_run events completed on the default branch, of workflows in workflowNames if not empty
+	workflowRun   bool
+	workflowNames []string
+	// release enables release events of published releases
+	release bool
+}
+
+func githubEventToScanInput(event interface{}, triggers eventTriggers) *model.ScanGitHubRepoInput {
 	switch ev := event.(type) {
 	case *github.PushEvent:
 		if ev.HeadCommit == nil || ev.HeadCommit.ID == nil {
@@ -90,6 +211,7 @@ func githubEventToScanInput(event interface{}) *model.ScanGitHubRepoInput {
 				InstallationID: ev.GetInstallation().GetID(),
 			},
 			InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+			Trigger:   types.ScanTriggerPush,
 		}
 
 	case *github.PullRequestEvent:
@@ -133,15 +255,26 @@ func githubEventToScanInput(event interface{}) *model.ScanGitHubRepoInput {
 						Login: pr.GetBase().GetUser().GetLogin(),
 						Email: pr.GetBase().GetUser().GetEmail(),
 					},
+					Author: model.GitHubUser{
+						ID:    pr.GetUser().GetID(),
+						Login: pr.GetUser().GetLogin(),
+					},
 				},
 			},
 			InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+			Trigger:   types.ScanTriggerPullRequest,
 		}
 
 		return input
 
+	case *github.WorkflowRunEvent:
+		return workflowRunToScanInput(ev, triggers)
+
+	case *github.ReleaseEvent:
+		return releaseToScanInput(ev, triggers)
+
 	case *github.InstallationEvent, *github.InstallationRepositoriesEvent:
-		return nil // ignore
+		return nil // handled by githubEventToSeedInput
 
 	default:
 		logging.Default().Warn("unsupported event", slog.Any("event", fmt.Sprintf("%T", event)))
@@ -149,6 +282,122 @@ func githubEventToScanInput(event interface{}) *model.ScanGitHubRepoInput {
 	}
 }
 
+// workflowRunToScanInput returns input to scan the head commit of a workflow run completed on the default branch. It is for repositories whose default branch is updated by workflows, e.g. squash-merging bots, so that the commit is scanned after the workflow finishes.
+func workflowRunToScanInput(ev *github.WorkflowRunEvent, triggers eventTriggers) *model.ScanGitHubRepoInput {
+	run := ev.GetWorkflowRun()
+	switch {
+	case !triggers.workflowRun:
+		logging.Default().Debug("ignore workflow_run event because the trigger is disabled")
+		return nil
+	case ev.GetAction() != "completed":
+		logging.Default().Debug("ignore workflow_run event", slog.String("action", ev.GetAction()))
+		return nil
+	case run.GetHeadBranch() == "" || run.GetHeadBranch() != ev.GetRepo().GetDefaultBranch():
+		logging.Default().Debug("ignore workflow_run event not on the default branch", slog.String("branch", run.GetHeadBranch()))
+		return nil
+	case len(triggers.workflowNames) > 0 && !slices.Contains(triggers.workflowNames, run.GetName()):
+		logging.Default().Debug("ignore workflow_run event of other workflow", slog.String("workflow", run.GetName()))
+		return nil
+	}
+
+	return &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					RepoID:   ev.GetRepo().GetID(),
+					Owner:    ev.GetRepo().GetOwner().GetLogin(),
+					RepoName: ev.GetRepo().GetName(),
+				},
+				CommitID: run.GetHeadSHA(),
+				Branch:   run.GetHeadBranch(),
+				Ref:      "refs/heads/" + run.GetHeadBranch(),
+				Committer: model.GitHubUser{
+					Login: run.GetHeadCommit().GetCommitter().GetLogin(),
+					Email: run.GetHeadCommit().GetCommitter().GetEmail(),
+				},
+			},
+			DefaultBranch:  ev.GetRepo().GetDefaultBranch(),
+			InstallationID: ev.GetInstallation().GetID(),
+		},
+		InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+		Trigger:   types.ScanTriggerWorkflowRun,
+	}
+}
+
+// releaseToScanInput returns input to scan the tag of a published release. The payload has no commit of the tag, so it is resolved from the tag when the scan runs. The scan has no branch and is stored only in BigQuery, as well as a scan of a tag by CLI.
+func releaseToScanInput(ev *github.ReleaseEvent, triggers eventTriggers) *model.ScanGitHubRepoInput {
+	switch {
+	case !triggers.release:
+		logging.Default().Debug("ignore release event because the trigger is disabled")
+		return nil
+	case ev.GetAction() != "published":
+		logging.Default().Debug("ignore release event", slog.String("action", ev.GetAction()))
+		return nil
+	case ev.GetRelease().GetTagName() == "":
+		logging.Default().Warn("ignore release event without tag", slog.Any("event", ev))
+		return nil
+	}
+
+	return &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					RepoID:   ev.GetRepo().GetID(),
+					Owner:    ev.GetRepo().GetOwner().GetLogin(),
+					RepoName: ev.GetRepo().GetName(),
+				},
+				Ref: "refs/tags/" + ev.GetRelease().GetTagName(),
+			},
+			DefaultBranch:  ev.GetRepo().GetDefaultBranch(),
+			InstallationID: ev.GetInstallation().GetID(),
+			Tag:            ev.GetRelease().GetTagName(),
+		},
+		InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+		Trigger:   types.ScanTriggerRelease,
+	}
+}
+
+// githubEventToSeedInput returns input to register repositories in the ScanRepository when the GitHub App is installed or repositories are added to the installation. Other events return nil.
+func githubEventToSeedInput(event interface{}) *model.SeedInstallationReposInput {
+	switch ev := event.(type) {
+	case *github.InstallationEvent:
+		switch ev.GetAction() {
+		case "created", "new_permissions_accepted", "unsuspend":
+			return &model.SeedInstallationReposInput{
+				InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+			}
+		}
+		return nil
+
+	case *github.InstallationRepositoriesEvent:
+		if ev.GetAction() != "added" || len(ev.RepositoriesAdded) == 0 {
+			return nil
+		}
+		repos := make([]string, len(ev.RepositoriesAdded))
+		for i, repo := range ev.RepositoriesAdded {
+			repos[i] = repo.GetFullName()
+		}
+		return &model.SeedInstallationReposInput{
+			InstallID: types.GitHubAppInstallID(ev.GetInstallation().GetID()),
+			Repos:     repos,
+		}
+
+	default:
+		return nil
+	}
+}
+
+// runSeedInstallationRepos registers repositories of an installation in the provided context.
+// This function is designed to be called from a background goroutine.
+func runSeedInstallationRepos(ctx context.Context, uc interfaces.UseCase, input *model.SeedInstallationReposInput) {
+	logger := logging.From(ctx).With(slog.Any("input", input))
+	if _, err := uc.SeedInstallationRepos(ctx, input); err != nil {
+		errutil.HandleError(ctx, "failed to seed installation repositories", err)
+		return
+	}
+	logger.Info("Installation repositories seeded successfully")
+}
+
 func handleGitHubActionEvent(_ interfaces.UseCase, _ *http.Request) error {
 	return nil
 }
@@ -158,6 +407,14 @@ func RefToBranchForTest(v string) string {
 	return refToBranch(v)
 }
 
-func GithubEventToScanInputForTest(event interface{}) *model.ScanGitHubRepoInput {
-	return githubEventToScanInput(event)
+func GithubEventToScanInputForTest(event interface{}, options ...Option) *model.ScanGitHubRepoInput {
+	cfg := &config{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return githubEventToScanInput(event, cfg.triggers)
+}
+
+func GithubEventToSeedInputForTest(event interface{}) *model.SeedInstallationReposInput {
+	return githubEventToSeedInput(event)
 }
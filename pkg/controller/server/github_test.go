@@ -54,6 +54,12 @@ var testGitHubPush []byte
 //go:embed testdata/github/push.default.json
 var testGitHubPushDefault []byte
 
+//go:embed testdata/github/installation.created.json
+var testGitHubInstallationCreated []byte
+
+//go:embed testdata/github/installation_repositories.added.json
+var testGitHubInstallationRepositoriesAdded []byte
+
 func TestGitHubPullRequestSync(t *testing.T) {
 	const secret = "dummy"
 
@@ -71,6 +77,7 @@ func TestGitHubPullRequestSync(t *testing.T) {
 			}
 
 			mockUC := &mock.UseCaseMock{
+				CreateScanRunFunc: noScanRun,
 				ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 					defer wg.Done()
 					gt.V(t, input).Equal(tc.input)
@@ -126,9 +133,14 @@ func TestGitHubPullRequestSync(t *testing.T) {
 						ID:    605953,
 						Login: "m-mizutani",
 					},
+					Author: model.GitHubUser{
+						ID:    605953,
+						Login: "m-mizutani",
+					},
 				},
 			},
 			InstallID: 41633205,
+			Trigger:   types.ScanTriggerPullRequest,
 		},
 	}))
 
@@ -162,9 +174,14 @@ func TestGitHubPullRequestSync(t *testing.T) {
 						ID:    605953,
 						Login: "m-mizutani",
 					},
+					Author: model.GitHubUser{
+						ID:    605953,
+						Login: "m-mizutani",
+					},
 				},
 			},
 			InstallID: 41633205,
+			Trigger:   types.ScanTriggerPullRequest,
 		},
 	}))
 
@@ -197,6 +214,7 @@ func TestGitHubPullRequestSync(t *testing.T) {
 				InstallationID: 41633205,
 			},
 			InstallID: 41633205,
+			Trigger:   types.ScanTriggerPush,
 		},
 	}))
 
@@ -223,10 +241,64 @@ func TestGitHubPullRequestSync(t *testing.T) {
 				InstallationID: 41633205,
 			},
 			InstallID: 41633205,
+			Trigger:   types.ScanTriggerPush,
 		},
 	}))
 }
 
+func TestGitHubInstallationEvents(t *testing.T) {
+	const secret = "dummy"
+
+	testCases := map[string]struct {
+		event string
+		body  []byte
+		input *model.SeedInstallationReposInput
+	}{
+		"installation.created": {
+			event: "installation",
+			body:  testGitHubInstallationCreated,
+			input: &model.SeedInstallationReposInput{InstallID: 41633205},
+		},
+		"installation_repositories.added": {
+			event: "installation_repositories",
+			body:  testGitHubInstallationRepositoriesAdded,
+			input: &model.SeedInstallationReposInput{
+				InstallID: 41633205,
+				Repos:     []string{"m-mizutani/masq"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &mock.UseCaseMock{
+				SeedInstallationReposFunc: func(ctx context.Context, input *model.SeedInstallationReposInput) (int, error) {
+					return len(input.Repos), nil
+				},
+			}
+			srv := server.New(mockUC, server.WithGitHubSecret(secret))
+
+			w := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, tc.event, tc.body, secret))
+			gt.V(t, w.Code).Equal(http.StatusAccepted)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			gt.NoError(t, srv.Shutdown(ctx))
+
+			calls := mockUC.SeedInstallationReposCalls()
+			gt.A(t, calls).Length(1)
+			gt.V(t, calls[0].Input).Equal(tc.input)
+			gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(0)
+		})
+	}
+}
+
+// noScanRun is CreateScanRun of a server without ScanRepository, which does not record runs
+func noScanRun(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+	return nil, nil
+}
+
 func newGitHubWebhookRequest(t *testing.T, event string, body []byte, secret types.GitHubAppSecret) *http.Request {
 	req := gt.R1(http.NewRequest(http.MethodPost, "/webhook/github/app", bytes.NewReader(body))).NoError(t)
 
@@ -244,8 +316,9 @@ func newGitHubWebhookRequest(t *testing.T, event string, body []byte, secret typ
 func TestGitHubInvalidSignature(t *testing.T) {
 	const secret = "test-secret"
 
-	t.Run("invalid signature returns 500", func(t *testing.T) {
+	t.Run("invalid signature returns 401", func(t *testing.T) {
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				return nil
 			},
@@ -265,12 +338,15 @@ func TestGitHubInvalidSignature(t *testing.T) {
 		rec := httptest.NewRecorder()
 		srv.Mux().ServeHTTP(rec, req)
 
-		gt.V(t, rec.Code).Equal(http.StatusInternalServerError)
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		gt.V(t, rec.Header().Get("Content-Type")).Equal("application/json")
+		gt.V(t, rec.Body.String()).Equal(`{"status":"error","error":"unauthorized","message":"invalid webhook signature"}`)
 		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(0)
 	})
 
-	t.Run("malformed payload returns 500", func(t *testing.T) {
+	t.Run("malformed payload returns 400", func(t *testing.T) {
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				return nil
 			},
@@ -284,7 +360,8 @@ func TestGitHubInvalidSignature(t *testing.T) {
 		rec := httptest.NewRecorder()
 		srv.Mux().ServeHTTP(rec, req)
 
-		gt.V(t, rec.Code).Equal(http.StatusInternalServerError)
+		gt.V(t, rec.Code).Equal(http.StatusBadRequest)
+		gt.V(t, rec.Body.String()).Equal(`{"status":"error","error":"invalid_request","message":"invalid webhook payload"}`)
 		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(0)
 	})
 
@@ -293,6 +370,7 @@ func TestGitHubInvalidSignature(t *testing.T) {
 		wg.Add(1)
 
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				defer wg.Done()
 				return errors.New("intentional error")
@@ -320,6 +398,7 @@ func TestGitHubInvalidSignature(t *testing.T) {
 		wg.Add(1)
 
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				defer wg.Done()
 				panic("intentional panic for testing")
@@ -342,3 +421,282 @@ func TestGitHubInvalidSignature(t *testing.T) {
 		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(1)
 	})
 }
+
+func TestGitHubWebhookRedelivery(t *testing.T) {
+	const secret = "test-secret"
+
+	post := func(srv *server.Server, deliveryID string) *httptest.ResponseRecorder {
+		req := newGitHubWebhookRequest(t, "push", testGitHubPush, secret)
+		req.Header.Set("X-GitHub-Delivery", deliveryID)
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		return w
+	}
+	shutdown := func(srv *server.Server) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		gt.NoError(t, srv.Shutdown(ctx))
+	}
+	newMock := func() *mock.UseCaseMock {
+		return &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
+			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("redelivery of accepted event is ignored", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC, server.WithGitHubSecret(secret))
+		deliveryID := uuid.NewString()
+
+		gt.V(t, post(srv, deliveryID).Code).Equal(http.StatusAccepted)
+		w := post(srv, deliveryID)
+		gt.V(t, w.Code).Equal(http.StatusOK)
+		gt.S(t, w.Body.String()).Contains("duplicate delivery")
+		gt.V(t, post(srv, uuid.NewString()).Code).Equal(http.StatusAccepted)
+
+		shutdown(srv)
+		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(2)
+	})
+
+	t.Run("redelivery of rejected event is accepted", func(t *testing.T) {
+		srv := server.New(newMock(), server.WithGitHubSecret(secret))
+		shutdown(srv)
+		deliveryID := uuid.NewString()
+
+		gt.V(t, post(srv, deliveryID).Code).Equal(http.StatusServiceUnavailable)
+		gt.V(t, post(srv, deliveryID).Code).Equal(http.StatusServiceUnavailable)
+	})
+
+	t.Run("dedupe is disabled by zero TTL", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithWebhookDedupe(0))
+		deliveryID := uuid.NewString()
+
+		gt.V(t, post(srv, deliveryID).Code).Equal(http.StatusAccepted)
+		gt.V(t, post(srv, deliveryID).Code).Equal(http.StatusAccepted)
+
+		shutdown(srv)
+		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(2)
+	})
+}
+
+func TestGitHubWebhookScanRun(t *testing.T) {
+	const secret = "test-secret"
+
+	var scanned *model.ScanGitHubRepoInput
+	mockUC := &mock.UseCaseMock{
+		CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+			gt.V(t, input.Trigger).Equal(types.ScanTriggerPush)
+			input.RunID = "run-1"
+			return &model.ScanRun{ID: input.RunID, Status: types.ScanRunQueued}, nil
+		},
+		ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+			scanned = input
+			return nil
+		},
+	}
+	srv := server.New(mockUC, server.WithGitHubSecret(secret))
+
+	w := httptest.NewRecorder()
+	srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+	gt.V(t, w.Code).Equal(http.StatusAccepted)
+	gt.V(t, w.Body.String()).Equal(`{"status":"accepted","message":"scan enqueued","scan_run_id":"run-1"}`)
+	gt.V(t, w.Header().Get("Location")).Equal("/api/v1/scans/run-1")
+	gt.V(t, w.Header().Get("X-Request-Id")).NotEqual("")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	gt.NoError(t, srv.Shutdown(ctx))
+	gt.V(t, scanned.RunID).Equal(types.ScanRunID("run-1"))
+}
+
+func TestGitHubWebhookLimits(t *testing.T) {
+	const secret = "test-secret"
+
+	t.Run("body over the limit is rejected with 413", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithWebhookLimits(1024, 0))
+
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		gt.V(t, w.Code).Equal(http.StatusRequestEntityTooLarge)
+		gt.V(t, w.Body.String()).Equal(`{"status":"error","error":"payload_too_large","message":"webhook payload is too large"}`)
+	})
+
+	t.Run("body without content length is limited while reading", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{}
+		srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithWebhookLimits(1024, 0))
+
+		req := newGitHubWebhookRequest(t, "push", testGitHubPush, secret)
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		gt.V(t, w.Code).Equal(http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("webhook not handled within timeout is answered with 503", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+				<-release
+				return nil, nil
+			},
+			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+				return nil
+			},
+		}
+		srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithWebhookLimits(server.DefaultWebhookMaxBodySize, 100*time.Millisecond))
+
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		gt.V(t, w.Code).Equal(http.StatusServiceUnavailable)
+		gt.S(t, w.Body.String()).Contains("timed out")
+	})
+
+	t.Run("webhook within limits is accepted", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
+			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+				return nil
+			},
+		}
+		srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithWebhookLimits(server.DefaultWebhookMaxBodySize, time.Second))
+
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		gt.NoError(t, srv.Shutdown(ctx))
+	})
+}
+
+func TestGitHubMultipleApps(t *testing.T) {
+	secrets := map[types.GitHubAppID]types.GitHubAppSecret{
+		1111: "secret-of-1111",
+		2222: "secret-of-2222",
+	}
+	newServer := func(options ...server.Option) (*server.Server, *mock.UseCaseMock) {
+		mockUC := &mock.UseCaseMock{
+			SeedInstallationReposFunc: func(ctx context.Context, input *model.SeedInstallationReposInput) (int, error) {
+				return 0, nil
+			},
+		}
+		options = append([]server.Option{server.WithGitHubSecret("default-secret"), server.WithGitHubAppSecrets(secrets)}, options...)
+		srv := server.New(mockUC, options...)
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			gt.NoError(t, srv.Shutdown(ctx))
+		})
+		return srv, mockUC
+	}
+
+	testCases := map[string]struct {
+		path   string
+		target string
+		secret types.GitHubAppSecret
+		code   int
+	}{
+		"secret selected by target header": {
+			path:   "/webhook/github/app",
+			target: "2222",
+			secret: "secret-of-2222",
+			code:   http.StatusAccepted,
+		},
+		"secret selected by path": {
+			path:   "/webhook/github/app/1111",
+			secret: "secret-of-1111",
+			code:   http.StatusAccepted,
+		},
+		"path wins over target header": {
+			path:   "/webhook/github/app/1111",
+			target: "2222",
+			secret: "secret-of-2222",
+			code:   http.StatusUnauthorized,
+		},
+		"secret of another app is rejected": {
+			path:   "/webhook/github/app",
+			target: "1111",
+			secret: "secret-of-2222",
+			code:   http.StatusUnauthorized,
+		},
+		"default secret for unknown app": {
+			path:   "/webhook/github/app",
+			target: "3333",
+			secret: "default-secret",
+			code:   http.StatusAccepted,
+		},
+		"unknown app in path": {
+			path:   "/webhook/github/app/3333",
+			secret: "default-secret",
+			code:   http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			srv, mockUC := newServer()
+
+			req := newGitHubWebhookRequest(t, "installation", testGitHubInstallationCreated, tc.secret)
+			req.URL.Path = tc.path
+			if tc.target != "" {
+				req.Header.Set("X-GitHub-Hook-Installation-Target-ID", tc.target)
+			}
+
+			w := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(w, req)
+			gt.V(t, w.Code).Equal(tc.code)
+			if tc.code != http.StatusAccepted {
+				gt.A(t, mockUC.SeedInstallationReposCalls()).Length(0)
+			}
+		})
+	}
+
+	t.Run("unsigned payload to configured app is rejected", func(t *testing.T) {
+		// An app without a secret must not skip verification of the signature
+		srv, mockUC := newServer(server.WithGitHubAppSecrets(map[types.GitHubAppID]types.GitHubAppSecret{
+			1111: "secret-of-1111",
+			4444: "",
+		}))
+
+		for _, route := range []struct{ path, target string }{
+			{path: "/webhook/github/app/1111"},
+			{path: "/webhook/github/app/4444"},
+			{path: "/webhook/github/app", target: "1111"},
+			{path: "/webhook/github/app", target: "4444"},
+		} {
+			req := newGitHubWebhookRequest(t, "installation", testGitHubInstallationCreated, "")
+			req.Header.Del("X-Hub-Signature-256")
+			req.URL.Path = route.path
+			if route.target != "" {
+				req.Header.Set("X-GitHub-Hook-Installation-Target-ID", route.target)
+			}
+
+			w := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(w, req)
+			gt.V(t, w.Code).Equal(http.StatusUnauthorized)
+		}
+		gt.A(t, mockUC.SeedInstallationReposCalls()).Length(0)
+	})
+
+	t.Run("custom webhook path", func(t *testing.T) {
+		srv, _ := newServer(server.WithWebhookPath("/hooks/octovy"))
+
+		req := newGitHubWebhookRequest(t, "installation", testGitHubInstallationCreated, "secret-of-2222")
+		req.URL.Path = "/hooks/octovy/2222"
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+
+		req = newGitHubWebhookRequest(t, "installation", testGitHubInstallationCreated, "default-secret")
+		w = httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		gt.V(t, w.Code).Equal(http.StatusNotFound)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/go-github/v53/github"
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
 )
 
 func TestRefToBranch(t *testing.T) {
@@ -156,4 +157,107 @@ func TestGitHubEventToScanInput(t *testing.T) {
 		result := server.GithubEventToScanInputForTest(event)
 		gt.V(t, result).Equal(nil)
 	})
-}
\ No newline at end of file
+}
+
+func TestGitHubEventTriggers(t *testing.T) {
+	repo := &github.Repository{
+		ID:            github.Int64(123),
+		Owner:         &github.User{Login: github.String("owner")},
+		Name:          github.String("repo"),
+		DefaultBranch: github.String("main"),
+	}
+	installation := &github.Installation{ID: github.Int64(456)}
+	workflowRun := func(action, branch, name string) *github.WorkflowRunEvent {
+		return &github.WorkflowRunEvent{
+			Action: github.String(action),
+			WorkflowRun: &github.WorkflowRun{
+				Name:       github.String(name),
+				HeadBranch: github.String(branch),
+				HeadSHA:    github.String("aa0378cad00d375c1897c1b5b5a4dd125984b511"),
+				HeadCommit: &github.HeadCommit{Committer: &github.CommitAuthor{Login: github.String("bot")}},
+			},
+			Repo:         repo,
+			Installation: installation,
+		}
+	}
+	release := func(action string) *github.ReleaseEvent {
+		return &github.ReleaseEvent{
+			Action:       github.String(action),
+			Release:      &github.RepositoryRelease{TagName: github.String("v1.2.0")},
+			Repo:         repo,
+			Installation: installation,
+		}
+	}
+
+	t.Run("workflow run on default branch", func(t *testing.T) {
+		input := server.GithubEventToScanInputForTest(workflowRun("completed", "main", "merge"), server.WithWorkflowRunTrigger(nil))
+		gt.V(t, input).NotNil()
+		gt.V(t, input.CommitID).Equal("aa0378cad00d375c1897c1b5b5a4dd125984b511")
+		gt.V(t, input.Branch).Equal("main")
+		gt.V(t, input.Ref).Equal("refs/heads/main")
+		gt.V(t, input.Committer.Login).Equal("bot")
+		gt.V(t, input.InstallID).Equal(456)
+	})
+
+	t.Run("workflow run is ignored unless enabled", func(t *testing.T) {
+		gt.Nil(t, server.GithubEventToScanInputForTest(workflowRun("completed", "main", "merge")))
+	})
+
+	t.Run("workflow run not completed or on other branch is ignored", func(t *testing.T) {
+		gt.Nil(t, server.GithubEventToScanInputForTest(workflowRun("requested", "main", "merge"), server.WithWorkflowRunTrigger(nil)))
+		gt.Nil(t, server.GithubEventToScanInputForTest(workflowRun("completed", "feature", "merge"), server.WithWorkflowRunTrigger(nil)))
+	})
+
+	t.Run("workflow run is filtered by names", func(t *testing.T) {
+		opt := server.WithWorkflowRunTrigger([]string{"merge"})
+		gt.V(t, server.GithubEventToScanInputForTest(workflowRun("completed", "main", "merge"), opt)).NotNil()
+		gt.Nil(t, server.GithubEventToScanInputForTest(workflowRun("completed", "main", "lint"), opt))
+	})
+
+	t.Run("published release scans the tag", func(t *testing.T) {
+		input := server.GithubEventToScanInputForTest(release("published"), server.WithReleaseTrigger())
+		gt.V(t, input).NotNil()
+		gt.V(t, input.Tag).Equal("v1.2.0")
+		gt.V(t, input.Ref).Equal("refs/tags/v1.2.0")
+		gt.V(t, input.CommitID).Equal("")
+		gt.V(t, input.Branch).Equal("")
+	})
+
+	t.Run("release is ignored unless enabled or published", func(t *testing.T) {
+		gt.Nil(t, server.GithubEventToScanInputForTest(release("published")))
+		gt.Nil(t, server.GithubEventToScanInputForTest(release("edited"), server.WithReleaseTrigger()))
+	})
+}
+
+func TestGitHubEventToSeedInput(t *testing.T) {
+	installation := &github.Installation{ID: github.Int64(123)}
+
+	t.Run("installation created", func(t *testing.T) {
+		input := server.GithubEventToSeedInputForTest(&github.InstallationEvent{
+			Action:       github.String("created"),
+			Installation: installation,
+		})
+		gt.V(t, input).Equal(&model.SeedInstallationReposInput{InstallID: 123})
+	})
+
+	t.Run("installation deleted is ignored", func(t *testing.T) {
+		input := server.GithubEventToSeedInputForTest(&github.InstallationEvent{
+			Action:       github.String("deleted"),
+			Installation: installation,
+		})
+		gt.Nil(t, input)
+	})
+
+	t.Run("repositories removed is ignored", func(t *testing.T) {
+		input := server.GithubEventToSeedInputForTest(&github.InstallationRepositoriesEvent{
+			Action:              github.String("removed"),
+			Installation:        installation,
+			RepositoriesRemoved: []*github.Repository{{FullName: github.String("owner/repo")}},
+		})
+		gt.Nil(t, input)
+	})
+
+	t.Run("push is not a seed event", func(t *testing.T) {
+		gt.Nil(t, server.GithubEventToSeedInputForTest(&github.PushEvent{}))
+	})
+}
@@ -0,0 +1,398 @@
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+const (
+	maxGraphQLRequestSize = 64 << 10
+	// maxGraphQLDepth allows the deepest path of the schema, repositories.branches.targets.vulnerabilities.acknowledgement, with some margin
+	maxGraphQLDepth = 8
+)
+
+// errGraphQLInternal replaces messages of server side errors, which may include internal details, in GraphQL responses
+var errGraphQLInternal = errors.New("internal server error")
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphqlHandler serves queries of repositories, branches, targets and vulnerabilities stored in the scan repository. Errors are returned in the errors field of the response with 200 OK, as GraphQL over HTTP does.
+func graphqlHandler(uc interfaces.UseCase) http.HandlerFunc {
+	schema := graphql.MustParseSchema(graphqlSchema, &graphqlResolver{uc: uc},
+		graphql.MaxDepth(maxGraphQLDepth),
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize)).Decode(&req); err != nil {
+			handleAPIError(w, r, "invalid graphql request", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+			return
+		}
+		if req.Query == "" {
+			handleAPIError(w, r, "invalid graphql request", goerr.Wrap(types.ErrInvalidRequest, "query is required"))
+			return
+		}
+
+		resp := schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// graphqlError converts an error of usecase into an error of a field. Client errors are returned as they are, and others are reported and replaced with errGraphQLInternal.
+func graphqlError(ctx context.Context, msg string, err error) error {
+	if errors.Is(err, types.ErrInvalidOption) || errors.Is(err, types.ErrInvalidRequest) || errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	errutil.HandleError(ctx, msg, err)
+	return errGraphQLInternal
+}
+
+type graphqlResolver struct {
+	uc interfaces.UseCase
+}
+
+func (x *graphqlResolver) Repositories(ctx context.Context, args struct {
+	Owner string
+	Tag   *string
+	Team  *string
+}) ([]*repositoryResolver, error) {
+	input := &model.ListRepositoriesInput{Owner: args.Owner}
+	if args.Tag != nil {
+		input.Tag = *args.Tag
+	}
+	if args.Team != nil {
+		input.Team = *args.Team
+	}
+
+	repos, err := x.uc.ListRepositories(ctx, input)
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to list repositories", err)
+	}
+
+	resolvers := make([]*repositoryResolver, len(repos))
+	for i, repo := range repos {
+		resolvers[i] = &repositoryResolver{uc: x.uc, repo: repo}
+	}
+	return resolvers, nil
+}
+
+func (x *graphqlResolver) Repository(ctx context.Context, args struct {
+	Owner string
+	Name  string
+}) (*repositoryResolver, error) {
+	repo, err := x.uc.GetRepository(ctx, &model.GetRepositoryInput{Owner: args.Owner, Repo: args.Name})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to get repository", err)
+	}
+	return &repositoryResolver{uc: x.uc, repo: repo}, nil
+}
+
+type repositoryResolver struct {
+	uc   interfaces.UseCase
+	repo *model.Repository
+}
+
+func (x *repositoryResolver) ID() graphql.ID { return graphql.ID(x.repo.ID) }
+func (x *repositoryResolver) Owner() string  { return x.repo.Owner }
+func (x *repositoryResolver) Name() string   { return x.repo.Name }
+func (x *repositoryResolver) DefaultBranch() *string {
+	return optionalString(string(x.repo.DefaultBranch))
+}
+func (x *repositoryResolver) Team() *string             { return optionalString(x.repo.Team) }
+func (x *repositoryResolver) LastScanAt() *graphql.Time { return optionalTime(x.repo.LastScanAt) }
+func (x *repositoryResolver) CreatedAt() graphql.Time   { return graphql.Time{Time: x.repo.CreatedAt} }
+func (x *repositoryResolver) UpdatedAt() graphql.Time   { return graphql.Time{Time: x.repo.UpdatedAt} }
+
+func (x *repositoryResolver) Findings() *findingSummaryResolver {
+	return newFindingSummaryResolver(x.repo.Findings)
+}
+
+func (x *repositoryResolver) Tags() []string {
+	if x.repo.Tags == nil {
+		return []string{}
+	}
+	return x.repo.Tags
+}
+
+func (x *repositoryResolver) Branches(ctx context.Context) ([]*branchResolver, error) {
+	branches, err := x.uc.ListBranches(ctx, &model.ListBranchesInput{RepoID: x.repo.ID})
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to list branches", err)
+	}
+
+	resolvers := make([]*branchResolver, len(branches))
+	for i, branch := range branches {
+		resolvers[i] = &branchResolver{uc: x.uc, repo: x.repo, branch: branch}
+	}
+	return resolvers, nil
+}
+
+func (x *repositoryResolver) Branch(ctx context.Context, args struct{ Name string }) (*branchResolver, error) {
+	branches, err := x.uc.ListBranches(ctx, &model.ListBranchesInput{RepoID: x.repo.ID})
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to list branches", err)
+	}
+
+	name := types.NewBranchName(args.Name)
+	for _, branch := range branches {
+		if branch.Name == name {
+			return &branchResolver{uc: x.uc, repo: x.repo, branch: branch}, nil
+		}
+	}
+	return nil, nil
+}
+
+type branchResolver struct {
+	uc     interfaces.UseCase
+	repo   *model.Repository
+	branch *model.Branch
+}
+
+func (x *branchResolver) Name() string              { return string(x.branch.Name) }
+func (x *branchResolver) Status() string            { return toGraphQLEnum(string(x.branch.Status)) }
+func (x *branchResolver) LastScanID() *string       { return optionalString(string(x.branch.LastScanID)) }
+func (x *branchResolver) LastScanAt() *graphql.Time { return optionalTime(x.branch.LastScanAt) }
+func (x *branchResolver) LastCommitSha() *string {
+	return optionalString(string(x.branch.LastCommitSHA))
+}
+
+func (x *branchResolver) Findings() *findingSummaryResolver {
+	return newFindingSummaryResolver(x.branch.Findings)
+}
+
+func (x *branchResolver) Default() bool {
+	return x.repo.DefaultBranch != "" && x.branch.Name == x.repo.DefaultBranch
+}
+
+func (x *branchResolver) LastError() *string {
+	if x.branch.Status != types.ScanStatusFailure {
+		return nil
+	}
+	return optionalString(x.branch.LastError)
+}
+
+func (x *branchResolver) LastErrorAt() *graphql.Time {
+	if x.branch.Status != types.ScanStatusFailure {
+		return nil
+	}
+	return optionalTime(x.branch.LastErrorAt)
+}
+
+func (x *branchResolver) Targets(ctx context.Context, args struct{ Class *string }) ([]*targetResolver, error) {
+	targets, err := x.uc.ListTargets(ctx, &model.ListTargetsInput{RepoID: x.repo.ID, Branch: x.branch.Name})
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to list targets", err)
+	}
+
+	resolvers := make([]*targetResolver, 0, len(targets))
+	for _, target := range targets {
+		if args.Class != nil && target.Class != *args.Class {
+			continue
+		}
+		resolvers = append(resolvers, &targetResolver{uc: x.uc, repoID: x.repo.ID, branch: x.branch.Name, target: target})
+	}
+	return resolvers, nil
+}
+
+type targetResolver struct {
+	uc     interfaces.UseCase
+	repoID types.GitHubRepoID
+	branch types.BranchName
+	target *model.Target
+}
+
+func (x *targetResolver) ID() graphql.ID           { return graphql.ID(x.target.ID) }
+func (x *targetResolver) Target() string           { return x.target.Target }
+func (x *targetResolver) Class() string            { return x.target.Class }
+func (x *targetResolver) Type() string             { return x.target.Type }
+func (x *targetResolver) UpdatedAt() graphql.Time  { return graphql.Time{Time: x.target.UpdatedAt} }
+func (x *targetResolver) RemovedAt() *graphql.Time { return optionalTime(x.target.RemovedAt) }
+
+func (x *targetResolver) Vulnerabilities(ctx context.Context, args struct {
+	Severity *[]string
+	Status   *[]string
+	Type     *[]string
+}) ([]*vulnerabilityResolver, error) {
+	input := &model.ListVulnerabilitiesInput{
+		RepoID:   x.repoID,
+		Branch:   x.branch,
+		TargetID: x.target.ID,
+	}
+	// Enum values are validated by the schema, so they are converted without parsing
+	if args.Severity != nil {
+		for _, v := range *args.Severity {
+			input.Severities = append(input.Severities, types.Severity(v))
+		}
+	}
+	if args.Status != nil {
+		for _, v := range *args.Status {
+			input.Statuses = append(input.Statuses, types.VulnStatus(strings.ToLower(v)))
+		}
+	}
+	if args.Type != nil {
+		for _, v := range *args.Type {
+			input.Types = append(input.Types, types.FindingType(strings.ToLower(v)))
+		}
+	}
+
+	vulns, err := x.uc.ListVulnerabilities(ctx, input)
+	if err != nil {
+		return nil, graphqlError(ctx, "fail to list vulnerabilities", err)
+	}
+
+	resolvers := make([]*vulnerabilityResolver, len(vulns))
+	for i, vuln := range vulns {
+		resolvers[i] = &vulnerabilityResolver{vuln: vuln}
+	}
+	return resolvers, nil
+}
+
+type vulnerabilityResolver struct {
+	vuln *model.Vulnerability
+}
+
+func (x *vulnerabilityResolver) ID() graphql.ID   { return graphql.ID(x.vuln.ID) }
+func (x *vulnerabilityResolver) Status() string   { return toGraphQLEnum(string(x.vuln.Status)) }
+func (x *vulnerabilityResolver) PkgName() string  { return x.vuln.PkgName }
+func (x *vulnerabilityResolver) PkgPath() *string { return optionalString(x.vuln.PkgPath) }
+func (x *vulnerabilityResolver) PURL() *string    { return optionalString(x.vuln.PURL) }
+func (x *vulnerabilityResolver) InstalledVersion() *string {
+	return optionalString(x.vuln.InstalledVersion)
+}
+func (x *vulnerabilityResolver) FixedVersion() *string   { return optionalString(x.vuln.FixedVersion) }
+func (x *vulnerabilityResolver) Title() *string          { return optionalString(x.vuln.Title) }
+func (x *vulnerabilityResolver) PrimaryURL() *string     { return optionalString(x.vuln.PrimaryURL) }
+func (x *vulnerabilityResolver) CreatedAt() graphql.Time { return graphql.Time{Time: x.vuln.CreatedAt} }
+func (x *vulnerabilityResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: x.vuln.UpdatedAt} }
+
+func (x *vulnerabilityResolver) Type() string {
+	if x.vuln.Type == "" {
+		return toGraphQLEnum(string(types.FindingTypeVulnerability))
+	}
+	return toGraphQLEnum(string(x.vuln.Type))
+}
+
+// Severity returns UNKNOWN for a severity not defined in the schema, because the response is invalid otherwise
+func (x *vulnerabilityResolver) Severity() string {
+	severity, err := types.ParseSeverity(x.vuln.Severity)
+	if err != nil {
+		return string(types.SeverityUnknown)
+	}
+	return string(severity)
+}
+
+func (x *vulnerabilityResolver) OriginalSeverity() *string {
+	if x.vuln.OriginalSeverity == "" {
+		return nil
+	}
+	severity, err := types.ParseSeverity(x.vuln.OriginalSeverity)
+	if err != nil {
+		severity = types.SeverityUnknown
+	}
+	s := string(severity)
+	return &s
+}
+
+func (x *vulnerabilityResolver) Acknowledgement() *acknowledgementResolver {
+	if x.vuln.Acknowledgement == nil {
+		return nil
+	}
+	return &acknowledgementResolver{ack: x.vuln.Acknowledgement}
+}
+
+func (x *vulnerabilityResolver) Exploitability() *exploitabilityResolver {
+	if x.vuln.Exploitability == nil {
+		return nil
+	}
+	return &exploitabilityResolver{e: x.vuln.Exploitability}
+}
+
+type exploitabilityResolver struct {
+	e *model.Exploitability
+}
+
+func (x *exploitabilityResolver) EpssScore() float64      { return x.e.EPSSScore }
+func (x *exploitabilityResolver) EpssPercentile() float64 { return x.e.EPSSPercentile }
+func (x *exploitabilityResolver) KnownExploited() bool    { return x.e.KnownExploited }
+func (x *exploitabilityResolver) KevDateAdded() *string   { return optionalString(x.e.KEVDateAdded) }
+func (x *exploitabilityResolver) KevDueDate() *string     { return optionalString(x.e.KEVDueDate) }
+func (x *exploitabilityResolver) KevRansomware() bool     { return x.e.KEVRansomware }
+
+// findingSummaryResolver resolves counts of active findings stored in a branch or a repository. It is nil if the counts are not stored yet.
+type findingSummaryResolver struct {
+	s *model.FindingSummary
+}
+
+func newFindingSummaryResolver(s *model.FindingSummary) *findingSummaryResolver {
+	if s == nil {
+		return nil
+	}
+	return &findingSummaryResolver{s: s}
+}
+
+func (x *findingSummaryResolver) Vulnerabilities() *severityCountsResolver {
+	return &severityCountsResolver{c: &x.s.Vulnerabilities}
+}
+func (x *findingSummaryResolver) Misconfigurations() int32 { return int32(x.s.Misconfigurations) }
+func (x *findingSummaryResolver) Secrets() int32           { return int32(x.s.Secrets) }
+
+type severityCountsResolver struct {
+	c *model.SeverityCounts
+}
+
+func (x *severityCountsResolver) Critical() int32 { return int32(x.c.Critical) }
+func (x *severityCountsResolver) High() int32     { return int32(x.c.High) }
+func (x *severityCountsResolver) Medium() int32   { return int32(x.c.Medium) }
+func (x *severityCountsResolver) Low() int32      { return int32(x.c.Low) }
+func (x *severityCountsResolver) Unknown() int32  { return int32(x.c.Unknown) }
+func (x *severityCountsResolver) Total() int32    { return int32(x.c.Total) }
+
+type acknowledgementResolver struct {
+	ack *model.Acknowledgement
+}
+
+func (x *acknowledgementResolver) By() string               { return x.ack.By }
+func (x *acknowledgementResolver) Comment() *string         { return optionalString(x.ack.Comment) }
+func (x *acknowledgementResolver) At() graphql.Time         { return graphql.Time{Time: x.ack.At} }
+func (x *acknowledgementResolver) ExpiresAt() *graphql.Time { return optionalTime(x.ack.ExpiresAt) }
+
+func toGraphQLEnum(v string) string {
+	return strings.ToUpper(v)
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func optionalTime(v time.Time) *graphql.Time {
+	if v.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: v}
+}
@@ -0,0 +1,274 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, srv *server.Server, query string, variables map[string]any) (int, *graphqlResponse) {
+	body := gt.R1(json.Marshal(map[string]any{"query": query, "variables": variables})).NoError(t)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+	srv.Mux().ServeHTTP(rec, req)
+
+	var resp graphqlResponse
+	if rec.Code == http.StatusOK {
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec.Code, &resp
+}
+
+func TestGraphQL(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &model.Repository{
+		ID:            "myorg/myrepo",
+		Owner:         "myorg",
+		Name:          "myrepo",
+		DefaultBranch: "main",
+		Tags:          []string{"production"},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	newMock := func() *mock.UseCaseMock {
+		return &mock.UseCaseMock{
+			GetRepositoryFunc: func(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error) {
+				if input.Repo != "myrepo" {
+					return nil, goerr.Wrap(repository.ErrNotFound, "repository not found")
+				}
+				return repo, nil
+			},
+			ListRepositoriesFunc: func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+				return []*model.Repository{repo}, nil
+			},
+			ListBranchesFunc: func(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error) {
+				return []*model.Branch{
+					{Name: "feature", Status: types.ScanStatusFailure, LastError: "trivy failed", LastErrorAt: now},
+					{Name: "main", Status: types.ScanStatusSuccess, LastScanID: "scan-1", LastScanAt: now, LastCommitSHA: "abc",
+						Findings: &model.FindingSummary{Vulnerabilities: model.SeverityCounts{High: 2, Total: 2}, Secrets: 1}},
+				}, nil
+			},
+			ListTargetsFunc: func(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error) {
+				return []*model.Target{{ID: "t1", Target: "go.mod", Class: "lang-pkgs", Type: "gomod", UpdatedAt: now}}, nil
+			},
+			ListVulnerabilitiesFunc: func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+				return []*model.Vulnerability{
+					{
+						ID:               "CVE-2024-0001",
+						PkgName:          "golang.org/x/net",
+						InstalledVersion: "v0.1.0",
+						Severity:         "CRITICAL",
+						OriginalSeverity: "MEDIUM",
+						Status:           types.VulnStatusAcknowledged,
+						Acknowledgement:  &model.Acknowledgement{By: "alice", Comment: "not reachable", At: now},
+						Exploitability:   &model.Exploitability{EPSSScore: 0.5, EPSSPercentile: 0.9, KnownExploited: true, KEVDateAdded: "2024-01-10"},
+						CreatedAt:        now,
+						UpdatedAt:        now,
+					},
+				}, nil
+			},
+		}
+	}
+
+	t.Run("query nested repository data", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC)
+
+		code, resp := postGraphQL(t, srv, `query($owner: String!) {
+			repositories(owner: $owner, tag: "production") {
+				id
+				tags
+				branches {
+					name
+					default
+					status
+					lastError
+					targets {
+						target
+						vulnerabilities(severity: [CRITICAL, HIGH], status: [ACTIVE, ACKNOWLEDGED]) {
+							id
+							type
+							severity
+							originalSeverity
+							status
+							fixedVersion
+							acknowledgement { by comment expiresAt }
+							exploitability { epssScore knownExploited kevDateAdded kevDueDate }
+						}
+					}
+				}
+			}
+		}`, map[string]any{"owner": "myorg"})
+		gt.V(t, code).Equal(http.StatusOK)
+		gt.A(t, resp.Errors).Length(0)
+
+		var data struct {
+			Repositories []struct {
+				ID       string   `json:"id"`
+				Tags     []string `json:"tags"`
+				Branches []struct {
+					Name      string  `json:"name"`
+					Default   bool    `json:"default"`
+					Status    string  `json:"status"`
+					LastError *string `json:"lastError"`
+					Targets   []struct {
+						Target          string `json:"target"`
+						Vulnerabilities []struct {
+							ID               string  `json:"id"`
+							Type             string  `json:"type"`
+							Severity         string  `json:"severity"`
+							OriginalSeverity *string `json:"originalSeverity"`
+							Status           string  `json:"status"`
+							FixedVersion     *string `json:"fixedVersion"`
+							Acknowledgement  *struct {
+								By        string  `json:"by"`
+								Comment   string  `json:"comment"`
+								ExpiresAt *string `json:"expiresAt"`
+							} `json:"acknowledgement"`
+							Exploitability *struct {
+								EPSSScore      float64 `json:"epssScore"`
+								KnownExploited bool    `json:"knownExploited"`
+								KEVDateAdded   *string `json:"kevDateAdded"`
+								KEVDueDate     *string `json:"kevDueDate"`
+							} `json:"exploitability"`
+						} `json:"vulnerabilities"`
+					} `json:"targets"`
+				} `json:"branches"`
+			} `json:"repositories"`
+		}
+		gt.NoError(t, json.Unmarshal(resp.Data, &data))
+		gt.A(t, data.Repositories).Length(1)
+		gt.V(t, data.Repositories[0].ID).Equal("myorg/myrepo")
+
+		branches := data.Repositories[0].Branches
+		gt.A(t, branches).Length(2)
+		gt.False(t, branches[0].Default)
+		gt.V(t, branches[0].Status).Equal("FAILURE")
+		gt.V(t, *branches[0].LastError).Equal("trivy failed")
+		gt.True(t, branches[1].Default)
+		gt.V(t, branches[1].LastError).Nil()
+
+		vuln := branches[1].Targets[0].Vulnerabilities[0]
+		gt.V(t, vuln.Type).Equal("VULNERABILITY")
+		gt.V(t, vuln.Status).Equal("ACKNOWLEDGED")
+		gt.V(t, *vuln.OriginalSeverity).Equal("MEDIUM")
+		gt.V(t, vuln.FixedVersion).Nil()
+		gt.V(t, vuln.Acknowledgement.By).Equal("alice")
+		gt.V(t, vuln.Acknowledgement.ExpiresAt).Nil()
+		gt.V(t, vuln.Exploitability.EPSSScore).Equal(0.5)
+		gt.True(t, vuln.Exploitability.KnownExploited)
+		gt.V(t, *vuln.Exploitability.KEVDateAdded).Equal("2024-01-10")
+		gt.V(t, vuln.Exploitability.KEVDueDate).Nil()
+
+		// Filters are converted into values of the domain
+		gt.A(t, mockUC.ListRepositoriesCalls()).Length(1)
+		gt.V(t, mockUC.ListRepositoriesCalls()[0].Input.Tag).Equal("production")
+		input := mockUC.ListVulnerabilitiesCalls()[0].Input
+		gt.V(t, input.RepoID).Equal(types.GitHubRepoID("myorg/myrepo"))
+		gt.V(t, input.TargetID).Equal(types.TargetID("t1"))
+		gt.A(t, input.Severities).Equal([]types.Severity{types.SeverityCritical, types.SeverityHigh})
+		gt.A(t, input.Statuses).Equal([]types.VulnStatus{types.VulnStatusActive, types.VulnStatusAcknowledged})
+		gt.A(t, input.Types).Length(0)
+	})
+
+	t.Run("repository and branch are null if not found", func(t *testing.T) {
+		srv := server.New(newMock())
+
+		code, resp := postGraphQL(t, srv, `{
+			missing: repository(owner: "myorg", name: "unknown") { id }
+			found: repository(owner: "myorg", name: "myrepo") { branch(name: "develop") { name } }
+		}`, nil)
+		gt.V(t, code).Equal(http.StatusOK)
+		gt.A(t, resp.Errors).Length(0)
+		gt.V(t, string(resp.Data)).Equal(`{"missing":null,"found":{"branch":null}}`)
+	})
+
+	t.Run("stored finding counts are returned", func(t *testing.T) {
+		srv := server.New(newMock())
+
+		code, resp := postGraphQL(t, srv, `{
+			repository(owner: "myorg", name: "myrepo") {
+				lastScanAt
+				findings { secrets }
+				branches { name findings { vulnerabilities { high total } misconfigurations secrets } }
+			}
+		}`, nil)
+		gt.V(t, code).Equal(http.StatusOK)
+		gt.A(t, resp.Errors).Length(0)
+		gt.V(t, string(resp.Data)).Equal(`{"repository":{"lastScanAt":null,"findings":null,"branches":[` +
+			`{"name":"feature","findings":null},` +
+			`{"name":"main","findings":{"vulnerabilities":{"high":2,"total":2},"misconfigurations":0,"secrets":1}}]}}`)
+	})
+
+	t.Run("invalid enum value is rejected", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC)
+
+		code, resp := postGraphQL(t, srv, `{
+			repository(owner: "myorg", name: "myrepo") {
+				branches { targets { vulnerabilities(severity: [URGENT]) { id } } }
+			}
+		}`, nil)
+		gt.V(t, code).Equal(http.StatusOK)
+		gt.A(t, resp.Errors).Longer(0)
+		gt.A(t, mockUC.GetRepositoryCalls()).Length(0)
+	})
+
+	t.Run("internal error message is hidden", func(t *testing.T) {
+		mockUC := newMock()
+		mockUC.ListRepositoriesFunc = func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+			return nil, goerr.New("firestore: connection reset by peer")
+		}
+		srv := server.New(mockUC)
+
+		code, resp := postGraphQL(t, srv, `{ repositories(owner: "myorg") { id } }`, nil)
+		gt.V(t, code).Equal(http.StatusOK)
+		gt.A(t, resp.Errors).Length(1)
+		gt.V(t, resp.Errors[0].Message).Equal("internal server error")
+	})
+
+	t.Run("client error message is returned", func(t *testing.T) {
+		mockUC := newMock()
+		mockUC.ListRepositoriesFunc = func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "querying scan data requires Firestore")
+		}
+		srv := server.New(mockUC)
+
+		_, resp := postGraphQL(t, srv, `{ repositories(owner: "myorg") { id } }`, nil)
+		gt.A(t, resp.Errors).Length(1)
+		gt.S(t, resp.Errors[0].Message).Contains("requires Firestore")
+	})
+
+	t.Run("empty query is bad request", func(t *testing.T) {
+		srv := server.New(newMock())
+
+		code, _ := postGraphQL(t, srv, "", nil)
+		gt.V(t, code).Equal(http.StatusBadRequest)
+	})
+
+	t.Run("too deep query is rejected", func(t *testing.T) {
+		srv := server.New(newMock())
+
+		_, resp := postGraphQL(t, srv, `{ __schema { types { fields { type { ofType { ofType { ofType { ofType { name } } } } } } } } }`, nil)
+		gt.A(t, resp.Errors).Longer(0)
+	})
+}
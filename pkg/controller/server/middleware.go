@@ -1,20 +1,35 @@
 package server
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 	"time"
 
 	"log/slog"
 
-	"github.com/google/uuid"
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func preProcess(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger := logging.Default().With(slog.String("request_id", uuid.NewString()))
+		requestID, ctx := logging.CtxRequestID(r.Context())
+		logger := logging.Default().With(slog.String("request_id", requestID.String()))
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			logger = logger.With(slog.String("trace_id", sc.TraceID().String()))
+		}
 
-		ctx := logging.With(r.Context(), logger)
+		ctx = logging.With(ctx, logger)
+		w.Header().Set("X-Request-Id", requestID.String())
 
 		lw := &statusCodeLogger{
 			ResponseWriter: w,
@@ -37,6 +52,38 @@ func preProcess(next http.Handler) http.Handler {
 	})
 }
 
+// traceRequest records a span of the request, which is a child of the span of the traceparent header if given. The request ID is set before preProcess so that the span has it.
+func traceRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		_, ctx = logging.CtxRequestID(ctx)
+		ctx, span := tracing.Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		lw := &statusCodeLogger{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+		next.ServeHTTP(lw, r.WithContext(ctx))
+
+		// The route is known after routing, e.g. /api/v1/repos/{owner}/{repo}
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName("HTTP " + r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", lw.statusCode))
+		if lw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(lw.statusCode))
+		}
+	})
+}
+
 type statusCodeLogger struct {
 	http.ResponseWriter
 	statusCode int
@@ -46,3 +93,50 @@ func (x *statusCodeLogger) WriteHeader(code int) {
 	x.statusCode = code
 	x.ResponseWriter.WriteHeader(code)
 }
+
+const (
+	// DefaultWebhookMaxBodySize is the cap of webhook payloads sent by GitHub
+	DefaultWebhookMaxBodySize = 25 << 20
+	// DefaultWebhookTimeout is the time GitHub waits for a response of a webhook delivery
+	DefaultWebhookTimeout = 10 * time.Second
+	// DefaultWebhookPath is the path of webhooks of GitHub Apps
+	DefaultWebhookPath = "/webhook/github/app"
+)
+
+// limitWebhookRequest rejects webhooks whose body exceeds maxBodySize with 413 Payload Too Large, and responds 503 Service Unavailable to webhooks not handled within timeout. Scans triggered by webhooks run in background and are not limited by the timeout. Zero disables each limit.
+func limitWebhookRequest(maxBodySize int64, timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout > 0 {
+			next = http.TimeoutHandler(next, timeout, `{"status":"error","error":"timeout","message":"webhook handling timed out"}`)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBodySize > 0 {
+				if r.ContentLength > maxBodySize {
+					handleWebhookError(w, r, "reject too large webhook", goerr.Wrap(types.ErrPayloadTooLarge, "content length exceeds limit",
+						goerr.V("content_length", r.ContentLength),
+						goerr.V("limit", maxBodySize),
+					))
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireAdminToken rejects requests without the admin token as a bearer token
+func requireAdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -12,6 +12,12 @@ import (
 	"github.com/m-mizutani/octovy/pkg/infra"
 	"github.com/m-mizutani/octovy/pkg/usecase"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestMiddleware(t *testing.T) {
@@ -141,3 +147,37 @@ func TestMiddleware(t *testing.T) {
 		gt.V(t, w.Code).Equal(http.StatusOK)
 	})
 }
+
+func TestTraceRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	srv := server.New(usecase.New(infra.New()))
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	w := httptest.NewRecorder()
+	srv.Mux().ServeHTTP(w, req)
+	gt.V(t, w.Code).Equal(http.StatusOK)
+
+	spans := recorder.Ended()
+	gt.A(t, spans).Length(1)
+	span := spans[0]
+	gt.V(t, span.Name()).Equal("HTTP GET /health")
+	gt.V(t, span.SpanContext().TraceID().String()).Equal("0af7651916cd43dd8448eb211c80319c")
+	gt.V(t, span.Parent().SpanID().String()).Equal("b7ad6b7169203331")
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	gt.V(t, attrs["http.route"].AsString()).Equal("/health")
+	gt.V(t, attrs["http.response.status_code"].AsInt64()).Equal(int64(http.StatusOK))
+	gt.V(t, attrs[tracing.AttrRequestID].AsString()).Equal(w.Header().Get("X-Request-Id"))
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// permalinkRoutes serves permalinks built by model.Permalinks as JSON detail views
+func permalinkRoutes(uc interfaces.UseCase) func(r chi.Router) {
+	return func(r chi.Router) {
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			input, err := parsePermalinkRequest(r)
+			if err != nil {
+				handleAPIError(w, r, "invalid permalink", err)
+				return
+			}
+
+			view, err := uc.GetPermalinkView(r.Context(), input)
+			if err != nil {
+				handleAPIError(w, r, "fail to get permalink view", err)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, view)
+		}
+
+		r.Get("/{owner}/{repo}", handle)
+		r.Get("/{owner}/{repo}/b/{branch}", handle)
+		r.Get("/{owner}/{repo}/b/{branch}/t/{target}", handle)
+		r.Get("/{owner}/{repo}/b/{branch}/v/{vuln}", handle)
+	}
+}
+
+func parsePermalinkRequest(r *http.Request) (*model.GetPermalinkViewInput, error) {
+	var params [5]string
+	for i, key := range []string{"owner", "repo", "branch", "target", "vuln"} {
+		v, err := pathParam(r, key)
+		if err != nil {
+			return nil, err
+		}
+		params[i] = v
+	}
+
+	return &model.GetPermalinkViewInput{
+		Owner:    params[0],
+		Repo:     params[1],
+		Branch:   types.BranchName(params[2]),
+		TargetID: types.TargetID(params[3]),
+		VulnID:   params[4],
+	}, nil
+}
+
+// pathParam returns the unescaped URL parameter. chi matches routes against the escaped path if it has escaped characters such as "%2F" of a branch name with a slash.
+func pathParam(r *http.Request, key string) (string, error) {
+	v := chi.URLParam(r, key)
+	if r.URL.RawPath == "" {
+		return v, nil
+	}
+	unescaped, err := url.PathUnescape(v)
+	if err != nil {
+		return "", goerr.Wrap(types.ErrInvalidRequest, "invalid path parameter", goerr.V(key, v))
+	}
+	return unescaped, nil
+}
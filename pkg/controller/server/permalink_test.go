@@ -0,0 +1,135 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+func TestPermalink(t *testing.T) {
+	newUseCase := func() *mock.UseCaseMock {
+		return &mock.UseCaseMock{
+			GetPermalinkViewFunc: func(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error) {
+				if input.Owner != "myorg" || input.Repo != "myrepo" {
+					return nil, goerr.Wrap(repository.ErrNotFound, "repository not found")
+				}
+				return &model.PermalinkView{
+					Permalink:  "https://octovy.example.com/r/myorg/myrepo/b/feature%2Fx/v/CVE-2024-0001",
+					Repository: "myorg/myrepo",
+				}, nil
+			},
+		}
+	}
+
+	t.Run("valid link resolves to detail view", func(t *testing.T) {
+		uc := newUseCase()
+		srv := server.New(uc)
+
+		rec := serveWith(srv, http.MethodGet, "/r/myorg/myrepo/b/feature%2Fx/v/CVE-2024-0001", "", nil)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var view model.PermalinkView
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+		gt.V(t, view.Repository).Equal("myorg/myrepo")
+
+		gt.A(t, uc.GetPermalinkViewCalls()).Length(1).At(0, func(t testing.TB, v struct {
+			Ctx   context.Context
+			Input *model.GetPermalinkViewInput
+		}) {
+			gt.V(t, v.Input).Equal(&model.GetPermalinkViewInput{
+				Owner:  "myorg",
+				Repo:   "myrepo",
+				Branch: "feature/x",
+				VulnID: "CVE-2024-0001",
+			})
+		})
+	})
+
+	t.Run("target link has target ID", func(t *testing.T) {
+		uc := newUseCase()
+		srv := server.New(uc)
+
+		gt.V(t, serveWith(srv, http.MethodGet, "/r/myorg/myrepo/b/main/t/abc123", "", nil).Code).Equal(http.StatusOK)
+		gt.V(t, uc.GetPermalinkViewCalls()[0].Input.TargetID).Equal(types.TargetID("abc123"))
+	})
+
+	t.Run("unknown link is not found", func(t *testing.T) {
+		srv := server.New(newUseCase())
+		gt.V(t, serveWith(srv, http.MethodGet, "/r/other/repo", "", nil).Code).Equal(http.StatusNotFound)
+		gt.V(t, serveWith(srv, http.MethodGet, "/r/myorg/myrepo/b/main/x/CVE-2024-0001", "", nil).Code).Equal(http.StatusNotFound)
+	})
+
+	t.Run("browser is redirected to sign in and back to the link", func(t *testing.T) {
+		oauth := &mock.GitHubOAuthMock{
+			AuthCodeURLFunc: func(state string) string {
+				return "https://github.com/login/oauth/authorize?state=" + url.QueryEscape(state)
+			},
+			ExchangeFunc: func(ctx context.Context, code string) (*model.GitHubOAuthUser, error) {
+				return &model.GitHubOAuthUser{Login: "alice", Orgs: []string{"myorg"}}, nil
+			},
+		}
+		srv := server.New(newUseCase(),
+			server.WithGitHubOAuth(oauth, []byte("0123456789abcdef0123456789abcdef")),
+			server.WithAuthPolicy(&model.AuthPolicy{AllowedOrgs: []string{"myorg"}}),
+		)
+		const link = "/r/myorg/myrepo/b/feature%2Fx/v/CVE-2024-0001"
+		browser := func(cookies ...*http.Cookie) func(r *http.Request) {
+			return func(r *http.Request) {
+				r.Header.Set("Accept", "text/html,application/xhtml+xml")
+				for _, c := range cookies {
+					r.AddCookie(c)
+				}
+			}
+		}
+		cookieOf := func(resp *http.Response, name string) *http.Cookie {
+			for _, c := range resp.Cookies() {
+				if c.Name == name {
+					return c
+				}
+			}
+			return nil
+		}
+
+		// API clients are not redirected
+		gt.V(t, serveWith(srv, http.MethodGet, link, "", nil).Code).Equal(http.StatusUnauthorized)
+
+		rec := serveWith(srv, http.MethodGet, link, "", browser())
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		gt.V(t, rec.Header().Get("Location")).Equal("/auth/login?return_to=" + url.QueryEscape(link))
+
+		rec = serveWith(srv, http.MethodGet, rec.Header().Get("Location"), "", browser())
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		state := cookieOf(rec.Result(), "octovy_oauth_state")
+		returnTo := cookieOf(rec.Result(), "octovy_oauth_return")
+		gt.NotNil(t, state)
+		gt.NotNil(t, returnTo)
+
+		rec = serveWith(srv, http.MethodGet, "/auth/callback?code=member&state="+url.QueryEscape(state.Value), "", browser(state, returnTo))
+		gt.V(t, rec.Code).Equal(http.StatusFound)
+		gt.V(t, rec.Header().Get("Location")).Equal(link)
+		session := cookieOf(rec.Result(), "octovy_session")
+		gt.NotNil(t, session)
+
+		gt.V(t, serveWith(srv, http.MethodGet, link, "", browser(session)).Code).Equal(http.StatusOK)
+
+		t.Run("return path to another site is ignored", func(t *testing.T) {
+			rec := serveWith(srv, http.MethodGet, "/auth/login?return_to="+url.QueryEscape("//evil.example.com/r/x"), "", browser())
+			gt.V(t, rec.Code).Equal(http.StatusFound)
+			gt.Nil(t, cookieOf(rec.Result(), "octovy_oauth_return"))
+
+			state := cookieOf(rec.Result(), "octovy_oauth_state")
+			forged := &http.Cookie{Name: "octovy_oauth_return", Value: url.QueryEscape("https://evil.example.com")}
+			rec = serveWith(srv, http.MethodGet, "/auth/callback?code=member&state="+url.QueryEscape(state.Value), "", browser(state, forged))
+			gt.V(t, rec.Header().Get("Location")).Equal("/")
+		})
+	})
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const (
+	defaultAgentLeaseTimeout = 30 * time.Minute
+
+	// maxAgentResultSize limits the request body of a scan result. A report with all packages of a large monorepo can be tens of megabytes.
+	maxAgentResultSize = 256 * 1024 * 1024
+)
+
+// agentQueue holds scans delegated to scan agents, which poll the server to claim jobs, scan the source code in their own network and report the results. Jobs are kept in memory, so pending jobs are lost on restart and agents must poll the same server instance that received the webhooks.
+type agentQueue struct {
+	uc    interfaces.UseCase
+	repos []string
+	lease time.Duration
+
+	pending chan scanJob
+
+	mutex   sync.Mutex
+	claimed map[string]*claimedAgentJob
+	agents  map[string]time.Time
+
+	accepted  atomic.Int64
+	rejected  atomic.Int64
+	completed atomic.Int64
+	expired   atomic.Int64
+}
+
+type claimedAgentJob struct {
+	ctx       context.Context
+	job       *model.AgentScanJob
+	agent     string
+	claimedAt time.Time
+}
+
+// agentQueueStats is a snapshot of agentQueue exposed via /health/scan-agents.
+type agentQueueStats struct {
+	Capacity  int             `json:"capacity"`
+	Pending   int             `json:"pending"`
+	Claimed   int             `json:"claimed"`
+	Accepted  int64           `json:"accepted"`
+	Rejected  int64           `json:"rejected"`
+	Completed int64           `json:"completed"`
+	Expired   int64           `json:"expired"`
+	Agents    []agentLastSeen `json:"agents"`
+}
+
+type agentLastSeen struct {
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+func (x agentQueueStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("capacity", x.Capacity),
+		slog.Int("pending", x.Pending),
+		slog.Int("claimed", x.Claimed),
+		slog.Int64("accepted", x.Accepted),
+		slog.Int64("rejected", x.Rejected),
+		slog.Int64("completed", x.Completed),
+		slog.Int64("expired", x.Expired),
+		slog.Int("agents", len(x.Agents)),
+	)
+}
+
+func newAgentQueue(uc interfaces.UseCase, repos []string, capacity int, lease time.Duration) *agentQueue {
+	if capacity < 0 {
+		capacity = 0
+	}
+	if lease <= 0 {
+		lease = defaultAgentLeaseTimeout
+	}
+
+	normalized := make([]string, len(repos))
+	for i, repo := range repos {
+		normalized[i] = strings.ToLower(strings.TrimSpace(repo))
+	}
+
+	return &agentQueue{
+		uc:      uc,
+		repos:   normalized,
+		lease:   lease,
+		pending: make(chan scanJob, capacity),
+		claimed: make(map[string]*claimedAgentJob),
+		agents:  make(map[string]time.Time),
+	}
+}
+
+// delegates returns true if scans of the repository are delegated to agents. A delegated repository is given as "owner" or "owner/repo".
+func (x *agentQueue) delegates(owner, repo string) bool {
+	owner = strings.ToLower(owner)
+	fullName := owner + "/" + strings.ToLower(repo)
+	for _, r := range x.repos {
+		if r == owner || r == fullName {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue adds a scan for agents without blocking. It returns false if the queue is full, and the caller should shed the request.
+func (x *agentQueue) enqueue(ctx context.Context, input *model.ScanGitHubRepoInput) bool {
+	select {
+	case x.pending <- scanJob{ctx: ctx, input: input}:
+		x.accepted.Add(1)
+		return true
+	default:
+		x.rejected.Add(1)
+		return false
+	}
+}
+
+// claim returns the next job for the agent, or nil if there is no pending job. A pending scan whose commit has already been scanned is skipped, and a scan which can not be prepared is dropped after its failure is recorded.
+func (x *agentQueue) claim(ctx context.Context, agent string) *model.AgentScanJob {
+	x.expire(ctx)
+
+	x.mutex.Lock()
+	x.agents[agent] = time.Now()
+	x.mutex.Unlock()
+
+	for {
+		var pending scanJob
+		select {
+		case pending = <-x.pending:
+		default:
+			return nil
+		}
+
+		job, err := x.uc.PrepareAgentScanJob(pending.ctx, pending.input)
+		if err != nil {
+			errutil.HandleError(pending.ctx, "fail to prepare agent scan job", err)
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		x.mutex.Lock()
+		x.claimed[job.ID] = &claimedAgentJob{ctx: pending.ctx, job: job, agent: agent, claimedAt: time.Now()}
+		x.mutex.Unlock()
+
+		logging.From(ctx).Info("agent scan job claimed",
+			slog.String("job_id", job.ID),
+			slog.String("agent", agent),
+			slog.String("owner", job.Metadata.Owner),
+			slog.String("repo", job.Metadata.RepoName),
+			slog.String("commit", job.Metadata.CommitID),
+		)
+		return job
+	}
+}
+
+// complete stores the result of the claimed job. It returns repository.ErrNotFound if the job is unknown, e.g. its lease has expired.
+func (x *agentQueue) complete(ctx context.Context, jobID string, result *model.AgentScanResult) error {
+	x.mutex.Lock()
+	claimed, ok := x.claimed[jobID]
+	delete(x.claimed, jobID)
+	x.mutex.Unlock()
+
+	if !ok {
+		return goerr.Wrap(repository.ErrNotFound, "agent scan job is not claimed", goerr.V("job_id", jobID))
+	}
+
+	if err := x.uc.CompleteAgentScanJob(ctx, claimed.job, result); err != nil {
+		return err
+	}
+	x.completed.Add(1)
+
+	logging.From(ctx).Info("agent scan job completed",
+		slog.String("job_id", jobID),
+		slog.String("agent", claimed.agent),
+		slog.Duration("duration", time.Since(claimed.claimedAt)),
+		slog.Bool("failed", result.Report == nil),
+	)
+	return nil
+}
+
+// expire records failure of jobs not reported within the lease, e.g. because the agent crashed
+func (x *agentQueue) expire(ctx context.Context) {
+	now := time.Now()
+	var expired []*claimedAgentJob
+
+	x.mutex.Lock()
+	for id, claimed := range x.claimed {
+		if now.Sub(claimed.claimedAt) > x.lease {
+			expired = append(expired, claimed)
+			delete(x.claimed, id)
+		}
+	}
+	x.mutex.Unlock()
+
+	for _, claimed := range expired {
+		x.expired.Add(1)
+		result := &model.AgentScanResult{Error: "scan agent " + claimed.agent + " did not report the result within " + x.lease.String()}
+		if err := x.uc.CompleteAgentScanJob(claimed.ctx, claimed.job, result); err != nil {
+			errutil.HandleError(ctx, "fail to record expired agent scan job", err)
+		}
+	}
+}
+
+func (x *agentQueue) stats() agentQueueStats {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	agents := make([]agentLastSeen, 0, len(x.agents))
+	for name, lastSeen := range x.agents {
+		agents = append(agents, agentLastSeen{Name: name, LastSeen: lastSeen})
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+
+	return agentQueueStats{
+		Capacity:  cap(x.pending),
+		Pending:   len(x.pending),
+		Claimed:   len(x.claimed),
+		Accepted:  x.accepted.Load(),
+		Rejected:  x.rejected.Load(),
+		Completed: x.completed.Load(),
+		Expired:   x.expired.Load(),
+		Agents:    agents,
+	}
+}
+
+type agentClaimRequest struct {
+	Agent string `json:"agent"`
+}
+
+type agentClaimResponse struct {
+	Job *model.AgentScanJob `json:"job"`
+}
+
+// agentRoutes serves the API for scan agents. All endpoints require the agent token as a bearer token.
+func agentRoutes(agents *agentQueue, token string) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(requireAdminToken(token))
+
+		r.Post("/jobs/claim", func(w http.ResponseWriter, r *http.Request) {
+			var req agentClaimRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+				handleAPIError(w, r, "invalid agent claim request", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+				return
+			}
+			if req.Agent == "" {
+				handleAPIError(w, r, "invalid agent claim request", goerr.Wrap(types.ErrInvalidRequest, "agent name is required"))
+				return
+			}
+
+			job := agents.claim(r.Context(), req.Agent)
+			if job == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			writeJSON(w, http.StatusOK, agentClaimResponse{Job: job})
+		})
+
+		r.Post("/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+			var result model.AgentScanResult
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAgentResultSize)).Decode(&result); err != nil {
+				handleAPIError(w, r, "invalid agent scan result", goerr.Wrap(types.ErrInvalidRequest, "invalid request body", goerr.V("error", err.Error())))
+				return
+			}
+
+			if err := agents.complete(r.Context(), chi.URLParam(r, "id"), &result); err != nil {
+				handleAPIError(w, r, "fail to complete agent scan job", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
+	}
+}
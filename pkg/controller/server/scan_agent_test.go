@@ -0,0 +1,156 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestScanAgentDelegation(t *testing.T) {
+	const (
+		secret = "dummy"
+		token  = "agent-token"
+	)
+
+	newMock := func() *mock.UseCaseMock {
+		return &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
+			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+				return nil
+			},
+			PrepareAgentScanJobFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error) {
+				return &model.AgentScanJob{
+					ID:         "job-1",
+					Metadata:   input.GitHubMetadata,
+					ArchiveURL: "https://codeload.github.com/m-mizutani/masq/zip/aa0378c",
+				}, nil
+			},
+			CompleteAgentScanJobFunc: func(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error {
+				return nil
+			},
+		}
+	}
+
+	claim := func(t *testing.T, srv *server.Server, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/jobs/claim", bytes.NewReader([]byte(`{"agent":"team-a"}`)))
+		req.Header.Set("Authorization", "Bearer "+auth)
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		return w
+	}
+	report := func(t *testing.T, srv *server.Server, jobID string, result *model.AgentScanResult) *httptest.ResponseRecorder {
+		body := gt.R1(json.Marshal(result)).NoError(t)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/jobs/"+jobID+"/result", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		return w
+	}
+	push := func(t *testing.T, srv *server.Server) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		return w
+	}
+
+	t.Run("scan of delegated repository is claimed and reported by agent", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC,
+			server.WithGitHubSecret(secret),
+			server.WithScanAgents(token, []string{"M-Mizutani/masq"}, time.Hour),
+		)
+
+		w := push(t, srv)
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+		gt.S(t, w.Body.String()).Contains("delegated")
+
+		gt.V(t, claim(t, srv, "wrong-token").Code).Equal(http.StatusUnauthorized)
+
+		w = claim(t, srv, token)
+		gt.V(t, w.Code).Equal(http.StatusOK)
+		var resp struct {
+			Job *model.AgentScanJob `json:"job"`
+		}
+		gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		gt.V(t, resp.Job.ID).Equal("job-1")
+		gt.V(t, resp.Job.Metadata.RepoName).Equal("masq")
+
+		// No more jobs
+		gt.V(t, claim(t, srv, token).Code).Equal(http.StatusNoContent)
+
+		w = report(t, srv, "job-1", &model.AgentScanResult{Report: &trivy.Report{SchemaVersion: 2}})
+		gt.V(t, w.Code).Equal(http.StatusOK)
+		gt.A(t, mockUC.CompleteAgentScanJobCalls()).Length(1)
+		gt.V(t, mockUC.CompleteAgentScanJobCalls()[0].Job.ID).Equal("job-1")
+
+		// Result of a job is accepted only once
+		gt.V(t, report(t, srv, "job-1", &model.AgentScanResult{Error: "again"}).Code).Equal(http.StatusNotFound)
+
+		gt.NoError(t, srv.Shutdown(context.Background()))
+		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(0)
+
+		t.Run("stats reports agents", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/scan-agents", nil))
+			gt.V(t, w.Code).Equal(http.StatusOK)
+			var stats struct {
+				Accepted  int `json:"accepted"`
+				Completed int `json:"completed"`
+				Agents    []struct {
+					Name string `json:"name"`
+				} `json:"agents"`
+			}
+			gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+			gt.V(t, stats.Accepted).Equal(1)
+			gt.V(t, stats.Completed).Equal(1)
+			gt.A(t, stats.Agents).Length(1)
+			gt.V(t, stats.Agents[0].Name).Equal("team-a")
+		})
+	})
+
+	t.Run("other repositories are scanned by server", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC,
+			server.WithGitHubSecret(secret),
+			server.WithScanAgents(token, []string{"another-org"}, time.Hour),
+		)
+
+		gt.V(t, push(t, srv).Code).Equal(http.StatusAccepted)
+		gt.NoError(t, srv.Shutdown(context.Background()))
+		gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(1)
+		gt.V(t, claim(t, srv, token).Code).Equal(http.StatusNoContent)
+	})
+
+	t.Run("job not reported within lease is recorded as failure", func(t *testing.T) {
+		mockUC := newMock()
+		srv := server.New(mockUC,
+			server.WithGitHubSecret(secret),
+			server.WithScanAgents(token, []string{"m-mizutani"}, time.Millisecond),
+		)
+
+		gt.V(t, push(t, srv).Code).Equal(http.StatusAccepted)
+		gt.V(t, claim(t, srv, token).Code).Equal(http.StatusOK)
+		time.Sleep(10 * time.Millisecond)
+
+		// Expired jobs are collected when an agent polls
+		gt.V(t, claim(t, srv, token).Code).Equal(http.StatusNoContent)
+		gt.A(t, mockUC.CompleteAgentScanJobCalls()).Length(1)
+		gt.S(t, mockUC.CompleteAgentScanJobCalls()[0].Result.Error).Contains("did not report")
+
+		gt.V(t, report(t, srv, "job-1", &model.AgentScanResult{Report: &trivy.Report{}}).Code).Equal(http.StatusNotFound)
+	})
+
+	t.Run("agent API is disabled without token", func(t *testing.T) {
+		srv := server.New(newMock(), server.WithGitHubSecret(secret))
+		gt.V(t, claim(t, srv, token).Code).Equal(http.StatusNotFound)
+	})
+}
@@ -0,0 +1,443 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultScanWorkers   = 4
+	defaultScanQueueSize = 100
+)
+
+// scanPriority orders pending jobs of scanQueue
+type scanPriority int
+
+const (
+	// scanPriorityHigh is for pull requests and pushes to the default branch, whose results developers are waiting for
+	scanPriorityHigh scanPriority = iota
+	// scanPriorityNormal is for other scans, e.g. pushes to other branches, releases and RPC
+	scanPriorityNormal
+	// scanPriorityLow is for bulk work which nobody waits for, i.e. seeding, owner scans and scheduled re-scans
+	scanPriorityLow
+
+	numScanPriorities
+)
+
+func (x scanPriority) String() string {
+	switch x {
+	case scanPriorityHigh:
+		return "high"
+	case scanPriorityNormal:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// scanPriorityOf returns the priority of a repository scan
+func scanPriorityOf(input *model.ScanGitHubRepoInput) scanPriority {
+	switch {
+	case input.Trigger == types.ScanTriggerPullRequest || input.PullRequest != nil:
+		return scanPriorityHigh
+	case input.Trigger == types.ScanTriggerPush && input.Branch != "" && input.Branch == input.DefaultBranch:
+		return scanPriorityHigh
+	case input.Trigger == types.ScanTriggerSchedule:
+		return scanPriorityLow
+	default:
+		return scanPriorityNormal
+	}
+}
+
+// ScanPriorityWeights are relative shares of workers given to pending jobs of each priority. While jobs of several priorities are pending, a job of a priority is started in proportion to its weight, so that lower priorities are delayed but not starved by a burst of higher ones.
+type ScanPriorityWeights struct {
+	High   int
+	Normal int
+	Low    int
+}
+
+// DefaultScanPriorityWeights starts 6 high, 3 normal and 1 low priority jobs of every 10 jobs while all priorities are pending
+var DefaultScanPriorityWeights = ScanPriorityWeights{High: 6, Normal: 3, Low: 1}
+
+// weight returns the weight of the priority, or the default one if it is not positive
+func (x ScanPriorityWeights) weight(p scanPriority) int {
+	var w, d int
+	switch p {
+	case scanPriorityHigh:
+		w, d = x.High, DefaultScanPriorityWeights.High
+	case scanPriorityNormal:
+		w, d = x.Normal, DefaultScanPriorityWeights.Normal
+	default:
+		w, d = x.Low, DefaultScanPriorityWeights.Low
+	}
+	if w <= 0 {
+		return d
+	}
+	return w
+}
+
+// scanJob is either a repository scan, seeding of installation repositories or a scan of all repositories of an owner
+type scanJob struct {
+	ctx      context.Context
+	priority scanPriority
+	input    *model.ScanGitHubRepoInput
+	seed     *model.SeedInstallationReposInput
+	owner    *model.ScanGitHubReposByOwnerFromAPIInput
+	// done is called when the job is finished, if set
+	done func()
+	// queuedAt is when the job is accepted, to record the time waiting for a worker
+	queuedAt time.Time
+}
+
+// scanPriorityCounters are counters of jobs of a priority
+type scanPriorityCounters struct {
+	accepted atomic.Int64
+	rejected atomic.Int64
+	running  atomic.Int64
+}
+
+// scanQueue is a bounded intake queue for webhook triggered scans. A fixed number of workers consume jobs so that a burst of webhooks can not exhaust memory and disk by running unbounded scans in parallel. Pending jobs are kept in a FIFO list per priority, and a worker takes the next job from the lists by smooth weighted round robin.
+type scanQueue struct {
+	uc       interfaces.UseCase
+	workers  int
+	capacity int
+	weights  [numScanPriorities]int
+	wg       sync.WaitGroup
+	accepted atomic.Int64
+	rejected atomic.Int64
+	running  atomic.Int64
+	counters [numScanPriorities]scanPriorityCounters
+
+	// mutex guards the fields below, and cond wakes up waiting workers when a job is pushed or the queue is closed
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	pending [numScanPriorities][]scanJob
+	credits [numScanPriorities]int
+	queued  int
+	waiting int
+	closed  bool
+
+	// inflight holds running jobs by sequence number to log scans abandoned at shutdown
+	inflightMutex sync.Mutex
+	inflight      map[uint64]scanJob
+	seq           uint64
+}
+
+// scanQueueStats is a snapshot of scanQueue counters exposed via /health/scan-queue.
+type scanQueueStats struct {
+	Workers    int                                `json:"workers"`
+	Capacity   int                                `json:"capacity"`
+	Queued     int                                `json:"queued"`
+	Running    int64                              `json:"running"`
+	Accepted   int64                              `json:"accepted"`
+	Rejected   int64                              `json:"rejected"`
+	Priorities map[string]*scanPriorityQueueStats `json:"priorities"`
+}
+
+// scanPriorityQueueStats is counters of jobs of a priority
+type scanPriorityQueueStats struct {
+	Weight   int   `json:"weight"`
+	Queued   int   `json:"queued"`
+	Running  int64 `json:"running"`
+	Accepted int64 `json:"accepted"`
+	Rejected int64 `json:"rejected"`
+}
+
+func (x scanQueueStats) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Int("workers", x.Workers),
+		slog.Int("capacity", x.Capacity),
+		slog.Int("queued", x.Queued),
+		slog.Int64("running", x.Running),
+		slog.Int64("accepted", x.Accepted),
+		slog.Int64("rejected", x.Rejected),
+	}
+	for p := scanPriority(0); p < numScanPriorities; p++ {
+		if stats, ok := x.Priorities[p.String()]; ok {
+			attrs = append(attrs, slog.Group(p.String(),
+				slog.Int("queued", stats.Queued),
+				slog.Int64("running", stats.Running),
+			))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func newScanQueue(uc interfaces.UseCase, workers, capacity int, weights ScanPriorityWeights) *scanQueue {
+	if workers <= 0 {
+		workers = defaultScanWorkers
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	q := &scanQueue{
+		uc:       uc,
+		workers:  workers,
+		capacity: capacity,
+		inflight: make(map[uint64]scanJob),
+	}
+	q.cond = sync.NewCond(&q.mutex)
+
+	for p := scanPriority(0); p < numScanPriorities; p++ {
+		q.weights[p] = weights.weight(p)
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (x *scanQueue) work() {
+	defer x.wg.Done()
+	for {
+		job, ok := x.take()
+		if !ok {
+			return
+		}
+		x.run(job)
+	}
+}
+
+// take waits for a pending job. It returns false when the queue is closed and no job is pending.
+func (x *scanQueue) take() (scanJob, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	for {
+		if job, ok := x.next(); ok {
+			return job, true
+		}
+		if x.closed {
+			return scanJob{}, false
+		}
+		x.waiting++
+		x.cond.Wait()
+		x.waiting--
+	}
+}
+
+// next removes a pending job by smooth weighted round robin over priorities having pending jobs, which picks priorities in proportion to their weights and interleaves them evenly. The mutex must be held.
+func (x *scanQueue) next() (scanJob, bool) {
+	best, total := -1, 0
+	for p := range x.pending {
+		if len(x.pending[p]) == 0 {
+			continue
+		}
+		x.credits[p] += x.weights[p]
+		total += x.weights[p]
+		if best < 0 || x.credits[p] > x.credits[best] {
+			best = p
+		}
+	}
+	if best < 0 {
+		return scanJob{}, false
+	}
+	x.credits[best] -= total
+
+	job := x.pending[best][0]
+	x.pending[best][0] = scanJob{}
+	x.pending[best] = x.pending[best][1:]
+	if len(x.pending[best]) == 0 {
+		// A priority joining again starts fresh instead of with credits of its last burst
+		x.pending[best] = nil
+		x.credits[best] = 0
+	}
+	x.queued--
+	return job, true
+}
+
+func (x *scanQueue) run(job scanJob) {
+	counters := &x.counters[job.priority]
+	x.running.Add(1)
+	counters.running.Add(1)
+	defer x.running.Add(-1)
+	defer counters.running.Add(-1)
+	if job.done != nil {
+		defer job.done()
+	}
+
+	id := x.register(job)
+	defer x.unregister(id)
+
+	_, wait := tracing.Start(job.ctx, "scan_queue.wait", trace.WithTimestamp(job.queuedAt), trace.WithAttributes(
+		attribute.String("octovy.scan_priority", job.priority.String()),
+	))
+	wait.End()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logging.From(job.ctx).Error("recovered from panic in background scan",
+				slog.Any("panic", r),
+				slog.Any("input", job.input),
+				slog.Any("seed", job.seed),
+				slog.Any("owner", job.owner),
+			)
+		}
+	}()
+
+	if job.seed != nil {
+		runSeedInstallationRepos(job.ctx, x.uc, job.seed)
+		return
+	}
+	if job.owner != nil {
+		runOwnerScan(job.ctx, x.uc, job.owner)
+		return
+	}
+	runGitHubRepoScan(job.ctx, x.uc, job.input)
+}
+
+// enqueue adds a scan job without blocking. It returns false if the queue is full or already closed, and the caller should shed the request.
+func (x *scanQueue) enqueue(ctx context.Context, input *model.ScanGitHubRepoInput) bool {
+	return x.push(scanJob{ctx: ctx, priority: scanPriorityOf(input), input: input})
+}
+
+// enqueueSeed adds seeding of installation repositories in the same way as enqueue. It shares workers and capacity with scans at low priority.
+func (x *scanQueue) enqueueSeed(ctx context.Context, input *model.SeedInstallationReposInput) bool {
+	return x.push(scanJob{ctx: ctx, priority: scanPriorityLow, seed: input})
+}
+
+// enqueueOwnerScan adds a scan of all repositories of the owner in the same way as enqueue at low priority. done is called when the scan is finished.
+func (x *scanQueue) enqueueOwnerScan(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput, done func()) bool {
+	return x.push(scanJob{ctx: ctx, priority: scanPriorityLow, owner: input, done: done})
+}
+
+// push accepts the job if the number of pending jobs is below the capacity, or a waiting worker takes it right away. A full queue rejects jobs of any priority so that the intake stays bounded.
+func (x *scanQueue) push(job scanJob) bool {
+	counters := &x.counters[job.priority]
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.closed || x.queued >= x.capacity+x.waiting {
+		x.rejected.Add(1)
+		counters.rejected.Add(1)
+		return false
+	}
+
+	job.queuedAt = time.Now()
+	x.pending[job.priority] = append(x.pending[job.priority], job)
+	x.queued++
+	x.accepted.Add(1)
+	counters.accepted.Add(1)
+	x.cond.Signal()
+	return true
+}
+
+func (x *scanQueue) register(job scanJob) uint64 {
+	x.inflightMutex.Lock()
+	defer x.inflightMutex.Unlock()
+	x.seq++
+	x.inflight[x.seq] = job
+	return x.seq
+}
+
+func (x *scanQueue) unregister(id uint64) {
+	x.inflightMutex.Lock()
+	defer x.inflightMutex.Unlock()
+	delete(x.inflight, id)
+}
+
+// logAbandoned logs jobs which are still running or queued. Queued jobs are taken from the queue so that they are not started after the deadline.
+func (x *scanQueue) logAbandoned(ctx context.Context) {
+	logger := logging.From(ctx)
+
+	x.inflightMutex.Lock()
+	running := make([]scanJob, 0, len(x.inflight))
+	for _, job := range x.inflight {
+		running = append(running, job)
+	}
+	x.inflightMutex.Unlock()
+
+	for _, job := range running {
+		logger.Warn("background scan is abandoned at shutdown",
+			slog.String("state", "running"),
+			slog.Any("input", job.input),
+			slog.Any("seed", job.seed),
+			slog.Any("owner", job.owner),
+		)
+	}
+
+	x.mutex.Lock()
+	var queued []scanJob
+	for p := range x.pending {
+		queued = append(queued, x.pending[p]...)
+		x.pending[p] = nil
+	}
+	x.queued = 0
+	x.mutex.Unlock()
+
+	for _, job := range queued {
+		logger.Warn("background scan is abandoned at shutdown",
+			slog.String("state", "queued"),
+			slog.String("priority", job.priority.String()),
+			slog.Any("input", job.input),
+			slog.Any("seed", job.seed),
+			slog.Any("owner", job.owner),
+		)
+	}
+}
+
+func (x *scanQueue) stats() scanQueueStats {
+	stats := scanQueueStats{
+		Workers:    x.workers,
+		Capacity:   x.capacity,
+		Running:    x.running.Load(),
+		Accepted:   x.accepted.Load(),
+		Rejected:   x.rejected.Load(),
+		Priorities: make(map[string]*scanPriorityQueueStats, numScanPriorities),
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	stats.Queued = x.queued
+	for p := scanPriority(0); p < numScanPriorities; p++ {
+		counters := &x.counters[p]
+		stats.Priorities[p.String()] = &scanPriorityQueueStats{
+			Weight:   x.weights[p],
+			Queued:   len(x.pending[p]),
+			Running:  counters.running.Load(),
+			Accepted: counters.accepted.Load(),
+			Rejected: counters.rejected.Load(),
+		}
+	}
+	return stats
+}
+
+// shutdown stops accepting new jobs and waits until queued and running scans are finished or ctx is done. Scans not finished by then are logged as abandoned.
+func (x *scanQueue) shutdown(ctx context.Context) error {
+	x.mutex.Lock()
+	x.closed = true
+	x.cond.Broadcast()
+	x.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		x.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		stats := x.stats()
+		x.logAbandoned(ctx)
+		return goerr.Wrap(ctx.Err(), "scan queue is not drained", goerr.V("stats", stats))
+	}
+}
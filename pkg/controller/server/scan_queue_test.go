@@ -0,0 +1,191 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestScanQueueLoadShedding(t *testing.T) {
+	const secret = "dummy"
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	mockUC := &mock.UseCaseMock{
+		CreateScanRunFunc: noScanRun,
+		ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+
+	srv := server.New(mockUC,
+		server.WithGitHubSecret(secret),
+		server.WithScanQueue(1, 1),
+		server.WithRetryAfter(30*time.Second),
+	)
+
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		return w
+	}
+
+	// First scan is picked up by the only worker
+	gt.V(t, post().Code).Equal(http.StatusAccepted)
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the first scan to start")
+	}
+
+	// Second scan waits in the queue
+	gt.V(t, post().Code).Equal(http.StatusAccepted)
+
+	// Third scan exceeds capacity and is shed
+	w := post()
+	gt.V(t, w.Code).Equal(http.StatusServiceUnavailable)
+	gt.V(t, w.Header().Get("Retry-After")).Equal("30")
+	gt.S(t, w.Body.String()).Contains("scan queue is full")
+
+	t.Run("stats reports queue state", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/scan-queue", nil))
+		gt.V(t, w.Code).Equal(http.StatusOK)
+
+		var stats struct {
+			Workers    int                       `json:"workers"`
+			Capacity   int                       `json:"capacity"`
+			Queued     int                       `json:"queued"`
+			Running    int                       `json:"running"`
+			Accepted   int                       `json:"accepted"`
+			Rejected   int                       `json:"rejected"`
+			Priorities map[string]map[string]int `json:"priorities"`
+		}
+		gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+		gt.V(t, stats.Workers).Equal(1)
+		gt.V(t, stats.Capacity).Equal(1)
+		gt.V(t, stats.Queued).Equal(1)
+		gt.V(t, stats.Running).Equal(1)
+		gt.V(t, stats.Accepted).Equal(2)
+		gt.V(t, stats.Rejected).Equal(1)
+
+		// The push is of a branch other than the default one
+		gt.V(t, stats.Priorities["normal"]).Equal(map[string]int{"weight": 3, "queued": 1, "running": 1, "accepted": 2, "rejected": 1})
+		gt.V(t, stats.Priorities["high"]).Equal(map[string]int{"weight": 6, "queued": 0, "running": 0, "accepted": 0, "rejected": 0})
+	})
+
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	gt.NoError(t, srv.Shutdown(ctx))
+	gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(2)
+
+	t.Run("webhook after shutdown is rejected", func(t *testing.T) {
+		gt.V(t, post().Code).Equal(http.StatusServiceUnavailable)
+	})
+}
+
+func TestScanQueueShutdownTimeout(t *testing.T) {
+	const secret = "dummy"
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	mockUC := &mock.UseCaseMock{
+		CreateScanRunFunc: noScanRun,
+		ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+
+	srv := server.New(mockUC, server.WithGitHubSecret(secret), server.WithScanQueue(1, 1))
+
+	post := func() {
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, newGitHubWebhookRequest(t, "push", testGitHubPush, secret))
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+	}
+	post()
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the first scan to start")
+	}
+	post()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	gt.Error(t, srv.Shutdown(ctx))
+
+	// The queued scan is abandoned and not started after the deadline
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+	gt.A(t, mockUC.ScanGitHubRepoCalls()).Length(1)
+}
+
+func TestScanQueuePriority(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	mockUC := &mock.UseCaseMock{
+		ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+
+	srv := server.New(mockUC,
+		server.WithScanQueue(1, 10),
+		server.WithScanPriorityWeights(server.ScanPriorityWeights{High: 2, Normal: 1, Low: 1}),
+	)
+
+	scan := func(branch string, trigger types.ScanTrigger) *model.ScanGitHubRepoInput {
+		input := &model.ScanGitHubRepoInput{Trigger: trigger}
+		input.Owner = "myorg"
+		input.RepoName = "myrepo"
+		input.Branch = branch
+		input.DefaultBranch = "main"
+		gt.True(t, srv.EnqueueScan(context.Background(), input))
+		return input
+	}
+
+	// The only worker is busy while others are queued
+	scan("old", types.ScanTriggerSchedule)
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the first scan to start")
+	}
+
+	scan("stale-1", types.ScanTriggerSchedule)
+	scan("feature-1", types.ScanTriggerPush)
+	scan("feature-2", types.ScanTriggerPush)
+	scan("main", types.ScanTriggerPush)
+	scan("pr-1", types.ScanTriggerPullRequest)
+	scan("pr-2", types.ScanTriggerPullRequest)
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	gt.NoError(t, srv.Shutdown(ctx))
+
+	var branches []string
+	for _, call := range mockUC.ScanGitHubRepoCalls() {
+		branches = append(branches, call.Input.Branch)
+	}
+	// High priority scans are started twice as often as the others, and pending low priority scans are not starved
+	gt.A(t, branches).Equal([]string{"old", "main", "feature-1", "stale-1", "pr-1", "pr-2", "feature-2"})
+}
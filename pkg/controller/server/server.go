@@ -1,19 +1,25 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"time"
 
 	"log/slog"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/errutil"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
 )
 
 type Server struct {
-	mux *chi.Mux
+	mux    *chi.Mux
+	queue  *scanQueue
+	agents *agentQueue
 }
 
 func safeWrite(w http.ResponseWriter, code int, body []byte) {
@@ -27,7 +33,31 @@ func safeWrite(w http.ResponseWriter, code int, body []byte) {
 }
 
 type config struct {
-	ghSecret types.GitHubAppSecret
+	ghSecret       types.GitHubAppSecret
+	ghSecrets      map[types.GitHubAppID]types.GitHubAppSecret
+	webhookPath    string
+	gateMaxScanAge time.Duration
+	scanWorkers    int
+	scanQueueSize  int
+	scanWeights    ScanPriorityWeights
+	retryAfter     time.Duration
+	adminToken     string
+	agentToken     string
+	agentRepos     []string
+	agentLease     time.Duration
+	uiUser         string
+	uiPassword     string
+	apiTokens      []string
+	oidc           interfaces.OIDCVerifier
+	oauth          interfaces.GitHubOAuth
+	sessionKey     []byte
+	authPolicy     *model.AuthPolicy
+	triggers       eventTriggers
+	dedupeTTL      time.Duration
+	webhookMaxBody int64
+	webhookTimeout time.Duration
+	taskOIDC       interfaces.OIDCVerifier
+	taskPrincipals []string
 }
 
 type Option func(*config)
@@ -38,69 +68,327 @@ func WithGitHubSecret(secret types.GitHubAppSecret) Option {
 	}
 }
 
+// WithGitHubAppSecrets sets webhook secrets of GitHub Apps by app ID, so that a server receives webhooks of several apps. The secret of a webhook is selected by the app ID in the path, e.g. /webhook/github/app/12345, or in the X-GitHub-Hook-Installation-Target-ID header. The secret of WithGitHubSecret is used for webhooks of other apps.
+func WithGitHubAppSecrets(secrets map[types.GitHubAppID]types.GitHubAppSecret) Option {
+	return func(cfg *config) {
+		cfg.ghSecrets = secrets
+	}
+}
+
+// WithWebhookPath sets the path of webhooks of GitHub Apps, DefaultWebhookPath by default
+func WithWebhookPath(path string) Option {
+	return func(cfg *config) {
+		cfg.webhookPath = path
+	}
+}
+
+// WithWorkflowRunTrigger scans the head commit of a workflow run completed on the default branch, in addition to push events. If names are given, only runs of workflows with the names trigger scans. A commit already scanned, e.g. by its push event, is not scanned again.
+func WithWorkflowRunTrigger(names []string) Option {
+	return func(cfg *config) {
+		cfg.triggers.workflowRun = true
+		cfg.triggers.workflowNames = names
+	}
+}
+
+// WithReleaseTrigger scans the tag of a release when it is published
+func WithReleaseTrigger() Option {
+	return func(cfg *config) {
+		cfg.triggers.release = true
+	}
+}
+
+// WithWebhookDedupe sets how long X-GitHub-Delivery GUIDs of accepted webhooks are remembered to ignore their redeliveries. Zero disables deduplication.
+func WithWebhookDedupe(ttl time.Duration) Option {
+	return func(cfg *config) {
+		cfg.dedupeTTL = ttl
+	}
+}
+
+// WithWebhookLimits limits the body size of webhooks and the time to handle them, i.e. to validate and enqueue. Zero disables each limit.
+func WithWebhookLimits(maxBodySize int64, timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.webhookMaxBody = maxBodySize
+		cfg.webhookTimeout = timeout
+	}
+}
+
+// WithGateMaxScanAge sets the default freshness requirement of the deployment gate API. It can be overridden by max-age-hours query parameter.
+func WithGateMaxScanAge(age time.Duration) Option {
+	return func(cfg *config) {
+		cfg.gateMaxScanAge = age
+	}
+}
+
+// WithScanQueue sets the number of background scan workers and the capacity of the webhook intake queue. Webhooks arriving while the queue is full are rejected with 503 Service Unavailable.
+func WithScanQueue(workers, capacity int) Option {
+	return func(cfg *config) {
+		cfg.scanWorkers = workers
+		cfg.scanQueueSize = capacity
+	}
+}
+
+// WithScanPriorityWeights sets relative shares of background scan workers given to high, normal and low priority scans while scans of several priorities are pending. Pull requests and pushes to the default branch are high, seeding, owner scans and scheduled scans are low, and others are normal.
+func WithScanPriorityWeights(weights ScanPriorityWeights) Option {
+	return func(cfg *config) {
+		cfg.scanWeights = weights
+	}
+}
+
+// WithRetryAfter sets the Retry-After value returned with 503 responses when the scan queue is full.
+func WithRetryAfter(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.retryAfter = d
+	}
+}
+
+// WithAdminToken enables API endpoints which modify data, e.g. repository metadata. Requests to them must have the token as a bearer token, unless the user is a member of admin teams of WithAuthPolicy. They are not available if neither is configured.
+func WithAdminToken(token string) Option {
+	return func(cfg *config) {
+		cfg.adminToken = token
+	}
+}
+
+// WithScanAgents delegates webhook scans of repos, given as "owner" or "owner/repo", to scan agents authenticated by the token. A job not reported within lease is recorded as failure. Delegation is disabled if the token is empty.
+func WithScanAgents(token string, repos []string, lease time.Duration) Option {
+	return func(cfg *config) {
+		cfg.agentToken = token
+		cfg.agentRepos = repos
+		cfg.agentLease = lease
+	}
+}
+
+// WithUIBasicAuth accepts HTTP basic authentication for the dashboard and the read API. The read API and the dashboard are public if no authentication method is configured.
+func WithUIBasicAuth(user, password string) Option {
+	return func(cfg *config) {
+		cfg.uiUser = user
+		cfg.uiPassword = password
+	}
+}
+
+// WithAPITokens accepts the static tokens as bearer tokens of the read API.
+func WithAPITokens(tokens []string) Option {
+	return func(cfg *config) {
+		cfg.apiTokens = tokens
+	}
+}
+
+// WithOIDC accepts OIDC ID tokens verified by the verifier as bearer tokens of the read API. Tokens must also be allowed by WithAuthPolicy.
+func WithOIDC(verifier interfaces.OIDCVerifier) Option {
+	return func(cfg *config) {
+		cfg.oidc = verifier
+	}
+}
+
+// WithGitHubOAuth enables sign-in of the dashboard with GitHub under /auth. Sessions are stored in cookies signed by sessionKey. Users must also be allowed by WithAuthPolicy.
+func WithGitHubOAuth(client interfaces.GitHubOAuth, sessionKey []byte) Option {
+	return func(cfg *config) {
+		cfg.oauth = client
+		cfg.sessionKey = sessionKey
+	}
+}
+
+// WithAuthPolicy sets GitHub organizations and teams allowed to sign in with GitHub OAuth and to use OIDC tokens, and tenants whose principals can access only their repositories. Nobody is allowed by these methods without the policy.
+func WithAuthPolicy(policy *model.AuthPolicy) Option {
+	return func(cfg *config) {
+		cfg.authPolicy = policy
+	}
+}
+
+// WithTasks enables task endpoints under /tasks, e.g. to scan all repositories of an owner on schedule by Cloud Scheduler or a Pub/Sub push subscription. Requests must have an OIDC token verified by the verifier whose subject or email is one of principals.
+func WithTasks(verifier interfaces.OIDCVerifier, principals []string) Option {
+	return func(cfg *config) {
+		cfg.taskOIDC = verifier
+		cfg.taskPrincipals = principals
+	}
+}
+
 func New(uc interfaces.UseCase, options ...Option) *Server {
-	cfg := &config{}
+	cfg := &config{
+		scanWorkers:    defaultScanWorkers,
+		scanQueueSize:  defaultScanQueueSize,
+		scanWeights:    DefaultScanPriorityWeights,
+		retryAfter:     time.Minute,
+		dedupeTTL:      defaultWebhookDedupeTTL,
+		webhookMaxBody: DefaultWebhookMaxBodySize,
+		webhookTimeout: DefaultWebhookTimeout,
+		webhookPath:    DefaultWebhookPath,
+		authPolicy:     &model.AuthPolicy{},
+	}
 	for _, opt := range options {
 		opt(cfg)
 	}
 
+	queue := newScanQueue(uc, cfg.scanWorkers, cfg.scanQueueSize, cfg.scanWeights)
+	deliveries := newDeliveryCache(cfg.dedupeTTL)
+	var agents *agentQueue
+	if cfg.agentToken != "" {
+		agents = newAgentQueue(uc, cfg.agentRepos, cfg.scanQueueSize, cfg.agentLease)
+	}
+
 	r := chi.NewRouter()
+	r.Use(traceRequest)
 	r.Use(preProcess)
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		safeWrite(w, http.StatusOK, []byte("ok"))
 	})
-	r.Route("/webhook", func(r chi.Router) {
-		r.Route("/github", func(r chi.Router) {
-			r.Post("/app", func(w http.ResponseWriter, r *http.Request) {
-				// Validate and parse the webhook event synchronously
-				result, err := validateGitHubAppEvent(r, cfg.ghSecret)
-				if err != nil {
-					errutil.HandleError(r.Context(), "fail to validate GitHub App event", err)
-					safeWrite(w, http.StatusInternalServerError, []byte(err.Error()))
-					return
-				}
-
-				// If no scan is required, return immediately
-				if result.ScanInput == nil {
-					safeWrite(w, http.StatusOK, []byte(`{"status":"ok","message":"no scan required"}`))
-					return
-				}
-
-				// Create a detached context for background processing
-				// The original request context will be cancelled when the HTTP response is sent
-				bgCtx := DetachContext(r.Context())
-
-				// Start background scan with panic recovery
-				go func() {
-					defer func() {
-						if r := recover(); r != nil {
-							logging.From(bgCtx).Error("recovered from panic in background scan",
-								slog.Any("panic", r),
-								slog.Any("input", result.ScanInput),
-							)
-						}
-					}()
-					runGitHubRepoScan(bgCtx, uc, result.ScanInput)
-				}()
-
-				// Return immediately with 202 Accepted
-				safeWrite(w, http.StatusAccepted, []byte(`{"status":"accepted","message":"scan enqueued"}`))
-			})
-			r.Post("/action", func(w http.ResponseWriter, r *http.Request) {
-				if err := handleGitHubActionEvent(uc, r); err != nil {
-					errutil.HandleError(r.Context(), "fail to handle GitHub action event", err)
-					safeWrite(w, http.StatusInternalServerError, []byte(err.Error()))
-					return
-				}
-
-				safeWrite(w, http.StatusOK, []byte("ok"))
-			})
+	r.Get("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		readiness := uc.CheckReadiness(r.Context())
+		code := http.StatusOK
+		if !readiness.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, readiness)
+	})
+	r.Get("/health/scan-queue", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, queue.stats())
+	})
+	if agents != nil {
+		r.Get("/health/scan-agents", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, agents.stats())
+		})
+	}
+	r.Get("/health/github-rate-limit", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"rate_limits": uc.GetGitHubRateLimits(r.Context()),
 		})
 	})
+	// Webhooks of GitHub Apps are received at the configured path. A path with an app ID selects the secret of the app explicitly.
+	githubAppWebhook := func(w http.ResponseWriter, r *http.Request) {
+		secret, err := cfg.webhookSecret(r)
+		if err != nil {
+			handleWebhookError(w, r, "fail to select secret of GitHub App event", err)
+			return
+		}
+
+		// Validate and parse the webhook event synchronously
+		result, err := validateGitHubAppEvent(r, secret, cfg.triggers)
+		if err != nil {
+			handleWebhookError(w, r, "fail to validate GitHub App event", err)
+			return
+		}
+
+		// If no scan is required, return immediately
+		if result.SeedInput == nil && result.ScanInput == nil {
+			safeWrite(w, http.StatusOK, []byte(`{"status":"ok","message":"no scan required"}`))
+			return
+		}
+
+		// Redeliveries of an accepted event have the same GUID and are ignored
+		deliveryID := r.Header.Get("X-GitHub-Delivery")
+		if !deliveries.claim(deliveryID) {
+			logging.From(r.Context()).Info("ignore redelivered GitHub App event", slog.String("delivery", deliveryID))
+			safeWrite(w, http.StatusOK, []byte(`{"status":"ok","message":"duplicate delivery"}`))
+			return
+		}
+
+		if result.SeedInput != nil {
+			if !queue.enqueueSeed(DetachContext(r.Context()), result.SeedInput) {
+				logging.From(r.Context()).Warn("scan queue is full, rejecting installation event",
+					slog.Any("input", result.SeedInput),
+					slog.Any("queue", queue.stats()),
+				)
+				deliveries.release(deliveryID)
+				w.Header().Set("Retry-After", strconv.Itoa(int(cfg.retryAfter.Seconds())))
+				safeWrite(w, http.StatusServiceUnavailable, []byte(`{"status":"unavailable","message":"scan queue is full"}`))
+				return
+			}
+			safeWrite(w, http.StatusAccepted, []byte(`{"status":"accepted","message":"repository seeding enqueued"}`))
+			return
+		}
+
+		// Create a detached context for background processing
+		// The original request context will be cancelled when the HTTP response is sent
+		bgCtx := DetachContext(r.Context())
+
+		// The run is recorded before the scan is enqueued so that a worker never starts the scan before its run exists. A run of a rejected webhook stays queued until it expires, but nobody knows its ID.
+		run, err := uc.CreateScanRun(r.Context(), result.ScanInput)
+		if err != nil {
+			errutil.HandleError(r.Context(), "fail to create scan run, continue without tracking", err)
+		}
+
+		// Source code of delegated repositories is downloaded and scanned only by scan agents
+		if agents != nil && agents.delegates(result.ScanInput.Owner, result.ScanInput.RepoName) {
+			if !agents.enqueue(bgCtx, result.ScanInput) {
+				logging.From(r.Context()).Warn("agent scan queue is full, rejecting webhook",
+					slog.Any("input", result.ScanInput),
+					slog.Any("agents", agents.stats()),
+				)
+				deliveries.release(deliveryID)
+				w.Header().Set("Retry-After", strconv.Itoa(int(cfg.retryAfter.Seconds())))
+				safeWrite(w, http.StatusServiceUnavailable, []byte(`{"status":"unavailable","message":"agent scan queue is full"}`))
+				return
+			}
+			writeWebhookAccepted(w, "scan delegated to agent", run)
+			return
+		}
+
+		// Shed the request instead of piling up scans when the queue is full. GitHub does not redeliver automatically, but Retry-After tells the sender when to try again.
+		if !queue.enqueue(bgCtx, result.ScanInput) {
+			logging.From(r.Context()).Warn("scan queue is full, rejecting webhook",
+				slog.Any("input", result.ScanInput),
+				slog.Any("queue", queue.stats()),
+			)
+			deliveries.release(deliveryID)
+			w.Header().Set("Retry-After", strconv.Itoa(int(cfg.retryAfter.Seconds())))
+			safeWrite(w, http.StatusServiceUnavailable, []byte(`{"status":"unavailable","message":"scan queue is full"}`))
+			return
+		}
+
+		// Return immediately with 202 Accepted
+		writeWebhookAccepted(w, "scan enqueued", run)
+	}
+	webhook := r.With(limitWebhookRequest(cfg.webhookMaxBody, cfg.webhookTimeout))
+	webhook.Post(cfg.webhookPath, githubAppWebhook)
+	webhook.Post(cfg.webhookPath+"/{app_id}", githubAppWebhook)
+	webhook.Post("/webhook/github/action", func(w http.ResponseWriter, r *http.Request) {
+		if err := handleGitHubActionEvent(uc, r); err != nil {
+			handleWebhookError(w, r, "fail to handle GitHub action event", err)
+			return
+		}
+
+		safeWrite(w, http.StatusOK, []byte("ok"))
+	})
+
+	r.Route("/api/v1", apiRoutes(uc, cfg))
+
+	// The dashboard and read API are public unless an authentication method is configured
+	r.Group(func(r chi.Router) {
+		r.Use(requireAuth(cfg, false))
+		r.Post("/graphql", graphqlHandler(uc))
+
+		ui := uiHandler()
+		r.Get("/", ui.ServeHTTP)
+		r.Get("/assets/*", ui.ServeHTTP)
+		r.Route("/r", permalinkRoutes(uc))
+	})
+	if cfg.oauth != nil {
+		r.Route("/auth", oauthRoutes(cfg))
+	}
+	if agents != nil {
+		r.Route("/api/v1/agent", agentRoutes(agents, cfg.agentToken))
+	}
+	if cfg.taskOIDC != nil {
+		r.Route("/tasks", taskRoutes(cfg, queue))
+	}
 
 	return &Server{
-		mux: r,
+		mux:    r,
+		queue:  queue,
+		agents: agents,
+	}
+}
+
+// EnqueueScan runs the scan in background in the same way as webhook scans, i.e. by scan agents if the repository is delegated to them. It returns false if the queue is full or shut down.
+func (x *Server) EnqueueScan(ctx context.Context, input *model.ScanGitHubRepoInput) bool {
+	if x.agents != nil && x.agents.delegates(input.Owner, input.RepoName) {
+		return x.agents.enqueue(ctx, input)
 	}
+	return x.queue.enqueue(ctx, input)
+}
+
+// Shutdown stops accepting webhook scans and waits for queued and running scans to finish until ctx is done. It should be called after the HTTP server is shut down.
+func (x *Server) Shutdown(ctx context.Context) error {
+	return x.queue.shutdown(ctx)
 }
 
 func (x *Server) Mux() *chi.Mux {
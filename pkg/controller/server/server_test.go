@@ -3,6 +3,7 @@ package server_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -45,8 +46,65 @@ func TestRouterSmokeTests(t *testing.T) {
 		gt.V(t, rec.Body.String()).Equal("ok")
 	})
 
+	t.Run("GET /health/github-rate-limit returns observed rate limits", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			GetGitHubRateLimitsFunc: func(ctx context.Context) []*model.GitHubRateLimit {
+				return []*model.GitHubRateLimit{
+					{InstallID: 12345, Limit: 5000, Remaining: 4210},
+				}
+			},
+		}
+		srv := server.New(mockUC)
+
+		req := httptest.NewRequest(http.MethodGet, "/health/github-rate-limit", nil)
+		rec := httptest.NewRecorder()
+
+		srv.Mux().ServeHTTP(rec, req)
+
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var resp struct {
+			RateLimits []*model.GitHubRateLimit `json:"rate_limits"`
+		}
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.A(t, resp.RateLimits).Length(1)
+		gt.V(t, resp.RateLimits[0].InstallID).Equal(types.GitHubAppInstallID(12345))
+		gt.V(t, resp.RateLimits[0].Remaining).Equal(4210)
+	})
+
+	t.Run("GET /health/ready returns 503 if a dependency is not ready", func(t *testing.T) {
+		ready := true
+		mockUC := &mock.UseCaseMock{
+			CheckReadinessFunc: func(ctx context.Context) *model.Readiness {
+				return &model.Readiness{
+					Ready: ready,
+					Dependencies: []*model.DependencyStatus{
+						{Name: "trivy", OK: ready, Version: "0.58.1"},
+					},
+				}
+			},
+		}
+		srv := server.New(mockUC)
+
+		get := func() *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			srv.Mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			return rec
+		}
+
+		rec := get()
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		var resp model.Readiness
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.A(t, resp.Dependencies).Length(1)
+		gt.V(t, resp.Dependencies[0].Version).Equal("0.58.1")
+
+		ready = false
+		gt.V(t, get().Code).Equal(http.StatusServiceUnavailable)
+	})
+
 	t.Run("POST /webhook/github/app with mock UseCase", func(t *testing.T) {
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				return nil
 			},
@@ -61,12 +119,13 @@ func TestRouterSmokeTests(t *testing.T) {
 
 		srv.Mux().ServeHTTP(rec, req)
 
-		// Without proper signature, it should fail
-		gt.V(t, rec.Code).Equal(http.StatusInternalServerError)
+		// Without proper signature, it should be rejected
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
 	})
 
 	t.Run("POST /webhook/github/action", func(t *testing.T) {
 		mockUC := &mock.UseCaseMock{
+			CreateScanRunFunc: noScanRun,
 			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 				return nil
 			},
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/errutil"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// maxTaskBodySize limits the request body of a task. A task has only a few parameters, even in a Pub/Sub envelope.
+const maxTaskBodySize = 64 * 1024
+
+// scanOwnerTask is the payload of /tasks/scan-owner
+type scanOwnerTask struct {
+	Owner     string `json:"owner"`
+	InstallID int64  `json:"install_id"`
+	// Force rescans unchanged commits to pick up newly disclosed vulnerabilities. It is true if omitted, in the same way as scheduled scans of the server.
+	Force *bool `json:"force"`
+}
+
+// pubSubPushMessage is the envelope of a Pub/Sub push subscription. The task payload is base64 encoded in data, which encoding/json decodes into []byte.
+type pubSubPushMessage struct {
+	Message *struct {
+		Data      []byte `json:"data"`
+		MessageID string `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// ownerScans remembers owners whose scan is queued or running, so that a redelivered task does not scan the owner twice
+type ownerScans struct {
+	mutex  sync.Mutex
+	owners map[string]bool
+}
+
+func (x *ownerScans) claim(owner string) bool {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	key := strings.ToLower(owner)
+	if x.owners[key] {
+		return false
+	}
+	x.owners[key] = true
+	return true
+}
+
+func (x *ownerScans) release(owner string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	delete(x.owners, strings.ToLower(owner))
+}
+
+// taskRoutes serves tasks pushed by Cloud Scheduler or a Pub/Sub push subscription with an OIDC token of an allowed service account. Tasks run in background on the scan queue, so the response only tells whether the task is accepted.
+func taskRoutes(cfg *config, queue *scanQueue) func(r chi.Router) {
+	scans := &ownerScans{owners: map[string]bool{}}
+
+	return func(r chi.Router) {
+		r.Use(requireTaskToken(cfg.taskOIDC, cfg.taskPrincipals))
+		r.Use(limitWebhookRequest(maxTaskBodySize, cfg.webhookTimeout))
+
+		r.Post("/scan-owner", func(w http.ResponseWriter, r *http.Request) {
+			task, err := parseScanOwnerTask(r)
+			if err != nil {
+				handleAPIError(w, r, "fail to parse scan owner task", err)
+				return
+			}
+
+			force := task.Force == nil || *task.Force
+			input := &model.ScanGitHubReposByOwnerFromAPIInput{
+				Owner:     task.Owner,
+				InstallID: types.GitHubAppInstallID(task.InstallID),
+				Force:     force,
+				Trigger:   types.ScanTriggerSchedule,
+			}
+
+			// Pub/Sub delivers a message at least once, and Cloud Scheduler retries a task which timed out
+			if !scans.claim(task.Owner) {
+				logging.From(r.Context()).Info("ignore scan owner task, scan of the owner is already running", slog.String("owner", task.Owner))
+				writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "message": "scan of the owner is already running"})
+				return
+			}
+
+			if !queue.enqueueOwnerScan(DetachContext(r.Context()), input, func() { scans.release(task.Owner) }) {
+				scans.release(task.Owner)
+				logging.From(r.Context()).Warn("scan queue is full, rejecting scan owner task",
+					slog.String("owner", task.Owner),
+					slog.Any("queue", queue.stats()),
+				)
+				w.Header().Set("Retry-After", strconv.Itoa(int(cfg.retryAfter.Seconds())))
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "message": "scan queue is full"})
+				return
+			}
+
+			writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted", "message": "owner scan enqueued"})
+		})
+	}
+}
+
+// parseScanOwnerTask reads the task from the body, which is either the task itself or a Pub/Sub push envelope of it
+func parseScanOwnerTask(r *http.Request) (*scanOwnerTask, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "task payload is too large")
+		}
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid task payload", goerr.V("error", err.Error()))
+	}
+
+	var envelope pubSubPushMessage
+	if err := json.Unmarshal(raw, &envelope); err == nil && envelope.Message != nil {
+		logging.From(r.Context()).Info("received Pub/Sub push message",
+			slog.String("message_id", envelope.Message.MessageID),
+			slog.String("subscription", envelope.Subscription),
+		)
+		raw = envelope.Message.Data
+	}
+
+	var task scanOwnerTask
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid task payload", goerr.V("error", err.Error()))
+	}
+	if task.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "owner is required")
+	}
+	return &task, nil
+}
+
+// runOwnerScan scans all repositories of the owner. A failure of a repository is logged by the usecase and does not stop scans of the others.
+func runOwnerScan(ctx context.Context, uc interfaces.UseCase, input *model.ScanGitHubReposByOwnerFromAPIInput) {
+	logger := logging.From(ctx).With(slog.String("owner", input.Owner))
+	logger.Info("Starting owner scan by task")
+
+	run, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	if run != nil {
+		logger = logger.With(slog.String("owner_scan_run_id", run.ID.String()))
+	}
+	if err != nil {
+		errutil.HandleErrorWithTags(ctx, "owner scan by task failed", err, map[string]string{
+			"owner":   input.Owner,
+			"trigger": string(input.Trigger),
+		})
+		return
+	}
+	logger.Info("Owner scan by task completed")
+}
+
+// requireTaskToken rejects requests without an OIDC token of one of principals, e.g. the service account of Cloud Scheduler or a Pub/Sub push subscription
+func requireTaskToken(verifier interfaces.OIDCVerifier, principals []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				logging.From(r.Context()).Info("rejected OIDC token of task", slog.Any("error", err))
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, apiErrorResponse{Error: "unauthorized"})
+				return
+			}
+			if !claims.IsPrincipalOf(principals) {
+				logging.From(r.Context()).Warn("OIDC token of task is not allowed",
+					slog.String("issuer", claims.Issuer),
+					slog.String("subject", claims.Subject),
+					slog.String("email", claims.Email),
+				)
+				writeJSON(w, http.StatusForbidden, apiErrorResponse{Error: "forbidden"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
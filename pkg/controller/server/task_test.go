@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestScanOwnerTask(t *testing.T) {
+	const serviceAccount = "scheduler@example.iam.gserviceaccount.com"
+
+	verifier := &mock.OIDCVerifierMock{
+		VerifyFunc: func(ctx context.Context, token string) (*model.OIDCClaims, error) {
+			switch token {
+			case "scheduler-token":
+				return &model.OIDCClaims{Issuer: "https://accounts.google.com", Subject: "111", Email: serviceAccount, EmailVerified: true}, nil
+			case "other-token":
+				return &model.OIDCClaims{Issuer: "https://accounts.google.com", Subject: "222", Email: "other@example.iam.gserviceaccount.com", EmailVerified: true}, nil
+			default:
+				return nil, goerr.Wrap(types.ErrUnauthorized, "invalid token")
+			}
+		},
+	}
+
+	post := func(srv *server.Server, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks/scan-owner", bytes.NewReader([]byte(body)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("owner is scanned in background", func(t *testing.T) {
+		scanned := make(chan *model.ScanGitHubReposByOwnerFromAPIInput, 1)
+		srv := server.New(&mock.UseCaseMock{
+			ScanGitHubReposByOwnerFromAPIFunc: func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+				scanned <- input
+				return &model.OwnerScanRun{ID: types.NewOwnerScanRunID(), Owner: input.Owner}, nil
+			},
+		}, server.WithTasks(verifier, []string{serviceAccount}))
+
+		w := post(srv, "scheduler-token", `{"owner":"myorg","install_id":123}`)
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+
+		select {
+		case input := <-scanned:
+			gt.V(t, input).Equal(&model.ScanGitHubReposByOwnerFromAPIInput{
+				Owner:     "myorg",
+				InstallID: 123,
+				Force:     true,
+				Trigger:   types.ScanTriggerSchedule,
+			})
+		case <-time.After(5 * time.Second):
+			t.Fatal("owner scan was not started")
+		}
+		gt.NoError(t, srv.Shutdown(context.Background()))
+	})
+
+	t.Run("payload in Pub/Sub push message", func(t *testing.T) {
+		scanned := make(chan *model.ScanGitHubReposByOwnerFromAPIInput, 1)
+		srv := server.New(&mock.UseCaseMock{
+			ScanGitHubReposByOwnerFromAPIFunc: func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+				scanned <- input
+				return &model.OwnerScanRun{ID: types.NewOwnerScanRunID(), Owner: input.Owner}, nil
+			},
+		}, server.WithTasks(verifier, []string{serviceAccount}))
+
+		data := base64.StdEncoding.EncodeToString([]byte(`{"owner":"myorg","force":false}`))
+		w := post(srv, "scheduler-token", `{"message":{"data":"`+data+`","messageId":"1"},"subscription":"projects/p/subscriptions/octovy"}`)
+		gt.V(t, w.Code).Equal(http.StatusAccepted)
+
+		input := <-scanned
+		gt.V(t, input.Owner).Equal("myorg")
+		gt.False(t, input.Force)
+		gt.NoError(t, srv.Shutdown(context.Background()))
+	})
+
+	t.Run("redelivered task is ignored while the owner is scanned", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var calls int
+		var mutex sync.Mutex
+		srv := server.New(&mock.UseCaseMock{
+			ScanGitHubReposByOwnerFromAPIFunc: func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+				mutex.Lock()
+				calls++
+				mutex.Unlock()
+				close(started)
+				<-release
+				return &model.OwnerScanRun{ID: types.NewOwnerScanRunID(), Owner: input.Owner}, nil
+			},
+		}, server.WithTasks(verifier, []string{serviceAccount}))
+
+		gt.V(t, post(srv, "scheduler-token", `{"owner":"myorg"}`).Code).Equal(http.StatusAccepted)
+		<-started
+
+		w := post(srv, "scheduler-token", `{"owner":"MyOrg"}`)
+		gt.V(t, w.Code).Equal(http.StatusOK)
+		gt.S(t, w.Body.String()).Contains("already running")
+
+		close(release)
+		gt.NoError(t, srv.Shutdown(context.Background()))
+		gt.V(t, calls).Equal(1)
+	})
+
+	t.Run("rejected requests", func(t *testing.T) {
+		srv := server.New(&mock.UseCaseMock{}, server.WithTasks(verifier, []string{serviceAccount}))
+
+		gt.V(t, post(srv, "", `{"owner":"myorg"}`).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, post(srv, "invalid-token", `{"owner":"myorg"}`).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, post(srv, "other-token", `{"owner":"myorg"}`).Code).Equal(http.StatusForbidden)
+		gt.V(t, post(srv, "scheduler-token", `{}`).Code).Equal(http.StatusBadRequest)
+		gt.V(t, post(srv, "scheduler-token", `not json`).Code).Equal(http.StatusBadRequest)
+	})
+
+	t.Run("not served without configuration", func(t *testing.T) {
+		srv := server.New(&mock.UseCaseMock{})
+		gt.V(t, post(srv, "scheduler-token", `{"owner":"myorg"}`).Code).Equal(http.StatusNotFound)
+	})
+}
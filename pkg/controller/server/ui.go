@@ -0,0 +1,26 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed frontend
+var frontendFS embed.FS
+
+// uiHandler serves the dashboard. It is a static page which reads data from the GraphQL and REST APIs in the browser, so the assets do not depend on the server state.
+func uiHandler() http.Handler {
+	assets, err := fs.Sub(frontendFS, "frontend")
+	if err != nil {
+		panic("frontend assets are not embedded: " + err.Error())
+	}
+	files := http.FileServerFS(assets)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The dashboard loads only its own scripts and styles and calls only the API of this server
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,77 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestUI(t *testing.T) {
+	get := func(srv *server.Server, path string, setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if setAuth != nil {
+			setAuth(req)
+		}
+		rec := httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("serves dashboard and assets", func(t *testing.T) {
+		srv := server.New(&mock.UseCaseMock{})
+
+		rec := get(srv, "/", nil)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Header().Get("Content-Type")).Contains("text/html")
+		gt.S(t, rec.Header().Get("Content-Security-Policy")).Contains("default-src 'self'")
+		gt.S(t, rec.Body.String()).Contains(`<script src="/assets/app.js">`)
+
+		rec = get(srv, "/assets/app.js", nil)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.S(t, rec.Body.String()).Contains("/graphql")
+
+		gt.V(t, get(srv, "/assets/style.css", nil).Code).Equal(http.StatusOK)
+		gt.V(t, get(srv, "/assets/missing.js", nil).Code).Equal(http.StatusNotFound)
+	})
+
+	t.Run("basic authentication protects dashboard and GraphQL API", func(t *testing.T) {
+		mockUC := &mock.UseCaseMock{
+			ListRepositoriesFunc: func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+				return nil, nil
+			},
+		}
+		srv := server.New(mockUC, server.WithUIBasicAuth("octovy", "s3cret"))
+
+		rec := get(srv, "/", nil)
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		gt.S(t, rec.Header().Get("WWW-Authenticate")).Contains("Basic")
+
+		gt.V(t, get(srv, "/", func(r *http.Request) { r.SetBasicAuth("octovy", "wrong") }).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, get(srv, "/", func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") }).Code).Equal(http.StatusUnauthorized)
+		gt.V(t, get(srv, "/assets/app.js", func(r *http.Request) { r.SetBasicAuth("octovy", "s3cret") }).Code).Equal(http.StatusOK)
+
+		body := `{"query": "{ repositories(owner: \"myorg\") { id } }"}`
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		rec = httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		gt.V(t, rec.Code).Equal(http.StatusUnauthorized)
+		gt.A(t, mockUC.ListRepositoriesCalls()).Length(0)
+
+		req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.SetBasicAuth("octovy", "s3cret")
+		rec = httptest.NewRecorder()
+		srv.Mux().ServeHTTP(rec, req)
+		gt.V(t, rec.Code).Equal(http.StatusOK)
+		gt.A(t, mockUC.ListRepositoriesCalls()).Length(1)
+
+		// Other endpoints are not affected
+		gt.V(t, get(srv, "/health", nil).Code).Equal(http.StatusOK)
+	})
+}
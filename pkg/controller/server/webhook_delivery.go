@@ -0,0 +1,63 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultWebhookDedupeTTL = time.Hour
+
+// deliveryCache remembers X-GitHub-Delivery GUIDs of accepted webhooks for ttl, so that automatic retries and manual redeliveries of an event do not start duplicate scans. It is kept in memory, then deliveries to other server instances are not deduplicated.
+type deliveryCache struct {
+	ttl   time.Duration
+	now   func() time.Time
+	mutex sync.Mutex
+	seen  map[string]time.Time
+	// nextPrune is time to remove expired GUIDs from seen next
+	nextPrune time.Time
+}
+
+func newDeliveryCache(ttl time.Duration) *deliveryCache {
+	return &deliveryCache{
+		ttl:  ttl,
+		now:  time.Now,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// claim records the delivery and returns true if it has not been seen within ttl. A delivery without GUID, or any delivery if the cache is disabled, is always claimed.
+func (x *deliveryCache) claim(id string) bool {
+	if x == nil || x.ttl <= 0 || id == "" {
+		return true
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	now := x.now()
+	if now.After(x.nextPrune) {
+		for key, expiresAt := range x.seen {
+			if !now.Before(expiresAt) {
+				delete(x.seen, key)
+			}
+		}
+		x.nextPrune = now.Add(x.ttl)
+	}
+
+	if expiresAt, ok := x.seen[id]; ok && now.Before(expiresAt) {
+		return false
+	}
+	x.seen[id] = now.Add(x.ttl)
+	return true
+}
+
+// release forgets the delivery so that its redelivery is accepted, e.g. when the webhook is rejected because the scan queue is full.
+func (x *deliveryCache) release(id string) {
+	if x == nil || id == "" {
+		return
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	delete(x.seen, id)
+}
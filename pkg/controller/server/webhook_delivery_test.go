@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/controller/server"
+)
+
+func TestDeliveryCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("duplicate delivery is not claimed", func(t *testing.T) {
+		cache := server.NewDeliveryCacheForTest(time.Hour, clock)
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+		gt.False(t, cache.ClaimForTest("delivery-1"))
+		gt.True(t, cache.ClaimForTest("delivery-2"))
+	})
+
+	t.Run("released delivery can be redelivered", func(t *testing.T) {
+		cache := server.NewDeliveryCacheForTest(time.Hour, clock)
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+
+		// e.g. the scan queue was full and the webhook was rejected
+		cache.ReleaseForTest("delivery-1")
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+		gt.False(t, cache.ClaimForTest("delivery-1"))
+	})
+
+	t.Run("delivery expires after TTL", func(t *testing.T) {
+		current := now
+		cache := server.NewDeliveryCacheForTest(time.Hour, func() time.Time { return current })
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+
+		current = now.Add(59 * time.Minute)
+		gt.False(t, cache.ClaimForTest("delivery-1"))
+
+		current = now.Add(time.Hour)
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+	})
+
+	t.Run("delivery without GUID is always claimed", func(t *testing.T) {
+		cache := server.NewDeliveryCacheForTest(time.Hour, clock)
+		gt.True(t, cache.ClaimForTest(""))
+		gt.True(t, cache.ClaimForTest(""))
+	})
+
+	t.Run("disabled cache claims all deliveries", func(t *testing.T) {
+		cache := server.NewDeliveryCacheForTest(0, clock)
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+		gt.True(t, cache.ClaimForTest("delivery-1"))
+
+		var nilCache *server.DeliveryCacheForTest
+		gt.True(t, nilCache.ClaimForTest("delivery-1"))
+		nilCache.ReleaseForTest("delivery-1")
+	})
+}
@@ -0,0 +1,23 @@
+package interfaces
+
+//go:generate moq -out ../mock/auth.go -pkg mock . GitHubOAuth OIDCVerifier
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+// GitHubOAuth signs in users of the dashboard with the authorization code flow of a GitHub OAuth App
+type GitHubOAuth interface {
+	// AuthCodeURL returns the URL of GitHub to which a user is redirected to authorize the app
+	AuthCodeURL(state string) string
+	// Exchange exchanges the authorization code for an access token and returns the user with organizations and teams which the user belongs to
+	Exchange(ctx context.Context, code string) (*model.GitHubOAuthUser, error)
+}
+
+// OIDCVerifier verifies OIDC ID tokens given to the API as bearer tokens, e.g. tokens of GitHub Actions
+type OIDCVerifier interface {
+	// Verify checks the signature, issuer, audience and expiry of the token and returns its claims
+	Verify(ctx context.Context, token string) (*model.OIDCClaims, error)
+}
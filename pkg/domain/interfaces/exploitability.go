@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+// ExploitabilitySource provides EPSS scores and KEV flags of CVEs
+type ExploitabilitySource interface {
+	// Lookup returns exploitability of CVEs by CVE ID. CVEs without any data are not included.
+	Lookup(ctx context.Context, cveIDs []string) (map[string]*model.Exploitability, error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+// Fetcher makes source code of a scan available as a local directory. A fetcher is selected by the kind of input.Source.
+type Fetcher interface {
+	// Fetch places the source code in workDir, which is an empty temporary directory removed after the scan, and returns the directory to be scanned. A fetcher of code which already exists locally may return another directory.
+	Fetch(ctx context.Context, input *model.ScanGitHubRepoInput, workDir string) (*model.FetchedSource, error)
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+// GatePolicy decides whether a branch passes a deployment gate in addition to the built-in evaluation, e.g. by a WASM policy plugin
+type GatePolicy interface {
+	// Name identifies the policy in gate results and logs
+	Name() string
+	Evaluate(ctx context.Context, input *model.GatePolicyInput) (*model.GatePolicyDecision, error)
+}
@@ -1,11 +1,13 @@
 package interfaces
 
-//go:generate moq -out ../mock/infra.go -pkg mock . BigQuery GitHubApp
+//go:generate moq -out ../mock/infra.go -pkg mock . BigQuery GitHubApp ObjectStorage ObjectReader
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 
@@ -27,17 +29,96 @@ func WithRetry(retry bool) BigQueryInsertOption {
 
 type BigQuery interface {
 	Insert(ctx context.Context, schema bigquery.Schema, data any, opts ...BigQueryInsertOption) error
+	// InsertRows appends multiple rows to the table through a single stream. It does nothing if rows is empty.
+	InsertRows(ctx context.Context, schema bigquery.Schema, rows []any, opts ...BigQueryInsertOption) error
 
 	GetMetadata(ctx context.Context) (*bigquery.TableMetadata, error)
 	UpdateTable(ctx context.Context, md bigquery.TableMetadataToUpdate, eTag string) error
 	CreateTable(ctx context.Context, md *bigquery.TableMetadata) error
+
+	// Table returns a client of another table in the same dataset.
+	Table(tableID types.BQTableID) BigQuery
+
+	// CountRows returns the number of rows matching all conditions. It returns 0 if the table does not exist.
+	CountRows(ctx context.Context, conditions ...BigQueryCondition) (int64, error)
+	// DeleteRows deletes rows matching all conditions and returns the number of deleted rows. At least one condition is required. It returns 0 if the table does not exist.
+	DeleteRows(ctx context.Context, conditions ...BigQueryCondition) (int64, error)
+
+	// CountRowsByDay returns the number of rows of each UTC day before the time by the timestamp column in ascending order of day. It returns nil if the table does not exist.
+	CountRowsByDay(ctx context.Context, before time.Time) ([]*BigQueryDayRows, error)
+	// ExportRows writes rows in the time range to Cloud Storage as Snappy compressed Parquet files. uri must have one "*" wildcard, e.g. "gs://bucket/archive/*.parquet".
+	ExportRows(ctx context.Context, uri string, r BigQueryTimeRange) error
+	// DeleteRowsInRange deletes rows in the time range and returns the number of deleted rows.
+	DeleteRowsInRange(ctx context.Context, r BigQueryTimeRange) (int64, error)
+	// LoadParquet appends rows of Parquet files on Cloud Storage to the table and returns the number of loaded rows. uri may have a "*" wildcard.
+	LoadParquet(ctx context.Context, uri string) (int64, error)
+
+	// ListRawScans calls fn with scans of the raw scan table matching all conditions in ascending order of timestamp. Rows are read one by one, so that the whole history is not loaded into memory. It does nothing if the table does not exist.
+	ListRawScans(ctx context.Context, fn func(scan *model.Scan) error, conditions ...BigQueryCondition) error
+
+	// CheckDataset returns an error if metadata of the dataset can not be read, e.g. the dataset does not exist or permission is missing
+	CheckDataset(ctx context.Context) error
+}
+
+// BigQueryCondition matches rows whose Column equals Value. Column is a column path such as "github.owner" and must not come from user input.
+type BigQueryCondition struct {
+	Column string
+	Value  string
+}
+
+// BigQueryTimeRange matches rows whose timestamp column is in [Start, End)
+type BigQueryTimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// BigQueryDayRows is the number of rows of a UTC day
+type BigQueryDayRows struct {
+	Day  time.Time
+	Rows int64
+}
+
+// ObjectStorage stores data which does not fit in the scan repository
+type ObjectStorage interface {
+	// Put writes data to the key, overwriting an existing object, and returns URI of the object
+	Put(ctx context.Context, key string, data []byte) (string, error)
+}
+
+// ObjectReader reads objects of any bucket, e.g. source code archives uploaded by CI
+type ObjectReader interface {
+	Open(ctx context.Context, bucket, object string) (io.ReadCloser, error)
+}
+
+// ScanLock excludes concurrent scans of the same key, among goroutines of a process or among processes sharing the lock
+type ScanLock interface {
+	// Lock waits until the lock of the key is acquired or ctx is done, and returns a function to release it
+	Lock(ctx context.Context, key string) (unlock func(), err error)
 }
 
 type GitHubApp interface {
 	GetArchiveURL(ctx context.Context, input *GetArchiveURLInput) (*url.URL, error)
 	HTTPClient(installID types.GitHubAppInstallID) (*http.Client, error)
+	// InstallationToken returns an access token of the installation, e.g. to clone a repository with git
+	InstallationToken(ctx context.Context, installID types.GitHubAppInstallID) (string, error)
 	ListInstallationRepos(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error)
 	GetInstallationIDForOwner(ctx context.Context, owner string) (types.GitHubAppInstallID, error)
+	ListCodeScanningAlerts(ctx context.Context, input *ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error)
+	ListPullRequestsWithCommit(ctx context.Context, input *ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error)
+	ListRepositoryTeams(ctx context.Context, input *ListRepositoryTeamsInput) ([]*model.GitHubTeam, error)
+	AddLabelsToPullRequest(ctx context.Context, input *AddLabelsToPullRequestInput) error
+	// CreateCheckRun creates a completed check run of the commit with annotations
+	CreateCheckRun(ctx context.Context, input *CreateCheckRunInput) error
+	// ListPullRequestFiles returns paths of files changed by the pull request. The previous path of a renamed file is also included.
+	ListPullRequestFiles(ctx context.Context, input *ListPullRequestFilesInput) ([]string, error)
+	// ListPullRequests returns pull requests of the repository in the state, optionally of the head branch
+	ListPullRequests(ctx context.Context, input *ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error)
+	// CreatePullRequest commits files onto a new branch from the base commit and opens a pull request of the branch
+	CreatePullRequest(ctx context.Context, input *CreatePullRequestInput) (*model.GitHubPullRequestRef, error)
+	// RateLimit returns the latest observed rate limit of the installation, or nil if unknown
+	RateLimit(installID types.GitHubAppInstallID) *model.GitHubRateLimit
+	RateLimits() []*model.GitHubRateLimit
+	// CheckCredential returns an error if the app can not be authenticated with its ID and private key
+	CheckCredential(ctx context.Context) error
 }
 
 type GetArchiveURLInput struct {
@@ -46,3 +127,76 @@ type GetArchiveURLInput struct {
 	CommitID  string
 	InstallID types.GitHubAppInstallID
 }
+
+type ListCodeScanningAlertsInput struct {
+	Owner     string
+	Repo      string
+	Branch    string
+	State     string // "open", "closed", "dismissed" or "fixed"; empty means all states
+	InstallID types.GitHubAppInstallID
+}
+
+type ListPullRequestsWithCommitInput struct {
+	Owner     string
+	Repo      string
+	CommitID  string
+	InstallID types.GitHubAppInstallID
+}
+
+type ListRepositoryTeamsInput struct {
+	Owner     string
+	Repo      string
+	InstallID types.GitHubAppInstallID
+}
+
+type AddLabelsToPullRequestInput struct {
+	Owner     string
+	Repo      string
+	Number    int
+	Labels    []string
+	InstallID types.GitHubAppInstallID
+}
+
+type ListPullRequestFilesInput struct {
+	Owner     string
+	Repo      string
+	Number    int
+	InstallID types.GitHubAppInstallID
+}
+
+type ListPullRequestsInput struct {
+	Owner string
+	Repo  string
+	State string // "open", "closed" or "all"
+	// Head is the branch of pull requests in the repository, or empty for all branches
+	Head      string
+	InstallID types.GitHubAppInstallID
+}
+
+type CreatePullRequestInput struct {
+	Owner string
+	Repo  string
+	// BaseBranch is the branch the pull request is merged into, and BaseCommitID is the commit of it which the new branch is created from
+	BaseBranch   string
+	BaseCommitID string
+	Branch       string
+	Title        string
+	Body         string
+	Message      string
+	// Files are contents of files to commit by paths relative to the repository root
+	Files     map[string][]byte
+	Labels    []string
+	InstallID types.GitHubAppInstallID
+}
+
+type CreateCheckRunInput struct {
+	Owner       string
+	Repo        string
+	HeadSHA     string
+	Name        string
+	Conclusion  string
+	Title       string
+	Summary     string
+	Annotations []*model.CheckAnnotation
+	InstallID   types.GitHubAppInstallID
+}
@@ -2,13 +2,12 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
 
-//go:generate moq -out ../mock/scan_repository_mock.go -pkg mock . ScanRepository
-
 // ScanRepository manages scan information for GitHub repositories
 type ScanRepository interface {
 	// Repository operations
@@ -16,9 +15,13 @@ type ScanRepository interface {
 	GetRepository(ctx context.Context, repoID types.GitHubRepoID) (*model.Repository, error)
 	ListRepositories(ctx context.Context, installationID int64) ([]*model.Repository, error)
 	ListRepositoriesByOwner(ctx context.Context, owner string) ([]*model.Repository, error)
+	// DeleteRepository deletes the repository with all of its branches, targets, vulnerabilities, status history and scan records. It does nothing if the repository does not exist.
+	DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error
 
 	// Branch operations
 	CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error
+	// ClaimBranchScan creates or updates the branch atomically unless the stored branch has later LastScanStartedAt than the branch, in which case it returns repository.ErrConflict. A scan claims the branch before storing its findings, so that a scan started earlier but finished later does not overwrite newer state.
+	ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error
 	GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error)
 	ListBranches(ctx context.Context, repoID types.GitHubRepoID) ([]*model.Branch, error)
 
@@ -26,9 +29,52 @@ type ScanRepository interface {
 	CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error
 	GetTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*model.Target, error)
 	ListTargets(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) ([]*model.Target, error)
+	// MoveTarget moves the target of fromID with its vulnerabilities, status history and packages to target.ID, and stores target as the moved target. It returns repository.ErrNotFound if the target of fromID does not exist.
+	MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error
 
 	// Vulnerability operations (batch only)
 	ListVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Vulnerability, error)
 	BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error
-	BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, updates map[string]types.VulnStatus) error
+	// BatchUpdateVulnerabilityStatus applies status changes and appends them to the history of each vulnerability. UpdatedAt of the vulnerability is set to the timestamp of the change.
+	BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error
+	// ListVulnerabilityHistory returns status changes of the vulnerability, oldest first
+	ListVulnerabilityHistory(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnID string) ([]*model.VulnStatusChange, error)
+	BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error
+	// ListRepositoryVulnerabilities returns vulnerabilities of all targets of all branches of the repository with their branch and target. It returns repository.ErrNotFound if the repository does not exist.
+	ListRepositoryVulnerabilities(ctx context.Context, repoID types.GitHubRepoID) ([]*model.BranchVulnerability, error)
+
+	// Package operations (batch only)
+	ListPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Package, error)
+	// BatchUpsertPackages creates packages or overwrites existing ones with the same ID
+	BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error
+	BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error
+
+	// Scan history operations
+	CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error
+	ListScanRecords(ctx context.Context, repoID types.GitHubRepoID) ([]*model.ScanRecord, error)
+	// ListScanRecordsByCommitter returns scan records of all repositories committed by login since the time, newest first
+	ListScanRecordsByCommitter(ctx context.Context, login string, since time.Time) ([]*model.ScanRecord, error)
+	BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error
+
+	// Severity override operations
+	// PutSeverityOverride creates the override or overwrites an existing one with the same owner and ID
+	PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error
+	ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error)
+	// DeleteSeverityOverride returns repository.ErrNotFound if the override does not exist
+	DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error
+
+	// Scan run operations
+	// PutScanRun creates the run or overwrites an existing one with the same ID
+	PutScanRun(ctx context.Context, run *model.ScanRun) error
+	// GetScanRun returns repository.ErrNotFound if the run does not exist
+	GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error)
+
+	// Owner scan run operations
+	// PutOwnerScanRun creates the run or overwrites an existing one with the same ID
+	PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error
+	// GetOwnerScanRun returns repository.ErrNotFound if the run does not exist
+	GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error)
+
+	// Ping returns an error if the backend of the repository is not reachable
+	Ping(ctx context.Context) error
 }
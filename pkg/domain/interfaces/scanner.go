@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+// Scanner scans a directory of source code for vulnerabilities. The result is returned in the Trivy report format regardless of the scanner, so that results of every scanner are stored and notified in the same way.
+type Scanner interface {
+	Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error)
+}
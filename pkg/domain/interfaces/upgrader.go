@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+// PackageUpgrader upgrades a package in manifest and lock files of a directory with the package manager of its ecosystem
+type PackageUpgrader interface {
+	// Upgrade updates files of model.UpgradeManifests in dir to the fixed version of the upgrade. Only the files are placed in dir.
+	Upgrade(ctx context.Context, dir string, upgrade *model.PackageUpgrade) error
+}
@@ -13,4 +13,34 @@ import (
 type UseCase interface {
 	InsertScanResult(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error)
 	ScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) error
+	PrepareScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error)
+	CreateScanRun(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error)
+	GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error)
+	EvaluateGate(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error)
+	CheckSLA(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error)
+	GetDeveloperSummary(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error)
+	GetBackstagePosture(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error)
+	ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error)
+	GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error)
+	SeedInstallationRepos(ctx context.Context, input *model.SeedInstallationReposInput) (int, error)
+	GetGitHubRateLimits(ctx context.Context) []*model.GitHubRateLimit
+	CheckReadiness(ctx context.Context) *model.Readiness
+	UpdateRepositoryMetadata(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error)
+	PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error)
+	ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error)
+	DeleteSeverityOverride(ctx context.Context, input *model.DeleteSeverityOverrideInput) error
+	ListBranchStatus(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error)
+	GetVulnerabilityRollup(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error)
+	GetPermalinkView(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error)
+	GetVulnerabilityDetail(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error)
+
+	GetRepository(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error)
+	ListRepositories(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error)
+	ListBranches(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error)
+	ListTargets(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error)
+	ListVulnerabilities(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error)
+
+	PrepareAgentScanJob(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error)
+	ScanAgentJob(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error)
+	CompleteAgentScanJob(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error
 }
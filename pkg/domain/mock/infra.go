@@ -9,9 +9,11 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"io"
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 )
 
 // Ensure, that BigQueryMock does implement interfaces.BigQuery.
@@ -24,15 +26,45 @@ var _ interfaces.BigQuery = &BigQueryMock{}
 //
 //		// make and configure a mocked interfaces.BigQuery
 //		mockedBigQuery := &BigQueryMock{
+//			CheckDatasetFunc: func(ctx context.Context) error {
+//				panic("mock out the CheckDataset method")
+//			},
+//			CountRowsFunc: func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+//				panic("mock out the CountRows method")
+//			},
+//			CountRowsByDayFunc: func(ctx context.Context, before time.Time) ([]*interfaces.BigQueryDayRows, error) {
+//				panic("mock out the CountRowsByDay method")
+//			},
 //			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error {
 //				panic("mock out the CreateTable method")
 //			},
+//			DeleteRowsFunc: func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+//				panic("mock out the DeleteRows method")
+//			},
+//			DeleteRowsInRangeFunc: func(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error) {
+//				panic("mock out the DeleteRowsInRange method")
+//			},
+//			ExportRowsFunc: func(ctx context.Context, uri string, r interfaces.BigQueryTimeRange) error {
+//				panic("mock out the ExportRows method")
+//			},
 //			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
 //				panic("mock out the GetMetadata method")
 //			},
 //			InsertFunc: func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
 //				panic("mock out the Insert method")
 //			},
+//			InsertRowsFunc: func(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error {
+//				panic("mock out the InsertRows method")
+//			},
+//			ListRawScansFunc: func(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error {
+//				panic("mock out the ListRawScans method")
+//			},
+//			LoadParquetFunc: func(ctx context.Context, uri string) (int64, error) {
+//				panic("mock out the LoadParquet method")
+//			},
+//			TableFunc: func(tableID types.BQTableID) interfaces.BigQuery {
+//				panic("mock out the Table method")
+//			},
 //			UpdateTableFunc: func(ctx context.Context, md bigquery.TableMetadataToUpdate, eTag string) error {
 //				panic("mock out the UpdateTable method")
 //			},
@@ -43,20 +75,69 @@ var _ interfaces.BigQuery = &BigQueryMock{}
 //
 //	}
 type BigQueryMock struct {
+	// CheckDatasetFunc mocks the CheckDataset method.
+	CheckDatasetFunc func(ctx context.Context) error
+
+	// CountRowsFunc mocks the CountRows method.
+	CountRowsFunc func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error)
+
+	// CountRowsByDayFunc mocks the CountRowsByDay method.
+	CountRowsByDayFunc func(ctx context.Context, before time.Time) ([]*interfaces.BigQueryDayRows, error)
+
 	// CreateTableFunc mocks the CreateTable method.
 	CreateTableFunc func(ctx context.Context, md *bigquery.TableMetadata) error
 
+	// DeleteRowsFunc mocks the DeleteRows method.
+	DeleteRowsFunc func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error)
+
+	// DeleteRowsInRangeFunc mocks the DeleteRowsInRange method.
+	DeleteRowsInRangeFunc func(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error)
+
+	// ExportRowsFunc mocks the ExportRows method.
+	ExportRowsFunc func(ctx context.Context, uri string, r interfaces.BigQueryTimeRange) error
+
 	// GetMetadataFunc mocks the GetMetadata method.
 	GetMetadataFunc func(ctx context.Context) (*bigquery.TableMetadata, error)
 
 	// InsertFunc mocks the Insert method.
 	InsertFunc func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error
 
+	// InsertRowsFunc mocks the InsertRows method.
+	InsertRowsFunc func(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error
+
+	// ListRawScansFunc mocks the ListRawScans method.
+	ListRawScansFunc func(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error
+
+	// LoadParquetFunc mocks the LoadParquet method.
+	LoadParquetFunc func(ctx context.Context, uri string) (int64, error)
+
+	// TableFunc mocks the Table method.
+	TableFunc func(tableID types.BQTableID) interfaces.BigQuery
+
 	// UpdateTableFunc mocks the UpdateTable method.
 	UpdateTableFunc func(ctx context.Context, md bigquery.TableMetadataToUpdate, eTag string) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// CheckDataset holds details about calls to the CheckDataset method.
+		CheckDataset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// CountRows holds details about calls to the CountRows method.
+		CountRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Conditions is the conditions argument value.
+			Conditions []interfaces.BigQueryCondition
+		}
+		// CountRowsByDay holds details about calls to the CountRowsByDay method.
+		CountRowsByDay []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
 		// CreateTable holds details about calls to the CreateTable method.
 		CreateTable []struct {
 			// Ctx is the ctx argument value.
@@ -64,6 +145,29 @@ type BigQueryMock struct {
 			// Md is the md argument value.
 			Md *bigquery.TableMetadata
 		}
+		// DeleteRows holds details about calls to the DeleteRows method.
+		DeleteRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Conditions is the conditions argument value.
+			Conditions []interfaces.BigQueryCondition
+		}
+		// DeleteRowsInRange holds details about calls to the DeleteRowsInRange method.
+		DeleteRowsInRange []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// R is the r argument value.
+			R interfaces.BigQueryTimeRange
+		}
+		// ExportRows holds details about calls to the ExportRows method.
+		ExportRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// URI is the uri argument value.
+			URI string
+			// R is the r argument value.
+			R interfaces.BigQueryTimeRange
+		}
 		// GetMetadata holds details about calls to the GetMetadata method.
 		GetMetadata []struct {
 			// Ctx is the ctx argument value.
@@ -80,6 +184,38 @@ type BigQueryMock struct {
 			// Opts is the opts argument value.
 			Opts []interfaces.BigQueryInsertOption
 		}
+		// InsertRows holds details about calls to the InsertRows method.
+		InsertRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Schema is the schema argument value.
+			Schema bigquery.Schema
+			// Rows is the rows argument value.
+			Rows []any
+			// Opts is the opts argument value.
+			Opts []interfaces.BigQueryInsertOption
+		}
+		// ListRawScans holds details about calls to the ListRawScans method.
+		ListRawScans []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Fn is the fn argument value.
+			Fn func(scan *model.Scan) error
+			// Conditions is the conditions argument value.
+			Conditions []interfaces.BigQueryCondition
+		}
+		// LoadParquet holds details about calls to the LoadParquet method.
+		LoadParquet []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// URI is the uri argument value.
+			URI string
+		}
+		// Table holds details about calls to the Table method.
+		Table []struct {
+			// TableID is the tableID argument value.
+			TableID types.BQTableID
+		}
 		// UpdateTable holds details about calls to the UpdateTable method.
 		UpdateTable []struct {
 			// Ctx is the ctx argument value.
@@ -90,10 +226,124 @@ type BigQueryMock struct {
 			ETag string
 		}
 	}
-	lockCreateTable sync.RWMutex
-	lockGetMetadata sync.RWMutex
-	lockInsert      sync.RWMutex
-	lockUpdateTable sync.RWMutex
+	lockCheckDataset      sync.RWMutex
+	lockCountRows         sync.RWMutex
+	lockCountRowsByDay    sync.RWMutex
+	lockCreateTable       sync.RWMutex
+	lockDeleteRows        sync.RWMutex
+	lockDeleteRowsInRange sync.RWMutex
+	lockExportRows        sync.RWMutex
+	lockGetMetadata       sync.RWMutex
+	lockInsert            sync.RWMutex
+	lockInsertRows        sync.RWMutex
+	lockListRawScans      sync.RWMutex
+	lockLoadParquet       sync.RWMutex
+	lockTable             sync.RWMutex
+	lockUpdateTable       sync.RWMutex
+}
+
+// CheckDataset calls CheckDatasetFunc.
+func (mock *BigQueryMock) CheckDataset(ctx context.Context) error {
+	if mock.CheckDatasetFunc == nil {
+		panic("BigQueryMock.CheckDatasetFunc: method is nil but BigQuery.CheckDataset was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCheckDataset.Lock()
+	mock.calls.CheckDataset = append(mock.calls.CheckDataset, callInfo)
+	mock.lockCheckDataset.Unlock()
+	return mock.CheckDatasetFunc(ctx)
+}
+
+// CheckDatasetCalls gets all the calls that were made to CheckDataset.
+// Check the length with:
+//
+//	len(mockedBigQuery.CheckDatasetCalls())
+func (mock *BigQueryMock) CheckDatasetCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCheckDataset.RLock()
+	calls = mock.calls.CheckDataset
+	mock.lockCheckDataset.RUnlock()
+	return calls
+}
+
+// CountRows calls CountRowsFunc.
+func (mock *BigQueryMock) CountRows(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+	if mock.CountRowsFunc == nil {
+		panic("BigQueryMock.CountRowsFunc: method is nil but BigQuery.CountRows was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Conditions []interfaces.BigQueryCondition
+	}{
+		Ctx:        ctx,
+		Conditions: conditions,
+	}
+	mock.lockCountRows.Lock()
+	mock.calls.CountRows = append(mock.calls.CountRows, callInfo)
+	mock.lockCountRows.Unlock()
+	return mock.CountRowsFunc(ctx, conditions...)
+}
+
+// CountRowsCalls gets all the calls that were made to CountRows.
+// Check the length with:
+//
+//	len(mockedBigQuery.CountRowsCalls())
+func (mock *BigQueryMock) CountRowsCalls() []struct {
+	Ctx        context.Context
+	Conditions []interfaces.BigQueryCondition
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Conditions []interfaces.BigQueryCondition
+	}
+	mock.lockCountRows.RLock()
+	calls = mock.calls.CountRows
+	mock.lockCountRows.RUnlock()
+	return calls
+}
+
+// CountRowsByDay calls CountRowsByDayFunc.
+func (mock *BigQueryMock) CountRowsByDay(ctx context.Context, before time.Time) ([]*interfaces.BigQueryDayRows, error) {
+	if mock.CountRowsByDayFunc == nil {
+		panic("BigQueryMock.CountRowsByDayFunc: method is nil but BigQuery.CountRowsByDay was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockCountRowsByDay.Lock()
+	mock.calls.CountRowsByDay = append(mock.calls.CountRowsByDay, callInfo)
+	mock.lockCountRowsByDay.Unlock()
+	return mock.CountRowsByDayFunc(ctx, before)
+}
+
+// CountRowsByDayCalls gets all the calls that were made to CountRowsByDay.
+// Check the length with:
+//
+//	len(mockedBigQuery.CountRowsByDayCalls())
+func (mock *BigQueryMock) CountRowsByDayCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockCountRowsByDay.RLock()
+	calls = mock.calls.CountRowsByDay
+	mock.lockCountRowsByDay.RUnlock()
+	return calls
 }
 
 // CreateTable calls CreateTableFunc.
@@ -132,6 +382,118 @@ func (mock *BigQueryMock) CreateTableCalls() []struct {
 	return calls
 }
 
+// DeleteRows calls DeleteRowsFunc.
+func (mock *BigQueryMock) DeleteRows(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+	if mock.DeleteRowsFunc == nil {
+		panic("BigQueryMock.DeleteRowsFunc: method is nil but BigQuery.DeleteRows was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Conditions []interfaces.BigQueryCondition
+	}{
+		Ctx:        ctx,
+		Conditions: conditions,
+	}
+	mock.lockDeleteRows.Lock()
+	mock.calls.DeleteRows = append(mock.calls.DeleteRows, callInfo)
+	mock.lockDeleteRows.Unlock()
+	return mock.DeleteRowsFunc(ctx, conditions...)
+}
+
+// DeleteRowsCalls gets all the calls that were made to DeleteRows.
+// Check the length with:
+//
+//	len(mockedBigQuery.DeleteRowsCalls())
+func (mock *BigQueryMock) DeleteRowsCalls() []struct {
+	Ctx        context.Context
+	Conditions []interfaces.BigQueryCondition
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Conditions []interfaces.BigQueryCondition
+	}
+	mock.lockDeleteRows.RLock()
+	calls = mock.calls.DeleteRows
+	mock.lockDeleteRows.RUnlock()
+	return calls
+}
+
+// DeleteRowsInRange calls DeleteRowsInRangeFunc.
+func (mock *BigQueryMock) DeleteRowsInRange(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error) {
+	if mock.DeleteRowsInRangeFunc == nil {
+		panic("BigQueryMock.DeleteRowsInRangeFunc: method is nil but BigQuery.DeleteRowsInRange was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		R   interfaces.BigQueryTimeRange
+	}{
+		Ctx: ctx,
+		R:   r,
+	}
+	mock.lockDeleteRowsInRange.Lock()
+	mock.calls.DeleteRowsInRange = append(mock.calls.DeleteRowsInRange, callInfo)
+	mock.lockDeleteRowsInRange.Unlock()
+	return mock.DeleteRowsInRangeFunc(ctx, r)
+}
+
+// DeleteRowsInRangeCalls gets all the calls that were made to DeleteRowsInRange.
+// Check the length with:
+//
+//	len(mockedBigQuery.DeleteRowsInRangeCalls())
+func (mock *BigQueryMock) DeleteRowsInRangeCalls() []struct {
+	Ctx context.Context
+	R   interfaces.BigQueryTimeRange
+} {
+	var calls []struct {
+		Ctx context.Context
+		R   interfaces.BigQueryTimeRange
+	}
+	mock.lockDeleteRowsInRange.RLock()
+	calls = mock.calls.DeleteRowsInRange
+	mock.lockDeleteRowsInRange.RUnlock()
+	return calls
+}
+
+// ExportRows calls ExportRowsFunc.
+func (mock *BigQueryMock) ExportRows(ctx context.Context, uri string, r interfaces.BigQueryTimeRange) error {
+	if mock.ExportRowsFunc == nil {
+		panic("BigQueryMock.ExportRowsFunc: method is nil but BigQuery.ExportRows was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		URI string
+		R   interfaces.BigQueryTimeRange
+	}{
+		Ctx: ctx,
+		URI: uri,
+		R:   r,
+	}
+	mock.lockExportRows.Lock()
+	mock.calls.ExportRows = append(mock.calls.ExportRows, callInfo)
+	mock.lockExportRows.Unlock()
+	return mock.ExportRowsFunc(ctx, uri, r)
+}
+
+// ExportRowsCalls gets all the calls that were made to ExportRows.
+// Check the length with:
+//
+//	len(mockedBigQuery.ExportRowsCalls())
+func (mock *BigQueryMock) ExportRowsCalls() []struct {
+	Ctx context.Context
+	URI string
+	R   interfaces.BigQueryTimeRange
+} {
+	var calls []struct {
+		Ctx context.Context
+		URI string
+		R   interfaces.BigQueryTimeRange
+	}
+	mock.lockExportRows.RLock()
+	calls = mock.calls.ExportRows
+	mock.lockExportRows.RUnlock()
+	return calls
+}
+
 // GetMetadata calls GetMetadataFunc.
 func (mock *BigQueryMock) GetMetadata(ctx context.Context) (*bigquery.TableMetadata, error) {
 	if mock.GetMetadataFunc == nil {
@@ -208,6 +570,158 @@ func (mock *BigQueryMock) InsertCalls() []struct {
 	return calls
 }
 
+// InsertRows calls InsertRowsFunc.
+func (mock *BigQueryMock) InsertRows(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error {
+	if mock.InsertRowsFunc == nil {
+		panic("BigQueryMock.InsertRowsFunc: method is nil but BigQuery.InsertRows was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Schema bigquery.Schema
+		Rows   []any
+		Opts   []interfaces.BigQueryInsertOption
+	}{
+		Ctx:    ctx,
+		Schema: schema,
+		Rows:   rows,
+		Opts:   opts,
+	}
+	mock.lockInsertRows.Lock()
+	mock.calls.InsertRows = append(mock.calls.InsertRows, callInfo)
+	mock.lockInsertRows.Unlock()
+	return mock.InsertRowsFunc(ctx, schema, rows, opts...)
+}
+
+// InsertRowsCalls gets all the calls that were made to InsertRows.
+// Check the length with:
+//
+//	len(mockedBigQuery.InsertRowsCalls())
+func (mock *BigQueryMock) InsertRowsCalls() []struct {
+	Ctx    context.Context
+	Schema bigquery.Schema
+	Rows   []any
+	Opts   []interfaces.BigQueryInsertOption
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Schema bigquery.Schema
+		Rows   []any
+		Opts   []interfaces.BigQueryInsertOption
+	}
+	mock.lockInsertRows.RLock()
+	calls = mock.calls.InsertRows
+	mock.lockInsertRows.RUnlock()
+	return calls
+}
+
+// ListRawScans calls ListRawScansFunc.
+func (mock *BigQueryMock) ListRawScans(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error {
+	if mock.ListRawScansFunc == nil {
+		panic("BigQueryMock.ListRawScansFunc: method is nil but BigQuery.ListRawScans was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Fn         func(scan *model.Scan) error
+		Conditions []interfaces.BigQueryCondition
+	}{
+		Ctx:        ctx,
+		Fn:         fn,
+		Conditions: conditions,
+	}
+	mock.lockListRawScans.Lock()
+	mock.calls.ListRawScans = append(mock.calls.ListRawScans, callInfo)
+	mock.lockListRawScans.Unlock()
+	return mock.ListRawScansFunc(ctx, fn, conditions...)
+}
+
+// ListRawScansCalls gets all the calls that were made to ListRawScans.
+// Check the length with:
+//
+//	len(mockedBigQuery.ListRawScansCalls())
+func (mock *BigQueryMock) ListRawScansCalls() []struct {
+	Ctx        context.Context
+	Fn         func(scan *model.Scan) error
+	Conditions []interfaces.BigQueryCondition
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Fn         func(scan *model.Scan) error
+		Conditions []interfaces.BigQueryCondition
+	}
+	mock.lockListRawScans.RLock()
+	calls = mock.calls.ListRawScans
+	mock.lockListRawScans.RUnlock()
+	return calls
+}
+
+// LoadParquet calls LoadParquetFunc.
+func (mock *BigQueryMock) LoadParquet(ctx context.Context, uri string) (int64, error) {
+	if mock.LoadParquetFunc == nil {
+		panic("BigQueryMock.LoadParquetFunc: method is nil but BigQuery.LoadParquet was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		URI string
+	}{
+		Ctx: ctx,
+		URI: uri,
+	}
+	mock.lockLoadParquet.Lock()
+	mock.calls.LoadParquet = append(mock.calls.LoadParquet, callInfo)
+	mock.lockLoadParquet.Unlock()
+	return mock.LoadParquetFunc(ctx, uri)
+}
+
+// LoadParquetCalls gets all the calls that were made to LoadParquet.
+// Check the length with:
+//
+//	len(mockedBigQuery.LoadParquetCalls())
+func (mock *BigQueryMock) LoadParquetCalls() []struct {
+	Ctx context.Context
+	URI string
+} {
+	var calls []struct {
+		Ctx context.Context
+		URI string
+	}
+	mock.lockLoadParquet.RLock()
+	calls = mock.calls.LoadParquet
+	mock.lockLoadParquet.RUnlock()
+	return calls
+}
+
+// Table calls TableFunc.
+func (mock *BigQueryMock) Table(tableID types.BQTableID) interfaces.BigQuery {
+	if mock.TableFunc == nil {
+		panic("BigQueryMock.TableFunc: method is nil but BigQuery.Table was just called")
+	}
+	callInfo := struct {
+		TableID types.BQTableID
+	}{
+		TableID: tableID,
+	}
+	mock.lockTable.Lock()
+	mock.calls.Table = append(mock.calls.Table, callInfo)
+	mock.lockTable.Unlock()
+	return mock.TableFunc(tableID)
+}
+
+// TableCalls gets all the calls that were made to Table.
+// Check the length with:
+//
+//	len(mockedBigQuery.TableCalls())
+func (mock *BigQueryMock) TableCalls() []struct {
+	TableID types.BQTableID
+} {
+	var calls []struct {
+		TableID types.BQTableID
+	}
+	mock.lockTable.RLock()
+	calls = mock.calls.Table
+	mock.lockTable.RUnlock()
+	return calls
+}
+
 // UpdateTable calls UpdateTableFunc.
 func (mock *BigQueryMock) UpdateTable(ctx context.Context, md bigquery.TableMetadataToUpdate, eTag string) error {
 	if mock.UpdateTableFunc == nil {
@@ -258,6 +772,18 @@ var _ interfaces.GitHubApp = &GitHubAppMock{}
 //
 //		// make and configure a mocked interfaces.GitHubApp
 //		mockedGitHubApp := &GitHubAppMock{
+//			AddLabelsToPullRequestFunc: func(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+//				panic("mock out the AddLabelsToPullRequest method")
+//			},
+//			CheckCredentialFunc: func(ctx context.Context) error {
+//				panic("mock out the CheckCredential method")
+//			},
+//			CreateCheckRunFunc: func(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+//				panic("mock out the CreateCheckRun method")
+//			},
+//			CreatePullRequestFunc: func(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error) {
+//				panic("mock out the CreatePullRequest method")
+//			},
 //			GetArchiveURLFunc: func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
 //				panic("mock out the GetArchiveURL method")
 //			},
@@ -267,9 +793,33 @@ var _ interfaces.GitHubApp = &GitHubAppMock{}
 //			HTTPClientFunc: func(installID types.GitHubAppInstallID) (*http.Client, error) {
 //				panic("mock out the HTTPClient method")
 //			},
+//			InstallationTokenFunc: func(ctx context.Context, installID types.GitHubAppInstallID) (string, error) {
+//				panic("mock out the InstallationToken method")
+//			},
+//			ListCodeScanningAlertsFunc: func(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error) {
+//				panic("mock out the ListCodeScanningAlerts method")
+//			},
 //			ListInstallationReposFunc: func(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
 //				panic("mock out the ListInstallationRepos method")
 //			},
+//			ListPullRequestFilesFunc: func(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error) {
+//				panic("mock out the ListPullRequestFiles method")
+//			},
+//			ListPullRequestsFunc: func(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error) {
+//				panic("mock out the ListPullRequests method")
+//			},
+//			ListPullRequestsWithCommitFunc: func(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error) {
+//				panic("mock out the ListPullRequestsWithCommit method")
+//			},
+//			ListRepositoryTeamsFunc: func(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error) {
+//				panic("mock out the ListRepositoryTeams method")
+//			},
+//			RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+//				panic("mock out the RateLimit method")
+//			},
+//			RateLimitsFunc: func() []*model.GitHubRateLimit {
+//				panic("mock out the RateLimits method")
+//			},
 //		}
 //
 //		// use mockedGitHubApp in code that requires interfaces.GitHubApp
@@ -277,6 +827,18 @@ var _ interfaces.GitHubApp = &GitHubAppMock{}
 //
 //	}
 type GitHubAppMock struct {
+	// AddLabelsToPullRequestFunc mocks the AddLabelsToPullRequest method.
+	AddLabelsToPullRequestFunc func(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error
+
+	// CheckCredentialFunc mocks the CheckCredential method.
+	CheckCredentialFunc func(ctx context.Context) error
+
+	// CreateCheckRunFunc mocks the CreateCheckRun method.
+	CreateCheckRunFunc func(ctx context.Context, input *interfaces.CreateCheckRunInput) error
+
+	// CreatePullRequestFunc mocks the CreatePullRequest method.
+	CreatePullRequestFunc func(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error)
+
 	// GetArchiveURLFunc mocks the GetArchiveURL method.
 	GetArchiveURLFunc func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error)
 
@@ -286,30 +848,94 @@ type GitHubAppMock struct {
 	// HTTPClientFunc mocks the HTTPClient method.
 	HTTPClientFunc func(installID types.GitHubAppInstallID) (*http.Client, error)
 
+	// InstallationTokenFunc mocks the InstallationToken method.
+	InstallationTokenFunc func(ctx context.Context, installID types.GitHubAppInstallID) (string, error)
+
+	// ListCodeScanningAlertsFunc mocks the ListCodeScanningAlerts method.
+	ListCodeScanningAlertsFunc func(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error)
+
 	// ListInstallationReposFunc mocks the ListInstallationRepos method.
 	ListInstallationReposFunc func(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error)
 
+	// ListPullRequestFilesFunc mocks the ListPullRequestFiles method.
+	ListPullRequestFilesFunc func(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error)
+
+	// ListPullRequestsFunc mocks the ListPullRequests method.
+	ListPullRequestsFunc func(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error)
+
+	// ListPullRequestsWithCommitFunc mocks the ListPullRequestsWithCommit method.
+	ListPullRequestsWithCommitFunc func(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error)
+
+	// ListRepositoryTeamsFunc mocks the ListRepositoryTeams method.
+	ListRepositoryTeamsFunc func(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error)
+
+	// RateLimitFunc mocks the RateLimit method.
+	RateLimitFunc func(installID types.GitHubAppInstallID) *model.GitHubRateLimit
+
+	// RateLimitsFunc mocks the RateLimits method.
+	RateLimitsFunc func() []*model.GitHubRateLimit
+
 	// calls tracks calls to the methods.
 	calls struct {
-		// GetArchiveURL holds details about calls to the GetArchiveURL method.
-		GetArchiveURL []struct {
+		// AddLabelsToPullRequest holds details about calls to the AddLabelsToPullRequest method.
+		AddLabelsToPullRequest []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 			// Input is the input argument value.
-			Input *interfaces.GetArchiveURLInput
+			Input *interfaces.AddLabelsToPullRequestInput
 		}
-		// GetInstallationIDForOwner holds details about calls to the GetInstallationIDForOwner method.
-		GetInstallationIDForOwner []struct {
+		// CheckCredential holds details about calls to the CheckCredential method.
+		CheckCredential []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
-			// Owner is the owner argument value.
-			Owner string
 		}
-		// HTTPClient holds details about calls to the HTTPClient method.
+		// CreateCheckRun holds details about calls to the CreateCheckRun method.
+		CreateCheckRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.CreateCheckRunInput
+		}
+		// CreatePullRequest holds details about calls to the CreatePullRequest method.
+		CreatePullRequest []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.CreatePullRequestInput
+		}
+		// GetArchiveURL holds details about calls to the GetArchiveURL method.
+		GetArchiveURL []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.GetArchiveURLInput
+		}
+		// GetInstallationIDForOwner holds details about calls to the GetInstallationIDForOwner method.
+		GetInstallationIDForOwner []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Owner is the owner argument value.
+			Owner string
+		}
+		// HTTPClient holds details about calls to the HTTPClient method.
 		HTTPClient []struct {
 			// InstallID is the installID argument value.
 			InstallID types.GitHubAppInstallID
 		}
+		// InstallationToken holds details about calls to the InstallationToken method.
+		InstallationToken []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstallID is the installID argument value.
+			InstallID types.GitHubAppInstallID
+		}
+		// ListCodeScanningAlerts holds details about calls to the ListCodeScanningAlerts method.
+		ListCodeScanningAlerts []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.ListCodeScanningAlertsInput
+		}
 		// ListInstallationRepos holds details about calls to the ListInstallationRepos method.
 		ListInstallationRepos []struct {
 			// Ctx is the ctx argument value.
@@ -317,11 +943,199 @@ type GitHubAppMock struct {
 			// InstallID is the installID argument value.
 			InstallID types.GitHubAppInstallID
 		}
+		// ListPullRequestFiles holds details about calls to the ListPullRequestFiles method.
+		ListPullRequestFiles []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.ListPullRequestFilesInput
+		}
+		// ListPullRequests holds details about calls to the ListPullRequests method.
+		ListPullRequests []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.ListPullRequestsInput
+		}
+		// ListPullRequestsWithCommit holds details about calls to the ListPullRequestsWithCommit method.
+		ListPullRequestsWithCommit []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.ListPullRequestsWithCommitInput
+		}
+		// ListRepositoryTeams holds details about calls to the ListRepositoryTeams method.
+		ListRepositoryTeams []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *interfaces.ListRepositoryTeamsInput
+		}
+		// RateLimit holds details about calls to the RateLimit method.
+		RateLimit []struct {
+			// InstallID is the installID argument value.
+			InstallID types.GitHubAppInstallID
+		}
+		// RateLimits holds details about calls to the RateLimits method.
+		RateLimits []struct {
+		}
+	}
+	lockAddLabelsToPullRequest     sync.RWMutex
+	lockCheckCredential            sync.RWMutex
+	lockCreateCheckRun             sync.RWMutex
+	lockCreatePullRequest          sync.RWMutex
+	lockGetArchiveURL              sync.RWMutex
+	lockGetInstallationIDForOwner  sync.RWMutex
+	lockHTTPClient                 sync.RWMutex
+	lockInstallationToken          sync.RWMutex
+	lockListCodeScanningAlerts     sync.RWMutex
+	lockListInstallationRepos      sync.RWMutex
+	lockListPullRequestFiles       sync.RWMutex
+	lockListPullRequests           sync.RWMutex
+	lockListPullRequestsWithCommit sync.RWMutex
+	lockListRepositoryTeams        sync.RWMutex
+	lockRateLimit                  sync.RWMutex
+	lockRateLimits                 sync.RWMutex
+}
+
+// AddLabelsToPullRequest calls AddLabelsToPullRequestFunc.
+func (mock *GitHubAppMock) AddLabelsToPullRequest(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+	if mock.AddLabelsToPullRequestFunc == nil {
+		panic("GitHubAppMock.AddLabelsToPullRequestFunc: method is nil but GitHubApp.AddLabelsToPullRequest was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.AddLabelsToPullRequestInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockAddLabelsToPullRequest.Lock()
+	mock.calls.AddLabelsToPullRequest = append(mock.calls.AddLabelsToPullRequest, callInfo)
+	mock.lockAddLabelsToPullRequest.Unlock()
+	return mock.AddLabelsToPullRequestFunc(ctx, input)
+}
+
+// AddLabelsToPullRequestCalls gets all the calls that were made to AddLabelsToPullRequest.
+// Check the length with:
+//
+//	len(mockedGitHubApp.AddLabelsToPullRequestCalls())
+func (mock *GitHubAppMock) AddLabelsToPullRequestCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.AddLabelsToPullRequestInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.AddLabelsToPullRequestInput
+	}
+	mock.lockAddLabelsToPullRequest.RLock()
+	calls = mock.calls.AddLabelsToPullRequest
+	mock.lockAddLabelsToPullRequest.RUnlock()
+	return calls
+}
+
+// CheckCredential calls CheckCredentialFunc.
+func (mock *GitHubAppMock) CheckCredential(ctx context.Context) error {
+	if mock.CheckCredentialFunc == nil {
+		panic("GitHubAppMock.CheckCredentialFunc: method is nil but GitHubApp.CheckCredential was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
 	}
-	lockGetArchiveURL             sync.RWMutex
-	lockGetInstallationIDForOwner sync.RWMutex
-	lockHTTPClient                sync.RWMutex
-	lockListInstallationRepos     sync.RWMutex
+	mock.lockCheckCredential.Lock()
+	mock.calls.CheckCredential = append(mock.calls.CheckCredential, callInfo)
+	mock.lockCheckCredential.Unlock()
+	return mock.CheckCredentialFunc(ctx)
+}
+
+// CheckCredentialCalls gets all the calls that were made to CheckCredential.
+// Check the length with:
+//
+//	len(mockedGitHubApp.CheckCredentialCalls())
+func (mock *GitHubAppMock) CheckCredentialCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCheckCredential.RLock()
+	calls = mock.calls.CheckCredential
+	mock.lockCheckCredential.RUnlock()
+	return calls
+}
+
+// CreateCheckRun calls CreateCheckRunFunc.
+func (mock *GitHubAppMock) CreateCheckRun(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+	if mock.CreateCheckRunFunc == nil {
+		panic("GitHubAppMock.CreateCheckRunFunc: method is nil but GitHubApp.CreateCheckRun was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.CreateCheckRunInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockCreateCheckRun.Lock()
+	mock.calls.CreateCheckRun = append(mock.calls.CreateCheckRun, callInfo)
+	mock.lockCreateCheckRun.Unlock()
+	return mock.CreateCheckRunFunc(ctx, input)
+}
+
+// CreateCheckRunCalls gets all the calls that were made to CreateCheckRun.
+// Check the length with:
+//
+//	len(mockedGitHubApp.CreateCheckRunCalls())
+func (mock *GitHubAppMock) CreateCheckRunCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.CreateCheckRunInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.CreateCheckRunInput
+	}
+	mock.lockCreateCheckRun.RLock()
+	calls = mock.calls.CreateCheckRun
+	mock.lockCreateCheckRun.RUnlock()
+	return calls
+}
+
+// CreatePullRequest calls CreatePullRequestFunc.
+func (mock *GitHubAppMock) CreatePullRequest(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error) {
+	if mock.CreatePullRequestFunc == nil {
+		panic("GitHubAppMock.CreatePullRequestFunc: method is nil but GitHubApp.CreatePullRequest was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.CreatePullRequestInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockCreatePullRequest.Lock()
+	mock.calls.CreatePullRequest = append(mock.calls.CreatePullRequest, callInfo)
+	mock.lockCreatePullRequest.Unlock()
+	return mock.CreatePullRequestFunc(ctx, input)
+}
+
+// CreatePullRequestCalls gets all the calls that were made to CreatePullRequest.
+// Check the length with:
+//
+//	len(mockedGitHubApp.CreatePullRequestCalls())
+func (mock *GitHubAppMock) CreatePullRequestCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.CreatePullRequestInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.CreatePullRequestInput
+	}
+	mock.lockCreatePullRequest.RLock()
+	calls = mock.calls.CreatePullRequest
+	mock.lockCreatePullRequest.RUnlock()
+	return calls
 }
 
 // GetArchiveURL calls GetArchiveURLFunc.
@@ -428,6 +1242,78 @@ func (mock *GitHubAppMock) HTTPClientCalls() []struct {
 	return calls
 }
 
+// InstallationToken calls InstallationTokenFunc.
+func (mock *GitHubAppMock) InstallationToken(ctx context.Context, installID types.GitHubAppInstallID) (string, error) {
+	if mock.InstallationTokenFunc == nil {
+		panic("GitHubAppMock.InstallationTokenFunc: method is nil but GitHubApp.InstallationToken was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		InstallID types.GitHubAppInstallID
+	}{
+		Ctx:       ctx,
+		InstallID: installID,
+	}
+	mock.lockInstallationToken.Lock()
+	mock.calls.InstallationToken = append(mock.calls.InstallationToken, callInfo)
+	mock.lockInstallationToken.Unlock()
+	return mock.InstallationTokenFunc(ctx, installID)
+}
+
+// InstallationTokenCalls gets all the calls that were made to InstallationToken.
+// Check the length with:
+//
+//	len(mockedGitHubApp.InstallationTokenCalls())
+func (mock *GitHubAppMock) InstallationTokenCalls() []struct {
+	Ctx       context.Context
+	InstallID types.GitHubAppInstallID
+} {
+	var calls []struct {
+		Ctx       context.Context
+		InstallID types.GitHubAppInstallID
+	}
+	mock.lockInstallationToken.RLock()
+	calls = mock.calls.InstallationToken
+	mock.lockInstallationToken.RUnlock()
+	return calls
+}
+
+// ListCodeScanningAlerts calls ListCodeScanningAlertsFunc.
+func (mock *GitHubAppMock) ListCodeScanningAlerts(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error) {
+	if mock.ListCodeScanningAlertsFunc == nil {
+		panic("GitHubAppMock.ListCodeScanningAlertsFunc: method is nil but GitHubApp.ListCodeScanningAlerts was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.ListCodeScanningAlertsInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListCodeScanningAlerts.Lock()
+	mock.calls.ListCodeScanningAlerts = append(mock.calls.ListCodeScanningAlerts, callInfo)
+	mock.lockListCodeScanningAlerts.Unlock()
+	return mock.ListCodeScanningAlertsFunc(ctx, input)
+}
+
+// ListCodeScanningAlertsCalls gets all the calls that were made to ListCodeScanningAlerts.
+// Check the length with:
+//
+//	len(mockedGitHubApp.ListCodeScanningAlertsCalls())
+func (mock *GitHubAppMock) ListCodeScanningAlertsCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.ListCodeScanningAlertsInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.ListCodeScanningAlertsInput
+	}
+	mock.lockListCodeScanningAlerts.RLock()
+	calls = mock.calls.ListCodeScanningAlerts
+	mock.lockListCodeScanningAlerts.RUnlock()
+	return calls
+}
+
 // ListInstallationRepos calls ListInstallationReposFunc.
 func (mock *GitHubAppMock) ListInstallationRepos(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
 	if mock.ListInstallationReposFunc == nil {
@@ -463,3 +1349,362 @@ func (mock *GitHubAppMock) ListInstallationReposCalls() []struct {
 	mock.lockListInstallationRepos.RUnlock()
 	return calls
 }
+
+// ListPullRequestFiles calls ListPullRequestFilesFunc.
+func (mock *GitHubAppMock) ListPullRequestFiles(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error) {
+	if mock.ListPullRequestFilesFunc == nil {
+		panic("GitHubAppMock.ListPullRequestFilesFunc: method is nil but GitHubApp.ListPullRequestFiles was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestFilesInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListPullRequestFiles.Lock()
+	mock.calls.ListPullRequestFiles = append(mock.calls.ListPullRequestFiles, callInfo)
+	mock.lockListPullRequestFiles.Unlock()
+	return mock.ListPullRequestFilesFunc(ctx, input)
+}
+
+// ListPullRequestFilesCalls gets all the calls that were made to ListPullRequestFiles.
+// Check the length with:
+//
+//	len(mockedGitHubApp.ListPullRequestFilesCalls())
+func (mock *GitHubAppMock) ListPullRequestFilesCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.ListPullRequestFilesInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestFilesInput
+	}
+	mock.lockListPullRequestFiles.RLock()
+	calls = mock.calls.ListPullRequestFiles
+	mock.lockListPullRequestFiles.RUnlock()
+	return calls
+}
+
+// ListPullRequests calls ListPullRequestsFunc.
+func (mock *GitHubAppMock) ListPullRequests(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error) {
+	if mock.ListPullRequestsFunc == nil {
+		panic("GitHubAppMock.ListPullRequestsFunc: method is nil but GitHubApp.ListPullRequests was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestsInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListPullRequests.Lock()
+	mock.calls.ListPullRequests = append(mock.calls.ListPullRequests, callInfo)
+	mock.lockListPullRequests.Unlock()
+	return mock.ListPullRequestsFunc(ctx, input)
+}
+
+// ListPullRequestsCalls gets all the calls that were made to ListPullRequests.
+// Check the length with:
+//
+//	len(mockedGitHubApp.ListPullRequestsCalls())
+func (mock *GitHubAppMock) ListPullRequestsCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.ListPullRequestsInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestsInput
+	}
+	mock.lockListPullRequests.RLock()
+	calls = mock.calls.ListPullRequests
+	mock.lockListPullRequests.RUnlock()
+	return calls
+}
+
+// ListPullRequestsWithCommit calls ListPullRequestsWithCommitFunc.
+func (mock *GitHubAppMock) ListPullRequestsWithCommit(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error) {
+	if mock.ListPullRequestsWithCommitFunc == nil {
+		panic("GitHubAppMock.ListPullRequestsWithCommitFunc: method is nil but GitHubApp.ListPullRequestsWithCommit was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestsWithCommitInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListPullRequestsWithCommit.Lock()
+	mock.calls.ListPullRequestsWithCommit = append(mock.calls.ListPullRequestsWithCommit, callInfo)
+	mock.lockListPullRequestsWithCommit.Unlock()
+	return mock.ListPullRequestsWithCommitFunc(ctx, input)
+}
+
+// ListPullRequestsWithCommitCalls gets all the calls that were made to ListPullRequestsWithCommit.
+// Check the length with:
+//
+//	len(mockedGitHubApp.ListPullRequestsWithCommitCalls())
+func (mock *GitHubAppMock) ListPullRequestsWithCommitCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.ListPullRequestsWithCommitInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.ListPullRequestsWithCommitInput
+	}
+	mock.lockListPullRequestsWithCommit.RLock()
+	calls = mock.calls.ListPullRequestsWithCommit
+	mock.lockListPullRequestsWithCommit.RUnlock()
+	return calls
+}
+
+// ListRepositoryTeams calls ListRepositoryTeamsFunc.
+func (mock *GitHubAppMock) ListRepositoryTeams(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error) {
+	if mock.ListRepositoryTeamsFunc == nil {
+		panic("GitHubAppMock.ListRepositoryTeamsFunc: method is nil but GitHubApp.ListRepositoryTeams was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *interfaces.ListRepositoryTeamsInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListRepositoryTeams.Lock()
+	mock.calls.ListRepositoryTeams = append(mock.calls.ListRepositoryTeams, callInfo)
+	mock.lockListRepositoryTeams.Unlock()
+	return mock.ListRepositoryTeamsFunc(ctx, input)
+}
+
+// ListRepositoryTeamsCalls gets all the calls that were made to ListRepositoryTeams.
+// Check the length with:
+//
+//	len(mockedGitHubApp.ListRepositoryTeamsCalls())
+func (mock *GitHubAppMock) ListRepositoryTeamsCalls() []struct {
+	Ctx   context.Context
+	Input *interfaces.ListRepositoryTeamsInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *interfaces.ListRepositoryTeamsInput
+	}
+	mock.lockListRepositoryTeams.RLock()
+	calls = mock.calls.ListRepositoryTeams
+	mock.lockListRepositoryTeams.RUnlock()
+	return calls
+}
+
+// RateLimit calls RateLimitFunc.
+func (mock *GitHubAppMock) RateLimit(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+	if mock.RateLimitFunc == nil {
+		panic("GitHubAppMock.RateLimitFunc: method is nil but GitHubApp.RateLimit was just called")
+	}
+	callInfo := struct {
+		InstallID types.GitHubAppInstallID
+	}{
+		InstallID: installID,
+	}
+	mock.lockRateLimit.Lock()
+	mock.calls.RateLimit = append(mock.calls.RateLimit, callInfo)
+	mock.lockRateLimit.Unlock()
+	return mock.RateLimitFunc(installID)
+}
+
+// RateLimitCalls gets all the calls that were made to RateLimit.
+// Check the length with:
+//
+//	len(mockedGitHubApp.RateLimitCalls())
+func (mock *GitHubAppMock) RateLimitCalls() []struct {
+	InstallID types.GitHubAppInstallID
+} {
+	var calls []struct {
+		InstallID types.GitHubAppInstallID
+	}
+	mock.lockRateLimit.RLock()
+	calls = mock.calls.RateLimit
+	mock.lockRateLimit.RUnlock()
+	return calls
+}
+
+// RateLimits calls RateLimitsFunc.
+func (mock *GitHubAppMock) RateLimits() []*model.GitHubRateLimit {
+	if mock.RateLimitsFunc == nil {
+		panic("GitHubAppMock.RateLimitsFunc: method is nil but GitHubApp.RateLimits was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockRateLimits.Lock()
+	mock.calls.RateLimits = append(mock.calls.RateLimits, callInfo)
+	mock.lockRateLimits.Unlock()
+	return mock.RateLimitsFunc()
+}
+
+// RateLimitsCalls gets all the calls that were made to RateLimits.
+// Check the length with:
+//
+//	len(mockedGitHubApp.RateLimitsCalls())
+func (mock *GitHubAppMock) RateLimitsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockRateLimits.RLock()
+	calls = mock.calls.RateLimits
+	mock.lockRateLimits.RUnlock()
+	return calls
+}
+
+// Ensure, that ObjectStorageMock does implement interfaces.ObjectStorage.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.ObjectStorage = &ObjectStorageMock{}
+
+// ObjectStorageMock is a mock implementation of interfaces.ObjectStorage.
+//
+//	func TestSomethingThatUsesObjectStorage(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.ObjectStorage
+//		mockedObjectStorage := &ObjectStorageMock{
+//			PutFunc: func(ctx context.Context, key string, data []byte) (string, error) {
+//				panic("mock out the Put method")
+//			},
+//		}
+//
+//		// use mockedObjectStorage in code that requires interfaces.ObjectStorage
+//		// and then make assertions.
+//
+//	}
+type ObjectStorageMock struct {
+	// PutFunc mocks the Put method.
+	PutFunc func(ctx context.Context, key string, data []byte) (string, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Data is the data argument value.
+			Data []byte
+		}
+	}
+	lockPut sync.RWMutex
+}
+
+// Put calls PutFunc.
+func (mock *ObjectStorageMock) Put(ctx context.Context, key string, data []byte) (string, error) {
+	if mock.PutFunc == nil {
+		panic("ObjectStorageMock.PutFunc: method is nil but ObjectStorage.Put was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Key  string
+		Data []byte
+	}{
+		Ctx:  ctx,
+		Key:  key,
+		Data: data,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	return mock.PutFunc(ctx, key, data)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedObjectStorage.PutCalls())
+func (mock *ObjectStorageMock) PutCalls() []struct {
+	Ctx  context.Context
+	Key  string
+	Data []byte
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Key  string
+		Data []byte
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
+
+// Ensure, that ObjectReaderMock does implement interfaces.ObjectReader.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.ObjectReader = &ObjectReaderMock{}
+
+// ObjectReaderMock is a mock implementation of interfaces.ObjectReader.
+//
+//	func TestSomethingThatUsesObjectReader(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.ObjectReader
+//		mockedObjectReader := &ObjectReaderMock{
+//			OpenFunc: func(ctx context.Context, bucket string, object string) (io.ReadCloser, error) {
+//				panic("mock out the Open method")
+//			},
+//		}
+//
+//		// use mockedObjectReader in code that requires interfaces.ObjectReader
+//		// and then make assertions.
+//
+//	}
+type ObjectReaderMock struct {
+	// OpenFunc mocks the Open method.
+	OpenFunc func(ctx context.Context, bucket string, object string) (io.ReadCloser, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Open holds details about calls to the Open method.
+		Open []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bucket is the bucket argument value.
+			Bucket string
+			// Object is the object argument value.
+			Object string
+		}
+	}
+	lockOpen sync.RWMutex
+}
+
+// Open calls OpenFunc.
+func (mock *ObjectReaderMock) Open(ctx context.Context, bucket string, object string) (io.ReadCloser, error) {
+	if mock.OpenFunc == nil {
+		panic("ObjectReaderMock.OpenFunc: method is nil but ObjectReader.Open was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bucket string
+		Object string
+	}{
+		Ctx:    ctx,
+		Bucket: bucket,
+		Object: object,
+	}
+	mock.lockOpen.Lock()
+	mock.calls.Open = append(mock.calls.Open, callInfo)
+	mock.lockOpen.Unlock()
+	return mock.OpenFunc(ctx, bucket, object)
+}
+
+// OpenCalls gets all the calls that were made to Open.
+// Check the length with:
+//
+//	len(mockedObjectReader.OpenCalls())
+func (mock *ObjectReaderMock) OpenCalls() []struct {
+	Ctx    context.Context
+	Bucket string
+	Object string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bucket string
+		Object string
+	}
+	mock.lockOpen.RLock()
+	calls = mock.calls.Open
+	mock.lockOpen.RUnlock()
+	return calls
+}
@@ -22,12 +22,96 @@ var _ interfaces.UseCase = &UseCaseMock{}
 //
 //		// make and configure a mocked interfaces.UseCase
 //		mockedUseCase := &UseCaseMock{
+//			CheckReadinessFunc: func(ctx context.Context) *model.Readiness {
+//				panic("mock out the CheckReadiness method")
+//			},
+//			CheckSLAFunc: func(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error) {
+//				panic("mock out the CheckSLA method")
+//			},
+//			CompleteAgentScanJobFunc: func(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error {
+//				panic("mock out the CompleteAgentScanJob method")
+//			},
+//			CreateScanRunFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+//				panic("mock out the CreateScanRun method")
+//			},
+//			DeleteSeverityOverrideFunc: func(ctx context.Context, input *model.DeleteSeverityOverrideInput) error {
+//				panic("mock out the DeleteSeverityOverride method")
+//			},
+//			EvaluateGateFunc: func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+//				panic("mock out the EvaluateGate method")
+//			},
+//			GetBackstagePostureFunc: func(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error) {
+//				panic("mock out the GetBackstagePosture method")
+//			},
+//			GetDeveloperSummaryFunc: func(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error) {
+//				panic("mock out the GetDeveloperSummary method")
+//			},
+//			GetGitHubRateLimitsFunc: func(ctx context.Context) []*model.GitHubRateLimit {
+//				panic("mock out the GetGitHubRateLimits method")
+//			},
+//			GetOwnerScanRunFunc: func(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+//				panic("mock out the GetOwnerScanRun method")
+//			},
+//			GetPermalinkViewFunc: func(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error) {
+//				panic("mock out the GetPermalinkView method")
+//			},
+//			GetRepositoryFunc: func(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error) {
+//				panic("mock out the GetRepository method")
+//			},
+//			GetScanRunFunc: func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+//				panic("mock out the GetScanRun method")
+//			},
+//			GetVulnerabilityDetailFunc: func(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error) {
+//				panic("mock out the GetVulnerabilityDetail method")
+//			},
+//			GetVulnerabilityRollupFunc: func(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error) {
+//				panic("mock out the GetVulnerabilityRollup method")
+//			},
 //			InsertScanResultFunc: func(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error) {
 //				panic("mock out the InsertScanResult method")
 //			},
+//			ListBranchStatusFunc: func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+//				panic("mock out the ListBranchStatus method")
+//			},
+//			ListBranchesFunc: func(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error) {
+//				panic("mock out the ListBranches method")
+//			},
+//			ListRepositoriesFunc: func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+//				panic("mock out the ListRepositories method")
+//			},
+//			ListSeverityOverridesFunc: func(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+//				panic("mock out the ListSeverityOverrides method")
+//			},
+//			ListTargetsFunc: func(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error) {
+//				panic("mock out the ListTargets method")
+//			},
+//			ListVulnerabilitiesFunc: func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+//				panic("mock out the ListVulnerabilities method")
+//			},
+//			PrepareAgentScanJobFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error) {
+//				panic("mock out the PrepareAgentScanJob method")
+//			},
+//			PrepareScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+//				panic("mock out the PrepareScanGitHubRepo method")
+//			},
+//			PutSeverityOverrideFunc: func(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error) {
+//				panic("mock out the PutSeverityOverride method")
+//			},
+//			ScanAgentJobFunc: func(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error) {
+//				panic("mock out the ScanAgentJob method")
+//			},
 //			ScanGitHubRepoFunc: func(ctx context.Context, input *model.ScanGitHubRepoInput) error {
 //				panic("mock out the ScanGitHubRepo method")
 //			},
+//			ScanGitHubReposByOwnerFromAPIFunc: func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+//				panic("mock out the ScanGitHubReposByOwnerFromAPI method")
+//			},
+//			SeedInstallationReposFunc: func(ctx context.Context, input *model.SeedInstallationReposInput) (int, error) {
+//				panic("mock out the SeedInstallationRepos method")
+//			},
+//			UpdateRepositoryMetadataFunc: func(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error) {
+//				panic("mock out the UpdateRepositoryMetadata method")
+//			},
 //		}
 //
 //		// use mockedUseCase in code that requires interfaces.UseCase
@@ -35,14 +119,201 @@ var _ interfaces.UseCase = &UseCaseMock{}
 //
 //	}
 type UseCaseMock struct {
+	// CheckReadinessFunc mocks the CheckReadiness method.
+	CheckReadinessFunc func(ctx context.Context) *model.Readiness
+
+	// CheckSLAFunc mocks the CheckSLA method.
+	CheckSLAFunc func(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error)
+
+	// CompleteAgentScanJobFunc mocks the CompleteAgentScanJob method.
+	CompleteAgentScanJobFunc func(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error
+
+	// CreateScanRunFunc mocks the CreateScanRun method.
+	CreateScanRunFunc func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error)
+
+	// DeleteSeverityOverrideFunc mocks the DeleteSeverityOverride method.
+	DeleteSeverityOverrideFunc func(ctx context.Context, input *model.DeleteSeverityOverrideInput) error
+
+	// EvaluateGateFunc mocks the EvaluateGate method.
+	EvaluateGateFunc func(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error)
+
+	// GetBackstagePostureFunc mocks the GetBackstagePosture method.
+	GetBackstagePostureFunc func(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error)
+
+	// GetDeveloperSummaryFunc mocks the GetDeveloperSummary method.
+	GetDeveloperSummaryFunc func(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error)
+
+	// GetGitHubRateLimitsFunc mocks the GetGitHubRateLimits method.
+	GetGitHubRateLimitsFunc func(ctx context.Context) []*model.GitHubRateLimit
+
+	// GetOwnerScanRunFunc mocks the GetOwnerScanRun method.
+	GetOwnerScanRunFunc func(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error)
+
+	// GetPermalinkViewFunc mocks the GetPermalinkView method.
+	GetPermalinkViewFunc func(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error)
+
+	// GetRepositoryFunc mocks the GetRepository method.
+	GetRepositoryFunc func(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error)
+
+	// GetScanRunFunc mocks the GetScanRun method.
+	GetScanRunFunc func(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error)
+
+	// GetVulnerabilityDetailFunc mocks the GetVulnerabilityDetail method.
+	GetVulnerabilityDetailFunc func(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error)
+
+	// GetVulnerabilityRollupFunc mocks the GetVulnerabilityRollup method.
+	GetVulnerabilityRollupFunc func(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error)
+
 	// InsertScanResultFunc mocks the InsertScanResult method.
 	InsertScanResultFunc func(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error)
 
+	// ListBranchStatusFunc mocks the ListBranchStatus method.
+	ListBranchStatusFunc func(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error)
+
+	// ListBranchesFunc mocks the ListBranches method.
+	ListBranchesFunc func(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error)
+
+	// ListRepositoriesFunc mocks the ListRepositories method.
+	ListRepositoriesFunc func(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error)
+
+	// ListSeverityOverridesFunc mocks the ListSeverityOverrides method.
+	ListSeverityOverridesFunc func(ctx context.Context, owner string) ([]*model.SeverityOverride, error)
+
+	// ListTargetsFunc mocks the ListTargets method.
+	ListTargetsFunc func(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error)
+
+	// ListVulnerabilitiesFunc mocks the ListVulnerabilities method.
+	ListVulnerabilitiesFunc func(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error)
+
+	// PrepareAgentScanJobFunc mocks the PrepareAgentScanJob method.
+	PrepareAgentScanJobFunc func(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error)
+
+	// PrepareScanGitHubRepoFunc mocks the PrepareScanGitHubRepo method.
+	PrepareScanGitHubRepoFunc func(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error)
+
+	// PutSeverityOverrideFunc mocks the PutSeverityOverride method.
+	PutSeverityOverrideFunc func(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error)
+
+	// ScanAgentJobFunc mocks the ScanAgentJob method.
+	ScanAgentJobFunc func(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error)
+
 	// ScanGitHubRepoFunc mocks the ScanGitHubRepo method.
 	ScanGitHubRepoFunc func(ctx context.Context, input *model.ScanGitHubRepoInput) error
 
+	// ScanGitHubReposByOwnerFromAPIFunc mocks the ScanGitHubReposByOwnerFromAPI method.
+	ScanGitHubReposByOwnerFromAPIFunc func(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error)
+
+	// SeedInstallationReposFunc mocks the SeedInstallationRepos method.
+	SeedInstallationReposFunc func(ctx context.Context, input *model.SeedInstallationReposInput) (int, error)
+
+	// UpdateRepositoryMetadataFunc mocks the UpdateRepositoryMetadata method.
+	UpdateRepositoryMetadataFunc func(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// CheckReadiness holds details about calls to the CheckReadiness method.
+		CheckReadiness []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// CheckSLA holds details about calls to the CheckSLA method.
+		CheckSLA []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.CheckSLAInput
+		}
+		// CompleteAgentScanJob holds details about calls to the CompleteAgentScanJob method.
+		CompleteAgentScanJob []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Job is the job argument value.
+			Job *model.AgentScanJob
+			// Result is the result argument value.
+			Result *model.AgentScanResult
+		}
+		// CreateScanRun holds details about calls to the CreateScanRun method.
+		CreateScanRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ScanGitHubRepoInput
+		}
+		// DeleteSeverityOverride holds details about calls to the DeleteSeverityOverride method.
+		DeleteSeverityOverride []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.DeleteSeverityOverrideInput
+		}
+		// EvaluateGate holds details about calls to the EvaluateGate method.
+		EvaluateGate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.EvaluateGateInput
+		}
+		// GetBackstagePosture holds details about calls to the GetBackstagePosture method.
+		GetBackstagePosture []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.BackstagePostureInput
+		}
+		// GetDeveloperSummary holds details about calls to the GetDeveloperSummary method.
+		GetDeveloperSummary []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.DeveloperSummaryInput
+		}
+		// GetGitHubRateLimits holds details about calls to the GetGitHubRateLimits method.
+		GetGitHubRateLimits []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetOwnerScanRun holds details about calls to the GetOwnerScanRun method.
+		GetOwnerScanRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RunID is the runID argument value.
+			RunID types.OwnerScanRunID
+		}
+		// GetPermalinkView holds details about calls to the GetPermalinkView method.
+		GetPermalinkView []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.GetPermalinkViewInput
+		}
+		// GetRepository holds details about calls to the GetRepository method.
+		GetRepository []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.GetRepositoryInput
+		}
+		// GetScanRun holds details about calls to the GetScanRun method.
+		GetScanRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RunID is the runID argument value.
+			RunID types.ScanRunID
+		}
+		// GetVulnerabilityDetail holds details about calls to the GetVulnerabilityDetail method.
+		GetVulnerabilityDetail []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.GetVulnerabilityDetailInput
+		}
+		// GetVulnerabilityRollup holds details about calls to the GetVulnerabilityRollup method.
+		GetVulnerabilityRollup []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.GetVulnerabilityRollupInput
+		}
 		// InsertScanResult holds details about calls to the InsertScanResult method.
 		InsertScanResult []struct {
 			// Ctx is the ctx argument value.
@@ -52,6 +323,76 @@ type UseCaseMock struct {
 			// Report is the report argument value.
 			Report trivy.Report
 		}
+		// ListBranchStatus holds details about calls to the ListBranchStatus method.
+		ListBranchStatus []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ListBranchStatusInput
+		}
+		// ListBranches holds details about calls to the ListBranches method.
+		ListBranches []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ListBranchesInput
+		}
+		// ListRepositories holds details about calls to the ListRepositories method.
+		ListRepositories []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ListRepositoriesInput
+		}
+		// ListSeverityOverrides holds details about calls to the ListSeverityOverrides method.
+		ListSeverityOverrides []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Owner is the owner argument value.
+			Owner string
+		}
+		// ListTargets holds details about calls to the ListTargets method.
+		ListTargets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ListTargetsInput
+		}
+		// ListVulnerabilities holds details about calls to the ListVulnerabilities method.
+		ListVulnerabilities []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ListVulnerabilitiesInput
+		}
+		// PrepareAgentScanJob holds details about calls to the PrepareAgentScanJob method.
+		PrepareAgentScanJob []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ScanGitHubRepoInput
+		}
+		// PrepareScanGitHubRepo holds details about calls to the PrepareScanGitHubRepo method.
+		PrepareScanGitHubRepo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ScanGitHubRepoRemoteInput
+		}
+		// PutSeverityOverride holds details about calls to the PutSeverityOverride method.
+		PutSeverityOverride []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Override is the override argument value.
+			Override *model.SeverityOverride
+		}
+		// ScanAgentJob holds details about calls to the ScanAgentJob method.
+		ScanAgentJob []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Job is the job argument value.
+			Job *model.AgentScanJob
+		}
 		// ScanGitHubRepo holds details about calls to the ScanGitHubRepo method.
 		ScanGitHubRepo []struct {
 			// Ctx is the ctx argument value.
@@ -59,55 +400,172 @@ type UseCaseMock struct {
 			// Input is the input argument value.
 			Input *model.ScanGitHubRepoInput
 		}
+		// ScanGitHubReposByOwnerFromAPI holds details about calls to the ScanGitHubReposByOwnerFromAPI method.
+		ScanGitHubReposByOwnerFromAPI []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.ScanGitHubReposByOwnerFromAPIInput
+		}
+		// SeedInstallationRepos holds details about calls to the SeedInstallationRepos method.
+		SeedInstallationRepos []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.SeedInstallationReposInput
+		}
+		// UpdateRepositoryMetadata holds details about calls to the UpdateRepositoryMetadata method.
+		UpdateRepositoryMetadata []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *model.UpdateRepositoryMetadataInput
+		}
 	}
-	lockInsertScanResult sync.RWMutex
-	lockScanGitHubRepo   sync.RWMutex
+	lockCheckReadiness                sync.RWMutex
+	lockCheckSLA                      sync.RWMutex
+	lockCompleteAgentScanJob          sync.RWMutex
+	lockCreateScanRun                 sync.RWMutex
+	lockDeleteSeverityOverride        sync.RWMutex
+	lockEvaluateGate                  sync.RWMutex
+	lockGetBackstagePosture           sync.RWMutex
+	lockGetDeveloperSummary           sync.RWMutex
+	lockGetGitHubRateLimits           sync.RWMutex
+	lockGetOwnerScanRun               sync.RWMutex
+	lockGetPermalinkView              sync.RWMutex
+	lockGetRepository                 sync.RWMutex
+	lockGetScanRun                    sync.RWMutex
+	lockGetVulnerabilityDetail        sync.RWMutex
+	lockGetVulnerabilityRollup        sync.RWMutex
+	lockInsertScanResult              sync.RWMutex
+	lockListBranchStatus              sync.RWMutex
+	lockListBranches                  sync.RWMutex
+	lockListRepositories              sync.RWMutex
+	lockListSeverityOverrides         sync.RWMutex
+	lockListTargets                   sync.RWMutex
+	lockListVulnerabilities           sync.RWMutex
+	lockPrepareAgentScanJob           sync.RWMutex
+	lockPrepareScanGitHubRepo         sync.RWMutex
+	lockPutSeverityOverride           sync.RWMutex
+	lockScanAgentJob                  sync.RWMutex
+	lockScanGitHubRepo                sync.RWMutex
+	lockScanGitHubReposByOwnerFromAPI sync.RWMutex
+	lockSeedInstallationRepos         sync.RWMutex
+	lockUpdateRepositoryMetadata      sync.RWMutex
 }
 
-// InsertScanResult calls InsertScanResultFunc.
-func (mock *UseCaseMock) InsertScanResult(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error) {
-	if mock.InsertScanResultFunc == nil {
-		panic("UseCaseMock.InsertScanResultFunc: method is nil but UseCase.InsertScanResult was just called")
+// CheckReadiness calls CheckReadinessFunc.
+func (mock *UseCaseMock) CheckReadiness(ctx context.Context) *model.Readiness {
+	if mock.CheckReadinessFunc == nil {
+		panic("UseCaseMock.CheckReadinessFunc: method is nil but UseCase.CheckReadiness was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCheckReadiness.Lock()
+	mock.calls.CheckReadiness = append(mock.calls.CheckReadiness, callInfo)
+	mock.lockCheckReadiness.Unlock()
+	return mock.CheckReadinessFunc(ctx)
+}
+
+// CheckReadinessCalls gets all the calls that were made to CheckReadiness.
+// Check the length with:
+//
+//	len(mockedUseCase.CheckReadinessCalls())
+func (mock *UseCaseMock) CheckReadinessCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCheckReadiness.RLock()
+	calls = mock.calls.CheckReadiness
+	mock.lockCheckReadiness.RUnlock()
+	return calls
+}
+
+// CheckSLA calls CheckSLAFunc.
+func (mock *UseCaseMock) CheckSLA(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error) {
+	if mock.CheckSLAFunc == nil {
+		panic("UseCaseMock.CheckSLAFunc: method is nil but UseCase.CheckSLA was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.CheckSLAInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockCheckSLA.Lock()
+	mock.calls.CheckSLA = append(mock.calls.CheckSLA, callInfo)
+	mock.lockCheckSLA.Unlock()
+	return mock.CheckSLAFunc(ctx, input)
+}
+
+// CheckSLACalls gets all the calls that were made to CheckSLA.
+// Check the length with:
+//
+//	len(mockedUseCase.CheckSLACalls())
+func (mock *UseCaseMock) CheckSLACalls() []struct {
+	Ctx   context.Context
+	Input *model.CheckSLAInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.CheckSLAInput
+	}
+	mock.lockCheckSLA.RLock()
+	calls = mock.calls.CheckSLA
+	mock.lockCheckSLA.RUnlock()
+	return calls
+}
+
+// CompleteAgentScanJob calls CompleteAgentScanJobFunc.
+func (mock *UseCaseMock) CompleteAgentScanJob(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error {
+	if mock.CompleteAgentScanJobFunc == nil {
+		panic("UseCaseMock.CompleteAgentScanJobFunc: method is nil but UseCase.CompleteAgentScanJob was just called")
 	}
 	callInfo := struct {
 		Ctx    context.Context
-		Meta   model.GitHubMetadata
-		Report trivy.Report
+		Job    *model.AgentScanJob
+		Result *model.AgentScanResult
 	}{
 		Ctx:    ctx,
-		Meta:   meta,
-		Report: report,
+		Job:    job,
+		Result: result,
 	}
-	mock.lockInsertScanResult.Lock()
-	mock.calls.InsertScanResult = append(mock.calls.InsertScanResult, callInfo)
-	mock.lockInsertScanResult.Unlock()
-	return mock.InsertScanResultFunc(ctx, meta, report)
+	mock.lockCompleteAgentScanJob.Lock()
+	mock.calls.CompleteAgentScanJob = append(mock.calls.CompleteAgentScanJob, callInfo)
+	mock.lockCompleteAgentScanJob.Unlock()
+	return mock.CompleteAgentScanJobFunc(ctx, job, result)
 }
 
-// InsertScanResultCalls gets all the calls that were made to InsertScanResult.
+// CompleteAgentScanJobCalls gets all the calls that were made to CompleteAgentScanJob.
 // Check the length with:
 //
-//	len(mockedUseCase.InsertScanResultCalls())
-func (mock *UseCaseMock) InsertScanResultCalls() []struct {
+//	len(mockedUseCase.CompleteAgentScanJobCalls())
+func (mock *UseCaseMock) CompleteAgentScanJobCalls() []struct {
 	Ctx    context.Context
-	Meta   model.GitHubMetadata
-	Report trivy.Report
+	Job    *model.AgentScanJob
+	Result *model.AgentScanResult
 } {
 	var calls []struct {
 		Ctx    context.Context
-		Meta   model.GitHubMetadata
-		Report trivy.Report
+		Job    *model.AgentScanJob
+		Result *model.AgentScanResult
 	}
-	mock.lockInsertScanResult.RLock()
-	calls = mock.calls.InsertScanResult
-	mock.lockInsertScanResult.RUnlock()
+	mock.lockCompleteAgentScanJob.RLock()
+	calls = mock.calls.CompleteAgentScanJob
+	mock.lockCompleteAgentScanJob.RUnlock()
 	return calls
 }
 
-// ScanGitHubRepo calls ScanGitHubRepoFunc.
-func (mock *UseCaseMock) ScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) error {
-	if mock.ScanGitHubRepoFunc == nil {
-		panic("UseCaseMock.ScanGitHubRepoFunc: method is nil but UseCase.ScanGitHubRepo was just called")
+// CreateScanRun calls CreateScanRunFunc.
+func (mock *UseCaseMock) CreateScanRun(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+	if mock.CreateScanRunFunc == nil {
+		panic("UseCaseMock.CreateScanRunFunc: method is nil but UseCase.CreateScanRun was just called")
 	}
 	callInfo := struct {
 		Ctx   context.Context
@@ -116,17 +574,17 @@ func (mock *UseCaseMock) ScanGitHubRepo(ctx context.Context, input *model.ScanGi
 		Ctx:   ctx,
 		Input: input,
 	}
-	mock.lockScanGitHubRepo.Lock()
-	mock.calls.ScanGitHubRepo = append(mock.calls.ScanGitHubRepo, callInfo)
-	mock.lockScanGitHubRepo.Unlock()
-	return mock.ScanGitHubRepoFunc(ctx, input)
+	mock.lockCreateScanRun.Lock()
+	mock.calls.CreateScanRun = append(mock.calls.CreateScanRun, callInfo)
+	mock.lockCreateScanRun.Unlock()
+	return mock.CreateScanRunFunc(ctx, input)
 }
 
-// ScanGitHubRepoCalls gets all the calls that were made to ScanGitHubRepo.
+// CreateScanRunCalls gets all the calls that were made to CreateScanRun.
 // Check the length with:
 //
-//	len(mockedUseCase.ScanGitHubRepoCalls())
-func (mock *UseCaseMock) ScanGitHubRepoCalls() []struct {
+//	len(mockedUseCase.CreateScanRunCalls())
+func (mock *UseCaseMock) CreateScanRunCalls() []struct {
 	Ctx   context.Context
 	Input *model.ScanGitHubRepoInput
 } {
@@ -134,8 +592,944 @@ func (mock *UseCaseMock) ScanGitHubRepoCalls() []struct {
 		Ctx   context.Context
 		Input *model.ScanGitHubRepoInput
 	}
-	mock.lockScanGitHubRepo.RLock()
-	calls = mock.calls.ScanGitHubRepo
-	mock.lockScanGitHubRepo.RUnlock()
+	mock.lockCreateScanRun.RLock()
+	calls = mock.calls.CreateScanRun
+	mock.lockCreateScanRun.RUnlock()
+	return calls
+}
+
+// DeleteSeverityOverride calls DeleteSeverityOverrideFunc.
+func (mock *UseCaseMock) DeleteSeverityOverride(ctx context.Context, input *model.DeleteSeverityOverrideInput) error {
+	if mock.DeleteSeverityOverrideFunc == nil {
+		panic("UseCaseMock.DeleteSeverityOverrideFunc: method is nil but UseCase.DeleteSeverityOverride was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.DeleteSeverityOverrideInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockDeleteSeverityOverride.Lock()
+	mock.calls.DeleteSeverityOverride = append(mock.calls.DeleteSeverityOverride, callInfo)
+	mock.lockDeleteSeverityOverride.Unlock()
+	return mock.DeleteSeverityOverrideFunc(ctx, input)
+}
+
+// DeleteSeverityOverrideCalls gets all the calls that were made to DeleteSeverityOverride.
+// Check the length with:
+//
+//	len(mockedUseCase.DeleteSeverityOverrideCalls())
+func (mock *UseCaseMock) DeleteSeverityOverrideCalls() []struct {
+	Ctx   context.Context
+	Input *model.DeleteSeverityOverrideInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.DeleteSeverityOverrideInput
+	}
+	mock.lockDeleteSeverityOverride.RLock()
+	calls = mock.calls.DeleteSeverityOverride
+	mock.lockDeleteSeverityOverride.RUnlock()
+	return calls
+}
+
+// EvaluateGate calls EvaluateGateFunc.
+func (mock *UseCaseMock) EvaluateGate(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+	if mock.EvaluateGateFunc == nil {
+		panic("UseCaseMock.EvaluateGateFunc: method is nil but UseCase.EvaluateGate was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.EvaluateGateInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockEvaluateGate.Lock()
+	mock.calls.EvaluateGate = append(mock.calls.EvaluateGate, callInfo)
+	mock.lockEvaluateGate.Unlock()
+	return mock.EvaluateGateFunc(ctx, input)
+}
+
+// EvaluateGateCalls gets all the calls that were made to EvaluateGate.
+// Check the length with:
+//
+//	len(mockedUseCase.EvaluateGateCalls())
+func (mock *UseCaseMock) EvaluateGateCalls() []struct {
+	Ctx   context.Context
+	Input *model.EvaluateGateInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.EvaluateGateInput
+	}
+	mock.lockEvaluateGate.RLock()
+	calls = mock.calls.EvaluateGate
+	mock.lockEvaluateGate.RUnlock()
+	return calls
+}
+
+// GetBackstagePosture calls GetBackstagePostureFunc.
+func (mock *UseCaseMock) GetBackstagePosture(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error) {
+	if mock.GetBackstagePostureFunc == nil {
+		panic("UseCaseMock.GetBackstagePostureFunc: method is nil but UseCase.GetBackstagePosture was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.BackstagePostureInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetBackstagePosture.Lock()
+	mock.calls.GetBackstagePosture = append(mock.calls.GetBackstagePosture, callInfo)
+	mock.lockGetBackstagePosture.Unlock()
+	return mock.GetBackstagePostureFunc(ctx, input)
+}
+
+// GetBackstagePostureCalls gets all the calls that were made to GetBackstagePosture.
+// Check the length with:
+//
+//	len(mockedUseCase.GetBackstagePostureCalls())
+func (mock *UseCaseMock) GetBackstagePostureCalls() []struct {
+	Ctx   context.Context
+	Input *model.BackstagePostureInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.BackstagePostureInput
+	}
+	mock.lockGetBackstagePosture.RLock()
+	calls = mock.calls.GetBackstagePosture
+	mock.lockGetBackstagePosture.RUnlock()
+	return calls
+}
+
+// GetDeveloperSummary calls GetDeveloperSummaryFunc.
+func (mock *UseCaseMock) GetDeveloperSummary(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error) {
+	if mock.GetDeveloperSummaryFunc == nil {
+		panic("UseCaseMock.GetDeveloperSummaryFunc: method is nil but UseCase.GetDeveloperSummary was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.DeveloperSummaryInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetDeveloperSummary.Lock()
+	mock.calls.GetDeveloperSummary = append(mock.calls.GetDeveloperSummary, callInfo)
+	mock.lockGetDeveloperSummary.Unlock()
+	return mock.GetDeveloperSummaryFunc(ctx, input)
+}
+
+// GetDeveloperSummaryCalls gets all the calls that were made to GetDeveloperSummary.
+// Check the length with:
+//
+//	len(mockedUseCase.GetDeveloperSummaryCalls())
+func (mock *UseCaseMock) GetDeveloperSummaryCalls() []struct {
+	Ctx   context.Context
+	Input *model.DeveloperSummaryInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.DeveloperSummaryInput
+	}
+	mock.lockGetDeveloperSummary.RLock()
+	calls = mock.calls.GetDeveloperSummary
+	mock.lockGetDeveloperSummary.RUnlock()
+	return calls
+}
+
+// GetGitHubRateLimits calls GetGitHubRateLimitsFunc.
+func (mock *UseCaseMock) GetGitHubRateLimits(ctx context.Context) []*model.GitHubRateLimit {
+	if mock.GetGitHubRateLimitsFunc == nil {
+		panic("UseCaseMock.GetGitHubRateLimitsFunc: method is nil but UseCase.GetGitHubRateLimits was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetGitHubRateLimits.Lock()
+	mock.calls.GetGitHubRateLimits = append(mock.calls.GetGitHubRateLimits, callInfo)
+	mock.lockGetGitHubRateLimits.Unlock()
+	return mock.GetGitHubRateLimitsFunc(ctx)
+}
+
+// GetGitHubRateLimitsCalls gets all the calls that were made to GetGitHubRateLimits.
+// Check the length with:
+//
+//	len(mockedUseCase.GetGitHubRateLimitsCalls())
+func (mock *UseCaseMock) GetGitHubRateLimitsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetGitHubRateLimits.RLock()
+	calls = mock.calls.GetGitHubRateLimits
+	mock.lockGetGitHubRateLimits.RUnlock()
+	return calls
+}
+
+// GetOwnerScanRun calls GetOwnerScanRunFunc.
+func (mock *UseCaseMock) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	if mock.GetOwnerScanRunFunc == nil {
+		panic("UseCaseMock.GetOwnerScanRunFunc: method is nil but UseCase.GetOwnerScanRun was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		RunID types.OwnerScanRunID
+	}{
+		Ctx:   ctx,
+		RunID: runID,
+	}
+	mock.lockGetOwnerScanRun.Lock()
+	mock.calls.GetOwnerScanRun = append(mock.calls.GetOwnerScanRun, callInfo)
+	mock.lockGetOwnerScanRun.Unlock()
+	return mock.GetOwnerScanRunFunc(ctx, runID)
+}
+
+// GetOwnerScanRunCalls gets all the calls that were made to GetOwnerScanRun.
+// Check the length with:
+//
+//	len(mockedUseCase.GetOwnerScanRunCalls())
+func (mock *UseCaseMock) GetOwnerScanRunCalls() []struct {
+	Ctx   context.Context
+	RunID types.OwnerScanRunID
+} {
+	var calls []struct {
+		Ctx   context.Context
+		RunID types.OwnerScanRunID
+	}
+	mock.lockGetOwnerScanRun.RLock()
+	calls = mock.calls.GetOwnerScanRun
+	mock.lockGetOwnerScanRun.RUnlock()
+	return calls
+}
+
+// GetPermalinkView calls GetPermalinkViewFunc.
+func (mock *UseCaseMock) GetPermalinkView(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error) {
+	if mock.GetPermalinkViewFunc == nil {
+		panic("UseCaseMock.GetPermalinkViewFunc: method is nil but UseCase.GetPermalinkView was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.GetPermalinkViewInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetPermalinkView.Lock()
+	mock.calls.GetPermalinkView = append(mock.calls.GetPermalinkView, callInfo)
+	mock.lockGetPermalinkView.Unlock()
+	return mock.GetPermalinkViewFunc(ctx, input)
+}
+
+// GetPermalinkViewCalls gets all the calls that were made to GetPermalinkView.
+// Check the length with:
+//
+//	len(mockedUseCase.GetPermalinkViewCalls())
+func (mock *UseCaseMock) GetPermalinkViewCalls() []struct {
+	Ctx   context.Context
+	Input *model.GetPermalinkViewInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.GetPermalinkViewInput
+	}
+	mock.lockGetPermalinkView.RLock()
+	calls = mock.calls.GetPermalinkView
+	mock.lockGetPermalinkView.RUnlock()
+	return calls
+}
+
+// GetRepository calls GetRepositoryFunc.
+func (mock *UseCaseMock) GetRepository(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error) {
+	if mock.GetRepositoryFunc == nil {
+		panic("UseCaseMock.GetRepositoryFunc: method is nil but UseCase.GetRepository was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.GetRepositoryInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetRepository.Lock()
+	mock.calls.GetRepository = append(mock.calls.GetRepository, callInfo)
+	mock.lockGetRepository.Unlock()
+	return mock.GetRepositoryFunc(ctx, input)
+}
+
+// GetRepositoryCalls gets all the calls that were made to GetRepository.
+// Check the length with:
+//
+//	len(mockedUseCase.GetRepositoryCalls())
+func (mock *UseCaseMock) GetRepositoryCalls() []struct {
+	Ctx   context.Context
+	Input *model.GetRepositoryInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.GetRepositoryInput
+	}
+	mock.lockGetRepository.RLock()
+	calls = mock.calls.GetRepository
+	mock.lockGetRepository.RUnlock()
+	return calls
+}
+
+// GetScanRun calls GetScanRunFunc.
+func (mock *UseCaseMock) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	if mock.GetScanRunFunc == nil {
+		panic("UseCaseMock.GetScanRunFunc: method is nil but UseCase.GetScanRun was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		RunID types.ScanRunID
+	}{
+		Ctx:   ctx,
+		RunID: runID,
+	}
+	mock.lockGetScanRun.Lock()
+	mock.calls.GetScanRun = append(mock.calls.GetScanRun, callInfo)
+	mock.lockGetScanRun.Unlock()
+	return mock.GetScanRunFunc(ctx, runID)
+}
+
+// GetScanRunCalls gets all the calls that were made to GetScanRun.
+// Check the length with:
+//
+//	len(mockedUseCase.GetScanRunCalls())
+func (mock *UseCaseMock) GetScanRunCalls() []struct {
+	Ctx   context.Context
+	RunID types.ScanRunID
+} {
+	var calls []struct {
+		Ctx   context.Context
+		RunID types.ScanRunID
+	}
+	mock.lockGetScanRun.RLock()
+	calls = mock.calls.GetScanRun
+	mock.lockGetScanRun.RUnlock()
+	return calls
+}
+
+// GetVulnerabilityDetail calls GetVulnerabilityDetailFunc.
+func (mock *UseCaseMock) GetVulnerabilityDetail(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error) {
+	if mock.GetVulnerabilityDetailFunc == nil {
+		panic("UseCaseMock.GetVulnerabilityDetailFunc: method is nil but UseCase.GetVulnerabilityDetail was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.GetVulnerabilityDetailInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetVulnerabilityDetail.Lock()
+	mock.calls.GetVulnerabilityDetail = append(mock.calls.GetVulnerabilityDetail, callInfo)
+	mock.lockGetVulnerabilityDetail.Unlock()
+	return mock.GetVulnerabilityDetailFunc(ctx, input)
+}
+
+// GetVulnerabilityDetailCalls gets all the calls that were made to GetVulnerabilityDetail.
+// Check the length with:
+//
+//	len(mockedUseCase.GetVulnerabilityDetailCalls())
+func (mock *UseCaseMock) GetVulnerabilityDetailCalls() []struct {
+	Ctx   context.Context
+	Input *model.GetVulnerabilityDetailInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.GetVulnerabilityDetailInput
+	}
+	mock.lockGetVulnerabilityDetail.RLock()
+	calls = mock.calls.GetVulnerabilityDetail
+	mock.lockGetVulnerabilityDetail.RUnlock()
+	return calls
+}
+
+// GetVulnerabilityRollup calls GetVulnerabilityRollupFunc.
+func (mock *UseCaseMock) GetVulnerabilityRollup(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error) {
+	if mock.GetVulnerabilityRollupFunc == nil {
+		panic("UseCaseMock.GetVulnerabilityRollupFunc: method is nil but UseCase.GetVulnerabilityRollup was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.GetVulnerabilityRollupInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockGetVulnerabilityRollup.Lock()
+	mock.calls.GetVulnerabilityRollup = append(mock.calls.GetVulnerabilityRollup, callInfo)
+	mock.lockGetVulnerabilityRollup.Unlock()
+	return mock.GetVulnerabilityRollupFunc(ctx, input)
+}
+
+// GetVulnerabilityRollupCalls gets all the calls that were made to GetVulnerabilityRollup.
+// Check the length with:
+//
+//	len(mockedUseCase.GetVulnerabilityRollupCalls())
+func (mock *UseCaseMock) GetVulnerabilityRollupCalls() []struct {
+	Ctx   context.Context
+	Input *model.GetVulnerabilityRollupInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.GetVulnerabilityRollupInput
+	}
+	mock.lockGetVulnerabilityRollup.RLock()
+	calls = mock.calls.GetVulnerabilityRollup
+	mock.lockGetVulnerabilityRollup.RUnlock()
+	return calls
+}
+
+// InsertScanResult calls InsertScanResultFunc.
+func (mock *UseCaseMock) InsertScanResult(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error) {
+	if mock.InsertScanResultFunc == nil {
+		panic("UseCaseMock.InsertScanResultFunc: method is nil but UseCase.InsertScanResult was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Meta   model.GitHubMetadata
+		Report trivy.Report
+	}{
+		Ctx:    ctx,
+		Meta:   meta,
+		Report: report,
+	}
+	mock.lockInsertScanResult.Lock()
+	mock.calls.InsertScanResult = append(mock.calls.InsertScanResult, callInfo)
+	mock.lockInsertScanResult.Unlock()
+	return mock.InsertScanResultFunc(ctx, meta, report)
+}
+
+// InsertScanResultCalls gets all the calls that were made to InsertScanResult.
+// Check the length with:
+//
+//	len(mockedUseCase.InsertScanResultCalls())
+func (mock *UseCaseMock) InsertScanResultCalls() []struct {
+	Ctx    context.Context
+	Meta   model.GitHubMetadata
+	Report trivy.Report
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Meta   model.GitHubMetadata
+		Report trivy.Report
+	}
+	mock.lockInsertScanResult.RLock()
+	calls = mock.calls.InsertScanResult
+	mock.lockInsertScanResult.RUnlock()
+	return calls
+}
+
+// ListBranchStatus calls ListBranchStatusFunc.
+func (mock *UseCaseMock) ListBranchStatus(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+	if mock.ListBranchStatusFunc == nil {
+		panic("UseCaseMock.ListBranchStatusFunc: method is nil but UseCase.ListBranchStatus was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ListBranchStatusInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListBranchStatus.Lock()
+	mock.calls.ListBranchStatus = append(mock.calls.ListBranchStatus, callInfo)
+	mock.lockListBranchStatus.Unlock()
+	return mock.ListBranchStatusFunc(ctx, input)
+}
+
+// ListBranchStatusCalls gets all the calls that were made to ListBranchStatus.
+// Check the length with:
+//
+//	len(mockedUseCase.ListBranchStatusCalls())
+func (mock *UseCaseMock) ListBranchStatusCalls() []struct {
+	Ctx   context.Context
+	Input *model.ListBranchStatusInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ListBranchStatusInput
+	}
+	mock.lockListBranchStatus.RLock()
+	calls = mock.calls.ListBranchStatus
+	mock.lockListBranchStatus.RUnlock()
+	return calls
+}
+
+// ListBranches calls ListBranchesFunc.
+func (mock *UseCaseMock) ListBranches(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error) {
+	if mock.ListBranchesFunc == nil {
+		panic("UseCaseMock.ListBranchesFunc: method is nil but UseCase.ListBranches was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ListBranchesInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListBranches.Lock()
+	mock.calls.ListBranches = append(mock.calls.ListBranches, callInfo)
+	mock.lockListBranches.Unlock()
+	return mock.ListBranchesFunc(ctx, input)
+}
+
+// ListBranchesCalls gets all the calls that were made to ListBranches.
+// Check the length with:
+//
+//	len(mockedUseCase.ListBranchesCalls())
+func (mock *UseCaseMock) ListBranchesCalls() []struct {
+	Ctx   context.Context
+	Input *model.ListBranchesInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ListBranchesInput
+	}
+	mock.lockListBranches.RLock()
+	calls = mock.calls.ListBranches
+	mock.lockListBranches.RUnlock()
+	return calls
+}
+
+// ListRepositories calls ListRepositoriesFunc.
+func (mock *UseCaseMock) ListRepositories(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+	if mock.ListRepositoriesFunc == nil {
+		panic("UseCaseMock.ListRepositoriesFunc: method is nil but UseCase.ListRepositories was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ListRepositoriesInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListRepositories.Lock()
+	mock.calls.ListRepositories = append(mock.calls.ListRepositories, callInfo)
+	mock.lockListRepositories.Unlock()
+	return mock.ListRepositoriesFunc(ctx, input)
+}
+
+// ListRepositoriesCalls gets all the calls that were made to ListRepositories.
+// Check the length with:
+//
+//	len(mockedUseCase.ListRepositoriesCalls())
+func (mock *UseCaseMock) ListRepositoriesCalls() []struct {
+	Ctx   context.Context
+	Input *model.ListRepositoriesInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ListRepositoriesInput
+	}
+	mock.lockListRepositories.RLock()
+	calls = mock.calls.ListRepositories
+	mock.lockListRepositories.RUnlock()
+	return calls
+}
+
+// ListSeverityOverrides calls ListSeverityOverridesFunc.
+func (mock *UseCaseMock) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	if mock.ListSeverityOverridesFunc == nil {
+		panic("UseCaseMock.ListSeverityOverridesFunc: method is nil but UseCase.ListSeverityOverrides was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Owner string
+	}{
+		Ctx:   ctx,
+		Owner: owner,
+	}
+	mock.lockListSeverityOverrides.Lock()
+	mock.calls.ListSeverityOverrides = append(mock.calls.ListSeverityOverrides, callInfo)
+	mock.lockListSeverityOverrides.Unlock()
+	return mock.ListSeverityOverridesFunc(ctx, owner)
+}
+
+// ListSeverityOverridesCalls gets all the calls that were made to ListSeverityOverrides.
+// Check the length with:
+//
+//	len(mockedUseCase.ListSeverityOverridesCalls())
+func (mock *UseCaseMock) ListSeverityOverridesCalls() []struct {
+	Ctx   context.Context
+	Owner string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Owner string
+	}
+	mock.lockListSeverityOverrides.RLock()
+	calls = mock.calls.ListSeverityOverrides
+	mock.lockListSeverityOverrides.RUnlock()
+	return calls
+}
+
+// ListTargets calls ListTargetsFunc.
+func (mock *UseCaseMock) ListTargets(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error) {
+	if mock.ListTargetsFunc == nil {
+		panic("UseCaseMock.ListTargetsFunc: method is nil but UseCase.ListTargets was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ListTargetsInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListTargets.Lock()
+	mock.calls.ListTargets = append(mock.calls.ListTargets, callInfo)
+	mock.lockListTargets.Unlock()
+	return mock.ListTargetsFunc(ctx, input)
+}
+
+// ListTargetsCalls gets all the calls that were made to ListTargets.
+// Check the length with:
+//
+//	len(mockedUseCase.ListTargetsCalls())
+func (mock *UseCaseMock) ListTargetsCalls() []struct {
+	Ctx   context.Context
+	Input *model.ListTargetsInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ListTargetsInput
+	}
+	mock.lockListTargets.RLock()
+	calls = mock.calls.ListTargets
+	mock.lockListTargets.RUnlock()
+	return calls
+}
+
+// ListVulnerabilities calls ListVulnerabilitiesFunc.
+func (mock *UseCaseMock) ListVulnerabilities(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+	if mock.ListVulnerabilitiesFunc == nil {
+		panic("UseCaseMock.ListVulnerabilitiesFunc: method is nil but UseCase.ListVulnerabilities was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ListVulnerabilitiesInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockListVulnerabilities.Lock()
+	mock.calls.ListVulnerabilities = append(mock.calls.ListVulnerabilities, callInfo)
+	mock.lockListVulnerabilities.Unlock()
+	return mock.ListVulnerabilitiesFunc(ctx, input)
+}
+
+// ListVulnerabilitiesCalls gets all the calls that were made to ListVulnerabilities.
+// Check the length with:
+//
+//	len(mockedUseCase.ListVulnerabilitiesCalls())
+func (mock *UseCaseMock) ListVulnerabilitiesCalls() []struct {
+	Ctx   context.Context
+	Input *model.ListVulnerabilitiesInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ListVulnerabilitiesInput
+	}
+	mock.lockListVulnerabilities.RLock()
+	calls = mock.calls.ListVulnerabilities
+	mock.lockListVulnerabilities.RUnlock()
+	return calls
+}
+
+// PrepareAgentScanJob calls PrepareAgentScanJobFunc.
+func (mock *UseCaseMock) PrepareAgentScanJob(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error) {
+	if mock.PrepareAgentScanJobFunc == nil {
+		panic("UseCaseMock.PrepareAgentScanJobFunc: method is nil but UseCase.PrepareAgentScanJob was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockPrepareAgentScanJob.Lock()
+	mock.calls.PrepareAgentScanJob = append(mock.calls.PrepareAgentScanJob, callInfo)
+	mock.lockPrepareAgentScanJob.Unlock()
+	return mock.PrepareAgentScanJobFunc(ctx, input)
+}
+
+// PrepareAgentScanJobCalls gets all the calls that were made to PrepareAgentScanJob.
+// Check the length with:
+//
+//	len(mockedUseCase.PrepareAgentScanJobCalls())
+func (mock *UseCaseMock) PrepareAgentScanJobCalls() []struct {
+	Ctx   context.Context
+	Input *model.ScanGitHubRepoInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoInput
+	}
+	mock.lockPrepareAgentScanJob.RLock()
+	calls = mock.calls.PrepareAgentScanJob
+	mock.lockPrepareAgentScanJob.RUnlock()
+	return calls
+}
+
+// PrepareScanGitHubRepo calls PrepareScanGitHubRepoFunc.
+func (mock *UseCaseMock) PrepareScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+	if mock.PrepareScanGitHubRepoFunc == nil {
+		panic("UseCaseMock.PrepareScanGitHubRepoFunc: method is nil but UseCase.PrepareScanGitHubRepo was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoRemoteInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockPrepareScanGitHubRepo.Lock()
+	mock.calls.PrepareScanGitHubRepo = append(mock.calls.PrepareScanGitHubRepo, callInfo)
+	mock.lockPrepareScanGitHubRepo.Unlock()
+	return mock.PrepareScanGitHubRepoFunc(ctx, input)
+}
+
+// PrepareScanGitHubRepoCalls gets all the calls that were made to PrepareScanGitHubRepo.
+// Check the length with:
+//
+//	len(mockedUseCase.PrepareScanGitHubRepoCalls())
+func (mock *UseCaseMock) PrepareScanGitHubRepoCalls() []struct {
+	Ctx   context.Context
+	Input *model.ScanGitHubRepoRemoteInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoRemoteInput
+	}
+	mock.lockPrepareScanGitHubRepo.RLock()
+	calls = mock.calls.PrepareScanGitHubRepo
+	mock.lockPrepareScanGitHubRepo.RUnlock()
+	return calls
+}
+
+// PutSeverityOverride calls PutSeverityOverrideFunc.
+func (mock *UseCaseMock) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error) {
+	if mock.PutSeverityOverrideFunc == nil {
+		panic("UseCaseMock.PutSeverityOverrideFunc: method is nil but UseCase.PutSeverityOverride was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Override *model.SeverityOverride
+	}{
+		Ctx:      ctx,
+		Override: override,
+	}
+	mock.lockPutSeverityOverride.Lock()
+	mock.calls.PutSeverityOverride = append(mock.calls.PutSeverityOverride, callInfo)
+	mock.lockPutSeverityOverride.Unlock()
+	return mock.PutSeverityOverrideFunc(ctx, override)
+}
+
+// PutSeverityOverrideCalls gets all the calls that were made to PutSeverityOverride.
+// Check the length with:
+//
+//	len(mockedUseCase.PutSeverityOverrideCalls())
+func (mock *UseCaseMock) PutSeverityOverrideCalls() []struct {
+	Ctx      context.Context
+	Override *model.SeverityOverride
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Override *model.SeverityOverride
+	}
+	mock.lockPutSeverityOverride.RLock()
+	calls = mock.calls.PutSeverityOverride
+	mock.lockPutSeverityOverride.RUnlock()
+	return calls
+}
+
+// ScanAgentJob calls ScanAgentJobFunc.
+func (mock *UseCaseMock) ScanAgentJob(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error) {
+	if mock.ScanAgentJobFunc == nil {
+		panic("UseCaseMock.ScanAgentJobFunc: method is nil but UseCase.ScanAgentJob was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Job *model.AgentScanJob
+	}{
+		Ctx: ctx,
+		Job: job,
+	}
+	mock.lockScanAgentJob.Lock()
+	mock.calls.ScanAgentJob = append(mock.calls.ScanAgentJob, callInfo)
+	mock.lockScanAgentJob.Unlock()
+	return mock.ScanAgentJobFunc(ctx, job)
+}
+
+// ScanAgentJobCalls gets all the calls that were made to ScanAgentJob.
+// Check the length with:
+//
+//	len(mockedUseCase.ScanAgentJobCalls())
+func (mock *UseCaseMock) ScanAgentJobCalls() []struct {
+	Ctx context.Context
+	Job *model.AgentScanJob
+} {
+	var calls []struct {
+		Ctx context.Context
+		Job *model.AgentScanJob
+	}
+	mock.lockScanAgentJob.RLock()
+	calls = mock.calls.ScanAgentJob
+	mock.lockScanAgentJob.RUnlock()
+	return calls
+}
+
+// ScanGitHubRepo calls ScanGitHubRepoFunc.
+func (mock *UseCaseMock) ScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+	if mock.ScanGitHubRepoFunc == nil {
+		panic("UseCaseMock.ScanGitHubRepoFunc: method is nil but UseCase.ScanGitHubRepo was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockScanGitHubRepo.Lock()
+	mock.calls.ScanGitHubRepo = append(mock.calls.ScanGitHubRepo, callInfo)
+	mock.lockScanGitHubRepo.Unlock()
+	return mock.ScanGitHubRepoFunc(ctx, input)
+}
+
+// ScanGitHubRepoCalls gets all the calls that were made to ScanGitHubRepo.
+// Check the length with:
+//
+//	len(mockedUseCase.ScanGitHubRepoCalls())
+func (mock *UseCaseMock) ScanGitHubRepoCalls() []struct {
+	Ctx   context.Context
+	Input *model.ScanGitHubRepoInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubRepoInput
+	}
+	mock.lockScanGitHubRepo.RLock()
+	calls = mock.calls.ScanGitHubRepo
+	mock.lockScanGitHubRepo.RUnlock()
+	return calls
+}
+
+// ScanGitHubReposByOwnerFromAPI calls ScanGitHubReposByOwnerFromAPIFunc.
+func (mock *UseCaseMock) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
+	if mock.ScanGitHubReposByOwnerFromAPIFunc == nil {
+		panic("UseCaseMock.ScanGitHubReposByOwnerFromAPIFunc: method is nil but UseCase.ScanGitHubReposByOwnerFromAPI was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubReposByOwnerFromAPIInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockScanGitHubReposByOwnerFromAPI.Lock()
+	mock.calls.ScanGitHubReposByOwnerFromAPI = append(mock.calls.ScanGitHubReposByOwnerFromAPI, callInfo)
+	mock.lockScanGitHubReposByOwnerFromAPI.Unlock()
+	return mock.ScanGitHubReposByOwnerFromAPIFunc(ctx, input)
+}
+
+// ScanGitHubReposByOwnerFromAPICalls gets all the calls that were made to ScanGitHubReposByOwnerFromAPI.
+// Check the length with:
+//
+//	len(mockedUseCase.ScanGitHubReposByOwnerFromAPICalls())
+func (mock *UseCaseMock) ScanGitHubReposByOwnerFromAPICalls() []struct {
+	Ctx   context.Context
+	Input *model.ScanGitHubReposByOwnerFromAPIInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.ScanGitHubReposByOwnerFromAPIInput
+	}
+	mock.lockScanGitHubReposByOwnerFromAPI.RLock()
+	calls = mock.calls.ScanGitHubReposByOwnerFromAPI
+	mock.lockScanGitHubReposByOwnerFromAPI.RUnlock()
+	return calls
+}
+
+// SeedInstallationRepos calls SeedInstallationReposFunc.
+func (mock *UseCaseMock) SeedInstallationRepos(ctx context.Context, input *model.SeedInstallationReposInput) (int, error) {
+	if mock.SeedInstallationReposFunc == nil {
+		panic("UseCaseMock.SeedInstallationReposFunc: method is nil but UseCase.SeedInstallationRepos was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.SeedInstallationReposInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockSeedInstallationRepos.Lock()
+	mock.calls.SeedInstallationRepos = append(mock.calls.SeedInstallationRepos, callInfo)
+	mock.lockSeedInstallationRepos.Unlock()
+	return mock.SeedInstallationReposFunc(ctx, input)
+}
+
+// SeedInstallationReposCalls gets all the calls that were made to SeedInstallationRepos.
+// Check the length with:
+//
+//	len(mockedUseCase.SeedInstallationReposCalls())
+func (mock *UseCaseMock) SeedInstallationReposCalls() []struct {
+	Ctx   context.Context
+	Input *model.SeedInstallationReposInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.SeedInstallationReposInput
+	}
+	mock.lockSeedInstallationRepos.RLock()
+	calls = mock.calls.SeedInstallationRepos
+	mock.lockSeedInstallationRepos.RUnlock()
+	return calls
+}
+
+// UpdateRepositoryMetadata calls UpdateRepositoryMetadataFunc.
+func (mock *UseCaseMock) UpdateRepositoryMetadata(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error) {
+	if mock.UpdateRepositoryMetadataFunc == nil {
+		panic("UseCaseMock.UpdateRepositoryMetadataFunc: method is nil but UseCase.UpdateRepositoryMetadata was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Input *model.UpdateRepositoryMetadataInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockUpdateRepositoryMetadata.Lock()
+	mock.calls.UpdateRepositoryMetadata = append(mock.calls.UpdateRepositoryMetadata, callInfo)
+	mock.lockUpdateRepositoryMetadata.Unlock()
+	return mock.UpdateRepositoryMetadataFunc(ctx, input)
+}
+
+// UpdateRepositoryMetadataCalls gets all the calls that were made to UpdateRepositoryMetadata.
+// Check the length with:
+//
+//	len(mockedUseCase.UpdateRepositoryMetadataCalls())
+func (mock *UseCaseMock) UpdateRepositoryMetadataCalls() []struct {
+	Ctx   context.Context
+	Input *model.UpdateRepositoryMetadataInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *model.UpdateRepositoryMetadataInput
+	}
+	mock.lockUpdateRepositoryMetadata.RLock()
+	calls = mock.calls.UpdateRepositoryMetadata
+	mock.lockUpdateRepositoryMetadata.RUnlock()
 	return calls
 }
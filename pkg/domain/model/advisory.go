@@ -0,0 +1,222 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// InternalAdvisorySourceID is the data source ID of vulnerabilities generated from internal advisories
+const InternalAdvisorySourceID trivy.SourceID = "internal"
+
+// InternalAdvisoryFeed is a private vulnerability advisory feed for in-house libraries
+type InternalAdvisoryFeed struct {
+	Advisories []*InternalAdvisory `json:"advisories"`
+}
+
+// InternalAdvisory is an in-house CVE-equivalent record of an internal library. VulnerableVersions is a comma separated list of constraints which must all match, e.g. ">= 1.0.0, < 1.4.2". Alternatives are separated by "||".
+type InternalAdvisory struct {
+	ID                 string   `json:"id"`
+	Package            string   `json:"package"`
+	Type               string   `json:"type,omitempty"`
+	VulnerableVersions string   `json:"vulnerable_versions"`
+	FixedVersion       string   `json:"fixed_version,omitempty"`
+	Severity           string   `json:"severity"`
+	Title              string   `json:"title,omitempty"`
+	Description        string   `json:"description,omitempty"`
+	PrimaryURL         string   `json:"primary_url,omitempty"`
+	References         []string `json:"references,omitempty"`
+}
+
+func (x *InternalAdvisory) Validate() error {
+	if x.ID == "" {
+		return goerr.Wrap(types.ErrValidationFailed, "advisory ID is required")
+	}
+	if x.Package == "" {
+		return goerr.Wrap(types.ErrValidationFailed, "advisory package is required", goerr.V("id", x.ID))
+	}
+	if _, err := types.ParseSeverity(x.Severity); err != nil {
+		return goerr.Wrap(err, "invalid advisory severity", goerr.V("id", x.ID))
+	}
+	if _, err := parseVersionConstraints(x.VulnerableVersions); err != nil {
+		return goerr.Wrap(err, "invalid advisory vulnerable versions", goerr.V("id", x.ID))
+	}
+	return nil
+}
+
+// Affects reports whether the package of a result with resultType is vulnerable to the advisory. Invalid advisories never match, so Validate must be called on load.
+func (x *InternalAdvisory) Affects(resultType string, pkg *trivy.Package) bool {
+	if pkg.Name != x.Package || pkg.Version == "" {
+		return false
+	}
+	if x.Type != "" && x.Type != resultType {
+		return false
+	}
+
+	alternatives, err := parseVersionConstraints(x.VulnerableVersions)
+	if err != nil {
+		return false
+	}
+	for _, constraints := range alternatives {
+		if constraints.match(pkg.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDetectedVulnerability creates a Trivy vulnerability of the package from the advisory, marked with InternalAdvisorySourceID.
+func (x *InternalAdvisory) NewDetectedVulnerability(pkg *trivy.Package) trivy.DetectedVulnerability {
+	status := "affected"
+	if x.FixedVersion != "" {
+		status = "fixed"
+	}
+
+	return trivy.DetectedVulnerability{
+		VulnerabilityID:  x.ID,
+		PkgID:            pkg.ID,
+		PkgName:          pkg.Name,
+		PkgPath:          pkg.FilePath,
+		InstalledVersion: pkg.Version,
+		FixedVersion:     x.FixedVersion,
+		Status:           status,
+		SeveritySource:   InternalAdvisorySourceID,
+		PrimaryURL:       x.PrimaryURL,
+		DataSource: &trivy.DataSource{
+			ID:   InternalAdvisorySourceID,
+			Name: "Internal advisory feed",
+		},
+		Vulnerability: trivy.Vulnerability{
+			Title:       x.Title,
+			Description: x.Description,
+			Severity:    strings.ToUpper(x.Severity),
+			References:  x.References,
+		},
+	}
+}
+
+type versionConstraint struct {
+	op      string
+	version string
+}
+
+type versionConstraints []versionConstraint
+
+func (x versionConstraints) match(version string) bool {
+	for _, c := range x {
+		cmp := compareVersions(version, c.version)
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionConstraints parses alternatives separated by "||" of comma separated constraints. A constraint without operator means "=".
+func parseVersionConstraints(s string) ([]versionConstraints, error) {
+	var alternatives []versionConstraints
+	for _, alt := range strings.Split(s, "||") {
+		var constraints versionConstraints
+		for _, part := range strings.Split(alt, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			c := versionConstraint{op: "="}
+			for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+				if strings.HasPrefix(part, op) {
+					c.op = op
+					part = strings.TrimSpace(strings.TrimPrefix(part, op))
+					break
+				}
+			}
+			if part == "" {
+				return nil, goerr.Wrap(types.ErrValidationFailed, "version is missing in constraint", goerr.V("constraints", s))
+			}
+			c.version = part
+			constraints = append(constraints, c)
+		}
+		if len(constraints) == 0 {
+			return nil, goerr.Wrap(types.ErrValidationFailed, "empty version constraint", goerr.V("constraints", s))
+		}
+		alternatives = append(alternatives, constraints)
+	}
+	return alternatives, nil
+}
+
+// compareVersions compares dotted versions segment by segment. Numeric segments are compared as numbers and others as strings, missing segments are treated as 0, and a version with a pre-release suffix (after "-") is lower than the same version without it.
+func compareVersions(a, b string) int {
+	a, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	// Build metadata does not affect precedence
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	if cmp := compareSegments(strings.Split(a, "."), strings.Split(b, ".")); cmp != 0 {
+		return cmp
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	default:
+		return compareSegments(strings.Split(aPre, "."), strings.Split(bPre, "."))
+	}
+}
+
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		sa, sb := "0", "0"
+		if i < len(a) {
+			sa = a[i]
+		}
+		if i < len(b) {
+			sb = b[i]
+		}
+
+		na, errA := strconv.ParseUint(sa, 10, 64)
+		nb, errB := strconv.ParseUint(sb, 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			// Numeric identifiers have lower precedence than alphanumeric ones
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if cmp := strings.Compare(sa, sb); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
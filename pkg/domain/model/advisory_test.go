@@ -0,0 +1,95 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestInternalAdvisoryAffects(t *testing.T) {
+	testCases := map[string]struct {
+		versions string
+		advType  string
+		version  string
+		expected bool
+	}{
+		"in range":                      {">= 1.0.0, < 1.4.2", "", "1.3.9", true},
+		"lower bound":                   {">= 1.0.0, < 1.4.2", "", "1.0.0", true},
+		"upper bound is exclusive":      {">= 1.0.0, < 1.4.2", "", "1.4.2", false},
+		"below range":                   {">= 1.0.0, < 1.4.2", "", "0.9.12", false},
+		"numeric compare of segments":   {"< 1.10.0", "", "1.9.0", true},
+		"v prefix":                      {"< 1.4.2", "", "v1.4.1", true},
+		"missing segment is zero":       {"<= 1.4", "", "1.4.0", true},
+		"pre-release is lower":          {"< 2.0.0", "", "2.0.0-rc.1", true},
+		"exact version":                 {"1.2.3", "", "1.2.3", true},
+		"second alternative":            {"< 1.0.0 || >= 2.0.0, < 2.1.0", "", "2.0.5", true},
+		"out of all alternatives":       {"< 1.0.0 || >= 2.0.0, < 2.1.0", "", "1.5.0", false},
+		"matched type":                  {"< 2.0.0", "gomod", "1.0.0", true},
+		"different type is not matched": {"< 2.0.0", "npm", "1.0.0", false},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			advisory := &model.InternalAdvisory{
+				ID:                 "INTERNAL-2024-0001",
+				Package:            "github.com/example/lib",
+				Type:               tc.advType,
+				VulnerableVersions: tc.versions,
+				Severity:           "high",
+			}
+			gt.NoError(t, advisory.Validate())
+
+			pkg := &trivy.Package{Name: "github.com/example/lib", Version: tc.version}
+			gt.V(t, advisory.Affects("gomod", pkg)).Equal(tc.expected)
+		})
+	}
+
+	t.Run("different package is not matched", func(t *testing.T) {
+		advisory := &model.InternalAdvisory{ID: "INTERNAL-2024-0001", Package: "lib-a", VulnerableVersions: "< 2.0.0", Severity: "HIGH"}
+		gt.False(t, advisory.Affects("npm", &trivy.Package{Name: "lib-b", Version: "1.0.0"}))
+	})
+}
+
+func TestInternalAdvisoryValidate(t *testing.T) {
+	valid := model.InternalAdvisory{ID: "INTERNAL-2024-0001", Package: "lib-a", VulnerableVersions: "< 2.0.0", Severity: "HIGH"}
+	gt.NoError(t, valid.Validate())
+
+	testCases := map[string]func(x *model.InternalAdvisory){
+		"no ID":             func(x *model.InternalAdvisory) { x.ID = "" },
+		"no package":        func(x *model.InternalAdvisory) { x.Package = "" },
+		"invalid severity":  func(x *model.InternalAdvisory) { x.Severity = "urgent" },
+		"no versions":       func(x *model.InternalAdvisory) { x.VulnerableVersions = "" },
+		"operator only":     func(x *model.InternalAdvisory) { x.VulnerableVersions = ">= 1.0.0, <" },
+		"empty alternative": func(x *model.InternalAdvisory) { x.VulnerableVersions = "< 1.0.0 ||" },
+	}
+	for title, modify := range testCases {
+		t.Run(title, func(t *testing.T) {
+			advisory := valid
+			modify(&advisory)
+			gt.Error(t, advisory.Validate())
+		})
+	}
+}
+
+func TestInternalAdvisoryNewDetectedVulnerability(t *testing.T) {
+	advisory := &model.InternalAdvisory{
+		ID:                 "INTERNAL-2024-0001",
+		Package:            "lib-a",
+		VulnerableVersions: "< 2.0.0",
+		FixedVersion:       "2.0.0",
+		Severity:           "critical",
+		Title:              "RCE in lib-a",
+	}
+	detected := advisory.NewDetectedVulnerability(&trivy.Package{ID: "lib-a@1.0.0", Name: "lib-a", Version: "1.0.0"})
+	gt.V(t, detected.VulnerabilityID).Equal("INTERNAL-2024-0001")
+	gt.V(t, detected.InstalledVersion).Equal("1.0.0")
+	gt.V(t, detected.FixedVersion).Equal("2.0.0")
+	gt.V(t, detected.Severity).Equal("CRITICAL")
+	gt.V(t, detected.DataSource.ID).Equal(model.InternalAdvisorySourceID)
+
+	vuln := model.NewVulnerability(&detected)
+	gt.V(t, vuln.Source).Equal("internal")
+	gt.V(t, vuln.Title).Equal("RCE in lib-a")
+}
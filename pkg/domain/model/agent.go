@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// AgentScanJob is a scan delegated to a scan agent. The agent downloads the source code from ArchiveURL by itself, so that the code never passes through the server.
+type AgentScanJob struct {
+	ID         string         `json:"id"`
+	Metadata   GitHubMetadata `json:"metadata"`
+	ArchiveURL string         `json:"archive_url"`
+	// ScanConfig is the scan config stored for the repository. A scan config file in the repository takes precedence over it.
+	ScanConfig *ScanConfig `json:"scan_config,omitempty"`
+	// RunID is the ScanRun of the scan, empty if runs are not recorded
+	RunID types.ScanRunID `json:"run_id,omitempty"`
+}
+
+// AgentScanResult is the result of an AgentScanJob reported by a scan agent. Error is set instead of Report if the scan failed.
+type AgentScanResult struct {
+	Report   *trivy.Report `json:"report,omitempty"`
+	Coverage *ScanCoverage `json:"coverage,omitempty"`
+	Archive  *ScanArchive  `json:"archive,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
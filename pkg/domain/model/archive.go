@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+)
+
+// ArchiveBigQueryInput selects rows of BigQuery tables to archive to Cloud Storage
+type ArchiveBigQueryInput struct {
+	// Before archives rows of UTC days before the day of this time, so that only whole days are archived
+	Before time.Time
+	// Bucket and Prefix are the Cloud Storage location of Parquet files
+	Bucket string
+	Prefix string
+	// StatePath is a local file which records progress of archival to resume an interrupted job and to restore archived days
+	StatePath string
+	// Interval is the wait between days to limit the rate of export and DML jobs
+	Interval time.Duration
+	// MaxDays limits the number of days of a table archived in a run. 0 means no limit.
+	MaxDays int
+	// DryRun only lists the days to be archived
+	DryRun bool
+}
+
+// RestoreBigQueryInput selects archived days to load back to BigQuery tables
+type RestoreBigQueryInput struct {
+	// From and To are the first and the last UTC day to restore
+	From      time.Time
+	To        time.Time
+	StatePath string
+	// DryRun only lists the days to be restored
+	DryRun bool
+}
+
+// ArchiveBigQueryResult is the days archived or restored, or to be processed in dry run
+type ArchiveBigQueryResult struct {
+	DryRun bool
+	Days   []*ArchivedDay
+}
+
+// ArchiveState is the progress of BigQuery archival. A day is exported first and then deleted from BigQuery, and each step is recorded so that an interrupted job resumes without exporting the day again.
+type ArchiveState struct {
+	Days []*ArchivedDay `json:"days"`
+}
+
+// ArchivedDay is rows of a UTC day of a BigQuery table. Table is "scan", "vulnerability" or "package".
+type ArchivedDay struct {
+	Table      string     `json:"table"`
+	Day        string     `json:"day"`
+	URI        string     `json:"uri"`
+	Rows       int64      `json:"rows"`
+	ExportedAt *time.Time `json:"exported_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Find returns the day of the table, or nil if it is not recorded
+func (x *ArchiveState) Find(table, day string) *ArchivedDay {
+	for _, d := range x.Days {
+		if d.Table == table && d.Day == day {
+			return d
+		}
+	}
+	return nil
+}
+
+// Remove removes the day of the table from the state
+func (x *ArchiveState) Remove(table, day string) {
+	days := x.Days[:0]
+	for _, d := range x.Days {
+		if d.Table != table || d.Day != day {
+			days = append(days, d)
+		}
+	}
+	x.Days = days
+}
@@ -0,0 +1,40 @@
+package model
+
+const (
+	// DefaultMaxArchiveSize is large enough for source code of most monorepos, but stops a zip bomb before it fills the disk
+	DefaultMaxArchiveSize     int64 = 4 << 30
+	DefaultMaxArchiveFileSize int64 = 1 << 30
+	DefaultMaxArchiveFiles          = 200000
+	// DefaultMaxArchiveDownloadSize limits the compressed archive, which is smaller than the extracted files
+	DefaultMaxArchiveDownloadSize int64 = 2 << 30
+)
+
+// ArchiveLimits limits extraction of a zip archive of source code. Sizes are of uncompressed data in bytes. Zero means no limit.
+type ArchiveLimits struct {
+	// MaxTotalSize is the limit of the sum of sizes of all files
+	MaxTotalSize int64
+	// MaxFileSize is the limit of the size of each file
+	MaxFileSize int64
+	// MaxFiles is the limit of the number of files, excluding directories
+	MaxFiles int
+	// MaxDownloadSize is the limit of the size of the archive itself. A download is aborted as soon as it exceeds the limit.
+	MaxDownloadSize int64
+}
+
+// DefaultArchiveLimits returns limits applied unless configured
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxTotalSize:    DefaultMaxArchiveSize,
+		MaxFileSize:     DefaultMaxArchiveFileSize,
+		MaxFiles:        DefaultMaxArchiveFiles,
+		MaxDownloadSize: DefaultMaxArchiveDownloadSize,
+	}
+}
+
+// ScanArchive describes the zip archive of source code fetched for a scan, so that the scanned code can be identified afterwards
+type ScanArchive struct {
+	// Size is the size of the archive in bytes
+	Size int64 `bigquery:"size" json:"size"`
+	// SHA256 is the hex encoded SHA-256 digest of the archive. It is empty unless checksum recording is enabled.
+	SHA256 string `bigquery:"sha256" json:"sha256,omitempty"`
+}
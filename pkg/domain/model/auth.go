@@ -0,0 +1,86 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// GitHubOAuthUser is a GitHub user who signed in to the dashboard with GitHub OAuth
+type GitHubOAuthUser struct {
+	Login string
+	Orgs  []string
+	Teams []string // "org/team-slug"
+}
+
+// OIDCClaims are claims of a verified OIDC ID token. RepositoryOwner is set by GitHub Actions, and Email by Google for service accounts.
+type OIDCClaims struct {
+	Issuer          string `json:"iss"`
+	Subject         string `json:"sub"`
+	RepositoryOwner string `json:"repository_owner"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+}
+
+// IsPrincipalOf returns true if the subject or the verified email of the token is one of principals, e.g. service accounts
+func (x *OIDCClaims) IsPrincipalOf(principals []string) bool {
+	if slices.Contains(principals, x.Subject) {
+		return true
+	}
+	return x.EmailVerified && x.Email != "" && slices.ContainsFunc(principals, func(p string) bool {
+		return strings.EqualFold(p, x.Email)
+	})
+}
+
+// AuthPolicy decides who can access the read API and the dashboard, and who can call admin endpoints. Principals allowed by the policy itself can access all data. Names of organizations and teams are compared case-insensitively, as GitHub does.
+type AuthPolicy struct {
+	// AllowedOrgs are GitHub organizations whose members and GitHub Actions workflows are allowed
+	AllowedOrgs []string
+	// AllowedTeams are GitHub teams in "org/team-slug" form whose members are allowed
+	AllowedTeams []string
+	// AdminTeams are GitHub teams in "org/team-slug" form whose members are allowed to call admin endpoints as well
+	AdminTeams []string
+	// OIDCSubjects are subjects of OIDC tokens allowed regardless of repository owner, e.g. service accounts of a cloud provider
+	OIDCSubjects []string
+
+	// Tenants allow principals which are not allowed by the fields above to access data of their tenants only
+	Tenants []*TenantDefinition
+}
+
+func containsFold(list []string, v string) bool {
+	return slices.ContainsFunc(list, func(x string) bool {
+		return strings.EqualFold(x, v)
+	})
+}
+
+// AllowsUser returns true if the user belongs to one of allowed organizations or teams, or admin teams
+func (x *AuthPolicy) AllowsUser(user *GitHubOAuthUser) bool {
+	for _, org := range user.Orgs {
+		if containsFold(x.AllowedOrgs, org) {
+			return true
+		}
+	}
+	for _, team := range user.Teams {
+		if containsFold(x.AllowedTeams, team) {
+			return true
+		}
+	}
+	return x.IsAdmin(user)
+}
+
+// IsAdmin returns true if the user belongs to one of admin teams
+func (x *AuthPolicy) IsAdmin(user *GitHubOAuthUser) bool {
+	for _, team := range user.Teams {
+		if containsFold(x.AdminTeams, team) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOIDC returns true if the token is issued for a workflow of an allowed organization or for an allowed subject. Teams are not checked, because OIDC tokens do not have team membership.
+func (x *AuthPolicy) AllowsOIDC(claims *OIDCClaims) bool {
+	if claims.RepositoryOwner != "" && containsFold(x.AllowedOrgs, claims.RepositoryOwner) {
+		return true
+	}
+	return slices.Contains(x.OIDCSubjects, claims.Subject)
+}
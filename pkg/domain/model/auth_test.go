@@ -0,0 +1,55 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestAuthPolicy(t *testing.T) {
+	policy := &model.AuthPolicy{
+		AllowedOrgs:  []string{"MyOrg"},
+		AllowedTeams: []string{"partner/security"},
+		AdminTeams:   []string{"myorg/admins"},
+		OIDCSubjects: []string{"deployer@example.iam.gserviceaccount.com"},
+	}
+
+	t.Run("users", func(t *testing.T) {
+		member := &model.GitHubOAuthUser{Login: "alice", Orgs: []string{"other", "myorg"}}
+		gt.True(t, policy.AllowsUser(member))
+		gt.False(t, policy.IsAdmin(member))
+
+		teamMember := &model.GitHubOAuthUser{Login: "bob", Orgs: []string{"partner"}, Teams: []string{"partner/Security"}}
+		gt.True(t, policy.AllowsUser(teamMember))
+
+		admin := &model.GitHubOAuthUser{Login: "carol", Teams: []string{"myorg/admins"}}
+		gt.True(t, policy.AllowsUser(admin))
+		gt.True(t, policy.IsAdmin(admin))
+
+		outsider := &model.GitHubOAuthUser{Login: "mallory", Orgs: []string{"partner"}, Teams: []string{"partner/dev"}}
+		gt.False(t, policy.AllowsUser(outsider))
+		gt.False(t, policy.AllowsUser(&model.GitHubOAuthUser{Login: "nobody"}))
+	})
+
+	t.Run("OIDC tokens", func(t *testing.T) {
+		gt.True(t, policy.AllowsOIDC(&model.OIDCClaims{Subject: "repo:myorg/app:ref:refs/heads/main", RepositoryOwner: "myorg"}))
+		gt.True(t, policy.AllowsOIDC(&model.OIDCClaims{Subject: "deployer@example.iam.gserviceaccount.com"}))
+		gt.False(t, policy.AllowsOIDC(&model.OIDCClaims{Subject: "repo:partner/app:ref:refs/heads/main", RepositoryOwner: "partner"}))
+		gt.False(t, policy.AllowsOIDC(&model.OIDCClaims{Subject: "someone@example.com"}))
+	})
+
+	t.Run("principals of OIDC tokens", func(t *testing.T) {
+		principals := []string{"scheduler@example.iam.gserviceaccount.com", "1234567890"}
+		gt.True(t, (&model.OIDCClaims{Subject: "1234567890"}).IsPrincipalOf(principals))
+		gt.True(t, (&model.OIDCClaims{Subject: "999", Email: "Scheduler@example.iam.gserviceaccount.com", EmailVerified: true}).IsPrincipalOf(principals))
+		gt.False(t, (&model.OIDCClaims{Subject: "999", Email: "scheduler@example.iam.gserviceaccount.com"}).IsPrincipalOf(principals))
+		gt.False(t, (&model.OIDCClaims{Subject: "999", Email: "other@example.iam.gserviceaccount.com", EmailVerified: true}).IsPrincipalOf(principals))
+	})
+
+	t.Run("empty policy allows nobody", func(t *testing.T) {
+		empty := &model.AuthPolicy{}
+		gt.False(t, empty.AllowsUser(&model.GitHubOAuthUser{Login: "alice", Orgs: []string{"myorg"}}))
+		gt.False(t, empty.AllowsOIDC(&model.OIDCClaims{Subject: "repo:myorg/app", RepositoryOwner: "myorg"}))
+	})
+}
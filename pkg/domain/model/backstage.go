@@ -0,0 +1,89 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// Annotations of Backstage catalog entities to map them to repositories. BackstageAnnotationRepository takes precedence over the project slug of the Backstage GitHub integration.
+const (
+	BackstageAnnotationRepository  = "octovy.io/repository"
+	BackstageAnnotationProjectSlug = "github.com/project-slug"
+	BackstageAnnotationBranch      = "octovy.io/branch"
+)
+
+// BackstageEntity is a subset of a Backstage catalog entity needed to resolve the repository
+type BackstageEntity struct {
+	Kind     string                  `json:"kind"`
+	Metadata BackstageEntityMetadata `json:"metadata"`
+}
+
+type BackstageEntityMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Ref returns the entity reference in Backstage format, e.g. "component:default/my-service"
+func (x *BackstageEntity) Ref() string {
+	namespace := x.Metadata.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return strings.ToLower(x.Kind) + ":" + namespace + "/" + x.Metadata.Name
+}
+
+// Repository returns the repository and branch annotated to the entity. The branch is empty if not annotated.
+func (x *BackstageEntity) Repository() (types.GitHubRepoID, types.BranchName) {
+	slug := x.Metadata.Annotations[BackstageAnnotationRepository]
+	if slug == "" {
+		slug = x.Metadata.Annotations[BackstageAnnotationProjectSlug]
+	}
+	return types.GitHubRepoID(strings.TrimSpace(slug)), types.NewBranchName(x.Metadata.Annotations[BackstageAnnotationBranch])
+}
+
+// BackstagePostureInput is input for summarizing security posture of Backstage catalog entities
+type BackstagePostureInput struct {
+	Entities []*BackstageEntity
+
+	// MaxScanAge is how old the last scan can be before it is reported as stale. Zero disables the check.
+	MaxScanAge time.Duration
+}
+
+// BackstagePostureStatus tells whether the posture of an entity is available
+type BackstagePostureStatus string
+
+const (
+	BackstagePostureOK BackstagePostureStatus = "ok"
+	// BackstagePostureUnmapped means the entity has no valid repository annotation
+	BackstagePostureUnmapped BackstagePostureStatus = "unmapped"
+	// BackstagePostureNotScanned means the repository or branch has not been scanned by Octovy
+	BackstagePostureNotScanned BackstagePostureStatus = "not_scanned"
+)
+
+// BackstagePosture is the security posture of an entity, shaped for a Backstage plugin
+type BackstagePosture struct {
+	EntityRef  string                 `json:"entity_ref"`
+	Status     BackstagePostureStatus `json:"status"`
+	Repository string                 `json:"repository,omitempty"`
+	Branch     string                 `json:"branch,omitempty"`
+	Team       string                 `json:"team,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+
+	CommitSHA  string           `json:"commit_sha,omitempty"`
+	ScanStatus types.ScanStatus `json:"scan_status,omitempty"`
+	LastScanAt *time.Time       `json:"last_scan_at,omitempty"`
+	Stale      bool             `json:"stale"`
+	Findings   *FindingSummary  `json:"findings,omitempty"`
+
+	// Links has the shape of Backstage EntityLink so that it can be rendered as is
+	Links []BackstageLink `json:"links,omitempty"`
+}
+
+type BackstageLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Icon  string `json:"icon,omitempty"`
+}
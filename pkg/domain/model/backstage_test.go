@@ -0,0 +1,48 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestBackstageEntity(t *testing.T) {
+	t.Run("ref defaults namespace", func(t *testing.T) {
+		entity := &model.BackstageEntity{Kind: "Component", Metadata: model.BackstageEntityMetadata{Name: "my-service"}}
+		gt.V(t, entity.Ref()).Equal("component:default/my-service")
+	})
+
+	t.Run("octovy annotation takes precedence over project slug", func(t *testing.T) {
+		entity := &model.BackstageEntity{
+			Kind: "Component",
+			Metadata: model.BackstageEntityMetadata{
+				Name:      "my-service",
+				Namespace: "payments",
+				Annotations: map[string]string{
+					model.BackstageAnnotationProjectSlug: "myorg/monorepo",
+					model.BackstageAnnotationRepository:  "myorg/my-service",
+					model.BackstageAnnotationBranch:      "refs/heads/release",
+				},
+			},
+		}
+		repoID, branch := entity.Repository()
+		gt.V(t, repoID).Equal(types.GitHubRepoID("myorg/my-service"))
+		gt.V(t, branch).Equal(types.BranchName("release"))
+		gt.V(t, entity.Ref()).Equal("component:payments/my-service")
+	})
+
+	t.Run("project slug", func(t *testing.T) {
+		entity := &model.BackstageEntity{
+			Kind: "Component",
+			Metadata: model.BackstageEntityMetadata{
+				Name:        "my-service",
+				Annotations: map[string]string{model.BackstageAnnotationProjectSlug: "myorg/monorepo"},
+			},
+		}
+		repoID, branch := entity.Repository()
+		gt.V(t, repoID).Equal(types.GitHubRepoID("myorg/monorepo"))
+		gt.V(t, branch).Equal(types.BranchName(""))
+	})
+}
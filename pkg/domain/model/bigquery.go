@@ -1 +1,197 @@
 package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ScanSummaryRow is a scan record without the Trivy report, stored in the scan table in normalized insert mode. Its columns are compatible with Scan so that raw and normalized records can coexist in one table.
+type ScanSummaryRow struct {
+	ID        types.ScanID `bigquery:"id" json:"id"`
+	Timestamp time.Time    `bigquery:"timestamp" json:"-"`
+	// TimestampMicro is the JSON representation of Timestamp because the Storage Write API accepts TIMESTAMP as UNIX microseconds
+	TimestampMicro int64          `bigquery:"-" json:"timestamp"`
+	GitHub         GitHubMetadata `bigquery:"github" json:"github"`
+	// Owner and RepoName are copies of GitHub.Owner and GitHub.RepoName as top-level columns to cluster the table by
+	Owner    string `bigquery:"owner" json:"owner"`
+	RepoName string `bigquery:"repo_name" json:"repo_name"`
+
+	ArtifactName          string `bigquery:"artifact_name" json:"artifact_name"`
+	ArtifactType          string `bigquery:"artifact_type" json:"artifact_type"`
+	TargetCount           int    `bigquery:"target_count" json:"target_count"`
+	VulnerabilityCount    int    `bigquery:"vulnerability_count" json:"vulnerability_count"`
+	PackageCount          int    `bigquery:"package_count" json:"package_count"`
+	MisconfigurationCount int    `bigquery:"misconfiguration_count" json:"misconfiguration_count"`
+	SecretCount           int    `bigquery:"secret_count" json:"secret_count"`
+	// TrivyVersion is the version of Trivy which created the report, or empty for other scanners
+	TrivyVersion string `bigquery:"trivy_version" json:"trivy_version,omitempty"`
+
+	ImageAnalysis *ImageAnalysis   `bigquery:"image_analysis" json:"image_analysis,omitempty"`
+	Coverage      *ScanCoverage    `bigquery:"coverage" json:"coverage,omitempty"`
+	Incremental   *IncrementalScan `bigquery:"incremental" json:"incremental,omitempty"`
+	Archive       *ScanArchive     `bigquery:"archive" json:"archive,omitempty"`
+}
+
+// ScanRowKey is embedded in vulnerability and package rows to join them with ScanSummaryRow by scan ID.
+type ScanRowKey struct {
+	ScanID         types.ScanID `bigquery:"scan_id" json:"scan_id"`
+	Timestamp      time.Time    `bigquery:"timestamp" json:"-"`
+	TimestampMicro int64        `bigquery:"-" json:"timestamp"`
+	Owner          string       `bigquery:"owner" json:"owner"`
+	RepoName       string       `bigquery:"repo_name" json:"repo_name"`
+	Branch         string       `bigquery:"branch" json:"branch"`
+	CommitID       string       `bigquery:"commit_id" json:"commit_id"`
+	Target         string       `bigquery:"target" json:"target"`
+	Class          string       `bigquery:"class" json:"class"`
+	Type           string       `bigquery:"type" json:"type"`
+}
+
+// VulnerabilityRow is one detected vulnerability, stored in the vulnerability table in normalized insert mode.
+type VulnerabilityRow struct {
+	ScanRowKey
+
+	VulnerabilityID  string   `bigquery:"vulnerability_id" json:"vulnerability_id"`
+	PkgID            string   `bigquery:"pkg_id" json:"pkg_id"`
+	PkgName          string   `bigquery:"pkg_name" json:"pkg_name"`
+	PkgPath          string   `bigquery:"pkg_path" json:"pkg_path"`
+	PURL             string   `bigquery:"purl" json:"purl"`
+	InstalledVersion string   `bigquery:"installed_version" json:"installed_version"`
+	FixedVersion     string   `bigquery:"fixed_version" json:"fixed_version"`
+	Status           string   `bigquery:"status" json:"status"`
+	Severity         string   `bigquery:"severity" json:"severity"`
+	SeveritySource   string   `bigquery:"severity_source" json:"severity_source"`
+	DataSource       string   `bigquery:"data_source" json:"data_source"`
+	Title            string   `bigquery:"title" json:"title"`
+	PrimaryURL       string   `bigquery:"primary_url" json:"primary_url"`
+	CweIDs           []string `bigquery:"cwe_ids" json:"cwe_ids"`
+	PublishedDate    string   `bigquery:"published_date" json:"published_date"`
+	LastModifiedDate string   `bigquery:"last_modified_date" json:"last_modified_date"`
+}
+
+// PackageRow is one detected package, stored in the package table in normalized insert mode.
+type PackageRow struct {
+	ScanRowKey
+
+	PkgID        string   `bigquery:"pkg_id" json:"pkg_id"`
+	Name         string   `bigquery:"name" json:"name"`
+	Version      string   `bigquery:"version" json:"version"`
+	Release      string   `bigquery:"release" json:"release"`
+	PURL         string   `bigquery:"purl" json:"purl"`
+	Licenses     []string `bigquery:"licenses" json:"licenses"`
+	Indirect     bool     `bigquery:"indirect" json:"indirect"`
+	Relationship string   `bigquery:"relationship" json:"relationship"`
+	FilePath     string   `bigquery:"file_path" json:"file_path"`
+}
+
+// NormalizedScan is a scan fanned out into rows of separate tables
+type NormalizedScan struct {
+	Summary         *ScanSummaryRow
+	Vulnerabilities []*VulnerabilityRow
+	Packages        []*PackageRow
+}
+
+// NewNormalizedScan fans out the Trivy report of scan into one row per vulnerability and package.
+func NewNormalizedScan(scan *Scan) *NormalizedScan {
+	summary := &ScanSummaryRow{
+		ID:             scan.ID,
+		Timestamp:      scan.Timestamp,
+		TimestampMicro: scan.Timestamp.UnixMicro(),
+		Owner:          scan.GitHub.Owner,
+		RepoName:       scan.GitHub.RepoName,
+		GitHub:         scan.GitHub,
+		ArtifactName:   scan.Report.ArtifactName,
+		ArtifactType:   string(scan.Report.ArtifactType),
+		TargetCount:    len(scan.Report.Results),
+		TrivyVersion:   scan.Report.TrivyVersion(),
+		ImageAnalysis:  scan.ImageAnalysis,
+		Coverage:       scan.Coverage,
+		Incremental:    scan.Incremental,
+		Archive:        scan.Archive,
+	}
+	result := &NormalizedScan{Summary: summary}
+
+	for _, res := range scan.Report.Results {
+		key := ScanRowKey{
+			ScanID:         scan.ID,
+			Timestamp:      scan.Timestamp,
+			TimestampMicro: scan.Timestamp.UnixMicro(),
+			Owner:          scan.GitHub.Owner,
+			RepoName:       scan.GitHub.RepoName,
+			Branch:         scan.GitHub.Branch,
+			CommitID:       scan.GitHub.CommitID,
+			Target:         res.Target,
+			Class:          string(res.Class),
+			Type:           res.Type,
+		}
+
+		for _, vuln := range res.Vulnerabilities {
+			result.Vulnerabilities = append(result.Vulnerabilities, newVulnerabilityRow(key, vuln))
+		}
+		for _, pkg := range res.Packages {
+			result.Packages = append(result.Packages, newPackageRow(key, pkg))
+		}
+
+		summary.MisconfigurationCount += len(res.Misconfigurations)
+		summary.SecretCount += len(res.Secrets)
+	}
+
+	summary.VulnerabilityCount = len(result.Vulnerabilities)
+	summary.PackageCount = len(result.Packages)
+
+	return result
+}
+
+func newVulnerabilityRow(key ScanRowKey, vuln trivy.DetectedVulnerability) *VulnerabilityRow {
+	var dataSource string
+	if vuln.DataSource != nil {
+		dataSource = string(vuln.DataSource.ID)
+	}
+
+	return &VulnerabilityRow{
+		ScanRowKey:       key,
+		VulnerabilityID:  vuln.VulnerabilityID,
+		PkgID:            vuln.PkgID,
+		PkgName:          vuln.PkgName,
+		PkgPath:          vuln.PkgPath,
+		PURL:             vulnerabilityRowPURL(key.Type, &vuln),
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Status:           vuln.Status,
+		Severity:         vuln.Severity,
+		SeveritySource:   string(vuln.SeveritySource),
+		DataSource:       dataSource,
+		Title:            vuln.Title,
+		PrimaryURL:       vuln.PrimaryURL,
+		CweIDs:           vuln.CweIDs,
+		PublishedDate:    vuln.PublishedDate,
+		LastModifiedDate: vuln.LastModifiedDate,
+	}
+}
+
+// vulnerabilityRowPURL returns the same package URL as Vulnerability of Firestore, so that rows can be joined with findings
+func vulnerabilityRowPURL(ecosystem string, vuln *trivy.DetectedVulnerability) string {
+	if purl := detectedPURL(vuln); purl != "" {
+		return purl
+	}
+	return NewPURL(ecosystem, vuln.PkgName, vuln.InstalledVersion)
+}
+
+func newPackageRow(key ScanRowKey, pkg trivy.Package) *PackageRow {
+	row := &PackageRow{
+		ScanRowKey:   key,
+		PkgID:        pkg.ID,
+		Name:         pkg.Name,
+		Version:      pkg.Version,
+		Release:      pkg.Release,
+		Licenses:     pkg.Licenses,
+		Indirect:     pkg.Indirect,
+		Relationship: pkg.Relationship,
+		FilePath:     pkg.FilePath,
+	}
+	if pkg.Identifier != nil {
+		row.PURL = NormalizePURL(pkg.Identifier.PURL)
+	}
+	return row
+}
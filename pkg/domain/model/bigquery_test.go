@@ -0,0 +1,91 @@
+package model_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestNewNormalizedScan(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	scan := &model.Scan{
+		ID:        types.ScanID("scan-1"),
+		Timestamp: ts,
+		GitHub: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "octo", RepoName: "repo"},
+				Branch:     "main",
+				CommitID:   "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+		Report: trivy.Report{
+			ArtifactName: "repo",
+			ArtifactType: "repository",
+			Trivy:        &trivy.TrivyInfo{Version: "0.58.1"},
+			Results: trivy.Results{
+				{
+					Target: "go.mod",
+					Class:  "lang-pkgs",
+					Type:   "gomod",
+					Packages: []trivy.Package{
+						{ID: "a@1.0.0", Name: "a", Version: "1.0.0", Identifier: &trivy.PackageIdentifier{PURL: "pkg:golang/a@1.0.0"}},
+					},
+					Vulnerabilities: []trivy.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2024-0001",
+							PkgName:          "a",
+							InstalledVersion: "1.0.0",
+							FixedVersion:     "1.0.1",
+							Vulnerability:    trivy.Vulnerability{Severity: "HIGH", CweIDs: []string{"CWE-79"}},
+						},
+					},
+				},
+				{
+					Target:            "Dockerfile",
+					Misconfigurations: []trivy.DetectedMisconfiguration{{ID: "DS001"}},
+					Secrets:           []trivy.SecretFinding{{RuleID: "aws-access-key-id"}},
+				},
+			},
+		},
+	}
+
+	normalized := model.NewNormalizedScan(scan)
+
+	gt.V(t, normalized.Summary.ID).Equal(scan.ID)
+	gt.V(t, normalized.Summary.TimestampMicro).Equal(ts.UnixMicro())
+	gt.V(t, normalized.Summary.TargetCount).Equal(2)
+	gt.V(t, normalized.Summary.VulnerabilityCount).Equal(1)
+	gt.V(t, normalized.Summary.PackageCount).Equal(1)
+	gt.V(t, normalized.Summary.MisconfigurationCount).Equal(1)
+	gt.V(t, normalized.Summary.SecretCount).Equal(1)
+	gt.V(t, normalized.Summary.TrivyVersion).Equal("0.58.1")
+
+	gt.A(t, normalized.Vulnerabilities).Length(1)
+	vuln := normalized.Vulnerabilities[0]
+	gt.V(t, vuln.ScanID).Equal(scan.ID)
+	gt.V(t, vuln.Owner).Equal("octo")
+	gt.V(t, vuln.Target).Equal("go.mod")
+	gt.V(t, vuln.Class).Equal("lang-pkgs")
+	gt.V(t, vuln.Severity).Equal("HIGH")
+	gt.V(t, vuln.FixedVersion).Equal("1.0.1")
+	gt.A(t, vuln.CweIDs).Equal([]string{"CWE-79"})
+	// Built from the result type because the vulnerability has no package identifier
+	gt.V(t, vuln.PURL).Equal("pkg:golang/a@1.0.0")
+
+	gt.A(t, normalized.Packages).Length(1)
+	pkg := normalized.Packages[0]
+	gt.V(t, pkg.Name).Equal("a")
+	gt.V(t, pkg.PURL).Equal("pkg:golang/a@1.0.0")
+	gt.V(t, pkg.CommitID).Equal("0123456789abcdef0123456789abcdef01234567")
+
+	t.Run("timestamp is marshaled as UNIX microseconds for Storage Write API", func(t *testing.T) {
+		raw := gt.R1(json.Marshal(pkg)).NoError(t)
+		gt.S(t, string(raw)).Contains(`"timestamp":` + strconv.FormatInt(ts.UnixMicro(), 10))
+	})
+}
@@ -12,7 +12,36 @@ type Branch struct {
 	LastScanID    types.ScanID
 	LastScanAt    time.Time
 	LastCommitSHA types.CommitSHA
-	Status        types.ScanStatus
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// LastScanStartedAt is when the scan of LastScanID started. A scan started before it is stale and its result is not stored, see ScanRepository.ClaimBranchScan.
+	LastScanStartedAt time.Time
+	Status            types.ScanStatus
+	// LastError and LastErrorAt describe the last failed scan while Status is failure. If the scan failed before storing its result, e.g. in download or Trivy, LastScanID, LastScanAt and LastCommitSHA keep the last successful scan.
+	LastError   string
+	LastErrorAt time.Time
+	// Findings is the number of active findings of the branch, counted when a scan is stored or a status is changed so that listing branches does not read all findings. It is nil while the scan is being stored, and for branches stored before it was introduced.
+	Findings  *FindingSummary
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RepositoryBranchStatus is scan status of all scanned branches of a repository
+type RepositoryBranchStatus struct {
+	Repository    string          `json:"repository"`
+	DefaultBranch string          `json:"default_branch,omitempty"`
+	Permalink     string          `json:"permalink,omitempty"`
+	Branches      []*BranchStatus `json:"branches"`
+}
+
+// BranchStatus is the last scan of a branch and its active findings
+type BranchStatus struct {
+	Name       string           `json:"name"`
+	Default    bool             `json:"default"`
+	LastScanID string           `json:"last_scan_id"`
+	LastScanAt time.Time        `json:"last_scan_at"`
+	CommitSHA  string           `json:"commit_sha"`
+	Status     types.ScanStatus `json:"status"`
+	Error      string           `json:"error,omitempty"`
+	ErrorAt    *time.Time       `json:"error_at,omitempty"`
+	Findings   *FindingSummary  `json:"findings"`
+	Permalink  string           `json:"permalink,omitempty"`
 }
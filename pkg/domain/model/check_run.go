@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// MaxCheckAnnotations is the max number of annotations of a check run accepted by a request of GitHub API
+const MaxCheckAnnotations = 50
+
+// MisconfigCheckRunName is the name of the check run reporting misconfigurations of a pull request
+const MisconfigCheckRunName = "octovy / misconfigurations"
+
+// CheckAnnotationLevel is the level of an annotation of a check run
+type CheckAnnotationLevel string
+
+const (
+	CheckAnnotationNotice  CheckAnnotationLevel = "notice"
+	CheckAnnotationWarning CheckAnnotationLevel = "warning"
+	CheckAnnotationFailure CheckAnnotationLevel = "failure"
+)
+
+// CheckAnnotation is a finding shown inline in the diff of a pull request. Path is relative to the repository root.
+type CheckAnnotation struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Level     CheckAnnotationLevel
+	Title     string
+	Message   string
+	severity  types.Severity
+}
+
+// MisconfigCheck is failed misconfiguration checks of a scan reported as a check run of the pull request. Annotations are the most severe MaxCheckAnnotations of them.
+type MisconfigCheck struct {
+	Total       int
+	Annotations []*CheckAnnotation
+}
+
+// NewMisconfigCheck collects failed misconfiguration checks in config results of the report
+func NewMisconfigCheck(report *trivy.Report) *MisconfigCheck {
+	var annotations []*CheckAnnotation
+	for _, result := range report.Results {
+		if result.Class != trivy.ClassConfig {
+			continue
+		}
+		for _, misconf := range result.Misconfigurations {
+			if misconf.Status != trivy.MisconfStatusFailure {
+				continue
+			}
+			annotations = append(annotations, newMisconfigAnnotation(result.Target, &misconf))
+		}
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool {
+		a, b := annotations[i], annotations[j]
+		if a.severity != b.severity {
+			return a.severity.Rank() > b.severity.Rank()
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.StartLine < b.StartLine
+	})
+
+	check := &MisconfigCheck{Total: len(annotations)}
+	if len(annotations) > MaxCheckAnnotations {
+		annotations = annotations[:MaxCheckAnnotations]
+	}
+	check.Annotations = annotations
+	return check
+}
+
+func newMisconfigAnnotation(target string, misconf *trivy.DetectedMisconfiguration) *CheckAnnotation {
+	severity, err := types.ParseSeverity(misconf.Severity)
+	if err != nil {
+		severity = types.SeverityUnknown
+	}
+
+	// A check of the whole file has no lines, and GitHub requires a line to annotate
+	start := max(misconf.CauseMetadata.StartLine, 1)
+	end := max(misconf.CauseMetadata.EndLine, start)
+
+	id := misconf.AVDID
+	if id == "" {
+		id = misconf.ID
+	}
+	message := misconf.Message
+	if message == "" {
+		message = misconf.Description
+	}
+	if misconf.Resolution != "" {
+		message += "\n\nResolution: " + misconf.Resolution
+	}
+	if misconf.PrimaryURL != "" {
+		message += "\n" + misconf.PrimaryURL
+	}
+
+	return &CheckAnnotation{
+		Path:      target,
+		StartLine: start,
+		EndLine:   end,
+		Level:     checkAnnotationLevel(severity),
+		Title:     fmt.Sprintf("[%s] %s: %s", severity, id, misconf.Title),
+		Message:   strings.TrimSpace(message),
+		severity:  severity,
+	}
+}
+
+func checkAnnotationLevel(severity types.Severity) CheckAnnotationLevel {
+	switch severity {
+	case types.SeverityCritical, types.SeverityHigh:
+		return CheckAnnotationFailure
+	case types.SeverityMedium:
+		return CheckAnnotationWarning
+	default:
+		return CheckAnnotationNotice
+	}
+}
+
+// Conclusion of the check run. Misconfigurations do not fail the check, so that blocking merges is left to the gate API.
+func (x *MisconfigCheck) Conclusion() string {
+	if x.Total == 0 {
+		return "success"
+	}
+	return "neutral"
+}
+
+// Title of the check run
+func (x *MisconfigCheck) Title() string {
+	switch x.Total {
+	case 0:
+		return "No misconfiguration found"
+	case 1:
+		return "1 misconfiguration found"
+	default:
+		return fmt.Sprintf("%d misconfigurations found", x.Total)
+	}
+}
+
+// Summary of the check run in markdown
+func (x *MisconfigCheck) Summary() string {
+	if x.Total == 0 {
+		return "Trivy found no failed misconfiguration check in configuration files of the pull request."
+	}
+
+	summary := fmt.Sprintf("Trivy found %d failed misconfiguration checks in configuration files such as Terraform, Kubernetes manifests and Dockerfiles.", x.Total)
+	if omitted := x.Total - len(x.Annotations); omitted > 0 {
+		summary += fmt.Sprintf(" %d less severe ones are not annotated because of the limit of GitHub.", omitted)
+	}
+	return summary
+}
@@ -0,0 +1,79 @@
+package model_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestNewMisconfigCheck(t *testing.T) {
+	misconf := func(id, severity string, status trivy.MisconfStatus, start int) trivy.DetectedMisconfiguration {
+		return trivy.DetectedMisconfiguration{
+			AVDID:         id,
+			Title:         "title of " + id,
+			Severity:      severity,
+			Status:        status,
+			CauseMetadata: trivy.CauseMetadata{StartLine: start},
+		}
+	}
+
+	t.Run("failed checks of config results are annotated by severity", func(t *testing.T) {
+		check := model.NewMisconfigCheck(&trivy.Report{Results: trivy.Results{
+			{Target: "k8s/deploy.yaml", Class: trivy.ClassConfig, Misconfigurations: []trivy.DetectedMisconfiguration{
+				misconf("AVD-KSV-0001", "MEDIUM", trivy.MisconfStatusFailure, 10),
+				misconf("AVD-KSV-0002", "LOW", trivy.MisconfStatusPassed, 12),
+			}},
+			{Target: "Dockerfile", Class: trivy.ClassConfig, Misconfigurations: []trivy.DetectedMisconfiguration{
+				misconf("AVD-DS-0002", "HIGH", trivy.MisconfStatusFailure, 0),
+				misconf("AVD-DS-0026", "LOW", trivy.MisconfStatusFailure, 0),
+			}},
+			{Target: "go.mod", Class: trivy.ClassLangPkg},
+		}})
+
+		gt.V(t, check.Total).Equal(3)
+		gt.V(t, check.Conclusion()).Equal("neutral")
+		gt.V(t, check.Title()).Equal("3 misconfigurations found")
+		gt.A(t, check.Annotations).Length(3).
+			At(0, func(t testing.TB, v *model.CheckAnnotation) {
+				gt.V(t, v.Path).Equal("Dockerfile")
+				gt.V(t, v.Level).Equal(model.CheckAnnotationFailure)
+				// A check of the whole file is annotated at the first line
+				gt.V(t, v.StartLine).Equal(1)
+				gt.V(t, v.EndLine).Equal(1)
+			}).
+			At(1, func(t testing.TB, v *model.CheckAnnotation) {
+				gt.V(t, v.Path).Equal("k8s/deploy.yaml")
+				gt.V(t, v.Level).Equal(model.CheckAnnotationWarning)
+				gt.V(t, v.StartLine).Equal(10)
+			}).
+			At(2, func(t testing.TB, v *model.CheckAnnotation) {
+				gt.V(t, v.Level).Equal(model.CheckAnnotationNotice)
+			})
+	})
+
+	t.Run("annotations are limited", func(t *testing.T) {
+		var misconfs []trivy.DetectedMisconfiguration
+		for i := range model.MaxCheckAnnotations + 10 {
+			misconfs = append(misconfs, misconf(fmt.Sprintf("AVD-AWS-%04d", i), "LOW", trivy.MisconfStatusFailure, i+1))
+		}
+		misconfs = append(misconfs, misconf("AVD-AWS-9999", "CRITICAL", trivy.MisconfStatusFailure, 100))
+
+		check := model.NewMisconfigCheck(&trivy.Report{Results: trivy.Results{
+			{Target: "main.tf", Class: trivy.ClassConfig, Misconfigurations: misconfs},
+		}})
+		gt.V(t, check.Total).Equal(model.MaxCheckAnnotations + 11)
+		gt.A(t, check.Annotations).Length(model.MaxCheckAnnotations).At(0, func(t testing.TB, v *model.CheckAnnotation) {
+			gt.S(t, v.Title).Contains("AVD-AWS-9999")
+		})
+		gt.S(t, check.Summary()).Contains("11 less severe ones are not annotated")
+	})
+
+	t.Run("no misconfiguration", func(t *testing.T) {
+		check := model.NewMisconfigCheck(&trivy.Report{})
+		gt.V(t, check.Conclusion()).Equal("success")
+		gt.A(t, check.Annotations).Length(0)
+	})
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"path"
+	"sort"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+// MaxUnscannedManifests limits manifest files listed in ScanCoverage.Unscanned so that a large monorepo does not bloat the scan record
+const MaxUnscannedManifests = 100
+
+// MaxSuppressedFindings limits findings listed in ScanCoverage.Suppressed as well as MaxUnscannedManifests
+const MaxSuppressedFindings = 100
+
+// manifestFileNames are names of dependency manifests and lockfiles which scanners are expected to report as targets
+var manifestFileNames = map[string]bool{
+	"go.mod":             true,
+	"package-lock.json":  true,
+	"yarn.lock":          true,
+	"pnpm-lock.yaml":     true,
+	"bun.lock":           true,
+	"Gemfile.lock":       true,
+	"Cargo.lock":         true,
+	"composer.lock":      true,
+	"Pipfile.lock":       true,
+	"poetry.lock":        true,
+	"uv.lock":            true,
+	"requirements.txt":   true,
+	"pom.xml":            true,
+	"gradle.lockfile":    true,
+	"packages.lock.json": true,
+	"packages.config":    true,
+	"conan.lock":         true,
+	"Podfile.lock":       true,
+	"Package.resolved":   true,
+	"pubspec.lock":       true,
+	"mix.lock":           true,
+}
+
+// IsManifestFile returns true if the file name looks like a dependency manifest or lockfile
+func IsManifestFile(name string) bool {
+	return manifestFileNames[path.Base(name)]
+}
+
+// ScanCoverage describes what a scan covered, so that questions such as "why is this lockfile not scanned" can be answered from the stored scan record instead of running the scanner again with debug logs.
+type ScanCoverage struct {
+	// Targets is the number of targets in the report by result class and type
+	Targets []*ScanTargetStats `bigquery:"targets" json:"targets"`
+	// Manifests is the number of files in the scanned directory which look like dependency manifests, except paths skipped by the scan config
+	Manifests int `bigquery:"manifests" json:"manifests"`
+	// ScannedManifests is the number of the manifest files reported as targets
+	ScannedManifests int `bigquery:"scanned_manifests" json:"scanned_manifests"`
+	// Unscanned are manifest files not reported as targets, e.g. lockfiles of an unsupported version. At most MaxUnscannedManifests files are listed.
+	Unscanned []string `bigquery:"unscanned" json:"unscanned,omitempty"`
+
+	// IgnoreFile is the ignore file of Trivy in the repository passed to Trivy, e.g. ".trivyignore", relative to the scanned directory
+	IgnoreFile string `bigquery:"ignore_file" json:"ignore_file,omitempty"`
+	// SuppressedFindings is the number of findings suppressed by the ignore file, or by VEX given with --trivy-arg
+	SuppressedFindings int `bigquery:"suppressed_findings" json:"suppressed_findings"`
+	// Suppressed are the suppressed findings. At most MaxSuppressedFindings findings are listed.
+	Suppressed []*SuppressedFinding `bigquery:"suppressed" json:"suppressed,omitempty"`
+}
+
+// SuppressedFinding is a finding which the scanner found but did not report because of an ignore file or VEX
+type SuppressedFinding struct {
+	Target string `bigquery:"target" json:"target"`
+	// Type is one of "vulnerability", "misconfiguration", "secret" and "license"
+	Type      string `bigquery:"type" json:"type"`
+	ID        string `bigquery:"id" json:"id"`
+	PkgName   string `bigquery:"pkg_name" json:"pkg_name,omitempty"`
+	Status    string `bigquery:"status" json:"status"`
+	Statement string `bigquery:"statement" json:"statement,omitempty"`
+	Source    string `bigquery:"source" json:"source,omitempty"`
+}
+
+type ScanTargetStats struct {
+	Class string `bigquery:"class" json:"class"`
+	Type  string `bigquery:"type" json:"type"`
+	Count int    `bigquery:"count" json:"count"`
+}
+
+// NewScanCoverage compares manifest files with targets of the report. manifests are paths relative to the scanned directory.
+func NewScanCoverage(report *trivy.Report, manifests []string) *ScanCoverage {
+	coverage := &ScanCoverage{Manifests: len(manifests)}
+
+	targets := make(map[string]bool, len(report.Results))
+	stats := map[[2]string]*ScanTargetStats{}
+	for _, result := range report.Results {
+		targets[result.Target] = true
+
+		key := [2]string{string(result.Class), result.Type}
+		if _, ok := stats[key]; !ok {
+			stats[key] = &ScanTargetStats{Class: key[0], Type: key[1]}
+			coverage.Targets = append(coverage.Targets, stats[key])
+		}
+		stats[key].Count++
+
+		for _, modified := range result.ModifiedFindings {
+			coverage.SuppressedFindings++
+			if len(coverage.Suppressed) < MaxSuppressedFindings {
+				coverage.Suppressed = append(coverage.Suppressed, &SuppressedFinding{
+					Target:    result.Target,
+					Type:      modified.Type,
+					ID:        modified.FindingID(),
+					PkgName:   modified.Finding.PkgName,
+					Status:    modified.Status,
+					Statement: modified.Statement,
+					Source:    modified.Source,
+				})
+			}
+		}
+	}
+	sort.Slice(coverage.Targets, func(i, j int) bool {
+		if coverage.Targets[i].Class != coverage.Targets[j].Class {
+			return coverage.Targets[i].Class < coverage.Targets[j].Class
+		}
+		return coverage.Targets[i].Type < coverage.Targets[j].Type
+	})
+
+	sorted := append([]string{}, manifests...)
+	sort.Strings(sorted)
+	for _, manifest := range sorted {
+		if targets[manifest] {
+			coverage.ScannedManifests++
+			continue
+		}
+		if len(coverage.Unscanned) < MaxUnscannedManifests {
+			coverage.Unscanned = append(coverage.Unscanned, manifest)
+		}
+	}
+
+	return coverage
+}
+
+// UnscannedManifests returns the number of manifest files not reported as targets, including ones not listed in Unscanned
+func (x *ScanCoverage) UnscannedManifests() int {
+	if x == nil {
+		return 0
+	}
+	return x.Manifests - x.ScannedManifests
+}
@@ -0,0 +1,73 @@
+package model_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestNewScanCoverage(t *testing.T) {
+	report := &trivy.Report{
+		Results: trivy.Results{
+			{Target: "go.mod", Class: "lang-pkgs", Type: "gomod"},
+			{Target: "web/package-lock.json", Class: "lang-pkgs", Type: "npm"},
+			{Target: "api/go.mod", Class: "lang-pkgs", Type: "gomod"},
+			{Target: "Dockerfile", Class: "config", Type: "dockerfile"},
+		},
+	}
+
+	coverage := model.NewScanCoverage(report, []string{"web/yarn.lock", "go.mod", "api/go.mod", "legacy/Pipfile.lock"})
+	gt.V(t, coverage.Manifests).Equal(4)
+	gt.V(t, coverage.ScannedManifests).Equal(2)
+	gt.V(t, coverage.UnscannedManifests()).Equal(2)
+	gt.A(t, coverage.Unscanned).Equal([]string{"legacy/Pipfile.lock", "web/yarn.lock"})
+	gt.A(t, coverage.Targets).Length(3)
+	gt.V(t, *coverage.Targets[0]).Equal(model.ScanTargetStats{Class: "config", Type: "dockerfile", Count: 1})
+	gt.V(t, *coverage.Targets[1]).Equal(model.ScanTargetStats{Class: "lang-pkgs", Type: "gomod", Count: 2})
+	gt.V(t, *coverage.Targets[2]).Equal(model.ScanTargetStats{Class: "lang-pkgs", Type: "npm", Count: 1})
+
+	t.Run("unscanned files are limited", func(t *testing.T) {
+		var manifests []string
+		for i := 0; i < model.MaxUnscannedManifests+10; i++ {
+			manifests = append(manifests, fmt.Sprintf("svc%03d/go.mod", i))
+		}
+		coverage := model.NewScanCoverage(&trivy.Report{}, manifests)
+		gt.A(t, coverage.Unscanned).Length(model.MaxUnscannedManifests)
+		gt.V(t, coverage.UnscannedManifests()).Equal(model.MaxUnscannedManifests + 10)
+	})
+
+	t.Run("suppressed findings", func(t *testing.T) {
+		// ExperimentalModifiedFindings as reported by trivy fs --show-suppressed
+		raw := `{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","ExperimentalModifiedFindings":[
+			{"Type":"vulnerability","Status":"ignored","Statement":"not reachable","Source":"/tmp/repo/.trivyignore","Finding":{"VulnerabilityID":"CVE-2024-0001","PkgName":"golang.org/x/net","Severity":"HIGH"}},
+			{"Type":"secret","Status":"ignored","Source":"/tmp/repo/.trivyignore","Finding":{"RuleID":"aws-access-key-id","Severity":"CRITICAL"}}
+		]}`
+		var result trivy.Result
+		gt.NoError(t, json.Unmarshal([]byte(raw), &result))
+
+		coverage := model.NewScanCoverage(&trivy.Report{Results: trivy.Results{result}}, nil)
+		gt.V(t, coverage.SuppressedFindings).Equal(2)
+		gt.A(t, coverage.Suppressed).Length(2)
+		gt.V(t, *coverage.Suppressed[0]).Equal(model.SuppressedFinding{
+			Target:    "go.mod",
+			Type:      "vulnerability",
+			ID:        "CVE-2024-0001",
+			PkgName:   "golang.org/x/net",
+			Status:    "ignored",
+			Statement: "not reachable",
+			Source:    "/tmp/repo/.trivyignore",
+		})
+		gt.V(t, coverage.Suppressed[1].ID).Equal("aws-access-key-id")
+	})
+
+	t.Run("manifest file names", func(t *testing.T) {
+		gt.True(t, model.IsManifestFile("services/api/go.mod"))
+		gt.True(t, model.IsManifestFile("Gemfile.lock"))
+		gt.False(t, model.IsManifestFile("go.sum"))
+		gt.False(t, model.IsManifestFile("main.go"))
+	})
+}
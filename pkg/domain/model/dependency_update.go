@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// dependencyUpdateBots are logins of GitHub Apps opening dependency update pull requests
+var dependencyUpdateBots = map[string]bool{
+	"dependabot[bot]":    true,
+	"renovate[bot]":      true,
+	"mend-renovate[bot]": true,
+}
+
+// IsDependencyUpdateBot returns true if the login is a bot opening dependency update pull requests, i.e. Dependabot or Renovate
+func IsDependencyUpdateBot(login string) bool {
+	return dependencyUpdateBots[login]
+}
+
+// DependencyUpdateFinding is a vulnerability of a package in a scan target
+type DependencyUpdateFinding struct {
+	Target           string
+	VulnID           string
+	PkgName          string
+	InstalledVersion string
+	Severity         string
+	// FixedVersion is versions fixing the vulnerability given by the scanner, and Ecosystem is the result type of the target, e.g. "gomod", to suggest an upgrade
+	FixedVersion string
+	Ecosystem    string
+	// Status is the stored status of the vulnerability, or empty if the finding is of a report which is not stored yet
+	Status types.VulnStatus
+}
+
+// DependencyUpdateAssessment compares vulnerabilities of a dependency update pull request with its base branch. Fixed are vulnerabilities active in the base branch and not detected in the pull request, and Introduced are the opposite.
+type DependencyUpdateAssessment struct {
+	RepoID      types.GitHubRepoID
+	PullRequest int
+	Author      string
+	BaseBranch  types.BranchName
+	CommitSHA   types.CommitSHA
+	Fixed       []*DependencyUpdateFinding
+	Introduced  []*DependencyUpdateFinding
+}
+
+// RiskReducing returns true if the update fixes at least one vulnerability and introduces none
+func (x *DependencyUpdateAssessment) RiskReducing() bool {
+	return len(x.Fixed) > 0 && len(x.Introduced) == 0
+}
+
+func (x *DependencyUpdateAssessment) LogValue() slog.Value {
+	format := func(findings []*DependencyUpdateFinding) []string {
+		out := make([]string, len(findings))
+		for i, f := range findings {
+			out[i] = fmt.Sprintf("%s %s@%s (%s) in %s", f.VulnID, f.PkgName, f.InstalledVersion, f.Severity, f.Target)
+		}
+		return out
+	}
+
+	return slog.GroupValue(
+		slog.Any("repo_id", x.RepoID),
+		slog.Int("pull_request", x.PullRequest),
+		slog.String("author", x.Author),
+		slog.Any("base_branch", x.BaseBranch),
+		slog.Any("commit", x.CommitSHA),
+		slog.Int("fixed_count", len(x.Fixed)),
+		slog.Int("introduced_count", len(x.Introduced)),
+		slog.Bool("risk_reducing", x.RiskReducing()),
+		slog.Any("fixed", format(x.Fixed)),
+		slog.Any("introduced", format(x.Introduced)),
+	)
+}
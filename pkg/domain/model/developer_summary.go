@@ -0,0 +1,77 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// SeverityCounts is the number of findings per severity
+type SeverityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+	Total    int `json:"total"`
+}
+
+// Add counts a finding of the severity. An unrecognized severity is counted as unknown.
+func (x *SeverityCounts) Add(severity string) {
+	switch types.Severity(strings.ToUpper(severity)) {
+	case types.SeverityCritical:
+		x.Critical++
+	case types.SeverityHigh:
+		x.High++
+	case types.SeverityMedium:
+		x.Medium++
+	case types.SeverityLow:
+		x.Low++
+	default:
+		x.Unknown++
+	}
+	x.Total++
+}
+
+// FindingSummary is the number of active findings in a branch. Acknowledged and fixed findings are not counted.
+type FindingSummary struct {
+	Vulnerabilities   SeverityCounts `json:"vulnerabilities"`
+	Misconfigurations int            `json:"misconfigurations"`
+	Secrets           int            `json:"secrets"`
+}
+
+// DeveloperSummaryInput is input for summarizing repositories a GitHub user recently committed to
+type DeveloperSummaryInput struct {
+	Login string
+	// Period is how far back commits are looked up
+	Period time.Duration
+	// Tag and Team filter repositories by their metadata. Empty means no filter.
+	Tag  string
+	Team string
+}
+
+// DeveloperSummary is vulnerability summary of repositories a GitHub user recently committed to
+type DeveloperSummary struct {
+	Login        string                  `json:"login"`
+	Since        time.Time               `json:"since"`
+	Repositories []*DeveloperRepoSummary `json:"repositories"`
+}
+
+// DeveloperRepoSummary is a repository the user committed to. Findings are of the default branch, which is what the commits are eventually shipped from.
+type DeveloperRepoSummary struct {
+	Repository    string    `json:"repository"`
+	Owner         string    `json:"owner"`
+	Name          string    `json:"name"`
+	URL           string    `json:"url"`
+	Permalink     string    `json:"permalink,omitempty"`
+	Branches      []string  `json:"branches"`
+	LastCommitSHA string    `json:"last_commit_sha"`
+	LastCommitAt  time.Time `json:"last_commit_at"`
+
+	Team          string          `json:"team,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+	DefaultBranch string          `json:"default_branch,omitempty"`
+	LastScanAt    *time.Time      `json:"last_scan_at,omitempty"`
+	Findings      *FindingSummary `json:"findings,omitempty"`
+}
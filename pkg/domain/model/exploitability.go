@@ -0,0 +1,31 @@
+package model
+
+import "strings"
+
+// Exploitability tells how likely a vulnerability is exploited, to prioritize findings beyond CVSS. It is available only for CVEs.
+type Exploitability struct {
+	// EPSSScore is the probability of exploitation activity in the next 30 days by EPSS (https://www.first.org/epss/), from 0 to 1
+	EPSSScore float64 `json:"epss_score"`
+	// EPSSPercentile is the rank of EPSSScore among all scored CVEs, from 0 to 1
+	EPSSPercentile float64 `json:"epss_percentile"`
+	// KnownExploited is true if the CVE is in the CISA Known Exploited Vulnerabilities catalog
+	KnownExploited bool `json:"known_exploited"`
+	// KEVDateAdded and KEVDueDate are dates of the catalog entry in "YYYY-MM-DD" form
+	KEVDateAdded string `json:"kev_date_added,omitempty"`
+	KEVDueDate   string `json:"kev_due_date,omitempty"`
+	// KEVRansomware is true if the CVE is known to be used in ransomware campaigns
+	KEVRansomware bool `json:"kev_ransomware,omitempty"`
+}
+
+// Equal returns true if both have the same data. nil equals only nil.
+func (x *Exploitability) Equal(other *Exploitability) bool {
+	if x == nil || other == nil {
+		return x == other
+	}
+	return *x == *other
+}
+
+// IsCVE returns true if the vulnerability ID is a CVE ID, which EPSS and KEV have data of
+func IsCVE(vulnID string) bool {
+	return strings.HasPrefix(vulnID, "CVE-")
+}
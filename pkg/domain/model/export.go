@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ExportFindingsInput selects stored findings of the owner to export for compliance reporting
+type ExportFindingsInput struct {
+	Owner  string
+	Repo   string           // optional; if not set, all repositories of the owner
+	Branch types.BranchName // optional; if not set, all branches of each repository
+
+	// Since drops findings fixed before it, so that the export has findings open at any time since then. Zero means no filter.
+	Since time.Time
+	// MinSeverity filters findings below the severity out. Empty means no filter.
+	MinSeverity types.Severity
+	// Tag and Team filter repositories by their metadata. Empty means no filter.
+	Tag  string
+	Team string
+}
+
+// ExportedFinding is a row of the export of findings
+type ExportedFinding struct {
+	Repository       string
+	Branch           string
+	DefaultBranch    bool
+	Team             string
+	Target           string
+	Type             types.FindingType
+	ID               string
+	Severity         string
+	Status           types.VulnStatus
+	PkgName          string
+	InstalledVersion string
+	FixedVersion     string
+	Title            string
+	DetectedAt       time.Time
+	// FixedAt is when the finding was last marked as fixed, or zero if it is not fixed
+	FixedAt   time.Time
+	Permalink string
+}
+
+// ExportColumns are headers of the columns of ExportedFinding.Row
+var ExportColumns = []string{
+	"Repository", "Branch", "Default Branch", "Team", "Target", "Type", "ID", "Severity", "Status",
+	"Package", "Installed Version", "Fixed Version", "Title", "Detected At", "Fixed At", "Permalink",
+}
+
+// NewExportedFinding converts a stored finding of a branch of the repository. The fix date is the last update of a fixed finding, because a finding is not updated after it is marked as fixed.
+func NewExportedFinding(repo *Repository, bv *BranchVulnerability) *ExportedFinding {
+	vuln := bv.Vulnerability
+	findingType := vuln.Type
+	if findingType == "" {
+		findingType = types.FindingTypeVulnerability
+	}
+
+	exported := &ExportedFinding{
+		Repository:       string(repo.ID),
+		Branch:           string(bv.Branch),
+		DefaultBranch:    repo.DefaultBranch != "" && bv.Branch == repo.DefaultBranch,
+		Team:             repo.Team,
+		Type:             findingType,
+		ID:               vuln.ID,
+		Severity:         vuln.Severity,
+		Status:           vuln.Status,
+		PkgName:          vuln.PkgName,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Title:            vuln.Title,
+		DetectedAt:       vuln.CreatedAt,
+	}
+	if bv.Target != nil {
+		exported.Target = bv.Target.Target
+	}
+	if vuln.Status == types.VulnStatusFixed {
+		exported.FixedAt = vuln.UpdatedAt
+	}
+	return exported
+}
+
+// Row returns values of the columns of ExportColumns. Dates are in RFC 3339 in UTC, and empty if not set.
+func (x *ExportedFinding) Row() []string {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	defaultBranch := "false"
+	if x.DefaultBranch {
+		defaultBranch = "true"
+	}
+
+	return []string{
+		x.Repository, x.Branch, defaultBranch, x.Team, x.Target, string(x.Type), x.ID, x.Severity, string(x.Status),
+		x.PkgName, x.InstalledVersion, x.FixedVersion, x.Title, formatTime(x.DetectedAt), formatTime(x.FixedAt), x.Permalink,
+	}
+}
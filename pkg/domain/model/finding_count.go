@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// CountFindingsAtOrAbove returns the number of vulnerabilities, failed misconfigurations and
+// secrets in the report whose severity is equal to or higher than the threshold.
+func CountFindingsAtOrAbove(report *trivy.Report, threshold types.Severity) int {
+	var count int
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			if types.Severity(vuln.Severity).AtLeast(threshold) {
+				count++
+			}
+		}
+		for _, misconf := range result.Misconfigurations {
+			if misconf.Status != "" && misconf.Status != trivy.MisconfStatusFailure {
+				continue
+			}
+			if types.Severity(misconf.Severity).AtLeast(threshold) {
+				count++
+			}
+		}
+		for _, secret := range result.Secrets {
+			if types.Severity(secret.Severity).AtLeast(threshold) {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,43 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestCountFindingsAtOrAbove(t *testing.T) {
+	report := &trivy.Report{
+		Results: []trivy.Result{
+			{
+				Target: "go.mod",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+					{VulnerabilityID: "CVE-2024-0002", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+					{VulnerabilityID: "CVE-2024-0003", Vulnerability: trivy.Vulnerability{Severity: "LOW"}},
+				},
+			},
+			{
+				Target: "Dockerfile",
+				Misconfigurations: []trivy.DetectedMisconfiguration{
+					{AVDID: "AVD-DS-0002", Severity: "HIGH", Status: trivy.MisconfStatusFailure},
+					{AVDID: "AVD-DS-0001", Severity: "CRITICAL", Status: trivy.MisconfStatusPassed},
+				},
+			},
+			{
+				Target: "config/secret.env",
+				Secrets: []trivy.SecretFinding{
+					{RuleID: "aws-access-key-id", Severity: "CRITICAL"},
+				},
+			},
+		},
+	}
+
+	gt.V(t, model.CountFindingsAtOrAbove(report, types.SeverityCritical)).Equal(2)
+	gt.V(t, model.CountFindingsAtOrAbove(report, types.SeverityHigh)).Equal(4)
+	gt.V(t, model.CountFindingsAtOrAbove(report, types.SeverityLow)).Equal(5)
+	gt.V(t, model.CountFindingsAtOrAbove(&trivy.Report{}, types.SeverityLow)).Equal(0)
+}
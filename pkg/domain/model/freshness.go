@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// CheckScanFreshnessInput is input for detecting default branches that have not been scanned recently
+type CheckScanFreshnessInput struct {
+	Owner  string
+	MaxAge time.Duration
+}
+
+// ScanFreshnessReport is the result of a scan freshness check
+type ScanFreshnessReport struct {
+	Owner   string
+	MaxAge  time.Duration
+	Checked int
+	Stale   []StaleBranch
+}
+
+// StaleBranch is a default branch whose last scan is missing or older than the allowed age
+type StaleBranch struct {
+	RepoID     types.GitHubRepoID
+	Branch     types.BranchName
+	LastScanAt time.Time // zero if the branch has never been scanned
+	Reason     string
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// EvaluateGateInput is input for deciding whether a branch is clean enough to be deployed
+type EvaluateGateInput struct {
+	Owner  string
+	Repo   string
+	Branch types.BranchName // optional; the default branch is used if empty
+
+	// MaxSeverity is the highest severity of active findings that is tolerated. If empty, any active finding fails the gate.
+	MaxSeverity types.Severity
+
+	// MaxScanAge is how old the last successful scan can be. Zero disables the freshness check.
+	MaxScanAge time.Duration
+
+	// EnforceSLA fails the gate also by active findings not fixed within the SLA, even if they do not exceed MaxSeverity
+	EnforceSLA bool
+}
+
+// GateResult is the decision of a deployment gate with its justification
+type GateResult struct {
+	Pass          bool            `json:"pass"`
+	Repository    string          `json:"repository"`
+	Branch        string          `json:"branch"`
+	CommitSHA     string          `json:"commit_sha"`
+	LastScanAt    time.Time       `json:"last_scan_at"`
+	MaxSeverity   string          `json:"max_severity,omitempty"`
+	MaxScanAge    string          `json:"max_scan_age,omitempty"`
+	SLA           string          `json:"sla,omitempty"`
+	Justification []string        `json:"justification"`
+	Violations    []GateViolation `json:"violations,omitempty"`
+	Permalink     string          `json:"permalink,omitempty"`
+
+	// Policies are decisions of gate policies, e.g. WASM policy plugins. The gate fails if any of them does not pass.
+	Policies []GatePolicyDecision `json:"policies,omitempty"`
+}
+
+// GateViolation is an active finding that exceeds the tolerated severity, or is overdue by the SLA
+type GateViolation struct {
+	Target         string            `json:"target"`
+	ID             string            `json:"id"`
+	Type           types.FindingType `json:"type"`
+	Severity       string            `json:"severity"`
+	PkgName        string            `json:"pkg_name,omitempty"`
+	Title          string            `json:"title,omitempty"`
+	Exploitability *Exploitability   `json:"exploitability,omitempty"`
+	// Age is set if SLA of vulnerabilities is configured
+	Age       *VulnerabilityAge `json:"age,omitempty"`
+	Permalink string            `json:"permalink,omitempty"`
+}
+
+// GatePolicyInput is the summary of the last scan of a branch given to gate policies, with the decision of the built-in evaluation
+type GatePolicyInput struct {
+	Repository     string           `json:"repository"`
+	Branch         string           `json:"branch"`
+	CommitSHA      string           `json:"commit_sha"`
+	LastScanAt     time.Time        `json:"last_scan_at"`
+	LastScanStatus types.ScanStatus `json:"last_scan_status"`
+	MaxSeverity    string           `json:"max_severity,omitempty"`
+
+	// Pass and Justification are the decision of the built-in evaluation
+	Pass          bool     `json:"pass"`
+	Justification []string `json:"justification"`
+
+	// Findings are all active findings of the branch, including ones which are not violations of the built-in evaluation
+	Findings []GateViolation `json:"findings"`
+}
+
+// GatePolicyDecision is the decision of a gate policy
+type GatePolicyDecision struct {
+	// Policy is the name of the policy, set by octovy
+	Policy  string   `json:"policy"`
+	Pass    bool     `json:"pass"`
+	Reasons []string `json:"reasons,omitempty"`
+}
@@ -1,7 +1,9 @@
 package model
 
 import (
-	"regexp"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
@@ -40,6 +42,8 @@ type GitHubMetadata struct {
 	PullRequest    *GitHubPullRequest `json:"pull_request"`
 	DefaultBranch  string             `json:"default_branch"`
 	InstallationID int64              `json:"installation_id"`
+	// Tag is the Git tag resolved to CommitID, set only when a tag or a release is scanned
+	Tag string `json:"tag,omitempty"`
 }
 
 // ValidateBasic validates that required GitHub metadata fields are not empty.
@@ -61,6 +65,8 @@ type GitHubPullRequest struct {
 	BaseBranch   string     `json:"base_branch"`
 	BaseCommitID string     `json:"base_commit_id"`
 	User         GitHubUser `json:"user"`
+	// Author is the user who opened the pull request, e.g. "dependabot[bot]"
+	Author GitHubUser `json:"author"`
 }
 
 type GitHubUser struct {
@@ -69,22 +75,37 @@ type GitHubUser struct {
 	Email string `json:"email"`
 }
 
-var (
-	ptnValidCommitID = regexp.MustCompile("^[0-9a-f]{40}$")
-)
-
 func (x *GitHubCommit) Validate() error {
 	if err := x.GitHubRepo.Validate(); err != nil {
 		return err
 	}
 
-	if !ptnValidCommitID.MatchString(x.CommitID) {
-		return goerr.Wrap(types.ErrValidationFailed, "invalid commit ID")
+	if err := types.CommitSHA(x.CommitID).Validate(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// Normalize trims owner and repository names, strips "refs/heads/" from the branch and lowers
+// the case of the commit ID so that webhook and CLI inputs map to the same identifiers.
+func (x *GitHubCommit) Normalize() {
+	x.Owner = strings.TrimSpace(x.Owner)
+	x.RepoName = strings.TrimSpace(x.RepoName)
+	x.CommitID = string(types.NewCommitSHA(x.CommitID))
+	x.Branch = string(types.NewBranchName(x.Branch))
+}
+
+// Normalize normalizes the commit, the default branch and the pull request base
+func (x *GitHubMetadata) Normalize() {
+	x.GitHubCommit.Normalize()
+	x.DefaultBranch = string(types.NewBranchName(x.DefaultBranch))
+	if x.PullRequest != nil {
+		x.PullRequest.BaseBranch = string(types.NewBranchName(x.PullRequest.BaseBranch))
+		x.PullRequest.BaseCommitID = string(types.NewCommitSHA(x.PullRequest.BaseCommitID))
+	}
+}
+
 type GitHubIssueComment struct {
 	ID          string
 	Login       string
@@ -100,4 +121,42 @@ type GitHubAPIRepository struct {
 	DefaultBranch string
 	Archived      bool
 	Disabled      bool
+	Topics        []string
+}
+
+// GitHubTeam is a team which has access to a repository
+type GitHubTeam struct {
+	Slug       string
+	Permission string // "admin", "maintain", "push", "triage" or "pull"
+}
+
+// CodeScanningAlert represents a GitHub code scanning alert. For Trivy SARIF uploads,
+// RuleID is the vulnerability ID and Path is the scan target.
+type CodeScanningAlert struct {
+	Number           int
+	RuleID           string
+	State            string
+	DismissedReason  string
+	DismissedComment string
+	DismissedBy      string
+	DismissedAt      time.Time
+	Path             string
+}
+
+// GitHubRateLimit is the latest REST API (core) rate limit status of a GitHub App installation, observed from X-RateLimit-* headers of API responses.
+type GitHubRateLimit struct {
+	InstallID  types.GitHubAppInstallID `json:"install_id"`
+	Limit      int                      `json:"limit"`
+	Remaining  int                      `json:"remaining"`
+	Reset      time.Time                `json:"reset"`
+	ObservedAt time.Time                `json:"observed_at"`
+}
+
+func (x *GitHubRateLimit) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("install_id", x.InstallID),
+		slog.Int("limit", x.Limit),
+		slog.Int("remaining", x.Remaining),
+		slog.Time("reset", x.Reset),
+	)
 }
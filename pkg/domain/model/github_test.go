@@ -1,10 +1,12 @@
 package model_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
 
 func TestGitHubRepoValidate(t *testing.T) {
@@ -287,3 +289,67 @@ func TestScanGitHubRepoInputValidate(t *testing.T) {
 		gt.Error(t, input.Validate())
 	})
 }
+
+func TestGitHubMetadataNormalize(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    " test-owner ",
+				RepoName: "test-repo\n",
+			},
+			CommitID: "F7C8851DA7C7FCC46212FCCFB6C9C4BDA520F1CA",
+			Branch:   "refs/heads/feature/foo",
+			Ref:      "refs/heads/feature/foo",
+		},
+		DefaultBranch: "refs/heads/main",
+		PullRequest: &model.GitHubPullRequest{
+			BaseBranch:   "refs/heads/main",
+			BaseCommitID: "ABCDEF0123456789ABCDEF0123456789ABCDEF01",
+		},
+	}
+	meta.Normalize()
+
+	gt.V(t, meta.Owner).Equal("test-owner")
+	gt.V(t, meta.RepoName).Equal("test-repo")
+	gt.V(t, meta.CommitID).Equal("f7c8851da7c7fcc46212fccfb6c9c4bda520f1ca")
+	gt.V(t, meta.Branch).Equal("feature/foo")
+	gt.V(t, meta.Ref).Equal("refs/heads/feature/foo")
+	gt.V(t, meta.DefaultBranch).Equal("main")
+	gt.V(t, meta.PullRequest.BaseBranch).Equal("main")
+	gt.V(t, meta.PullRequest.BaseCommitID).Equal("abcdef0123456789abcdef0123456789abcdef01")
+}
+
+func TestScanGitHubRepoRemoteInputNormalize(t *testing.T) {
+	input := &model.ScanGitHubRepoRemoteInput{
+		Owner:  "test-owner ",
+		Repo:   " test-repo",
+		Commit: " F7C8851DA7C7FCC46212FCCFB6C9C4BDA520F1CA",
+		Branch: "refs/heads/main",
+	}
+	input.Normalize()
+
+	gt.V(t, input.Owner).Equal("test-owner")
+	gt.V(t, input.Repo).Equal("test-repo")
+	gt.V(t, input.Commit).Equal("f7c8851da7c7fcc46212fccfb6c9c4bda520f1ca")
+	gt.V(t, input.Branch).Equal("main")
+
+	input.Tag = " refs/tags/v1.2.0"
+	input.Normalize()
+	gt.V(t, input.Tag).Equal("v1.2.0")
+}
+
+func TestScanGitHubRepoRemoteInputValidateRef(t *testing.T) {
+	gt.NoError(t, (&model.ScanGitHubRepoRemoteInput{Tag: "v1.2.0"}).ValidateRef())
+	gt.NoError(t, (&model.ScanGitHubRepoRemoteInput{}).ValidateRef())
+
+	err := (&model.ScanGitHubRepoRemoteInput{Branch: "main", Tag: "v1.2.0"}).ValidateRef()
+	gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	gt.S(t, err.Error()).Contains("branch and tag")
+}
+
+func TestIsDependencyUpdateBot(t *testing.T) {
+	gt.True(t, model.IsDependencyUpdateBot("dependabot[bot]"))
+	gt.True(t, model.IsDependencyUpdateBot("renovate[bot]"))
+	gt.False(t, model.IsDependencyUpdateBot("octocat"))
+	gt.False(t, model.IsDependencyUpdateBot("dependabot"))
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ArtifactTypeContainerImage is Trivy's artifact type for container image scans
+const ArtifactTypeContainerImage trivy.ArtifactType = "container_image"
+
+// baseImageNameLabel is the OCI annotation that records the base image reference
+const baseImageNameLabel = "org.opencontainers.image.base.name"
+
+// ImageAnalysis attributes vulnerabilities of a container image scan to base image
+// layers or application layers and recommends actions for the base image.
+type ImageAnalysis struct {
+	BaseImage            string             `bigquery:"base_image" json:"base_image,omitempty"`
+	BaseLayerCount       int                `bigquery:"base_layer_count" json:"base_layer_count"`
+	BaseVulnCount        int                `bigquery:"base_vuln_count" json:"base_vuln_count"`
+	BaseFixableVulnCount int                `bigquery:"base_fixable_vuln_count" json:"base_fixable_vuln_count"`
+	ApplicationVulnCount int                `bigquery:"application_vuln_count" json:"application_vuln_count"`
+	Layers               []LayerAttribution `bigquery:"layers" json:"layers"`
+	Recommendations      []string           `bigquery:"recommendations" json:"recommendations,omitempty"`
+}
+
+// LayerAttribution summarizes vulnerabilities introduced by a single image layer
+type LayerAttribution struct {
+	DiffID    string            `bigquery:"diff_id" json:"diff_id"`
+	CreatedBy string            `bigquery:"created_by" json:"created_by,omitempty"`
+	Origin    types.LayerOrigin `bigquery:"origin" json:"origin"`
+	VulnCount int               `bigquery:"vuln_count" json:"vuln_count"`
+}
+
+// NewImageAnalysis builds an ImageAnalysis from a container image report.
+// It returns nil if the report is not a container image scan or has no layer information.
+func NewImageAnalysis(report *trivy.Report) *ImageAnalysis {
+	if report.ArtifactType != ArtifactTypeContainerImage || len(report.Metadata.DiffIDs) == 0 {
+		return nil
+	}
+
+	analysis := &ImageAnalysis{}
+
+	var history []trivy.History
+	if cfg := report.Metadata.ImageConfig; cfg != nil {
+		history = cfg.History
+		if cfg.Config != nil {
+			analysis.BaseImage = cfg.Config.Labels[baseImageNameLabel]
+		}
+	}
+
+	// Map non-empty history entries to diff IDs in order, marking layers up to the
+	// base image boundary as base layers.
+	baseIndex := guessBaseImageIndex(history)
+	createdBy := make(map[string]string)
+	baseDiffIDs := make(map[string]bool)
+	layerIdx := 0
+	for i, h := range history {
+		if h.EmptyLayer {
+			continue
+		}
+		if layerIdx >= len(report.Metadata.DiffIDs) {
+			break
+		}
+		diffID := report.Metadata.DiffIDs[layerIdx]
+		createdBy[diffID] = h.CreatedBy
+		if i < baseIndex {
+			baseDiffIDs[diffID] = true
+		}
+		layerIdx++
+	}
+
+	for _, diffID := range report.Metadata.DiffIDs {
+		origin := types.LayerOriginApplication
+		if baseDiffIDs[diffID] {
+			origin = types.LayerOriginBase
+			analysis.BaseLayerCount++
+		}
+		analysis.Layers = append(analysis.Layers, LayerAttribution{
+			DiffID:    diffID,
+			CreatedBy: createdBy[diffID],
+			Origin:    origin,
+		})
+	}
+
+	vulnCounts := make(map[string]int)
+	fixable := &fixableVulns{appLayerCounts: make(map[string]int)}
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			var diffID string
+			if vuln.Layer != nil {
+				diffID = vuln.Layer.DiffID
+			}
+			vulnCounts[diffID]++
+
+			if analysis.Origin(diffID) == types.LayerOriginBase {
+				analysis.BaseVulnCount++
+				if vuln.FixedVersion != "" {
+					analysis.BaseFixableVulnCount++
+					fixable.addBasePackage(vuln)
+				}
+			} else {
+				analysis.ApplicationVulnCount++
+				if vuln.FixedVersion != "" {
+					fixable.appCount++
+					fixable.appLayerCounts[diffID]++
+				}
+			}
+		}
+	}
+
+	for i := range analysis.Layers {
+		analysis.Layers[i].VulnCount = vulnCounts[analysis.Layers[i].DiffID]
+	}
+
+	analysis.Recommendations = analysis.recommend(report.Metadata.OS, fixable)
+
+	return analysis
+}
+
+// Origin returns whether the layer identified by diffID belongs to the base image.
+// Unknown layers are attributed to the application.
+func (x *ImageAnalysis) Origin(diffID string) types.LayerOrigin {
+	if x == nil {
+		return types.LayerOriginApplication
+	}
+	for _, layer := range x.Layers {
+		if layer.DiffID == diffID {
+			return layer.Origin
+		}
+	}
+	return types.LayerOriginApplication
+}
+
+// maxRecommendedPackages limits packages named in a recommendation
+const maxRecommendedPackages = 3
+
+// fixableVulns collects vulnerabilities having fixed versions for recommendations
+type fixableVulns struct {
+	// basePackages are upgrades of base image packages, e.g. "openssl 1.1.1n -> 1.1.1w"
+	basePackages []string
+	// appCount and appLayerCounts are fixable vulnerabilities of application layers in total and per diff ID
+	appCount       int
+	appLayerCounts map[string]int
+}
+
+func (x *fixableVulns) addBasePackage(vuln trivy.DetectedVulnerability) {
+	upgrade := fmt.Sprintf("%s %s -> %s", vuln.PkgName, vuln.InstalledVersion, vuln.FixedVersion)
+	if !slices.Contains(x.basePackages, upgrade) {
+		x.basePackages = append(x.basePackages, upgrade)
+	}
+}
+
+func (x *ImageAnalysis) recommend(os *trivy.OS, fixable *fixableVulns) []string {
+	baseImage := x.BaseImage
+	if baseImage == "" {
+		baseImage = "the base image"
+	}
+
+	var recommendations []string
+	if os != nil && os.Eosl {
+		recommendations = append(recommendations,
+			fmt.Sprintf("%s %s has reached end of service life; move %s to a supported release", os.Family, os.Name, baseImage))
+	}
+	if x.BaseFixableVulnCount > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("%d of %d vulnerabilities in %s have fixed versions; rebuild with a fresher tag of %s that includes %s",
+				x.BaseFixableVulnCount, x.BaseVulnCount, baseImage, baseImage, summarizePackages(fixable.basePackages)))
+	}
+	if layer := x.mostFixableLayer(fixable.appLayerCounts); layer != nil {
+		recommendations = append(recommendations,
+			fmt.Sprintf("%d vulnerabilities in application layers have fixed versions; %d of them come from the layer created by `%s`",
+				fixable.appCount, fixable.appLayerCounts[layer.DiffID], layer.CreatedBy))
+	}
+	return recommendations
+}
+
+// mostFixableLayer returns the application layer with the most fixable vulnerabilities, or nil if there is no such layer with a known instruction
+func (x *ImageAnalysis) mostFixableLayer(counts map[string]int) *LayerAttribution {
+	var most *LayerAttribution
+	for i, layer := range x.Layers {
+		if layer.Origin != types.LayerOriginApplication || layer.CreatedBy == "" || counts[layer.DiffID] == 0 {
+			continue
+		}
+		if most == nil || counts[layer.DiffID] > counts[most.DiffID] {
+			most = &x.Layers[i]
+		}
+	}
+	return most
+}
+
+// summarizePackages joins sorted package upgrades up to maxRecommendedPackages
+func summarizePackages(packages []string) string {
+	sorted := slices.Sorted(slices.Values(packages))
+	if len(sorted) <= maxRecommendedPackages {
+		return strings.Join(sorted, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(sorted[:maxRecommendedPackages], ", "), len(sorted)-maxRecommendedPackages)
+}
+
+// guessBaseImageIndex returns the index of the history entry that ends the base image,
+// using the same heuristic as Trivy: the last CMD instruction before the application's
+// own layers marks the boundary. It returns -1 if no boundary is found.
+func guessBaseImageIndex(history []trivy.History) int {
+	foundNonEmpty := false
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+
+		// Skip trailing metadata-only instructions (CMD, ENTRYPOINT, etc.) of the image itself
+		if !foundNonEmpty {
+			if h.EmptyLayer {
+				continue
+			}
+			foundNonEmpty = true
+		}
+
+		if !h.EmptyLayer {
+			continue
+		}
+
+		if strings.HasPrefix(h.CreatedBy, "/bin/sh -c #(nop)  CMD") || strings.HasPrefix(h.CreatedBy, "CMD") {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,127 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func newImageReport() *trivy.Report {
+	return &trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "example/app:latest",
+		ArtifactType:  model.ArtifactTypeContainerImage,
+		Metadata: trivy.Metadata{
+			OS:      &trivy.OS{Family: "debian", Name: "10.13", Eosl: true},
+			DiffIDs: []string{"sha256:base1", "sha256:base2", "sha256:app1"},
+			ImageConfig: &trivy.ConfigFile{
+				History: []trivy.History{
+					{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in / "},
+					{CreatedBy: "/bin/sh -c apt-get update"},
+					{CreatedBy: "/bin/sh -c #(nop)  CMD [\"bash\"]", EmptyLayer: true},
+					{CreatedBy: "WORKDIR /app", EmptyLayer: true},
+					{CreatedBy: "COPY app /app # buildkit"},
+					{CreatedBy: "ENTRYPOINT [\"/app/app\"]", EmptyLayer: true},
+				},
+				Config: &trivy.Config{
+					Labels: map[string]string{
+						"org.opencontainers.image.base.name": "debian:buster",
+					},
+				},
+			},
+		},
+		Results: []trivy.Result{
+			{
+				Target: "example/app:latest (debian 10.13)",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "libssl1.1", InstalledVersion: "1.1.1n-0+deb10u3", FixedVersion: "1.1.1n-0+deb10u6", Layer: &trivy.Layer{DiffID: "sha256:base1"}},
+					{VulnerabilityID: "CVE-2024-0002", Layer: &trivy.Layer{DiffID: "sha256:base2"}},
+				},
+			},
+			{
+				Target: "app/go.mod",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0003", PkgName: "golang.org/x/net", InstalledVersion: "0.0.1", FixedVersion: "0.1.0", Layer: &trivy.Layer{DiffID: "sha256:app1"}},
+				},
+			},
+		},
+	}
+}
+
+func TestNewImageAnalysis(t *testing.T) {
+	t.Run("attributes vulnerabilities to base and application layers", func(t *testing.T) {
+		analysis := model.NewImageAnalysis(newImageReport())
+		gt.V(t, analysis).NotNil()
+
+		gt.V(t, analysis.BaseImage).Equal("debian:buster")
+		gt.V(t, analysis.BaseLayerCount).Equal(2)
+		gt.V(t, analysis.BaseVulnCount).Equal(2)
+		gt.V(t, analysis.BaseFixableVulnCount).Equal(1)
+		gt.V(t, analysis.ApplicationVulnCount).Equal(1)
+
+		gt.A(t, analysis.Layers).Length(3)
+		gt.V(t, analysis.Layers[0].Origin).Equal(types.LayerOriginBase)
+		gt.V(t, analysis.Layers[0].VulnCount).Equal(1)
+		gt.V(t, analysis.Layers[1].Origin).Equal(types.LayerOriginBase)
+		gt.V(t, analysis.Layers[2].Origin).Equal(types.LayerOriginApplication)
+		gt.V(t, analysis.Layers[2].CreatedBy).Equal("COPY app /app # buildkit")
+
+		gt.V(t, analysis.Origin("sha256:base2")).Equal(types.LayerOriginBase)
+		gt.V(t, analysis.Origin("sha256:unknown")).Equal(types.LayerOriginApplication)
+	})
+
+	t.Run("recommends base image updates", func(t *testing.T) {
+		analysis := model.NewImageAnalysis(newImageReport())
+		gt.A(t, analysis.Recommendations).Length(3)
+		gt.V(t, analysis.Recommendations[0]).Equal("debian 10.13 has reached end of service life; move debian:buster to a supported release")
+		gt.V(t, analysis.Recommendations[1]).Equal("1 of 2 vulnerabilities in debian:buster have fixed versions; rebuild with a fresher tag of debian:buster that includes libssl1.1 1.1.1n-0+deb10u3 -> 1.1.1n-0+deb10u6")
+		gt.V(t, analysis.Recommendations[2]).Equal("1 vulnerabilities in application layers have fixed versions; 1 of them come from the layer created by `COPY app /app # buildkit`")
+	})
+
+	t.Run("names limited packages of base image", func(t *testing.T) {
+		report := newImageReport()
+		report.Metadata.OS.Eosl = false
+		var vulns []trivy.DetectedVulnerability
+		for _, name := range []string{"zlib1g", "libc6", "openssl", "curl", "libc6"} {
+			vulns = append(vulns, trivy.DetectedVulnerability{
+				PkgName:          name,
+				InstalledVersion: "1.0",
+				FixedVersion:     "1.1",
+				Layer:            &trivy.Layer{DiffID: "sha256:base1"},
+			})
+		}
+		report.Results = []trivy.Result{{Target: "example/app:latest (debian 10.13)", Vulnerabilities: vulns}}
+
+		analysis := model.NewImageAnalysis(report)
+		gt.A(t, analysis.Recommendations).Length(1)
+		gt.S(t, analysis.Recommendations[0]).Contains("includes curl 1.0 -> 1.1, libc6 1.0 -> 1.1, openssl 1.0 -> 1.1 and 1 more")
+	})
+
+	t.Run("treats all layers as application when no base boundary is found", func(t *testing.T) {
+		report := newImageReport()
+		report.Metadata.ImageConfig.History = []trivy.History{
+			{CreatedBy: "ADD rootfs /"},
+			{CreatedBy: "RUN make"},
+			{CreatedBy: "COPY app /app"},
+		}
+
+		analysis := model.NewImageAnalysis(report)
+		gt.V(t, analysis.BaseLayerCount).Equal(0)
+		gt.V(t, analysis.BaseVulnCount).Equal(0)
+		gt.V(t, analysis.ApplicationVulnCount).Equal(3)
+	})
+
+	t.Run("returns nil for non-image reports", func(t *testing.T) {
+		report := newImageReport()
+		report.ArtifactType = "filesystem"
+		gt.V(t, model.NewImageAnalysis(report)).Nil()
+	})
+
+	t.Run("nil analysis attributes to application", func(t *testing.T) {
+		var analysis *model.ImageAnalysis
+		gt.V(t, analysis.Origin("sha256:base1")).Equal(types.LayerOriginApplication)
+	})
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// dependencyFileNames are names of dependency definitions which are not reported as targets, but change packages resolved from manifests and lockfiles in the same directory
+var dependencyFileNames = map[string]bool{
+	"go.sum":                   true,
+	"package.json":             true,
+	"Gemfile":                  true,
+	"Cargo.toml":               true,
+	"composer.json":            true,
+	"Pipfile":                  true,
+	"pyproject.toml":           true,
+	"build.gradle":             true,
+	"build.gradle.kts":         true,
+	"Directory.Packages.props": true,
+	"Podfile":                  true,
+	"Package.swift":            true,
+	"pubspec.yaml":             true,
+	"mix.exs":                  true,
+	"conanfile.txt":            true,
+	"conanfile.py":             true,
+}
+
+// IsDependencyFile returns true if a change of the file can change detected packages, i.e. it is a manifest, a lockfile or a dependency definition such as package.json
+func IsDependencyFile(name string) bool {
+	base := path.Base(name)
+	return IsManifestFile(name) || dependencyFileNames[base] || strings.HasSuffix(base, ".csproj")
+}
+
+// IncrementalScan limits a scan of a pull request to directories of files changed by the pull request. It is recorded in the scan because targets out of Paths are not in the report.
+type IncrementalScan struct {
+	// Paths are directories scanned, relative to the repository root
+	Paths []string `bigquery:"paths" json:"paths"`
+	// ChangedFiles is the number of files changed by the pull request
+	ChangedFiles int `bigquery:"changed_files" json:"changed_files"`
+}
+
+// NewIncrementalScan returns an incremental scan of directories of the changed files. If dependencyOnly is true, only changes of dependency files are relevant, because the scanner detects vulnerabilities of packages only. It returns nil if the whole repository needs to be scanned, i.e. no relevant file is changed, a relevant file in the repository root is changed or the scan config is changed.
+func NewIncrementalScan(changed []string, dependencyOnly bool) *IncrementalScan {
+	var dirs []string
+	for _, file := range normalizeScanPaths(changed) {
+		// A change of the scan config changes paths to scan
+		if file == ScanConfigFileName {
+			return nil
+		}
+		if dependencyOnly && !IsDependencyFile(file) {
+			continue
+		}
+		dir := path.Dir(file)
+		if dir == "." {
+			return nil
+		}
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	return &IncrementalScan{
+		Paths:        removeNestedPaths(dirs),
+		ChangedFiles: len(changed),
+	}
+}
+
+// Covers returns true if the file, e.g. a target of a report, is in the scanned directories
+func (x *IncrementalScan) Covers(file string) bool {
+	for _, p := range x.Paths {
+		if isSubPath(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanConfig returns the config of the repository limited to Paths. It returns nil if no path of the incremental scan is in the paths of the config, because nothing changed in the scope of the scan.
+func (x *IncrementalScan) ScanConfig(base *ScanConfig) *ScanConfig {
+	cfg := &ScanConfig{Paths: x.Paths}
+	if base == nil {
+		return cfg
+	}
+	cfg.Exclude = base.Exclude
+	if len(base.Paths) == 0 {
+		return cfg
+	}
+
+	var paths []string
+	for _, p := range x.Paths {
+		for _, q := range base.Paths {
+			switch {
+			case isSubPath(p, q):
+				paths = append(paths, p)
+			case isSubPath(q, p):
+				paths = append(paths, q)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	cfg.Paths = removeNestedPaths(paths)
+	return cfg
+}
+
+// isSubPath returns true if p is dir or in dir
+func isSubPath(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// removeNestedPaths sorts paths and drops duplicated ones and ones in another path
+func removeNestedPaths(paths []string) []string {
+	sorted := append([]string{}, paths...)
+	sort.Strings(sorted)
+
+	var result []string
+	for _, p := range sorted {
+		nested := slices.ContainsFunc(result, func(dir string) bool { return isSubPath(p, dir) })
+		if !nested {
+			result = append(result, p)
+		}
+	}
+	return result
+}
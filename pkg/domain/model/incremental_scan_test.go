@@ -0,0 +1,63 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestNewIncrementalScan(t *testing.T) {
+	t.Run("directories of dependency files", func(t *testing.T) {
+		incremental := model.NewIncrementalScan([]string{
+			"services/web/src/app.ts",
+			"services/web/package.json",
+			"services/api/go.sum",
+			"services/api/internal/go.mod",
+			"services/api-gateway/App.csproj",
+		}, true)
+		gt.V(t, incremental.Paths).Equal([]string{"services/api", "services/api-gateway", "services/web"})
+		gt.V(t, incremental.ChangedFiles).Equal(5)
+
+		gt.True(t, incremental.Covers("services/api/internal/go.mod"))
+		gt.False(t, incremental.Covers("services/api2/go.mod"))
+		gt.False(t, incremental.Covers("go.mod"))
+	})
+
+	t.Run("directories of all files", func(t *testing.T) {
+		incremental := model.NewIncrementalScan([]string{"services/web/src/app.ts", "docs/README.md"}, false)
+		gt.V(t, incremental.Paths).Equal([]string{"docs", "services/web/src"})
+	})
+
+	t.Run("whole repository", func(t *testing.T) {
+		gt.V(t, model.NewIncrementalScan([]string{"services/web/src/app.ts"}, true)).Nil()
+		gt.V(t, model.NewIncrementalScan([]string{"services/api/go.mod", "go.mod"}, true)).Nil()
+		gt.V(t, model.NewIncrementalScan([]string{"services/api/go.mod", "README.md"}, false)).Nil()
+		gt.V(t, model.NewIncrementalScan([]string{"services/api/go.mod", ".octovy.yml"}, true)).Nil()
+		gt.V(t, model.NewIncrementalScan(nil, false)).Nil()
+	})
+
+	// README.md in the root is irrelevant to vulnerabilities of packages
+	gt.V(t, model.NewIncrementalScan([]string{"services/api/go.mod", "README.md"}, true).Paths).Equal([]string{"services/api"})
+}
+
+func TestIncrementalScanConfig(t *testing.T) {
+	incremental := &model.IncrementalScan{Paths: []string{"services/api", "services/web/src", "tools"}}
+
+	t.Run("without config", func(t *testing.T) {
+		gt.V(t, incremental.ScanConfig(nil).Paths).Equal(incremental.Paths)
+	})
+
+	t.Run("paths are limited by config", func(t *testing.T) {
+		cfg := incremental.ScanConfig(&model.ScanConfig{Paths: []string{"services"}, Exclude: []string{"vendor"}})
+		gt.V(t, cfg.Paths).Equal([]string{"services/api", "services/web/src"})
+		gt.V(t, cfg.Exclude).Equal([]string{"vendor"})
+
+		cfg = incremental.ScanConfig(&model.ScanConfig{Paths: []string{"services/web/src/lib"}})
+		gt.V(t, cfg.Paths).Equal([]string{"services/web/src/lib"})
+	})
+
+	t.Run("no changed path in config", func(t *testing.T) {
+		gt.V(t, incremental.ScanConfig(&model.ScanConfig{Paths: []string{"docs"}})).Nil()
+	})
+}
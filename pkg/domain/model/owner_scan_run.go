@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// Sources of repositories of an owner-wide scan
+const (
+	OwnerScanSourceGitHub    = "github"
+	OwnerScanSourceFirestore = "firestore"
+)
+
+// OwnerScanRun is the result of a scan of all repositories of an owner. It is returned even if some repositories failed, so that the caller can report which ones, and stored in ScanRepository for later inspection.
+type OwnerScanRun struct {
+	ID    types.OwnerScanRunID `json:"id"`
+	Owner string               `json:"owner"`
+	// Source is where the repositories were listed, OwnerScanSourceGitHub or OwnerScanSourceFirestore
+	Source    string            `json:"source"`
+	Trigger   types.ScanTrigger `json:"trigger"`
+	RequestID types.RequestID   `json:"request_id,omitempty"`
+	// Status is failed if any repository failed or the run was interrupted
+	Status types.ScanRunStatus `json:"status"`
+	// Error is why the run was interrupted before scanning all repositories, e.g. cancellation while waiting for the GitHub rate limit
+	Error string `json:"error,omitempty"`
+
+	Succeeded    int                    `json:"succeeded"`
+	Skipped      int                    `json:"skipped"`
+	Failed       int                    `json:"failed"`
+	Repositories []*OwnerScanRepoResult `json:"repositories"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// OwnerScanRepoResult is the result of a repository in an OwnerScanRun
+type OwnerScanRepoResult struct {
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+	// CommitID is empty if the scan failed before resolving the branch
+	CommitID string              `json:"commit_id,omitempty"`
+	Status   types.ScanRunStatus `json:"status"`
+	// ScanID is the stored scan. Skipped is true instead if the commit had already been scanned.
+	ScanID  types.ScanID `json:"scan_id,omitempty"`
+	Skipped bool         `json:"skipped"`
+	Error   string       `json:"error,omitempty"`
+	// Findings is active findings of the branch after the scan. It is nil if the scan failed or ScanRepository is not configured.
+	Findings   *FindingSummary `json:"findings,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// NewOwnerScanRun returns a run of the owner started at now
+func NewOwnerScanRun(owner, source string, trigger types.ScanTrigger, requestID types.RequestID, now time.Time) *OwnerScanRun {
+	if trigger == "" {
+		trigger = types.ScanTriggerCLI
+	}
+	return &OwnerScanRun{
+		ID:           types.NewOwnerScanRunID(),
+		Owner:        owner,
+		Source:       source,
+		Trigger:      trigger,
+		RequestID:    requestID,
+		Status:       types.ScanRunRunning,
+		Repositories: []*OwnerScanRepoResult{},
+		StartedAt:    now,
+		ExpiresAt:    now.Add(ScanRunRetention),
+	}
+}
+
+// Add appends the result of a repository and counts it
+func (x *OwnerScanRun) Add(result *OwnerScanRepoResult) {
+	x.Repositories = append(x.Repositories, result)
+	switch {
+	case result.Status == types.ScanRunFailed:
+		x.Failed++
+	case result.Skipped:
+		x.Skipped++
+	default:
+		x.Succeeded++
+	}
+}
+
+// Finish sets the status of the run. interruptErr is the error which stopped the run before scanning all repositories, or nil.
+func (x *OwnerScanRun) Finish(interruptErr error, now time.Time) {
+	x.FinishedAt = now
+	if interruptErr != nil {
+		x.Error = interruptErr.Error()
+	}
+	if x.Failed > 0 || interruptErr != nil {
+		x.Status = types.ScanRunFailed
+	} else {
+		x.Status = types.ScanRunSucceeded
+	}
+}
+
+// FailedRepos returns full names of repositories that failed to scan
+func (x *OwnerScanRun) FailedRepos() []string {
+	var repos []string
+	for _, r := range x.Repositories {
+		if r.Status == types.ScanRunFailed {
+			repos = append(repos, r.Owner+"/"+r.Repo)
+		}
+	}
+	return repos
+}
@@ -0,0 +1,52 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestOwnerScanRun(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("counts results of repositories", func(t *testing.T) {
+		run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceGitHub, "", "req-1", now)
+		gt.V(t, run.Trigger).Equal(types.ScanTriggerCLI)
+		gt.V(t, run.Status).Equal(types.ScanRunRunning)
+		gt.V(t, run.ExpiresAt).Equal(now.Add(model.ScanRunRetention))
+
+		run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "a", Status: types.ScanRunSucceeded, ScanID: "scan-1"})
+		run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "b", Status: types.ScanRunSucceeded, Skipped: true})
+		run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "c", Status: types.ScanRunFailed, Error: "failed"})
+		run.Finish(nil, now.Add(time.Minute))
+
+		gt.V(t, run.Succeeded).Equal(1)
+		gt.V(t, run.Skipped).Equal(1)
+		gt.V(t, run.Failed).Equal(1)
+		gt.V(t, run.Status).Equal(types.ScanRunFailed)
+		gt.V(t, run.FinishedAt).Equal(now.Add(time.Minute))
+		gt.V(t, run.FailedRepos()).Equal([]string{"myorg/c"})
+	})
+
+	t.Run("succeeds if no repository failed", func(t *testing.T) {
+		run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceFirestore, types.ScanTriggerSchedule, "", now)
+		run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "a", Status: types.ScanRunSucceeded, ScanID: "scan-1"})
+		run.Finish(nil, now)
+
+		gt.V(t, run.Status).Equal(types.ScanRunSucceeded)
+		gt.V(t, run.Error).Equal("")
+		gt.A(t, run.FailedRepos()).Length(0)
+	})
+
+	t.Run("interrupted run fails", func(t *testing.T) {
+		run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceGitHub, "", "", now)
+		run.Finish(errors.New("context canceled"), now)
+
+		gt.V(t, run.Status).Equal(types.ScanRunFailed)
+		gt.V(t, run.Error).Equal("context canceled")
+	})
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+// Package is a package installed in a target, stored as the dependency inventory of the target. A package is identified by its name and version, so that multiple versions of the same package in a lockfile are kept apart.
+type Package struct {
+	ID       string
+	Name     string
+	Version  string
+	Licenses []string
+	PURL     string
+	Indirect bool
+	// CreatedAt is when the package is first detected in the target, and UpdatedAt is when the package is last changed
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ToPackageID converts a name and version of a package into a Firestore-safe document ID by SHA256 hashing, because package names contain "/" (e.g. "golang.org/x/net", "@types/node")
+func ToPackageID(name, version string) string {
+	hash := sha256.Sum256([]byte(name + "@" + version))
+	return hex.EncodeToString(hash[:])
+}
+
+// NewPackage creates a Package from a package of Trivy report detected at ts
+func NewPackage(pkg *trivy.Package, ts time.Time) *Package {
+	p := &Package{
+		ID:        ToPackageID(pkg.Name, pkg.Version),
+		Name:      pkg.Name,
+		Version:   pkg.Version,
+		Licenses:  pkg.Licenses,
+		Indirect:  pkg.Indirect,
+		CreatedAt: ts,
+		UpdatedAt: ts,
+	}
+	if pkg.Identifier != nil {
+		p.PURL = NormalizePURL(pkg.Identifier.PURL)
+	}
+	return p
+}
+
+// SameContent returns true if the packages have the same attributes except timestamps
+func (x *Package) SameContent(other *Package) bool {
+	return x.ID == other.ID &&
+		x.Name == other.Name &&
+		x.Version == other.Version &&
+		slices.Equal(x.Licenses, other.Licenses) &&
+		x.PURL == other.PURL &&
+		x.Indirect == other.Indirect
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// Permalinks builds stable links of repositories, branches, targets and findings served by the octovy server, e.g. {base}/r/{owner}/{repo}/b/{branch}/v/{vulnID}. Each segment is escaped, so that a branch or target name with slashes stays in one segment. A finding is identified by its ID in the branch, so the link keeps resolving after the finding is fixed. Methods of nil Permalinks return an empty string.
+type Permalinks struct {
+	baseURL string
+}
+
+// NewPermalinks returns nil if baseURL is empty, i.e. permalinks are not configured
+func NewPermalinks(baseURL string) *Permalinks {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil
+	}
+	return &Permalinks{baseURL: baseURL}
+}
+
+func (x *Permalinks) Repository(repoID types.GitHubRepoID) string {
+	owner, repo := repoID.Split()
+	return x.build("r", owner, repo)
+}
+
+func (x *Permalinks) Branch(repoID types.GitHubRepoID, branch types.BranchName) string {
+	owner, repo := repoID.Split()
+	return x.build("r", owner, repo, "b", string(branch))
+}
+
+func (x *Permalinks) Target(repoID types.GitHubRepoID, branch types.BranchName, targetID types.TargetID) string {
+	owner, repo := repoID.Split()
+	return x.build("r", owner, repo, "b", string(branch), "t", string(targetID))
+}
+
+func (x *Permalinks) Finding(repoID types.GitHubRepoID, branch types.BranchName, vulnID string) string {
+	owner, repo := repoID.Split()
+	return x.build("r", owner, repo, "b", string(branch), "v", vulnID)
+}
+
+func (x *Permalinks) build(segments ...string) string {
+	if x == nil {
+		return ""
+	}
+	for _, s := range segments {
+		if s == "" {
+			return ""
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(x.baseURL)
+	for _, s := range segments {
+		b.WriteString("/")
+		b.WriteString(url.PathEscape(s))
+	}
+	return b.String()
+}
+
+// GetPermalinkViewInput identifies the page of a permalink. The view is of the repository if Branch is empty, of the branch if TargetID and VulnID are empty, and of the target or the finding otherwise.
+type GetPermalinkViewInput struct {
+	Owner    string
+	Repo     string
+	Branch   types.BranchName
+	TargetID types.TargetID
+	VulnID   string
+}
+
+// PermalinkView is the JSON detail view which a permalink resolves to
+type PermalinkView struct {
+	Permalink     string   `json:"permalink,omitempty"`
+	Repository    string   `json:"repository"`
+	GitHubURL     string   `json:"github_url"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	Team          string   `json:"team,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+
+	// Branches is set in the view of a repository
+	Branches []*BranchStatus `json:"branches,omitempty"`
+	// Branch is set in the view of a branch, a target and a finding
+	Branch *BranchStatus `json:"branch,omitempty"`
+	// Target is set in the view of a target
+	Target string `json:"target,omitempty"`
+	// Findings are active findings of the branch or the target, or the finding in any status in every target of the branch
+	Findings []*Finding `json:"findings,omitempty"`
+}
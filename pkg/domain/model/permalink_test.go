@@ -0,0 +1,27 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestPermalinks(t *testing.T) {
+	links := model.NewPermalinks("https://octovy.example.com/")
+	repoID := types.NewGitHubRepoID("my-org", "my-repo")
+
+	gt.V(t, links.Repository(repoID)).Equal("https://octovy.example.com/r/my-org/my-repo")
+	gt.V(t, links.Branch(repoID, "feature/x")).Equal("https://octovy.example.com/r/my-org/my-repo/b/feature%2Fx")
+	gt.V(t, links.Target(repoID, "main", model.ToTargetID("go.mod", "", ""))).
+		Equal("https://octovy.example.com/r/my-org/my-repo/b/main/t/" + string(model.ToTargetID("go.mod", "", "")))
+	gt.V(t, links.Finding(repoID, "main", "CVE-2024-0001")).Equal("https://octovy.example.com/r/my-org/my-repo/b/main/v/CVE-2024-0001")
+
+	// Missing segment builds no link
+	gt.V(t, links.Finding(repoID, "", "CVE-2024-0001")).Equal("")
+
+	var disabled *model.Permalinks
+	gt.V(t, model.NewPermalinks("")).Equal(disabled)
+	gt.V(t, disabled.Repository(repoID)).Equal("")
+}
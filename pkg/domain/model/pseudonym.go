@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pseudonyms maps organization identifying names to stable pseudonyms, e.g. "acme" to "owner-1", to share findings outside of the organization. It is kept as a local mapping file, so that the same names get the same pseudonyms across exports and shared results can be re-identified internally.
+type Pseudonyms struct {
+	Owners       map[string]string `json:"owners"`
+	Repositories map[string]string `json:"repositories"` // key is "owner/repo"
+	Teams        map[string]string `json:"teams"`
+	Tags         map[string]string `json:"tags"`
+}
+
+func NewPseudonyms() *Pseudonyms {
+	x := &Pseudonyms{}
+	x.init()
+	return x
+}
+
+// init allocates maps missing in a mapping file, e.g. written by an older version
+func (x *Pseudonyms) init() {
+	if x.Owners == nil {
+		x.Owners = make(map[string]string)
+	}
+	if x.Repositories == nil {
+		x.Repositories = make(map[string]string)
+	}
+	if x.Teams == nil {
+		x.Teams = make(map[string]string)
+	}
+	if x.Tags == nil {
+		x.Tags = make(map[string]string)
+	}
+}
+
+// pseudonym returns the pseudonym of the name, assigning the next number of the kind if the name is new. Names are compared case-insensitively because GitHub names are.
+func pseudonym(m map[string]string, kind, name string) string {
+	key := strings.ToLower(name)
+	if p, ok := m[key]; ok {
+		return p
+	}
+	p := fmt.Sprintf("%s-%d", kind, len(m)+1)
+	m[key] = p
+	return p
+}
+
+// AnonymizeFinding returns a copy of the finding whose repository, team and tags are replaced with pseudonyms. The owner is also replaced where it appears as a path segment of the target or the package name, e.g. "github.com/acme/lib", because module paths of internal packages include it.
+func (x *Pseudonyms) AnonymizeFinding(f *Finding) *Finding {
+	x.init()
+	anon := *f
+
+	var ownerPattern *regexp.Regexp
+	var ownerPseudonym string
+	if owner, repo, ok := strings.Cut(f.Repository, "/"); ok {
+		ownerPseudonym = pseudonym(x.Owners, "owner", owner)
+		anon.Repository = ownerPseudonym + "/" + pseudonym(x.Repositories, "repo", owner+"/"+repo)
+		ownerPattern = regexp.MustCompile(`(?i)(^|[/@:])` + regexp.QuoteMeta(owner) + `($|/)`)
+	}
+
+	if f.Team != "" {
+		anon.Team = pseudonym(x.Teams, "team", f.Team)
+	}
+	if len(f.Tags) > 0 {
+		anon.Tags = make([]string, len(f.Tags))
+		for i, tag := range f.Tags {
+			anon.Tags[i] = pseudonym(x.Tags, "tag", tag)
+		}
+	}
+
+	if ownerPattern != nil {
+		replace := "${1}" + ownerPseudonym + "${2}"
+		anon.Target = ownerPattern.ReplaceAllString(f.Target, replace)
+		anon.PkgName = ownerPattern.ReplaceAllString(f.PkgName, replace)
+	}
+	// A permalink has the real owner and repository, and is not reachable from outside anyway
+	anon.Permalink = ""
+
+	return &anon
+}
@@ -0,0 +1,46 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestPseudonymsAnonymizeFinding(t *testing.T) {
+	p := model.NewPseudonyms()
+
+	f1 := p.AnonymizeFinding(&model.Finding{
+		Repository: "Acme/payments",
+		Branch:     "main",
+		Team:       "backend",
+		Tags:       []string{"tier-1", "pci"},
+		Target:     "services/acme/go.mod",
+		ID:         "CVE-2024-0001",
+		PkgName:    "github.com/acme/internal-lib",
+		Permalink:  "https://octovy.example.com/r/Acme/payments/b/main/v/CVE-2024-0001",
+	})
+	gt.V(t, f1.Repository).Equal("owner-1/repo-1")
+	gt.V(t, f1.Branch).Equal("main")
+	gt.V(t, f1.Team).Equal("team-1")
+	gt.V(t, f1.Tags).Equal([]string{"tag-1", "tag-2"})
+	gt.V(t, f1.Target).Equal("services/owner-1/go.mod")
+	gt.V(t, f1.PkgName).Equal("github.com/owner-1/internal-lib")
+	gt.V(t, f1.ID).Equal("CVE-2024-0001")
+	gt.V(t, f1.Permalink).Equal("")
+
+	// Same names get the same pseudonyms, and a package merely containing the owner name is kept
+	f2 := p.AnonymizeFinding(&model.Finding{
+		Repository: "acme/web",
+		Team:       "Backend",
+		Tags:       []string{"pci"},
+		Target:     "package-lock.json",
+		PkgName:    "acme-ui",
+	})
+	gt.V(t, f2.Repository).Equal("owner-1/repo-2")
+	gt.V(t, f2.Team).Equal("team-1")
+	gt.V(t, f2.Tags).Equal([]string{"tag-2"})
+	gt.V(t, f2.PkgName).Equal("acme-ui")
+
+	gt.V(t, p.Repositories).Equal(map[string]string{"acme/payments": "repo-1", "acme/web": "repo-2"})
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// purlTypes maps result types of Trivy (and of Grype converted into them) to package URL types. OS packages are not mapped because their package URL requires the distribution, which is not in a result.
+var purlTypes = map[string]string{
+	"gomod":           "golang",
+	"gobinary":        "golang",
+	"npm":             "npm",
+	"yarn":            "npm",
+	"pnpm":            "npm",
+	"bun":             "npm",
+	"node-pkg":        "npm",
+	"pip":             "pypi",
+	"pipenv":          "pypi",
+	"poetry":          "pypi",
+	"uv":              "pypi",
+	"python-pkg":      "pypi",
+	"pom":             "maven",
+	"gradle":          "maven",
+	"sbt":             "maven",
+	"jar":             "maven",
+	"cargo":           "cargo",
+	"rustbinary":      "cargo",
+	"composer":        "composer",
+	"composer-vendor": "composer",
+	"bundler":         "gem",
+	"gemspec":         "gem",
+	"nuget":           "nuget",
+	"dotnet-core":     "nuget",
+	"packages-props":  "nuget",
+	"conan":           "conan",
+	"hex":             "hex",
+	"pub":             "pub",
+	"swift":           "swift",
+	"cocoapods":       "cocoapods",
+}
+
+// lowerCasePURLTypes are package URL types whose namespace and name are case insensitive by the specification
+var lowerCasePURLTypes = map[string]bool{
+	"npm":       true,
+	"pypi":      true,
+	"github":    true,
+	"bitbucket": true,
+	"composer":  true,
+	"hex":       true,
+}
+
+// NewPURL builds a package URL from a result type of Trivy, e.g. "gomod", and a package. It returns an empty string if the ecosystem has no package URL type or the name is empty.
+func NewPURL(ecosystem, name, version string) string {
+	purlType, ok := purlTypes[ecosystem]
+	if !ok || name == "" {
+		return ""
+	}
+
+	// A Maven package is named "groupId:artifactId" by Trivy
+	if purlType == "maven" {
+		name = strings.Replace(name, ":", "/", 1)
+	}
+
+	var segments []string
+	for _, s := range strings.Split(name, "/") {
+		if s != "" {
+			segments = append(segments, escapePURL(s))
+		}
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+
+	purl := "pkg:" + purlType + "/" + strings.Join(segments, "/")
+	if version != "" {
+		purl += "@" + escapePURL(version)
+	}
+	return NormalizePURL(purl)
+}
+
+// NormalizePURL converts a package URL into the canonical form of the specification, so that package URLs of different scanners can be compared as strings: the type, and the namespace and name of case insensitive types, are lower cased, "_" of a PyPI name is replaced with "-", and qualifiers are sorted by key. It returns an empty string if purl is not a package URL.
+func NormalizePURL(purl string) string {
+	rest, ok := cutPrefixFold(strings.TrimSpace(purl), "pkg:")
+	if !ok {
+		return ""
+	}
+	rest = strings.TrimLeft(rest, "/")
+
+	rest, subpath, _ := strings.Cut(rest, "#")
+	rest, qualifiers, _ := strings.Cut(rest, "?")
+
+	purlType, path, ok := strings.Cut(rest, "/")
+	if !ok || purlType == "" || path == "" {
+		return ""
+	}
+	purlType = strings.ToLower(purlType)
+
+	// The version is after the last "@" because a namespace may start with "%40" or "@", e.g. an npm scope
+	var version string
+	if i := strings.LastIndex(path, "@"); i > 0 {
+		path, version = path[:i], path[i+1:]
+	}
+
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s == "" {
+			continue
+		}
+		if decoded, err := url.PathUnescape(s); err == nil {
+			s = decoded
+		}
+		if lowerCasePURLTypes[purlType] {
+			s = strings.ToLower(s)
+		}
+		segments = append(segments, escapePURL(s))
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	if purlType == "pypi" {
+		last := len(segments) - 1
+		segments[last] = strings.ReplaceAll(segments[last], "_", "-")
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:" + purlType + "/" + strings.Join(segments, "/"))
+	if version != "" {
+		if decoded, err := url.PathUnescape(version); err == nil {
+			version = decoded
+		}
+		b.WriteString("@" + escapePURL(version))
+	}
+	if q := normalizePURLQualifiers(qualifiers); q != "" {
+		b.WriteString("?" + q)
+	}
+	if subpath = strings.Trim(subpath, "/"); subpath != "" {
+		b.WriteString("#" + subpath)
+	}
+	return b.String()
+}
+
+// normalizePURLQualifiers lower cases keys, drops empty values and sorts qualifiers by key
+func normalizePURLQualifiers(qualifiers string) string {
+	var pairs []string
+	for _, q := range strings.Split(qualifiers, "&") {
+		key, value, _ := strings.Cut(q, "=")
+		if key == "" || value == "" {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		pairs = append(pairs, strings.ToLower(key)+"="+url.QueryEscape(value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escapePURL percent-encodes a segment of a package URL. "@" is also encoded to be distinguished from the version separator, e.g. "%40babel" of an npm scope.
+func escapePURL(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
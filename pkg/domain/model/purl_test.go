@@ -0,0 +1,80 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+)
+
+func TestNormalizePURL(t *testing.T) {
+	testCases := map[string]struct {
+		input  string
+		expect string
+	}{
+		"canonical":            {"pkg:golang/golang.org/x/net@v0.17.0", "pkg:golang/golang.org/x/net@v0.17.0"},
+		"case sensitive name":  {"PKG:Golang/github.com/BurntSushi/toml@v1.0.0", "pkg:golang/github.com/BurntSushi/toml@v1.0.0"},
+		"npm scope is encoded": {"pkg:npm/@Babel/Core@7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		"encoded npm scope":    {"pkg:npm/%40babel/core@7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		"pypi name":            {"pkg:pypi/Django_Rest@3.0", "pkg:pypi/django-rest@3.0"},
+		"sorted qualifiers":    {"pkg:maven/org.apache/commons@1.0?Type=jar&classifier=sources&repository_url=", "pkg:maven/org.apache/commons@1.0?classifier=sources&type=jar"},
+		"subpath":              {"pkg:golang/google.golang.org/genproto@v1#/googleapis/api/", "pkg:golang/google.golang.org/genproto@v1#googleapis/api"},
+		"without version":      {"pkg:cargo/serde", "pkg:cargo/serde"},
+		"not a package URL":    {"b2a46a4b-8367-4bae-9820-95557cfe03a8", ""},
+		"without name":         {"pkg:npm", ""},
+		"empty":                {"", ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gt.V(t, model.NormalizePURL(tc.input)).Equal(tc.expect)
+		})
+	}
+}
+
+func TestNewPURL(t *testing.T) {
+	testCases := map[string]struct {
+		ecosystem string
+		name      string
+		version   string
+		expect    string
+	}{
+		"go module":         {"gomod", "golang.org/x/net", "v0.17.0", "pkg:golang/golang.org/x/net@v0.17.0"},
+		"npm scope":         {"npm", "@babel/core", "7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		"pypi":              {"poetry", "Django_Rest", "3.0", "pkg:pypi/django-rest@3.0"},
+		"maven":             {"pom", "org.apache:commons", "1.0", "pkg:maven/org.apache/commons@1.0"},
+		"os package":        {"debian", "openssl", "3.0.11-1", ""},
+		"unknown ecosystem": {"", "openssl", "3.0.11", ""},
+		"without name":      {"npm", "", "1.0.0", ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gt.V(t, model.NewPURL(tc.ecosystem, tc.name, tc.version)).Equal(tc.expect)
+		})
+	}
+}
+
+func TestNewVulnerabilityPURL(t *testing.T) {
+	t.Run("identifier of the scanner is normalized", func(t *testing.T) {
+		vuln := model.NewVulnerability(&trivy.DetectedVulnerability{
+			PkgName:       "@babel/core",
+			PkgIdentifier: &trivy.PackageIdentifier{PURL: "pkg:npm/@babel/core@7.0.0"},
+		})
+		gt.V(t, vuln.PURL).Equal("pkg:npm/%40babel/core@7.0.0")
+	})
+
+	t.Run("package reference of SBOM", func(t *testing.T) {
+		vuln := model.NewVulnerability(&trivy.DetectedVulnerability{
+			PkgName: "serde",
+			PkgRef:  "pkg:cargo/serde@1.0.0",
+		})
+		gt.V(t, vuln.PURL).Equal("pkg:cargo/serde@1.0.0")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		vuln := model.NewVulnerability(&trivy.DetectedVulnerability{PkgName: "serde"})
+		gt.V(t, vuln.PURL).Equal("")
+	})
+}
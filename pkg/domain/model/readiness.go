@@ -0,0 +1,17 @@
+package model
+
+// Readiness is the result of checking external dependencies of the server. Ready is true if all checks succeeded.
+type Readiness struct {
+	Ready        bool                `json:"ready"`
+	Dependencies []*DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus is the result of checking an external dependency. Dependencies which are not configured are not checked.
+type DependencyStatus struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	// Version is set if the dependency reports it, e.g. the version of the Trivy binary
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
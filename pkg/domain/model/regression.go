@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// MergedPullRequest is a pull request associated with a commit, resolved via GitHub commits API
+type MergedPullRequest struct {
+	Number   int
+	Title    string
+	URL      string
+	Author   string
+	MergedAt time.Time
+}
+
+// IntroducedVulnerability is a vulnerability which became active in a scan target
+type IntroducedVulnerability struct {
+	Target    string
+	Permalink string
+	*Vulnerability
+}
+
+// DefaultBranchRegression represents vulnerabilities introduced into the default branch by a commit, compared with the previous default branch scan.
+type DefaultBranchRegression struct {
+	RepoID            types.GitHubRepoID
+	Branch            types.BranchName
+	CommitSHA         types.CommitSHA
+	PreviousCommitSHA types.CommitSHA
+	ScanID            types.ScanID
+	Team              string
+	Tags              []string
+	PullRequests      []*MergedPullRequest
+	Vulnerabilities   []*IntroducedVulnerability
+	Permalink         string
+}
+
+func (x *DefaultBranchRegression) LogValue() slog.Value {
+	prs := make([]string, len(x.PullRequests))
+	for i, pr := range x.PullRequests {
+		prs[i] = fmt.Sprintf("#%d %s (@%s)", pr.Number, pr.URL, pr.Author)
+	}
+	vulns := make([]string, len(x.Vulnerabilities))
+	for i, v := range x.Vulnerabilities {
+		vulns[i] = fmt.Sprintf("%s %s@%s (%s) in %s", v.ID, v.PkgName, v.InstalledVersion, v.Severity, v.Target)
+		if v.Permalink != "" {
+			vulns[i] += " " + v.Permalink
+		}
+	}
+
+	attrs := []slog.Attr{
+		slog.Any("repo_id", x.RepoID),
+		slog.Any("branch", x.Branch),
+		slog.Any("commit", x.CommitSHA),
+		slog.Any("previous_commit", x.PreviousCommitSHA),
+		slog.Any("scan_id", x.ScanID),
+		slog.String("team", x.Team),
+		slog.Any("tags", x.Tags),
+		slog.Any("pull_requests", prs),
+		slog.Any("vulnerabilities", vulns),
+	}
+	if x.Permalink != "" {
+		attrs = append(attrs, slog.String("permalink", x.Permalink))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ListFindingsInput is input for listing findings for triage, either stored in Firestore or in a local Trivy report
+type ListFindingsInput struct {
+	Owner  string
+	Repo   string           // optional; if not set, all repositories of the owner in Firestore
+	Branch types.BranchName // optional; if not set, the default branch of each repository
+
+	// MinSeverity filters findings below the severity out. Empty means no filter.
+	MinSeverity types.Severity
+	// Statuses filters findings by status. Empty means active findings only.
+	Statuses []types.VulnStatus
+	// Tag and Team filter repositories by their metadata. Empty means no filter. Not available with Report.
+	Tag  string
+	Team string
+
+	// Report is read instead of Firestore if set. All findings of a report are active.
+	Report *trivy.Report
+}
+
+// Finding is a finding of a repository branch for triage
+type Finding struct {
+	Repository       string            `json:"repository,omitempty"`
+	Branch           string            `json:"branch,omitempty"`
+	Team             string            `json:"team,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Target           string            `json:"target"`
+	Type             types.FindingType `json:"type"`
+	ID               string            `json:"id"`
+	Severity         string            `json:"severity"`
+	OriginalSeverity string            `json:"original_severity,omitempty"`
+	Status           types.VulnStatus  `json:"status"`
+	PkgName          string            `json:"pkg_name,omitempty"`
+	InstalledVersion string            `json:"installed_version,omitempty"`
+	FixedVersion     string            `json:"fixed_version,omitempty"`
+	Title            string            `json:"title,omitempty"`
+	Source           string            `json:"source,omitempty"`
+	Exploitability   *Exploitability   `json:"exploitability,omitempty"`
+	Permalink        string            `json:"permalink,omitempty"`
+}
+
+// NewFinding converts a stored finding of the target
+func NewFinding(repository, branch, target string, vuln *Vulnerability) *Finding {
+	findingType := vuln.Type
+	if findingType == "" {
+		findingType = types.FindingTypeVulnerability
+	}
+	return &Finding{
+		Repository:       repository,
+		Branch:           branch,
+		Target:           target,
+		Type:             findingType,
+		ID:               vuln.ID,
+		Severity:         vuln.Severity,
+		OriginalSeverity: vuln.OriginalSeverity,
+		Status:           vuln.Status,
+		PkgName:          vuln.PkgName,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Title:            vuln.Title,
+		Source:           vuln.Source,
+		Exploitability:   vuln.Exploitability,
+	}
+}
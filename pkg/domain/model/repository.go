@@ -1,6 +1,8 @@
 package model
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/m-mizutani/octovy/pkg/domain/types"
@@ -13,6 +15,121 @@ type Repository struct {
 	Name           string
 	DefaultBranch  types.BranchName
 	InstallationID int64
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+
+	// Tags are labels to filter and group repositories in reports, e.g. GitHub topics. Normalized by NormalizeTags.
+	Tags []string
+	// Team is slug of the GitHub team accountable for vulnerabilities of the repository
+	Team string
+	// ScanConfig limits directories to scan. It is used only if the repository does not have ScanConfigFileName.
+	ScanConfig *ScanConfig
+	// PullRequestCheck overrides the server-wide setting of the vulnerability check run of pull requests. nil uses the server-wide setting.
+	PullRequestCheck *PullRequestCheckConfig
+
+	// LastScanAt is when any branch of the repository was last scanned
+	LastScanAt time.Time
+	// Findings is Branch.Findings of the default branch, to list repositories with their findings without reading their branches
+	Findings *FindingSummary
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HasTag returns true if the repository has the tag. Tags are compared case-insensitively.
+func (x *Repository) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range x.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchMetadata returns true if the repository has the tag and belongs to the team. Empty tag or team matches any repository.
+func (x *Repository) MatchMetadata(tag, team string) bool {
+	if tag != "" && !x.HasTag(tag) {
+		return false
+	}
+	if team != "" && x.Team != NormalizeTeam(team) {
+		return false
+	}
+	return true
+}
+
+// NormalizeTags lower-cases and trims tags, and drops empty and duplicated ones. The result is sorted.
+func NormalizeTags(tags []string) []string {
+	result := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTeam lower-cases and trims a team slug. GitHub team slugs are case-insensitive.
+func NormalizeTeam(team string) string {
+	return strings.ToLower(strings.TrimSpace(team))
+}
+
+// DeleteRepositoryDataInput selects stored data of all repositories of Owner, or of Repo if set, to delete
+type DeleteRepositoryDataInput struct {
+	Owner string
+	Repo  string
+	// BigQuery also deletes rows of the repositories from BigQuery tables
+	BigQuery bool
+	// DryRun only counts the data without deleting it
+	DryRun bool
+}
+
+// DeleteRepositoryDataResult is the data deleted, or to be deleted in dry run
+type DeleteRepositoryDataResult struct {
+	DryRun       bool
+	Repositories []*DeletedRepository
+	BigQuery     []*DeletedBigQueryRows
+}
+
+// DeletedRepository is the number of Firestore documents of a repository. Status history of vulnerabilities is deleted with them but not counted.
+type DeletedRepository struct {
+	ID              types.GitHubRepoID
+	Branches        int
+	Targets         int
+	Vulnerabilities int
+	ScanRecords     int
+}
+
+// DeletedBigQueryRows is the number of rows of a BigQuery table. Table is "scan", "vulnerability" or "package".
+type DeletedBigQueryRows struct {
+	Table string
+	Rows  int64
+}
+
+// BackfillScanRepositoryInput selects scans stored in BigQuery to replay to the scan repository. All scans are replayed if Owner is empty, and only scans of Repo if it is set.
+type BackfillScanRepositoryInput struct {
+	Owner string
+	Repo  string
+	// DryRun only counts the scans to be replayed
+	DryRun bool
+}
+
+// BackfillScanRepositoryResult is the number of scans replayed, or to be replayed in dry run, for each repository
+type BackfillScanRepositoryResult struct {
+	DryRun       bool
+	Repositories []*BackfilledRepository
+}
+
+// BackfilledRepository is the number of scans of a repository. Skipped is scans not newer than the last scan of the branch in the scan repository.
+type BackfilledRepository struct {
+	ID      types.GitHubRepoID
+	Scans   int
+	Skipped int
 }
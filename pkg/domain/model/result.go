@@ -12,6 +12,22 @@ type Scan struct {
 	Timestamp time.Time      `bigquery:"timestamp" json:"timestamp"`
 	GitHub    GitHubMetadata `bigquery:"github" json:"github"`
 	Report    trivy.Report   `bigquery:"report" json:"report"`
+
+	// Owner and RepoName are copies of GitHub.Owner and GitHub.RepoName as top-level columns to cluster the table by
+	Owner    string `bigquery:"owner" json:"owner"`
+	RepoName string `bigquery:"repo_name" json:"repo_name"`
+
+	// ImageAnalysis is set only for container image scans
+	ImageAnalysis *ImageAnalysis `bigquery:"image_analysis" json:"image_analysis,omitempty"`
+
+	// Coverage is set only for scans of source code run by octovy
+	Coverage *ScanCoverage `bigquery:"coverage" json:"coverage,omitempty"`
+
+	// Incremental is set only for scans of pull requests limited to directories of changed files
+	Incremental *IncrementalScan `bigquery:"incremental" json:"incremental,omitempty"`
+
+	// Archive is set only for scans of source code fetched as a zip archive
+	Archive *ScanArchive `bigquery:"archive" json:"archive,omitempty"`
 }
 
 type ScanRawRecord struct {
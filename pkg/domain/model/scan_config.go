@@ -0,0 +1,91 @@
+package model
+
+import (
+	"path"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ScanConfigFileName is the file in the repository root which configures the scan of the repository
+const ScanConfigFileName = ".octovy.yml"
+
+// ScanConfig limits directories of a repository scanned by Trivy, e.g. to scan only some services of a monorepo. It is read from ScanConfigFileName of the repository, or stored in the Repository if the file does not exist.
+type ScanConfig struct {
+	// Paths are directories or files scanned, relative to the repository root. The whole repository is scanned if empty.
+	Paths []string `yaml:"paths" json:"paths,omitempty"`
+	// Exclude are directories skipped by Trivy, e.g. "vendor" or "services/*/testdata". A name without slash matches directories of the name at any depth.
+	Exclude []string `yaml:"exclude" json:"exclude,omitempty"`
+}
+
+// Normalize cleans paths and drops empty and duplicated ones. A path of the repository root in Paths clears Paths because it means the whole repository.
+func (x *ScanConfig) Normalize() {
+	x.Paths = normalizeScanPaths(x.Paths)
+	for _, p := range x.Paths {
+		if p == "." {
+			x.Paths = nil
+			break
+		}
+	}
+	x.Exclude = normalizeScanPaths(x.Exclude)
+}
+
+// Validate checks that paths stay in the repository. It should be called after Normalize.
+func (x *ScanConfig) Validate() error {
+	for _, p := range append(append([]string{}, x.Paths...), x.Exclude...) {
+		if p == ".." || strings.HasPrefix(p, "../") {
+			return goerr.Wrap(types.ErrInvalidOption, "scan config path must be in the repository", goerr.V("path", p))
+		}
+	}
+	for _, p := range x.Exclude {
+		if p == "." {
+			return goerr.Wrap(types.ErrInvalidOption, "scan config can not exclude the repository root")
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if the config does not limit the scan
+func (x *ScanConfig) IsEmpty() bool {
+	return x == nil || (len(x.Paths) == 0 && len(x.Exclude) == 0)
+}
+
+// SkipDirPatterns returns patterns of Exclude for --skip-dirs of Trivy, which are matched against paths relative to the scan target
+func (x *ScanConfig) SkipDirPatterns() []string {
+	patterns := make([]string, len(x.Exclude))
+	for i, p := range x.Exclude {
+		if !strings.Contains(p, "/") {
+			p = "**/" + p
+		}
+		patterns[i] = p
+	}
+	return patterns
+}
+
+func normalizeScanPaths(paths []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		p = path.Clean(strings.TrimPrefix(strings.ReplaceAll(p, "\\", "/"), "/"))
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// ScanTarget is a directory scanned by a scanner. ScanConfig of the repository is resolved into SkipDirs and SkipFiles, which are paths relative to Dir or glob patterns such as "**/vendor".
+type ScanTarget struct {
+	Dir       string
+	SkipDirs  []string
+	SkipFiles []string
+	// IgnoreFile is the ignore file of Trivy relative to Dir, or empty if the repository has no ignore file
+	IgnoreFile string
+}
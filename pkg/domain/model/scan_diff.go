@@ -0,0 +1,161 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// DiffScansInput selects two scans to compare. Base and Head are a scan ID or a commit SHA. Owner and Repo are required to select a scan by commit SHA, and the latest scan of the commit is used.
+type DiffScansInput struct {
+	Owner string
+	Repo  string
+	Base  string
+	Head  string
+}
+
+// ScanDiff is vulnerabilities added, removed and unchanged from the base scan to the head scan
+type ScanDiff struct {
+	Base    *ScanDiffRef  `json:"base"`
+	Head    *ScanDiffRef  `json:"head"`
+	Targets []*TargetDiff `json:"targets"`
+}
+
+// ScanDiffRef identifies a compared scan
+type ScanDiffRef struct {
+	ScanID    types.ScanID `json:"scan_id"`
+	Owner     string       `json:"owner"`
+	Repo      string       `json:"repo"`
+	Branch    string       `json:"branch"`
+	CommitID  string       `json:"commit_id"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// TargetDiff is the diff of a target which exists in the base or the head scan
+type TargetDiff struct {
+	Target    string               `json:"target"`
+	Added     []*DiffVulnerability `json:"added"`
+	Removed   []*DiffVulnerability `json:"removed"`
+	Unchanged []*DiffVulnerability `json:"unchanged"`
+}
+
+// DiffVulnerability is a vulnerability of a package. An unchanged vulnerability has the installed version of the head scan, which may differ from the base scan.
+type DiffVulnerability struct {
+	VulnID           string `json:"vuln_id"`
+	PkgName          string `json:"pkg_name"`
+	InstalledVersion string `json:"installed_version"`
+	FixedVersion     string `json:"fixed_version"`
+	Severity         string `json:"severity"`
+	Title            string `json:"title"`
+}
+
+func newScanDiffRef(scan *Scan) *ScanDiffRef {
+	return &ScanDiffRef{
+		ScanID:    scan.ID,
+		Owner:     scan.GitHub.Owner,
+		Repo:      scan.GitHub.RepoName,
+		Branch:    scan.GitHub.Branch,
+		CommitID:  scan.GitHub.CommitID,
+		Timestamp: scan.Timestamp,
+	}
+}
+
+// NewScanDiff compares vulnerabilities of the scans per target. A vulnerability is identified by the target, the vulnerability ID and the package name regardless of the installed version, so that a version bump of a still vulnerable package is neither added nor removed. Targets are sorted by name.
+func NewScanDiff(base, head *Scan) *ScanDiff {
+	diff := &ScanDiff{
+		Base:    newScanDiffRef(base),
+		Head:    newScanDiffRef(head),
+		Targets: []*TargetDiff{},
+	}
+
+	baseVulns := diffVulnerabilities(&base.Report)
+	headVulns := diffVulnerabilities(&head.Report)
+
+	targets := map[string]*TargetDiff{}
+	targetOf := func(name string) *TargetDiff {
+		if t, ok := targets[name]; ok {
+			return t
+		}
+		t := &TargetDiff{
+			Target:    name,
+			Added:     []*DiffVulnerability{},
+			Removed:   []*DiffVulnerability{},
+			Unchanged: []*DiffVulnerability{},
+		}
+		targets[name] = t
+		diff.Targets = append(diff.Targets, t)
+		return t
+	}
+
+	for target, vulns := range headVulns {
+		t := targetOf(target)
+		for key, vuln := range vulns {
+			if _, ok := baseVulns[target][key]; ok {
+				t.Unchanged = append(t.Unchanged, vuln)
+			} else {
+				t.Added = append(t.Added, vuln)
+			}
+		}
+	}
+	for target, vulns := range baseVulns {
+		t := targetOf(target)
+		for key, vuln := range vulns {
+			if _, ok := headVulns[target][key]; !ok {
+				t.Removed = append(t.Removed, vuln)
+			}
+		}
+	}
+
+	sort.Slice(diff.Targets, func(i, j int) bool { return diff.Targets[i].Target < diff.Targets[j].Target })
+	for _, t := range diff.Targets {
+		sortDiffVulnerabilities(t.Added)
+		sortDiffVulnerabilities(t.Removed)
+		sortDiffVulnerabilities(t.Unchanged)
+	}
+
+	return diff
+}
+
+// diffVulnerabilities returns vulnerabilities of the report by target and key. Results without vulnerabilities are included so that a target without change is listed.
+func diffVulnerabilities(report *trivy.Report) map[string]map[string]*DiffVulnerability {
+	vulns := map[string]map[string]*DiffVulnerability{}
+	for i := range report.Results {
+		result := &report.Results[i]
+		if _, ok := vulns[result.Target]; !ok {
+			vulns[result.Target] = map[string]*DiffVulnerability{}
+		}
+		for j := range result.Vulnerabilities {
+			v := &result.Vulnerabilities[j]
+			vulns[result.Target][v.VulnerabilityID+"|"+v.PkgName] = &DiffVulnerability{
+				VulnID:           v.VulnerabilityID,
+				PkgName:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         v.Severity,
+				Title:            v.Title,
+			}
+		}
+	}
+	return vulns
+}
+
+func sortDiffVulnerabilities(vulns []*DiffVulnerability) {
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].VulnID != vulns[j].VulnID {
+			return vulns[i].VulnID < vulns[j].VulnID
+		}
+		return vulns[i].PkgName < vulns[j].PkgName
+	})
+}
+
+// Counts returns the number of added, removed and unchanged vulnerabilities of all targets
+func (x *ScanDiff) Counts() (added, removed, unchanged int) {
+	for _, t := range x.Targets {
+		added += len(t.Added)
+		removed += len(t.Removed)
+		unchanged += len(t.Unchanged)
+	}
+	return added, removed, unchanged
+}
@@ -0,0 +1,72 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func newDiffScan(id string, results ...trivy.Result) *model.Scan {
+	return &model.Scan{
+		ID:     types.ScanID(id),
+		Report: trivy.Report{Results: results},
+	}
+}
+
+func diffVuln(id, pkg, version string) trivy.DetectedVulnerability {
+	return trivy.DetectedVulnerability{
+		VulnerabilityID:  id,
+		PkgName:          pkg,
+		InstalledVersion: version,
+		Vulnerability:    trivy.Vulnerability{Severity: "HIGH"},
+	}
+}
+
+func TestNewScanDiff(t *testing.T) {
+	base := newDiffScan("base",
+		trivy.Result{Target: "go.mod", Vulnerabilities: []trivy.DetectedVulnerability{
+			diffVuln("CVE-2024-0001", "golang.org/x/net", "v0.15.0"),
+			diffVuln("CVE-2024-0002", "golang.org/x/text", "v0.3.0"),
+		}},
+		trivy.Result{Target: "web/package-lock.json", Vulnerabilities: []trivy.DetectedVulnerability{
+			diffVuln("CVE-2024-0003", "lodash", "4.17.20"),
+		}},
+	)
+	head := newDiffScan("head",
+		trivy.Result{Target: "go.mod", Vulnerabilities: []trivy.DetectedVulnerability{
+			// Still vulnerable after version bump
+			diffVuln("CVE-2024-0001", "golang.org/x/net", "v0.16.0"),
+			diffVuln("CVE-2024-0004", "golang.org/x/crypto", "v0.1.0"),
+		}},
+		trivy.Result{Target: "Cargo.lock"},
+	)
+
+	diff := model.NewScanDiff(base, head)
+	gt.V(t, diff.Base.ScanID).Equal(types.ScanID("base"))
+	gt.V(t, diff.Head.ScanID).Equal(types.ScanID("head"))
+	gt.A(t, diff.Targets).Length(3)
+
+	gt.V(t, diff.Targets[0].Target).Equal("Cargo.lock")
+	gt.A(t, diff.Targets[0].Added).Length(0)
+
+	goMod := diff.Targets[1]
+	gt.V(t, goMod.Target).Equal("go.mod")
+	gt.A(t, goMod.Added).Length(1)
+	gt.V(t, goMod.Added[0].VulnID).Equal("CVE-2024-0004")
+	gt.A(t, goMod.Removed).Length(1)
+	gt.V(t, goMod.Removed[0].VulnID).Equal("CVE-2024-0002")
+	gt.A(t, goMod.Unchanged).Length(1)
+	gt.V(t, goMod.Unchanged[0].InstalledVersion).Equal("v0.16.0")
+
+	npm := diff.Targets[2]
+	gt.V(t, npm.Target).Equal("web/package-lock.json")
+	gt.A(t, npm.Removed).Length(1)
+
+	added, removed, unchanged := diff.Counts()
+	gt.V(t, added).Equal(1)
+	gt.V(t, removed).Equal(2)
+	gt.V(t, unchanged).Equal(1)
+}
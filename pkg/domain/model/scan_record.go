@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ScanRecord is an entry of the scan history of a repository. One record is stored for every scan
+// inserted into Firestore.
+type ScanRecord struct {
+	ID types.ScanID
+	// RepoID is set by ScanRepository when the record is created
+	RepoID    types.GitHubRepoID
+	Branch    types.BranchName
+	CommitSHA types.CommitSHA
+	// CommitterLogin is the lower-cased GitHub login of the committer of CommitSHA, empty if unknown
+	CommitterLogin        string
+	Timestamp             time.Time
+	TargetCount           int
+	VulnerabilityCount    int
+	MisconfigurationCount int
+	SecretCount           int
+	// TrivyVersion is the version of Trivy which created the report, empty for other scanners
+	TrivyVersion string
+}
+
+// PruneScanHistoryInput is input for deleting scan history and resolved findings older than Keep
+type PruneScanHistoryInput struct {
+	Owner string
+	Keep  time.Duration
+}
+
+// PruneScanHistoryResult is the number of deleted records
+type PruneScanHistoryResult struct {
+	Repositories           int
+	DeletedScans           int
+	DeletedVulnerabilities int
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ScanRunRetention is how long a ScanRun is kept. ExpiresAt of a run is set by it so that a TTL policy of Firestore can delete old runs.
+const ScanRunRetention = 30 * 24 * time.Hour
+
+// ScanRun tracks a requested scan from when it is queued until it finishes, so that callers of asynchronous scans, e.g. webhooks answered with 202 Accepted, can see how the scan ended.
+type ScanRun struct {
+	ID      types.ScanRunID
+	Status  types.ScanRunStatus
+	Trigger types.ScanTrigger
+	// RequestID is the HTTP request or the command which requested the scan
+	RequestID types.RequestID
+
+	Owner    string
+	RepoName string
+	Branch   string
+	Tag      string
+	// CommitID is resolved from Tag when the scan starts if it is not given
+	CommitID string
+
+	// ScanID is the stored scan, set when the run succeeded. Skipped is true instead if the commit had already been scanned.
+	ScanID  types.ScanID
+	Skipped bool
+	// Error is a summary of the error if the run failed
+	Error string
+
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// NewScanRun returns a queued run of the scan requested by the input
+func NewScanRun(input *ScanGitHubRepoInput, requestID types.RequestID, now time.Time) *ScanRun {
+	trigger := input.Trigger
+	if trigger == "" {
+		trigger = types.ScanTriggerCLI
+	}
+	return &ScanRun{
+		ID:        types.NewScanRunID(),
+		Status:    types.ScanRunQueued,
+		Trigger:   trigger,
+		RequestID: requestID,
+		Owner:     input.Owner,
+		RepoName:  input.RepoName,
+		Branch:    input.Branch,
+		Tag:       input.Tag,
+		CommitID:  input.CommitID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ScanRunRetention),
+	}
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// SeverityOverride replaces severity of vulnerabilities in all repositories of Owner, e.g. to downgrade a noisy CVE or to upgrade a package critical for the organization. It matches vulnerabilities by VulnID, PkgName or both.
+type SeverityOverride struct {
+	Owner    string
+	VulnID   string
+	PkgName  string
+	Severity types.Severity
+	// Reason and By are recorded for audit
+	Reason    string
+	By        string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ID identifies the override in the owner by VulnID and PkgName. It is a hash because package names can contain characters not allowed in document IDs, e.g. "/".
+func (x *SeverityOverride) ID() string {
+	sum := sha256.Sum256([]byte(x.VulnID + "|" + x.PkgName))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Normalize trims fields and upper-cases VulnID and severity, as Trivy reports them
+func (x *SeverityOverride) Normalize() {
+	x.Owner = strings.TrimSpace(x.Owner)
+	x.VulnID = strings.ToUpper(strings.TrimSpace(x.VulnID))
+	x.PkgName = strings.TrimSpace(x.PkgName)
+	x.Severity = types.Severity(strings.ToUpper(strings.TrimSpace(string(x.Severity))))
+}
+
+func (x *SeverityOverride) Validate() error {
+	if x.Owner == "" {
+		return goerr.Wrap(types.ErrInvalidRequest, "owner is required for severity override")
+	}
+	if x.VulnID == "" && x.PkgName == "" {
+		return goerr.Wrap(types.ErrInvalidRequest, "vulnerability ID or package name is required for severity override")
+	}
+	if _, err := types.ParseSeverity(string(x.Severity)); err != nil {
+		return goerr.Wrap(types.ErrInvalidRequest, "invalid severity of severity override", goerr.V("severity", x.Severity))
+	}
+	return nil
+}
+
+// SortSeverityOverrides sorts overrides by vulnerability ID and package name, so that repositories return them in the same order
+func SortSeverityOverrides(overrides []*SeverityOverride) {
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].VulnID != overrides[j].VulnID {
+			return overrides[i].VulnID < overrides[j].VulnID
+		}
+		return overrides[i].PkgName < overrides[j].PkgName
+	})
+}
+
+// SeverityOverrides is a set of overrides of an owner
+type SeverityOverrides []*SeverityOverride
+
+// Find returns the most specific override matching the vulnerability, or nil. An override of both the vulnerability ID and the package is preferred to one of the vulnerability ID, which is preferred to one of the package.
+func (x SeverityOverrides) Find(vulnID, pkgName string) *SeverityOverride {
+	var byVuln, byPkg *SeverityOverride
+	for _, o := range x {
+		switch {
+		case o.VulnID != "" && o.PkgName != "":
+			if strings.EqualFold(o.VulnID, vulnID) && o.PkgName == pkgName {
+				return o
+			}
+		case o.VulnID != "":
+			if strings.EqualFold(o.VulnID, vulnID) {
+				byVuln = o
+			}
+		default:
+			if o.PkgName == pkgName {
+				byPkg = o
+			}
+		}
+	}
+	if byVuln != nil {
+		return byVuln
+	}
+	return byPkg
+}
+
+// Apply replaces severity of vulnerability findings matching an override. The severity reported by the scanner is kept in OriginalSeverity. Misconfigurations and secrets are not overridden.
+func (x SeverityOverrides) Apply(vulns []*Vulnerability) {
+	if len(x) == 0 {
+		return
+	}
+	for _, vuln := range vulns {
+		if vuln.Type != "" && vuln.Type != types.FindingTypeVulnerability {
+			continue
+		}
+		o := x.Find(vuln.ID, vuln.PkgName)
+		if o == nil || string(o.Severity) == vuln.Severity {
+			continue
+		}
+		vuln.OriginalSeverity = vuln.Severity
+		vuln.Severity = string(o.Severity)
+	}
+}
+
+// ApplyToReport returns a copy of the report with overridden severities of vulnerabilities. The report itself is not modified.
+func (x SeverityOverrides) ApplyToReport(report *trivy.Report) *trivy.Report {
+	if len(x) == 0 {
+		return report
+	}
+
+	cpy := *report
+	cpy.Results = make([]trivy.Result, len(report.Results))
+	for i, result := range report.Results {
+		cpy.Results[i] = result
+		if len(result.Vulnerabilities) == 0 {
+			continue
+		}
+		vulns := make([]trivy.DetectedVulnerability, len(result.Vulnerabilities))
+		for j, vuln := range result.Vulnerabilities {
+			if o := x.Find(vuln.VulnerabilityID, vuln.PkgName); o != nil {
+				vuln.Severity = string(o.Severity)
+			}
+			vulns[j] = vuln
+		}
+		cpy.Results[i].Vulnerabilities = vulns
+	}
+	return &cpy
+}
+
+// DeleteSeverityOverrideInput selects the override to delete by the same vulnerability ID and package name as it was set with
+type DeleteSeverityOverrideInput struct {
+	Owner   string
+	VulnID  string
+	PkgName string
+}
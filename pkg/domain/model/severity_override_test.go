@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestSeverityOverrideValidate(t *testing.T) {
+	o := &model.SeverityOverride{Owner: " myorg ", VulnID: " cve-2024-0001", Severity: "low"}
+	o.Normalize()
+	gt.NoError(t, o.Validate())
+	gt.V(t, o.VulnID).Equal("CVE-2024-0001")
+	gt.V(t, o.Severity).Equal(types.SeverityLow)
+
+	gt.Error(t, (&model.SeverityOverride{Owner: "myorg", Severity: types.SeverityLow}).Validate())
+	gt.Error(t, (&model.SeverityOverride{Owner: "myorg", PkgName: "lib", Severity: "urgent"}).Validate())
+	gt.Error(t, (&model.SeverityOverride{PkgName: "lib", Severity: types.SeverityLow}).Validate())
+
+	// ID depends only on the vulnerability ID and the package name
+	a := &model.SeverityOverride{Owner: "myorg", VulnID: "CVE-2024-0001", Severity: types.SeverityLow}
+	b := &model.SeverityOverride{Owner: "myorg", VulnID: "CVE-2024-0001", Severity: types.SeverityHigh, Reason: "changed"}
+	c := &model.SeverityOverride{Owner: "myorg", VulnID: "CVE-2024-0001", PkgName: "lib", Severity: types.SeverityLow}
+	gt.V(t, a.ID()).Equal(b.ID())
+	gt.V(t, a.ID()).NotEqual(c.ID())
+}
+
+func TestSeverityOverridesApply(t *testing.T) {
+	overrides := model.SeverityOverrides{
+		{VulnID: "CVE-2024-0001", Severity: types.SeverityLow},
+		{VulnID: "CVE-2024-0001", PkgName: "payment-sdk", Severity: types.SeverityHigh},
+		{PkgName: "payment-sdk", Severity: types.SeverityCritical},
+	}
+
+	t.Run("most specific override wins", func(t *testing.T) {
+		gt.V(t, overrides.Find("CVE-2024-0001", "payment-sdk").Severity).Equal(types.SeverityHigh)
+		gt.V(t, overrides.Find("CVE-2024-0001", "other").Severity).Equal(types.SeverityLow)
+		gt.V(t, overrides.Find("CVE-2024-0002", "payment-sdk").Severity).Equal(types.SeverityCritical)
+		gt.V(t, overrides.Find("CVE-2024-0002", "other")).Nil()
+	})
+
+	t.Run("keeps original severity of vulnerabilities", func(t *testing.T) {
+		vulns := []*model.Vulnerability{
+			{ID: "CVE-2024-0001", PkgName: "other", Severity: "CRITICAL"},
+			{ID: "CVE-2024-0002", PkgName: "payment-sdk", Severity: "CRITICAL"},
+			{ID: "CVE-2024-0003", PkgName: "other", Severity: "MEDIUM"},
+			{ID: "AVD-DS-0001", Type: types.FindingTypeMisconfiguration, PkgName: "payment-sdk", Severity: "LOW"},
+		}
+		overrides.Apply(vulns)
+
+		gt.V(t, vulns[0].Severity).Equal("LOW")
+		gt.V(t, vulns[0].OriginalSeverity).Equal("CRITICAL")
+		// Same severity as the override is not regarded as overridden
+		gt.V(t, vulns[1].Severity).Equal("CRITICAL")
+		gt.V(t, vulns[1].OriginalSeverity).Equal("")
+		gt.V(t, vulns[2].Severity).Equal("MEDIUM")
+		gt.V(t, vulns[3].Severity).Equal("LOW")
+	})
+
+	t.Run("report is copied", func(t *testing.T) {
+		report := &trivy.Report{Results: []trivy.Result{{
+			Target: "go.mod",
+			Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "other", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+				{VulnerabilityID: "CVE-2024-0003", PkgName: "payment-sdk", Vulnerability: trivy.Vulnerability{Severity: "LOW"}},
+			},
+		}}}
+
+		overridden := overrides.ApplyToReport(report)
+		gt.V(t, model.CountFindingsAtOrAbove(overridden, types.SeverityCritical)).Equal(1)
+		gt.V(t, overridden.Results[0].Vulnerabilities[0].Severity).Equal("LOW")
+		gt.V(t, report.Results[0].Vulnerabilities[0].Severity).Equal("CRITICAL")
+	})
+}
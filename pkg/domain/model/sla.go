@@ -0,0 +1,128 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// SLAPolicy is the time to fix an active vulnerability per severity, counted from its first detection. Severities not in the policy have no SLA.
+type SLAPolicy map[types.Severity]time.Duration
+
+// ParseSLAPolicy parses a comma separated list of severity and time to fix, e.g. "CRITICAL=7d,HIGH=30d". The time is days with "d" suffix or a Go duration such as "72h".
+func ParseSLAPolicy(v string) (SLAPolicy, error) {
+	policy := SLAPolicy{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "SLA must be SEVERITY=TIME", goerr.V("sla", part))
+		}
+		severity, err := types.ParseSeverity(key)
+		if err != nil {
+			return nil, goerr.Wrap(err, "invalid severity of SLA", goerr.V("sla", part))
+		}
+		deadline, err := parseSLADuration(strings.TrimSpace(value))
+		if err != nil || deadline <= 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "time of SLA must be positive days such as 7d or a duration such as 72h", goerr.V("sla", part))
+		}
+		policy[severity] = deadline
+	}
+	return policy, nil
+}
+
+func parseSLADuration(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(v)
+}
+
+// String returns the policy in the format of ParseSLAPolicy, from the highest severity
+func (x SLAPolicy) String() string {
+	severities := make([]types.Severity, 0, len(x))
+	for severity := range x {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		return severities[i].Rank() > severities[j].Rank()
+	})
+
+	parts := make([]string, len(severities))
+	for i, severity := range severities {
+		deadline := x[severity]
+		if deadline%(24*time.Hour) == 0 {
+			parts[i] = string(severity) + "=" + strconv.Itoa(int(deadline/(24*time.Hour))) + "d"
+		} else {
+			parts[i] = string(severity) + "=" + deadline.String()
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// AgeOf returns the age of the vulnerability at now. CreatedAt of a stored vulnerability is its first detection on the branch, and is kept even if it has been fixed and detected again.
+func (x SLAPolicy) AgeOf(vuln *Vulnerability, now time.Time) *VulnerabilityAge {
+	age := &VulnerabilityAge{
+		FirstDetectedAt: vuln.CreatedAt,
+		DaysOpen:        int(now.Sub(vuln.CreatedAt) / (24 * time.Hour)),
+	}
+	if deadline, ok := x[types.Severity(strings.ToUpper(vuln.Severity))]; ok && !vuln.CreatedAt.IsZero() {
+		dueAt := vuln.CreatedAt.Add(deadline)
+		age.DueAt = &dueAt
+		age.Overdue = now.After(dueAt)
+	}
+	return age
+}
+
+// VulnerabilityAge is how long a vulnerability has been open, and its deadline by SLAPolicy
+type VulnerabilityAge struct {
+	FirstDetectedAt time.Time `json:"first_detected_at"`
+	DaysOpen        int       `json:"days_open"`
+	// DueAt is nil if the severity has no SLA
+	DueAt   *time.Time `json:"due_at,omitempty"`
+	Overdue bool       `json:"overdue"`
+}
+
+// CheckSLAInput is input for finding active vulnerabilities which are not fixed within the SLA
+type CheckSLAInput struct {
+	Owner string
+	// Repo is optional; default branches of all repositories of the owner are checked if empty
+	Repo string
+	// Branch is optional and only with Repo; the default branch is used if empty
+	Branch types.BranchName
+}
+
+// SLAReport is active vulnerabilities breaching the SLA
+type SLAReport struct {
+	Owner      string         `json:"owner"`
+	Policy     string         `json:"policy"`
+	Checked    int            `json:"checked_branches"`
+	Violations []SLAViolation `json:"violations"`
+}
+
+// SLAViolation is an active vulnerability which is not fixed by its due date
+type SLAViolation struct {
+	Repository string            `json:"repository"`
+	Branch     string            `json:"branch"`
+	Target     string            `json:"target"`
+	ID         string            `json:"id"`
+	Type       types.FindingType `json:"type"`
+	Severity   string            `json:"severity"`
+	PkgName    string            `json:"pkg_name,omitempty"`
+	Title      string            `json:"title,omitempty"`
+	VulnerabilityAge
+	DaysOverdue int    `json:"days_overdue"`
+	Permalink   string `json:"permalink,omitempty"`
+}
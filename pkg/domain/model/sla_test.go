@@ -0,0 +1,42 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseSLAPolicy(t *testing.T) {
+	t.Run("days and durations", func(t *testing.T) {
+		policy := gt.R1(model.ParseSLAPolicy("high=30d, CRITICAL=7d,MEDIUM=36h")).NoError(t)
+		gt.V(t, policy[types.SeverityCritical]).Equal(7 * 24 * time.Hour)
+		gt.V(t, policy[types.SeverityHigh]).Equal(30 * 24 * time.Hour)
+		gt.V(t, policy[types.SeverityMedium]).Equal(36 * time.Hour)
+		gt.V(t, policy.String()).Equal("CRITICAL=7d,HIGH=30d,MEDIUM=36h0m0s")
+	})
+
+	t.Run("invalid policies", func(t *testing.T) {
+		for _, v := range []string{"CRITICAL", "URGENT=7d", "CRITICAL=0d", "CRITICAL=week"} {
+			_, err := model.ParseSLAPolicy(v)
+			gt.Error(t, err).Is(types.ErrInvalidOption)
+		}
+	})
+}
+
+func TestSLAPolicyAgeOf(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := model.SLAPolicy{types.SeverityCritical: 7 * 24 * time.Hour}
+
+	age := policy.AgeOf(&model.Vulnerability{Severity: "critical", CreatedAt: now.Add(-8 * 24 * time.Hour)}, now)
+	gt.V(t, age.DaysOpen).Equal(8)
+	gt.True(t, age.Overdue)
+	gt.V(t, *age.DueAt).Equal(now.Add(-24 * time.Hour))
+
+	age = policy.AgeOf(&model.Vulnerability{Severity: "HIGH", CreatedAt: now.Add(-100 * 24 * time.Hour)}, now)
+	gt.V(t, age.DaysOpen).Equal(100)
+	gt.False(t, age.Overdue)
+	gt.V(t, age.DueAt).Nil()
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// CodeSource is where source code of a scan is fetched from. A nil CodeSource means the GitHub zipball of the commit.
+type CodeSource struct {
+	Kind types.SourceKind
+	// Location is the directory path of SourceLocal, gs://bucket/object of SourceGCS or URL of SourceURL. It is empty for SourceGitHub.
+	Location string
+}
+
+// ParseCodeSource parses a source given by a user: an empty string for the GitHub zipball, gs://bucket/object.zip, http(s)://host/code.zip or a local path (optionally with file://).
+func ParseCodeSource(s string) (*CodeSource, error) {
+	switch {
+	case s == "":
+		return &CodeSource{Kind: types.SourceGitHub}, nil
+
+	case strings.HasPrefix(s, "gs://"):
+		src := &CodeSource{Kind: types.SourceGCS, Location: s}
+		if _, _, err := src.GCSObject(); err != nil {
+			return nil, err
+		}
+		return src, nil
+
+	case strings.HasPrefix(s, "https://"), strings.HasPrefix(s, "http://"):
+		if _, err := url.Parse(s); err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "invalid source URL", goerr.V("source", s))
+		}
+		return &CodeSource{Kind: types.SourceURL, Location: s}, nil
+
+	case strings.HasPrefix(s, "s3://"):
+		return nil, goerr.Wrap(types.ErrInvalidOption, "S3 is not supported as a source, use a presigned https URL of the object instead", goerr.V("source", s))
+
+	case strings.Contains(s, "://") && !strings.HasPrefix(s, "file://"):
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unsupported source scheme", goerr.V("source", s))
+	}
+
+	return &CodeSource{Kind: types.SourceLocal, Location: filepath.Clean(strings.TrimPrefix(s, "file://"))}, nil
+}
+
+// SourceKind returns the kind of the source. It returns SourceGitHub for nil.
+func (x *CodeSource) SourceKind() types.SourceKind {
+	if x == nil || x.Kind == "" {
+		return types.SourceGitHub
+	}
+	return x.Kind
+}
+
+// GCSObject returns the bucket and object name of SourceGCS
+func (x *CodeSource) GCSObject() (string, string, error) {
+	bucket, object, ok := strings.Cut(strings.TrimPrefix(x.Location, "gs://"), "/")
+	if x.SourceKind() != types.SourceGCS || !ok || bucket == "" || object == "" {
+		return "", "", goerr.Wrap(types.ErrInvalidOption, "invalid Cloud Storage source, gs://bucket/object is required", goerr.V("source", x.Location))
+	}
+	return bucket, object, nil
+}
+
+func (x *CodeSource) String() string {
+	if x.SourceKind() == types.SourceGitHub {
+		return string(types.SourceGitHub)
+	}
+	return x.Location
+}
+
+// FetchedSource is source code placed in a local directory by a fetcher
+type FetchedSource struct {
+	// Dir is the directory to be scanned
+	Dir string
+	// Archive is set if the source code is fetched as a zip archive
+	Archive *ScanArchive
+}
@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseCodeSource(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		kind     types.SourceKind
+		location string
+		isErr    bool
+	}{
+		"empty is GitHub":   {input: "", kind: types.SourceGitHub},
+		"relative path":     {input: "./code/", kind: types.SourceLocal, location: "code"},
+		"file scheme":       {input: "file:///tmp/code", kind: types.SourceLocal, location: "/tmp/code"},
+		"Cloud Storage":     {input: "gs://bucket/ci/app.zip", kind: types.SourceGCS, location: "gs://bucket/ci/app.zip"},
+		"HTTPS URL":         {input: "https://example.com/app.zip?sig=x", kind: types.SourceURL, location: "https://example.com/app.zip?sig=x"},
+		"no object in GCS":  {input: "gs://bucket", isErr: true},
+		"S3 is unsupported": {input: "s3://bucket/app.zip", isErr: true},
+		"unknown scheme":    {input: "ftp://example.com/app.zip", isErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			src, err := model.ParseCodeSource(tc.input)
+			if tc.isErr {
+				gt.True(t, errors.Is(err, types.ErrInvalidOption))
+				return
+			}
+			gt.NoError(t, err)
+			gt.V(t, src.Kind).Equal(tc.kind)
+			gt.V(t, src.Location).Equal(tc.location)
+		})
+	}
+}
+
+func TestCodeSourceGCSObject(t *testing.T) {
+	src := gt.R1(model.ParseCodeSource("gs://bucket/ci/app.zip")).NoError(t)
+	bucket, object, err := src.GCSObject()
+	gt.NoError(t, err)
+	gt.V(t, bucket).Equal("bucket")
+	gt.V(t, object).Equal("ci/app.zip")
+
+	var nilSource *model.CodeSource
+	gt.V(t, nilSource.SourceKind()).Equal(types.SourceGitHub)
+}
+
+func TestScanGitHubRepoInputValidateSource(t *testing.T) {
+	input := &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "owner", RepoName: "repo"},
+				CommitID:   "local-build",
+			},
+		},
+	}
+	// Commit SHA format and installation ID are required only for GitHub
+	gt.Error(t, input.Validate())
+
+	input.Source = &model.CodeSource{Kind: types.SourceLocal, Location: "."}
+	gt.NoError(t, input.Validate())
+}
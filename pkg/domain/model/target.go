@@ -5,23 +5,64 @@ import (
 	"encoding/hex"
 	"time"
 
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
 
 // Target represents a scan target (Trivy's Result)
 type Target struct {
-	ID        types.TargetID
-	Target    string
-	Class     string
-	Type      string
+	ID     types.TargetID
+	Target string
+	Class  string
+	Type   string
+	// ArtifactType is the artifact type of the report which has the target, e.g. "filesystem" or "container_image". Empty for targets stored before it was recorded.
+	ArtifactType string
+	// RemovedAt is when the target disappeared from scans of the branch, e.g. go.mod of a deleted directory. Zero while the target is scanned.
+	RemovedAt time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-// ToTargetID converts a target string to a TargetID by SHA256 hashing
+// IsConfig returns true if the target is a configuration file scanned for misconfigurations, e.g. Terraform or a Kubernetes manifest
+func (x *Target) IsConfig() bool {
+	return x.Class == string(trivy.ClassConfig)
+}
+
+// Removed returns true if the target is not in the last scan of the branch
+func (x *Target) Removed() bool {
+	return !x.RemovedAt.IsZero()
+}
+
+// CanonicalID returns the ID derived from the target, class and type by ToTargetID. It differs from ID if the target was stored with LegacyTargetID and has not been migrated.
+func (x *Target) CanonicalID() types.TargetID {
+	return ToTargetID(x.Target, x.Class, x.Type)
+}
+
+// ToTargetID converts a target with its class and type to a TargetID by SHA256 hashing. Class and type are included because a report can have several results of the same target, e.g. OS packages and language packages of a container image, which must not share findings.
 // This ensures safe Firestore document IDs even when target contains special characters
 // (e.g., "alpine:3.14" -> SHA256 hash)
-func ToTargetID(target string) types.TargetID {
+func ToTargetID(target, class, targetType string) types.TargetID {
+	if class == "" && targetType == "" {
+		return LegacyTargetID(target)
+	}
+	hash := sha256.Sum256([]byte(target + "\x00" + class + "\x00" + targetType))
+	return types.TargetID(hex.EncodeToString(hash[:]))
+}
+
+// LegacyTargetID is the ID of a target stored before its class and type were part of the ID, i.e. the hash of the target only. It is also the ID of a target without class and type.
+func LegacyTargetID(target string) types.TargetID {
 	hash := sha256.Sum256([]byte(target))
 	return types.TargetID(hex.EncodeToString(hash[:]))
 }
+
+// MigrateTargetIDsInput is input for moving targets of an owner stored with LegacyTargetID to their CanonicalID
+type MigrateTargetIDsInput struct {
+	Owner string
+}
+
+// MigrateTargetIDsResult is the number of migrated targets
+type MigrateTargetIDsResult struct {
+	Repositories int
+	Branches     int
+	Targets      int
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"crypto/subtle"
+	"slices"
+)
+
+// Tenant is the boundary of data accessible by a principal of a shared deployment. A repository is in the tenant if its owner or GitHub App installation is listed. A nil tenant is not restricted.
+type Tenant struct {
+	Owners          []string `json:"owners,omitempty"`
+	InstallationIDs []int64  `json:"installation_ids,omitempty"`
+}
+
+// AllowsOwner returns true if all repositories of the owner are in the tenant. Repositories of other owners may still be in the tenant by their installation.
+func (x *Tenant) AllowsOwner(owner string) bool {
+	return x == nil || containsFold(x.Owners, owner)
+}
+
+// AllowsInstallation returns true if repositories of the GitHub App installation are in the tenant
+func (x *Tenant) AllowsInstallation(installationID int64) bool {
+	return x == nil || (installationID != 0 && slices.Contains(x.InstallationIDs, installationID))
+}
+
+// AllowsRepository returns true if the repository is in the tenant
+func (x *Tenant) AllowsRepository(repo *Repository) bool {
+	return x.AllowsOwner(repo.Owner) || x.AllowsInstallation(repo.InstallationID)
+}
+
+// merge returns a tenant including repositories of both tenants
+func (x *Tenant) merge(other *Tenant) *Tenant {
+	merged := &Tenant{}
+	for _, t := range []*Tenant{x, other} {
+		for _, owner := range t.Owners {
+			if !containsFold(merged.Owners, owner) {
+				merged.Owners = append(merged.Owners, owner)
+			}
+		}
+		for _, id := range t.InstallationIDs {
+			if !slices.Contains(merged.InstallationIDs, id) {
+				merged.InstallationIDs = append(merged.InstallationIDs, id)
+			}
+		}
+	}
+	return merged
+}
+
+// TenantDefinition defines a tenant of a shared deployment and principals bound to it. Principals of a tenant see only repositories of the tenant and are never admins.
+type TenantDefinition struct {
+	Name            string   `yaml:"name"`
+	Owners          []string `yaml:"owners"`
+	InstallationIDs []int64  `yaml:"installation_ids"`
+
+	// APITokens are static bearer tokens of the read API bound to the tenant
+	APITokens []string `yaml:"api_tokens"`
+	// GitHubOrgs are GitHub organizations whose members (GitHub OAuth) and workflows (repository_owner claim of OIDC tokens) are bound to the tenant
+	GitHubOrgs []string `yaml:"github_orgs"`
+	// GitHubTeams are GitHub teams in "org/team-slug" form whose members are bound to the tenant
+	GitHubTeams []string `yaml:"github_teams"`
+	// OIDCSubjects are subjects of OIDC tokens bound to the tenant
+	OIDCSubjects []string `yaml:"oidc_subjects"`
+}
+
+func (x *TenantDefinition) tenant() *Tenant {
+	return &Tenant{Owners: x.Owners, InstallationIDs: x.InstallationIDs}
+}
+
+// TenantOfUser returns the tenant of the user and true if the user is allowed. The tenant is nil if the user is allowed by the policy itself, and it merges all tenants which the user is bound to otherwise.
+func (x *AuthPolicy) TenantOfUser(user *GitHubOAuthUser) (*Tenant, bool) {
+	if x.AllowsUser(user) {
+		return nil, true
+	}
+
+	return x.mergeTenants(func(def *TenantDefinition) bool {
+		for _, org := range user.Orgs {
+			if containsFold(def.GitHubOrgs, org) {
+				return true
+			}
+		}
+		for _, team := range user.Teams {
+			if containsFold(def.GitHubTeams, team) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// TenantOfOIDC returns the tenant of the OIDC token and true if the token is allowed, in the same way as TenantOfUser
+func (x *AuthPolicy) TenantOfOIDC(claims *OIDCClaims) (*Tenant, bool) {
+	if x.AllowsOIDC(claims) {
+		return nil, true
+	}
+
+	return x.mergeTenants(func(def *TenantDefinition) bool {
+		if claims.RepositoryOwner != "" && containsFold(def.GitHubOrgs, claims.RepositoryOwner) {
+			return true
+		}
+		return slices.Contains(def.OIDCSubjects, claims.Subject)
+	})
+}
+
+// TenantOfAPIToken returns the tenant and name of the tenant bound to the token, or false if no tenant has the token
+func (x *AuthPolicy) TenantOfAPIToken(token string) (*Tenant, string, bool) {
+	var found *TenantDefinition
+	// Every token is compared not to leak which one partially matched by timing
+	for _, def := range x.Tenants {
+		for _, t := range def.APITokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				found = def
+			}
+		}
+	}
+	if found == nil {
+		return nil, "", false
+	}
+	return found.tenant(), found.Name, true
+}
+
+func (x *AuthPolicy) mergeTenants(match func(def *TenantDefinition) bool) (*Tenant, bool) {
+	var merged *Tenant
+	for _, def := range x.Tenants {
+		if !match(def) {
+			continue
+		}
+		if merged == nil {
+			merged = def.tenant()
+		} else {
+			merged = merged.merge(def.tenant())
+		}
+	}
+	return merged, merged != nil
+}
@@ -0,0 +1,90 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestTenant(t *testing.T) {
+	tenant := &model.Tenant{Owners: []string{"MyOrg"}, InstallationIDs: []int64{2}}
+
+	gt.True(t, tenant.AllowsRepository(&model.Repository{Owner: "myorg", InstallationID: 1}))
+	gt.True(t, tenant.AllowsRepository(&model.Repository{Owner: "partner", InstallationID: 2}))
+	gt.False(t, tenant.AllowsRepository(&model.Repository{Owner: "partner", InstallationID: 3}))
+	gt.False(t, tenant.AllowsInstallation(0))
+
+	var unrestricted *model.Tenant
+	gt.True(t, unrestricted.AllowsRepository(&model.Repository{Owner: "anyone"}))
+}
+
+func TestAuthPolicyTenants(t *testing.T) {
+	policy := &model.AuthPolicy{
+		AllowedOrgs: []string{"operator"},
+		Tenants: []*model.TenantDefinition{
+			{
+				Name:        "alpha",
+				Owners:      []string{"alpha-org"},
+				APITokens:   []string{"alpha-token"},
+				GitHubOrgs:  []string{"alpha-org"},
+				GitHubTeams: []string{"shared/alpha"},
+			},
+			{
+				Name:            "beta",
+				Owners:          []string{"beta-org"},
+				InstallationIDs: []int64{42},
+				GitHubOrgs:      []string{"beta-org"},
+				GitHubTeams:     []string{"shared/beta"},
+				OIDCSubjects:    []string{"beta-deployer"},
+			},
+		},
+	}
+
+	t.Run("users allowed by policy are not restricted", func(t *testing.T) {
+		tenant, ok := policy.TenantOfUser(&model.GitHubOAuthUser{Login: "op", Orgs: []string{"operator", "alpha-org"}})
+		gt.True(t, ok)
+		gt.V(t, tenant).Nil()
+	})
+
+	t.Run("users are bound to merged tenants", func(t *testing.T) {
+		tenant, ok := policy.TenantOfUser(&model.GitHubOAuthUser{Login: "alice", Orgs: []string{"alpha-org"}})
+		gt.True(t, ok)
+		gt.A(t, tenant.Owners).Equal([]string{"alpha-org"})
+
+		tenant, ok = policy.TenantOfUser(&model.GitHubOAuthUser{Login: "bob", Orgs: []string{"shared"}, Teams: []string{"shared/alpha", "shared/beta"}})
+		gt.True(t, ok)
+		gt.A(t, tenant.Owners).Equal([]string{"alpha-org", "beta-org"})
+		gt.A(t, tenant.InstallationIDs).Equal([]int64{42})
+
+		_, ok = policy.TenantOfUser(&model.GitHubOAuthUser{Login: "mallory", Orgs: []string{"shared"}})
+		gt.False(t, ok)
+	})
+
+	t.Run("OIDC tokens", func(t *testing.T) {
+		tenant, ok := policy.TenantOfOIDC(&model.OIDCClaims{Subject: "repo:beta-org/app", RepositoryOwner: "beta-org"})
+		gt.True(t, ok)
+		gt.A(t, tenant.Owners).Equal([]string{"beta-org"})
+
+		tenant, ok = policy.TenantOfOIDC(&model.OIDCClaims{Subject: "beta-deployer"})
+		gt.True(t, ok)
+		gt.A(t, tenant.Owners).Equal([]string{"beta-org"})
+
+		tenant, ok = policy.TenantOfOIDC(&model.OIDCClaims{Subject: "repo:operator/app", RepositoryOwner: "operator"})
+		gt.True(t, ok)
+		gt.V(t, tenant).Nil()
+
+		_, ok = policy.TenantOfOIDC(&model.OIDCClaims{Subject: "repo:other/app", RepositoryOwner: "other"})
+		gt.False(t, ok)
+	})
+
+	t.Run("API tokens", func(t *testing.T) {
+		tenant, name, ok := policy.TenantOfAPIToken("alpha-token")
+		gt.True(t, ok)
+		gt.V(t, name).Equal("alpha")
+		gt.A(t, tenant.Owners).Equal([]string{"alpha-org"})
+
+		_, _, ok = policy.TenantOfAPIToken("unknown")
+		gt.False(t, ok)
+	})
+}
@@ -8,6 +8,12 @@ type MisconfSummary struct {
 
 type MisconfStatus string
 
+const (
+	MisconfStatusPassed    MisconfStatus = "PASS"
+	MisconfStatusFailure   MisconfStatus = "FAIL"
+	MisconfStatusException MisconfStatus = "EXCEPTION"
+)
+
 // DetectedMisconfiguration holds detected misconfigurations
 type DetectedMisconfiguration struct {
 	Type          string        `json:",omitempty"`
@@ -0,0 +1,36 @@
+package trivy
+
+// ModifiedFinding is a finding suppressed by an ignore file or VEX. Trivy reports them only with --show-suppressed.
+type ModifiedFinding struct {
+	// Type is one of "vulnerability", "misconfiguration", "secret" and "license"
+	Type string
+	// Status is "ignored" for an ignore file, or "not_affected" and so on for VEX
+	Status    string
+	Statement string `json:",omitempty"`
+	// Source is the ignore file or the VEX document which suppressed the finding
+	Source  string
+	Finding ModifiedFindingDetail
+}
+
+// ModifiedFindingDetail is identifiers of the suppressed finding, which is encoded as DetectedVulnerability, DetectedMisconfiguration, SecretFinding or DetectedLicense by Trivy. Other fields are not decoded.
+type ModifiedFindingDetail struct {
+	VulnerabilityID string `json:",omitempty"`
+	PkgName         string `json:",omitempty"`
+	// ID is ID of a misconfiguration
+	ID string `json:",omitempty"`
+	// RuleID is ID of a secret rule
+	RuleID string `json:",omitempty"`
+	// Name is name of a license
+	Name     string `json:",omitempty"`
+	Severity string `json:",omitempty"`
+}
+
+// FindingID returns ID of the finding by its type
+func (x *ModifiedFinding) FindingID() string {
+	for _, id := range []string{x.Finding.VulnerabilityID, x.Finding.ID, x.Finding.RuleID, x.Finding.Name} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
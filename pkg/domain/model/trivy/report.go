@@ -14,6 +14,22 @@ type Report struct {
 	ArtifactType  ArtifactType `json:",omitempty"`
 	Metadata      Metadata     `json:",omitempty"`
 	Results       Results      `json:",omitempty"`
+
+	// Trivy is the Trivy which created the report. octovy fills it when it runs Trivy, and it is nil in reports of other scanners.
+	Trivy *TrivyInfo `json:",omitempty"`
+}
+
+// TrivyInfo is the Trivy which created a report
+type TrivyInfo struct {
+	Version string `json:",omitempty"`
+}
+
+// TrivyVersion returns the version of Trivy which created the report, or empty if it is unknown
+func (x *Report) TrivyVersion() string {
+	if x.Trivy == nil {
+		return ""
+	}
+	return x.Trivy.Version
 }
 
 // Validate checks the required fields are filled.
@@ -65,10 +81,23 @@ type Result struct {
 	Misconfigurations []DetectedMisconfiguration `json:"Misconfigurations,omitempty"`
 	Secrets           []SecretFinding            `json:"Secrets,omitempty"`
 	Licenses          []DetectedLicense          `json:"Licenses,omitempty"`
+	// ModifiedFindings are findings suppressed by an ignore file, reported with --show-suppressed
+	ModifiedFindings []ModifiedFinding `json:"ExperimentalModifiedFindings,omitempty"`
 	// CustomResources   []ftypes.CustomResource    `json:"CustomResources,omitempty"`
 }
 
 type ResultClass string
+
+// Classes of results. Misconfigurations are reported in results of ClassConfig, one per configuration file such as Terraform, Kubernetes manifests and Dockerfiles, separately from results of packages.
+const (
+	ClassOSPkg       ResultClass = "os-pkgs"
+	ClassLangPkg     ResultClass = "lang-pkgs"
+	ClassConfig      ResultClass = "config"
+	ClassSecret      ResultClass = "secret"
+	ClassLicense     ResultClass = "license"
+	ClassLicenseFile ResultClass = "license-file"
+)
+
 type Compliance = string
 type Format string
 type ArtifactType string
@@ -0,0 +1,84 @@
+package trivy
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+const (
+	// SchemaVersion2 is the schema of Trivy JSON reports since Trivy v0.20
+	SchemaVersion2 = 2
+	// SchemaVersion3 is the next schema of Trivy JSON reports, in which experimental sections of SchemaVersion2 are promoted to regular fields
+	SchemaVersion3 = 3
+)
+
+// reportDecoders decode a report of each supported schema version into Report. A report without SchemaVersion is decoded as SchemaVersion2 so that Report.Validate tells what is wrong with it.
+var reportDecoders = map[int]func(raw []byte) (*Report, error){
+	0:              decodeReportV2,
+	SchemaVersion2: decodeReportV2,
+	SchemaVersion3: decodeReportV3,
+}
+
+// ParseReport decodes a Trivy JSON report of a supported schema version and normalizes it into Report. SchemaVersion of the report is kept as it is. It returns types.ErrUnsupportedSchemaVersion for other versions, including legacy reports of Trivy before v0.20 which are JSON arrays of results.
+func ParseReport(raw []byte) (*Report, error) {
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		return nil, goerr.Wrap(types.ErrUnsupportedSchemaVersion, "legacy trivy report without schema version is not supported, use Trivy v0.20 or later")
+	}
+
+	var header struct {
+		SchemaVersion int
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode trivy report")
+	}
+
+	decode, ok := reportDecoders[header.SchemaVersion]
+	if !ok {
+		return nil, goerr.Wrap(types.ErrUnsupportedSchemaVersion, "unsupported schema version of trivy report",
+			goerr.V("schema_version", header.SchemaVersion),
+			goerr.V("supported", []int{SchemaVersion2, SchemaVersion3}),
+		)
+	}
+	return decode(raw)
+}
+
+func decodeReportV2(raw []byte) (*Report, error) {
+	var report Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode trivy report", goerr.V("schema_version", SchemaVersion2))
+	}
+	return &report, nil
+}
+
+// reportV3 is a report of SchemaVersion3. Fields not listed here have the same names as SchemaVersion2.
+type reportV3 struct {
+	Report
+	Results []resultV3 `json:",omitempty"`
+}
+
+type resultV3 struct {
+	Result
+	// ModifiedFindings is ExperimentalModifiedFindings of SchemaVersion2
+	ModifiedFindings []ModifiedFinding `json:",omitempty"`
+}
+
+func decodeReportV3(raw []byte) (*Report, error) {
+	var v3 reportV3
+	if err := json.Unmarshal(raw, &v3); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode trivy report", goerr.V("schema_version", SchemaVersion3))
+	}
+
+	report := v3.Report
+	report.Results = make(Results, len(v3.Results))
+	for i, r := range v3.Results {
+		result := r.Result
+		if len(r.ModifiedFindings) > 0 {
+			result.ModifiedFindings = r.ModifiedFindings
+		}
+		report.Results[i] = result
+	}
+	return &report, nil
+}
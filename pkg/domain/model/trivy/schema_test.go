@@ -0,0 +1,72 @@
+package trivy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseReport(t *testing.T) {
+	t.Run("schema version 2", func(t *testing.T) {
+		report := gt.R1(trivy.ParseReport([]byte(`{
+			"SchemaVersion": 2,
+			"ArtifactName": ".",
+			"Results": [{
+				"Target": "go.mod",
+				"Vulnerabilities": [{"VulnerabilityID": "CVE-2024-0001", "PkgName": "pkg1"}],
+				"ExperimentalModifiedFindings": [{"Type": "vulnerability", "Status": "ignored", "Finding": {"VulnerabilityID": "CVE-2024-0002"}}]
+			}]
+		}`))).NoError(t)
+
+		gt.V(t, report.SchemaVersion).Equal(trivy.SchemaVersion2)
+		gt.A(t, report.Results).Length(1).At(0, func(t testing.TB, v trivy.Result) {
+			gt.V(t, v.Target).Equal("go.mod")
+			gt.A(t, v.Vulnerabilities).Length(1)
+			gt.A(t, v.ModifiedFindings).Length(1).At(0, func(t testing.TB, v trivy.ModifiedFinding) {
+				gt.V(t, v.FindingID()).Equal("CVE-2024-0002")
+			})
+		})
+	})
+
+	t.Run("renamed fields of schema version 3 are normalized", func(t *testing.T) {
+		report := gt.R1(trivy.ParseReport([]byte(`{
+			"SchemaVersion": 3,
+			"ArtifactName": ".",
+			"ArtifactType": "filesystem",
+			"ExperimentalNewSection": {"Foo": "bar"},
+			"Results": [{
+				"Target": "go.mod",
+				"Type": "gomod",
+				"Vulnerabilities": [{"VulnerabilityID": "CVE-2024-0001", "PkgName": "pkg1"}],
+				"ModifiedFindings": [{"Type": "vulnerability", "Status": "ignored", "Finding": {"VulnerabilityID": "CVE-2024-0002"}}]
+			}]
+		}`))).NoError(t)
+
+		gt.V(t, report.SchemaVersion).Equal(trivy.SchemaVersion3)
+		gt.V(t, report.ArtifactType).Equal("filesystem")
+		gt.NoError(t, report.Validate())
+		gt.A(t, report.Results).Length(1).At(0, func(t testing.TB, v trivy.Result) {
+			gt.V(t, v.Type).Equal("gomod")
+			gt.A(t, v.Vulnerabilities).Length(1)
+			gt.A(t, v.ModifiedFindings).Length(1)
+		})
+	})
+
+	t.Run("newer schema version is not supported", func(t *testing.T) {
+		_, err := trivy.ParseReport([]byte(`{"SchemaVersion": 4, "ArtifactName": "."}`))
+		gt.True(t, errors.Is(err, types.ErrUnsupportedSchemaVersion))
+	})
+
+	t.Run("legacy report is not supported", func(t *testing.T) {
+		_, err := trivy.ParseReport([]byte(` [{"Target": "go.mod", "Vulnerabilities": null}]`))
+		gt.True(t, errors.Is(err, types.ErrUnsupportedSchemaVersion))
+	})
+
+	t.Run("report without schema version is left to validation", func(t *testing.T) {
+		report := gt.R1(trivy.ParseReport([]byte(`{"ArtifactName": "."}`))).NoError(t)
+		gt.True(t, errors.Is(report.Validate(), types.ErrValidationFailed))
+	})
+}
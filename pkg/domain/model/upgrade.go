@@ -0,0 +1,185 @@
+package model
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PackageUpgrade is the minimal upgrade of a package of a target fixing all its vulnerabilities which have a fix
+type PackageUpgrade struct {
+	Ecosystem        string
+	Target           string
+	PkgName          string
+	InstalledVersion string
+	// FixedVersion is the highest of the minimal fixed versions of the vulnerabilities, or empty if none of them has a fix
+	FixedVersion string
+	// VulnIDs are vulnerabilities fixed by the upgrade, and Unfixed are vulnerabilities of the package without a fix
+	VulnIDs []string
+	Unfixed []string
+}
+
+// SuggestUpgrades aggregates fixed versions of vulnerabilities per package of a target. Upgrades are sorted by target and package name.
+func SuggestUpgrades(findings []*DependencyUpdateFinding) []*PackageUpgrade {
+	upgrades := make(map[string]*PackageUpgrade)
+	var result []*PackageUpgrade
+	for _, f := range findings {
+		if f.PkgName == "" {
+			continue
+		}
+		key := f.Target + "|" + f.PkgName
+		upgrade, ok := upgrades[key]
+		if !ok {
+			upgrade = &PackageUpgrade{
+				Ecosystem:        f.Ecosystem,
+				Target:           f.Target,
+				PkgName:          f.PkgName,
+				InstalledVersion: f.InstalledVersion,
+			}
+			upgrades[key] = upgrade
+			result = append(result, upgrade)
+		}
+
+		fixed := MinimalFixedVersion(f.InstalledVersion, f.FixedVersion)
+		if fixed == "" {
+			upgrade.Unfixed = appendUnique(upgrade.Unfixed, f.VulnID)
+			continue
+		}
+		upgrade.VulnIDs = appendUnique(upgrade.VulnIDs, f.VulnID)
+		if upgrade.FixedVersion == "" || compareVersions(fixed, upgrade.FixedVersion) > 0 {
+			upgrade.FixedVersion = fixed
+		}
+	}
+
+	for _, upgrade := range result {
+		sort.Strings(upgrade.VulnIDs)
+		sort.Strings(upgrade.Unfixed)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Target != result[j].Target {
+			return result[i].Target < result[j].Target
+		}
+		return result[i].PkgName < result[j].PkgName
+	})
+	return result
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// MinimalFixedVersion returns the lowest version of fixed, a comma separated list such as "1.2.5, 2.0.1" given by scanners for each release line, which is higher than installed. It returns empty if no version is higher.
+func MinimalFixedVersion(installed, fixed string) string {
+	var minimal string
+	for _, v := range strings.FieldsFunc(fixed, func(r rune) bool { return r == ',' || r == '|' || unicode.IsSpace(r) }) {
+		v = strings.TrimLeft(v, ">=~^")
+		if v == "" {
+			continue
+		}
+		if installed != "" && compareVersions(v, installed) <= 0 {
+			continue
+		}
+		if minimal == "" || compareVersions(v, minimal) < 0 {
+			minimal = v
+		}
+	}
+	return minimal
+}
+
+// UpgradeCommands returns shell commands to apply the upgrades, one per target directory and package manager. Upgrades of ecosystems without a known command, or without a fixed version, are skipped.
+func UpgradeCommands(upgrades []*PackageUpgrade) []string {
+	type group struct {
+		dir  string
+		tool string
+		args []string
+	}
+	var groups []*group
+	index := make(map[string]*group)
+
+	for _, u := range upgrades {
+		if u.FixedVersion == "" {
+			continue
+		}
+		tool, arg := upgradeCommand(u.Ecosystem, u.PkgName, u.FixedVersion)
+		if tool == "" {
+			continue
+		}
+		dir := path.Dir(u.Target)
+		key := dir + "|" + tool
+		// cargo takes only one package with --precise
+		if u.Ecosystem == "cargo" {
+			key += "|" + u.PkgName
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &group{dir: dir, tool: tool}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.args = append(g.args, arg)
+	}
+
+	commands := make([]string, len(groups))
+	for i, g := range groups {
+		cmd := g.tool + " " + strings.Join(g.args, " ")
+		if g.dir != "." && g.dir != "" && g.dir != "/" {
+			cmd = "(cd " + shellQuote(g.dir) + " && " + cmd + ")"
+		}
+		commands[i] = cmd
+	}
+	return commands
+}
+
+// upgradeCommand returns the command of the package manager of the ecosystem, a result type of Trivy, and its argument to upgrade the package. It returns empty for an unknown ecosystem.
+func upgradeCommand(ecosystem, pkgName, version string) (string, string) {
+	switch ecosystem {
+	case "gomod":
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		return "go get", shellQuote(pkgName + "@" + version)
+	case "npm":
+		return "npm install", shellQuote(pkgName + "@" + version)
+	case "yarn":
+		return "yarn add", shellQuote(pkgName + "@" + version)
+	case "pnpm":
+		return "pnpm add", shellQuote(pkgName + "@" + version)
+	case "pip":
+		return "pip install", shellQuote(pkgName + ">=" + version)
+	case "pipenv":
+		return "pipenv install", shellQuote(pkgName + ">=" + version)
+	case "poetry":
+		return "poetry add", shellQuote(pkgName + ">=" + version)
+	case "uv":
+		return "uv add", shellQuote(pkgName + ">=" + version)
+	case "cargo":
+		return "cargo update --precise " + shellQuote(version) + " -p", shellQuote(pkgName)
+	case "composer":
+		return "composer require", shellQuote(pkgName + ":^" + version)
+	case "bundler":
+		return "bundle update --conservative", shellQuote(pkgName)
+	default:
+		return "", ""
+	}
+}
+
+// shellQuote quotes s for a POSIX shell if it has characters other than safe ones
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("@%+=:,./_-", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// UpgradeBranchPrefix is the prefix of branches of pull requests upgrading vulnerable packages
+const UpgradeBranchPrefix = "octovy/upgrade/"
+
+// defaultMaxOpenUpgradePullRequests is the max number of open upgrade pull requests per repository if not configured
+const defaultMaxOpenUpgradePullRequests = 5
+
+// UpgradePullRequestConfig is how pull requests upgrading vulnerable packages of the default branch are created
+type UpgradePullRequestConfig struct {
+	// MaxOpen is the max number of open upgrade pull requests per repository. No pull request is created while the repository has as many. Zero means the default, 5.
+	MaxOpen int
+	// Labels are added to created pull requests
+	Labels []string
+}
+
+// MaxOpenPullRequests returns MaxOpen, or the default if it is not set
+func (x UpgradePullRequestConfig) MaxOpenPullRequests() int {
+	if x.MaxOpen <= 0 {
+		return defaultMaxOpenUpgradePullRequests
+	}
+	return x.MaxOpen
+}
+
+// GitHubPullRequestRef is a pull request found by its head branch
+type GitHubPullRequestRef struct {
+	Number int
+	URL    string
+	Branch string
+	// State is "open" or "closed", including merged
+	State string
+}
+
+// upgradeManifests are manifest and lock files modified by an upgrade per ecosystem which supports upgrade pull requests. The first one is required.
+var upgradeManifests = map[string][]string{
+	"gomod": {"go.mod", "go.sum"},
+	"npm":   {"package.json", "package-lock.json"},
+}
+
+// IsUpgradableEcosystem returns true if pull requests upgrading packages of the ecosystem, a result type of Trivy, can be created
+func IsUpgradableEcosystem(ecosystem string) bool {
+	_, ok := upgradeManifests[ecosystem]
+	return ok
+}
+
+// UpgradeManifests returns names of manifest and lock files of the ecosystem modified by an upgrade, in the directory of the target. The first one is required and others may not exist.
+func UpgradeManifests(ecosystem string) []string {
+	return upgradeManifests[ecosystem]
+}
+
+// Dir returns the directory of the target relative to the repository root, "." for the root
+func (x *PackageUpgrade) Dir() string {
+	return path.Dir(x.Target)
+}
+
+// PackageBranchPrefix returns the prefix of branches upgrading the package of the target to any version, to find an open pull request of the package
+func (x *PackageUpgrade) PackageBranchPrefix() string {
+	var b strings.Builder
+	b.WriteString(UpgradeBranchPrefix)
+	b.WriteString(branchComponent(x.Ecosystem) + "/")
+	if dir := x.Dir(); dir != "." {
+		b.WriteString(branchComponent(dir) + "/")
+	}
+	b.WriteString(branchComponent(x.PkgName) + "@")
+	return b.String()
+}
+
+// BranchName returns the branch of the pull request of the upgrade, e.g. "octovy/upgrade/npm/web/lodash@4.17.21". It is determined by the upgrade, so that the same upgrade is not proposed twice.
+func (x *PackageUpgrade) BranchName() string {
+	return x.PackageBranchPrefix() + branchComponent(x.FixedVersion)
+}
+
+// branchComponent replaces characters not allowed in a git branch name with "-"
+func branchComponent(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("._/@+-", r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	segments := strings.Split(b.String(), "/")
+	for i, seg := range segments {
+		for strings.Contains(seg, "..") {
+			seg = strings.ReplaceAll(seg, "..", ".")
+		}
+		seg = strings.ReplaceAll(seg, "@{", "@-")
+		seg = strings.TrimSuffix(seg, ".lock")
+		if strings.HasPrefix(seg, ".") {
+			seg = "_" + seg[1:]
+		}
+		segments[i] = seg
+	}
+	return strings.Join(segments, "/")
+}
+
+// PullRequestTitle returns the title of the pull request of the upgrade
+func (x *PackageUpgrade) PullRequestTitle() string {
+	title := fmt.Sprintf("Bump %s from %s to %s", x.PkgName, x.InstalledVersion, x.FixedVersion)
+	if dir := x.Dir(); dir != "." {
+		title += " in /" + dir
+	}
+	return title
+}
+
+// CommitMessage returns the message of the commit of the upgrade
+func (x *PackageUpgrade) CommitMessage() string {
+	return x.PullRequestTitle() + "\n\nFixes " + strings.Join(x.VulnIDs, ", ")
+}
+
+// PullRequestBody returns the markdown body of the pull request of the upgrade
+func (x *PackageUpgrade) PullRequestBody() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bumps `%s` in `%s` from `%s` to `%s`, the lowest version fixing the following vulnerabilities.\n\n",
+		x.PkgName, x.Target, x.InstalledVersion, x.FixedVersion)
+	for _, id := range x.VulnIDs {
+		fmt.Fprintf(&b, "- %s\n", id)
+	}
+	if len(x.Unfixed) > 0 {
+		fmt.Fprintf(&b, "\nThe following vulnerabilities of the package have no fix yet and remain: %s\n", strings.Join(x.Unfixed, ", "))
+	}
+	b.WriteString("\nThis pull request was created by octovy. Please check the changes and tests before merging. Closing it without merging prevents octovy from proposing the same version again.\n")
+	return b.String()
+}
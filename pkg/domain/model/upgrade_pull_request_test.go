@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestPackageUpgradeBranchName(t *testing.T) {
+	testCases := map[string]struct {
+		upgrade  model.PackageUpgrade
+		expected string
+	}{
+		"root go module": {
+			upgrade:  model.PackageUpgrade{Ecosystem: "gomod", Target: "go.mod", PkgName: "golang.org/x/net", FixedVersion: "v0.17.0"},
+			expected: "octovy/upgrade/gomod/golang.org/x/net@v0.17.0",
+		},
+		"scoped npm package in sub directory": {
+			upgrade:  model.PackageUpgrade{Ecosystem: "npm", Target: "web/package-lock.json", PkgName: "@babel/core", FixedVersion: "7.23.2"},
+			expected: "octovy/upgrade/npm/web/@babel/core@7.23.2",
+		},
+		"invalid characters are replaced": {
+			upgrade:  model.PackageUpgrade{Ecosystem: "npm", Target: ".hidden/a b/package-lock.json", PkgName: "x", FixedVersion: "1.0.0~rc..1"},
+			expected: "octovy/upgrade/npm/_hidden/a-b/x@1.0.0-rc.1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gt.V(t, tc.upgrade.BranchName()).Equal(tc.expected)
+		})
+	}
+
+	t.Run("branch of another version has the same package prefix", func(t *testing.T) {
+		a := &model.PackageUpgrade{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/a", FixedVersion: "v1.1.0"}
+		b := &model.PackageUpgrade{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/a", FixedVersion: "v1.2.0"}
+		c := &model.PackageUpgrade{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/ab", FixedVersion: "v1.2.0"}
+		gt.S(t, b.BranchName()).HasPrefix(a.PackageBranchPrefix())
+		gt.False(t, strings.HasPrefix(c.BranchName(), a.PackageBranchPrefix()))
+	})
+}
+
+func TestPackageUpgradePullRequest(t *testing.T) {
+	upgrade := &model.PackageUpgrade{
+		Ecosystem:        "npm",
+		Target:           "web/package-lock.json",
+		PkgName:          "lodash",
+		InstalledVersion: "4.17.0",
+		FixedVersion:     "4.17.21",
+		VulnIDs:          []string{"CVE-2020-8203", "CVE-2021-23337"},
+		Unfixed:          []string{"CVE-2099-0001"},
+	}
+
+	gt.V(t, upgrade.PullRequestTitle()).Equal("Bump lodash from 4.17.0 to 4.17.21 in /web")
+	gt.V(t, upgrade.CommitMessage()).Equal("Bump lodash from 4.17.0 to 4.17.21 in /web\n\nFixes CVE-2020-8203, CVE-2021-23337")
+	body := upgrade.PullRequestBody()
+	gt.S(t, body).Contains("- CVE-2021-23337\n")
+	gt.S(t, body).Contains("have no fix yet and remain: CVE-2099-0001")
+}
+
+func TestUpgradePullRequestConfig(t *testing.T) {
+	gt.V(t, model.UpgradePullRequestConfig{}.MaxOpenPullRequests()).Equal(5)
+	gt.V(t, model.UpgradePullRequestConfig{MaxOpen: 2}.MaxOpenPullRequests()).Equal(2)
+	gt.True(t, model.IsUpgradableEcosystem("gomod"))
+	gt.False(t, model.IsUpgradableEcosystem("cargo"))
+}
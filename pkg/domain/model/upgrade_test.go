@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+func TestMinimalFixedVersion(t *testing.T) {
+	testCases := map[string]struct {
+		installed string
+		fixed     string
+		expected  string
+	}{
+		"single version":             {installed: "1.2.3", fixed: "1.2.5", expected: "1.2.5"},
+		"lowest of release lines":    {installed: "1.2.3", fixed: "2.0.1, 1.2.5", expected: "1.2.5"},
+		"skip older release line":    {installed: "1.3.0", fixed: "1.2.5, 1.3.2", expected: "1.3.2"},
+		"constraint prefix":          {installed: "4.17.0", fixed: ">=4.17.21", expected: "4.17.21"},
+		"no fix":                     {installed: "1.2.3", fixed: "", expected: ""},
+		"no version above installed": {installed: "1.2.3", fixed: "1.2.0", expected: ""},
+		"semver with v prefix":       {installed: "v0.9.0", fixed: "v0.10.0", expected: "v0.10.0"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gt.V(t, model.MinimalFixedVersion(tc.installed, tc.fixed)).Equal(tc.expected)
+		})
+	}
+}
+
+func TestSuggestUpgrades(t *testing.T) {
+	upgrades := model.SuggestUpgrades([]*model.DependencyUpdateFinding{
+		{Target: "web/package-lock.json", Ecosystem: "npm", VulnID: "CVE-2024-0003", PkgName: "lodash", InstalledVersion: "4.17.0", FixedVersion: "4.17.21"},
+		{Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0002", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "v1.2.0"},
+		{Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0001", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "v1.1.0"},
+		{Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0004", PkgName: "example.com/a", InstalledVersion: "v1.0.0"},
+	})
+
+	gt.A(t, upgrades).Length(2).
+		At(0, func(t testing.TB, v *model.PackageUpgrade) {
+			gt.V(t, v.Target).Equal("go.mod")
+			gt.V(t, v.FixedVersion).Equal("v1.2.0")
+			gt.A(t, v.VulnIDs).Equal([]string{"CVE-2024-0001", "CVE-2024-0002"})
+			gt.A(t, v.Unfixed).Equal([]string{"CVE-2024-0004"})
+		}).
+		At(1, func(t testing.TB, v *model.PackageUpgrade) {
+			gt.V(t, v.PkgName).Equal("lodash")
+			gt.V(t, v.FixedVersion).Equal("4.17.21")
+		})
+}
+
+func TestUpgradeCommands(t *testing.T) {
+	commands := model.UpgradeCommands([]*model.PackageUpgrade{
+		{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/a", FixedVersion: "1.2.0"},
+		{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/b", FixedVersion: "v0.3.1"},
+		{Ecosystem: "npm", Target: "web/package-lock.json", PkgName: "@babel/core", FixedVersion: "7.23.2"},
+		{Ecosystem: "cargo", Target: "Cargo.lock", PkgName: "serde", FixedVersion: "1.0.190"},
+		{Ecosystem: "gomod", Target: "go.mod", PkgName: "example.com/c"},
+		{Ecosystem: "unknown", Target: "x.lock", PkgName: "x", FixedVersion: "1.0.0"},
+	})
+
+	gt.A(t, commands).Equal([]string{
+		"go get example.com/a@v1.2.0 example.com/b@v0.3.1",
+		"(cd web && npm install @babel/core@7.23.2)",
+		"cargo update --precise 1.0.190 -p serde",
+	})
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"strings"
+
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 )
@@ -8,6 +10,17 @@ import (
 type ScanGitHubRepoInput struct {
 	GitHubMetadata
 	InstallID types.GitHubAppInstallID
+
+	// Force disables the commit SHA cache and always downloads and scans the repository
+	Force bool
+
+	// Source is where the source code is fetched from. The GitHub zipball of the commit is used if nil.
+	Source *CodeSource
+
+	// RunID is the ScanRun created by CreateScanRun to track the scan. A new run is created by the scan if empty.
+	RunID types.ScanRunID
+	// Trigger is what requested the scan, types.ScanTriggerCLI if empty
+	Trigger types.ScanTrigger
 }
 
 func (x *ScanGitHubRepoInput) Validate() error {
@@ -16,9 +29,13 @@ func (x *ScanGitHubRepoInput) Validate() error {
 	if err := x.GitHubMetadata.ValidateBasic(); err != nil {
 		return err
 	}
+	// Commit ID and installation ID are used only to fetch source code from GitHub
+	if x.Source.SourceKind() != types.SourceGitHub {
+		return nil
+	}
 	// Validate commit ID format
-	if !ptnValidCommitID.MatchString(x.CommitID) {
-		return goerr.Wrap(types.ErrValidationFailed, "invalid commit ID")
+	if err := types.CommitSHA(x.CommitID).Validate(); err != nil {
+		return err
 	}
 	if x.InstallID == 0 {
 		return goerr.Wrap(types.ErrInvalidOption, "install ID is empty")
@@ -32,11 +49,42 @@ type ScanGitHubRepoRemoteInput struct {
 	Repo      string
 	Commit    string
 	Branch    string
+	Tag       string // Git tag to scan, e.g. "v1.2.0". A release is scanned by its tag name
 	InstallID types.GitHubAppInstallID
+	Force     bool
+	Trigger   types.ScanTrigger
+}
+
+// Normalize trims owner and repository names, strips "refs/heads/" from the branch and "refs/tags/" from the tag and lowers the case of the commit
+func (x *ScanGitHubRepoRemoteInput) Normalize() {
+	x.Owner = strings.TrimSpace(x.Owner)
+	x.Repo = strings.TrimSpace(x.Repo)
+	x.Commit = string(types.NewCommitSHA(x.Commit))
+	x.Branch = string(types.NewBranchName(x.Branch))
+	x.Tag = strings.TrimPrefix(strings.TrimSpace(x.Tag), "refs/tags/")
+}
+
+// ValidateRef returns an error if more than one of commit, branch and tag is specified
+func (x *ScanGitHubRepoRemoteInput) ValidateRef() error {
+	var specified []string
+	for _, ref := range []struct{ name, value string }{
+		{"commit", x.Commit},
+		{"branch", x.Branch},
+		{"tag", x.Tag},
+	} {
+		if ref.value != "" {
+			specified = append(specified, ref.name)
+		}
+	}
+	if len(specified) > 1 {
+		return goerr.Wrap(types.ErrInvalidOption, strings.Join(specified, " and ")+" cannot be specified at the same time")
+	}
+	return nil
 }
 
 type ScanGitHubReposByOwnerInput struct {
 	Owner string
+	Force bool
 }
 
 // ScanGitHubReposByOwnerFromAPIInput is input for scanning all repositories
@@ -44,4 +92,74 @@ type ScanGitHubReposByOwnerInput struct {
 type ScanGitHubReposByOwnerFromAPIInput struct {
 	Owner     string
 	InstallID types.GitHubAppInstallID // optional; if not set, will be fetched from GitHub API
+	Force     bool
+	Trigger   types.ScanTrigger
+}
+
+// SeedInstallationReposInput is input for registering repositories of a GitHub App
+// installation in the ScanRepository before they are scanned.
+type SeedInstallationReposInput struct {
+	InstallID types.GitHubAppInstallID
+	Repos     []string // optional; full names (owner/repo) to register. If not set, all repositories of the installation are registered
+}
+
+// UpdateRepositoryMetadataInput is input for setting tags, team, scan config
+// and pull request check of a repository. Nil fields are left unchanged.
+type UpdateRepositoryMetadataInput struct {
+	Owner            string
+	Repo             string
+	Tags             *[]string
+	Team             *string
+	ScanConfig       *ScanConfig             // an empty config removes the stored one
+	PullRequestCheck *PullRequestCheckConfig // an empty config removes the stored one
+}
+
+// SyncCodeScanningAlertsInput is input for reflecting GitHub code scanning alert
+// dismissals into vulnerability statuses.
+type SyncCodeScanningAlertsInput struct {
+	Owner string
+	Repo  string // optional; if not set, all repositories of the owner in Firestore are synced
+}
+
+// ListBranchStatusInput is input for listing scan status of all scanned branches
+// of a repository.
+type ListBranchStatusInput struct {
+	Owner string
+	Repo  string
+}
+
+// GetRepositoryInput is input for getting a scanned repository.
+type GetRepositoryInput struct {
+	Owner string
+	Repo  string
+}
+
+// ListRepositoriesInput is input for listing scanned repositories of an owner.
+// Tag and Team are optional filters.
+type ListRepositoriesInput struct {
+	Owner string
+	Tag   string
+	Team  string
+}
+
+// ListBranchesInput is input for listing scanned branches of a repository.
+type ListBranchesInput struct {
+	RepoID types.GitHubRepoID
+}
+
+// ListTargetsInput is input for listing scan targets of a branch.
+type ListTargetsInput struct {
+	RepoID types.GitHubRepoID
+	Branch types.BranchName
+}
+
+// ListVulnerabilitiesInput is input for listing findings of a scan target.
+// Empty filters match all findings.
+type ListVulnerabilitiesInput struct {
+	RepoID     types.GitHubRepoID
+	Branch     types.BranchName
+	TargetID   types.TargetID
+	Severities []types.Severity
+	Statuses   []types.VulnStatus
+	Types      []types.FindingType
 }
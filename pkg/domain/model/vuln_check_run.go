@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// VulnCheckRunName is the name of the check run gating a pull request by its vulnerabilities
+const VulnCheckRunName = "octovy / vulnerabilities"
+
+// maxVulnCheckRows is the max number of vulnerabilities listed in the summary of the check run, to keep it in the size limit of GitHub
+const maxVulnCheckRows = 50
+
+// PullRequestCheckConfig is a setting of the vulnerability check run of pull requests for a repository, which takes precedence over the server-wide setting
+type PullRequestCheckConfig struct {
+	// Baseline is empty to use the server-wide baseline
+	Baseline types.CheckBaseline `json:"baseline,omitempty"`
+}
+
+// IsEmpty returns true if the config does not change the server-wide setting
+func (x *PullRequestCheckConfig) IsEmpty() bool {
+	return x == nil || x.Baseline == ""
+}
+
+// VulnerabilityCheck is vulnerabilities of a pull request at or above Threshold reported as a check run. With CheckBaselineBase, only New ones fail the check and Existing counts those present in the base branch.
+type VulnerabilityCheck struct {
+	Threshold  types.Severity
+	Baseline   types.CheckBaseline
+	BaseBranch types.BranchName
+	// BaseMissing is true if the base branch has never been scanned, then all vulnerabilities are New
+	BaseMissing bool
+	New         []*DependencyUpdateFinding
+	Existing    int
+	// Upgrades are minimal upgrades of packages with New vulnerabilities, fixing all vulnerabilities of the packages
+	Upgrades []*PackageUpgrade
+}
+
+// NewVulnerabilityCheck picks vulnerabilities of head at or above the threshold. head and base are keyed by the identity of a vulnerability, and base is nil to count all of head as new. Upgrades aggregate all vulnerabilities of head, also below the threshold, of packages with new ones.
+func NewVulnerabilityCheck(head map[string]*DependencyUpdateFinding, base map[string]*DependencyUpdateFinding, threshold types.Severity) *VulnerabilityCheck {
+	check := &VulnerabilityCheck{Threshold: threshold}
+	packages := make(map[string]bool)
+	for key, finding := range head {
+		if !types.Severity(finding.Severity).AtLeast(threshold) {
+			continue
+		}
+		if _, ok := base[key]; ok {
+			check.Existing++
+			continue
+		}
+		check.New = append(check.New, finding)
+		packages[finding.Target+"|"+finding.PkgName] = true
+	}
+
+	var upgradable []*DependencyUpdateFinding
+	for _, finding := range head {
+		if packages[finding.Target+"|"+finding.PkgName] {
+			upgradable = append(upgradable, finding)
+		}
+	}
+	check.Upgrades = SuggestUpgrades(upgradable)
+
+	sort.Slice(check.New, func(i, j int) bool {
+		a, b := check.New[i], check.New[j]
+		if ra, rb := types.Severity(a.Severity).Rank(), types.Severity(b.Severity).Rank(); ra != rb {
+			return ra > rb
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.VulnID != b.VulnID {
+			return a.VulnID < b.VulnID
+		}
+		return a.PkgName < b.PkgName
+	})
+	return check
+}
+
+// Conclusion of the check run. It fails if the pull request has any new vulnerability at or above the threshold.
+func (x *VulnerabilityCheck) Conclusion() string {
+	if len(x.New) > 0 {
+		return "failure"
+	}
+	return "success"
+}
+
+// Title of the check run
+func (x *VulnerabilityCheck) Title() string {
+	adjective := " "
+	if x.comparesBase() {
+		adjective = " new "
+	}
+
+	switch len(x.New) {
+	case 0:
+		return fmt.Sprintf("No%svulnerability at or above %s", adjective, x.Threshold)
+	case 1:
+		return fmt.Sprintf("1%svulnerability at or above %s", adjective, x.Threshold)
+	default:
+		return fmt.Sprintf("%d%svulnerabilities at or above %s", len(x.New), adjective, x.Threshold)
+	}
+}
+
+// Summary of the check run in markdown
+func (x *VulnerabilityCheck) Summary() string {
+	var b strings.Builder
+	switch {
+	case x.comparesBase():
+		fmt.Fprintf(&b, "Vulnerabilities at or above %s which are not in the last scan of the base branch `%s` fail the check.", x.Threshold, x.BaseBranch)
+		if x.Existing > 0 {
+			fmt.Fprintf(&b, " %d vulnerabilities also present in the base branch are ignored.", x.Existing)
+		}
+	case x.BaseMissing:
+		fmt.Fprintf(&b, "The base branch `%s` has never been scanned, so all vulnerabilities at or above %s fail the check.", x.BaseBranch, x.Threshold)
+	default:
+		fmt.Fprintf(&b, "All vulnerabilities at or above %s fail the check.", x.Threshold)
+	}
+	b.WriteString("\n")
+
+	if len(x.New) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n| Severity | Vulnerability | Package | Version | Target |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for i, f := range x.New {
+		if i == maxVulnCheckRows {
+			fmt.Fprintf(&b, "\n%d more vulnerabilities are not listed.\n", len(x.New)-maxVulnCheckRows)
+			break
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			escapeCheckCell(f.Severity), escapeCheckCell(f.VulnID), escapeCheckCell(f.PkgName), escapeCheckCell(f.InstalledVersion), escapeCheckCell(f.Target))
+	}
+
+	x.writeUpgrades(&b)
+	return b.String()
+}
+
+// writeUpgrades writes the minimal upgrade of each package and commands to apply them
+func (x *VulnerabilityCheck) writeUpgrades(b *strings.Builder) {
+	var fixable []*PackageUpgrade
+	for _, u := range x.Upgrades {
+		if u.FixedVersion != "" {
+			fixable = append(fixable, u)
+		}
+	}
+	if len(fixable) == 0 {
+		return
+	}
+
+	b.WriteString("\n### Suggested upgrades\n\n")
+	b.WriteString("Upgrading to the version fixes all known vulnerabilities of the package which have a fix.\n\n")
+	b.WriteString("| Package | Installed | Upgrade to | Fixes | Target |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for i, u := range fixable {
+		if i == maxVulnCheckRows {
+			fmt.Fprintf(b, "\n%d more upgrades are not listed.\n", len(fixable)-maxVulnCheckRows)
+			break
+		}
+		fixes := strings.Join(u.VulnIDs, ", ")
+		if len(u.Unfixed) > 0 {
+			fixes += fmt.Sprintf(" (%d without fix remain)", len(u.Unfixed))
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n",
+			escapeCheckCell(u.PkgName), escapeCheckCell(u.InstalledVersion), escapeCheckCell(u.FixedVersion), escapeCheckCell(fixes), escapeCheckCell(u.Target))
+	}
+
+	if commands := UpgradeCommands(fixable); len(commands) > 0 {
+		b.WriteString("\n```sh\n")
+		for _, cmd := range commands {
+			b.WriteString(cmd + "\n")
+		}
+		b.WriteString("```\n")
+	}
+}
+
+func (x *VulnerabilityCheck) comparesBase() bool {
+	return x.Baseline == types.CheckBaselineBase && !x.BaseMissing
+}
+
+func escapeCheckCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,87 @@
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestNewVulnerabilityCheck(t *testing.T) {
+	finding := func(id, pkg, severity string) *model.DependencyUpdateFinding {
+		return &model.DependencyUpdateFinding{Target: "go.mod", VulnID: id, PkgName: pkg, InstalledVersion: "v1.0.0", Severity: severity}
+	}
+
+	t.Run("new vulnerabilities are sorted by severity", func(t *testing.T) {
+		head := map[string]*model.DependencyUpdateFinding{
+			"a": finding("CVE-2024-0001", "example.com/a", "HIGH"),
+			"b": finding("CVE-2024-0002", "example.com/b", "CRITICAL"),
+			"c": finding("CVE-2024-0003", "example.com/c", "MEDIUM"),
+			"d": finding("CVE-2024-0004", "example.com/d", "HIGH"),
+		}
+		base := map[string]*model.DependencyUpdateFinding{"d": finding("CVE-2024-0004", "example.com/d", "HIGH")}
+
+		check := model.NewVulnerabilityCheck(head, base, types.SeverityHigh)
+		check.Baseline = types.CheckBaselineBase
+		check.BaseBranch = "main"
+
+		gt.V(t, check.Existing).Equal(1)
+		gt.A(t, check.New).Length(2).
+			At(0, func(t testing.TB, v *model.DependencyUpdateFinding) { gt.V(t, v.VulnID).Equal("CVE-2024-0002") }).
+			At(1, func(t testing.TB, v *model.DependencyUpdateFinding) { gt.V(t, v.VulnID).Equal("CVE-2024-0001") })
+		gt.V(t, check.Conclusion()).Equal("failure")
+		gt.V(t, check.Title()).Equal("2 new vulnerabilities at or above HIGH")
+		gt.S(t, check.Summary()).Contains("base branch `main`")
+	})
+
+	t.Run("no vulnerability at or above threshold succeeds", func(t *testing.T) {
+		check := model.NewVulnerabilityCheck(map[string]*model.DependencyUpdateFinding{
+			"a": finding("CVE-2024-0001", "example.com/a", "LOW"),
+		}, nil, types.SeverityHigh)
+		check.Baseline = types.CheckBaselineNone
+
+		gt.V(t, check.Conclusion()).Equal("success")
+		gt.V(t, check.Title()).Equal("No vulnerability at or above HIGH")
+		gt.S(t, check.Summary()).NotContains("| Severity |")
+	})
+
+	t.Run("summary lists limited number of vulnerabilities", func(t *testing.T) {
+		head := map[string]*model.DependencyUpdateFinding{}
+		for i := 0; i < 60; i++ {
+			head[fmt.Sprint(i)] = finding(fmt.Sprintf("CVE-2024-%04d", i), "example.com/a|b", "HIGH")
+		}
+		check := model.NewVulnerabilityCheck(head, nil, types.SeverityHigh)
+
+		summary := check.Summary()
+		gt.N(t, strings.Count(summary, "| HIGH |")).Equal(50)
+		gt.S(t, summary).Contains("10 more vulnerabilities are not listed")
+		gt.S(t, summary).Contains(`example.com/a\|b`)
+	})
+}
+
+func TestVulnerabilityCheckUpgrades(t *testing.T) {
+	head := map[string]*model.DependencyUpdateFinding{
+		"a": {Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0001", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "v1.0.2", Severity: "HIGH"},
+		"b": {Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0002", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "v1.1.0", Severity: "LOW"},
+		"c": {Target: "go.mod", Ecosystem: "gomod", VulnID: "CVE-2024-0003", PkgName: "example.com/b", InstalledVersion: "v2.0.0", FixedVersion: "v2.0.1", Severity: "LOW"},
+	}
+
+	check := model.NewVulnerabilityCheck(head, nil, types.SeverityHigh)
+
+	// example.com/b has no new vulnerability at or above the threshold
+	gt.A(t, check.Upgrades).Length(1).
+		At(0, func(t testing.TB, v *model.PackageUpgrade) {
+			gt.V(t, v.PkgName).Equal("example.com/a")
+			gt.V(t, v.FixedVersion).Equal("v1.1.0")
+			gt.A(t, v.VulnIDs).Equal([]string{"CVE-2024-0001", "CVE-2024-0002"})
+		})
+
+	summary := check.Summary()
+	gt.S(t, summary).Contains("### Suggested upgrades")
+	gt.S(t, summary).Contains("| example.com/a | v1.0.0 | v1.1.0 | CVE-2024-0001, CVE-2024-0002 | go.mod |")
+	gt.S(t, summary).Contains("go get example.com/a@v1.1.0")
+	gt.S(t, summary).NotContains("example.com/b")
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// StatusChangeActorOctovy is the actor of status changes made by Octovy itself, e.g. by scans
+const StatusChangeActorOctovy = "octovy"
+
+// Acknowledgement is a triage decision to accept the risk of a vulnerability that is still detected
+type Acknowledgement struct {
+	By      string
+	Comment string
+	At      time.Time
+	// ExpiresAt is when the vulnerability becomes active again. Zero means no expiration.
+	ExpiresAt time.Time
+}
+
+// Expired returns true if the acknowledgement has an expiration and it has passed at now
+func (x *Acknowledgement) Expired(now time.Time) bool {
+	return !x.ExpiresAt.IsZero() && !now.Before(x.ExpiresAt)
+}
+
+// VulnStatusChange is a status change of a vulnerability. Changes are recorded in the append-only history of the vulnerability.
+type VulnStatusChange struct {
+	VulnID string
+	From   types.VulnStatus
+	To     types.VulnStatus
+	Actor  string
+	// Comment is the reason of the change
+	Comment string
+	// Acknowledgement is set when To is acknowledged
+	Acknowledgement *Acknowledgement
+	// ScanID is the scan which caused the change, if any
+	ScanID    types.ScanID
+	Timestamp time.Time
+}
+
+// AcknowledgeVulnerabilityInput is input for accepting the risk of a vulnerability, or revoking the acceptance
+type AcknowledgeVulnerabilityInput struct {
+	Owner  string
+	Repo   string
+	Branch types.BranchName // optional; if not set, the default branch
+	Target string           // optional; if not set, all targets where the vulnerability is found
+	VulnID string
+
+	By        string
+	Comment   string
+	ExpiresAt time.Time // optional; zero means no expiration
+
+	// Revoke makes acknowledged vulnerabilities active again
+	Revoke bool
+}
+
+// SetVulnerabilityStatusInput is input for changing the status of a vulnerability manually, e.g. in triage
+type SetVulnerabilityStatusInput struct {
+	Owner  string
+	Repo   string
+	Branch types.BranchName // optional; if not set, the default branch
+	Target string           // optional; if not set, all targets where the vulnerability is found
+	VulnID string
+	Status types.VulnStatus
+
+	By        string
+	Reason    string
+	ExpiresAt time.Time // optional and only for acknowledged; zero means no expiration
+}
+
+// FailedVulnStatusChange is a status change which could not be applied, with the reason
+type FailedVulnStatusChange struct {
+	Change *VulnStatusChange
+	Reason string
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
@@ -15,14 +16,18 @@ type CVSS struct {
 	V3Score  float64
 }
 
-// Vulnerability represents a detected vulnerability
+// Vulnerability represents a detected vulnerability. Misconfigurations and secrets are
+// stored as Vulnerability too so that they share the same status management; Type tells them apart.
 type Vulnerability struct {
 	ID               string
+	Type             types.FindingType
 	PkgName          string
 	PkgPath          string
 	InstalledVersion string
 	FixedVersion     string
 	Severity         string
+	// OriginalSeverity is the severity reported by the scanner if Severity is replaced by a SeverityOverride
+	OriginalSeverity string
 	Title            string
 	Description      string
 	References       []string
@@ -31,15 +36,38 @@ type Vulnerability struct {
 	CVSS             map[string]CVSS
 	PublishedDate    string
 	LastModifiedDate string
+	LayerDiffID      string
+	LayerOrigin      types.LayerOrigin
 	Status           types.VulnStatus
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// Truncated means long fields are shortened to fit in the Firestore document size limit. OverflowURI points the full vulnerability in object storage if it is configured.
+	Truncated   bool
+	OverflowURI string
+	// PURL is the normalized package URL of the vulnerable package to join findings with other tools, or empty if it is unknown
+	PURL string
+	// Source is the data source ID of the advisory, e.g. "internal" for an internal advisory feed
+	Source string
+	// Exploitability is EPSS and KEV data of a CVE at the last scan, or nil if not available
+	Exploitability *Exploitability
+	// Acknowledgement is kept while the vulnerability is fixed so that it applies again on re-detection
+	Acknowledgement *Acknowledgement
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 // NewVulnerability creates a Vulnerability from Trivy's DetectedVulnerability
 func NewVulnerability(detected *trivy.DetectedVulnerability) *Vulnerability {
 	now := time.Now()
 
+	var layerDiffID string
+	if detected.Layer != nil {
+		layerDiffID = detected.Layer.DiffID
+	}
+
+	var source string
+	if detected.DataSource != nil {
+		source = string(detected.DataSource.ID)
+	}
+
 	// CVSS information conversion
 	cvss := make(map[string]CVSS)
 	for sourceID, vendorCVSS := range detected.CVSS {
@@ -53,8 +81,10 @@ func NewVulnerability(detected *trivy.DetectedVulnerability) *Vulnerability {
 
 	return &Vulnerability{
 		ID:               detected.VulnerabilityID,
+		Type:             types.FindingTypeVulnerability,
 		PkgName:          detected.PkgName,
 		PkgPath:          detected.PkgPath,
+		PURL:             detectedPURL(detected),
 		InstalledVersion: detected.InstalledVersion,
 		FixedVersion:     detected.FixedVersion,
 		Severity:         detected.Severity,
@@ -66,8 +96,62 @@ func NewVulnerability(detected *trivy.DetectedVulnerability) *Vulnerability {
 		CVSS:             cvss,
 		PublishedDate:    detected.PublishedDate,
 		LastModifiedDate: detected.LastModifiedDate,
+		LayerDiffID:      layerDiffID,
 		Status:           types.VulnStatusActive,
+		Source:           source,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
 }
+
+// detectedPURL returns the normalized package URL reported by the scanner, or an empty string if it is not reported
+func detectedPURL(detected *trivy.DetectedVulnerability) string {
+	if detected.PkgIdentifier != nil {
+		if purl := NormalizePURL(detected.PkgIdentifier.PURL); purl != "" {
+			return purl
+		}
+	}
+	// PkgRef is a package URL if the target is an SBOM of CycloneDX
+	return NormalizePURL(detected.PkgRef)
+}
+
+// NewMisconfigurationFinding creates a Vulnerability from Trivy's DetectedMisconfiguration
+func NewMisconfigurationFinding(detected *trivy.DetectedMisconfiguration) *Vulnerability {
+	now := time.Now()
+
+	id := detected.AVDID
+	if id == "" {
+		id = detected.ID
+	}
+
+	return &Vulnerability{
+		ID:          id,
+		Type:        types.FindingTypeMisconfiguration,
+		PkgPath:     detected.CauseMetadata.Resource,
+		Severity:    detected.Severity,
+		Title:       detected.Title,
+		Description: detected.Description,
+		References:  detected.References,
+		PrimaryURL:  detected.PrimaryURL,
+		Status:      types.VulnStatusActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// NewSecretFinding creates a Vulnerability from Trivy's SecretFinding. The matched
+// secret itself is not copied. The ID includes the start line because one rule can
+// match several times in the same file.
+func NewSecretFinding(detected *trivy.SecretFinding) *Vulnerability {
+	now := time.Now()
+
+	return &Vulnerability{
+		ID:        fmt.Sprintf("%s:%d", detected.RuleID, detected.StartLine),
+		Type:      types.FindingTypeSecret,
+		Severity:  detected.Severity,
+		Title:     detected.Title,
+		Status:    types.VulnStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// GetVulnerabilityDetailInput identifies a finding of a scan target
+type GetVulnerabilityDetailInput struct {
+	Owner    string
+	Repo     string
+	Branch   types.BranchName
+	TargetID types.TargetID
+	VulnID   string
+}
+
+// VulnerabilityDetail is a stored finding with all of its advisory data and status history, for a detail page of UI
+type VulnerabilityDetail struct {
+	Repository       string            `json:"repository"`
+	Branch           string            `json:"branch"`
+	TargetID         string            `json:"target_id"`
+	Target           string            `json:"target"`
+	Type             types.FindingType `json:"type"`
+	ID               string            `json:"id"`
+	PkgName          string            `json:"pkg_name,omitempty"`
+	PkgPath          string            `json:"pkg_path,omitempty"`
+	PURL             string            `json:"purl,omitempty"`
+	InstalledVersion string            `json:"installed_version,omitempty"`
+	FixedVersion     string            `json:"fixed_version,omitempty"`
+	Severity         string            `json:"severity"`
+	OriginalSeverity string            `json:"original_severity,omitempty"`
+	Status           types.VulnStatus  `json:"status"`
+	Title            string            `json:"title,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	PrimaryURL       string            `json:"primary_url,omitempty"`
+	References       []string          `json:"references"`
+	CweIDs           []string          `json:"cwe_ids"`
+	// CVSS is scores and vectors keyed by the data source, e.g. "nvd" and "ghsa"
+	CVSS             map[string]*CVSSDetail `json:"cvss"`
+	PublishedDate    string                 `json:"published_date,omitempty"`
+	LastModifiedDate string                 `json:"last_modified_date,omitempty"`
+	LayerDiffID      string                 `json:"layer_diff_id,omitempty"`
+	LayerOrigin      types.LayerOrigin      `json:"layer_origin,omitempty"`
+	Source           string                 `json:"source,omitempty"`
+	Exploitability   *Exploitability        `json:"exploitability,omitempty"`
+	Acknowledgement  *AcknowledgementDetail `json:"acknowledgement,omitempty"`
+	// Truncated means long fields such as Description and References are shortened in storage. OverflowURI points the full finding in object storage if it is kept.
+	Truncated       bool                     `json:"truncated,omitempty"`
+	OverflowURI     string                   `json:"overflow_uri,omitempty"`
+	FirstDetectedAt time.Time                `json:"first_detected_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+	History         []*VulnStatusChangeEntry `json:"history"`
+	Permalink       string                   `json:"permalink,omitempty"`
+}
+
+// CVSSDetail is CVSS of a data source. Empty vectors and zero scores are not reported by the source.
+type CVSSDetail struct {
+	V2Vector string  `json:"v2_vector,omitempty"`
+	V2Score  float64 `json:"v2_score,omitempty"`
+	V3Vector string  `json:"v3_vector,omitempty"`
+	V3Score  float64 `json:"v3_score,omitempty"`
+}
+
+// AcknowledgementDetail is an accepted risk of a finding
+type AcknowledgementDetail struct {
+	By        string     `json:"by"`
+	Comment   string     `json:"comment,omitempty"`
+	At        time.Time  `json:"at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// VulnStatusChangeEntry is a status change in the history of a finding
+type VulnStatusChangeEntry struct {
+	From      types.VulnStatus `json:"from,omitempty"`
+	To        types.VulnStatus `json:"to"`
+	Actor     string           `json:"actor,omitempty"`
+	Comment   string           `json:"comment,omitempty"`
+	ScanID    string           `json:"scan_id,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// NewVulnerabilityDetail converts a stored finding of the target with its status changes, oldest first
+func NewVulnerabilityDetail(repoID types.GitHubRepoID, branch types.BranchName, target *Target, vuln *Vulnerability, history []*VulnStatusChange) *VulnerabilityDetail {
+	findingType := vuln.Type
+	if findingType == "" {
+		findingType = types.FindingTypeVulnerability
+	}
+
+	detail := &VulnerabilityDetail{
+		Repository:       string(repoID),
+		Branch:           string(branch),
+		TargetID:         string(target.ID),
+		Target:           target.Target,
+		Type:             findingType,
+		ID:               vuln.ID,
+		PkgName:          vuln.PkgName,
+		PkgPath:          vuln.PkgPath,
+		PURL:             vuln.PURL,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Severity:         vuln.Severity,
+		OriginalSeverity: vuln.OriginalSeverity,
+		Status:           vuln.Status,
+		Title:            vuln.Title,
+		Description:      vuln.Description,
+		PrimaryURL:       vuln.PrimaryURL,
+		References:       append([]string{}, vuln.References...),
+		CweIDs:           append([]string{}, vuln.CweIDs...),
+		CVSS:             make(map[string]*CVSSDetail, len(vuln.CVSS)),
+		PublishedDate:    vuln.PublishedDate,
+		LastModifiedDate: vuln.LastModifiedDate,
+		LayerDiffID:      vuln.LayerDiffID,
+		LayerOrigin:      vuln.LayerOrigin,
+		Source:           vuln.Source,
+		Exploitability:   vuln.Exploitability,
+		Truncated:        vuln.Truncated,
+		OverflowURI:      vuln.OverflowURI,
+		FirstDetectedAt:  vuln.CreatedAt,
+		UpdatedAt:        vuln.UpdatedAt,
+		History:          make([]*VulnStatusChangeEntry, 0, len(history)),
+	}
+
+	for source, cvss := range vuln.CVSS {
+		detail.CVSS[source] = &CVSSDetail{
+			V2Vector: cvss.V2Vector,
+			V2Score:  cvss.V2Score,
+			V3Vector: cvss.V3Vector,
+			V3Score:  cvss.V3Score,
+		}
+	}
+
+	if ack := vuln.Acknowledgement; ack != nil {
+		detail.Acknowledgement = &AcknowledgementDetail{
+			By:      ack.By,
+			Comment: ack.Comment,
+			At:      ack.At,
+		}
+		if !ack.ExpiresAt.IsZero() {
+			expiresAt := ack.ExpiresAt
+			detail.Acknowledgement.ExpiresAt = &expiresAt
+		}
+	}
+
+	for _, change := range history {
+		detail.History = append(detail.History, &VulnStatusChangeEntry{
+			From:      change.From,
+			To:        change.To,
+			Actor:     change.Actor,
+			Comment:   change.Comment,
+			ScanID:    string(change.ScanID),
+			Timestamp: change.Timestamp,
+		})
+	}
+
+	return detail
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// BranchVulnerability is a stored finding with the branch and target which it belongs to
+type BranchVulnerability struct {
+	Branch        types.BranchName
+	Target        *Target
+	Vulnerability *Vulnerability
+}
+
+// GetVulnerabilityRollupInput is a request of findings of all branches of a repository
+type GetVulnerabilityRollupInput struct {
+	Owner string
+	Repo  string
+	// IncludeFixed also rolls up fixed findings. Only active and acknowledged findings are rolled up by default.
+	IncludeFixed bool
+}
+
+// VulnerabilityRollup is findings of all branches of a repository, where the same finding detected in several branches or targets is one entry
+type VulnerabilityRollup struct {
+	Repository    string                     `json:"repository"`
+	DefaultBranch string                     `json:"default_branch,omitempty"`
+	Permalink     string                     `json:"permalink,omitempty"`
+	Findings      []*RolledUpFinding         `json:"findings"`
+	Summary       VulnerabilityRollupSummary `json:"summary"`
+}
+
+// VulnerabilityRollupSummary counts logical findings of a rollup, and their occurrences in branches and targets
+type VulnerabilityRollupSummary struct {
+	Findings    int            `json:"findings"`
+	Occurrences int            `json:"occurrences"`
+	Severities  SeverityCounts `json:"severities"`
+}
+
+// RolledUpFinding is a logical finding of a repository. Vulnerabilities are identical if they have the same ID and package regardless of the installed version and target, and misconfigurations and secrets are identical if they have the same ID in the same target.
+type RolledUpFinding struct {
+	ID       string            `json:"id"`
+	Type     types.FindingType `json:"type"`
+	PkgName  string            `json:"pkg_name,omitempty"`
+	PURL     string            `json:"purl,omitempty"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title,omitempty"`
+	// Status is active if the finding is active in any branch, acknowledged if it is acknowledged in all branches where it is not fixed, and fixed otherwise
+	Status     types.VulnStatus `json:"status"`
+	PrimaryURL string           `json:"primary_url,omitempty"`
+	// FirstDetectedAt is the earliest detection of the finding in all branches
+	FirstDetectedAt time.Time          `json:"first_detected_at"`
+	InDefaultBranch bool               `json:"in_default_branch"`
+	Branches        []*FindingPresence `json:"branches"`
+}
+
+// FindingPresence is an occurrence of a rolled up finding in a target of a branch
+type FindingPresence struct {
+	Branch           string           `json:"branch"`
+	Default          bool             `json:"default"`
+	Target           string           `json:"target"`
+	PkgPath          string           `json:"pkg_path,omitempty"`
+	InstalledVersion string           `json:"installed_version,omitempty"`
+	FixedVersion     string           `json:"fixed_version,omitempty"`
+	Status           types.VulnStatus `json:"status"`
+	Permalink        string           `json:"permalink,omitempty"`
+}
+
+type rollupKey struct {
+	findingType types.FindingType
+	id          string
+	pkgName     string
+	target      string
+}
+
+// NewVulnerabilityRollup aggregates findings of branches by rollupKey. Findings are sorted by severity in descending order, then by ID, and their occurrences are sorted with the default branch first.
+func NewVulnerabilityRollup(repo *Repository, vulns []*BranchVulnerability, includeFixed bool, permalinks *Permalinks) *VulnerabilityRollup {
+	rollup := &VulnerabilityRollup{
+		Repository:    string(repo.ID),
+		DefaultBranch: string(repo.DefaultBranch),
+		Permalink:     permalinks.Repository(repo.ID),
+		Findings:      []*RolledUpFinding{},
+	}
+
+	findings := make(map[rollupKey]*RolledUpFinding)
+	for _, bv := range vulns {
+		vuln := bv.Vulnerability
+		if vuln.Status == types.VulnStatusFixed && !includeFixed {
+			continue
+		}
+
+		// Findings stored before the type was recorded are vulnerabilities
+		findingType := vuln.Type
+		if findingType == "" {
+			findingType = types.FindingTypeVulnerability
+		}
+
+		key := rollupKey{findingType: findingType, id: vuln.ID, pkgName: vuln.PkgName}
+		// A misconfiguration or secret is identified in its file, while a vulnerable package is the same finding in any target
+		if findingType != types.FindingTypeVulnerability && bv.Target != nil {
+			key.target = bv.Target.Target
+		}
+		finding, ok := findings[key]
+		if !ok {
+			finding = &RolledUpFinding{
+				ID:              vuln.ID,
+				Type:            findingType,
+				PkgName:         vuln.PkgName,
+				FirstDetectedAt: vuln.CreatedAt,
+			}
+			findings[key] = finding
+			rollup.Findings = append(rollup.Findings, finding)
+		}
+		finding.add(repo, bv, permalinks)
+	}
+
+	for _, finding := range rollup.Findings {
+		sort.SliceStable(finding.Branches, func(i, j int) bool {
+			a, b := finding.Branches[i], finding.Branches[j]
+			if a.Default != b.Default {
+				return a.Default
+			}
+			if a.Branch != b.Branch {
+				return a.Branch < b.Branch
+			}
+			return a.Target < b.Target
+		})
+
+		rollup.Summary.Findings++
+		rollup.Summary.Occurrences += len(finding.Branches)
+		if finding.Type == types.FindingTypeVulnerability {
+			rollup.Summary.Severities.Add(finding.Severity)
+		}
+	}
+
+	sort.SliceStable(rollup.Findings, func(i, j int) bool {
+		a, b := rollup.Findings[i], rollup.Findings[j]
+		if ra, rb := types.Severity(a.Severity).Rank(), types.Severity(b.Severity).Rank(); ra != rb {
+			return ra > rb
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.PkgName < b.PkgName
+	})
+
+	return rollup
+}
+
+// add merges an occurrence of the finding. The highest severity is taken because a severity override or a newer advisory may differ between branches.
+func (x *RolledUpFinding) add(repo *Repository, bv *BranchVulnerability, permalinks *Permalinks) {
+	vuln := bv.Vulnerability
+	isDefault := repo.DefaultBranch != "" && bv.Branch == repo.DefaultBranch
+
+	if x.Severity == "" || types.Severity(vuln.Severity).Rank() > types.Severity(x.Severity).Rank() {
+		x.Severity = vuln.Severity
+	}
+	if x.Title == "" {
+		x.Title = vuln.Title
+	}
+	if x.PURL == "" {
+		x.PURL = vuln.PURL
+	}
+	if x.PrimaryURL == "" {
+		x.PrimaryURL = vuln.PrimaryURL
+	}
+	if !vuln.CreatedAt.IsZero() && (x.FirstDetectedAt.IsZero() || vuln.CreatedAt.Before(x.FirstDetectedAt)) {
+		x.FirstDetectedAt = vuln.CreatedAt
+	}
+	x.InDefaultBranch = x.InDefaultBranch || isDefault
+	x.Status = rollupStatus(x.Status, vuln.Status)
+
+	var target string
+	if bv.Target != nil {
+		target = bv.Target.Target
+	}
+	x.Branches = append(x.Branches, &FindingPresence{
+		Branch:           string(bv.Branch),
+		Default:          isDefault,
+		Target:           target,
+		PkgPath:          vuln.PkgPath,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+		Status:           vuln.Status,
+		Permalink:        permalinks.Finding(repo.ID, bv.Branch, vuln.ID),
+	})
+}
+
+// rollupStatus returns the status of a rolled up finding from the status so far and the status of an occurrence: active wins over acknowledged, and acknowledged wins over fixed
+func rollupStatus(current, next types.VulnStatus) types.VulnStatus {
+	rank := func(s types.VulnStatus) int {
+		switch s {
+		case types.VulnStatusActive:
+			return 3
+		case types.VulnStatusAcknowledged:
+			return 2
+		case types.VulnStatusFixed:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(next) > rank(current) {
+		return next
+	}
+	return current
+}
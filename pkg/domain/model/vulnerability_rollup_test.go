@@ -0,0 +1,89 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestNewVulnerabilityRollup(t *testing.T) {
+	t1 := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(24 * time.Hour)
+	repo := &model.Repository{ID: "myorg/myrepo", DefaultBranch: "main"}
+	goMod := &model.Target{ID: "t1", Target: "go.mod"}
+	subMod := &model.Target{ID: "t2", Target: "tools/go.mod"}
+	dockerfile := &model.Target{ID: "t3", Target: "Dockerfile"}
+	otherDockerfile := &model.Target{ID: "t4", Target: "build/Dockerfile"}
+
+	vulns := []*model.BranchVulnerability{
+		{Branch: "feature/x", Target: goMod, Vulnerability: &model.Vulnerability{
+			ID: "CVE-2024-0001", Type: types.FindingTypeVulnerability, PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0",
+			Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: t2,
+		}},
+		{Branch: "main", Target: goMod, Vulnerability: &model.Vulnerability{
+			ID: "CVE-2024-0001", PkgName: "golang.org/x/net", InstalledVersion: "v0.2.0", PURL: "pkg:golang/golang.org/x/net@v0.2.0",
+			Severity: "CRITICAL", Status: types.VulnStatusAcknowledged, CreatedAt: t1,
+		}},
+		{Branch: "main", Target: subMod, Vulnerability: &model.Vulnerability{
+			ID: "CVE-2024-0001", Type: types.FindingTypeVulnerability, PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0",
+			Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: t2,
+		}},
+		{Branch: "main", Target: goMod, Vulnerability: &model.Vulnerability{
+			ID: "CVE-2024-0002", Type: types.FindingTypeVulnerability, PkgName: "golang.org/x/text",
+			Severity: "LOW", Status: types.VulnStatusFixed, CreatedAt: t1,
+		}},
+		{Branch: "main", Target: dockerfile, Vulnerability: &model.Vulnerability{
+			ID: "DS002", Type: types.FindingTypeMisconfiguration, Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: t1,
+		}},
+		{Branch: "main", Target: otherDockerfile, Vulnerability: &model.Vulnerability{
+			ID: "DS002", Type: types.FindingTypeMisconfiguration, Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: t1,
+		}},
+	}
+
+	t.Run("same vulnerabilities of branches and targets are one finding", func(t *testing.T) {
+		rollup := model.NewVulnerabilityRollup(repo, vulns, false, model.NewPermalinks("https://octovy.example.com"))
+		gt.V(t, rollup.Repository).Equal("myorg/myrepo")
+		gt.V(t, rollup.Summary.Findings).Equal(3)
+		gt.V(t, rollup.Summary.Occurrences).Equal(5)
+		gt.V(t, rollup.Summary.Severities.Critical).Equal(1)
+		gt.A(t, rollup.Findings).Length(3)
+
+		finding := rollup.Findings[0]
+		gt.V(t, finding.ID).Equal("CVE-2024-0001")
+		gt.V(t, finding.Type).Equal(types.FindingTypeVulnerability)
+		gt.V(t, finding.Severity).Equal("CRITICAL")
+		gt.V(t, finding.Status).Equal(types.VulnStatusActive)
+		gt.V(t, finding.PURL).Equal("pkg:golang/golang.org/x/net@v0.2.0")
+		gt.V(t, finding.FirstDetectedAt).Equal(t1)
+		gt.True(t, finding.InDefaultBranch)
+		gt.A(t, finding.Branches).Length(3)
+		gt.V(t, finding.Branches[0].Branch).Equal("main")
+		gt.V(t, finding.Branches[0].Target).Equal("go.mod")
+		gt.V(t, finding.Branches[0].Status).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, finding.Branches[1].Target).Equal("tools/go.mod")
+		gt.V(t, finding.Branches[2].Branch).Equal("feature/x")
+		gt.V(t, finding.Branches[2].Permalink).Equal("https://octovy.example.com/r/myorg/myrepo/b/feature%2Fx/v/CVE-2024-0001")
+
+		// Misconfigurations of different files are different findings
+		gt.V(t, rollup.Findings[1].ID).Equal("DS002")
+		gt.V(t, rollup.Findings[2].ID).Equal("DS002")
+		gt.A(t, rollup.Findings[1].Branches).Length(1)
+	})
+
+	t.Run("fixed findings are included on request", func(t *testing.T) {
+		rollup := model.NewVulnerabilityRollup(repo, vulns, true, nil)
+		gt.A(t, rollup.Findings).Length(4)
+		last := rollup.Findings[3]
+		gt.V(t, last.ID).Equal("CVE-2024-0002")
+		gt.V(t, last.Status).Equal(types.VulnStatusFixed)
+		gt.V(t, last.Branches[0].Permalink).Equal("")
+	})
+
+	t.Run("no findings", func(t *testing.T) {
+		rollup := model.NewVulnerabilityRollup(repo, nil, false, nil)
+		gt.A(t, rollup.Findings).Length(0)
+	})
+}
@@ -139,3 +139,46 @@ func TestNewVulnerability(t *testing.T) {
 		gt.V(t, len(vuln.CVSS)).Equal(0)
 	})
 }
+
+func TestNewMisconfigurationFinding(t *testing.T) {
+	t.Run("uses AVD ID as finding ID", func(t *testing.T) {
+		finding := model.NewMisconfigurationFinding(&trivy.DetectedMisconfiguration{
+			ID:            "DS002",
+			AVDID:         "AVD-DS-0002",
+			Title:         "Image user should not be 'root'",
+			Description:   "Running containers with 'root' user can lead to a container escape situation.",
+			Severity:      "HIGH",
+			PrimaryURL:    "https://avd.aquasec.com/misconfig/ds002",
+			References:    []string{"https://docs.docker.com/develop/develop-images/dockerfile_best-practices/"},
+			CauseMetadata: trivy.CauseMetadata{Resource: "Dockerfile"},
+		})
+
+		gt.V(t, finding.ID).Equal("AVD-DS-0002")
+		gt.V(t, finding.Type).Equal(types.FindingTypeMisconfiguration)
+		gt.V(t, finding.Severity).Equal("HIGH")
+		gt.V(t, finding.PkgPath).Equal("Dockerfile")
+		gt.V(t, finding.PrimaryURL).Equal("https://avd.aquasec.com/misconfig/ds002")
+		gt.V(t, finding.Status).Equal(types.VulnStatusActive)
+	})
+
+	t.Run("falls back to check ID", func(t *testing.T) {
+		finding := model.NewMisconfigurationFinding(&trivy.DetectedMisconfiguration{ID: "CUSTOM-001"})
+		gt.V(t, finding.ID).Equal("CUSTOM-001")
+	})
+}
+
+func TestNewSecretFinding(t *testing.T) {
+	finding := model.NewSecretFinding(&trivy.SecretFinding{
+		RuleID:    "github-pat",
+		Title:     "GitHub Personal Access Token",
+		Severity:  "CRITICAL",
+		StartLine: 12,
+		Match:     "token: ****",
+	})
+
+	gt.V(t, finding.ID).Equal("github-pat:12")
+	gt.V(t, finding.Type).Equal(types.FindingTypeSecret)
+	gt.V(t, finding.Severity).Equal("CRITICAL")
+	gt.V(t, finding.Title).Equal("GitHub Personal Access Token")
+	gt.V(t, finding.Description).Equal("")
+}
@@ -0,0 +1,23 @@
+package types
+
+import "github.com/m-mizutani/goerr/v2"
+
+// CheckBaseline is which findings fail the vulnerability check run of a pull request
+type CheckBaseline string
+
+const (
+	// CheckBaselineBase fails the check only on findings not present in the last scan of the base branch, so that long-standing findings do not block unrelated pull requests
+	CheckBaselineBase CheckBaseline = "base"
+	// CheckBaselineNone fails the check on all findings of the pull request
+	CheckBaselineNone CheckBaseline = "none"
+)
+
+// Validate checks the baseline is supported
+func (x CheckBaseline) Validate() error {
+	switch x {
+	case CheckBaselineBase, CheckBaselineNone:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported check baseline", goerr.V("baseline", x))
+	}
+}
@@ -1,6 +1,10 @@
 package types
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/m-mizutani/goerr/v2"
+)
 
 var (
 	// ErrInvalidOption is an error that indicates an invalid option is given by user via CLI or configuration
@@ -9,12 +13,32 @@ var (
 	// ErrInvalidRequest is an error that indicates an invalid HTTP request
 	ErrInvalidRequest = errors.New("invalid request")
 
+	// ErrUnauthorized is an error that indicates an HTTP request without valid credentials, e.g. a webhook with a wrong signature
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrPayloadTooLarge is an error that indicates an HTTP request body exceeding the size limit
+	ErrPayloadTooLarge = errors.New("payload too large")
+
 	// ErrInvalidResponse is an error that indicates a failure in data consistency in the application
 	ErrValidationFailed = errors.New("validation failed")
 
+	// ErrUnsupportedSchemaVersion is an error that indicates a report of a scanner in a schema version which can not be read, e.g. a Trivy report of a newer schema
+	ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+
 	// ErrInvalidGitHubData is an error that indicates an invalid data provided by GitHub. Mainly used in GitHub API response
 	ErrInvalidGitHubData = errors.New("invalid GitHub data")
 
+	// ErrArchiveTooLarge is an error that indicates a zip archive of source code exceeds the extraction limits, e.g. a zip bomb
+	ErrArchiveTooLarge = errors.New("archive too large")
+
+	// ErrIncompatibleTrivy is an error that indicates the Trivy binary is older than the minimum version, whose report may not be read correctly
+	ErrIncompatibleTrivy = errors.New("incompatible Trivy version")
+
 	// ErrLogicError is an error that indicates a logic error in the application
 	ErrLogicError = errors.New("logic error")
 )
+
+var (
+	// TagBigQuery is a goerr tag of errors in storing scans to BigQuery, to tell them from scan failures in error reports
+	TagBigQuery = goerr.NewTag("bigquery")
+)
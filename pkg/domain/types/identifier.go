@@ -0,0 +1,85 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+var (
+	ptnCommitSHA   = regexp.MustCompile("^[0-9a-f]{40}$")
+	ptnGitHubOwner = regexp.MustCompile("^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$")
+	ptnGitHubRepo  = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// NewGitHubRepoID builds a repository ID in "owner/repo" form. Surrounding spaces are removed.
+func NewGitHubRepoID(owner, repo string) GitHubRepoID {
+	return GitHubRepoID(strings.TrimSpace(owner) + "/" + strings.TrimSpace(repo))
+}
+
+// Split returns owner and repository name of the ID. Both are empty if the ID is not in "owner/repo" form.
+func (x GitHubRepoID) Split() (owner, repo string) {
+	parts := strings.Split(string(x), "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// Validate checks that the ID is "owner/repo" with names allowed by GitHub
+func (x GitHubRepoID) Validate() error {
+	owner, repo := x.Split()
+	if !ptnGitHubOwner.MatchString(owner) {
+		return goerr.Wrap(ErrValidationFailed, "invalid repository owner", goerr.V("repo_id", x))
+	}
+	if !ptnGitHubRepo.MatchString(repo) || repo == "." || repo == ".." {
+		return goerr.Wrap(ErrValidationFailed, "invalid repository name", goerr.V("repo_id", x))
+	}
+	return nil
+}
+
+// NewBranchName normalizes a branch name or a "refs/heads/" ref to a branch name
+func NewBranchName(s string) BranchName {
+	return BranchName(strings.TrimPrefix(strings.TrimSpace(s), "refs/heads/"))
+}
+
+// Validate checks the branch name against the rules of git check-ref-format. A name that is
+// not normalized by NewBranchName, e.g. with "refs/heads/" prefix, is rejected as well.
+func (x BranchName) Validate() error {
+	name := string(x)
+	if name == "" {
+		return goerr.Wrap(ErrValidationFailed, "branch name is empty")
+	}
+	if x != NewBranchName(name) {
+		return goerr.Wrap(ErrValidationFailed, "branch name is not normalized", goerr.V("branch", x))
+	}
+	if name == "@" || strings.Contains(name, "..") || strings.Contains(name, "@{") ||
+		strings.HasSuffix(name, ".") || strings.ContainsAny(name, " ~^:?*[\\") {
+		return goerr.Wrap(ErrValidationFailed, "invalid branch name", goerr.V("branch", x))
+	}
+	for _, c := range name {
+		if c < 0x20 || c == 0x7f {
+			return goerr.Wrap(ErrValidationFailed, "branch name contains control character", goerr.V("branch", x))
+		}
+	}
+	for _, component := range strings.Split(name, "/") {
+		if component == "" || strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return goerr.Wrap(ErrValidationFailed, "invalid branch name", goerr.V("branch", x))
+		}
+	}
+	return nil
+}
+
+// NewCommitSHA normalizes a commit SHA by removing surrounding spaces and lowering the case
+func NewCommitSHA(s string) CommitSHA {
+	return CommitSHA(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// Validate checks that the commit SHA is a normalized full length SHA-1 hash
+func (x CommitSHA) Validate() error {
+	if !ptnCommitSHA.MatchString(string(x)) {
+		return goerr.Wrap(ErrValidationFailed, "invalid commit ID", goerr.V("commit", x))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestGitHubRepoID(t *testing.T) {
+	id := types.NewGitHubRepoID(" my-org ", "my.repo_1 ")
+	gt.V(t, id).Equal(types.GitHubRepoID("my-org/my.repo_1"))
+	gt.NoError(t, id.Validate())
+
+	owner, repo := id.Split()
+	gt.V(t, owner).Equal("my-org")
+	gt.V(t, repo).Equal("my.repo_1")
+
+	for _, invalid := range []types.GitHubRepoID{
+		"",
+		"owner",
+		"owner/",
+		"/repo",
+		"owner/repo/extra",
+		"own:er/repo",
+		"-owner/repo",
+		"owner/re po",
+		"owner/..",
+	} {
+		err := invalid.Validate()
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrValidationFailed))
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	gt.V(t, types.NewBranchName("refs/heads/feature/foo")).Equal(types.BranchName("feature/foo"))
+	gt.V(t, types.NewBranchName(" main\n")).Equal(types.BranchName("main"))
+
+	for _, valid := range []types.BranchName{"main", "feature/foo", "release/v1.0.0", "hotfix/bug/urgent/fix", "dependabot/go_modules/golang.org/x/net-0.17.0"} {
+		gt.NoError(t, valid.Validate())
+	}
+
+	for _, invalid := range []types.BranchName{
+		"",
+		"refs/heads/main",
+		" main",
+		"feature//foo",
+		"/main",
+		"main/",
+		"feature/.hidden",
+		"main.lock",
+		"main.",
+		"a..b",
+		"a@{1}",
+		"@",
+		"has space",
+		"has:colon",
+		"has~tilde",
+		"has\ttab",
+	} {
+		err := invalid.Validate()
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrValidationFailed))
+	}
+}
+
+func TestCommitSHA(t *testing.T) {
+	sha := types.NewCommitSHA(" F7C8851DA7C7FCC46212FCCFB6C9C4BDA520F1CA ")
+	gt.V(t, sha).Equal(types.CommitSHA("f7c8851da7c7fcc46212fccfb6c9c4bda520f1ca"))
+	gt.NoError(t, sha.Validate())
+
+	gt.Error(t, types.CommitSHA("abc123").Validate())
+	gt.Error(t, types.CommitSHA("F7C8851DA7C7FCC46212FCCFB6C9C4BDA520F1CA").Validate())
+	gt.Error(t, types.CommitSHA("").Validate())
+}
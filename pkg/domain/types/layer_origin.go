@@ -0,0 +1,9 @@
+package types
+
+// LayerOrigin indicates which part of a container image a layer belongs to.
+type LayerOrigin string
+
+const (
+	LayerOriginBase        LayerOrigin = "base"
+	LayerOriginApplication LayerOrigin = "application"
+)
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// Severity is a severity level of a finding reported by Trivy
+type Severity string
+
+const (
+	SeverityUnknown  Severity = "UNKNOWN"
+	SeverityLow      Severity = "LOW"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityHigh     Severity = "HIGH"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityUnknown:  0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// ParseSeverity converts a case-insensitive string to Severity
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := severityRanks[sev]; !ok {
+		return "", goerr.Wrap(ErrInvalidOption, "invalid severity", goerr.V("severity", s))
+	}
+	return sev, nil
+}
+
+// Rank returns the order of the severity. An unrecognized severity is ranked as UNKNOWN.
+func (x Severity) Rank() int {
+	return severityRanks[Severity(strings.ToUpper(string(x)))]
+}
+
+// Exceeds returns true if the severity is higher than the threshold
+func (x Severity) Exceeds(threshold Severity) bool {
+	return x.Rank() > threshold.Rank()
+}
+
+// AtLeast returns true if the severity is equal to or higher than the threshold
+func (x Severity) AtLeast(threshold Severity) bool {
+	return x.Rank() >= threshold.Rank()
+}
+
+// ParseSeverityThreshold parses a comma separated list of severities such as "CRITICAL,HIGH"
+// and returns the lowest one, so that findings at or above it can be matched with AtLeast.
+func ParseSeverityThreshold(s string) (Severity, error) {
+	var threshold Severity
+	for _, part := range strings.Split(s, ",") {
+		sev, err := ParseSeverity(part)
+		if err != nil {
+			return "", err
+		}
+		if threshold == "" || sev.Rank() < threshold.Rank() {
+			threshold = sev
+		}
+	}
+	return threshold, nil
+}
@@ -0,0 +1,54 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestParseSeverity(t *testing.T) {
+	sev, err := types.ParseSeverity("high")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.SeverityHigh)
+
+	_, err = types.ParseSeverity("urgent")
+	gt.Error(t, err)
+}
+
+func TestSeverityExceeds(t *testing.T) {
+	gt.True(t, types.SeverityCritical.Exceeds(types.SeverityHigh))
+	gt.False(t, types.SeverityHigh.Exceeds(types.SeverityHigh))
+	gt.False(t, types.SeverityLow.Exceeds(types.SeverityMedium))
+
+	// Trivy reports severities in upper case, but lower case values are accepted as well
+	gt.True(t, types.Severity("critical").Exceeds(types.SeverityMedium))
+	gt.False(t, types.Severity("").Exceeds(types.SeverityUnknown))
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	gt.True(t, types.SeverityCritical.AtLeast(types.SeverityHigh))
+	gt.True(t, types.SeverityHigh.AtLeast(types.SeverityHigh))
+	gt.False(t, types.SeverityMedium.AtLeast(types.SeverityHigh))
+	gt.False(t, types.Severity("").AtLeast(types.SeverityLow))
+}
+
+func TestParseSeverityThreshold(t *testing.T) {
+	sev, err := types.ParseSeverityThreshold("CRITICAL,HIGH")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.SeverityHigh)
+
+	sev, err = types.ParseSeverityThreshold("critical")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.SeverityCritical)
+
+	sev, err = types.ParseSeverityThreshold("HIGH, LOW ,MEDIUM")
+	gt.NoError(t, err)
+	gt.V(t, sev).Equal(types.SeverityLow)
+
+	_, err = types.ParseSeverityThreshold("CRITICAL,urgent")
+	gt.Error(t, err)
+
+	_, err = types.ParseSeverityThreshold("")
+	gt.Error(t, err)
+}
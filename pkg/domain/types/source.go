@@ -0,0 +1,37 @@
+package types
+
+import "github.com/m-mizutani/goerr/v2"
+
+// SourceKind is a kind of location where source code to be scanned is fetched from
+type SourceKind string
+
+const (
+	// SourceGitHub is the zipball of the commit downloaded via GitHub App
+	SourceGitHub SourceKind = "github"
+	// SourceLocal is a local directory scanned in place
+	SourceLocal SourceKind = "local"
+	// SourceGCS is a zip archive object in Cloud Storage
+	SourceGCS SourceKind = "gcs"
+	// SourceURL is a zip archive downloaded by HTTP(S)
+	SourceURL SourceKind = "url"
+)
+
+// FetchMode is how source code of a GitHub repository is fetched
+type FetchMode string
+
+const (
+	// FetchModeArchive downloads the zipball of the commit
+	FetchModeArchive FetchMode = "archive"
+	// FetchModeClone runs a shallow clone of the commit with git, for repositories too large for the zipball API
+	FetchModeClone FetchMode = "clone"
+)
+
+// Validate checks the fetch mode is supported
+func (x FetchMode) Validate() error {
+	switch x {
+	case FetchModeArchive, FetchModeClone:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported fetch mode", goerr.V("fetch_mode", x))
+	}
+}
@@ -0,0 +1,51 @@
+package types
+
+import "github.com/m-mizutani/goerr/v2"
+
+// TrivyScanner is a security scanner of Trivy enabled by --scanners option
+type TrivyScanner string
+
+const (
+	TrivyScannerVuln    TrivyScanner = "vuln"
+	TrivyScannerSecret  TrivyScanner = "secret"
+	TrivyScannerMisconf TrivyScanner = "misconfig"
+	TrivyScannerLicense TrivyScanner = "license"
+)
+
+// Validate checks the scanner is supported by Octovy
+func (x TrivyScanner) Validate() error {
+	switch x {
+	case TrivyScannerVuln, TrivyScannerSecret, TrivyScannerMisconf, TrivyScannerLicense:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported trivy scanner", goerr.V("scanner", x))
+	}
+}
+
+// ScannerName is a vulnerability scanner which scans source code. Reports of every scanner are normalized into the Trivy report format.
+type ScannerName string
+
+const (
+	ScannerTrivy ScannerName = "trivy"
+	ScannerGrype ScannerName = "grype"
+)
+
+// Validate checks the scanner is supported by Octovy
+func (x ScannerName) Validate() error {
+	switch x {
+	case ScannerTrivy, ScannerGrype:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported scanner", goerr.V("scanner", x))
+	}
+}
+
+// FindingType is a kind of finding stored with its status in the scan repository
+type FindingType string
+
+const (
+	// FindingTypeVulnerability is also assumed when the type is empty, for data stored before finding types were introduced
+	FindingTypeVulnerability    FindingType = "vulnerability"
+	FindingTypeMisconfiguration FindingType = "misconfiguration"
+	FindingTypeSecret           FindingType = "secret"
+)
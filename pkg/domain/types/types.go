@@ -1,6 +1,12 @@
 package types
 
-import "github.com/google/uuid"
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m-mizutani/goerr/v2"
+)
 
 type (
 	ScanID string
@@ -14,6 +20,41 @@ func (x ScanID) String() string { return string(x) }
 func NewRequestID() RequestID      { return RequestID(uuid.NewString()) }
 func (x RequestID) String() string { return string(x) }
 
+// ScanRunID identifies a ScanRun, which tracks a requested scan until it finishes
+type ScanRunID string
+
+func NewScanRunID() ScanRunID      { return ScanRunID(uuid.NewString()) }
+func (x ScanRunID) String() string { return string(x) }
+
+// OwnerScanRunID identifies an OwnerScanRun, which is the result of a scan of all repositories of an owner
+type OwnerScanRunID string
+
+func NewOwnerScanRunID() OwnerScanRunID { return OwnerScanRunID(uuid.NewString()) }
+func (x OwnerScanRunID) String() string { return string(x) }
+
+// ScanRunStatus is a state of a ScanRun
+type ScanRunStatus string
+
+const (
+	ScanRunQueued    ScanRunStatus = "queued"
+	ScanRunRunning   ScanRunStatus = "running"
+	ScanRunSucceeded ScanRunStatus = "succeeded"
+	ScanRunFailed    ScanRunStatus = "failed"
+)
+
+// ScanTrigger is what requested a scan, i.e. a GitHub event, the scan schedule, a CLI command or a gRPC client
+type ScanTrigger string
+
+const (
+	ScanTriggerPush        ScanTrigger = "push"
+	ScanTriggerPullRequest ScanTrigger = "pull_request"
+	ScanTriggerWorkflowRun ScanTrigger = "workflow_run"
+	ScanTriggerRelease     ScanTrigger = "release"
+	ScanTriggerSchedule    ScanTrigger = "schedule"
+	ScanTriggerCLI         ScanTrigger = "cli"
+	ScanTriggerRPC         ScanTrigger = "rpc"
+)
+
 type (
 	GoogleProjectID string
 
@@ -24,3 +65,85 @@ type (
 func (x GoogleProjectID) String() string { return string(x) }
 func (x BQDatasetID) String() string     { return string(x) }
 func (x BQTableID) String() string       { return string(x) }
+
+// BQInsertMode is a layout of scan results stored in BigQuery
+type BQInsertMode string
+
+const (
+	// BQInsertModeRaw stores a whole scan including the Trivy report as one row
+	BQInsertModeRaw BQInsertMode = "raw"
+	// BQInsertModeNormalized stores a scan summary, vulnerabilities and packages as separate rows in separate tables
+	BQInsertModeNormalized BQInsertMode = "normalized"
+)
+
+// Validate checks the insert mode is supported
+func (x BQInsertMode) Validate() error {
+	switch x {
+	case BQInsertModeRaw, BQInsertModeNormalized:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported BigQuery insert mode", goerr.V("mode", x))
+	}
+}
+
+// BQPartitioning is a granularity of time-partitioning of BigQuery tables by the timestamp column
+type BQPartitioning string
+
+const (
+	// BQPartitioningNone creates tables without partitioning
+	BQPartitioningNone  BQPartitioning = "none"
+	BQPartitioningHour  BQPartitioning = "hour"
+	BQPartitioningDay   BQPartitioning = "day"
+	BQPartitioningMonth BQPartitioning = "month"
+	BQPartitioningYear  BQPartitioning = "year"
+)
+
+// Validate checks the partitioning is supported
+func (x BQPartitioning) Validate() error {
+	switch x {
+	case BQPartitioningNone, BQPartitioningHour, BQPartitioningDay, BQPartitioningMonth, BQPartitioningYear:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported BigQuery partitioning", goerr.V("partitioning", x))
+	}
+}
+
+// BQTableRouting splits scan records into tables by the time of the scan or the owner of the repository, so that old or unneeded records can be dropped with their tables
+type BQTableRouting string
+
+const (
+	// BQTableRoutingNone stores all scans in the configured tables
+	BQTableRoutingNone BQTableRouting = "none"
+	// BQTableRoutingMonth stores scans in tables of the UTC month, e.g. scans_202501
+	BQTableRoutingMonth BQTableRouting = "month"
+	// BQTableRoutingOwner stores scans in tables of the repository owner, e.g. scans_my_org
+	BQTableRoutingOwner BQTableRouting = "owner"
+)
+
+// Validate checks the routing is supported
+func (x BQTableRouting) Validate() error {
+	switch x {
+	case BQTableRoutingNone, BQTableRoutingMonth, BQTableRoutingOwner:
+		return nil
+	default:
+		return goerr.Wrap(ErrInvalidOption, "unsupported BigQuery table routing", goerr.V("routing", x))
+	}
+}
+
+// Route returns the table to store a scan of the owner at the timestamp instead of table. Characters not allowed in table IDs are replaced with "_", and the owner is lower-cased because GitHub owners are case-insensitive.
+func (x BQTableRouting) Route(table BQTableID, timestamp time.Time, owner string) BQTableID {
+	switch x {
+	case BQTableRoutingMonth:
+		return table + "_" + BQTableID(timestamp.UTC().Format("200601"))
+	case BQTableRoutingOwner:
+		suffix := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+			return '_'
+		}, strings.ToLower(owner))
+		return table + "_" + BQTableID(suffix)
+	default:
+		return table
+	}
+}
@@ -0,0 +1,21 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+func TestBQTableRoutingRoute(t *testing.T) {
+	ts := time.Date(2025, 1, 31, 23, 30, 0, 0, time.FixedZone("UTC-9", -9*60*60))
+
+	gt.V(t, types.BQTableRoutingNone.Route("scans", ts, "my-org")).Equal("scans")
+	// The month is of UTC
+	gt.V(t, types.BQTableRoutingMonth.Route("scans", ts, "my-org")).Equal("scans_202502")
+	gt.V(t, types.BQTableRoutingOwner.Route("scans_vulnerabilities", ts, "My-Org")).Equal("scans_vulnerabilities_my_org")
+
+	gt.NoError(t, types.BQTableRoutingOwner.Validate())
+	gt.Error(t, types.BQTableRouting("repo").Validate())
+}
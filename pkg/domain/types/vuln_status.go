@@ -1,8 +1,29 @@
 package types
 
+import (
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
 type VulnStatus string
 
 const (
 	VulnStatusActive VulnStatus = "active"
 	VulnStatusFixed  VulnStatus = "fixed"
+
+	// VulnStatusAcknowledged means the vulnerability is still detected but has been triaged
+	// (e.g. dismissed as a code scanning alert in GitHub)
+	VulnStatusAcknowledged VulnStatus = "acknowledged"
 )
+
+// ParseVulnStatus converts a case-insensitive string to VulnStatus
+func ParseVulnStatus(s string) (VulnStatus, error) {
+	status := VulnStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case VulnStatusActive, VulnStatusFixed, VulnStatusAcknowledged:
+		return status, nil
+	default:
+		return "", goerr.Wrap(ErrInvalidOption, "invalid vulnerability status", goerr.V("status", s))
+	}
+}
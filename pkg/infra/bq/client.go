@@ -6,32 +6,40 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/bigquery/storage/managedwriter"
-	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
-	"github.com/m-mizutani/octovy/pkg/utils/safe"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 const (
-	maxRetries        = 10
-	initialBackoff    = 5 * time.Second
-	maxBackoff        = 60 * time.Second
+	// DefaultInsertMaxRetries, DefaultInsertInitialBackoff and DefaultInsertMaxBackoff are used to retry inserts until the updated schema of the table is propagated to the Storage Write API
+	DefaultInsertMaxRetries     = 10
+	DefaultInsertInitialBackoff = 5 * time.Second
+	DefaultInsertMaxBackoff     = 60 * time.Second
+
 	backoffMultiplier = 1.5
+
+	// maxAppendRequestBytes keeps each AppendRows request under the 10MB limit of the Storage Write API with some margin for request overhead.
+	maxAppendRequestBytes = 9 * 1024 * 1024
 )
 
 type Client struct {
@@ -40,28 +48,73 @@ type Client struct {
 	project  string
 	dataset  string
 	tableID  types.BQTableID
+
+	// streams is shared with clients of other tables created by Table
+	streams *streamCache
+
+	retry insertRetry
 }
 
 var _ interfaces.BigQuery = (*Client)(nil)
 
-func New(ctx context.Context, projectID types.GoogleProjectID, datasetID types.BQDatasetID, tableID types.BQTableID, options ...option.ClientOption) (*Client, error) {
-	mwClient, err := managedwriter.NewClient(ctx, projectID.String(), options...)
+type config struct {
+	clientOptions []option.ClientOption
+	retry         insertRetry
+}
+
+type Option func(*config)
+
+// WithClientOptions sets options of Google Cloud API clients, e.g. credentials
+func WithClientOptions(options ...option.ClientOption) Option {
+	return func(c *config) {
+		c.clientOptions = append(c.clientOptions, options...)
+	}
+}
+
+// WithInsertRetry replaces the default retry of inserts failed by a schema mismatch. The backoff starts from initialBackoff and grows up to maxBackoff.
+func WithInsertRetry(maxRetries int, initialBackoff, maxBackoff time.Duration) Option {
+	return func(c *config) {
+		c.retry = insertRetry{
+			maxRetries:     maxRetries,
+			initialBackoff: initialBackoff,
+			maxBackoff:     maxBackoff,
+		}
+	}
+}
+
+func New(ctx context.Context, projectID types.GoogleProjectID, datasetID types.BQDatasetID, tableID types.BQTableID, options ...Option) (*Client, error) {
+	cfg := &config{
+		retry: insertRetry{
+			maxRetries:     DefaultInsertMaxRetries,
+			initialBackoff: DefaultInsertInitialBackoff,
+			maxBackoff:     DefaultInsertMaxBackoff,
+		},
+	}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	mwClient, err := managedwriter.NewClient(ctx, projectID.String(), cfg.clientOptions...)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to create bigquery client", goerr.V("projectID", projectID))
 	}
 
-	bqClient, err := bigquery.NewClient(ctx, string(projectID), options...)
+	bqClient, err := bigquery.NewClient(ctx, string(projectID), cfg.clientOptions...)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to create BigQuery client", goerr.V("projectID", projectID))
 	}
 
-	return &Client{
+	client := &Client{
 		bqClient: bqClient,
 		mwClient: mwClient,
 		project:  projectID.String(),
 		dataset:  datasetID.String(),
 		tableID:  tableID,
-	}, nil
+		retry:    cfg.retry,
+	}
+	client.streams = newStreamCache(client.newManagedStream)
+
+	return client, nil
 }
 
 // CreateTable implements interfaces.BigQuery.
@@ -72,6 +125,35 @@ func (x *Client) CreateTable(ctx context.Context, md *bigquery.TableMetadata) er
 	return nil
 }
 
+// CheckDataset implements interfaces.BigQuery.
+func (x *Client) CheckDataset(ctx context.Context) error {
+	if _, err := x.bqClient.Dataset(x.dataset).Metadata(ctx); err != nil {
+		return goerr.Wrap(err, "failed to get dataset metadata", goerr.V("project", x.project), goerr.V("dataset", x.dataset))
+	}
+	return nil
+}
+
+// CreateDatasetIfNotExists creates the dataset in the location if it does not exist. The default location of BigQuery is used if location is empty.
+func (x *Client) CreateDatasetIfNotExists(ctx context.Context, location string) error {
+	dataset := x.bqClient.Dataset(x.dataset)
+	if _, err := dataset.Metadata(ctx); err == nil {
+		return nil
+	} else if gErr, ok := err.(*googleapi.Error); !ok || gErr.Code != 404 {
+		return goerr.Wrap(err, "failed to get dataset metadata", goerr.V("project", x.project), goerr.V("dataset", x.dataset))
+	}
+
+	if err := dataset.Create(ctx, &bigquery.DatasetMetadata{Location: location}); err != nil {
+		// The dataset may be created by another instance at the same time
+		if gErr, ok := err.(*googleapi.Error); ok && gErr.Code == 409 {
+			return nil
+		}
+		return goerr.Wrap(err, "failed to create dataset", goerr.V("project", x.project), goerr.V("dataset", x.dataset), goerr.V("location", location))
+	}
+
+	logging.From(ctx).Info("created BigQuery dataset", "project", x.project, "dataset", x.dataset, "location", location)
+	return nil
+}
+
 // GetMetadata implements interfaces.BigQuery. If the table does not exist, it returns nil.
 func (x *Client) GetMetadata(ctx context.Context) (*bigquery.TableMetadata, error) {
 	md, err := x.bqClient.Dataset(x.dataset).Table(x.tableID.String()).Metadata(ctx)
@@ -85,21 +167,50 @@ func (x *Client) GetMetadata(ctx context.Context) (*bigquery.TableMetadata, erro
 	return md, nil
 }
 
+// Table implements interfaces.BigQuery. The returned client shares underlying connections with x.
+func (x *Client) Table(tableID types.BQTableID) interfaces.BigQuery {
+	client := *x
+	client.tableID = tableID
+	return &client
+}
+
 // Insert implements interfaces.BigQuery.
 func (x *Client) Insert(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+	return x.InsertRows(ctx, schema, []any{data}, opts...)
+}
+
+// InsertRows implements interfaces.BigQuery. All rows are appended through one managed stream, and split into multiple append requests if they exceed the request size limit.
+func (x *Client) InsertRows(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
 	cfg := &interfaces.BigQueryInsertConfig{}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	enableRetry := cfg.EnableRetry
+	return x.retry.do(ctx, cfg.EnableRetry, func() error {
+		return x.attemptInsert(ctx, schema, rows)
+	})
+}
+
+// insertRetry retries inserts failed by a schema mismatch with exponential backoff, because an updated schema of the table takes time to be propagated to the Storage Write API
+type insertRetry struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// do calls insert until it succeeds, fails with an error other than a schema mismatch, or fails maxRetries+1 times. The wait between attempts is aborted when ctx is cancelled, so that shutdown is not blocked by the backoff.
+func (x insertRetry) do(ctx context.Context, enableRetry bool, insert func() error) error {
 	logger := logging.From(ctx)
 
 	var lastErr error
-	backoff := initialBackoff
+	backoff := x.initialBackoff
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		err := x.attemptInsert(ctx, schema, data)
+	for attempt := 0; attempt <= x.maxRetries; attempt++ {
+		err := insert()
 		if err == nil {
 			return nil
 		}
@@ -121,7 +232,7 @@ func (x *Client) Insert(ctx context.Context, schema bigquery.Schema, data any, o
 		}
 
 		// If we've exhausted retries, return the error
-		if attempt >= maxRetries {
+		if attempt >= x.maxRetries {
 			logger.Error("Maximum retry attempts reached for schema mismatch error",
 				"attempts", attempt+1,
 				"error", err,
@@ -138,90 +249,128 @@ func (x *Client) Insert(ctx context.Context, schema bigquery.Schema, data any, o
 			"error", err,
 		)
 
-		// Wait with exponential backoff, respecting context cancellation
-		select {
-		case <-time.After(backoff):
-		case <-ctx.Done():
-			return goerr.Wrap(ctx.Err(), "context cancelled during backoff wait", goerr.V("last_error", lastErr))
+		if err := sleepWithContext(ctx, backoff); err != nil {
+			return goerr.Wrap(err, "context cancelled during backoff wait", goerr.V("last_error", lastErr))
 		}
 		backoff = time.Duration(float64(backoff) * backoffMultiplier)
-		if backoff > maxBackoff {
-			backoff = maxBackoff
+		if backoff > x.maxBackoff {
+			backoff = x.maxBackoff
 		}
 	}
 
 	return lastErr
 }
 
-func (x *Client) attemptInsert(ctx context.Context, schema bigquery.Schema, data any) error {
-	convertedSchema, err := adapt.BQSchemaToStorageTableSchema(schema)
-	if err != nil {
-		return goerr.Wrap(err, "failed to convert schema")
+// sleepWithContext waits for d, or returns the error of ctx as soon as it is cancelled
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	descriptor, err := adapt.StorageSchemaToProto2Descriptor(convertedSchema, "root")
+func (x *Client) newManagedStream(ctx context.Context, tableID types.BQTableID, dp *descriptorpb.DescriptorProto) (appendStream, error) {
+	return x.mwClient.NewManagedStream(ctx,
+		managedwriter.WithDestinationTable(
+			managedwriter.TableParentFromParts(
+				x.project,
+				x.dataset,
+				tableID.String(),
+			),
+		),
+		// managedwriter.WithType(managedwriter.CommittedStream),
+		managedwriter.WithSchemaDescriptor(dp),
+	)
+}
+
+func (x *Client) attemptInsert(ctx context.Context, schema bigquery.Schema, rows []any) error {
+	entry, err := x.streams.acquire(ctx, x.tableID, schema)
 	if err != nil {
-		return goerr.Wrap(err, "failed to convert schema to descriptor")
+		return err
 	}
-	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
-	if !ok {
-		return goerr.Wrap(err, "adapted descriptor is not a message descriptor")
+	defer x.streams.release(entry)
+
+	encoded := make([][]byte, 0, len(rows))
+	for _, data := range rows {
+		b, err := encodeRow(entry.descriptor, data)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, b)
 	}
-	descriptorProto, err := adapt.NormalizeDescriptor(messageDescriptor)
-	if err != nil {
-		return goerr.Wrap(err, "failed to normalize descriptor")
+
+	// Send all batches first and then wait for the results so that appends are pipelined on the stream.
+	var results []*managedwriter.AppendResult
+	for _, batch := range splitRows(encoded, maxAppendRequestBytes) {
+		arResult, err := entry.stream.AppendRows(ctx, batch)
+		if err != nil {
+			x.streams.invalidate(x.tableID, entry)
+			return goerr.Wrap(err, "failed to append rows", goerr.V("table", x.tableID), goerr.V("rows", len(batch)))
+		}
+		results = append(results, arResult)
 	}
 
+	for _, arResult := range results {
+		if _, err := arResult.FullResponse(ctx); err != nil {
+			// The stream may be broken or bound to an outdated schema, so do not reuse it.
+			x.streams.invalidate(x.tableID, entry)
+			return goerr.Wrap(err, "failed to get append result", goerr.V("table", x.tableID))
+		}
+	}
+
+	return nil
+}
+
+func encodeRow(messageDescriptor protoreflect.MessageDescriptor, data any) ([]byte, error) {
 	message := dynamicpb.NewMessage(messageDescriptor)
 
 	raw, err := json.Marshal(data)
 	if err != nil {
-		return goerr.Wrap(err, "failed to Marshal json message", goerr.V("v", data))
+		return nil, goerr.Wrap(err, "failed to Marshal json message", goerr.V("v", data))
 	}
 	sanitizedRaw, err := sanitizeProtoJSON(raw)
 	if err != nil {
-		return goerr.Wrap(err, "failed to sanitize json message", goerr.V("raw", string(raw)))
+		return nil, goerr.Wrap(err, "failed to sanitize json message", goerr.V("raw", string(raw)))
 	}
 
 	// First, json->proto message
-	err = protojson.Unmarshal(sanitizedRaw, message)
-	if err != nil {
-		return goerr.Wrap(err, "failed to Unmarshal json message", goerr.V("raw", string(raw)))
+	if err := protojson.Unmarshal(sanitizedRaw, message); err != nil {
+		return nil, goerr.Wrap(err, "failed to Unmarshal json message", goerr.V("raw", string(raw)))
 	}
 	// Then, proto message -> bytes.
 	b, err := proto.Marshal(message)
 	if err != nil {
-		return goerr.Wrap(err, "failed to Marshal proto message")
+		return nil, goerr.Wrap(err, "failed to Marshal proto message")
 	}
 
-	rows := [][]byte{b}
-
-	ms, err := x.mwClient.NewManagedStream(ctx,
-		managedwriter.WithDestinationTable(
-			managedwriter.TableParentFromParts(
-				x.project,
-				x.dataset,
-				x.tableID.String(),
-			),
-		),
-		// managedwriter.WithType(managedwriter.CommittedStream),
-		managedwriter.WithSchemaDescriptor(descriptorProto),
-	)
-	if err != nil {
-		return goerr.Wrap(err, "failed to create managed stream")
-	}
-	defer safe.Close(ms)
+	return b, nil
+}
 
-	arResult, err := ms.AppendRows(ctx, rows)
-	if err != nil {
-		return goerr.Wrap(err, "failed to append rows")
+// splitRows groups encoded rows into batches whose total size does not exceed limit. A row larger than limit is put into its own batch and left to be rejected by BigQuery.
+func splitRows(rows [][]byte, limit int) [][][]byte {
+	var batches [][][]byte
+	var current [][]byte
+	size := 0
+
+	for _, row := range rows {
+		if len(current) > 0 && size+len(row) > limit {
+			batches = append(batches, current)
+			current = nil
+			size = 0
+		}
+		current = append(current, row)
+		size += len(row)
 	}
-
-	if _, err := arResult.FullResponse(ctx); err != nil {
-		return goerr.Wrap(err, "failed to get append result")
+	if len(current) > 0 {
+		batches = append(batches, current)
 	}
 
-	return nil
+	return batches
 }
 
 func sanitizeProtoJSON(raw []byte) ([]byte, error) {
@@ -277,6 +426,326 @@ func (x *Client) UpdateTable(ctx context.Context, md bigquery.TableMetadataToUpd
 	return nil
 }
 
+// CountRows implements interfaces.BigQuery.
+func (x *Client) CountRows(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+	if md, err := x.GetMetadata(ctx); err != nil || md == nil {
+		return 0, err
+	}
+
+	where, params, err := buildWhereClause(conditions)
+	if err != nil {
+		return 0, err
+	}
+
+	q := x.bqClient.Query("SELECT COUNT(*) FROM " + x.quotedTable() + " WHERE " + where)
+	q.Parameters = params
+	it, err := q.Read(ctx)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to count rows", goerr.V("table", x.tableID), goerr.V("conditions", conditions))
+	}
+
+	var row []bigquery.Value
+	if err := it.Next(&row); err != nil {
+		return 0, goerr.Wrap(err, "failed to read row count", goerr.V("table", x.tableID))
+	}
+	count, ok := row[0].(int64)
+	if !ok {
+		return 0, goerr.New("unexpected type of row count", goerr.V("table", x.tableID), goerr.V("value", row[0]))
+	}
+	return count, nil
+}
+
+// DeleteRows implements interfaces.BigQuery. Rows still in the streaming buffer cannot be deleted by DML, so the deletion fails for recently inserted rows.
+func (x *Client) DeleteRows(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+	if md, err := x.GetMetadata(ctx); err != nil || md == nil {
+		return 0, err
+	}
+
+	where, params, err := buildWhereClause(conditions)
+	if err != nil {
+		return 0, err
+	}
+
+	q := x.bqClient.Query("DELETE FROM " + x.quotedTable() + " WHERE " + where)
+	q.Parameters = params
+	return x.runDML(ctx, q, goerr.V("conditions", conditions))
+}
+
+// runDML runs the DML query and returns the number of affected rows
+func (x *Client) runDML(ctx context.Context, q *bigquery.Query, opts ...goerr.Option) (int64, error) {
+	jobStatus, err := x.runJob(ctx, q, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	stats, ok := jobStatus.Statistics.Details.(*bigquery.QueryStatistics)
+	if !ok {
+		return 0, nil
+	}
+	return stats.NumDMLAffectedRows, nil
+}
+
+// runJob runs the query and waits until the job completes
+func (x *Client) runJob(ctx context.Context, q *bigquery.Query, opts ...goerr.Option) (*bigquery.JobStatus, error) {
+	opts = append(opts, goerr.V("table", x.tableID))
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to start query job", opts...)
+	}
+	jobStatus, err := job.Wait(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to wait query job", append(opts, goerr.V("job_id", job.ID()))...)
+	}
+	if err := jobStatus.Err(); err != nil {
+		return nil, goerr.Wrap(err, "query job failed", append(opts, goerr.V("job_id", job.ID()))...)
+	}
+	return jobStatus, nil
+}
+
+// CountRowsByDay implements interfaces.BigQuery.
+func (x *Client) CountRowsByDay(ctx context.Context, before time.Time) ([]*interfaces.BigQueryDayRows, error) {
+	md, err := x.GetMetadata(ctx)
+	if err != nil || md == nil {
+		return nil, err
+	}
+	fieldType, err := timestampFieldType(md.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	day := "DATE(timestamp)"
+	if fieldType == bigquery.IntegerFieldType {
+		day = "DATE(TIMESTAMP_MICROS(timestamp))"
+	}
+	q := x.bqClient.Query("SELECT FORMAT_DATE('%F', " + day + ") AS day, COUNT(*) AS num FROM " + x.quotedTable() +
+		" WHERE timestamp < @before GROUP BY day ORDER BY day")
+	q.Parameters = []bigquery.QueryParameter{{Name: "before", Value: timestampValue(fieldType, before)}}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to count rows by day", goerr.V("table", x.tableID), goerr.V("before", before))
+	}
+
+	var days []*interfaces.BigQueryDayRows
+	for {
+		var row struct {
+			Day string `bigquery:"day"`
+			Num int64  `bigquery:"num"`
+		}
+		if err := it.Next(&row); err != nil {
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			return nil, goerr.Wrap(err, "failed to read row count by day", goerr.V("table", x.tableID))
+		}
+
+		d, err := time.Parse(time.DateOnly, row.Day)
+		if err != nil {
+			return nil, goerr.Wrap(err, "unexpected day format", goerr.V("table", x.tableID), goerr.V("day", row.Day))
+		}
+		days = append(days, &interfaces.BigQueryDayRows{Day: d, Rows: row.Num})
+	}
+	return days, nil
+}
+
+// ExportRows implements interfaces.BigQuery. Files of the destination are overwritten, so that an interrupted export can be retried.
+func (x *Client) ExportRows(ctx context.Context, uri string, r interfaces.BigQueryTimeRange) error {
+	if !exportURIPattern.MatchString(uri) {
+		return goerr.New("invalid export URI", goerr.V("uri", uri))
+	}
+
+	md, err := x.GetMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return goerr.New("table not found", goerr.V("table", x.tableID))
+	}
+	where, params, err := timeRangeClause(md.Schema, r)
+	if err != nil {
+		return err
+	}
+
+	// URI can not be a query parameter in OPTIONS, so it is embedded after validation
+	q := x.bqClient.Query("EXPORT DATA OPTIONS(uri='" + uri + "', format='PARQUET', compression='SNAPPY', overwrite=true) AS SELECT * FROM " +
+		x.quotedTable() + " WHERE " + where)
+	q.Parameters = params
+	if _, err := x.runJob(ctx, q, goerr.V("uri", uri), goerr.V("range", r)); err != nil {
+		return goerr.Wrap(err, "failed to export rows")
+	}
+	return nil
+}
+
+// exportURIPattern accepts a Cloud Storage URI with one wildcard and without quote
+var exportURIPattern = regexp.MustCompile(`^gs://[a-z0-9][a-z0-9._-]*/[^'"\\*]*\*[^'"\\*]*$`)
+
+// DeleteRowsInRange implements interfaces.BigQuery.
+func (x *Client) DeleteRowsInRange(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error) {
+	md, err := x.GetMetadata(ctx)
+	if err != nil || md == nil {
+		return 0, err
+	}
+	where, params, err := timeRangeClause(md.Schema, r)
+	if err != nil {
+		return 0, err
+	}
+
+	q := x.bqClient.Query("DELETE FROM " + x.quotedTable() + " WHERE " + where)
+	q.Parameters = params
+	return x.runDML(ctx, q, goerr.V("range", r))
+}
+
+// LoadParquet implements interfaces.BigQuery.
+func (x *Client) LoadParquet(ctx context.Context, uri string) (int64, error) {
+	ref := bigquery.NewGCSReference(uri)
+	ref.SourceFormat = bigquery.Parquet
+	// Repeated fields are exported as Parquet LIST, which is loaded back to repeated fields only with list inference
+	ref.ParquetOptions = &bigquery.ParquetOptions{EnableListInference: true}
+
+	loader := x.bqClient.Dataset(x.dataset).Table(x.tableID.String()).LoaderFrom(ref)
+	loader.WriteDisposition = bigquery.WriteAppend
+
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to start load job", goerr.V("table", x.tableID), goerr.V("uri", uri))
+	}
+	jobStatus, err := job.Wait(ctx)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to wait load job", goerr.V("table", x.tableID), goerr.V("job_id", job.ID()))
+	}
+	if err := jobStatus.Err(); err != nil {
+		return 0, goerr.Wrap(err, "failed to load Parquet files", goerr.V("table", x.tableID), goerr.V("uri", uri), goerr.V("job_id", job.ID()))
+	}
+
+	stats, ok := jobStatus.Statistics.Details.(*bigquery.LoadStatistics)
+	if !ok {
+		return 0, nil
+	}
+	return stats.OutputRows, nil
+}
+
+// ListRawScans implements interfaces.BigQuery. Rows are read as JSON because column names of the table are inferred from JSON tags of the model.
+func (x *Client) ListRawScans(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error {
+	md, err := x.GetMetadata(ctx)
+	if err != nil || md == nil {
+		return err
+	}
+	if fieldType, err := timestampFieldType(md.Schema); err != nil || fieldType != bigquery.IntegerFieldType {
+		return goerr.New("table is not a raw scan table, scans can not be read in normalized insert mode", goerr.V("table", x.tableID))
+	}
+
+	query := "SELECT TO_JSON_STRING(t) AS scan FROM " + x.quotedTable() + " AS t"
+	var params []bigquery.QueryParameter
+	if len(conditions) > 0 {
+		var where string
+		if where, params, err = buildWhereClause(conditions); err != nil {
+			return err
+		}
+		query += " WHERE " + where
+	}
+	q := x.bqClient.Query(query + " ORDER BY timestamp")
+	q.Parameters = params
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return goerr.Wrap(err, "failed to query scans", goerr.V("table", x.tableID), goerr.V("conditions", conditions))
+	}
+	for {
+		var row struct {
+			Scan string `bigquery:"scan"`
+		}
+		if err := it.Next(&row); err != nil {
+			if errors.Is(err, iterator.Done) {
+				return nil
+			}
+			return goerr.Wrap(err, "failed to read scan row", goerr.V("table", x.tableID))
+		}
+
+		scan, err := decodeRawScan([]byte(row.Scan))
+		if err != nil {
+			return err
+		}
+		if err := fn(scan); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeRawScan decodes a row of the raw scan table, which has the timestamp as UNIX microseconds
+func decodeRawScan(raw []byte) (*model.Scan, error) {
+	var record model.ScanRawRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode scan row")
+	}
+	scan := record.Scan
+	scan.Timestamp = time.UnixMicro(record.Timestamp).UTC()
+	return &scan, nil
+}
+
+// timestampFieldType returns type of the timestamp column. The scan table has UNIX microseconds as INTEGER, and normalized tables have TIMESTAMP.
+func timestampFieldType(schema bigquery.Schema) (bigquery.FieldType, error) {
+	for _, field := range schema {
+		if field.Name != "timestamp" {
+			continue
+		}
+		switch field.Type {
+		case bigquery.IntegerFieldType, bigquery.TimestampFieldType:
+			return field.Type, nil
+		default:
+			return "", goerr.New("unsupported type of timestamp column", goerr.V("type", field.Type))
+		}
+	}
+	return "", goerr.New("timestamp column is not found")
+}
+
+func timestampValue(fieldType bigquery.FieldType, t time.Time) any {
+	if fieldType == bigquery.IntegerFieldType {
+		return t.UnixMicro()
+	}
+	return t
+}
+
+func timeRangeClause(schema bigquery.Schema, r interfaces.BigQueryTimeRange) (string, []bigquery.QueryParameter, error) {
+	if !r.Start.Before(r.End) {
+		return "", nil, goerr.New("invalid time range", goerr.V("start", r.Start), goerr.V("end", r.End))
+	}
+	fieldType, err := timestampFieldType(schema)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "timestamp >= @start AND timestamp < @end", []bigquery.QueryParameter{
+		{Name: "start", Value: timestampValue(fieldType, r.Start)},
+		{Name: "end", Value: timestampValue(fieldType, r.End)},
+	}, nil
+}
+
+func (x *Client) quotedTable() string {
+	return "`" + x.project + "." + x.dataset + "." + x.tableID.String() + "`"
+}
+
+// buildWhereClause joins conditions with AND. Values are passed as query parameters, and columns are validated because they are embedded in the query.
+func buildWhereClause(conditions []interfaces.BigQueryCondition) (string, []bigquery.QueryParameter, error) {
+	if len(conditions) == 0 {
+		return "", nil, goerr.New("at least one condition is required")
+	}
+
+	clauses := make([]string, len(conditions))
+	params := make([]bigquery.QueryParameter, len(conditions))
+	for i, cond := range conditions {
+		if !columnPathPattern.MatchString(cond.Column) {
+			return "", nil, goerr.New("invalid column path", goerr.V("column", cond.Column))
+		}
+		name := "p" + strconv.Itoa(i)
+		clauses[i] = cond.Column + " = @" + name
+		params[i] = bigquery.QueryParameter{Name: name, Value: cond.Value}
+	}
+	return strings.Join(clauses, " AND "), params, nil
+}
+
+var columnPathPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
 // isSchemaNotFoundError checks if the error is a schema mismatch error from BigQuery Storage Write API.
 func isSchemaNotFoundError(err error) bool {
 	if err == nil {
@@ -86,7 +86,7 @@ func TestImpersonation(t *testing.T) {
 	gt.NoError(t, err)
 
 	tblName := types.BQTableID(time.Now().Format("impersonation_test_20060102_150405"))
-	client, err := bq.New(ctx, types.GoogleProjectID(projectID), types.BQDatasetID(datasetID), tblName, option.WithTokenSource(ts))
+	client, err := bq.New(ctx, types.GoogleProjectID(projectID), types.BQDatasetID(datasetID), tblName, bq.WithClientOptions(option.WithTokenSource(ts)))
 	gt.NoError(t, err)
 
 	msg := struct {
@@ -262,6 +262,53 @@ func TestIsSchemaNotFoundError(t *testing.T) {
 	})
 }
 
+func TestInsertRetry(t *testing.T) {
+	schemaErr := status.Error(codes.InvalidArgument, "Input schema has more fields than BigQuery schema, extra fields: 'field1'")
+
+	t.Run("retries schema mismatch until success", func(t *testing.T) {
+		var calls int
+		err := bq.RetryInsertForTest(context.Background(), 3, time.Millisecond, 2*time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return schemaErr
+			}
+			return nil
+		})
+		gt.NoError(t, err)
+		gt.V(t, calls).Equal(3)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		var calls int
+		err := bq.RetryInsertForTest(context.Background(), 2, time.Millisecond, time.Millisecond, func() error {
+			calls++
+			return schemaErr
+		})
+		gt.Error(t, err)
+		gt.V(t, calls).Equal(3)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		var calls int
+		err := bq.RetryInsertForTest(context.Background(), 3, time.Millisecond, time.Millisecond, func() error {
+			calls++
+			return errors.New("permission denied")
+		})
+		gt.Error(t, err)
+		gt.V(t, calls).Equal(1)
+	})
+
+	t.Run("backoff is aborted by cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := bq.RetryInsertForTest(ctx, 3, time.Minute, time.Minute, func() error { return schemaErr })
+		gt.True(t, errors.Is(err, context.DeadlineExceeded))
+		gt.True(t, time.Since(start) < 10*time.Second)
+	})
+}
+
 func TestSchemaUpdateAndRetry(t *testing.T) {
 	projectID := testutil.GetEnvOrSkip(t, "TEST_BIGQUERY_PROJECT_ID")
 	datasetID := testutil.GetEnvOrSkip(t, "TEST_BIGQUERY_DATASET_ID")
@@ -313,3 +360,117 @@ func TestSchemaUpdateAndRetry(t *testing.T) {
 		gt.NoError(t, err)
 	})
 }
+
+func TestSplitRows(t *testing.T) {
+	row := func(size int) []byte { return bytes.Repeat([]byte{'x'}, size) }
+
+	t.Run("rows within limit are sent in one batch", func(t *testing.T) {
+		batches := bq.SplitRows([][]byte{row(3), row(3), row(4)}, 10)
+		gt.A(t, batches).Length(1)
+		gt.A(t, batches[0]).Length(3)
+	})
+
+	t.Run("rows exceeding limit are split", func(t *testing.T) {
+		batches := bq.SplitRows([][]byte{row(4), row(4), row(4), row(4)}, 10)
+		gt.A(t, batches).Length(2)
+		gt.A(t, batches[0]).Length(2)
+		gt.A(t, batches[1]).Length(2)
+	})
+
+	t.Run("oversized row is put into its own batch", func(t *testing.T) {
+		batches := bq.SplitRows([][]byte{row(2), row(20), row(2)}, 10)
+		gt.A(t, batches).Length(3)
+		gt.A(t, batches[1]).Length(1)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		gt.A(t, bq.SplitRows(nil, 10)).Length(0)
+	})
+}
+
+func TestBuildWhereClause(t *testing.T) {
+	where, params, err := bq.BuildWhereClause([]interfaces.BigQueryCondition{
+		{Column: "github.owner", Value: "test-org"},
+		{Column: "github.repo_name", Value: "app"},
+	})
+	gt.NoError(t, err)
+	gt.V(t, where).Equal("github.owner = @p0 AND github.repo_name = @p1")
+	gt.V(t, params).Equal([]bigquery.QueryParameter{
+		{Name: "p0", Value: "test-org"},
+		{Name: "p1", Value: "app"},
+	})
+
+	t.Run("condition is required", func(t *testing.T) {
+		_, _, err := bq.BuildWhereClause(nil)
+		gt.Error(t, err)
+	})
+
+	t.Run("column is not injectable", func(t *testing.T) {
+		_, _, err := bq.BuildWhereClause([]interfaces.BigQueryCondition{{Column: "owner = owner OR 1", Value: "x"}})
+		gt.Error(t, err)
+	})
+}
+
+func TestTimeRangeClause(t *testing.T) {
+	r := interfaces.BigQueryTimeRange{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("scan table has UNIX microseconds", func(t *testing.T) {
+		where, params, err := bq.TimeRangeClause(bigquery.Schema{{Name: "timestamp", Type: bigquery.IntegerFieldType}}, r)
+		gt.NoError(t, err)
+		gt.V(t, where).Equal("timestamp >= @start AND timestamp < @end")
+		gt.V(t, params).Equal([]bigquery.QueryParameter{
+			{Name: "start", Value: r.Start.UnixMicro()},
+			{Name: "end", Value: r.End.UnixMicro()},
+		})
+	})
+
+	t.Run("normalized table has TIMESTAMP", func(t *testing.T) {
+		_, params, err := bq.TimeRangeClause(bigquery.Schema{{Name: "timestamp", Type: bigquery.TimestampFieldType}}, r)
+		gt.NoError(t, err)
+		gt.V(t, params).Equal([]bigquery.QueryParameter{
+			{Name: "start", Value: r.Start},
+			{Name: "end", Value: r.End},
+		})
+	})
+
+	t.Run("timestamp column is required", func(t *testing.T) {
+		_, _, err := bq.TimeRangeClause(bigquery.Schema{{Name: "id", Type: bigquery.StringFieldType}}, r)
+		gt.Error(t, err)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		_, _, err := bq.TimeRangeClause(bigquery.Schema{{Name: "timestamp", Type: bigquery.IntegerFieldType}}, interfaces.BigQueryTimeRange{Start: r.End, End: r.Start})
+		gt.Error(t, err)
+	})
+}
+
+func TestExportURIPattern(t *testing.T) {
+	gt.True(t, bq.ExportURIPattern.MatchString("gs://my-bucket/octovy/archive/scan/day=2024-01-01/*.parquet"))
+	gt.False(t, bq.ExportURIPattern.MatchString("s3://my-bucket/archive/*.parquet"))
+	gt.False(t, bq.ExportURIPattern.MatchString("gs://my-bucket/archive/data.parquet"))
+	gt.False(t, bq.ExportURIPattern.MatchString("gs://my-bucket/archive/*/*.parquet"))
+	gt.False(t, bq.ExportURIPattern.MatchString("gs://my-bucket/a', format='CSV')--/*.parquet"))
+}
+
+func TestDecodeRawScan(t *testing.T) {
+	scan := model.Scan{
+		ID:        "scan-1",
+		Timestamp: time.Date(2024, 1, 1, 12, 30, 0, 123456000, time.UTC),
+		GitHub: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-org", RepoName: "app"},
+				CommitID:   "abc123",
+				Branch:     "main",
+			},
+		},
+	}
+	raw, err := json.Marshal(model.ScanRawRecord{Scan: scan, Timestamp: scan.Timestamp.UnixMicro()})
+	gt.NoError(t, err)
+
+	decoded, err := bq.DecodeRawScan(raw)
+	gt.NoError(t, err)
+	gt.V(t, decoded).Equal(&scan)
+}
@@ -1,7 +1,45 @@
 package bq
 
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
 var (
 	SanitizeProtoJSON     = sanitizeProtoJSON
 	ProtoFieldJSONName    = protoFieldJSONName
 	IsSchemaNotFoundError = isSchemaNotFoundError
+	SplitRows             = splitRows
+	BuildDescriptor       = buildDescriptor
+	EncodeRow             = encodeRow
+	BuildWhereClause      = buildWhereClause
+	TimeRangeClause       = timeRangeClause
+	ExportURIPattern      = exportURIPattern
+	DecodeRawScan         = decodeRawScan
+)
+
+type (
+	AppendStream  = appendStream
+	StreamFactory = streamFactory
+	StreamCache   = streamCache
+	StreamEntry   = streamEntry
 )
+
+func NewStreamCache(factory StreamFactory) *StreamCache { return newStreamCache(factory) }
+
+func (x *streamCache) Acquire(ctx context.Context, tableID types.BQTableID, schema bigquery.Schema) (*StreamEntry, error) {
+	return x.acquire(ctx, tableID, schema)
+}
+func (x *streamCache) Release(entry *StreamEntry) { x.release(entry) }
+func (x *streamCache) Invalidate(tableID types.BQTableID, entry *StreamEntry) {
+	x.invalidate(tableID, entry)
+}
+
+func (x *streamEntry) Stream() AppendStream { return x.stream }
+
+func RetryInsertForTest(ctx context.Context, maxRetries int, initialBackoff, maxBackoff time.Duration, insert func() error) error {
+	return insertRetry{maxRetries: maxRetries, initialBackoff: initialBackoff, maxBackoff: maxBackoff}.do(ctx, true, insert)
+}
@@ -0,0 +1,151 @@
+package bq
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// appendStream is a subset of managedwriter.ManagedStream used by Client
+type appendStream interface {
+	AppendRows(ctx context.Context, data [][]byte, opts ...managedwriter.AppendOption) (*managedwriter.AppendResult, error)
+	io.Closer
+}
+
+type streamFactory func(ctx context.Context, tableID types.BQTableID, dp *descriptorpb.DescriptorProto) (appendStream, error)
+
+// streamEntry is a managed stream opened for a specific schema of a table
+type streamEntry struct {
+	stream     appendStream
+	schemaKey  string
+	descriptor protoreflect.MessageDescriptor
+
+	// refs is number of inserts using the stream, and retired means the entry is no longer in the cache. The stream is closed when both are satisfied.
+	refs    int
+	retired bool
+}
+
+// streamCache keeps one managed stream per table and reuses it across inserts to avoid connection setup on every insert. The stream is replaced when an insert comes with a different schema, or invalidated when an append fails.
+type streamCache struct {
+	factory streamFactory
+	mutex   sync.Mutex
+	streams map[types.BQTableID]*streamEntry
+}
+
+func newStreamCache(factory streamFactory) *streamCache {
+	return &streamCache{
+		factory: factory,
+		streams: make(map[types.BQTableID]*streamEntry),
+	}
+}
+
+// acquire returns the stream of the table for the schema. The caller must call release after use.
+func (x *streamCache) acquire(ctx context.Context, tableID types.BQTableID, schema bigquery.Schema) (*streamEntry, error) {
+	key, err := schemaKey(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if entry, ok := x.streams[tableID]; ok {
+		if entry.schemaKey == key {
+			entry.refs++
+			return entry, nil
+		}
+		x.retire(tableID, entry)
+	}
+
+	descriptor, descriptorProto, err := buildDescriptor(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream lives beyond the insert, so it must not be bound to cancellation of the caller's context.
+	stream, err := x.factory(context.WithoutCancel(ctx), tableID, descriptorProto)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create managed stream", goerr.V("table", tableID))
+	}
+
+	entry := &streamEntry{
+		stream:     stream,
+		schemaKey:  key,
+		descriptor: descriptor,
+		refs:       1,
+	}
+	x.streams[tableID] = entry
+	return entry, nil
+}
+
+func (x *streamCache) release(entry *streamEntry) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	entry.refs--
+	if entry.retired && entry.refs == 0 {
+		safe.Close(entry.stream)
+	}
+}
+
+// invalidate drops the entry from the cache so that the next insert opens a new stream. It does nothing if the entry has been already replaced.
+func (x *streamCache) invalidate(tableID types.BQTableID, entry *streamEntry) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.streams[tableID] == entry {
+		x.retire(tableID, entry)
+	}
+}
+
+// retire must be called with the lock held
+func (x *streamCache) retire(tableID types.BQTableID, entry *streamEntry) {
+	delete(x.streams, tableID)
+	entry.retired = true
+	if entry.refs == 0 {
+		safe.Close(entry.stream)
+	}
+}
+
+func schemaKey(schema bigquery.Schema) (string, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to marshal schema")
+	}
+	h := sha256.Sum256(raw)
+	return hex.EncodeToString(h[:]), nil
+}
+
+func buildDescriptor(schema bigquery.Schema) (protoreflect.MessageDescriptor, *descriptorpb.DescriptorProto, error) {
+	convertedSchema, err := adapt.BQSchemaToStorageTableSchema(schema)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to convert schema")
+	}
+
+	descriptor, err := adapt.StorageSchemaToProto2Descriptor(convertedSchema, "root")
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to convert schema to descriptor")
+	}
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, nil, goerr.New("adapted descriptor is not a message descriptor")
+	}
+	descriptorProto, err := adapt.NormalizeDescriptor(messageDescriptor)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to normalize descriptor")
+	}
+
+	return messageDescriptor, descriptorProto, nil
+}
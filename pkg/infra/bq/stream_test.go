@@ -0,0 +1,203 @@
+package bq_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"github.com/m-mizutani/bqs"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/bq"
+	"github.com/m-mizutani/octovy/pkg/utils/testutil"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type fakeStream struct {
+	closed bool
+}
+
+func (x *fakeStream) AppendRows(ctx context.Context, data [][]byte, opts ...managedwriter.AppendOption) (*managedwriter.AppendResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (x *fakeStream) Close() error {
+	x.closed = true
+	return nil
+}
+
+type fakeStreamFactory struct {
+	created []*fakeStream
+	tables  []types.BQTableID
+}
+
+func (x *fakeStreamFactory) New(ctx context.Context, tableID types.BQTableID, dp *descriptorpb.DescriptorProto) (bq.AppendStream, error) {
+	s := &fakeStream{}
+	x.created = append(x.created, s)
+	x.tables = append(x.tables, tableID)
+	return s, nil
+}
+
+var (
+	schemaV1 = bigquery.Schema{
+		{Name: "id", Type: bigquery.StringFieldType},
+	}
+	schemaV2 = bigquery.Schema{
+		{Name: "id", Type: bigquery.StringFieldType},
+		{Name: "count", Type: bigquery.IntegerFieldType},
+	}
+)
+
+func TestStreamCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stream is reused for the same table and schema", func(t *testing.T) {
+		factory := &fakeStreamFactory{}
+		cache := bq.NewStreamCache(factory.New)
+
+		e1 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Release(e1)
+		e2 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Release(e2)
+
+		gt.A(t, factory.created).Length(1)
+		gt.V(t, e1).Equal(e2)
+		gt.False(t, factory.created[0].closed)
+	})
+
+	t.Run("each table has its own stream", func(t *testing.T) {
+		factory := &fakeStreamFactory{}
+		cache := bq.NewStreamCache(factory.New)
+
+		e1 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		e2 := gt.R1(cache.Acquire(ctx, "scans_packages", schemaV1)).NoError(t)
+		cache.Release(e1)
+		cache.Release(e2)
+
+		gt.A(t, factory.tables).Equal([]types.BQTableID{"scans", "scans_packages"})
+	})
+
+	t.Run("schema change replaces the stream", func(t *testing.T) {
+		factory := &fakeStreamFactory{}
+		cache := bq.NewStreamCache(factory.New)
+
+		e1 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Release(e1)
+		e2 := gt.R1(cache.Acquire(ctx, "scans", schemaV2)).NoError(t)
+		cache.Release(e2)
+
+		gt.A(t, factory.created).Length(2)
+		gt.True(t, factory.created[0].closed)
+		gt.False(t, factory.created[1].closed)
+	})
+
+	t.Run("replaced stream is closed after in-flight insert is released", func(t *testing.T) {
+		factory := &fakeStreamFactory{}
+		cache := bq.NewStreamCache(factory.New)
+
+		inFlight := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		e2 := gt.R1(cache.Acquire(ctx, "scans", schemaV2)).NoError(t)
+		gt.False(t, factory.created[0].closed)
+
+		cache.Release(inFlight)
+		gt.True(t, factory.created[0].closed)
+		cache.Release(e2)
+	})
+
+	t.Run("invalidated stream is not reused", func(t *testing.T) {
+		factory := &fakeStreamFactory{}
+		cache := bq.NewStreamCache(factory.New)
+
+		e1 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Invalidate("scans", e1)
+		cache.Release(e1)
+		gt.True(t, factory.created[0].closed)
+
+		e2 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Release(e2)
+		gt.A(t, factory.created).Length(2)
+
+		// Invalidating an old entry does not drop the current one
+		cache.Invalidate("scans", e1)
+		e3 := gt.R1(cache.Acquire(ctx, "scans", schemaV1)).NoError(t)
+		cache.Release(e3)
+		gt.V(t, e3).Equal(e2)
+	})
+}
+
+func loadBenchmarkRecord(b *testing.B) (bigquery.Schema, any) {
+	var scan model.Scan
+	data := gt.R1(os.ReadFile("testdata/data.json")).NoError(b)
+	gt.NoError(b, json.Unmarshal(data, &scan.Report))
+	scan.ID = types.NewScanID()
+	scan.Timestamp = time.Now()
+
+	schema := gt.R1(bqs.Infer(scan)).NoError(b)
+	return schema, &model.ScanRawRecord{Scan: scan, Timestamp: scan.Timestamp.UnixMicro()}
+}
+
+// BenchmarkBuildDescriptor measures the schema conversion that is skipped when a cached stream is reused.
+func BenchmarkBuildDescriptor(b *testing.B) {
+	schema, _ := loadBenchmarkRecord(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := bq.BuildDescriptor(schema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamCacheAcquire(b *testing.B) {
+	schema, _ := loadBenchmarkRecord(b)
+	factory := &fakeStreamFactory{}
+	cache := bq.NewStreamCache(factory.New)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entry, err := cache.Acquire(ctx, "scans", schema)
+		if err != nil {
+			b.Fatal(err)
+		}
+		cache.Release(entry)
+	}
+}
+
+func BenchmarkEncodeRow(b *testing.B) {
+	schema, record := loadBenchmarkRecord(b)
+	descriptor, _, err := bq.BuildDescriptor(schema)
+	gt.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := bq.EncodeRow(descriptor, record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkInsert measures end-to-end insert latency with a real BigQuery table. The first iteration opens the stream and following ones reuse it.
+func BenchmarkInsert(b *testing.B) {
+	projectID := testutil.GetEnvOrSkip(b, "TEST_BIGQUERY_PROJECT_ID")
+	datasetID := testutil.GetEnvOrSkip(b, "TEST_BIGQUERY_DATASET_ID")
+
+	ctx := context.Background()
+	tblName := types.BQTableID(time.Now().Format("bench_insert_20060102_150405"))
+	client := gt.R1(bq.New(ctx, types.GoogleProjectID(projectID), types.BQDatasetID(datasetID), tblName)).NoError(b)
+
+	schema, record := loadBenchmarkRecord(b)
+	gt.NoError(b, client.CreateTable(ctx, &bigquery.TableMetadata{Name: tblName.String(), Schema: schema}))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.Insert(ctx, schema, record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
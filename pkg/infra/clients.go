@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/infra/scanlock"
 	"github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/repository/scoped"
 )
 
 type Clients struct {
@@ -13,6 +15,8 @@ type Clients struct {
 	trivyClient    trivy.Client
 	bqClient       interfaces.BigQuery
 	scanRepository interfaces.ScanRepository
+	objectReader   interfaces.ObjectReader
+	scanLock       interfaces.ScanLock
 }
 
 type HTTPClient interface {
@@ -25,6 +29,7 @@ func New(options ...Option) *Clients {
 	client := &Clients{
 		httpClient:  http.DefaultClient,
 		trivyClient: trivy.New("trivy"),
+		scanLock:    scanlock.NewMemory(),
 	}
 
 	for _, opt := range options {
@@ -49,6 +54,12 @@ func (x *Clients) BigQuery() interfaces.BigQuery {
 func (x *Clients) ScanRepository() interfaces.ScanRepository {
 	return x.scanRepository
 }
+func (x *Clients) ObjectReader() interfaces.ObjectReader {
+	return x.objectReader
+}
+func (x *Clients) ScanLock() interfaces.ScanLock {
+	return x.scanLock
+}
 
 func WithGitHubApp(client interfaces.GitHubApp) Option {
 	return func(x *Clients) {
@@ -74,8 +85,22 @@ func WithBigQuery(client interfaces.BigQuery) Option {
 	}
 }
 
+// WithScanRepository sets the scan repository. It is wrapped to restrict every operation to the tenant of the context, if any, so that data of tenants are isolated regardless of the caller.
 func WithScanRepository(repo interfaces.ScanRepository) Option {
 	return func(x *Clients) {
-		x.scanRepository = repo
+		x.scanRepository = scoped.New(repo)
+	}
+}
+
+func WithObjectReader(reader interfaces.ObjectReader) Option {
+	return func(x *Clients) {
+		x.objectReader = reader
+	}
+}
+
+// WithScanLock sets the lock to exclude concurrent scans of the same commit. A lock in memory of the process is used by default, which does not exclude scans of other instances.
+func WithScanLock(lock interfaces.ScanLock) Option {
+	return func(x *Clients) {
+		x.scanLock = lock
 	}
 }
@@ -78,4 +78,16 @@ func (m *mockTrivyClient) Run(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (m *mockTrivyClient) DownloadDB(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockTrivyClient) Version(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (m *mockTrivyClient) CheckVersion(ctx context.Context) (string, error) {
+	return "", nil
+}
+
 var _ trivy.Client = (*mockTrivyClient)(nil)
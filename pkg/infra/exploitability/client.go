@@ -0,0 +1,348 @@
+// Package exploitability provides EPSS scores (https://www.first.org/epss/) and the CISA Known Exploited Vulnerabilities catalog (https://www.cisa.gov/known-exploited-vulnerabilities-catalog). Both are published as daily files, so the whole files are downloaded and looked up locally instead of calling an API per CVE.
+package exploitability
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+const (
+	DefaultEPSSURL = "https://epss.cyentia.com/epss_scores-current.csv.gz"
+	DefaultKEVURL  = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+	// Both data sources are updated daily
+	defaultTTL = 24 * time.Hour
+
+	epssCacheFile = "epss_scores.csv.gz"
+	kevCacheFile  = "known_exploited_vulnerabilities.json"
+)
+
+// Client looks up exploitability of CVEs. Data is loaded at the first lookup and reloaded after TTL. If reloading fails, the previous data is used and the reload is tried again at the next lookup.
+type Client struct {
+	httpClient *http.Client
+	epssURL    string
+	kevURL     string
+	cacheDir   string
+	ttl        time.Duration
+
+	mutex    sync.Mutex
+	loadedAt time.Time
+	epss     map[string]epssScore
+	kev      map[string]kevEntry
+}
+
+var _ interfaces.ExploitabilitySource = (*Client)(nil)
+
+type epssScore struct {
+	score      float64
+	percentile float64
+}
+
+type kevEntry struct {
+	dateAdded  string
+	dueDate    string
+	ransomware bool
+}
+
+type Option func(*Client)
+
+// WithCacheDir stores downloaded files in the directory and reuses them while they are newer than TTL, e.g. across restarts. Files are kept only in memory if not set.
+func WithCacheDir(dir string) Option {
+	return func(x *Client) {
+		x.cacheDir = dir
+	}
+}
+
+// WithTTL sets how long loaded data is used before reloading. Default is 24 hours.
+func WithTTL(ttl time.Duration) Option {
+	return func(x *Client) {
+		x.ttl = ttl
+	}
+}
+
+// WithURLs replaces URLs of the EPSS CSV file (gzip compressed or not) and the KEV JSON file, e.g. for a mirror in a closed network
+func WithURLs(epssURL, kevURL string) Option {
+	return func(x *Client) {
+		x.epssURL = epssURL
+		x.kevURL = kevURL
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Client) {
+		x.httpClient = client
+	}
+}
+
+func New(options ...Option) *Client {
+	client := &Client{
+		httpClient: http.DefaultClient,
+		epssURL:    DefaultEPSSURL,
+		kevURL:     DefaultKEVURL,
+		ttl:        defaultTTL,
+	}
+	for _, opt := range options {
+		opt(client)
+	}
+	return client
+}
+
+func (x *Client) Lookup(ctx context.Context, cveIDs []string) (map[string]*model.Exploitability, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if time.Since(x.loadedAt) >= x.ttl {
+		if err := x.load(ctx); err != nil {
+			if x.epss == nil {
+				return nil, err
+			}
+			logging.From(ctx).Warn("failed to reload exploitability data, previous data is used", "loaded_at", x.loadedAt, "error", err)
+		}
+	}
+
+	found := make(map[string]*model.Exploitability)
+	for _, id := range cveIDs {
+		score, hasScore := x.epss[id]
+		entry, known := x.kev[id]
+		if !hasScore && !known {
+			continue
+		}
+		found[id] = &model.Exploitability{
+			EPSSScore:      score.score,
+			EPSSPercentile: score.percentile,
+			KnownExploited: known,
+			KEVDateAdded:   entry.dateAdded,
+			KEVDueDate:     entry.dueDate,
+			KEVRansomware:  entry.ransomware,
+		}
+	}
+	return found, nil
+}
+
+// load replaces data only if both sources are loaded, so that scores and flags are always of the same generation
+func (x *Client) load(ctx context.Context) error {
+	var epss map[string]epssScore
+	if err := x.read(ctx, x.epssURL, epssCacheFile, func(r io.Reader) (err error) {
+		epss, err = parseEPSS(r)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	var kev map[string]kevEntry
+	if err := x.read(ctx, x.kevURL, kevCacheFile, func(r io.Reader) (err error) {
+		kev, err = parseKEV(r)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	x.epss, x.kev, x.loadedAt = epss, kev, time.Now()
+	logging.From(ctx).Info("loaded exploitability data", "epss_scores", len(epss), "kev_entries", len(kev))
+	return nil
+}
+
+// read parses the cached file if it is newer than TTL, and downloads the file otherwise. An expired cache is still used if the download fails. A cached file which cannot be parsed is removed, not to be used until TTL.
+func (x *Client) read(ctx context.Context, src, cacheFile string, parse func(r io.Reader) error) error {
+	if x.cacheDir == "" {
+		return x.download(ctx, src, parse)
+	}
+
+	path := filepath.Join(x.cacheDir, cacheFile)
+	stat, err := os.Stat(path)
+	cached := err == nil
+	if cached && time.Since(stat.ModTime()) < x.ttl {
+		return readCache(path, parse)
+	}
+
+	if err := x.downloadToCache(ctx, src, path); err != nil {
+		if !cached {
+			return err
+		}
+		logging.From(ctx).Warn("failed to download exploitability data, expired cache is used", "url", src, "path", path, "error", err)
+	}
+	return readCache(path, parse)
+}
+
+func (x *Client) get(ctx context.Context, src string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create request of exploitability data", goerr.V("url", src))
+	}
+	resp, err := x.httpClient.Do(req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to download exploitability data", goerr.V("url", src))
+	}
+	if resp.StatusCode != http.StatusOK {
+		safe.Close(resp.Body)
+		return nil, goerr.New("unexpected status code of exploitability data", goerr.V("url", src), goerr.V("code", resp.StatusCode))
+	}
+	return resp.Body, nil
+}
+
+func (x *Client) download(ctx context.Context, src string, parse func(r io.Reader) error) error {
+	body, err := x.get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer safe.Close(body)
+	return parse(body)
+}
+
+// downloadToCache writes the file to a temporary file and renames it, so that a broken download does not replace the cache
+func (x *Client) downloadToCache(ctx context.Context, src, path string) error {
+	body, err := x.get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer safe.Close(body)
+
+	if err := os.MkdirAll(x.cacheDir, 0750); err != nil {
+		return goerr.Wrap(err, "failed to create cache directory", goerr.V("dir", x.cacheDir))
+	}
+	tmp, err := os.CreateTemp(x.cacheDir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return goerr.Wrap(err, "failed to create cache file", goerr.V("dir", x.cacheDir))
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			safe.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		safe.Close(tmp)
+		return goerr.Wrap(err, "failed to download exploitability data", goerr.V("url", src))
+	}
+	if err := tmp.Close(); err != nil {
+		return goerr.Wrap(err, "failed to write cache file", goerr.V("path", tmp.Name()))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return goerr.Wrap(err, "failed to replace cache file", goerr.V("path", path))
+	}
+	renamed = true
+	return nil
+}
+
+func readCache(path string, parse func(r io.Reader) error) error {
+	fd, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return goerr.Wrap(err, "failed to open cache file", goerr.V("path", path))
+	}
+	defer safe.Close(fd)
+
+	if err := parse(fd); err != nil {
+		safe.Remove(path)
+		return goerr.Wrap(err, "failed to parse cache file", goerr.V("path", path))
+	}
+	return nil
+}
+
+// parseEPSS parses the EPSS CSV file. The first line is a comment of the model version and the score date, followed by a header "cve,epss,percentile".
+func parseEPSS(r io.Reader) (map[string]epssScore, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to decompress EPSS data")
+		}
+		defer safe.Close(gz)
+		br = bufio.NewReader(gz)
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read header of EPSS data")
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[name] = i
+	}
+	cveCol, ok1 := col["cve"]
+	scoreCol, ok2 := col["epss"]
+	pctCol, ok3 := col["percentile"]
+	if !ok1 || !ok2 || !ok3 {
+		return nil, goerr.New("unexpected header of EPSS data", goerr.V("header", header))
+	}
+
+	scores := make(map[string]epssScore)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to read EPSS data")
+		}
+		if len(record) <= max(cveCol, scoreCol, pctCol) {
+			continue
+		}
+		score, err := strconv.ParseFloat(record[scoreCol], 64)
+		if err != nil {
+			return nil, goerr.Wrap(err, "invalid EPSS score", goerr.V("cve", record[cveCol]))
+		}
+		pct, err := strconv.ParseFloat(record[pctCol], 64)
+		if err != nil {
+			return nil, goerr.Wrap(err, "invalid EPSS percentile", goerr.V("cve", record[cveCol]))
+		}
+		scores[record[cveCol]] = epssScore{score: score, percentile: pct}
+	}
+
+	if len(scores) == 0 {
+		return nil, goerr.New("no score in EPSS data")
+	}
+	return scores, nil
+}
+
+// kevCatalog is the part of the KEV JSON feed used by octovy
+type kevCatalog struct {
+	Vulnerabilities []struct {
+		CveID                      string `json:"cveID"`
+		DateAdded                  string `json:"dateAdded"`
+		DueDate                    string `json:"dueDate"`
+		KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+	} `json:"vulnerabilities"`
+}
+
+func parseKEV(r io.Reader) (map[string]kevEntry, error) {
+	var catalog kevCatalog
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode KEV catalog")
+	}
+	if len(catalog.Vulnerabilities) == 0 {
+		return nil, goerr.New("no vulnerability in KEV catalog")
+	}
+
+	entries := make(map[string]kevEntry, len(catalog.Vulnerabilities))
+	for _, v := range catalog.Vulnerabilities {
+		entries[v.CveID] = kevEntry{
+			dateAdded:  v.DateAdded,
+			dueDate:    v.DueDate,
+			ransomware: v.KnownRansomwareCampaignUse == "Known",
+		}
+	}
+	return entries, nil
+}
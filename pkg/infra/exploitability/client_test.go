@@ -0,0 +1,144 @@
+package exploitability_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra/exploitability"
+)
+
+const epssCSV = `#model_version:v2023.03.01,score_date:2025-01-01T00:00:00+0000
+cve,epss,percentile
+CVE-2021-44228,0.97565,0.99996
+CVE-2023-0001,0.00043,0.09251
+`
+
+const kevJSON = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "vulnerabilities": [
+    {"cveID": "CVE-2021-44228", "dateAdded": "2021-12-10", "dueDate": "2021-12-24", "knownRansomwareCampaignUse": "Known"},
+    {"cveID": "CVE-2020-0002", "dateAdded": "2022-01-10", "dueDate": "2022-07-10", "knownRansomwareCampaignUse": "Unknown"}
+  ]
+}`
+
+type feedServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	broken   atomic.Bool
+}
+
+func newFeedServer(t *testing.T) *feedServer {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(epssCSV))
+	gt.NoError(t, w.Close())
+
+	srv := &feedServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.requests.Add(1)
+		if srv.broken.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/epss.csv.gz":
+			_, _ = w.Write(gz.Bytes())
+		case "/kev.json":
+			_, _ = w.Write([]byte(kevJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("looks up EPSS scores and KEV entries", func(t *testing.T) {
+		srv := newFeedServer(t)
+		client := exploitability.New(exploitability.WithURLs(srv.URL+"/epss.csv.gz", srv.URL+"/kev.json"))
+
+		found := gt.R1(client.Lookup(ctx, []string{"CVE-2021-44228", "CVE-2023-0001", "CVE-2020-0002", "CVE-2099-9999"})).NoError(t)
+		gt.M(t, found).Length(3)
+		gt.V(t, found["CVE-2021-44228"]).Equal(&model.Exploitability{
+			EPSSScore:      0.97565,
+			EPSSPercentile: 0.99996,
+			KnownExploited: true,
+			KEVDateAdded:   "2021-12-10",
+			KEVDueDate:     "2021-12-24",
+			KEVRansomware:  true,
+		})
+		gt.V(t, found["CVE-2023-0001"]).Equal(&model.Exploitability{EPSSScore: 0.00043, EPSSPercentile: 0.09251})
+		gt.True(t, found["CVE-2020-0002"].KnownExploited)
+
+		// Data is loaded only once within TTL
+		gt.R1(client.Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+		gt.V(t, srv.requests.Load()).Equal(int32(2))
+	})
+
+	t.Run("previous data is used if reload fails", func(t *testing.T) {
+		srv := newFeedServer(t)
+		client := exploitability.New(
+			exploitability.WithURLs(srv.URL+"/epss.csv.gz", srv.URL+"/kev.json"),
+			exploitability.WithTTL(time.Nanosecond),
+		)
+		gt.R1(client.Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+
+		srv.broken.Store(true)
+		found := gt.R1(client.Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+		gt.True(t, found["CVE-2021-44228"].KnownExploited)
+	})
+
+	t.Run("fails without any data", func(t *testing.T) {
+		srv := newFeedServer(t)
+		srv.broken.Store(true)
+		client := exploitability.New(exploitability.WithURLs(srv.URL+"/epss.csv.gz", srv.URL+"/kev.json"))
+		_, err := client.Lookup(ctx, []string{"CVE-2021-44228"})
+		gt.Error(t, err)
+	})
+
+	t.Run("cache directory", func(t *testing.T) {
+		srv := newFeedServer(t)
+		dir := t.TempDir()
+		newClient := func(ttl time.Duration) *exploitability.Client {
+			return exploitability.New(
+				exploitability.WithURLs(srv.URL+"/epss.csv.gz", srv.URL+"/kev.json"),
+				exploitability.WithCacheDir(dir),
+				exploitability.WithTTL(ttl),
+			)
+		}
+
+		gt.R1(newClient(time.Hour).Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+		gt.V(t, srv.requests.Load()).Equal(int32(2))
+
+		// Another client, e.g. after restart, reuses cached files
+		found := gt.R1(newClient(time.Hour).Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+		gt.True(t, found["CVE-2021-44228"].KnownExploited)
+		gt.V(t, srv.requests.Load()).Equal(int32(2))
+
+		// Expired cache is still used if download fails
+		srv.broken.Store(true)
+		found = gt.R1(newClient(time.Nanosecond).Lookup(ctx, []string{"CVE-2021-44228"})).NoError(t)
+		gt.V(t, found["CVE-2021-44228"].EPSSScore).Equal(0.97565)
+		gt.V(t, srv.requests.Load()).Equal(int32(4))
+
+		// A broken cache file is removed
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, "known_exploited_vulnerabilities.json"), []byte("<html>"), 0600))
+		_, err := newClient(time.Hour).Lookup(ctx, []string{"CVE-2021-44228"})
+		gt.Error(t, err)
+		_, err = os.Stat(filepath.Join(dir, "known_exploited_vulnerabilities.json"))
+		gt.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,52 @@
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"path"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// Client stores objects in a Cloud Storage bucket under the prefix
+type Client struct {
+	service *storage.Service
+	bucket  string
+	prefix  string
+}
+
+var _ interfaces.ObjectStorage = (*Client)(nil)
+
+func New(ctx context.Context, bucket, prefix string, options ...option.ClientOption) (*Client, error) {
+	if bucket == "" {
+		return nil, goerr.New("bucket is required")
+	}
+
+	service, err := storage.NewService(ctx, options...)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create Cloud Storage client", goerr.V("bucket", bucket))
+	}
+
+	return &Client{
+		service: service,
+		bucket:  bucket,
+		prefix:  prefix,
+	}, nil
+}
+
+func (x *Client) Put(ctx context.Context, key string, data []byte) (string, error) {
+	name := path.Join(x.prefix, key)
+	obj := &storage.Object{
+		Name:        name,
+		ContentType: "application/json",
+	}
+
+	if _, err := x.service.Objects.Insert(x.bucket, obj).Media(bytes.NewReader(data)).Context(ctx).Do(); err != nil {
+		return "", goerr.Wrap(err, "failed to put object to Cloud Storage", goerr.V("bucket", x.bucket), goerr.V("name", name))
+	}
+
+	return "gs://" + x.bucket + "/" + name, nil
+}
@@ -0,0 +1,39 @@
+package gcs_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/infra/gcs"
+	"google.golang.org/api/option"
+)
+
+func TestPut(t *testing.T) {
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"bucket":"test-bucket"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client, err := gcs.New(ctx, "test-bucket", "octovy/overflow",
+		option.WithEndpoint(srv.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	gt.NoError(t, err)
+
+	uri, err := client.Put(ctx, "vulnerability/owner:repo/main/go.mod/CVE-2024-0001.json", []byte(`{"ID":"CVE-2024-0001"}`))
+	gt.NoError(t, err)
+	gt.V(t, uri).Equal("gs://test-bucket/octovy/overflow/vulnerability/owner:repo/main/go.mod/CVE-2024-0001.json")
+	// Object metadata and content are sent as a multipart body
+	gt.True(t, strings.Contains(gotBody, `"name":"octovy/overflow/vulnerability/owner:repo/main/go.mod/CVE-2024-0001.json"`))
+	gt.True(t, strings.Contains(gotBody, `{"ID":"CVE-2024-0001"}`))
+}
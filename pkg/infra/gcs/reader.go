@@ -0,0 +1,36 @@
+package gcs
+
+import (
+	"context"
+	"io"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// Reader reads objects of any Cloud Storage bucket, e.g. source code archives to be scanned
+type Reader struct {
+	service *storage.Service
+}
+
+var _ interfaces.ObjectReader = (*Reader)(nil)
+
+func NewReader(ctx context.Context, options ...option.ClientOption) (*Reader, error) {
+	service, err := storage.NewService(ctx, options...)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create Cloud Storage client")
+	}
+
+	return &Reader{service: service}, nil
+}
+
+func (x *Reader) Open(ctx context.Context, bucket, object string) (io.ReadCloser, error) {
+	resp, err := x.service.Objects.Get(bucket, object).Context(ctx).Download()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get object from Cloud Storage", goerr.V("bucket", bucket), goerr.V("object", object))
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,55 @@
+package gcs_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/infra/gcs"
+	"google.golang.org/api/option"
+)
+
+func TestReaderOpen(t *testing.T) {
+	var gotPath, gotAlt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAlt = r.URL.Query().Get("alt")
+		_, _ = w.Write([]byte("zip content"))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	reader, err := gcs.NewReader(ctx,
+		option.WithEndpoint(srv.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	gt.NoError(t, err)
+
+	r, err := reader.Open(ctx, "ci-artifacts", "octovy/app.zip")
+	gt.NoError(t, err)
+	defer r.Close()
+
+	body := gt.R1(io.ReadAll(r)).NoError(t)
+	gt.V(t, string(body)).Equal("zip content")
+	gt.V(t, gotPath).Equal("/storage/v1/b/ci-artifacts/o/octovy%2Fapp.zip")
+	gt.V(t, gotAlt).Equal("media")
+
+	t.Run("object not found", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		reader, err := gcs.NewReader(ctx,
+			option.WithEndpoint(srv.URL+"/storage/v1/"),
+			option.WithoutAuthentication(),
+		)
+		gt.NoError(t, err)
+
+		_, err = reader.Open(ctx, "ci-artifacts", "missing.zip")
+		gt.Error(t, err)
+	})
+}
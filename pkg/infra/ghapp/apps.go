@@ -0,0 +1,214 @@
+package ghapp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// Apps serves installations of several GitHub Apps, e.g. of different organizations or environments, with separate credentials. A request of an installation is sent by the app which the installation belongs to, found by asking each app in order and remembered.
+type Apps struct {
+	apps []*Client
+
+	mutex    sync.RWMutex
+	installs map[types.GitHubAppInstallID]*Client
+}
+
+var _ interfaces.GitHubApp = (*Apps)(nil)
+
+// NewApps returns a client of the apps. IDs of the apps must be unique.
+func NewApps(apps ...*Client) (*Apps, error) {
+	if len(apps) == 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "no GitHub App is given")
+	}
+	seen := make(map[types.GitHubAppID]bool)
+	for _, app := range apps {
+		if seen[app.appID] {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App ID is duplicated", goerr.V("appID", app.appID))
+		}
+		seen[app.appID] = true
+	}
+
+	return &Apps{
+		apps:     apps,
+		installs: make(map[types.GitHubAppInstallID]*Client),
+	}, nil
+}
+
+// app returns the app which the installation belongs to
+func (x *Apps) app(ctx context.Context, installID types.GitHubAppInstallID) (*Client, error) {
+	x.mutex.RLock()
+	app, ok := x.installs[installID]
+	x.mutex.RUnlock()
+	if ok {
+		return app, nil
+	}
+
+	for _, app := range x.apps {
+		found, err := app.hasInstallation(ctx, installID)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			x.remember(installID, app)
+			return app, nil
+		}
+	}
+
+	return nil, goerr.Wrap(types.ErrInvalidGitHubData, "installation not found in any GitHub App", goerr.V("installID", installID))
+}
+
+func (x *Apps) remember(installID types.GitHubAppInstallID, app *Client) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.installs[installID] = app
+}
+
+func (x *Apps) GetArchiveURL(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.GetArchiveURL(ctx, input)
+}
+
+// HTTPClient returns a client of the app of the installation. The app is looked up without a deadline if it is not known yet.
+func (x *Apps) HTTPClient(installID types.GitHubAppInstallID) (*http.Client, error) {
+	app, err := x.app(context.Background(), installID)
+	if err != nil {
+		return nil, err
+	}
+	return app.HTTPClient(installID)
+}
+
+func (x *Apps) InstallationToken(ctx context.Context, installID types.GitHubAppInstallID) (string, error) {
+	app, err := x.app(ctx, installID)
+	if err != nil {
+		return "", err
+	}
+	return app.InstallationToken(ctx, installID)
+}
+
+func (x *Apps) ListInstallationRepos(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
+	app, err := x.app(ctx, installID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListInstallationRepos(ctx, installID)
+}
+
+// GetInstallationIDForOwner returns the installation of the first app installed by the owner
+func (x *Apps) GetInstallationIDForOwner(ctx context.Context, owner string) (types.GitHubAppInstallID, error) {
+	var lastErr error
+	for _, app := range x.apps {
+		installID, err := app.GetInstallationIDForOwner(ctx, owner)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		x.remember(installID, app)
+		return installID, nil
+	}
+	return 0, goerr.Wrap(lastErr, "installation not found for owner in any GitHub App", goerr.V("owner", owner))
+}
+
+func (x *Apps) ListCodeScanningAlerts(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListCodeScanningAlerts(ctx, input)
+}
+
+func (x *Apps) ListPullRequestsWithCommit(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListPullRequestsWithCommit(ctx, input)
+}
+
+func (x *Apps) ListRepositoryTeams(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListRepositoryTeams(ctx, input)
+}
+
+func (x *Apps) AddLabelsToPullRequest(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return err
+	}
+	return app.AddLabelsToPullRequest(ctx, input)
+}
+
+func (x *Apps) CreateCheckRun(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return err
+	}
+	return app.CreateCheckRun(ctx, input)
+}
+
+func (x *Apps) ListPullRequestFiles(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListPullRequestFiles(ctx, input)
+}
+
+func (x *Apps) ListPullRequests(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.ListPullRequests(ctx, input)
+}
+
+func (x *Apps) CreatePullRequest(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error) {
+	app, err := x.app(ctx, input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	return app.CreatePullRequest(ctx, input)
+}
+
+// RateLimit returns the rate limit of the installation observed by any app
+func (x *Apps) RateLimit(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+	for _, app := range x.apps {
+		if rl := app.RateLimit(installID); rl != nil {
+			return rl
+		}
+	}
+	return nil
+}
+
+// RateLimits returns rate limits of installations of all apps
+func (x *Apps) RateLimits() []*model.GitHubRateLimit {
+	limits := []*model.GitHubRateLimit{}
+	for _, app := range x.apps {
+		limits = append(limits, app.RateLimits()...)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].InstallID < limits[j].InstallID })
+	return limits
+}
+
+// CheckCredential returns an error if any of the apps can not be authenticated
+func (x *Apps) CheckCredential(ctx context.Context) error {
+	for _, app := range x.apps {
+		if err := app.CheckCredential(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package ghapp_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
+)
+
+// redirectTransport sends requests to GitHub API to the test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (x *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = x.target.Scheme
+	req.URL.Host = x.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newPrivateKey(t *testing.T) types.GitHubAppPrivateKey {
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	return types.GitHubAppPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+// jwtIssuer returns the app ID in the JWT of the request, without verifying it
+func jwtIssuer(r *http.Request) string {
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return ""
+	}
+	return claims.Issuer
+}
+
+func TestApps(t *testing.T) {
+	// Installation 100 belongs to app 1, and 200 to app 2
+	owners := map[string]string{"100": "1", "200": "2"}
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var installID string
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			lookups.Add(1)
+			installID = strings.TrimPrefix(r.URL.Path, "/app/installations/")
+			if owners[installID] != jwtIssuer(r) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"id":%s}`, installID)
+
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/access_tokens"):
+			installID = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/app/installations/"), "/access_tokens")
+			if owners[installID] != jwtIssuer(r) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"token":"token-of-app-%s","expires_at":"2099-01-01T00:00:00Z"}`, jwtIssuer(r))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	target := gt.R1(url.Parse(srv.URL)).NoError(t)
+	transport := ghapp.WithTransport(&redirectTransport{target: target})
+	app1 := gt.R1(ghapp.New(1, newPrivateKey(t), transport)).NoError(t)
+	app2 := gt.R1(ghapp.New(2, newPrivateKey(t), transport)).NoError(t)
+	apps := gt.R1(ghapp.NewApps(app1, app2)).NoError(t)
+
+	t.Run("request is sent by the app of the installation", func(t *testing.T) {
+		ctx := context.Background()
+		gt.V(t, gt.R1(apps.InstallationToken(ctx, 100)).NoError(t)).Equal("token-of-app-1")
+		gt.V(t, gt.R1(apps.InstallationToken(ctx, 200)).NoError(t)).Equal("token-of-app-2")
+	})
+
+	t.Run("app of installation is remembered", func(t *testing.T) {
+		before := lookups.Load()
+		gt.V(t, gt.R1(apps.InstallationToken(context.Background(), 200)).NoError(t)).Equal("token-of-app-2")
+		gt.V(t, lookups.Load()).Equal(before)
+	})
+
+	t.Run("installation of no app fails", func(t *testing.T) {
+		_, err := apps.InstallationToken(context.Background(), 300)
+		gt.True(t, errors.Is(err, types.ErrInvalidGitHubData))
+	})
+
+	t.Run("duplicated app ID is rejected", func(t *testing.T) {
+		_, err := ghapp.NewApps(app1, app1)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v53/github"
@@ -17,13 +18,24 @@ import (
 )
 
 type Client struct {
-	appID types.GitHubAppID
-	pem   types.GitHubAppPrivateKey
+	appID      types.GitHubAppID
+	pem        types.GitHubAppPrivateKey
+	rateLimits *rateLimitTracker
+	transport  http.RoundTripper
 }
 
 var _ interfaces.GitHubApp = (*Client)(nil)
 
-func New(appID types.GitHubAppID, pem types.GitHubAppPrivateKey) (*Client, error) {
+type Option func(*Client)
+
+// WithTransport sets the base transport of GitHub API requests, e.g. to route them through a proxy. http.DefaultTransport is used if not set.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(x *Client) {
+		x.transport = transport
+	}
+}
+
+func New(appID types.GitHubAppID, pem types.GitHubAppPrivateKey, options ...Option) (*Client, error) {
 	if appID == 0 {
 		return nil, goerr.Wrap(types.ErrInvalidOption, "appID is empty")
 	}
@@ -32,8 +44,13 @@ func New(appID types.GitHubAppID, pem types.GitHubAppPrivateKey) (*Client, error
 	}
 
 	client := &Client{
-		appID: appID,
-		pem:   pem,
+		appID:      appID,
+		pem:        pem,
+		rateLimits: newRateLimitTracker(),
+		transport:  http.DefaultTransport,
+	}
+	for _, opt := range options {
+		opt(client)
 	}
 
 	return client, nil
@@ -48,17 +65,30 @@ func (x *Client) buildGithubClient(installID types.GitHubAppInstallID) (*github.
 }
 
 func (x *Client) buildGithubHTTPClient(installID types.GitHubAppInstallID) (*http.Client, error) {
-	tr := http.DefaultTransport
-	itr, err := ghinstallation.New(tr, int64(x.appID), int64(installID), []byte(x.pem))
+	itr, err := ghinstallation.New(x.transport, int64(x.appID), int64(installID), []byte(x.pem))
 
 	if err != nil {
 		return nil, goerr.Wrap(err, "Failed to create github client")
 	}
 
-	client := &http.Client{Transport: itr}
+	client := &http.Client{Transport: &rateLimitTransport{
+		base:      itr,
+		installID: installID,
+		tracker:   x.rateLimits,
+	}}
 	return client, nil
 }
 
+// RateLimit returns the latest rate limit of the installation observed in API responses, or nil if no response has been observed yet.
+func (x *Client) RateLimit(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+	return x.rateLimits.get(installID)
+}
+
+// RateLimits returns the latest rate limits of all installations observed in API responses.
+func (x *Client) RateLimits() []*model.GitHubRateLimit {
+	return x.rateLimits.list()
+}
+
 func (x *Client) GetArchiveURL(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
 	logging.From(ctx).Info("Sending GetArchiveLink request",
 		slog.Any("appID", x.appID),
@@ -95,6 +125,19 @@ func (x *Client) HTTPClient(installID types.GitHubAppInstallID) (*http.Client, e
 	return x.buildGithubHTTPClient(installID)
 }
 
+func (x *Client) InstallationToken(ctx context.Context, installID types.GitHubAppInstallID) (string, error) {
+	itr, err := ghinstallation.New(x.transport, int64(x.appID), int64(installID), []byte(x.pem))
+	if err != nil {
+		return "", goerr.Wrap(err, "Failed to create github client")
+	}
+
+	token, err := itr.Token(ctx)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to get installation token", goerr.V("installID", installID))
+	}
+	return token, nil
+}
+
 func (x *Client) ListInstallationRepos(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
 	client, err := x.buildGithubClient(installID)
 	if err != nil {
@@ -117,6 +160,7 @@ func (x *Client) ListInstallationRepos(ctx context.Context, installID types.GitH
 				DefaultBranch: repo.GetDefaultBranch(),
 				Archived:      repo.GetArchived(),
 				Disabled:      repo.GetDisabled(),
+				Topics:        repo.Topics,
 			})
 		}
 
@@ -135,14 +179,48 @@ func (x *Client) ListInstallationRepos(ctx context.Context, installID types.GitH
 }
 
 func (x *Client) buildAppClient() (*github.Client, error) {
-	tr := http.DefaultTransport
-	itr, err := ghinstallation.NewAppsTransport(tr, int64(x.appID), []byte(x.pem))
+	itr, err := ghinstallation.NewAppsTransport(x.transport, int64(x.appID), []byte(x.pem))
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to create app transport")
 	}
 	return github.NewClient(&http.Client{Transport: itr}), nil
 }
 
+// AppID returns the ID of the app
+func (x *Client) AppID() types.GitHubAppID {
+	return x.appID
+}
+
+// hasInstallation returns true if the installation belongs to the app. An installation of another app is not found by the app.
+func (x *Client) hasInstallation(ctx context.Context, installID types.GitHubAppInstallID) (bool, error) {
+	client, err := x.buildAppClient()
+	if err != nil {
+		return false, err
+	}
+
+	// https://docs.github.com/en/rest/apps/apps#get-an-installation-for-the-authenticated-app
+	_, resp, err := client.Apps.GetInstallation(ctx, int64(installID))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, goerr.Wrap(err, "failed to get installation", goerr.V("appID", x.appID), goerr.V("installID", installID))
+	}
+	return true, nil
+}
+
+// CheckCredential gets the app itself, which requires a JWT signed by the private key
+func (x *Client) CheckCredential(ctx context.Context) error {
+	client, err := x.buildAppClient()
+	if err != nil {
+		return err
+	}
+	if _, _, err := client.Apps.Get(ctx, ""); err != nil {
+		return goerr.Wrap(err, "failed to get GitHub App", goerr.V("appID", x.appID))
+	}
+	return nil
+}
+
 func (x *Client) GetInstallationIDForOwner(ctx context.Context, owner string) (types.GitHubAppInstallID, error) {
 	client, err := x.buildAppClient()
 	if err != nil {
@@ -188,3 +266,356 @@ func (x *Client) GetInstallationIDForOwner(ctx context.Context, owner string) (t
 		goerr.V("owner", owner),
 	)
 }
+
+func (x *Client) ListCodeScanningAlerts(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.AlertListOptions{
+		State:       input.State,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if input.Branch != "" {
+		opts.Ref = "refs/heads/" + input.Branch
+	}
+
+	// https://docs.github.com/en/rest/code-scanning/code-scanning#list-code-scanning-alerts-for-a-repository
+	var alerts []*model.CodeScanningAlert
+	for {
+		result, resp, err := client.CodeScanning.ListAlertsForRepo(ctx, input.Owner, input.Repo, opts)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list code scanning alerts",
+				goerr.V("owner", input.Owner),
+				goerr.V("repo", input.Repo),
+			)
+		}
+
+		for _, alert := range result {
+			ruleID := alert.GetRuleID()
+			if ruleID == "" {
+				ruleID = alert.GetRule().GetID()
+			}
+			alerts = append(alerts, &model.CodeScanningAlert{
+				Number:           alert.GetNumber(),
+				RuleID:           ruleID,
+				State:            alert.GetState(),
+				DismissedReason:  alert.GetDismissedReason(),
+				DismissedComment: alert.GetDismissedComment(),
+				DismissedBy:      alert.GetDismissedBy().GetLogin(),
+				DismissedAt:      alert.GetDismissedAt().Time,
+				Path:             alert.GetMostRecentInstance().GetLocation().GetPath(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+
+	logging.From(ctx).Info("Listed code scanning alerts",
+		slog.String("owner", input.Owner),
+		slog.String("repo", input.Repo),
+		slog.String("state", input.State),
+		slog.Int("count", len(alerts)),
+	)
+
+	return alerts, nil
+}
+
+func (x *Client) ListPullRequestsWithCommit(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+
+	// https://docs.github.com/en/rest/commits/commits#list-pull-requests-associated-with-a-commit
+	prs, _, err := client.PullRequests.ListPullRequestsWithCommit(ctx, input.Owner, input.Repo, input.CommitID, &github.PullRequestListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list pull requests associated with commit",
+			goerr.V("owner", input.Owner),
+			goerr.V("repo", input.Repo),
+			goerr.V("commit", input.CommitID),
+		)
+	}
+
+	var result []*model.MergedPullRequest
+	for _, pr := range prs {
+		result = append(result, &model.MergedPullRequest{
+			Number:   pr.GetNumber(),
+			Title:    pr.GetTitle(),
+			URL:      pr.GetHTMLURL(),
+			Author:   pr.GetUser().GetLogin(),
+			MergedAt: pr.GetMergedAt().Time,
+		})
+	}
+
+	return result, nil
+}
+
+func (x *Client) ListRepositoryTeams(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []*model.GitHubTeam
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		// https://docs.github.com/en/rest/repos/repos#list-repository-teams
+		result, resp, err := client.Repositories.ListTeams(ctx, input.Owner, input.Repo, opts)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list repository teams",
+				goerr.V("owner", input.Owner),
+				goerr.V("repo", input.Repo),
+			)
+		}
+
+		for _, team := range result {
+			teams = append(teams, &model.GitHubTeam{
+				Slug:       team.GetSlug(),
+				Permission: team.GetPermission(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return teams, nil
+}
+
+func (x *Client) ListPullRequestFiles(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		// https://docs.github.com/en/rest/pulls/pulls#list-pull-requests-files
+		result, resp, err := client.PullRequests.ListFiles(ctx, input.Owner, input.Repo, input.Number, opts)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list pull request files",
+				goerr.V("owner", input.Owner),
+				goerr.V("repo", input.Repo),
+				goerr.V("number", input.Number),
+			)
+		}
+
+		for _, file := range result {
+			files = append(files, file.GetFilename())
+			if prev := file.GetPreviousFilename(); prev != "" {
+				files = append(files, prev)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+func (x *Client) AddLabelsToPullRequest(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return err
+	}
+
+	// Labels of a pull request are managed via the issues API
+	// https://docs.github.com/en/rest/issues/labels#add-labels-to-an-issue
+	if _, _, err := client.Issues.AddLabelsToIssue(ctx, input.Owner, input.Repo, input.Number, input.Labels); err != nil {
+		return goerr.Wrap(err, "failed to add labels to pull request",
+			goerr.V("owner", input.Owner),
+			goerr.V("repo", input.Repo),
+			goerr.V("number", input.Number),
+			goerr.V("labels", input.Labels),
+		)
+	}
+
+	return nil
+}
+
+func (x *Client) CreateCheckRun(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return err
+	}
+
+	annotations := make([]*github.CheckRunAnnotation, len(input.Annotations))
+	for i, a := range input.Annotations {
+		annotations[i] = &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.StartLine),
+			EndLine:         github.Int(a.EndLine),
+			AnnotationLevel: github.String(string(a.Level)),
+			Title:           github.String(a.Title),
+			Message:         github.String(a.Message),
+		}
+	}
+
+	// https://docs.github.com/en/rest/checks/runs#create-a-check-run
+	if _, _, err := client.Checks.CreateCheckRun(ctx, input.Owner, input.Repo, github.CreateCheckRunOptions{
+		Name:       input.Name,
+		HeadSHA:    input.HeadSHA,
+		Status:     github.String("completed"),
+		Conclusion: github.String(input.Conclusion),
+		Output: &github.CheckRunOutput{
+			Title:       github.String(input.Title),
+			Summary:     github.String(input.Summary),
+			Annotations: annotations,
+		},
+	}); err != nil {
+		return goerr.Wrap(err, "failed to create check run",
+			goerr.V("owner", input.Owner),
+			goerr.V("repo", input.Repo),
+			goerr.V("head_sha", input.HeadSHA),
+			goerr.V("name", input.Name),
+		)
+	}
+
+	return nil
+}
+
+func (x *Client) ListPullRequests(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.PullRequestListOptions{
+		State:       input.State,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if input.Head != "" {
+		// The head filter requires the owner of the branch
+		opts.Head = input.Owner + ":" + input.Head
+	}
+
+	var result []*model.GitHubPullRequestRef
+	for {
+		// https://docs.github.com/en/rest/pulls/pulls#list-pull-requests
+		prs, resp, err := client.PullRequests.List(ctx, input.Owner, input.Repo, opts)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list pull requests",
+				goerr.V("owner", input.Owner),
+				goerr.V("repo", input.Repo),
+				goerr.V("state", input.State),
+				goerr.V("head", input.Head),
+			)
+		}
+
+		for _, pr := range prs {
+			result = append(result, &model.GitHubPullRequestRef{
+				Number: pr.GetNumber(),
+				URL:    pr.GetHTMLURL(),
+				Branch: pr.GetHead().GetRef(),
+				State:  pr.GetState(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+func (x *Client) CreatePullRequest(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error) {
+	client, err := x.buildGithubClient(input.InstallID)
+	if err != nil {
+		return nil, err
+	}
+	errValues := []goerr.Option{
+		goerr.V("owner", input.Owner),
+		goerr.V("repo", input.Repo),
+		goerr.V("branch", input.Branch),
+	}
+
+	// The commit is created with the Git database API, so that files are committed without cloning the repository
+	// https://docs.github.com/en/rest/git/commits#get-a-commit-object
+	base, _, err := client.Git.GetCommit(ctx, input.Owner, input.Repo, input.BaseCommitID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get base commit", append(errValues, goerr.V("base_commit", input.BaseCommitID))...)
+	}
+
+	paths := make([]string, 0, len(input.Files))
+	for p := range input.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	entries := make([]*github.TreeEntry, len(paths))
+	for i, p := range paths {
+		entries[i] = &github.TreeEntry{
+			Path:    github.String(p),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(string(input.Files[p])),
+		}
+	}
+
+	// https://docs.github.com/en/rest/git/trees#create-a-tree
+	tree, _, err := client.Git.CreateTree(ctx, input.Owner, input.Repo, base.GetTree().GetSHA(), entries)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create tree", errValues...)
+	}
+
+	// https://docs.github.com/en/rest/git/commits#create-a-commit
+	commit, _, err := client.Git.CreateCommit(ctx, input.Owner, input.Repo, &github.Commit{
+		Message: github.String(input.Message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: base.SHA}},
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create commit", errValues...)
+	}
+
+	// https://docs.github.com/en/rest/git/refs#create-a-reference
+	if _, _, err := client.Git.CreateRef(ctx, input.Owner, input.Repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + input.Branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}); err != nil {
+		return nil, goerr.Wrap(err, "failed to create branch", errValues...)
+	}
+
+	// https://docs.github.com/en/rest/pulls/pulls#create-a-pull-request
+	pr, _, err := client.PullRequests.Create(ctx, input.Owner, input.Repo, &github.NewPullRequest{
+		Title: github.String(input.Title),
+		Head:  github.String(input.Branch),
+		Base:  github.String(input.BaseBranch),
+		Body:  github.String(input.Body),
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create pull request", errValues...)
+	}
+
+	created := &model.GitHubPullRequestRef{
+		Number: pr.GetNumber(),
+		URL:    pr.GetHTMLURL(),
+		Branch: input.Branch,
+		State:  pr.GetState(),
+	}
+
+	if len(input.Labels) > 0 {
+		// https://docs.github.com/en/rest/issues/labels#add-labels-to-an-issue
+		if _, _, err := client.Issues.AddLabelsToIssue(ctx, input.Owner, input.Repo, created.Number, input.Labels); err != nil {
+			return created, goerr.Wrap(err, "failed to add labels to pull request", append(errValues, goerr.V("number", created.Number))...)
+		}
+	}
+
+	return created, nil
+}
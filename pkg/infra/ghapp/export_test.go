@@ -0,0 +1,12 @@
+package ghapp
+
+import (
+	"net/http"
+
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// NewRateLimitTransportForTest returns a transport reporting to the rate limit tracker of the client
+func (x *Client) NewRateLimitTransportForTest(base http.RoundTripper, installID types.GitHubAppInstallID) http.RoundTripper {
+	return &rateLimitTransport{base: base, installID: installID, tracker: x.rateLimits}
+}
@@ -0,0 +1,114 @@
+package ghapp
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// lowRateLimitRatio is the ratio of remaining quota to the limit below which the quota is logged as a warning
+const lowRateLimitRatio = 0.1
+
+// rateLimitTracker keeps the latest rate limit of each installation. The transport of every installation client reports to it, so the status is shared across clients built for each request.
+type rateLimitTracker struct {
+	mutex  sync.RWMutex
+	limits map[types.GitHubAppInstallID]*model.GitHubRateLimit
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{
+		limits: make(map[types.GitHubAppInstallID]*model.GitHubRateLimit),
+	}
+}
+
+// observe updates the rate limit of the installation from the response headers. Responses without the headers, e.g. of archive downloads, and of resources other than the REST API core are ignored.
+func (x *rateLimitTracker) observe(installID types.GitHubAppInstallID, header http.Header) {
+	if resource := header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" {
+		return
+	}
+
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	rl := &model.GitHubRateLimit{
+		InstallID:  installID,
+		Limit:      limit,
+		Remaining:  remaining,
+		Reset:      time.Unix(reset, 0),
+		ObservedAt: time.Now(),
+	}
+
+	x.mutex.Lock()
+	prev := x.limits[installID]
+	x.limits[installID] = rl
+	x.mutex.Unlock()
+
+	// Warn once when the quota of a window becomes low, not on every response after that
+	if isLowRateLimit(rl) && (prev == nil || !isLowRateLimit(prev) || !prev.Reset.Equal(rl.Reset)) {
+		logging.Default().Warn("GitHub API rate limit is low", slog.Any("rate_limit", rl))
+	} else {
+		logging.Default().Debug("GitHub API rate limit", slog.Any("rate_limit", rl))
+	}
+}
+
+func isLowRateLimit(rl *model.GitHubRateLimit) bool {
+	return float64(rl.Remaining) < float64(rl.Limit)*lowRateLimitRatio
+}
+
+func (x *rateLimitTracker) get(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	rl, ok := x.limits[installID]
+	if !ok {
+		return nil
+	}
+	copied := *rl
+	return &copied
+}
+
+func (x *rateLimitTracker) list() []*model.GitHubRateLimit {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	limits := make([]*model.GitHubRateLimit, 0, len(x.limits))
+	for _, rl := range x.limits {
+		copied := *rl
+		limits = append(limits, &copied)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].InstallID < limits[j].InstallID })
+	return limits
+}
+
+// rateLimitTransport reports rate limit headers of every response of an installation to the tracker
+type rateLimitTransport struct {
+	base      http.RoundTripper
+	installID types.GitHubAppInstallID
+	tracker   *rateLimitTracker
+}
+
+func (x *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := x.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	x.tracker.observe(x.installID, resp.Header)
+	return resp, nil
+}
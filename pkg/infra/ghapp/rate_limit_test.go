@@ -0,0 +1,78 @@
+package ghapp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
+)
+
+func TestRateLimitTracking(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+
+	var headers http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client, err := ghapp.New(types.GitHubAppID(12345), types.GitHubAppPrivateKey("test-key"))
+	gt.NoError(t, err)
+
+	httpClient := &http.Client{
+		Transport: client.NewRateLimitTransportForTest(http.DefaultTransport, 100),
+	}
+	get := func(h http.Header) {
+		headers = h
+		resp, err := httpClient.Get(ts.URL)
+		gt.NoError(t, err)
+		gt.NoError(t, resp.Body.Close())
+	}
+	rateLimitHeader := func(resource string, remaining int) http.Header {
+		h := http.Header{}
+		h.Set("X-RateLimit-Limit", "5000")
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		h.Set("X-RateLimit-Resource", resource)
+		return h
+	}
+
+	t.Run("not observed before any response", func(t *testing.T) {
+		gt.V(t, client.RateLimit(100)).Nil()
+		gt.A(t, client.RateLimits()).Length(0)
+	})
+
+	t.Run("response without headers is ignored", func(t *testing.T) {
+		get(http.Header{})
+		gt.V(t, client.RateLimit(100)).Nil()
+	})
+
+	t.Run("core rate limit is tracked", func(t *testing.T) {
+		get(rateLimitHeader("core", 4321))
+
+		rl := client.RateLimit(100)
+		gt.V(t, rl).NotNil()
+		gt.V(t, rl.InstallID).Equal(types.GitHubAppInstallID(100))
+		gt.V(t, rl.Limit).Equal(5000)
+		gt.V(t, rl.Remaining).Equal(4321)
+		gt.True(t, rl.Reset.Equal(reset))
+		gt.A(t, client.RateLimits()).Length(1)
+	})
+
+	t.Run("other resources do not overwrite core", func(t *testing.T) {
+		get(rateLimitHeader("search", 1))
+		gt.V(t, client.RateLimit(100).Remaining).Equal(4321)
+	})
+
+	t.Run("other installations are tracked separately", func(t *testing.T) {
+		gt.V(t, client.RateLimit(200)).Nil()
+	})
+}
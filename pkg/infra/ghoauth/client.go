@@ -0,0 +1,115 @@
+package ghoauth
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"golang.org/x/oauth2"
+	githubEndpoint "golang.org/x/oauth2/github"
+)
+
+// Client signs in users with a GitHub OAuth App. The access token of a user is used only to read the user's organizations and teams, and it is not kept after sign-in.
+type Client struct {
+	oauth  *oauth2.Config
+	apiURL *url.URL
+}
+
+var _ interfaces.GitHubOAuth = (*Client)(nil)
+
+type Option func(*Client)
+
+// WithEndpoint replaces URLs of GitHub, e.g. for GitHub Enterprise Server. apiURL must end with a slash.
+func WithEndpoint(authURL, tokenURL string, apiURL *url.URL) Option {
+	return func(x *Client) {
+		x.oauth.Endpoint = oauth2.Endpoint{AuthURL: authURL, TokenURL: tokenURL}
+		x.apiURL = apiURL
+	}
+}
+
+// New creates a client of the OAuth App. redirectURL is the callback URL registered to the app.
+func New(clientID, clientSecret, redirectURL string, options ...Option) (*Client, error) {
+	if clientID == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "clientID is empty")
+	}
+	if clientSecret == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "clientSecret is empty")
+	}
+	if redirectURL == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "redirectURL is empty")
+	}
+
+	client := &Client{
+		oauth: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     githubEndpoint.Endpoint,
+			// read:org is required to list private memberships of organizations and teams
+			Scopes: []string{"read:org"},
+		},
+	}
+	for _, opt := range options {
+		opt(client)
+	}
+
+	return client, nil
+}
+
+func (x *Client) AuthCodeURL(state string) string {
+	return x.oauth.AuthCodeURL(state)
+}
+
+func (x *Client) Exchange(ctx context.Context, code string) (*model.GitHubOAuthUser, error) {
+	token, err := x.oauth.Exchange(ctx, code)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrUnauthorized, "failed to exchange authorization code", goerr.V("error", err.Error()))
+	}
+
+	client := github.NewClient(x.oauth.Client(ctx, token))
+	if x.apiURL != nil {
+		client.BaseURL = x.apiURL
+	}
+
+	ghUser, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get GitHub user")
+	}
+	user := &model.GitHubOAuthUser{Login: ghUser.GetLogin()}
+
+	orgOpt := &github.ListOptions{PerPage: 100}
+	for {
+		orgs, resp, err := client.Organizations.List(ctx, "", orgOpt)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list organizations of GitHub user", goerr.V("login", user.Login))
+		}
+		for _, org := range orgs {
+			user.Orgs = append(user.Orgs, org.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		orgOpt.Page = resp.NextPage
+	}
+
+	teamOpt := &github.ListOptions{PerPage: 100}
+	for {
+		teams, resp, err := client.Teams.ListUserTeams(ctx, teamOpt)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list teams of GitHub user", goerr.V("login", user.Login))
+		}
+		for _, team := range teams {
+			user.Teams = append(user.Teams, team.GetOrganization().GetLogin()+"/"+team.GetSlug())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		teamOpt.Page = resp.NextPage
+	}
+
+	return user, nil
+}
@@ -0,0 +1,93 @@
+package ghoauth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/ghoauth"
+)
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		if r.PostForm.Get("code") != "valid-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"bad_verification_code"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"user-token","token_type":"bearer","scope":"read:org"}`))
+	})
+	requireToken := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			gt.V(t, r.Header.Get("Authorization")).Equal("Bearer user-token")
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("GET /api/user", requireToken(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"login":"alice"}`))
+	}))
+	mux.HandleFunc("GET /api/user/orgs", requireToken(func(w http.ResponseWriter, r *http.Request) {
+		// Organizations are returned in two pages
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"login":"other"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/user/orgs?page=2>; rel="next"`, ts.URL))
+		_, _ = w.Write([]byte(`[{"login":"myorg"}]`))
+	}))
+	mux.HandleFunc("GET /api/user/teams", requireToken(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"slug":"security","organization":{"login":"myorg"}}]`))
+	}))
+	ts = httptest.NewServer(mux)
+	defer ts.Close()
+
+	apiURL, err := url.Parse(ts.URL + "/api/")
+	gt.NoError(t, err)
+	client, err := ghoauth.New("client-id", "client-secret", "https://octovy.example.com/auth/callback",
+		ghoauth.WithEndpoint(ts.URL+"/login/oauth/authorize", ts.URL+"/login/oauth/access_token", apiURL),
+	)
+	gt.NoError(t, err)
+
+	t.Run("auth code URL has state and scope", func(t *testing.T) {
+		authURL, err := url.Parse(client.AuthCodeURL("state-1"))
+		gt.NoError(t, err)
+		gt.V(t, authURL.Path).Equal("/login/oauth/authorize")
+		gt.V(t, authURL.Query().Get("state")).Equal("state-1")
+		gt.V(t, authURL.Query().Get("client_id")).Equal("client-id")
+		gt.V(t, authURL.Query().Get("scope")).Equal("read:org")
+		gt.V(t, authURL.Query().Get("redirect_uri")).Equal("https://octovy.example.com/auth/callback")
+	})
+
+	t.Run("exchange returns user with organizations and teams", func(t *testing.T) {
+		user, err := client.Exchange(context.Background(), "valid-code")
+		gt.NoError(t, err)
+		gt.V(t, user.Login).Equal("alice")
+		gt.A(t, user.Orgs).Equal([]string{"myorg", "other"})
+		gt.A(t, user.Teams).Equal([]string{"myorg/security"})
+	})
+
+	t.Run("invalid code is unauthorized", func(t *testing.T) {
+		_, err := client.Exchange(context.Background(), "invalid-code")
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrUnauthorized))
+	})
+
+	t.Run("options are required", func(t *testing.T) {
+		_, err := ghoauth.New("", "secret", "https://octovy.example.com/auth/callback")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+		_, err = ghoauth.New("id", "", "https://octovy.example.com/auth/callback")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+		_, err = ghoauth.New("id", "secret", "")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,92 @@
+package gitclone_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/gitclone"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	gt.NoError(t, err).Required()
+	return strings.TrimSpace(string(out))
+}
+
+func TestFetch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// A local repository served as <base>/<owner>/<repo>.git
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "test-owner", "test-repo.git")
+	gt.NoError(t, os.MkdirAll(repoDir, 0755))
+	git(t, repoDir, "init", "--quiet")
+	git(t, repoDir, "config", "uploadpack.allowAnySHA1InWant", "true")
+
+	gt.NoError(t, os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte("module example.com/old\n"), 0644))
+	git(t, repoDir, "add", ".")
+	git(t, repoDir, "commit", "--quiet", "-m", "first")
+	first := git(t, repoDir, "rev-parse", "HEAD")
+
+	gt.NoError(t, os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte("module example.com/new\n"), 0644))
+	git(t, repoDir, "commit", "--quiet", "-am", "second")
+
+	app := &mock.GitHubAppMock{
+		InstallationTokenFunc: func(ctx context.Context, installID types.GitHubAppInstallID) (string, error) {
+			return "test-token", nil
+		},
+	}
+	fetcher := gitclone.New(app, "git", gitclone.WithBaseURL("file://"+base))
+
+	input := &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				CommitID:   first,
+			},
+		},
+		InstallID: 12345,
+	}
+
+	t.Run("commit is checked out", func(t *testing.T) {
+		workDir := t.TempDir()
+		fetched := gt.R1(fetcher.Fetch(context.Background(), input, workDir)).NoError(t)
+		gt.V(t, fetched.Dir).Equal(workDir)
+		gt.V(t, fetched.Archive).Nil()
+
+		content := gt.R1(os.ReadFile(filepath.Join(workDir, "go.mod"))).NoError(t)
+		gt.V(t, string(content)).Equal("module example.com/old\n")
+		_, err := os.Stat(filepath.Join(workDir, ".git"))
+		gt.True(t, os.IsNotExist(err))
+
+		gt.A(t, app.InstallationTokenCalls()).Length(1).At(0, func(t testing.TB, v struct {
+			Ctx       context.Context
+			InstallID types.GitHubAppInstallID
+		}) {
+			gt.V(t, v.InstallID).Equal(types.GitHubAppInstallID(12345))
+		})
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		unknown := *input
+		unknown.CommitID = strings.Repeat("0", 40)
+		_, err := fetcher.Fetch(context.Background(), &unknown, t.TempDir())
+		gt.Error(t, err)
+	})
+}
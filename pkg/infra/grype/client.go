@@ -0,0 +1,326 @@
+// Package grype scans source code with Grype (https://github.com/anchore/grype) as an alternative of Trivy. The report of Grype is converted into the Trivy report format so that it is stored and notified in the same way as Trivy's.
+package grype
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+)
+
+// Scanner runs Grype binary at path
+type Scanner struct {
+	path string
+	env  []string
+}
+
+var _ interfaces.Scanner = (*Scanner)(nil)
+
+type Option func(*Scanner)
+
+// WithEnv adds environment variables of Grype in "KEY=value" form, e.g. HTTPS_PROXY for the DB download. Grype inherits the environment of octovy otherwise.
+func WithEnv(env ...string) Option {
+	return func(x *Scanner) {
+		x.env = append(x.env, env...)
+	}
+}
+
+func New(path string, options ...Option) *Scanner {
+	scanner := &Scanner{path: path}
+	for _, opt := range options {
+		opt(scanner)
+	}
+	return scanner
+}
+
+// Scan runs `grype dir:` for the target. Grype reports only packages with vulnerabilities, so the converted report does not include other packages.
+func (x *Scanner) Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error) {
+	tmpResult, err := os.CreateTemp("", "octovy_grype.*.json")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temp file for scan result")
+	}
+	defer safe.Remove(tmpResult.Name())
+
+	if err := tmpResult.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close temp file for scan result")
+	}
+
+	args := []string{
+		"dir:" + target.Dir,
+		"--quiet",
+		"--output", "json",
+		"--file", tmpResult.Name(),
+	}
+	// Exclusion of Grype is a glob pattern matched against paths starting with "./"
+	for _, dir := range target.SkipDirs {
+		args = append(args, "--exclude", "./"+dir+"/**")
+	}
+	for _, file := range target.SkipFiles {
+		args = append(args, "--exclude", "./"+file)
+	}
+
+	if err := x.exec(ctx, args); err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(tmpResult.Name()))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read grype result", goerr.V("file", tmpResult.Name()))
+	}
+
+	var result grypeReport
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse grype result")
+	}
+
+	return convertReport(target.Dir, &result), nil
+}
+
+func (x *Scanner) exec(ctx context.Context, args []string) (err error) {
+	ctx, span := tracing.Start(ctx, "grype.run")
+	defer func() { tracing.End(span, err) }()
+
+	// Why: The arguments are not from user input
+	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+	// #nosec: G204
+	cmd := exec.CommandContext(ctx, x.path, args...)
+	if len(x.env) > 0 {
+		cmd.Env = append(os.Environ(), x.env...)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		logging.From(ctx).With("stderr", stderr.String()).With("stdout", stdout.String()).Error("grype failed")
+		return goerr.Wrap(err, "executing grype", goerr.V("stderr", stderr.String()), goerr.V("stdout", stdout.String()))
+	}
+
+	return nil
+}
+
+// grypeReport is the subset of `grype -o json` output used by octovy
+type grypeReport struct {
+	Matches []grypeMatch `json:"matches"`
+}
+
+type grypeMatch struct {
+	Vulnerability          grypeVulnerability   `json:"vulnerability"`
+	RelatedVulnerabilities []grypeVulnerability `json:"relatedVulnerabilities"`
+	Artifact               grypeArtifact        `json:"artifact"`
+}
+
+type grypeVulnerability struct {
+	ID          string      `json:"id"`
+	DataSource  string      `json:"dataSource"`
+	Namespace   string      `json:"namespace"`
+	Severity    string      `json:"severity"`
+	URLs        []string    `json:"urls"`
+	Description string      `json:"description"`
+	CVSS        []grypeCVSS `json:"cvss"`
+	Fix         struct {
+		Versions []string `json:"versions"`
+		State    string   `json:"state"`
+	} `json:"fix"`
+}
+
+type grypeCVSS struct {
+	Version string `json:"version"`
+	Vector  string `json:"vector"`
+	Metrics struct {
+		BaseScore float64 `json:"baseScore"`
+	} `json:"metrics"`
+}
+
+type grypeArtifact struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Type      string `json:"type"`
+	Locations []struct {
+		Path string `json:"path"`
+	} `json:"locations"`
+	Licenses []string `json:"licenses"`
+	PURL     string   `json:"purl"`
+}
+
+// trivyTypes maps package types of Grype to result types of Trivy. Unknown types are used as they are.
+var trivyTypes = map[string]string{
+	"go-module":    "gomod",
+	"npm":          "npm",
+	"python":       "pip",
+	"java-archive": "jar",
+	"gem":          "bundler",
+	"rust-crate":   "cargo",
+	"php-composer": "composer",
+	"dotnet":       "nuget",
+	"apk":          "alpine",
+	"deb":          "debian",
+	"rpm":          "redhat",
+}
+
+// fixStatuses maps fix states of Grype to statuses of Trivy
+var fixStatuses = map[string]string{
+	"fixed":     "fixed",
+	"not-fixed": "affected",
+	"wont-fix":  "will_not_fix",
+}
+
+var osPackageTypes = map[string]bool{
+	"apk": true,
+	"deb": true,
+	"rpm": true,
+}
+
+// convertReport converts the Grype report into the Trivy report format. Matches are grouped into results by the file where the package is found, as Trivy does.
+func convertReport(dir string, src *grypeReport) *trivy.Report {
+	results := map[string]*trivy.Result{}
+	// packages prevents duplicated packages in a result, because a package has a match for each vulnerability
+	packages := map[string]map[string]bool{}
+
+	for _, match := range src.Matches {
+		artifact := match.Artifact
+		target := "."
+		if len(artifact.Locations) > 0 {
+			target = strings.TrimPrefix(artifact.Locations[0].Path, "/")
+		}
+
+		result, ok := results[target]
+		if !ok {
+			class := trivy.ClassLangPkg
+			if osPackageTypes[artifact.Type] {
+				class = trivy.ClassOSPkg
+			}
+			resultType := artifact.Type
+			if t, ok := trivyTypes[artifact.Type]; ok {
+				resultType = t
+			}
+			result = &trivy.Result{Target: target, Class: class, Type: resultType}
+			results[target] = result
+			packages[target] = map[string]bool{}
+		}
+
+		var identifier *trivy.PackageIdentifier
+		if artifact.PURL != "" {
+			identifier = &trivy.PackageIdentifier{PURL: artifact.PURL}
+		}
+		pkgID := artifact.Name + "@" + artifact.Version
+		if !packages[target][pkgID] {
+			packages[target][pkgID] = true
+			result.Packages = append(result.Packages, trivy.Package{
+				ID:         pkgID,
+				Name:       artifact.Name,
+				Identifier: identifier,
+				Version:    artifact.Version,
+				Licenses:   artifact.Licenses,
+			})
+		}
+
+		result.Vulnerabilities = append(result.Vulnerabilities, convertVulnerability(&match, pkgID, identifier))
+	}
+
+	report := &trivy.Report{
+		SchemaVersion: trivy.SchemaVersion2,
+		ArtifactName:  dir,
+		ArtifactType:  "filesystem",
+	}
+
+	targets := make([]string, 0, len(results))
+	for target := range results {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		report.Results = append(report.Results, *results[target])
+	}
+
+	return report
+}
+
+// convertVulnerability converts a match. A GHSA advisory of Grype refers its CVE in RelatedVulnerabilities, and the CVE is used as the vulnerability ID for consistency with Trivy.
+func convertVulnerability(match *grypeMatch, pkgID string, identifier *trivy.PackageIdentifier) trivy.DetectedVulnerability {
+	vuln := match.Vulnerability
+	id := vuln.ID
+	var vendorIDs []string
+	description := vuln.Description
+	cvssList := vuln.CVSS
+	for _, related := range match.RelatedVulnerabilities {
+		if !strings.HasPrefix(id, "CVE-") && strings.HasPrefix(related.ID, "CVE-") {
+			vendorIDs = append(vendorIDs, id)
+			id = related.ID
+		}
+		if description == "" {
+			description = related.Description
+		}
+		if len(cvssList) == 0 {
+			cvssList = related.CVSS
+		}
+	}
+
+	cvss := trivy.CVSS{}
+	for _, c := range cvssList {
+		switch {
+		case strings.HasPrefix(c.Version, "2"):
+			cvss.V2Vector = c.Vector
+			cvss.V2Score = c.Metrics.BaseScore
+		case strings.HasPrefix(c.Version, "3"):
+			cvss.V3Vector = c.Vector
+			cvss.V3Score = c.Metrics.BaseScore
+		}
+	}
+
+	detected := trivy.DetectedVulnerability{
+		VulnerabilityID:  id,
+		VendorIDs:        vendorIDs,
+		PkgID:            pkgID,
+		PkgName:          match.Artifact.Name,
+		PkgIdentifier:    identifier,
+		InstalledVersion: match.Artifact.Version,
+		FixedVersion:     strings.Join(vuln.Fix.Versions, ", "),
+		Status:           fixStatuses[vuln.Fix.State],
+		SeveritySource:   "grype",
+		PrimaryURL:       vuln.DataSource,
+		DataSource: &trivy.DataSource{
+			ID:   trivy.SourceID(vuln.Namespace),
+			Name: vuln.Namespace,
+			URL:  vuln.DataSource,
+		},
+		Vulnerability: trivy.Vulnerability{
+			Description: description,
+			Severity:    convertSeverity(vuln.Severity),
+			References:  vuln.URLs,
+		},
+	}
+	if cvss != (trivy.CVSS{}) {
+		detected.CVSS = trivy.VendorCVSS{"grype": cvss}
+	}
+	return detected
+}
+
+// convertSeverity converts a severity of Grype into Trivy's. Negligible has no counterpart in Trivy and is treated as LOW.
+func convertSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return "CRITICAL"
+	case "high":
+		return "HIGH"
+	case "medium":
+		return "MEDIUM"
+	case "low", "negligible":
+		return "LOW"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,102 @@
+package grype_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra/grype"
+)
+
+// newFakeGrype creates a script which records its arguments and writes the report to the path of --file, as grype does
+func newFakeGrype(t *testing.T, report string) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake grype requires sh")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := fmt.Sprintf(`#!/bin/sh
+for arg in "$@"; do echo "$arg" >> %q; done
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--file" ]; then cp %q "$2"; fi
+  shift
+done
+`, argsFile, report)
+
+	path := filepath.Join(dir, "grype")
+	gt.NoError(t, os.WriteFile(path, []byte(script), 0700))
+	return path, argsFile
+}
+
+func TestScan(t *testing.T) {
+	report := gt.R1(filepath.Abs("testdata/report.json")).NoError(t)
+	path, argsFile := newFakeGrype(t, report)
+
+	scanner := grype.New(path)
+	result, err := scanner.Scan(context.Background(), &model.ScanTarget{
+		Dir:       "/tmp/code",
+		SkipDirs:  []string{"**/vendor", "docs"},
+		SkipFiles: []string{"go.mod"},
+	})
+	gt.NoError(t, err)
+
+	t.Run("arguments", func(t *testing.T) {
+		args := strings.Split(strings.TrimSpace(string(gt.R1(os.ReadFile(argsFile)).NoError(t))), "\n")
+		gt.V(t, args[0]).Equal("dir:/tmp/code")
+		gt.A(t, args).Contains([]string{"--output", "json"})
+		gt.A(t, args).Contains([]string{"--exclude", "./**/vendor/**"})
+		gt.A(t, args).Contains([]string{"--exclude", "./docs/**"})
+		gt.A(t, args).Contains([]string{"--exclude", "./go.mod"})
+	})
+
+	t.Run("report is converted into Trivy format", func(t *testing.T) {
+		gt.NoError(t, result.Validate())
+		gt.V(t, result.ArtifactName).Equal("/tmp/code")
+		gt.A(t, result.Results).Length(2)
+
+		goResult := result.Results[0]
+		gt.V(t, goResult.Target).Equal("services/api/go.mod")
+		gt.V(t, goResult.Class).Equal("lang-pkgs")
+		gt.V(t, goResult.Type).Equal("gomod")
+		// A package is listed once even if it has multiple vulnerabilities
+		gt.A(t, goResult.Packages).Length(1)
+		gt.V(t, goResult.Packages[0].Name).Equal("golang.org/x/net")
+		gt.V(t, goResult.Packages[0].Identifier.PURL).Equal("pkg:golang/golang.org/x/net@v0.15.0")
+		gt.A(t, goResult.Vulnerabilities).Length(2)
+
+		// GHSA advisory is identified by the related CVE
+		cve := goResult.Vulnerabilities[0]
+		gt.V(t, cve.VulnerabilityID).Equal("CVE-2023-44487")
+		gt.A(t, cve.VendorIDs).Equal([]string{"GHSA-qppj-fm5r-hxr3"})
+		gt.V(t, cve.PkgName).Equal("golang.org/x/net")
+		gt.V(t, cve.InstalledVersion).Equal("v0.15.0")
+		gt.V(t, cve.FixedVersion).Equal("0.17.0")
+		gt.V(t, cve.Status).Equal("fixed")
+		gt.V(t, cve.Severity).Equal("MEDIUM")
+		gt.V(t, cve.CVSS["grype"].V3Score).Equal(7.5)
+
+		ghsa := goResult.Vulnerabilities[1]
+		gt.V(t, ghsa.VulnerabilityID).Equal("GHSA-4374-p667-p6c8")
+		gt.V(t, ghsa.Severity).Equal("CRITICAL")
+		gt.V(t, ghsa.FixedVersion).Equal("")
+		gt.V(t, ghsa.Status).Equal("affected")
+
+		npmResult := result.Results[1]
+		gt.V(t, npmResult.Target).Equal("web/package-lock.json")
+		gt.V(t, npmResult.Type).Equal("npm")
+		gt.V(t, npmResult.Vulnerabilities[0].Severity).Equal("LOW")
+	})
+}
+
+func TestScanFailure(t *testing.T) {
+	scanner := grype.New(filepath.Join(t.TempDir(), "no-such-grype"))
+	_, err := scanner.Scan(context.Background(), &model.ScanTarget{Dir: t.TempDir()})
+	gt.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package oidc
+
+import (
+	"context"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// GitHubActionsIssuer is the issuer of OIDC tokens of GitHub Actions workflows
+const GitHubActionsIssuer = "https://token.actions.githubusercontent.com"
+
+// Verifier verifies ID tokens of an OIDC issuer with keys published by its discovery document. Keys are fetched and cached by go-oidc, and refetched when a token is signed by an unknown key.
+type Verifier struct {
+	verifier *gooidc.IDTokenVerifier
+}
+
+var _ interfaces.OIDCVerifier = (*Verifier)(nil)
+
+// New fetches the discovery document of the issuer. Tokens must have the audience.
+func New(ctx context.Context, issuer, audience string) (*Verifier, error) {
+	if issuer == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "issuer is empty")
+	}
+	if audience == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "audience is empty")
+	}
+
+	provider, err := gooidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get OIDC provider", goerr.V("issuer", issuer))
+	}
+
+	return &Verifier{
+		verifier: provider.Verifier(&gooidc.Config{ClientID: audience}),
+	}, nil
+}
+
+func (x *Verifier) Verify(ctx context.Context, token string) (*model.OIDCClaims, error) {
+	idToken, err := x.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrUnauthorized, "invalid OIDC token", goerr.V("error", err.Error()))
+	}
+
+	var claims model.OIDCClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, goerr.Wrap(types.ErrUnauthorized, "invalid claims of OIDC token", goerr.V("error", err.Error()))
+	}
+
+	return &claims, nil
+}
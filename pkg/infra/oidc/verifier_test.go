@@ -0,0 +1,108 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/oidc"
+)
+
+func TestVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	gt.NoError(t, err)
+	jwk := jose.JSONWebKey{Key: key.Public(), KeyID: "key-1", Algorithm: string(jose.RS256), Use: "sig"}
+
+	var ts *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                ts.URL,
+			"jwks_uri":                              ts.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		}))
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{jwk}}))
+	})
+	ts = httptest.NewServer(mux)
+	defer ts.Close()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithHeader("kid", "key-1"))
+	gt.NoError(t, err)
+	sign := func(claims map[string]any) string {
+		payload, err := json.Marshal(claims)
+		gt.NoError(t, err)
+		jws, err := signer.Sign(payload)
+		gt.NoError(t, err)
+		token, err := jws.CompactSerialize()
+		gt.NoError(t, err)
+		return token
+	}
+	claims := func(aud string, exp time.Time) map[string]any {
+		return map[string]any{
+			"iss":              ts.URL,
+			"sub":              "repo:myorg/app:ref:refs/heads/main",
+			"aud":              aud,
+			"exp":              exp.Unix(),
+			"iat":              time.Now().Unix(),
+			"repository_owner": "myorg",
+		}
+	}
+
+	ctx := context.Background()
+	verifier, err := oidc.New(ctx, ts.URL, "octovy")
+	gt.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		got, err := verifier.Verify(ctx, sign(claims("octovy", time.Now().Add(time.Hour))))
+		gt.NoError(t, err)
+		gt.V(t, got.Issuer).Equal(ts.URL)
+		gt.V(t, got.Subject).Equal("repo:myorg/app:ref:refs/heads/main")
+		gt.V(t, got.RepositoryOwner).Equal("myorg")
+	})
+
+	t.Run("other audience", func(t *testing.T) {
+		_, err := verifier.Verify(ctx, sign(claims("other", time.Now().Add(time.Hour))))
+		gt.True(t, errors.Is(err, types.ErrUnauthorized))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := verifier.Verify(ctx, sign(claims("octovy", time.Now().Add(-time.Hour))))
+		gt.True(t, errors.Is(err, types.ErrUnauthorized))
+	})
+
+	t.Run("token signed by another key", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		gt.NoError(t, err)
+		otherSigner, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: otherKey},
+			(&jose.SignerOptions{}).WithHeader("kid", "key-1"))
+		gt.NoError(t, err)
+		payload, err := json.Marshal(claims("octovy", time.Now().Add(time.Hour)))
+		gt.NoError(t, err)
+		jws, err := otherSigner.Sign(payload)
+		gt.NoError(t, err)
+		token, err := jws.CompactSerialize()
+		gt.NoError(t, err)
+
+		_, err = verifier.Verify(ctx, token)
+		gt.True(t, errors.Is(err, types.ErrUnauthorized))
+	})
+
+	t.Run("options are required", func(t *testing.T) {
+		_, err := oidc.New(ctx, "", "octovy")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+		_, err = oidc.New(ctx, ts.URL, "")
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
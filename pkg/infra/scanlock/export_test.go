@@ -0,0 +1,8 @@
+package scanlock
+
+// KeysForTest returns the number of keys held or waited for
+func (x *Memory) KeysForTest() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return len(x.locks)
+}
@@ -0,0 +1,226 @@
+package scanlock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// collectionScanLock has a lease document of each locked key
+	collectionScanLock = "scan_lock"
+
+	// DefaultLeaseTTL is how long a lease is valid without renewal. A lock of a crashed instance is taken over by others after this.
+	DefaultLeaseTTL = time.Minute
+	// defaultPollInterval is the interval to retry acquiring a lease held by others
+	defaultPollInterval = 2 * time.Second
+	// releaseTimeout limits deleting a lease on unlock, which runs even if the context of Lock is done
+	releaseTimeout = 10 * time.Second
+)
+
+// lease is a document of collectionScanLock
+type lease struct {
+	Key       string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// Firestore is a lock shared by instances using the same Firestore database. A holder owns a lease document of the key, which is renewed at a third of the TTL while the lock is held and deleted on unlock. A lease not renewed by its holder, e.g. of a crashed instance, expires after the TTL and is taken over by a waiter. Expired leases left by crashes can be removed by a TTL policy of the ExpiresAt field.
+type Firestore struct {
+	client       *firestore.Client
+	ttl          time.Duration
+	pollInterval time.Duration
+}
+
+var _ interfaces.ScanLock = (*Firestore)(nil)
+
+type FirestoreOption func(*Firestore)
+
+// WithLeaseTTL sets the TTL of a lease, DefaultLeaseTTL by default
+func WithLeaseTTL(ttl time.Duration) FirestoreOption {
+	return func(x *Firestore) {
+		if ttl > 0 {
+			x.ttl = ttl
+		}
+	}
+}
+
+// NewFirestore creates a lock with leases in the Firestore database
+func NewFirestore(ctx context.Context, projectID, databaseID string, options ...FirestoreOption) (*Firestore, error) {
+	var client *firestore.Client
+	var err error
+	if databaseID != "" {
+		client, err = firestore.NewClientWithDatabase(ctx, projectID, databaseID)
+	} else {
+		client, err = firestore.NewClient(ctx, projectID)
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create Firestore client of scan lock",
+			goerr.V("projectID", projectID),
+			goerr.V("databaseID", databaseID),
+		)
+	}
+
+	lock := &Firestore{
+		client:       client,
+		ttl:          DefaultLeaseTTL,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range options {
+		opt(lock)
+	}
+	return lock, nil
+}
+
+// leaseDocID returns the document ID of the key. Keys are hashed because they may have "/" which is not allowed in document IDs.
+func leaseDocID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (x *Firestore) Lock(ctx context.Context, key string) (func(), error) {
+	ref := x.client.Collection(collectionScanLock).Doc(leaseDocID(key))
+	holder := uuid.NewString()
+
+	for {
+		acquired, err := x.acquire(ctx, ref, key, holder)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+
+		select {
+		case <-time.After(x.pollInterval):
+		case <-ctx.Done():
+			return nil, goerr.Wrap(ctx.Err(), "cancelled while waiting for scan lock", goerr.V("key", key))
+		}
+	}
+
+	renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		x.renew(renewCtx, ref, key, holder)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+
+			releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+			defer releaseCancel()
+			if err := x.release(releaseCtx, ref, holder); err != nil {
+				logging.From(ctx).Warn("failed to release scan lock, it expires after TTL",
+					slog.String("key", key),
+					slog.Duration("ttl", x.ttl),
+					slog.Any("error", err),
+				)
+			}
+		})
+	}, nil
+}
+
+// acquire creates or takes over the lease of the key if it does not exist or is expired. It returns false if another holder has a valid lease.
+func (x *Firestore) acquire(ctx context.Context, ref *firestore.DocumentRef, key, holder string) (bool, error) {
+	var acquired bool
+	err := x.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		acquired = false
+		now := time.Now()
+
+		snap, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return goerr.Wrap(err, "failed to get scan lock lease")
+		default:
+			var current lease
+			if err := snap.DataTo(&current); err != nil {
+				return goerr.Wrap(err, "failed to decode scan lock lease")
+			}
+			if current.Holder != holder && now.Before(current.ExpiresAt) {
+				return nil
+			}
+		}
+
+		acquired = true
+		return tx.Set(ref, &lease{Key: key, Holder: holder, ExpiresAt: now.Add(x.ttl)})
+	})
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to acquire scan lock", goerr.V("key", key))
+	}
+	return acquired, nil
+}
+
+// renew extends the lease until ctx is done. A failure is logged and retried at the next interval, and the lock is kept until unlock even if the lease is lost, because the scan can not be stopped safely in the middle.
+func (x *Firestore) renew(ctx context.Context, ref *firestore.DocumentRef, key, holder string) {
+	ticker := time.NewTicker(x.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := x.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			snap, err := tx.Get(ref)
+			if err != nil {
+				return goerr.Wrap(err, "failed to get scan lock lease")
+			}
+			var current lease
+			if err := snap.DataTo(&current); err != nil {
+				return goerr.Wrap(err, "failed to decode scan lock lease")
+			}
+			if current.Holder != holder {
+				return goerr.New("scan lock lease is taken over by another holder", goerr.V("holder", current.Holder))
+			}
+			return tx.Update(ref, []firestore.Update{{Path: "ExpiresAt", Value: time.Now().Add(x.ttl)}})
+		})
+		if err != nil && ctx.Err() == nil {
+			logging.From(ctx).Warn("failed to renew scan lock lease", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// release deletes the lease if it is still held by the holder
+func (x *Firestore) release(ctx context.Context, ref *firestore.DocumentRef, holder string) error {
+	return x.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return goerr.Wrap(err, "failed to get scan lock lease")
+		}
+		var current lease
+		if err := snap.DataTo(&current); err != nil {
+			return goerr.Wrap(err, "failed to decode scan lock lease")
+		}
+		if current.Holder != holder {
+			return nil
+		}
+		return tx.Delete(ref)
+	})
+}
+
+// Close closes the Firestore client
+func (x *Firestore) Close() error {
+	return x.client.Close()
+}
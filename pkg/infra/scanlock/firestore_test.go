@@ -0,0 +1,43 @@
+package scanlock_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/infra/scanlock"
+)
+
+func TestFirestore(t *testing.T) {
+	projectID := os.Getenv("TEST_FIRESTORE_PROJECT_ID")
+	databaseID := os.Getenv("TEST_FIRESTORE_DATABASE_ID")
+	if projectID == "" || databaseID == "" {
+		t.Skip("Firestore credentials not configured (TEST_FIRESTORE_PROJECT_ID, TEST_FIRESTORE_DATABASE_ID)")
+	}
+
+	ctx := context.Background()
+	lock := gt.R1(scanlock.NewFirestore(ctx, projectID, databaseID, scanlock.WithLeaseTTL(3*time.Second))).NoError(t)
+	defer func() { gt.NoError(t, lock.Close()) }()
+	key := "test/" + uuid.NewString()
+
+	unlock := gt.R1(lock.Lock(ctx, key)).NoError(t)
+
+	t.Run("held lease is not acquired", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, err := lock.Lock(waitCtx, key)
+		gt.Error(t, err)
+	})
+
+	unlock()
+
+	t.Run("released lease is acquired", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		again := gt.R1(lock.Lock(waitCtx, key)).NoError(t)
+		again()
+	})
+}
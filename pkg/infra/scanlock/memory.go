@@ -0,0 +1,65 @@
+// Package scanlock implements interfaces.ScanLock, by a keyed mutex in memory for a single instance, or by leases in Firestore for instances sharing it.
+package scanlock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+)
+
+// Memory is a keyed mutex of a process. Entries of keys are removed when nobody holds or waits for them.
+type Memory struct {
+	mutex sync.Mutex
+	locks map[string]*memoryLock
+}
+
+type memoryLock struct {
+	// held has a value while the lock is held, so that waiting for it can be cancelled by select
+	held chan struct{}
+	refs int
+}
+
+var _ interfaces.ScanLock = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		locks: make(map[string]*memoryLock),
+	}
+}
+
+func (x *Memory) Lock(ctx context.Context, key string) (func(), error) {
+	x.mutex.Lock()
+	lock, ok := x.locks[key]
+	if !ok {
+		lock = &memoryLock{held: make(chan struct{}, 1)}
+		x.locks[key] = lock
+	}
+	lock.refs++
+	x.mutex.Unlock()
+
+	select {
+	case lock.held <- struct{}{}:
+	case <-ctx.Done():
+		x.unref(key, lock)
+		return nil, goerr.Wrap(ctx.Err(), "cancelled while waiting for scan lock", goerr.V("key", key))
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lock.held
+			x.unref(key, lock)
+		})
+	}, nil
+}
+
+func (x *Memory) unref(key string, lock *memoryLock) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(x.locks, key)
+	}
+}
@@ -0,0 +1,75 @@
+package scanlock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/infra/scanlock"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("excludes holders of the same key", func(t *testing.T) {
+		lock := scanlock.NewMemory()
+		unlock := gt.R1(lock.Lock(ctx, "octo/repo/aaaa")).NoError(t)
+
+		acquired := make(chan func())
+		go func() {
+			second, err := lock.Lock(ctx, "octo/repo/aaaa")
+			gt.NoError(t, err)
+			acquired <- second
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("lock of the same key is acquired while it is held")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		unlock()
+		select {
+		case second := <-acquired:
+			second()
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for the released lock")
+		}
+		gt.V(t, lock.KeysForTest()).Equal(0)
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		lock := scanlock.NewMemory()
+		unlockA := gt.R1(lock.Lock(ctx, "octo/repo/aaaa")).NoError(t)
+		unlockB := gt.R1(lock.Lock(ctx, "octo/repo/bbbb")).NoError(t)
+		gt.V(t, lock.KeysForTest()).Equal(2)
+		unlockA()
+		unlockB()
+		gt.V(t, lock.KeysForTest()).Equal(0)
+	})
+
+	t.Run("waiting is cancelled by context", func(t *testing.T) {
+		lock := scanlock.NewMemory()
+		unlock := gt.R1(lock.Lock(ctx, "octo/repo/aaaa")).NoError(t)
+		defer unlock()
+
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := lock.Lock(waitCtx, "octo/repo/aaaa")
+		gt.True(t, errors.Is(err, context.DeadlineExceeded))
+		gt.V(t, lock.KeysForTest()).Equal(1)
+	})
+
+	t.Run("unlock twice releases once", func(t *testing.T) {
+		lock := scanlock.NewMemory()
+		unlock := gt.R1(lock.Lock(ctx, "octo/repo/aaaa")).NoError(t)
+		unlock()
+		unlock()
+
+		again := gt.R1(lock.Lock(ctx, "octo/repo/aaaa")).NoError(t)
+		again()
+		gt.V(t, lock.KeysForTest()).Equal(0)
+	})
+}
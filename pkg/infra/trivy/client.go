@@ -3,31 +3,171 @@ package trivy
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"os"
 	"os/exec"
+	"sync"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client interface {
 	Run(ctx context.Context, args []string) error
+	// DownloadDB downloads or refreshes the vulnerability DB in the cache. After it succeeds, scans use the cached DB without updating it.
+	DownloadDB(ctx context.Context) error
+	// Version returns the version of the Trivy binary, e.g. "0.58.1"
+	Version(ctx context.Context) (string, error)
+	// CheckVersion detects the version of the Trivy binary once, logs it and compares it with the minimum version. It returns the detected version, and types.ErrIncompatibleTrivy if the version is below the minimum and the minimum is enforced. Scans check the version by themselves if the minimum version is set.
+	CheckVersion(ctx context.Context) (string, error)
 }
 
 type clientImpl struct {
-	path string
+	path     string
+	cacheDir string
+	env      []string
+
+	// dbMutex prevents scans from reading the DB while it is being replaced
+	dbMutex sync.RWMutex
+	dbReady bool
+
+	minVersion        string
+	enforceMinVersion bool
+	versionMutex      sync.Mutex
+	// version is the detected version, or empty until it is detected
+	version string
+}
+
+type Option func(*clientImpl)
+
+// WithCacheDir sets the cache directory of Trivy shared by DB download and scans. Trivy's default cache directory is used if not set.
+func WithCacheDir(dir string) Option {
+	return func(x *clientImpl) {
+		x.cacheDir = dir
+	}
+}
+
+// WithEnv adds environment variables of Trivy in "KEY=value" form, e.g. HTTPS_PROXY for the DB download. Trivy inherits the environment of octovy otherwise.
+func WithEnv(env ...string) Option {
+	return func(x *clientImpl) {
+		x.env = append(x.env, env...)
+	}
+}
+
+// WithMinVersion sets the minimum version of Trivy. An older Trivy is warned at the first scan, or refused if enforce is true, because its report may not be read correctly. The version is not checked if it is empty.
+func WithMinVersion(version string, enforce bool) Option {
+	return func(x *clientImpl) {
+		x.minVersion = version
+		x.enforceMinVersion = enforce
+	}
 }
 
-func New(path string) Client {
-	return &clientImpl{
+func New(path string, options ...Option) Client {
+	client := &clientImpl{
 		path: path,
 	}
+	for _, opt := range options {
+		opt(client)
+	}
+	return client
+}
+
+// scanCommands are Trivy subcommands which use the vulnerability DB
+var scanCommands = map[string]bool{
+	"fs":         true,
+	"filesystem": true,
+	"image":      true,
+	"repo":       true,
+	"repository": true,
+	"rootfs":     true,
+	"sbom":       true,
+	"vm":         true,
+}
+
+func (x *clientImpl) Run(ctx context.Context, args []string) (err error) {
+	if len(args) == 0 || !scanCommands[args[0]] {
+		return x.exec(ctx, args)
+	}
+
+	// The span includes waiting for a refresh of the DB, which blocks scans
+	ctx, span := tracing.Start(ctx, "trivy.run", trace.WithAttributes(attribute.String("trivy.command", args[0])))
+	defer func() { tracing.End(span, err) }()
+
+	// A failure of the version check stops the scan only if the minimum version is enforced
+	if x.minVersion != "" {
+		if _, err := x.CheckVersion(ctx); err != nil && x.enforceMinVersion {
+			return err
+		}
+	}
+
+	x.dbMutex.RLock()
+	defer x.dbMutex.RUnlock()
+
+	// Options are placed right after the subcommand so that they precede the scan target
+	scanArgs := []string{args[0]}
+	if x.cacheDir != "" {
+		scanArgs = append(scanArgs, "--cache-dir", x.cacheDir)
+	}
+	if x.dbReady {
+		scanArgs = append(scanArgs, "--skip-db-update")
+	}
+	scanArgs = append(scanArgs, args[1:]...)
+
+	return x.exec(ctx, scanArgs)
+}
+
+func (x *clientImpl) DownloadDB(ctx context.Context) error {
+	args := []string{"image", "--download-db-only", "--no-progress"}
+	if x.cacheDir != "" {
+		args = append(args, "--cache-dir", x.cacheDir)
+	}
+
+	x.dbMutex.Lock()
+	defer x.dbMutex.Unlock()
+
+	start := time.Now()
+	if err := x.exec(ctx, args); err != nil {
+		return goerr.Wrap(err, "failed to download Trivy DB", goerr.V("cache_dir", x.cacheDir))
+	}
+	x.dbReady = true
+
+	logging.From(ctx).Info("Trivy DB downloaded", "cache_dir", x.cacheDir, "duration", time.Since(start))
+	return nil
+}
+
+func (x *clientImpl) Version(ctx context.Context) (string, error) {
+	out, err := x.output(ctx, []string{"version", "--format", "json"})
+	if err != nil {
+		return "", err
+	}
+
+	var version struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal(out, &version); err != nil {
+		return "", goerr.Wrap(err, "failed to parse trivy version", goerr.V("output", string(out)))
+	}
+	return version.Version, nil
+}
+
+func (x *clientImpl) exec(ctx context.Context, args []string) error {
+	_, err := x.output(ctx, args)
+	return err
 }
 
-func (x *clientImpl) Run(ctx context.Context, args []string) error {
+// output runs Trivy and returns its stdout
+func (x *clientImpl) output(ctx context.Context, args []string) ([]byte, error) {
 	// Why: The arguments are not from user input
 	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
 	// #nosec: G204
 	cmd := exec.CommandContext(ctx, x.path, args...)
+	if len(x.env) > 0 {
+		cmd.Env = append(os.Environ(), x.env...)
+	}
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -35,8 +175,28 @@ func (x *clientImpl) Run(ctx context.Context, args []string) error {
 
 	if err := cmd.Run(); err != nil {
 		logging.From(ctx).With("stderr", stderr.String()).With("stdout", stdout.String()).Error("trivy failed")
-		return goerr.Wrap(err, "executing trivy", goerr.V("stderr", stderr.String()), goerr.V("stdout", stdout.String()))
+		return nil, goerr.Wrap(err, "executing trivy", goerr.V("stderr", stderr.String()), goerr.V("stdout", stdout.String()))
 	}
 
-	return nil
+	return stdout.Bytes(), nil
+}
+
+// RefreshDB downloads the vulnerability DB every interval until ctx is cancelled. A failed refresh is logged and scans keep using the previously downloaded DB.
+func RefreshDB(ctx context.Context, client Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := client.DownloadDB(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				logging.From(ctx).Error("failed to refresh Trivy DB", "error", err)
+			}
+		}
+	}
 }
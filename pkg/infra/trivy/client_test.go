@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/m-mizutani/gt"
@@ -76,3 +77,92 @@ func TestRunWithInvalidPath(t *testing.T) {
 		gt.Error(t, err)
 	})
 }
+
+// newFakeTrivy creates a script recording its arguments, one line per call. It fails if fail file exists in the same directory.
+func newFakeTrivy(t *testing.T) (path string, readCalls func() []string, setFail func(bool)) {
+	dir := t.TempDir()
+	path = filepath.Join(dir, "trivy")
+	logPath := filepath.Join(dir, "calls.log")
+	failPath := filepath.Join(dir, "fail")
+	script := "#!/bin/sh\necho \"$*\" >> " + logPath + "\ntest ! -e " + failPath + "\n"
+	gt.NoError(t, os.WriteFile(path, []byte(script), 0700))
+
+	readCalls = func() []string {
+		raw, err := os.ReadFile(logPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		gt.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(raw)), "\n")
+	}
+	setFail = func(fail bool) {
+		if fail {
+			gt.NoError(t, os.WriteFile(failPath, nil, 0600))
+		} else {
+			gt.NoError(t, os.Remove(failPath))
+		}
+	}
+	return path, readCalls, setFail
+}
+
+func TestDownloadDB(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("scans skip DB update after download", func(t *testing.T) {
+		path, readCalls, _ := newFakeTrivy(t)
+		client := trivy.New(path, trivy.WithCacheDir("/tmp/trivy-cache"))
+
+		gt.NoError(t, client.Run(ctx, []string{"fs", "--format", "json", "/src"}))
+		gt.NoError(t, client.DownloadDB(ctx))
+		gt.NoError(t, client.Run(ctx, []string{"fs", "--format", "json", "/src"}))
+		gt.NoError(t, client.Run(ctx, []string{"version"}))
+
+		gt.A(t, readCalls()).Equal([]string{
+			"fs --cache-dir /tmp/trivy-cache --format json /src",
+			"image --download-db-only --no-progress --cache-dir /tmp/trivy-cache",
+			"fs --cache-dir /tmp/trivy-cache --skip-db-update --format json /src",
+			"version",
+		})
+	})
+
+	t.Run("scans update DB by themselves if download failed", func(t *testing.T) {
+		path, readCalls, setFail := newFakeTrivy(t)
+		client := trivy.New(path)
+
+		setFail(true)
+		gt.Error(t, client.DownloadDB(ctx))
+		setFail(false)
+		gt.NoError(t, client.Run(ctx, []string{"fs", "/src"}))
+
+		gt.A(t, readCalls()).Equal([]string{
+			"image --download-db-only --no-progress",
+			"fs /src",
+		})
+	})
+
+	t.Run("failed refresh keeps the downloaded DB", func(t *testing.T) {
+		path, readCalls, setFail := newFakeTrivy(t)
+		client := trivy.New(path)
+
+		gt.NoError(t, client.DownloadDB(ctx))
+		setFail(true)
+		gt.Error(t, client.DownloadDB(ctx))
+		setFail(false)
+		gt.NoError(t, client.Run(ctx, []string{"fs", "/src"}))
+
+		calls := readCalls()
+		gt.V(t, calls[len(calls)-1]).Equal("fs --skip-db-update /src")
+	})
+}
+
+func TestVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trivy")
+	script := "#!/bin/sh\necho '{\"Version\":\"0.58.1\",\"VulnerabilityDB\":{\"Version\":2}}'\n"
+	gt.NoError(t, os.WriteFile(path, []byte(script), 0700))
+
+	version := gt.R1(trivy.New(path).Version(context.Background())).NoError(t)
+	gt.V(t, version).Equal("0.58.1")
+
+	_, err := trivy.New(filepath.Join(t.TempDir(), "not-found")).Version(context.Background())
+	gt.Error(t, err)
+}
@@ -0,0 +1,3 @@
+package trivy
+
+var CompareVersionsForTest = compareVersions
@@ -0,0 +1,93 @@
+package trivy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// DefaultMinVersion is the oldest Trivy whose report octovy is tested with
+const DefaultMinVersion = "0.50.0"
+
+func (x *clientImpl) CheckVersion(ctx context.Context) (string, error) {
+	x.versionMutex.Lock()
+	defer x.versionMutex.Unlock()
+
+	// A failure of detection is not cached, so that the next scan retries it
+	if x.version == "" {
+		version, err := x.Version(ctx)
+		if err != nil {
+			return "", goerr.Wrap(err, "failed to detect Trivy version", goerr.V("path", x.path))
+		}
+		x.version = version
+		x.logVersion(ctx)
+	}
+
+	if x.enforceMinVersion && x.minVersion != "" && compareVersions(x.version, x.minVersion) < 0 {
+		return x.version, goerr.Wrap(types.ErrIncompatibleTrivy, "Trivy is older than the minimum version",
+			goerr.V("version", x.version),
+			goerr.V("min_version", x.minVersion),
+			goerr.V("path", x.path),
+		)
+	}
+	return x.version, nil
+}
+
+func (x *clientImpl) logVersion(ctx context.Context) {
+	logger := logging.From(ctx).With("version", x.version, "min_version", x.minVersion, "path", x.path)
+	switch {
+	case x.minVersion == "":
+		logger.Info("Trivy version detected")
+	case parseVersion(x.version) == nil:
+		logger.Warn("Trivy version can not be compared with the minimum version")
+	case compareVersions(x.version, x.minVersion) >= 0:
+		logger.Info("Trivy version detected")
+	case x.enforceMinVersion:
+		logger.Error("Trivy is older than the minimum version, scans are refused")
+	default:
+		logger.Warn("Trivy is older than the minimum version, its report may not be read correctly")
+	}
+}
+
+// parseVersion returns major, minor and patch numbers of a version such as "0.58.1", "v0.58.1" or "0.58.1-dev". It returns nil if the version is not in the form.
+func parseVersion(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return nil
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil
+		}
+		nums[i] = n
+	}
+	return nums
+}
+
+// compareVersions returns -1, 0 or 1 if a is older than, the same as or newer than b. A version which can not be parsed is regarded as the same, so that an unknown version, e.g. a custom build, is not refused.
+func compareVersions(a, b string) int {
+	va, vb := parseVersion(a), parseVersion(b)
+	if va == nil || vb == nil {
+		return 0
+	}
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package trivy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra/trivy"
+)
+
+// fakeTrivy writes a script which reports the version and counts its runs in a file
+func fakeTrivy(t *testing.T, version string) (path, countFile string) {
+	dir := t.TempDir()
+	path = filepath.Join(dir, "trivy")
+	countFile = filepath.Join(dir, "count")
+	script := `#!/bin/sh
+if [ "$1" = "version" ]; then
+  echo x >> "` + countFile + `"
+  echo '{"Version":"` + version + `"}'
+fi
+`
+	gt.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path, countFile
+}
+
+func versionChecks(t *testing.T, countFile string) int {
+	data, err := os.ReadFile(countFile)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	gt.NoError(t, err)
+	return len(data) / 2
+}
+
+func TestCheckVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("version is detected once", func(t *testing.T) {
+		path, countFile := fakeTrivy(t, "0.58.1")
+		client := trivy.New(path, trivy.WithMinVersion("0.50.0", true))
+
+		gt.V(t, gt.R1(client.CheckVersion(ctx)).NoError(t)).Equal("0.58.1")
+		gt.NoError(t, client.Run(ctx, []string{"fs", "."}))
+		gt.V(t, versionChecks(t, countFile)).Equal(1)
+	})
+
+	t.Run("older version is refused if enforced", func(t *testing.T) {
+		path, _ := fakeTrivy(t, "0.45.0")
+		client := trivy.New(path, trivy.WithMinVersion("0.50.0", true))
+
+		version, err := client.CheckVersion(ctx)
+		gt.Error(t, err).Is(types.ErrIncompatibleTrivy)
+		gt.V(t, version).Equal("0.45.0")
+		gt.Error(t, client.Run(ctx, []string{"fs", "."})).Is(types.ErrIncompatibleTrivy)
+
+		// Commands other than scans are not refused
+		gt.NoError(t, client.Run(ctx, []string{"--help"}))
+	})
+
+	t.Run("older version is only warned by default", func(t *testing.T) {
+		path, _ := fakeTrivy(t, "0.45.0")
+		client := trivy.New(path, trivy.WithMinVersion("0.50.0", false))
+
+		gt.V(t, gt.R1(client.CheckVersion(ctx)).NoError(t)).Equal("0.45.0")
+		gt.NoError(t, client.Run(ctx, []string{"fs", "."}))
+	})
+
+	t.Run("unknown version is not refused", func(t *testing.T) {
+		path, _ := fakeTrivy(t, "dev")
+		client := trivy.New(path, trivy.WithMinVersion("0.50.0", true))
+
+		gt.V(t, gt.R1(client.CheckVersion(ctx)).NoError(t)).Equal("dev")
+	})
+
+	t.Run("failure of detection is retried", func(t *testing.T) {
+		client := trivy.New(filepath.Join(t.TempDir(), "missing"), trivy.WithMinVersion("0.50.0", true))
+
+		_, err := client.CheckVersion(ctx)
+		gt.Error(t, err)
+		gt.Error(t, client.Run(ctx, []string{"fs", "."}))
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{"0.58.1", "0.50.0", 1},
+		{"0.50.0", "0.50.0", 0},
+		{"v0.50.0", "0.50", 0},
+		{"0.49.9", "0.50.0", -1},
+		{"0.9.0", "0.10.0", -1},
+		{"1.0.0", "0.99.9", 1},
+		{"0.50.0-dev", "0.50.0", 0},
+		{"dev", "0.50.0", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+"_"+tc.b, func(t *testing.T) {
+			gt.V(t, trivy.CompareVersionsForTest(tc.a, tc.b)).Equal(tc.want)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+package upgrader
+
+var BumpPackageJSON = bumpPackageJSON
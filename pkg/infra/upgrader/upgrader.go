@@ -0,0 +1,168 @@
+// Package upgrader upgrades a vulnerable package in manifest and lock files with the package manager of its ecosystem, to create a pull request of the upgrade.
+package upgrader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// Upgrader runs go binary at goPath for Go modules and npm binary at npmPath for npm
+type Upgrader struct {
+	goPath  string
+	npmPath string
+	env     []string
+}
+
+var _ interfaces.PackageUpgrader = (*Upgrader)(nil)
+
+type Option func(*Upgrader)
+
+// WithEnv adds environment variables of the package managers in "KEY=value" form, e.g. HTTPS_PROXY or GOPRIVATE. They inherit the environment of octovy otherwise.
+func WithEnv(env ...string) Option {
+	return func(x *Upgrader) {
+		x.env = append(x.env, env...)
+	}
+}
+
+func New(goPath, npmPath string, options ...Option) *Upgrader {
+	upgrader := &Upgrader{goPath: goPath, npmPath: npmPath}
+	for _, opt := range options {
+		opt(upgrader)
+	}
+	return upgrader
+}
+
+// Upgrade runs `go get` for gomod. For npm, it bumps the version range of the package in package.json and runs `npm install --package-lock-only` without running scripts of packages. A package which is not a direct dependency in package.json is not upgraded for npm, because adding it as a direct dependency changes the manifest more than needed.
+func (x *Upgrader) Upgrade(ctx context.Context, dir string, upgrade *model.PackageUpgrade) error {
+	switch upgrade.Ecosystem {
+	case "gomod":
+		version := upgrade.FixedVersion
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		// -mod=mod allows go.sum to be updated without source code of the module
+		return x.exec(ctx, dir, x.goPath, []string{"get", upgrade.PkgName + "@" + version}, "GOFLAGS=-mod=mod")
+
+	case "npm":
+		manifest := filepath.Join(dir, "package.json")
+		raw, err := os.ReadFile(filepath.Clean(manifest))
+		if err != nil {
+			return goerr.Wrap(err, "failed to read package.json", goerr.V("dir", dir))
+		}
+		bumped, err := bumpPackageJSON(raw, upgrade.PkgName, upgrade.FixedVersion)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(manifest, bumped, 0600); err != nil {
+			return goerr.Wrap(err, "failed to write package.json", goerr.V("dir", dir))
+		}
+		return x.exec(ctx, dir, x.npmPath, []string{"install", "--package-lock-only", "--ignore-scripts", "--no-audit", "--no-fund"})
+
+	default:
+		return goerr.Wrap(types.ErrInvalidOption, "ecosystem is not supported by upgrade", goerr.V("ecosystem", upgrade.Ecosystem))
+	}
+}
+
+// dependencySections are sections of package.json whose versions are bumped. peerDependencies are not, because they are ranges required of users of the package.
+var dependencySections = []string{"dependencies", "devDependencies", "optionalDependencies"}
+
+// semverRange matches a version range which is bumped, with its prefix kept
+var semverRange = regexp.MustCompile(`^(\^|~|>=|=)?v?\d+(\.\d+){0,2}([-+][0-9A-Za-z.-]+)?$`)
+
+// bumpPackageJSON replaces the version range of the package in dependency sections with the version, keeping the prefix of the range such as "^" and formatting of the file. It fails if the package is not a direct dependency or its range is not a plain version, e.g. a git URL.
+func bumpPackageJSON(raw []byte, name, version string) ([]byte, error) {
+	sections, err := dependencySectionSpans(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := regexp.MustCompile(`("` + regexp.QuoteMeta(name) + `"\s*:\s*")([^"]*)(")`)
+	var b bytes.Buffer
+	last := 0
+	found := false
+	for _, span := range sections {
+		for _, m := range pattern.FindAllSubmatchIndex(raw[span[0]:span[1]], -1) {
+			current := string(raw[span[0]+m[4] : span[0]+m[5]])
+			sub := semverRange.FindStringSubmatch(current)
+			if sub == nil {
+				return nil, goerr.New("version range of package is not a plain version", goerr.V("package", name), goerr.V("range", current))
+			}
+			prefix := sub[1]
+			if prefix == ">=" || prefix == "=" {
+				prefix = ""
+			}
+			b.Write(raw[last : span[0]+m[4]])
+			b.WriteString(prefix + strings.TrimPrefix(version, "v"))
+			last = span[0] + m[5]
+			found = true
+		}
+	}
+	if !found {
+		return nil, goerr.New("package is not a direct dependency in package.json", goerr.V("package", name))
+	}
+	b.Write(raw[last:])
+	return b.Bytes(), nil
+}
+
+// dependencySectionSpans returns offsets of values of dependencySections in package.json, in the order of the file
+func dependencySectionSpans(raw []byte) ([][2]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, goerr.New("package.json is not a JSON object", goerr.V("error", err))
+	}
+
+	var spans [][2]int
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to parse package.json")
+		}
+		start := int(dec.InputOffset())
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, goerr.Wrap(err, "failed to parse package.json")
+		}
+		if slices.Contains(dependencySections, key.(string)) {
+			spans = append(spans, [2]int{start, int(dec.InputOffset())})
+		}
+	}
+	return spans, nil
+}
+
+func (x *Upgrader) exec(ctx context.Context, dir, path string, args []string, env ...string) error {
+	// Why: The arguments are not from user input
+	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+	// #nosec: G204
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), env...), x.env...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		logging.From(ctx).With("stderr", stderr.String()).With("stdout", stdout.String()).Error("package manager failed", "path", path, "args", args)
+		return goerr.Wrap(err, "executing package manager",
+			goerr.V("path", path),
+			goerr.V("args", args),
+			goerr.V("stderr", stderr.String()),
+			goerr.V("stdout", stdout.String()),
+		)
+	}
+
+	return nil
+}
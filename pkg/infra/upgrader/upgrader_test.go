@@ -0,0 +1,149 @@
+package upgrader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra/upgrader"
+)
+
+// newFakeCommand creates a script which records its working directory, arguments and GOFLAGS, and appends a line to the file of the package manager, as go and npm update their lock files
+func newFakeCommand(t *testing.T, name, lockFile string) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake command requires sh")
+	}
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "log")
+	script := fmt.Sprintf(`#!/bin/sh
+pwd >> %q
+echo "GOFLAGS=$GOFLAGS" >> %q
+for arg in "$@"; do echo "$arg" >> %q; done
+echo updated >> %s
+`, logFile, logFile, logFile, lockFile)
+
+	path := filepath.Join(dir, name)
+	gt.NoError(t, os.WriteFile(path, []byte(script), 0700))
+	return path, logFile
+}
+
+func readLog(t *testing.T, path string) []string {
+	return strings.Split(strings.TrimSpace(string(gt.R1(os.ReadFile(path)).NoError(t))), "\n")
+}
+
+func TestUpgradeGoModule(t *testing.T) {
+	goPath, logFile := newFakeCommand(t, "go", "go.sum")
+	dir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0600))
+
+	client := upgrader.New(goPath, "npm")
+	gt.NoError(t, client.Upgrade(context.Background(), dir, &model.PackageUpgrade{
+		Ecosystem: "gomod", PkgName: "golang.org/x/net", FixedVersion: "0.17.0",
+	}))
+
+	log := readLog(t, logFile)
+	gt.V(t, gt.R1(filepath.EvalSymlinks(log[0])).NoError(t)).Equal(gt.R1(filepath.EvalSymlinks(dir)).NoError(t))
+	gt.V(t, log[1]).Equal("GOFLAGS=-mod=mod")
+	gt.A(t, log[2:]).Equal([]string{"get", "golang.org/x/net@v0.17.0"})
+}
+
+func TestUpgradeNpm(t *testing.T) {
+	t.Run("package.json is bumped before npm install", func(t *testing.T) {
+		npmPath, logFile := newFakeCommand(t, "npm", "package-lock.json")
+		dir := t.TempDir()
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"dependencies": {"lodash": "^4.17.0"}}`), 0600))
+
+		client := upgrader.New("go", npmPath)
+		gt.NoError(t, client.Upgrade(context.Background(), dir, &model.PackageUpgrade{
+			Ecosystem: "npm", PkgName: "lodash", FixedVersion: "4.17.21",
+		}))
+
+		gt.V(t, string(gt.R1(os.ReadFile(filepath.Join(dir, "package.json"))).NoError(t))).Equal(`{"dependencies": {"lodash": "^4.17.21"}}`)
+		gt.A(t, readLog(t, logFile)[2:]).Equal([]string{"install", "--package-lock-only", "--ignore-scripts", "--no-audit", "--no-fund"})
+	})
+
+	t.Run("transitive dependency is not upgraded", func(t *testing.T) {
+		npmPath, logFile := newFakeCommand(t, "npm", "package-lock.json")
+		dir := t.TempDir()
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"dependencies": {"express": "^4.18.0"}}`), 0600))
+
+		client := upgrader.New("go", npmPath)
+		gt.Error(t, client.Upgrade(context.Background(), dir, &model.PackageUpgrade{
+			Ecosystem: "npm", PkgName: "qs", FixedVersion: "6.11.0",
+		}))
+		_, err := os.Stat(logFile)
+		gt.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestUpgradeUnsupportedEcosystem(t *testing.T) {
+	client := upgrader.New("go", "npm")
+	gt.Error(t, client.Upgrade(context.Background(), t.TempDir(), &model.PackageUpgrade{
+		Ecosystem: "cargo", PkgName: "serde", FixedVersion: "1.0.190",
+	}))
+}
+
+func TestBumpPackageJSON(t *testing.T) {
+	const manifest = `{
+  "name": "app",
+  "dependencies": {
+    "lodash": "~4.17.0",
+    "react": "18.2.0"
+  },
+  "devDependencies": {
+    "jest": ">=29.0.0"
+  },
+  "peerDependencies": {
+    "react": "^18.0.0"
+  },
+  "overrides": {
+    "jest": "29.0.0"
+  }
+}`
+
+	testCases := map[string]struct {
+		name     string
+		version  string
+		expected []string
+	}{
+		"tilde range is kept": {
+			name: "lodash", version: "4.17.21",
+			expected: []string{`"lodash": "~4.17.21"`},
+		},
+		"exact version": {
+			name: "react", version: "v18.2.1",
+			expected: []string{`"react": "18.2.1"`, `"react": "^18.0.0"`},
+		},
+		"dev dependency and not overrides": {
+			name: "jest", version: "29.7.0",
+			expected: []string{`"jest": "29.7.0"`, `"jest": "29.0.0"`},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bumped := string(gt.R1(upgrader.BumpPackageJSON([]byte(manifest), tc.name, tc.version)).NoError(t))
+			for _, s := range tc.expected {
+				gt.S(t, bumped).Contains(s)
+			}
+			gt.S(t, bumped).Contains(`"name": "app"`)
+		})
+	}
+
+	t.Run("range which is not a plain version fails", func(t *testing.T) {
+		_, err := upgrader.BumpPackageJSON([]byte(`{"dependencies": {"x": "github:owner/x"}}`), "x", "1.0.0")
+		gt.Error(t, err)
+	})
+
+	t.Run("package not in dependencies fails", func(t *testing.T) {
+		_, err := upgrader.BumpPackageJSON([]byte(manifest), "qs", "6.11.0")
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package wasmpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+const (
+	// OCIScheme prefixes a reference of a plugin stored as an OCI artifact, e.g. oci://ghcr.io/myorg/gate-policy:v1
+	OCIScheme = "oci://"
+
+	// mediaTypeWASM is the layer media type of a plugin pushed by e.g. `oras push ghcr.io/myorg/gate-policy:v1 policy.wasm:application/wasm`
+	mediaTypeWASM = "application/wasm"
+	// mediaTypeWASMLayer is the layer media type of WASM modules by wasm-to-oci
+	mediaTypeWASMLayer = "application/vnd.wasm.content.layer.v1+wasm"
+
+	// maxManifestSize and maxPluginSize limit downloads from OCI registries
+	maxManifestSize = 4 << 20
+	maxPluginSize   = 64 << 20
+)
+
+// Load reads the WASM module of a plugin from a file path, or from an OCI registry if the source has the oci:// prefix, and compiles it. The source is the name of the plugin. Credentials of OCI registries are read from the Docker config, e.g. ~/.docker/config.json.
+func Load(ctx context.Context, source string, options ...Option) (*Plugin, error) {
+	var wasm []byte
+	if ref, ok := strings.CutPrefix(source, OCIScheme); ok {
+		pulled, err := pull(ctx, ref, newConfig(options))
+		if err != nil {
+			return nil, err
+		}
+		wasm = pulled
+	} else {
+		raw, err := os.ReadFile(source)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to read WASM policy plugin", goerr.V("path", source))
+		}
+		wasm = raw
+	}
+
+	return New(ctx, source, wasm, options...)
+}
+
+// pull downloads the WASM module of the artifact. The layer of a WASM media type is used, or the only layer of the artifact.
+func pull(ctx context.Context, ref string, cfg *config) ([]byte, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid OCI reference of WASM policy plugin", goerr.V("ref", ref), goerr.V("error", err.Error()))
+	}
+	if repo.Reference.Reference == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "tag or digest is required in OCI reference of WASM policy plugin", goerr.V("ref", ref))
+	}
+
+	client := &auth.Client{
+		Client: cfg.httpClient,
+		Cache:  auth.NewCache(),
+	}
+	if store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{}); err == nil {
+		client.Credential = credentials.Credential(store)
+	}
+	repo.Client = client
+	repo.PlainHTTP = cfg.plainHTTP
+
+	desc, rc, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to fetch manifest of WASM policy plugin", goerr.V("ref", ref))
+	}
+	defer rc.Close()
+	if desc.Size > maxManifestSize {
+		return nil, goerr.New("manifest of WASM policy plugin is too large", goerr.V("ref", ref), goerr.V("size", desc.Size))
+	}
+	raw, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read manifest of WASM policy plugin", goerr.V("ref", ref))
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, goerr.Wrap(err, "invalid manifest of WASM policy plugin", goerr.V("ref", ref))
+	}
+	layer, err := wasmLayer(manifest.Layers)
+	if err != nil {
+		return nil, goerr.Wrap(err, "no WASM module in artifact", goerr.V("ref", ref))
+	}
+	if layer.Size > maxPluginSize {
+		return nil, goerr.New("WASM policy plugin is too large", goerr.V("ref", ref), goerr.V("size", layer.Size))
+	}
+
+	blob, err := repo.Fetch(ctx, layer)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to fetch WASM policy plugin", goerr.V("ref", ref), goerr.V("digest", layer.Digest))
+	}
+	defer blob.Close()
+
+	// ReadAll verifies the digest of the layer
+	wasm, err := content.ReadAll(blob, layer)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read WASM policy plugin", goerr.V("ref", ref), goerr.V("digest", layer.Digest))
+	}
+	return wasm, nil
+}
+
+func wasmLayer(layers []ocispec.Descriptor) (ocispec.Descriptor, error) {
+	for _, layer := range layers {
+		if layer.MediaType == mediaTypeWASM || layer.MediaType == mediaTypeWASMLayer {
+			return layer, nil
+		}
+	}
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+	return ocispec.Descriptor{}, goerr.Wrap(types.ErrInvalidOption, "artifact must have a layer of WASM media type or only one layer", goerr.V("layers", len(layers)))
+}
@@ -0,0 +1,127 @@
+package wasmpolicy_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra/wasmpolicy"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newRegistry serves an artifact of the layers as test/policy:v1 by the OCI distribution API
+func newRegistry(t *testing.T, layers map[string][]byte) *httptest.Server {
+	blobs := map[digest.Digest][]byte{}
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+	}
+	manifest.SchemaVersion = 2
+	blobs[ocispec.DescriptorEmptyJSON.Digest] = ocispec.DescriptorEmptyJSON.Data
+	for mediaType, data := range layers {
+		d := digest.FromBytes(data)
+		blobs[d] = data
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{MediaType: mediaType, Digest: d, Size: int64(len(data))})
+	}
+	rawManifest := gt.R1(json.Marshal(manifest)).NoError(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/test/policy/manifests/v1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(rawManifest).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(rawManifest)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(rawManifest)
+		}
+	})
+	mux.HandleFunc("/v2/test/policy/blobs/", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := blobs[digest.Digest(strings.TrimPrefix(r.URL.Path, "/v2/test/policy/blobs/"))]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		_, _ = w.Write(data)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	output := `{"pass":false,"reasons":["denied"]}`
+	module := wasmModule(fixedBody(len(output)), []byte(output))
+
+	t.Run("load plugin from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.wasm")
+		gt.NoError(t, os.WriteFile(path, module, 0600))
+
+		plugin := gt.R1(wasmpolicy.Load(ctx, path)).NoError(t)
+		defer plugin.Close(ctx)
+		gt.V(t, plugin.Name()).Equal(path)
+
+		decision := gt.R1(plugin.Evaluate(ctx, &model.GatePolicyInput{})).NoError(t)
+		gt.False(t, decision.Pass)
+		gt.A(t, decision.Reasons).Equal([]string{"denied"})
+	})
+
+	t.Run("missing file is an error", func(t *testing.T) {
+		gt.R1(wasmpolicy.Load(ctx, filepath.Join(t.TempDir(), "not-found.wasm"))).Error(t)
+	})
+
+	t.Run("load plugin from OCI registry", func(t *testing.T) {
+		server := newRegistry(t, map[string][]byte{"application/wasm": module})
+		source := wasmpolicy.OCIScheme + strings.TrimPrefix(server.URL, "http://") + "/test/policy:v1"
+
+		plugin := gt.R1(wasmpolicy.Load(ctx, source, wasmpolicy.WithPlainHTTP())).NoError(t)
+		defer plugin.Close(ctx)
+		gt.V(t, plugin.Name()).Equal(source)
+
+		decision := gt.R1(plugin.Evaluate(ctx, &model.GatePolicyInput{})).NoError(t)
+		gt.A(t, decision.Reasons).Equal([]string{"denied"})
+	})
+
+	t.Run("WASM layer is selected from layers", func(t *testing.T) {
+		server := newRegistry(t, map[string][]byte{
+			"text/markdown": []byte("# policy"),
+			"application/vnd.wasm.content.layer.v1+wasm": module,
+		})
+		source := wasmpolicy.OCIScheme + strings.TrimPrefix(server.URL, "http://") + "/test/policy:v1"
+
+		plugin := gt.R1(wasmpolicy.Load(ctx, source, wasmpolicy.WithPlainHTTP())).NoError(t)
+		defer plugin.Close(ctx)
+	})
+
+	t.Run("artifact without WASM layer is an error", func(t *testing.T) {
+		server := newRegistry(t, map[string][]byte{
+			"text/markdown": []byte("# policy"),
+			"text/plain":    []byte("policy"),
+		})
+		source := wasmpolicy.OCIScheme + strings.TrimPrefix(server.URL, "http://") + "/test/policy:v1"
+
+		gt.R1(wasmpolicy.Load(ctx, source, wasmpolicy.WithPlainHTTP())).Error(t)
+	})
+
+	t.Run("unknown tag is an error", func(t *testing.T) {
+		server := newRegistry(t, map[string][]byte{"application/wasm": module})
+		source := wasmpolicy.OCIScheme + strings.TrimPrefix(server.URL, "http://") + "/test/policy:v2"
+
+		gt.R1(wasmpolicy.Load(ctx, source, wasmpolicy.WithPlainHTTP())).Error(t)
+	})
+
+	t.Run("reference without tag is an error", func(t *testing.T) {
+		gt.R1(wasmpolicy.Load(ctx, wasmpolicy.OCIScheme+"ghcr.io/test/policy")).Error(t)
+	})
+}
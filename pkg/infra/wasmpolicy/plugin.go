@@ -0,0 +1,234 @@
+// Package wasmpolicy runs deployment gate policies compiled to WebAssembly, so that gating logic can be written in any language compiling to WASM. A plugin is a WASM module, optionally using WASI, which exports:
+//
+//   - memory: the linear memory of the module
+//   - octovy_alloc(size i32) i32: allocates size bytes and returns the pointer to them, where the input is written
+//   - octovy_evaluate(ptr i32, size i32) i64: evaluates the input JSON of model.GatePolicyInput at ptr and returns the output JSON of model.GatePolicyDecision, with the pointer to the output in the upper 32 bits and its size in the lower 32 bits
+//
+// A reactor module exporting _initialize, e.g. built by Go with -buildmode=c-shared, is initialized before the evaluation. _start is never called. Every evaluation runs in a new instance of the module, so that no state is shared between evaluations.
+package wasmpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	funcAlloc      = "octovy_alloc"
+	funcEvaluate   = "octovy_evaluate"
+	funcInitialize = "_initialize"
+
+	// DefaultTimeout limits an evaluation so that a looping plugin does not block the gate
+	DefaultTimeout = 10 * time.Second
+
+	// memoryLimitPages limits memory of a plugin to 256 MiB
+	memoryLimitPages = 4096
+
+	// maxOutputSize limits the decision read from a plugin
+	maxOutputSize = 1 << 20
+
+	// maxStderrSize limits stderr of a plugin kept for errors
+	maxStderrSize = 4096
+)
+
+// Plugin is a compiled WASM policy plugin
+type Plugin struct {
+	name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+var _ interfaces.GatePolicy = (*Plugin)(nil)
+
+type config struct {
+	timeout    time.Duration
+	httpClient *http.Client
+	plainHTTP  bool
+}
+
+type Option func(*config)
+
+// WithTimeout sets the time limit of an evaluation
+func WithTimeout(timeout time.Duration) Option {
+	return func(x *config) {
+		x.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client to pull plugins from OCI registries, e.g. to go through a proxy
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *config) {
+		x.httpClient = client
+	}
+}
+
+// WithPlainHTTP pulls plugins from OCI registries by HTTP instead of HTTPS, e.g. from a local registry
+func WithPlainHTTP() Option {
+	return func(x *config) {
+		x.plainHTTP = true
+	}
+}
+
+func newConfig(options []Option) *config {
+	cfg := &config{timeout: DefaultTimeout}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// New compiles the WASM module of a plugin and checks that it exports the ABI. name identifies the plugin in gate results.
+func New(ctx context.Context, name string, wasm []byte, options ...Option) (*Plugin, error) {
+	cfg := newConfig(options)
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(memoryLimitPages).
+		WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, goerr.Wrap(err, "failed to instantiate WASI", goerr.V("plugin", name))
+	}
+
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, goerr.Wrap(types.ErrInvalidOption, "failed to compile WASM policy plugin", goerr.V("plugin", name), goerr.V("error", err.Error()))
+	}
+	if err := validateABI(compiled); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, goerr.Wrap(err, "invalid WASM policy plugin", goerr.V("plugin", name))
+	}
+
+	return &Plugin{
+		name:     name,
+		runtime:  runtime,
+		compiled: compiled,
+		timeout:  cfg.timeout,
+	}, nil
+}
+
+func validateABI(compiled wazero.CompiledModule) error {
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		return goerr.Wrap(types.ErrInvalidOption, "memory is not exported")
+	}
+
+	signatures := []struct {
+		name    string
+		params  []api.ValueType
+		results []api.ValueType
+	}{
+		{funcAlloc, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}},
+		{funcEvaluate, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}},
+	}
+	exported := compiled.ExportedFunctions()
+	for _, sig := range signatures {
+		def, ok := exported[sig.name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "function is not exported", goerr.V("function", sig.name))
+		}
+		if !slices.Equal(def.ParamTypes(), sig.params) || !slices.Equal(def.ResultTypes(), sig.results) {
+			return goerr.Wrap(types.ErrInvalidOption, "function has invalid signature", goerr.V("function", sig.name))
+		}
+	}
+	return nil
+}
+
+func (x *Plugin) Name() string {
+	return x.name
+}
+
+// Close releases the compiled module
+func (x *Plugin) Close(ctx context.Context) error {
+	return x.runtime.Close(ctx)
+}
+
+// Evaluate runs the plugin with the input in a new instance of the module
+func (x *Plugin) Evaluate(ctx context.Context, input *model.GatePolicyInput) (*model.GatePolicyDecision, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal gate policy input")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, x.timeout)
+	defer cancel()
+
+	stderr := &limitedBuffer{limit: maxStderrSize}
+	modConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithStartFunctions()
+	if _, ok := x.compiled.ExportedFunctions()[funcInitialize]; ok {
+		modConfig = modConfig.WithStartFunctions(funcInitialize)
+	}
+
+	mod, err := x.runtime.InstantiateModule(ctx, x.compiled, modConfig)
+	if err != nil {
+		return nil, x.wrap(err, "failed to instantiate WASM policy plugin", stderr)
+	}
+	defer mod.Close(context.Background())
+
+	results, err := mod.ExportedFunction(funcAlloc).Call(ctx, uint64(len(raw)))
+	if err != nil {
+		return nil, x.wrap(err, "failed to allocate memory of WASM policy plugin", stderr)
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, raw) {
+		return nil, goerr.New("memory allocated by WASM policy plugin is out of range", goerr.V("plugin", x.name), goerr.V("ptr", ptr), goerr.V("size", len(raw)))
+	}
+
+	results, err = mod.ExportedFunction(funcEvaluate).Call(ctx, uint64(ptr), uint64(len(raw)))
+	if err != nil {
+		return nil, x.wrap(err, "failed to evaluate WASM policy plugin", stderr)
+	}
+	outPtr, outSize := uint32(results[0]>>32), uint32(results[0])
+	if outSize > maxOutputSize {
+		return nil, goerr.New("output of WASM policy plugin is too large", goerr.V("plugin", x.name), goerr.V("size", outSize))
+	}
+	output, ok := mod.Memory().Read(outPtr, outSize)
+	if !ok {
+		return nil, goerr.New("output of WASM policy plugin is out of range", goerr.V("plugin", x.name), goerr.V("ptr", outPtr), goerr.V("size", outSize))
+	}
+
+	var decision model.GatePolicyDecision
+	if err := json.Unmarshal(output, &decision); err != nil {
+		return nil, goerr.Wrap(err, "invalid output of WASM policy plugin", goerr.V("plugin", x.name), goerr.V("output", string(output)))
+	}
+	decision.Policy = x.name
+
+	return &decision, nil
+}
+
+func (x *Plugin) wrap(err error, msg string, stderr *limitedBuffer) error {
+	return goerr.Wrap(err, msg, goerr.V("plugin", x.name), goerr.V("stderr", stderr.String()))
+}
+
+// limitedBuffer keeps the first bytes written up to the limit and discards the rest
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (x *limitedBuffer) Write(p []byte) (int, error) {
+	if remain := x.limit - x.buf.Len(); remain > 0 {
+		x.buf.Write(p[:min(len(p), remain)])
+	}
+	return len(p), nil
+}
+
+func (x *limitedBuffer) String() string {
+	return x.buf.String()
+}
@@ -0,0 +1,195 @@
+package wasmpolicy_test
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra/wasmpolicy"
+)
+
+// Instructions of WASM function bodies of test plugins
+var (
+	// echoBody returns the input as the output, so that the decision is the pass field of the input
+	echoBody = []byte{
+		0x20, 0x00, // local.get 0 (ptr)
+		0xad,       // i64.extend_i32_u
+		0x42, 0x20, // i64.const 32
+		0x86,       // i64.shl
+		0x20, 0x01, // local.get 1 (size)
+		0xad, // i64.extend_i32_u
+		0x84, // i64.or
+		0x0b, // end
+	}
+
+	// trapBody fails the evaluation
+	trapBody = []byte{
+		0x00, // unreachable
+		0x0b, // end
+	}
+
+	// loopBody never returns
+	loopBody = []byte{
+		0x03, 0x40, // loop
+		0x0c, 0x00, // br 0
+		0x0b,       // end
+		0x42, 0x00, // i64.const 0
+		0x0b, // end
+	}
+)
+
+// dataOffset is the address of the data segment of test plugins
+const dataOffset = 16
+
+// fixedBody returns the data segment as the output
+func fixedBody(size int) []byte {
+	body := append([]byte{0x42}, sleb128(int64(dataOffset)<<32|int64(size))...) // i64.const
+	return append(body, 0x0b)                                                   // end
+}
+
+// wasmModule builds a plugin module exporting memory, octovy_alloc as a bump allocator from 1024 and octovy_evaluate of the body. The data is put at dataOffset.
+func wasmModule(evaluateBody []byte, data []byte) []byte {
+	const i32, i64 = 0x7f, 0x7e
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	section := func(id byte, content ...[]byte) {
+		var payload []byte
+		for _, c := range content {
+			payload = append(payload, c...)
+		}
+		module = append(module, id)
+		module = append(module, uleb128(uint64(len(payload)))...)
+		module = append(module, payload...)
+	}
+	name := func(s string) []byte {
+		return append(uleb128(uint64(len(s))), s...)
+	}
+	code := func(body []byte) []byte {
+		entry := append([]byte{0x00}, body...) // no locals
+		return append(uleb128(uint64(len(entry))), entry...)
+	}
+
+	allocBody := []byte{
+		0x23, 0x00, // global.get 0
+		0x23, 0x00, // global.get 0
+		0x20, 0x00, // local.get 0
+		0x6a,       // i32.add
+		0x24, 0x00, // global.set 0
+		0x0b, // end
+	}
+
+	// (i32) -> i32 and (i32, i32) -> i64
+	section(0x01, []byte{0x02, 0x60, 0x01, i32, 0x01, i32, 0x60, 0x02, i32, i32, 0x01, i64})
+	section(0x03, []byte{0x02, 0x00, 0x01})
+	// 1 page of memory
+	section(0x05, []byte{0x01, 0x00, 0x01})
+	// mutable i32 heap pointer initialized to 1024
+	section(0x06, []byte{0x01, i32, 0x01, 0x41, 0x80, 0x08, 0x0b})
+	section(0x07, []byte{0x03},
+		name("memory"), []byte{0x02, 0x00},
+		name("octovy_alloc"), []byte{0x00, 0x00},
+		name("octovy_evaluate"), []byte{0x00, 0x01},
+	)
+	section(0x0a, []byte{0x02}, code(allocBody), code(evaluateBody))
+	if data != nil {
+		section(0x0b, []byte{0x01, 0x00, 0x41}, sleb128(dataOffset), []byte{0x0b}, name(string(data)))
+	}
+	return module
+}
+
+func uleb128(v uint64) []byte {
+	return binary.AppendUvarint(nil, v)
+}
+
+func sleb128(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	ctx := context.Background()
+	input := &model.GatePolicyInput{
+		Repository: "test-owner/test-repo",
+		Branch:     "main",
+		Pass:       true,
+		Findings: []model.GateViolation{
+			{Target: "go.mod", ID: "CVE-2024-0001", Severity: "HIGH"},
+		},
+	}
+
+	t.Run("input is given to plugin", func(t *testing.T) {
+		plugin := gt.R1(wasmpolicy.New(ctx, "echo", wasmModule(echoBody, nil))).NoError(t)
+		defer plugin.Close(ctx)
+		gt.V(t, plugin.Name()).Equal("echo")
+
+		decision := gt.R1(plugin.Evaluate(ctx, input)).NoError(t)
+		gt.V(t, decision.Policy).Equal("echo")
+		gt.True(t, decision.Pass)
+
+		failed := *input
+		failed.Pass = false
+		decision = gt.R1(plugin.Evaluate(ctx, &failed)).NoError(t)
+		gt.False(t, decision.Pass)
+	})
+
+	t.Run("decision of plugin is returned", func(t *testing.T) {
+		output := `{"pass":false,"reasons":["critical findings in production"]}`
+		plugin := gt.R1(wasmpolicy.New(ctx, "deny", wasmModule(fixedBody(len(output)), []byte(output)))).NoError(t)
+		defer plugin.Close(ctx)
+
+		decision := gt.R1(plugin.Evaluate(ctx, input)).NoError(t)
+		gt.V(t, decision).Equal(&model.GatePolicyDecision{
+			Policy:  "deny",
+			Pass:    false,
+			Reasons: []string{"critical findings in production"},
+		})
+	})
+
+	t.Run("invalid output is an error", func(t *testing.T) {
+		output := `not json`
+		plugin := gt.R1(wasmpolicy.New(ctx, "invalid", wasmModule(fixedBody(len(output)), []byte(output)))).NoError(t)
+		defer plugin.Close(ctx)
+
+		gt.R1(plugin.Evaluate(ctx, input)).Error(t)
+	})
+
+	t.Run("output out of memory is an error", func(t *testing.T) {
+		plugin := gt.R1(wasmpolicy.New(ctx, "out-of-range", wasmModule(fixedBody(1<<17), nil))).NoError(t)
+		defer plugin.Close(ctx)
+
+		gt.R1(plugin.Evaluate(ctx, input)).Error(t)
+	})
+
+	t.Run("trap is an error", func(t *testing.T) {
+		plugin := gt.R1(wasmpolicy.New(ctx, "trap", wasmModule(trapBody, nil))).NoError(t)
+		defer plugin.Close(ctx)
+
+		gt.R1(plugin.Evaluate(ctx, input)).Error(t)
+	})
+
+	t.Run("evaluation times out", func(t *testing.T) {
+		plugin := gt.R1(wasmpolicy.New(ctx, "loop", wasmModule(loopBody, nil), wasmpolicy.WithTimeout(100*time.Millisecond))).NoError(t)
+		defer plugin.Close(ctx)
+
+		gt.R1(plugin.Evaluate(ctx, input)).Error(t)
+	})
+
+	t.Run("module without ABI is rejected", func(t *testing.T) {
+		// A module with no section
+		gt.R1(wasmpolicy.New(ctx, "empty", []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00})).Error(t)
+	})
+
+	t.Run("invalid module is rejected", func(t *testing.T) {
+		gt.R1(wasmpolicy.New(ctx, "invalid", []byte("not wasm"))).Error(t)
+	})
+}
@@ -0,0 +1,297 @@
+package cached
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const (
+	DefaultSize = 1024
+	DefaultTTL  = time.Minute
+)
+
+type entryKind int
+
+const (
+	kindRepository entryKind = iota
+	kindBranch
+	kindVulnerabilities
+)
+
+type cacheKey struct {
+	kind     entryKind
+	repoID   types.GitHubRepoID
+	branch   types.BranchName
+	targetID types.TargetID
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	value     any
+	expiresAt time.Time
+}
+
+// scanRepository is a read-through cache of GetRepository, GetBranch and ListVulnerabilities. Entries are evicted in least recently used order when the cache is full, and expire after the TTL so that writes by other instances are seen eventually. Writes through the cache invalidate entries which they may change. Errors, including repository.ErrNotFound, are not cached.
+type scanRepository struct {
+	interfaces.ScanRepository
+
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	order   *list.List
+	// gen is incremented by every invalidation, so that a value read before an invalidation is not stored after it
+	gen uint64
+}
+
+type Option func(*scanRepository)
+
+// WithSize sets the maximum number of cached entries. A list of vulnerabilities of a target counts as one entry.
+func WithSize(size int) Option {
+	return func(x *scanRepository) {
+		x.size = size
+	}
+}
+
+// WithTTL sets how long an entry is served from the cache
+func WithTTL(ttl time.Duration) Option {
+	return func(x *scanRepository) {
+		x.ttl = ttl
+	}
+}
+
+// New wraps the repository with the cache. It returns nil if repo is nil, so that callers can keep checking whether the repository is configured.
+func New(repo interfaces.ScanRepository, options ...Option) interfaces.ScanRepository {
+	if repo == nil {
+		return nil
+	}
+
+	x := &scanRepository{
+		ScanRepository: repo,
+		size:           DefaultSize,
+		ttl:            DefaultTTL,
+		entries:        make(map[cacheKey]*list.Element),
+		order:          list.New(),
+	}
+	for _, opt := range options {
+		opt(x)
+	}
+	return x
+}
+
+// get returns the cached value of the key and the generation of the cache. The generation must be given to put when the value is read from the underlying repository.
+func (x *scanRepository) get(ctx context.Context, key cacheKey) (any, uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	elem, ok := x.entries[key]
+	if !ok {
+		return nil, x.gen
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !logging.CtxTime(ctx).Before(entry.expiresAt) {
+		x.order.Remove(elem)
+		delete(x.entries, key)
+		return nil, x.gen
+	}
+
+	x.order.MoveToFront(elem)
+	return entry.value, x.gen
+}
+
+func (x *scanRepository) put(ctx context.Context, key cacheKey, value any, gen uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if gen != x.gen || x.size <= 0 {
+		return
+	}
+
+	expiresAt := logging.CtxTime(ctx).Add(x.ttl)
+	if elem, ok := x.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		x.order.MoveToFront(elem)
+		return
+	}
+
+	x.entries[key] = x.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+	for x.order.Len() > x.size {
+		oldest := x.order.Back()
+		x.order.Remove(oldest)
+		delete(x.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// invalidate removes entries of the repository matching the filter
+func (x *scanRepository) invalidate(repoID types.GitHubRepoID, match func(key cacheKey) bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.gen++
+	for key, elem := range x.entries {
+		if key.repoID == repoID && match(key) {
+			x.order.Remove(elem)
+			delete(x.entries, key)
+		}
+	}
+}
+
+func (x *scanRepository) invalidateKey(key cacheKey) {
+	x.invalidate(key.repoID, func(k cacheKey) bool { return k == key })
+}
+
+func repositoryKey(repoID types.GitHubRepoID) cacheKey {
+	return cacheKey{kind: kindRepository, repoID: repoID}
+}
+
+func branchKey(repoID types.GitHubRepoID, branchName types.BranchName) cacheKey {
+	return cacheKey{kind: kindBranch, repoID: repoID, branch: branchName}
+}
+
+func vulnerabilitiesKey(repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) cacheKey {
+	return cacheKey{kind: kindVulnerabilities, repoID: repoID, branch: branchName, targetID: targetID}
+}
+
+// Cached values are shared by callers, so they are copied both when stored and when returned. The copies are shallow: callers may change fields of returned models but must not change slices, maps or pointed values in them.
+func copyRepository(repo *model.Repository) *model.Repository {
+	if repo == nil {
+		return nil
+	}
+	cpy := *repo
+	return &cpy
+}
+
+func copyBranch(branch *model.Branch) *model.Branch {
+	if branch == nil {
+		return nil
+	}
+	cpy := *branch
+	return &cpy
+}
+
+func copyVulnerabilities(vulns []*model.Vulnerability) []*model.Vulnerability {
+	if vulns == nil {
+		return nil
+	}
+	cpy := make([]*model.Vulnerability, len(vulns))
+	for i, vuln := range vulns {
+		if vuln != nil {
+			v := *vuln
+			cpy[i] = &v
+		}
+	}
+	return cpy
+}
+
+func (x *scanRepository) GetRepository(ctx context.Context, repoID types.GitHubRepoID) (*model.Repository, error) {
+	key := repositoryKey(repoID)
+	cached, gen := x.get(ctx, key)
+	if repo, ok := cached.(*model.Repository); ok {
+		return copyRepository(repo), nil
+	}
+
+	repo, err := x.ScanRepository.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	x.put(ctx, key, copyRepository(repo), gen)
+	return repo, nil
+}
+
+func (x *scanRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
+	key := branchKey(repoID, branchName)
+	cached, gen := x.get(ctx, key)
+	if branch, ok := cached.(*model.Branch); ok {
+		return copyBranch(branch), nil
+	}
+
+	branch, err := x.ScanRepository.GetBranch(ctx, repoID, branchName)
+	if err != nil {
+		return nil, err
+	}
+	x.put(ctx, key, copyBranch(branch), gen)
+	return branch, nil
+}
+
+func (x *scanRepository) ListVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Vulnerability, error) {
+	key := vulnerabilitiesKey(repoID, branchName, targetID)
+	cached, gen := x.get(ctx, key)
+	if vulns, ok := cached.([]*model.Vulnerability); ok {
+		return copyVulnerabilities(vulns), nil
+	}
+
+	vulns, err := x.ScanRepository.ListVulnerabilities(ctx, repoID, branchName, targetID)
+	if err != nil {
+		return nil, err
+	}
+	x.put(ctx, key, copyVulnerabilities(vulns), gen)
+	return vulns, nil
+}
+
+// Writes invalidate entries before and after calling the underlying repository. Invalidation before the write keeps a concurrent read from storing a value read before the write, and invalidation after the write drops a value read during the write. Entries are invalidated even if the write fails, because it may have been applied partially.
+
+func (x *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	x.invalidateKey(repositoryKey(repo.ID))
+	defer x.invalidateKey(repositoryKey(repo.ID))
+	return x.ScanRepository.CreateOrUpdateRepository(ctx, repo)
+}
+
+func (x *scanRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	all := func(cacheKey) bool { return true }
+	x.invalidate(repoID, all)
+	defer x.invalidate(repoID, all)
+	return x.ScanRepository.DeleteRepository(ctx, repoID)
+}
+
+func (x *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	x.invalidateKey(branchKey(repoID, branch.Name))
+	defer x.invalidateKey(branchKey(repoID, branch.Name))
+	return x.ScanRepository.CreateOrUpdateBranch(ctx, repoID, branch)
+}
+
+func (x *scanRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	x.invalidateKey(branchKey(repoID, branch.Name))
+	defer x.invalidateKey(branchKey(repoID, branch.Name))
+	return x.ScanRepository.ClaimBranchScan(ctx, repoID, branch)
+}
+
+func (x *scanRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	moved := func(key cacheKey) bool {
+		return key.kind == kindVulnerabilities && key.branch == branchName && (key.targetID == fromID || key.targetID == target.ID)
+	}
+	x.invalidate(repoID, moved)
+	defer x.invalidate(repoID, moved)
+	return x.ScanRepository.MoveTarget(ctx, repoID, branchName, fromID, target)
+}
+
+func (x *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	key := vulnerabilitiesKey(repoID, branchName, targetID)
+	x.invalidateKey(key)
+	defer x.invalidateKey(key)
+	return x.ScanRepository.BatchCreateVulnerabilities(ctx, repoID, branchName, targetID, vulns)
+}
+
+func (x *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
+	key := vulnerabilitiesKey(repoID, branchName, targetID)
+	x.invalidateKey(key)
+	defer x.invalidateKey(key)
+	return x.ScanRepository.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, changes)
+}
+
+func (x *scanRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	key := vulnerabilitiesKey(repoID, branchName, targetID)
+	x.invalidateKey(key)
+	defer x.invalidateKey(key)
+	return x.ScanRepository.BatchDeleteVulnerabilities(ctx, repoID, branchName, targetID, vulnIDs)
+}
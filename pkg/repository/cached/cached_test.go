@@ -0,0 +1,196 @@
+package cached_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/cached"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/repository/testhelper"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// countingRepository counts reads which reach the memory repository
+type countingRepository struct {
+	interfaces.ScanRepository
+	reads int
+}
+
+func (x *countingRepository) GetRepository(ctx context.Context, repoID types.GitHubRepoID) (*model.Repository, error) {
+	x.reads++
+	return x.ScanRepository.GetRepository(ctx, repoID)
+}
+
+func (x *countingRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
+	x.reads++
+	return x.ScanRepository.GetBranch(ctx, repoID, branchName)
+}
+
+func (x *countingRepository) ListVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Vulnerability, error) {
+	x.reads++
+	return x.ScanRepository.ListVulnerabilities(ctx, repoID, branchName, targetID)
+}
+
+func TestCachedScanRepository(t *testing.T) {
+	testhelper.TestAll(t, cached.New(memory.New()))
+}
+
+func setup(t *testing.T, options ...cached.Option) (context.Context, *time.Time, *countingRepository, interfaces.ScanRepository) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+	base := &countingRepository{ScanRepository: memory.New()}
+	for _, name := range []string{"app", "lib"} {
+		repoID := types.NewGitHubRepoID("myorg", name)
+		gt.NoError(t, base.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "myorg", Name: name, DefaultBranch: "main"}))
+		gt.NoError(t, base.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main", LastCommitSHA: "aaa"}))
+		gt.NoError(t, base.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: "t1", Target: "go.mod"}))
+		gt.NoError(t, base.BatchCreateVulnerabilities(ctx, repoID, "main", "t1", []*model.Vulnerability{
+			{ID: "CVE-2024-0001", PkgName: "pkg-a", Status: types.VulnStatusActive},
+		}))
+	}
+
+	return ctx, &now, base, cached.New(base, options...)
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx, _, base, repo := setup(t)
+
+	for range 3 {
+		r := gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+		gt.V(t, r.DefaultBranch).Equal("main")
+		b := gt.R1(repo.GetBranch(ctx, "myorg/app", "main")).NoError(t)
+		gt.V(t, b.LastCommitSHA).Equal("aaa")
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)
+		gt.A(t, vulns).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.PkgName).Equal("pkg-a")
+		})
+	}
+	gt.V(t, base.reads).Equal(3)
+
+	t.Run("returned models are copies", func(t *testing.T) {
+		r := gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+		r.DefaultBranch = "changed"
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)
+		vulns[0].PkgName = "changed"
+
+		gt.V(t, gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t).DefaultBranch).Equal("main")
+		gt.V(t, gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)[0].PkgName).Equal("pkg-a")
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		reads := base.reads
+		for range 2 {
+			_, err := repo.GetBranch(ctx, "myorg/app", "dev")
+			gt.True(t, errors.Is(err, repository.ErrNotFound))
+		}
+		gt.V(t, base.reads).Equal(reads + 2)
+	})
+}
+
+func TestInvalidation(t *testing.T) {
+	ctx, _, base, repo := setup(t)
+	warm := func(targetID types.TargetID) {
+		gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+		gt.R1(repo.GetBranch(ctx, "myorg/app", "main")).NoError(t)
+		gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", targetID)).NoError(t)
+		gt.R1(repo.GetRepository(ctx, "myorg/lib")).NoError(t)
+	}
+
+	t.Run("writes of repository and branch", func(t *testing.T) {
+		warm("t1")
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: "myorg/app", Owner: "myorg", Name: "app", DefaultBranch: "develop"}))
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, "myorg/app", &model.Branch{Name: "main", LastCommitSHA: "bbb"}))
+
+		reads := base.reads
+		gt.V(t, gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t).DefaultBranch).Equal("develop")
+		gt.V(t, gt.R1(repo.GetBranch(ctx, "myorg/app", "main")).NoError(t).LastCommitSHA).Equal("bbb")
+		gt.V(t, base.reads).Equal(reads + 2)
+
+		// Entries of another repository are kept
+		gt.R1(repo.GetRepository(ctx, "myorg/lib")).NoError(t)
+		gt.V(t, base.reads).Equal(reads + 2)
+	})
+
+	t.Run("writes of vulnerabilities", func(t *testing.T) {
+		warm("t1")
+		gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, "myorg/app", "main", "t1", []*model.VulnStatusChange{
+			{VulnID: "CVE-2024-0001", From: types.VulnStatusActive, To: types.VulnStatusFixed, Timestamp: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)},
+		}))
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)
+		gt.V(t, vulns[0].Status).Equal(types.VulnStatusFixed)
+
+		gt.NoError(t, repo.BatchDeleteVulnerabilities(ctx, "myorg/app", "main", "t1", []string{"CVE-2024-0001"}))
+		gt.A(t, gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)).Length(0)
+
+		gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, "myorg/app", "main", "t1", []*model.Vulnerability{
+			{ID: "CVE-2024-0002", PkgName: "pkg-b", Status: types.VulnStatusActive},
+		}))
+		gt.A(t, gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.ID).Equal("CVE-2024-0002")
+		})
+	})
+
+	t.Run("moving target", func(t *testing.T) {
+		gt.A(t, gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")).NoError(t)).Length(1)
+		gt.NoError(t, repo.MoveTarget(ctx, "myorg/app", "main", "t1", &model.Target{ID: "t2", Target: "go.mod"}))
+
+		_, err := repo.ListVulnerabilities(ctx, "myorg/app", "main", "t1")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		gt.A(t, gt.R1(repo.ListVulnerabilities(ctx, "myorg/app", "main", "t2")).NoError(t)).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.ID).Equal("CVE-2024-0002")
+		})
+	})
+
+	t.Run("deleting repository", func(t *testing.T) {
+		warm("t2")
+		gt.NoError(t, repo.DeleteRepository(ctx, "myorg/app"))
+
+		_, err := repo.GetRepository(ctx, "myorg/app")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = repo.GetBranch(ctx, "myorg/app", "main")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = repo.ListVulnerabilities(ctx, "myorg/app", "main", "t2")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+func TestExpiration(t *testing.T) {
+	ctx, now, base, repo := setup(t, cached.WithTTL(time.Minute))
+
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	*now = now.Add(59 * time.Second)
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	gt.V(t, base.reads).Equal(1)
+
+	*now = now.Add(time.Second)
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	gt.V(t, base.reads).Equal(2)
+}
+
+func TestEviction(t *testing.T) {
+	ctx, _, base, repo := setup(t, cached.WithSize(2))
+
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	gt.R1(repo.GetRepository(ctx, "myorg/lib")).NoError(t)
+	// app becomes the most recently used, so that lib is evicted by the branch
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	gt.R1(repo.GetBranch(ctx, "myorg/app", "main")).NoError(t)
+	gt.V(t, base.reads).Equal(3)
+
+	gt.R1(repo.GetRepository(ctx, "myorg/app")).NoError(t)
+	gt.V(t, base.reads).Equal(3)
+	gt.R1(repo.GetRepository(ctx, "myorg/lib")).NoError(t)
+	gt.V(t, base.reads).Equal(4)
+}
+
+func TestNilRepository(t *testing.T) {
+	gt.V(t, cached.New(nil)).Nil()
+}
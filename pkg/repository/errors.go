@@ -1,9 +1,35 @@
 package repository
 
-import "github.com/m-mizutani/goerr/v2"
+import (
+	"fmt"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
 
 var (
 	ErrNotFound      = goerr.New("not found")
 	ErrAlreadyExists = goerr.New("already exists")
 	ErrInvalidInput  = goerr.New("invalid input")
+	// ErrConflict means the operation is rejected because newer data is already stored
+	ErrConflict = goerr.New("conflict")
 )
+
+// VulnStatusUpdateError is returned by BatchUpdateVulnerabilityStatus when some of the changes could not be applied. Changes not listed in Failed have been applied. Use errors.As to retrieve it from a wrapped error.
+type VulnStatusUpdateError struct {
+	Applied int
+	Failed  []*model.FailedVulnStatusChange
+}
+
+func (x *VulnStatusUpdateError) Error() string {
+	return fmt.Sprintf("failed to apply %d of %d vulnerability status changes", len(x.Failed), x.Applied+len(x.Failed))
+}
+
+// FailedChanges returns changes which could not be applied
+func (x *VulnStatusUpdateError) FailedChanges() []*model.VulnStatusChange {
+	changes := make([]*model.VulnStatusChange, len(x.Failed))
+	for i, f := range x.Failed {
+		changes[i] = f.Change
+	}
+	return changes
+}
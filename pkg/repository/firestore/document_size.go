@@ -0,0 +1,106 @@
+package firestore
+
+import (
+	"context"
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const (
+	// maxDocumentBytes is the Firestore document size limit (1 MiB) minus margin for the document name and the difference between JSON and Firestore encoding
+	maxDocumentBytes = 1024*1024 - 64*1024
+
+	// maxBatchBytes keeps a batch commit under the 10 MiB request size limit of Firestore
+	maxBatchBytes = 9 * 1024 * 1024
+
+	maxTruncatedTitle       = 1024
+	maxTruncatedDescription = 16 * 1024
+	maxTruncatedReferences  = 50
+	maxTruncatedReference   = 2048
+)
+
+// documentSize estimates the size of a document by its JSON encoding. Field names of JSON are the same as Firestore because model structs do not have firestore tags.
+func documentSize(v any) (int, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to encode document to estimate size")
+	}
+	return len(raw), nil
+}
+
+// fitVulnerability returns the vulnerability as is if it fits in the document size limit. Otherwise, it stores the full vulnerability to the overflow storage if available, and returns a copy with truncated long fields. It returns nil if the vulnerability does not fit even after truncation, so that the caller skips it instead of failing the batch.
+func fitVulnerability(ctx context.Context, overflow interfaces.ObjectStorage, overflowKey string, vuln *model.Vulnerability) (*model.Vulnerability, int, error) {
+	size, err := documentSize(vuln)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size <= maxDocumentBytes {
+		return vuln, size, nil
+	}
+
+	fitted := *vuln
+	fitted.Truncated = true
+	fitted.Title = truncateString(vuln.Title, maxTruncatedTitle)
+	fitted.Description = truncateString(vuln.Description, maxTruncatedDescription)
+
+	refs := vuln.References
+	if len(refs) > maxTruncatedReferences {
+		refs = refs[:maxTruncatedReferences]
+	}
+	fitted.References = make([]string, len(refs))
+	for i, ref := range refs {
+		fitted.References[i] = truncateString(ref, maxTruncatedReference)
+	}
+
+	if overflow != nil {
+		raw, err := json.Marshal(vuln)
+		if err != nil {
+			return nil, 0, goerr.Wrap(err, "failed to encode overflowed vulnerability", goerr.V("vulnID", vuln.ID))
+		}
+		// Failure of the overflow storage is not fatal because the truncated vulnerability is still stored
+		if uri, err := overflow.Put(ctx, overflowKey, raw); err != nil {
+			logging.From(ctx).Warn("failed to store overflowed vulnerability", "vuln_id", vuln.ID, "key", overflowKey, "error", err)
+		} else {
+			fitted.OverflowURI = uri
+		}
+	}
+
+	fittedSize, err := documentSize(&fitted)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fittedSize > maxDocumentBytes {
+		logging.From(ctx).Warn("skip vulnerability exceeding Firestore document size limit",
+			"vuln_id", vuln.ID,
+			"pkg_name", vuln.PkgName,
+			"size", size,
+			"truncated_size", fittedSize,
+		)
+		return nil, 0, nil
+	}
+
+	logging.From(ctx).Warn("truncated vulnerability to fit in Firestore document size limit",
+		"vuln_id", vuln.ID,
+		"pkg_name", vuln.PkgName,
+		"size", size,
+		"truncated_size", fittedSize,
+		"overflow_uri", fitted.OverflowURI,
+	)
+	return &fitted, fittedSize, nil
+}
+
+// truncateString cuts s to at most n bytes without breaking a UTF-8 character
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,91 @@
+package firestore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/repository/firestore"
+)
+
+func TestFitVulnerability(t *testing.T) {
+	ctx := context.Background()
+	const key = "vulnerability/owner:repo/main/go.mod/CVE-2024-0001.json"
+
+	hugeVuln := func() *model.Vulnerability {
+		refs := make([]string, 200)
+		for i := range refs {
+			refs[i] = "https://example.com/" + strings.Repeat("r", 100)
+		}
+		return &model.Vulnerability{
+			ID:          "CVE-2024-0001",
+			PkgName:     "pkg-a",
+			Title:       "huge",
+			Description: strings.Repeat("あ", firestore.MaxDocumentBytes/3+1),
+			References:  refs,
+		}
+	}
+
+	t.Run("small vulnerability is not modified", func(t *testing.T) {
+		vuln := &model.Vulnerability{ID: "CVE-2024-0001", Description: "small"}
+		fitted, size, err := firestore.FitVulnerability(ctx, nil, key, vuln)
+		gt.NoError(t, err)
+		gt.V(t, fitted).Equal(vuln)
+		gt.True(t, size > 0)
+	})
+
+	t.Run("huge vulnerability is truncated with overflow pointer", func(t *testing.T) {
+		var stored []byte
+		storage := &mock.ObjectStorageMock{
+			PutFunc: func(ctx context.Context, k string, data []byte) (string, error) {
+				gt.V(t, k).Equal(key)
+				stored = data
+				return "gs://bucket/" + k, nil
+			},
+		}
+
+		vuln := hugeVuln()
+		fitted, size, err := firestore.FitVulnerability(ctx, storage, key, vuln)
+		gt.NoError(t, err)
+		gt.True(t, fitted.Truncated)
+		gt.V(t, fitted.OverflowURI).Equal("gs://bucket/" + key)
+		gt.A(t, fitted.References).Length(50)
+		gt.True(t, size <= firestore.MaxDocumentBytes)
+		gt.True(t, len(fitted.Description) < len(vuln.Description))
+		gt.True(t, strings.HasPrefix(vuln.Description, fitted.Description))
+
+		// The original is kept in full in the overflow storage
+		gt.False(t, vuln.Truncated)
+		var full model.Vulnerability
+		gt.NoError(t, json.Unmarshal(stored, &full))
+		gt.V(t, full.Description).Equal(vuln.Description)
+		gt.A(t, full.References).Length(200)
+	})
+
+	t.Run("failure of overflow storage is not fatal", func(t *testing.T) {
+		storage := &mock.ObjectStorageMock{
+			PutFunc: func(ctx context.Context, k string, data []byte) (string, error) {
+				return "", errors.New("unavailable")
+			},
+		}
+
+		fitted, _, err := firestore.FitVulnerability(ctx, storage, key, hugeVuln())
+		gt.NoError(t, err)
+		gt.True(t, fitted.Truncated)
+		gt.V(t, fitted.OverflowURI).Equal("")
+	})
+
+	t.Run("vulnerability not fitting after truncation is skipped", func(t *testing.T) {
+		vuln := hugeVuln()
+		vuln.PkgPath = strings.Repeat("p", firestore.MaxDocumentBytes)
+
+		fitted, _, err := firestore.FitVulnerability(ctx, nil, key, vuln)
+		gt.NoError(t, err)
+		gt.V(t, fitted).Nil()
+	})
+}
@@ -0,0 +1,7 @@
+package firestore
+
+var FitVulnerability = fitVulnerability
+
+const MaxDocumentBytes = maxDocumentBytes
+
+var IsTransientError = isTransientError
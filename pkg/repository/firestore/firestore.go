@@ -8,8 +8,17 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 )
 
+type Option func(*scanRepository)
+
+// WithOverflowStorage makes the repository store a vulnerability to the storage in full when it is truncated to fit in the Firestore document size limit.
+func WithOverflowStorage(storage interfaces.ObjectStorage) Option {
+	return func(x *scanRepository) {
+		x.overflow = storage
+	}
+}
+
 // New creates a new Firestore-based repository
-func New(ctx context.Context, projectID, databaseID string) (interfaces.ScanRepository, error) {
+func New(ctx context.Context, projectID, databaseID string, options ...Option) (interfaces.ScanRepository, error) {
 	var client *firestore.Client
 	var err error
 
@@ -26,7 +35,12 @@ func New(ctx context.Context, projectID, databaseID string) (interfaces.ScanRepo
 		)
 	}
 
-	return &scanRepository{
+	repo := &scanRepository{
 		client: client,
-	}, nil
+	}
+	for _, opt := range options {
+		opt(repo)
+	}
+
+	return repo, nil
 }
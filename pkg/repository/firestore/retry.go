@@ -0,0 +1,56 @@
+package firestore
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxCommitAttempts    = 3
+	initialCommitBackoff = time.Second
+)
+
+// commitWithRetry commits the batch, retrying on transient errors. A batch is applied atomically, so a failed commit applies none of its writes. If the batch creates documents with fixed IDs, AlreadyExists on a retry means the previous attempt was committed although its response was lost.
+func commitWithRetry(ctx context.Context, batch *firestore.WriteBatch) error {
+	backoff := initialCommitBackoff
+	for attempt := 1; ; attempt++ {
+		_, err := batch.Commit(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt > 1 && status.Code(err) == codes.AlreadyExists {
+			return nil
+		}
+		if attempt >= maxCommitAttempts || !isTransientError(err) {
+			return err
+		}
+
+		logging.From(ctx).Warn("Firestore batch commit failed, retrying after backoff",
+			"attempt", attempt,
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return goerr.Wrap(ctx.Err(), "context cancelled during commit retry backoff", goerr.V("last_error", err))
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientError reports whether a failed Firestore request may succeed on retry
+func isTransientError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
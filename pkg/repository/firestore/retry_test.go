@@ -0,0 +1,21 @@
+package firestore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/repository/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransientError(t *testing.T) {
+	gt.True(t, firestore.IsTransientError(status.Error(codes.Unavailable, "unavailable")))
+	gt.True(t, firestore.IsTransientError(status.Error(codes.Aborted, "contention")))
+	gt.True(t, firestore.IsTransientError(goerr.Wrap(status.Error(codes.DeadlineExceeded, "timeout"), "wrapped")))
+	gt.False(t, firestore.IsTransientError(status.Error(codes.NotFound, "no document")))
+	gt.False(t, firestore.IsTransientError(status.Error(codes.InvalidArgument, "too large")))
+	gt.False(t, firestore.IsTransientError(errors.New("unknown")))
+}
@@ -2,13 +2,18 @@ package firestore
 
 import (
 	"context"
+	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,11 +24,21 @@ const (
 	collectionBranch        = "branch"
 	collectionTarget        = "target"
 	collectionVulnerability = "vulnerability"
-	batchSize               = 500
+	collectionPackage       = "package"
+	collectionScan          = "scan"
+	collectionHistory       = "history"
+	// collectionSeverityOverride is a top level collection because overrides apply to all repositories of an owner
+	collectionSeverityOverride = "severity_override"
+	// collectionScanRun is a top level collection because runs are looked up only by their ID
+	collectionScanRun = "scan_run"
+	// collectionOwnerScanRun is a top level collection for the same reason as collectionScanRun
+	collectionOwnerScanRun = "owner_scan_run"
+	batchSize              = 500
 )
 
 type scanRepository struct {
-	client *firestore.Client
+	client   *firestore.Client
+	overflow interfaces.ObjectStorage
 }
 
 // ToFirestoreID converts owner and repo to a Firestore-safe document ID
@@ -55,9 +70,23 @@ func toBranchDocID(branchName string) string {
 	return strings.ReplaceAll(branchName, "/", ":")
 }
 
+// Ping reads at most one repository document, which fails if the database is not reachable or permission is missing
+func (r *scanRepository) Ping(ctx context.Context) error {
+	iter := r.client.Collection(collectionRepo).Limit(1).Documents(ctx)
+	defer iter.Stop()
+	if _, err := iter.Next(); err != nil && err != iterator.Done {
+		return goerr.Wrap(err, "failed to read Firestore")
+	}
+	return nil
+}
+
 // Repository operations
 
 func (r *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	if err := repository.ValidateRepository(repo); err != nil {
+		return err
+	}
+
 	firestoreID, err := ToFirestoreID(repo.Owner, repo.Name)
 	if err != nil {
 		return err
@@ -142,6 +171,9 @@ func (r *scanRepository) ListRepositories(ctx context.Context, installationID in
 	return repos, nil
 }
 
+// ListRepositoriesByOwner returns repositories of the owner sorted by name. The query uses
+// Firestore's automatic single-field index on Owner; sorting is done in memory so that no
+// composite index is required.
 func (r *scanRepository) ListRepositoriesByOwner(ctx context.Context, owner string) ([]*model.Repository, error) {
 	query := r.client.Collection(collectionRepo).Where("Owner", "==", owner)
 
@@ -168,12 +200,96 @@ func (r *scanRepository) ListRepositoriesByOwner(ctx context.Context, owner stri
 		repos = append(repos, &repo)
 	}
 
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+
 	return repos, nil
 }
 
+func (r *scanRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	refs, err := collectDocumentTree(ctx, r.client.Collection(collectionRepo).Doc(firestoreID))
+	if err != nil {
+		return goerr.Wrap(err, "failed to list documents of repository", goerr.V("repoID", repoID))
+	}
+
+	// Descendants are deleted before the repository document, so that the repository is still listed by owner to retry after a partial failure
+	for i := 0; i < len(refs); i += batchSize {
+		end := i + batchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		batch := r.client.Batch()
+		for _, ref := range refs[i:end] {
+			batch.Delete(ref)
+		}
+		if err := commitWithRetry(ctx, batch); err != nil {
+			return goerr.Wrap(err, "failed to batch delete repository documents",
+				goerr.V("repoID", repoID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	return nil
+}
+
+// collectDocumentTree returns all documents under ref followed by ref itself. Subcollections are discovered by listing, so that documents without a parent document are also found.
+func collectDocumentTree(ctx context.Context, ref *firestore.DocumentRef) ([]*firestore.DocumentRef, error) {
+	var refs []*firestore.DocumentRef
+
+	collections := ref.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list collections", goerr.V("path", ref.Path))
+		}
+
+		docs := collection.DocumentRefs(ctx)
+		for {
+			doc, err := docs.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to list documents", goerr.V("path", collection.Path))
+			}
+
+			children, err := collectDocumentTree(ctx, doc)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, children...)
+		}
+	}
+
+	return append(refs, ref), nil
+}
+
 // Branch operations
 
 func (r *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
 		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
@@ -200,6 +316,57 @@ func (r *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.
 	return nil
 }
 
+func (r *scanRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	docRef := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionBranch).Doc(toBranchDocID(string(branch.Name)))
+
+	err = r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(docRef)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return goerr.Wrap(err, "failed to get branch")
+		default:
+			var stored model.Branch
+			if err := snap.DataTo(&stored); err != nil {
+				return goerr.Wrap(err, "failed to decode branch")
+			}
+			if stored.LastScanStartedAt.After(branch.LastScanStartedAt) {
+				return goerr.Wrap(repository.ErrConflict, "newer scan of the branch is stored",
+					goerr.V("storedScanID", stored.LastScanID),
+					goerr.V("storedScanStartedAt", stored.LastScanStartedAt),
+				)
+			}
+		}
+		return tx.Set(docRef, branch)
+	})
+	if err != nil {
+		return goerr.Wrap(err, "failed to claim branch scan",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branch.Name),
+			goerr.V("scanID", branch.LastScanID),
+		)
+	}
+
+	return nil
+}
+
 func (r *scanRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
@@ -284,6 +451,10 @@ func (r *scanRepository) ListBranches(ctx context.Context, repoID types.GitHubRe
 // Target operations
 
 func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
 		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
@@ -312,6 +483,111 @@ func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.
 	return nil
 }
 
+// MoveTarget copies all documents under the target to the new target before deleting them, so that a partial failure leaves the original target and the move can be retried
+func (r *scanRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	targets := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionBranch).Doc(toBranchDocID(string(branchName))).
+		Collection(collectionTarget)
+	fromRef := targets.Doc(string(fromID))
+	toRef := targets.Doc(string(target.ID))
+
+	if _, err := fromRef.Get(ctx); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(repository.ErrNotFound, "target not found",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branchName),
+				goerr.V("targetID", fromID),
+			)
+		}
+		return goerr.Wrap(err, "failed to get target",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", fromID),
+		)
+	}
+
+	refs, err := collectDocumentTree(ctx, fromRef)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list documents of target", goerr.V("targetID", fromID))
+	}
+	// The last one is the target document itself, which is replaced by target
+	descendants := refs[:len(refs)-1]
+
+	for i := 0; i < len(descendants); i += batchSize {
+		end := min(i+batchSize, len(descendants))
+
+		snaps, err := r.client.GetAll(ctx, descendants[i:end])
+		if err != nil {
+			return goerr.Wrap(err, "failed to get documents of target", goerr.V("targetID", fromID))
+		}
+
+		batch := r.client.Batch()
+		for _, snap := range snaps {
+			if !snap.Exists() {
+				continue
+			}
+			batch.Set(rebaseDocRef(snap.Ref, fromRef, toRef), snap.Data())
+		}
+		if err := commitWithRetry(ctx, batch); err != nil {
+			return goerr.Wrap(err, "failed to copy documents of target",
+				goerr.V("targetID", fromID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	if _, err := toRef.Set(ctx, target); err != nil {
+		return goerr.Wrap(err, "failed to create moved target",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", target.ID),
+		)
+	}
+
+	for i := 0; i < len(refs); i += batchSize {
+		end := min(i+batchSize, len(refs))
+
+		batch := r.client.Batch()
+		for _, ref := range refs[i:end] {
+			batch.Delete(ref)
+		}
+		if err := commitWithRetry(ctx, batch); err != nil {
+			return goerr.Wrap(err, "failed to delete documents of moved target",
+				goerr.V("targetID", fromID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	return nil
+}
+
+// rebaseDocRef returns the document at the same relative path from to as ref from from
+func rebaseDocRef(ref, from, to *firestore.DocumentRef) *firestore.DocumentRef {
+	if ref.Path == from.Path {
+		return to
+	}
+	return rebaseDocRef(ref.Parent.Parent, from, to).Collection(ref.Parent.ID).Doc(ref.ID)
+}
+
 func (r *scanRepository) GetTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*model.Target, error) {
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
@@ -446,6 +722,10 @@ func (r *scanRepository) ListVulnerabilities(ctx context.Context, repoID types.G
 }
 
 func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
 		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
@@ -463,15 +743,32 @@ func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID
 		Collection(collectionTarget).Doc(string(targetID)).
 		Collection(collectionVulnerability)
 
-	// Process in batches of 500 (Firestore limit)
-	for i := 0; i < len(vulns); i += batchSize {
-		end := i + batchSize
-		if end > len(vulns) {
-			end = len(vulns)
+	// Each document is checked against the document size limit before it is added to a batch, so that one huge vulnerability does not fail the whole batch
+	docs := make([]*model.Vulnerability, 0, len(vulns))
+	sizes := make([]int, 0, len(vulns))
+	for _, vuln := range vulns {
+		overflowKey := path.Join(collectionVulnerability, firestoreID, toBranchDocID(string(branchName)), string(targetID), vuln.ID+".json")
+		fitted, size, err := fitVulnerability(ctx, r.overflow, overflowKey, vuln)
+		if err != nil {
+			return err
+		}
+		if fitted == nil {
+			continue
+		}
+		docs = append(docs, fitted)
+		sizes = append(sizes, size)
+	}
+
+	// Batches are split by the number of writes (Firestore limit of 500) and by the total size of documents (request size limit)
+	for i := 0; i < len(docs); {
+		end, total := i, 0
+		for end < len(docs) && end-i < batchSize && (end == i || total+sizes[end] <= maxBatchBytes) {
+			total += sizes[end]
+			end++
 		}
 
 		batch := r.client.Batch()
-		for _, vuln := range vulns[i:end] {
+		for _, vuln := range docs[i:end] {
 			docRef := vulnCollection.Doc(vuln.ID)
 			batch.Set(docRef, vuln)
 		}
@@ -485,12 +782,13 @@ func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID
 				goerr.V("batchEnd", end),
 			)
 		}
+		i = end
 	}
 
 	return nil
 }
 
-func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, updates map[string]types.VulnStatus) error {
+func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
 	parts := strings.Split(string(repoID), "/")
 	if len(parts) != 2 {
 		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
@@ -508,34 +806,140 @@ func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, rep
 		Collection(collectionTarget).Doc(string(targetID)).
 		Collection(collectionVulnerability)
 
-	// Convert map to slice for batching
-	type update struct {
-		id     string
-		status types.VulnStatus
+	// Each change writes the status and a history entry in the same batch, so a batch can hold half of the Firestore limit. A chunk failing after retries does not stop the following chunks, and its changes are reported in VulnStatusUpdateError.
+	const changesPerBatch = batchSize / 2
+	updateErr := &repository.VulnStatusUpdateError{}
+	for i := 0; i < len(changes); i += changesPerBatch {
+		end := i + changesPerBatch
+		if end > len(changes) {
+			end = len(changes)
+		}
+
+		batch := r.client.Batch()
+		for _, change := range changes[i:end] {
+			docRef := vulnCollection.Doc(change.VulnID)
+			updates := []firestore.Update{
+				{Path: "Status", Value: change.To},
+				{Path: "UpdatedAt", Value: change.Timestamp},
+			}
+			// The acknowledgement is kept when the vulnerability is fixed so that it applies again on re-detection
+			switch change.To {
+			case types.VulnStatusAcknowledged:
+				updates = append(updates, firestore.Update{Path: "Acknowledgement", Value: change.Acknowledgement})
+			case types.VulnStatusActive:
+				updates = append(updates, firestore.Update{Path: "Acknowledgement", Value: nil})
+			}
+			batch.Update(docRef, updates)
+			// The history document ID is fixed before commit so that a retry does not duplicate the history
+			batch.Create(docRef.Collection(collectionHistory).NewDoc(), change)
+		}
+
+		if err := commitWithRetry(ctx, batch); err != nil {
+			logging.From(ctx).Error("failed to batch update vulnerability status",
+				"repo_id", repoID,
+				"branch", branchName,
+				"target_id", targetID,
+				"batch_start", i,
+				"batch_end", end,
+				"error", err,
+			)
+			for _, change := range changes[i:end] {
+				updateErr.Failed = append(updateErr.Failed, &model.FailedVulnStatusChange{Change: change, Reason: err.Error()})
+			}
+			continue
+		}
+		updateErr.Applied += end - i
+	}
+
+	if len(updateErr.Failed) > 0 {
+		return goerr.Wrap(updateErr, "failed to batch update vulnerability status",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", targetID),
+		)
+	}
+
+	return nil
+}
+
+func (r *scanRepository) ListVulnerabilityHistory(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnID string) ([]*model.VulnStatusChange, error) {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return nil, goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	iter := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionBranch).Doc(toBranchDocID(string(branchName))).
+		Collection(collectionTarget).Doc(string(targetID)).
+		Collection(collectionVulnerability).Doc(vulnID).
+		Collection(collectionHistory).OrderBy("Timestamp", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	var history []*model.VulnStatusChange
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate vulnerability history",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branchName),
+				goerr.V("targetID", targetID),
+				goerr.V("vulnID", vulnID),
+			)
+		}
+
+		var change model.VulnStatusChange
+		if err := snap.DataTo(&change); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode vulnerability history")
+		}
+
+		history = append(history, &change)
 	}
-	var updateList []update
-	for id, status := range updates {
-		updateList = append(updateList, update{id: id, status: status})
+
+	return history, nil
+}
+
+func (r *scanRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
 	}
 
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	vulnCollection := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionBranch).Doc(toBranchDocID(string(branchName))).
+		Collection(collectionTarget).Doc(string(targetID)).
+		Collection(collectionVulnerability)
+
 	// Process in batches of 500 (Firestore limit)
-	for i := 0; i < len(updateList); i += batchSize {
+	for i := 0; i < len(vulnIDs); i += batchSize {
 		end := i + batchSize
-		if end > len(updateList) {
-			end = len(updateList)
+		if end > len(vulnIDs) {
+			end = len(vulnIDs)
 		}
 
 		batch := r.client.Batch()
-		for _, u := range updateList[i:end] {
-			docRef := vulnCollection.Doc(u.id)
-			batch.Update(docRef, []firestore.Update{
-				{Path: "Status", Value: u.status},
-				{Path: "UpdatedAt", Value: firestore.ServerTimestamp},
-			})
+		for _, id := range vulnIDs[i:end] {
+			batch.Delete(vulnCollection.Doc(id))
 		}
 
 		if _, err := batch.Commit(ctx); err != nil {
-			return goerr.Wrap(err, "failed to batch update vulnerability status",
+			return goerr.Wrap(err, "failed to batch delete vulnerabilities",
 				goerr.V("repoID", repoID),
 				goerr.V("branchName", branchName),
 				goerr.V("targetID", targetID),
@@ -547,3 +951,464 @@ func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, rep
 
 	return nil
 }
+
+// ListRepositoryVulnerabilities reads vulnerabilities of each target of each branch, because documents of a collection group can not be filtered by their parent repository
+func (r *scanRepository) ListRepositoryVulnerabilities(ctx context.Context, repoID types.GitHubRepoID) ([]*model.BranchVulnerability, error) {
+	if _, err := r.GetRepository(ctx, repoID); err != nil {
+		return nil, err
+	}
+
+	branches, err := r.ListBranches(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var vulns []*model.BranchVulnerability
+	for _, branch := range branches {
+		targets, err := r.ListTargets(ctx, repoID, branch.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			targetVulns, err := r.ListVulnerabilities(ctx, repoID, branch.Name, target.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, vuln := range targetVulns {
+				vulns = append(vulns, &model.BranchVulnerability{
+					Branch:        branch.Name,
+					Target:        target,
+					Vulnerability: vuln,
+				})
+			}
+		}
+	}
+
+	return vulns, nil
+}
+
+// Package operations
+
+func (r *scanRepository) packageCollection(repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*firestore.CollectionRef, error) {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return nil, goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionBranch).Doc(toBranchDocID(string(branchName))).
+		Collection(collectionTarget).Doc(string(targetID)).
+		Collection(collectionPackage), nil
+}
+
+func (r *scanRepository) ListPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Package, error) {
+	pkgCollection, err := r.packageCollection(repoID, branchName, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := pkgCollection.Documents(ctx)
+	defer iter.Stop()
+
+	var pkgs []*model.Package
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate packages",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branchName),
+				goerr.V("targetID", targetID),
+			)
+		}
+
+		var pkg model.Package
+		if err := snap.DataTo(&pkg); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode package")
+		}
+
+		pkgs = append(pkgs, &pkg)
+	}
+
+	return pkgs, nil
+}
+
+func (r *scanRepository) BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	pkgCollection, err := r.packageCollection(repoID, branchName, targetID)
+	if err != nil {
+		return err
+	}
+
+	// Process in batches of 500 (Firestore limit). A package document is small enough not to hit the request size limit.
+	for i := 0; i < len(pkgs); i += batchSize {
+		end := i + batchSize
+		if end > len(pkgs) {
+			end = len(pkgs)
+		}
+
+		batch := r.client.Batch()
+		for _, pkg := range pkgs[i:end] {
+			batch.Set(pkgCollection.Doc(pkg.ID), pkg)
+		}
+
+		if err := commitWithRetry(ctx, batch); err != nil {
+			return goerr.Wrap(err, "failed to batch upsert packages",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branchName),
+				goerr.V("targetID", targetID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (r *scanRepository) BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error {
+	pkgCollection, err := r.packageCollection(repoID, branchName, targetID)
+	if err != nil {
+		return err
+	}
+
+	// Process in batches of 500 (Firestore limit)
+	for i := 0; i < len(pkgIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(pkgIDs) {
+			end = len(pkgIDs)
+		}
+
+		batch := r.client.Batch()
+		for _, id := range pkgIDs[i:end] {
+			batch.Delete(pkgCollection.Doc(id))
+		}
+
+		if err := commitWithRetry(ctx, batch); err != nil {
+			return goerr.Wrap(err, "failed to batch delete packages",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branchName),
+				goerr.V("targetID", targetID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Scan history operations
+
+func (r *scanRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	if err := repoID.Validate(); err != nil {
+		return err
+	}
+
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	docRef := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionScan).Doc(string(record.ID))
+
+	cpy := *record
+	cpy.RepoID = repoID
+	if _, err := docRef.Set(ctx, &cpy); err != nil {
+		return goerr.Wrap(err, "failed to create scan record",
+			goerr.V("repoID", repoID),
+			goerr.V("scanID", record.ID),
+		)
+	}
+
+	return nil
+}
+
+// ListScanRecords returns the scan history of the repository, newest first
+func (r *scanRepository) ListScanRecords(ctx context.Context, repoID types.GitHubRepoID) ([]*model.ScanRecord, error) {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return nil, goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	iter := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionScan).OrderBy("Timestamp", firestore.Desc).Documents(ctx)
+	defer iter.Stop()
+
+	var records []*model.ScanRecord
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate scan records",
+				goerr.V("repoID", repoID),
+			)
+		}
+
+		var record model.ScanRecord
+		if err := snap.DataTo(&record); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode scan record")
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+// ListScanRecordsByCommitter queries scan records of all repositories with a collection group query. It requires a composite index of CommitterLogin (ascending) and Timestamp (descending) on the scan collection group.
+func (r *scanRepository) ListScanRecordsByCommitter(ctx context.Context, login string, since time.Time) ([]*model.ScanRecord, error) {
+	iter := r.client.CollectionGroup(collectionScan).
+		Where("CommitterLogin", "==", login).
+		Where("Timestamp", ">=", since).
+		OrderBy("Timestamp", firestore.Desc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var records []*model.ScanRecord
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate scan records by committer",
+				goerr.V("login", login),
+			)
+		}
+
+		var record model.ScanRecord
+		if err := snap.DataTo(&record); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode scan record")
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+func (r *scanRepository) BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error {
+	parts := strings.Split(string(repoID), "/")
+	if len(parts) != 2 {
+		return goerr.Wrap(repository.ErrInvalidInput, "invalid repoID format",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	firestoreID, err := ToFirestoreID(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+
+	scanCollection := r.client.Collection(collectionRepo).Doc(firestoreID).
+		Collection(collectionScan)
+
+	// Process in batches of 500 (Firestore limit)
+	for i := 0; i < len(scanIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(scanIDs) {
+			end = len(scanIDs)
+		}
+
+		batch := r.client.Batch()
+		for _, id := range scanIDs[i:end] {
+			batch.Delete(scanCollection.Doc(string(id)))
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			return goerr.Wrap(err, "failed to batch delete scan records",
+				goerr.V("repoID", repoID),
+				goerr.V("batchStart", i),
+				goerr.V("batchEnd", end),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Severity override operations
+
+func (r *scanRepository) severityOverrideDoc(owner, overrideID string) (*firestore.DocumentRef, error) {
+	// Owner and ID do not contain ':', so the same form as repository IDs is used
+	docID, err := ToFirestoreID(owner, overrideID)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.Collection(collectionSeverityOverride).Doc(docID), nil
+}
+
+func (r *scanRepository) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error {
+	docRef, err := r.severityOverrideDoc(override.Owner, override.ID())
+	if err != nil {
+		return err
+	}
+
+	if _, err := docRef.Set(ctx, override); err != nil {
+		return goerr.Wrap(err, "failed to put severity override",
+			goerr.V("owner", override.Owner),
+			goerr.V("vulnID", override.VulnID),
+			goerr.V("pkgName", override.PkgName),
+		)
+	}
+
+	return nil
+}
+
+// ListSeverityOverrides returns overrides of the owner sorted by vulnerability ID and package name. Sorting is done in memory so that no composite index is required.
+func (r *scanRepository) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	iter := r.client.Collection(collectionSeverityOverride).Where("Owner", "==", owner).Documents(ctx)
+	defer iter.Stop()
+
+	var overrides []*model.SeverityOverride
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate severity overrides",
+				goerr.V("owner", owner),
+			)
+		}
+
+		var override model.SeverityOverride
+		if err := snap.DataTo(&override); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode severity override")
+		}
+
+		overrides = append(overrides, &override)
+	}
+
+	model.SortSeverityOverrides(overrides)
+	return overrides, nil
+}
+
+func (r *scanRepository) DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error {
+	docRef, err := r.severityOverrideDoc(owner, overrideID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := docRef.Delete(ctx, firestore.Exists); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(repository.ErrNotFound, "severity override not found",
+				goerr.V("owner", owner),
+				goerr.V("overrideID", overrideID),
+			)
+		}
+		return goerr.Wrap(err, "failed to delete severity override",
+			goerr.V("owner", owner),
+			goerr.V("overrideID", overrideID),
+		)
+	}
+
+	return nil
+}
+
+// Scan run operations
+
+func (r *scanRepository) PutScanRun(ctx context.Context, run *model.ScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "scan run ID is empty")
+	}
+
+	if _, err := r.client.Collection(collectionScanRun).Doc(string(run.ID)).Set(ctx, run); err != nil {
+		return goerr.Wrap(err, "failed to put scan run",
+			goerr.V("runID", run.ID),
+		)
+	}
+
+	return nil
+}
+
+func (r *scanRepository) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	// An empty or nested path is not a document of the collection
+	if runID == "" || strings.Contains(string(runID), "/") {
+		return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found", goerr.V("runID", runID))
+	}
+
+	snap, err := r.client.Collection(collectionScanRun).Doc(string(runID)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found", goerr.V("runID", runID))
+		}
+		return nil, goerr.Wrap(err, "failed to get scan run", goerr.V("runID", runID))
+	}
+
+	var run model.ScanRun
+	if err := snap.DataTo(&run); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode scan run", goerr.V("runID", runID))
+	}
+
+	return &run, nil
+}
+
+// Owner scan run operations
+
+func (r *scanRepository) PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "owner scan run ID is empty")
+	}
+
+	if _, err := r.client.Collection(collectionOwnerScanRun).Doc(string(run.ID)).Set(ctx, run); err != nil {
+		return goerr.Wrap(err, "failed to put owner scan run",
+			goerr.V("runID", run.ID),
+		)
+	}
+
+	return nil
+}
+
+func (r *scanRepository) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	// An empty or nested path is not a document of the collection
+	if runID == "" || strings.Contains(string(runID), "/") {
+		return nil, goerr.Wrap(repository.ErrNotFound, "owner scan run not found", goerr.V("runID", runID))
+	}
+
+	snap, err := r.client.Collection(collectionOwnerScanRun).Doc(string(runID)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(repository.ErrNotFound, "owner scan run not found", goerr.V("runID", runID))
+		}
+		return nil, goerr.Wrap(err, "failed to get owner scan run", goerr.V("runID", runID))
+	}
+
+	var run model.OwnerScanRun
+	if err := snap.DataTo(&run); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode owner scan run", goerr.V("runID", runID))
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// snapshot is the JSON file format of a persisted repository. Maps keep keys of the in-memory repository as they are.
+type snapshot struct {
+	Repos     map[string]*repoSnapshot                      `json:"repos"`
+	Overrides map[string]map[string]*model.SeverityOverride `json:"severity_overrides"`
+	Runs      map[types.ScanRunID]*model.ScanRun            `json:"scan_runs"`
+	OwnerRuns map[types.OwnerScanRunID]*model.OwnerScanRun  `json:"owner_scan_runs"`
+}
+
+type repoSnapshot struct {
+	Repository *model.Repository            `json:"repository"`
+	Branches   map[string]*branchSnapshot   `json:"branches"`
+	Scans      map[string]*model.ScanRecord `json:"scans"`
+}
+
+type branchSnapshot struct {
+	Branch  *model.Branch              `json:"branch"`
+	Targets map[string]*targetSnapshot `json:"targets"`
+}
+
+type targetSnapshot struct {
+	Target          *model.Target                        `json:"target"`
+	Vulnerabilities map[string]*model.Vulnerability      `json:"vulnerabilities"`
+	Packages        map[string]*model.Package            `json:"packages"`
+	History         map[string][]*model.VulnStatusChange `json:"history"`
+}
+
+// fileRepository is an in-memory repository which is loaded from a JSON file and written back to it after every successful write, so that data survives restarts of a local server. The file is replaced atomically and must not be shared by processes running at the same time, because each of them overwrites changes of others.
+type fileRepository struct {
+	*scanRepository
+
+	path string
+	// flushMu serializes flushes so that a stale snapshot never replaces a newer one
+	flushMu sync.Mutex
+}
+
+// NewFile creates an in-memory repository persisted to a JSON file at path. Data of the file is loaded if it exists, and the file is created by the first write otherwise.
+func NewFile(path string) (interfaces.ScanRepository, error) {
+	repo := &fileRepository{
+		scanRepository: New().(*scanRepository),
+		path:           path,
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return repo, nil
+		}
+		return nil, goerr.Wrap(err, "failed to read repository file", goerr.V("path", path))
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse repository file", goerr.V("path", path))
+	}
+	repo.restore(&snap)
+
+	return repo, nil
+}
+
+func (r *scanRepository) restore(snap *snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, rs := range snap.Repos {
+		data := &repoData{
+			repo:     rs.Repository,
+			branches: make(map[string]*branchData),
+			scans:    make(map[string]*model.ScanRecord),
+		}
+		for name, bs := range rs.Branches {
+			branch := &branchData{
+				branch:  bs.Branch,
+				targets: make(map[string]*targetData),
+			}
+			for targetID, ts := range bs.Targets {
+				target := &targetData{
+					target:  ts.Target,
+					vulns:   make(map[string]*model.Vulnerability),
+					pkgs:    make(map[string]*model.Package),
+					history: make(map[string][]*model.VulnStatusChange),
+				}
+				for k, v := range ts.Vulnerabilities {
+					target.vulns[k] = v
+				}
+				for k, v := range ts.Packages {
+					target.pkgs[k] = v
+				}
+				for k, v := range ts.History {
+					target.history[k] = v
+				}
+				branch.targets[targetID] = target
+			}
+			data.branches[name] = branch
+		}
+		for k, v := range rs.Scans {
+			data.scans[k] = v
+		}
+		r.repos[id] = data
+	}
+	for owner, overrides := range snap.Overrides {
+		if overrides != nil {
+			r.overrides[owner] = overrides
+		}
+	}
+	for id, run := range snap.Runs {
+		r.runs[id] = run
+	}
+	for id, run := range snap.OwnerRuns {
+		r.ownerRuns[id] = run
+	}
+}
+
+func (r *scanRepository) snapshot() ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snap := snapshot{
+		Repos:     make(map[string]*repoSnapshot, len(r.repos)),
+		Overrides: r.overrides,
+		Runs:      r.runs,
+		OwnerRuns: r.ownerRuns,
+	}
+	for id, data := range r.repos {
+		rs := &repoSnapshot{
+			Repository: data.repo,
+			Branches:   make(map[string]*branchSnapshot, len(data.branches)),
+			Scans:      data.scans,
+		}
+		for name, branch := range data.branches {
+			bs := &branchSnapshot{
+				Branch:  branch.branch,
+				Targets: make(map[string]*targetSnapshot, len(branch.targets)),
+			}
+			for targetID, target := range branch.targets {
+				bs.Targets[targetID] = &targetSnapshot{
+					Target:          target.target,
+					Vulnerabilities: target.vulns,
+					Packages:        target.pkgs,
+					History:         target.history,
+				}
+			}
+			rs.Branches[name] = bs
+		}
+		snap.Repos[id] = rs
+	}
+
+	raw, err := json.Marshal(&snap)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal repository snapshot")
+	}
+	return raw, nil
+}
+
+// flush writes the current data to a temporary file and renames it to the path, so that a crash during the write does not break the file
+func (r *fileRepository) flush() error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	raw, err := r.snapshot()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return goerr.Wrap(err, "failed to create temp file of repository", goerr.V("path", r.path))
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return goerr.Wrap(err, "failed to write repository file", goerr.V("path", tmp.Name()))
+	}
+	if err := tmp.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close repository file", goerr.V("path", tmp.Name()))
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return goerr.Wrap(err, "failed to replace repository file", goerr.V("path", r.path))
+	}
+	return nil
+}
+
+// persist flushes data if the write succeeded
+func (r *fileRepository) persist(err error) error {
+	if err != nil {
+		return err
+	}
+	return r.flush()
+}
+
+func (r *fileRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	return r.persist(r.scanRepository.CreateOrUpdateRepository(ctx, repo))
+}
+
+func (r *fileRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	return r.persist(r.scanRepository.DeleteRepository(ctx, repoID))
+}
+
+func (r *fileRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	return r.persist(r.scanRepository.CreateOrUpdateBranch(ctx, repoID, branch))
+}
+
+func (r *fileRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	return r.persist(r.scanRepository.ClaimBranchScan(ctx, repoID, branch))
+}
+
+func (r *fileRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	return r.persist(r.scanRepository.CreateOrUpdateTarget(ctx, repoID, branchName, target))
+}
+
+func (r *fileRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	return r.persist(r.scanRepository.MoveTarget(ctx, repoID, branchName, fromID, target))
+}
+
+func (r *fileRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	return r.persist(r.scanRepository.BatchCreateVulnerabilities(ctx, repoID, branchName, targetID, vulns))
+}
+
+func (r *fileRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
+	return r.persist(r.scanRepository.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, changes))
+}
+
+func (r *fileRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	return r.persist(r.scanRepository.BatchDeleteVulnerabilities(ctx, repoID, branchName, targetID, vulnIDs))
+}
+
+func (r *fileRepository) BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error {
+	return r.persist(r.scanRepository.BatchUpsertPackages(ctx, repoID, branchName, targetID, pkgs))
+}
+
+func (r *fileRepository) BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error {
+	return r.persist(r.scanRepository.BatchDeletePackages(ctx, repoID, branchName, targetID, pkgIDs))
+}
+
+func (r *fileRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	return r.persist(r.scanRepository.CreateScanRecord(ctx, repoID, record))
+}
+
+func (r *fileRepository) BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error {
+	return r.persist(r.scanRepository.BatchDeleteScanRecords(ctx, repoID, scanIDs))
+}
+
+func (r *fileRepository) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error {
+	return r.persist(r.scanRepository.PutSeverityOverride(ctx, override))
+}
+
+func (r *fileRepository) DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error {
+	return r.persist(r.scanRepository.DeleteSeverityOverride(ctx, owner, overrideID))
+}
+
+func (r *fileRepository) PutScanRun(ctx context.Context, run *model.ScanRun) error {
+	return r.persist(r.scanRepository.PutScanRun(ctx, run))
+}
+
+func (r *fileRepository) PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error {
+	return r.persist(r.scanRepository.PutOwnerScanRun(ctx, run))
+}
+
+// Ping returns an error if the directory of the file is not accessible
+func (r *fileRepository) Ping(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Dir(r.path)); err != nil {
+		return goerr.Wrap(err, "directory of repository file is not accessible", goerr.V("path", r.path))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/repository/testhelper"
+)
+
+func TestFileScanRepository(t *testing.T) {
+	repo := gt.R1(memory.NewFile(filepath.Join(t.TempDir(), "octovy.json"))).NoError(t)
+	testhelper.TestAll(t, repo)
+}
+
+func TestFileScanRepositoryReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "octovy.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	repoID := types.NewGitHubRepoID("octo", "app")
+	branch := types.BranchName("main")
+	targetID := types.TargetID("go.mod")
+
+	repo := gt.R1(memory.NewFile(path)).NoError(t)
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:        repoID,
+		Owner:     "octo",
+		Name:      "app",
+		Tags:      []string{"prod"},
+		CreatedAt: now,
+	}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: branch, LastScanAt: now}))
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, branch, &model.Target{ID: targetID}))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, branch, targetID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", PkgName: "example.com/lib", Severity: "HIGH", Status: types.VulnStatusActive},
+	}))
+	gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branch, targetID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2024-0001", From: types.VulnStatusActive, To: types.VulnStatusAcknowledged, Actor: "octocat", Timestamp: now},
+	}))
+	gt.NoError(t, repo.PutScanRun(ctx, &model.ScanRun{ID: "run-1", Status: types.ScanRunSucceeded, CreatedAt: now}))
+
+	t.Run("data is loaded from the file", func(t *testing.T) {
+		reloaded := gt.R1(memory.NewFile(path)).NoError(t)
+
+		got := gt.R1(reloaded.GetRepository(ctx, repoID)).NoError(t)
+		gt.V(t, got.Tags).Equal([]string{"prod"})
+		gt.True(t, got.CreatedAt.Equal(now))
+
+		gt.R1(reloaded.GetBranch(ctx, repoID, branch)).NoError(t)
+
+		vulns := gt.R1(reloaded.ListVulnerabilities(ctx, repoID, branch, targetID)).NoError(t)
+		gt.A(t, vulns).Length(1)
+		gt.V(t, vulns[0].Status).Equal(types.VulnStatusAcknowledged)
+
+		history := gt.R1(reloaded.ListVulnerabilityHistory(ctx, repoID, branch, targetID, "CVE-2024-0001")).NoError(t)
+		gt.A(t, history).Length(1)
+
+		run := gt.R1(reloaded.GetScanRun(ctx, "run-1")).NoError(t)
+		gt.V(t, run.Status).Equal(types.ScanRunSucceeded)
+	})
+
+	t.Run("deletion is persisted", func(t *testing.T) {
+		gt.NoError(t, repo.DeleteRepository(ctx, repoID))
+
+		reloaded := gt.R1(memory.NewFile(path)).NoError(t)
+		_, err := reloaded.GetRepository(ctx, repoID)
+		gt.Error(t, err)
+	})
+
+	t.Run("no temporary file is left", func(t *testing.T) {
+		entries := gt.R1(os.ReadDir(filepath.Dir(path))).NoError(t)
+		gt.A(t, entries).Length(1)
+	})
+}
+
+func TestFileScanRepositoryInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "octovy.json")
+	gt.NoError(t, os.WriteFile(path, []byte("{broken"), 0600))
+
+	_, err := memory.NewFile(path)
+	gt.Error(t, err)
+}
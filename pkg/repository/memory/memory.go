@@ -1,10 +1,17 @@
 package memory
 
-import "github.com/m-mizutani/octovy/pkg/domain/interfaces"
+import (
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
 
 // New creates a new in-memory repository
 func New() interfaces.ScanRepository {
 	return &scanRepository{
-		repos:   make(map[string]*repoData),
+		repos:     make(map[string]*repoData),
+		overrides: make(map[string]map[string]*model.SeverityOverride),
+		runs:      make(map[types.ScanRunID]*model.ScanRun),
+		ownerRuns: make(map[types.OwnerScanRunID]*model.OwnerScanRun),
 	}
 }
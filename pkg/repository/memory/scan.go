@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 type repoData struct {
 	repo     *model.Repository
 	branches map[string]*branchData
+	scans    map[string]*model.ScanRecord
 }
 
 type branchData struct {
@@ -24,16 +26,31 @@ type branchData struct {
 type targetData struct {
 	target *model.Target
 	vulns  map[string]*model.Vulnerability
+	pkgs   map[string]*model.Package
+	// history is kept apart from vulns so that it survives deletion of vulnerabilities
+	history map[string][]*model.VulnStatusChange
 }
 
 type scanRepository struct {
 	mu    sync.RWMutex
 	repos map[string]*repoData
+	// overrides is severity overrides by owner and override ID
+	overrides map[string]map[string]*model.SeverityOverride
+	runs      map[types.ScanRunID]*model.ScanRun
+	ownerRuns map[types.OwnerScanRunID]*model.OwnerScanRun
+}
+
+func (r *scanRepository) Ping(ctx context.Context) error {
+	return nil
 }
 
 // Repository operations
 
 func (r *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	if err := repository.ValidateRepository(repo); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -41,6 +58,7 @@ func (r *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *mod
 		r.repos[string(repo.ID)] = &repoData{
 			repo:     copyRepository(repo),
 			branches: make(map[string]*branchData),
+			scans:    make(map[string]*model.ScanRecord),
 		}
 	} else {
 		r.repos[string(repo.ID)].repo = copyRepository(repo)
@@ -88,12 +106,29 @@ func (r *scanRepository) ListRepositoriesByOwner(ctx context.Context, owner stri
 		}
 	}
 
+	// Sort by name to return the same order as the Firestore implementation
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+
 	return repos, nil
 }
 
+func (r *scanRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.repos, string(repoID))
+	return nil
+}
+
 // Branch operations
 
 func (r *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -117,6 +152,42 @@ func (r *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.
 	return nil
 }
 
+func (r *scanRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	branchName := string(branch.Name)
+	stored, exists := data.branches[branchName]
+	if !exists {
+		data.branches[branchName] = &branchData{
+			branch:  copyBranch(branch),
+			targets: make(map[string]*targetData),
+		}
+		return nil
+	}
+	if stored.branch.LastScanStartedAt.After(branch.LastScanStartedAt) {
+		return goerr.Wrap(repository.ErrConflict, "newer scan of the branch is stored",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branch.Name),
+			goerr.V("storedScanID", stored.branch.LastScanID),
+		)
+	}
+	stored.branch = copyBranch(branch)
+
+	return nil
+}
+
 func (r *scanRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -161,6 +232,10 @@ func (r *scanRepository) ListBranches(ctx context.Context, repoID types.GitHubRe
 // Target operations
 
 func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -182,8 +257,10 @@ func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.
 	targetID := string(target.ID)
 	if _, exists := branchData.targets[targetID]; !exists {
 		branchData.targets[targetID] = &targetData{
-			target: copyTarget(target),
-			vulns:  make(map[string]*model.Vulnerability),
+			target:  copyTarget(target),
+			vulns:   make(map[string]*model.Vulnerability),
+			pkgs:    make(map[string]*model.Package),
+			history: make(map[string][]*model.VulnStatusChange),
 		}
 	} else {
 		branchData.targets[targetID].target = copyTarget(target)
@@ -192,6 +269,45 @@ func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.
 	return nil
 }
 
+func (r *scanRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	branchData, exists := data.branches[string(branchName)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "branch not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+		)
+	}
+
+	targetData, exists := branchData.targets[string(fromID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "target not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", fromID),
+		)
+	}
+
+	delete(branchData.targets, string(fromID))
+	targetData.target = copyTarget(target)
+	branchData.targets[string(target.ID)] = targetData
+
+	return nil
+}
+
 func (r *scanRepository) GetTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*model.Target, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -289,6 +405,10 @@ func (r *scanRepository) ListVulnerabilities(ctx context.Context, repoID types.G
 }
 
 func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -323,7 +443,7 @@ func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID
 	return nil
 }
 
-func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, updates map[string]types.VulnStatus) error {
+func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -351,16 +471,397 @@ func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, rep
 		)
 	}
 
-	for vulnID, status := range updates {
-		if vuln, exists := targetData.vulns[vulnID]; exists {
-			vuln.Status = status
-			vuln.UpdatedAt = time.Now()
+	for _, change := range changes {
+		vuln, exists := targetData.vulns[change.VulnID]
+		if !exists {
+			continue
 		}
+		vuln.Status = change.To
+		vuln.Acknowledgement = nextAcknowledgement(vuln.Acknowledgement, change)
+		vuln.UpdatedAt = change.Timestamp
+		targetData.history[change.VulnID] = append(targetData.history[change.VulnID], copyVulnStatusChange(change))
 	}
 
 	return nil
 }
 
+// nextAcknowledgement returns the acknowledgement after the change. It is kept when the vulnerability is fixed so that it applies again on re-detection.
+func nextAcknowledgement(current *model.Acknowledgement, change *model.VulnStatusChange) *model.Acknowledgement {
+	switch change.To {
+	case types.VulnStatusAcknowledged:
+		return copyAcknowledgement(change.Acknowledgement)
+	case types.VulnStatusActive:
+		return nil
+	default:
+		return current
+	}
+}
+
+func (r *scanRepository) ListVulnerabilityHistory(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnID string) ([]*model.VulnStatusChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return nil, nil
+	}
+	branchData, exists := data.branches[string(branchName)]
+	if !exists {
+		return nil, nil
+	}
+	targetData, exists := branchData.targets[string(targetID)]
+	if !exists {
+		return nil, nil
+	}
+
+	var history []*model.VulnStatusChange
+	for _, change := range targetData.history[vulnID] {
+		history = append(history, copyVulnStatusChange(change))
+	}
+	return history, nil
+}
+
+func (r *scanRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	branchData, exists := data.branches[string(branchName)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "branch not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+		)
+	}
+
+	targetData, exists := branchData.targets[string(targetID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "target not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", targetID),
+		)
+	}
+
+	for _, vulnID := range vulnIDs {
+		delete(targetData.vulns, vulnID)
+	}
+
+	return nil
+}
+
+func (r *scanRepository) ListRepositoryVulnerabilities(ctx context.Context, repoID types.GitHubRepoID) ([]*model.BranchVulnerability, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	var vulns []*model.BranchVulnerability
+	for _, branchData := range data.branches {
+		for _, targetData := range branchData.targets {
+			for _, vuln := range targetData.vulns {
+				vulns = append(vulns, &model.BranchVulnerability{
+					Branch:        branchData.branch.Name,
+					Target:        copyTarget(targetData.target),
+					Vulnerability: copyVulnerability(vuln),
+				})
+			}
+		}
+	}
+
+	return vulns, nil
+}
+
+// Package operations
+
+func (r *scanRepository) ListPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Package, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	targetData, err := r.getTargetData(repoID, branchName, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []*model.Package
+	for _, pkg := range targetData.pkgs {
+		pkgs = append(pkgs, copyPackage(pkg))
+	}
+
+	return pkgs, nil
+}
+
+func (r *scanRepository) BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targetData, err := r.getTargetData(repoID, branchName, targetID)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		targetData.pkgs[pkg.ID] = copyPackage(pkg)
+	}
+
+	return nil
+}
+
+func (r *scanRepository) BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targetData, err := r.getTargetData(repoID, branchName, targetID)
+	if err != nil {
+		return err
+	}
+
+	for _, pkgID := range pkgIDs {
+		delete(targetData.pkgs, pkgID)
+	}
+
+	return nil
+}
+
+// getTargetData returns the target data. The caller must hold the lock.
+func (r *scanRepository) getTargetData(repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*targetData, error) {
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	branchData, exists := data.branches[string(branchName)]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "branch not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+		)
+	}
+
+	targetData, exists := branchData.targets[string(targetID)]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "target not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", targetID),
+		)
+	}
+
+	return targetData, nil
+}
+
+// Scan history operations
+
+func (r *scanRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	if err := repoID.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	cpy := *record
+	cpy.RepoID = repoID
+	data.scans[string(record.ID)] = &cpy
+
+	return nil
+}
+
+func (r *scanRepository) ListScanRecords(ctx context.Context, repoID types.GitHubRepoID) ([]*model.ScanRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	var records []*model.ScanRecord
+	for _, record := range data.scans {
+		cpy := *record
+		records = append(records, &cpy)
+	}
+
+	// Newest first, same as the Firestore implementation
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+
+	return records, nil
+}
+
+func (r *scanRepository) ListScanRecordsByCommitter(ctx context.Context, login string, since time.Time) ([]*model.ScanRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []*model.ScanRecord
+	for _, data := range r.repos {
+		for _, record := range data.scans {
+			if record.CommitterLogin != login || record.Timestamp.Before(since) {
+				continue
+			}
+			cpy := *record
+			records = append(records, &cpy)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+
+	return records, nil
+}
+
+func (r *scanRepository) BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.repos[string(repoID)]
+	if !exists {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+
+	for _, scanID := range scanIDs {
+		delete(data.scans, string(scanID))
+	}
+
+	return nil
+}
+
+// Severity override operations
+
+func (r *scanRepository) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owned, exists := r.overrides[override.Owner]
+	if !exists {
+		owned = make(map[string]*model.SeverityOverride)
+		r.overrides[override.Owner] = owned
+	}
+	cpy := *override
+	owned[override.ID()] = &cpy
+
+	return nil
+}
+
+func (r *scanRepository) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var overrides []*model.SeverityOverride
+	for _, override := range r.overrides[owner] {
+		cpy := *override
+		overrides = append(overrides, &cpy)
+	}
+	model.SortSeverityOverrides(overrides)
+
+	return overrides, nil
+}
+
+func (r *scanRepository) DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.overrides[owner][overrideID]; !exists {
+		return goerr.Wrap(repository.ErrNotFound, "severity override not found",
+			goerr.V("owner", owner),
+			goerr.V("overrideID", overrideID),
+		)
+	}
+	delete(r.overrides[owner], overrideID)
+
+	return nil
+}
+
+// Scan run operations
+
+func (r *scanRepository) PutScanRun(ctx context.Context, run *model.ScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "scan run ID is empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cpy := *run
+	r.runs[run.ID] = &cpy
+
+	return nil
+}
+
+func (r *scanRepository) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, exists := r.runs[runID]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found",
+			goerr.V("runID", runID),
+		)
+	}
+	cpy := *run
+
+	return &cpy, nil
+}
+
+// Owner scan run operations
+
+func (r *scanRepository) PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "owner scan run ID is empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ownerRuns[run.ID] = copyOwnerScanRun(run)
+
+	return nil
+}
+
+func (r *scanRepository) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, exists := r.ownerRuns[runID]
+	if !exists {
+		return nil, goerr.Wrap(repository.ErrNotFound, "owner scan run not found",
+			goerr.V("runID", runID),
+		)
+	}
+
+	return copyOwnerScanRun(run), nil
+}
+
 // Helper functions for deep copy
 
 func copyRepository(repo *model.Repository) *model.Repository {
@@ -368,6 +869,19 @@ func copyRepository(repo *model.Repository) *model.Repository {
 		return nil
 	}
 	cpy := *repo
+	if repo.Tags != nil {
+		cpy.Tags = append([]string{}, repo.Tags...)
+	}
+	if repo.ScanConfig != nil {
+		cfg := *repo.ScanConfig
+		cfg.Paths = append([]string(nil), cfg.Paths...)
+		cfg.Exclude = append([]string(nil), cfg.Exclude...)
+		cpy.ScanConfig = &cfg
+	}
+	if repo.PullRequestCheck != nil {
+		cfg := *repo.PullRequestCheck
+		cpy.PullRequestCheck = &cfg
+	}
 	return &cpy
 }
 
@@ -411,5 +925,45 @@ func copyVulnerability(vuln *model.Vulnerability) *model.Vulnerability {
 		}
 	}
 
+	cpy.Acknowledgement = copyAcknowledgement(vuln.Acknowledgement)
+
+	return &cpy
+}
+
+func copyPackage(pkg *model.Package) *model.Package {
+	cpy := *pkg
+	if pkg.Licenses != nil {
+		cpy.Licenses = append([]string{}, pkg.Licenses...)
+	}
+	return &cpy
+}
+
+func copyAcknowledgement(ack *model.Acknowledgement) *model.Acknowledgement {
+	if ack == nil {
+		return nil
+	}
+	cpy := *ack
+	return &cpy
+}
+
+func copyVulnStatusChange(change *model.VulnStatusChange) *model.VulnStatusChange {
+	cpy := *change
+	cpy.Acknowledgement = copyAcknowledgement(change.Acknowledgement)
+	return &cpy
+}
+
+func copyOwnerScanRun(run *model.OwnerScanRun) *model.OwnerScanRun {
+	cpy := *run
+	if run.Repositories != nil {
+		cpy.Repositories = make([]*model.OwnerScanRepoResult, len(run.Repositories))
+		for i, result := range run.Repositories {
+			r := *result
+			if result.Findings != nil {
+				findings := *result.Findings
+				r.Findings = &findings
+			}
+			cpy.Repositories[i] = &r
+		}
+	}
 	return &cpy
 }
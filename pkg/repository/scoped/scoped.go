@@ -0,0 +1,381 @@
+package scoped
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+// scanRepository restricts every operation to the tenant of the context given by tenancy.With. Repositories out of the tenant are handled as if they did not exist, i.e. they are excluded from lists and other operations fail with repository.ErrNotFound, so that a principal cannot learn which repositories other tenants have. Operations pass through to the underlying repository if the context has no tenant.
+type scanRepository struct {
+	repo interfaces.ScanRepository
+}
+
+// New wraps the repository to isolate data of tenants. It returns nil if repo is nil, so that callers can keep checking whether the repository is configured.
+func New(repo interfaces.ScanRepository) interfaces.ScanRepository {
+	if repo == nil {
+		return nil
+	}
+	return &scanRepository{repo: repo}
+}
+
+// check returns repository.ErrNotFound if the repository is out of the tenant. The repository is looked up only if the tenant does not have its owner, to see its installation.
+func (x *scanRepository) check(ctx context.Context, repoID types.GitHubRepoID) error {
+	tenant := tenancy.From(ctx)
+	owner, _ := repoID.Split()
+	if tenant.AllowsOwner(owner) {
+		return nil
+	}
+
+	if len(tenant.InstallationIDs) > 0 {
+		repo, err := x.repo.GetRepository(ctx, repoID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if repo != nil && tenant.AllowsInstallation(repo.InstallationID) {
+			return nil
+		}
+	}
+
+	return goerr.Wrap(repository.ErrNotFound, "repository not found", goerr.V("repo_id", repoID))
+}
+
+func filterRepositories(ctx context.Context, repos []*model.Repository) []*model.Repository {
+	tenant := tenancy.From(ctx)
+	if tenant == nil {
+		return repos
+	}
+
+	var allowed []*model.Repository
+	for _, repo := range repos {
+		if tenant.AllowsRepository(repo) {
+			allowed = append(allowed, repo)
+		}
+	}
+	return allowed
+}
+
+func (x *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	if err := x.check(ctx, repo.ID); err != nil {
+		return err
+	}
+	return x.repo.CreateOrUpdateRepository(ctx, repo)
+}
+
+func (x *scanRepository) GetRepository(ctx context.Context, repoID types.GitHubRepoID) (*model.Repository, error) {
+	repo, err := x.repo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo != nil && !tenancy.From(ctx).AllowsRepository(repo) {
+		return nil, goerr.Wrap(repository.ErrNotFound, "repository not found", goerr.V("repo_id", repoID))
+	}
+	return repo, nil
+}
+
+func (x *scanRepository) ListRepositories(ctx context.Context, installationID int64) ([]*model.Repository, error) {
+	repos, err := x.repo.ListRepositories(ctx, installationID)
+	if err != nil {
+		return nil, err
+	}
+	return filterRepositories(ctx, repos), nil
+}
+
+func (x *scanRepository) ListRepositoriesByOwner(ctx context.Context, owner string) ([]*model.Repository, error) {
+	repos, err := x.repo.ListRepositoriesByOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return filterRepositories(ctx, repos), nil
+}
+
+func (x *scanRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.DeleteRepository(ctx, repoID)
+}
+
+func (x *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.CreateOrUpdateBranch(ctx, repoID, branch)
+}
+
+func (x *scanRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.ClaimBranchScan(ctx, repoID, branch)
+}
+
+func (x *scanRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.GetBranch(ctx, repoID, branchName)
+}
+
+func (x *scanRepository) ListBranches(ctx context.Context, repoID types.GitHubRepoID) ([]*model.Branch, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListBranches(ctx, repoID)
+}
+
+func (x *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.CreateOrUpdateTarget(ctx, repoID, branchName, target)
+}
+
+func (x *scanRepository) GetTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*model.Target, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.GetTarget(ctx, repoID, branchName, targetID)
+}
+
+func (x *scanRepository) ListTargets(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) ([]*model.Target, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListTargets(ctx, repoID, branchName)
+}
+
+func (x *scanRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.MoveTarget(ctx, repoID, branchName, fromID, target)
+}
+
+func (x *scanRepository) ListVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Vulnerability, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListVulnerabilities(ctx, repoID, branchName, targetID)
+}
+
+func (x *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchCreateVulnerabilities(ctx, repoID, branchName, targetID, vulns)
+}
+
+func (x *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, changes)
+}
+
+func (x *scanRepository) ListVulnerabilityHistory(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnID string) ([]*model.VulnStatusChange, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListVulnerabilityHistory(ctx, repoID, branchName, targetID, vulnID)
+}
+
+func (x *scanRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchDeleteVulnerabilities(ctx, repoID, branchName, targetID, vulnIDs)
+}
+
+func (x *scanRepository) ListRepositoryVulnerabilities(ctx context.Context, repoID types.GitHubRepoID) ([]*model.BranchVulnerability, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListRepositoryVulnerabilities(ctx, repoID)
+}
+
+func (x *scanRepository) ListPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Package, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListPackages(ctx, repoID, branchName, targetID)
+}
+
+func (x *scanRepository) BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchUpsertPackages(ctx, repoID, branchName, targetID, pkgs)
+}
+
+func (x *scanRepository) BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchDeletePackages(ctx, repoID, branchName, targetID, pkgIDs)
+}
+
+func (x *scanRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.CreateScanRecord(ctx, repoID, record)
+}
+
+func (x *scanRepository) ListScanRecords(ctx context.Context, repoID types.GitHubRepoID) ([]*model.ScanRecord, error) {
+	if err := x.check(ctx, repoID); err != nil {
+		return nil, err
+	}
+	return x.repo.ListScanRecords(ctx, repoID)
+}
+
+func (x *scanRepository) ListScanRecordsByCommitter(ctx context.Context, login string, since time.Time) ([]*model.ScanRecord, error) {
+	records, err := x.repo.ListScanRecordsByCommitter(ctx, login, since)
+	if err != nil || tenancy.From(ctx) == nil {
+		return records, err
+	}
+
+	// Records of the same repository are checked once
+	allowed := map[types.GitHubRepoID]bool{}
+	var filtered []*model.ScanRecord
+	for _, record := range records {
+		ok, checked := allowed[record.RepoID]
+		if !checked {
+			err := x.check(ctx, record.RepoID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+			ok = err == nil
+			allowed[record.RepoID] = ok
+		}
+		if ok {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+func (x *scanRepository) BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error {
+	if err := x.check(ctx, repoID); err != nil {
+		return err
+	}
+	return x.repo.BatchDeleteScanRecords(ctx, repoID, scanIDs)
+}
+
+// checkOwner returns repository.ErrNotFound if the owner is out of the tenant. Overrides apply to all repositories of the owner, so a tenant having only some of them by installation can read overrides but cannot change them.
+func (x *scanRepository) checkOwner(ctx context.Context, owner string, write bool) error {
+	tenant := tenancy.From(ctx)
+	if tenant.AllowsOwner(owner) {
+		return nil
+	}
+
+	if !write && len(tenant.InstallationIDs) > 0 {
+		repos, err := x.repo.ListRepositoriesByOwner(ctx, owner)
+		if err != nil {
+			return err
+		}
+		if len(filterRepositories(ctx, repos)) > 0 {
+			return nil
+		}
+	}
+
+	return goerr.Wrap(repository.ErrNotFound, "owner not found", goerr.V("owner", owner))
+}
+
+func (x *scanRepository) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error {
+	if err := x.checkOwner(ctx, override.Owner, true); err != nil {
+		return err
+	}
+	return x.repo.PutSeverityOverride(ctx, override)
+}
+
+func (x *scanRepository) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	if err := x.checkOwner(ctx, owner, false); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return x.repo.ListSeverityOverrides(ctx, owner)
+}
+
+func (x *scanRepository) DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error {
+	if err := x.checkOwner(ctx, owner, true); err != nil {
+		return err
+	}
+	return x.repo.DeleteSeverityOverride(ctx, owner, overrideID)
+}
+
+// PutScanRun is not restricted because runs are recorded by scans, which are not requested by principals of tenants
+func (x *scanRepository) PutScanRun(ctx context.Context, run *model.ScanRun) error {
+	return x.repo.PutScanRun(ctx, run)
+}
+
+func (x *scanRepository) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	run, err := x.repo.GetScanRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.check(ctx, types.NewGitHubRepoID(run.Owner, run.RepoName)); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// PutOwnerScanRun is not restricted for the same reason as PutScanRun
+func (x *scanRepository) PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error {
+	return x.repo.PutOwnerScanRun(ctx, run)
+}
+
+// GetOwnerScanRun returns the run as it is to a tenant of the owner. A tenant having only some repositories of the owner by installation gets a copy of the run with results of its repositories only, counted again, so that it cannot learn about other repositories. The run is not found if the tenant has none of them.
+func (x *scanRepository) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	run, err := x.repo.GetOwnerScanRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	tenant := tenancy.From(ctx)
+	if tenant.AllowsOwner(run.Owner) {
+		return run, nil
+	}
+
+	notFound := goerr.Wrap(repository.ErrNotFound, "owner scan run not found", goerr.V("run_id", runID))
+	if len(tenant.InstallationIDs) == 0 {
+		return nil, notFound
+	}
+	repos, err := x.repo.ListRepositoriesByOwner(ctx, run.Owner)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[types.GitHubRepoID]bool)
+	for _, repo := range filterRepositories(ctx, repos) {
+		allowed[repo.ID] = true
+	}
+
+	filtered := *run
+	filtered.Succeeded, filtered.Skipped, filtered.Failed = 0, 0, 0
+	filtered.Repositories = []*model.OwnerScanRepoResult{}
+	for _, result := range run.Repositories {
+		if allowed[types.NewGitHubRepoID(result.Owner, result.Repo)] {
+			filtered.Add(result)
+		}
+	}
+	if len(filtered.Repositories) == 0 {
+		return nil, notFound
+	}
+	if filtered.Status == types.ScanRunFailed && filtered.Failed == 0 && filtered.Error == "" {
+		filtered.Status = types.ScanRunSucceeded
+	}
+	return &filtered, nil
+}
+
+// Ping is not restricted because it does not return data
+func (x *scanRepository) Ping(ctx context.Context) error {
+	return x.repo.Ping(ctx)
+}
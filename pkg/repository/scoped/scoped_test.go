@@ -0,0 +1,176 @@
+package scoped_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/repository/scoped"
+	"github.com/m-mizutani/octovy/pkg/repository/testhelper"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+func TestScopedScanRepositoryWithoutTenant(t *testing.T) {
+	testhelper.TestAll(t, scoped.New(memory.New()))
+}
+
+func TestScopedScanRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := scoped.New(memory.New())
+
+	now := time.Now()
+	for _, r := range []*model.Repository{
+		{ID: "myorg/app", Owner: "myorg", Name: "app", InstallationID: 1},
+		{ID: "myorg/lib", Owner: "myorg", Name: "lib", InstallationID: 1},
+		{ID: "partner/shared", Owner: "partner", Name: "shared", InstallationID: 2},
+		{ID: "partner/private", Owner: "partner", Name: "private", InstallationID: 3},
+	} {
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, r))
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, r.ID, &model.Branch{Name: "main", LastScanAt: now}))
+		gt.NoError(t, repo.CreateScanRecord(ctx, r.ID, &model.ScanRecord{ID: types.ScanID("scan-" + r.Name), Branch: "main", CommitterLogin: "alice", Timestamp: now}))
+	}
+
+	// The tenant has all repositories of myorg and repositories of partner installed by installation 2
+	tenantCtx := tenancy.With(ctx, &model.Tenant{Owners: []string{"MyOrg"}, InstallationIDs: []int64{2}})
+
+	t.Run("lists only repositories of the tenant", func(t *testing.T) {
+		repos, err := repo.ListRepositoriesByOwner(tenantCtx, "partner")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(1).At(0, func(t testing.TB, v *model.Repository) {
+			gt.V(t, v.ID).Equal(types.GitHubRepoID("partner/shared"))
+		})
+
+		repos, err = repo.ListRepositoriesByOwner(tenantCtx, "myorg")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(2)
+
+		repos, err = repo.ListRepositories(tenantCtx, 3)
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(0)
+
+		// Without tenant, all repositories are listed
+		repos, err = repo.ListRepositoriesByOwner(ctx, "partner")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(2)
+	})
+
+	t.Run("repositories out of the tenant are not found", func(t *testing.T) {
+		_, err := repo.GetRepository(tenantCtx, "partner/private")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = repo.GetBranch(tenantCtx, "partner/private", "main")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = repo.ListScanRecords(tenantCtx, "partner/private")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		err = repo.CreateOrUpdateBranch(tenantCtx, "partner/private", &model.Branch{Name: "dev"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		err = repo.DeleteRepository(tenantCtx, "partner/private")
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		// Nothing has been changed or deleted
+		branches, err := repo.ListBranches(ctx, "partner/private")
+		gt.NoError(t, err)
+		gt.A(t, branches).Length(1)
+	})
+
+	t.Run("repositories in the tenant by owner or installation are accessible", func(t *testing.T) {
+		r, err := repo.GetRepository(tenantCtx, "myorg/app")
+		gt.NoError(t, err)
+		gt.V(t, r.Name).Equal("app")
+
+		branch, err := repo.GetBranch(tenantCtx, "partner/shared", "main")
+		gt.NoError(t, err)
+		gt.V(t, branch.Name).Equal(types.BranchName("main"))
+	})
+
+	t.Run("scan records of committer are filtered", func(t *testing.T) {
+		records, err := repo.ListScanRecordsByCommitter(tenantCtx, "alice", now.Add(-time.Hour))
+		gt.NoError(t, err)
+		gt.A(t, records).Length(3)
+		for _, record := range records {
+			gt.V(t, record.RepoID).NotEqual(types.GitHubRepoID("partner/private"))
+		}
+
+		records, err = repo.ListScanRecordsByCommitter(ctx, "alice", now.Add(-time.Hour))
+		gt.NoError(t, err)
+		gt.A(t, records).Length(4)
+	})
+
+	t.Run("severity overrides can be changed only by the tenant of the owner", func(t *testing.T) {
+		gt.NoError(t, repo.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "partner", VulnID: "CVE-2024-0001", Severity: types.SeverityLow}))
+		gt.NoError(t, repo.PutSeverityOverride(tenantCtx, &model.SeverityOverride{Owner: "myorg", VulnID: "CVE-2024-0001", Severity: types.SeverityLow}))
+
+		err := repo.PutSeverityOverride(tenantCtx, &model.SeverityOverride{Owner: "partner", VulnID: "CVE-2024-0002", Severity: types.SeverityLow})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		override := &model.SeverityOverride{VulnID: "CVE-2024-0001"}
+		err = repo.DeleteSeverityOverride(tenantCtx, "partner", override.ID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		// Overrides apply to repositories of the installation, so they are readable
+		overrides, err := repo.ListSeverityOverrides(tenantCtx, "partner")
+		gt.NoError(t, err)
+		gt.A(t, overrides).Length(1)
+
+		overrides, err = repo.ListSeverityOverrides(tenancy.With(ctx, &model.Tenant{Owners: []string{"myorg"}}), "partner")
+		gt.NoError(t, err)
+		gt.A(t, overrides).Length(0)
+	})
+
+	t.Run("scan runs of repositories out of the tenant are not found", func(t *testing.T) {
+		private := &model.ScanRun{ID: types.NewScanRunID(), Owner: "partner", RepoName: "private"}
+		own := &model.ScanRun{ID: types.NewScanRunID(), Owner: "myorg", RepoName: "app"}
+		gt.NoError(t, repo.PutScanRun(tenantCtx, private))
+		gt.NoError(t, repo.PutScanRun(tenantCtx, own))
+
+		_, err := repo.GetScanRun(tenantCtx, private.ID)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		run, err := repo.GetScanRun(tenantCtx, own.ID)
+		gt.NoError(t, err)
+		gt.V(t, run.ID).Equal(own.ID)
+		_, err = repo.GetScanRun(ctx, private.ID)
+		gt.NoError(t, err)
+	})
+
+	t.Run("owner scan runs have only repositories of the tenant", func(t *testing.T) {
+		run := model.NewOwnerScanRun("partner", model.OwnerScanSourceFirestore, types.ScanTriggerCLI, "", now)
+		run.Add(&model.OwnerScanRepoResult{Owner: "partner", Repo: "shared", Branch: "main", Status: types.ScanRunSucceeded, ScanID: "scan-shared"})
+		run.Add(&model.OwnerScanRepoResult{Owner: "partner", Repo: "private", Branch: "main", Status: types.ScanRunFailed, Error: "clone failed"})
+		run.Finish(nil, now)
+		gt.NoError(t, repo.PutOwnerScanRun(ctx, run))
+
+		got, err := repo.GetOwnerScanRun(tenantCtx, run.ID)
+		gt.NoError(t, err)
+		gt.A(t, got.Repositories).Length(1).At(0, func(t testing.TB, v *model.OwnerScanRepoResult) {
+			gt.V(t, v.Repo).Equal("shared")
+		})
+		gt.V(t, got.Succeeded).Equal(1)
+		gt.V(t, got.Failed).Equal(0)
+		gt.V(t, got.Status).Equal(types.ScanRunSucceeded)
+
+		// The stored run is not changed
+		got, err = repo.GetOwnerScanRun(ctx, run.ID)
+		gt.NoError(t, err)
+		gt.A(t, got.Repositories).Length(2)
+		gt.V(t, got.Status).Equal(types.ScanRunFailed)
+
+		// A tenant of the owner gets the whole run
+		got, err = repo.GetOwnerScanRun(tenancy.With(ctx, &model.Tenant{Owners: []string{"partner"}}), run.ID)
+		gt.NoError(t, err)
+		gt.A(t, got.Repositories).Length(2)
+
+		// A tenant having none of the repositories can not find the run
+		_, err = repo.GetOwnerScanRun(tenancy.With(ctx, &model.Tenant{Owners: []string{"myorg"}}), run.ID)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = repo.GetOwnerScanRun(tenancy.With(ctx, &model.Tenant{InstallationIDs: []int64{1}}), run.ID)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("nil repository stays nil", func(t *testing.T) {
+		gt.V(t, scoped.New(nil)).Nil()
+	})
+}
@@ -0,0 +1,698 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+func (r *scanRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return goerr.Wrap(err, "failed to ping SQLite database")
+	}
+	return nil
+}
+
+// requireRepository returns ErrNotFound if the repository does not exist
+func requireRepository(ctx context.Context, q queryer, repoID types.GitHubRepoID) error {
+	found, err := exists(ctx, q, `SELECT 1 FROM repositories WHERE id = ?`, string(repoID))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+	return nil
+}
+
+// requireBranch returns ErrNotFound if the repository or the branch does not exist
+func requireBranch(ctx context.Context, q queryer, repoID types.GitHubRepoID, branchName types.BranchName) error {
+	if err := requireRepository(ctx, q, repoID); err != nil {
+		return err
+	}
+	found, err := exists(ctx, q, `SELECT 1 FROM branches WHERE repo_id = ? AND name = ?`, string(repoID), string(branchName))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return goerr.Wrap(repository.ErrNotFound, "branch not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+		)
+	}
+	return nil
+}
+
+// requireTarget returns ErrNotFound if the repository, the branch or the target does not exist
+func requireTarget(ctx context.Context, q queryer, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) error {
+	if err := requireBranch(ctx, q, repoID, branchName); err != nil {
+		return err
+	}
+	found, err := exists(ctx, q, `SELECT 1 FROM targets WHERE repo_id = ? AND branch = ? AND id = ?`, string(repoID), string(branchName), string(targetID))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return goerr.Wrap(repository.ErrNotFound, "target not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", targetID),
+		)
+	}
+	return nil
+}
+
+// Repository operations
+
+func (r *scanRepository) CreateOrUpdateRepository(ctx context.Context, repo *model.Repository) error {
+	if err := repository.ValidateRepository(repo); err != nil {
+		return err
+	}
+
+	data, err := marshal(repo)
+	if err != nil {
+		return err
+	}
+	// Upsert instead of replace, which would delete children of the repository
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO repositories (id, owner, name, installation_id, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET owner = excluded.owner, name = excluded.name, installation_id = excluded.installation_id, data = excluded.data`,
+		string(repo.ID), repo.Owner, repo.Name, repo.InstallationID, data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save repository", goerr.V("repoID", repo.ID))
+	}
+	return nil
+}
+
+func (r *scanRepository) GetRepository(ctx context.Context, repoID types.GitHubRepoID) (*model.Repository, error) {
+	repo, err := getDocument[model.Repository](ctx, r.db, `SELECT data FROM repositories WHERE id = ?`, string(repoID))
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "repository not found",
+			goerr.V("repoID", repoID),
+		)
+	}
+	return repo, nil
+}
+
+func (r *scanRepository) ListRepositories(ctx context.Context, installationID int64) ([]*model.Repository, error) {
+	return queryDocuments[model.Repository](ctx, r.db, `SELECT data FROM repositories WHERE installation_id = ?`, installationID)
+}
+
+func (r *scanRepository) ListRepositoriesByOwner(ctx context.Context, owner string) ([]*model.Repository, error) {
+	// Sort by name to return the same order as the Firestore implementation
+	return queryDocuments[model.Repository](ctx, r.db, `SELECT data FROM repositories WHERE owner = ? ORDER BY name`, owner)
+}
+
+func (r *scanRepository) DeleteRepository(ctx context.Context, repoID types.GitHubRepoID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM repositories WHERE id = ?`, string(repoID)); err != nil {
+		return goerr.Wrap(err, "failed to delete repository", goerr.V("repoID", repoID))
+	}
+	return nil
+}
+
+// Branch operations
+
+func (r *scanRepository) CreateOrUpdateBranch(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireRepository(ctx, tx, repoID); err != nil {
+			return err
+		}
+		return putBranch(ctx, tx, repoID, branch)
+	})
+}
+
+func putBranch(ctx context.Context, q queryer, repoID types.GitHubRepoID, branch *model.Branch) error {
+	data, err := marshal(branch)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO branches (repo_id, name, data) VALUES (?, ?, ?)
+		ON CONFLICT (repo_id, name) DO UPDATE SET data = excluded.data`,
+		string(repoID), string(branch.Name), data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save branch", goerr.V("repoID", repoID), goerr.V("branchName", branch.Name))
+	}
+	return nil
+}
+
+func (r *scanRepository) ClaimBranchScan(ctx context.Context, repoID types.GitHubRepoID, branch *model.Branch) error {
+	if err := repository.ValidateBranchKey(repoID, branch.Name); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireRepository(ctx, tx, repoID); err != nil {
+			return err
+		}
+
+		stored, err := getDocument[model.Branch](ctx, tx, `SELECT data FROM branches WHERE repo_id = ? AND name = ?`, string(repoID), string(branch.Name))
+		if err != nil {
+			return err
+		}
+		if stored != nil && stored.LastScanStartedAt.After(branch.LastScanStartedAt) {
+			return goerr.Wrap(repository.ErrConflict, "newer scan of the branch is stored",
+				goerr.V("repoID", repoID),
+				goerr.V("branchName", branch.Name),
+				goerr.V("storedScanID", stored.LastScanID),
+			)
+		}
+		return putBranch(ctx, tx, repoID, branch)
+	})
+}
+
+func (r *scanRepository) GetBranch(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
+	if err := requireRepository(ctx, r.db, repoID); err != nil {
+		return nil, err
+	}
+
+	branch, err := getDocument[model.Branch](ctx, r.db, `SELECT data FROM branches WHERE repo_id = ? AND name = ?`, string(repoID), string(branchName))
+	if err != nil {
+		return nil, err
+	}
+	if branch == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "branch not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+		)
+	}
+	return branch, nil
+}
+
+func (r *scanRepository) ListBranches(ctx context.Context, repoID types.GitHubRepoID) ([]*model.Branch, error) {
+	if err := requireRepository(ctx, r.db, repoID); err != nil {
+		return nil, err
+	}
+	return queryDocuments[model.Branch](ctx, r.db, `SELECT data FROM branches WHERE repo_id = ?`, string(repoID))
+}
+
+// Target operations
+
+func (r *scanRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireBranch(ctx, tx, repoID, branchName); err != nil {
+			return err
+		}
+
+		data, err := marshal(target)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO targets (repo_id, branch, id, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (repo_id, branch, id) DO UPDATE SET data = excluded.data`,
+			string(repoID), string(branchName), string(target.ID), data,
+		); err != nil {
+			return goerr.Wrap(err, "failed to save target", goerr.V("repoID", repoID), goerr.V("branchName", branchName), goerr.V("targetID", target.ID))
+		}
+		return nil
+	})
+}
+
+func (r *scanRepository) MoveTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, fromID types.TargetID, target *model.Target) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, fromID); err != nil {
+			return err
+		}
+
+		data, err := marshal(target)
+		if err != nil {
+			return err
+		}
+		// A target already stored at the new ID is replaced with its children
+		if target.ID != fromID {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM targets WHERE repo_id = ? AND branch = ? AND id = ?`,
+				string(repoID), string(branchName), string(target.ID),
+			); err != nil {
+				return goerr.Wrap(err, "failed to delete target", goerr.V("targetID", target.ID))
+			}
+		}
+		// Children of the target follow its new ID by ON UPDATE CASCADE
+		if _, err := tx.ExecContext(ctx, `UPDATE targets SET id = ?, data = ? WHERE repo_id = ? AND branch = ? AND id = ?`,
+			string(target.ID), data, string(repoID), string(branchName), string(fromID),
+		); err != nil {
+			return goerr.Wrap(err, "failed to move target", goerr.V("fromID", fromID), goerr.V("targetID", target.ID))
+		}
+		return nil
+	})
+}
+
+func (r *scanRepository) GetTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) (*model.Target, error) {
+	if err := requireBranch(ctx, r.db, repoID, branchName); err != nil {
+		return nil, err
+	}
+
+	target, err := getDocument[model.Target](ctx, r.db, `SELECT data FROM targets WHERE repo_id = ? AND branch = ? AND id = ?`,
+		string(repoID), string(branchName), string(targetID))
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "target not found",
+			goerr.V("repoID", repoID),
+			goerr.V("branchName", branchName),
+			goerr.V("targetID", targetID),
+		)
+	}
+	return target, nil
+}
+
+func (r *scanRepository) ListTargets(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) ([]*model.Target, error) {
+	if err := requireBranch(ctx, r.db, repoID, branchName); err != nil {
+		return nil, err
+	}
+	return queryDocuments[model.Target](ctx, r.db, `SELECT data FROM targets WHERE repo_id = ? AND branch = ?`, string(repoID), string(branchName))
+}
+
+// Vulnerability operations
+
+func (r *scanRepository) ListVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Vulnerability, error) {
+	if err := requireTarget(ctx, r.db, repoID, branchName, targetID); err != nil {
+		return nil, err
+	}
+	return queryDocuments[model.Vulnerability](ctx, r.db, `SELECT data FROM vulnerabilities WHERE repo_id = ? AND branch = ? AND target_id = ?`,
+		string(repoID), string(branchName), string(targetID))
+}
+
+func (r *scanRepository) BatchCreateVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulns []*model.Vulnerability) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, targetID); err != nil {
+			return err
+		}
+		for _, vuln := range vulns {
+			if err := putVulnerability(ctx, tx, repoID, branchName, targetID, vuln); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func putVulnerability(ctx context.Context, q queryer, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vuln *model.Vulnerability) error {
+	data, err := marshal(vuln)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO vulnerabilities (repo_id, branch, target_id, id, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (repo_id, branch, target_id, id) DO UPDATE SET data = excluded.data`,
+		string(repoID), string(branchName), string(targetID), vuln.ID, data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save vulnerability", goerr.V("targetID", targetID), goerr.V("vulnID", vuln.ID))
+	}
+	return nil
+}
+
+func (r *scanRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, targetID); err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			vuln, err := getDocument[model.Vulnerability](ctx, tx, `SELECT data FROM vulnerabilities WHERE repo_id = ? AND branch = ? AND target_id = ? AND id = ?`,
+				string(repoID), string(branchName), string(targetID), change.VulnID)
+			if err != nil {
+				return err
+			}
+			if vuln == nil {
+				continue
+			}
+
+			vuln.Status = change.To
+			vuln.Acknowledgement = nextAcknowledgement(vuln.Acknowledgement, change)
+			vuln.UpdatedAt = change.Timestamp
+			if err := putVulnerability(ctx, tx, repoID, branchName, targetID, vuln); err != nil {
+				return err
+			}
+
+			data, err := marshal(change)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO vulnerability_history (repo_id, branch, target_id, vuln_id, data) VALUES (?, ?, ?, ?, ?)`,
+				string(repoID), string(branchName), string(targetID), change.VulnID, data,
+			); err != nil {
+				return goerr.Wrap(err, "failed to save vulnerability history", goerr.V("vulnID", change.VulnID))
+			}
+		}
+		return nil
+	})
+}
+
+// nextAcknowledgement returns the acknowledgement after the change. It is kept when the vulnerability is fixed so that it applies again on re-detection.
+func nextAcknowledgement(current *model.Acknowledgement, change *model.VulnStatusChange) *model.Acknowledgement {
+	switch change.To {
+	case types.VulnStatusAcknowledged:
+		return change.Acknowledgement
+	case types.VulnStatusActive:
+		return nil
+	default:
+		return current
+	}
+}
+
+func (r *scanRepository) ListVulnerabilityHistory(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnID string) ([]*model.VulnStatusChange, error) {
+	return queryDocuments[model.VulnStatusChange](ctx, r.db, `
+		SELECT data FROM vulnerability_history WHERE repo_id = ? AND branch = ? AND target_id = ? AND vuln_id = ? ORDER BY seq`,
+		string(repoID), string(branchName), string(targetID), vulnID)
+}
+
+func (r *scanRepository) BatchDeleteVulnerabilities(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, vulnIDs []string) error {
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, targetID); err != nil {
+			return err
+		}
+		for _, vulnID := range vulnIDs {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM vulnerabilities WHERE repo_id = ? AND branch = ? AND target_id = ? AND id = ?`,
+				string(repoID), string(branchName), string(targetID), vulnID,
+			); err != nil {
+				return goerr.Wrap(err, "failed to delete vulnerability", goerr.V("vulnID", vulnID))
+			}
+		}
+		return nil
+	})
+}
+
+func (r *scanRepository) ListRepositoryVulnerabilities(ctx context.Context, repoID types.GitHubRepoID) ([]*model.BranchVulnerability, error) {
+	if err := requireRepository(ctx, r.db, repoID); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[[2]string]*model.Target)
+	rows, err := r.db.QueryContext(ctx, `SELECT branch, id, data FROM targets WHERE repo_id = ?`, string(repoID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to query targets", goerr.V("repoID", repoID))
+	}
+	for rows.Next() {
+		var branch, id, data string
+		if err := rows.Scan(&branch, &id, &data); err != nil {
+			_ = rows.Close()
+			return nil, goerr.Wrap(err, "failed to scan target")
+		}
+		var target model.Target
+		if err := unmarshal(data, &target); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		targets[[2]string{branch, id}] = &target
+	}
+	if err := rows.Err(); err != nil {
+		return nil, goerr.Wrap(err, "failed to iterate targets")
+	}
+	_ = rows.Close()
+
+	rows, err = r.db.QueryContext(ctx, `SELECT branch, target_id, data FROM vulnerabilities WHERE repo_id = ?`, string(repoID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to query vulnerabilities", goerr.V("repoID", repoID))
+	}
+	defer func() { _ = rows.Close() }()
+
+	var vulns []*model.BranchVulnerability
+	for rows.Next() {
+		var branch, targetID, data string
+		if err := rows.Scan(&branch, &targetID, &data); err != nil {
+			return nil, goerr.Wrap(err, "failed to scan vulnerability")
+		}
+		var vuln model.Vulnerability
+		if err := unmarshal(data, &vuln); err != nil {
+			return nil, err
+		}
+		target := *targets[[2]string{branch, targetID}]
+		vulns = append(vulns, &model.BranchVulnerability{
+			Branch:        types.BranchName(branch),
+			Target:        &target,
+			Vulnerability: &vuln,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, goerr.Wrap(err, "failed to iterate vulnerabilities")
+	}
+
+	return vulns, nil
+}
+
+// Package operations
+
+func (r *scanRepository) ListPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID) ([]*model.Package, error) {
+	if err := requireTarget(ctx, r.db, repoID, branchName, targetID); err != nil {
+		return nil, err
+	}
+	return queryDocuments[model.Package](ctx, r.db, `SELECT data FROM packages WHERE repo_id = ? AND branch = ? AND target_id = ?`,
+		string(repoID), string(branchName), string(targetID))
+}
+
+func (r *scanRepository) BatchUpsertPackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgs []*model.Package) error {
+	if err := repository.ValidateBranchKey(repoID, branchName); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, targetID); err != nil {
+			return err
+		}
+		for _, pkg := range pkgs {
+			data, err := marshal(pkg)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO packages (repo_id, branch, target_id, id, data) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (repo_id, branch, target_id, id) DO UPDATE SET data = excluded.data`,
+				string(repoID), string(branchName), string(targetID), pkg.ID, data,
+			); err != nil {
+				return goerr.Wrap(err, "failed to save package", goerr.V("pkgID", pkg.ID))
+			}
+		}
+		return nil
+	})
+}
+
+func (r *scanRepository) BatchDeletePackages(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, pkgIDs []string) error {
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireTarget(ctx, tx, repoID, branchName, targetID); err != nil {
+			return err
+		}
+		for _, pkgID := range pkgIDs {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM packages WHERE repo_id = ? AND branch = ? AND target_id = ? AND id = ?`,
+				string(repoID), string(branchName), string(targetID), pkgID,
+			); err != nil {
+				return goerr.Wrap(err, "failed to delete package", goerr.V("pkgID", pkgID))
+			}
+		}
+		return nil
+	})
+}
+
+// Scan history operations
+
+func (r *scanRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	if err := repoID.Validate(); err != nil {
+		return err
+	}
+
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireRepository(ctx, tx, repoID); err != nil {
+			return err
+		}
+
+		cpy := *record
+		cpy.RepoID = repoID
+		data, err := marshal(&cpy)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO scan_records (repo_id, id, committer_login, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (repo_id, id) DO UPDATE SET committer_login = excluded.committer_login, data = excluded.data`,
+			string(repoID), string(record.ID), record.CommitterLogin, data,
+		); err != nil {
+			return goerr.Wrap(err, "failed to save scan record", goerr.V("repoID", repoID), goerr.V("scanID", record.ID))
+		}
+		return nil
+	})
+}
+
+func (r *scanRepository) ListScanRecords(ctx context.Context, repoID types.GitHubRepoID) ([]*model.ScanRecord, error) {
+	if err := requireRepository(ctx, r.db, repoID); err != nil {
+		return nil, err
+	}
+
+	records, err := queryDocuments[model.ScanRecord](ctx, r.db, `SELECT data FROM scan_records WHERE repo_id = ?`, string(repoID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first, same as the Firestore implementation
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+func (r *scanRepository) ListScanRecordsByCommitter(ctx context.Context, login string, since time.Time) ([]*model.ScanRecord, error) {
+	all, err := queryDocuments[model.ScanRecord](ctx, r.db, `SELECT data FROM scan_records WHERE committer_login = ?`, login)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*model.ScanRecord
+	for _, record := range all {
+		if !record.Timestamp.Before(since) {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+func (r *scanRepository) BatchDeleteScanRecords(ctx context.Context, repoID types.GitHubRepoID, scanIDs []types.ScanID) error {
+	return r.transact(ctx, func(tx *sql.Tx) error {
+		if err := requireRepository(ctx, tx, repoID); err != nil {
+			return err
+		}
+		for _, scanID := range scanIDs {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM scan_records WHERE repo_id = ? AND id = ?`, string(repoID), string(scanID)); err != nil {
+				return goerr.Wrap(err, "failed to delete scan record", goerr.V("scanID", scanID))
+			}
+		}
+		return nil
+	})
+}
+
+// Severity override operations
+
+func (r *scanRepository) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) error {
+	data, err := marshal(override)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO severity_overrides (owner, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (owner, id) DO UPDATE SET data = excluded.data`,
+		override.Owner, override.ID(), data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save severity override", goerr.V("owner", override.Owner), goerr.V("overrideID", override.ID()))
+	}
+	return nil
+}
+
+func (r *scanRepository) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	overrides, err := queryDocuments[model.SeverityOverride](ctx, r.db, `SELECT data FROM severity_overrides WHERE owner = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	model.SortSeverityOverrides(overrides)
+	return overrides, nil
+}
+
+func (r *scanRepository) DeleteSeverityOverride(ctx context.Context, owner, overrideID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM severity_overrides WHERE owner = ? AND id = ?`, owner, overrideID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to delete severity override", goerr.V("owner", owner), goerr.V("overrideID", overrideID))
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return goerr.Wrap(repository.ErrNotFound, "severity override not found",
+			goerr.V("owner", owner),
+			goerr.V("overrideID", overrideID),
+		)
+	}
+	return nil
+}
+
+// Scan run operations
+
+func (r *scanRepository) PutScanRun(ctx context.Context, run *model.ScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "scan run ID is empty")
+	}
+
+	data, err := marshal(run)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO scan_runs (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		string(run.ID), data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save scan run", goerr.V("runID", run.ID))
+	}
+	return nil
+}
+
+func (r *scanRepository) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	run, err := getDocument[model.ScanRun](ctx, r.db, `SELECT data FROM scan_runs WHERE id = ?`, string(runID))
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "scan run not found",
+			goerr.V("runID", runID),
+		)
+	}
+	return run, nil
+}
+
+// Owner scan run operations
+
+func (r *scanRepository) PutOwnerScanRun(ctx context.Context, run *model.OwnerScanRun) error {
+	if run.ID == "" {
+		return goerr.Wrap(repository.ErrInvalidInput, "owner scan run ID is empty")
+	}
+
+	data, err := marshal(run)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO owner_scan_runs (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		string(run.ID), data,
+	); err != nil {
+		return goerr.Wrap(err, "failed to save owner scan run", goerr.V("runID", run.ID))
+	}
+	return nil
+}
+
+func (r *scanRepository) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	run, err := getDocument[model.OwnerScanRun](ctx, r.db, `SELECT data FROM owner_scan_runs WHERE id = ?`, string(runID))
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "owner scan run not found",
+			goerr.V("runID", runID),
+		)
+	}
+	return run, nil
+}
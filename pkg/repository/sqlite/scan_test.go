@@ -0,0 +1,35 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository/sqlite"
+	"github.com/m-mizutani/octovy/pkg/repository/testhelper"
+)
+
+func TestSQLiteScanRepository(t *testing.T) {
+	repo, err := sqlite.New(context.Background(), filepath.Join(t.TempDir(), "octovy.db"))
+	gt.NoError(t, err)
+
+	testhelper.TestAll(t, repo)
+}
+
+func TestSQLiteScanRepositoryReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "octovy.db")
+	repoID := types.NewGitHubRepoID("octo", "app")
+
+	repo := gt.R1(sqlite.New(ctx, path)).NoError(t)
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "octo", Name: "app", Tags: []string{"prod"}}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main"}))
+
+	reopened := gt.R1(sqlite.New(ctx, path)).NoError(t)
+	got := gt.R1(reopened.GetRepository(ctx, repoID)).NoError(t)
+	gt.V(t, got.Tags).Equal([]string{"prod"})
+	gt.R1(reopened.GetBranch(ctx, repoID, "main")).NoError(t)
+}
@@ -0,0 +1,217 @@
+// Package sqlite implements ScanRepository with a SQLite database file, for deployments which need durable storage without an external database. Entities are stored as JSON documents in tables keyed like collections of the Firestore implementation.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/url"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+
+	// Register the pure Go SQLite driver as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// schema creates tables of the repository. Children of a repository are deleted with it by foreign keys, and children of a target follow it when MoveTarget changes its ID.
+const schema = `
+CREATE TABLE IF NOT EXISTS repositories (
+	id              TEXT PRIMARY KEY,
+	owner           TEXT NOT NULL,
+	name            TEXT NOT NULL,
+	installation_id INTEGER NOT NULL,
+	data            TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS repositories_owner ON repositories (owner, name);
+CREATE INDEX IF NOT EXISTS repositories_installation ON repositories (installation_id);
+
+CREATE TABLE IF NOT EXISTS branches (
+	repo_id TEXT NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+	name    TEXT NOT NULL,
+	data    TEXT NOT NULL,
+	PRIMARY KEY (repo_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS targets (
+	repo_id TEXT NOT NULL,
+	branch  TEXT NOT NULL,
+	id      TEXT NOT NULL,
+	data    TEXT NOT NULL,
+	PRIMARY KEY (repo_id, branch, id),
+	FOREIGN KEY (repo_id, branch) REFERENCES branches (repo_id, name) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS vulnerabilities (
+	repo_id   TEXT NOT NULL,
+	branch    TEXT NOT NULL,
+	target_id TEXT NOT NULL,
+	id        TEXT NOT NULL,
+	data      TEXT NOT NULL,
+	PRIMARY KEY (repo_id, branch, target_id, id),
+	FOREIGN KEY (repo_id, branch, target_id) REFERENCES targets (repo_id, branch, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS vulnerability_history (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	repo_id   TEXT NOT NULL,
+	branch    TEXT NOT NULL,
+	target_id TEXT NOT NULL,
+	vuln_id   TEXT NOT NULL,
+	data      TEXT NOT NULL,
+	FOREIGN KEY (repo_id, branch, target_id) REFERENCES targets (repo_id, branch, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS vulnerability_history_vuln ON vulnerability_history (repo_id, branch, target_id, vuln_id);
+
+CREATE TABLE IF NOT EXISTS packages (
+	repo_id   TEXT NOT NULL,
+	branch    TEXT NOT NULL,
+	target_id TEXT NOT NULL,
+	id        TEXT NOT NULL,
+	data      TEXT NOT NULL,
+	PRIMARY KEY (repo_id, branch, target_id, id),
+	FOREIGN KEY (repo_id, branch, target_id) REFERENCES targets (repo_id, branch, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS scan_records (
+	repo_id         TEXT NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+	id              TEXT NOT NULL,
+	committer_login TEXT NOT NULL,
+	data            TEXT NOT NULL,
+	PRIMARY KEY (repo_id, id)
+);
+CREATE INDEX IF NOT EXISTS scan_records_committer ON scan_records (committer_login);
+
+CREATE TABLE IF NOT EXISTS severity_overrides (
+	owner TEXT NOT NULL,
+	id    TEXT NOT NULL,
+	data  TEXT NOT NULL,
+	PRIMARY KEY (owner, id)
+);
+
+CREATE TABLE IF NOT EXISTS scan_runs (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS owner_scan_runs (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+`
+
+type scanRepository struct {
+	db *sql.DB
+}
+
+// New opens the SQLite database file at path, creating it and its tables if they do not exist. The database is used through a single connection, so that concurrent writes of the process never fail by lock contention of SQLite. Other processes, e.g. admin commands, can use the file at the same time and wait up to 5 seconds for its lock.
+func New(ctx context.Context, path string) (interfaces.ScanRepository, error) {
+	// Foreign keys are disabled by default in SQLite and must be enabled per connection
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to open SQLite database", goerr.V("path", path))
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		safe.Close(db)
+		return nil, goerr.Wrap(err, "failed to create tables of SQLite database", goerr.V("path", path))
+	}
+
+	return &scanRepository{db: db}, nil
+}
+
+// queryer is either the database or a transaction
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// transact runs f in a transaction, which is committed if f returns no error
+func (r *scanRepository) transact(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return goerr.Wrap(err, "failed to begin transaction")
+	}
+	defer safe.Rollback(tx)
+
+	if err := f(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return goerr.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func marshal(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to marshal document")
+	}
+	return string(raw), nil
+}
+
+func unmarshal(data string, v any) error {
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return goerr.Wrap(err, "failed to unmarshal document")
+	}
+	return nil
+}
+
+// queryDocuments returns documents of the query, which must select only the data column
+func queryDocuments[T any](ctx context.Context, q queryer, query string, args ...any) ([]*T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to query documents")
+	}
+	defer safe.Close(rows)
+
+	var docs []*T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, goerr.Wrap(err, "failed to scan document")
+		}
+		var doc T
+		if err := unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, goerr.Wrap(err, "failed to iterate documents")
+	}
+	return docs, nil
+}
+
+// getDocument returns the document of the query, or nil if no row matches
+func getDocument[T any](ctx context.Context, q queryer, query string, args ...any) (*T, error) {
+	var data string
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to get document")
+	}
+	var doc T
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// exists returns true if the query returns a row
+func exists(ctx context.Context, q queryer, query string, args ...any) (bool, error) {
+	var one int
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&one); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, goerr.Wrap(err, "failed to check existence")
+	}
+	return true, nil
+}
@@ -30,15 +30,51 @@ func TestAll(t *testing.T, repo interfaces.ScanRepository) {
 	t.Run("BranchWithSlash", func(t *testing.T) {
 		TestBranchWithSlash(t, repo)
 	})
+	t.Run("ClaimBranchScan", func(t *testing.T) {
+		TestClaimBranchScan(t, repo)
+	})
 	t.Run("TargetCRUD", func(t *testing.T) {
 		TestTargetCRUD(t, repo)
 	})
+	t.Run("MoveTarget", func(t *testing.T) {
+		TestMoveTarget(t, repo)
+	})
 	t.Run("VulnerabilityBatchOps", func(t *testing.T) {
 		TestVulnerabilityBatchOps(t, repo)
 	})
 	t.Run("VulnerabilityStatusUpdate", func(t *testing.T) {
 		TestVulnerabilityStatusUpdate(t, repo)
 	})
+	t.Run("VulnerabilityBatchDelete", func(t *testing.T) {
+		TestVulnerabilityBatchDelete(t, repo)
+	})
+	t.Run("RepositoryVulnerabilities", func(t *testing.T) {
+		TestRepositoryVulnerabilities(t, repo)
+	})
+	t.Run("PackageBatchOps", func(t *testing.T) {
+		TestPackageBatchOps(t, repo)
+	})
+	t.Run("ScanRecordOps", func(t *testing.T) {
+		TestScanRecordOps(t, repo)
+	})
+	t.Run("DeleteRepository", func(t *testing.T) {
+		TestDeleteRepository(t, repo)
+	})
+	t.Run("ScanRecordsByCommitter", func(t *testing.T) {
+		TestScanRecordsByCommitter(t, repo)
+	})
+	t.Run("RejectInvalidIdentifiers", func(t *testing.T) {
+		TestRejectInvalidIdentifiers(t, repo)
+	})
+	t.Run("SeverityOverrideOps", func(t *testing.T) {
+		TestSeverityOverrideOps(t, repo)
+	})
+	t.Run("ScanRunOps", func(t *testing.T) {
+		TestScanRunOps(t, repo)
+	})
+	t.Run("OwnerScanRunOps", func(t *testing.T) {
+		TestOwnerScanRunOps(t, repo)
+	})
 }
 
 // TestRepositoryCRUD tests basic CRUD operations for Repository
@@ -59,6 +95,8 @@ func TestRepositoryCRUD(t *testing.T, repo interfaces.ScanRepository) {
 		Name:           repoName,
 		DefaultBranch:  "main",
 		InstallationID: installationID,
+		Tags:           []string{"payments", "tier-1"},
+		Team:           "backend",
 		CreatedAt:      now,
 		UpdatedAt:      now,
 	}
@@ -74,6 +112,8 @@ func TestRepositoryCRUD(t *testing.T, repo interfaces.ScanRepository) {
 	gt.V(t, retrieved.Name).Equal(testRepo.Name)
 	gt.V(t, retrieved.DefaultBranch).Equal(testRepo.DefaultBranch)
 	gt.V(t, retrieved.InstallationID).Equal(testRepo.InstallationID)
+	gt.V(t, retrieved.Tags).Equal(testRepo.Tags)
+	gt.V(t, retrieved.Team).Equal(testRepo.Team)
 
 	// Update the repository
 	testRepo.DefaultBranch = "develop"
@@ -137,9 +177,9 @@ func TestListRepositoriesByOwner(t *testing.T, repo interfaces.ScanRepository) {
 		},
 	}
 
-	// Create repositories
-	for _, r := range repos {
-		err := repo.CreateOrUpdateRepository(ctx, r)
+	// Create repositories in reverse order to verify results are sorted by name
+	for i := len(repos) - 1; i >= 0; i-- {
+		err := repo.CreateOrUpdateRepository(ctx, repos[i])
 		gt.NoError(t, err)
 	}
 
@@ -157,11 +197,40 @@ func TestListRepositoriesByOwner(t *testing.T, repo interfaces.ScanRepository) {
 	err := repo.CreateOrUpdateRepository(ctx, otherRepo)
 	gt.NoError(t, err)
 
+	// Create a repository whose owner has the same prefix (should not be included)
+	prefixRepo := &model.Repository{
+		ID:             types.GitHubRepoID(fmt.Sprintf("%s-suffix/repo5", owner)),
+		Owner:          owner + "-suffix",
+		Name:           "repo5",
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, prefixRepo))
+
 	// List repositories by owner
 	retrieved, err := repo.ListRepositoriesByOwner(ctx, owner)
 	gt.NoError(t, err)
 	gt.V(t, len(retrieved)).Equal(3)
 
+	// Verify repositories are sorted by name
+	gt.V(t, retrieved[0].Name).Equal("repo1")
+	gt.V(t, retrieved[1].Name).Equal("repo2")
+	gt.V(t, retrieved[2].Name).Equal("repo3")
+
+	// Updating a repository must not duplicate it in the owner listing
+	updated := *repos[0]
+	updated.DefaultBranch = "release"
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &updated))
+	retrieved, err = repo.ListRepositoriesByOwner(ctx, owner)
+	gt.NoError(t, err)
+	gt.V(t, len(retrieved)).Equal(3)
+	gt.V(t, retrieved[0].DefaultBranch).Equal(types.BranchName("release"))
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, repos[0]))
+	retrieved, err = repo.ListRepositoriesByOwner(ctx, owner)
+	gt.NoError(t, err)
+
 	// Verify all repositories have the correct owner
 	repoMap := make(map[string]*model.Repository)
 	for _, r := range retrieved {
@@ -260,6 +329,54 @@ func TestBranchCRUD(t *testing.T, repo interfaces.ScanRepository) {
 	gt.True(t, errors.Is(err, repository.ErrNotFound))
 }
 
+// TestClaimBranchScan tests that a branch is not overwritten by a scan started before the stored one
+func TestClaimBranchScan(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:        repoID,
+		Owner:     owner,
+		Name:      repoName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	newBranch := func(scanID types.ScanID, startedAt time.Time) *model.Branch {
+		return &model.Branch{
+			Name:              "main",
+			LastScanID:        scanID,
+			LastScanAt:        now,
+			LastScanStartedAt: startedAt,
+			Status:            types.ScanStatusSuccess,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+	}
+
+	// New branch is created
+	gt.NoError(t, repo.ClaimBranchScan(ctx, repoID, newBranch("scan-2", now)))
+
+	// Scan started before the stored one is rejected
+	err := repo.ClaimBranchScan(ctx, repoID, newBranch("scan-1", now.Add(-time.Minute)))
+	gt.True(t, errors.Is(err, repository.ErrConflict))
+	stored, err := repo.GetBranch(ctx, repoID, "main")
+	gt.NoError(t, err)
+	gt.V(t, stored.LastScanID).Equal(types.ScanID("scan-2"))
+
+	// Scan started at the same time or later is accepted
+	gt.NoError(t, repo.ClaimBranchScan(ctx, repoID, newBranch("scan-2", now)))
+	gt.NoError(t, repo.ClaimBranchScan(ctx, repoID, newBranch("scan-3", now.Add(time.Minute))))
+	stored, err = repo.GetBranch(ctx, repoID, "main")
+	gt.NoError(t, err)
+	gt.V(t, stored.LastScanID).Equal(types.ScanID("scan-3"))
+	gt.True(t, stored.LastScanStartedAt.Equal(now.Add(time.Minute)))
+}
+
 // TestTargetCRUD tests basic CRUD operations for Target
 func TestTargetCRUD(t *testing.T, repo interfaces.ScanRepository) {
 	ctx := context.Background()
@@ -343,6 +460,82 @@ func TestTargetCRUD(t *testing.T, repo interfaces.ScanRepository) {
 	gt.True(t, errors.Is(err, repository.ErrNotFound))
 }
 
+// TestMoveTarget tests moving a target with its vulnerabilities, status history and packages to another ID
+func TestMoveTarget(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoID := types.NewGitHubRepoID(owner, "repo")
+	now := time.Now().UTC().Truncate(time.Second)
+	fromID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+	toID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+	otherID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: owner, Name: "repo", CreatedAt: now, UpdatedAt: now}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main", CreatedAt: now, UpdatedAt: now}))
+	for _, id := range []types.TargetID{fromID, otherID} {
+		gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: id, Target: "go.mod", CreatedAt: now, UpdatedAt: now}))
+		gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", id, []*model.Vulnerability{
+			{ID: "CVE-2021-0001", PkgName: "package1", Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: now, UpdatedAt: now},
+		}))
+	}
+	gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", fromID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2021-0001", From: types.VulnStatusActive, To: types.VulnStatusAcknowledged, Actor: "alice", Timestamp: now},
+	}))
+	gt.NoError(t, repo.BatchUpsertPackages(ctx, repoID, "main", fromID, []*model.Package{
+		{ID: model.ToPackageID("package1", "1.0.0"), Name: "package1", Version: "1.0.0"},
+	}))
+
+	gt.NoError(t, repo.MoveTarget(ctx, repoID, "main", fromID, &model.Target{
+		ID:        toID,
+		Target:    "go.mod",
+		Class:     "lang-pkgs",
+		Type:      "gomod",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	t.Run("target is stored with the new ID", func(t *testing.T) {
+		moved := gt.R1(repo.GetTarget(ctx, repoID, "main", toID)).NoError(t)
+		gt.V(t, moved.ID).Equal(toID)
+		gt.V(t, moved.Class).Equal("lang-pkgs")
+
+		_, err := repo.GetTarget(ctx, repoID, "main", fromID)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		targets := gt.R1(repo.ListTargets(ctx, repoID, "main")).NoError(t)
+		gt.A(t, targets).Length(2)
+	})
+
+	t.Run("vulnerabilities, history and packages are moved", func(t *testing.T) {
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, repoID, "main", toID)).NoError(t)
+		gt.A(t, vulns).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.Status).Equal(types.VulnStatusAcknowledged)
+		})
+		history := gt.R1(repo.ListVulnerabilityHistory(ctx, repoID, "main", toID, "CVE-2021-0001")).NoError(t)
+		gt.A(t, history).Length(1).At(0, func(t testing.TB, v *model.VulnStatusChange) {
+			gt.V(t, v.Actor).Equal("alice")
+		})
+		pkgs := gt.R1(repo.ListPackages(ctx, repoID, "main", toID)).NoError(t)
+		gt.A(t, pkgs).Length(1)
+
+		history = gt.R1(repo.ListVulnerabilityHistory(ctx, repoID, "main", fromID, "CVE-2021-0001")).NoError(t)
+		gt.A(t, history).Length(0)
+	})
+
+	t.Run("other targets are not affected", func(t *testing.T) {
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, repoID, "main", otherID)).NoError(t)
+		gt.A(t, vulns).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.Status).Equal(types.VulnStatusActive)
+		})
+	})
+
+	t.Run("missing target is not found", func(t *testing.T) {
+		err := repo.MoveTarget(ctx, repoID, "main", fromID, &model.Target{ID: toID, Target: "go.mod"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
 // TestVulnerabilityBatchOps tests batch operations for vulnerabilities
 func TestVulnerabilityBatchOps(t *testing.T, repo interfaces.ScanRepository) {
 	ctx := context.Background()
@@ -540,24 +733,710 @@ func TestVulnerabilityStatusUpdate(t *testing.T, repo interfaces.ScanRepository)
 	gt.NoError(t, err)
 
 	// Update status to fixed
-	updates := map[string]types.VulnStatus{
-		"CVE-2021-0001": types.VulnStatusFixed,
+	err = repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2021-0001", From: types.VulnStatusActive, To: types.VulnStatusFixed, Actor: "octovy", Timestamp: now},
+	})
+	gt.NoError(t, err)
+
+	getVulns := func() map[string]*model.Vulnerability {
+		retrieved, err := repo.ListVulnerabilities(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+
+		vulnMap := make(map[string]*model.Vulnerability)
+		for _, v := range retrieved {
+			vulnMap[v.ID] = v
+		}
+		return vulnMap
+	}
+
+	// Verify status update
+	vulnMap := getVulns()
+	gt.V(t, vulnMap["CVE-2021-0001"].Status).Equal(types.VulnStatusFixed)
+	gt.V(t, vulnMap["CVE-2021-0002"].Status).Equal(types.VulnStatusActive)
+
+	// Acknowledge with who, comment and expiration
+	ack := &model.Acknowledgement{
+		By:        "alice",
+		Comment:   "not reachable",
+		At:        now,
+		ExpiresAt: now.Add(30 * 24 * time.Hour),
 	}
+	err = repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2021-0002", From: types.VulnStatusActive, To: types.VulnStatusAcknowledged, Actor: "alice", Comment: "not reachable", Acknowledgement: ack, Timestamp: now.Add(time.Second)},
+	})
+	gt.NoError(t, err)
 
-	err = repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, updates)
+	vulnMap = getVulns()
+	gt.V(t, vulnMap["CVE-2021-0002"].Status).Equal(types.VulnStatusAcknowledged)
+	gt.NotNil(t, vulnMap["CVE-2021-0002"].Acknowledgement)
+	gt.V(t, vulnMap["CVE-2021-0002"].Acknowledgement.By).Equal("alice")
+	gt.V(t, vulnMap["CVE-2021-0002"].Acknowledgement.Comment).Equal("not reachable")
+	gt.V(t, vulnMap["CVE-2021-0002"].Acknowledgement.ExpiresAt.Unix()).Equal(ack.ExpiresAt.Unix())
+
+	// Acknowledgement is kept while fixed, and cleared when active again
+	err = repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2021-0002", From: types.VulnStatusAcknowledged, To: types.VulnStatusFixed, Actor: "octovy", Timestamp: now.Add(2 * time.Second)},
+	})
 	gt.NoError(t, err)
+	gt.NotNil(t, getVulns()["CVE-2021-0002"].Acknowledgement)
+
+	err = repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+		{VulnID: "CVE-2021-0002", From: types.VulnStatusFixed, To: types.VulnStatusActive, Actor: "octovy", Timestamp: now.Add(3 * time.Second)},
+	})
+	gt.NoError(t, err)
+	gt.Nil(t, getVulns()["CVE-2021-0002"].Acknowledgement)
+
+	// All changes are recorded in the history, oldest first
+	history, err := repo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2021-0002")
+	gt.NoError(t, err)
+	gt.A(t, history).Length(3)
+	gt.V(t, history[0].To).Equal(types.VulnStatusAcknowledged)
+	gt.V(t, history[0].Actor).Equal("alice")
+	gt.NotNil(t, history[0].Acknowledgement)
+	gt.V(t, history[1].To).Equal(types.VulnStatusFixed)
+	gt.V(t, history[2].From).Equal(types.VulnStatusFixed)
+	gt.V(t, history[2].To).Equal(types.VulnStatusActive)
+
+	history, err = repo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2021-0001")
+	gt.NoError(t, err)
+	gt.A(t, history).Length(1)
+}
+
+// TestVulnerabilityBatchDelete tests batch deletion of vulnerabilities
+func TestVulnerabilityBatchDelete(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	// Generate unique IDs for this test
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+	targetID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+
+	// Setup: create repository, branch, target, and vulnerabilities
+	now := time.Now()
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:             repoID,
+		Owner:          owner,
+		Name:           repoName,
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+		Name:          "main",
+		LastScanID:    "scan-123",
+		LastScanAt:    now,
+		LastCommitSHA: "abc123",
+		Status:        types.ScanStatusSuccess,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}))
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{
+		ID:        targetID,
+		Target:    "go.mod",
+		Class:     "lang-pkgs",
+		Type:      "gomod",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	var vulns []*model.Vulnerability
+	for i := 1; i <= 3; i++ {
+		vulns = append(vulns, &model.Vulnerability{
+			ID:        fmt.Sprintf("CVE-2021-000%d", i),
+			PkgName:   fmt.Sprintf("package%d", i),
+			Severity:  "HIGH",
+			Status:    types.VulnStatusFixed,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", targetID, vulns))
+
+	// Delete two of them, including an ID that does not exist
+	gt.NoError(t, repo.BatchDeleteVulnerabilities(ctx, repoID, "main", targetID, []string{"CVE-2021-0001", "CVE-2021-0003", "CVE-2021-9999"}))
 
-	// Verify status update
 	retrieved, err := repo.ListVulnerabilities(ctx, repoID, "main", targetID)
 	gt.NoError(t, err)
+	gt.A(t, retrieved).Length(1)
+	gt.V(t, retrieved[0].ID).Equal("CVE-2021-0002")
+}
 
-	vulnMap := make(map[string]*model.Vulnerability)
-	for _, v := range retrieved {
-		vulnMap[v.ID] = v
+// TestRepositoryVulnerabilities tests listing vulnerabilities of all branches and targets of a repository
+func TestRepositoryVulnerabilities(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+
+	now := time.Now()
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:             repoID,
+		Owner:          owner,
+		Name:           repoName,
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}))
+
+	for _, branchName := range []types.BranchName{"main", "feature/x"} {
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:      branchName,
+			Status:    types.ScanStatusSuccess,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+		for _, target := range []string{"go.mod", "web/package-lock.json"} {
+			targetID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+			gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, branchName, &model.Target{
+				ID:        targetID,
+				Target:    target,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}))
+			gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, branchName, targetID, []*model.Vulnerability{
+				{ID: "CVE-2024-0001", PkgName: target, Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: now, UpdatedAt: now},
+			}))
+		}
 	}
 
-	gt.V(t, vulnMap["CVE-2021-0001"].Status).Equal(types.VulnStatusFixed)
-	gt.V(t, vulnMap["CVE-2021-0002"].Status).Equal(types.VulnStatusActive)
+	vulns, err := repo.ListRepositoryVulnerabilities(ctx, repoID)
+	gt.NoError(t, err)
+	gt.A(t, vulns).Length(4)
+
+	seen := make(map[string]bool)
+	for _, v := range vulns {
+		gt.V(t, v.Vulnerability.ID).Equal("CVE-2024-0001")
+		gt.V(t, v.Vulnerability.PkgName).Equal(v.Target.Target)
+		seen[string(v.Branch)+":"+v.Target.Target] = true
+	}
+	gt.V(t, seen).Equal(map[string]bool{
+		"main:go.mod":                     true,
+		"main:web/package-lock.json":      true,
+		"feature/x:go.mod":                true,
+		"feature/x:web/package-lock.json": true,
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.ListRepositoryVulnerabilities(ctx, types.GitHubRepoID(owner+"/no-such-repo"))
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+// TestPackageBatchOps tests upserting, listing and deleting packages of a target
+func TestPackageBatchOps(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+	targetID := types.TargetID(fmt.Sprintf("target-%s", uuid.New().String()[:8]))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:             repoID,
+		Owner:          owner,
+		Name:           repoName,
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+		Name:      "main",
+		Status:    types.ScanStatusSuccess,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{
+		ID:        targetID,
+		Target:    "go.mod",
+		Class:     "lang-pkgs",
+		Type:      "gomod",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	var pkgs []*model.Package
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("example.com/package%d", i)
+		pkgs = append(pkgs, &model.Package{
+			ID:        model.ToPackageID(name, "v1.0.0"),
+			Name:      name,
+			Version:   "v1.0.0",
+			Licenses:  []string{"MIT"},
+			PURL:      fmt.Sprintf("pkg:golang/%s@v1.0.0", name),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	gt.NoError(t, repo.BatchUpsertPackages(ctx, repoID, "main", targetID, pkgs))
+
+	retrieved, err := repo.ListPackages(ctx, repoID, "main", targetID)
+	gt.NoError(t, err)
+	gt.A(t, retrieved).Length(3)
+
+	t.Run("upsert overwrites existing package", func(t *testing.T) {
+		updated := *pkgs[0]
+		updated.Licenses = []string{"Apache-2.0"}
+		updated.UpdatedAt = now.Add(time.Hour)
+		gt.NoError(t, repo.BatchUpsertPackages(ctx, repoID, "main", targetID, []*model.Package{&updated}))
+
+		retrieved, err := repo.ListPackages(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		gt.A(t, retrieved).Length(3)
+		for _, pkg := range retrieved {
+			if pkg.ID == updated.ID {
+				gt.A(t, pkg.Licenses).Equal([]string{"Apache-2.0"})
+				gt.V(t, pkg.Name).Equal(updated.Name)
+			}
+		}
+	})
+
+	t.Run("delete removes packages and ignores unknown IDs", func(t *testing.T) {
+		gt.NoError(t, repo.BatchDeletePackages(ctx, repoID, "main", targetID, []string{pkgs[0].ID, pkgs[2].ID, "no-such-package"}))
+
+		retrieved, err := repo.ListPackages(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		gt.A(t, retrieved).Length(1)
+		gt.V(t, retrieved[0].ID).Equal(pkgs[1].ID)
+	})
+}
+
+// TestDeleteRepository tests deleting a repository with all of its documents
+func TestDeleteRepository(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	now := time.Now().UTC().Truncate(time.Second)
+	targetID := types.TargetID("go.mod")
+
+	var repoIDs []types.GitHubRepoID
+	for _, name := range []string{"deleted", "kept"} {
+		repoID := types.NewGitHubRepoID(owner, name)
+		repoIDs = append(repoIDs, repoID)
+
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:             repoID,
+			Owner:          owner,
+			Name:           name,
+			DefaultBranch:  "main",
+			InstallationID: 12345,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}))
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:       "main",
+			LastScanID: "scan-123",
+			Status:     types.ScanStatusSuccess,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}))
+		gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{
+			ID:        targetID,
+			Target:    "go.mod",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+		gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", targetID, []*model.Vulnerability{
+			{ID: "CVE-2021-0001", PkgName: "package1", Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: now, UpdatedAt: now},
+		}))
+		gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+			{VulnID: "CVE-2021-0001", From: types.VulnStatusActive, To: types.VulnStatusFixed, Timestamp: now},
+		}))
+		gt.NoError(t, repo.CreateScanRecord(ctx, repoID, &model.ScanRecord{
+			ID:        types.ScanID(uuid.New().String()),
+			Branch:    "main",
+			Timestamp: now,
+		}))
+	}
+
+	gt.NoError(t, repo.DeleteRepository(ctx, repoIDs[0]))
+
+	_, err := repo.GetRepository(ctx, repoIDs[0])
+	gt.True(t, errors.Is(err, repository.ErrNotFound))
+	repos, err := repo.ListRepositoriesByOwner(ctx, owner)
+	gt.NoError(t, err)
+	gt.A(t, repos).Length(1)
+	gt.V(t, repos[0].ID).Equal(repoIDs[1])
+
+	// Recreated repository does not inherit documents of the deleted one
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:        repoIDs[0],
+		Owner:     owner,
+		Name:      "deleted",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+	branches, err := repo.ListBranches(ctx, repoIDs[0])
+	gt.NoError(t, err)
+	gt.A(t, branches).Length(0)
+	records, err := repo.ListScanRecords(ctx, repoIDs[0])
+	gt.NoError(t, err)
+	gt.A(t, records).Length(0)
+
+	// Other repositories are not affected
+	vulns, err := repo.ListVulnerabilities(ctx, repoIDs[1], "main", targetID)
+	gt.NoError(t, err)
+	gt.A(t, vulns).Length(1)
+	records, err = repo.ListScanRecords(ctx, repoIDs[1])
+	gt.NoError(t, err)
+	gt.A(t, records).Length(1)
+
+	// Deleting a missing repository is not an error
+	gt.NoError(t, repo.DeleteRepository(ctx, types.NewGitHubRepoID(owner, "missing")))
+}
+
+// TestScanRecordOps tests creating, listing and deleting scan history records
+func TestScanRecordOps(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	// Generate unique IDs for this test
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:             repoID,
+		Owner:          owner,
+		Name:           repoName,
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}))
+
+	records := []*model.ScanRecord{
+		{
+			ID:                 types.ScanID(uuid.New().String()),
+			Branch:             "main",
+			CommitSHA:          "1111111111111111111111111111111111111111",
+			Timestamp:          now.Add(-48 * time.Hour),
+			TargetCount:        2,
+			VulnerabilityCount: 5,
+		},
+		{
+			ID:                    types.ScanID(uuid.New().String()),
+			Branch:                "feature/foo",
+			CommitSHA:             "2222222222222222222222222222222222222222",
+			Timestamp:             now,
+			TargetCount:           3,
+			VulnerabilityCount:    1,
+			MisconfigurationCount: 2,
+			SecretCount:           1,
+		},
+		{
+			ID:        types.ScanID(uuid.New().String()),
+			Branch:    "main",
+			CommitSHA: "3333333333333333333333333333333333333333",
+			Timestamp: now.Add(-24 * time.Hour),
+		},
+	}
+	for _, record := range records {
+		gt.NoError(t, repo.CreateScanRecord(ctx, repoID, record))
+	}
+
+	// Records are listed newest first
+	retrieved, err := repo.ListScanRecords(ctx, repoID)
+	gt.NoError(t, err)
+	gt.A(t, retrieved).Length(3)
+	gt.V(t, retrieved[0].ID).Equal(records[1].ID)
+	gt.V(t, retrieved[1].ID).Equal(records[2].ID)
+	gt.V(t, retrieved[2].ID).Equal(records[0].ID)
+
+	gt.V(t, retrieved[0].Branch).Equal(types.BranchName("feature/foo"))
+	gt.V(t, retrieved[0].CommitSHA).Equal(records[1].CommitSHA)
+	gt.True(t, retrieved[0].Timestamp.Equal(now))
+	gt.V(t, retrieved[0].TargetCount).Equal(3)
+	gt.V(t, retrieved[0].VulnerabilityCount).Equal(1)
+	gt.V(t, retrieved[0].MisconfigurationCount).Equal(2)
+	gt.V(t, retrieved[0].SecretCount).Equal(1)
+	gt.V(t, retrieved[0].RepoID).Equal(repoID)
+
+	// Delete the oldest two records
+	gt.NoError(t, repo.BatchDeleteScanRecords(ctx, repoID, []types.ScanID{records[0].ID, records[2].ID}))
+
+	retrieved, err = repo.ListScanRecords(ctx, repoID)
+	gt.NoError(t, err)
+	gt.A(t, retrieved).Length(1)
+	gt.V(t, retrieved[0].ID).Equal(records[1].ID)
+}
+
+func TestScanRecordsByCommitter(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	login := fmt.Sprintf("user-%s", uuid.New().String()[:8])
+	now := time.Now().UTC().Truncate(time.Second)
+
+	var repoIDs []types.GitHubRepoID
+	for _, name := range []string{"repo-a", "repo-b"} {
+		repoID := types.NewGitHubRepoID(owner, name)
+		repoIDs = append(repoIDs, repoID)
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:        repoID,
+			Owner:     owner,
+			Name:      name,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+	}
+
+	newRecord := func(committer string, ts time.Time) *model.ScanRecord {
+		return &model.ScanRecord{
+			ID:             types.ScanID(uuid.New().String()),
+			Branch:         "main",
+			CommitSHA:      "1111111111111111111111111111111111111111",
+			CommitterLogin: committer,
+			Timestamp:      ts,
+		}
+	}
+	recent := newRecord(login, now.Add(-time.Hour))
+	latest := newRecord(login, now)
+	old := newRecord(login, now.Add(-30*24*time.Hour))
+	other := newRecord("other-"+login, now)
+
+	gt.NoError(t, repo.CreateScanRecord(ctx, repoIDs[0], recent))
+	gt.NoError(t, repo.CreateScanRecord(ctx, repoIDs[0], old))
+	gt.NoError(t, repo.CreateScanRecord(ctx, repoIDs[1], latest))
+	gt.NoError(t, repo.CreateScanRecord(ctx, repoIDs[1], other))
+
+	records, err := repo.ListScanRecordsByCommitter(ctx, login, now.Add(-7*24*time.Hour))
+	gt.NoError(t, err)
+	gt.A(t, records).Length(2)
+	gt.V(t, records[0].ID).Equal(latest.ID)
+	gt.V(t, records[0].RepoID).Equal(repoIDs[1])
+	gt.V(t, records[1].ID).Equal(recent.ID)
+	gt.V(t, records[1].RepoID).Equal(repoIDs[0])
+
+	records, err = repo.ListScanRecordsByCommitter(ctx, "nobody-"+login, now.Add(-7*24*time.Hour))
+	gt.NoError(t, err)
+	gt.A(t, records).Length(0)
+}
+
+// TestSeverityOverrideOps tests put, list and delete of severity overrides of an owner
+func TestSeverityOverrideOps(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	now := time.Now().UTC().Truncate(time.Second)
+
+	byPkg := &model.SeverityOverride{Owner: owner, PkgName: "github.com/example/lib", Severity: types.SeverityCritical, Reason: "used for payment", By: "alice", CreatedAt: now, UpdatedAt: now}
+	byVuln := &model.SeverityOverride{Owner: owner, VulnID: "CVE-2024-0001", Severity: types.SeverityLow, By: "bob", CreatedAt: now, UpdatedAt: now}
+	other := &model.SeverityOverride{Owner: "other-" + owner, VulnID: "CVE-2024-0001", Severity: types.SeverityHigh, CreatedAt: now, UpdatedAt: now}
+	gt.NoError(t, repo.PutSeverityOverride(ctx, byPkg))
+	gt.NoError(t, repo.PutSeverityOverride(ctx, byVuln))
+	gt.NoError(t, repo.PutSeverityOverride(ctx, other))
+
+	overrides, err := repo.ListSeverityOverrides(ctx, owner)
+	gt.NoError(t, err)
+	gt.A(t, overrides).Length(2)
+	gt.V(t, overrides[0]).Equal(byPkg)
+	gt.V(t, overrides[1]).Equal(byVuln)
+
+	t.Run("put overwrites override of the same vulnerability and package", func(t *testing.T) {
+		updated := *byVuln
+		updated.Severity = types.SeverityMedium
+		updated.UpdatedAt = now.Add(time.Hour)
+		gt.NoError(t, repo.PutSeverityOverride(ctx, &updated))
+
+		overrides, err := repo.ListSeverityOverrides(ctx, owner)
+		gt.NoError(t, err)
+		gt.A(t, overrides).Length(2)
+		gt.V(t, overrides[1].Severity).Equal(types.SeverityMedium)
+		gt.V(t, overrides[1].CreatedAt).Equal(now)
+	})
+
+	t.Run("delete removes only the override", func(t *testing.T) {
+		gt.NoError(t, repo.DeleteSeverityOverride(ctx, owner, byPkg.ID()))
+
+		overrides, err := repo.ListSeverityOverrides(ctx, owner)
+		gt.NoError(t, err)
+		gt.A(t, overrides).Length(1)
+		gt.V(t, overrides[0].VulnID).Equal("CVE-2024-0001")
+
+		overrides, err = repo.ListSeverityOverrides(ctx, other.Owner)
+		gt.NoError(t, err)
+		gt.A(t, overrides).Length(1)
+
+		err = repo.DeleteSeverityOverride(ctx, owner, byPkg.ID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+// TestScanRunOps tests put and get of scan runs
+func TestScanRunOps(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	run := &model.ScanRun{
+		ID:        types.NewScanRunID(),
+		Status:    types.ScanRunQueued,
+		Trigger:   types.ScanTriggerPush,
+		RequestID: types.NewRequestID(),
+		Owner:     fmt.Sprintf("owner-%s", uuid.New().String()[:8]),
+		RepoName:  "repo",
+		Branch:    "main",
+		CommitID:  "1234567890abcdef1234567890abcdef12345678",
+		CreatedAt: now,
+		ExpiresAt: now.Add(model.ScanRunRetention),
+	}
+	gt.NoError(t, repo.PutScanRun(ctx, run))
+
+	got, err := repo.GetScanRun(ctx, run.ID)
+	gt.NoError(t, err)
+	gt.V(t, got).Equal(run)
+
+	t.Run("put overwrites the run", func(t *testing.T) {
+		finished := *run
+		finished.Status = types.ScanRunSucceeded
+		finished.ScanID = types.NewScanID()
+		finished.StartedAt = now.Add(time.Second)
+		finished.FinishedAt = now.Add(time.Minute)
+		gt.NoError(t, repo.PutScanRun(ctx, &finished))
+
+		got, err := repo.GetScanRun(ctx, run.ID)
+		gt.NoError(t, err)
+		gt.V(t, got).Equal(&finished)
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		_, err := repo.GetScanRun(ctx, types.NewScanRunID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+func TestOwnerScanRunOps(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	run := model.NewOwnerScanRun(owner, model.OwnerScanSourceGitHub, types.ScanTriggerSchedule, types.NewRequestID(), now)
+	gt.NoError(t, repo.PutOwnerScanRun(ctx, run))
+
+	got, err := repo.GetOwnerScanRun(ctx, run.ID)
+	gt.NoError(t, err)
+	gt.V(t, got.Owner).Equal(owner)
+	gt.V(t, got.Status).Equal(types.ScanRunRunning)
+	gt.A(t, got.Repositories).Length(0)
+
+	t.Run("put overwrites the run with results of repositories", func(t *testing.T) {
+		finished := *run
+		finished.Add(&model.OwnerScanRepoResult{
+			Owner:      owner,
+			Repo:       "app",
+			Branch:     "main",
+			CommitID:   "1234567890abcdef1234567890abcdef12345678",
+			Status:     types.ScanRunSucceeded,
+			ScanID:     types.NewScanID(),
+			Findings:   &model.FindingSummary{Vulnerabilities: model.SeverityCounts{High: 2, Total: 2}, Secrets: 1},
+			DurationMS: 1500,
+		})
+		finished.Add(&model.OwnerScanRepoResult{
+			Owner:  owner,
+			Repo:   "lib",
+			Branch: "main",
+			Status: types.ScanRunFailed,
+			Error:  "failed to download archive",
+		})
+		finished.Finish(nil, now.Add(time.Minute))
+		gt.NoError(t, repo.PutOwnerScanRun(ctx, &finished))
+
+		got, err := repo.GetOwnerScanRun(ctx, run.ID)
+		gt.NoError(t, err)
+		gt.V(t, got).Equal(&finished)
+		gt.V(t, got.Status).Equal(types.ScanRunFailed)
+		gt.V(t, got.Succeeded).Equal(1)
+		gt.V(t, got.Failed).Equal(1)
+		gt.A(t, got.Repositories).Length(2).At(0, func(t testing.TB, v *model.OwnerScanRepoResult) {
+			gt.V(t, v.Repo).Equal("app")
+			gt.V(t, v.Findings.Vulnerabilities.High).Equal(2)
+		})
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		_, err := repo.GetOwnerScanRun(ctx, types.NewOwnerScanRunID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+// TestRejectInvalidIdentifiers tests that malformed repository IDs and branch names are rejected
+// instead of creating documents that can not be looked up
+func TestRejectInvalidIdentifiers(t *testing.T, repo interfaces.ScanRepository) {
+	ctx := context.Background()
+
+	// Generate unique IDs for this test
+	owner := fmt.Sprintf("owner-%s", uuid.New().String()[:8])
+	repoName := fmt.Sprintf("repo-%s", uuid.New().String()[:8])
+	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", owner, repoName))
+	now := time.Now()
+
+	newRepo := func(id types.GitHubRepoID, owner, name string) *model.Repository {
+		return &model.Repository{
+			ID:             id,
+			Owner:          owner,
+			Name:           name,
+			DefaultBranch:  "main",
+			InstallationID: 12345,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+
+	// Invalid repositories
+	for _, invalid := range []*model.Repository{
+		newRepo(types.GitHubRepoID(owner), owner, ""),
+		newRepo(types.GitHubRepoID(owner+"/"+repoName+"/extra"), owner, repoName),
+		newRepo(types.GitHubRepoID(owner+"/other"), owner, repoName),
+		newRepo(types.GitHubRepoID(owner+"/re po"), owner, "re po"),
+	} {
+		err := repo.CreateOrUpdateRepository(ctx, invalid)
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrValidationFailed))
+	}
+
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, newRepo(repoID, owner, repoName)))
+
+	// Invalid branch names
+	for _, name := range []types.BranchName{"", "refs/heads/main", "feature//foo", "has space", "a..b", "main.lock"} {
+		err := repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:       name,
+			LastScanID: "scan-123",
+			LastScanAt: now,
+			Status:     types.ScanStatusSuccess,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrValidationFailed))
+	}
+
+	// Children of a branch are validated as well
+	err := repo.CreateOrUpdateTarget(ctx, repoID, "refs/heads/main", &model.Target{
+		ID:        "target",
+		Target:    "go.mod",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	gt.Error(t, err)
+	gt.True(t, errors.Is(err, types.ErrValidationFailed))
+
+	err = repo.BatchCreateVulnerabilities(ctx, repoID, "refs/heads/main", "target", []*model.Vulnerability{{ID: "CVE-2021-0001"}})
+	gt.Error(t, err)
+	gt.True(t, errors.Is(err, types.ErrValidationFailed))
+
+	err = repo.CreateScanRecord(ctx, types.GitHubRepoID(owner), &model.ScanRecord{ID: "scan-123", Timestamp: now})
+	gt.Error(t, err)
+	gt.True(t, errors.Is(err, types.ErrValidationFailed))
+
+	// Rejected writes must not leave branches behind
+	branches, err := repo.ListBranches(ctx, repoID)
+	gt.NoError(t, err)
+	gt.A(t, branches).Length(0)
 }
 
 // TestBranchWithSlash tests branch names containing "/" which must be safely converted for Firestore
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ValidateRepository checks the repository ID and that it matches owner and name, which are
+// used as the document key by the Firestore implementation.
+func ValidateRepository(repo *model.Repository) error {
+	if err := repo.ID.Validate(); err != nil {
+		return err
+	}
+	if repo.ID != types.NewGitHubRepoID(repo.Owner, repo.Name) {
+		return goerr.Wrap(types.ErrValidationFailed, "repository ID does not match owner and name",
+			goerr.V("repoID", repo.ID),
+			goerr.V("owner", repo.Owner),
+			goerr.V("name", repo.Name),
+		)
+	}
+	return nil
+}
+
+// ValidateBranchKey checks identifiers used as keys of a branch and its children. Invalid
+// values would create documents that can not be found by normalized lookups.
+func ValidateBranchKey(repoID types.GitHubRepoID, branchName types.BranchName) error {
+	if err := repoID.Validate(); err != nil {
+		return err
+	}
+	return branchName.Validate()
+}
@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+func TestValidateRepository(t *testing.T) {
+	testCases := map[string]struct {
+		repo  *model.Repository
+		valid bool
+	}{
+		"valid repository": {
+			repo:  &model.Repository{ID: "myorg/myrepo", Owner: "myorg", Name: "myrepo"},
+			valid: true,
+		},
+		"repository name with dot and underscore": {
+			repo:  &model.Repository{ID: "my-org/my_repo.go", Owner: "my-org", Name: "my_repo.go"},
+			valid: true,
+		},
+		"ID not matching owner": {
+			repo: &model.Repository{ID: "myorg/myrepo", Owner: "other", Name: "myrepo"},
+		},
+		"ID not matching name": {
+			repo: &model.Repository{ID: "myorg/myrepo", Owner: "myorg", Name: "other"},
+		},
+		"ID without name": {
+			repo: &model.Repository{ID: "myorg", Owner: "myorg"},
+		},
+		"empty ID": {
+			repo: &model.Repository{},
+		},
+		"ID with path traversal": {
+			repo: &model.Repository{ID: "myorg/..", Owner: "myorg", Name: ".."},
+		},
+		"ID with extra slash": {
+			repo: &model.Repository{ID: "myorg/myrepo/sub", Owner: "myorg", Name: "myrepo/sub"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := repository.ValidateRepository(tc.repo)
+			if tc.valid {
+				gt.NoError(t, err)
+			} else {
+				gt.True(t, errors.Is(err, types.ErrValidationFailed))
+			}
+		})
+	}
+}
+
+func TestValidateBranchKey(t *testing.T) {
+	testCases := map[string]struct {
+		repoID types.GitHubRepoID
+		branch types.BranchName
+		valid  bool
+	}{
+		"valid key": {
+			repoID: "myorg/myrepo",
+			branch: "main",
+			valid:  true,
+		},
+		"branch with slash": {
+			repoID: "myorg/myrepo",
+			branch: "feature/x",
+			valid:  true,
+		},
+		"invalid repository ID": {
+			repoID: "myorg",
+			branch: "main",
+		},
+		"empty branch": {
+			repoID: "myorg/myrepo",
+		},
+		"branch not normalized": {
+			repoID: "myorg/myrepo",
+			branch: "refs/heads/main",
+		},
+		"branch with double dots": {
+			repoID: "myorg/myrepo",
+			branch: "feature/../main",
+		},
+		"branch with empty component": {
+			repoID: "myorg/myrepo",
+			branch: "feature//x",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := repository.ValidateBranchKey(tc.repoID, tc.branch)
+			if tc.valid {
+				gt.NoError(t, err)
+			} else {
+				gt.True(t, errors.Is(err, types.ErrValidationFailed))
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// AcknowledgeVulnerability accepts the risk of a vulnerability that is still detected, or revokes the acceptance, and returns the number of updated findings. The acknowledgement is preserved across scans until it expires.
+func (x *UseCase) AcknowledgeVulnerability(ctx context.Context, input *model.AcknowledgeVulnerabilityInput) (int, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "acknowledgement requires Firestore")
+	}
+	if input.VulnID == "" {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "vulnerability ID is empty")
+	}
+	if !input.Revoke && (input.By == "" || input.Comment == "") {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "acknowledgement requires who and why", goerr.V("by", input.By), goerr.V("comment", input.Comment))
+	}
+
+	now := logging.CtxTime(ctx)
+	if !input.ExpiresAt.IsZero() && !input.ExpiresAt.After(now) {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "expiration is in the past", goerr.V("expires_at", input.ExpiresAt))
+	}
+
+	return x.changeVulnerabilityStatus(ctx, &vulnSelector{
+		owner:  input.Owner,
+		repo:   input.Repo,
+		branch: input.Branch,
+		target: input.Target,
+		vulnID: input.VulnID,
+	}, func(vuln *model.Vulnerability) *model.VulnStatusChange {
+		return newAcknowledgementChange(vuln, input, now)
+	})
+}
+
+// vulnSelector selects findings of a vulnerability in a branch. The default branch is selected if branch is empty, and all targets if target is empty.
+type vulnSelector struct {
+	owner  string
+	repo   string
+	branch types.BranchName
+	target string
+	vulnID string
+}
+
+// changeVulnerabilityStatus applies the change returned by newChange to each selected finding and returns the number of changed findings. newChange returns nil if the finding is not changed. It returns repository.ErrNotFound if no finding is selected.
+func (x *UseCase) changeVulnerabilityStatus(ctx context.Context, sel *vulnSelector, newChange func(vuln *model.Vulnerability) *model.VulnStatusChange) (int, error) {
+	scanRepo := x.clients.ScanRepository()
+	repoID := types.NewGitHubRepoID(sel.owner, sel.repo)
+	branchName := sel.branch
+	if branchName == "" {
+		repo, err := scanRepo.GetRepository(ctx, repoID)
+		if err != nil {
+			return 0, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+		}
+		branchName = repo.DefaultBranch
+	}
+
+	targets, err := scanRepo.ListTargets(ctx, repoID, branchName)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	var found, updated int
+	defer func() {
+		if updated > 0 {
+			refreshFindingSummary(ctx, scanRepo, repoID, branchName)
+		}
+	}()
+	for _, target := range targets {
+		if sel.target != "" && target.Target != sel.target {
+			continue
+		}
+
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branchName, target.ID)
+		if err != nil {
+			return updated, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("target", target.Target))
+		}
+
+		for _, vuln := range vulns {
+			if vuln.ID != sel.vulnID {
+				continue
+			}
+			found++
+
+			change := newChange(vuln)
+			if change == nil {
+				continue
+			}
+			if err := scanRepo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, target.ID, []*model.VulnStatusChange{change}); err != nil {
+				return updated, goerr.Wrap(err, "failed to update vulnerability status", goerr.V("target", target.Target))
+			}
+			updated++
+
+			logging.From(ctx).Info("Vulnerability status changed",
+				slog.Any("repo_id", repoID),
+				slog.Any("branch", branchName),
+				slog.String("target", target.Target),
+				slog.String("vuln_id", vuln.ID),
+				slog.Any("from", change.From),
+				slog.Any("to", change.To),
+				slog.String("by", change.Actor),
+				slog.String("comment", change.Comment),
+			)
+		}
+	}
+
+	if found == 0 {
+		return 0, goerr.Wrap(repository.ErrNotFound, "vulnerability not found",
+			goerr.V("repo_id", repoID),
+			goerr.V("branch", branchName),
+			goerr.V("target", sel.target),
+			goerr.V("vuln_id", sel.vulnID),
+		)
+	}
+
+	return updated, nil
+}
+
+// newAcknowledgementChange returns the status change of the vulnerability for the input, or nil if the vulnerability is not subject to it. Fixed vulnerabilities are not changed because only detected vulnerabilities can be acknowledged.
+func newAcknowledgementChange(vuln *model.Vulnerability, input *model.AcknowledgeVulnerabilityInput, now time.Time) *model.VulnStatusChange {
+	change := &model.VulnStatusChange{
+		VulnID:    vuln.ID,
+		From:      vuln.Status,
+		Actor:     input.By,
+		Comment:   input.Comment,
+		Timestamp: now,
+	}
+
+	if input.Revoke {
+		if vuln.Status != types.VulnStatusAcknowledged {
+			return nil
+		}
+		change.To = types.VulnStatusActive
+		if change.Actor == "" {
+			change.Actor = model.StatusChangeActorOctovy
+		}
+		if change.Comment == "" {
+			change.Comment = "acknowledgement revoked"
+		}
+		return change
+	}
+
+	// Acknowledging an acknowledged vulnerability again updates who, why and the expiration
+	if vuln.Status == types.VulnStatusFixed {
+		return nil
+	}
+	change.To = types.VulnStatusAcknowledged
+	change.Acknowledgement = &model.Acknowledgement{
+		By:        input.By,
+		Comment:   input.Comment,
+		At:        now,
+		ExpiresAt: input.ExpiresAt,
+	}
+	return change
+}
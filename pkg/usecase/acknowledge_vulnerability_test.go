@@ -0,0 +1,251 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestAcknowledgeVulnerability(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	targetID := model.ToTargetID("go.mod", "", "")
+
+	setup := func(t *testing.T) (*usecase.UseCase, interfaces.ScanRepository, func(vulnIDs ...string)) {
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+		scan := func(vulnIDs ...string) {
+			var vulns []trivy.DetectedVulnerability
+			for _, id := range vulnIDs {
+				vulns = append(vulns, trivy.DetectedVulnerability{VulnerabilityID: id, PkgName: "pkg-" + id, InstalledVersion: "1.0.0"})
+			}
+			_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+					Branch:     "main",
+					CommitID:   "0000000000000000000000000000000000000000",
+				},
+				DefaultBranch: "main",
+			}, trivy.Report{
+				SchemaVersion: 2,
+				ArtifactName:  "test-repo",
+				Results:       []trivy.Result{{Target: "go.mod", Vulnerabilities: vulns}},
+			})
+			gt.NoError(t, err)
+		}
+		scan("CVE-2024-0001", "CVE-2024-0002")
+
+		return uc, memRepo, scan
+	}
+
+	getVuln := func(t *testing.T, repo interfaces.ScanRepository, vulnID string) *model.Vulnerability {
+		vulns, err := repo.ListVulnerabilities(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		for _, v := range vulns {
+			if v.ID == vulnID {
+				return v
+			}
+		}
+		t.Fatalf("vulnerability %s not found", vulnID)
+		return nil
+	}
+
+	acknowledge := func(expiresAt time.Time) *model.AcknowledgeVulnerabilityInput {
+		return &model.AcknowledgeVulnerabilityInput{
+			Owner:     "test-owner",
+			Repo:      "test-repo",
+			VulnID:    "CVE-2024-0001",
+			By:        "alice",
+			Comment:   "not reachable",
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	t.Run("acknowledgement is preserved across scans", func(t *testing.T) {
+		uc, repo, scan := setup(t)
+
+		updated, err := uc.AcknowledgeVulnerability(ctx, acknowledge(time.Now().Add(24*time.Hour)))
+		gt.NoError(t, err)
+		gt.V(t, updated).Equal(1)
+
+		vuln := getVuln(t, repo, "CVE-2024-0001")
+		gt.V(t, vuln.Status).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, vuln.Acknowledgement.By).Equal("alice")
+		gt.V(t, vuln.Acknowledgement.Comment).Equal("not reachable")
+		gt.V(t, getVuln(t, repo, "CVE-2024-0002").Status).Equal(types.VulnStatusActive)
+
+		// Still detected
+		scan("CVE-2024-0001", "CVE-2024-0002")
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Status).Equal(types.VulnStatusAcknowledged)
+
+		// Fixed, then re-detected
+		scan("CVE-2024-0002")
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Status).Equal(types.VulnStatusFixed)
+		scan("CVE-2024-0001", "CVE-2024-0002")
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Status).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Acknowledgement.By).Equal("alice")
+
+		history, err := repo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2024-0001")
+		gt.NoError(t, err)
+		gt.A(t, history).Length(3)
+		gt.V(t, history[0].Actor).Equal("alice")
+		gt.V(t, history[1].To).Equal(types.VulnStatusFixed)
+		gt.V(t, history[1].Actor).Equal(model.StatusChangeActorOctovy)
+		gt.V(t, history[2].To).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, history[2].ScanID).NotEqual(types.ScanID(""))
+	})
+
+	t.Run("expired acknowledgement becomes active on next scan", func(t *testing.T) {
+		_, repo, scan := setup(t)
+
+		past := time.Now().Add(-time.Hour)
+		gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", targetID, []*model.VulnStatusChange{
+			{
+				VulnID: "CVE-2024-0001",
+				From:   types.VulnStatusActive,
+				To:     types.VulnStatusAcknowledged,
+				Actor:  "alice",
+				Acknowledgement: &model.Acknowledgement{
+					By: "alice", Comment: "temporary", At: past.Add(-time.Hour), ExpiresAt: past,
+				},
+				Timestamp: past.Add(-time.Hour),
+			},
+		}))
+
+		scan("CVE-2024-0001", "CVE-2024-0002")
+		vuln := getVuln(t, repo, "CVE-2024-0001")
+		gt.V(t, vuln.Status).Equal(types.VulnStatusActive)
+		gt.Nil(t, vuln.Acknowledgement)
+
+		history, err := repo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2024-0001")
+		gt.NoError(t, err)
+		gt.A(t, history).Length(2)
+		gt.V(t, history[1].Comment).Equal("acknowledgement expired")
+	})
+
+	t.Run("revoke makes vulnerability active", func(t *testing.T) {
+		uc, repo, _ := setup(t)
+
+		_, err := uc.AcknowledgeVulnerability(ctx, acknowledge(time.Time{}))
+		gt.NoError(t, err)
+
+		updated, err := uc.AcknowledgeVulnerability(ctx, &model.AcknowledgeVulnerabilityInput{
+			Owner:  "test-owner",
+			Repo:   "test-repo",
+			VulnID: "CVE-2024-0001",
+			By:     "bob",
+			Revoke: true,
+		})
+		gt.NoError(t, err)
+		gt.V(t, updated).Equal(1)
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Status).Equal(types.VulnStatusActive)
+	})
+
+	t.Run("fixed vulnerability is not acknowledged", func(t *testing.T) {
+		uc, repo, scan := setup(t)
+		scan("CVE-2024-0002")
+
+		updated, err := uc.AcknowledgeVulnerability(ctx, acknowledge(time.Time{}))
+		gt.NoError(t, err)
+		gt.V(t, updated).Equal(0)
+		gt.V(t, getVuln(t, repo, "CVE-2024-0001").Status).Equal(types.VulnStatusFixed)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		uc, _, _ := setup(t)
+
+		input := acknowledge(time.Time{})
+		input.Comment = ""
+		_, err := uc.AcknowledgeVulnerability(ctx, input)
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		_, err = uc.AcknowledgeVulnerability(ctx, acknowledge(time.Now().Add(-time.Hour)))
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		input = acknowledge(time.Time{})
+		input.VulnID = "CVE-2099-9999"
+		_, err = uc.AcknowledgeVulnerability(ctx, input)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+func TestAcknowledgementExpirationWithClock(t *testing.T) {
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	targetID := model.ToTargetID("go.mod", "", "")
+
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+	memRepo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+	scan := func() types.ScanID {
+		scanID, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "0000000000000000000000000000000000000000",
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-repo",
+			Results: []trivy.Result{{Target: "go.mod", Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a", InstalledVersion: "1.0.0"},
+			}}},
+		})
+		gt.NoError(t, err)
+		return scanID
+	}
+	getVuln := func() *model.Vulnerability {
+		vulns, err := memRepo.ListVulnerabilities(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(1)
+		return vulns[0]
+	}
+
+	scan()
+	gt.V(t, getVuln().CreatedAt).Equal(now)
+
+	_, err := uc.AcknowledgeVulnerability(ctx, &model.AcknowledgeVulnerabilityInput{
+		Owner:     "test-owner",
+		Repo:      "test-repo",
+		VulnID:    "CVE-2024-0001",
+		By:        "alice",
+		Comment:   "waiting for upstream fix",
+		ExpiresAt: now.Add(7 * 24 * time.Hour),
+	})
+	gt.NoError(t, err)
+	gt.V(t, getVuln().Acknowledgement.At).Equal(now)
+
+	// Six days later, the acknowledgement is still valid
+	now = now.Add(6 * 24 * time.Hour)
+	scan()
+	gt.V(t, getVuln().Status).Equal(types.VulnStatusAcknowledged)
+
+	// Eight days later, it has expired
+	now = now.Add(2 * 24 * time.Hour)
+	scanID := scan()
+	vuln := getVuln()
+	gt.V(t, vuln.Status).Equal(types.VulnStatusActive)
+	gt.V(t, vuln.UpdatedAt).Equal(now)
+
+	history, err := memRepo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2024-0001")
+	gt.NoError(t, err)
+	gt.A(t, history).Length(2)
+	gt.V(t, history[1].ScanID).Equal(scanID)
+	gt.V(t, history[1].Timestamp).Equal(now)
+}
@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// ArchiveBigQuery exports rows of old days from the BigQuery tables to Parquet files on Cloud Storage and deletes them from BigQuery, so that query cost is bounded while raw history is preserved. Days are processed one by one with an interval, and the state file is updated after each step to resume an interrupted job.
+func (x *UseCase) ArchiveBigQuery(ctx context.Context, input *model.ArchiveBigQueryInput) (*model.ArchiveBigQueryResult, error) {
+	if x.clients.BigQuery() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "BigQuery is required to archive rows")
+	}
+	if input.Bucket == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "archive bucket is required")
+	}
+	if input.StatePath == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "archive state file is required")
+	}
+
+	state, err := loadArchiveState(input.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	before := input.Before.UTC().Truncate(24 * time.Hour)
+	type item struct {
+		table bigQueryTable
+		day   *interfaces.BigQueryDayRows
+	}
+	var items []item
+	for _, tbl := range x.bigQueryTables() {
+		days, err := tbl.client.CountRowsByDay(ctx, before)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to count rows by day", goerr.V("table", tbl.name))
+		}
+		for i, day := range days {
+			if input.MaxDays > 0 && i >= input.MaxDays {
+				break
+			}
+			items = append(items, item{table: tbl, day: day})
+		}
+	}
+	// Older days are archived first, so that a run stopped halfway leaves a contiguous range in BigQuery
+	sort.SliceStable(items, func(i, j int) bool { return items[i].day.Day.Before(items[j].day.Day) })
+
+	result := &model.ArchiveBigQueryResult{DryRun: input.DryRun}
+	if input.DryRun {
+		for _, it := range items {
+			day := it.day.Day.Format(time.DateOnly)
+			result.Days = append(result.Days, &model.ArchivedDay{
+				Table: it.table.name,
+				Day:   day,
+				URI:   archiveURI(input.Bucket, input.Prefix, it.table.name, day),
+				Rows:  it.day.Rows,
+			})
+		}
+		return result, nil
+	}
+
+	logger := logging.From(ctx)
+	task := progress.From(ctx).Start("Archiving BigQuery rows", len(items))
+	defer task.Done()
+	for i, it := range items {
+		day := it.day.Day.Format(time.DateOnly)
+		task.Describe(it.table.name + " " + day)
+
+		if i > 0 && input.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(input.Interval):
+			}
+		}
+
+		archived, err := archiveDay(ctx, state, input, it.table, it.day)
+		if err != nil {
+			task.Fail()
+			return result, err
+		}
+		result.Days = append(result.Days, archived)
+		task.Succeed()
+
+		logger.Info("Archived BigQuery rows",
+			slog.String("table", archived.Table),
+			slog.String("day", archived.Day),
+			slog.String("uri", archived.URI),
+			slog.Int64("rows", archived.Rows),
+		)
+	}
+
+	progress.From(ctx).Set("archived_days", len(result.Days))
+	return result, nil
+}
+
+// archiveDay exports and deletes rows of the day. An exported day recorded in the state is not exported again because overwriting files may leave stale ones of the previous export.
+func archiveDay(ctx context.Context, state *model.ArchiveState, input *model.ArchiveBigQueryInput, tbl bigQueryTable, dayRows *interfaces.BigQueryDayRows) (*model.ArchivedDay, error) {
+	day := dayRows.Day.Format(time.DateOnly)
+	r := interfaces.BigQueryTimeRange{Start: dayRows.Day, End: dayRows.Day.AddDate(0, 0, 1)}
+
+	archived := state.Find(tbl.name, day)
+	if archived != nil && archived.DeletedAt != nil {
+		return nil, goerr.New("rows of archived day are found in BigQuery, restore the day before archiving it again",
+			goerr.V("table", tbl.name), goerr.V("day", day), goerr.V("uri", archived.URI))
+	}
+
+	if archived == nil {
+		archived = &model.ArchivedDay{
+			Table: tbl.name,
+			Day:   day,
+			URI:   archiveURI(input.Bucket, input.Prefix, tbl.name, day),
+			Rows:  dayRows.Rows,
+		}
+		if err := tbl.client.ExportRows(ctx, archived.URI, r); err != nil {
+			return nil, goerr.Wrap(err, "failed to export rows", goerr.V("table", tbl.name), goerr.V("day", day))
+		}
+		exportedAt := logging.CtxTime(ctx)
+		archived.ExportedAt = &exportedAt
+		state.Days = append(state.Days, archived)
+		if err := saveArchiveState(input.StatePath, state); err != nil {
+			return nil, err
+		}
+	}
+
+	deleted, err := tbl.client.DeleteRowsInRange(ctx, r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to delete archived rows", goerr.V("table", tbl.name), goerr.V("day", day))
+	}
+	if deleted != archived.Rows {
+		logging.From(ctx).Warn("number of deleted rows differs from exported rows",
+			slog.String("table", tbl.name),
+			slog.String("day", day),
+			slog.Int64("exported", archived.Rows),
+			slog.Int64("deleted", deleted),
+		)
+	}
+	deletedAt := logging.CtxTime(ctx)
+	archived.DeletedAt = &deletedAt
+	if err := saveArchiveState(input.StatePath, state); err != nil {
+		return nil, err
+	}
+
+	return archived, nil
+}
+
+// RestoreBigQuery loads archived days in the range back to the BigQuery tables. A restored day is removed from the state, so that it can be archived again.
+func (x *UseCase) RestoreBigQuery(ctx context.Context, input *model.RestoreBigQueryInput) (*model.ArchiveBigQueryResult, error) {
+	if x.clients.BigQuery() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "BigQuery is required to restore rows")
+	}
+	if input.StatePath == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "archive state file is required")
+	}
+	if input.To.Before(input.From) {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "end of restore range is before start", goerr.V("from", input.From), goerr.V("to", input.To))
+	}
+
+	state, err := loadArchiveState(input.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string]bigQueryTable)
+	for _, tbl := range x.bigQueryTables() {
+		tables[tbl.name] = tbl
+	}
+
+	from := input.From.UTC().Format(time.DateOnly)
+	to := input.To.UTC().Format(time.DateOnly)
+	var targets []*model.ArchivedDay
+	for _, archived := range state.Days {
+		// Days not deleted yet still have rows in BigQuery
+		if archived.DeletedAt == nil || archived.Day < from || to < archived.Day {
+			continue
+		}
+		if _, ok := tables[archived.Table]; !ok {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "table of archived day is not configured, normalized tables require --bigquery-insert-mode normalized",
+				goerr.V("table", archived.Table), goerr.V("day", archived.Day))
+		}
+		targets = append(targets, archived)
+	}
+
+	result := &model.ArchiveBigQueryResult{DryRun: input.DryRun}
+	if input.DryRun {
+		result.Days = targets
+		return result, nil
+	}
+
+	task := progress.From(ctx).Start("Restoring BigQuery rows", len(targets))
+	defer task.Done()
+	for _, archived := range targets {
+		task.Describe(archived.Table + " " + archived.Day)
+
+		rows, err := tables[archived.Table].client.LoadParquet(ctx, archived.URI)
+		if err != nil {
+			task.Fail()
+			return result, goerr.Wrap(err, "failed to restore archived rows", goerr.V("table", archived.Table), goerr.V("day", archived.Day))
+		}
+		state.Remove(archived.Table, archived.Day)
+		if err := saveArchiveState(input.StatePath, state); err != nil {
+			task.Fail()
+			return result, err
+		}
+
+		result.Days = append(result.Days, archived)
+		task.Succeed()
+
+		logging.From(ctx).Info("Restored BigQuery rows",
+			slog.String("table", archived.Table),
+			slog.String("day", archived.Day),
+			slog.String("uri", archived.URI),
+			slog.Int64("rows", rows),
+		)
+	}
+
+	progress.From(ctx).Set("restored_days", len(result.Days))
+	return result, nil
+}
+
+func archiveURI(bucket, prefix, table, day string) string {
+	return "gs://" + bucket + "/" + path.Join(prefix, table, "day="+day) + "/*.parquet"
+}
+
+// loadArchiveState reads the state file. It returns an empty state if the file does not exist yet.
+func loadArchiveState(statePath string) (*model.ArchiveState, error) {
+	raw, err := os.ReadFile(filepath.Clean(statePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &model.ArchiveState{}, nil
+		}
+		return nil, goerr.Wrap(err, "failed to read archive state", goerr.V("path", statePath))
+	}
+
+	var state model.ArchiveState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse archive state", goerr.V("path", statePath))
+	}
+	return &state, nil
+}
+
+// saveArchiveState writes the state to a temporary file and renames it, so that the state file is not broken by an interruption
+func saveArchiveState(statePath string, state *model.ArchiveState) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return goerr.Wrap(err, "failed to encode archive state")
+	}
+
+	tmp := filepath.Clean(statePath) + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0600); err != nil {
+		return goerr.Wrap(err, "failed to write archive state", goerr.V("path", tmp))
+	}
+	if err := os.Rename(tmp, filepath.Clean(statePath)); err != nil {
+		return goerr.Wrap(err, "failed to replace archive state", goerr.V("path", statePath))
+	}
+	return nil
+}
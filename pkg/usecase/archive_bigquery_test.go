@@ -0,0 +1,166 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestArchiveBigQuery(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	newMock := func() *mock.BigQueryMock {
+		return &mock.BigQueryMock{
+			CountRowsByDayFunc: func(ctx context.Context, b time.Time) ([]*interfaces.BigQueryDayRows, error) {
+				// Only whole days are archived
+				gt.V(t, b).Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+				return []*interfaces.BigQueryDayRows{{Day: day1, Rows: 10}, {Day: day2, Rows: 20}}, nil
+			},
+			ExportRowsFunc: func(ctx context.Context, uri string, r interfaces.BigQueryTimeRange) error {
+				return nil
+			},
+			DeleteRowsInRangeFunc: func(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error) {
+				if r.Start.Equal(day1) {
+					return 10, nil
+				}
+				return 20, nil
+			},
+		}
+	}
+	newInput := func(t *testing.T) *model.ArchiveBigQueryInput {
+		return &model.ArchiveBigQueryInput{
+			Before:    before,
+			Bucket:    "archive-bucket",
+			Prefix:    "octovy/archive",
+			StatePath: filepath.Join(t.TempDir(), "state.json"),
+		}
+	}
+
+	t.Run("dry run only lists days", func(t *testing.T) {
+		bqMock := newMock()
+		uc := usecase.New(infra.New(infra.WithBigQuery(bqMock)))
+		input := newInput(t)
+		input.DryRun = true
+
+		result, err := uc.ArchiveBigQuery(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, result.Days).Length(2)
+		gt.V(t, result.Days[0].URI).Equal("gs://archive-bucket/octovy/archive/scan/day=2024-01-01/*.parquet")
+		gt.V(t, result.Days[1].Rows).Equal(int64(20))
+		gt.A(t, bqMock.ExportRowsCalls()).Length(0)
+		gt.A(t, bqMock.DeleteRowsInRangeCalls()).Length(0)
+	})
+
+	t.Run("export and delete days", func(t *testing.T) {
+		bqMock := newMock()
+		uc := usecase.New(infra.New(infra.WithBigQuery(bqMock)))
+		input := newInput(t)
+		input.MaxDays = 1
+
+		result, err := uc.ArchiveBigQuery(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, result.Days).Length(1)
+		gt.A(t, bqMock.ExportRowsCalls()).Length(1)
+		gt.V(t, bqMock.ExportRowsCalls()[0].R).Equal(interfaces.BigQueryTimeRange{Start: day1, End: day2})
+		gt.A(t, bqMock.DeleteRowsInRangeCalls()).Length(1)
+
+		var state model.ArchiveState
+		raw := gt.R1(os.ReadFile(input.StatePath)).NoError(t)
+		gt.NoError(t, json.Unmarshal(raw, &state))
+		gt.A(t, state.Days).Length(1)
+		gt.V(t, state.Days[0].Day).Equal("2024-01-01")
+		gt.V(t, state.Days[0].ExportedAt).NotNil()
+		gt.V(t, state.Days[0].DeletedAt).NotNil()
+	})
+
+	t.Run("resume without exporting again", func(t *testing.T) {
+		bqMock := newMock()
+		bqMock.DeleteRowsInRangeFunc = func(ctx context.Context, r interfaces.BigQueryTimeRange) (int64, error) {
+			return 0, errors.New("streaming buffer")
+		}
+		uc := usecase.New(infra.New(infra.WithBigQuery(bqMock)))
+		input := newInput(t)
+
+		_, err := uc.ArchiveBigQuery(ctx, input)
+		gt.Error(t, err)
+		gt.A(t, bqMock.ExportRowsCalls()).Length(1)
+
+		resumed := newMock()
+		uc = usecase.New(infra.New(infra.WithBigQuery(resumed)))
+		result, err := uc.ArchiveBigQuery(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, result.Days).Length(2)
+		// day1 was exported before the failure
+		gt.A(t, resumed.ExportRowsCalls()).Length(1)
+		gt.V(t, resumed.ExportRowsCalls()[0].R.Start).Equal(day2)
+		gt.A(t, resumed.DeleteRowsInRangeCalls()).Length(2)
+	})
+
+	t.Run("rows of archived day are not archived again", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(newMock())))
+		input := newInput(t)
+		gt.R1(uc.ArchiveBigQuery(ctx, input)).NoError(t)
+
+		bqMock := newMock()
+		uc = usecase.New(infra.New(infra.WithBigQuery(bqMock)))
+		_, err := uc.ArchiveBigQuery(ctx, input)
+		gt.Error(t, err)
+		gt.A(t, bqMock.ExportRowsCalls()).Length(0)
+	})
+
+	t.Run("restore archived days", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(newMock())))
+		input := newInput(t)
+		gt.R1(uc.ArchiveBigQuery(ctx, input)).NoError(t)
+
+		bqMock := &mock.BigQueryMock{
+			LoadParquetFunc: func(ctx context.Context, uri string) (int64, error) {
+				return 10, nil
+			},
+		}
+		uc = usecase.New(infra.New(infra.WithBigQuery(bqMock)))
+		result, err := uc.RestoreBigQuery(ctx, &model.RestoreBigQueryInput{
+			From:      day1,
+			To:        day1,
+			StatePath: input.StatePath,
+		})
+		gt.NoError(t, err)
+		gt.A(t, result.Days).Length(1)
+		gt.A(t, bqMock.LoadParquetCalls()).Length(1)
+		gt.V(t, bqMock.LoadParquetCalls()[0].URI).Equal("gs://archive-bucket/octovy/archive/scan/day=2024-01-01/*.parquet")
+
+		var state model.ArchiveState
+		raw := gt.R1(os.ReadFile(input.StatePath)).NoError(t)
+		gt.NoError(t, json.Unmarshal(raw, &state))
+		gt.A(t, state.Days).Length(1)
+		gt.V(t, state.Days[0].Day).Equal("2024-01-02")
+	})
+
+	t.Run("normalized tables are archived", func(t *testing.T) {
+		bqMock := newMock()
+		bqMock.TableFunc = func(tableID types.BQTableID) interfaces.BigQuery { return bqMock }
+		uc := usecase.New(infra.New(infra.WithBigQuery(bqMock)), usecase.WithBigQueryNormalizedInsert("vulnerability", "package"))
+		input := newInput(t)
+		input.DryRun = true
+
+		result, err := uc.ArchiveBigQuery(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, result.Days).Length(6)
+		gt.V(t, result.Days[1].Table).Equal("vulnerability")
+	})
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// assessDependencyUpdate compares vulnerabilities of a dependency update pull request with the stored scan of its base branch, and reports how many vulnerabilities the update fixes and introduces as a dedicated log event (event=dependency_update). If WithDependencyUpdateLabel is set, the label is added to the pull request when the update is strictly risk reducing. Failures are logged and do not fail the scan.
+func (x *UseCase) assessDependencyUpdate(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report, incremental *model.IncrementalScan) {
+	logger := logging.From(ctx)
+
+	assessment, err := x.compareWithBaseBranch(ctx, meta, report, incremental)
+	if err != nil {
+		logger.Warn("failed to assess dependency update pull request", slog.Any("error", err))
+		return
+	}
+	if assessment == nil {
+		return
+	}
+
+	logger.Info("Dependency update pull request assessed",
+		slog.String("event", "dependency_update"),
+		slog.Any("assessment", assessment),
+	)
+
+	if x.dependencyUpdateLabel == "" || !assessment.RiskReducing() {
+		return
+	}
+	gh := x.clients.GitHubApp()
+	if gh == nil || meta.InstallationID == 0 {
+		return
+	}
+	if err := gh.AddLabelsToPullRequest(ctx, &interfaces.AddLabelsToPullRequestInput{
+		Owner:     meta.Owner,
+		Repo:      meta.RepoName,
+		Number:    meta.PullRequest.Number,
+		Labels:    []string{x.dependencyUpdateLabel},
+		InstallID: types.GitHubAppInstallID(meta.InstallationID),
+	}); err != nil {
+		logger.Warn("failed to label dependency update pull request", slog.Any("error", err))
+		return
+	}
+	logger.Info("labeled risk reducing dependency update pull request",
+		slog.Int("pull_request", meta.PullRequest.Number),
+		slog.String("label", x.dependencyUpdateLabel),
+	)
+}
+
+// compareWithBaseBranch returns nil if the base branch has never been scanned, because then every vulnerability would look introduced. If the report is of an incremental scan, only targets in its paths are compared.
+func (x *UseCase) compareWithBaseBranch(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report, incremental *model.IncrementalScan) (*model.DependencyUpdateAssessment, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		logging.From(ctx).Debug("skip dependency update assessment without ScanRepository")
+		return nil, nil
+	}
+
+	repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+	baseBranch := types.BranchName(meta.PullRequest.BaseBranch)
+	base, err := baseBranchVulnerabilities(ctx, scanRepo, repoID, baseBranch, incremental)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		logging.From(ctx).Info("skip dependency update assessment because base branch is not scanned",
+			slog.Any("repo_id", repoID),
+			slog.Any("base_branch", baseBranch),
+		)
+		return nil, nil
+	}
+
+	head, err := x.reportVulnerabilities(ctx, meta.Owner, report)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment := &model.DependencyUpdateAssessment{
+		RepoID:      repoID,
+		PullRequest: meta.PullRequest.Number,
+		Author:      meta.PullRequest.Author.Login,
+		BaseBranch:  baseBranch,
+		CommitSHA:   types.CommitSHA(meta.CommitID),
+	}
+	for key, finding := range base {
+		if _, ok := head[key]; !ok {
+			assessment.Fixed = append(assessment.Fixed, finding)
+		}
+	}
+	for key, finding := range head {
+		if _, ok := base[key]; !ok {
+			assessment.Introduced = append(assessment.Introduced, finding)
+		}
+	}
+	sortDependencyUpdateFindings(assessment.Fixed)
+	sortDependencyUpdateFindings(assessment.Introduced)
+
+	return assessment, nil
+}
+
+// baseBranchVulnerabilities returns vulnerabilities of the last scan of the base branch, or nil if the base branch has never been scanned. If incremental is given, only targets in its paths are returned.
+func baseBranchVulnerabilities(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, baseBranch types.BranchName, incremental *model.IncrementalScan) (map[string]*model.DependencyUpdateFinding, error) {
+	if _, err := scanRepo.GetBranch(ctx, repoID, baseBranch); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to get base branch", goerr.V("repo_id", repoID), goerr.V("branch", baseBranch))
+	}
+
+	base, err := listBranchVulnerabilities(ctx, scanRepo, repoID, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	if incremental != nil {
+		for key, finding := range base {
+			if !incremental.Covers(finding.Target) {
+				delete(base, key)
+			}
+		}
+	}
+	return base, nil
+}
+
+// reportVulnerabilities returns vulnerabilities of the report with severity overrides of the owner applied, to be compared with stored ones whose severities are already overridden
+func (x *UseCase) reportVulnerabilities(ctx context.Context, owner string, report *trivy.Report) (map[string]*model.DependencyUpdateFinding, error) {
+	overrides, err := x.loadSeverityOverrides(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	report = overrides.ApplyToReport(report)
+
+	findings := make(map[string]*model.DependencyUpdateFinding)
+	for i := range report.Results {
+		result := &report.Results[i]
+		for j := range result.Vulnerabilities {
+			vuln := &result.Vulnerabilities[j]
+			finding := &model.DependencyUpdateFinding{
+				Target:           result.Target,
+				VulnID:           vuln.VulnerabilityID,
+				PkgName:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				Severity:         vuln.Severity,
+				FixedVersion:     vuln.FixedVersion,
+				Ecosystem:        result.Type,
+			}
+			findings[dependencyUpdateFindingKey(finding)] = finding
+		}
+	}
+	return findings, nil
+}
+
+// listBranchVulnerabilities returns vulnerabilities of the branch which are not fixed. Acknowledged ones are included because they are still present in the code.
+func listBranchVulnerabilities(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName) (map[string]*model.DependencyUpdateFinding, error) {
+	targets, err := scanRepo.ListTargets(ctx, repoID, branchName)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	findings := make(map[string]*model.DependencyUpdateFinding)
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branchName, target.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("repo_id", repoID), goerr.V("target", target.Target))
+		}
+
+		for _, vuln := range vulns {
+			if vuln.Status == types.VulnStatusFixed || (vuln.Type != "" && vuln.Type != types.FindingTypeVulnerability) {
+				continue
+			}
+			finding := &model.DependencyUpdateFinding{
+				Target:           target.Target,
+				VulnID:           vuln.ID,
+				PkgName:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				Severity:         vuln.Severity,
+				FixedVersion:     vuln.FixedVersion,
+				Ecosystem:        target.Type,
+				Status:           vuln.Status,
+			}
+			findings[dependencyUpdateFindingKey(finding)] = finding
+		}
+	}
+
+	return findings, nil
+}
+
+// dependencyUpdateFindingKey identifies a vulnerability regardless of the installed version, so that a version bump of a still vulnerable package is neither fixed nor introduced
+func dependencyUpdateFindingKey(f *model.DependencyUpdateFinding) string {
+	return f.Target + "|" + f.VulnID + "|" + f.PkgName
+}
+
+func sortDependencyUpdateFindings(findings []*model.DependencyUpdateFinding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Target != findings[j].Target {
+			return findings[i].Target < findings[j].Target
+		}
+		if findings[i].VulnID != findings[j].VulnID {
+			return findings[i].VulnID < findings[j].VulnID
+		}
+		return findings[i].PkgName < findings[j].PkgName
+	})
+}
@@ -0,0 +1,106 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestAssessDependencyUpdate(t *testing.T) {
+	const (
+		baseReport        = `{"SchemaVersion":2,"ArtifactName":"test","Results":[{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0001","PkgName":"pkg-a","InstalledVersion":"1.0.0","Severity":"HIGH"},{"VulnerabilityID":"CVE-2024-0002","PkgName":"pkg-b","InstalledVersion":"1.0.0","Severity":"LOW"}]}]}`
+		fixingReport      = `{"SchemaVersion":2,"ArtifactName":"test","Results":[{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0002","PkgName":"pkg-b","InstalledVersion":"1.0.0","Severity":"LOW"}]}]}`
+		introducingReport = `{"SchemaVersion":2,"ArtifactName":"test","Results":[{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0002","PkgName":"pkg-b","InstalledVersion":"1.0.0","Severity":"LOW"},{"VulnerabilityID":"CVE-2024-0003","PkgName":"pkg-a","InstalledVersion":"2.0.0","Severity":"MEDIUM"}]}]}`
+	)
+
+	newMeta := func(branch string, pr *model.GitHubPullRequest) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					Owner:    "test-owner",
+					RepoName: "test-repo",
+				},
+				Branch:   branch,
+				CommitID: "0000000000000000000000000000000000000000",
+			},
+			InstallationID: 12345,
+			PullRequest:    pr,
+		}
+	}
+	newPR := func(author string) *model.GitHubPullRequest {
+		return &model.GitHubPullRequest{
+			Number:     42,
+			BaseBranch: "main",
+			Author:     model.GitHubUser{Login: author},
+		}
+	}
+
+	// run scans the base branch unless baseReport is empty, then scans the pull request. It returns labels added to the pull request.
+	run := func(t *testing.T, baseReport, headReport string, meta model.GitHubMetadata) [][]string {
+		ctx := context.Background()
+		var report string
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(report), 0644)
+				}
+			}
+			return nil
+		}
+
+		var labels [][]string
+		mockGH := &mock.GitHubAppMock{
+			AddLabelsToPullRequestFunc: func(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+				gt.V(t, input.Owner).Equal("test-owner")
+				gt.V(t, input.Repo).Equal("test-repo")
+				gt.V(t, input.Number).Equal(42)
+				labels = append(labels, input.Labels)
+				return nil
+			},
+		}
+
+		uc := usecase.New(infra.New(
+			infra.WithTrivy(mockTrivy),
+			infra.WithGitHubApp(mockGH),
+			infra.WithScanRepository(memory.New()),
+		), usecase.WithDependencyUpdateLabel("security-fix"))
+
+		if baseReport != "" {
+			report = baseReport
+			gt.NoError(t, uc.ScanAndInsert(ctx, t.TempDir(), newMeta("main", nil)))
+		}
+		report = headReport
+		gt.NoError(t, uc.ScanAndInsert(ctx, t.TempDir(), meta))
+		return labels
+	}
+
+	t.Run("label update which only fixes vulnerabilities", func(t *testing.T) {
+		labels := run(t, baseReport, fixingReport, newMeta("renovate/pkg-a-2.x", newPR("renovate[bot]")))
+		gt.A(t, labels).Length(1)
+		gt.A(t, labels[0]).Equal([]string{"security-fix"})
+	})
+
+	t.Run("do not label update introducing a vulnerability", func(t *testing.T) {
+		labels := run(t, baseReport, introducingReport, newMeta("dependabot/go_modules/pkg-a-2.0.0", newPR("dependabot[bot]")))
+		gt.A(t, labels).Length(0)
+	})
+
+	t.Run("do not label pull request of human", func(t *testing.T) {
+		labels := run(t, baseReport, fixingReport, newMeta("feature", newPR("octocat")))
+		gt.A(t, labels).Length(0)
+	})
+
+	t.Run("do not label when base branch is not scanned", func(t *testing.T) {
+		labels := run(t, "", fixingReport, newMeta("renovate/pkg-a-2.x", newPR("renovate[bot]")))
+		gt.A(t, labels).Length(0)
+	})
+}
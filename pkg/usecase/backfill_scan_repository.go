@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// BackfillScanRepository replays scans stored in the BigQuery scan table to the scan repository in order of timestamp, so that repositories, branches, targets and statuses of findings are rebuilt as if the scans had been inserted with Firestore enabled. It is meant to migrate from BigQuery only deployments. A scan not newer than the last scan of its branch is skipped, so that an interrupted backfill can be run again.
+func (x *UseCase) BackfillScanRepository(ctx context.Context, input *model.BackfillScanRepositoryInput) (*model.BackfillScanRepositoryResult, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "backfill requires Firestore")
+	}
+	if x.clients.BigQuery() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "backfill requires BigQuery")
+	}
+	if input.Repo != "" && input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "owner is required to select repository")
+	}
+
+	var conditions []interfaces.BigQueryCondition
+	if input.Owner != "" {
+		conditions = append(conditions, interfaces.BigQueryCondition{Column: "github.owner", Value: input.Owner})
+	}
+	if input.Repo != "" {
+		conditions = append(conditions, interfaces.BigQueryCondition{Column: "github.repo_name", Value: input.Repo})
+	}
+
+	logger := logging.From(ctx)
+	result := &model.BackfillScanRepositoryResult{DryRun: input.DryRun}
+	repos := make(map[types.GitHubRepoID]*model.BackfilledRepository)
+	// lastScanAt caches the last scan time of branches to decide whether a scan is already in the scan repository
+	lastScanAt := make(map[string]int64)
+
+	task := progress.From(ctx).Start("Backfilling scans", 0)
+	defer task.Done()
+
+	err := x.clients.BigQuery().ListRawScans(ctx, func(scan *model.Scan) error {
+		meta := scan.GitHub
+		repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+		task.Describe(string(repoID) + "@" + meta.Branch)
+
+		backfilled, ok := repos[repoID]
+		if !ok {
+			backfilled = &model.BackfilledRepository{ID: repoID}
+			repos[repoID] = backfilled
+			result.Repositories = append(result.Repositories, backfilled)
+		}
+
+		branchKey := string(repoID) + "/" + meta.Branch
+		last, ok := lastScanAt[branchKey]
+		if !ok {
+			branch, err := getPreviousBranch(ctx, scanRepo, repoID, types.BranchName(meta.Branch))
+			if err != nil {
+				return err
+			}
+			if branch != nil && !branch.LastScanAt.IsZero() {
+				last = branch.LastScanAt.UnixMicro()
+			}
+		}
+		if scan.Timestamp.UnixMicro() <= last {
+			backfilled.Skipped++
+			task.Skip()
+			lastScanAt[branchKey] = last
+			return nil
+		}
+
+		if !input.DryRun {
+			// Regressions are not notified because they were detected when the scans were inserted
+			if _, err := x.insertToFirestore(ctx, meta, scan, scan.Report, scan.Timestamp); err != nil {
+				task.Fail()
+				return goerr.Wrap(err, "failed to replay scan", goerr.V("scan_id", scan.ID), goerr.V("repo_id", repoID), goerr.V("branch", meta.Branch))
+			}
+			logger.Debug("Replayed scan", slog.Any("scan_id", scan.ID), slog.Any("repo_id", repoID), slog.String("branch", meta.Branch))
+		}
+		lastScanAt[branchKey] = scan.Timestamp.UnixMicro()
+		backfilled.Scans++
+		task.Succeed()
+		return nil
+	}, conditions...)
+
+	var scans, skipped int
+	for _, repo := range result.Repositories {
+		scans += repo.Scans
+		skipped += repo.Skipped
+	}
+	progress.From(ctx).Set("backfilled_scans", scans)
+	progress.From(ctx).Set("skipped_scans", skipped)
+	progress.From(ctx).Set("dry_run", input.DryRun)
+
+	if err != nil {
+		return result, goerr.Wrap(err, "failed to backfill scans")
+	}
+
+	logger.Info("Completed backfill of scan repository",
+		slog.String("owner", input.Owner),
+		slog.String("repo", input.Repo),
+		slog.Bool("dry_run", input.DryRun),
+		slog.Int("repositories", len(result.Repositories)),
+		slog.Int("scans", scans),
+		slog.Int("skipped", skipped),
+	)
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestBackfillScanRepository(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newScan := func(id types.ScanID, ts time.Time, vulnIDs ...string) *model.Scan {
+		vulns := make([]trivy.DetectedVulnerability, len(vulnIDs))
+		for i, vulnID := range vulnIDs {
+			vulns[i] = trivy.DetectedVulnerability{
+				VulnerabilityID:  vulnID,
+				PkgName:          "test-pkg",
+				InstalledVersion: "1.0.0",
+				Vulnerability:    trivy.Vulnerability{Severity: "HIGH"},
+			}
+		}
+		return &model.Scan{
+			ID:        id,
+			Timestamp: ts,
+			GitHub: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{Owner: "test-org", RepoName: "app"},
+					CommitID:   "commit-" + string(id),
+					Branch:     "main",
+				},
+				DefaultBranch: "main",
+			},
+			Report: trivy.Report{
+				SchemaVersion: 2,
+				Results: []trivy.Result{
+					{Target: "go.mod", Class: "lang-pkgs", Type: "gomod", Vulnerabilities: vulns},
+				},
+			},
+		}
+	}
+	scans := []*model.Scan{
+		newScan("scan-1", base, "CVE-2024-0001", "CVE-2024-0002"),
+		newScan("scan-2", base.Add(time.Hour), "CVE-2024-0002"),
+	}
+	newBQ := func() *mock.BigQueryMock {
+		return &mock.BigQueryMock{
+			ListRawScansFunc: func(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error {
+				gt.V(t, conditions).Equal([]interfaces.BigQueryCondition{{Column: "github.owner", Value: "test-org"}})
+				for _, scan := range scans {
+					if err := fn(scan); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+	}
+
+	t.Run("replay scans to rebuild statuses", func(t *testing.T) {
+		repo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo), infra.WithBigQuery(newBQ())))
+
+		result, err := uc.BackfillScanRepository(ctx, &model.BackfillScanRepositoryInput{Owner: "test-org"})
+		gt.NoError(t, err)
+		gt.A(t, result.Repositories).Length(1)
+		gt.V(t, result.Repositories[0]).Equal(&model.BackfilledRepository{ID: "test-org/app", Scans: 2})
+
+		branch := gt.R1(repo.GetBranch(ctx, "test-org/app", "main")).NoError(t)
+		gt.V(t, branch.LastScanID).Equal(types.ScanID("scan-2"))
+		gt.V(t, branch.LastScanAt).Equal(base.Add(time.Hour))
+
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, "test-org/app", "main", model.ToTargetID("go.mod", "lang-pkgs", "gomod"))).NoError(t)
+		statuses := make(map[string]types.VulnStatus)
+		for _, v := range vulns {
+			statuses[v.ID] = v.Status
+		}
+		gt.V(t, statuses["CVE-2024-0001"]).Equal(types.VulnStatusFixed)
+		gt.V(t, statuses["CVE-2024-0002"]).Equal(types.VulnStatusActive)
+
+		records := gt.R1(repo.ListScanRecords(ctx, "test-org/app")).NoError(t)
+		gt.A(t, records).Length(2)
+
+		t.Run("run again skips replayed scans", func(t *testing.T) {
+			result, err := uc.BackfillScanRepository(ctx, &model.BackfillScanRepositoryInput{Owner: "test-org"})
+			gt.NoError(t, err)
+			gt.V(t, result.Repositories[0]).Equal(&model.BackfilledRepository{ID: "test-org/app", Skipped: 2})
+
+			records := gt.R1(repo.ListScanRecords(ctx, "test-org/app")).NoError(t)
+			gt.A(t, records).Length(2)
+		})
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		repo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo), infra.WithBigQuery(newBQ())))
+
+		result, err := uc.BackfillScanRepository(ctx, &model.BackfillScanRepositoryInput{Owner: "test-org", DryRun: true})
+		gt.NoError(t, err)
+		gt.True(t, result.DryRun)
+		gt.V(t, result.Repositories[0].Scans).Equal(2)
+
+		repos := gt.R1(repo.ListRepositoriesByOwner(ctx, "test-org")).NoError(t)
+		gt.A(t, repos).Length(0)
+	})
+
+	t.Run("repository requires owner", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New()), infra.WithBigQuery(newBQ())))
+		_, err := uc.BackfillScanRepository(ctx, &model.BackfillScanRepositoryInput{Repo: "app"})
+		gt.Error(t, err)
+	})
+}
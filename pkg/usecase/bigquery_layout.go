@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const bqPartitioningField = "timestamp"
+
+// bqClusteringFields are columns to cluster tables of scans by. BigQuery clusters tables only by top-level columns, so tables have owner and repo_name besides the github record.
+var bqClusteringFields = []string{"owner", "repo_name"}
+
+// bqTableLayout is partitioning and clustering of BigQuery tables
+type bqTableLayout struct {
+	partitioning types.BQPartitioning
+	clustering   bool
+
+	// warned has IDs of existing tables warned about partitioning, to warn only once per table
+	warned sync.Map
+}
+
+var bqPartitioningTypes = map[types.BQPartitioning]bigquery.TimePartitioningType{
+	types.BQPartitioningHour:  bigquery.HourPartitioningType,
+	types.BQPartitioningDay:   bigquery.DayPartitioningType,
+	types.BQPartitioningMonth: bigquery.MonthPartitioningType,
+	types.BQPartitioningYear:  bigquery.YearPartitioningType,
+}
+
+// timePartitioning returns partitioning of a new table with the schema, or nil if partitioning is disabled or the schema has no timestamp column
+func (x *bqTableLayout) timePartitioning(schema bigquery.Schema) *bigquery.TimePartitioning {
+	if x == nil {
+		return nil
+	}
+	partitioningType, ok := bqPartitioningTypes[x.partitioning]
+	if !ok || !hasTopLevelField(schema, bqPartitioningField, bigquery.TimestampFieldType) {
+		return nil
+	}
+	return &bigquery.TimePartitioning{
+		Type:  partitioningType,
+		Field: bqPartitioningField,
+	}
+}
+
+// clusteringOf returns clustering of a table with the schema, or nil if clustering is disabled or the schema lacks clustering columns
+func (x *bqTableLayout) clusteringOf(schema bigquery.Schema) *bigquery.Clustering {
+	if x == nil || !x.clustering {
+		return nil
+	}
+	for _, name := range bqClusteringFields {
+		if !hasTopLevelField(schema, name, bigquery.StringFieldType) {
+			return nil
+		}
+	}
+	return &bigquery.Clustering{Fields: bqClusteringFields}
+}
+
+// clusteringUpdate returns clustering to set to the existing table, or nil if it is already clustered as configured
+func (x *bqTableLayout) clusteringUpdate(md *bigquery.TableMetadata, schema bigquery.Schema) *bigquery.Clustering {
+	clustering := x.clusteringOf(schema)
+	if clustering == nil {
+		return nil
+	}
+	if md.Clustering != nil && slices.Equal(md.Clustering.Fields, clustering.Fields) {
+		return nil
+	}
+	return clustering
+}
+
+// checkPartitioning warns once if the existing table is not partitioned as configured. BigQuery can not partition an existing table, so it is used as it is.
+func (x *bqTableLayout) checkPartitioning(ctx context.Context, md *bigquery.TableMetadata) {
+	expected := x.timePartitioning(md.Schema)
+	if expected == nil {
+		return
+	}
+	current := md.TimePartitioning
+	if current != nil && current.Type == expected.Type && current.Field == expected.Field {
+		return
+	}
+	if _, warned := x.warned.LoadOrStore(md.FullID, true); warned {
+		return
+	}
+
+	attrs := []any{
+		"table", md.FullID,
+		"partitioning", x.partitioning,
+	}
+	if current != nil {
+		attrs = append(attrs, "current_type", current.Type, "current_field", current.Field)
+	}
+	logging.From(ctx).Warn("existing BigQuery table is not partitioned as configured, recreate the table to change partitioning", attrs...)
+}
+
+func hasTopLevelField(schema bigquery.Schema, name string, fieldType bigquery.FieldType) bool {
+	return slices.ContainsFunc(schema, func(field *bigquery.FieldSchema) bool {
+		return strings.EqualFold(field.Name, name) && field.Type == fieldType && !field.Repeated
+	})
+}
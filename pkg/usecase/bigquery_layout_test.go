@@ -0,0 +1,127 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestBQTableLayout(t *testing.T) {
+	schema := bigquery.Schema{
+		{Name: "id", Type: bigquery.StringFieldType},
+		{Name: "timestamp", Type: bigquery.TimestampFieldType},
+		{Name: "owner", Type: bigquery.StringFieldType},
+		{Name: "repo_name", Type: bigquery.StringFieldType},
+	}
+
+	t.Run("new table", func(t *testing.T) {
+		testCases := map[string]struct {
+			layout *usecase.BQTableLayoutForTest
+			schema bigquery.Schema
+			want   *bigquery.TimePartitioning
+		}{
+			"partitioned by day": {
+				layout: usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, false),
+				schema: schema,
+				want:   &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "timestamp"},
+			},
+			"partitioned by month": {
+				layout: usecase.NewBQTableLayoutForTest(types.BQPartitioningMonth, false),
+				schema: schema,
+				want:   &bigquery.TimePartitioning{Type: bigquery.MonthPartitioningType, Field: "timestamp"},
+			},
+			"partitioning disabled": {
+				layout: usecase.NewBQTableLayoutForTest("", false),
+				schema: schema,
+			},
+			"no layout": {
+				schema: schema,
+			},
+			"schema without timestamp": {
+				layout: usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, false),
+				schema: bigquery.Schema{{Name: "id", Type: bigquery.StringFieldType}},
+			},
+			"timestamp of wrong type": {
+				layout: usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, false),
+				schema: bigquery.Schema{{Name: "timestamp", Type: bigquery.StringFieldType}},
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				gt.V(t, tc.layout.TimePartitioningForTest(tc.schema)).Equal(tc.want)
+			})
+		}
+	})
+
+	t.Run("existing table missing clustering", func(t *testing.T) {
+		layout := usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, true)
+
+		clustering := layout.ClusteringUpdateForTest(&bigquery.TableMetadata{Schema: schema}, schema)
+		gt.V(t, clustering).NotNil()
+		gt.A(t, clustering.Fields).Equal([]string{"owner", "repo_name"})
+
+		// Clustering by other columns is replaced
+		clustering = layout.ClusteringUpdateForTest(&bigquery.TableMetadata{
+			Schema:     schema,
+			Clustering: &bigquery.Clustering{Fields: []string{"id"}},
+		}, schema)
+		gt.V(t, clustering).NotNil()
+
+		// Already clustered table is not updated
+		gt.V(t, layout.ClusteringUpdateForTest(&bigquery.TableMetadata{
+			Schema:     schema,
+			Clustering: &bigquery.Clustering{Fields: []string{"owner", "repo_name"}},
+		}, schema)).Nil()
+
+		// Schema without clustering columns is not clustered
+		gt.V(t, layout.ClusteringUpdateForTest(&bigquery.TableMetadata{}, bigquery.Schema{{Name: "id", Type: bigquery.StringFieldType}})).Nil()
+
+		// Clustering is not updated if disabled
+		gt.V(t, usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, false).ClusteringUpdateForTest(&bigquery.TableMetadata{Schema: schema}, schema)).Nil()
+	})
+
+	t.Run("mismatched partitioning", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := logging.With(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+		warnings := func() int {
+			return strings.Count(buf.String(), "is not partitioned as configured")
+		}
+		layout := usecase.NewBQTableLayoutForTest(types.BQPartitioningDay, false)
+
+		// Partitioned as configured
+		layout.CheckPartitioningForTest(ctx, &bigquery.TableMetadata{
+			FullID:           "project:octovy.scans",
+			Schema:           schema,
+			TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "timestamp"},
+		})
+		gt.V(t, warnings()).Equal(0)
+
+		// Partitioned by another type, warned only once
+		monthly := &bigquery.TableMetadata{
+			FullID:           "project:octovy.scans_monthly",
+			Schema:           schema,
+			TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.MonthPartitioningType, Field: "timestamp"},
+		}
+		layout.CheckPartitioningForTest(ctx, monthly)
+		layout.CheckPartitioningForTest(ctx, monthly)
+		gt.V(t, warnings()).Equal(1)
+		gt.S(t, buf.String()).Contains(`"current_type":"MONTH"`)
+
+		// Not partitioned
+		layout.CheckPartitioningForTest(ctx, &bigquery.TableMetadata{FullID: "project:octovy.scans_raw", Schema: schema})
+		gt.V(t, warnings()).Equal(2)
+
+		// Nothing is checked if partitioning is disabled
+		usecase.NewBQTableLayoutForTest("", true).CheckPartitioningForTest(ctx, &bigquery.TableMetadata{FullID: "project:octovy.other", Schema: schema})
+		gt.V(t, warnings()).Equal(2)
+	})
+}
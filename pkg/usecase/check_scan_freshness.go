@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// CheckScanFreshness finds default branches of the owner's repositories whose last scan is
+// missing or older than MaxAge. Such gaps usually mean that webhook delivery is broken or the
+// GitHub App is suspended. Every stale branch is logged as a warning and the total is logged
+// as the "stale_branches" field so that log based alerts and metrics can pick them up.
+func (x *UseCase) CheckScanFreshness(ctx context.Context, input *model.CheckScanFreshnessInput) (*model.ScanFreshnessReport, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "scan freshness check requires Firestore")
+	}
+	if input.MaxAge <= 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "max age must be positive", goerr.V("max_age", input.MaxAge))
+	}
+
+	repos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+	}
+
+	logger := logging.From(ctx)
+	report := &model.ScanFreshnessReport{
+		Owner:  input.Owner,
+		MaxAge: input.MaxAge,
+	}
+	now := logging.CtxTime(ctx)
+
+	for _, repo := range repos {
+		if repo.DefaultBranch == "" {
+			continue
+		}
+		report.Checked++
+
+		stale := model.StaleBranch{
+			RepoID: repo.ID,
+			Branch: repo.DefaultBranch,
+		}
+
+		branch, err := scanRepo.GetBranch(ctx, repo.ID, repo.DefaultBranch)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			stale.Reason = "default branch has never been scanned"
+		case err != nil:
+			return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repo.ID), goerr.V("branch", repo.DefaultBranch))
+		case now.Sub(branch.LastScanAt) > input.MaxAge:
+			stale.LastScanAt = branch.LastScanAt
+			stale.Reason = fmt.Sprintf("last scan is older than %s", input.MaxAge)
+		default:
+			continue
+		}
+
+		report.Stale = append(report.Stale, stale)
+		logger.Warn("Stale branch scan detected",
+			slog.Any("repo_id", stale.RepoID),
+			slog.Any("branch", stale.Branch),
+			slog.Time("last_scan_at", stale.LastScanAt),
+			slog.String("reason", stale.Reason),
+		)
+	}
+
+	logger.Info("Completed scan freshness check",
+		slog.String("owner", input.Owner),
+		slog.Duration("max_age", input.MaxAge),
+		slog.Int("checked_branches", report.Checked),
+		slog.Int("stale_branches", len(report.Stale)),
+	)
+
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestCheckScanFreshness(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	now := time.Now()
+
+	addRepo := func(name string, defaultBranch types.BranchName, lastScanAt time.Time) {
+		r := &model.Repository{
+			ID:             types.GitHubRepoID("test-owner/" + name),
+			Owner:          "test-owner",
+			Name:           name,
+			DefaultBranch:  defaultBranch,
+			InstallationID: 12345,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, r))
+		if lastScanAt.IsZero() || defaultBranch == "" {
+			return
+		}
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, r.ID, &model.Branch{
+			Name:       defaultBranch,
+			LastScanID: "scan-id",
+			LastScanAt: lastScanAt,
+			Status:     types.ScanStatusSuccess,
+			CreatedAt:  lastScanAt,
+			UpdatedAt:  lastScanAt,
+		}))
+	}
+
+	addRepo("fresh", "main", now.Add(-time.Hour))
+	addRepo("old", "main", now.Add(-48*time.Hour))
+	addRepo("never", "main", time.Time{})
+	addRepo("no-default-branch", "", now.Add(-48*time.Hour))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+	t.Run("reports stale and never scanned default branches", func(t *testing.T) {
+		report, err := uc.CheckScanFreshness(ctx, &model.CheckScanFreshnessInput{
+			Owner:  "test-owner",
+			MaxAge: 24 * time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.V(t, report.Checked).Equal(3)
+		gt.A(t, report.Stale).Length(2)
+
+		// Repositories are returned sorted by name
+		gt.V(t, report.Stale[0].RepoID).Equal(types.GitHubRepoID("test-owner/never"))
+		gt.True(t, report.Stale[0].LastScanAt.IsZero())
+		gt.S(t, report.Stale[0].Reason).Contains("never been scanned")
+
+		gt.V(t, report.Stale[1].RepoID).Equal(types.GitHubRepoID("test-owner/old"))
+		gt.V(t, report.Stale[1].Branch).Equal(types.BranchName("main"))
+		gt.False(t, report.Stale[1].LastScanAt.IsZero())
+	})
+
+	t.Run("wider window accepts old scans", func(t *testing.T) {
+		report, err := uc.CheckScanFreshness(ctx, &model.CheckScanFreshnessInput{
+			Owner:  "test-owner",
+			MaxAge: 72 * time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.A(t, report.Stale).Length(1)
+		gt.V(t, report.Stale[0].RepoID).Equal(types.GitHubRepoID("test-owner/never"))
+	})
+
+	t.Run("requires positive max age", func(t *testing.T) {
+		_, err := uc.CheckScanFreshness(ctx, &model.CheckScanFreshnessInput{Owner: "test-owner"})
+		gt.Error(t, err)
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		_, err := usecase.New(infra.New()).CheckScanFreshness(ctx, &model.CheckScanFreshnessInput{
+			Owner:  "test-owner",
+			MaxAge: time.Hour,
+		})
+		gt.Error(t, err)
+	})
+}
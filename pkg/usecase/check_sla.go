@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// CheckSLA finds active vulnerabilities which are not fixed within the SLA of their severity, in the branch of the repository or in default branches of all repositories of the owner. Every violation is logged as a warning with event=sla_breach so that log based alerting can notify owners, in the same way as regressions. Acknowledged findings are not counted.
+func (x *UseCase) CheckSLA(ctx context.Context, input *model.CheckSLAInput) (*model.SLAReport, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+	if len(x.slaPolicy) == 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "SLA policy is not configured")
+	}
+	if input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "owner is required")
+	}
+
+	var branches []branchRef
+	if input.Repo != "" {
+		repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+		branchName := input.Branch
+		if branchName == "" {
+			repo, err := scanRepo.GetRepository(ctx, repoID)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+			}
+			branchName = repo.DefaultBranch
+		}
+		if branchName == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "branch is not specified and repository has no default branch", goerr.V("repo_id", repoID))
+		}
+		branches = append(branches, branchRef{repoID: repoID, branch: branchName})
+	} else {
+		repos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+		}
+		for _, repo := range repos {
+			if repo.DefaultBranch != "" {
+				branches = append(branches, branchRef{repoID: repo.ID, branch: repo.DefaultBranch})
+			}
+		}
+	}
+
+	logger := logging.From(ctx)
+	report := &model.SLAReport{
+		Owner:      input.Owner,
+		Policy:     x.slaPolicy.String(),
+		Violations: []model.SLAViolation{},
+	}
+	now := logging.CtxTime(ctx)
+
+	for _, ref := range branches {
+		violations, err := x.listSLAViolations(ctx, scanRepo, ref, now)
+		if errors.Is(err, repository.ErrNotFound) && input.Repo == "" {
+			// A default branch which has never been scanned has no vulnerabilities
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Checked++
+
+		for _, v := range violations {
+			logger.Warn("SLA breach detected",
+				slog.String("event", "sla_breach"),
+				slog.Any("violation", v),
+			)
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+
+	logger.Info("Completed SLA check",
+		slog.String("owner", input.Owner),
+		slog.String("policy", report.Policy),
+		slog.Int("checked_branches", report.Checked),
+		slog.Int("violations", len(report.Violations)),
+	)
+
+	return report, nil
+}
+
+type branchRef struct {
+	repoID types.GitHubRepoID
+	branch types.BranchName
+}
+
+// listSLAViolations returns active vulnerabilities of the branch which are overdue at now. It returns repository.ErrNotFound if the branch has never been scanned.
+func (x *UseCase) listSLAViolations(ctx context.Context, scanRepo interfaces.ScanRepository, ref branchRef, now time.Time) ([]model.SLAViolation, error) {
+	if _, err := scanRepo.GetBranch(ctx, ref.repoID, ref.branch); err != nil {
+		return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", ref.repoID), goerr.V("branch", ref.branch))
+	}
+
+	targets, err := scanRepo.ListTargets(ctx, ref.repoID, ref.branch)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", ref.repoID), goerr.V("branch", ref.branch))
+	}
+
+	var violations []model.SLAViolation
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, ref.repoID, ref.branch, target.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("target", target.Target))
+		}
+
+		for _, vuln := range vulns {
+			if vuln.Status != types.VulnStatusActive {
+				continue
+			}
+			age := x.slaPolicy.AgeOf(vuln, now)
+			if !age.Overdue {
+				continue
+			}
+
+			findingType := vuln.Type
+			if findingType == "" {
+				findingType = types.FindingTypeVulnerability
+			}
+			violations = append(violations, model.SLAViolation{
+				Repository:       string(ref.repoID),
+				Branch:           string(ref.branch),
+				Target:           target.Target,
+				ID:               vuln.ID,
+				Type:             findingType,
+				Severity:         vuln.Severity,
+				PkgName:          vuln.PkgName,
+				Title:            vuln.Title,
+				VulnerabilityAge: *age,
+				DaysOverdue:      int(now.Sub(*age.DueAt) / (24 * time.Hour)),
+				Permalink:        x.permalinks.Finding(ref.repoID, ref.branch, vuln.ID),
+			})
+		}
+	}
+	return violations, nil
+}
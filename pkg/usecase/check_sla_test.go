@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestCheckSLA(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+	policy := gt.R1(model.ParseSLAPolicy("CRITICAL=7d,HIGH=30d")).NoError(t)
+
+	setup := func(t *testing.T, opts ...usecase.Option) *usecase.UseCase {
+		repo := memory.New()
+		for _, name := range []string{"repo-a", "repo-b"} {
+			repoID := types.NewGitHubRepoID("test-owner", name)
+			gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+				ID:            repoID,
+				Owner:         "test-owner",
+				Name:          name,
+				DefaultBranch: "main",
+			}))
+			gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main", LastScanAt: now, Status: types.ScanStatusSuccess}))
+			target := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+			gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", target))
+			gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", target.ID, []*model.Vulnerability{
+				// overdue by 3 days
+				{ID: "CVE-2024-0001", PkgName: "pkg-a", Severity: "CRITICAL", Status: types.VulnStatusActive, CreatedAt: now.Add(-10 * 24 * time.Hour)},
+				// within SLA
+				{ID: "CVE-2024-0002", PkgName: "pkg-b", Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: now.Add(-10 * 24 * time.Hour)},
+				// no SLA of the severity
+				{ID: "CVE-2024-0003", PkgName: "pkg-c", Severity: "LOW", Status: types.VulnStatusActive, CreatedAt: now.Add(-365 * 24 * time.Hour)},
+				// not active
+				{ID: "CVE-2024-0004", PkgName: "pkg-d", Severity: "CRITICAL", Status: types.VulnStatusAcknowledged, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+			}))
+		}
+		// A repository whose default branch has never been scanned
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:            types.NewGitHubRepoID("test-owner", "repo-new"),
+			Owner:         "test-owner",
+			Name:          "repo-new",
+			DefaultBranch: "main",
+		}))
+		return usecase.New(infra.New(infra.WithScanRepository(repo)), opts...)
+	}
+
+	t.Run("finds overdue vulnerabilities of the owner", func(t *testing.T) {
+		uc := setup(t, usecase.WithSLAPolicy(policy))
+
+		report, err := uc.CheckSLA(ctx, &model.CheckSLAInput{Owner: "test-owner"})
+		gt.NoError(t, err)
+		gt.V(t, report.Policy).Equal("CRITICAL=7d,HIGH=30d")
+		gt.V(t, report.Checked).Equal(2)
+		gt.A(t, report.Violations).Length(2)
+
+		v := report.Violations[0]
+		gt.V(t, v.ID).Equal("CVE-2024-0001")
+		gt.V(t, v.DaysOpen).Equal(10)
+		gt.V(t, v.DaysOverdue).Equal(3)
+		gt.True(t, v.Overdue)
+		gt.V(t, *v.DueAt).Equal(now.Add(-3 * 24 * time.Hour))
+	})
+
+	t.Run("checks the branch of the repository", func(t *testing.T) {
+		uc := setup(t, usecase.WithSLAPolicy(policy))
+
+		report, err := uc.CheckSLA(ctx, &model.CheckSLAInput{Owner: "test-owner", Repo: "repo-a"})
+		gt.NoError(t, err)
+		gt.V(t, report.Checked).Equal(1)
+		gt.A(t, report.Violations).Length(1)
+		gt.V(t, report.Violations[0].Repository).Equal("test-owner/repo-a")
+	})
+
+	t.Run("gate fails by overdue vulnerabilities with EnforceSLA", func(t *testing.T) {
+		uc := setup(t, usecase.WithSLAPolicy(policy))
+
+		result, err := uc.EvaluateGate(ctx, &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "repo-a",
+			MaxSeverity: types.SeverityCritical,
+		})
+		gt.NoError(t, err)
+		gt.True(t, result.Pass)
+
+		result, err = uc.EvaluateGate(ctx, &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "repo-a",
+			MaxSeverity: types.SeverityCritical,
+			EnforceSLA:  true,
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.V(t, result.SLA).Equal("CRITICAL=7d,HIGH=30d")
+		gt.A(t, result.Violations).Length(1)
+		gt.V(t, result.Violations[0].ID).Equal("CVE-2024-0001")
+		gt.V(t, result.Violations[0].Age.DaysOpen).Equal(10)
+	})
+
+	t.Run("requires SLA policy", func(t *testing.T) {
+		uc := setup(t)
+
+		_, err := uc.CheckSLA(ctx, &model.CheckSLAInput{Owner: "test-owner"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+
+		_, err = uc.EvaluateGate(ctx, &model.EvaluateGateInput{Owner: "test-owner", Repo: "repo-a", EnforceSLA: true})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// DeleteRepositoryData deletes all Firestore documents of the repositories of the owner, or of the repository if Repo is set, and optionally their BigQuery rows. It is meant to clean up test and staging environments. In dry run, it only counts the data to be deleted.
+func (x *UseCase) DeleteRepositoryData(ctx context.Context, input *model.DeleteRepositoryDataInput) (*model.DeleteRepositoryDataResult, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "repository data deletion requires Firestore")
+	}
+	if input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "owner is required")
+	}
+	if input.BigQuery && x.clients.BigQuery() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "BigQuery is required to delete BigQuery rows")
+	}
+
+	var repos []*model.Repository
+	if input.Repo != "" {
+		repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+		repo, err := scanRepo.GetRepository(ctx, repoID)
+		switch {
+		case err == nil:
+			repos = append(repos, repo)
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+		}
+	} else {
+		found, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+		}
+		repos = found
+	}
+
+	logger := logging.From(ctx)
+	result := &model.DeleteRepositoryDataResult{DryRun: input.DryRun}
+
+	task := progress.From(ctx).Start("Deleting repositories of "+input.Owner, len(repos))
+	defer task.Done()
+	for _, repo := range repos {
+		task.Describe(string(repo.ID))
+		deleted, err := countRepositoryData(ctx, scanRepo, repo.ID)
+		if err != nil {
+			task.Fail()
+			return result, err
+		}
+
+		if !input.DryRun {
+			if err := scanRepo.DeleteRepository(ctx, repo.ID); err != nil {
+				task.Fail()
+				return result, goerr.Wrap(err, "failed to delete repository", goerr.V("repo_id", repo.ID))
+			}
+			logger.Info("Deleted repository data",
+				slog.Any("repo_id", repo.ID),
+				slog.Int("branches", deleted.Branches),
+				slog.Int("targets", deleted.Targets),
+				slog.Int("vulnerabilities", deleted.Vulnerabilities),
+				slog.Int("scan_records", deleted.ScanRecords),
+			)
+		}
+
+		result.Repositories = append(result.Repositories, deleted)
+		task.Succeed()
+	}
+
+	if input.BigQuery {
+		rows, err := x.deleteBigQueryRows(ctx, input)
+		result.BigQuery = rows
+		if err != nil {
+			return result, err
+		}
+	}
+
+	progress.From(ctx).Set("repositories", len(result.Repositories))
+	progress.From(ctx).Set("dry_run", input.DryRun)
+
+	logger.Info("Completed repository data deletion",
+		slog.String("owner", input.Owner),
+		slog.String("repo", input.Repo),
+		slog.Bool("dry_run", input.DryRun),
+		slog.Int("repositories", len(result.Repositories)),
+	)
+
+	return result, nil
+}
+
+// countRepositoryData counts documents of the repository which are deleted with it
+func countRepositoryData(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID) (*model.DeletedRepository, error) {
+	deleted := &model.DeletedRepository{ID: repoID}
+
+	branches, err := scanRepo.ListBranches(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list branches", goerr.V("repo_id", repoID))
+	}
+	deleted.Branches = len(branches)
+
+	for _, branch := range branches {
+		targets, err := scanRepo.ListTargets(ctx, repoID, branch.Name)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name))
+		}
+		deleted.Targets += len(targets)
+
+		for _, target := range targets {
+			vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branch.Name, target.ID)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name), goerr.V("target_id", target.ID))
+			}
+			deleted.Vulnerabilities += len(vulns)
+		}
+	}
+
+	records, err := scanRepo.ListScanRecords(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list scan records", goerr.V("repo_id", repoID))
+	}
+	deleted.ScanRecords = len(records)
+
+	return deleted, nil
+}
+
+// deleteBigQueryRows deletes rows of the owner or the repository from the scan table, and from the vulnerability and package tables in normalized insert mode. Rows are matched by owner and repository name, so rows of repositories missing in Firestore are also deleted.
+func (x *UseCase) deleteBigQueryRows(ctx context.Context, input *model.DeleteRepositoryDataInput) ([]*model.DeletedBigQueryRows, error) {
+	var results []*model.DeletedBigQueryRows
+	for _, tbl := range x.bigQueryTables() {
+		conditions := []interfaces.BigQueryCondition{{Column: tbl.prefix + "owner", Value: input.Owner}}
+		if input.Repo != "" {
+			conditions = append(conditions, interfaces.BigQueryCondition{Column: tbl.prefix + "repo_name", Value: input.Repo})
+		}
+
+		var rows int64
+		var err error
+		if input.DryRun {
+			rows, err = tbl.client.CountRows(ctx, conditions...)
+		} else {
+			rows, err = tbl.client.DeleteRows(ctx, conditions...)
+		}
+		if err != nil {
+			return results, goerr.Wrap(err, "failed to delete BigQuery rows", goerr.V("table", tbl.name), goerr.V("dry_run", input.DryRun))
+		}
+
+		results = append(results, &model.DeletedBigQueryRows{Table: tbl.name, Rows: rows})
+		progress.From(ctx).Set("bigquery_"+tbl.name+"_rows", rows)
+	}
+
+	return results, nil
+}
+
+// bigQueryTable is a BigQuery table of scan results with the prefix of repository columns. The scan table has repository columns in the github record, and normalized tables have them at top level.
+type bigQueryTable struct {
+	name   string
+	client interfaces.BigQuery
+	prefix string
+}
+
+// bigQueryTables returns the scan table, and the vulnerability and package tables in normalized insert mode
+func (x *UseCase) bigQueryTables() []bigQueryTable {
+	bq := x.clients.BigQuery()
+	tables := []bigQueryTable{{"scan", bq, "github."}}
+	if x.bqVulnTable != "" {
+		tables = append(tables, bigQueryTable{"vulnerability", bq.Table(x.bqVulnTable), ""})
+	}
+	if x.bqPackageTable != "" {
+		tables = append(tables, bigQueryTable{"package", bq.Table(x.bqPackageTable), ""})
+	}
+	return tables
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestDeleteRepositoryData(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) interfaces.ScanRepository {
+		repo := memory.New()
+		for _, repoID := range []types.GitHubRepoID{"test-org/app", "test-org/lib", "prod-org/app"} {
+			owner, name := repoID.Split()
+			gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: owner, Name: name, CreatedAt: now, UpdatedAt: now}))
+			gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main", Status: types.ScanStatusSuccess}))
+			targetID := model.ToTargetID("go.mod", "", "")
+			gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: targetID, Target: "go.mod"}))
+			gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", targetID, []*model.Vulnerability{
+				{ID: "CVE-2024-0001", Status: types.VulnStatusActive},
+				{ID: "CVE-2024-0002", Status: types.VulnStatusFixed},
+			}))
+			gt.NoError(t, repo.CreateScanRecord(ctx, repoID, &model.ScanRecord{ID: "scan-1", Branch: "main", Timestamp: now}))
+		}
+		return repo
+	}
+
+	t.Run("dry run only counts data", func(t *testing.T) {
+		repo := setup(t)
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+		result, err := uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org", DryRun: true})
+		gt.NoError(t, err)
+		gt.True(t, result.DryRun)
+		gt.A(t, result.Repositories).Length(2)
+		gt.V(t, result.Repositories[0]).Equal(&model.DeletedRepository{ID: "test-org/app", Branches: 1, Targets: 1, Vulnerabilities: 2, ScanRecords: 1})
+
+		repos, err := repo.ListRepositoriesByOwner(ctx, "test-org")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(2)
+	})
+
+	t.Run("delete all repositories of owner", func(t *testing.T) {
+		repo := setup(t)
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+		result, err := uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org"})
+		gt.NoError(t, err)
+		gt.A(t, result.Repositories).Length(2)
+
+		repos, err := repo.ListRepositoriesByOwner(ctx, "test-org")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(0)
+		repos, err = repo.ListRepositoriesByOwner(ctx, "prod-org")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(1)
+	})
+
+	t.Run("delete a repository", func(t *testing.T) {
+		repo := setup(t)
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+		result, err := uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org", Repo: "lib"})
+		gt.NoError(t, err)
+		gt.A(t, result.Repositories).Length(1)
+		gt.V(t, result.Repositories[0].ID).Equal(types.GitHubRepoID("test-org/lib"))
+
+		repos, err := repo.ListRepositoriesByOwner(ctx, "test-org")
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(1)
+		gt.V(t, repos[0].Name).Equal("app")
+
+		// Missing repository is not an error so that BigQuery rows can be deleted
+		result, err = uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org", Repo: "lib"})
+		gt.NoError(t, err)
+		gt.A(t, result.Repositories).Length(0)
+	})
+
+	t.Run("delete BigQuery rows", func(t *testing.T) {
+		var deleted, counted [][]interfaces.BigQueryCondition
+		var tables []types.BQTableID
+		newTable := func() *mock.BigQueryMock {
+			return &mock.BigQueryMock{
+				CountRowsFunc: func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+					counted = append(counted, conditions)
+					return 3, nil
+				},
+				DeleteRowsFunc: func(ctx context.Context, conditions ...interfaces.BigQueryCondition) (int64, error) {
+					deleted = append(deleted, conditions)
+					return 3, nil
+				},
+			}
+		}
+		bq := newTable()
+		bq.TableFunc = func(tableID types.BQTableID) interfaces.BigQuery {
+			tables = append(tables, tableID)
+			return newTable()
+		}
+
+		uc := usecase.New(infra.New(infra.WithScanRepository(setup(t)), infra.WithBigQuery(bq)),
+			usecase.WithBigQueryNormalizedInsert("scans_vulnerabilities", "scans_packages"))
+
+		result, err := uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org", Repo: "app", BigQuery: true, DryRun: true})
+		gt.NoError(t, err)
+		gt.A(t, result.BigQuery).Length(3)
+		gt.V(t, result.BigQuery[0]).Equal(&model.DeletedBigQueryRows{Table: "scan", Rows: 3})
+		gt.A(t, deleted).Length(0)
+		gt.A(t, counted).Length(3)
+		gt.V(t, counted[0]).Equal([]interfaces.BigQueryCondition{
+			{Column: "github.owner", Value: "test-org"},
+			{Column: "github.repo_name", Value: "app"},
+		})
+		gt.V(t, counted[1]).Equal([]interfaces.BigQueryCondition{
+			{Column: "owner", Value: "test-org"},
+			{Column: "repo_name", Value: "app"},
+		})
+		gt.V(t, tables).Equal([]types.BQTableID{"scans_vulnerabilities", "scans_packages"})
+
+		_, err = uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org", BigQuery: true})
+		gt.NoError(t, err)
+		gt.A(t, deleted).Length(3)
+		gt.V(t, deleted[0]).Equal([]interfaces.BigQueryCondition{{Column: "github.owner", Value: "test-org"}})
+	})
+
+	t.Run("Firestore is required", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.DeleteRepositoryData(ctx, &model.DeleteRepositoryDataInput{Owner: "test-org"})
+		gt.Error(t, err)
+	})
+}
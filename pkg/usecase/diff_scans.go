@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// DiffScans loads two scans from the BigQuery raw scan table and compares their vulnerabilities. A commit SHA is resolved to the latest scan of the commit, looked up in the scan history of ScanRepository if configured, otherwise in BigQuery.
+func (x *UseCase) DiffScans(ctx context.Context, input *model.DiffScansInput) (*model.ScanDiff, error) {
+	if x.clients.BigQuery() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "diff requires BigQuery")
+	}
+	if input.Base == "" || input.Head == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "base and head are required")
+	}
+
+	base, err := x.loadScan(ctx, input.Owner, input.Repo, input.Base)
+	if err != nil {
+		return nil, err
+	}
+	head, err := x.loadScan(ctx, input.Owner, input.Repo, input.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := model.NewScanDiff(base, head)
+	added, removed, unchanged := diff.Counts()
+	logging.From(ctx).Info("scans compared",
+		"base", base.ID,
+		"head", head.ID,
+		"added", added,
+		"removed", removed,
+		"unchanged", unchanged,
+	)
+	return diff, nil
+}
+
+// loadScan returns the scan of ref, a scan ID or a commit SHA
+func (x *UseCase) loadScan(ctx context.Context, owner, repo, ref string) (*model.Scan, error) {
+	var conditions []interfaces.BigQueryCondition
+	if owner != "" {
+		conditions = append(conditions, interfaces.BigQueryCondition{Column: "github.owner", Value: owner})
+	}
+	if repo != "" {
+		conditions = append(conditions, interfaces.BigQueryCondition{Column: "github.repo_name", Value: repo})
+	}
+
+	commit := types.NewCommitSHA(ref)
+	if commit.Validate() != nil {
+		conditions = append(conditions, interfaces.BigQueryCondition{Column: "id", Value: ref})
+	} else {
+		if owner == "" || repo == "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "owner and repo are required to select a scan by commit", goerr.V("commit", ref))
+		}
+		scanID, err := x.latestScanIDOfCommit(ctx, types.NewGitHubRepoID(owner, repo), commit)
+		if err != nil {
+			return nil, err
+		}
+		if scanID != "" {
+			conditions = append(conditions, interfaces.BigQueryCondition{Column: "id", Value: string(scanID)})
+		} else {
+			conditions = append(conditions, interfaces.BigQueryCondition{Column: "github.commit_id", Value: string(commit)})
+		}
+	}
+
+	// Scans are listed in ascending order of timestamp, so the last one is the latest
+	var found *model.Scan
+	if err := x.clients.BigQuery().ListRawScans(ctx, func(scan *model.Scan) error {
+		found = scan
+		return nil
+	}, conditions...); err != nil {
+		return nil, goerr.Wrap(err, "failed to load scan", goerr.V("ref", ref))
+	}
+	if found == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "scan not found", goerr.V("ref", ref), goerr.V("owner", owner), goerr.V("repo", repo))
+	}
+
+	return found, nil
+}
+
+// latestScanIDOfCommit returns an empty ID if ScanRepository is not configured or has no scan of the commit, e.g. the repository is scanned without Firestore
+func (x *UseCase) latestScanIDOfCommit(ctx context.Context, repoID types.GitHubRepoID, commit types.CommitSHA) (types.ScanID, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return "", nil
+	}
+
+	records, err := scanRepo.ListScanRecords(ctx, repoID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to list scan records", goerr.V("repo_id", repoID))
+	}
+
+	var latest *model.ScanRecord
+	for _, record := range records {
+		if record.CommitSHA == commit && (latest == nil || record.Timestamp.After(latest.Timestamp)) {
+			latest = record
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.ID, nil
+}
@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+const (
+	diffBaseCommit = "1111111111111111111111111111111111111111"
+	diffHeadCommit = "2222222222222222222222222222222222222222"
+)
+
+// newDiffBigQuery returns scans matching all conditions as BigQuery does, in ascending order of timestamp
+func newDiffBigQuery(scans ...*model.Scan) *mock.BigQueryMock {
+	return &mock.BigQueryMock{
+		ListRawScansFunc: func(ctx context.Context, fn func(scan *model.Scan) error, conditions ...interfaces.BigQueryCondition) error {
+			for _, scan := range scans {
+				matched := true
+				for _, c := range conditions {
+					var v string
+					switch c.Column {
+					case "id":
+						v = string(scan.ID)
+					case "github.owner":
+						v = scan.GitHub.Owner
+					case "github.repo_name":
+						v = scan.GitHub.RepoName
+					case "github.commit_id":
+						v = scan.GitHub.CommitID
+					}
+					matched = matched && v == c.Value
+				}
+				if matched {
+					if err := fn(scan); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newDiffTestScan(id, commit string, ts time.Time, vulnIDs ...string) *model.Scan {
+	result := trivy.Result{Target: "go.mod"}
+	for _, vulnID := range vulnIDs {
+		result.Vulnerabilities = append(result.Vulnerabilities, trivy.DetectedVulnerability{VulnerabilityID: vulnID, PkgName: "example.com/pkg"})
+	}
+	return &model.Scan{
+		ID:        types.ScanID(id),
+		Timestamp: ts,
+		GitHub: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "myorg", RepoName: "myrepo"},
+				Branch:     "main",
+				CommitID:   commit,
+			},
+		},
+		Report: trivy.Report{Results: []trivy.Result{result}},
+	}
+}
+
+func TestDiffScans(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	bq := newDiffBigQuery(
+		newDiffTestScan("scan-base", diffBaseCommit, now.Add(-2*time.Hour), "CVE-2024-0001", "CVE-2024-0002"),
+		newDiffTestScan("scan-head-old", diffHeadCommit, now.Add(-time.Hour), "CVE-2024-0001"),
+		newDiffTestScan("scan-head", diffHeadCommit, now, "CVE-2024-0001", "CVE-2024-0003"),
+	)
+
+	t.Run("by scan ID", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq)))
+		diff, err := uc.DiffScans(ctx, &model.DiffScansInput{Base: "scan-base", Head: "scan-head-old"})
+		gt.NoError(t, err)
+		added, removed, unchanged := diff.Counts()
+		gt.V(t, [3]int{added, removed, unchanged}).Equal([3]int{0, 1, 1})
+	})
+
+	t.Run("by commit uses the latest scan", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq)))
+		diff, err := uc.DiffScans(ctx, &model.DiffScansInput{Owner: "myorg", Repo: "myrepo", Base: diffBaseCommit, Head: diffHeadCommit})
+		gt.NoError(t, err)
+		gt.V(t, diff.Base.ScanID).Equal(types.ScanID("scan-base"))
+		gt.V(t, diff.Head.ScanID).Equal(types.ScanID("scan-head"))
+		gt.A(t, diff.Targets[0].Added).Length(1)
+		gt.V(t, diff.Targets[0].Added[0].VulnID).Equal("CVE-2024-0003")
+		gt.V(t, diff.Targets[0].Removed[0].VulnID).Equal("CVE-2024-0002")
+	})
+
+	t.Run("commit is resolved by scan history", func(t *testing.T) {
+		repo := memory.New()
+		repoID := types.NewGitHubRepoID("myorg", "myrepo")
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "myorg", Name: "myrepo"}))
+		gt.NoError(t, repo.CreateScanRecord(ctx, repoID, &model.ScanRecord{ID: "scan-head-old", CommitSHA: diffHeadCommit, Timestamp: now}))
+
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq), infra.WithScanRepository(repo)))
+		diff, err := uc.DiffScans(ctx, &model.DiffScansInput{Owner: "myorg", Repo: "myrepo", Base: "scan-base", Head: diffHeadCommit})
+		gt.NoError(t, err)
+		gt.V(t, diff.Head.ScanID).Equal(types.ScanID("scan-head-old"))
+	})
+
+	t.Run("repository without scan history falls back to BigQuery", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq), infra.WithScanRepository(memory.New())))
+		diff, err := uc.DiffScans(ctx, &model.DiffScansInput{Owner: "myorg", Repo: "myrepo", Base: "scan-base", Head: diffHeadCommit})
+		gt.NoError(t, err)
+		gt.V(t, diff.Head.ScanID).Equal(types.ScanID("scan-head"))
+	})
+
+	t.Run("commit requires owner and repo", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq)))
+		_, err := uc.DiffScans(ctx, &model.DiffScansInput{Base: diffBaseCommit, Head: "scan-head"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("scan not found", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq)))
+		_, err := uc.DiffScans(ctx, &model.DiffScansInput{Base: "scan-base", Head: "no-such-scan"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("BigQuery is required", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.DiffScans(ctx, &model.DiffScansInput{Base: "scan-base", Head: "scan-head"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
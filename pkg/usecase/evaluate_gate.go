@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// EvaluateGate decides whether the branch passes a deployment gate. The gate fails if the
+// last scan did not succeed, is older than MaxScanAge, or if any active finding exceeds
+// MaxSeverity. With EnforceSLA, it fails also if any active finding is overdue by the SLA
+// policy. Acknowledged and fixed findings are not counted. Policies of WithGatePolicies are
+// then evaluated with all active findings, and the gate fails if any of them does not pass.
+func (x *UseCase) EvaluateGate(ctx context.Context, input *model.EvaluateGateInput) (*model.GateResult, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "deployment gate requires Firestore")
+	}
+	if input.EnforceSLA && len(x.slaPolicy) == 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "SLA policy is not configured")
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+
+	branchName := input.Branch
+	if branchName == "" {
+		branchName = repo.DefaultBranch
+	}
+	if branchName == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "branch is not specified and repository has no default branch", goerr.V("repo_id", repoID))
+	}
+
+	branch, err := scanRepo.GetBranch(ctx, repoID, branchName)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	result := &model.GateResult{
+		Pass:        true,
+		Repository:  string(repoID),
+		Branch:      string(branch.Name),
+		CommitSHA:   string(branch.LastCommitSHA),
+		LastScanAt:  branch.LastScanAt,
+		MaxSeverity: string(input.MaxSeverity),
+		Permalink:   x.permalinks.Branch(repoID, branch.Name),
+	}
+	if input.EnforceSLA {
+		result.SLA = x.slaPolicy.String()
+	}
+	fail := func(reason string) {
+		result.Pass = false
+		result.Justification = append(result.Justification, reason)
+	}
+
+	if branch.Status != types.ScanStatusSuccess {
+		fail(fmt.Sprintf("last scan status is %q", branch.Status))
+	}
+
+	if input.MaxScanAge > 0 {
+		result.MaxScanAge = input.MaxScanAge.String()
+		if age := logging.CtxTime(ctx).Sub(branch.LastScanAt); age > input.MaxScanAge {
+			fail(fmt.Sprintf("last scan at %s is older than %s", branch.LastScanAt.Format(time.RFC3339), input.MaxScanAge))
+		}
+	}
+
+	targets, err := scanRepo.ListTargets(ctx, repoID, branch.Name)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name))
+	}
+
+	now := logging.CtxTime(ctx)
+	var exceeded, overdue int
+	var findings []model.GateViolation
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branch.Name, target.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("target", target.Target))
+		}
+
+		for _, vuln := range vulns {
+			if vuln.Status != types.VulnStatusActive {
+				continue
+			}
+
+			var age *model.VulnerabilityAge
+			if len(x.slaPolicy) > 0 {
+				age = x.slaPolicy.AgeOf(vuln, now)
+			}
+			findingType := vuln.Type
+			if findingType == "" {
+				findingType = types.FindingTypeVulnerability
+			}
+			finding := model.GateViolation{
+				Target:         target.Target,
+				ID:             vuln.ID,
+				Type:           findingType,
+				Severity:       vuln.Severity,
+				PkgName:        vuln.PkgName,
+				Title:          vuln.Title,
+				Exploitability: vuln.Exploitability,
+				Age:            age,
+				Permalink:      x.permalinks.Finding(repoID, branch.Name, vuln.ID),
+			}
+			if len(x.gatePolicies) > 0 {
+				findings = append(findings, finding)
+			}
+
+			exceeds := input.MaxSeverity == "" || types.Severity(vuln.Severity).Exceeds(input.MaxSeverity)
+			isOverdue := input.EnforceSLA && age.Overdue
+			if !exceeds && !isOverdue {
+				continue
+			}
+			if exceeds {
+				exceeded++
+			} else {
+				overdue++
+			}
+			result.Violations = append(result.Violations, finding)
+		}
+	}
+
+	if exceeded > 0 {
+		if input.MaxSeverity != "" {
+			fail(fmt.Sprintf("%d active findings exceed severity %s", exceeded, input.MaxSeverity))
+		} else {
+			fail(fmt.Sprintf("%d active findings", exceeded))
+		}
+	}
+	if overdue > 0 {
+		fail(fmt.Sprintf("%d active findings are overdue by SLA %s", overdue, result.SLA))
+	}
+
+	if result.Pass {
+		result.Justification = append(result.Justification,
+			fmt.Sprintf("last scan succeeded at %s", branch.LastScanAt.Format(time.RFC3339)))
+		if input.MaxSeverity != "" {
+			result.Justification = append(result.Justification,
+				fmt.Sprintf("no active findings exceed severity %s", input.MaxSeverity))
+		} else {
+			result.Justification = append(result.Justification, "no active findings")
+		}
+		if input.EnforceSLA {
+			result.Justification = append(result.Justification,
+				fmt.Sprintf("no active findings are overdue by SLA %s", result.SLA))
+		}
+	}
+
+	if len(x.gatePolicies) > 0 {
+		x.evaluateGatePolicies(ctx, result, &model.GatePolicyInput{
+			Repository:     result.Repository,
+			Branch:         result.Branch,
+			CommitSHA:      result.CommitSHA,
+			LastScanAt:     result.LastScanAt,
+			LastScanStatus: branch.Status,
+			MaxSeverity:    result.MaxSeverity,
+			Pass:           result.Pass,
+			Justification:  slices.Clone(result.Justification),
+			Findings:       findings,
+		})
+	}
+
+	logging.From(ctx).Info("deployment gate evaluated",
+		"repo_id", repoID,
+		"branch", branch.Name,
+		"pass", result.Pass,
+		"violations", len(result.Violations),
+	)
+
+	return result, nil
+}
+
+// evaluateGatePolicies adds decisions of gate policies to the result. A policy which fails to be evaluated fails the gate, so that a broken policy does not let a branch pass.
+func (x *UseCase) evaluateGatePolicies(ctx context.Context, result *model.GateResult, input *model.GatePolicyInput) {
+	if input.Findings == nil {
+		input.Findings = []model.GateViolation{}
+	}
+
+	for _, policy := range x.gatePolicies {
+		decision, err := policy.Evaluate(ctx, input)
+		if err != nil {
+			logging.From(ctx).Error("failed to evaluate gate policy",
+				"policy", policy.Name(),
+				"repository", result.Repository,
+				"branch", result.Branch,
+				"error", err,
+			)
+			decision = &model.GatePolicyDecision{
+				Policy:  policy.Name(),
+				Reasons: []string{"failed to evaluate policy"},
+			}
+		}
+
+		result.Policies = append(result.Policies, *decision)
+		if !decision.Pass {
+			result.Pass = false
+		}
+		for _, reason := range decision.Reasons {
+			result.Justification = append(result.Justification, fmt.Sprintf("policy %s: %s", decision.Policy, reason))
+		}
+		if !decision.Pass && len(decision.Reasons) == 0 {
+			result.Justification = append(result.Justification, fmt.Sprintf("policy %s: not passed", decision.Policy))
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestEvaluateGate(t *testing.T) {
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+
+	setup := func(t *testing.T, lastScanAt time.Time, status types.ScanStatus, vulns []*model.Vulnerability, options ...usecase.Option) *usecase.UseCase {
+		ctx := context.Background()
+		repo := memory.New()
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:            repoID,
+			Owner:         "test-owner",
+			Name:          "test-repo",
+			DefaultBranch: "main",
+		}))
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:          "main",
+			LastScanAt:    lastScanAt,
+			LastCommitSHA: "0123456789abcdef0123456789abcdef01234567",
+			Status:        status,
+		}))
+		target := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+		gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", target))
+		if len(vulns) > 0 {
+			gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", target.ID, vulns))
+		}
+		return usecase.New(infra.New(infra.WithScanRepository(repo)), options...)
+	}
+	vulns := []*model.Vulnerability{
+		{ID: "CVE-2024-0001", PkgName: "pkg-a", Severity: "CRITICAL", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0002", PkgName: "pkg-b", Severity: "HIGH", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0003", PkgName: "pkg-c", Severity: "CRITICAL", Status: types.VulnStatusAcknowledged},
+		{ID: "CVE-2024-0004", PkgName: "pkg-d", Severity: "CRITICAL", Status: types.VulnStatusFixed},
+	}
+
+	t.Run("fails on active findings above max severity", func(t *testing.T) {
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns)
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "test-repo",
+			MaxSeverity: types.SeverityHigh,
+			MaxScanAge:  time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.V(t, result.Branch).Equal("main")
+		gt.A(t, result.Violations).Length(1)
+		gt.V(t, result.Violations[0].ID).Equal("CVE-2024-0001")
+		gt.V(t, result.Violations[0].Type).Equal(types.FindingTypeVulnerability)
+		gt.A(t, result.Justification).Length(1)
+	})
+
+	t.Run("passes when findings are within max severity", func(t *testing.T) {
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns)
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "test-repo",
+			MaxSeverity: types.SeverityCritical,
+		})
+		gt.NoError(t, err)
+		gt.True(t, result.Pass)
+		gt.A(t, result.Violations).Length(0)
+	})
+
+	t.Run("any active finding fails without max severity", func(t *testing.T) {
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns)
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.A(t, result.Violations).Length(2)
+	})
+
+	t.Run("fails on stale scan", func(t *testing.T) {
+		uc := setup(t, time.Now().Add(-48*time.Hour), types.ScanStatusSuccess, nil)
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:      "test-owner",
+			Repo:       "test-repo",
+			Branch:     "main",
+			MaxScanAge: 24 * time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.S(t, result.Justification[0]).Contains("older than")
+	})
+
+	t.Run("fails when last scan did not succeed", func(t *testing.T) {
+		uc := setup(t, time.Now(), types.ScanStatusFailure, nil)
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+	})
+
+	t.Run("unknown branch is not found", func(t *testing.T) {
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, nil)
+
+		_, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:  "test-owner",
+			Repo:   "test-repo",
+			Branch: "no-such-branch",
+		})
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("policy gets active findings and decision of built-in evaluation", func(t *testing.T) {
+		policy := &gatePolicy{name: "test-policy", decision: &model.GatePolicyDecision{Pass: true, Reasons: []string{"allowed"}}}
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns, usecase.WithGatePolicies(policy))
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "test-repo",
+			MaxSeverity: types.SeverityCritical,
+		})
+		gt.NoError(t, err)
+		gt.True(t, result.Pass)
+		gt.A(t, result.Policies).Length(1).At(0, func(t testing.TB, v model.GatePolicyDecision) {
+			gt.V(t, v.Policy).Equal("test-policy")
+			gt.True(t, v.Pass)
+		})
+		gt.A(t, result.Justification).Contains([]string{"policy test-policy: allowed"})
+
+		gt.A(t, policy.inputs).Length(1).At(0, func(t testing.TB, v *model.GatePolicyInput) {
+			gt.V(t, v.Repository).Equal("test-owner/test-repo")
+			gt.V(t, v.Branch).Equal("main")
+			gt.V(t, v.LastScanStatus).Equal(types.ScanStatusSuccess)
+			gt.True(t, v.Pass)
+			gt.A(t, v.Findings).Length(2)
+		})
+	})
+
+	t.Run("policy fails gate passed by built-in evaluation", func(t *testing.T) {
+		policy := &gatePolicy{name: "test-policy", decision: &model.GatePolicyDecision{Pass: false, Reasons: []string{"HIGH findings are not allowed"}}}
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns, usecase.WithGatePolicies(policy))
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner:       "test-owner",
+			Repo:        "test-repo",
+			MaxSeverity: types.SeverityCritical,
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.A(t, result.Justification).Contains([]string{"policy test-policy: HIGH findings are not allowed"})
+	})
+
+	t.Run("policy does not pass gate failed by built-in evaluation", func(t *testing.T) {
+		policy := &gatePolicy{name: "test-policy", decision: &model.GatePolicyDecision{Pass: true}}
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, vulns, usecase.WithGatePolicies(policy))
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.A(t, policy.inputs).Length(1).At(0, func(t testing.TB, v *model.GatePolicyInput) {
+			gt.False(t, v.Pass)
+		})
+	})
+
+	t.Run("policy failing to evaluate fails gate", func(t *testing.T) {
+		policy := &gatePolicy{name: "broken-policy", err: errors.New("trap")}
+		uc := setup(t, time.Now(), types.ScanStatusSuccess, nil, usecase.WithGatePolicies(policy))
+
+		result, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.NoError(t, err)
+		gt.False(t, result.Pass)
+		gt.A(t, result.Policies).Length(1).At(0, func(t testing.TB, v model.GatePolicyDecision) {
+			gt.V(t, v.Policy).Equal("broken-policy")
+			gt.False(t, v.Pass)
+		})
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.EvaluateGate(context.Background(), &model.EvaluateGateInput{Owner: "test-owner", Repo: "test-repo"})
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+// gatePolicy is a gate policy returning the decision or the error, recording inputs
+type gatePolicy struct {
+	name     string
+	decision *model.GatePolicyDecision
+	err      error
+	inputs   []*model.GatePolicyInput
+}
+
+func (x *gatePolicy) Name() string {
+	return x.name
+}
+
+func (x *gatePolicy) Evaluate(ctx context.Context, input *model.GatePolicyInput) (*model.GatePolicyDecision, error) {
+	x.inputs = append(x.inputs, input)
+	if x.err != nil {
+		return nil, x.err
+	}
+	decision := *x.decision
+	decision.Policy = x.name
+	return &decision, nil
+}
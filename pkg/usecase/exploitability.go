@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// lookupExploitability returns exploitability of CVEs in the report, or nil if enrichment is disabled or fails. Enrichment is auxiliary to the scan, so a failure is logged and vulnerabilities are stored without it.
+func (x *UseCase) lookupExploitability(ctx context.Context, report trivy.Report) map[string]*model.Exploitability {
+	if x.exploitability == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var cveIDs []string
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			if model.IsCVE(vuln.VulnerabilityID) && !seen[vuln.VulnerabilityID] {
+				seen[vuln.VulnerabilityID] = true
+				cveIDs = append(cveIDs, vuln.VulnerabilityID)
+			}
+		}
+	}
+	if len(cveIDs) == 0 {
+		return nil
+	}
+	sort.Strings(cveIDs)
+
+	found, err := x.exploitability.Lookup(ctx, cveIDs)
+	if err != nil {
+		logging.From(ctx).Warn("failed to look up exploitability of vulnerabilities", "artifact", report.ArtifactName, "cves", len(cveIDs), "error", err)
+		return nil
+	}
+
+	var kev int
+	for _, e := range found {
+		if e.KnownExploited {
+			kev++
+		}
+	}
+	logging.From(ctx).Info("looked up exploitability of vulnerabilities",
+		"artifact", report.ArtifactName,
+		"cves", len(cveIDs),
+		"found", len(found),
+		"known_exploited", kev,
+	)
+	return found
+}
+
+// setExploitability sets exploitability to vulnerability findings of CVEs in the map
+func setExploitability(findings []*model.Vulnerability, exploitability map[string]*model.Exploitability) {
+	for _, finding := range findings {
+		if e, ok := exploitability[finding.ID]; ok && (finding.Type == "" || finding.Type == types.FindingTypeVulnerability) {
+			finding.Exploitability = e
+		}
+	}
+}
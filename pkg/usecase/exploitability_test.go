@@ -0,0 +1,130 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+// exploitabilitySource returns data of the map, or err if set
+type exploitabilitySource struct {
+	data    map[string]*model.Exploitability
+	err     error
+	queries [][]string
+}
+
+func (x *exploitabilitySource) Lookup(ctx context.Context, cveIDs []string) (map[string]*model.Exploitability, error) {
+	x.queries = append(x.queries, cveIDs)
+	if x.err != nil {
+		return nil, x.err
+	}
+	found := map[string]*model.Exploitability{}
+	for _, id := range cveIDs {
+		if e, ok := x.data[id]; ok {
+			found[id] = e
+		}
+	}
+	return found, nil
+}
+
+func TestInsertScanResultWithExploitability(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	source := &exploitabilitySource{data: map[string]*model.Exploitability{
+		"CVE-2021-44228": {EPSSScore: 0.97, EPSSPercentile: 0.99, KnownExploited: true, KEVDateAdded: "2021-12-10"},
+		"CVE-2024-0001":  {EPSSScore: 0.01, EPSSPercentile: 0.2},
+	}}
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)), usecase.WithExploitability(source))
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-repo",
+		Results: []trivy.Result{
+			{
+				Target: "pom.xml",
+				Type:   "pom",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2021-44228", PkgName: "log4j-core", InstalledVersion: "2.14.1", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "commons-text", InstalledVersion: "1.9", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+					{VulnerabilityID: "GHSA-xxxx-yyyy-zzzz", PkgName: "commons-io", InstalledVersion: "2.6", Vulnerability: trivy.Vulnerability{Severity: "LOW"}},
+				},
+			},
+		},
+	}
+	targetID := model.ToTargetID("pom.xml", "", "pom")
+	listVulns := func(t *testing.T) map[string]*model.Vulnerability {
+		vulns, err := repo.ListVulnerabilities(ctx, "test-owner/test-repo", "main", targetID)
+		gt.NoError(t, err)
+		m := map[string]*model.Vulnerability{}
+		for _, v := range vulns {
+			m[v.ID] = v
+		}
+		return m
+	}
+
+	t.Run("stores exploitability of CVEs", func(t *testing.T) {
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+
+		// Only CVE IDs are looked up
+		gt.A(t, source.queries).Length(1).At(0, func(t testing.TB, v []string) {
+			gt.V(t, v).Equal([]string{"CVE-2021-44228", "CVE-2024-0001"})
+		})
+
+		vulns := listVulns(t)
+		gt.True(t, vulns["CVE-2021-44228"].Exploitability.KnownExploited)
+		gt.V(t, vulns["CVE-2024-0001"].Exploitability.EPSSScore).Equal(0.01)
+		gt.V(t, vulns["GHSA-xxxx-yyyy-zzzz"].Exploitability).Nil()
+
+		findings := gt.R1(uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner"})).NoError(t)
+		gt.A(t, findings).Length(3).At(0, func(t testing.TB, v *model.Finding) {
+			gt.V(t, v.ID).Equal("CVE-2021-44228")
+			gt.V(t, v.Exploitability.KEVDateAdded).Equal("2021-12-10")
+		})
+	})
+
+	t.Run("refreshes exploitability of stored vulnerabilities", func(t *testing.T) {
+		// The CVE is added to KEV and acknowledged before the next scan
+		source.data["CVE-2024-0001"] = &model.Exploitability{EPSSScore: 0.6, EPSSPercentile: 0.95, KnownExploited: true}
+		gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, "test-owner/test-repo", "main", targetID, []*model.VulnStatusChange{
+			{VulnID: "CVE-2024-0001", From: types.VulnStatusActive, To: types.VulnStatusAcknowledged, Acknowledgement: &model.Acknowledgement{By: "alice"}},
+		}))
+		createdAt := listVulns(t)["CVE-2024-0001"].CreatedAt
+
+		meta.CommitID = "1111111111111111111111111111111111111111"
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+
+		vuln := listVulns(t)["CVE-2024-0001"]
+		gt.True(t, vuln.Exploitability.KnownExploited)
+		gt.V(t, vuln.Exploitability.EPSSScore).Equal(0.6)
+		gt.V(t, vuln.Status).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, vuln.Acknowledgement.By).Equal("alice")
+		gt.V(t, vuln.CreatedAt).Equal(createdAt)
+	})
+
+	t.Run("keeps stored exploitability if lookup fails", func(t *testing.T) {
+		source.err = errors.New("feed is unavailable")
+
+		meta.CommitID = "2222222222222222222222222222222222222222"
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+
+		vulns := listVulns(t)
+		gt.True(t, vulns["CVE-2021-44228"].Exploitability.KnownExploited)
+		gt.V(t, vulns["CVE-2024-0001"].Exploitability.EPSSScore).Equal(0.6)
+	})
+}
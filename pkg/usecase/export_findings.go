@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ExportFindings returns stored findings of all branches of the owner's repositories matching the filters, for compliance reporting. Unlike ListFindings, fixed findings are included with their fix dates unless they were fixed before Since. Findings are ordered by repository, branch with the default branch first, severity (highest first), target and ID.
+func (x *UseCase) ExportFindings(ctx context.Context, input *model.ExportFindingsInput) ([]*model.ExportedFinding, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "exporting findings requires Firestore")
+	}
+	if input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "owner is required")
+	}
+
+	var repos []*model.Repository
+	if input.Repo != "" {
+		repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+		repo, err := scanRepo.GetRepository(ctx, repoID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+		}
+		repos = append(repos, repo)
+	} else {
+		ownerRepos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+		}
+		repos = ownerRepos
+	}
+
+	findings := []*model.ExportedFinding{}
+	for _, repo := range repos {
+		if !repo.MatchMetadata(input.Tag, input.Team) {
+			continue
+		}
+
+		vulns, err := scanRepo.ListRepositoryVulnerabilities(ctx, repo.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities of repository", goerr.V("repo_id", repo.ID))
+		}
+
+		for _, bv := range vulns {
+			if input.Branch != "" && bv.Branch != input.Branch {
+				continue
+			}
+			if input.MinSeverity != "" && !types.Severity(bv.Vulnerability.Severity).AtLeast(input.MinSeverity) {
+				continue
+			}
+
+			finding := model.NewExportedFinding(repo, bv)
+			if !input.Since.IsZero() && !finding.FixedAt.IsZero() && finding.FixedAt.Before(input.Since) {
+				continue
+			}
+			finding.Permalink = x.permalinks.Finding(repo.ID, bv.Branch, bv.Vulnerability.ID)
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		if a.Branch != b.Branch {
+			if a.DefaultBranch != b.DefaultBranch {
+				return a.DefaultBranch
+			}
+			return a.Branch < b.Branch
+		}
+		if ra, rb := types.Severity(a.Severity).Rank(), types.Severity(b.Severity).Rank(); ra != rb {
+			return ra > rb
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.ID < b.ID
+	})
+
+	return findings, nil
+}
@@ -0,0 +1,95 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestExportFindings(t *testing.T) {
+	vuln := func(id, pkg, severity string) trivy.DetectedVulnerability {
+		return trivy.DetectedVulnerability{VulnerabilityID: id, PkgName: pkg, Vulnerability: trivy.Vulnerability{Severity: severity, Title: "title of " + id}}
+	}
+	report := func(vulns ...trivy.DetectedVulnerability) trivy.Report {
+		return trivy.Report{SchemaVersion: 2, ArtifactName: "test-repo", Results: []trivy.Result{{Target: "go.mod", Vulnerabilities: vulns}}}
+	}
+	meta := func(branch, commit string) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     branch,
+				CommitID:   commit,
+			},
+			DefaultBranch: "main",
+		}
+	}
+	at := func(day string) context.Context {
+		ts := gt.R1(time.Parse(time.DateOnly, day)).NoError(t)
+		return logging.CtxWithTime(context.Background(), func() time.Time { return ts })
+	}
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithPermalinkBaseURL("https://octovy.example.com"))
+	gt.R1(uc.InsertScanResult(at("2024-01-10"), meta("main", "1111111111111111111111111111111111111111"),
+		report(vuln("CVE-2024-0001", "pkg-a", "HIGH"), vuln("CVE-2024-0002", "pkg-b", "LOW")))).NoError(t)
+	// CVE-2024-0002 is fixed on 2024-02-01
+	gt.R1(uc.InsertScanResult(at("2024-02-01"), meta("main", "2222222222222222222222222222222222222222"),
+		report(vuln("CVE-2024-0001", "pkg-a", "HIGH"), vuln("CVE-2024-0003", "pkg-c", "CRITICAL")))).NoError(t)
+	gt.R1(uc.InsertScanResult(at("2024-02-05"), meta("feature", "3333333333333333333333333333333333333333"),
+		report(vuln("CVE-2024-0001", "pkg-a", "HIGH")))).NoError(t)
+
+	t.Run("all findings of all branches", func(t *testing.T) {
+		findings := gt.R1(uc.ExportFindings(context.Background(), &model.ExportFindingsInput{Owner: "test-owner"})).NoError(t)
+		gt.A(t, findings).Length(4).
+			At(0, func(t testing.TB, v *model.ExportedFinding) {
+				gt.V(t, v.Branch).Equal("main")
+				gt.True(t, v.DefaultBranch)
+				gt.V(t, v.ID).Equal("CVE-2024-0003")
+				gt.V(t, v.DetectedAt.Format(time.DateOnly)).Equal("2024-02-01")
+				gt.True(t, v.FixedAt.IsZero())
+				gt.V(t, v.Permalink).Equal("https://octovy.example.com/r/test-owner/test-repo/b/main/v/CVE-2024-0003")
+			}).
+			At(2, func(t testing.TB, v *model.ExportedFinding) {
+				gt.V(t, v.ID).Equal("CVE-2024-0002")
+				gt.V(t, v.Status).Equal(types.VulnStatusFixed)
+				gt.V(t, v.FixedAt.Format(time.DateOnly)).Equal("2024-02-01")
+			}).
+			At(3, func(t testing.TB, v *model.ExportedFinding) {
+				gt.V(t, v.Branch).Equal("feature")
+				gt.False(t, v.DefaultBranch)
+			})
+	})
+
+	t.Run("findings fixed before since are dropped", func(t *testing.T) {
+		since := gt.R1(time.Parse(time.DateOnly, "2024-02-02")).NoError(t)
+		findings := gt.R1(uc.ExportFindings(context.Background(), &model.ExportFindingsInput{Owner: "test-owner", Since: since})).NoError(t)
+		gt.A(t, findings).Length(3)
+		for _, f := range findings {
+			gt.V(t, f.ID).NotEqual("CVE-2024-0002")
+		}
+	})
+
+	t.Run("filtered by branch and severity", func(t *testing.T) {
+		findings := gt.R1(uc.ExportFindings(context.Background(), &model.ExportFindingsInput{
+			Owner:       "test-owner",
+			Repo:        "test-repo",
+			Branch:      "main",
+			MinSeverity: types.SeverityHigh,
+		})).NoError(t)
+		gt.A(t, findings).Length(2)
+	})
+
+	t.Run("owner is required", func(t *testing.T) {
+		_, err := uc.ExportFindings(context.Background(), &model.ExportFindingsInput{})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
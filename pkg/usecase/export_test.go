@@ -1,12 +1,76 @@
 package usecase
 
+import (
+	"archive/zip"
+	"context"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	trivyClient "github.com/m-mizutani/octovy/pkg/infra/trivy"
+)
+
 // Export unexported functions for testing
 var (
-	DownloadZipFileForTest                 = downloadZipFile
-	ExtractCodeForTest                     = extractCode
-	StepDownDirectoryForTest               = stepDownDirectory
-	ExtractZipFileForTest                  = extractZipFile
-	CreateOrUpdateBigQueryTableForTest     = createOrUpdateBigQueryTable
-	LoadTrivyReportFromFileForTest         = LoadTrivyReportFromFile
+	DownloadZipFileForTest         = downloadZipFile
+	StepDownDirectoryForTest       = stepDownDirectory
+	ExtractZipFileForTest          = extractZipFile
+	LoadTrivyReportFromFileForTest = LoadTrivyReportFromFile
+	FindTrivyIgnoreFileForTest     = findTrivyIgnoreFile
 )
 
+// ExtractZipEntryForTest extracts a file of a GitHub zipball within the limits, as the only file of the archive
+func ExtractZipEntryForTest(f *zip.File, dst string, limits model.ArchiveLimits) error {
+	limiter, err := newZipLimiter([]*zip.File{f}, limits)
+	if err != nil {
+		return err
+	}
+	return extractZipEntry(f, dst, true, limiter)
+}
+
+func NewTrivyScannerForTest(client trivyClient.Client, scanners []types.TrivyScanner, args []string) interfaces.Scanner {
+	return &trivyScanner{client: client, scanners: scanners, args: args}
+}
+
+func (x *UseCase) CreateOrUpdateBigQueryTableForTest(ctx context.Context, bq interfaces.BigQuery, data any) (bigquery.Schema, bool, error) {
+	return x.createOrUpdateBigQueryTable(ctx, bq, data)
+}
+
+type ScannedReportsForTest = scannedReports
+
+const MaxScannedReportsForTest = maxScannedReports
+
+func NewScannedReportsForTest() *ScannedReportsForTest {
+	return newScannedReports()
+}
+
+func (x *scannedReports) PutForTest(ctx context.Context, meta *model.GitHubMetadata, report *trivy.Report) {
+	x.put(ctx, meta, report, nil, nil)
+}
+
+func (x *scannedReports) GetForTest(ctx context.Context, meta *model.GitHubMetadata) *trivy.Report {
+	if r := x.get(ctx, meta); r != nil {
+		return r.report
+	}
+	return nil
+}
+
+type BQTableLayoutForTest = bqTableLayout
+
+func NewBQTableLayoutForTest(partitioning types.BQPartitioning, clustering bool) *BQTableLayoutForTest {
+	return &bqTableLayout{partitioning: partitioning, clustering: clustering}
+}
+
+func (x *bqTableLayout) TimePartitioningForTest(schema bigquery.Schema) *bigquery.TimePartitioning {
+	return x.timePartitioning(schema)
+}
+
+func (x *bqTableLayout) ClusteringUpdateForTest(md *bigquery.TableMetadata, schema bigquery.Schema) *bigquery.Clustering {
+	return x.clusteringUpdate(md, schema)
+}
+
+func (x *bqTableLayout) CheckPartitioningForTest(ctx context.Context, md *bigquery.TableMetadata) {
+	x.checkPartitioning(ctx, md)
+}
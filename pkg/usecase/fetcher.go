@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+// fetcher returns the fetcher of the source kind. A fetcher set by WithFetcher has priority over the default one.
+func (x *UseCase) fetcher(kind types.SourceKind) (interfaces.Fetcher, error) {
+	if fetcher, ok := x.fetchers[kind]; ok {
+		return fetcher, nil
+	}
+
+	switch kind {
+	case types.SourceGitHub:
+		if x.clients.GitHubApp() == nil {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App client is required to fetch source code from GitHub")
+		}
+		return &githubFetcher{app: x.clients.GitHubApp(), httpClient: x.clients.HTTPClient(), archive: x.archive}, nil
+
+	case types.SourceLocal:
+		return &localFetcher{}, nil
+
+	case types.SourceURL:
+		return &urlFetcher{httpClient: x.clients.HTTPClient(), archive: x.archive}, nil
+
+	case types.SourceGCS:
+		if x.clients.ObjectReader() == nil {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "Cloud Storage client is required to fetch source code from Cloud Storage")
+		}
+		return &gcsFetcher{reader: x.clients.ObjectReader(), archive: x.archive}, nil
+
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unsupported source kind", goerr.V("kind", kind))
+	}
+}
+
+// githubFetcher downloads the zipball of the commit with GitHub App credentials
+type githubFetcher struct {
+	app        interfaces.GitHubApp
+	httpClient infra.HTTPClient
+	archive    archiveConfig
+}
+
+func (x *githubFetcher) Fetch(ctx context.Context, input *model.ScanGitHubRepoInput, workDir string) (*model.FetchedSource, error) {
+	zipURL, err := x.app.GetArchiveURL(ctx, &interfaces.GetArchiveURLInput{
+		Owner:     input.Owner,
+		Repo:      input.RepoName,
+		CommitID:  input.CommitID,
+		InstallID: input.InstallID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := x.archive.fetch(ctx, &input.GitHubMetadata, workDir, extractZipFile, func(w io.Writer) error {
+		return downloadZipFile(ctx, x.httpClient, zipURL, w, x.archive.limits.MaxDownloadSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.FetchedSource{Dir: workDir, Archive: archive}, nil
+}
+
+// localFetcher scans a local directory in place without copying it
+type localFetcher struct{}
+
+func (x *localFetcher) Fetch(_ context.Context, input *model.ScanGitHubRepoInput, _ string) (*model.FetchedSource, error) {
+	dir := input.Source.Location
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "source directory is not accessible", goerr.V("dir", dir), goerr.V("error", err.Error()))
+	}
+	if !stat.IsDir() {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "source is not a directory", goerr.V("dir", dir))
+	}
+	return &model.FetchedSource{Dir: dir}, nil
+}
+
+// urlFetcher downloads a zip archive by plain HTTP(S) GET, e.g. a presigned URL of an object storage. No credential is sent.
+type urlFetcher struct {
+	httpClient infra.HTTPClient
+	archive    archiveConfig
+}
+
+func (x *urlFetcher) Fetch(ctx context.Context, input *model.ScanGitHubRepoInput, workDir string) (*model.FetchedSource, error) {
+	zipURL, err := url.Parse(input.Source.Location)
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "invalid source URL", goerr.V("url", input.Source.Location))
+	}
+
+	archive, err := x.archive.fetch(ctx, &input.GitHubMetadata, workDir, extractArchiveFile, func(w io.Writer) error {
+		return downloadZipFile(ctx, x.httpClient, zipURL, w, x.archive.limits.MaxDownloadSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.FetchedSource{Dir: workDir, Archive: archive}, nil
+}
+
+// gcsFetcher reads a zip archive object from Cloud Storage
+type gcsFetcher struct {
+	reader  interfaces.ObjectReader
+	archive archiveConfig
+}
+
+func (x *gcsFetcher) Fetch(ctx context.Context, input *model.ScanGitHubRepoInput, workDir string) (*model.FetchedSource, error) {
+	bucket, object, err := input.Source.GCSObject()
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := x.archive.fetch(ctx, &input.GitHubMetadata, workDir, extractArchiveFile, func(w io.Writer) error {
+		r, err := x.reader.Open(ctx, bucket, object)
+		if err != nil {
+			return err
+		}
+		defer safe.Close(r)
+
+		if _, err := io.Copy(w, r); err != nil {
+			return goerr.Wrap(err, "failed to read zip file from Cloud Storage", goerr.V("bucket", bucket), goerr.V("object", object))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.FetchedSource{Dir: workDir, Archive: archive}, nil
+}
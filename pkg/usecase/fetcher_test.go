@@ -0,0 +1,245 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+// listingScanner records files of the scanned directory because the directory is removed after the scan
+type listingScanner struct {
+	dir   string
+	files []string
+}
+
+func (x *listingScanner) Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error) {
+	x.dir = target.Dir
+	err := filepath.WalkDir(target.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(target.Dir, path)
+		if err != nil {
+			return err
+		}
+		x.files = append(x.files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(x.files)
+	return &trivy.Report{SchemaVersion: 2, ArtifactName: target.Dir}, err
+}
+
+func newSourceScanInput(src *model.CodeSource) *model.ScanGitHubRepoInput {
+	return &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "build-123",
+			},
+		},
+		Source: src,
+		Force:  true,
+	}
+}
+
+func TestScanGitHubRepoLocalSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n"), 0644))
+
+	scanner := &listingScanner{}
+	repo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)), usecase.WithScanner(scanner))
+
+	gt.NoError(t, uc.ScanGitHubRepo(ctx, newSourceScanInput(&model.CodeSource{Kind: types.SourceLocal, Location: dir})))
+
+	// The local directory is scanned in place and the result is stored with the given metadata
+	gt.V(t, scanner.dir).Equal(dir)
+	gt.A(t, scanner.files).Equal([]string{"go.mod"})
+	branch := gt.R1(repo.GetBranch(ctx, types.NewGitHubRepoID("test-owner", "test-repo"), "main")).NoError(t)
+	gt.V(t, branch.LastCommitSHA).Equal(types.CommitSHA("build-123"))
+
+	t.Run("not a directory", func(t *testing.T) {
+		err := uc.ScanGitHubRepo(ctx, newSourceScanInput(&model.CodeSource{Kind: types.SourceLocal, Location: filepath.Join(dir, "go.mod")}))
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+func TestScanGitHubRepoURLSource(t *testing.T) {
+	testCases := map[string]struct {
+		files    map[string]string
+		expected []string
+	}{
+		"archive with a root directory": {
+			files:    map[string]string{"app/go.mod": "module example\n", "app/web/package-lock.json": "{}"},
+			expected: []string{"go.mod", "web/package-lock.json"},
+		},
+		"archive without root directory": {
+			files:    map[string]string{"go.mod": "module example\n", "web/package-lock.json": "{}"},
+			expected: []string{"go.mod", "web/package-lock.json"},
+		},
+		"archive of a single directory": {
+			files:    map[string]string{"web/package-lock.json": "{}", "web/yarn.lock": ""},
+			expected: []string{"package-lock.json", "yarn.lock"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			zipData := buildZipArchive(t, tc.files)
+			var gotAuth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				_, _ = w.Write(zipData)
+			}))
+			defer srv.Close()
+
+			scanner := &listingScanner{}
+			uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithScanner(scanner))
+			src := gt.R1(model.ParseCodeSource(srv.URL + "/app.zip")).NoError(t)
+
+			gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+			gt.A(t, scanner.files).Equal(tc.expected)
+			gt.V(t, gotAuth).Equal("")
+		})
+	}
+
+	t.Run("archive is recorded in scan", func(t *testing.T) {
+		zipData := buildZipArchive(t, map[string]string{"go.mod": "module example\n"})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(zipData)
+		}))
+		defer srv.Close()
+
+		var inserted *model.ScanRawRecord
+		bq := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+				return nil, nil
+			},
+			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error {
+				return nil
+			},
+			InsertFunc: func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+				inserted = data.(*model.ScanRawRecord)
+				return nil
+			},
+		}
+		src := gt.R1(model.ParseCodeSource(srv.URL + "/app.zip")).NoError(t)
+
+		uc := usecase.New(infra.New(infra.WithBigQuery(bq)), usecase.WithScanner(&listingScanner{}), usecase.WithArchiveChecksum())
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+		sum := sha256.Sum256(zipData)
+		gt.V(t, inserted.Archive).Equal(&model.ScanArchive{Size: int64(len(zipData)), SHA256: hex.EncodeToString(sum[:])})
+
+		// The digest is not computed by default
+		uc = usecase.New(infra.New(infra.WithBigQuery(bq)), usecase.WithScanner(&listingScanner{}))
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+		gt.V(t, inserted.Archive).Equal(&model.ScanArchive{Size: int64(len(zipData))})
+	})
+
+	t.Run("download exceeding limit is aborted", func(t *testing.T) {
+		zipData := buildZipArchive(t, map[string]string{"go.mod": "module example\n"})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Without Content-Length, the limit is checked while writing the archive
+			if r.URL.Query().Has("chunked") {
+				w.(http.Flusher).Flush()
+			}
+			_, _ = w.Write(zipData)
+		}))
+		defer srv.Close()
+
+		limits := model.DefaultArchiveLimits()
+		limits.MaxDownloadSize = int64(len(zipData)) - 1
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithScanner(&listingScanner{}), usecase.WithArchiveLimits(limits))
+
+		for _, u := range []string{srv.URL + "/app.zip", srv.URL + "/app.zip?chunked"} {
+			src := gt.R1(model.ParseCodeSource(u)).NoError(t)
+			err := uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src))
+			gt.True(t, errors.Is(err, types.ErrArchiveTooLarge))
+		}
+	})
+
+	t.Run("download failure", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer srv.Close()
+
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithScanner(&listingScanner{}))
+		src := gt.R1(model.ParseCodeSource(srv.URL + "/app.zip")).NoError(t)
+		gt.Error(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+	})
+}
+
+func TestScanGitHubRepoGCSSource(t *testing.T) {
+	zipData := buildZipArchive(t, map[string]string{"go.mod": "module example\n"})
+	reader := &mock.ObjectReaderMock{
+		OpenFunc: func(ctx context.Context, bucket, object string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(zipData)), nil
+		},
+	}
+	src := gt.R1(model.ParseCodeSource("gs://ci-artifacts/builds/app.zip")).NoError(t)
+
+	t.Run("object is fetched", func(t *testing.T) {
+		scanner := &listingScanner{}
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New()), infra.WithObjectReader(reader)), usecase.WithScanner(scanner))
+
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+		gt.A(t, scanner.files).Equal([]string{"go.mod"})
+		gt.A(t, reader.OpenCalls()).Length(1)
+		gt.V(t, reader.OpenCalls()[0].Bucket).Equal("ci-artifacts")
+		gt.V(t, reader.OpenCalls()[0].Object).Equal("builds/app.zip")
+	})
+
+	t.Run("Cloud Storage client is required", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithScanner(&listingScanner{}))
+		err := uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src))
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
+
+type staticFetcher struct {
+	dir string
+}
+
+func (x *staticFetcher) Fetch(ctx context.Context, input *model.ScanGitHubRepoInput, workDir string) (*model.FetchedSource, error) {
+	return &model.FetchedSource{Dir: x.dir}, nil
+}
+
+func TestScanGitHubRepoWithFetcher(t *testing.T) {
+	dir := t.TempDir()
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, "Cargo.lock"), []byte(""), 0644))
+
+	scanner := &listingScanner{}
+	uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())),
+		usecase.WithScanner(scanner),
+		usecase.WithFetcher(types.SourceURL, &staticFetcher{dir: dir}),
+	)
+
+	src := gt.R1(model.ParseCodeSource("https://artifacts.example.com/app.zip")).NoError(t)
+	gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newSourceScanInput(src)))
+	gt.V(t, scanner.dir).Equal(dir)
+	gt.A(t, scanner.files).Equal([]string{"Cargo.lock"})
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// summarizeActiveFindings counts active findings of all targets in the branch
+func summarizeActiveFindings(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName) (*model.FindingSummary, error) {
+	targets, err := scanRepo.ListTargets(ctx, repoID, branchName)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	summary := &model.FindingSummary{}
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branchName, target.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("repo_id", repoID), goerr.V("target", target.Target))
+		}
+
+		for _, vuln := range vulns {
+			if vuln.Status != types.VulnStatusActive {
+				continue
+			}
+			switch vuln.Type {
+			case types.FindingTypeMisconfiguration:
+				summary.Misconfigurations++
+			case types.FindingTypeSecret:
+				summary.Secrets++
+			default:
+				summary.Vulnerabilities.Add(vuln.Severity)
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// branchFindings returns active findings of the branch, from the counts stored in the branch if they are available
+func branchFindings(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, branch *model.Branch) (*model.FindingSummary, error) {
+	if branch.Findings != nil {
+		return branch.Findings, nil
+	}
+	return summarizeActiveFindings(ctx, scanRepo, repoID, branch.Name)
+}
+
+// storeFindingSummary counts active findings of the branch and stores them in the branch, and in the repository if the branch is its default branch. The branch is stored by ClaimBranchScan so that the counts do not overwrite a newer scan, which stores its own counts. The counts are only a cache of findings, so a failure is logged and the stale counts are cleared to fall back to counting findings on read.
+func storeFindingSummary(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, branch *model.Branch) {
+	logger := logging.From(ctx).With("repo_id", repoID, "branch", branch.Name)
+
+	summary, err := summarizeActiveFindings(ctx, scanRepo, repoID, branch.Name)
+	if err != nil {
+		logger.Error("failed to count active findings of branch", "error", err)
+	}
+	branch.Findings = summary
+	if err := scanRepo.ClaimBranchScan(ctx, repoID, branch); err != nil {
+		if !errors.Is(err, repository.ErrConflict) {
+			logger.Error("failed to store active findings of branch", "error", err)
+		}
+		return
+	}
+
+	repo, err := getStoredRepository(ctx, scanRepo, repoID)
+	if err != nil {
+		logger.Error("failed to get repository to store active findings", "error", err)
+		return
+	}
+	if repo == nil || repo.DefaultBranch != branch.Name {
+		return
+	}
+	repo.Findings = summary
+	if err := scanRepo.CreateOrUpdateRepository(ctx, repo); err != nil {
+		logger.Error("failed to store active findings of repository", "error", err)
+	}
+}
+
+// refreshFindingSummary recounts active findings of the branch after statuses of its findings are changed out of a scan, e.g. by acknowledgement
+func refreshFindingSummary(ctx context.Context, scanRepo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName) {
+	branch, err := scanRepo.GetBranch(ctx, repoID, branchName)
+	if err != nil {
+		logging.From(ctx).Error("failed to get branch to store active findings", "repo_id", repoID, "branch", branchName, "error", err)
+		return
+	}
+	storeFindingSummary(ctx, scanRepo, repoID, branch)
+}
@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestStoredFindingSummary(t *testing.T) {
+	memRepo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+
+	scan := func(t *testing.T, ts time.Time, branch string, vulns ...trivy.DetectedVulnerability) {
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return ts })
+		gt.R1(uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     branch,
+				CommitID:   "0000000000000000000000000000000000000000",
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-repo",
+			Results:       []trivy.Result{{Target: "go.mod", Vulnerabilities: vulns}},
+		})).NoError(t)
+	}
+	vuln := func(id, severity string) trivy.DetectedVulnerability {
+		return trivy.DetectedVulnerability{VulnerabilityID: id, PkgName: "pkg-" + id, InstalledVersion: "1.0.0", Vulnerability: trivy.Vulnerability{Severity: severity}}
+	}
+	getBranch := func(t *testing.T, name types.BranchName) *model.Branch {
+		return gt.R1(memRepo.GetBranch(context.Background(), repoID, name)).NoError(t)
+	}
+	getRepo := func(t *testing.T) *model.Repository {
+		return gt.R1(memRepo.GetRepository(context.Background(), repoID)).NoError(t)
+	}
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	scan(t, t0, "main", vuln("CVE-2024-0001", "CRITICAL"), vuln("CVE-2024-0002", "HIGH"), vuln("CVE-2024-0003", "LOW"))
+
+	t.Run("counts are stored in the branch and the repository", func(t *testing.T) {
+		findings := getBranch(t, "main").Findings
+		gt.NotNil(t, findings)
+		gt.V(t, findings.Vulnerabilities).Equal(model.SeverityCounts{Critical: 1, High: 1, Low: 1, Total: 3})
+
+		repo := getRepo(t)
+		gt.V(t, repo.Findings).Equal(findings)
+		gt.V(t, repo.LastScanAt).Equal(t0)
+	})
+
+	t.Run("scan of other branch updates only last scan time of the repository", func(t *testing.T) {
+		t1 := t0.Add(time.Hour)
+		scan(t, t1, "feature", vuln("CVE-2024-0001", "CRITICAL"))
+
+		gt.V(t, getBranch(t, "feature").Findings.Vulnerabilities).Equal(model.SeverityCounts{Critical: 1, Total: 1})
+		repo := getRepo(t)
+		gt.V(t, repo.Findings.Vulnerabilities.Total).Equal(3)
+		gt.V(t, repo.LastScanAt).Equal(t1)
+	})
+
+	t.Run("acknowledgement updates counts", func(t *testing.T) {
+		gt.R1(uc.AcknowledgeVulnerability(context.Background(), &model.AcknowledgeVulnerabilityInput{
+			Owner:   "test-owner",
+			Repo:    "test-repo",
+			VulnID:  "CVE-2024-0001",
+			By:      "alice",
+			Comment: "not reachable",
+		})).NoError(t)
+
+		expected := model.SeverityCounts{High: 1, Low: 1, Total: 2}
+		gt.V(t, getBranch(t, "main").Findings.Vulnerabilities).Equal(expected)
+		gt.V(t, getRepo(t).Findings.Vulnerabilities).Equal(expected)
+		// Findings of other branches are not acknowledged
+		gt.V(t, getBranch(t, "feature").Findings.Vulnerabilities.Total).Equal(1)
+	})
+
+	t.Run("rescan updates counts", func(t *testing.T) {
+		scan(t, t0.Add(2*time.Hour), "main", vuln("CVE-2024-0001", "CRITICAL"), vuln("CVE-2024-0003", "LOW"))
+
+		expected := model.SeverityCounts{Low: 1, Total: 1}
+		gt.V(t, getBranch(t, "main").Findings.Vulnerabilities).Equal(expected)
+		gt.V(t, getRepo(t).Findings.Vulnerabilities).Equal(expected)
+	})
+
+	t.Run("branch status uses stored counts", func(t *testing.T) {
+		ctx := context.Background()
+		branch := getBranch(t, "feature")
+		branch.Findings = &model.FindingSummary{Secrets: 5}
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, branch))
+
+		status := gt.R1(uc.ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: "test-owner", Repo: "test-repo"})).NoError(t)
+		gt.A(t, status.Branches).Length(2).At(1, func(t testing.TB, v *model.BranchStatus) {
+			gt.V(t, v.Name).Equal("feature")
+			gt.V(t, v.Findings.Secrets).Equal(5)
+		})
+	})
+
+	t.Run("branch without stored counts is counted from findings", func(t *testing.T) {
+		ctx := context.Background()
+		branch := getBranch(t, "feature")
+		branch.Findings = nil
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, branch))
+
+		status := gt.R1(uc.ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: "test-owner", Repo: "test-repo"})).NoError(t)
+		gt.A(t, status.Branches).Length(2).At(1, func(t testing.TB, v *model.BranchStatus) {
+			gt.V(t, v.Findings.Vulnerabilities).Equal(model.SeverityCounts{Critical: 1, Total: 1})
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// maxBackstageEntities bounds the number of entities of one request because each entity reads all findings of a branch
+const maxBackstageEntities = 100
+
+// GetBackstagePosture returns security posture of Backstage catalog entities in the order of the input. Entities are mapped to repositories by annotations; entities without a valid annotation or a scanned branch are reported with their status instead of an error.
+func (x *UseCase) GetBackstagePosture(ctx context.Context, input *model.BackstagePostureInput) ([]*model.BackstagePosture, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "Backstage integration requires Firestore")
+	}
+	if len(input.Entities) > maxBackstageEntities {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "too many entities", goerr.V("count", len(input.Entities)), goerr.V("max", maxBackstageEntities))
+	}
+
+	now := logging.CtxTime(ctx)
+	postures := make([]*model.BackstagePosture, 0, len(input.Entities))
+	for _, entity := range input.Entities {
+		if entity == nil || entity.Metadata.Name == "" {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "entity has no name")
+		}
+
+		posture, err := x.getBackstagePosture(ctx, entity, input.MaxScanAge, now)
+		if err != nil {
+			return nil, err
+		}
+		postures = append(postures, posture)
+	}
+
+	logging.From(ctx).Info("Backstage posture created", "entities", len(postures))
+
+	return postures, nil
+}
+
+func (x *UseCase) getBackstagePosture(ctx context.Context, entity *model.BackstageEntity, maxScanAge time.Duration, now time.Time) (*model.BackstagePosture, error) {
+	scanRepo := x.clients.ScanRepository()
+	posture := &model.BackstagePosture{
+		EntityRef: entity.Ref(),
+		Status:    model.BackstagePostureUnmapped,
+	}
+
+	repoID, branchName := entity.Repository()
+	if repoID.Validate() != nil {
+		return posture, nil
+	}
+	posture.Repository = string(repoID)
+	posture.Status = model.BackstagePostureNotScanned
+	posture.Links = []model.BackstageLink{
+		{URL: "https://github.com/" + string(repoID), Title: "Repository", Icon: "github"},
+		{URL: "https://github.com/" + string(repoID) + "/security/code-scanning", Title: "Code scanning alerts", Icon: "alert"},
+	}
+
+	repo, err := getStoredRepository(ctx, scanRepo, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo != nil {
+		posture.Team = repo.Team
+		posture.Tags = repo.Tags
+		if branchName == "" {
+			branchName = repo.DefaultBranch
+		}
+	}
+	if branchName == "" {
+		return posture, nil
+	}
+	posture.Branch = string(branchName)
+
+	branch, err := scanRepo.GetBranch(ctx, repoID, branchName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return posture, nil
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	findings, err := branchFindings(ctx, scanRepo, repoID, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	lastScanAt := branch.LastScanAt
+	posture.Status = model.BackstagePostureOK
+	posture.CommitSHA = string(branch.LastCommitSHA)
+	posture.ScanStatus = branch.Status
+	posture.LastScanAt = &lastScanAt
+	posture.Stale = maxScanAge > 0 && now.Sub(branch.LastScanAt) > maxScanAge
+	posture.Findings = findings
+	if link := x.permalinks.Branch(repoID, branch.Name); link != "" {
+		posture.Links = append(posture.Links, model.BackstageLink{URL: link, Title: "Octovy", Icon: "dashboard"})
+	}
+	if branch.LastCommitSHA != "" {
+		posture.Links = append(posture.Links, model.BackstageLink{
+			URL:   "https://github.com/" + string(repoID) + "/commit/" + string(branch.LastCommitSHA),
+			Title: "Last scanned commit",
+			Icon:  "github",
+		})
+	}
+
+	return posture, nil
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestGetBackstagePosture(t *testing.T) {
+	ctx := context.Background()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())))
+
+	_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "myorg", RepoName: "my-service"},
+			Branch:     "main",
+			CommitID:   "1111111111111111111111111111111111111111",
+		},
+		DefaultBranch: "main",
+	}, trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "my-service",
+		Results: []trivy.Result{{
+			Target: "go.mod",
+			Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+				{VulnerabilityID: "CVE-2024-0002", PkgName: "pkg-b", Vulnerability: trivy.Vulnerability{Severity: "MEDIUM"}},
+			},
+		}},
+	})
+	gt.NoError(t, err)
+
+	entity := func(name string, annotations map[string]string) *model.BackstageEntity {
+		return &model.BackstageEntity{
+			Kind:     "Component",
+			Metadata: model.BackstageEntityMetadata{Name: name, Annotations: annotations},
+		}
+	}
+
+	t.Run("postures in order of entities", func(t *testing.T) {
+		postures, err := uc.GetBackstagePosture(ctx, &model.BackstagePostureInput{
+			Entities: []*model.BackstageEntity{
+				entity("my-service", map[string]string{model.BackstageAnnotationProjectSlug: "myorg/my-service"}),
+				entity("no-annotation", nil),
+				entity("invalid", map[string]string{model.BackstageAnnotationRepository: "not a repo"}),
+				entity("unknown", map[string]string{model.BackstageAnnotationRepository: "myorg/unknown"}),
+				entity("other-branch", map[string]string{
+					model.BackstageAnnotationRepository: "myorg/my-service",
+					model.BackstageAnnotationBranch:     "release",
+				}),
+			},
+			MaxScanAge: time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.A(t, postures).Length(5)
+
+		ok := postures[0]
+		gt.V(t, ok.EntityRef).Equal("component:default/my-service")
+		gt.V(t, ok.Status).Equal(model.BackstagePostureOK)
+		gt.V(t, ok.Repository).Equal("myorg/my-service")
+		gt.V(t, ok.Branch).Equal("main")
+		gt.V(t, ok.CommitSHA).Equal("1111111111111111111111111111111111111111")
+		gt.V(t, ok.ScanStatus).Equal(types.ScanStatusSuccess)
+		gt.False(t, ok.Stale)
+		gt.V(t, ok.Findings.Vulnerabilities).Equal(model.SeverityCounts{Critical: 1, Medium: 1, Total: 2})
+		gt.A(t, ok.Links).Length(3)
+
+		gt.V(t, postures[1].Status).Equal(model.BackstagePostureUnmapped)
+		gt.V(t, postures[2].Status).Equal(model.BackstagePostureUnmapped)
+		gt.V(t, postures[3].Status).Equal(model.BackstagePostureNotScanned)
+		gt.V(t, postures[4].Status).Equal(model.BackstagePostureNotScanned)
+		gt.V(t, postures[4].Branch).Equal("release")
+	})
+
+	t.Run("old scan is stale", func(t *testing.T) {
+		postures, err := uc.GetBackstagePosture(ctx, &model.BackstagePostureInput{
+			Entities: []*model.BackstageEntity{
+				entity("my-service", map[string]string{model.BackstageAnnotationProjectSlug: "myorg/my-service"}),
+			},
+			MaxScanAge: time.Nanosecond,
+		})
+		gt.NoError(t, err)
+		gt.True(t, postures[0].Stale)
+	})
+
+	t.Run("too many entities", func(t *testing.T) {
+		entities := make([]*model.BackstageEntity, 101)
+		for i := range entities {
+			entities[i] = entity("e", nil)
+		}
+		_, err := uc.GetBackstagePosture(ctx, &model.BackstagePostureInput{Entities: entities})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const defaultDeveloperSummaryPeriod = 30 * 24 * time.Hour
+
+// GetDeveloperSummary returns repositories which the user committed to within the period, found by committer of stored scans, with active findings of their default branches. Repositories are ordered by the last commit of the user.
+func (x *UseCase) GetDeveloperSummary(ctx context.Context, input *model.DeveloperSummaryInput) (*model.DeveloperSummary, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "developer summary requires Firestore")
+	}
+
+	// GitHub logins are case-insensitive and stored in lower case
+	login := strings.ToLower(strings.TrimSpace(input.Login))
+	if login == "" {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "login is empty")
+	}
+
+	period := input.Period
+	if period <= 0 {
+		period = defaultDeveloperSummaryPeriod
+	}
+
+	summary := &model.DeveloperSummary{
+		Login:        login,
+		Since:        logging.CtxTime(ctx).Add(-period).UTC(),
+		Repositories: []*model.DeveloperRepoSummary{},
+	}
+
+	records, err := scanRepo.ListScanRecordsByCommitter(ctx, login, summary.Since)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list scan records by committer", goerr.V("login", login))
+	}
+
+	// Records are newest first, so the first record of each repository is the last commit
+	repoMap := make(map[types.GitHubRepoID]*model.DeveloperRepoSummary)
+	for _, record := range records {
+		repoSummary, ok := repoMap[record.RepoID]
+		if !ok {
+			owner, name := record.RepoID.Split()
+			repoSummary = &model.DeveloperRepoSummary{
+				Repository:    string(record.RepoID),
+				Owner:         owner,
+				Name:          name,
+				URL:           "https://github.com/" + string(record.RepoID),
+				Permalink:     x.permalinks.Repository(record.RepoID),
+				LastCommitSHA: string(record.CommitSHA),
+				LastCommitAt:  record.Timestamp,
+			}
+			repoMap[record.RepoID] = repoSummary
+			summary.Repositories = append(summary.Repositories, repoSummary)
+		}
+
+		branch := string(record.Branch)
+		if !containsString(repoSummary.Branches, branch) {
+			repoSummary.Branches = append(repoSummary.Branches, branch)
+		}
+	}
+
+	repos := make([]*model.DeveloperRepoSummary, 0, len(summary.Repositories))
+	for _, repoSummary := range summary.Repositories {
+		matched, err := x.fillDefaultBranchFindings(ctx, repoSummary, input)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			repos = append(repos, repoSummary)
+		}
+	}
+	summary.Repositories = repos
+
+	logging.From(ctx).Info("developer summary created",
+		"login", login,
+		"since", summary.Since,
+		"tag", input.Tag,
+		"team", input.Team,
+		"repositories", len(summary.Repositories),
+	)
+
+	return summary, nil
+}
+
+// fillDefaultBranchFindings sets metadata and findings of the default branch to the summary. It returns false if the repository does not match the tag and team filters of the input.
+func (x *UseCase) fillDefaultBranchFindings(ctx context.Context, repoSummary *model.DeveloperRepoSummary, input *model.DeveloperSummaryInput) (bool, error) {
+	scanRepo := x.clients.ScanRepository()
+	repoID := types.GitHubRepoID(repoSummary.Repository)
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return input.Tag == "" && input.Team == "", nil
+	}
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+	if !repo.MatchMetadata(input.Tag, input.Team) {
+		return false, nil
+	}
+	repoSummary.Team = repo.Team
+	repoSummary.Tags = repo.Tags
+	if repo.DefaultBranch == "" {
+		return true, nil
+	}
+	repoSummary.DefaultBranch = string(repo.DefaultBranch)
+
+	branch, err := scanRepo.GetBranch(ctx, repoID, repo.DefaultBranch)
+	if errors.Is(err, repository.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repoID), goerr.V("branch", repo.DefaultBranch))
+	}
+	lastScanAt := branch.LastScanAt
+	repoSummary.LastScanAt = &lastScanAt
+
+	findings, err := branchFindings(ctx, scanRepo, repoID, branch)
+	if err != nil {
+		return false, err
+	}
+	repoSummary.Findings = findings
+
+	return true, nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestGetDeveloperSummary(t *testing.T) {
+	ctx := context.Background()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())))
+
+	insert := func(repo, branch, commit, login string, vulns ...trivy.DetectedVulnerability) {
+		t.Helper()
+		_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: repo},
+				Committer:  model.GitHubUser{Login: login},
+				Branch:     branch,
+				CommitID:   commit,
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  repo,
+			Results:       []trivy.Result{{Target: "go.mod", Vulnerabilities: vulns}},
+		})
+		gt.NoError(t, err)
+	}
+	vuln := func(id, severity string) trivy.DetectedVulnerability {
+		return trivy.DetectedVulnerability{
+			VulnerabilityID:  id,
+			PkgName:          "pkg-" + id,
+			InstalledVersion: "1.0.0",
+			Vulnerability:    trivy.Vulnerability{Severity: severity},
+		}
+	}
+
+	insert("repo-a", "main", "1111111111111111111111111111111111111111", "Alice",
+		vuln("CVE-2024-0001", "CRITICAL"), vuln("CVE-2024-0002", "HIGH"), vuln("CVE-2024-0003", "HIGH"))
+	insert("repo-b", "feature", "2222222222222222222222222222222222222222", "alice")
+	insert("repo-a", "feature", "3333333333333333333333333333333333333333", "alice")
+	insert("repo-c", "main", "4444444444444444444444444444444444444444", "bob", vuln("CVE-2024-0004", "LOW"))
+
+	t.Run("repositories committed by user", func(t *testing.T) {
+		summary, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "ALICE"})
+		gt.NoError(t, err)
+		gt.V(t, summary.Login).Equal("alice")
+		gt.A(t, summary.Repositories).Length(2)
+
+		// Ordered by last commit of the user
+		repoA := summary.Repositories[0]
+		gt.V(t, repoA.Repository).Equal("test-owner/repo-a")
+		gt.V(t, repoA.LastCommitSHA).Equal("3333333333333333333333333333333333333333")
+		gt.A(t, repoA.Branches).Length(2)
+		gt.V(t, repoA.DefaultBranch).Equal("main")
+		gt.NotNil(t, repoA.LastScanAt)
+		gt.V(t, repoA.Findings.Vulnerabilities).Equal(model.SeverityCounts{Critical: 1, High: 2, Total: 3})
+
+		// Default branch of repo-b has not been scanned
+		repoB := summary.Repositories[1]
+		gt.V(t, repoB.Repository).Equal("test-owner/repo-b")
+		gt.Nil(t, repoB.Findings)
+	})
+
+	t.Run("user without commits", func(t *testing.T) {
+		summary, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "carol"})
+		gt.NoError(t, err)
+		gt.A(t, summary.Repositories).Length(0)
+	})
+
+	t.Run("commits before the period are excluded", func(t *testing.T) {
+		summary, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "alice", Period: time.Nanosecond})
+		gt.NoError(t, err)
+		gt.A(t, summary.Repositories).Length(0)
+	})
+
+	t.Run("filtered by team and tag", func(t *testing.T) {
+		team, tags := "Backend", []string{"payments"}
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner", Repo: "repo-a", Team: &team, Tags: &tags,
+		})
+		gt.NoError(t, err)
+
+		summary, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "alice", Team: "backend"})
+		gt.NoError(t, err)
+		gt.A(t, summary.Repositories).Length(1)
+		gt.V(t, summary.Repositories[0].Repository).Equal("test-owner/repo-a")
+		gt.V(t, summary.Repositories[0].Team).Equal("backend")
+		gt.V(t, summary.Repositories[0].Tags).Equal([]string{"payments"})
+
+		summary, err = uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "alice", Tag: "frontend"})
+		gt.NoError(t, err)
+		gt.A(t, summary.Repositories).Length(0)
+	})
+
+	t.Run("empty login is invalid", func(t *testing.T) {
+		_, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.GetDeveloperSummary(ctx, &model.DeveloperSummaryInput{Login: "alice"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+// GetPermalinkView returns the detail view of a repository, a branch, a target or a finding which a permalink points to. A finding is looked up in every target of the branch in any status, so that a link in a past notification keeps resolving after the finding is fixed.
+func (x *UseCase) GetPermalinkView(ctx context.Context, input *model.GetPermalinkViewInput) (*model.PermalinkView, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "permalink requires Firestore")
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+
+	view := &model.PermalinkView{
+		Permalink:     x.permalinks.Repository(repoID),
+		Repository:    string(repoID),
+		GitHubURL:     "https://github.com/" + string(repoID),
+		DefaultBranch: string(repo.DefaultBranch),
+		Team:          repo.Team,
+		Tags:          repo.Tags,
+	}
+
+	if input.Branch == "" {
+		status, err := x.ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: input.Owner, Repo: input.Repo})
+		if err != nil {
+			return nil, err
+		}
+		view.Branches = status.Branches
+		return view, nil
+	}
+
+	branch, err := scanRepo.GetBranch(ctx, repoID, input.Branch)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repoID), goerr.V("branch", input.Branch))
+	}
+	if view.Branch, err = x.newBranchStatus(ctx, scanRepo, repo, branch); err != nil {
+		return nil, err
+	}
+
+	var targets []*model.Target
+	match := func(vuln *model.Vulnerability) bool { return vuln.Status == types.VulnStatusActive }
+
+	switch {
+	case input.TargetID != "":
+		target, err := scanRepo.GetTarget(ctx, repoID, branch.Name, input.TargetID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get target", goerr.V("repo_id", repoID), goerr.V("target_id", input.TargetID))
+		}
+		targets = []*model.Target{target}
+		view.Permalink = x.permalinks.Target(repoID, branch.Name, target.ID)
+		view.Target = target.Target
+
+	case input.VulnID != "":
+		if targets, err = scanRepo.ListTargets(ctx, repoID, branch.Name); err != nil {
+			return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name))
+		}
+		view.Permalink = x.permalinks.Finding(repoID, branch.Name, input.VulnID)
+		match = func(vuln *model.Vulnerability) bool { return vuln.ID == input.VulnID }
+
+	default:
+		if targets, err = scanRepo.ListTargets(ctx, repoID, branch.Name); err != nil {
+			return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name))
+		}
+		view.Permalink = x.permalinks.Branch(repoID, branch.Name)
+	}
+
+	if view.Findings, err = x.listTargetFindings(ctx, scanRepo, repo, branch.Name, targets, match); err != nil {
+		return nil, err
+	}
+	if input.VulnID != "" && len(view.Findings) == 0 {
+		return nil, goerr.Wrap(repository.ErrNotFound, "finding not found", goerr.V("repo_id", repoID), goerr.V("branch", branch.Name), goerr.V("vuln_id", input.VulnID))
+	}
+
+	return view, nil
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestGetPermalinkView(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := memory.New()
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:            repoID,
+		Owner:         "test-owner",
+		Name:          "test-repo",
+		DefaultBranch: "main",
+		Team:          "platform",
+	}))
+	for _, branch := range []*model.Branch{
+		{Name: "main", LastScanID: "scan-main", LastScanAt: now, LastCommitSHA: "cccc", Status: types.ScanStatusSuccess},
+		{Name: "feature/x", LastScanID: "scan-x", LastScanAt: now, LastCommitSHA: "dddd", Status: types.ScanStatusSuccess},
+	} {
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, branch))
+	}
+	goMod := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+	npm := &model.Target{ID: model.ToTargetID("web/package-lock.json", "", ""), Target: "web/package-lock.json"}
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", goMod))
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", npm))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", goMod.ID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", Severity: "CRITICAL", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0002", Severity: "HIGH", Status: types.VulnStatusFixed},
+	}))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", npm.ID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", Severity: "CRITICAL", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0003", Severity: "LOW", Status: types.VulnStatusActive},
+	}))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)), usecase.WithPermalinkBaseURL("https://octovy.example.com"))
+	const base = "https://octovy.example.com/r/test-owner/test-repo"
+
+	t.Run("repository", func(t *testing.T) {
+		view := gt.R1(uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo"})).NoError(t)
+		gt.V(t, view.Permalink).Equal(base)
+		gt.V(t, view.GitHubURL).Equal("https://github.com/test-owner/test-repo")
+		gt.V(t, view.Team).Equal("platform")
+		gt.A(t, view.Branches).Length(2)
+		gt.V(t, view.Branches[0].Permalink).Equal(base + "/b/main")
+		gt.V(t, view.Branches[1].Permalink).Equal(base + "/b/feature%2Fx")
+		gt.A(t, view.Findings).Length(0)
+	})
+
+	t.Run("branch has active findings", func(t *testing.T) {
+		view := gt.R1(uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main"})).NoError(t)
+		gt.V(t, view.Permalink).Equal(base + "/b/main")
+		gt.V(t, view.Branch.CommitSHA).Equal("cccc")
+		gt.A(t, view.Findings).Length(3)
+		gt.V(t, view.Findings[0].ID).Equal("CVE-2024-0001")
+		gt.V(t, view.Findings[0].Permalink).Equal(base + "/b/main/v/CVE-2024-0001")
+		gt.V(t, view.Findings[2].ID).Equal("CVE-2024-0003")
+	})
+
+	t.Run("target", func(t *testing.T) {
+		view := gt.R1(uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main", TargetID: npm.ID})).NoError(t)
+		gt.V(t, view.Permalink).Equal(base + "/b/main/t/" + string(npm.ID))
+		gt.V(t, view.Target).Equal("web/package-lock.json")
+		gt.A(t, view.Findings).Length(2)
+	})
+
+	t.Run("finding in every target and any status", func(t *testing.T) {
+		view := gt.R1(uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main", VulnID: "CVE-2024-0001"})).NoError(t)
+		gt.V(t, view.Permalink).Equal(base + "/b/main/v/CVE-2024-0001")
+		gt.A(t, view.Findings).Length(2)
+
+		fixed := gt.R1(uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main", VulnID: "CVE-2024-0002"})).NoError(t)
+		gt.A(t, fixed.Findings).Length(1)
+		gt.V(t, fixed.Findings[0].Status).Equal(types.VulnStatusFixed)
+	})
+
+	t.Run("unknown finding and branch are not found", func(t *testing.T) {
+		_, err := uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main", VulnID: "CVE-0000-0000"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		_, err = uc.GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "unknown"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("permalinks are omitted without base URL", func(t *testing.T) {
+		view := gt.R1(usecase.New(infra.New(infra.WithScanRepository(repo))).GetPermalinkView(ctx, &model.GetPermalinkViewInput{Owner: "test-owner", Repo: "test-repo", Branch: "main"})).NoError(t)
+		gt.V(t, view.Permalink).Equal("")
+		gt.V(t, view.Findings[0].Permalink).Equal("")
+	})
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+// GetVulnerabilityDetail returns the finding of the target in any status with its advisory data and status history. It returns repository.ErrNotFound if the repository, the branch, the target or the finding does not exist.
+func (x *UseCase) GetVulnerabilityDetail(ctx context.Context, input *model.GetVulnerabilityDetailInput) (*model.VulnerabilityDetail, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+	if input.Branch == "" || input.TargetID == "" || input.VulnID == "" {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "branch, target and vulnerability ID are required")
+	}
+
+	target, err := scanRepo.GetTarget(ctx, repoID, input.Branch, input.TargetID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get target",
+			goerr.V("repo_id", repoID),
+			goerr.V("branch", input.Branch),
+			goerr.V("target_id", input.TargetID),
+		)
+	}
+
+	vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, input.Branch, target.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list vulnerabilities",
+			goerr.V("repo_id", repoID),
+			goerr.V("branch", input.Branch),
+			goerr.V("target_id", target.ID),
+		)
+	}
+
+	var vuln *model.Vulnerability
+	for _, v := range vulns {
+		if v.ID == input.VulnID {
+			vuln = v
+			break
+		}
+	}
+	if vuln == nil {
+		return nil, goerr.Wrap(repository.ErrNotFound, "vulnerability not found",
+			goerr.V("repo_id", repoID),
+			goerr.V("branch", input.Branch),
+			goerr.V("target_id", target.ID),
+			goerr.V("vuln_id", input.VulnID),
+		)
+	}
+
+	history, err := scanRepo.ListVulnerabilityHistory(ctx, repoID, input.Branch, target.ID, vuln.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list vulnerability history",
+			goerr.V("repo_id", repoID),
+			goerr.V("branch", input.Branch),
+			goerr.V("target_id", target.ID),
+			goerr.V("vuln_id", vuln.ID),
+		)
+	}
+
+	detail := model.NewVulnerabilityDetail(repoID, input.Branch, target, vuln, history)
+	detail.Permalink = x.permalinks.Finding(repoID, input.Branch, vuln.ID)
+	return detail, nil
+}
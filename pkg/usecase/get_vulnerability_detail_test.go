@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestGetVulnerabilityDetail(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := memory.New()
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "test-owner", Name: "test-repo", DefaultBranch: "main"}))
+	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "feature/x", LastScanAt: now}))
+	goMod := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "feature/x", goMod))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "feature/x", goMod.ID, []*model.Vulnerability{
+		{
+			ID:               "CVE-2024-0001",
+			PkgName:          "golang.org/x/net",
+			InstalledVersion: "v0.1.0",
+			FixedVersion:     "v0.2.0",
+			Severity:         "HIGH",
+			Status:           types.VulnStatusActive,
+			References:       []string{"https://example.com/advisory"},
+			CweIDs:           []string{"CWE-400"},
+			CVSS: map[string]model.CVSS{
+				"nvd":  {V3Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", V3Score: 7.5},
+				"ghsa": {V3Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H", V3Score: 5.9},
+			},
+			CreatedAt: now,
+		},
+	}))
+	gt.NoError(t, repo.BatchUpdateVulnerabilityStatus(ctx, repoID, "feature/x", goMod.ID, []*model.VulnStatusChange{
+		{
+			VulnID:          "CVE-2024-0001",
+			From:            types.VulnStatusActive,
+			To:              types.VulnStatusAcknowledged,
+			Actor:           "alice",
+			Comment:         "not reachable",
+			Acknowledgement: &model.Acknowledgement{By: "alice", Comment: "not reachable", At: now.Add(time.Hour)},
+			Timestamp:       now.Add(time.Hour),
+		},
+	}))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)), usecase.WithPermalinkBaseURL("https://octovy.example.com"))
+
+	t.Run("returns finding with CVSS and history", func(t *testing.T) {
+		detail := gt.R1(uc.GetVulnerabilityDetail(ctx, &model.GetVulnerabilityDetailInput{
+			Owner:    "test-owner",
+			Repo:     "test-repo",
+			Branch:   "feature/x",
+			TargetID: goMod.ID,
+			VulnID:   "CVE-2024-0001",
+		})).NoError(t)
+
+		gt.V(t, detail.Repository).Equal("test-owner/test-repo")
+		gt.V(t, detail.Target).Equal("go.mod")
+		gt.V(t, detail.Type).Equal(types.FindingTypeVulnerability)
+		gt.V(t, detail.Status).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, detail.CVSS["nvd"].V3Score).Equal(7.5)
+		gt.V(t, detail.CVSS["ghsa"].V3Vector).Equal("CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H")
+		gt.A(t, detail.CweIDs).Equal([]string{"CWE-400"})
+		gt.A(t, detail.References).Equal([]string{"https://example.com/advisory"})
+		gt.V(t, detail.Acknowledgement.By).Equal("alice")
+		gt.Nil(t, detail.Acknowledgement.ExpiresAt)
+		gt.A(t, detail.History).Length(1)
+		gt.V(t, detail.History[0].To).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, detail.History[0].Comment).Equal("not reachable")
+		gt.V(t, detail.FirstDetectedAt).Equal(now)
+		gt.V(t, detail.Permalink).Equal("https://octovy.example.com/r/test-owner/test-repo/b/feature%2Fx/v/CVE-2024-0001")
+	})
+
+	t.Run("unknown finding is not found", func(t *testing.T) {
+		_, err := uc.GetVulnerabilityDetail(ctx, &model.GetVulnerabilityDetailInput{
+			Owner:    "test-owner",
+			Repo:     "test-repo",
+			Branch:   "feature/x",
+			TargetID: goMod.ID,
+			VulnID:   "CVE-0000-0000",
+		})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("unknown target is not found", func(t *testing.T) {
+		_, err := uc.GetVulnerabilityDetail(ctx, &model.GetVulnerabilityDetailInput{
+			Owner:    "test-owner",
+			Repo:     "test-repo",
+			Branch:   "feature/x",
+			TargetID: "unknown",
+			VulnID:   "CVE-2024-0001",
+		})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("invalid repository", func(t *testing.T) {
+		_, err := uc.GetVulnerabilityDetail(ctx, &model.GetVulnerabilityDetailInput{
+			Repo:     "test-repo",
+			Branch:   "feature/x",
+			TargetID: goMod.ID,
+			VulnID:   "CVE-2024-0001",
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const defaultGitHubRateLimitReserve = 100
+
+// waitForGitHubRateLimit blocks until the rate limit window resets if the remaining quota of the installation is below the reserve. It returns immediately if GitHub App is not configured, the rate limit has not been observed yet or the window has already reset.
+func (x *UseCase) waitForGitHubRateLimit(ctx context.Context, installID types.GitHubAppInstallID) error {
+	if x.clients.GitHubApp() == nil {
+		return nil
+	}
+	rl := x.clients.GitHubApp().RateLimit(installID)
+	if rl == nil || rl.Remaining >= x.githubRateLimitReserve {
+		return nil
+	}
+
+	wait := rl.Reset.Sub(logging.CtxTime(ctx))
+	if wait <= 0 {
+		return nil
+	}
+
+	logging.From(ctx).Warn("GitHub API quota is below reserve, waiting until rate limit reset",
+		slog.Any("rate_limit", rl),
+		slog.Int("reserve", x.githubRateLimitReserve),
+		slog.Duration("wait", wait),
+	)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return goerr.Wrap(ctx.Err(), "cancelled while waiting for GitHub API rate limit reset", goerr.V("install_id", installID))
+	}
+}
+
+// GetGitHubRateLimits returns the latest observed rate limits of all installations. It returns an empty list if GitHub App is not configured.
+func (x *UseCase) GetGitHubRateLimits(ctx context.Context) []*model.GitHubRateLimit {
+	if x.clients.GitHubApp() == nil {
+		return []*model.GitHubRateLimit{}
+	}
+	return x.clients.GitHubApp().RateLimits()
+}
@@ -0,0 +1,109 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestScanGitHubReposByOwnerFromAPI_RateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func(rl *model.GitHubRateLimit) (*usecase.UseCase, *mock.GitHubAppMock) {
+		mockGH := &mock.GitHubAppMock{
+			RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit {
+				return rl
+			},
+			ListInstallationReposFunc: func(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
+				return []*model.GitHubAPIRepository{
+					{Owner: "test-owner", Name: "repo-1", DefaultBranch: "main"},
+				}, nil
+			},
+			HTTPClientFunc: func(installID types.GitHubAppInstallID) (*http.Client, error) {
+				return nil, io.EOF
+			},
+		}
+		uc := usecase.New(infra.New(infra.WithGitHubApp(mockGH)), usecase.WithGitHubRateLimitReserve(100))
+		return uc, mockGH
+	}
+	input := &model.ScanGitHubReposByOwnerFromAPIInput{
+		Owner:     "test-owner",
+		InstallID: types.GitHubAppInstallID(12345),
+	}
+
+	t.Run("waits until reset when quota is below reserve", func(t *testing.T) {
+		uc, mockGH := setup(&model.GitHubRateLimit{
+			InstallID: 12345,
+			Limit:     5000,
+			Remaining: 50,
+			Reset:     now.Add(time.Hour),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		ctx = logging.CtxWithTime(ctx, func() time.Time { return now })
+
+		run, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+		gt.Error(t, err)
+		gt.V(t, run.Status).Equal(types.ScanRunFailed)
+		gt.A(t, run.Repositories).Length(0)
+		gt.S(t, err.Error()).Contains("waiting for GitHub API rate limit reset")
+		gt.A(t, mockGH.HTTPClientCalls()).Length(0)
+	})
+
+	t.Run("proceeds when rate limit window has already reset", func(t *testing.T) {
+		uc, mockGH := setup(&model.GitHubRateLimit{
+			InstallID: 12345,
+			Limit:     5000,
+			Remaining: 0,
+			Reset:     now.Add(-time.Minute),
+		})
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+		// Scan itself fails by the mock, but it is attempted without waiting
+		gt.R1(uc.ScanGitHubReposByOwnerFromAPI(ctx, input)).Error(t)
+		gt.A(t, mockGH.HTTPClientCalls()).Length(1)
+	})
+
+	t.Run("proceeds when quota is above reserve", func(t *testing.T) {
+		uc, mockGH := setup(&model.GitHubRateLimit{
+			InstallID: 12345,
+			Limit:     5000,
+			Remaining: 101,
+			Reset:     now.Add(time.Hour),
+		})
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+		gt.R1(uc.ScanGitHubReposByOwnerFromAPI(ctx, input)).Error(t)
+		gt.A(t, mockGH.HTTPClientCalls()).Length(1)
+	})
+}
+
+func TestGetGitHubRateLimits(t *testing.T) {
+	t.Run("empty without GitHub App", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		gt.A(t, uc.GetGitHubRateLimits(context.Background())).Length(0)
+	})
+
+	t.Run("returns observed rate limits", func(t *testing.T) {
+		mockGH := &mock.GitHubAppMock{
+			RateLimitsFunc: func() []*model.GitHubRateLimit {
+				return []*model.GitHubRateLimit{{InstallID: 1, Limit: 5000, Remaining: 10}}
+			},
+		}
+		uc := usecase.New(infra.New(infra.WithGitHubApp(mockGH)))
+		limits := uc.GetGitHubRateLimits(context.Background())
+		gt.A(t, limits).Length(1)
+		gt.V(t, limits[0].Remaining).Equal(10)
+	})
+}
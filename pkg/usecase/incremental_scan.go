@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// maxPullRequestFiles is the maximum number of files of a pull request listed by GitHub API. The list of a larger pull request is truncated, so the whole repository is scanned.
+const maxPullRequestFiles = 3000
+
+// incrementalScanOf returns the incremental scan of the pull request of the input if WithIncrementalPullRequestScan is set, or nil to scan the whole repository. A failure to list changed files is logged and the whole repository is scanned, because the incremental scan is only an optimization.
+func (x *UseCase) incrementalScanOf(ctx context.Context, input *model.ScanGitHubRepoInput) *model.IncrementalScan {
+	gh := x.clients.GitHubApp()
+	if !x.incrementalPRScan || input.PullRequest == nil || input.Source.SourceKind() != types.SourceGitHub || gh == nil {
+		return nil
+	}
+
+	logger := logging.From(ctx).With(
+		slog.String("owner", input.Owner),
+		slog.String("repo", input.RepoName),
+		slog.Int("pull_request", input.PullRequest.Number),
+	)
+
+	files, err := gh.ListPullRequestFiles(ctx, &interfaces.ListPullRequestFilesInput{
+		Owner:     input.Owner,
+		Repo:      input.RepoName,
+		Number:    input.PullRequest.Number,
+		InstallID: input.InstallID,
+	})
+	if err != nil {
+		logger.Warn("failed to list changed files of pull request, scan whole repository", slog.Any("error", err))
+		return nil
+	}
+	if len(files) >= maxPullRequestFiles {
+		logger.Info("too many changed files of pull request, scan whole repository", slog.Int("changed_files", len(files)))
+		return nil
+	}
+
+	incremental := model.NewIncrementalScan(files, x.scansPackagesOnly())
+	if incremental == nil {
+		logger.Info("changes of pull request need scan of whole repository", slog.Int("changed_files", len(files)))
+		return nil
+	}
+	logger.Info("scan only directories of changed files of pull request",
+		slog.Any("paths", incremental.Paths),
+		slog.Int("changed_files", len(files)),
+	)
+	return incremental
+}
+
+// scansPackagesOnly returns true if the scanner reports only vulnerabilities and licenses of packages, so that only changes of dependency files can change the result
+func (x *UseCase) scansPackagesOnly() bool {
+	// Scanners replacing Trivy, e.g. Grype, detect vulnerabilities of packages only
+	if x.scanner != nil {
+		return true
+	}
+	// Default scanners of Trivy include the secret scanner
+	if len(x.trivyScanners) == 0 {
+		return false
+	}
+	for _, s := range x.trivyScanners {
+		if s != types.TrivyScannerVuln && s != types.TrivyScannerLicense {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestScanGitHubRepoIncremental(t *testing.T) {
+	const (
+		headReport = `{"SchemaVersion":2,"ArtifactName":"test","Results":[{"Target":"services/api/go.mod","Class":"lang-pkgs","Type":"gomod"}]}`
+	)
+	baseReport := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test",
+		Results: []trivy.Result{
+			{
+				Target: "services/api/go.mod",
+				Class:  "lang-pkgs",
+				Type:   "gomod",
+			},
+			{
+				Target: "services/web/package-lock.json",
+				Class:  "lang-pkgs",
+				Type:   "npm",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0002", PkgName: "pkg-b", InstalledVersion: "1.0.0", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+				},
+			},
+		},
+	}
+	zipData := buildZipArchive(t, map[string]string{
+		"octovy-test/services/api/go.mod":              "module example.com/api",
+		"octovy-test/services/web/package-lock.json":   "{}",
+		"octovy-test/services/worker/requirements.txt": "requests==2.0.0",
+	})
+
+	type result struct {
+		trivyArgs []string
+		inserted  *model.ScanRawRecord
+		labels    [][]string
+		repo      interfaces.ScanRepository
+	}
+	run := func(t *testing.T, changed []string, listErr error, options ...usecase.Option) *result {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, zipData)
+		res := &result{repo: memory.New()}
+
+		fx.mockTrivy.mockRun = func(ctx context.Context, args []string) error {
+			res.trivyArgs = args
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(headReport), 0644)
+				}
+			}
+			return nil
+		}
+		fx.mockBQ.InsertFunc = func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+			res.inserted = data.(*model.ScanRawRecord)
+			return nil
+		}
+		fx.mockGH.ListPullRequestFilesFunc = func(ctx context.Context, input *interfaces.ListPullRequestFilesInput) ([]string, error) {
+			gt.V(t, input.Number).Equal(42)
+			gt.V(t, input.InstallID).Equal(types.GitHubAppInstallID(12345))
+			return changed, listErr
+		}
+		fx.mockGH.AddLabelsToPullRequestFunc = func(ctx context.Context, input *interfaces.AddLabelsToPullRequestInput) error {
+			res.labels = append(res.labels, input.Labels)
+			return nil
+		}
+
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(res.repo),
+		), append([]usecase.Option{usecase.WithDependencyUpdateLabel("security-fix")}, options...)...)
+
+		baseMeta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: defaultTestOwner, RepoName: defaultTestRepo},
+				Branch:     "main",
+				CommitID:   "1111111111111111111111111111111111111111",
+			},
+		}
+		gt.R1(uc.InsertScanResult(ctx, baseMeta, baseReport)).NoError(t)
+
+		gt.NoError(t, uc.ScanGitHubRepo(ctx, &model.ScanGitHubRepoInput{
+			GitHubMetadata: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{Owner: defaultTestOwner, RepoName: defaultTestRepo},
+					Branch:     "dependabot/go_modules/pkg-a",
+					CommitID:   defaultTestCommitID,
+				},
+				InstallationID: 12345,
+				PullRequest: &model.GitHubPullRequest{
+					Number:     42,
+					BaseBranch: "main",
+					Author:     model.GitHubUser{Login: "dependabot[bot]"},
+				},
+			},
+			InstallID: 12345,
+		}))
+		return res
+	}
+
+	t.Run("scan only directories of changed dependency files", func(t *testing.T) {
+		res := run(t, []string{"services/api/go.mod", "services/api/go.sum", "services/worker/main.py"}, nil,
+			usecase.WithIncrementalPullRequestScan(), usecase.WithTrivyScanners(types.TrivyScannerVuln))
+
+		gt.A(t, res.trivyArgs).Contains([]string{"--skip-dirs", "services/web"})
+		gt.A(t, res.trivyArgs).Contains([]string{"--skip-dirs", "services/worker"})
+		gt.V(t, res.inserted.Incremental).NotNil()
+		gt.V(t, res.inserted.Incremental.Paths).Equal([]string{"services/api"})
+		gt.V(t, res.inserted.Incremental.ChangedFiles).Equal(3)
+
+		// The vulnerability of services/web is not regarded as fixed because it is not scanned
+		gt.A(t, res.labels).Length(0)
+
+		// The partial result does not update the branch
+		_, err := res.repo.GetBranch(context.Background(), types.NewGitHubRepoID(defaultTestOwner, defaultTestRepo), "dependabot/go_modules/pkg-a")
+		gt.Error(t, err)
+	})
+
+	t.Run("changes of other files are relevant with secret scanner", func(t *testing.T) {
+		res := run(t, []string{"services/api/go.mod", "services/worker/main.py"}, nil, usecase.WithIncrementalPullRequestScan())
+
+		gt.V(t, res.inserted.Incremental.Paths).Equal([]string{"services/api", "services/worker"})
+		gt.A(t, res.trivyArgs).Contains([]string{"--skip-dirs", "services/web"})
+	})
+
+	t.Run("file in repository root needs full scan", func(t *testing.T) {
+		res := run(t, []string{"services/api/go.mod", ".octovy.yml"}, nil,
+			usecase.WithIncrementalPullRequestScan(), usecase.WithTrivyScanners(types.TrivyScannerVuln))
+
+		gt.V(t, res.inserted.Incremental).Nil()
+		gt.A(t, res.trivyArgs).NotHas("--skip-dirs")
+	})
+
+	t.Run("full scan if changed files can not be listed", func(t *testing.T) {
+		res := run(t, nil, errors.New("not found"), usecase.WithIncrementalPullRequestScan())
+
+		gt.V(t, res.inserted.Incremental).Nil()
+		gt.A(t, res.trivyArgs).NotHas("--skip-dirs")
+		// The vulnerability of services/web is regarded as fixed by the full scan
+		gt.A(t, res.labels).Length(1)
+	})
+
+	t.Run("full scan unless enabled", func(t *testing.T) {
+		res := run(t, []string{"services/api/go.mod"}, nil)
+
+		gt.V(t, res.inserted.Incremental).Nil()
+		gt.A(t, res.trivyArgs).NotHas("--skip-dirs")
+	})
+}
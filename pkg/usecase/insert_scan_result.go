@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
@@ -11,49 +13,181 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (x *UseCase) InsertScanResult(ctx context.Context, meta model.GitHubMetadata, report trivy.Report) (types.ScanID, error) {
+	return x.insertScanResult(ctx, meta, report, nil, nil, nil, time.Time{})
+}
+
+// insertScanResult stores the report with the coverage, the incremental scan and the archive of the scan. They are nil for a report scanned outside octovy. startedAt is when the scan started, which orders scans of a branch in Firestore. The time of insertion is used if it is zero.
+func (x *UseCase) insertScanResult(ctx context.Context, meta model.GitHubMetadata, report trivy.Report, coverage *model.ScanCoverage, incremental *model.IncrementalScan, archive *model.ScanArchive, startedAt time.Time) (types.ScanID, error) {
 	if err := report.Validate(); err != nil {
 		return "", goerr.Wrap(err, "invalid trivy report")
 	}
+	meta.Normalize()
+	report = x.applyInternalAdvisories(ctx, report)
 
 	scan := &model.Scan{
-		ID:        types.NewScanID(),
-		Timestamp: time.Now().UTC(),
-		GitHub:    meta,
-		Report:    report,
+		ID:          types.NewScanID(),
+		Timestamp:   logging.CtxTime(ctx).UTC(),
+		Owner:       meta.Owner,
+		RepoName:    meta.RepoName,
+		GitHub:      meta,
+		Report:      report,
+		Coverage:    coverage,
+		Incremental: incremental,
+		Archive:     archive,
+	}
+	if startedAt.IsZero() {
+		startedAt = scan.Timestamp
+	}
+
+	if analysis := model.NewImageAnalysis(&report); analysis != nil {
+		scan.ImageAnalysis = analysis
+		logging.From(ctx).Info("analyzed container image layers",
+			"artifact", report.ArtifactName,
+			"base_image", analysis.BaseImage,
+			"base_vulns", analysis.BaseVulnCount,
+			"application_vulns", analysis.ApplicationVulnCount,
+			"recommendations", analysis.Recommendations,
+		)
 	}
 
 	// Insert to BigQuery
 	if x.clients.BigQuery() != nil {
-		schema, schemaUpdated, err := createOrUpdateBigQueryTable(ctx, x.clients.BigQuery(), scan)
+		normalized := x.bqVulnTable != "" && x.bqPackageTable != ""
+		bqCtx, span := tracing.Start(ctx, "bigquery.insert", trace.WithAttributes(
+			attribute.Bool("octovy.bigquery_normalized", normalized),
+		))
+		var err error
+		if normalized {
+			err = x.insertNormalizedToBigQuery(bqCtx, scan)
+		} else {
+			err = x.insertRawToBigQuery(bqCtx, x.bigQueryTable(x.bqTable, scan), scan)
+		}
+		tracing.End(span, err)
 		if err != nil {
-			return "", err
+			return "", goerr.Wrap(err, "failed to store scan to BigQuery", goerr.T(types.TagBigQuery), goerr.V("scan_id", scan.ID))
 		}
+	}
 
-		rawRecord := &model.ScanRawRecord{
-			Scan:      *scan,
-			Timestamp: scan.Timestamp.UnixMicro(),
+	// Insert to Firestore. Statuses of findings are tracked per branch, so a scan of a commit or a tag without branch is stored only in BigQuery, as well as an incremental scan which does not report targets out of its paths.
+	switch {
+	case x.clients.ScanRepository() == nil:
+	case meta.Branch == "":
+		logging.From(ctx).Info("scan without branch is not stored in Firestore",
+			"owner", meta.Owner,
+			"repo", meta.RepoName,
+			"commit", meta.CommitID,
+			"tag", meta.Tag,
+		)
+	case incremental != nil:
+		logging.From(ctx).Info("incremental scan is not stored in Firestore",
+			"owner", meta.Owner,
+			"repo", meta.RepoName,
+			"branch", meta.Branch,
+			"paths", incremental.Paths,
+		)
+	default:
+		fsCtx, span := tracing.Start(ctx, "firestore.store", trace.WithAttributes(
+			attribute.Int("octovy.targets", len(report.Results)),
+		))
+		regression, err := x.insertToFirestore(fsCtx, meta, scan, report, startedAt.UTC())
+		tracing.End(span, err)
+		if err != nil {
+			return "", goerr.Wrap(err, "failed to insert scan data to Firestore")
 		}
-
-		if err := x.clients.BigQuery().Insert(ctx, schema, rawRecord, interfaces.WithRetry(schemaUpdated)); err != nil {
-			return "", goerr.Wrap(err, "failed to insert scan data to BigQuery")
+		if regression != nil {
+			x.notifyRegression(ctx, meta, regression)
 		}
 	}
 
-	// Insert to Firestore
-	if x.clients.ScanRepository() != nil {
-		if err := x.insertToFirestore(ctx, meta, scan, report); err != nil {
-			return "", goerr.Wrap(err, "failed to insert scan data to Firestore")
+	return scan.ID, nil
+}
+
+func (x *UseCase) insertRawToBigQuery(ctx context.Context, bq interfaces.BigQuery, scan *model.Scan) error {
+	schema, schemaUpdated, err := x.createOrUpdateBigQueryTable(ctx, bq, scan)
+	if err != nil {
+		return err
+	}
+
+	rawRecord := &model.ScanRawRecord{
+		Scan:      *scan,
+		Timestamp: scan.Timestamp.UnixMicro(),
+	}
+
+	if err := bq.Insert(ctx, schema, rawRecord, interfaces.WithRetry(schemaUpdated)); err != nil {
+		return goerr.Wrap(err, "failed to insert scan data to BigQuery")
+	}
+	return nil
+}
+
+// insertNormalizedToBigQuery fans out the scan into summary, vulnerability and package rows. Rows of each table are sent through one stream to avoid the row size limit of a huge report.
+func (x *UseCase) insertNormalizedToBigQuery(ctx context.Context, scan *model.Scan) error {
+	normalized := model.NewNormalizedScan(scan)
+
+	vulnRows := make([]any, len(normalized.Vulnerabilities))
+	for i := range normalized.Vulnerabilities {
+		vulnRows[i] = normalized.Vulnerabilities[i]
+	}
+	pkgRows := make([]any, len(normalized.Packages))
+	for i := range normalized.Packages {
+		pkgRows[i] = normalized.Packages[i]
+	}
+
+	tables := []struct {
+		client interfaces.BigQuery
+		sample any
+		rows   []any
+	}{
+		{x.bigQueryTable(x.bqTable, scan), normalized.Summary, []any{normalized.Summary}},
+		{x.bigQueryTable(x.bqVulnTable, scan), &model.VulnerabilityRow{}, vulnRows},
+		{x.bigQueryTable(x.bqPackageTable, scan), &model.PackageRow{}, pkgRows},
+	}
+
+	for _, tbl := range tables {
+		if len(tbl.rows) == 0 {
+			continue
+		}
+
+		schema, schemaUpdated, err := x.createOrUpdateBigQueryTable(ctx, tbl.client, tbl.sample)
+		if err != nil {
+			return err
+		}
+		if err := tbl.client.InsertRows(ctx, schema, tbl.rows, interfaces.WithRetry(schemaUpdated)); err != nil {
+			return goerr.Wrap(err, "failed to insert normalized scan data to BigQuery", goerr.V("rows", len(tbl.rows)))
 		}
 	}
 
-	return scan.ID, nil
+	logging.From(ctx).Info("inserted normalized scan data to BigQuery",
+		"scan_id", scan.ID,
+		"vulnerabilities", len(vulnRows),
+		"packages", len(pkgRows),
+	)
+
+	return nil
+}
+
+// bigQueryTable returns the client of the table to store the scan in, routed from table. An empty table is the table of the BigQuery client.
+func (x *UseCase) bigQueryTable(table types.BQTableID, scan *model.Scan) interfaces.BigQuery {
+	if table == "" {
+		return x.clients.BigQuery()
+	}
+	routed := x.bqRouting.Route(table, scan.Timestamp, scan.GitHub.Owner)
+	if routed == table && table == x.bqTable {
+		return x.clients.BigQuery()
+	}
+	return x.clients.BigQuery().Table(routed)
 }
 
-func createOrUpdateBigQueryTable(ctx context.Context, bq interfaces.BigQuery, scan *model.Scan) (schema bigquery.Schema, schemaUpdated bool, err error) {
-	schema, err = bqs.Infer(scan)
+// createOrUpdateBigQueryTable creates the table for the data with the configured layout, or adds missing columns and clustering to the existing table. schemaUpdated is true if the existing table is updated.
+func (x *UseCase) createOrUpdateBigQueryTable(ctx context.Context, bq interfaces.BigQuery, data any) (schema bigquery.Schema, schemaUpdated bool, err error) {
+	schema, err = bqs.Infer(data)
 	if err != nil {
 		return nil, false, goerr.Wrap(err, "failed to infer scan schema")
 	}
@@ -64,7 +198,9 @@ func createOrUpdateBigQueryTable(ctx context.Context, bq interfaces.BigQuery, sc
 	}
 	if metaData == nil {
 		if err := bq.CreateTable(ctx, &bigquery.TableMetadata{
-			Schema: schema,
+			Schema:           schema,
+			TimePartitioning: x.bqLayout.timePartitioning(schema),
+			Clustering:       x.bqLayout.clusteringOf(schema),
 		}); err != nil {
 			return nil, false, goerr.Wrap(err, "failed to create BigQuery table")
 		}
@@ -72,137 +208,560 @@ func createOrUpdateBigQueryTable(ctx context.Context, bq interfaces.BigQuery, sc
 		return schema, false, nil
 	}
 
-	if bqs.Equal(metaData.Schema, schema) {
+	x.bqLayout.checkPartitioning(ctx, metaData)
+	clustering := x.bqLayout.clusteringUpdate(metaData, schema)
+
+	if bqs.Equal(metaData.Schema, schema) && clustering == nil {
 		return schema, false, nil
 	}
 
-	mergedSchema, err := bqs.Merge(metaData.Schema, schema)
-	if err != nil {
-		return nil, false, goerr.Wrap(err, "failed to merge BigQuery schema")
+	update := bigquery.TableMetadataToUpdate{
+		Clustering: clustering,
 	}
-	if err := bq.UpdateTable(ctx, bigquery.TableMetadataToUpdate{
-		Schema: mergedSchema,
-	}, metaData.ETag); err != nil {
+	if !bqs.Equal(metaData.Schema, schema) {
+		mergedSchema, err := bqs.Merge(metaData.Schema, schema)
+		if err != nil {
+			return nil, false, goerr.Wrap(err, "failed to merge BigQuery schema")
+		}
+		update.Schema = mergedSchema
+		schema = mergedSchema
+	}
+	if err := bq.UpdateTable(ctx, update, metaData.ETag); err != nil {
 		return nil, false, goerr.Wrap(err, "failed to update BigQuery table")
 	}
+	if clustering != nil {
+		logging.From(ctx).Info("updated clustering of BigQuery table", "table", metaData.FullID, "fields", clustering.Fields)
+	}
 
-	return mergedSchema, true, nil
+	return schema, true, nil
 }
 
-func (x *UseCase) insertToFirestore(ctx context.Context, meta model.GitHubMetadata, scan *model.Scan, report trivy.Report) error {
+// insertToFirestore updates the repository, the branch, targets and statuses of findings by the scan. It returns the regression of the default branch introduced by the scan, or nil if there is none.
+// Concurrent scans of the same branch, e.g. by a push and a pull request, are ordered by startedAt: a scan started before the stored one is not stored, and a scan stops storing findings once a newer scan claims the branch, leaving them to the newer scan.
+func (x *UseCase) insertToFirestore(ctx context.Context, meta model.GitHubMetadata, scan *model.Scan, report trivy.Report, startedAt time.Time) (*model.DefaultBranchRegression, error) {
 	repo := x.clients.ScanRepository()
 
 	// Create or update repository
-	repoID := types.GitHubRepoID(meta.Owner + "/" + meta.RepoName)
-	repository := &model.Repository{
+	repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+	repoData := &model.Repository{
 		ID:             repoID,
 		Owner:          meta.Owner,
 		Name:           meta.RepoName,
 		DefaultBranch:  types.BranchName(meta.DefaultBranch),
 		InstallationID: meta.InstallationID,
+		LastScanAt:     scan.Timestamp,
 		CreatedAt:      scan.Timestamp,
 		UpdatedAt:      scan.Timestamp,
 	}
-	if err := repo.CreateOrUpdateRepository(ctx, repository); err != nil {
-		return goerr.Wrap(err, "failed to create or update repository")
+	// Tags, team, scan config, pull request check and findings are not part of scan metadata, so they are carried over from the stored repository
+	existing, err := getStoredRepository(ctx, repo, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		repoData.Tags = existing.Tags
+		repoData.Team = existing.Team
+		repoData.ScanConfig = existing.ScanConfig
+		repoData.PullRequestCheck = existing.PullRequestCheck
+		repoData.Findings = existing.Findings
+	}
+	if err := repo.CreateOrUpdateRepository(ctx, repoData); err != nil {
+		return nil, goerr.Wrap(err, "failed to create or update repository")
 	}
 
-	// Create or update branch
+	// The previous scan of the branch is needed to detect regressions on the default branch
+	prevBranch, err := getPreviousBranch(ctx, repo, repoID, types.BranchName(meta.Branch))
+	if err != nil {
+		return nil, err
+	}
+	checkRegression := meta.DefaultBranch != "" && meta.Branch == meta.DefaultBranch &&
+		prevBranch != nil && prevBranch.LastCommitSHA != types.CommitSHA(meta.CommitID)
+	var introduced []*model.IntroducedVulnerability
+
+	// Claim the branch to store findings of the scan
 	branch := &model.Branch{
-		Name:          types.BranchName(meta.Branch),
-		LastScanID:    scan.ID,
-		LastScanAt:    scan.Timestamp,
-		LastCommitSHA: types.CommitSHA(meta.CommitID),
-		Status:        types.ScanStatusSuccess,
-		CreatedAt:     scan.Timestamp,
-		UpdatedAt:     scan.Timestamp,
+		Name:              types.BranchName(meta.Branch),
+		LastScanID:        scan.ID,
+		LastScanAt:        scan.Timestamp,
+		LastCommitSHA:     types.CommitSHA(meta.CommitID),
+		LastScanStartedAt: startedAt,
+		Status:            types.ScanStatusSuccess,
+		CreatedAt:         scan.Timestamp,
+		UpdatedAt:         scan.Timestamp,
+	}
+	logSuperseded := func() {
+		logging.From(ctx).Warn("newer scan of the branch is stored, findings of the scan are not stored in Firestore",
+			"repo_id", repoID,
+			"branch", branch.Name,
+			"commit", meta.CommitID,
+			"scan_id", scan.ID,
+			"started_at", startedAt,
+		)
+	}
+	if err := repo.ClaimBranchScan(ctx, repoID, branch); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			logSuperseded()
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to create or update branch")
+	}
+	// superseded returns true if another scan has claimed the branch after this scan
+	superseded := func() (bool, error) {
+		stored, err := repo.GetBranch(ctx, repoID, branch.Name)
+		if err != nil {
+			return false, goerr.Wrap(err, "failed to get branch")
+		}
+		if stored.LastScanID != scan.ID {
+			logSuperseded()
+			return true, nil
+		}
+		return false, nil
+	}
+
+	record := &model.ScanRecord{
+		ID:             scan.ID,
+		Branch:         branch.Name,
+		CommitSHA:      branch.LastCommitSHA,
+		CommitterLogin: strings.ToLower(meta.Committer.Login),
+		Timestamp:      scan.Timestamp,
+		TargetCount:    len(report.Results),
+		TrivyVersion:   report.TrivyVersion(),
+	}
+
+	exploitability := x.lookupExploitability(ctx, report)
+	overrides, err := x.loadSeverityOverrides(ctx, meta.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	// failScan records the error in the branch, because stored statuses do not reflect the scan and the branch must not be reported as successfully scanned
+	failScan := func(err error) {
+		branch.Status = types.ScanStatusFailure
+		branch.LastError = summarizeScanError(err)
+		branch.LastErrorAt = scan.Timestamp
+		if err := repo.ClaimBranchScan(ctx, repoID, branch); err != nil && !errors.Is(err, repository.ErrConflict) {
+			logging.From(ctx).Error("failed to mark branch scan as failure", "repo_id", repoID, "branch", branch.Name, "error", err)
+		}
 	}
-	if err := repo.CreateOrUpdateBranch(ctx, repoID, branch); err != nil {
-		return goerr.Wrap(err, "failed to create or update branch")
+	// Findings of targets stored before class and type were part of target IDs are carried over to the new IDs
+	if _, err := migrateTargetIDs(ctx, repo, repoID, branch.Name); err != nil {
+		failScan(err)
+		return nil, err
 	}
+	scannedTargets := make(map[types.TargetID]bool)
 
 	// Process each target (Result) in the report
 	for _, result := range report.Results {
+		if ok, err := superseded(); err != nil {
+			failScan(err)
+			return nil, err
+		} else if ok {
+			return nil, nil
+		}
+
 		// Create or update target
-		targetID := model.ToTargetID(result.Target)
+		targetID := model.ToTargetID(result.Target, string(result.Class), result.Type)
 		target := &model.Target{
-			ID:        targetID,
-			Target:    result.Target,
-			Class:     string(result.Class),
-			Type:      result.Type,
-			CreatedAt: scan.Timestamp,
-			UpdatedAt: scan.Timestamp,
+			ID:           targetID,
+			Target:       result.Target,
+			Class:        string(result.Class),
+			Type:         result.Type,
+			ArtifactType: string(report.ArtifactType),
+			CreatedAt:    scan.Timestamp,
+			UpdatedAt:    scan.Timestamp,
 		}
 		if err := repo.CreateOrUpdateTarget(ctx, repoID, branch.Name, target); err != nil {
-			return goerr.Wrap(err, "failed to create or update target")
+			failScan(err)
+			return nil, goerr.Wrap(err, "failed to create or update target")
 		}
+		scannedTargets[targetID] = true
 
-		// Process vulnerabilities with status management
-		if err := x.processVulnerabilities(ctx, repo, repoID, branch.Name, targetID, result.Vulnerabilities, scan.Timestamp); err != nil {
-			return goerr.Wrap(err, "failed to process vulnerabilities")
+		// Process vulnerabilities, misconfigurations and secrets with status management
+		findings := newFindings(&result, scan.ImageAnalysis)
+		setExploitability(findings, exploitability)
+		overrides.Apply(findings)
+		introducedVulns, err := x.processVulnerabilities(ctx, repo, repoID, branch.Name, targetID, findings, scan)
+		if err != nil {
+			failScan(err)
+			return nil, goerr.Wrap(err, "failed to process vulnerabilities")
+		}
+		for _, vuln := range introducedVulns {
+			introduced = append(introduced, &model.IntroducedVulnerability{Target: result.Target, Vulnerability: vuln})
+		}
+
+		// The package inventory is auxiliary to vulnerability status, so a failure does not fail the scan
+		if err := syncPackages(ctx, repo, repoID, branch.Name, targetID, result.Packages, scan.Timestamp); err != nil {
+			logging.From(ctx).Error("failed to sync packages", "repo_id", repoID, "branch", branch.Name, "target_id", targetID, "error", err)
+		}
+
+		for _, finding := range findings {
+			switch finding.Type {
+			case types.FindingTypeMisconfiguration:
+				record.MisconfigurationCount++
+			case types.FindingTypeSecret:
+				record.SecretCount++
+			default:
+				record.VulnerabilityCount++
+			}
 		}
 	}
 
-	return nil
+	if ok, err := superseded(); err != nil {
+		failScan(err)
+		return nil, err
+	} else if ok {
+		return nil, nil
+	}
+	if err := reconcileRemovedTargets(ctx, repo, repoID, branch.Name, string(report.ArtifactType), scannedTargets, scan); err != nil {
+		failScan(err)
+		return nil, goerr.Wrap(err, "failed to reconcile removed targets")
+	}
+	storeFindingSummary(ctx, repo, repoID, branch)
+
+	// Record the scan in the history of the repository
+	if err := repo.CreateScanRecord(ctx, repoID, record); err != nil {
+		failScan(err)
+		return nil, goerr.Wrap(err, "failed to create scan record")
+	}
+
+	if checkRegression && len(introduced) > 0 {
+		return &model.DefaultBranchRegression{
+			RepoID:            repoID,
+			Branch:            branch.Name,
+			CommitSHA:         branch.LastCommitSHA,
+			PreviousCommitSHA: prevBranch.LastCommitSHA,
+			ScanID:            scan.ID,
+			Team:              repoData.Team,
+			Tags:              repoData.Tags,
+			Vulnerabilities:   introduced,
+		}, nil
+	}
+
+	return nil, nil
 }
 
-func (x *UseCase) processVulnerabilities(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, detectedVulns []trivy.DetectedVulnerability, timestamp time.Time) error {
+// processVulnerabilities updates statuses of findings in the target. Acknowledgements are preserved across scans until they expire, including while the finding is fixed. It returns vulnerabilities that became active in packages which had no active finding before, as candidates of regression.
+func (x *UseCase) processVulnerabilities(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, detectedVulns []*model.Vulnerability, scan *model.Scan) ([]*model.Vulnerability, error) {
 	// Get existing vulnerabilities
 	existing, err := repo.ListVulnerabilities(ctx, repoID, branchName, targetID)
 	if err != nil {
-		return goerr.Wrap(err, "failed to list existing vulnerabilities")
+		return nil, goerr.Wrap(err, "failed to list existing vulnerabilities")
 	}
 
 	existingMap := make(map[string]*model.Vulnerability)
+	// presentPackages is packages that had active findings in the previous scan. A new CVE of such a package is a routine disclosure rather than a regression.
+	presentPackages := make(map[string]bool)
 	for _, v := range existing {
 		existingMap[v.ID] = v
+		if v.Status != types.VulnStatusFixed {
+			presentPackages[v.PkgName+"@"+v.InstalledVersion] = true
+		}
+	}
+	var introduced []*model.Vulnerability
+	isIntroduced := func(vuln *model.Vulnerability) bool {
+		return (vuln.Type == "" || vuln.Type == types.FindingTypeVulnerability) &&
+			!presentPackages[vuln.PkgName+"@"+vuln.InstalledVersion]
 	}
 
 	// Build detected vulnerability map and new vulnerabilities list
 	detectedMap := make(map[string]bool)
 	var newVulns []*model.Vulnerability
-	statusUpdates := make(map[string]types.VulnStatus)
+	var changes []*model.VulnStatusChange
+	addChange := func(vuln *model.Vulnerability, to types.VulnStatus, comment string) {
+		change := &model.VulnStatusChange{
+			VulnID:    vuln.ID,
+			From:      vuln.Status,
+			To:        to,
+			Actor:     model.StatusChangeActorOctovy,
+			Comment:   comment,
+			ScanID:    scan.ID,
+			Timestamp: scan.Timestamp,
+		}
+		if to == types.VulnStatusAcknowledged {
+			change.Acknowledgement = vuln.Acknowledgement
+		}
+		changes = append(changes, change)
+	}
 
-	for i := range detectedVulns {
-		vuln := model.NewVulnerability(&detectedVulns[i])
+	for _, vuln := range detectedVulns {
 		detectedMap[vuln.ID] = true
 
 		if existingVuln, exists := existingMap[vuln.ID]; exists {
+			if refreshed := refreshVulnerability(existingVuln, vuln); refreshed != nil {
+				newVulns = append(newVulns, refreshed)
+			}
+
 			// Existing vulnerability detected
-			if existingVuln.Status == types.VulnStatusFixed {
+			ack := existingVuln.Acknowledgement
+			switch existingVuln.Status {
+			case types.VulnStatusFixed:
+				if ack != nil && !ack.Expired(scan.Timestamp) {
+					// Fixed → Acknowledged (re-detection of an accepted risk)
+					addChange(existingVuln, types.VulnStatusAcknowledged, "detected again, acknowledgement restored")
+					break
+				}
 				// Fixed → Active (re-detection)
-				statusUpdates[vuln.ID] = types.VulnStatusActive
+				addChange(existingVuln, types.VulnStatusActive, "detected again")
+				if isIntroduced(vuln) {
+					introduced = append(introduced, vuln)
+				}
+
+			case types.VulnStatusAcknowledged:
+				// Acknowledgements without metadata were made before expiration was supported and never expire
+				if ack != nil && ack.Expired(scan.Timestamp) {
+					addChange(existingVuln, types.VulnStatusActive, "acknowledgement expired")
+				}
 			}
 			// Continuous detection → keep status (no update needed)
 		} else {
 			// New detection → Active
 			vuln.Status = types.VulnStatusActive
-			vuln.CreatedAt = timestamp
-			vuln.UpdatedAt = timestamp
+			vuln.CreatedAt = scan.Timestamp
+			vuln.UpdatedAt = scan.Timestamp
 			newVulns = append(newVulns, vuln)
+			if isIntroduced(vuln) {
+				introduced = append(introduced, vuln)
+			}
 		}
 	}
 
 	// Mark vulnerabilities not detected as Fixed
 	for id, existingVuln := range existingMap {
-		if !detectedMap[id] && existingVuln.Status == types.VulnStatusActive {
-			statusUpdates[id] = types.VulnStatusFixed
+		if !detectedMap[id] && (existingVuln.Status == types.VulnStatusActive || existingVuln.Status == types.VulnStatusAcknowledged) {
+			addChange(existingVuln, types.VulnStatusFixed, "not detected")
 		}
 	}
 
-	// Batch create new vulnerabilities
+	// Batch create new vulnerabilities and overwrite refreshed ones. Status changes are applied after that.
 	if len(newVulns) > 0 {
 		if err := repo.BatchCreateVulnerabilities(ctx, repoID, branchName, targetID, newVulns); err != nil {
-			return goerr.Wrap(err, "failed to batch create vulnerabilities")
+			return nil, goerr.Wrap(err, "failed to batch create vulnerabilities")
 		}
 	}
 
 	// Batch update statuses
-	if len(statusUpdates) > 0 {
-		if err := repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, statusUpdates); err != nil {
-			return goerr.Wrap(err, "failed to batch update vulnerability status")
+	if len(changes) > 0 {
+		if err := repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, changes); err != nil {
+			if err := reconcileVulnStatusChanges(ctx, repo, repoID, branchName, targetID, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return introduced, nil
+}
+
+// refreshVulnerability returns a copy of the stored vulnerability with data that can change without a status change, or nil if nothing changed. EPSS scores and KEV flags change daily, and nil of them means enrichment was not available and keeps the stored data. Severity changes by severity overrides and updates of the vulnerability database.
+func refreshVulnerability(stored, detected *model.Vulnerability) *model.Vulnerability {
+	refreshed := *stored
+	changed := false
+	if detected.Exploitability != nil && !detected.Exploitability.Equal(stored.Exploitability) {
+		refreshed.Exploitability = detected.Exploitability
+		changed = true
+	}
+	if detected.Severity != stored.Severity || detected.OriginalSeverity != stored.OriginalSeverity {
+		refreshed.Severity = detected.Severity
+		refreshed.OriginalSeverity = detected.OriginalSeverity
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return &refreshed
+}
+
+// syncPackages stores packages of the target as its inventory. Only new or changed packages are written and packages not in the scan are deleted, so that a rescan of an unchanged lockfile does not rewrite all packages. CreatedAt of a stored package is preserved.
+func syncPackages(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, packages []trivy.Package, ts time.Time) error {
+	stored, err := repo.ListPackages(ctx, repoID, branchName, targetID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list packages")
+	}
+	storedMap := make(map[string]*model.Package, len(stored))
+	for _, pkg := range stored {
+		storedMap[pkg.ID] = pkg
+	}
+
+	detected := make(map[string]bool, len(packages))
+	var upserts []*model.Package
+	for i := range packages {
+		pkg := model.NewPackage(&packages[i], ts)
+		// The same package may be reported twice, e.g. for each location in a lockfile
+		if detected[pkg.ID] {
+			continue
+		}
+		detected[pkg.ID] = true
+
+		if prev, ok := storedMap[pkg.ID]; ok {
+			if prev.SameContent(pkg) {
+				continue
+			}
+			pkg.CreatedAt = prev.CreatedAt
+		}
+		upserts = append(upserts, pkg)
+	}
+
+	var deletes []string
+	for id := range storedMap {
+		if !detected[id] {
+			deletes = append(deletes, id)
+		}
+	}
+
+	if len(upserts) > 0 {
+		if err := repo.BatchUpsertPackages(ctx, repoID, branchName, targetID, upserts); err != nil {
+			return goerr.Wrap(err, "failed to upsert packages")
+		}
+	}
+	if len(deletes) > 0 {
+		if err := repo.BatchDeletePackages(ctx, repoID, branchName, targetID, deletes); err != nil {
+			return goerr.Wrap(err, "failed to delete packages")
 		}
 	}
 
 	return nil
 }
+
+// reconcileRemovedTargets marks targets of the branch which are not in the scan as removed, e.g. go.mod of a deleted directory, so that their findings do not stay active forever. Their findings are fixed and their packages are deleted. A removed target which appears again is stored as a scanned target and its findings are detected again. Only targets of the same artifact type as the report are reconciled, so that a container image scan does not remove targets of source code scans of the branch; targets without artifact type are reconciled by any scan.
+func reconcileRemovedTargets(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName, artifactType string, scanned map[types.TargetID]bool, scan *model.Scan) error {
+	targets, err := repo.ListTargets(ctx, repoID, branchName)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list targets")
+	}
+
+	for _, target := range targets {
+		if scanned[target.ID] || target.Removed() || (target.ArtifactType != "" && target.ArtifactType != artifactType) {
+			continue
+		}
+
+		vulns, err := repo.ListVulnerabilities(ctx, repoID, branchName, target.ID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to list vulnerabilities of removed target", goerr.V("target", target.Target))
+		}
+		var changes []*model.VulnStatusChange
+		for _, vuln := range vulns {
+			if vuln.Status != types.VulnStatusActive && vuln.Status != types.VulnStatusAcknowledged {
+				continue
+			}
+			changes = append(changes, &model.VulnStatusChange{
+				VulnID:    vuln.ID,
+				From:      vuln.Status,
+				To:        types.VulnStatusFixed,
+				Actor:     model.StatusChangeActorOctovy,
+				Comment:   "target removed",
+				ScanID:    scan.ID,
+				Timestamp: scan.Timestamp,
+			})
+		}
+		if len(changes) > 0 {
+			if err := repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, target.ID, changes); err != nil {
+				if err := reconcileVulnStatusChanges(ctx, repo, repoID, branchName, target.ID, err); err != nil {
+					return err
+				}
+			}
+		}
+
+		// The package inventory is auxiliary to vulnerability status, so a failure does not fail the scan
+		if err := syncPackages(ctx, repo, repoID, branchName, target.ID, nil, scan.Timestamp); err != nil {
+			logging.From(ctx).Error("failed to delete packages of removed target", "repo_id", repoID, "branch", branchName, "target", target.Target, "error", err)
+		}
+
+		target.RemovedAt = scan.Timestamp
+		target.UpdatedAt = scan.Timestamp
+		if err := repo.CreateOrUpdateTarget(ctx, repoID, branchName, target); err != nil {
+			return goerr.Wrap(err, "failed to mark target as removed", goerr.V("target", target.Target))
+		}
+
+		logging.From(ctx).Info("target removed from branch",
+			"repo_id", repoID,
+			"branch", branchName,
+			"target", target.Target,
+			"fixed", len(changes),
+		)
+	}
+
+	return nil
+}
+
+// reconcileVulnStatusChanges compensates a partial failure of BatchUpdateVulnerabilityStatus. Failed changes are compared with the stored status, because a change may have been applied even if its commit reported an error, and the rest are applied again. It returns an error if some changes still cannot be applied.
+func reconcileVulnStatusChanges(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, updateErr error) error {
+	var partial *repository.VulnStatusUpdateError
+	if !errors.As(updateErr, &partial) {
+		return goerr.Wrap(updateErr, "failed to batch update vulnerability status")
+	}
+
+	stored, err := repo.ListVulnerabilities(ctx, repoID, branchName, targetID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list vulnerabilities to reconcile status", goerr.V("update_error", updateErr.Error()))
+	}
+	storedStatus := make(map[string]types.VulnStatus, len(stored))
+	for _, v := range stored {
+		storedStatus[v.ID] = v.Status
+	}
+
+	var retry []*model.VulnStatusChange
+	for _, change := range partial.FailedChanges() {
+		if status, ok := storedStatus[change.VulnID]; ok && status != change.To {
+			retry = append(retry, change)
+		}
+	}
+
+	if len(retry) > 0 {
+		if err := repo.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, retry); err != nil {
+			var ids []string
+			if errors.As(err, &partial) {
+				for _, change := range partial.FailedChanges() {
+					ids = append(ids, change.VulnID)
+				}
+			}
+			return goerr.Wrap(err, "vulnerability status diverged from the scan result", goerr.V("failed_vuln_ids", ids))
+		}
+	}
+
+	logging.From(ctx).Warn("recovered partial failure of vulnerability status update",
+		"repo_id", repoID,
+		"branch", branchName,
+		"target_id", targetID,
+		"failed", len(partial.Failed),
+		"reapplied", len(retry),
+	)
+	return nil
+}
+
+// newFindings converts vulnerabilities, failed misconfiguration checks and secrets of a
+// Trivy result into records for the scan repository.
+func newFindings(result *trivy.Result, analysis *model.ImageAnalysis) []*model.Vulnerability {
+	var findings []*model.Vulnerability
+
+	for i := range result.Vulnerabilities {
+		vuln := model.NewVulnerability(&result.Vulnerabilities[i])
+		if vuln.PURL == "" {
+			// Package URL is not reported by old Trivy and some scanners, then it is built from the ecosystem of the result
+			vuln.PURL = model.NewPURL(result.Type, vuln.PkgName, vuln.InstalledVersion)
+		}
+		if analysis != nil {
+			vuln.LayerOrigin = analysis.Origin(vuln.LayerDiffID)
+		}
+		findings = append(findings, vuln)
+	}
+
+	// A check can fail at several places in the same file; keep one record per check
+	seen := make(map[string]bool)
+	for i := range result.Misconfigurations {
+		misconf := &result.Misconfigurations[i]
+		if misconf.Status != "" && misconf.Status != trivy.MisconfStatusFailure {
+			continue
+		}
+		finding := model.NewMisconfigurationFinding(misconf)
+		if seen[finding.ID] {
+			continue
+		}
+		seen[finding.ID] = true
+		findings = append(findings, finding)
+	}
+
+	for i := range result.Secrets {
+		findings = append(findings, model.NewSecretFinding(&result.Secrets[i]))
+	}
+
+	return findings
+}
@@ -2,9 +2,13 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/bqs"
+	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/mock"
@@ -12,8 +16,10 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
 	"github.com/m-mizutani/octovy/pkg/repository/memory"
 	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
 )
 
 func TestInsertScanResult(t *testing.T) {
@@ -92,7 +98,7 @@ func TestInsertScanResult(t *testing.T) {
 		gt.NoError(t, err)
 		gt.V(t, string(branch.Name)).Equal("main")
 
-		targetID := model.ToTargetID("test-target")
+		targetID := model.ToTargetID("test-target", "os-pkgs", "alpine")
 		target, err := memRepo.GetTarget(ctx, repoID, "main", targetID)
 		gt.NoError(t, err)
 		gt.V(t, target.ID).Equal(targetID)
@@ -294,7 +300,7 @@ func TestInsertScanResult(t *testing.T) {
 
 		repoID := types.GitHubRepoID("test-owner/test-repo")
 		branchName := types.BranchName("main")
-		targetID := model.ToTargetID("go.mod")
+		targetID := model.ToTargetID("go.mod", "lang-pkgs", "gomod")
 
 		// Scenario 1: First scan - new vulnerabilities should be Active
 		report1 := trivy.Report{
@@ -368,4 +374,890 @@ func TestInsertScanResult(t *testing.T) {
 		gt.V(t, len(vulns)).Equal(1)
 		gt.V(t, vulns[0].Status).Equal(types.VulnStatusActive)
 	})
+
+	t.Run("container image layer attribution", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{}
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(
+			infra.WithBigQuery(mockBQ),
+			infra.WithScanRepository(memRepo),
+		))
+
+		ctx := context.Background()
+
+		var inserted *model.ScanRawRecord
+		mockBQ.InsertFunc = func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+			inserted = data.(*model.ScanRawRecord)
+			return nil
+		}
+		mockBQ.GetMetadataFunc = func(ctx context.Context) (*bigquery.TableMetadata, error) {
+			return nil, nil
+		}
+		mockBQ.CreateTableFunc = func(ctx context.Context, md *bigquery.TableMetadata) error {
+			return nil
+		}
+
+		meta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					Owner:    "test-owner",
+					RepoName: "image-repo",
+					RepoID:   123,
+				},
+				Branch:   "main",
+				CommitID: "0000000000000000000000000000000000000000",
+			},
+		}
+		report := trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "example/app:latest",
+			ArtifactType:  model.ArtifactTypeContainerImage,
+			Metadata: trivy.Metadata{
+				DiffIDs: []string{"sha256:base", "sha256:app"},
+				ImageConfig: &trivy.ConfigFile{
+					History: []trivy.History{
+						{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in / "},
+						{CreatedBy: "/bin/sh -c #(nop)  CMD [\"sh\"]", EmptyLayer: true},
+						{CreatedBy: "COPY app /app # buildkit"},
+					},
+				},
+			},
+			Results: []trivy.Result{
+				{
+					Target: "example/app:latest (alpine 3.18.0)",
+					Class:  "os-pkgs",
+					Type:   "alpine",
+					Vulnerabilities: []trivy.DetectedVulnerability{
+						{VulnerabilityID: "CVE-2024-0001", PkgName: "libssl", FixedVersion: "3.1.1", Layer: &trivy.Layer{DiffID: "sha256:base"}},
+						{VulnerabilityID: "CVE-2024-0002", PkgName: "app-lib", Layer: &trivy.Layer{DiffID: "sha256:app"}},
+					},
+				},
+			},
+		}
+
+		_, err := uc.InsertScanResult(ctx, meta, report)
+		gt.NoError(t, err)
+
+		gt.V(t, inserted).NotNil()
+		gt.V(t, inserted.ImageAnalysis).NotNil()
+		gt.V(t, inserted.ImageAnalysis.BaseVulnCount).Equal(1)
+		gt.V(t, inserted.ImageAnalysis.ApplicationVulnCount).Equal(1)
+		gt.A(t, inserted.ImageAnalysis.Recommendations).Length(1)
+
+		repoID := types.GitHubRepoID("test-owner/image-repo")
+		targetID := model.ToTargetID("example/app:latest (alpine 3.18.0)", "os-pkgs", "alpine")
+		vulns, err := memRepo.ListVulnerabilities(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		gt.V(t, len(vulns)).Equal(2)
+
+		origins := make(map[string]types.LayerOrigin)
+		for _, v := range vulns {
+			origins[v.ID] = v.LayerOrigin
+		}
+		gt.V(t, origins["CVE-2024-0001"]).Equal(types.LayerOriginBase)
+		gt.V(t, origins["CVE-2024-0002"]).Equal(types.LayerOriginApplication)
+	})
+
+	t.Run("misconfigurations and secrets are stored as findings", func(t *testing.T) {
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+		ctx := context.Background()
+
+		meta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					Owner:    "test-owner",
+					RepoName: "finding-repo",
+				},
+				Branch:   "main",
+				CommitID: "0000000000000000000000000000000000000000",
+			},
+		}
+		report := trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-artifact",
+			Results: []trivy.Result{
+				{
+					Target: "Dockerfile",
+					Class:  "config",
+					Type:   "dockerfile",
+					Misconfigurations: []trivy.DetectedMisconfiguration{
+						{ID: "DS002", AVDID: "AVD-DS-0002", Title: "Image user should not be 'root'", Severity: "HIGH", Status: trivy.MisconfStatusFailure},
+						{ID: "DS002", AVDID: "AVD-DS-0002", Title: "Image user should not be 'root'", Severity: "HIGH", Status: trivy.MisconfStatusFailure},
+						{ID: "DS001", AVDID: "AVD-DS-0001", Title: "':latest' tag used", Severity: "MEDIUM", Status: trivy.MisconfStatusPassed},
+					},
+				},
+				{
+					Target: "config/secret.env",
+					Class:  "secret",
+					Secrets: []trivy.SecretFinding{
+						{RuleID: "aws-access-key-id", Title: "AWS Access Key ID", Severity: "CRITICAL", StartLine: 3, Match: "AWS_ACCESS_KEY_ID=****"},
+						{RuleID: "aws-access-key-id", Title: "AWS Access Key ID", Severity: "CRITICAL", StartLine: 7, Match: "AWS_ACCESS_KEY_ID=****"},
+					},
+				},
+			},
+		}
+
+		_, err := uc.InsertScanResult(ctx, meta, report)
+		gt.NoError(t, err)
+
+		repoID := types.GitHubRepoID("test-owner/finding-repo")
+		misconfs, err := memRepo.ListVulnerabilities(ctx, repoID, "main", model.ToTargetID("Dockerfile", "config", "dockerfile"))
+		gt.NoError(t, err)
+		gt.A(t, misconfs).Length(1)
+		gt.V(t, misconfs[0].ID).Equal("AVD-DS-0002")
+		gt.V(t, misconfs[0].Type).Equal(types.FindingTypeMisconfiguration)
+		gt.V(t, misconfs[0].Status).Equal(types.VulnStatusActive)
+
+		secrets, err := memRepo.ListVulnerabilities(ctx, repoID, "main", model.ToTargetID("config/secret.env", "secret", ""))
+		gt.NoError(t, err)
+		gt.A(t, secrets).Length(2)
+		for _, s := range secrets {
+			gt.V(t, s.Type).Equal(types.FindingTypeSecret)
+		}
+
+		// Secrets are marked fixed once removed
+		report.Results[1].Secrets = report.Results[1].Secrets[:1]
+		_, err = uc.InsertScanResult(ctx, meta, report)
+		gt.NoError(t, err)
+
+		secrets, err = memRepo.ListVulnerabilities(ctx, repoID, "main", model.ToTargetID("config/secret.env", "secret", ""))
+		gt.NoError(t, err)
+		statuses := make(map[string]types.VulnStatus)
+		for _, s := range secrets {
+			statuses[s.ID] = s.Status
+		}
+		gt.V(t, statuses["aws-access-key-id:3"]).Equal(types.VulnStatusActive)
+		gt.V(t, statuses["aws-access-key-id:7"]).Equal(types.VulnStatusFixed)
+
+		// Every scan is recorded in the history with its finding counts
+		records, err := memRepo.ListScanRecords(ctx, repoID)
+		gt.NoError(t, err)
+		gt.A(t, records).Length(2)
+		gt.V(t, records[0].Branch).Equal(types.BranchName("main"))
+		gt.V(t, records[0].CommitSHA).Equal(types.CommitSHA("0000000000000000000000000000000000000000"))
+		gt.V(t, records[0].TargetCount).Equal(2)
+		gt.V(t, records[0].MisconfigurationCount).Equal(1)
+		gt.V(t, records[0].SecretCount).Equal(1)
+		gt.V(t, records[0].VulnerabilityCount).Equal(0)
+		gt.V(t, records[1].SecretCount).Equal(2)
+	})
+
+	t.Run("identifiers are normalized before storing", func(t *testing.T) {
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+		ctx := context.Background()
+
+		meta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					Owner:    "test-owner",
+					RepoName: "normalize-repo",
+				},
+				Branch:   "refs/heads/main",
+				CommitID: "F7C8851DA7C7FCC46212FCCFB6C9C4BDA520F1CA",
+			},
+			DefaultBranch: "refs/heads/main",
+		}
+		report := trivy.Report{SchemaVersion: 2, ArtifactName: "test-artifact"}
+
+		_, err := uc.InsertScanResult(ctx, meta, report)
+		gt.NoError(t, err)
+
+		repoID := types.GitHubRepoID("test-owner/normalize-repo")
+		repo, err := memRepo.GetRepository(ctx, repoID)
+		gt.NoError(t, err)
+		gt.V(t, repo.DefaultBranch).Equal(types.BranchName("main"))
+
+		branch, err := memRepo.GetBranch(ctx, repoID, "main")
+		gt.NoError(t, err)
+		gt.V(t, branch.LastCommitSHA).Equal(types.CommitSHA("f7c8851da7c7fcc46212fccfb6c9c4bda520f1ca"))
+	})
+
+	t.Run("BigQuery insert failure is tagged", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+				return nil, nil
+			},
+			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error {
+				return nil
+			},
+			InsertFunc: func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+				return errors.New("quota exceeded")
+			},
+		}
+		uc := usecase.New(infra.New(infra.WithBigQuery(mockBQ)))
+
+		meta := model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo", RepoID: 123},
+				Branch:     "main",
+				CommitID:   "0000000000000000000000000000000000000000",
+			},
+		}
+		report := trivy.Report{SchemaVersion: 2, ArtifactName: "test-artifact"}
+
+		_, err := uc.InsertScanResult(context.Background(), meta, report)
+		gt.Error(t, err)
+		gt.True(t, goerr.HasTag(err, types.TagBigQuery))
+	})
+}
+
+func TestInsertScanResultNormalized(t *testing.T) {
+	newTableMock := func() *mock.BigQueryMock {
+		return &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+				return nil, nil
+			},
+			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error {
+				return nil
+			},
+			InsertRowsFunc: func(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error {
+				return nil
+			},
+		}
+	}
+
+	scanTable := newTableMock()
+	vulnTable := newTableMock()
+	pkgTable := newTableMock()
+	scanTable.TableFunc = func(tableID types.BQTableID) interfaces.BigQuery {
+		switch tableID {
+		case "scans_vulnerabilities":
+			return vulnTable
+		case "scans_packages":
+			return pkgTable
+		}
+		t.Errorf("unexpected table: %s", tableID)
+		return nil
+	}
+
+	uc := usecase.New(infra.New(infra.WithBigQuery(scanTable)),
+		usecase.WithBigQueryNormalizedInsert("scans_vulnerabilities", "scans_packages"),
+	)
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-artifact",
+		Results: []trivy.Result{
+			{
+				Target: "go.mod",
+				Packages: []trivy.Package{
+					{Name: "pkg-a", Version: "1.0.0"},
+					{Name: "pkg-b", Version: "2.0.0"},
+				},
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a"},
+					{VulnerabilityID: "CVE-2024-0002", PkgName: "pkg-a"},
+					{VulnerabilityID: "CVE-2024-0003", PkgName: "pkg-b"},
+				},
+			},
+		},
+	}
+
+	scanID, err := uc.InsertScanResult(context.Background(), meta, report)
+	gt.NoError(t, err)
+
+	// The whole report is never sent as one row
+	gt.A(t, scanTable.InsertCalls()).Length(0)
+
+	gt.A(t, scanTable.InsertRowsCalls()).Length(1)
+	summary := scanTable.InsertRowsCalls()[0].Rows[0].(*model.ScanSummaryRow)
+	gt.V(t, summary.ID).Equal(scanID)
+	gt.V(t, summary.VulnerabilityCount).Equal(3)
+	gt.V(t, summary.PackageCount).Equal(2)
+
+	gt.A(t, vulnTable.InsertRowsCalls()).Length(1)
+	gt.A(t, vulnTable.InsertRowsCalls()[0].Rows).Length(3)
+	gt.A(t, vulnTable.CreateTableCalls()).Length(1)
+
+	gt.A(t, pkgTable.InsertRowsCalls()).Length(1)
+	gt.A(t, pkgTable.InsertRowsCalls()[0].Rows).Length(2)
+	for _, row := range pkgTable.InsertRowsCalls()[0].Rows {
+		gt.V(t, row.(*model.PackageRow).ScanID).Equal(scanID)
+	}
+}
+
+func TestCreateOrUpdateBigQueryTableLayout(t *testing.T) {
+	ctx := context.Background()
+	scan := &model.Scan{}
+	schema := gt.R1(bqs.Infer(scan)).NoError(t)
+
+	t.Run("new table is partitioned and clustered", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) { return nil, nil },
+			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error { return nil },
+		}
+		uc := usecase.New(infra.New(), usecase.WithBigQueryTableLayout(types.BQPartitioningDay, true))
+
+		_, updated := gt.R2(uc.CreateOrUpdateBigQueryTableForTest(ctx, mockBQ, scan)).NoError(t)
+		gt.False(t, updated)
+		gt.A(t, mockBQ.CreateTableCalls()).Length(1)
+		md := mockBQ.CreateTableCalls()[0].Md
+		gt.V(t, md.TimePartitioning).NotNil()
+		gt.V(t, md.TimePartitioning.Type).Equal(bigquery.DayPartitioningType)
+		gt.V(t, md.TimePartitioning.Field).Equal("timestamp")
+		gt.V(t, md.Clustering).NotNil()
+		gt.V(t, md.Clustering.Fields).Equal([]string{"owner", "repo_name"})
+	})
+
+	t.Run("new table is neither partitioned nor clustered by default", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) { return nil, nil },
+			CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error { return nil },
+		}
+		uc := usecase.New(infra.New())
+
+		gt.R2(uc.CreateOrUpdateBigQueryTableForTest(ctx, mockBQ, scan)).NoError(t)
+		md := mockBQ.CreateTableCalls()[0].Md
+		gt.V(t, md.TimePartitioning).Nil()
+		gt.V(t, md.Clustering).Nil()
+	})
+
+	t.Run("existing unpartitioned table is kept and clustered", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+				return &bigquery.TableMetadata{FullID: "project:octovy.scans", Schema: schema, ETag: "test-etag"}, nil
+			},
+			UpdateTableFunc: func(ctx context.Context, md bigquery.TableMetadataToUpdate, eTag string) error { return nil },
+		}
+		uc := usecase.New(infra.New(), usecase.WithBigQueryTableLayout(types.BQPartitioningDay, true))
+
+		gt.R2(uc.CreateOrUpdateBigQueryTableForTest(ctx, mockBQ, scan)).NoError(t)
+		gt.A(t, mockBQ.UpdateTableCalls()).Length(1)
+		update := mockBQ.UpdateTableCalls()[0]
+		gt.V(t, update.ETag).Equal("test-etag")
+		gt.V(t, update.Md.Schema).Nil()
+		gt.V(t, update.Md.Clustering.Fields).Equal([]string{"owner", "repo_name"})
+	})
+
+	t.Run("existing clustered table is not updated", func(t *testing.T) {
+		mockBQ := &mock.BigQueryMock{
+			GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+				return &bigquery.TableMetadata{
+					Schema:           schema,
+					TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.MonthPartitioningType, Field: "timestamp"},
+					Clustering:       &bigquery.Clustering{Fields: []string{"owner", "repo_name"}},
+				}, nil
+			},
+		}
+		uc := usecase.New(infra.New(), usecase.WithBigQueryTableLayout(types.BQPartitioningDay, true))
+
+		_, updated := gt.R2(uc.CreateOrUpdateBigQueryTableForTest(ctx, mockBQ, scan)).NoError(t)
+		gt.False(t, updated)
+		gt.A(t, mockBQ.UpdateTableCalls()).Length(0)
+	})
+}
+
+func TestInsertScanResultTableRouting(t *testing.T) {
+	newRoutedMock := func(tables map[types.BQTableID]*mock.BigQueryMock) *mock.BigQueryMock {
+		return &mock.BigQueryMock{
+			TableFunc: func(tableID types.BQTableID) interfaces.BigQuery {
+				tbl := &mock.BigQueryMock{
+					GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) { return nil, nil },
+					CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error { return nil },
+					InsertFunc: func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+						return nil
+					},
+					InsertRowsFunc: func(ctx context.Context, schema bigquery.Schema, rows []any, opts ...interfaces.BigQueryInsertOption) error {
+						return nil
+					},
+				}
+				tables[tableID] = tbl
+				return tbl
+			},
+		}
+	}
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "My-Org", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-artifact",
+		Results: []trivy.Result{
+			{
+				Target:          "go.mod",
+				Packages:        []trivy.Package{{Name: "pkg-a", Version: "1.0.0"}},
+				Vulnerabilities: []trivy.DetectedVulnerability{{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a"}},
+			},
+		},
+	}
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC) })
+
+	t.Run("raw scan is stored in the table of the month", func(t *testing.T) {
+		tables := map[types.BQTableID]*mock.BigQueryMock{}
+		mockBQ := newRoutedMock(tables)
+		uc := usecase.New(infra.New(infra.WithBigQuery(mockBQ)),
+			usecase.WithBigQueryTableRouting("scans", types.BQTableRoutingMonth),
+		)
+
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+		gt.A(t, mockBQ.InsertCalls()).Length(0)
+		gt.A(t, tables["scans_202501"].CreateTableCalls()).Length(1)
+		gt.A(t, tables["scans_202501"].InsertCalls()).Length(1)
+	})
+
+	t.Run("normalized tables are routed by owner", func(t *testing.T) {
+		tables := map[types.BQTableID]*mock.BigQueryMock{}
+		mockBQ := newRoutedMock(tables)
+		uc := usecase.New(infra.New(infra.WithBigQuery(mockBQ)),
+			usecase.WithBigQueryTableRouting("scans", types.BQTableRoutingOwner),
+			usecase.WithBigQueryNormalizedInsert("scans_vulnerabilities", "scans_packages"),
+		)
+
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+		gt.A(t, mockBQ.InsertRowsCalls()).Length(0)
+		gt.A(t, tables["scans_my_org"].InsertRowsCalls()).Length(1)
+		gt.A(t, tables["scans_vulnerabilities_my_org"].InsertRowsCalls()).Length(1)
+		gt.A(t, tables["scans_packages_my_org"].InsertRowsCalls()).Length(1)
+	})
+}
+
+// flakyStatusRepository fails status updates of the vulnerabilities in failIDs for the first failures calls. If apply is true, the failed changes are still applied as if the commit succeeded but its response was lost.
+type flakyStatusRepository struct {
+	interfaces.ScanRepository
+	failIDs  map[string]bool
+	failures int
+	apply    bool
+	calls    int
+}
+
+func (x *flakyStatusRepository) BatchUpdateVulnerabilityStatus(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, targetID types.TargetID, changes []*model.VulnStatusChange) error {
+	x.calls++
+	if x.calls > x.failures {
+		return x.ScanRepository.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, changes)
+	}
+
+	updateErr := &repository.VulnStatusUpdateError{}
+	var applied []*model.VulnStatusChange
+	for _, change := range changes {
+		if x.failIDs[change.VulnID] {
+			updateErr.Failed = append(updateErr.Failed, &model.FailedVulnStatusChange{Change: change, Reason: "unavailable"})
+			if !x.apply {
+				continue
+			}
+		} else {
+			updateErr.Applied++
+		}
+		applied = append(applied, change)
+	}
+	if err := x.ScanRepository.BatchUpdateVulnerabilityStatus(ctx, repoID, branchName, targetID, applied); err != nil {
+		return err
+	}
+	return goerr.Wrap(updateErr, "failed to commit vulnerability status batch")
+}
+
+func TestInsertScanResultPartialStatusUpdate(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	branchName := types.BranchName("main")
+	targetID := model.ToTargetID("go.mod", "lang-pkgs", "gomod")
+
+	newReport := func(vulnIDs ...string) trivy.Report {
+		result := trivy.Result{Target: "go.mod", Class: "lang-pkgs", Type: "gomod"}
+		for _, id := range vulnIDs {
+			result.Vulnerabilities = append(result.Vulnerabilities, trivy.DetectedVulnerability{
+				VulnerabilityID:  id,
+				PkgName:          "pkg1",
+				InstalledVersion: "1.0.0",
+				Vulnerability:    trivy.Vulnerability{Severity: "HIGH"},
+			})
+		}
+		return trivy.Report{SchemaVersion: 2, ArtifactName: "test-artifact", Results: []trivy.Result{result}}
+	}
+
+	// Both vulnerabilities are fixed by the second scan, and the change of CVE-2024-0002 fails
+	run := func(t *testing.T, repo *flakyStatusRepository) error {
+		ctx := context.Background()
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+		_, err := uc.InsertScanResult(ctx, meta, newReport("CVE-2024-0001", "CVE-2024-0002"))
+		gt.NoError(t, err)
+		_, err = uc.InsertScanResult(ctx, meta, newReport())
+		return err
+	}
+	statuses := func(t *testing.T, repo *flakyStatusRepository) map[string]types.VulnStatus {
+		vulns, err := repo.ListVulnerabilities(context.Background(), repoID, branchName, targetID)
+		gt.NoError(t, err)
+		result := make(map[string]types.VulnStatus)
+		for _, v := range vulns {
+			result[v.ID] = v.Status
+		}
+		return result
+	}
+
+	t.Run("failed changes are applied again", func(t *testing.T) {
+		repo := &flakyStatusRepository{ScanRepository: memory.New(), failIDs: map[string]bool{"CVE-2024-0002": true}, failures: 1}
+		gt.NoError(t, run(t, repo))
+		gt.V(t, repo.calls).Equal(2)
+
+		got := statuses(t, repo)
+		gt.V(t, got["CVE-2024-0001"]).Equal(types.VulnStatusFixed)
+		gt.V(t, got["CVE-2024-0002"]).Equal(types.VulnStatusFixed)
+
+		branch, err := repo.GetBranch(context.Background(), repoID, branchName)
+		gt.NoError(t, err)
+		gt.V(t, branch.Status).Equal(types.ScanStatusSuccess)
+	})
+
+	t.Run("changes applied despite the error are not applied twice", func(t *testing.T) {
+		repo := &flakyStatusRepository{ScanRepository: memory.New(), failIDs: map[string]bool{"CVE-2024-0002": true}, failures: 1, apply: true}
+		gt.NoError(t, run(t, repo))
+		gt.V(t, repo.calls).Equal(1)
+		gt.V(t, statuses(t, repo)["CVE-2024-0002"]).Equal(types.VulnStatusFixed)
+	})
+
+	t.Run("persistent failure marks the branch scan as failure", func(t *testing.T) {
+		repo := &flakyStatusRepository{ScanRepository: memory.New(), failIDs: map[string]bool{"CVE-2024-0002": true}, failures: 2}
+		err := run(t, repo)
+		gt.Error(t, err)
+
+		var updateErr *repository.VulnStatusUpdateError
+		gt.True(t, errors.As(err, &updateErr))
+		gt.A(t, updateErr.FailedChanges()).Length(1)
+		gt.V(t, updateErr.FailedChanges()[0].VulnID).Equal("CVE-2024-0002")
+
+		got := statuses(t, repo)
+		gt.V(t, got["CVE-2024-0001"]).Equal(types.VulnStatusFixed)
+		gt.V(t, got["CVE-2024-0002"]).Equal(types.VulnStatusActive)
+
+		branch, err := repo.GetBranch(context.Background(), repoID, branchName)
+		gt.NoError(t, err)
+		gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+	})
+}
+
+// failingWriteRepository fails writes of targets or scan records
+type failingWriteRepository struct {
+	interfaces.ScanRepository
+	failTarget bool
+	failRecord bool
+}
+
+func (x *failingWriteRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if x.failTarget {
+		return goerr.New("unavailable")
+	}
+	return x.ScanRepository.CreateOrUpdateTarget(ctx, repoID, branchName, target)
+}
+
+func (x *failingWriteRepository) CreateScanRecord(ctx context.Context, repoID types.GitHubRepoID, record *model.ScanRecord) error {
+	if x.failRecord {
+		return goerr.New("unavailable")
+	}
+	return x.ScanRepository.CreateScanRecord(ctx, repoID, record)
+}
+
+func TestInsertScanResultWriteFailure(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-artifact",
+		Results: []trivy.Result{{
+			Target: "go.mod",
+			Class:  "lang-pkgs",
+			Type:   "gomod",
+			Vulnerabilities: []trivy.DetectedVulnerability{{
+				VulnerabilityID:  "CVE-2024-0001",
+				PkgName:          "pkg1",
+				InstalledVersion: "1.0.0",
+				Vulnerability:    trivy.Vulnerability{Severity: "HIGH"},
+			}},
+		}},
+	}
+
+	testCases := map[string]*failingWriteRepository{
+		"target":      {failTarget: true},
+		"scan record": {failRecord: true},
+	}
+	for name, repo := range testCases {
+		t.Run("failure of "+name+" marks the branch scan as failure", func(t *testing.T) {
+			ctx := context.Background()
+			repo.ScanRepository = memory.New()
+			uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+			gt.R1(uc.InsertScanResult(ctx, meta, report)).Error(t)
+
+			branch := gt.R1(repo.GetBranch(ctx, "test-owner/test-repo", "main")).NoError(t)
+			gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+			gt.S(t, branch.LastError).Contains("unavailable")
+		})
+	}
+}
+
+func TestInsertScanResultPackages(t *testing.T) {
+	memRepo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    "test-owner",
+				RepoName: "test-repo",
+				RepoID:   123,
+			},
+			Branch:   "main",
+			CommitID: "0000000000000000000000000000000000000000",
+		},
+		InstallationID: 456,
+	}
+	newReport := func(pkgs ...trivy.Package) trivy.Report {
+		return trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-artifact",
+			Results: []trivy.Result{
+				{
+					Target:   "go.mod",
+					Class:    "lang-pkgs",
+					Type:     "gomod",
+					Packages: pkgs,
+				},
+			},
+		}
+	}
+
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	targetID := model.ToTargetID("go.mod", "lang-pkgs", "gomod")
+	listPackages := func(t *testing.T) map[string]*model.Package {
+		pkgs, err := memRepo.ListPackages(context.Background(), repoID, "main", targetID)
+		gt.NoError(t, err)
+		resp := make(map[string]*model.Package, len(pkgs))
+		for _, pkg := range pkgs {
+			resp[pkg.Name+"@"+pkg.Version] = pkg
+		}
+		return resp
+	}
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(24 * time.Hour)
+
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return t0 })
+	_, err := uc.InsertScanResult(ctx, meta, newReport(
+		trivy.Package{Name: "golang.org/x/net", Version: "v0.15.0", Licenses: []string{"BSD-3-Clause"},
+			Identifier: &trivy.PackageIdentifier{PURL: "pkg:golang/golang.org/x/net@v0.15.0"}},
+		trivy.Package{Name: "github.com/google/uuid", Version: "v1.6.0", Licenses: []string{"BSD-3-Clause"}},
+		trivy.Package{Name: "golang.org/x/text", Version: "v0.13.0", Indirect: true},
+	))
+	gt.NoError(t, err)
+
+	pkgs := listPackages(t)
+	gt.V(t, len(pkgs)).Equal(3)
+	net := pkgs["golang.org/x/net@v0.15.0"]
+	gt.V(t, net).NotNil()
+	gt.V(t, net.PURL).Equal("pkg:golang/golang.org/x/net@v0.15.0")
+	gt.A(t, net.Licenses).Equal([]string{"BSD-3-Clause"})
+	gt.True(t, pkgs["golang.org/x/text@v0.13.0"].Indirect)
+
+	// Rescan: x/net is upgraded, uuid is unchanged, x/text is removed
+	ctx = logging.CtxWithTime(context.Background(), func() time.Time { return t1 })
+	_, err = uc.InsertScanResult(ctx, meta, newReport(
+		trivy.Package{Name: "golang.org/x/net", Version: "v0.17.0", Licenses: []string{"BSD-3-Clause"}},
+		trivy.Package{Name: "github.com/google/uuid", Version: "v1.6.0", Licenses: []string{"BSD-3-Clause"}},
+	))
+	gt.NoError(t, err)
+
+	pkgs = listPackages(t)
+	gt.V(t, len(pkgs)).Equal(2)
+	gt.V(t, pkgs["golang.org/x/net@v0.15.0"]).Nil()
+	gt.V(t, pkgs["golang.org/x/text@v0.13.0"]).Nil()
+	gt.V(t, pkgs["golang.org/x/net@v0.17.0"].CreatedAt).Equal(t1)
+
+	// An unchanged package is not rewritten
+	uuid := pkgs["github.com/google/uuid@v1.6.0"]
+	gt.V(t, uuid.CreatedAt).Equal(t0)
+	gt.V(t, uuid.UpdatedAt).Equal(t0)
+}
+
+func TestInsertScanResultRemovedTargets(t *testing.T) {
+	memRepo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}
+	newResult := func(target, vulnID string) trivy.Result {
+		return trivy.Result{
+			Target: target,
+			Class:  "lang-pkgs",
+			Type:   "gomod",
+			Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: vulnID, PkgName: "pkg1", InstalledVersion: "1.0.0", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+			},
+			Packages: []trivy.Package{{Name: "pkg1", Version: "1.0.0"}},
+		}
+	}
+	newReport := func(artifactType trivy.ArtifactType, results ...trivy.Result) trivy.Report {
+		return trivy.Report{SchemaVersion: 2, ArtifactName: "test-artifact", ArtifactType: artifactType, Results: results}
+	}
+
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	rootID := model.ToTargetID("go.mod", "lang-pkgs", "gomod")
+	subID := model.ToTargetID("tools/go.mod", "lang-pkgs", "gomod")
+	imageID := model.ToTargetID("alpine:3.19 (alpine 3.19.0)", "os-pkgs", "alpine")
+	getStatus := func(t *testing.T, targetID types.TargetID) types.VulnStatus {
+		vulns, err := memRepo.ListVulnerabilities(context.Background(), repoID, "main", targetID)
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(1)
+		return vulns[0].Status
+	}
+	getTarget := func(t *testing.T, targetID types.TargetID) *model.Target {
+		return gt.R1(memRepo.GetTarget(context.Background(), repoID, "main", targetID)).NoError(t)
+	}
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return t0 })
+	gt.R1(uc.InsertScanResult(ctx, meta, newReport("filesystem", newResult("go.mod", "CVE-2024-0001"), newResult("tools/go.mod", "CVE-2024-0002")))).NoError(t)
+	// A container image of the branch is scanned separately
+	gt.R1(uc.InsertScanResult(ctx, meta, newReport("container_image", trivy.Result{
+		Target: "alpine:3.19 (alpine 3.19.0)",
+		Class:  "os-pkgs",
+		Type:   "alpine",
+		Vulnerabilities: []trivy.DetectedVulnerability{
+			{VulnerabilityID: "CVE-2024-0003", PkgName: "musl", InstalledVersion: "1.2.4", Vulnerability: trivy.Vulnerability{Severity: "LOW"}},
+		},
+	}))).NoError(t)
+	gt.V(t, getStatus(t, subID)).Equal(types.VulnStatusActive)
+	gt.False(t, getTarget(t, rootID).Removed())
+
+	t.Run("findings of a target not in the scan are fixed", func(t *testing.T) {
+		t1 := t0.Add(time.Hour)
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return t1 })
+		gt.R1(uc.InsertScanResult(ctx, meta, newReport("filesystem", newResult("go.mod", "CVE-2024-0001")))).NoError(t)
+
+		gt.V(t, getStatus(t, rootID)).Equal(types.VulnStatusActive)
+		gt.V(t, getStatus(t, subID)).Equal(types.VulnStatusFixed)
+		gt.V(t, getTarget(t, subID).RemovedAt).Equal(t1)
+		gt.A(t, gt.R1(memRepo.ListPackages(ctx, repoID, "main", subID)).NoError(t)).Length(0)
+
+		history := gt.R1(memRepo.ListVulnerabilityHistory(ctx, repoID, "main", subID, "CVE-2024-0002")).NoError(t)
+		gt.A(t, history).Length(1).At(0, func(t testing.TB, v *model.VulnStatusChange) {
+			gt.V(t, v.Comment).Equal("target removed")
+		})
+
+		// Targets of the container image scan are kept
+		gt.V(t, getStatus(t, imageID)).Equal(types.VulnStatusActive)
+		gt.False(t, getTarget(t, imageID).Removed())
+	})
+
+	t.Run("removed target is reconciled only once", func(t *testing.T) {
+		t2 := t0.Add(2 * time.Hour)
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return t2 })
+		gt.R1(uc.InsertScanResult(ctx, meta, newReport("filesystem", newResult("go.mod", "CVE-2024-0001")))).NoError(t)
+
+		gt.V(t, getTarget(t, subID).RemovedAt).Equal(t0.Add(time.Hour))
+		gt.A(t, gt.R1(memRepo.ListVulnerabilityHistory(ctx, repoID, "main", subID, "CVE-2024-0002")).NoError(t)).Length(1)
+	})
+
+	t.Run("target scanned again is restored", func(t *testing.T) {
+		gt.R1(uc.InsertScanResult(context.Background(), meta, newReport("filesystem", newResult("go.mod", "CVE-2024-0001"), newResult("tools/go.mod", "CVE-2024-0002")))).NoError(t)
+
+		gt.V(t, getStatus(t, subID)).Equal(types.VulnStatusActive)
+		gt.False(t, getTarget(t, subID).Removed())
+	})
+}
+
+// supersedingRepository claims the branch by a newer scan when the target is created, as if the newer scan started storing its findings concurrently
+type supersedingRepository struct {
+	interfaces.ScanRepository
+	target string
+}
+
+func (x *supersedingRepository) CreateOrUpdateTarget(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName, target *model.Target) error {
+	if target.Target == x.target {
+		if err := x.ScanRepository.ClaimBranchScan(ctx, repoID, &model.Branch{
+			Name:              branchName,
+			LastScanID:        "newer-scan",
+			LastScanStartedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Status:            types.ScanStatusSuccess,
+		}); err != nil {
+			return err
+		}
+	}
+	return x.ScanRepository.CreateOrUpdateTarget(ctx, repoID, branchName, target)
+}
+
+func TestInsertScanResultOutOfOrder(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	ctxAt := func(ts time.Time) context.Context {
+		return logging.CtxWithTime(context.Background(), func() time.Time { return ts })
+	}
+	newReport := func(vulnID string, targets ...string) trivy.Report {
+		report := trivy.Report{SchemaVersion: 2, ArtifactName: "test-artifact"}
+		for _, target := range targets {
+			report.Results = append(report.Results, trivy.Result{
+				Target: target, Class: "lang-pkgs", Type: "gomod",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: vulnID, PkgName: "pkg1", InstalledVersion: "1.0.0", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+				},
+			})
+		}
+		return report
+	}
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("scan started before the stored scan is not stored", func(t *testing.T) {
+		repo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+		newer := gt.R1(uc.InsertScanResult(ctxAt(base.Add(5*time.Minute)), meta, newReport("CVE-2024-0001", "go.mod"))).NoError(t)
+		gt.R1(uc.InsertScanResult(ctxAt(base), meta, newReport("CVE-2024-0002", "go.mod"))).NoError(t)
+
+		branch := gt.R1(repo.GetBranch(context.Background(), repoID, "main")).NoError(t)
+		gt.V(t, branch.LastScanID).Equal(newer)
+		vulns := gt.R1(repo.ListVulnerabilities(context.Background(), repoID, "main", model.ToTargetID("go.mod", "lang-pkgs", "gomod"))).NoError(t)
+		gt.A(t, vulns).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.ID).Equal("CVE-2024-0001")
+		})
+	})
+
+	t.Run("scan stops storing findings once a newer scan claims the branch", func(t *testing.T) {
+		repo := memory.New()
+		uc := usecase.New(infra.New(infra.WithScanRepository(&supersedingRepository{ScanRepository: repo, target: "a/go.mod"})))
+
+		gt.R1(uc.InsertScanResult(ctxAt(base), meta, newReport("CVE-2024-0001", "a/go.mod", "b/go.mod"))).NoError(t)
+
+		branch := gt.R1(repo.GetBranch(context.Background(), repoID, "main")).NoError(t)
+		gt.V(t, branch.LastScanID).Equal(types.ScanID("newer-scan"))
+		gt.V(t, branch.Status).Equal(types.ScanStatusSuccess)
+		_, err := repo.GetTarget(context.Background(), repoID, "main", model.ToTargetID("b/go.mod", "lang-pkgs", "gomod"))
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+		gt.A(t, gt.R1(repo.ListScanRecords(context.Background(), repoID)).NoError(t)).Length(0)
+	})
 }
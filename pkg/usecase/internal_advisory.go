@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+// LoadInternalAdvisories loads an internal advisory feed from a file path or an http(s) URL and validates all advisories in it
+func LoadInternalAdvisories(ctx context.Context, src string) ([]*model.InternalAdvisory, error) {
+	var r io.ReadCloser
+	if strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create advisory feed request", goerr.V("url", src))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to fetch advisory feed", goerr.V("url", src))
+		}
+		if resp.StatusCode != http.StatusOK {
+			safe.Close(resp.Body)
+			return nil, goerr.New("unexpected status code of advisory feed", goerr.V("url", src), goerr.V("code", resp.StatusCode))
+		}
+		r = resp.Body
+	} else {
+		fd, err := os.Open(filepath.Clean(src))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to open advisory feed file", goerr.V("path", src))
+		}
+		r = fd
+	}
+	defer safe.Close(r)
+
+	var feed model.InternalAdvisoryFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode advisory feed", goerr.V("src", src))
+	}
+	for _, advisory := range feed.Advisories {
+		if err := advisory.Validate(); err != nil {
+			return nil, goerr.Wrap(err, "invalid advisory in feed", goerr.V("src", src))
+		}
+	}
+
+	return feed.Advisories, nil
+}
+
+// applyInternalAdvisories returns the report with vulnerabilities of packages matched with internal advisories. Results of the given report are not modified. Packages are listed only if Trivy runs with --list-all-pkgs.
+func (x *UseCase) applyInternalAdvisories(ctx context.Context, report trivy.Report) trivy.Report {
+	if len(x.internalAdvisories) == 0 {
+		return report
+	}
+
+	results := make([]trivy.Result, len(report.Results))
+	var matched int
+	for i, result := range report.Results {
+		results[i] = result
+
+		detected := make(map[string]bool, len(result.Vulnerabilities))
+		for _, vuln := range result.Vulnerabilities {
+			detected[vuln.VulnerabilityID+"|"+vuln.PkgName+"|"+vuln.InstalledVersion] = true
+		}
+
+		var added []trivy.DetectedVulnerability
+		for _, pkg := range result.Packages {
+			for _, advisory := range x.internalAdvisories {
+				if !advisory.Affects(result.Type, &pkg) {
+					continue
+				}
+				key := advisory.ID + "|" + pkg.Name + "|" + pkg.Version
+				if detected[key] {
+					continue
+				}
+				detected[key] = true
+				added = append(added, advisory.NewDetectedVulnerability(&pkg))
+			}
+		}
+
+		if len(added) > 0 {
+			results[i].Vulnerabilities = append(result.Vulnerabilities[:len(result.Vulnerabilities):len(result.Vulnerabilities)], added...)
+			matched += len(added)
+		}
+	}
+
+	if matched > 0 {
+		logging.From(ctx).Info("matched internal advisories",
+			"artifact", report.ArtifactName,
+			"vulnerabilities", matched,
+		)
+	}
+
+	report.Results = results
+	return report
+}
@@ -0,0 +1,130 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+const testAdvisoryFeed = `{
+  "advisories": [
+    {
+      "id": "ACME-2024-0001",
+      "package": "github.com/acme/auth",
+      "type": "gomod",
+      "vulnerable_versions": ">= 1.0.0, < 1.4.2",
+      "fixed_version": "1.4.2",
+      "severity": "high",
+      "title": "Token validation bypass in acme/auth"
+    }
+  ]
+}`
+
+func TestLoadInternalAdvisories(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "feed.json")
+		gt.NoError(t, os.WriteFile(path, []byte(testAdvisoryFeed), 0600))
+
+		advisories, err := usecase.LoadInternalAdvisories(ctx, path)
+		gt.NoError(t, err)
+		gt.A(t, advisories).Length(1)
+		gt.V(t, advisories[0].ID).Equal("ACME-2024-0001")
+	})
+
+	t.Run("from URL", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testAdvisoryFeed))
+		}))
+		defer srv.Close()
+
+		advisories, err := usecase.LoadInternalAdvisories(ctx, srv.URL)
+		gt.NoError(t, err)
+		gt.A(t, advisories).Length(1)
+	})
+
+	t.Run("error status of URL", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		_, err := usecase.LoadInternalAdvisories(ctx, srv.URL)
+		gt.Error(t, err)
+	})
+
+	t.Run("invalid advisory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "feed.json")
+		gt.NoError(t, os.WriteFile(path, []byte(`{"advisories":[{"id":"ACME-2024-0001","package":"lib","vulnerable_versions":"<","severity":"HIGH"}]}`), 0600))
+
+		_, err := usecase.LoadInternalAdvisories(ctx, path)
+		gt.Error(t, err)
+	})
+}
+
+func TestInsertScanResultWithInternalAdvisories(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "feed.json")
+	gt.NoError(t, os.WriteFile(path, []byte(testAdvisoryFeed), 0600))
+	advisories, err := usecase.LoadInternalAdvisories(ctx, path)
+	gt.NoError(t, err)
+
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-repo",
+		Results: []trivy.Result{
+			{
+				Target: "go.mod",
+				Type:   "gomod",
+				Packages: []trivy.Package{
+					{Name: "github.com/acme/auth", Version: "v1.3.0"},
+					{Name: "github.com/acme/log", Version: "v1.3.0"},
+				},
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0", Vulnerability: trivy.Vulnerability{Severity: "MEDIUM"}},
+				},
+			},
+			{
+				Target:   "web/package-lock.json",
+				Type:     "npm",
+				Packages: []trivy.Package{{Name: "github.com/acme/auth", Version: "1.3.0"}},
+			},
+		},
+	}
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())), usecase.WithInternalAdvisories(advisories))
+	_, err = uc.InsertScanResult(ctx, model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}, report)
+	gt.NoError(t, err)
+
+	// The report of the caller is not modified
+	gt.A(t, report.Results[0].Vulnerabilities).Length(1)
+
+	findings, err := uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner"})
+	gt.NoError(t, err)
+	gt.A(t, findings).Length(2)
+	gt.V(t, findings[0].ID).Equal("ACME-2024-0001")
+	gt.V(t, findings[0].Target).Equal("go.mod")
+	gt.V(t, findings[0].PkgName).Equal("github.com/acme/auth")
+	gt.V(t, findings[0].FixedVersion).Equal("1.4.2")
+	gt.V(t, findings[0].Source).Equal("internal")
+	gt.V(t, findings[1].ID).Equal("CVE-2024-0001")
+	gt.V(t, findings[1].Source).Equal("")
+}
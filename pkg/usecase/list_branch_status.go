@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// ListBranchStatus returns the last scan and active finding counts of all scanned branches of the repository. The default branch comes first, and others are sorted by the last scan time in descending order.
+func (x *UseCase) ListBranchStatus(ctx context.Context, input *model.ListBranchStatusInput) (*model.RepositoryBranchStatus, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "branch status requires Firestore")
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+
+	branches, err := scanRepo.ListBranches(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list branches", goerr.V("repo_id", repoID))
+	}
+
+	result := &model.RepositoryBranchStatus{
+		Repository:    string(repoID),
+		DefaultBranch: string(repo.DefaultBranch),
+		Permalink:     x.permalinks.Repository(repoID),
+		Branches:      make([]*model.BranchStatus, 0, len(branches)),
+	}
+	for _, branch := range branches {
+		status, err := x.newBranchStatus(ctx, scanRepo, repo, branch)
+		if err != nil {
+			return nil, err
+		}
+		result.Branches = append(result.Branches, status)
+	}
+
+	sort.SliceStable(result.Branches, func(i, j int) bool {
+		a, b := result.Branches[i], result.Branches[j]
+		if a.Default != b.Default {
+			return a.Default
+		}
+		if !a.LastScanAt.Equal(b.LastScanAt) {
+			return a.LastScanAt.After(b.LastScanAt)
+		}
+		return a.Name < b.Name
+	})
+
+	return result, nil
+}
+
+func (x *UseCase) newBranchStatus(ctx context.Context, scanRepo interfaces.ScanRepository, repo *model.Repository, branch *model.Branch) (*model.BranchStatus, error) {
+	findings, err := branchFindings(ctx, scanRepo, repo.ID, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.BranchStatus{
+		Name:       string(branch.Name),
+		Default:    repo.DefaultBranch != "" && branch.Name == repo.DefaultBranch,
+		LastScanID: string(branch.LastScanID),
+		LastScanAt: branch.LastScanAt,
+		CommitSHA:  string(branch.LastCommitSHA),
+		Status:     branch.Status,
+		Findings:   findings,
+		Permalink:  x.permalinks.Branch(repo.ID, branch.Name),
+	}
+	if branch.Status == types.ScanStatusFailure {
+		status.Error = branch.LastError
+		if !branch.LastErrorAt.IsZero() {
+			errorAt := branch.LastErrorAt
+			status.ErrorAt = &errorAt
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestListBranchStatus(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := memory.New()
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:            repoID,
+		Owner:         "test-owner",
+		Name:          "test-repo",
+		DefaultBranch: "main",
+	}))
+	for _, branch := range []*model.Branch{
+		{Name: "feature-a", LastScanID: "scan-a", LastScanAt: now.Add(-2 * time.Hour), LastCommitSHA: "aaaa", Status: types.ScanStatusSuccess},
+		{Name: "main", LastScanID: "scan-main", LastScanAt: now.Add(-3 * time.Hour), LastCommitSHA: "cccc", Status: types.ScanStatusSuccess},
+		{Name: "feature-b", LastScanID: "scan-b", LastScanAt: now.Add(-1 * time.Hour), LastCommitSHA: "bbbb", Status: types.ScanStatusFailure},
+	} {
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, branch))
+	}
+	target := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", target))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", target.ID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", Severity: "CRITICAL", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0002", Severity: "HIGH", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0003", Severity: "HIGH", Status: types.VulnStatusFixed},
+		{ID: "AWS-0001", Type: types.FindingTypeMisconfiguration, Severity: "LOW", Status: types.VulnStatusActive},
+	}))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+	t.Run("lists branches with default branch first", func(t *testing.T) {
+		result, err := uc.ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: "test-owner", Repo: "test-repo"})
+		gt.NoError(t, err)
+		gt.V(t, result.Repository).Equal("test-owner/test-repo")
+		gt.V(t, result.DefaultBranch).Equal("main")
+		gt.A(t, result.Branches).Length(3)
+
+		main := result.Branches[0]
+		gt.V(t, main.Name).Equal("main")
+		gt.True(t, main.Default)
+		gt.V(t, main.LastScanID).Equal("scan-main")
+		gt.V(t, main.CommitSHA).Equal("cccc")
+		gt.V(t, main.Findings.Vulnerabilities.Critical).Equal(1)
+		gt.V(t, main.Findings.Vulnerabilities.High).Equal(1)
+		gt.V(t, main.Findings.Vulnerabilities.Total).Equal(2)
+		gt.V(t, main.Findings.Misconfigurations).Equal(1)
+
+		gt.V(t, result.Branches[1].Name).Equal("feature-b")
+		gt.V(t, result.Branches[1].Status).Equal(types.ScanStatusFailure)
+		gt.V(t, result.Branches[1].Findings.Vulnerabilities.Total).Equal(0)
+		gt.V(t, result.Branches[2].Name).Equal("feature-a")
+	})
+
+	t.Run("unknown repository is not found", func(t *testing.T) {
+		_, err := uc.ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: "test-owner", Repo: "unknown"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		_, err := usecase.New(infra.New()).ListBranchStatus(ctx, &model.ListBranchStatusInput{Owner: "test-owner", Repo: "test-repo"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+)
+
+// ListFindings returns findings matching the filters, from the Trivy report of the input if given or from Firestore otherwise. Findings are ordered by repository, branch, severity (highest first), target and ID.
+func (x *UseCase) ListFindings(ctx context.Context, input *model.ListFindingsInput) ([]*model.Finding, error) {
+	statuses := input.Statuses
+	if len(statuses) == 0 {
+		statuses = []types.VulnStatus{types.VulnStatusActive}
+	}
+	match := func(vuln *model.Vulnerability) bool {
+		if input.MinSeverity != "" && !types.Severity(vuln.Severity).AtLeast(input.MinSeverity) {
+			return false
+		}
+		for _, status := range statuses {
+			if vuln.Status == status {
+				return true
+			}
+		}
+		return false
+	}
+
+	var findings []*model.Finding
+	if input.Report != nil {
+		if input.Tag != "" || input.Team != "" {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "tag and team filters are not available for a Trivy report")
+		}
+		for i := range input.Report.Results {
+			result := &input.Report.Results[i]
+			for _, vuln := range newFindings(result, nil) {
+				if match(vuln) {
+					findings = append(findings, model.NewFinding("", "", result.Target, vuln))
+				}
+			}
+		}
+	} else {
+		stored, err := x.listStoredFindings(ctx, input, match)
+		if err != nil {
+			return nil, err
+		}
+		findings = stored
+	}
+
+	sortFindings(findings)
+
+	return findings, nil
+}
+
+// sortFindings orders findings by repository, branch, severity (highest first), target and ID
+func sortFindings(findings []*model.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		if a.Branch != b.Branch {
+			return a.Branch < b.Branch
+		}
+		if ra, rb := types.Severity(a.Severity).Rank(), types.Severity(b.Severity).Rank(); ra != rb {
+			return ra > rb
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.ID < b.ID
+	})
+}
+
+func (x *UseCase) listStoredFindings(ctx context.Context, input *model.ListFindingsInput, match func(*model.Vulnerability) bool) ([]*model.Finding, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "listing stored findings requires Firestore")
+	}
+
+	var repos []*model.Repository
+	if input.Repo != "" {
+		repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+		repo, err := scanRepo.GetRepository(ctx, repoID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+		}
+		repos = append(repos, repo)
+	} else {
+		ownerRepos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+		}
+		repos = ownerRepos
+	}
+
+	var findings []*model.Finding
+	for _, repo := range repos {
+		if !repo.MatchMetadata(input.Tag, input.Team) {
+			continue
+		}
+
+		branchName := input.Branch
+		if branchName == "" {
+			branchName = repo.DefaultBranch
+		}
+		if branchName == "" {
+			continue
+		}
+
+		targets, err := scanRepo.ListTargets(ctx, repo.ID, branchName)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repo.ID), goerr.V("branch", branchName))
+		}
+
+		repoFindings, err := x.listTargetFindings(ctx, scanRepo, repo, branchName, targets, match)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, repoFindings...)
+	}
+
+	return findings, nil
+}
+
+// listTargetFindings returns findings of the targets matching the filter, ordered by sortFindings
+func (x *UseCase) listTargetFindings(ctx context.Context, scanRepo interfaces.ScanRepository, repo *model.Repository, branchName types.BranchName, targets []*model.Target, match func(*model.Vulnerability) bool) ([]*model.Finding, error) {
+	var findings []*model.Finding
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repo.ID, branchName, target.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("repo_id", repo.ID), goerr.V("target", target.Target))
+		}
+		for _, vuln := range vulns {
+			if !match(vuln) {
+				continue
+			}
+			finding := model.NewFinding(string(repo.ID), string(branchName), target.Target, vuln)
+			finding.Team = repo.Team
+			finding.Tags = repo.Tags
+			finding.Permalink = x.permalinks.Finding(repo.ID, branchName, vuln.ID)
+			findings = append(findings, finding)
+		}
+	}
+
+	sortFindings(findings)
+	return findings, nil
+}
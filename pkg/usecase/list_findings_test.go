@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestListFindings(t *testing.T) {
+	ctx := context.Background()
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-repo",
+		Results: []trivy.Result{{
+			Target: "go.mod",
+			Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a", Vulnerability: trivy.Vulnerability{Severity: "MEDIUM"}},
+				{VulnerabilityID: "CVE-2024-0002", PkgName: "pkg-b", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+				{VulnerabilityID: "CVE-2024-0003", PkgName: "pkg-c", Vulnerability: trivy.Vulnerability{Severity: "LOW"}},
+			},
+		}},
+	}
+
+	t.Run("local report", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		findings, err := uc.ListFindings(ctx, &model.ListFindingsInput{
+			Report:      &report,
+			MinSeverity: types.SeverityMedium,
+		})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(2)
+		gt.V(t, findings[0].ID).Equal("CVE-2024-0002")
+		gt.V(t, findings[1].ID).Equal("CVE-2024-0001")
+	})
+
+	t.Run("stored findings filtered by status", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())))
+		_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "0000000000000000000000000000000000000000",
+			},
+			DefaultBranch: "main",
+		}, report)
+		gt.NoError(t, err)
+
+		_, err = uc.AcknowledgeVulnerability(ctx, &model.AcknowledgeVulnerabilityInput{
+			Owner: "test-owner", Repo: "test-repo", VulnID: "CVE-2024-0002", By: "alice", Comment: "accepted",
+		})
+		gt.NoError(t, err)
+
+		findings, err := uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner"})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(2)
+		gt.V(t, findings[0].Repository).Equal("test-owner/test-repo")
+		gt.V(t, findings[0].Branch).Equal("main")
+
+		findings, err = uc.ListFindings(ctx, &model.ListFindingsInput{
+			Owner:    "test-owner",
+			Repo:     "test-repo",
+			Statuses: []types.VulnStatus{types.VulnStatusAcknowledged},
+		})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(1)
+		gt.V(t, findings[0].ID).Equal("CVE-2024-0002")
+	})
+
+	t.Run("stored findings filtered by team and tag", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())))
+		for _, repo := range []string{"repo-a", "repo-b"} {
+			_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: repo},
+					Branch:     "main",
+					CommitID:   "0000000000000000000000000000000000000000",
+				},
+				DefaultBranch: "main",
+			}, report)
+			gt.NoError(t, err)
+		}
+
+		team, tags := "backend", []string{"tier-1", "payments"}
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner", Repo: "repo-a", Team: &team, Tags: &tags,
+		})
+		gt.NoError(t, err)
+
+		findings, err := uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner", Team: "Backend"})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(3)
+		for _, f := range findings {
+			gt.V(t, f.Repository).Equal("test-owner/repo-a")
+			gt.V(t, f.Team).Equal("backend")
+			gt.V(t, f.Tags).Equal([]string{"payments", "tier-1"})
+		}
+
+		findings, err = uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner", Tag: "tier-2"})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(0)
+
+		findings, err = uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner"})
+		gt.NoError(t, err)
+		gt.A(t, findings).Length(6)
+	})
+
+	t.Run("team filter is not available for local report", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.ListFindings(ctx, &model.ListFindingsInput{Report: &report, Team: "backend"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
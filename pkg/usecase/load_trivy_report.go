@@ -3,27 +3,41 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/safe"
 )
 
-// LoadTrivyReport loads a Trivy report from an io.Reader and validates it
+// LoadTrivyReport loads a Trivy report from an io.Reader and validates it. Reports of schema versions supported by trivy.ParseReport are normalized into the same model.
 func LoadTrivyReport(ctx context.Context, r io.Reader) (*trivy.Report, error) {
-	var report trivy.Report
-	if err := json.NewDecoder(r).Decode(&report); err != nil {
-		return nil, goerr.Wrap(err, "failed to decode trivy result")
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read trivy result")
+	}
+
+	report, err := trivy.ParseReport(raw)
+	if errors.Is(err, types.ErrUnsupportedSchemaVersion) {
+		return nil, goerr.Wrap(err, "unsupported trivy result, Octovy must be updated to read it or Trivy must be v0.20 or later")
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to decode trivy result, Trivy must be run with JSON format (--format json)")
 	}
 
 	if err := report.Validate(); err != nil {
+		if isSARIF(raw) {
+			return nil, goerr.Wrap(types.ErrValidationFailed, "SARIF is not supported, Trivy must be run with JSON format (--format json)")
+		}
 		return nil, goerr.Wrap(err, "invalid trivy report")
 	}
 
-	return &report, nil
+	return report, nil
 }
 
 // LoadTrivyReportFromFile loads a Trivy report from a file and validates it
@@ -36,3 +50,15 @@ func LoadTrivyReportFromFile(ctx context.Context, filePath string) (*trivy.Repor
 
 	return LoadTrivyReport(ctx, fd)
 }
+
+// isSARIF reports whether raw is a SARIF log, which is a common output format of trivy-action
+func isSARIF(raw []byte) bool {
+	var log struct {
+		Version string            `json:"version"`
+		Runs    []json.RawMessage `json:"runs"`
+	}
+	if err := json.Unmarshal(raw, &log); err != nil {
+		return false
+	}
+	return strings.HasPrefix(log.Version, "2.") && log.Runs != nil
+}
@@ -2,6 +2,7 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/usecase"
 )
 
@@ -57,6 +59,13 @@ func TestLoadTrivyReport(t *testing.T) {
 		gt.Error(t, err)
 	})
 
+	t.Run("unsupported schema version", func(t *testing.T) {
+		_, err := usecase.LoadTrivyReport(ctx, strings.NewReader(`{"SchemaVersion": 99, "ArtifactName": "."}`))
+
+		gt.True(t, errors.Is(err, types.ErrUnsupportedSchemaVersion))
+		gt.S(t, err.Error()).Contains("unsupported trivy result")
+	})
+
 	t.Run("result with empty target", func(t *testing.T) {
 		emptyTargetJSON := `{
   "SchemaVersion": 2,
@@ -102,6 +111,25 @@ func TestLoadTrivyReport(t *testing.T) {
 		ge := goerr.Unwrap(err)
 		gt.V(t, ge.Values()["index"]).Equal(1)
 	})
+
+	t.Run("SARIF output of trivy-action", func(t *testing.T) {
+		sarifJSON := `{
+  "version": "2.1.0",
+  "$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/main/sarif-2.1/schema/sarif-schema-2.1.0.json",
+  "runs": [{"tool": {"driver": {"name": "Trivy"}}, "results": []}]
+}`
+		_, err := usecase.LoadTrivyReport(ctx, strings.NewReader(sarifJSON))
+
+		gt.Error(t, err)
+		gt.S(t, err.Error()).Contains("SARIF is not supported")
+	})
+
+	t.Run("table output", func(t *testing.T) {
+		_, err := usecase.LoadTrivyReport(ctx, strings.NewReader("go.mod (gomod)\n=============\nTotal: 0\n"))
+
+		gt.Error(t, err)
+		gt.S(t, err.Error()).Contains("--format json")
+	})
 }
 
 func TestLoadTrivyReportFromFile(t *testing.T) {
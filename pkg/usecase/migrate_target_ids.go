@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// MigrateTargetIDs moves targets of all branches of the owner's repositories from LegacyTargetID to their CanonicalID. Scans migrate targets of the scanned branch as well, so this is needed only for branches which are not scanned anymore but queried, e.g. by permalinks. It can be run again after a partial failure.
+func (x *UseCase) MigrateTargetIDs(ctx context.Context, input *model.MigrateTargetIDsInput) (*model.MigrateTargetIDsResult, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "target ID migration requires Firestore")
+	}
+	if input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "owner is required")
+	}
+
+	repos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+	}
+
+	logger := logging.From(ctx)
+	result := &model.MigrateTargetIDsResult{}
+
+	task := progress.From(ctx).Start("Migrating target IDs", len(repos))
+	defer task.Done()
+	for _, repo := range repos {
+		task.Describe(string(repo.ID))
+		branches, err := scanRepo.ListBranches(ctx, repo.ID)
+		if err != nil {
+			task.Fail()
+			return result, goerr.Wrap(err, "failed to list branches", goerr.V("repo_id", repo.ID))
+		}
+
+		for _, branch := range branches {
+			migrated, err := migrateTargetIDs(ctx, scanRepo, repo.ID, branch.Name)
+			if err != nil {
+				task.Fail()
+				return result, err
+			}
+			result.Branches++
+			result.Targets += migrated
+		}
+		task.Succeed()
+		result.Repositories++
+	}
+
+	progress.From(ctx).Set("migrated_targets", result.Targets)
+	logger.Info("Completed target ID migration",
+		slog.String("owner", input.Owner),
+		slog.Int("repositories", result.Repositories),
+		slog.Int("branches", result.Branches),
+		slog.Int("targets", result.Targets),
+	)
+
+	return result, nil
+}
+
+// migrateTargetIDs moves targets of the branch stored with LegacyTargetID to their CanonicalID with findings, status history and packages, and returns the number of moved targets. A legacy target is left as it is if a target with the canonical ID already exists, in which case it is reconciled as removed by the next scan.
+func migrateTargetIDs(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName) (int, error) {
+	targets, err := repo.ListTargets(ctx, repoID, branchName)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+
+	stored := make(map[types.TargetID]bool, len(targets))
+	for _, target := range targets {
+		stored[target.ID] = true
+	}
+
+	var migrated int
+	for _, target := range targets {
+		canonicalID := target.CanonicalID()
+		if target.ID == canonicalID || target.ID != model.LegacyTargetID(target.Target) || stored[canonicalID] {
+			continue
+		}
+
+		legacyID := target.ID
+		moved := *target
+		moved.ID = canonicalID
+		if err := repo.MoveTarget(ctx, repoID, branchName, legacyID, &moved); err != nil {
+			return migrated, goerr.Wrap(err, "failed to migrate target ID",
+				goerr.V("repo_id", repoID),
+				goerr.V("branch", branchName),
+				goerr.V("target", target.Target),
+			)
+		}
+		stored[canonicalID] = true
+		migrated++
+
+		logging.From(ctx).Info("migrated target ID",
+			"repo_id", repoID,
+			"branch", branchName,
+			"target", target.Target,
+			"from", legacyID,
+			"to", canonicalID,
+		)
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestTargetIDsOfSamePath(t *testing.T) {
+	memRepo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+	repoID := types.GitHubRepoID("test-owner/image-repo")
+
+	const image = "example/app:latest (alpine 3.18.0)"
+	gt.R1(uc.InsertScanResult(context.Background(), model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "image-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}, trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "example/app:latest",
+		Results: []trivy.Result{
+			{Target: image, Class: trivy.ClassOSPkg, Type: "alpine", Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "openssl", InstalledVersion: "3.1.0"},
+			}},
+			{Target: image, Class: trivy.ClassLangPkg, Type: "gobinary", Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0002", PkgName: "golang.org/x/net", InstalledVersion: "0.1.0"},
+			}},
+		},
+	})).NoError(t)
+
+	targets := gt.R1(memRepo.ListTargets(context.Background(), repoID, "main")).NoError(t)
+	gt.A(t, targets).Length(2)
+	for _, target := range targets {
+		gt.V(t, target.ID).Equal(target.CanonicalID())
+		vulns := gt.R1(memRepo.ListVulnerabilities(context.Background(), repoID, "main", target.ID)).NoError(t)
+		gt.A(t, vulns).Length(1)
+	}
+}
+
+func TestMigrateTargetIDs(t *testing.T) {
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	legacyID := model.LegacyTargetID("go.mod")
+	canonicalID := model.ToTargetID("go.mod", "lang-pkgs", "gomod")
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// setup stores an acknowledged finding under the legacy ID as scans before the migration did
+	setup := func(t *testing.T) interfaces.ScanRepository {
+		ctx := context.Background()
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "test-owner", Name: "test-repo", DefaultBranch: "main"}))
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: "main", LastScanAt: t0}))
+		gt.NoError(t, memRepo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: legacyID, Target: "go.mod", Class: "lang-pkgs", Type: "gomod", CreatedAt: t0}))
+		gt.NoError(t, memRepo.BatchCreateVulnerabilities(ctx, repoID, "main", legacyID, []*model.Vulnerability{
+			{ID: "CVE-2024-0001", PkgName: "example.com/pkg", InstalledVersion: "1.0.0", Severity: "HIGH", Status: types.VulnStatusActive, CreatedAt: t0},
+		}))
+		gt.NoError(t, memRepo.BatchUpdateVulnerabilityStatus(ctx, repoID, "main", legacyID, []*model.VulnStatusChange{
+			{VulnID: "CVE-2024-0001", From: types.VulnStatusActive, To: types.VulnStatusAcknowledged, Actor: "alice", Timestamp: t0},
+		}))
+		return memRepo
+	}
+	assertMigrated := func(t *testing.T, memRepo interfaces.ScanRepository) {
+		_, err := memRepo.GetTarget(context.Background(), repoID, "main", legacyID)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		vulns := gt.R1(memRepo.ListVulnerabilities(context.Background(), repoID, "main", canonicalID)).NoError(t)
+		gt.A(t, vulns).Length(1).At(0, func(t testing.TB, v *model.Vulnerability) {
+			gt.V(t, v.Status).Equal(types.VulnStatusAcknowledged)
+			gt.V(t, v.CreatedAt).Equal(t0)
+		})
+	}
+
+	t.Run("scan migrates targets of the branch", func(t *testing.T) {
+		memRepo := setup(t)
+		uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return t0.Add(time.Hour) })
+		gt.R1(uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "1111111111111111111111111111111111111111",
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-repo",
+			Results: []trivy.Result{{Target: "go.mod", Class: trivy.ClassLangPkg, Type: "gomod", Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "example.com/pkg", InstalledVersion: "1.0.0", Vulnerability: trivy.Vulnerability{Severity: "HIGH"}},
+			}}},
+		})).NoError(t)
+
+		assertMigrated(t, memRepo)
+		targets := gt.R1(memRepo.ListTargets(context.Background(), repoID, "main")).NoError(t)
+		gt.A(t, targets).Length(1)
+	})
+
+	t.Run("admin migration moves targets of all branches", func(t *testing.T) {
+		memRepo := setup(t)
+		uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+		result := gt.R1(uc.MigrateTargetIDs(context.Background(), &model.MigrateTargetIDsInput{Owner: "test-owner"})).NoError(t)
+		gt.V(t, result).Equal(&model.MigrateTargetIDsResult{Repositories: 1, Branches: 1, Targets: 1})
+		assertMigrated(t, memRepo)
+
+		// Migrated targets are not moved again
+		result = gt.R1(uc.MigrateTargetIDs(context.Background(), &model.MigrateTargetIDsInput{Owner: "test-owner"})).NoError(t)
+		gt.V(t, result.Targets).Equal(0)
+	})
+}
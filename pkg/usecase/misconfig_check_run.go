@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// reportMisconfigCheckRun creates a check run of the head commit of the pull request with failed misconfiguration checks as annotations, so that they are shown inline in the diff. A failure is logged and does not fail the scan.
+func (x *UseCase) reportMisconfigCheckRun(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report) {
+	gh := x.clients.GitHubApp()
+	if gh == nil || meta.InstallationID == 0 {
+		return
+	}
+
+	logger := logging.From(ctx)
+	check := model.NewMisconfigCheck(report)
+	if err := gh.CreateCheckRun(ctx, &interfaces.CreateCheckRunInput{
+		Owner:       meta.Owner,
+		Repo:        meta.RepoName,
+		HeadSHA:     meta.CommitID,
+		Name:        model.MisconfigCheckRunName,
+		Conclusion:  check.Conclusion(),
+		Title:       check.Title(),
+		Summary:     check.Summary(),
+		Annotations: check.Annotations,
+		InstallID:   types.GitHubAppInstallID(meta.InstallationID),
+	}); err != nil {
+		logger.Warn("failed to create check run of misconfigurations", slog.Any("error", err))
+		return
+	}
+	logger.Info("created check run of misconfigurations",
+		slog.Int("pull_request", meta.PullRequest.Number),
+		slog.Int("misconfigurations", check.Total),
+		slog.Int("annotations", len(check.Annotations)),
+	)
+}
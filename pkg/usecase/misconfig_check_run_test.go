@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestMisconfigCheckRun(t *testing.T) {
+	const report = `{"SchemaVersion":2,"ArtifactName":"test","Results":[
+		{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod"},
+		{"Target":"deploy/main.tf","Class":"config","Type":"terraform","Misconfigurations":[
+			{"ID":"AVD-AWS-0086","AVDID":"AVD-AWS-0086","Title":"S3 Access block should block public ACL","Message":"No public access block so not blocking public acls","Resolution":"Enable blocking any PUT calls with a public ACL specified","Severity":"HIGH","Status":"FAIL","CauseMetadata":{"Resource":"aws_s3_bucket.example","StartLine":3,"EndLine":5}},
+			{"ID":"AVD-AWS-0087","AVDID":"AVD-AWS-0087","Title":"S3 Access block should block public policy","Severity":"HIGH","Status":"PASS"}
+		]}
+	]}`
+
+	run := func(t *testing.T, meta model.GitHubMetadata, options ...usecase.Option) []*interfaces.CreateCheckRunInput {
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(report), 0644)
+				}
+			}
+			return nil
+		}
+
+		var checkRuns []*interfaces.CreateCheckRunInput
+		mockGH := &mock.GitHubAppMock{
+			CreateCheckRunFunc: func(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+				checkRuns = append(checkRuns, input)
+				return nil
+			},
+		}
+
+		uc := usecase.New(infra.New(
+			infra.WithTrivy(mockTrivy),
+			infra.WithGitHubApp(mockGH),
+			infra.WithScanRepository(memory.New()),
+		), options...)
+		gt.NoError(t, uc.ScanAndInsert(context.Background(), t.TempDir(), meta))
+		return checkRuns
+	}
+
+	newMeta := func(pr *model.GitHubPullRequest) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "feature",
+				CommitID:   "1111111111111111111111111111111111111111",
+			},
+			InstallationID: 12345,
+			PullRequest:    pr,
+		}
+	}
+
+	t.Run("misconfigurations of pull request are annotated", func(t *testing.T) {
+		checkRuns := run(t, newMeta(&model.GitHubPullRequest{Number: 42, BaseBranch: "main"}), usecase.WithMisconfigCheckRun())
+
+		gt.A(t, checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Owner).Equal("test-owner")
+			gt.V(t, v.Repo).Equal("test-repo")
+			gt.V(t, v.HeadSHA).Equal("1111111111111111111111111111111111111111")
+			gt.V(t, v.Name).Equal(model.MisconfigCheckRunName)
+			gt.V(t, v.Conclusion).Equal("neutral")
+			gt.V(t, v.Title).Equal("1 misconfiguration found")
+			gt.A(t, v.Annotations).Length(1).At(0, func(t testing.TB, a *model.CheckAnnotation) {
+				gt.V(t, a.Path).Equal("deploy/main.tf")
+				gt.V(t, a.StartLine).Equal(3)
+				gt.V(t, a.EndLine).Equal(5)
+				gt.V(t, a.Level).Equal(model.CheckAnnotationFailure)
+				gt.S(t, a.Title).Contains("AVD-AWS-0086")
+			})
+		})
+	})
+
+	t.Run("push is not annotated", func(t *testing.T) {
+		gt.A(t, run(t, newMeta(nil), usecase.WithMisconfigCheckRun())).Length(0)
+	})
+
+	t.Run("check run is disabled by default", func(t *testing.T) {
+		gt.A(t, run(t, newMeta(&model.GitHubPullRequest{Number: 42, BaseBranch: "main"}))).Length(0)
+	})
+}
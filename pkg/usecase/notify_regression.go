@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// getPreviousBranch returns the branch before it is updated by the current scan, or nil if the branch has never been scanned.
+func getPreviousBranch(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID, branchName types.BranchName) (*model.Branch, error) {
+	branch, err := repo.GetBranch(ctx, repoID, branchName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get branch", goerr.V("repo_id", repoID), goerr.V("branch", branchName))
+	}
+	return branch, nil
+}
+
+// getStoredRepository returns the repository registered in ScanRepository, or nil if it has never been registered.
+func getStoredRepository(ctx context.Context, repo interfaces.ScanRepository, repoID types.GitHubRepoID) (*model.Repository, error) {
+	stored, err := repo.GetRepository(ctx, repoID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+	return stored, nil
+}
+
+// notifyRegression reports vulnerabilities introduced into the default branch with the pull requests of the commit. It is emitted as a dedicated log event (event=regression) so that log based alerting can route it separately from routine new CVE detections. Failure of resolving pull requests does not fail the scan.
+func (x *UseCase) notifyRegression(ctx context.Context, meta model.GitHubMetadata, regression *model.DefaultBranchRegression) {
+	logger := logging.From(ctx)
+
+	if gh := x.clients.GitHubApp(); gh != nil && meta.InstallationID != 0 {
+		prs, err := gh.ListPullRequestsWithCommit(ctx, &interfaces.ListPullRequestsWithCommitInput{
+			Owner:     meta.Owner,
+			Repo:      meta.RepoName,
+			CommitID:  meta.CommitID,
+			InstallID: types.GitHubAppInstallID(meta.InstallationID),
+		})
+		if err != nil {
+			logger.Warn("failed to resolve pull requests of regression commit", slog.Any("error", err))
+		} else {
+			regression.PullRequests = prs
+		}
+	}
+
+	regression.Permalink = x.permalinks.Branch(regression.RepoID, regression.Branch)
+	for _, v := range regression.Vulnerabilities {
+		v.Permalink = x.permalinks.Finding(regression.RepoID, regression.Branch, v.ID)
+	}
+
+	logger.Warn("Default branch regression detected",
+		slog.String("event", "regression"),
+		slog.Int("count", len(regression.Vulnerabilities)),
+		slog.Any("regression", regression),
+	)
+}
@@ -0,0 +1,169 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestDefaultBranchRegression(t *testing.T) {
+	const (
+		commit1 = "1111111111111111111111111111111111111111"
+		commit2 = "2222222222222222222222222222222222222222"
+	)
+
+	newMeta := func(branch, commit string) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo", RepoID: 1},
+				Branch:     branch,
+				CommitID:   commit,
+			},
+			DefaultBranch:  "main",
+			InstallationID: 10,
+		}
+	}
+	newReport := func(vulns ...trivy.DetectedVulnerability) trivy.Report {
+		return trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-repo",
+			Results: []trivy.Result{
+				{Target: "go.mod", Vulnerabilities: vulns},
+			},
+		}
+	}
+	vuln := func(id, pkg, version string) trivy.DetectedVulnerability {
+		return trivy.DetectedVulnerability{
+			VulnerabilityID:  id,
+			PkgName:          pkg,
+			InstalledVersion: version,
+			Vulnerability:    trivy.Vulnerability{Severity: "HIGH"},
+		}
+	}
+
+	setup := func(t *testing.T) (*usecase.UseCase, *mock.GitHubAppMock, context.Context, *bytes.Buffer) {
+		mockGH := &mock.GitHubAppMock{
+			ListPullRequestsWithCommitFunc: func(ctx context.Context, input *interfaces.ListPullRequestsWithCommitInput) ([]*model.MergedPullRequest, error) {
+				return []*model.MergedPullRequest{
+					{Number: 42, URL: "https://github.com/test-owner/test-repo/pull/42", Author: "alice"},
+				}, nil
+			},
+		}
+		uc := usecase.New(infra.New(
+			infra.WithScanRepository(memory.New()),
+			infra.WithGitHubApp(mockGH),
+		))
+
+		var buf bytes.Buffer
+		ctx := logging.With(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		// Baseline scan of the default branch never reports a regression
+		_, err := uc.InsertScanResult(ctx, newMeta("main", commit1), newReport(
+			vuln("CVE-2024-0001", "pkg-a", "1.0.0"),
+		))
+		gt.NoError(t, err)
+		gt.A(t, mockGH.ListPullRequestsWithCommitCalls()).Length(0)
+		gt.S(t, buf.String()).NotContains("regression")
+
+		return uc, mockGH, ctx, &buf
+	}
+
+	t.Run("new vulnerable package merged into default branch", func(t *testing.T) {
+		uc, mockGH, ctx, buf := setup(t)
+
+		_, err := uc.InsertScanResult(ctx, newMeta("main", commit2), newReport(
+			vuln("CVE-2024-0001", "pkg-a", "1.0.0"),
+			vuln("CVE-2024-0002", "pkg-b", "2.0.0"),
+		))
+		gt.NoError(t, err)
+
+		calls := mockGH.ListPullRequestsWithCommitCalls()
+		gt.A(t, calls).Length(1)
+		gt.V(t, calls[0].Input.CommitID).Equal(commit2)
+
+		gt.S(t, buf.String()).Contains("Default branch regression detected")
+		gt.S(t, buf.String()).Contains("CVE-2024-0002 pkg-b@2.0.0")
+		gt.S(t, buf.String()).Contains("#42 https://github.com/test-owner/test-repo/pull/42")
+		gt.S(t, buf.String()).NotContains("CVE-2024-0001 pkg-a")
+	})
+
+	t.Run("regression has team and tags of repository", func(t *testing.T) {
+		uc, _, ctx, buf := setup(t)
+
+		team, tags := "payments-team", []string{"tier-1"}
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner", Repo: "test-repo", Team: &team, Tags: &tags,
+		})
+		gt.NoError(t, err)
+
+		_, err = uc.InsertScanResult(ctx, newMeta("main", commit2), newReport(
+			vuln("CVE-2024-0002", "pkg-b", "2.0.0"),
+		))
+		gt.NoError(t, err)
+		gt.S(t, buf.String()).Contains(`"team":"payments-team"`)
+		gt.S(t, buf.String()).Contains(`"tags":["tier-1"]`)
+	})
+
+	t.Run("new CVE of an already present package is not a regression", func(t *testing.T) {
+		uc, mockGH, ctx, buf := setup(t)
+
+		_, err := uc.InsertScanResult(ctx, newMeta("main", commit2), newReport(
+			vuln("CVE-2024-0001", "pkg-a", "1.0.0"),
+			vuln("CVE-2024-0003", "pkg-a", "1.0.0"),
+		))
+		gt.NoError(t, err)
+		gt.A(t, mockGH.ListPullRequestsWithCommitCalls()).Length(0)
+		gt.S(t, buf.String()).NotContains("regression")
+	})
+
+	t.Run("rescan of the same commit is not a regression", func(t *testing.T) {
+		uc, mockGH, ctx, buf := setup(t)
+
+		_, err := uc.InsertScanResult(ctx, newMeta("main", commit1), newReport(
+			vuln("CVE-2024-0001", "pkg-a", "1.0.0"),
+			vuln("CVE-2024-0002", "pkg-b", "2.0.0"),
+		))
+		gt.NoError(t, err)
+		gt.A(t, mockGH.ListPullRequestsWithCommitCalls()).Length(0)
+		gt.S(t, buf.String()).NotContains("regression")
+	})
+
+	t.Run("non-default branch is not checked", func(t *testing.T) {
+		uc, mockGH, ctx, buf := setup(t)
+
+		for _, commit := range []string{commit1, commit2} {
+			_, err := uc.InsertScanResult(ctx, newMeta("feature", commit), newReport(
+				vuln("CVE-2024-0002", "pkg-b", "2.0.0"),
+			))
+			gt.NoError(t, err)
+		}
+		gt.A(t, mockGH.ListPullRequestsWithCommitCalls()).Length(0)
+		gt.S(t, buf.String()).NotContains("regression")
+	})
+
+	t.Run("re-introduced vulnerability after fix is a regression", func(t *testing.T) {
+		uc, _, ctx, buf := setup(t)
+
+		const commit3 = "3333333333333333333333333333333333333333"
+		_, err := uc.InsertScanResult(ctx, newMeta("main", commit2), newReport())
+		gt.NoError(t, err)
+		gt.S(t, buf.String()).NotContains("regression")
+
+		_, err = uc.InsertScanResult(ctx, newMeta("main", commit3), newReport(
+			vuln("CVE-2024-0001", "pkg-a", "1.0.0"),
+		))
+		gt.NoError(t, err)
+		gt.S(t, buf.String()).Contains("CVE-2024-0001 pkg-a@1.0.0")
+	})
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// GetOwnerScanRun returns the result of a scan of all repositories of an owner. It returns repository.ErrNotFound if the run does not exist or has expired.
+func (x *UseCase) GetOwnerScanRun(ctx context.Context, runID types.OwnerScanRunID) (*model.OwnerScanRun, error) {
+	repo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := repo.GetOwnerScanRun(ctx, runID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get owner scan run", goerr.V("run_id", runID))
+	}
+	return run, nil
+}
+
+func newOwnerScanRun(ctx context.Context, owner, source string, trigger types.ScanTrigger) *model.OwnerScanRun {
+	requestID, _ := logging.CtxRequestID(ctx)
+	return model.NewOwnerScanRun(owner, source, trigger, requestID, logging.CtxTime(ctx).UTC())
+}
+
+// scanOwnerRepo scans a repository of an owner-wide scan. A failure of the scan is recorded in the result instead of being returned, so that the other repositories are still scanned.
+func (x *UseCase) scanOwnerRepo(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) *model.OwnerScanRepoResult {
+	startedAt := logging.CtxTime(ctx)
+	result := &model.OwnerScanRepoResult{
+		Owner:  input.Owner,
+		Repo:   input.Repo,
+		Branch: input.Branch,
+	}
+
+	scanInput, err := x.PrepareScanGitHubRepo(ctx, input)
+	var scanID types.ScanID
+	if err == nil {
+		result.CommitID = scanInput.CommitID
+		scanID, err = x.runScanGitHubRepo(ctx, scanInput)
+	}
+	result.DurationMS = logging.CtxTime(ctx).Sub(startedAt).Milliseconds()
+
+	if err != nil {
+		result.Status = types.ScanRunFailed
+		result.Error = summarizeScanError(err)
+		return result
+	}
+
+	result.Status = types.ScanRunSucceeded
+	result.ScanID = scanID
+	result.Skipped = scanID == ""
+	result.Findings = x.branchFindings(ctx, types.NewGitHubRepoID(input.Owner, input.Repo), types.BranchName(input.Branch))
+	return result
+}
+
+// branchFindings returns active findings of the branch, or nil if they are unknown. A failure of reading is logged because the scan itself has succeeded.
+func (x *UseCase) branchFindings(ctx context.Context, repoID types.GitHubRepoID, branchName types.BranchName) *model.FindingSummary {
+	repo := x.clients.ScanRepository()
+	if repo == nil {
+		return nil
+	}
+
+	branch, err := repo.GetBranch(ctx, repoID, branchName)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			logging.From(ctx).Warn("failed to get findings of branch", "repo_id", repoID, "branch", branchName, "error", err)
+		}
+		return nil
+	}
+	return branch.Findings
+}
+
+// finishOwnerScanRun records the end of the run and logs its summary. It returns interruptErr if the run was interrupted, or an error if any repository failed, so that the caller exits with non-zero status. A failure of recording is logged because the scans themselves are already stored.
+func (x *UseCase) finishOwnerScanRun(ctx context.Context, run *model.OwnerScanRun, interruptErr error) error {
+	logger := logging.From(ctx)
+	run.Finish(interruptErr, logging.CtxTime(ctx).UTC())
+
+	if repo := x.clients.ScanRepository(); repo != nil {
+		if err := repo.PutOwnerScanRun(ctx, run); err != nil {
+			logger.Error("failed to record owner scan run", "run_id", run.ID, "error", err)
+		}
+	}
+
+	logger.Info("Completed scan of repositories of owner",
+		slog.String("run_id", run.ID.String()),
+		slog.String("owner", run.Owner),
+		slog.String("source", run.Source),
+		slog.Int("total_repos", len(run.Repositories)),
+		slog.Int("success", run.Succeeded),
+		slog.Int("skipped", run.Skipped),
+		slog.Int("failure", run.Failed),
+	)
+	for _, r := range run.Repositories {
+		if r.Status == types.ScanRunFailed {
+			logger.Error("Repository scan failure details",
+				slog.String("owner", r.Owner),
+				slog.String("repo", r.Repo),
+				slog.String("error", r.Error),
+			)
+		}
+	}
+
+	if interruptErr != nil {
+		return interruptErr
+	}
+
+	if run.Failed > 0 {
+		failedRepos := run.FailedRepos()
+		progress.From(ctx).Set("failed_repos", failedRepos)
+		return goerr.New("some repositories failed to scan",
+			goerr.V("run_id", run.ID),
+			goerr.V("owner", run.Owner),
+			goerr.V("success_count", run.Succeeded+run.Skipped),
+			goerr.V("failure_count", run.Failed),
+			goerr.V("failed_repos", failedRepos),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/repository/scoped"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+func TestGetOwnerScanRun(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	repo := scoped.New(memory.New())
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: "myorg/app", Owner: "myorg", Name: "app", InstallationID: 1}))
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: "myorg/lib", Owner: "myorg", Name: "lib", InstallationID: 2}))
+
+	run := model.NewOwnerScanRun("myorg", model.OwnerScanSourceGitHub, types.ScanTriggerSchedule, "", now)
+	run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "app", Branch: "main", Status: types.ScanRunSucceeded, ScanID: "scan-app"})
+	run.Add(&model.OwnerScanRepoResult{Owner: "myorg", Repo: "lib", Branch: "main", Status: types.ScanRunSucceeded, Skipped: true})
+	run.Finish(nil, now)
+	gt.NoError(t, repo.PutOwnerScanRun(ctx, run))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+	t.Run("stored run is returned", func(t *testing.T) {
+		got := gt.R1(uc.GetOwnerScanRun(ctx, run.ID)).NoError(t)
+		gt.V(t, got.Owner).Equal("myorg")
+		gt.V(t, got.Status).Equal(types.ScanRunSucceeded)
+		gt.V(t, got.Succeeded).Equal(1)
+		gt.V(t, got.Skipped).Equal(1)
+		gt.A(t, got.Repositories).Length(2)
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		_, err := uc.GetOwnerScanRun(ctx, types.NewOwnerScanRunID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("tenant of an installation gets its repositories only", func(t *testing.T) {
+		tenantCtx := tenancy.With(ctx, &model.Tenant{InstallationIDs: []int64{2}})
+		got := gt.R1(uc.GetOwnerScanRun(tenantCtx, run.ID)).NoError(t)
+		gt.A(t, got.Repositories).Length(1).At(0, func(t testing.TB, v *model.OwnerScanRepoResult) {
+			gt.V(t, v.Repo).Equal("lib")
+		})
+		gt.V(t, got.Succeeded).Equal(0)
+		gt.V(t, got.Skipped).Equal(1)
+	})
+
+	t.Run("Firestore is required", func(t *testing.T) {
+		_, err := usecase.New(infra.New()).GetOwnerScanRun(ctx, run.ID)
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// PruneScanHistory deletes scan records older than Keep and fixed vulnerabilities that have not
+// been updated within Keep for all repositories of the owner. The last scan of each branch is
+// always kept so that the branch keeps pointing to an existing record. Active and acknowledged
+// vulnerabilities are never deleted because they describe the current state of the branch.
+func (x *UseCase) PruneScanHistory(ctx context.Context, input *model.PruneScanHistoryInput) (*model.PruneScanHistoryResult, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "scan history pruning requires Firestore")
+	}
+	if input.Keep <= 0 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "retention period must be positive", goerr.V("keep", input.Keep))
+	}
+
+	repos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+	}
+
+	logger := logging.From(ctx)
+	cutoff := logging.CtxTime(ctx).Add(-input.Keep)
+	result := &model.PruneScanHistoryResult{}
+
+	task := progress.From(ctx).Start("Pruning scan history", len(repos))
+	defer task.Done()
+	for _, repo := range repos {
+		task.Describe(string(repo.ID))
+		scans, vulns, err := x.pruneRepository(ctx, repo.ID, cutoff)
+		if err != nil {
+			task.Fail()
+			return result, goerr.Wrap(err, "failed to prune repository", goerr.V("repo_id", repo.ID))
+		}
+		task.Succeed()
+
+		result.Repositories++
+		result.DeletedScans += scans
+		result.DeletedVulnerabilities += vulns
+
+		if scans > 0 || vulns > 0 {
+			logger.Info("Pruned scan history",
+				slog.Any("repo_id", repo.ID),
+				slog.Int("deleted_scans", scans),
+				slog.Int("deleted_vulnerabilities", vulns),
+			)
+		}
+	}
+
+	progress.From(ctx).Set("deleted_scans", result.DeletedScans)
+	progress.From(ctx).Set("deleted_vulnerabilities", result.DeletedVulnerabilities)
+
+	logger.Info("Completed scan history pruning",
+		slog.String("owner", input.Owner),
+		slog.Time("cutoff", cutoff),
+		slog.Int("repositories", result.Repositories),
+		slog.Int("deleted_scans", result.DeletedScans),
+		slog.Int("deleted_vulnerabilities", result.DeletedVulnerabilities),
+	)
+
+	return result, nil
+}
+
+// pruneRepository deletes old records of a repository and returns the number of deleted scan
+// records and vulnerabilities.
+func (x *UseCase) pruneRepository(ctx context.Context, repoID types.GitHubRepoID, cutoff time.Time) (int, int, error) {
+	scanRepo := x.clients.ScanRepository()
+
+	branches, err := scanRepo.ListBranches(ctx, repoID)
+	if err != nil {
+		return 0, 0, goerr.Wrap(err, "failed to list branches")
+	}
+
+	lastScans := make(map[types.ScanID]bool)
+	for _, branch := range branches {
+		lastScans[branch.LastScanID] = true
+	}
+
+	records, err := scanRepo.ListScanRecords(ctx, repoID)
+	if err != nil {
+		return 0, 0, goerr.Wrap(err, "failed to list scan records")
+	}
+
+	var oldScans []types.ScanID
+	for _, record := range records {
+		if record.Timestamp.Before(cutoff) && !lastScans[record.ID] {
+			oldScans = append(oldScans, record.ID)
+		}
+	}
+	if len(oldScans) > 0 {
+		if err := scanRepo.BatchDeleteScanRecords(ctx, repoID, oldScans); err != nil {
+			return 0, 0, goerr.Wrap(err, "failed to delete scan records")
+		}
+	}
+
+	var deletedVulns int
+	for _, branch := range branches {
+		targets, err := scanRepo.ListTargets(ctx, repoID, branch.Name)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return len(oldScans), deletedVulns, goerr.Wrap(err, "failed to list targets", goerr.V("branch", branch.Name))
+		}
+
+		for _, target := range targets {
+			vulns, err := scanRepo.ListVulnerabilities(ctx, repoID, branch.Name, target.ID)
+			if err != nil {
+				return len(oldScans), deletedVulns, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("target", target.Target))
+			}
+
+			var oldVulns []string
+			for _, vuln := range vulns {
+				if vuln.Status == types.VulnStatusFixed && vuln.UpdatedAt.Before(cutoff) {
+					oldVulns = append(oldVulns, vuln.ID)
+				}
+			}
+			if len(oldVulns) == 0 {
+				continue
+			}
+
+			if err := scanRepo.BatchDeleteVulnerabilities(ctx, repoID, branch.Name, target.ID, oldVulns); err != nil {
+				return len(oldScans), deletedVulns, goerr.Wrap(err, "failed to delete vulnerabilities", goerr.V("target", target.Target))
+			}
+			deletedVulns += len(oldVulns)
+		}
+	}
+
+	return len(oldScans), deletedVulns, nil
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestPruneScanHistory(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	now := time.Now()
+	old := now.Add(-100 * 24 * time.Hour)
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+
+	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+		ID:             repoID,
+		Owner:          "test-owner",
+		Name:           "test-repo",
+		DefaultBranch:  "main",
+		InstallationID: 12345,
+		CreatedAt:      old,
+		UpdatedAt:      now,
+	}))
+
+	// "stale" branch has not been scanned for a long time; its last scan must be kept
+	branches := []*model.Branch{
+		{Name: "main", LastScanID: "scan-new", LastScanAt: now, Status: types.ScanStatusSuccess},
+		{Name: "stale", LastScanID: "scan-stale", LastScanAt: old, Status: types.ScanStatusSuccess},
+	}
+	for _, b := range branches {
+		gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, b))
+	}
+
+	records := []*model.ScanRecord{
+		{ID: "scan-old", Branch: "main", Timestamp: old},
+		{ID: "scan-new", Branch: "main", Timestamp: now},
+		{ID: "scan-stale", Branch: "stale", Timestamp: old},
+	}
+	for _, r := range records {
+		gt.NoError(t, repo.CreateScanRecord(ctx, repoID, r))
+	}
+
+	targetID := model.ToTargetID("go.mod", "", "")
+	gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: targetID, Target: "go.mod"}))
+	gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, "main", targetID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", Status: types.VulnStatusFixed, UpdatedAt: old},
+		{ID: "CVE-2024-0002", Status: types.VulnStatusFixed, UpdatedAt: now},
+		{ID: "CVE-2024-0003", Status: types.VulnStatusActive, UpdatedAt: old},
+		{ID: "CVE-2024-0004", Status: types.VulnStatusAcknowledged, UpdatedAt: old},
+	}))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+	t.Run("deletes old scans and old fixed vulnerabilities", func(t *testing.T) {
+		result, err := uc.PruneScanHistory(ctx, &model.PruneScanHistoryInput{
+			Owner: "test-owner",
+			Keep:  90 * 24 * time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.V(t, result.Repositories).Equal(1)
+		gt.V(t, result.DeletedScans).Equal(1)
+		gt.V(t, result.DeletedVulnerabilities).Equal(1)
+
+		remaining, err := repo.ListScanRecords(ctx, repoID)
+		gt.NoError(t, err)
+		gt.A(t, remaining).Length(2)
+		gt.V(t, remaining[0].ID).Equal(types.ScanID("scan-new"))
+		gt.V(t, remaining[1].ID).Equal(types.ScanID("scan-stale"))
+
+		vulns, err := repo.ListVulnerabilities(ctx, repoID, "main", targetID)
+		gt.NoError(t, err)
+		ids := make(map[string]bool)
+		for _, v := range vulns {
+			ids[v.ID] = true
+		}
+		gt.False(t, ids["CVE-2024-0001"])
+		gt.True(t, ids["CVE-2024-0002"])
+		gt.True(t, ids["CVE-2024-0003"])
+		gt.True(t, ids["CVE-2024-0004"])
+	})
+
+	t.Run("second run deletes nothing", func(t *testing.T) {
+		result, err := uc.PruneScanHistory(ctx, &model.PruneScanHistoryInput{
+			Owner: "test-owner",
+			Keep:  90 * 24 * time.Hour,
+		})
+		gt.NoError(t, err)
+		gt.V(t, result.DeletedScans).Equal(0)
+		gt.V(t, result.DeletedVulnerabilities).Equal(0)
+	})
+
+	t.Run("requires positive retention period", func(t *testing.T) {
+		_, err := uc.PruneScanHistory(ctx, &model.PruneScanHistoryInput{Owner: "test-owner"})
+		gt.Error(t, err)
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		_, err := usecase.New(infra.New()).PruneScanHistory(ctx, &model.PruneScanHistoryInput{
+			Owner: "test-owner",
+			Keep:  time.Hour,
+		})
+		gt.Error(t, err)
+	})
+}
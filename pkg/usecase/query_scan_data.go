@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// scanRepositoryForQuery returns the scan repository for read queries of the API
+func (x *UseCase) scanRepositoryForQuery() (interfaces.ScanRepository, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "querying scan data requires Firestore")
+	}
+	return scanRepo, nil
+}
+
+// GetRepository returns the scanned repository. It returns repository.ErrNotFound if the repository has never been scanned.
+func (x *UseCase) GetRepository(ctx context.Context, input *model.GetRepositoryInput) (*model.Repository, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+	return repo, nil
+}
+
+// ListRepositories returns scanned repositories of the owner matching the tag and team, sorted by name.
+func (x *UseCase) ListRepositories(ctx context.Context, input *model.ListRepositoriesInput) ([]*model.Repository, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "owner is required")
+	}
+
+	repos, err := scanRepo.ListRepositoriesByOwner(ctx, input.Owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+	}
+
+	matched := make([]*model.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if repo.MatchMetadata(input.Tag, input.Team) {
+			matched = append(matched, repo)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Name < matched[j].Name
+	})
+
+	return matched, nil
+}
+
+// ListBranches returns scanned branches of the repository sorted by name.
+func (x *UseCase) ListBranches(ctx context.Context, input *model.ListBranchesInput) ([]*model.Branch, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := scanRepo.ListBranches(ctx, input.RepoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list branches", goerr.V("repo_id", input.RepoID))
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+
+	return branches, nil
+}
+
+// ListTargets returns scan targets of the branch sorted by target path.
+func (x *UseCase) ListTargets(ctx context.Context, input *model.ListTargetsInput) ([]*model.Target, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := scanRepo.ListTargets(ctx, input.RepoID, input.Branch)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", input.RepoID), goerr.V("branch", input.Branch))
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Target < targets[j].Target
+	})
+
+	return targets, nil
+}
+
+// ListVulnerabilities returns findings of the target matching the filters, ordered by severity (highest first) and ID.
+func (x *UseCase) ListVulnerabilities(ctx context.Context, input *model.ListVulnerabilitiesInput) ([]*model.Vulnerability, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	vulns, err := scanRepo.ListVulnerabilities(ctx, input.RepoID, input.Branch, input.TargetID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list vulnerabilities",
+			goerr.V("repo_id", input.RepoID),
+			goerr.V("branch", input.Branch),
+			goerr.V("target_id", input.TargetID),
+		)
+	}
+
+	matched := make([]*model.Vulnerability, 0, len(vulns))
+	for _, vuln := range vulns {
+		if len(input.Severities) > 0 && !slices.Contains(input.Severities, types.Severity(vuln.Severity)) {
+			continue
+		}
+		if len(input.Statuses) > 0 && !slices.Contains(input.Statuses, vuln.Status) {
+			continue
+		}
+		findingType := vuln.Type
+		if findingType == "" {
+			findingType = types.FindingTypeVulnerability
+		}
+		if len(input.Types) > 0 && !slices.Contains(input.Types, findingType) {
+			continue
+		}
+		matched = append(matched, vuln)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if ri, rj := types.Severity(matched[i].Severity).Rank(), types.Severity(matched[j].Severity).Rank(); ri != rj {
+			return ri > rj
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	return matched, nil
+}
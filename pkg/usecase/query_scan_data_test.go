@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestQueryScanData(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	memRepo := memory.New()
+
+	for _, repo := range []*model.Repository{
+		{ID: "myorg/web", Owner: "myorg", Name: "web", DefaultBranch: "main", Team: "frontend"},
+		{ID: "myorg/api", Owner: "myorg", Name: "api", DefaultBranch: "main", Tags: []string{"production"}},
+	} {
+		repo.CreatedAt, repo.UpdatedAt = now, now
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, repo))
+	}
+
+	repoID := types.GitHubRepoID("myorg/api")
+	for _, name := range []types.BranchName{"main", "develop"} {
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: name, Status: types.ScanStatusSuccess, CreatedAt: now, UpdatedAt: now}))
+	}
+	for _, target := range []string{"web/package-lock.json", "go.mod"} {
+		gt.NoError(t, memRepo.CreateOrUpdateTarget(ctx, repoID, "main", &model.Target{ID: model.ToTargetID(target, "", ""), Target: target, CreatedAt: now, UpdatedAt: now}))
+	}
+	targetID := model.ToTargetID("go.mod", "", "")
+	gt.NoError(t, memRepo.BatchCreateVulnerabilities(ctx, repoID, "main", targetID, []*model.Vulnerability{
+		{ID: "CVE-2024-0001", Severity: "MEDIUM", Status: types.VulnStatusActive},
+		{ID: "CVE-2024-0002", Severity: "CRITICAL", Status: types.VulnStatusFixed},
+		{ID: "CVE-2024-0003", Severity: "CRITICAL", Status: types.VulnStatusActive},
+		{ID: "AVD-KSV-0001", Type: types.FindingTypeMisconfiguration, Severity: "HIGH", Status: types.VulnStatusActive},
+	}))
+
+	uc := usecase.New(infra.New(infra.WithScanRepository(memRepo)))
+
+	t.Run("list repositories filtered by tag and sorted by name", func(t *testing.T) {
+		repos, err := uc.ListRepositories(ctx, &model.ListRepositoriesInput{Owner: "myorg"})
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(2)
+		gt.V(t, repos[0].Name).Equal("api")
+		gt.V(t, repos[1].Name).Equal("web")
+
+		repos, err = uc.ListRepositories(ctx, &model.ListRepositoriesInput{Owner: "myorg", Tag: "production"})
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(1)
+		gt.V(t, repos[0].ID).Equal(repoID)
+
+		_, err = uc.ListRepositories(ctx, &model.ListRepositoriesInput{})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("get repository", func(t *testing.T) {
+		repo, err := uc.GetRepository(ctx, &model.GetRepositoryInput{Owner: "myorg", Repo: "web"})
+		gt.NoError(t, err)
+		gt.V(t, repo.Team).Equal("frontend")
+
+		_, err = uc.GetRepository(ctx, &model.GetRepositoryInput{Owner: "myorg", Repo: "unknown"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		_, err = uc.GetRepository(ctx, &model.GetRepositoryInput{Owner: "myorg", Repo: "../web"})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("list branches and targets sorted by name", func(t *testing.T) {
+		branches, err := uc.ListBranches(ctx, &model.ListBranchesInput{RepoID: repoID})
+		gt.NoError(t, err)
+		gt.A(t, branches).Length(2)
+		gt.V(t, branches[0].Name).Equal(types.BranchName("develop"))
+
+		targets, err := uc.ListTargets(ctx, &model.ListTargetsInput{RepoID: repoID, Branch: "main"})
+		gt.NoError(t, err)
+		gt.A(t, targets).Length(2)
+		gt.V(t, targets[0].Target).Equal("go.mod")
+	})
+
+	t.Run("list vulnerabilities with filters", func(t *testing.T) {
+		input := &model.ListVulnerabilitiesInput{RepoID: repoID, Branch: "main", TargetID: targetID}
+		vulns, err := uc.ListVulnerabilities(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(4)
+		// Ordered by severity, highest first
+		gt.V(t, vulns[0].ID).Equal("CVE-2024-0002")
+		gt.V(t, vulns[1].ID).Equal("CVE-2024-0003")
+		gt.V(t, vulns[3].ID).Equal("CVE-2024-0001")
+
+		input.Statuses = []types.VulnStatus{types.VulnStatusActive}
+		input.Severities = []types.Severity{types.SeverityCritical, types.SeverityHigh}
+		vulns, err = uc.ListVulnerabilities(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(2)
+		gt.V(t, vulns[0].ID).Equal("CVE-2024-0003")
+		gt.V(t, vulns[1].ID).Equal("AVD-KSV-0001")
+
+		// Findings stored without type are vulnerabilities
+		input.Types = []types.FindingType{types.FindingTypeVulnerability}
+		vulns, err = uc.ListVulnerabilities(ctx, input)
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(1)
+		gt.V(t, vulns[0].ID).Equal("CVE-2024-0003")
+	})
+
+	t.Run("Firestore is required", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.ListRepositories(ctx, &model.ListRepositoriesInput{Owner: "myorg"})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// readinessCheckTimeout bounds each check so that a hanging dependency does not hold the probe longer than load balancers wait
+const readinessCheckTimeout = 5 * time.Second
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) (string, error)
+}
+
+// CheckReadiness checks BigQuery, Firestore, Trivy and GitHub App in parallel. Dependencies which are not configured are skipped, and Trivy is skipped if another scanner replaces it.
+func (x *UseCase) CheckReadiness(ctx context.Context) *model.Readiness {
+	var checks []readinessCheck
+	if bq := x.clients.BigQuery(); bq != nil {
+		checks = append(checks, readinessCheck{name: "bigquery", check: func(ctx context.Context) (string, error) {
+			return "", bq.CheckDataset(ctx)
+		}})
+	}
+	if repo := x.clients.ScanRepository(); repo != nil {
+		checks = append(checks, readinessCheck{name: "firestore", check: func(ctx context.Context) (string, error) {
+			return "", repo.Ping(ctx)
+		}})
+	}
+	if x.scanner == nil {
+		checks = append(checks, readinessCheck{name: "trivy", check: x.clients.Trivy().Version})
+	}
+	if gh := x.clients.GitHubApp(); gh != nil {
+		checks = append(checks, readinessCheck{name: "github_app", check: func(ctx context.Context) (string, error) {
+			return "", gh.CheckCredential(ctx)
+		}})
+	}
+
+	result := &model.Readiness{
+		Ready:        true,
+		Dependencies: make([]*model.DependencyStatus, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.Dependencies[i] = runReadinessCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, dep := range result.Dependencies {
+		if !dep.OK {
+			result.Ready = false
+			logging.From(ctx).Warn("dependency is not ready", slog.Any("dependency", dep.Name), slog.String("error", dep.Error))
+		}
+	}
+	return result
+}
+
+func runReadinessCheck(ctx context.Context, c readinessCheck) *model.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	version, err := c.check(ctx)
+	status := &model.DependencyStatus{
+		Name:      c.name,
+		OK:        err == nil,
+		Version:   version,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,66 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestCheckReadiness(t *testing.T) {
+	ctx := context.Background()
+	mockTrivy := &trivyMock{
+		mockVersion: func(ctx context.Context) (string, error) {
+			return "0.58.1", nil
+		},
+	}
+	mockBQ := &mock.BigQueryMock{
+		CheckDatasetFunc: func(ctx context.Context) error {
+			return nil
+		},
+	}
+	mockGH := &mock.GitHubAppMock{
+		CheckCredentialFunc: func(ctx context.Context) error {
+			return errors.New("bad credentials")
+		},
+	}
+
+	t.Run("not ready if a dependency fails", func(t *testing.T) {
+		uc := usecase.New(infra.New(
+			infra.WithTrivy(mockTrivy),
+			infra.WithBigQuery(mockBQ),
+			infra.WithGitHubApp(mockGH),
+			infra.WithScanRepository(memory.New()),
+		))
+
+		result := uc.CheckReadiness(ctx)
+		gt.False(t, result.Ready)
+
+		deps := map[string]*model.DependencyStatus{}
+		for _, dep := range result.Dependencies {
+			deps[dep.Name] = dep
+		}
+		gt.A(t, result.Dependencies).Length(4)
+		gt.True(t, deps["bigquery"].OK)
+		gt.True(t, deps["firestore"].OK)
+		gt.True(t, deps["trivy"].OK)
+		gt.V(t, deps["trivy"].Version).Equal("0.58.1")
+		gt.False(t, deps["github_app"].OK)
+		gt.S(t, deps["github_app"].Error).Contains("bad credentials")
+	})
+
+	t.Run("dependencies not configured are skipped", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)))
+
+		result := uc.CheckReadiness(ctx)
+		gt.True(t, result.Ready)
+		gt.A(t, result.Dependencies).Length(1)
+		gt.V(t, result.Dependencies[0].Name).Equal("trivy")
+	})
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+var (
+	ptnRepositoryTag  = regexp.MustCompile(`^[a-z0-9][a-z0-9:._-]{0,49}$`)
+	ptnRepositoryTeam = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+)
+
+// UpdateRepositoryMetadata sets tags, team, scan config and pull request check of a registered repository. Values set by this are never overwritten by auto-population from GitHub.
+func (x *UseCase) UpdateRepositoryMetadata(ctx context.Context, input *model.UpdateRepositoryMetadataInput) (*model.Repository, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "repository metadata requires Firestore")
+	}
+	if input.Tags == nil && input.Team == nil && input.ScanConfig == nil && input.PullRequestCheck == nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "none of tags, team, scan config and pull request check is specified")
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+
+	if input.Tags != nil {
+		tags := model.NormalizeTags(*input.Tags)
+		for _, tag := range tags {
+			if !ptnRepositoryTag.MatchString(tag) {
+				return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid tag", goerr.V("tag", tag))
+			}
+		}
+		repo.Tags = tags
+	}
+	if input.Team != nil {
+		team := model.NormalizeTeam(*input.Team)
+		if team != "" && !ptnRepositoryTeam.MatchString(team) {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid team", goerr.V("team", team))
+		}
+		repo.Team = team
+	}
+	if input.ScanConfig != nil {
+		cfg := *input.ScanConfig
+		cfg.Normalize()
+		if err := cfg.Validate(); err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid scan config", goerr.V("error", err.Error()))
+		}
+		repo.ScanConfig = nil
+		if !cfg.IsEmpty() {
+			repo.ScanConfig = &cfg
+		}
+	}
+	if input.PullRequestCheck != nil {
+		cfg := *input.PullRequestCheck
+		if cfg.Baseline != "" {
+			if err := cfg.Baseline.Validate(); err != nil {
+				return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid pull request check", goerr.V("error", err.Error()))
+			}
+		}
+		repo.PullRequestCheck = nil
+		if !cfg.IsEmpty() {
+			repo.PullRequestCheck = &cfg
+		}
+	}
+	repo.UpdatedAt = logging.CtxTime(ctx)
+
+	if err := scanRepo.CreateOrUpdateRepository(ctx, repo); err != nil {
+		return nil, goerr.Wrap(err, "failed to update repository", goerr.V("repo_id", repoID))
+	}
+
+	logging.From(ctx).Info("Repository metadata updated",
+		slog.Any("repo_id", repoID),
+		slog.Any("tags", repo.Tags),
+		slog.String("team", repo.Team),
+		slog.Any("scan_config", repo.ScanConfig),
+		slog.Any("pull_request_check", repo.PullRequestCheck),
+	)
+
+	return repo, nil
+}
+
+// teamPermissionRank orders GitHub team permissions from the strongest. The team with the strongest permission is regarded as the owner of a repository.
+var teamPermissionRank = map[string]int{
+	"admin":    5,
+	"maintain": 4,
+	"push":     3,
+	"triage":   2,
+	"pull":     1,
+}
+
+// resolveRepositoryTeam returns slug of the team with the strongest permission on the repository, or empty if no team has access. Failure of the teams API is logged and ignored because the App may not have permission to read teams.
+func (x *UseCase) resolveRepositoryTeam(ctx context.Context, installID types.GitHubAppInstallID, owner, repo string) string {
+	teams, err := x.clients.GitHubApp().ListRepositoryTeams(ctx, &interfaces.ListRepositoryTeamsInput{
+		Owner:     owner,
+		Repo:      repo,
+		InstallID: installID,
+	})
+	if err != nil {
+		logging.From(ctx).Warn("failed to list repository teams, team is not populated",
+			slog.String("owner", owner),
+			slog.String("repo", repo),
+			slog.Any("error", err),
+		)
+		return ""
+	}
+	if len(teams) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(teams, func(i, j int) bool {
+		ri, rj := teamPermissionRank[teams[i].Permission], teamPermissionRank[teams[j].Permission]
+		if ri != rj {
+			return ri > rj
+		}
+		return teams[i].Slug < teams[j].Slug
+	})
+	return model.NormalizeTeam(teams[0].Slug)
+}
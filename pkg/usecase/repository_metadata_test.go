@@ -0,0 +1,212 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestUpdateRepositoryMetadata(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+	setup := func(t *testing.T) (*usecase.UseCase, interfaces.ScanRepository) {
+		repo := memory.New()
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:            "test-owner/test-repo",
+			Owner:         "test-owner",
+			Name:          "test-repo",
+			DefaultBranch: "main",
+			Tags:          []string{"legacy"},
+			Team:          "platform",
+		}))
+		return usecase.New(infra.New(infra.WithScanRepository(repo))), repo
+	}
+	get := func(t *testing.T, repo interfaces.ScanRepository) *model.Repository {
+		stored, err := repo.GetRepository(ctx, "test-owner/test-repo")
+		gt.NoError(t, err)
+		return stored
+	}
+	strPtr := func(s string) *string { return &s }
+	tagsPtr := func(tags ...string) *[]string { return &tags }
+
+	t.Run("tags are normalized and team is kept if not given", func(t *testing.T) {
+		uc, repo := setup(t)
+		updated, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Tags:  tagsPtr(" Tier-1 ", "payments", "tier-1", ""),
+		})
+		gt.NoError(t, err)
+		gt.V(t, updated.Tags).Equal([]string{"payments", "tier-1"})
+		gt.V(t, updated.Team).Equal("platform")
+
+		stored := get(t, repo)
+		gt.V(t, stored.Tags).Equal([]string{"payments", "tier-1"})
+		gt.V(t, stored.UpdatedAt).Equal(now)
+	})
+
+	t.Run("team is replaced and tags are kept if not given", func(t *testing.T) {
+		uc, repo := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Team:  strPtr("Security"),
+		})
+		gt.NoError(t, err)
+
+		stored := get(t, repo)
+		gt.V(t, stored.Team).Equal("security")
+		gt.V(t, stored.Tags).Equal([]string{"legacy"})
+	})
+
+	t.Run("empty values clear metadata", func(t *testing.T) {
+		uc, repo := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Tags:  tagsPtr(),
+			Team:  strPtr(""),
+		})
+		gt.NoError(t, err)
+
+		stored := get(t, repo)
+		gt.A(t, stored.Tags).Length(0)
+		gt.V(t, stored.Team).Equal("")
+	})
+
+	t.Run("metadata is kept across scans", func(t *testing.T) {
+		uc, repo := setup(t)
+		_, err := uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "1111111111111111111111111111111111111111",
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{SchemaVersion: 2, ArtifactName: "test-repo"})
+		gt.NoError(t, err)
+
+		stored := get(t, repo)
+		gt.V(t, stored.Tags).Equal([]string{"legacy"})
+		gt.V(t, stored.Team).Equal("platform")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Tags:  tagsPtr("has space"),
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("invalid team", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Team:  strPtr("org/team"),
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("scan config is normalized and empty config removes it", func(t *testing.T) {
+		uc, repo := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:      "test-owner",
+			Repo:       "test-repo",
+			ScanConfig: &model.ScanConfig{Paths: []string{"/services/api/", "services/api"}, Exclude: []string{"vendor/"}},
+		})
+		gt.NoError(t, err)
+		stored := get(t, repo)
+		gt.V(t, stored.ScanConfig).Equal(&model.ScanConfig{Paths: []string{"services/api"}, Exclude: []string{"vendor"}})
+		gt.V(t, stored.Team).Equal("platform")
+
+		_, err = uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:      "test-owner",
+			Repo:       "test-repo",
+			ScanConfig: &model.ScanConfig{},
+		})
+		gt.NoError(t, err)
+		gt.V(t, get(t, repo).ScanConfig).Nil()
+	})
+
+	t.Run("scan config out of repository", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:      "test-owner",
+			Repo:       "test-repo",
+			ScanConfig: &model.ScanConfig{Paths: []string{"../other"}},
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("pull request check is validated and empty config removes it", func(t *testing.T) {
+		uc, repo := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:            "test-owner",
+			Repo:             "test-repo",
+			PullRequestCheck: &model.PullRequestCheckConfig{Baseline: "latest"},
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		_, err = uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:            "test-owner",
+			Repo:             "test-repo",
+			PullRequestCheck: &model.PullRequestCheckConfig{Baseline: types.CheckBaselineNone},
+		})
+		gt.NoError(t, err)
+		gt.V(t, get(t, repo).PullRequestCheck).Equal(&model.PullRequestCheckConfig{Baseline: types.CheckBaselineNone})
+
+		_, err = uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner:            "test-owner",
+			Repo:             "test-repo",
+			PullRequestCheck: &model.PullRequestCheckConfig{},
+		})
+		gt.NoError(t, err)
+		gt.V(t, get(t, repo).PullRequestCheck).Nil()
+	})
+
+	t.Run("nothing to update", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "unknown",
+			Team:  strPtr("security"),
+		})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+		_, err := uc.UpdateRepositoryMetadata(ctx, &model.UpdateRepositoryMetadataInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Team:  strPtr("security"),
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+// PrepareAgentScanJob creates a job to delegate the scan to a scan agent. The archive URL issued by GitHub expires in minutes, so the job should be prepared when an agent claims it. It returns nil if the commit has already been scanned.
+// The ScanRun of the scan is running from then until CompleteAgentScanJob.
+func (x *UseCase) PrepareAgentScanJob(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error) {
+	run := x.startScanRun(ctx, input)
+	job, err := x.prepareAgentScanJob(ctx, input)
+	if err != nil || job == nil {
+		x.finishScanRun(ctx, run, &input.GitHubMetadata, "", err)
+		return nil, err
+	}
+	if run != nil {
+		job.RunID = run.ID
+	}
+	return job, nil
+}
+
+func (x *UseCase) prepareAgentScanJob(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.AgentScanJob, error) {
+	input.Normalize()
+	if err := x.resolveTagOfInput(ctx, input); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if x.clients.GitHubApp() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App client is required to delegate scans")
+	}
+
+	if !input.Force && x.failOnSeverity == "" && x.isCommitAlreadyScanned(ctx, &input.GitHubMetadata) {
+		logging.From(ctx).Info("commit is already scanned, skip delegating scan",
+			"owner", input.Owner,
+			"repo", input.RepoName,
+			"branch", input.Branch,
+			"commit", input.CommitID,
+		)
+		return nil, nil
+	}
+
+	zipURL, err := x.clients.GitHubApp().GetArchiveURL(ctx, &interfaces.GetArchiveURLInput{
+		Owner:     input.Owner,
+		Repo:      input.RepoName,
+		CommitID:  input.CommitID,
+		InstallID: input.InstallID,
+	})
+	if err != nil {
+		x.recordScanFailure(ctx, &input.GitHubMetadata, err)
+		return nil, err
+	}
+
+	job := &model.AgentScanJob{
+		ID:         uuid.NewString(),
+		Metadata:   input.GitHubMetadata,
+		ArchiveURL: zipURL.String(),
+	}
+	if scanRepo := x.clients.ScanRepository(); scanRepo != nil {
+		stored, err := getStoredRepository(ctx, scanRepo, types.NewGitHubRepoID(input.Owner, input.RepoName))
+		if err != nil {
+			return nil, err
+		}
+		if stored != nil && !stored.ScanConfig.IsEmpty() {
+			job.ScanConfig = stored.ScanConfig
+		}
+	}
+
+	return job, nil
+}
+
+// ScanAgentJob downloads the source code of the job and scans it in a scan agent. It needs only the scanner and does not store the report, which is sent back to the server by the agent with the scan coverage and the archive.
+func (x *UseCase) ScanAgentJob(ctx context.Context, job *model.AgentScanJob) (*model.AgentScanResult, error) {
+	zipURL, err := url.Parse(job.ArchiveURL)
+	if err != nil || (zipURL.Scheme != "https" && zipURL.Scheme != "http") {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid archive URL of scan job", goerr.V("job_id", job.ID))
+	}
+
+	meta := &job.Metadata
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("octovy.%s.%s.%s.*", meta.Owner, meta.RepoName, meta.CommitID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temp directory for zip file")
+	}
+	defer safe.RemoveAll(tmpDir)
+
+	archive, err := x.downloadArchive(ctx, zipURL, meta, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := readScanConfigFile(filepath.Join(tmpDir, model.ScanConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = job.ScanConfig
+	}
+
+	report, coverage, err := x.scanDirectory(ctx, tmpDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logging.From(ctx).Info("agent scan finished", "job_id", job.ID, "owner", meta.Owner, "repo", meta.RepoName, "commit", meta.CommitID)
+
+	return &model.AgentScanResult{Report: report, Coverage: coverage, Archive: archive}, nil
+}
+
+// CompleteAgentScanJob stores the result of the job reported by a scan agent in the same way as a scan run by the server. A failure reported by the agent is recorded to the branch.
+func (x *UseCase) CompleteAgentScanJob(ctx context.Context, job *model.AgentScanJob, result *model.AgentScanResult) error {
+	run := x.loadScanRun(ctx, job.RunID)
+	if result.Report == nil {
+		msg := result.Error
+		if msg == "" {
+			msg = "no report"
+		}
+		scanErr := goerr.Wrap(errors.New(msg), "scan failed in scan agent")
+		x.recordScanFailure(ctx, &job.Metadata, scanErr)
+		x.finishScanRun(ctx, run, &job.Metadata, "", scanErr)
+		logging.From(ctx).Warn("scan agent reported failure",
+			slog.String("job_id", job.ID),
+			slog.String("owner", job.Metadata.Owner),
+			slog.String("repo", job.Metadata.RepoName),
+			slog.String("commit", job.Metadata.CommitID),
+			slog.String("error", msg),
+		)
+		return nil
+	}
+
+	// The start of the scan in the agent is unknown, so the result is ordered by the time it is reported
+	scanID, err := x.insertScanReport(ctx, job.Metadata, result.Report, result.Coverage, nil, result.Archive, time.Time{})
+	if err != nil {
+		err = goerr.Wrap(err, "failed to insert scan result of agent", goerr.V("job_id", job.ID))
+	}
+	x.finishScanRun(ctx, run, &job.Metadata, scanID, err)
+	return err
+}
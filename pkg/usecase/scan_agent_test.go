@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestScanAgentJob(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.NewGitHubRepoID(defaultTestOwner, defaultTestRepo)
+	newInput := func() *model.ScanGitHubRepoInput {
+		return &model.ScanGitHubRepoInput{
+			GitHubMetadata: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{
+						RepoID:   12345,
+						Owner:    defaultTestOwner,
+						RepoName: defaultTestRepo,
+					},
+					CommitID: defaultTestCommitID,
+					Branch:   defaultTestBranch,
+				},
+				InstallationID: 12345,
+			},
+			InstallID: 12345,
+		}
+	}
+	setup := func(t *testing.T) (*usecase.UseCase, interfaces.ScanRepository) {
+		fx := newScanTestFixture(t, nil)
+		memRepo := memory.New()
+		return usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		)), memRepo
+	}
+
+	t.Run("job is scanned by agent and completed by server", func(t *testing.T) {
+		uc, memRepo := setup(t)
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:         repoID,
+			Owner:      defaultTestOwner,
+			Name:       defaultTestRepo,
+			ScanConfig: &model.ScanConfig{Exclude: []string{"vendor"}},
+		}))
+
+		job := gt.R1(uc.PrepareAgentScanJob(ctx, newInput())).NoError(t)
+		gt.V(t, job).NotNil()
+		gt.V(t, job.ArchiveURL).Equal("https://example.com/archive.zip")
+		gt.V(t, job.Metadata.CommitID).Equal(defaultTestCommitID)
+		gt.A(t, job.ScanConfig.Exclude).Equal([]string{"vendor"})
+		gt.V(t, job.RunID).NotEqual(types.ScanRunID(""))
+
+		result := gt.R1(uc.ScanAgentJob(ctx, job)).NoError(t)
+		gt.V(t, result.Report).NotNil()
+		gt.V(t, result.Coverage).NotNil()
+
+		gt.NoError(t, uc.CompleteAgentScanJob(ctx, job, result))
+
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusSuccess)
+		gt.V(t, string(branch.LastCommitSHA)).Equal(defaultTestCommitID)
+
+		run := gt.R1(uc.GetScanRun(ctx, job.RunID)).NoError(t)
+		gt.V(t, run.Status).Equal(types.ScanRunSucceeded)
+		gt.V(t, run.ScanID).NotEqual(types.ScanID(""))
+
+		t.Run("job of scanned commit is not prepared", func(t *testing.T) {
+			job := gt.R1(uc.PrepareAgentScanJob(ctx, newInput())).NoError(t)
+			gt.V(t, job).Nil()
+		})
+	})
+
+	t.Run("failure reported by agent is recorded", func(t *testing.T) {
+		uc, memRepo := setup(t)
+		job := gt.R1(uc.PrepareAgentScanJob(ctx, newInput())).NoError(t)
+
+		gt.NoError(t, uc.CompleteAgentScanJob(ctx, job, &model.AgentScanResult{Error: "trivy crashed"}))
+
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+		gt.S(t, branch.LastError).Contains("trivy crashed")
+
+		run := gt.R1(uc.GetScanRun(ctx, job.RunID)).NoError(t)
+		gt.V(t, run.Status).Equal(types.ScanRunFailed)
+	})
+
+	t.Run("GitHub App is required to prepare job", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithScanRepository(memory.New())))
+		_, err := uc.PrepareAgentScanJob(ctx, newInput())
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("job of invalid archive URL is rejected", func(t *testing.T) {
+		uc, _ := setup(t)
+		_, err := uc.ScanAgentJob(ctx, &model.AgentScanJob{ID: "job-1", ArchiveURL: "file:///etc/passwd"})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+}
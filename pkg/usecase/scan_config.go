@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// loadScanConfig returns the scan config of the repository. ScanConfigFileName in codeDir takes precedence over the config stored in ScanRepository. It returns nil if neither exists.
+func (x *UseCase) loadScanConfig(ctx context.Context, codeDir string, meta *model.GitHubMetadata) (*model.ScanConfig, error) {
+	cfg, err := readScanConfigFile(filepath.Join(codeDir, model.ScanConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		logging.From(ctx).Info("scan config loaded from repository", slog.Any("paths", cfg.Paths), slog.Any("exclude", cfg.Exclude))
+		return cfg, nil
+	}
+
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil || meta == nil || meta.Owner == "" || meta.RepoName == "" {
+		return nil, nil
+	}
+	stored, err := getStoredRepository(ctx, scanRepo, types.NewGitHubRepoID(meta.Owner, meta.RepoName))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.ScanConfig.IsEmpty() {
+		return nil, nil
+	}
+	logging.From(ctx).Info("scan config loaded from ScanRepository", slog.Any("paths", stored.ScanConfig.Paths), slog.Any("exclude", stored.ScanConfig.Exclude))
+	return stored.ScanConfig, nil
+}
+
+// readScanConfigFile returns nil if the file does not exist. Unknown fields are rejected so that a typo does not silently scan the whole repository.
+func readScanConfigFile(fpath string) (*model.ScanConfig, error) {
+	raw, err := os.ReadFile(filepath.Clean(fpath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to read scan config", goerr.V("path", fpath))
+	}
+
+	var cfg model.ScanConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, goerr.Wrap(err, "failed to parse scan config", goerr.V("file", model.ScanConfigFileName))
+	}
+
+	cfg.Normalize()
+	if err := cfg.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid scan config", goerr.V("file", model.ScanConfigFileName))
+	}
+	return &cfg, nil
+}
+
+// newScanTarget resolves the config into paths skipped by scanners. Scanners have no option to scan only some paths, so entries outside Paths are listed from codeDir and skipped. cfg can be nil to scan the whole directory.
+func newScanTarget(ctx context.Context, codeDir string, cfg *model.ScanConfig) (*model.ScanTarget, error) {
+	target := &model.ScanTarget{Dir: codeDir}
+	if cfg.IsEmpty() {
+		return target, nil
+	}
+
+	target.SkipDirs = append(target.SkipDirs, cfg.SkipDirPatterns()...)
+
+	if len(cfg.Paths) == 0 {
+		return target, nil
+	}
+
+	included := make(map[string]bool, len(cfg.Paths))
+	// parents are directories containing included paths, which are walked to list their other entries
+	parents := map[string]bool{".": true}
+	for _, p := range cfg.Paths {
+		included[p] = true
+		if _, err := os.Stat(filepath.Join(codeDir, filepath.FromSlash(p))); err != nil {
+			logging.From(ctx).Warn("path of scan config does not exist", slog.String("path", p))
+		}
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			parents[dir] = true
+		}
+	}
+
+	// Directories are walked in order so that the skipped paths are stable
+	dirs := make([]string, 0, len(parents))
+	for dir := range parents {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, parent := range dirs {
+		entries, err := os.ReadDir(filepath.Join(codeDir, filepath.FromSlash(parent)))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, goerr.Wrap(err, "failed to read directory to apply scan config", goerr.V("dir", parent))
+		}
+
+		for _, entry := range entries {
+			rel := path.Join(parent, entry.Name())
+			if included[rel] || parents[rel] {
+				continue
+			}
+			if entry.IsDir() {
+				target.SkipDirs = append(target.SkipDirs, rel)
+			} else {
+				target.SkipFiles = append(target.SkipFiles, rel)
+			}
+		}
+	}
+
+	return target, nil
+}
@@ -0,0 +1,152 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestScanAndInsertScanConfig(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    "test-owner",
+				RepoName: "test-repo",
+			},
+			Branch:   "main",
+			CommitID: "0000000000000000000000000000000000000000",
+		},
+	}
+
+	// newCodeDir creates a monorepo layout with the config file if given
+	newCodeDir := func(t *testing.T, configFile string) string {
+		dir := t.TempDir()
+		for _, d := range []string{"services/api/vendor", "services/web", "docs"} {
+			gt.NoError(t, os.MkdirAll(filepath.Join(dir, d), 0755))
+		}
+		for _, f := range []string{"go.mod", "services/api/go.mod", "services/README.md"} {
+			gt.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("x"), 0644))
+		}
+		if configFile != "" {
+			gt.NoError(t, os.WriteFile(filepath.Join(dir, ".octovy.yml"), []byte(configFile), 0644))
+		}
+		return dir
+	}
+
+	// run returns the arguments passed to Trivy before the scan target
+	run := func(t *testing.T, dir string, stored *model.ScanConfig) ([]string, error) {
+		ctx := context.Background()
+		var trivyArgs []string
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			trivyArgs = args
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`), 0644)
+				}
+			}
+			return nil
+		}
+
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:         "test-owner/test-repo",
+			Owner:      "test-owner",
+			Name:       "test-repo",
+			ScanConfig: stored,
+		}))
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy), infra.WithScanRepository(memRepo)))
+		if err := uc.ScanAndInsert(ctx, dir, meta); err != nil {
+			return nil, err
+		}
+		gt.V(t, trivyArgs[len(trivyArgs)-1]).Equal(dir)
+		return trivyArgs[:len(trivyArgs)-1], nil
+	}
+
+	t.Run("config file limits paths and excludes directories", func(t *testing.T) {
+		args, err := run(t, newCodeDir(t, "paths:\n  - services/api\nexclude:\n  - vendor/\n"), nil)
+		gt.NoError(t, err)
+		gt.A(t, args).Contains([]string{"--skip-dirs", "**/vendor"})
+		gt.A(t, args).Contains([]string{"--skip-dirs", "docs"})
+		gt.A(t, args).Contains([]string{"--skip-dirs", "services/web"})
+		gt.A(t, args).Contains([]string{"--skip-files", "go.mod"})
+		gt.A(t, args).Contains([]string{"--skip-files", "services/README.md"})
+		gt.A(t, args).Contains([]string{"--skip-files", ".octovy.yml"})
+		gt.A(t, args).NotContains([]string{"--skip-dirs", "services"})
+		gt.A(t, args).NotContains([]string{"--skip-dirs", "services/api"})
+	})
+
+	t.Run("config file takes precedence over stored config", func(t *testing.T) {
+		args, err := run(t, newCodeDir(t, "exclude: [docs]\n"), &model.ScanConfig{Exclude: []string{"services"}})
+		gt.NoError(t, err)
+		gt.A(t, args).Contains([]string{"--skip-dirs", "**/docs"})
+		gt.A(t, args).NotContains([]string{"--skip-dirs", "**/services"})
+	})
+
+	t.Run("stored config is used without config file", func(t *testing.T) {
+		args, err := run(t, newCodeDir(t, ""), &model.ScanConfig{Exclude: []string{"services/api/vendor"}})
+		gt.NoError(t, err)
+		gt.A(t, args).Contains([]string{"--skip-dirs", "services/api/vendor"})
+	})
+
+	t.Run("no config scans whole directory", func(t *testing.T) {
+		args, err := run(t, newCodeDir(t, ""), nil)
+		gt.NoError(t, err)
+		gt.A(t, args).NotContains([]string{"--skip-dirs"})
+		gt.A(t, args).NotContains([]string{"--skip-files"})
+	})
+
+	t.Run("invalid config file fails the scan", func(t *testing.T) {
+		_, err := run(t, newCodeDir(t, "path:\n  - services/api\n"), nil)
+		gt.Error(t, err)
+		gt.S(t, err.Error()).Contains("failed to parse scan config")
+
+		_, err = run(t, newCodeDir(t, "paths: [../other]\n"), nil)
+		gt.Error(t, err)
+	})
+}
+
+type fakeScanner struct {
+	targets []*model.ScanTarget
+}
+
+func (x *fakeScanner) Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error) {
+	x.targets = append(x.targets, target)
+	return &trivy.Report{SchemaVersion: 2, ArtifactName: target.Dir}, nil
+}
+
+func TestScanAndInsertWithScanner(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	gt.NoError(t, os.MkdirAll(filepath.Join(dir, "services/api"), 0755))
+	gt.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, ".octovy.yml"), []byte("paths: [services]\nexclude: [vendor]\n"), 0644))
+
+	mockTrivy := &mockTrivyClient{}
+	scanner := &fakeScanner{}
+	uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy), infra.WithScanRepository(memory.New())), usecase.WithScanner(scanner))
+
+	gt.NoError(t, uc.ScanAndInsert(ctx, dir, model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}))
+
+	// Trivy is replaced by the scanner, which receives paths skipped by the scan config
+	gt.V(t, mockTrivy.lastArgs).Equal(nil)
+	gt.A(t, scanner.targets).Length(1)
+	gt.V(t, scanner.targets[0].Dir).Equal(dir)
+	gt.A(t, scanner.targets[0].SkipDirs).Contains([]string{"**/vendor"})
+	gt.A(t, scanner.targets[0].SkipDirs).Contains([]string{"docs"})
+	gt.A(t, scanner.targets[0].SkipFiles).Contains([]string{".octovy.yml"})
+}
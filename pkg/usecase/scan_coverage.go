@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// newScanCoverage compares manifest files in the scanned directory with the report. A failure to walk the directory is logged and nil is returned, because the coverage is supplementary to the scan result.
+func newScanCoverage(ctx context.Context, target *model.ScanTarget, report *trivy.Report) *model.ScanCoverage {
+	manifests, err := listManifests(target)
+	if err != nil {
+		logging.From(ctx).Warn("failed to list manifest files for scan coverage", slog.Any("error", err))
+		return nil
+	}
+
+	coverage := model.NewScanCoverage(report, manifests)
+	coverage.IgnoreFile = target.IgnoreFile
+	// The ignore file is given to the scanner as a path in the temporary directory, which is recorded relative to the repository
+	for _, finding := range coverage.Suppressed {
+		if rel, err := filepath.Rel(target.Dir, finding.Source); err == nil && filepath.IsLocal(rel) {
+			finding.Source = filepath.ToSlash(rel)
+		}
+	}
+	if coverage.SuppressedFindings > 0 {
+		logging.From(ctx).Info("findings suppressed",
+			slog.Int("suppressed", coverage.SuppressedFindings),
+			slog.String("ignore_file", target.IgnoreFile),
+		)
+	}
+	if n := coverage.UnscannedManifests(); n > 0 {
+		logging.From(ctx).Info("manifest files not scanned",
+			slog.Int("unscanned", n),
+			slog.Int("manifests", coverage.Manifests),
+			slog.Any("files", coverage.Unscanned),
+		)
+	}
+	return coverage
+}
+
+// listManifests returns manifest files relative to the target directory, except paths skipped by the target. Directories which scanners never report as targets, such as .git and node_modules, are not walked.
+func listManifests(target *model.ScanTarget) ([]string, error) {
+	var manifests []string
+	err := filepath.WalkDir(target.Dir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(target.Dir, fpath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "node_modules" || matchSkipPaths(rel, target.SkipDirs) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() && model.IsManifestFile(rel) && !matchSkipPaths(rel, target.SkipFiles) {
+			manifests = append(manifests, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to walk scanned directory", goerr.V("dir", target.Dir))
+	}
+	return manifests, nil
+}
+
+// matchSkipPaths returns true if rel matches one of skipped paths. A pattern starting with "**/" matches the name at any depth.
+func matchSkipPaths(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if name, ok := strings.CutPrefix(pattern, "**/"); ok {
+			if matched, _ := path.Match(name, path.Base(rel)); matched {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
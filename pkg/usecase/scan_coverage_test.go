@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+// reportScanner returns the report for any target
+type reportScanner struct {
+	report *trivy.Report
+}
+
+func (x *reportScanner) Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error) {
+	return x.report, nil
+}
+
+func TestScanAndInsertCoverage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, f := range []string{
+		"services/api/go.mod",
+		"services/api/yarn.lock",
+		"services/api/vendor/go.mod",
+		"services/api/node_modules/lib/package-lock.json",
+		"docs/go.mod",
+		"services/api/main.go",
+	} {
+		gt.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755))
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("x"), 0644))
+	}
+	gt.NoError(t, os.WriteFile(filepath.Join(dir, ".octovy.yml"), []byte("paths: [services]\nexclude: [vendor]\n"), 0644))
+
+	scanner := &reportScanner{report: &trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  dir,
+		Results: trivy.Results{
+			{Target: "services/api/go.mod", Class: "lang-pkgs", Type: "gomod"},
+			{Target: "services/api/main.go", Class: "secret"},
+		},
+	}}
+
+	var inserted *model.ScanRawRecord
+	mockBQ := &mock.BigQueryMock{
+		GetMetadataFunc: func(ctx context.Context) (*bigquery.TableMetadata, error) {
+			return nil, nil
+		},
+		CreateTableFunc: func(ctx context.Context, md *bigquery.TableMetadata) error {
+			return nil
+		},
+		InsertFunc: func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+			inserted = data.(*model.ScanRawRecord)
+			return nil
+		},
+	}
+	uc := usecase.New(infra.New(infra.WithBigQuery(mockBQ)), usecase.WithScanner(scanner))
+
+	gt.NoError(t, uc.ScanAndInsert(ctx, dir, model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+	}))
+
+	// Manifests outside paths, in excluded directories and in node_modules are not counted
+	coverage := inserted.Coverage
+	gt.NotEqual(t, coverage, nil)
+	gt.V(t, coverage.Manifests).Equal(2)
+	gt.V(t, coverage.ScannedManifests).Equal(1)
+	gt.A(t, coverage.Unscanned).Equal([]string{"services/api/yarn.lock"})
+	gt.A(t, coverage.Targets).Length(2)
+	gt.V(t, *coverage.Targets[0]).Equal(model.ScanTargetStats{Class: "lang-pkgs", Type: "gomod", Count: 1})
+	gt.V(t, *coverage.Targets[1]).Equal(model.ScanTargetStats{Class: "secret", Count: 1})
+}
@@ -3,52 +3,72 @@ package usecase
 import (
 	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
-	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
 	"github.com/m-mizutani/octovy/pkg/utils/safe"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ScanGitHubRepoRemote scans a GitHub repository with parameter validation and completion.
 // It supports two modes:
-// 1. Full specification mode: all parameters (owner, repo, commit/branch, installID) are provided
+// 1. Full specification mode: all parameters (owner, repo, commit/branch/tag, installID) are provided
 // 2. DB completion mode: fetch missing parameters from repository (requires ScanRepository)
 func (x *UseCase) ScanGitHubRepoRemote(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) error {
+	scanInput, err := x.PrepareScanGitHubRepo(ctx, input)
+	if err != nil {
+		return err
+	}
+	return x.ScanGitHubRepo(ctx, scanInput)
+}
+
+// PrepareScanGitHubRepo validates and completes input in the same way as ScanGitHubRepoRemote and returns the input of ScanGitHubRepo without scanning, e.g. to run the scan in background.
+func (x *UseCase) PrepareScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*model.ScanGitHubRepoInput, error) {
+	normalized := *input
+	normalized.Normalize()
+	input = &normalized
+
 	// Validate mutually exclusive parameters
-	if input.Commit != "" && input.Branch != "" {
-		return goerr.Wrap(types.ErrInvalidOption, "commit and branch cannot be specified at the same time")
+	if err := input.ValidateRef(); err != nil {
+		return nil, err
 	}
 
 	// Determine operation mode
-	isFullSpecMode := input.InstallID != 0 && (input.Commit != "" || input.Branch != "")
+	isFullSpecMode := input.InstallID != 0 && (input.Commit != "" || input.Branch != "" || input.Tag != "")
 
+	var scanInput *model.ScanGitHubRepoInput
+	var err error
 	if isFullSpecMode {
-		scanInput, err := x.prepareScanInputFullSpec(ctx, input)
-		if err != nil {
-			return err
-		}
-		return x.ScanGitHubRepo(ctx, scanInput)
+		scanInput, err = x.prepareScanInputFullSpec(ctx, input)
+	} else {
+		// DB completion mode
+		scanInput, err = x.prepareScanInputDBCompletion(ctx, input)
 	}
-
-	// DB completion mode
-	scanInput, err := x.prepareScanInputDBCompletion(ctx, input)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return x.ScanGitHubRepo(ctx, scanInput)
+	scanInput.Trigger = input.Trigger
+	return scanInput, nil
 }
 
 // prepareScanInputFullSpec prepares ScanGitHubRepoInput for full specification mode
@@ -58,6 +78,7 @@ func (x *UseCase) prepareScanInputFullSpec(ctx context.Context, input *model.Sca
 		"repo", input.Repo,
 		"commit", input.Commit,
 		"branch", input.Branch,
+		"tag", input.Tag,
 		"install_id", input.InstallID,
 	)
 
@@ -77,6 +98,19 @@ func (x *UseCase) prepareScanInputFullSpec(ctx context.Context, input *model.Sca
 		commitID = resolvedCommit
 	}
 
+	// Resolve commit ID from tag if needed
+	if input.Tag != "" {
+		if x.clients.GitHubApp() == nil {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App client is required to resolve tag to commit")
+		}
+
+		resolvedCommit, err := x.resolveTagToCommit(ctx, input.Owner, input.Repo, input.Tag, input.InstallID)
+		if err != nil {
+			return nil, err
+		}
+		commitID = resolvedCommit
+	}
+
 	return &model.ScanGitHubRepoInput{
 		GitHubMetadata: model.GitHubMetadata{
 			GitHubCommit: model.GitHubCommit{
@@ -89,8 +123,10 @@ func (x *UseCase) prepareScanInputFullSpec(ctx context.Context, input *model.Sca
 				Branch:   branchName,
 			},
 			InstallationID: int64(input.InstallID),
+			Tag:            input.Tag,
 		},
 		InstallID: input.InstallID,
+		Force:     input.Force,
 	}, nil
 }
 
@@ -105,10 +141,11 @@ func (x *UseCase) prepareScanInputDBCompletion(ctx context.Context, input *model
 		"owner", input.Owner,
 		"repo", input.Repo,
 		"branch", input.Branch,
+		"tag", input.Tag,
 	)
 
 	// Get repository information from database
-	repoID := types.GitHubRepoID(fmt.Sprintf("%s/%s", input.Owner, input.Repo))
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
 	repoInfo, err := x.clients.ScanRepository().GetRepository(ctx, repoID)
 	if err != nil {
 		return nil, goerr.Wrap(err, "repository not found in database",
@@ -117,15 +154,41 @@ func (x *UseCase) prepareScanInputDBCompletion(ctx context.Context, input *model
 		)
 	}
 
+	// Tags are not stored in the database, so a tag is always resolved via GitHub API with the installation of the repository
+	if input.Tag != "" {
+		if x.clients.GitHubApp() == nil || repoInfo.InstallationID == 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App client and installation ID of the repository are required to resolve tag to commit",
+				goerr.V("owner", input.Owner),
+				goerr.V("repo", input.Repo),
+				goerr.V("tag", input.Tag),
+			)
+		}
+		full := *input
+		full.InstallID = types.GitHubAppInstallID(repoInfo.InstallationID)
+		return x.prepareScanInputFullSpec(ctx, &full)
+	}
+
 	// Determine which branch to use
 	branchName := types.BranchName(input.Branch)
 	if branchName == "" {
 		branchName = repoInfo.DefaultBranch
 	}
 
-	// Get branch information from database
+	// Get branch information from database. A repository seeded by an installation event has no branch until its first scan, so the head commit is resolved via GitHub API instead.
+	var commitID types.CommitSHA
 	branchInfo, err := x.clients.ScanRepository().GetBranch(ctx, repoID, branchName)
-	if err != nil {
+	switch {
+	case err == nil:
+		commitID = branchInfo.LastCommitSHA
+
+	case errors.Is(err, repository.ErrNotFound) && x.clients.GitHubApp() != nil && repoInfo.InstallationID != 0:
+		resolved, err := x.resolveBranchToCommit(ctx, input.Owner, input.Repo, string(branchName), types.GitHubAppInstallID(repoInfo.InstallationID))
+		if err != nil {
+			return nil, err
+		}
+		commitID = types.CommitSHA(resolved)
+
+	default:
 		return nil, goerr.Wrap(err, "branch not found in database",
 			goerr.V("owner", input.Owner),
 			goerr.V("repo", input.Repo),
@@ -137,7 +200,7 @@ func (x *UseCase) prepareScanInputDBCompletion(ctx context.Context, input *model
 		"owner", input.Owner,
 		"repo", input.Repo,
 		"branch", branchName,
-		"commit", branchInfo.LastCommitSHA,
+		"commit", commitID,
 		"installation_id", repoInfo.InstallationID,
 	)
 
@@ -149,12 +212,13 @@ func (x *UseCase) prepareScanInputDBCompletion(ctx context.Context, input *model
 					Owner:    input.Owner,
 					RepoName: input.Repo,
 				},
-				CommitID: string(branchInfo.LastCommitSHA),
+				CommitID: string(commitID),
 				Branch:   string(branchName),
 			},
 			InstallationID: repoInfo.InstallationID,
 		},
 		InstallID: types.GitHubAppInstallID(repoInfo.InstallationID),
+		Force:     input.Force,
 	}, nil
 }
 
@@ -211,119 +275,545 @@ func (x *UseCase) resolveBranchToCommit(ctx context.Context, owner, repo, branch
 	return branchInfo.Commit.SHA, nil
 }
 
+// resolveTagOfInput sets the commit of the tag to a scan of a tag given without commit, e.g. of a release event
+func (x *UseCase) resolveTagOfInput(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+	if input.Tag == "" || input.CommitID != "" || input.Source.SourceKind() != types.SourceGitHub {
+		return nil
+	}
+	if x.clients.GitHubApp() == nil {
+		return goerr.Wrap(types.ErrInvalidOption, "GitHub App client is required to resolve tag to commit")
+	}
+
+	commitID, err := x.resolveTagToCommit(ctx, input.Owner, input.RepoName, input.Tag, input.InstallID)
+	if err != nil {
+		return err
+	}
+	input.CommitID = commitID
+	return nil
+}
+
+// maxTagDepth limits dereference of tags pointing to other tags
+const maxTagDepth = 8
+
+// resolveTagToCommit resolves a tag name to a commit SHA using GitHub API. An annotated tag, e.g. created with a release, refers to a tag object, which is dereferenced to the commit.
+func (x *UseCase) resolveTagToCommit(ctx context.Context, owner, repo, tag string, installID types.GitHubAppInstallID) (string, error) {
+	httpClient, err := x.clients.GitHubApp().HTTPClient(installID)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create GitHub HTTP client")
+	}
+
+	object, err := getGitObject(ctx, httpClient, fmt.Sprintf("https://api.github.com/repos/%s/%s/git/ref/tags/%s", owner, repo, tag))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to get tag reference", goerr.V("owner", owner), goerr.V("repo", repo), goerr.V("tag", tag))
+	}
+
+	for depth := 0; object.Type == "tag"; depth++ {
+		if depth >= maxTagDepth {
+			return "", goerr.Wrap(types.ErrInvalidGitHubData, "too deeply nested tag", goerr.V("owner", owner), goerr.V("repo", repo), goerr.V("tag", tag))
+		}
+		object, err = getGitObject(ctx, httpClient, fmt.Sprintf("https://api.github.com/repos/%s/%s/git/tags/%s", owner, repo, object.SHA))
+		if err != nil {
+			return "", goerr.Wrap(err, "failed to get tag object", goerr.V("owner", owner), goerr.V("repo", repo), goerr.V("tag", tag))
+		}
+	}
+	if object.Type != "commit" {
+		return "", goerr.Wrap(types.ErrInvalidGitHubData, "tag does not refer to a commit",
+			goerr.V("owner", owner),
+			goerr.V("repo", repo),
+			goerr.V("tag", tag),
+			goerr.V("type", object.Type),
+		)
+	}
+
+	logging.From(ctx).Info("Resolved commit ID from tag",
+		"tag", tag,
+		"commit_id", object.SHA,
+	)
+
+	return object.SHA, nil
+}
+
+// gitObject is the object referred by a Git reference or a tag object of GitHub API
+type gitObject struct {
+	SHA  string `json:"sha"`
+	Type string `json:"type"`
+}
+
+// getGitObject gets a Git reference or a tag object from GitHub API and returns the object it refers to
+func getGitObject(ctx context.Context, httpClient *http.Client, apiURL string) (*gitObject, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create request for Git object")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send request for Git object", goerr.V("url", apiURL))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, goerr.Wrap(types.ErrInvalidGitHubData, "failed to get Git object",
+			goerr.V("url", apiURL),
+			goerr.V("status", resp.StatusCode),
+			goerr.V("body", string(body)),
+		)
+	}
+
+	var ref struct {
+		Object gitObject `json:"object"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ref); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse Git object", goerr.V("url", apiURL))
+	}
+	return &ref.Object, nil
+}
+
 // ScanGitHubRepo is a usecase to download a source code from GitHub and scan it with Trivy. Using GitHub App credentials to download a private repository, then the app should be installed to the repository and have read access.
+// If input.Source is given, the source code is fetched from there instead of GitHub and stored with the metadata of the input. See fetcher.
 // After scanning, the result is stored to the database. The temporary files are removed after the scan.
+// The scan is tracked by the ScanRun of input.RunID, or a new run if it is empty, when ScanRepository is configured.
 func (x *UseCase) ScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) error {
+	_, err := x.runScanGitHubRepo(ctx, input)
+	return err
+}
+
+// runScanGitHubRepo is ScanGitHubRepo returning the ID of the stored scan, or empty if the commit has already been scanned
+func (x *UseCase) runScanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) (types.ScanID, error) {
+	ctx, span := tracing.Start(ctx, "scan", trace.WithAttributes(
+		attribute.String("octovy.repository", input.Owner+"/"+input.RepoName),
+		attribute.String("octovy.branch", input.Branch),
+		attribute.String("octovy.commit", input.CommitID),
+		attribute.String("octovy.trigger", string(input.Trigger)),
+	))
+
+	run := x.startScanRun(ctx, input)
+	scanID, err := x.scanGitHubRepo(ctx, input)
+	x.finishScanRun(ctx, run, &input.GitHubMetadata, scanID, err)
+
+	span.SetAttributes(attribute.String("octovy.scan_id", string(scanID)))
+	tracing.End(span, err)
+	return scanID, err
+}
+
+// scanGitHubRepo is ScanGitHubRepo without tracking of the run. It returns the ID of the stored scan, or empty if the commit has already been scanned.
+func (x *UseCase) scanGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput) (types.ScanID, error) {
+	startedAt := logging.CtxTime(ctx)
+	input.Normalize()
+	if err := x.resolveTagOfInput(ctx, input); err != nil {
+		return "", err
+	}
 	if err := input.Validate(); err != nil {
-		return err
+		return "", err
 	}
 
+	// A push and a pull request event of the same commit often arrive at once. The later one waits for the lock, and then is skipped by the scan cache below if it is a push, or reports the report of the first one if it is a pull request, so that the commit is scanned once. A pull request waiting for a scan by another instance scans the commit again because reports are kept in memory of the process.
+	lockCtx, lockSpan := tracing.Start(ctx, "scan.lock")
+	unlock, err := x.clients.ScanLock().Lock(lockCtx, scannedReportKey(&input.GitHubMetadata))
+	tracing.End(lockSpan, err)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to lock scan of commit",
+			goerr.V("owner", input.Owner),
+			goerr.V("repo", input.RepoName),
+			goerr.V("commit", input.CommitID),
+		)
+	}
+	defer unlock()
+
+	// The severity threshold is evaluated against a fresh report, so the scan cache is not used with it. A pull request is not skipped even if its head commit has been scanned, e.g. by the push of its branch, so that the result, the assessment and the check runs of the pull request are reported.
+	useCache := !input.Force && x.failOnSeverity == ""
+	if useCache && input.PullRequest != nil {
+		if scanned := x.scannedReports.get(ctx, &input.GitHubMetadata); scanned != nil {
+			logging.From(ctx).Info("commit is already scanned, report its result for pull request",
+				"owner", input.Owner,
+				"repo", input.RepoName,
+				"pull_request", input.PullRequest.Number,
+				"commit", input.CommitID,
+			)
+			return x.insertScanReport(ctx, input.GitHubMetadata, scanned.report, scanned.coverage, nil, scanned.archive, startedAt)
+		}
+	}
+	if useCache && input.PullRequest == nil && x.isCommitAlreadyScanned(ctx, &input.GitHubMetadata) {
+		logging.From(ctx).Info("commit is already scanned, skip scanning",
+			"owner", input.Owner,
+			"repo", input.RepoName,
+			"branch", input.Branch,
+			"commit", input.CommitID,
+		)
+		return "", nil
+	}
+
+	fetcher, err := x.fetcher(input.Source.SourceKind())
+	if err != nil {
+		return "", err
+	}
+	incremental := x.incrementalScanOf(ctx, input)
+
 	// Extract zip file to local temp directory
 	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("octovy.%s.%s.%s.*", input.Owner, input.RepoName, input.CommitID))
 	if err != nil {
-		return goerr.Wrap(err, "failed to create temp directory for zip file")
+		return "", goerr.Wrap(err, "failed to create temp directory for zip file")
 	}
 	defer safe.RemoveAll(tmpDir)
 
-	if err := x.downloadGitHubRepo(ctx, input, tmpDir); err != nil {
-		return err
+	fetchCtx, fetchSpan := tracing.Start(ctx, "scan.fetch", trace.WithAttributes(
+		attribute.String("octovy.source", string(input.Source.SourceKind())),
+	))
+	fetched, err := fetcher.Fetch(fetchCtx, input, tmpDir)
+	tracing.End(fetchSpan, err)
+	if err != nil {
+		x.recordScanFailure(ctx, &input.GitHubMetadata, err)
+		return "", err
+	}
+
+	return x.scanAndInsert(ctx, fetched.Dir, input.GitHubMetadata, incremental, fetched.Archive, startedAt)
+}
+
+// ScanGitHubRepoStateless downloads and scans a GitHub repository and returns the Trivy report without storing it anywhere. It requires only the GitHub App and Trivy clients. If the installation ID is not given, it is looked up by the owner.
+func (x *UseCase) ScanGitHubRepoStateless(ctx context.Context, input *model.ScanGitHubRepoRemoteInput) (*trivy.Report, error) {
+	normalized := *input
+	normalized.Normalize()
+	input = &normalized
+
+	if err := input.ValidateRef(); err != nil {
+		return nil, err
+	}
+	if input.Commit == "" && input.Branch == "" && input.Tag == "" {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "commit, branch or tag is required in stateless mode")
+	}
+	if x.clients.GitHubApp() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App client is required in stateless mode")
+	}
+
+	remote := *input
+	if remote.InstallID == 0 {
+		installID, err := x.clients.GitHubApp().GetInstallationIDForOwner(ctx, input.Owner)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get installation ID for owner", goerr.V("owner", input.Owner))
+		}
+		remote.InstallID = installID
+	}
+
+	scanInput, err := x.prepareScanInputFullSpec(ctx, &remote)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("octovy.%s.%s.%s.*", scanInput.Owner, scanInput.RepoName, scanInput.CommitID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temp directory for zip file")
+	}
+	defer safe.RemoveAll(tmpDir)
+
+	fetcher, err := x.fetcher(types.SourceGitHub)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := fetcher.Fetch(ctx, scanInput, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := x.loadScanConfig(ctx, fetched.Dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	report, _, err := x.scanDirectory(ctx, fetched.Dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logging.From(ctx).Info("stateless scan finished", "owner", scanInput.Owner, "repo", scanInput.RepoName, "commit", scanInput.CommitID)
+
+	return report, nil
+}
+
+// isCommitAlreadyScanned returns true if the branch of the metadata has been successfully scanned with the same commit. It always returns false when ScanRepository is not configured because there is no record to look up.
+func (x *UseCase) isCommitAlreadyScanned(ctx context.Context, meta *model.GitHubMetadata) bool {
+	repo := x.clients.ScanRepository()
+	if repo == nil || meta.Branch == "" {
+		return false
+	}
+
+	repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+	branch, err := repo.GetBranch(ctx, repoID, types.BranchName(meta.Branch))
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			logging.From(ctx).Warn("failed to get branch for scan cache, continue scanning",
+				"repo_id", repoID,
+				"branch", meta.Branch,
+				"error", err,
+			)
+		}
+		return false
+	}
+
+	return branch.Status == types.ScanStatusSuccess && string(branch.LastCommitSHA) == meta.CommitID
+}
+
+// maxScanErrorLength limits the error summary stored in a branch so that a long error, e.g. including Trivy output, does not bloat the document
+const maxScanErrorLength = 1024
+
+// recordScanFailure marks the branch of the metadata as failed so that operators can find branches which have not been scanned successfully. The last successful scan of the branch is kept as is. The repository is registered if it has never been scanned. A failure of recording is logged because the scan error is more important to return.
+func (x *UseCase) recordScanFailure(ctx context.Context, meta *model.GitHubMetadata, scanErr error) {
+	repo := x.clients.ScanRepository()
+	if repo == nil || meta.Branch == "" {
+		return
+	}
+
+	now := logging.CtxTime(ctx).UTC()
+	repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+	logger := logging.From(ctx).With("repo_id", repoID, "branch", meta.Branch)
+
+	stored, err := getStoredRepository(ctx, repo, repoID)
+	if err != nil {
+		logger.Error("failed to record scan failure", "error", err)
+		return
+	}
+	if stored == nil {
+		if err := repo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:             repoID,
+			Owner:          meta.Owner,
+			Name:           meta.RepoName,
+			DefaultBranch:  types.BranchName(meta.DefaultBranch),
+			InstallationID: meta.InstallationID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}); err != nil {
+			logger.Error("failed to record scan failure", "error", err)
+			return
+		}
 	}
 
-	return x.ScanAndInsert(ctx, tmpDir, input.GitHubMetadata)
+	branch, err := getPreviousBranch(ctx, repo, repoID, types.BranchName(meta.Branch))
+	if err != nil {
+		logger.Error("failed to record scan failure", "error", err)
+		return
+	}
+	if branch == nil {
+		branch = &model.Branch{
+			Name:      types.BranchName(meta.Branch),
+			CreatedAt: now,
+		}
+	}
+	branch.Status = types.ScanStatusFailure
+	branch.LastError = summarizeScanError(scanErr)
+	branch.LastErrorAt = now
+	branch.UpdatedAt = now
+
+	if err := repo.CreateOrUpdateBranch(ctx, repoID, branch); err != nil {
+		logger.Error("failed to record scan failure", "error", err)
+		return
+	}
+	logger.Info("scan failure recorded", "commit", meta.CommitID, "scan_error", branch.LastError)
 }
 
-// ScanAndInsert scans a directory with Trivy and inserts the result to BigQuery
+func summarizeScanError(err error) string {
+	msg := err.Error()
+	if len(msg) <= maxScanErrorLength {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxScanErrorLength], "") + "..."
+}
+
+// ScanAndInsert scans a directory with Trivy and inserts the result to BigQuery. Paths of the directory are limited by the scan config of the repository, see loadScanConfig. If WithFailOnSeverity is set, it returns an error when the result has findings at or above the threshold after severity overrides of the owner.
 func (x *UseCase) ScanAndInsert(ctx context.Context, dir string, meta model.GitHubMetadata) error {
-	report, err := x.scanDirectory(ctx, dir)
+	_, err := x.scanAndInsert(ctx, dir, meta, nil, nil, logging.CtxTime(ctx))
+	return err
+}
+
+// scanAndInsert is ScanAndInsert limited to paths of the incremental scan if it is not nil, returning the ID of the stored scan. archive is the zip archive the directory is extracted from, or nil. startedAt is when the scan started including download of the source code.
+func (x *UseCase) scanAndInsert(ctx context.Context, dir string, meta model.GitHubMetadata, incremental *model.IncrementalScan, archive *model.ScanArchive, startedAt time.Time) (types.ScanID, error) {
+	cfg, err := x.loadScanConfig(ctx, dir, &meta)
 	if err != nil {
-		return err
+		x.recordScanFailure(ctx, &meta, err)
+		return "", err
+	}
+	if incremental != nil {
+		if limited := incremental.ScanConfig(cfg); limited != nil {
+			cfg = limited
+			incremental = &model.IncrementalScan{Paths: limited.Paths, ChangedFiles: incremental.ChangedFiles}
+		} else {
+			logging.From(ctx).Info("no changed file in paths of scan config, scan all of them", "paths", incremental.Paths)
+			incremental = nil
+		}
+	}
+
+	report, coverage, err := x.scanDirectory(ctx, dir, cfg)
+	if err != nil {
+		x.recordScanFailure(ctx, &meta, err)
+		return "", err
 	}
 	logging.From(ctx).Info("scan finished", "owner", meta.Owner, "repo", meta.RepoName, "commit", meta.CommitID)
 
-	scanID, err := x.InsertScanResult(ctx, meta, *report)
+	scanID, err := x.insertScanReport(ctx, meta, report, coverage, incremental, archive, startedAt)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if incremental == nil {
+		x.scannedReports.put(ctx, &meta, report, coverage, archive)
+	}
+	if x.upgradePR != nil && incremental == nil && meta.PullRequest == nil && meta.Branch != "" && meta.Branch == meta.DefaultBranch {
+		x.createUpgradePullRequests(ctx, dir, meta, report)
 	}
-	logging.From(ctx).Info("scan result inserted", "scan_id", scanID)
 
-	return nil
+	if x.failOnSeverity != "" {
+		// The threshold applies to severities after overrides, as stored
+		overrides, err := x.loadSeverityOverrides(ctx, meta.Owner)
+		if err != nil {
+			return scanID, err
+		}
+		if count := model.CountFindingsAtOrAbove(overrides.ApplyToReport(report), x.failOnSeverity); count > 0 {
+			return scanID, goerr.New("findings at or above severity threshold detected",
+				goerr.V("threshold", x.failOnSeverity),
+				goerr.V("count", count),
+				goerr.V("scan_id", scanID),
+			)
+		}
+	}
+
+	return scanID, nil
 }
 
-func (x *UseCase) downloadGitHubRepo(ctx context.Context, input *model.ScanGitHubRepoInput, dstDir string) error {
-	zipURL, err := x.clients.GitHubApp().GetArchiveURL(ctx, &interfaces.GetArchiveURLInput{
-		Owner:     input.Owner,
-		Repo:      input.RepoName,
-		CommitID:  input.CommitID,
-		InstallID: input.InstallID,
-	})
+// insertScanReport stores the report of a scan with its coverage, incremental scan and archive, which can be nil, assesses it if the scan is of a dependency update pull request, and reports misconfigurations and vulnerabilities of a pull request as check runs if WithMisconfigCheckRun and WithVulnerabilityCheckRun are set
+func (x *UseCase) insertScanReport(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report, coverage *model.ScanCoverage, incremental *model.IncrementalScan, archive *model.ScanArchive, startedAt time.Time) (types.ScanID, error) {
+	scanID, err := x.insertScanResult(ctx, meta, *report, coverage, incremental, archive, startedAt)
 	if err != nil {
-		return err
+		return "", err
+	}
+	logging.From(ctx).Info("scan result inserted", "scan_id", scanID)
+
+	if meta.PullRequest != nil && model.IsDependencyUpdateBot(meta.PullRequest.Author.Login) {
+		x.assessDependencyUpdate(ctx, meta, report, incremental)
+	}
+	if meta.PullRequest != nil && x.misconfigCheckRun {
+		x.reportMisconfigCheckRun(ctx, meta, report)
+	}
+	if meta.PullRequest != nil && x.vulnCheckRun != nil {
+		x.reportVulnerabilityCheckRun(ctx, meta, report, incremental)
 	}
+	return scanID, nil
+}
 
-	// Download zip file
+// downloadArchive downloads the zip archive of the repository from zipURL and extracts it to dstDir
+func (x *UseCase) downloadArchive(ctx context.Context, zipURL *url.URL, meta *model.GitHubMetadata, dstDir string) (*model.ScanArchive, error) {
+	return x.archive.fetch(ctx, meta, dstDir, extractZipFile, func(w io.Writer) error {
+		return downloadZipFile(ctx, x.clients.HTTPClient(), zipURL, w, x.archive.limits.MaxDownloadSize)
+	})
+}
+
+// archiveConfig is how zip archives of source code are fetched, set by WithArchiveLimits and WithArchiveChecksum
+type archiveConfig struct {
+	limits   model.ArchiveLimits
+	checksum bool
+}
+
+// fetch writes a zip archive by write into a temp file and extracts it to dstDir by extract within the limits. It returns the size of the archive and its SHA-256 digest if checksum is enabled.
+func (x archiveConfig) fetch(ctx context.Context, meta *model.GitHubMetadata, dstDir string, extract func(ctx context.Context, src, dst string, limits model.ArchiveLimits) error, write func(w io.Writer) error) (*model.ScanArchive, error) {
 	tmpZip, err := os.CreateTemp("", fmt.Sprintf("octovy_code.%s.%s.%s.*.zip",
-		input.Owner, input.RepoName, input.CommitID,
+		meta.Owner, meta.RepoName, meta.CommitID,
 	))
 	if err != nil {
-		return goerr.Wrap(err, "failed to create temp file for zip file")
+		return nil, goerr.Wrap(err, "failed to create temp file for zip file")
 	}
 	defer safe.Remove(tmpZip.Name())
 
-	if err := downloadZipFile(ctx, x.clients.HTTPClient(), zipURL, tmpZip); err != nil {
-		return err
+	w := &archiveWriter{w: tmpZip, max: x.limits.MaxDownloadSize}
+	if x.checksum {
+		w.hash = sha256.New()
+	}
+	_, downloadSpan := tracing.Start(ctx, "archive.download")
+	err = write(w)
+	downloadSpan.SetAttributes(attribute.Int64("octovy.archive_size", w.size))
+	tracing.End(downloadSpan, err)
+	if err != nil {
+		safe.Close(tmpZip)
+		return nil, err
 	}
 	if err := tmpZip.Close(); err != nil {
-		return goerr.Wrap(err, "failed to close temp file for zip file")
+		return nil, goerr.Wrap(err, "failed to close temp file for zip file")
 	}
 
-	if err := extractZipFile(ctx, tmpZip.Name(), dstDir); err != nil {
-		return err
+	archive := &model.ScanArchive{Size: w.size}
+	if w.hash != nil {
+		archive.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
 	}
+	logging.From(ctx).Info("zip archive fetched",
+		"owner", meta.Owner,
+		"repo", meta.RepoName,
+		"commit", meta.CommitID,
+		"size", archive.Size,
+		"sha256", archive.SHA256,
+	)
 
-	return nil
-}
-
-// scanDirectory scans a directory with Trivy and returns the report
-func (x *UseCase) scanDirectory(ctx context.Context, codeDir string) (*trivy.Report, error) {
-	// Scan local directory
-	tmpResult, err := os.CreateTemp("", "octovy_result.*.json")
+	extractCtx, extractSpan := tracing.Start(ctx, "archive.extract")
+	err = extract(extractCtx, tmpZip.Name(), dstDir, x.limits)
+	tracing.End(extractSpan, err)
 	if err != nil {
-		return nil, goerr.Wrap(err, "failed to create temp file for scan result")
+		return nil, err
 	}
-	defer safe.Remove(tmpResult.Name())
 
-	if err := tmpResult.Close(); err != nil {
-		return nil, goerr.Wrap(err, "failed to close temp file for scan result")
+	return archive, nil
+}
+
+// archiveWriter writes a zip archive to the temp file. It fails as soon as the archive exceeds max, so that a huge archive does not fill the disk, and digests the archive if hash is set.
+type archiveWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	max  int64
+	size int64
+}
+
+func (x *archiveWriter) Write(p []byte) (int, error) {
+	if x.max > 0 && x.size+int64(len(p)) > x.max {
+		return 0, goerr.Wrap(types.ErrArchiveTooLarge, "zip archive exceeds download size limit",
+			goerr.V("limit", x.max),
+		)
 	}
 
-	if err := x.clients.Trivy().Run(ctx, []string{
-		"fs",
-		"--exit-code", "0",
-		"--no-progress",
-		"--format", "json",
-		"--output", tmpResult.Name(),
-		"--list-all-pkgs",
-		codeDir,
-	}); err != nil {
-		return nil, goerr.Wrap(err, "failed to scan local directory")
+	n, err := x.w.Write(p)
+	x.size += int64(n)
+	if x.hash != nil {
+		_, _ = x.hash.Write(p[:n])
 	}
+	return n, err
+}
 
-	report, err := LoadTrivyReportFromFile(ctx, tmpResult.Name())
+// scanDirectory scans a directory with the configured scanner, Trivy by default, and returns the report with its coverage. cfg can be nil to scan the whole directory. The coverage is nil if it can not be computed.
+func (x *UseCase) scanDirectory(ctx context.Context, codeDir string, cfg *model.ScanConfig) (*trivy.Report, *model.ScanCoverage, error) {
+	target, err := newScanTarget(ctx, codeDir, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	logging.From(ctx).Debug("Scan result saved", "result_file", tmpResult.Name())
+	// An ignore file of Trivy is used only by Trivy
+	scanner := x.scanner
+	if scanner == nil {
+		scanner = &trivyScanner{client: x.clients.Trivy(), scanners: x.trivyScanners, args: x.trivyArgs}
+		target.IgnoreFile = findTrivyIgnoreFile(ctx, codeDir)
+	}
 
-	return report, nil
+	scanCtx, span := tracing.Start(ctx, "scan.scanner")
+	report, err := scanner.Scan(scanCtx, target)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return report, newScanCoverage(ctx, target, report), nil
 }
 
 // ScanDirectoryForTest is exported for testing purposes
 func (x *UseCase) ScanDirectoryForTest(ctx context.Context, codeDir string) (*trivy.Report, error) {
-	return x.scanDirectory(ctx, codeDir)
+	report, _, err := x.scanDirectory(ctx, codeDir, nil)
+	return report, err
 }
 
-func downloadZipFile(ctx context.Context, httpClient infra.HTTPClient, zipURL *url.URL, w io.Writer) error {
+// downloadZipFile downloads a zip archive from zipURL into w. A response larger than maxSize by Content-Length is rejected before download, unless maxSize is 0.
+func downloadZipFile(ctx context.Context, httpClient infra.HTTPClient, zipURL *url.URL, w io.Writer, maxSize int64) error {
 	zipReq, err := http.NewRequestWithContext(ctx, http.MethodGet, zipURL.String(), nil)
 	if err != nil {
 		return goerr.Wrap(err, "failed to create request for zip file", goerr.V("url", zipURL))
@@ -344,6 +834,17 @@ func downloadZipFile(ctx context.Context, httpClient infra.HTTPClient, zipURL *u
 		)
 	}
 
+	if maxSize > 0 && zipResp.ContentLength > maxSize {
+		return goerr.Wrap(types.ErrArchiveTooLarge, "zip archive exceeds download size limit",
+			goerr.V("url", zipURL),
+			goerr.V("content_length", zipResp.ContentLength),
+			goerr.V("limit", maxSize),
+		)
+	}
+	if zipResp.ContentLength >= minProgressLogSize {
+		w = &progressWriter{w: w, ctx: ctx, url: zipURL, total: zipResp.ContentLength}
+	}
+
 	if _, err = io.Copy(w, zipResp.Body); err != nil {
 		return goerr.Wrap(err, "failed to write zip file",
 			goerr.V("url", zipURL),
@@ -354,16 +855,55 @@ func downloadZipFile(ctx context.Context, httpClient infra.HTTPClient, zipURL *u
 	return nil
 }
 
-func extractZipFile(ctx context.Context, src, dst string) error {
+// minProgressLogSize is the minimum Content-Length of a zip archive to log progress of the download. Downloads of smaller archives finish quickly.
+const minProgressLogSize = 64 << 20
+
+// progressLogStep is the percentage of the download between progress logs
+const progressLogStep = 25
+
+// progressWriter logs progress of a download of which Content-Length is known
+type progressWriter struct {
+	w       io.Writer
+	ctx     context.Context
+	url     *url.URL
+	total   int64
+	written int64
+	logged  int64
+}
+
+func (x *progressWriter) Write(p []byte) (int, error) {
+	n, err := x.w.Write(p)
+	x.written += int64(n)
+
+	percent := x.written * 100 / x.total
+	if step := percent / progressLogStep * progressLogStep; step > x.logged && step < 100 {
+		x.logged = step
+		logging.From(x.ctx).Info("downloading zip file",
+			"url", x.url.Redacted(),
+			"percent", step,
+			"written", x.written,
+			"total", x.total,
+		)
+	}
+	return n, err
+}
+
+// extractZipFile extracts a GitHub zipball, which has all files under the top-level directory named after the repository and the commit
+func extractZipFile(_ context.Context, src, dst string, limits model.ArchiveLimits) error {
 	zipFile, err := zip.OpenReader(src)
 	if err != nil {
 		return goerr.Wrap(err, "failed to open zip file", goerr.V("file", src))
 	}
 	defer safe.Close(zipFile)
 
+	limiter, err := newZipLimiter(zipFile.File, limits)
+	if err != nil {
+		return err
+	}
+
 	// Extract a source code zip file
 	for _, f := range zipFile.File {
-		if err := extractCode(ctx, f, dst); err != nil {
+		if err := extractZipEntry(f, dst, true, limiter); err != nil {
 			return err
 		}
 	}
@@ -371,12 +911,127 @@ func extractZipFile(ctx context.Context, src, dst string) error {
 	return nil
 }
 
-func extractCode(_ context.Context, f *zip.File, dst string) error {
+// extractArchiveFile extracts a zip archive of any source. The top-level directory is removed only if all files are in the same one, as a GitHub zipball or an archive created by `zip -r code.zip code/`.
+func extractArchiveFile(_ context.Context, src, dst string, limits model.ArchiveLimits) error {
+	zipFile, err := zip.OpenReader(src)
+	if err != nil {
+		return goerr.Wrap(err, "failed to open zip file", goerr.V("file", src))
+	}
+	defer safe.Close(zipFile)
+
+	limiter, err := newZipLimiter(zipFile.File, limits)
+	if err != nil {
+		return err
+	}
+
+	stripRoot := hasSingleRootDirectory(zipFile.File)
+	for _, f := range zipFile.File {
+		if err := extractZipEntry(f, dst, stripRoot, limiter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasSingleRootDirectory(files []*zip.File) bool {
+	root := ""
+	for _, f := range files {
+		normalized := strings.TrimLeft(strings.ReplaceAll(f.Name, "\\", "/"), "/")
+		first, _, nested := strings.Cut(normalized, "/")
+		if !nested && !f.FileInfo().IsDir() {
+			return false
+		}
+		if root != "" && root != first {
+			return false
+		}
+		root = first
+	}
+	return root != ""
+}
+
+// zipLimiter enforces ArchiveLimits while extracting an archive. Sizes in the zip headers are checked before extraction, and written bytes are counted during extraction because the headers can understate them.
+type zipLimiter struct {
+	limits  model.ArchiveLimits
+	written int64
+}
+
+func newZipLimiter(files []*zip.File, limits model.ArchiveLimits) (*zipLimiter, error) {
+	var count int
+	var total uint64
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		count++
+		total += f.UncompressedSize64
+
+		if limits.MaxFileSize > 0 && f.UncompressedSize64 > uint64(limits.MaxFileSize) {
+			return nil, goerr.Wrap(types.ErrArchiveTooLarge, "file in zip archive exceeds size limit",
+				goerr.V("path", f.Name),
+				goerr.V("size", f.UncompressedSize64),
+				goerr.V("limit", limits.MaxFileSize),
+			)
+		}
+	}
+
+	if limits.MaxFiles > 0 && count > limits.MaxFiles {
+		return nil, goerr.Wrap(types.ErrArchiveTooLarge, "zip archive exceeds file count limit",
+			goerr.V("count", count),
+			goerr.V("limit", limits.MaxFiles),
+		)
+	}
+	if limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize) {
+		return nil, goerr.Wrap(types.ErrArchiveTooLarge, "zip archive exceeds total size limit",
+			goerr.V("size", total),
+			goerr.V("limit", limits.MaxTotalSize),
+		)
+	}
+
+	return &zipLimiter{limits: limits}, nil
+}
+
+// copy copies a file of the archive from src to dst, and fails as soon as the file or the archive exceeds the size limits
+func (x *zipLimiter) copy(dst io.Writer, src io.Reader, name string) error {
+	// Read one more byte than allowed to detect excess
+	allowed := int64(-1)
+	if x.limits.MaxFileSize > 0 {
+		allowed = x.limits.MaxFileSize
+	}
+	if x.limits.MaxTotalSize > 0 && (allowed < 0 || x.limits.MaxTotalSize-x.written < allowed) {
+		allowed = x.limits.MaxTotalSize - x.written
+	}
+	if allowed >= 0 {
+		src = io.LimitReader(src, allowed+1)
+	}
+
+	n, err := io.Copy(dst, src)
+	x.written += n
+	if err != nil {
+		return goerr.Wrap(err, "failed to copy file content")
+	}
+
+	if x.limits.MaxFileSize > 0 && n > x.limits.MaxFileSize {
+		return goerr.Wrap(types.ErrArchiveTooLarge, "file in zip archive exceeds size limit",
+			goerr.V("path", name),
+			goerr.V("limit", x.limits.MaxFileSize),
+		)
+	}
+	if x.limits.MaxTotalSize > 0 && x.written > x.limits.MaxTotalSize {
+		return goerr.Wrap(types.ErrArchiveTooLarge, "zip archive exceeds total size limit",
+			goerr.V("limit", x.limits.MaxTotalSize),
+		)
+	}
+	return nil
+}
+
+// extractZipEntry extracts a file of the archive into dst within the limits of limiter
+func extractZipEntry(f *zip.File, dst string, stripRoot bool, limiter *zipLimiter) error {
 	if f.FileInfo().IsDir() {
 		return nil
 	}
 
-	target, err := stepDownDirectory(f.Name)
+	target, err := cleanZipPath(f.Name, stripRoot)
 	if err != nil {
 		return err
 	}
@@ -406,16 +1061,15 @@ func extractCode(_ context.Context, f *zip.File, dst string) error {
 	}
 	defer safe.Close(rc)
 
-	// #nosec
-	_, err = io.Copy(out, rc)
-	if err != nil {
-		return goerr.Wrap(err, "failed to copy file content")
-	}
-
-	return nil
+	return limiter.copy(out, rc, f.Name)
 }
 
 func stepDownDirectory(fpath string) (string, error) {
+	return cleanZipPath(fpath, true)
+}
+
+// cleanZipPath converts a path in a zip archive into a relative local path. The top-level directory is removed if stripRoot is true. It returns an empty string if nothing is left.
+func cleanZipPath(fpath string, stripRoot bool) (string, error) {
 	if fpath == "" {
 		return "", nil
 	}
@@ -427,10 +1081,12 @@ func stepDownDirectory(fpath string) (string, error) {
 	}
 
 	parts := strings.Split(normalized, "/")
-	if len(parts) <= 1 {
-		return "", nil
+	if stripRoot {
+		if len(parts) <= 1 {
+			return "", nil
+		}
+		parts = parts[1:]
 	}
-	parts = parts[1:]
 
 	var safeParts []string
 	for _, part := range parts {
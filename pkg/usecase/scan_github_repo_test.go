@@ -6,10 +6,12 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"bytes"
 	"io"
@@ -17,6 +19,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 
@@ -29,6 +32,7 @@ import (
 	"github.com/m-mizutani/octovy/pkg/infra/ghapp"
 	"github.com/m-mizutani/octovy/pkg/repository/memory"
 	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
 	"github.com/m-mizutani/octovy/pkg/utils/testutil"
 )
 
@@ -251,6 +255,130 @@ func TestScanGitHubRepoTrivyError(t *testing.T) {
 	gt.True(t, strings.Contains(err.Error(), "failed to scan local directory"))
 }
 
+func TestScanGitHubRepoRecordsFailure(t *testing.T) {
+	repoID := types.GitHubRepoID(defaultTestOwner + "/" + defaultTestRepo)
+	input := &model.ScanGitHubRepoInput{
+		GitHubMetadata: model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{
+					RepoID:   12345,
+					Owner:    defaultTestOwner,
+					RepoName: defaultTestRepo,
+				},
+				CommitID: defaultTestCommitID,
+				Branch:   defaultTestBranch,
+			},
+			InstallationID: 12345,
+		},
+		InstallID: 12345,
+	}
+
+	newUseCase := func(fx *scanTestFixture, memRepo interfaces.ScanRepository, options ...usecase.Option) *usecase.UseCase {
+		return usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		), options...)
+	}
+
+	t.Run("Trivy failure keeps the last successful scan", func(t *testing.T) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, nil)
+		memRepo := memory.New()
+		lastScanAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:    repoID,
+			Owner: defaultTestOwner,
+			Name:  defaultTestRepo,
+		}))
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:          defaultTestBranch,
+			LastScanID:    "prev-scan",
+			LastScanAt:    lastScanAt,
+			LastCommitSHA: "0000000000000000000000000000000000000000",
+			Status:        types.ScanStatusSuccess,
+		}))
+		fx.mockTrivy.mockRun = func(ctx context.Context, args []string) error {
+			return errors.New("trivy failed")
+		}
+
+		gt.Error(t, newUseCase(fx, memRepo).ScanGitHubRepo(ctx, input))
+
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+		gt.S(t, branch.LastError).Contains("failed to scan local directory")
+		gt.False(t, branch.LastErrorAt.IsZero())
+		gt.V(t, branch.LastScanID).Equal(types.ScanID("prev-scan"))
+		gt.V(t, branch.LastScanAt).Equal(lastScanAt)
+		gt.V(t, branch.LastCommitSHA).Equal(types.CommitSHA("0000000000000000000000000000000000000000"))
+	})
+
+	t.Run("download failure registers unknown repository and branch", func(t *testing.T) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, nil)
+		memRepo := memory.New()
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(strings.NewReader("not found")),
+			}, nil
+		}
+
+		gt.Error(t, newUseCase(fx, memRepo).ScanGitHubRepo(ctx, input))
+
+		repo := gt.R1(memRepo.GetRepository(ctx, repoID)).NoError(t)
+		gt.V(t, repo.InstallationID).Equal(int64(12345))
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+		gt.S(t, branch.LastError).Contains("failed to download zip file")
+		gt.V(t, branch.LastScanID).Equal(types.ScanID(""))
+	})
+
+	t.Run("archive exceeding limits fails scan before Trivy", func(t *testing.T) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, buildZipArchive(t, map[string]string{
+			"octovy-test/go.mod":     "module example.com/test",
+			"octovy-test/go.sum":     "",
+			"octovy-test/main.go":    "package main",
+			"octovy-test/Dockerfile": "FROM scratch",
+		}))
+		memRepo := memory.New()
+
+		err := newUseCase(fx, memRepo, usecase.WithArchiveLimits(model.ArchiveLimits{MaxFiles: 3})).ScanGitHubRepo(ctx, input)
+		gt.True(t, errors.Is(err, types.ErrArchiveTooLarge))
+
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusFailure)
+		gt.S(t, branch.LastError).Contains("zip archive exceeds file count limit")
+	})
+
+	t.Run("successful scan clears the error", func(t *testing.T) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, nil)
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:    repoID,
+			Owner: defaultTestOwner,
+			Name:  defaultTestRepo,
+		}))
+		gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{
+			Name:        defaultTestBranch,
+			Status:      types.ScanStatusFailure,
+			LastError:   "trivy failed",
+			LastErrorAt: time.Now(),
+		}))
+
+		gt.NoError(t, newUseCase(fx, memRepo).ScanGitHubRepo(ctx, input))
+
+		branch := gt.R1(memRepo.GetBranch(ctx, repoID, defaultTestBranch)).NoError(t)
+		gt.V(t, branch.Status).Equal(types.ScanStatusSuccess)
+		gt.V(t, branch.LastError).Equal("")
+		gt.True(t, branch.LastErrorAt.IsZero())
+	})
+}
+
 type scanTestFixture struct {
 	uc        *usecase.UseCase
 	mockGH    *mock.GitHubAppMock
@@ -350,13 +478,29 @@ func buildZipArchive(t *testing.T, files map[string]string) []byte {
 }
 
 type trivyMock struct {
-	mockRun func(ctx context.Context, args []string) error
+	mockRun     func(ctx context.Context, args []string) error
+	mockVersion func(ctx context.Context) (string, error)
 }
 
 func (x *trivyMock) Run(ctx context.Context, args []string) error {
 	return x.mockRun(ctx, args)
 }
 
+func (x *trivyMock) DownloadDB(ctx context.Context) error {
+	return nil
+}
+
+func (x *trivyMock) Version(ctx context.Context) (string, error) {
+	if x.mockVersion != nil {
+		return x.mockVersion(ctx)
+	}
+	return "", nil
+}
+
+func (x *trivyMock) CheckVersion(ctx context.Context) (string, error) {
+	return x.Version(ctx)
+}
+
 type httpMock struct {
 	mockDo func(req *http.Request) (*http.Response, error)
 }
@@ -470,7 +614,7 @@ func TestDownloadZipFile(t *testing.T) {
 
 		var buf bytes.Buffer
 		httpClient := &http.Client{}
-		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf)
+		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf, 0)
 		gt.NoError(t, err)
 		gt.V(t, buf.String()).Equal("zip content")
 	})
@@ -487,7 +631,7 @@ func TestDownloadZipFile(t *testing.T) {
 
 		var buf bytes.Buffer
 		httpClient := &http.Client{}
-		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf)
+		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf, 0)
 		gt.Error(t, err)
 		gt.True(t, errors.Is(err, types.ErrInvalidGitHubData))
 	})
@@ -504,7 +648,7 @@ func TestDownloadZipFile(t *testing.T) {
 
 		var buf bytes.Buffer
 		httpClient := &http.Client{}
-		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf)
+		err = usecase.DownloadZipFileForTest(ctx, httpClient, zipURL, &buf, 0)
 		gt.Error(t, err)
 		gt.True(t, errors.Is(err, types.ErrInvalidGitHubData))
 	})
@@ -542,9 +686,7 @@ func TestStepDownDirectory(t *testing.T) {
 	})
 }
 
-func TestExtractCode(t *testing.T) {
-	ctx := context.Background()
-
+func TestExtractZipEntry(t *testing.T) {
 	t.Run("extract file successfully", func(t *testing.T) {
 		tmpDir := gt.R1(os.MkdirTemp("", "extract-test-*")).NoError(t)
 		defer os.RemoveAll(tmpDir)
@@ -571,7 +713,7 @@ func TestExtractCode(t *testing.T) {
 		gt.NoError(t, os.MkdirAll(extractDir, 0755))
 
 		for _, f := range reader.File {
-			err = usecase.ExtractCodeForTest(ctx, f, extractDir)
+			err = usecase.ExtractZipEntryForTest(f, extractDir, model.DefaultArchiveLimits())
 			gt.NoError(t, err)
 		}
 
@@ -607,7 +749,7 @@ func TestExtractCode(t *testing.T) {
 		gt.NoError(t, os.MkdirAll(extractDir, 0755))
 
 		for _, f := range reader.File {
-			err = usecase.ExtractCodeForTest(ctx, f, extractDir)
+			err = usecase.ExtractZipEntryForTest(f, extractDir, model.DefaultArchiveLimits())
 			gt.NoError(t, err) // Should not error, just skip
 		}
 
@@ -616,6 +758,30 @@ func TestExtractCode(t *testing.T) {
 		gt.NoError(t, err)
 		gt.V(t, len(files)).Equal(0)
 	})
+
+	t.Run("file exceeding size limit is rejected", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		zipPath := filepath.Join(tmpDir, "test.zip")
+		zipFile := gt.R1(os.Create(zipPath)).NoError(t)
+		zipWriter := zip.NewWriter(zipFile)
+		fileWriter := gt.R1(zipWriter.Create("root/large.txt")).NoError(t)
+		gt.R1(fileWriter.Write(bytes.Repeat([]byte("a"), 1024))).NoError(t)
+		gt.NoError(t, zipWriter.Close())
+		gt.NoError(t, zipFile.Close())
+
+		reader := gt.R1(zip.OpenReader(zipPath)).NoError(t)
+		defer reader.Close()
+
+		extractDir := filepath.Join(tmpDir, "extracted")
+		limits := model.ArchiveLimits{MaxFileSize: 512}
+		for _, f := range reader.File {
+			err := usecase.ExtractZipEntryForTest(f, extractDir, limits)
+			gt.True(t, errors.Is(err, types.ErrArchiveTooLarge))
+		}
+		_, err := os.Stat(filepath.Join(extractDir, "large.txt"))
+		gt.True(t, errors.Is(err, os.ErrNotExist))
+	})
 }
 
 func TestExtractZipFile(t *testing.T) {
@@ -651,7 +817,7 @@ func TestExtractZipFile(t *testing.T) {
 
 		// Extract
 		extractDir := filepath.Join(tmpDir, "extracted")
-		err = usecase.ExtractZipFileForTest(ctx, zipPath, extractDir)
+		err = usecase.ExtractZipFileForTest(ctx, zipPath, extractDir, model.DefaultArchiveLimits())
 		gt.NoError(t, err)
 
 		// Verify all files extracted (with root directory removed)
@@ -667,12 +833,74 @@ func TestExtractZipFile(t *testing.T) {
 		gt.NoError(t, err)
 		gt.V(t, string(content3)).Equal("content3")
 	})
+
+	writeZip := func(t *testing.T, write func(zw *zip.Writer)) string {
+		zipPath := filepath.Join(t.TempDir(), "test.zip")
+		zipFile := gt.R1(os.Create(zipPath)).NoError(t)
+		zw := zip.NewWriter(zipFile)
+		write(zw)
+		gt.NoError(t, zw.Close())
+		gt.NoError(t, zipFile.Close())
+		return zipPath
+	}
+	addFile := func(t *testing.T, zw *zip.Writer, name string, size int) {
+		fw := gt.R1(zw.Create(name)).NoError(t)
+		gt.R1(fw.Write(bytes.Repeat([]byte("a"), size))).NoError(t)
+	}
+
+	t.Run("archive within limits", func(t *testing.T) {
+		zipPath := writeZip(t, func(zw *zip.Writer) {
+			addFile(t, zw, "root/a.txt", 10)
+			addFile(t, zw, "root/b.txt", 10)
+		})
+		limits := model.ArchiveLimits{MaxTotalSize: 20, MaxFileSize: 10, MaxFiles: 2}
+		gt.NoError(t, usecase.ExtractZipFileForTest(ctx, zipPath, t.TempDir(), limits))
+	})
+
+	t.Run("archive exceeding limits is rejected before extraction", func(t *testing.T) {
+		zipPath := writeZip(t, func(zw *zip.Writer) {
+			addFile(t, zw, "root/a.txt", 10)
+			addFile(t, zw, "root/b.txt", 10)
+		})
+
+		for _, limits := range []model.ArchiveLimits{
+			{MaxTotalSize: 19},
+			{MaxFileSize: 9},
+			{MaxFiles: 1},
+		} {
+			extractDir := t.TempDir()
+			err := usecase.ExtractZipFileForTest(ctx, zipPath, extractDir, limits)
+			gt.True(t, errors.Is(err, types.ErrArchiveTooLarge))
+			gt.A(t, gt.R1(os.ReadDir(extractDir)).NoError(t)).Length(0)
+		}
+	})
+
+	t.Run("size understated in zip header does not bypass limits", func(t *testing.T) {
+		content := bytes.Repeat([]byte("a"), 100)
+		zipPath := writeZip(t, func(zw *zip.Writer) {
+			fw := gt.R1(zw.CreateRaw(&zip.FileHeader{
+				Name:               "root/bomb.txt",
+				Method:             zip.Store,
+				CRC32:              crc32.ChecksumIEEE(content),
+				CompressedSize64:   uint64(len(content)),
+				UncompressedSize64: 1,
+			})).NoError(t)
+			gt.R1(fw.Write(content)).NoError(t)
+		})
+
+		// The header passes the check before extraction, and reading stops at the limit
+		extractDir := t.TempDir()
+		gt.Error(t, usecase.ExtractZipFileForTest(ctx, zipPath, extractDir, model.ArchiveLimits{MaxFileSize: 10}))
+		stat := gt.R1(os.Stat(filepath.Join(extractDir, "bomb.txt"))).NoError(t)
+		gt.True(t, stat.Size() <= 11)
+	})
 }
 
 // mockTrivyClient for testing scanDirectory
 type mockTrivyClient struct {
 	runFunc  func(ctx context.Context, args []string) error
 	lastArgs []string
+	version  string
 }
 
 func (m *mockTrivyClient) Run(ctx context.Context, args []string) error {
@@ -683,6 +911,18 @@ func (m *mockTrivyClient) Run(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (m *mockTrivyClient) DownloadDB(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockTrivyClient) Version(ctx context.Context) (string, error) {
+	return m.version, nil
+}
+
+func (m *mockTrivyClient) CheckVersion(ctx context.Context) (string, error) {
+	return m.version, nil
+}
+
 func TestScanDirectory(t *testing.T) {
 	t.Run("trivy arguments contain required flags", func(t *testing.T) {
 		tmpDir := gt.R1(os.MkdirTemp("", "scan-test-*")).NoError(t)
@@ -767,6 +1007,211 @@ func TestScanDirectory(t *testing.T) {
 		gt.Error(t, err)
 		gt.V(t, report).Equal(nil)
 	})
+
+	t.Run("scanners are passed to trivy", func(t *testing.T) {
+		tmpDir := gt.R1(os.MkdirTemp("", "scan-test-*")).NoError(t)
+		defer os.RemoveAll(tmpDir)
+
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					testJSON := `{"SchemaVersion":2,"ArtifactName":"test","Results":[]}`
+					os.WriteFile(args[i+1], []byte(testJSON), 0644)
+					break
+				}
+			}
+			return nil
+		}
+
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)),
+			usecase.WithTrivyScanners(types.TrivyScannerVuln, types.TrivyScannerSecret, types.TrivyScannerMisconf),
+		)
+
+		_, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+		gt.NoError(t, err)
+
+		args := mockTrivy.lastArgs
+		gt.A(t, args).Has("--scanners")
+		for i, arg := range args {
+			if arg == "--scanners" {
+				gt.V(t, args[i+1]).Equal("vuln,secret,misconfig")
+			}
+		}
+		// The scan target must stay the last argument
+		gt.V(t, args[len(args)-1]).Equal(tmpDir)
+	})
+
+	t.Run("version of trivy is recorded in the report", func(t *testing.T) {
+		tmpDir := gt.R1(os.MkdirTemp("", "scan-test-*")).NoError(t)
+		defer os.RemoveAll(tmpDir)
+
+		mockTrivy := &mockTrivyClient{version: "0.58.1"}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					os.WriteFile(args[i+1], []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`), 0644)
+					break
+				}
+			}
+			return nil
+		}
+
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)))
+		report, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+		gt.NoError(t, err)
+		gt.V(t, report.TrivyVersion()).Equal("0.58.1")
+	})
+
+	t.Run("scanners option is omitted by default", func(t *testing.T) {
+		tmpDir := gt.R1(os.MkdirTemp("", "scan-test-*")).NoError(t)
+		defer os.RemoveAll(tmpDir)
+
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					os.WriteFile(args[i+1], []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`), 0644)
+					break
+				}
+			}
+			return nil
+		}
+
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)))
+		_, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+		gt.NoError(t, err)
+		gt.A(t, mockTrivy.lastArgs).NotHas("--scanners")
+	})
+
+	t.Run("extra args are passed before the scan target", func(t *testing.T) {
+		tmpDir := gt.R1(os.MkdirTemp("", "scan-test-*")).NoError(t)
+		defer os.RemoveAll(tmpDir)
+
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					os.WriteFile(args[i+1], []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`), 0644)
+					break
+				}
+			}
+			return nil
+		}
+
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)),
+			usecase.WithTrivyArgs("--ignore-unfixed", "--severity", "HIGH,CRITICAL"),
+		)
+		_, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+		gt.NoError(t, err)
+
+		args := mockTrivy.lastArgs
+		gt.A(t, args).Longer(4)
+		gt.A(t, args[len(args)-4:]).Equal([]string{"--ignore-unfixed", "--severity", "HIGH,CRITICAL", tmpDir})
+	})
+
+	t.Run("ignore file of the repository is passed to trivy", func(t *testing.T) {
+		writeReport := func(args []string) {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					os.WriteFile(args[i+1], []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`), 0644)
+					break
+				}
+			}
+		}
+
+		t.Run("yaml is preferred", func(t *testing.T) {
+			tmpDir := t.TempDir()
+			gt.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".trivyignore"), []byte("CVE-2024-0001\n"), 0644))
+			gt.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".trivyignore.yaml"), []byte("vulnerabilities:\n  - id: CVE-2024-0001\n"), 0644))
+
+			mockTrivy := &mockTrivyClient{}
+			mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+				writeReport(args)
+				return nil
+			}
+			uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)))
+			_, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+			gt.NoError(t, err)
+
+			args := mockTrivy.lastArgs
+			gt.A(t, args).Has("--show-suppressed")
+			for i, arg := range args {
+				if arg == "--ignorefile" {
+					gt.V(t, args[i+1]).Equal(filepath.Join(tmpDir, ".trivyignore.yaml"))
+				}
+			}
+			gt.V(t, args[len(args)-1]).Equal(tmpDir)
+		})
+
+		t.Run("symbolic link is not used", func(t *testing.T) {
+			tmpDir := t.TempDir()
+			outside := filepath.Join(t.TempDir(), "ignore")
+			gt.NoError(t, os.WriteFile(outside, []byte("CVE-2024-0001\n"), 0644))
+			gt.NoError(t, os.Symlink(outside, filepath.Join(tmpDir, ".trivyignore")))
+
+			mockTrivy := &mockTrivyClient{}
+			mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+				writeReport(args)
+				return nil
+			}
+			uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy)))
+			_, err := uc.ScanDirectoryForTest(context.Background(), tmpDir)
+			gt.NoError(t, err)
+			gt.A(t, mockTrivy.lastArgs).NotHas("--ignorefile").NotHas("--show-suppressed")
+		})
+	})
+}
+
+func TestScanAndInsertFailOnSeverity(t *testing.T) {
+	const reportJSON = `{"SchemaVersion":2,"ArtifactName":"test","Results":[{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0001","PkgName":"pkg-a","Severity":"HIGH"},{"VulnerabilityID":"CVE-2024-0002","PkgName":"pkg-b","Severity":"LOW"}]}]}`
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    "test-owner",
+				RepoName: "test-repo",
+			},
+			Branch:   "main",
+			CommitID: "0000000000000000000000000000000000000000",
+		},
+	}
+
+	run := func(t *testing.T, opts ...usecase.Option) (interfaces.ScanRepository, error) {
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(reportJSON), 0644)
+				}
+			}
+			return nil
+		}
+
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithTrivy(mockTrivy), infra.WithScanRepository(memRepo)), opts...)
+		return memRepo, uc.ScanAndInsert(context.Background(), t.TempDir(), meta)
+	}
+
+	t.Run("fails when findings reach the threshold after storing the result", func(t *testing.T) {
+		memRepo, err := run(t, usecase.WithFailOnSeverity(types.SeverityHigh))
+		gt.Error(t, err)
+		gt.S(t, err.Error()).Contains("severity threshold")
+
+		vulns, err := memRepo.ListVulnerabilities(context.Background(), "test-owner/test-repo", "main", model.ToTargetID("go.mod", "lang-pkgs", "gomod"))
+		gt.NoError(t, err)
+		gt.A(t, vulns).Length(2)
+	})
+
+	t.Run("passes when findings are below the threshold", func(t *testing.T) {
+		_, err := run(t, usecase.WithFailOnSeverity(types.SeverityCritical))
+		gt.NoError(t, err)
+	})
+
+	t.Run("passes without threshold", func(t *testing.T) {
+		_, err := run(t)
+		gt.NoError(t, err)
+	})
 }
 
 func TestScanGitHubRepoCleanup(t *testing.T) {
@@ -896,6 +1341,174 @@ func TestScanGitHubRepoRemote(t *testing.T) {
 		gt.V(t, branchResolvedCommit).Equal("1234567890123456789012345678901234567890")
 	})
 
+	t.Run("full specification mode with annotated tag", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		ctx := context.Background()
+
+		var requested []string
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.Path)
+			var responseJSON string
+			switch req.URL.Path {
+			case "/repos/test-owner/test-repo/git/ref/tags/v1.2.0":
+				responseJSON = `{"ref":"refs/tags/v1.2.0","object":{"sha":"9999999999999999999999999999999999999999","type":"tag"}}`
+			case "/repos/test-owner/test-repo/git/tags/9999999999999999999999999999999999999999":
+				responseJSON = `{"tag":"v1.2.0","object":{"sha":"1234567890123456789012345678901234567890","type":"commit"}}`
+			default:
+				// Archive download
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(testCodeZip)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(responseJSON))),
+			}, nil
+		}
+
+		var tagResolvedCommit string
+		fx.mockGH.GetArchiveURLFunc = func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+			tagResolvedCommit = input.CommitID
+			return gt.R1(url.Parse("https://example.com/archive.zip")).NoError(t), nil
+		}
+		var inserted *model.ScanRawRecord
+		fx.mockBQ.InsertFunc = func(ctx context.Context, schema bigquery.Schema, data any, opts ...interfaces.BigQueryInsertOption) error {
+			inserted = data.(*model.ScanRawRecord)
+			return nil
+		}
+
+		input := &model.ScanGitHubRepoRemoteInput{
+			Owner:     "test-owner",
+			Repo:      "test-repo",
+			Tag:       "refs/tags/v1.2.0",
+			InstallID: 12345,
+		}
+
+		gt.NoError(t, fx.uc.ScanGitHubRepoRemote(ctx, input))
+		gt.V(t, tagResolvedCommit).Equal("1234567890123456789012345678901234567890")
+		gt.A(t, requested).Length(3)
+		gt.V(t, inserted).NotNil()
+		gt.V(t, inserted.GitHub.Tag).Equal("v1.2.0")
+		gt.V(t, inserted.GitHub.CommitID).Equal("1234567890123456789012345678901234567890")
+		gt.V(t, inserted.GitHub.Branch).Equal("")
+	})
+
+	t.Run("tag not referring to a commit", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"object":{"sha":"1234567890123456789012345678901234567890","type":"tree"}}`)),
+			}, nil
+		}
+
+		err := fx.uc.ScanGitHubRepoRemote(context.Background(), &model.ScanGitHubRepoRemoteInput{
+			Owner:     "test-owner",
+			Repo:      "test-repo",
+			Tag:       "tree-tag",
+			InstallID: 12345,
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidGitHubData))
+		gt.A(t, fx.mockGH.GetArchiveURLCalls()).Length(0)
+	})
+
+	t.Run("scan of tag without commit resolves the tag", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/git/ref/tags/v1.2.0") {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"object":{"sha":"1234567890123456789012345678901234567890","type":"commit"}}`)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(testCodeZip)),
+			}, nil
+		}
+		fx.mockGH.GetArchiveURLFunc = func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+			gt.V(t, input.CommitID).Equal("1234567890123456789012345678901234567890")
+			return gt.R1(url.Parse("https://example.com/archive.zip")).NoError(t), nil
+		}
+
+		// Input of a release event has the tag only
+		gt.NoError(t, fx.uc.ScanGitHubRepo(context.Background(), &model.ScanGitHubRepoInput{
+			GitHubMetadata: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				},
+				Tag: "v1.2.0",
+			},
+			InstallID: 12345,
+		}))
+		gt.A(t, fx.mockGH.GetArchiveURLCalls()).Length(1)
+	})
+
+	t.Run("tag and branch are mutually exclusive", func(t *testing.T) {
+		uc := usecase.New(infra.New())
+
+		err := uc.ScanGitHubRepoRemote(context.Background(), &model.ScanGitHubRepoRemoteInput{
+			Owner:     "test-owner",
+			Repo:      "test-repo",
+			Branch:    "main",
+			Tag:       "v1.2.0",
+			InstallID: 12345,
+		})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+
+	t.Run("DB completion mode resolves tag with installation of repository", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		ctx := context.Background()
+
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:             "test-owner/test-repo",
+			Owner:          "test-owner",
+			Name:           "test-repo",
+			DefaultBranch:  "main",
+			InstallationID: 67890,
+		}))
+
+		clients := infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		)
+		uc := usecase.New(clients)
+
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/git/ref/tags/") {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"object":{"sha":"1234567890123456789012345678901234567890","type":"commit"}}`)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(testCodeZip)),
+			}, nil
+		}
+		fx.mockGH.GetArchiveURLFunc = func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+			gt.V(t, input.CommitID).Equal("1234567890123456789012345678901234567890")
+			gt.V(t, input.InstallID).Equal(types.GitHubAppInstallID(67890))
+			return gt.R1(url.Parse("https://example.com/archive.zip")).NoError(t), nil
+		}
+
+		gt.NoError(t, uc.ScanGitHubRepoRemote(ctx, &model.ScanGitHubRepoRemoteInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+			Tag:   "v1.2.0",
+		}))
+		gt.A(t, fx.mockGH.GetArchiveURLCalls()).Length(1)
+		gt.A(t, fx.mockGH.HTTPClientCalls()).Longer(0).At(0, func(t testing.TB, v struct{ InstallID types.GitHubAppInstallID }) {
+			gt.V(t, v.InstallID).Equal(types.GitHubAppInstallID(67890))
+		})
+	})
+
 	t.Run("DB completion mode requires Firestore", func(t *testing.T) {
 		// No Firestore configured
 		uc := usecase.New(infra.New())
@@ -1062,6 +1675,56 @@ func TestScanGitHubRepoRemote(t *testing.T) {
 		gt.S(t, err.Error()).Contains("branch not found")
 	})
 
+	t.Run("DB completion mode resolves head commit of seeded repository", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		ctx := context.Background()
+
+		// Repository registered by an installation event, not scanned yet
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:             "test-owner/test-repo",
+			Owner:          "test-owner",
+			Name:           "test-repo",
+			DefaultBranch:  "main",
+			InstallationID: 67890,
+		}))
+
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		))
+
+		fx.mockHTTP.mockDo = func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/branches/main") {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"commit":{"sha":"1234567890123456789012345678901234567890"}}`))),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(testCodeZip)),
+			}, nil
+		}
+
+		var scanCalledWithCommit string
+		fx.mockGH.GetArchiveURLFunc = func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+			scanCalledWithCommit = input.CommitID
+			gt.V(t, input.InstallID).Equal(types.GitHubAppInstallID(67890))
+			return gt.R1(url.Parse("https://example.com/archive.zip")).NoError(t), nil
+		}
+
+		err := uc.ScanGitHubRepoRemote(ctx, &model.ScanGitHubRepoRemoteInput{
+			Owner: "test-owner",
+			Repo:  "test-repo",
+		})
+		gt.NoError(t, err)
+		gt.V(t, scanCalledWithCommit).Equal("1234567890123456789012345678901234567890")
+	})
+
 	t.Run("branch resolution fails with GitHub API error", func(t *testing.T) {
 		fx := newScanTestFixture(t, nil)
 		ctx := context.Background()
@@ -1091,3 +1754,240 @@ func TestScanGitHubRepoRemote(t *testing.T) {
 		gt.S(t, err.Error()).Contains("failed to get branch information")
 	})
 }
+
+func TestScanGitHubRepoCommitCache(t *testing.T) {
+	newInput := func(force bool) *model.ScanGitHubRepoInput {
+		return &model.ScanGitHubRepoInput{
+			GitHubMetadata: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{
+						RepoID:   12345,
+						Owner:    defaultTestOwner,
+						RepoName: defaultTestRepo,
+					},
+					CommitID: defaultTestCommitID,
+					Branch:   defaultTestBranch,
+				},
+				InstallationID: 12345,
+			},
+			InstallID: 12345,
+			Force:     force,
+		}
+	}
+
+	// calls records calls of GitHub App by scans
+	type calls struct {
+		archive   int
+		trivy     int
+		checkRuns []*interfaces.CreateCheckRunInput
+	}
+
+	setup := func(t *testing.T, branch *model.Branch, options ...usecase.Option) (*usecase.UseCase, *calls) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, nil)
+		memRepo := memory.New()
+
+		repoID := types.GitHubRepoID(defaultTestOwner + "/" + defaultTestRepo)
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:    repoID,
+			Owner: defaultTestOwner,
+			Name:  defaultTestRepo,
+		}))
+		if branch != nil {
+			gt.NoError(t, memRepo.CreateOrUpdateBranch(ctx, repoID, branch))
+		}
+
+		var called calls
+		fx.mockGH.GetArchiveURLFunc = func(ctx context.Context, input *interfaces.GetArchiveURLInput) (*url.URL, error) {
+			called.archive++
+			return gt.R1(url.Parse("https://example.com/archive.zip")).NoError(t), nil
+		}
+		fx.mockGH.CreateCheckRunFunc = func(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+			called.checkRuns = append(called.checkRuns, input)
+			return nil
+		}
+		fx.mockTrivy.mockRun = func(ctx context.Context, args []string) error {
+			called.trivy++
+			return writeTrivyOutput(t, args)
+		}
+
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		), options...)
+		return uc, &called
+	}
+
+	t.Run("skip commit that is already scanned successfully", func(t *testing.T) {
+		uc, called := setup(t, &model.Branch{
+			Name:          defaultTestBranch,
+			LastCommitSHA: defaultTestCommitID,
+			Status:        types.ScanStatusSuccess,
+		})
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(false)))
+		gt.V(t, called.archive).Equal(0)
+	})
+
+	t.Run("force option bypasses cache", func(t *testing.T) {
+		uc, called := setup(t, &model.Branch{
+			Name:          defaultTestBranch,
+			LastCommitSHA: defaultTestCommitID,
+			Status:        types.ScanStatusSuccess,
+		})
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(true)))
+		gt.V(t, called.archive).Equal(1)
+	})
+
+	t.Run("scan when last scanned commit differs", func(t *testing.T) {
+		uc, called := setup(t, &model.Branch{
+			Name:          defaultTestBranch,
+			LastCommitSHA: "0000000000000000000000000000000000000000",
+			Status:        types.ScanStatusSuccess,
+		})
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(false)))
+		gt.V(t, called.archive).Equal(1)
+	})
+
+	t.Run("scan when last scan of the commit failed", func(t *testing.T) {
+		uc, called := setup(t, &model.Branch{
+			Name:          defaultTestBranch,
+			LastCommitSHA: defaultTestCommitID,
+			Status:        types.ScanStatusFailure,
+		})
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(false)))
+		gt.V(t, called.archive).Equal(1)
+	})
+
+	t.Run("scan when branch is not recorded", func(t *testing.T) {
+		uc, called := setup(t, nil)
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(false)))
+		gt.V(t, called.archive).Equal(1)
+	})
+
+	t.Run("pull request of commit scanned by push is scanned", func(t *testing.T) {
+		uc, called := setup(t, nil, usecase.WithMisconfigCheckRun())
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), newInput(false)))
+		gt.A(t, called.checkRuns).Length(0)
+
+		prInput := newInput(false)
+		prInput.PullRequest = &model.GitHubPullRequest{Number: 42, BaseBranch: "main"}
+		gt.NoError(t, uc.ScanGitHubRepo(context.Background(), prInput))
+		gt.A(t, called.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.HeadSHA).Equal(defaultTestCommitID)
+			gt.V(t, v.Name).Equal(model.MisconfigCheckRunName)
+		})
+		gt.V(t, called.trivy).Equal(1)
+	})
+
+	t.Run("pull request scans again if report of commit has expired", func(t *testing.T) {
+		uc, called := setup(t, nil, usecase.WithMisconfigCheckRun())
+		now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+		gt.NoError(t, uc.ScanGitHubRepo(ctx, newInput(false)))
+		now = now.Add(time.Hour)
+		prInput := newInput(false)
+		prInput.PullRequest = &model.GitHubPullRequest{Number: 42, BaseBranch: "main"}
+		gt.NoError(t, uc.ScanGitHubRepo(ctx, prInput))
+
+		gt.A(t, called.checkRuns).Length(1)
+		gt.V(t, called.trivy).Equal(2)
+	})
+
+	t.Run("concurrent push and pull request of the same commit scan it once", func(t *testing.T) {
+		uc, called := setup(t, nil, usecase.WithMisconfigCheckRun())
+		prInput := newInput(false)
+		prInput.PullRequest = &model.GitHubPullRequest{Number: 42, BaseBranch: "main"}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i, input := range []*model.ScanGitHubRepoInput{newInput(false), prInput} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = uc.ScanGitHubRepo(context.Background(), input)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			gt.NoError(t, err)
+		}
+		gt.V(t, called.trivy).Equal(1)
+		gt.A(t, called.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.HeadSHA).Equal(defaultTestCommitID)
+		})
+	})
+
+	t.Run("concurrent scans of the same commit scan it once", func(t *testing.T) {
+		uc, called := setup(t, nil)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = uc.ScanGitHubRepo(context.Background(), newInput(false))
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			gt.NoError(t, err)
+		}
+		gt.V(t, called.archive).Equal(1)
+	})
+}
+
+func TestScanGitHubRepoStateless(t *testing.T) {
+	t.Run("scan without storing results", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		fx.mockGH.GetInstallationIDForOwnerFunc = func(ctx context.Context, owner string) (types.GitHubAppInstallID, error) {
+			gt.V(t, owner).Equal(defaultTestOwner)
+			return 12345, nil
+		}
+
+		report, err := fx.uc.ScanGitHubRepoStateless(context.Background(), &model.ScanGitHubRepoRemoteInput{
+			Owner:  defaultTestOwner,
+			Repo:   defaultTestRepo,
+			Commit: defaultTestCommitID,
+		})
+		gt.NoError(t, err)
+		gt.V(t, report).NotNil()
+		gt.A(t, report.Results).Longer(0)
+
+		gt.A(t, fx.mockGH.GetInstallationIDForOwnerCalls()).Length(1)
+		gt.A(t, fx.mockGH.GetArchiveURLCalls()).Length(1)
+		gt.A(t, fx.mockBQ.InsertCalls()).Length(0)
+		gt.A(t, fx.mockBQ.CreateTableCalls()).Length(0)
+	})
+
+	t.Run("installation ID is not looked up if given", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+
+		_, err := fx.uc.ScanGitHubRepoStateless(context.Background(), &model.ScanGitHubRepoRemoteInput{
+			Owner:     defaultTestOwner,
+			Repo:      defaultTestRepo,
+			Commit:    defaultTestCommitID,
+			InstallID: 12345,
+		})
+		gt.NoError(t, err)
+		gt.A(t, fx.mockGH.GetInstallationIDForOwnerCalls()).Length(0)
+	})
+
+	t.Run("commit or branch is required", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+
+		_, err := fx.uc.ScanGitHubRepoStateless(context.Background(), &model.ScanGitHubRepoRemoteInput{
+			Owner:     defaultTestOwner,
+			Repo:      defaultTestRepo,
+			InstallID: 12345,
+		})
+		gt.Error(t, err)
+		gt.A(t, fx.mockGH.GetArchiveURLCalls()).Length(0)
+	})
+}
@@ -8,15 +8,17 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
 )
 
 // ScanGitHubReposByOwner scans all repositories owned by the specified owner.
 // It retrieves repositories from Firestore and scans only those that have both
 // DefaultBranch and InstallationID configured.
-func (x *UseCase) ScanGitHubReposByOwner(ctx context.Context, input *model.ScanGitHubReposByOwnerInput) error {
+// Like ScanGitHubReposByOwnerFromAPI, it returns the result of each repository even if some of them failed.
+func (x *UseCase) ScanGitHubReposByOwner(ctx context.Context, input *model.ScanGitHubReposByOwnerInput) (*model.OwnerScanRun, error) {
 	// Validate Firestore is configured
 	if x.clients.ScanRepository() == nil {
-		return goerr.Wrap(types.ErrInvalidOption,
+		return nil, goerr.Wrap(types.ErrInvalidOption,
 			"owner-only mode requires Firestore. Please configure Firestore or specify both owner and repo")
 	}
 
@@ -28,7 +30,7 @@ func (x *UseCase) ScanGitHubReposByOwner(ctx context.Context, input *model.ScanG
 	// Get all repositories for the owner
 	repos, err := x.clients.ScanRepository().ListRepositoriesByOwner(ctx, input.Owner)
 	if err != nil {
-		return goerr.Wrap(err, "failed to list repositories by owner",
+		return nil, goerr.Wrap(err, "failed to list repositories by owner",
 			goerr.V("owner", input.Owner),
 		)
 	}
@@ -63,12 +65,19 @@ func (x *UseCase) ScanGitHubReposByOwner(ctx context.Context, input *model.ScanG
 		logger.Warn("No repositories to scan",
 			slog.String("owner", input.Owner),
 		)
-		return nil
+		return nil, nil
 	}
 
 	// Scan each repository
-	var successCount, failureCount int
+	run := newOwnerScanRun(ctx, input.Owner, model.OwnerScanSourceFirestore, "")
+	task := progress.From(ctx).Start("Scanning repositories of "+input.Owner, len(validRepos))
+	defer task.Done()
 	for i, repo := range validRepos {
+		task.Describe(repo.Owner + "/" + repo.Name)
+		if err := x.waitForGitHubRateLimit(ctx, types.GitHubAppInstallID(repo.InstallationID)); err != nil {
+			return run, x.finishOwnerScanRun(ctx, run, err)
+		}
+
 		logger.Info("Scanning repository",
 			slog.Int("progress", i+1),
 			slog.Int("total", len(validRepos)),
@@ -84,41 +93,28 @@ func (x *UseCase) ScanGitHubReposByOwner(ctx context.Context, input *model.ScanG
 			Repo:      repo.Name,
 			Branch:    string(repo.DefaultBranch),
 			InstallID: types.GitHubAppInstallID(repo.InstallationID),
+			Force:     input.Force,
 		}
 
 		// Scan the repository
-		if err := x.ScanGitHubRepoRemote(ctx, scanInput); err != nil {
-			failureCount++
+		result := x.scanOwnerRepo(ctx, scanInput)
+		run.Add(result)
+		if result.Status == types.ScanRunFailed {
+			task.Fail()
 			logger.Warn("Failed to scan repository",
 				slog.String("owner", repo.Owner),
 				slog.String("repo", repo.Name),
-				slog.String("error", err.Error()),
+				slog.String("error", result.Error),
 			)
 			continue
 		}
 
-		successCount++
+		task.Succeed()
 		logger.Info("Successfully scanned repository",
 			slog.String("owner", repo.Owner),
 			slog.String("repo", repo.Name),
 		)
 	}
 
-	// Log summary
-	logger.Info("Completed owner-only scan mode",
-		slog.String("owner", input.Owner),
-		slog.Int("total_repos", len(validRepos)),
-		slog.Int("success", successCount),
-		slog.Int("failure", failureCount),
-	)
-
-	if failureCount > 0 {
-		return goerr.New("some repositories failed to scan",
-			goerr.V("owner", input.Owner),
-			goerr.V("success_count", successCount),
-			goerr.V("failure_count", failureCount),
-		)
-	}
-
-	return nil
+	return run, x.finishOwnerScanRun(ctx, run, nil)
 }
@@ -8,24 +8,20 @@ import (
 	"github.com/m-mizutani/octovy/pkg/domain/model"
 	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
 )
 
-// scanFailure represents a failed repository scan with its error details.
-type scanFailure struct {
-	Owner string
-	Repo  string
-	Error string
-}
-
 // ScanGitHubReposByOwnerFromAPI scans all repositories owned by the specified owner
 // using GitHub App API to fetch the repository list (instead of Firestore).
 // This is triggered by the --all flag in scan remote command.
-func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) error {
+// It returns the result of each repository even if some of them failed, together with an error
+// so that the caller exits with non-zero status. The result is nil if no repository was scanned.
+func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *model.ScanGitHubReposByOwnerFromAPIInput) (*model.OwnerScanRun, error) {
 	logger := logging.From(ctx)
 
 	// Validate GitHub App is configured
 	if x.clients.GitHubApp() == nil {
-		return goerr.Wrap(types.ErrInvalidOption, "GitHub App is required for --all mode")
+		return nil, goerr.Wrap(types.ErrInvalidOption, "GitHub App is required for --all mode")
 	}
 
 	// Get installation ID if not provided
@@ -33,7 +29,7 @@ func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *mode
 	if installID == 0 {
 		id, err := x.clients.GitHubApp().GetInstallationIDForOwner(ctx, input.Owner)
 		if err != nil {
-			return goerr.Wrap(err, "failed to get installation ID for owner",
+			return nil, goerr.Wrap(err, "failed to get installation ID for owner",
 				goerr.V("owner", input.Owner),
 			)
 		}
@@ -48,7 +44,7 @@ func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *mode
 	// Get all repositories from GitHub API
 	repos, err := x.clients.GitHubApp().ListInstallationRepos(ctx, installID)
 	if err != nil {
-		return goerr.Wrap(err, "failed to list installation repos",
+		return nil, goerr.Wrap(err, "failed to list installation repos",
 			goerr.V("owner", input.Owner),
 			goerr.V("installID", installID),
 		)
@@ -110,14 +106,19 @@ func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *mode
 		logger.Warn("No repositories to scan",
 			slog.String("owner", input.Owner),
 		)
-		return nil
+		return nil, nil
 	}
 
 	// Scan each repository
-	var successCount int
-	var failures []scanFailure
-
+	run := newOwnerScanRun(ctx, input.Owner, model.OwnerScanSourceGitHub, input.Trigger)
+	task := progress.From(ctx).Start("Scanning repositories of "+input.Owner, len(validRepos))
+	defer task.Done()
 	for i, repo := range validRepos {
+		task.Describe(repo.Owner + "/" + repo.Name)
+		if err := x.waitForGitHubRateLimit(ctx, installID); err != nil {
+			return run, x.finishOwnerScanRun(ctx, run, err)
+		}
+
 		logger.Info("Scanning repository",
 			slog.Int("progress", i+1),
 			slog.Int("total", len(validRepos)),
@@ -132,61 +133,29 @@ func (x *UseCase) ScanGitHubReposByOwnerFromAPI(ctx context.Context, input *mode
 			Repo:      repo.Name,
 			Branch:    repo.DefaultBranch,
 			InstallID: installID,
+			Force:     input.Force,
+			Trigger:   input.Trigger,
 		}
 
 		// Scan the repository
-		if err := x.ScanGitHubRepoRemote(ctx, scanInput); err != nil {
-			failures = append(failures, scanFailure{
-				Owner: repo.Owner,
-				Repo:  repo.Name,
-				Error: err.Error(),
-			})
+		result := x.scanOwnerRepo(ctx, scanInput)
+		run.Add(result)
+		if result.Status == types.ScanRunFailed {
+			task.Fail()
 			logger.Warn("Failed to scan repository",
 				slog.String("owner", repo.Owner),
 				slog.String("repo", repo.Name),
-				slog.String("error", err.Error()),
+				slog.String("error", result.Error),
 			)
 			continue
 		}
 
-		successCount++
+		task.Succeed()
 		logger.Info("Successfully scanned repository",
 			slog.String("owner", repo.Owner),
 			slog.String("repo", repo.Name),
 		)
 	}
 
-	// Log summary with failure details
-	logger.Info("Completed scan with --all mode",
-		slog.String("owner", input.Owner),
-		slog.Int("total_repos", len(validRepos)),
-		slog.Int("success", successCount),
-		slog.Int("failure", len(failures)),
-	)
-
-	// Log detailed failure information
-	for _, f := range failures {
-		logger.Error("Repository scan failure details",
-			slog.String("owner", f.Owner),
-			slog.String("repo", f.Repo),
-			slog.String("error", f.Error),
-		)
-	}
-
-	if len(failures) > 0 {
-		// Build failure summary for error message
-		failedRepos := make([]string, len(failures))
-		for i, f := range failures {
-			failedRepos[i] = f.Owner + "/" + f.Repo
-		}
-
-		return goerr.New("some repositories failed to scan",
-			goerr.V("owner", input.Owner),
-			goerr.V("success_count", successCount),
-			goerr.V("failure_count", len(failures)),
-			goerr.V("failed_repos", failedRepos),
-		)
-	}
-
-	return nil
+	return run, x.finishOwnerScanRun(ctx, run, nil)
 }
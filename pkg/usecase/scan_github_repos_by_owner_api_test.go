@@ -29,7 +29,7 @@ func TestScanGitHubReposByOwnerFromAPI_NoGitHubApp(t *testing.T) {
 		Owner: "test-owner",
 	}
 
-	err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	_, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
 	gt.Error(t, err)
 	gt.S(t, err.Error()).Contains("GitHub App is required for --all mode")
 }
@@ -37,7 +37,9 @@ func TestScanGitHubReposByOwnerFromAPI_NoGitHubApp(t *testing.T) {
 func TestScanGitHubReposByOwnerFromAPI_GetInstallationID(t *testing.T) {
 	ctx := context.Background()
 
-	mockGH := &mock.GitHubAppMock{}
+	mockGH := &mock.GitHubAppMock{
+		RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit { return nil },
+	}
 	var capturedOwner string
 
 	mockGH.GetInstallationIDForOwnerFunc = func(ctx context.Context, owner string) (types.GitHubAppInstallID, error) {
@@ -60,7 +62,7 @@ func TestScanGitHubReposByOwnerFromAPI_GetInstallationID(t *testing.T) {
 		// InstallID not provided, should be fetched
 	}
 
-	err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	_, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
 	gt.NoError(t, err)
 	gt.V(t, capturedOwner).Equal("test-owner")
 }
@@ -68,7 +70,9 @@ func TestScanGitHubReposByOwnerFromAPI_GetInstallationID(t *testing.T) {
 func TestScanGitHubReposByOwnerFromAPI_FilterRepositories(t *testing.T) {
 	ctx := context.Background()
 
-	mockGH := &mock.GitHubAppMock{}
+	mockGH := &mock.GitHubAppMock{
+		RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit { return nil },
+	}
 	mockHTTP := &httpMock{}
 	mockTrivy := &trivyMock{}
 	mockBQ := &mock.BigQueryMock{}
@@ -174,7 +178,7 @@ func TestScanGitHubReposByOwnerFromAPI_FilterRepositories(t *testing.T) {
 	}
 
 	// Execute (will fail because GetArchiveURL returns error, but filtering is tested)
-	err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	_, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
 	gt.Error(t, err) // Expected to fail due to mock returning io.EOF
 
 	// Verify only valid repositories were attempted
@@ -186,7 +190,9 @@ func TestScanGitHubReposByOwnerFromAPI_FilterRepositories(t *testing.T) {
 func TestScanGitHubReposByOwnerFromAPI_NoRepositories(t *testing.T) {
 	ctx := context.Background()
 
-	mockGH := &mock.GitHubAppMock{}
+	mockGH := &mock.GitHubAppMock{
+		RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit { return nil },
+	}
 
 	mockGH.ListInstallationReposFunc = func(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
 		return []*model.GitHubAPIRepository{}, nil
@@ -202,14 +208,17 @@ func TestScanGitHubReposByOwnerFromAPI_NoRepositories(t *testing.T) {
 		InstallID: types.GitHubAppInstallID(12345),
 	}
 
-	err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	run, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
 	gt.NoError(t, err) // Should complete successfully with no repos
+	gt.Nil(t, run)
 }
 
 func TestScanGitHubReposByOwnerFromAPI_PartialFailure(t *testing.T) {
 	ctx := context.Background()
 
-	mockGH := &mock.GitHubAppMock{}
+	mockGH := &mock.GitHubAppMock{
+		RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit { return nil },
+	}
 	mockHTTP := &httpMock{}
 	mockTrivy := &trivyMock{}
 	mockBQ := &mock.BigQueryMock{}
@@ -279,12 +288,23 @@ func TestScanGitHubReposByOwnerFromAPI_PartialFailure(t *testing.T) {
 		InstallID: types.GitHubAppInstallID(12345),
 	}
 
-	err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
+	run, err := uc.ScanGitHubReposByOwnerFromAPI(ctx, input)
 	gt.Error(t, err)
 	gt.S(t, err.Error()).Contains("some repositories failed to scan")
 
 	// Both repos should have been attempted
 	gt.V(t, callCount).Equal(2)
+
+	// The result of each repository is returned together with the error
+	gt.V(t, run.Status).Equal(types.ScanRunFailed)
+	gt.V(t, run.Source).Equal(model.OwnerScanSourceGitHub)
+	gt.V(t, run.Failed).Equal(2)
+	gt.V(t, run.FailedRepos()).Equal([]string{"test-owner/repo-1", "test-owner/repo-2"})
+	for _, r := range run.Repositories {
+		gt.V(t, r.Status).Equal(types.ScanRunFailed)
+		gt.V(t, r.Branch).Equal("main")
+		gt.V(t, r.Error).NotEqual("")
+	}
 }
 
 // Mock types (httpMock, mockTransport, trivyMock) are defined in scan_github_repo_test.go
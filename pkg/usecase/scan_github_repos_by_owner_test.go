@@ -31,7 +31,7 @@ func TestScanGitHubReposByOwner_NoFirestore(t *testing.T) {
 		Owner: "test-owner",
 	}
 
-	err := uc.ScanGitHubReposByOwner(ctx, input)
+	_, err := uc.ScanGitHubReposByOwner(ctx, input)
 	gt.Error(t, err)
 	gt.S(t, err.Error()).Contains("owner-only mode requires Firestore")
 }
@@ -48,7 +48,7 @@ func TestScanGitHubReposByOwner_NoRepositories(t *testing.T) {
 		Owner: "test-owner",
 	}
 
-	err := uc.ScanGitHubReposByOwner(ctx, input)
+	_, err := uc.ScanGitHubReposByOwner(ctx, input)
 	gt.NoError(t, err)
 }
 
@@ -107,12 +107,13 @@ func TestScanGitHubReposByOwner_FilterRepositories(t *testing.T) {
 	}
 	gt.NoError(t, repo.CreateOrUpdateRepository(ctx, differentOwner))
 
-	// Create branch for valid repository to enable DB completion mode
+	// Create branch for valid repository to enable DB completion mode.
+	// The last scanned commit differs from the current branch head so that the commit cache does not skip the scan.
 	branch := &model.Branch{
 		Name:          "main",
 		LastScanID:    "scan-123",
 		LastScanAt:    now,
-		LastCommitSHA: "abc123def456789012345678901234567890abcd",
+		LastCommitSHA: "0123456789abcdef0123456789abcdef01234567",
 		Status:        types.ScanStatusSuccess,
 		CreatedAt:     now,
 		UpdatedAt:     now,
@@ -120,7 +121,9 @@ func TestScanGitHubReposByOwner_FilterRepositories(t *testing.T) {
 	gt.NoError(t, repo.CreateOrUpdateBranch(ctx, validRepo.ID, branch))
 
 	// Setup mocks to track which repositories are scanned
-	mockGH := &mock.GitHubAppMock{}
+	mockGH := &mock.GitHubAppMock{
+		RateLimitFunc: func(installID types.GitHubAppInstallID) *model.GitHubRateLimit { return nil },
+	}
 	mockHTTP := &httpMock{}
 	mockTrivy := &trivyMock{}
 	mockBQ := &mock.BigQueryMock{}
@@ -177,7 +180,7 @@ func TestScanGitHubReposByOwner_FilterRepositories(t *testing.T) {
 	}
 
 	// Execute the usecase (will fail due to mockGH.GetArchiveURLFunc returning error, but that's fine)
-	err := uc.ScanGitHubReposByOwner(ctx, input)
+	run, err := uc.ScanGitHubReposByOwner(ctx, input)
 	gt.Error(t, err) // Expected to fail, but we can verify filtering worked
 
 	// Verify only valid-repo was attempted to be scanned
@@ -187,6 +190,19 @@ func TestScanGitHubReposByOwner_FilterRepositories(t *testing.T) {
 	// - Repos with different owner were not retrieved
 	gt.V(t, len(scannedRepos)).Equal(1)
 	gt.V(t, scannedRepos[0]).Equal("valid-repo")
+
+	// The failure is reported per repository and the run is stored for later inspection
+	gt.V(t, run.Source).Equal(model.OwnerScanSourceFirestore)
+	gt.V(t, run.Status).Equal(types.ScanRunFailed)
+	gt.V(t, run.Failed).Equal(1)
+	gt.A(t, run.Repositories).Length(1)
+	gt.V(t, run.Repositories[0].Repo).Equal("valid-repo")
+	gt.V(t, run.Repositories[0].CommitID).Equal("abc123def456789012345678901234567890abcd")
+	gt.V(t, run.Repositories[0].Error).NotEqual("")
+
+	stored := gt.R1(repo.GetOwnerScanRun(ctx, run.ID)).NoError(t)
+	gt.V(t, stored.Failed).Equal(1)
+	gt.V(t, stored.FailedRepos()).Equal([]string{"test-owner/valid-repo"})
 }
 
 // emptyZipReader returns empty data to avoid actual zip extraction
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// CreateScanRun records a queued run of the scan and sets its ID to input.RunID, so that the caller can return the ID before the scan starts in background. It returns nil if ScanRepository is not configured, in which case runs are not recorded.
+func (x *UseCase) CreateScanRun(ctx context.Context, input *model.ScanGitHubRepoInput) (*model.ScanRun, error) {
+	repo := x.clients.ScanRepository()
+	if repo == nil {
+		return nil, nil
+	}
+
+	requestID, _ := logging.CtxRequestID(ctx)
+	run := model.NewScanRun(input, requestID, logging.CtxTime(ctx).UTC())
+	if err := repo.PutScanRun(ctx, run); err != nil {
+		return nil, goerr.Wrap(err, "failed to create scan run", goerr.V("owner", input.Owner), goerr.V("repo", input.RepoName))
+	}
+	input.RunID = run.ID
+
+	return run, nil
+}
+
+// GetScanRun returns the run of a scan. It returns repository.ErrNotFound if the run does not exist or has expired.
+func (x *UseCase) GetScanRun(ctx context.Context, runID types.ScanRunID) (*model.ScanRun, error) {
+	repo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := repo.GetScanRun(ctx, runID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get scan run", goerr.V("run_id", runID))
+	}
+	return run, nil
+}
+
+// startScanRun marks the run of input.RunID as running, or records a new running run if the input has no run or the run can not be loaded. It returns nil if ScanRepository is not configured. A failure of recording is logged because it should not stop the scan.
+func (x *UseCase) startScanRun(ctx context.Context, input *model.ScanGitHubRepoInput) *model.ScanRun {
+	repo := x.clients.ScanRepository()
+	if repo == nil {
+		return nil
+	}
+
+	now := logging.CtxTime(ctx).UTC()
+	run := x.loadScanRun(ctx, input.RunID)
+	if run == nil {
+		requestID, _ := logging.CtxRequestID(ctx)
+		run = model.NewScanRun(input, requestID, now)
+	}
+
+	run.Status = types.ScanRunRunning
+	run.StartedAt = now
+	if err := repo.PutScanRun(ctx, run); err != nil {
+		logging.From(ctx).Error("failed to record start of scan run", "run_id", run.ID, "error", err)
+	}
+	return run
+}
+
+// loadScanRun returns the run to finish, or nil if the run is not recorded. A failure of loading is logged because it should not stop storing the scan.
+func (x *UseCase) loadScanRun(ctx context.Context, runID types.ScanRunID) *model.ScanRun {
+	repo := x.clients.ScanRepository()
+	if repo == nil || runID == "" {
+		return nil
+	}
+
+	run, err := repo.GetScanRun(ctx, runID)
+	if err != nil {
+		logging.From(ctx).Warn("failed to get scan run", "run_id", runID, "error", err)
+		return nil
+	}
+	return run
+}
+
+// finishScanRun records the end of the run. scanID is empty if the scan is skipped because the commit has already been scanned. The commit is taken from the metadata because it can be resolved from the tag during the scan.
+func (x *UseCase) finishScanRun(ctx context.Context, run *model.ScanRun, meta *model.GitHubMetadata, scanID types.ScanID, scanErr error) {
+	repo := x.clients.ScanRepository()
+	if repo == nil || run == nil {
+		return
+	}
+
+	run.CommitID = meta.CommitID
+	run.FinishedAt = logging.CtxTime(ctx).UTC()
+	if scanErr != nil {
+		run.Status = types.ScanRunFailed
+		run.Error = summarizeScanError(scanErr)
+	} else {
+		run.Status = types.ScanRunSucceeded
+		run.ScanID = scanID
+		run.Skipped = scanID == ""
+	}
+
+	if err := repo.PutScanRun(ctx, run); err != nil {
+		logging.From(ctx).Error("failed to record end of scan run", "run_id", run.ID, "error", err)
+		return
+	}
+	logging.From(ctx).Info("scan run finished", "run_id", run.ID, "status", run.Status)
+}
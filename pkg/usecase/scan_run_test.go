@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestScanRun(t *testing.T) {
+	newInput := func() *model.ScanGitHubRepoInput {
+		return &model.ScanGitHubRepoInput{
+			GitHubMetadata: model.GitHubMetadata{
+				GitHubCommit: model.GitHubCommit{
+					GitHubRepo: model.GitHubRepo{
+						RepoID:   12345,
+						Owner:    defaultTestOwner,
+						RepoName: defaultTestRepo,
+					},
+					CommitID: defaultTestCommitID,
+					Branch:   defaultTestBranch,
+				},
+				InstallationID: 12345,
+			},
+			InstallID: 12345,
+			Trigger:   types.ScanTriggerPush,
+		}
+	}
+	newUseCase := func(fx *scanTestFixture, memRepo interfaces.ScanRepository) *usecase.UseCase {
+		return usecase.New(infra.New(
+			infra.WithGitHubApp(fx.mockGH),
+			infra.WithHTTPClient(fx.mockHTTP),
+			infra.WithTrivy(fx.mockTrivy),
+			infra.WithBigQuery(fx.mockBQ),
+			infra.WithScanRepository(memRepo),
+		))
+	}
+
+	t.Run("queued run succeeds with the stored scan", func(t *testing.T) {
+		requestID, ctx := logging.CtxRequestID(context.Background())
+		fx := newScanTestFixture(t, nil)
+		uc := newUseCase(fx, memory.New())
+		input := newInput()
+
+		run := gt.R1(uc.CreateScanRun(ctx, input)).NoError(t)
+		gt.V(t, run.Status).Equal(types.ScanRunQueued)
+		gt.V(t, input.RunID).Equal(run.ID)
+
+		gt.NoError(t, uc.ScanGitHubRepo(ctx, input))
+
+		got := gt.R1(uc.GetScanRun(ctx, run.ID)).NoError(t)
+		gt.V(t, got.Status).Equal(types.ScanRunSucceeded)
+		gt.V(t, got.Trigger).Equal(types.ScanTriggerPush)
+		gt.V(t, got.RequestID).Equal(requestID)
+		gt.V(t, got.CommitID).Equal(defaultTestCommitID)
+		gt.V(t, got.ScanID).NotEqual(types.ScanID(""))
+		gt.False(t, got.Skipped)
+		gt.False(t, got.StartedAt.IsZero())
+		gt.False(t, got.FinishedAt.IsZero())
+		gt.V(t, got.ExpiresAt).Equal(got.CreatedAt.Add(model.ScanRunRetention))
+
+		t.Run("scan of the same commit is skipped", func(t *testing.T) {
+			next := newInput()
+			next.Trigger = ""
+			run := gt.R1(uc.CreateScanRun(ctx, next)).NoError(t)
+			gt.NoError(t, uc.ScanGitHubRepo(ctx, next))
+
+			got := gt.R1(uc.GetScanRun(ctx, run.ID)).NoError(t)
+			gt.V(t, got.Status).Equal(types.ScanRunSucceeded)
+			gt.V(t, got.Trigger).Equal(types.ScanTriggerCLI)
+			gt.True(t, got.Skipped)
+			gt.V(t, got.ScanID).Equal(types.ScanID(""))
+		})
+	})
+
+	t.Run("failed scan records the error", func(t *testing.T) {
+		ctx := context.Background()
+		fx := newScanTestFixture(t, nil)
+		fx.mockTrivy.mockRun = func(ctx context.Context, args []string) error {
+			return errors.New("trivy failed")
+		}
+		uc := newUseCase(fx, memory.New())
+		input := newInput()
+
+		run := gt.R1(uc.CreateScanRun(ctx, input)).NoError(t)
+		gt.Error(t, uc.ScanGitHubRepo(ctx, input))
+
+		got := gt.R1(uc.GetScanRun(ctx, run.ID)).NoError(t)
+		gt.V(t, got.Status).Equal(types.ScanRunFailed)
+		gt.S(t, got.Error).Contains("failed to scan local directory")
+		gt.V(t, got.ScanID).Equal(types.ScanID(""))
+	})
+
+	t.Run("unknown run is not found", func(t *testing.T) {
+		uc := newUseCase(newScanTestFixture(t, nil), memory.New())
+		_, err := uc.GetScanRun(context.Background(), types.NewScanRunID())
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("runs are not recorded without ScanRepository", func(t *testing.T) {
+		fx := newScanTestFixture(t, nil)
+		input := newInput()
+		run := gt.R1(fx.uc.CreateScanRun(context.Background(), input)).NoError(t)
+		gt.V(t, run).Nil()
+		gt.V(t, input.RunID).Equal(types.ScanRunID(""))
+	})
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+const (
+	// scannedReportTTL is how long the report of a commit is kept for another event of the commit, e.g. a pull request event arriving with the push of its branch
+	scannedReportTTL = 10 * time.Minute
+
+	// maxScannedReports limits reports kept in memory because a report can be large
+	maxScannedReports = 16
+)
+
+// scannedReport is the report of a full scan of a commit with its coverage and archive
+type scannedReport struct {
+	report    *trivy.Report
+	coverage  *model.ScanCoverage
+	archive   *model.ScanArchive
+	scannedAt time.Time
+}
+
+// scannedReports keeps reports of commits recently scanned by the process, so that a pull request event of a commit scanned by another event reports the result without scanning it again. Reports are not shared with other instances.
+type scannedReports struct {
+	mutex   sync.Mutex
+	reports map[string]*scannedReport
+}
+
+func newScannedReports() *scannedReports {
+	return &scannedReports{reports: make(map[string]*scannedReport)}
+}
+
+func scannedReportKey(meta *model.GitHubMetadata) string {
+	return meta.Owner + "/" + meta.RepoName + "/" + meta.CommitID
+}
+
+// put keeps the report of the commit of meta. The oldest report is dropped if too many reports are kept.
+func (x *scannedReports) put(ctx context.Context, meta *model.GitHubMetadata, report *trivy.Report, coverage *model.ScanCoverage, archive *model.ScanArchive) {
+	if meta.CommitID == "" {
+		return
+	}
+	now := logging.CtxTime(ctx)
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	var oldestKey string
+	var oldest time.Time
+	for key, r := range x.reports {
+		if now.Sub(r.scannedAt) >= scannedReportTTL {
+			delete(x.reports, key)
+			continue
+		}
+		if oldestKey == "" || r.scannedAt.Before(oldest) {
+			oldestKey, oldest = key, r.scannedAt
+		}
+	}
+	key := scannedReportKey(meta)
+	if _, ok := x.reports[key]; !ok && len(x.reports) >= maxScannedReports {
+		delete(x.reports, oldestKey)
+	}
+
+	x.reports[key] = &scannedReport{
+		report:    report,
+		coverage:  coverage,
+		archive:   archive,
+		scannedAt: now,
+	}
+}
+
+// get returns the report of the commit of meta, or nil if it has not been scanned recently
+func (x *scannedReports) get(ctx context.Context, meta *model.GitHubMetadata) *scannedReport {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	r, ok := x.reports[scannedReportKey(meta)]
+	if !ok || logging.CtxTime(ctx).Sub(r.scannedAt) >= scannedReportTTL {
+		return nil
+	}
+	return r
+}
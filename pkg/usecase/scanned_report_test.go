@@ -0,0 +1,69 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestScannedReports(t *testing.T) {
+	newMeta := func(commit string) *model.GitHubMetadata {
+		return &model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				CommitID:   commit,
+			},
+		}
+	}
+
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+	t.Run("report of commit is returned", func(t *testing.T) {
+		reports := usecase.NewScannedReportsForTest()
+		report := &trivy.Report{ArtifactName: "test"}
+		reports.PutForTest(ctx, newMeta("1111111111111111111111111111111111111111"), report)
+
+		gt.V(t, reports.GetForTest(ctx, newMeta("1111111111111111111111111111111111111111"))).Equal(report)
+		gt.V(t, reports.GetForTest(ctx, newMeta("2222222222222222222222222222222222222222"))).Nil()
+	})
+
+	t.Run("report without commit is not kept", func(t *testing.T) {
+		reports := usecase.NewScannedReportsForTest()
+		reports.PutForTest(ctx, newMeta(""), &trivy.Report{})
+		gt.V(t, reports.GetForTest(ctx, newMeta(""))).Nil()
+	})
+
+	t.Run("report expires", func(t *testing.T) {
+		now := now
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+		reports := usecase.NewScannedReportsForTest()
+		reports.PutForTest(ctx, newMeta("1111111111111111111111111111111111111111"), &trivy.Report{})
+
+		now = now.Add(9 * time.Minute)
+		gt.V(t, reports.GetForTest(ctx, newMeta("1111111111111111111111111111111111111111"))).NotNil()
+		now = now.Add(time.Minute)
+		gt.V(t, reports.GetForTest(ctx, newMeta("1111111111111111111111111111111111111111"))).Nil()
+	})
+
+	t.Run("oldest report is dropped if too many reports are kept", func(t *testing.T) {
+		now := now
+		ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+		reports := usecase.NewScannedReportsForTest()
+		for i := range usecase.MaxScannedReportsForTest + 1 {
+			reports.PutForTest(ctx, newMeta(fmt.Sprintf("%040d", i)), &trivy.Report{})
+			now = now.Add(time.Second)
+		}
+
+		gt.V(t, reports.GetForTest(ctx, newMeta(fmt.Sprintf("%040d", 0)))).Nil()
+		gt.V(t, reports.GetForTest(ctx, newMeta(fmt.Sprintf("%040d", 1)))).NotNil()
+		gt.V(t, reports.GetForTest(ctx, newMeta(fmt.Sprintf("%040d", usecase.MaxScannedReportsForTest)))).NotNil()
+	})
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// SeedInstallationRepos registers repositories accessible by a GitHub App installation in the ScanRepository with their default branch and installation ID, so that DB completion mode can scan them before the first push. Tags and team are populated from GitHub topics and teams if not set yet. Existing repositories keep their creation time. It does nothing and returns 0 if ScanRepository is not configured.
+func (x *UseCase) SeedInstallationRepos(ctx context.Context, input *model.SeedInstallationReposInput) (int, error) {
+	logger := logging.From(ctx)
+
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		logger.Debug("ScanRepository is not configured, skip seeding installation repositories", slog.Any("installID", input.InstallID))
+		return 0, nil
+	}
+	if x.clients.GitHubApp() == nil {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "GitHub App is required to seed installation repositories")
+	}
+	if input.InstallID == 0 {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "installation ID is required to seed installation repositories")
+	}
+
+	repos, err := x.clients.GitHubApp().ListInstallationRepos(ctx, input.InstallID)
+	if err != nil {
+		return 0, goerr.Wrap(err, "failed to list installation repos", goerr.V("installID", input.InstallID))
+	}
+
+	targets := make(map[string]bool, len(input.Repos))
+	for _, name := range input.Repos {
+		targets[strings.ToLower(name)] = true
+	}
+
+	now := logging.CtxTime(ctx)
+	var seeded int
+	for _, apiRepo := range repos {
+		if apiRepo.Archived || apiRepo.Disabled {
+			continue
+		}
+		if len(targets) > 0 && !targets[strings.ToLower(apiRepo.Owner+"/"+apiRepo.Name)] {
+			continue
+		}
+
+		repo := &model.Repository{
+			ID:             types.NewGitHubRepoID(apiRepo.Owner, apiRepo.Name),
+			Owner:          apiRepo.Owner,
+			Name:           apiRepo.Name,
+			DefaultBranch:  types.BranchName(apiRepo.DefaultBranch),
+			InstallationID: int64(input.InstallID),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+
+		existing, err := scanRepo.GetRepository(ctx, repo.ID)
+		switch {
+		case err == nil:
+			repo.CreatedAt = existing.CreatedAt
+			repo.Tags = existing.Tags
+			repo.Team = existing.Team
+			repo.ScanConfig = existing.ScanConfig
+			repo.PullRequestCheck = existing.PullRequestCheck
+			repo.LastScanAt = existing.LastScanAt
+			repo.Findings = existing.Findings
+		case !errors.Is(err, repository.ErrNotFound):
+			return seeded, goerr.Wrap(err, "failed to get repository", goerr.V("repoID", repo.ID))
+		}
+
+		// Tags and team are populated only when not set yet, so that values set by user are kept
+		if len(repo.Tags) == 0 {
+			repo.Tags = model.NormalizeTags(apiRepo.Topics)
+		}
+		if repo.Team == "" {
+			repo.Team = x.resolveRepositoryTeam(ctx, input.InstallID, apiRepo.Owner, apiRepo.Name)
+		}
+
+		if err := scanRepo.CreateOrUpdateRepository(ctx, repo); err != nil {
+			return seeded, goerr.Wrap(err, "failed to create or update repository", goerr.V("repoID", repo.ID))
+		}
+		seeded++
+	}
+
+	logger.Info("Seeded installation repositories",
+		slog.Any("installID", input.InstallID),
+		slog.Int("listed", len(repos)),
+		slog.Int("seeded", seeded),
+	)
+
+	return seeded, nil
+}
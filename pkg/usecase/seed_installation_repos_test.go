@@ -0,0 +1,154 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+func TestSeedInstallationRepos(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	ctx := logging.CtxWithTime(context.Background(), func() time.Time { return now })
+
+	newMockGH := func() *mock.GitHubAppMock {
+		return &mock.GitHubAppMock{
+			ListInstallationReposFunc: func(ctx context.Context, installID types.GitHubAppInstallID) ([]*model.GitHubAPIRepository, error) {
+				gt.V(t, installID).Equal(types.GitHubAppInstallID(12345))
+				return []*model.GitHubAPIRepository{
+					{Owner: "octo-org", Name: "api", DefaultBranch: "main", Topics: []string{"backend", "Payments"}},
+					{Owner: "octo-org", Name: "web", DefaultBranch: "master"},
+					{Owner: "octo-org", Name: "legacy", DefaultBranch: "main", Archived: true},
+				}, nil
+			},
+			ListRepositoryTeamsFunc: func(ctx context.Context, input *interfaces.ListRepositoryTeamsInput) ([]*model.GitHubTeam, error) {
+				if input.Repo == "web" {
+					return nil, errors.New("resource not accessible by integration")
+				}
+				return []*model.GitHubTeam{
+					{Slug: "readers", Permission: "pull"},
+					{Slug: "payments-team", Permission: "admin"},
+					{Slug: "platform", Permission: "push"},
+				}, nil
+			},
+		}
+	}
+
+	t.Run("all repositories of installation", func(t *testing.T) {
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithGitHubApp(newMockGH()), infra.WithScanRepository(memRepo)))
+
+		seeded, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{InstallID: 12345})
+		gt.NoError(t, err)
+		gt.V(t, seeded).Equal(2)
+
+		repo, err := memRepo.GetRepository(ctx, "octo-org/web")
+		gt.NoError(t, err)
+		gt.V(t, repo.DefaultBranch).Equal(types.BranchName("master"))
+		gt.V(t, repo.InstallationID).Equal(int64(12345))
+		gt.V(t, repo.CreatedAt).Equal(now)
+		// Failure of the teams API does not fail seeding
+		gt.V(t, repo.Team).Equal("")
+
+		repo, err = memRepo.GetRepository(ctx, "octo-org/api")
+		gt.NoError(t, err)
+		gt.V(t, repo.Tags).Equal([]string{"backend", "payments"})
+		gt.V(t, repo.Team).Equal("payments-team")
+
+		_, err = memRepo.GetRepository(ctx, "octo-org/legacy")
+		gt.Error(t, err)
+	})
+
+	t.Run("only added repositories", func(t *testing.T) {
+		memRepo := memory.New()
+		uc := usecase.New(infra.New(infra.WithGitHubApp(newMockGH()), infra.WithScanRepository(memRepo)))
+
+		seeded, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{
+			InstallID: 12345,
+			Repos:     []string{"Octo-Org/API"},
+		})
+		gt.NoError(t, err)
+		gt.V(t, seeded).Equal(1)
+
+		repos, err := memRepo.ListRepositories(ctx, 12345)
+		gt.NoError(t, err)
+		gt.A(t, repos).Length(1)
+		gt.V(t, repos[0].Name).Equal("api")
+	})
+
+	t.Run("existing repository keeps creation time", func(t *testing.T) {
+		memRepo := memory.New()
+		createdAt := now.Add(-30 * 24 * time.Hour)
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:            "octo-org/api",
+			Owner:         "octo-org",
+			Name:          "api",
+			DefaultBranch: "develop",
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		}))
+		uc := usecase.New(infra.New(infra.WithGitHubApp(newMockGH()), infra.WithScanRepository(memRepo)))
+
+		_, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{InstallID: 12345})
+		gt.NoError(t, err)
+
+		repo, err := memRepo.GetRepository(ctx, "octo-org/api")
+		gt.NoError(t, err)
+		gt.V(t, repo.DefaultBranch).Equal(types.BranchName("main"))
+		gt.V(t, repo.InstallationID).Equal(int64(12345))
+		gt.V(t, repo.CreatedAt).Equal(createdAt)
+		gt.V(t, repo.UpdatedAt).Equal(now)
+	})
+
+	t.Run("existing tags and team are kept", func(t *testing.T) {
+		memRepo := memory.New()
+		gt.NoError(t, memRepo.CreateOrUpdateRepository(ctx, &model.Repository{
+			ID:    "octo-org/api",
+			Owner: "octo-org",
+			Name:  "api",
+			Tags:  []string{"tier-1"},
+			Team:  "security",
+		}))
+		mockGH := newMockGH()
+		uc := usecase.New(infra.New(infra.WithGitHubApp(mockGH), infra.WithScanRepository(memRepo)))
+
+		_, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{
+			InstallID: 12345,
+			Repos:     []string{"octo-org/api"},
+		})
+		gt.NoError(t, err)
+
+		repo, err := memRepo.GetRepository(ctx, "octo-org/api")
+		gt.NoError(t, err)
+		gt.V(t, repo.Tags).Equal([]string{"tier-1"})
+		gt.V(t, repo.Team).Equal("security")
+		gt.A(t, mockGH.ListRepositoryTeamsCalls()).Length(0)
+	})
+
+	t.Run("no ScanRepository", func(t *testing.T) {
+		mockGH := newMockGH()
+		uc := usecase.New(infra.New(infra.WithGitHubApp(mockGH)))
+
+		seeded, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{InstallID: 12345})
+		gt.NoError(t, err)
+		gt.V(t, seeded).Equal(0)
+		gt.A(t, mockGH.ListInstallationReposCalls()).Length(0)
+	})
+
+	t.Run("missing installation ID", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithGitHubApp(newMockGH()), infra.WithScanRepository(memory.New())))
+
+		_, err := uc.SeedInstallationRepos(ctx, &model.SeedInstallationReposInput{})
+		gt.True(t, errors.Is(err, types.ErrInvalidOption))
+	})
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// SetVulnerabilityStatus changes the status of a vulnerability manually and returns the number of changed findings. Setting acknowledged is the same as AcknowledgeVulnerability. A finding set to fixed or active is changed back by the next scan if it does not match the scan result, i.e. fixed one is still detected or active one is not detected anymore.
+func (x *UseCase) SetVulnerabilityStatus(ctx context.Context, input *model.SetVulnerabilityStatusInput) (int, error) {
+	if x.clients.ScanRepository() == nil {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "setting vulnerability status requires Firestore")
+	}
+	status, err := types.ParseVulnStatus(string(input.Status))
+	if err != nil {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "invalid vulnerability status", goerr.V("status", input.Status))
+	}
+	if input.VulnID == "" {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "vulnerability ID is empty")
+	}
+	if input.By == "" || input.Reason == "" {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "status change requires who and why", goerr.V("by", input.By), goerr.V("reason", input.Reason))
+	}
+	if !input.ExpiresAt.IsZero() && status != types.VulnStatusAcknowledged {
+		return 0, goerr.Wrap(types.ErrInvalidRequest, "expiration is only for acknowledged status", goerr.V("status", status))
+	}
+
+	if status == types.VulnStatusAcknowledged {
+		return x.AcknowledgeVulnerability(ctx, &model.AcknowledgeVulnerabilityInput{
+			Owner:     input.Owner,
+			Repo:      input.Repo,
+			Branch:    input.Branch,
+			Target:    input.Target,
+			VulnID:    input.VulnID,
+			By:        input.By,
+			Comment:   input.Reason,
+			ExpiresAt: input.ExpiresAt,
+		})
+	}
+
+	now := logging.CtxTime(ctx)
+	return x.changeVulnerabilityStatus(ctx, &vulnSelector{
+		owner:  input.Owner,
+		repo:   input.Repo,
+		branch: input.Branch,
+		target: input.Target,
+		vulnID: input.VulnID,
+	}, func(vuln *model.Vulnerability) *model.VulnStatusChange {
+		if vuln.Status == status {
+			return nil
+		}
+		return &model.VulnStatusChange{
+			VulnID:    vuln.ID,
+			From:      vuln.Status,
+			To:        status,
+			Actor:     input.By,
+			Comment:   input.Reason,
+			Timestamp: now,
+		}
+	})
+}
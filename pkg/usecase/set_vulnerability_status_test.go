@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestSetVulnerabilityStatus(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+	targetID := model.ToTargetID("go.mod", "", "")
+
+	repo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+	scan := func(vulnIDs ...string) {
+		var vulns []trivy.DetectedVulnerability
+		for _, id := range vulnIDs {
+			vulns = append(vulns, trivy.DetectedVulnerability{VulnerabilityID: id, PkgName: "pkg-" + id, InstalledVersion: "1.0.0"})
+		}
+		gt.R1(uc.InsertScanResult(ctx, model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     "main",
+				CommitID:   "0000000000000000000000000000000000000000",
+			},
+			DefaultBranch: "main",
+		}, trivy.Report{
+			SchemaVersion: 2,
+			ArtifactName:  "test-repo",
+			Results:       []trivy.Result{{Target: "go.mod", Vulnerabilities: vulns}},
+		})).NoError(t)
+	}
+	getStatus := func(t *testing.T, vulnID string) types.VulnStatus {
+		vulns := gt.R1(repo.ListVulnerabilities(ctx, repoID, "main", targetID)).NoError(t)
+		for _, v := range vulns {
+			if v.ID == vulnID {
+				return v.Status
+			}
+		}
+		t.Fatalf("vulnerability %s not found", vulnID)
+		return ""
+	}
+	input := func(status types.VulnStatus) *model.SetVulnerabilityStatusInput {
+		return &model.SetVulnerabilityStatusInput{
+			Owner:  "test-owner",
+			Repo:   "test-repo",
+			Branch: "main",
+			Target: "go.mod",
+			VulnID: "CVE-2024-0001",
+			Status: status,
+			By:     "alice",
+			Reason: "triaged",
+		}
+	}
+	scan("CVE-2024-0001")
+
+	t.Run("invalid input is rejected", func(t *testing.T) {
+		_, err := uc.SetVulnerabilityStatus(ctx, input("dismissed"))
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		noReason := input(types.VulnStatusFixed)
+		noReason.Reason = ""
+		_, err = uc.SetVulnerabilityStatus(ctx, noReason)
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		expiring := input(types.VulnStatusFixed)
+		expiring.ExpiresAt = time.Now().Add(time.Hour)
+		_, err = uc.SetVulnerabilityStatus(ctx, expiring)
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+
+		unknown := input(types.VulnStatusFixed)
+		unknown.VulnID = "CVE-2024-9999"
+		_, err = uc.SetVulnerabilityStatus(ctx, unknown)
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("status is changed with history", func(t *testing.T) {
+		gt.V(t, gt.R1(uc.SetVulnerabilityStatus(ctx, input(types.VulnStatusAcknowledged))).NoError(t)).Equal(1)
+		gt.V(t, getStatus(t, "CVE-2024-0001")).Equal(types.VulnStatusAcknowledged)
+
+		gt.V(t, gt.R1(uc.SetVulnerabilityStatus(ctx, input(types.VulnStatusFixed))).NoError(t)).Equal(1)
+		gt.V(t, getStatus(t, "CVE-2024-0001")).Equal(types.VulnStatusFixed)
+
+		// Same status is not changed
+		gt.V(t, gt.R1(uc.SetVulnerabilityStatus(ctx, input(types.VulnStatusFixed))).NoError(t)).Equal(0)
+
+		history := gt.R1(repo.ListVulnerabilityHistory(ctx, repoID, "main", targetID, "CVE-2024-0001")).NoError(t)
+		last := history[len(history)-1]
+		gt.V(t, last.To).Equal(types.VulnStatusFixed)
+		gt.V(t, last.Actor).Equal("alice")
+		gt.V(t, last.Comment).Equal("triaged")
+	})
+
+	t.Run("next scan reflects detection", func(t *testing.T) {
+		// The acknowledgement is kept while fixed, so the detected vulnerability is acknowledged again
+		scan("CVE-2024-0001")
+		gt.V(t, getStatus(t, "CVE-2024-0001")).Equal(types.VulnStatusAcknowledged)
+
+		gt.R1(uc.SetVulnerabilityStatus(ctx, input(types.VulnStatusActive))).NoError(t)
+		gt.V(t, getStatus(t, "CVE-2024-0001")).Equal(types.VulnStatusActive)
+		gt.R1(uc.SetVulnerabilityStatus(ctx, input(types.VulnStatusFixed))).NoError(t)
+		scan("CVE-2024-0001")
+		gt.V(t, getStatus(t, "CVE-2024-0001")).Equal(types.VulnStatusActive)
+	})
+}
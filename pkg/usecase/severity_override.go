@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+// PutSeverityOverride creates a severity override of the owner, or replaces the existing one of the same vulnerability ID and package name. Stored vulnerabilities are updated at the next scan of each repository.
+func (x *UseCase) PutSeverityOverride(ctx context.Context, override *model.SeverityOverride) (*model.SeverityOverride, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "severity override requires Firestore")
+	}
+
+	cpy := *override
+	cpy.Normalize()
+	if err := cpy.Validate(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := scanRepo.ListSeverityOverrides(ctx, cpy.Owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list severity overrides", goerr.V("owner", cpy.Owner))
+	}
+	now := logging.CtxTime(ctx)
+	cpy.CreatedAt, cpy.UpdatedAt = now, now
+	for _, o := range overrides {
+		if o.ID() == cpy.ID() {
+			cpy.CreatedAt = o.CreatedAt
+		}
+	}
+
+	if err := scanRepo.PutSeverityOverride(ctx, &cpy); err != nil {
+		return nil, goerr.Wrap(err, "failed to put severity override", goerr.V("owner", cpy.Owner))
+	}
+
+	logging.From(ctx).Info("Severity override put",
+		slog.String("owner", cpy.Owner),
+		slog.String("vuln_id", cpy.VulnID),
+		slog.String("pkg_name", cpy.PkgName),
+		slog.Any("severity", cpy.Severity),
+		slog.String("by", cpy.By),
+	)
+
+	return &cpy, nil
+}
+
+// ListSeverityOverrides returns severity overrides of the owner
+func (x *UseCase) ListSeverityOverrides(ctx context.Context, owner string) ([]*model.SeverityOverride, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "severity override requires Firestore")
+	}
+	if owner == "" {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "owner is required")
+	}
+
+	overrides, err := scanRepo.ListSeverityOverrides(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list severity overrides", goerr.V("owner", owner))
+	}
+	return overrides, nil
+}
+
+// DeleteSeverityOverride deletes the severity override. Stored vulnerabilities get back the severity reported by the scanner at the next scan of each repository.
+func (x *UseCase) DeleteSeverityOverride(ctx context.Context, input *model.DeleteSeverityOverrideInput) error {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return goerr.Wrap(types.ErrInvalidOption, "severity override requires Firestore")
+	}
+
+	// Identify the override in the same way as it was put
+	target := &model.SeverityOverride{Owner: input.Owner, VulnID: input.VulnID, PkgName: input.PkgName}
+	target.Normalize()
+	if target.Owner == "" || (target.VulnID == "" && target.PkgName == "") {
+		return goerr.Wrap(types.ErrInvalidRequest, "owner and vulnerability ID or package name are required")
+	}
+
+	if err := scanRepo.DeleteSeverityOverride(ctx, target.Owner, target.ID()); err != nil {
+		return goerr.Wrap(err, "failed to delete severity override",
+			goerr.V("owner", target.Owner),
+			goerr.V("vuln_id", target.VulnID),
+			goerr.V("pkg_name", target.PkgName),
+		)
+	}
+
+	logging.From(ctx).Info("Severity override deleted",
+		slog.String("owner", target.Owner),
+		slog.String("vuln_id", target.VulnID),
+		slog.String("pkg_name", target.PkgName),
+	)
+	return nil
+}
+
+// loadSeverityOverrides returns severity overrides of the owner, or nil if Firestore is not configured
+func (x *UseCase) loadSeverityOverrides(ctx context.Context, owner string) (model.SeverityOverrides, error) {
+	scanRepo := x.clients.ScanRepository()
+	if scanRepo == nil {
+		return nil, nil
+	}
+
+	overrides, err := scanRepo.ListSeverityOverrides(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to load severity overrides", goerr.V("owner", owner))
+	}
+	return overrides, nil
+}
@@ -0,0 +1,105 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestSeverityOverride(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	uc := usecase.New(infra.New(infra.WithScanRepository(repo)))
+
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+			Branch:     "main",
+			CommitID:   "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch: "main",
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-repo",
+		Results: []trivy.Result{
+			{
+				Target: "go.mod",
+				Type:   "gomod",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "github.com/example/noisy", InstalledVersion: "v1.0.0", Vulnerability: trivy.Vulnerability{Severity: "CRITICAL"}},
+					{VulnerabilityID: "CVE-2024-0002", PkgName: "github.com/example/payment", InstalledVersion: "v2.0.0", Vulnerability: trivy.Vulnerability{Severity: "MEDIUM"}},
+				},
+			},
+		},
+	}
+	targetID := model.ToTargetID("go.mod", "", "gomod")
+	listVulns := func(t *testing.T) map[string]*model.Vulnerability {
+		vulns, err := repo.ListVulnerabilities(ctx, "test-owner/test-repo", "main", targetID)
+		gt.NoError(t, err)
+		m := map[string]*model.Vulnerability{}
+		for _, v := range vulns {
+			m[v.ID] = v
+		}
+		return m
+	}
+
+	t.Run("put rejects invalid override", func(t *testing.T) {
+		_, err := uc.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "test-owner", Severity: types.SeverityLow})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+
+	t.Run("overrides severity of stored vulnerabilities", func(t *testing.T) {
+		gt.R1(uc.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "test-owner", VulnID: "cve-2024-0001", Severity: "low", Reason: "not reachable", By: "alice"})).NoError(t)
+		gt.R1(uc.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "test-owner", PkgName: "github.com/example/payment", Severity: "critical", By: "alice"})).NoError(t)
+		// Overrides of other owners are not applied
+		gt.R1(uc.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "other-owner", VulnID: "CVE-2024-0002", Severity: "low"})).NoError(t)
+
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+
+		vulns := listVulns(t)
+		gt.V(t, vulns["CVE-2024-0001"].Severity).Equal("LOW")
+		gt.V(t, vulns["CVE-2024-0001"].OriginalSeverity).Equal("CRITICAL")
+		gt.V(t, vulns["CVE-2024-0002"].Severity).Equal("CRITICAL")
+		gt.V(t, vulns["CVE-2024-0002"].OriginalSeverity).Equal("MEDIUM")
+
+		findings := gt.R1(uc.ListFindings(ctx, &model.ListFindingsInput{Owner: "test-owner", MinSeverity: types.SeverityHigh})).NoError(t)
+		gt.A(t, findings).Length(1).At(0, func(t testing.TB, v *model.Finding) {
+			gt.V(t, v.ID).Equal("CVE-2024-0002")
+			gt.V(t, v.OriginalSeverity).Equal("MEDIUM")
+		})
+	})
+
+	t.Run("put keeps creation time of replaced override", func(t *testing.T) {
+		overrides := gt.R1(uc.ListSeverityOverrides(ctx, "test-owner")).NoError(t)
+		gt.A(t, overrides).Length(2)
+
+		updated := gt.R1(uc.PutSeverityOverride(ctx, &model.SeverityOverride{Owner: "test-owner", VulnID: "CVE-2024-0001", Severity: "medium", By: "bob"})).NoError(t)
+		gt.V(t, updated.CreatedAt).Equal(overrides[1].CreatedAt)
+		gt.A(t, gt.R1(uc.ListSeverityOverrides(ctx, "test-owner")).NoError(t)).Length(2)
+	})
+
+	t.Run("deleted override is reverted at the next scan", func(t *testing.T) {
+		gt.NoError(t, uc.DeleteSeverityOverride(ctx, &model.DeleteSeverityOverrideInput{Owner: "test-owner", VulnID: "CVE-2024-0001"}))
+		err := uc.DeleteSeverityOverride(ctx, &model.DeleteSeverityOverrideInput{Owner: "test-owner", VulnID: "CVE-2024-0001"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+
+		meta.CommitID = "1111111111111111111111111111111111111111"
+		gt.R1(uc.InsertScanResult(ctx, meta, report)).NoError(t)
+
+		vulns := listVulns(t)
+		gt.V(t, vulns["CVE-2024-0001"].Severity).Equal("CRITICAL")
+		gt.V(t, vulns["CVE-2024-0001"].OriginalSeverity).Equal("")
+		gt.V(t, vulns["CVE-2024-0001"].Status).Equal(types.VulnStatusActive)
+		gt.V(t, vulns["CVE-2024-0002"].Severity).Equal("CRITICAL")
+	})
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+// codeScanningAlertStateDismissed is the alert state set when an alert is dismissed in GitHub UI
+const codeScanningAlertStateDismissed = "dismissed"
+
+// SyncCodeScanningAlerts reads dismissed code scanning alerts of the default branch from
+// GitHub and marks the matching active vulnerabilities as acknowledged. It is meant to be
+// run periodically so that triage in GitHub and in Octovy stays consistent.
+func (x *UseCase) SyncCodeScanningAlerts(ctx context.Context, input *model.SyncCodeScanningAlertsInput) error {
+	if x.clients.ScanRepository() == nil {
+		return goerr.Wrap(types.ErrInvalidOption, "code scanning alert sync requires Firestore")
+	}
+	if x.clients.GitHubApp() == nil {
+		return goerr.Wrap(types.ErrInvalidOption, "code scanning alert sync requires GitHub App")
+	}
+
+	logger := logging.From(ctx)
+
+	var repos []*model.Repository
+	if input.Repo != "" {
+		repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+		repo, err := x.clients.ScanRepository().GetRepository(ctx, repoID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+		}
+		repos = append(repos, repo)
+	} else {
+		found, err := x.clients.ScanRepository().ListRepositoriesByOwner(ctx, input.Owner)
+		if err != nil {
+			return goerr.Wrap(err, "failed to list repositories by owner", goerr.V("owner", input.Owner))
+		}
+		repos = found
+	}
+
+	var successCount, failureCount, acknowledgedCount int
+	task := progress.From(ctx).Start("Syncing code scanning alerts", len(repos))
+	defer task.Done()
+	for _, repo := range repos {
+		task.Describe(repo.Owner + "/" + repo.Name)
+		if repo.DefaultBranch == "" || repo.InstallationID == 0 {
+			task.Skip()
+			logger.Debug("Skipping repository due to missing metadata",
+				slog.String("owner", repo.Owner),
+				slog.String("repo", repo.Name),
+			)
+			continue
+		}
+
+		count, err := x.syncRepoCodeScanningAlerts(ctx, repo)
+		if err != nil {
+			failureCount++
+			task.Fail()
+			logger.Warn("Failed to sync code scanning alerts",
+				slog.String("owner", repo.Owner),
+				slog.String("repo", repo.Name),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		successCount++
+		acknowledgedCount += count
+		task.Succeed()
+	}
+	progress.From(ctx).Set("acknowledged", acknowledgedCount)
+
+	logger.Info("Completed code scanning alert sync",
+		slog.String("owner", input.Owner),
+		slog.Int("success", successCount),
+		slog.Int("failure", failureCount),
+		slog.Int("acknowledged", acknowledgedCount),
+	)
+
+	if failureCount > 0 {
+		return goerr.New("some repositories failed to sync code scanning alerts",
+			goerr.V("owner", input.Owner),
+			goerr.V("success_count", successCount),
+			goerr.V("failure_count", failureCount),
+		)
+	}
+
+	return nil
+}
+
+// syncRepoCodeScanningAlerts acknowledges active vulnerabilities of the default branch
+// that are dismissed in GitHub and returns the number of updated vulnerabilities.
+func (x *UseCase) syncRepoCodeScanningAlerts(ctx context.Context, repo *model.Repository) (int, error) {
+	alerts, err := x.clients.GitHubApp().ListCodeScanningAlerts(ctx, &interfaces.ListCodeScanningAlertsInput{
+		Owner:     repo.Owner,
+		Repo:      repo.Name,
+		Branch:    string(repo.DefaultBranch),
+		State:     codeScanningAlertStateDismissed,
+		InstallID: types.GitHubAppInstallID(repo.InstallationID),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(alerts) == 0 {
+		return 0, nil
+	}
+
+	// Trivy SARIF uses the vulnerability ID as rule ID and the scan target as location path
+	dismissed := make(map[string]map[string]*model.CodeScanningAlert)
+	for _, alert := range alerts {
+		if alert.State != codeScanningAlertStateDismissed || alert.RuleID == "" {
+			continue
+		}
+		if dismissed[alert.RuleID] == nil {
+			dismissed[alert.RuleID] = make(map[string]*model.CodeScanningAlert)
+		}
+		dismissed[alert.RuleID][alert.Path] = alert
+	}
+
+	scanRepo := x.clients.ScanRepository()
+	targets, err := scanRepo.ListTargets(ctx, repo.ID, repo.DefaultBranch)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, goerr.Wrap(err, "failed to list targets", goerr.V("repo_id", repo.ID))
+	}
+
+	now := logging.CtxTime(ctx)
+	var total int
+	defer func() {
+		if total > 0 {
+			refreshFindingSummary(ctx, scanRepo, repo.ID, repo.DefaultBranch)
+		}
+	}()
+	for _, target := range targets {
+		vulns, err := scanRepo.ListVulnerabilities(ctx, repo.ID, repo.DefaultBranch, target.ID)
+		if err != nil {
+			return total, goerr.Wrap(err, "failed to list vulnerabilities", goerr.V("target", target.Target))
+		}
+
+		var changes []*model.VulnStatusChange
+		for _, vuln := range vulns {
+			if vuln.Status != types.VulnStatusActive {
+				continue
+			}
+			paths := dismissed[vuln.ID]
+			alert := paths[target.Target]
+			if alert == nil {
+				alert = paths[""]
+			}
+			if alert == nil {
+				continue
+			}
+
+			ack := newAlertAcknowledgement(alert, now)
+			changes = append(changes, &model.VulnStatusChange{
+				VulnID:          vuln.ID,
+				From:            vuln.Status,
+				To:              types.VulnStatusAcknowledged,
+				Actor:           ack.By,
+				Comment:         ack.Comment,
+				Acknowledgement: ack,
+				Timestamp:       now,
+			})
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		if err := scanRepo.BatchUpdateVulnerabilityStatus(ctx, repo.ID, repo.DefaultBranch, target.ID, changes); err != nil {
+			return total, goerr.Wrap(err, "failed to update vulnerability status", goerr.V("target", target.Target))
+		}
+		total += len(changes)
+	}
+
+	logging.From(ctx).Info("Synced code scanning alerts",
+		slog.String("owner", repo.Owner),
+		slog.String("repo", repo.Name),
+		slog.Int("dismissed_alerts", len(alerts)),
+		slog.Int("acknowledged", total),
+	)
+
+	return total, nil
+}
+
+// newAlertAcknowledgement converts dismissal of a code scanning alert into an acknowledgement. The dismisser is unknown for alerts dismissed by automation, e.g. by GitHub itself.
+func newAlertAcknowledgement(alert *model.CodeScanningAlert, now time.Time) *model.Acknowledgement {
+	ack := &model.Acknowledgement{
+		By:      alert.DismissedBy,
+		Comment: fmt.Sprintf("dismissed in GitHub code scanning alert #%d (%s)", alert.Number, alert.DismissedReason),
+		At:      alert.DismissedAt,
+	}
+	if ack.By == "" {
+		ack.By = "github"
+	}
+	if alert.DismissedComment != "" {
+		ack.Comment += ": " + alert.DismissedComment
+	}
+	if ack.At.IsZero() {
+		ack.At = now
+	}
+	return ack
+}
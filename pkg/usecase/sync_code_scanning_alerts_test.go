@@ -0,0 +1,167 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestSyncCodeScanningAlerts(t *testing.T) {
+	meta := model.GitHubMetadata{
+		GitHubCommit: model.GitHubCommit{
+			GitHubRepo: model.GitHubRepo{
+				Owner:    "test-owner",
+				RepoName: "test-repo",
+			},
+			Branch:   "main",
+			CommitID: "0000000000000000000000000000000000000000",
+		},
+		DefaultBranch:  "main",
+		InstallationID: 456,
+	}
+	report := trivy.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "test-artifact",
+		Results: []trivy.Result{
+			{
+				Target: "go.mod",
+				Class:  "lang-pkgs",
+				Type:   "gomod",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a"},
+					{VulnerabilityID: "CVE-2024-0002", PkgName: "pkg-b"},
+				},
+			},
+			{
+				Target: "web/package-lock.json",
+				Class:  "lang-pkgs",
+				Type:   "npm",
+				Vulnerabilities: []trivy.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-c"},
+				},
+			},
+		},
+	}
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+
+	setup := func(t *testing.T, alerts []*model.CodeScanningAlert) (*usecase.UseCase, interfaces.ScanRepository, *mock.GitHubAppMock) {
+		memRepo := memory.New()
+		mockGH := &mock.GitHubAppMock{
+			ListCodeScanningAlertsFunc: func(ctx context.Context, input *interfaces.ListCodeScanningAlertsInput) ([]*model.CodeScanningAlert, error) {
+				gt.V(t, input.Owner).Equal("test-owner")
+				gt.V(t, input.Repo).Equal("test-repo")
+				gt.V(t, input.Branch).Equal("main")
+				gt.V(t, input.State).Equal("dismissed")
+				gt.V(t, input.InstallID).Equal(types.GitHubAppInstallID(456))
+				return alerts, nil
+			},
+		}
+		uc := usecase.New(infra.New(
+			infra.WithScanRepository(memRepo),
+			infra.WithGitHubApp(mockGH),
+		))
+
+		_, err := uc.InsertScanResult(context.Background(), meta, report)
+		gt.NoError(t, err)
+		return uc, memRepo, mockGH
+	}
+
+	statuses := func(t *testing.T, repo interfaces.ScanRepository, target, targetType string) map[string]types.VulnStatus {
+		vulns, err := repo.ListVulnerabilities(context.Background(), repoID, "main", model.ToTargetID(target, "lang-pkgs", targetType))
+		gt.NoError(t, err)
+		result := make(map[string]types.VulnStatus)
+		for _, v := range vulns {
+			result[v.ID] = v.Status
+		}
+		return result
+	}
+
+	t.Run("acknowledges vulnerabilities dismissed in GitHub", func(t *testing.T) {
+		uc, repo, mockGH := setup(t, []*model.CodeScanningAlert{
+			{Number: 1, RuleID: "CVE-2024-0001", State: "dismissed", DismissedReason: "won't fix", DismissedComment: "not reachable", DismissedBy: "alice", Path: "go.mod"},
+		})
+
+		err := uc.SyncCodeScanningAlerts(context.Background(), &model.SyncCodeScanningAlertsInput{Owner: "test-owner"})
+		gt.NoError(t, err)
+		gt.A(t, mockGH.ListCodeScanningAlertsCalls()).Length(1)
+
+		goMod := statuses(t, repo, "go.mod", "gomod")
+		gt.V(t, goMod["CVE-2024-0001"]).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, goMod["CVE-2024-0002"]).Equal(types.VulnStatusActive)
+
+		// Who dismissed the alert and why are recorded in the history
+		history, err := repo.ListVulnerabilityHistory(context.Background(), repoID, "main", model.ToTargetID("go.mod", "lang-pkgs", "gomod"), "CVE-2024-0001")
+		gt.NoError(t, err)
+		gt.A(t, history).Length(1)
+		gt.V(t, history[0].Actor).Equal("alice")
+		gt.V(t, history[0].Acknowledgement.By).Equal("alice")
+		gt.S(t, history[0].Comment).Contains("won't fix")
+		gt.S(t, history[0].Comment).Contains("not reachable")
+
+		// Same vulnerability in another target is not dismissed
+		lock := statuses(t, repo, "web/package-lock.json", "npm")
+		gt.V(t, lock["CVE-2024-0001"]).Equal(types.VulnStatusActive)
+	})
+
+	t.Run("alert without location applies to all targets", func(t *testing.T) {
+		uc, repo, _ := setup(t, []*model.CodeScanningAlert{
+			{Number: 1, RuleID: "CVE-2024-0001", State: "dismissed"},
+		})
+
+		err := uc.SyncCodeScanningAlerts(context.Background(), &model.SyncCodeScanningAlertsInput{Owner: "test-owner", Repo: "test-repo"})
+		gt.NoError(t, err)
+
+		gt.V(t, statuses(t, repo, "go.mod", "gomod")["CVE-2024-0001"]).Equal(types.VulnStatusAcknowledged)
+		gt.V(t, statuses(t, repo, "web/package-lock.json", "npm")["CVE-2024-0001"]).Equal(types.VulnStatusAcknowledged)
+	})
+
+	t.Run("acknowledged status survives rescans and is fixed when no longer detected", func(t *testing.T) {
+		uc, repo, _ := setup(t, []*model.CodeScanningAlert{
+			{Number: 1, RuleID: "CVE-2024-0002", State: "dismissed", Path: "go.mod"},
+		})
+		ctx := context.Background()
+
+		gt.NoError(t, uc.SyncCodeScanningAlerts(ctx, &model.SyncCodeScanningAlertsInput{Owner: "test-owner"}))
+
+		_, err := uc.InsertScanResult(ctx, meta, report)
+		gt.NoError(t, err)
+		gt.V(t, statuses(t, repo, "go.mod", "gomod")["CVE-2024-0002"]).Equal(types.VulnStatusAcknowledged)
+
+		fixed := report
+		fixed.Results = []trivy.Result{{
+			Target: "go.mod",
+			Class:  "lang-pkgs",
+			Type:   "gomod",
+			Vulnerabilities: []trivy.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2024-0001", PkgName: "pkg-a"},
+			},
+		}}
+		_, err = uc.InsertScanResult(ctx, meta, fixed)
+		gt.NoError(t, err)
+		gt.V(t, statuses(t, repo, "go.mod", "gomod")["CVE-2024-0002"]).Equal(types.VulnStatusFixed)
+	})
+
+	t.Run("ignores alerts that are not dismissed", func(t *testing.T) {
+		uc, repo, _ := setup(t, []*model.CodeScanningAlert{
+			{Number: 1, RuleID: "CVE-2024-0001", State: "open", Path: "go.mod"},
+		})
+
+		gt.NoError(t, uc.SyncCodeScanningAlerts(context.Background(), &model.SyncCodeScanningAlertsInput{Owner: "test-owner"}))
+		gt.V(t, statuses(t, repo, "go.mod", "gomod")["CVE-2024-0001"]).Equal(types.VulnStatusActive)
+	})
+
+	t.Run("requires Firestore", func(t *testing.T) {
+		uc := usecase.New(infra.New(infra.WithGitHubApp(&mock.GitHubAppMock{})))
+		err := uc.SyncCodeScanningAlerts(context.Background(), &model.SyncCodeScanningAlertsInput{Owner: "test-owner"})
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	trivyClient "github.com/m-mizutani/octovy/pkg/infra/trivy"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+// trivyIgnoreFiles are ignore files of Trivy detected at the root of the repository in order of precedence
+var trivyIgnoreFiles = []string{".trivyignore.yaml", ".trivyignore"}
+
+// findTrivyIgnoreFile returns the ignore file of Trivy in dir relative to dir, or empty if not found. A symbolic link is not used so that the file can not be read from out of the repository.
+func findTrivyIgnoreFile(ctx context.Context, dir string) string {
+	for _, name := range trivyIgnoreFiles {
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			logging.From(ctx).Warn("ignore file of Trivy is not a regular file, skip it", "file", name)
+			continue
+		}
+		return name
+	}
+	return ""
+}
+
+// trivyScanner is the default scanner, which runs `trivy fs` with all packages listed
+type trivyScanner struct {
+	client   trivyClient.Client
+	scanners []types.TrivyScanner
+	// args are extra arguments given by the user
+	args []string
+}
+
+func (x *trivyScanner) Scan(ctx context.Context, target *model.ScanTarget) (*trivy.Report, error) {
+	tmpResult, err := os.CreateTemp("", "octovy_result.*.json")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temp file for scan result")
+	}
+	defer safe.Remove(tmpResult.Name())
+
+	if err := tmpResult.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close temp file for scan result")
+	}
+
+	args := []string{
+		"fs",
+		"--exit-code", "0",
+		"--no-progress",
+		"--format", "json",
+		"--output", tmpResult.Name(),
+		"--list-all-pkgs",
+	}
+	if len(x.scanners) > 0 {
+		scanners := make([]string, len(x.scanners))
+		for i, scanner := range x.scanners {
+			scanners[i] = string(scanner)
+		}
+		args = append(args, "--scanners", strings.Join(scanners, ","))
+	}
+	for _, dir := range target.SkipDirs {
+		args = append(args, "--skip-dirs", dir)
+	}
+	for _, file := range target.SkipFiles {
+		args = append(args, "--skip-files", file)
+	}
+	if target.IgnoreFile != "" {
+		args = append(args,
+			"--ignorefile", filepath.Join(target.Dir, target.IgnoreFile),
+			"--show-suppressed",
+		)
+	}
+	args = append(args, x.args...)
+	args = append(args, target.Dir)
+
+	if err := x.client.Run(ctx, args); err != nil {
+		return nil, goerr.Wrap(err, "failed to scan local directory")
+	}
+
+	report, err := LoadTrivyReportFromFile(ctx, tmpResult.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// The version has been detected by Run, so it is not detected again
+	if version, err := x.client.CheckVersion(ctx); err == nil && version != "" && report.TrivyVersion() == "" {
+		report.Trivy = &trivy.TrivyInfo{Version: version}
+	}
+
+	logging.From(ctx).Debug("Scan result saved", "result_file", tmpResult.Name())
+
+	return report, nil
+}
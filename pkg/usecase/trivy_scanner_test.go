@@ -0,0 +1,137 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestTrivyScanner(t *testing.T) {
+	ctx := context.Background()
+
+	// writeReport writes a report of the version to the --output path of args
+	writeReport := func(t *testing.T, args []string, report string) {
+		i := slices.Index(args, "--output")
+		gt.True(t, i >= 0 && i+1 < len(args))
+		gt.NoError(t, os.WriteFile(args[i+1], []byte(report), 0600))
+	}
+
+	t.Run("arguments are built from the target and options", func(t *testing.T) {
+		var called []string
+		client := &trivyMock{
+			mockRun: func(ctx context.Context, args []string) error {
+				called = args
+				writeReport(t, args, `{"SchemaVersion":2,"ArtifactName":"/tmp/code"}`)
+				return nil
+			},
+		}
+		scanner := usecase.NewTrivyScannerForTest(client,
+			[]types.TrivyScanner{types.TrivyScannerVuln, types.TrivyScannerSecret},
+			[]string{"--offline-scan"},
+		)
+
+		report := gt.R1(scanner.Scan(ctx, &model.ScanTarget{
+			Dir:        "/tmp/code",
+			SkipDirs:   []string{"vendor", "node_modules"},
+			SkipFiles:  []string{"testdata/key.pem"},
+			IgnoreFile: ".trivyignore",
+		})).NoError(t)
+		gt.V(t, report.ArtifactName).Equal("/tmp/code")
+
+		gt.A(t, called).Length(22)
+		gt.A(t, called[:4]).Equal([]string{"fs", "--exit-code", "0", "--no-progress"})
+		gt.A(t, called[8:]).Equal([]string{
+			"--list-all-pkgs",
+			"--scanners", "vuln,secret",
+			"--skip-dirs", "vendor",
+			"--skip-dirs", "node_modules",
+			"--skip-files", "testdata/key.pem",
+			"--ignorefile", filepath.Join("/tmp/code", ".trivyignore"),
+			"--show-suppressed",
+			"--offline-scan",
+			"/tmp/code",
+		})
+	})
+
+	t.Run("minimal arguments without options", func(t *testing.T) {
+		var called []string
+		client := &trivyMock{
+			mockRun: func(ctx context.Context, args []string) error {
+				called = args
+				writeReport(t, args, `{"SchemaVersion":2,"ArtifactName":"/tmp/code"}`)
+				return nil
+			},
+		}
+		gt.R1(usecase.NewTrivyScannerForTest(client, nil, nil).Scan(ctx, &model.ScanTarget{Dir: "/tmp/code"})).NoError(t)
+
+		gt.False(t, slices.Contains(called, "--scanners"))
+		gt.False(t, slices.Contains(called, "--ignorefile"))
+		gt.V(t, called[len(called)-1]).Equal("/tmp/code")
+	})
+
+	t.Run("version of Trivy is set if the report has none", func(t *testing.T) {
+		client := &trivyMock{
+			mockRun: func(ctx context.Context, args []string) error {
+				writeReport(t, args, `{"SchemaVersion":2,"ArtifactName":"/tmp/code"}`)
+				return nil
+			},
+			mockVersion: func(ctx context.Context) (string, error) {
+				return "0.58.1", nil
+			},
+		}
+		report := gt.R1(usecase.NewTrivyScannerForTest(client, nil, nil).Scan(ctx, &model.ScanTarget{Dir: "/tmp/code"})).NoError(t)
+		gt.V(t, report.TrivyVersion()).Equal("0.58.1")
+	})
+
+	t.Run("error of Trivy is propagated", func(t *testing.T) {
+		runErr := errors.New("trivy failed")
+		client := &trivyMock{
+			mockRun: func(ctx context.Context, args []string) error {
+				return runErr
+			},
+		}
+		_, err := usecase.NewTrivyScannerForTest(client, nil, nil).Scan(ctx, &model.ScanTarget{Dir: "/tmp/code"})
+		gt.True(t, errors.Is(err, runErr))
+	})
+
+	t.Run("invalid report is an error", func(t *testing.T) {
+		client := &trivyMock{
+			mockRun: func(ctx context.Context, args []string) error {
+				writeReport(t, args, `not json`)
+				return nil
+			},
+		}
+		gt.R1(usecase.NewTrivyScannerForTest(client, nil, nil).Scan(ctx, &model.ScanTarget{Dir: "/tmp/code"})).Error(t)
+	})
+}
+
+func TestFindTrivyIgnoreFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("YAML ignore file takes precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, ".trivyignore"), []byte("CVE-2024-0001\n"), 0600))
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, ".trivyignore.yaml"), []byte("vulnerabilities: []\n"), 0600))
+		gt.V(t, usecase.FindTrivyIgnoreFileForTest(ctx, dir)).Equal(".trivyignore.yaml")
+	})
+
+	t.Run("symbolic link is not used", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := filepath.Join(t.TempDir(), "ignore")
+		gt.NoError(t, os.WriteFile(outside, []byte("CVE-2024-0001\n"), 0600))
+		gt.NoError(t, os.Symlink(outside, filepath.Join(dir, ".trivyignore")))
+		gt.V(t, usecase.FindTrivyIgnoreFileForTest(ctx, dir)).Equal("")
+	})
+
+	t.Run("no ignore file", func(t *testing.T) {
+		gt.V(t, usecase.FindTrivyIgnoreFileForTest(ctx, t.TempDir())).Equal("")
+	})
+}
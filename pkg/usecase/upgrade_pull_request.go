@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/safe"
+)
+
+type upgradePRConfig struct {
+	upgrader interfaces.PackageUpgrader
+	config   model.UpgradePullRequestConfig
+}
+
+// createUpgradePullRequests opens pull requests upgrading packages of the default branch to versions fixing their active vulnerabilities, one per package of a target. dir is the source code of the scanned commit, from which manifests are taken. An upgrade already proposed by a pull request of the same branch, open or closed, is not proposed again, and no pull request is created while the repository has the max number of open ones. A failure is logged and does not fail the scan.
+func (x *UseCase) createUpgradePullRequests(ctx context.Context, dir string, meta model.GitHubMetadata, report *trivy.Report) {
+	gh := x.clients.GitHubApp()
+	if gh == nil || meta.InstallationID == 0 {
+		return
+	}
+	logger := logging.From(ctx).With("owner", meta.Owner, "repo", meta.RepoName, "branch", meta.Branch)
+	installID := types.GitHubAppInstallID(meta.InstallationID)
+
+	upgrades, err := x.defaultBranchUpgrades(ctx, meta, report)
+	if err != nil {
+		logger.Warn("failed to list upgrades of vulnerable packages", slog.Any("error", err))
+		return
+	}
+	if len(upgrades) == 0 {
+		return
+	}
+
+	open, err := gh.ListPullRequests(ctx, &interfaces.ListPullRequestsInput{
+		Owner:     meta.Owner,
+		Repo:      meta.RepoName,
+		State:     "open",
+		InstallID: installID,
+	})
+	if err != nil {
+		logger.Warn("failed to list open pull requests", slog.Any("error", err))
+		return
+	}
+	var openUpgrades []*model.GitHubPullRequestRef
+	for _, pr := range open {
+		if strings.HasPrefix(pr.Branch, model.UpgradeBranchPrefix) {
+			openUpgrades = append(openUpgrades, pr)
+		}
+	}
+
+	maxOpen := x.upgradePR.config.MaxOpenPullRequests()
+	for _, upgrade := range upgrades {
+		if len(openUpgrades) >= maxOpen {
+			logger.Info("max number of open upgrade pull requests reached", slog.Int("max_open", maxOpen))
+			return
+		}
+
+		// Dedupe against an open pull request of the package, which may upgrade it to another version
+		if hasUpgradeOfPackage(openUpgrades, upgrade) {
+			continue
+		}
+
+		pr, err := x.createUpgradePullRequest(ctx, dir, meta, upgrade)
+		if pr != nil {
+			// A pull request is counted even if adding labels to it failed
+			openUpgrades = append(openUpgrades, pr)
+		}
+		if err != nil {
+			logger.Warn("failed to create upgrade pull request", slog.Any("error", err), slog.String("package", upgrade.PkgName), slog.String("target", upgrade.Target))
+			continue
+		}
+		if pr == nil {
+			continue
+		}
+		logger.Info("created upgrade pull request",
+			slog.Int("number", pr.Number),
+			slog.String("package", upgrade.PkgName),
+			slog.String("target", upgrade.Target),
+			slog.String("fixed_version", upgrade.FixedVersion),
+		)
+	}
+}
+
+// defaultBranchUpgrades returns upgrades of packages of supported ecosystems fixing active vulnerabilities of the scan. Statuses are taken from the scan repository if it is configured, so that acknowledged vulnerabilities are not upgraded.
+func (x *UseCase) defaultBranchUpgrades(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report) ([]*model.PackageUpgrade, error) {
+	var findings map[string]*model.DependencyUpdateFinding
+	if scanRepo := x.clients.ScanRepository(); scanRepo != nil {
+		stored, err := listBranchVulnerabilities(ctx, scanRepo, types.NewGitHubRepoID(meta.Owner, meta.RepoName), types.BranchName(meta.Branch))
+		if err != nil {
+			return nil, err
+		}
+		findings = stored
+	} else {
+		reported, err := x.reportVulnerabilities(ctx, meta.Owner, report)
+		if err != nil {
+			return nil, err
+		}
+		findings = reported
+	}
+
+	var active []*model.DependencyUpdateFinding
+	for _, f := range findings {
+		if f.Status == types.VulnStatusAcknowledged || !model.IsUpgradableEcosystem(f.Ecosystem) {
+			continue
+		}
+		active = append(active, f)
+	}
+
+	var upgrades []*model.PackageUpgrade
+	for _, upgrade := range model.SuggestUpgrades(active) {
+		if upgrade.FixedVersion != "" {
+			upgrades = append(upgrades, upgrade)
+		}
+	}
+	return upgrades, nil
+}
+
+func hasUpgradeOfPackage(prs []*model.GitHubPullRequestRef, upgrade *model.PackageUpgrade) bool {
+	prefix := upgrade.PackageBranchPrefix()
+	for _, pr := range prs {
+		if strings.HasPrefix(pr.Branch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// createUpgradePullRequest upgrades manifests of the target in a temporary directory and opens a pull request of changed files. It returns nil without error if the upgrade has already been proposed or changes nothing.
+func (x *UseCase) createUpgradePullRequest(ctx context.Context, srcDir string, meta model.GitHubMetadata, upgrade *model.PackageUpgrade) (*model.GitHubPullRequestRef, error) {
+	gh := x.clients.GitHubApp()
+	installID := types.GitHubAppInstallID(meta.InstallationID)
+	branch := upgrade.BranchName()
+
+	// A closed pull request of the branch is not reopened, because it may have been rejected
+	proposed, err := gh.ListPullRequests(ctx, &interfaces.ListPullRequestsInput{
+		Owner:     meta.Owner,
+		Repo:      meta.RepoName,
+		State:     "all",
+		Head:      branch,
+		InstallID: installID,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list pull requests of branch", goerr.V("branch", branch))
+	}
+	if len(proposed) > 0 {
+		logging.From(ctx).Debug("upgrade is already proposed", slog.String("branch", branch), slog.Int("number", proposed[0].Number))
+		return nil, nil
+	}
+
+	files, err := x.upgradeManifests(ctx, srcDir, upgrade)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		logging.From(ctx).Info("upgrade changes no file", slog.String("package", upgrade.PkgName), slog.String("target", upgrade.Target))
+		return nil, nil
+	}
+
+	return gh.CreatePullRequest(ctx, &interfaces.CreatePullRequestInput{
+		Owner:        meta.Owner,
+		Repo:         meta.RepoName,
+		BaseBranch:   meta.Branch,
+		BaseCommitID: meta.CommitID,
+		Branch:       branch,
+		Title:        upgrade.PullRequestTitle(),
+		Body:         upgrade.PullRequestBody(),
+		Message:      upgrade.CommitMessage(),
+		Files:        files,
+		Labels:       x.upgradePR.config.Labels,
+		InstallID:    installID,
+	})
+}
+
+// upgradeManifests copies manifests of the target from srcDir to a temporary directory, runs the upgrader there and returns files changed by it by paths relative to the repository root
+func (x *UseCase) upgradeManifests(ctx context.Context, srcDir string, upgrade *model.PackageUpgrade) (map[string][]byte, error) {
+	dir := upgrade.Dir()
+	if !filepath.IsLocal(filepath.FromSlash(dir)) {
+		return nil, goerr.New("target is out of source code", goerr.V("target", upgrade.Target))
+	}
+
+	workDir, err := os.MkdirTemp("", "octovy_upgrade.*")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temp dir for upgrade")
+	}
+	defer safe.RemoveAll(workDir)
+
+	manifests := model.UpgradeManifests(upgrade.Ecosystem)
+	original := make(map[string][]byte)
+	for i, name := range manifests {
+		raw, err := os.ReadFile(filepath.Join(srcDir, filepath.FromSlash(dir), name))
+		if err != nil {
+			// Lock files other than the first manifest may not exist
+			if errors.Is(err, fs.ErrNotExist) && i > 0 {
+				continue
+			}
+			return nil, goerr.Wrap(err, "failed to read manifest", goerr.V("dir", dir), goerr.V("name", name))
+		}
+		if err := os.WriteFile(filepath.Join(workDir, name), raw, 0600); err != nil {
+			return nil, goerr.Wrap(err, "failed to copy manifest", goerr.V("name", name))
+		}
+		original[name] = raw
+	}
+
+	if err := x.upgradePR.upgrader.Upgrade(ctx, workDir, upgrade); err != nil {
+		return nil, goerr.Wrap(err, "failed to upgrade package", goerr.V("package", upgrade.PkgName), goerr.V("version", upgrade.FixedVersion))
+	}
+
+	files := make(map[string][]byte)
+	for _, name := range manifests {
+		raw, err := os.ReadFile(filepath.Join(workDir, name))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, goerr.Wrap(err, "failed to read upgraded manifest", goerr.V("name", name))
+		}
+		if prev, ok := original[name]; ok && bytes.Equal(prev, raw) {
+			continue
+		}
+		files[path.Join(dir, name)] = raw
+	}
+	return files, nil
+}
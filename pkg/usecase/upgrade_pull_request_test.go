@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+// fakeUpgrader appends the upgrade to the last manifest of the ecosystem, as a package manager updates the lock file
+type fakeUpgrader struct {
+	upgrades []*model.PackageUpgrade
+}
+
+func (x *fakeUpgrader) Upgrade(ctx context.Context, dir string, upgrade *model.PackageUpgrade) error {
+	x.upgrades = append(x.upgrades, upgrade)
+	manifests := model.UpgradeManifests(upgrade.Ecosystem)
+	f, err := os.OpenFile(filepath.Join(dir, manifests[len(manifests)-1]), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(upgrade.PkgName + "@" + upgrade.FixedVersion + "\n")
+	return err
+}
+
+func TestUpgradePullRequests(t *testing.T) {
+	const report = `{"SchemaVersion":2,"ArtifactName":"test","Results":[
+		{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0001","PkgName":"example.com/a","InstalledVersion":"v1.0.0","FixedVersion":"1.0.2","Severity":"HIGH"},
+			{"VulnerabilityID":"CVE-2024-0002","PkgName":"example.com/a","InstalledVersion":"v1.0.0","FixedVersion":"1.1.0","Severity":"LOW"},
+			{"VulnerabilityID":"CVE-2024-0003","PkgName":"example.com/nofix","InstalledVersion":"v1.0.0","Severity":"HIGH"}
+		]},
+		{"Target":"web/package-lock.json","Class":"lang-pkgs","Type":"npm","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0004","PkgName":"lodash","InstalledVersion":"4.17.0","FixedVersion":"4.17.21","Severity":"CRITICAL"}
+		]},
+		{"Target":"Cargo.lock","Class":"lang-pkgs","Type":"cargo","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0005","PkgName":"serde","InstalledVersion":"1.0.0","FixedVersion":"1.0.190","Severity":"HIGH"}
+		]}
+	]}`
+
+	type env struct {
+		uc       *usecase.UseCase
+		upgrader *fakeUpgrader
+		created  []*interfaces.CreatePullRequestInput
+		// existing are pull requests returned by ListPullRequests
+		existing []*model.GitHubPullRequestRef
+		scan     func(t *testing.T, meta model.GitHubMetadata)
+	}
+
+	setup := func(t *testing.T, config model.UpgradePullRequestConfig) *env {
+		e := &env{upgrader: &fakeUpgrader{}}
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(report), 0644)
+				}
+			}
+			return nil
+		}
+		mockGH := &mock.GitHubAppMock{
+			ListPullRequestsFunc: func(ctx context.Context, input *interfaces.ListPullRequestsInput) ([]*model.GitHubPullRequestRef, error) {
+				var prs []*model.GitHubPullRequestRef
+				for _, pr := range e.existing {
+					if (input.State == "all" || input.State == pr.State) && (input.Head == "" || input.Head == pr.Branch) {
+						prs = append(prs, pr)
+					}
+				}
+				return prs, nil
+			},
+			CreatePullRequestFunc: func(ctx context.Context, input *interfaces.CreatePullRequestInput) (*model.GitHubPullRequestRef, error) {
+				e.created = append(e.created, input)
+				pr := &model.GitHubPullRequestRef{Number: 100 + len(e.created), Branch: input.Branch, State: "open"}
+				e.existing = append(e.existing, pr)
+				return pr, nil
+			},
+		}
+		e.uc = usecase.New(infra.New(
+			infra.WithTrivy(mockTrivy),
+			infra.WithGitHubApp(mockGH),
+			infra.WithScanRepository(memory.New()),
+		), usecase.WithUpgradePullRequests(e.upgrader, config))
+
+		e.scan = func(t *testing.T, meta model.GitHubMetadata) {
+			dir := t.TempDir()
+			gt.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0600))
+			gt.NoError(t, os.MkdirAll(filepath.Join(dir, "web"), 0700))
+			gt.NoError(t, os.WriteFile(filepath.Join(dir, "web", "package.json"), []byte(`{"dependencies":{"lodash":"^4.17.0"}}`), 0600))
+			gt.NoError(t, os.WriteFile(filepath.Join(dir, "web", "package-lock.json"), []byte("{}\n"), 0600))
+			gt.NoError(t, e.uc.ScanAndInsert(context.Background(), dir, meta))
+		}
+		return e
+	}
+
+	newMeta := func(branch string) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     branch,
+				CommitID:   "1111111111111111111111111111111111111111",
+			},
+			DefaultBranch:  "main",
+			InstallationID: 12345,
+		}
+	}
+
+	t.Run("pull requests are created per package of supported ecosystems", func(t *testing.T) {
+		e := setup(t, model.UpgradePullRequestConfig{Labels: []string{"security"}})
+		e.scan(t, newMeta("main"))
+
+		gt.A(t, e.created).Length(2).
+			At(0, func(t testing.TB, v *interfaces.CreatePullRequestInput) {
+				gt.V(t, v.Branch).Equal("octovy/upgrade/gomod/example.com/a@1.1.0")
+				gt.V(t, v.BaseBranch).Equal("main")
+				gt.V(t, v.BaseCommitID).Equal("1111111111111111111111111111111111111111")
+				gt.V(t, v.Title).Equal("Bump example.com/a from v1.0.0 to 1.1.0")
+				gt.S(t, v.Body).Contains("- CVE-2024-0002")
+				gt.A(t, v.Labels).Equal([]string{"security"})
+				gt.V(t, string(v.Files["go.sum"])).Equal("example.com/a@1.1.0\n")
+				_, ok := v.Files["go.mod"]
+				gt.False(t, ok)
+			}).
+			At(1, func(t testing.TB, v *interfaces.CreatePullRequestInput) {
+				gt.V(t, v.Branch).Equal("octovy/upgrade/npm/web/lodash@4.17.21")
+				gt.V(t, string(v.Files["web/package-lock.json"])).Equal("{}\nlodash@4.17.21\n")
+			})
+	})
+
+	t.Run("scan of other branch creates no pull request", func(t *testing.T) {
+		e := setup(t, model.UpgradePullRequestConfig{})
+		e.scan(t, newMeta("feature"))
+		gt.A(t, e.created).Length(0)
+		gt.A(t, e.upgrader.upgrades).Length(0)
+	})
+
+	t.Run("upgrade already proposed is not proposed again", func(t *testing.T) {
+		e := setup(t, model.UpgradePullRequestConfig{})
+		e.existing = []*model.GitHubPullRequestRef{
+			// closed pull request of the same upgrade
+			{Number: 1, Branch: "octovy/upgrade/gomod/example.com/a@1.1.0", State: "closed"},
+			// open pull request of the package to another version
+			{Number: 2, Branch: "octovy/upgrade/npm/web/lodash@4.17.20", State: "open"},
+		}
+		e.scan(t, newMeta("main"))
+		gt.A(t, e.created).Length(0)
+
+		// The next scan does not create the same pull requests
+		e.existing = nil
+		e.scan(t, newMeta("main"))
+		gt.A(t, e.created).Length(2)
+		e.scan(t, newMeta("main"))
+		gt.A(t, e.created).Length(2)
+	})
+
+	t.Run("no pull request is created over max open", func(t *testing.T) {
+		e := setup(t, model.UpgradePullRequestConfig{MaxOpen: 2})
+		e.existing = []*model.GitHubPullRequestRef{
+			{Number: 1, Branch: "octovy/upgrade/gomod/example.com/other@1.0.0", State: "open"},
+			{Number: 2, Branch: "feature", State: "open"},
+		}
+		e.scan(t, newMeta("main"))
+		gt.A(t, e.created).Length(1)
+		gt.True(t, strings.HasPrefix(e.created[0].Branch, "octovy/upgrade/gomod/"))
+	})
+}
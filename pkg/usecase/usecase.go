@@ -1,15 +1,239 @@
 package usecase
 
 import (
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
 	"github.com/m-mizutani/octovy/pkg/infra"
 )
 
 type UseCase struct {
-	clients *infra.Clients
+	clients        *infra.Clients
+	trivyScanners  []types.TrivyScanner
+	trivyArgs      []string
+	failOnSeverity types.Severity
+
+	// scanner replaces Trivy if set
+	scanner interfaces.Scanner
+
+	// fetchers replace the default fetchers of the source kinds
+	fetchers map[types.SourceKind]interfaces.Fetcher
+
+	githubRateLimitReserve int
+
+	archive archiveConfig
+
+	dependencyUpdateLabel string
+
+	// incrementalPRScan limits scans of pull requests to directories of changed files
+	incrementalPRScan bool
+
+	// misconfigCheckRun reports misconfigurations of pull requests as a check run with inline annotations
+	misconfigCheckRun bool
+
+	// vulnCheckRun is nil unless vulnerabilities of pull requests are reported as a check run
+	vulnCheckRun *vulnCheckRunConfig
+
+	// upgradePR is nil unless pull requests upgrading vulnerable packages of the default branch are created
+	upgradePR *upgradePRConfig
+
+	internalAdvisories []*model.InternalAdvisory
+
+	// exploitability is nil unless enrichment by EPSS and KEV is enabled
+	exploitability interfaces.ExploitabilitySource
+
+	// permalinks is nil unless the public URL of the server is configured
+	permalinks *model.Permalinks
+
+	// slaPolicy is empty unless SLA of vulnerabilities is configured
+	slaPolicy model.SLAPolicy
+
+	// gatePolicies decide deployment gates in addition to the built-in evaluation
+	gatePolicies []interfaces.GatePolicy
+
+	// bqVulnTable and bqPackageTable are set only in normalized BigQuery insert mode
+	bqVulnTable    types.BQTableID
+	bqPackageTable types.BQTableID
+
+	// bqTable is the table of the BigQuery client, routed to another table by bqRouting
+	bqTable   types.BQTableID
+	bqRouting types.BQTableRouting
+
+	// bqLayout is nil unless partitioning or clustering of BigQuery tables is enabled
+	bqLayout *bqTableLayout
+
+	// scannedReports keeps reports of commits recently scanned for pull request events of the commits
+	scannedReports *scannedReports
+}
+
+type Option func(*UseCase)
+
+// WithTrivyScanners sets scanners passed to Trivy via --scanners. If not set, Trivy's default scanners are used.
+func WithTrivyScanners(scanners ...types.TrivyScanner) Option {
+	return func(x *UseCase) {
+		x.trivyScanners = scanners
+	}
+}
+
+// WithTrivyArgs appends extra arguments to the Trivy command line, e.g. "--ignore-unfixed". They are placed before the scan target, after the arguments set by octovy.
+func WithTrivyArgs(args ...string) Option {
+	return func(x *UseCase) {
+		x.trivyArgs = args
+	}
+}
+
+// WithScanner replaces Trivy with another scanner. Trivy specific options such as WithTrivyScanners are ignored.
+func WithScanner(scanner interfaces.Scanner) Option {
+	return func(x *UseCase) {
+		x.scanner = scanner
+	}
+}
+
+// WithFetcher replaces the fetcher of the source kind, e.g. to fetch source code from an artifact store
+func WithFetcher(kind types.SourceKind, fetcher interfaces.Fetcher) Option {
+	return func(x *UseCase) {
+		if x.fetchers == nil {
+			x.fetchers = map[types.SourceKind]interfaces.Fetcher{}
+		}
+		x.fetchers[kind] = fetcher
+	}
+}
+
+// WithFailOnSeverity makes ScanAndInsert return an error after storing the result if the report has findings at or above the threshold. It is used to gate CI pipelines.
+func WithFailOnSeverity(threshold types.Severity) Option {
+	return func(x *UseCase) {
+		x.failOnSeverity = threshold
+	}
+}
+
+// WithBigQueryNormalizedInsert makes InsertScanResult store a scan summary row into the default table, and one row per vulnerability and package into vulnTable and packageTable, instead of one raw row including the whole report.
+func WithBigQueryNormalizedInsert(vulnTable, packageTable types.BQTableID) Option {
+	return func(x *UseCase) {
+		x.bqVulnTable = vulnTable
+		x.bqPackageTable = packageTable
+	}
+}
+
+// WithBigQueryTableRouting makes InsertScanResult store a scan in tables routed by the time of the scan or the owner, e.g. scans_202501, instead of table, which is the table of the BigQuery client. Tables of normalized insert mode are routed in the same way.
+func WithBigQueryTableRouting(table types.BQTableID, routing types.BQTableRouting) Option {
+	return func(x *UseCase) {
+		x.bqTable = table
+		x.bqRouting = routing
+	}
+}
+
+// WithBigQueryTableLayout makes InsertScanResult create BigQuery tables partitioned by the timestamp column and, if clustering is true, clustered by owner and repository name. Partitioning of existing tables can not be changed and is kept with a warning, while clustering of them is updated.
+func WithBigQueryTableLayout(partitioning types.BQPartitioning, clustering bool) Option {
+	return func(x *UseCase) {
+		x.bqLayout = &bqTableLayout{
+			partitioning: partitioning,
+			clustering:   clustering,
+		}
+	}
 }
 
-func New(clients *infra.Clients) *UseCase {
-	return &UseCase{
-		clients: clients,
+// WithGitHubRateLimitReserve sets the number of GitHub API requests of an installation kept in reserve. Owner-wide scans wait for the rate limit reset before scanning the next repository if the remaining quota is below it.
+func WithGitHubRateLimitReserve(reserve int) Option {
+	return func(x *UseCase) {
+		x.githubRateLimitReserve = reserve
 	}
 }
+
+// WithArchiveLimits replaces the default limits of extraction of zip archives of source code. A scan fails with types.ErrArchiveTooLarge if the archive exceeds them.
+func WithArchiveLimits(limits model.ArchiveLimits) Option {
+	return func(x *UseCase) {
+		x.archive.limits = limits
+	}
+}
+
+// WithArchiveChecksum makes ScanGitHubRepo record the SHA-256 digest of the fetched zip archive in the scan, to identify the scanned code afterwards
+func WithArchiveChecksum() Option {
+	return func(x *UseCase) {
+		x.archive.checksum = true
+	}
+}
+
+// WithDependencyUpdateLabel makes ScanAndInsert add the label to a Dependabot or Renovate pull request which fixes vulnerabilities of its base branch without introducing new ones.
+func WithDependencyUpdateLabel(label string) Option {
+	return func(x *UseCase) {
+		x.dependencyUpdateLabel = label
+	}
+}
+
+// WithMisconfigCheckRun makes ScanGitHubRepo report failed misconfiguration checks of a pull request as a check run, which shows them inline in the diff. Misconfigurations are reported only if Trivy runs with the misconfig scanner.
+func WithMisconfigCheckRun() Option {
+	return func(x *UseCase) {
+		x.misconfigCheckRun = true
+	}
+}
+
+// WithVulnerabilityCheckRun makes ScanGitHubRepo report vulnerabilities of a pull request at or above the threshold as a check run, which fails on them. With CheckBaselineBase, the last scan of the base branch is reused and only vulnerabilities not present in it fail the check, so that long-standing ones do not block unrelated pull requests. The baseline can be overridden per repository by Repository.PullRequestCheck.
+func WithVulnerabilityCheckRun(threshold types.Severity, baseline types.CheckBaseline) Option {
+	return func(x *UseCase) {
+		x.vulnCheckRun = &vulnCheckRunConfig{threshold: threshold, baseline: baseline}
+	}
+}
+
+// WithUpgradePullRequests makes ScanGitHubRepo open pull requests upgrading packages with active vulnerabilities of the default branch to the lowest versions fixing them, after a scan of the default branch. Manifests are upgraded by the upgrader, and the number of open pull requests per repository is limited by the config.
+func WithUpgradePullRequests(upgrader interfaces.PackageUpgrader, config model.UpgradePullRequestConfig) Option {
+	return func(x *UseCase) {
+		x.upgradePR = &upgradePRConfig{upgrader: upgrader, config: config}
+	}
+}
+
+// WithIncrementalPullRequestScan makes ScanGitHubRepo scan only directories of files changed by a pull request, listed via GitHub API, instead of the whole repository. The result of an incremental scan is stored only in BigQuery because it does not include targets of other directories.
+func WithIncrementalPullRequestScan() Option {
+	return func(x *UseCase) {
+		x.incrementalPRScan = true
+	}
+}
+
+// WithInternalAdvisories sets advisories of internal libraries. InsertScanResult matches them against packages in the report and adds vulnerabilities of matched packages with the internal data source.
+func WithInternalAdvisories(advisories []*model.InternalAdvisory) Option {
+	return func(x *UseCase) {
+		x.internalAdvisories = advisories
+	}
+}
+
+// WithExploitability makes InsertScanResult store EPSS scores and KEV flags of CVEs with vulnerabilities in the scan repository. Those of stored vulnerabilities are refreshed when they change.
+func WithExploitability(source interfaces.ExploitabilitySource) Option {
+	return func(x *UseCase) {
+		x.exploitability = source
+	}
+}
+
+// WithSLAPolicy sets the time to fix active vulnerabilities per severity, which is checked by CheckSLA and by EvaluateGate with EnforceSLA
+func WithSLAPolicy(policy model.SLAPolicy) Option {
+	return func(x *UseCase) {
+		x.slaPolicy = policy
+	}
+}
+
+// WithGatePolicies sets policies evaluated by EvaluateGate after the built-in evaluation, e.g. WASM policy plugins. The gate passes only if all of them pass.
+func WithGatePolicies(policies ...interfaces.GatePolicy) Option {
+	return func(x *UseCase) {
+		x.gatePolicies = append(x.gatePolicies, policies...)
+	}
+}
+
+// WithPermalinkBaseURL sets the public URL of the octovy server. Findings, branch status, gate results and regression notifications then include permalinks to the server, which resolve to JSON detail views.
+func WithPermalinkBaseURL(baseURL string) Option {
+	return func(x *UseCase) {
+		x.permalinks = model.NewPermalinks(baseURL)
+	}
+}
+
+func New(clients *infra.Clients, options ...Option) *UseCase {
+	uc := &UseCase{
+		clients:                clients,
+		githubRateLimitReserve: defaultGitHubRateLimitReserve,
+		archive:                archiveConfig{limits: model.DefaultArchiveLimits()},
+		scannedReports:         newScannedReports(),
+	}
+
+	for _, opt := range options {
+		opt(uc)
+	}
+
+	return uc
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/model/trivy"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+)
+
+type vulnCheckRunConfig struct {
+	threshold types.Severity
+	baseline  types.CheckBaseline
+}
+
+// reportVulnerabilityCheckRun creates a check run of the head commit of the pull request which fails on vulnerabilities at or above the threshold. With CheckBaselineBase, vulnerabilities in the last scan of the base branch are not counted, and all are counted if the base branch has never been scanned. A failure is logged and does not fail the scan.
+func (x *UseCase) reportVulnerabilityCheckRun(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report, incremental *model.IncrementalScan) {
+	gh := x.clients.GitHubApp()
+	if gh == nil || meta.InstallationID == 0 {
+		return
+	}
+
+	logger := logging.From(ctx)
+	check, err := x.checkPullRequestVulnerabilities(ctx, meta, report, incremental)
+	if err != nil {
+		logger.Warn("failed to check vulnerabilities of pull request", slog.Any("error", err))
+		return
+	}
+
+	if err := gh.CreateCheckRun(ctx, &interfaces.CreateCheckRunInput{
+		Owner:      meta.Owner,
+		Repo:       meta.RepoName,
+		HeadSHA:    meta.CommitID,
+		Name:       model.VulnCheckRunName,
+		Conclusion: check.Conclusion(),
+		Title:      check.Title(),
+		Summary:    check.Summary(),
+		InstallID:  types.GitHubAppInstallID(meta.InstallationID),
+	}); err != nil {
+		logger.Warn("failed to create check run of vulnerabilities", slog.Any("error", err))
+		return
+	}
+	logger.Info("created check run of vulnerabilities",
+		slog.Int("pull_request", meta.PullRequest.Number),
+		slog.Any("baseline", check.Baseline),
+		slog.Bool("base_missing", check.BaseMissing),
+		slog.Int("new", len(check.New)),
+		slog.Int("existing", check.Existing),
+	)
+}
+
+func (x *UseCase) checkPullRequestVulnerabilities(ctx context.Context, meta model.GitHubMetadata, report *trivy.Report, incremental *model.IncrementalScan) (*model.VulnerabilityCheck, error) {
+	repoID := types.NewGitHubRepoID(meta.Owner, meta.RepoName)
+	baseBranch := types.BranchName(meta.PullRequest.BaseBranch)
+	baseline := x.vulnCheckRun.baseline
+	scanRepo := x.clients.ScanRepository()
+
+	if scanRepo != nil {
+		stored, err := getStoredRepository(ctx, scanRepo, repoID)
+		if err != nil {
+			return nil, err
+		}
+		if stored != nil && !stored.PullRequestCheck.IsEmpty() {
+			baseline = stored.PullRequestCheck.Baseline
+		}
+	}
+
+	head, err := x.reportVulnerabilities(ctx, meta.Owner, report)
+	if err != nil {
+		return nil, err
+	}
+
+	var base map[string]*model.DependencyUpdateFinding
+	baseMissing := false
+	if baseline == types.CheckBaselineBase {
+		// Without ScanRepository, the base branch can not be looked up and is regarded as never scanned
+		if scanRepo != nil {
+			base, err = baseBranchVulnerabilities(ctx, scanRepo, repoID, baseBranch, incremental)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to get vulnerabilities of base branch")
+			}
+		}
+		baseMissing = base == nil
+	}
+
+	check := model.NewVulnerabilityCheck(head, base, x.vulnCheckRun.threshold)
+	check.Baseline = baseline
+	check.BaseBranch = baseBranch
+	check.BaseMissing = baseMissing
+	return check, nil
+}
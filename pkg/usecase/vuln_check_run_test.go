@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/interfaces"
+	"github.com/m-mizutani/octovy/pkg/domain/mock"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestVulnerabilityCheckRun(t *testing.T) {
+	const baseReport = `{"SchemaVersion":2,"ArtifactName":"test","Results":[
+		{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0001","PkgName":"example.com/old","InstalledVersion":"v1.0.0","Severity":"HIGH"}
+		]}
+	]}`
+	const headReport = `{"SchemaVersion":2,"ArtifactName":"test","Results":[
+		{"Target":"go.mod","Class":"lang-pkgs","Type":"gomod","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0001","PkgName":"example.com/old","InstalledVersion":"v1.0.1","Severity":"HIGH"},
+			{"VulnerabilityID":"CVE-2024-0002","PkgName":"example.com/new","InstalledVersion":"v2.0.0","Severity":"CRITICAL"},
+			{"VulnerabilityID":"CVE-2024-0003","PkgName":"example.com/minor","InstalledVersion":"v0.1.0","Severity":"LOW"}
+		]}
+	]}`
+
+	type env struct {
+		uc        *usecase.UseCase
+		checkRuns []*interfaces.CreateCheckRunInput
+		scan      func(t *testing.T, meta model.GitHubMetadata, report string)
+	}
+
+	setup := func(t *testing.T, options ...usecase.Option) *env {
+		e := &env{}
+		var report string
+		mockTrivy := &mockTrivyClient{}
+		mockTrivy.runFunc = func(ctx context.Context, args []string) error {
+			for i, arg := range args {
+				if arg == "--output" && i+1 < len(args) {
+					return os.WriteFile(args[i+1], []byte(report), 0644)
+				}
+			}
+			return nil
+		}
+		mockGH := &mock.GitHubAppMock{
+			CreateCheckRunFunc: func(ctx context.Context, input *interfaces.CreateCheckRunInput) error {
+				e.checkRuns = append(e.checkRuns, input)
+				return nil
+			},
+		}
+		e.uc = usecase.New(infra.New(
+			infra.WithTrivy(mockTrivy),
+			infra.WithGitHubApp(mockGH),
+			infra.WithScanRepository(memory.New()),
+		), options...)
+		e.scan = func(t *testing.T, meta model.GitHubMetadata, r string) {
+			report = r
+			gt.NoError(t, e.uc.ScanAndInsert(context.Background(), t.TempDir(), meta))
+		}
+		return e
+	}
+
+	newMeta := func(branch, commit string, pr *model.GitHubPullRequest) model.GitHubMetadata {
+		return model.GitHubMetadata{
+			GitHubCommit: model.GitHubCommit{
+				GitHubRepo: model.GitHubRepo{Owner: "test-owner", RepoName: "test-repo"},
+				Branch:     branch,
+				CommitID:   commit,
+			},
+			DefaultBranch:  "main",
+			InstallationID: 12345,
+			PullRequest:    pr,
+		}
+	}
+	baseMeta := newMeta("main", "1111111111111111111111111111111111111111", nil)
+	prMeta := newMeta("feature", "2222222222222222222222222222222222222222", &model.GitHubPullRequest{Number: 42, BaseBranch: "main"})
+
+	t.Run("only vulnerabilities not in base branch fail the check", func(t *testing.T) {
+		e := setup(t, usecase.WithVulnerabilityCheckRun(types.SeverityHigh, types.CheckBaselineBase))
+		e.scan(t, baseMeta, baseReport)
+		gt.A(t, e.checkRuns).Length(0)
+
+		e.scan(t, prMeta, headReport)
+		gt.A(t, e.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Name).Equal(model.VulnCheckRunName)
+			gt.V(t, v.HeadSHA).Equal("2222222222222222222222222222222222222222")
+			gt.V(t, v.Conclusion).Equal("failure")
+			gt.V(t, v.Title).Equal("1 new vulnerability at or above HIGH")
+			gt.S(t, v.Summary).Contains("CVE-2024-0002")
+			gt.S(t, v.Summary).NotContains("| CVE-2024-0001 |")
+			gt.S(t, v.Summary).NotContains("CVE-2024-0003")
+		})
+	})
+
+	t.Run("existing vulnerabilities do not fail the check", func(t *testing.T) {
+		e := setup(t, usecase.WithVulnerabilityCheckRun(types.SeverityHigh, types.CheckBaselineBase))
+		e.scan(t, baseMeta, baseReport)
+		e.scan(t, prMeta, baseReport)
+		gt.A(t, e.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Conclusion).Equal("success")
+			gt.S(t, v.Summary).Contains("1 vulnerabilities also present in the base branch are ignored")
+		})
+	})
+
+	t.Run("all vulnerabilities fail the check if base branch is not scanned", func(t *testing.T) {
+		e := setup(t, usecase.WithVulnerabilityCheckRun(types.SeverityHigh, types.CheckBaselineBase))
+		e.scan(t, prMeta, headReport)
+		gt.A(t, e.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Conclusion).Equal("failure")
+			gt.V(t, v.Title).Equal("2 vulnerabilities at or above HIGH")
+			gt.S(t, v.Summary).Contains("has never been scanned")
+		})
+	})
+
+	t.Run("baseline none fails on all vulnerabilities", func(t *testing.T) {
+		e := setup(t, usecase.WithVulnerabilityCheckRun(types.SeverityHigh, types.CheckBaselineNone))
+		e.scan(t, baseMeta, baseReport)
+		e.scan(t, prMeta, headReport)
+		gt.A(t, e.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Title).Equal("2 vulnerabilities at or above HIGH")
+		})
+	})
+
+	t.Run("baseline of repository overrides server-wide one", func(t *testing.T) {
+		e := setup(t, usecase.WithVulnerabilityCheckRun(types.SeverityHigh, types.CheckBaselineBase))
+		e.scan(t, baseMeta, baseReport)
+		gt.R1(e.uc.UpdateRepositoryMetadata(context.Background(), &model.UpdateRepositoryMetadataInput{
+			Owner:            "test-owner",
+			Repo:             "test-repo",
+			PullRequestCheck: &model.PullRequestCheckConfig{Baseline: types.CheckBaselineNone},
+		})).NoError(t)
+
+		e.scan(t, prMeta, headReport)
+		gt.A(t, e.checkRuns).Length(1).At(0, func(t testing.TB, v *interfaces.CreateCheckRunInput) {
+			gt.V(t, v.Title).Equal("2 vulnerabilities at or above HIGH")
+		})
+	})
+
+	t.Run("check run is disabled by default", func(t *testing.T) {
+		e := setup(t)
+		e.scan(t, prMeta, headReport)
+		gt.A(t, e.checkRuns).Length(0)
+	})
+}
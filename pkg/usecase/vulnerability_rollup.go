@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// GetVulnerabilityRollup returns findings of all branches of the repository, where the same finding detected in several branches is one entry with its presence in each branch, so that it is triaged once
+func (x *UseCase) GetVulnerabilityRollup(ctx context.Context, input *model.GetVulnerabilityRollupInput) (*model.VulnerabilityRollup, error) {
+	scanRepo, err := x.scanRepositoryForQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := types.NewGitHubRepoID(input.Owner, input.Repo)
+	if err := repoID.Validate(); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidRequest, "invalid repository", goerr.V("repo_id", repoID))
+	}
+
+	repo, err := scanRepo.GetRepository(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get repository", goerr.V("repo_id", repoID))
+	}
+
+	vulns, err := scanRepo.ListRepositoryVulnerabilities(ctx, repoID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list vulnerabilities of repository", goerr.V("repo_id", repoID))
+	}
+
+	return model.NewVulnerabilityRollup(repo, vulns, input.IncludeFixed, x.permalinks), nil
+}
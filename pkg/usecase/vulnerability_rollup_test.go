@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"github.com/m-mizutani/octovy/pkg/infra"
+	"github.com/m-mizutani/octovy/pkg/repository"
+	"github.com/m-mizutani/octovy/pkg/repository/memory"
+	"github.com/m-mizutani/octovy/pkg/usecase"
+)
+
+func TestGetVulnerabilityRollup(t *testing.T) {
+	ctx := context.Background()
+	repoID := types.GitHubRepoID("test-owner/test-repo")
+
+	// occurrence is CVE-2024-0001 of golang.org/x/net in go.mod of the branch
+	type occurrence struct {
+		branch   types.BranchName
+		severity string
+		status   types.VulnStatus
+	}
+
+	setup := func(t *testing.T, occurrences []occurrence) *usecase.UseCase {
+		repo := memory.New()
+		gt.NoError(t, repo.CreateOrUpdateRepository(ctx, &model.Repository{ID: repoID, Owner: "test-owner", Name: "test-repo", DefaultBranch: "main"}))
+		goMod := &model.Target{ID: model.ToTargetID("go.mod", "", ""), Target: "go.mod"}
+		for _, o := range occurrences {
+			gt.NoError(t, repo.CreateOrUpdateBranch(ctx, repoID, &model.Branch{Name: o.branch}))
+			gt.NoError(t, repo.CreateOrUpdateTarget(ctx, repoID, o.branch, goMod))
+			gt.NoError(t, repo.BatchCreateVulnerabilities(ctx, repoID, o.branch, goMod.ID, []*model.Vulnerability{
+				{ID: "CVE-2024-0001", PkgName: "golang.org/x/net", Severity: o.severity, Status: o.status},
+			}))
+		}
+		return usecase.New(infra.New(infra.WithScanRepository(repo)))
+	}
+
+	testCases := map[string]struct {
+		occurrences  []occurrence
+		includeFixed bool
+		// findings is the expected number of findings, and severity, status and branches are of the finding if any
+		findings  int
+		severity  string
+		status    types.VulnStatus
+		inDefault bool
+		branches  []string
+	}{
+		"highest severity of branches is taken": {
+			occurrences: []occurrence{
+				{branch: "feature/x", severity: "LOW", status: types.VulnStatusActive},
+				{branch: "main", severity: "CRITICAL", status: types.VulnStatusActive},
+				{branch: "develop", severity: "MEDIUM", status: types.VulnStatusActive},
+			},
+			findings:  1,
+			severity:  "CRITICAL",
+			status:    types.VulnStatusActive,
+			inDefault: true,
+			branches:  []string{"main", "develop", "feature/x"},
+		},
+		"active in any branch is active": {
+			occurrences: []occurrence{
+				{branch: "main", severity: "HIGH", status: types.VulnStatusAcknowledged},
+				{branch: "feature/x", severity: "HIGH", status: types.VulnStatusActive},
+			},
+			findings:  1,
+			severity:  "HIGH",
+			status:    types.VulnStatusActive,
+			inDefault: true,
+			branches:  []string{"main", "feature/x"},
+		},
+		"acknowledged in all unfixed branches is acknowledged": {
+			occurrences: []occurrence{
+				{branch: "main", severity: "HIGH", status: types.VulnStatusFixed},
+				{branch: "feature/x", severity: "HIGH", status: types.VulnStatusAcknowledged},
+			},
+			includeFixed: true,
+			findings:     1,
+			severity:     "HIGH",
+			status:       types.VulnStatusAcknowledged,
+			inDefault:    true,
+			branches:     []string{"main", "feature/x"},
+		},
+		"fixed occurrences are excluded by default": {
+			occurrences: []occurrence{
+				{branch: "main", severity: "CRITICAL", status: types.VulnStatusFixed},
+				{branch: "feature/x", severity: "LOW", status: types.VulnStatusAcknowledged},
+			},
+			findings:  1,
+			severity:  "LOW",
+			status:    types.VulnStatusAcknowledged,
+			inDefault: false,
+			branches:  []string{"feature/x"},
+		},
+		"fixed in all branches is fixed if included": {
+			occurrences: []occurrence{
+				{branch: "main", severity: "HIGH", status: types.VulnStatusFixed},
+				{branch: "feature/x", severity: "HIGH", status: types.VulnStatusFixed},
+			},
+			includeFixed: true,
+			findings:     1,
+			severity:     "HIGH",
+			status:       types.VulnStatusFixed,
+			inDefault:    true,
+			branches:     []string{"main", "feature/x"},
+		},
+		"finding fixed in all branches is not rolled up by default": {
+			occurrences: []occurrence{
+				{branch: "main", severity: "HIGH", status: types.VulnStatusFixed},
+			},
+			findings: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			uc := setup(t, tc.occurrences)
+			rollup := gt.R1(uc.GetVulnerabilityRollup(ctx, &model.GetVulnerabilityRollupInput{
+				Owner:        "test-owner",
+				Repo:         "test-repo",
+				IncludeFixed: tc.includeFixed,
+			})).NoError(t)
+
+			gt.V(t, rollup.Repository).Equal("test-owner/test-repo")
+			gt.V(t, rollup.DefaultBranch).Equal("main")
+			gt.A(t, rollup.Findings).Length(tc.findings)
+			gt.V(t, rollup.Summary.Findings).Equal(tc.findings)
+			if tc.findings == 0 {
+				return
+			}
+
+			finding := rollup.Findings[0]
+			gt.V(t, finding.Severity).Equal(tc.severity)
+			gt.V(t, finding.Status).Equal(tc.status)
+			gt.V(t, finding.InDefaultBranch).Equal(tc.inDefault)
+			var branches []string
+			for _, b := range finding.Branches {
+				branches = append(branches, b.Branch)
+			}
+			gt.A(t, branches).Equal(tc.branches)
+			gt.V(t, rollup.Summary.Occurrences).Equal(len(tc.branches))
+			gt.V(t, rollup.Summary.Severities.Total).Equal(1)
+		})
+	}
+
+	t.Run("unknown repository is not found", func(t *testing.T) {
+		uc := setup(t, nil)
+		_, err := uc.GetVulnerabilityRollup(ctx, &model.GetVulnerabilityRollupInput{Owner: "test-owner", Repo: "unknown"})
+		gt.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+
+	t.Run("invalid repository is rejected", func(t *testing.T) {
+		uc := setup(t, nil)
+		_, err := uc.GetVulnerabilityRollup(ctx, &model.GetVulnerabilityRollupInput{Owner: "test-owner"})
+		gt.True(t, errors.Is(err, types.ErrInvalidRequest))
+	})
+}
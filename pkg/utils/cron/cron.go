@@ -0,0 +1,169 @@
+// Package cron parses standard 5-field cron expressions (minute, hour, day of month, month and day of week) and calculates their next activation time.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/octovy/pkg/domain/types"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domStar and dowStar are true if the field is "*". As in crontab(5), if both day fields are restricted, a day matches either of them.
+	domStar bool
+	dowStar bool
+}
+
+type fieldRange struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteRange = fieldRange{"minute", 0, 59}
+	hourRange   = fieldRange{"hour", 0, 23}
+	domRange    = fieldRange{"day of month", 1, 31}
+	monthRange  = fieldRange{"month", 1, 12}
+	// 7 is accepted as Sunday as well as 0
+	dowRange = fieldRange{"day of week", 0, 7}
+)
+
+// Parse parses a cron expression such as "0 3 * * *". Each field accepts "*", numbers, ranges ("1-5"), lists ("1,15") and steps ("*/15", "0-30/10").
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "cron expression must have 5 fields", goerr.V("expr", expr))
+	}
+
+	s := &Schedule{
+		expr:    expr,
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], minuteRange); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if s.hour, err = parseField(fields[1], hourRange); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if s.dom, err = parseField(fields[2], domRange); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if s.month, err = parseField(fields[3], monthRange); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if s.dow, err = parseField(fields[4], dowRange); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 << 0
+	}
+
+	return s, nil
+}
+
+func parseField(field string, r fieldRange) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, goerr.Wrap(types.ErrInvalidOption, "invalid step", goerr.V("field", r.name), goerr.V("value", part))
+			}
+			step = n
+		}
+
+		lo, hi := r.min, r.max
+		if rangePart != "*" {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(loStr, r); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(hiStr, r); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end by 15
+				hi = r.max
+			}
+			if lo > hi {
+				return 0, goerr.Wrap(types.ErrInvalidOption, "invalid range", goerr.V("field", r.name), goerr.V("value", part))
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, r fieldRange) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < r.min || n > r.max {
+		return 0, goerr.Wrap(types.ErrInvalidOption, "value out of range", goerr.V("field", r.name), goerr.V("value", s), goerr.V("min", r.min), goerr.V("max", r.max))
+	}
+	return n, nil
+}
+
+// maxSearchYears bounds the search of Next. An expression that matches no date, e.g. "0 0 30 2 *", never activates.
+const maxSearchYears = 5
+
+// Next returns the first activation time after t in the location of t. It returns zero time if the schedule never activates.
+func (x *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if x.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !x.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if x.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if x.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (x *Schedule) matchDay(t time.Time) bool {
+	domMatch := x.dom&(1<<uint(t.Day())) != 0
+	dowMatch := x.dow&(1<<uint(t.Weekday())) != 0
+	if x.domStar || x.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// String returns the original expression
+func (x *Schedule) String() string {
+	return x.expr
+}
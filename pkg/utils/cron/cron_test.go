@@ -0,0 +1,62 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/utils/cron"
+)
+
+func TestScheduleNext(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 30, 15, 0, time.UTC) // Wednesday
+
+	testCases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 3 * * *", time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2024, 1, 31, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 2, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 31, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day of month or day of week matches when both are restricted
+		{"0 0 15 * 5", time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			s := gt.R1(cron.Parse(tc.expr)).NoError(t)
+			gt.V(t, s.Next(base)).Equal(tc.want)
+		})
+	}
+
+	t.Run("never activates", func(t *testing.T) {
+		s := gt.R1(cron.Parse("0 0 30 2 *")).NoError(t)
+		gt.True(t, s.Next(base).IsZero())
+	})
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 3 * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := cron.Parse(expr)
+			gt.Error(t, err)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package errutil
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/m-mizutani/goerr/v2"
@@ -10,6 +12,11 @@ import (
 )
 
 func HandleError(ctx context.Context, msg string, err error) {
+	HandleErrorWithTags(ctx, msg, err, nil)
+}
+
+// HandleErrorWithTags is HandleError setting tags to the Sentry event, e.g. metadata of the scan, to search and group events by them. goerr tags of the error are set as "error.tags" as well.
+func HandleErrorWithTags(ctx context.Context, msg string, err error, tags map[string]string) {
 	// Sending error to Sentry
 	hub := sentry.CurrentHub().Clone()
 	hub.ConfigureScope(func(scope *sentry.Scope) {
@@ -18,11 +25,24 @@ func HandleError(ctx context.Context, msg string, err error) {
 				scope.SetExtra(fmt.Sprintf("%v", k), v)
 			}
 		}
+		if errTags := goerr.Tags(err); len(errTags) > 0 {
+			slices.Sort(errTags)
+			scope.SetTag("error.tags", strings.Join(errTags, ","))
+		}
+		for k, v := range tags {
+			if v != "" {
+				scope.SetTag(k, v)
+			}
+		}
 	})
 	evID := hub.CaptureException(err)
 
-	logging.From(ctx).Error(msg,
+	attrs := []any{
 		"error", err,
 		"sentry.EventID", evID,
-	)
+	}
+	if len(tags) > 0 {
+		attrs = append(attrs, "tags", tags)
+	}
+	logging.From(ctx).Error(msg, attrs...)
 }
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/getsentry/sentry-go"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/utils/errutil"
 )
 
@@ -23,4 +26,33 @@ func TestHandleError(t *testing.T) {
 		// Should not panic
 		errutil.HandleError(ctx, "test message", nil)
 	})
+
+	t.Run("report tags to Sentry", func(t *testing.T) {
+		transport := &sentry.MockTransport{}
+		client, err := sentry.NewClient(sentry.ClientOptions{
+			Dsn:       "https://public@sentry.example.com/1",
+			Transport: transport,
+		})
+		gt.NoError(t, err)
+		hub := sentry.CurrentHub()
+		hub.BindClient(client)
+		t.Cleanup(func() { hub.BindClient(nil) })
+
+		tag := goerr.NewTag("bigquery")
+		scanErr := goerr.New("insert failed", goerr.T(tag), goerr.V("scan_id", "s1"))
+		errutil.HandleErrorWithTags(context.Background(), "scan failed", scanErr, map[string]string{
+			"owner": "test-owner",
+			"repo":  "test-repo",
+			"tag":   "",
+		})
+
+		events := transport.Events()
+		gt.A(t, events).Length(1)
+		gt.M(t, events[0].Tags).
+			HasKeyValue("owner", "test-owner").
+			HasKeyValue("repo", "test-repo").
+			HasKeyValue("error.tags", "bigquery").
+			NotHasKey("tag")
+		gt.M(t, events[0].Extra).HasKeyValue("scan_id", "s1")
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/m-mizutani/octovy/pkg/domain/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ctxRequestIDKey struct{}
@@ -20,6 +21,12 @@ func CtxRequestID(ctx context.Context) (types.RequestID, context.Context) {
 	return newID, context.WithValue(ctx, ctxRequestIDKey{}, newID)
 }
 
+// LookupRequestID returns request ID from context without setting a new one
+func LookupRequestID(ctx context.Context) (types.RequestID, bool) {
+	id, ok := ctx.Value(ctxRequestIDKey{}).(types.RequestID)
+	return id, ok
+}
+
 type ctxLoggerKey struct{}
 
 // With returns a new context with logger
@@ -51,9 +58,10 @@ func CtxWithTime(ctx context.Context, timeFunc TimeFunc) context.Context {
 	return context.WithValue(ctx, ctxTimeKey{}, timeFunc)
 }
 
-// InheritContextValues copies request ID and time function from src context to dst context.
+// InheritContextValues copies request ID, time function and trace span from src context to dst context.
 // This is useful when creating a background context that should inherit values from
-// the original HTTP request context. Note that logger is NOT copied by this function;
+// the original HTTP request context. Spans of the background work are recorded in the
+// trace of the request. Note that logger is NOT copied by this function;
 // use With() separately to copy the logger.
 func InheritContextValues(dst, src context.Context) context.Context {
 	// Copy request ID if exists
@@ -66,5 +74,10 @@ func InheritContextValues(dst, src context.Context) context.Context {
 		dst = context.WithValue(dst, ctxTimeKey{}, timeFunc)
 	}
 
+	// Copy span context if exists
+	if sc := trace.SpanContextFromContext(src); sc.IsValid() {
+		dst = trace.ContextWithSpanContext(dst, sc)
+	}
+
 	return dst
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestWith(t *testing.T) {
@@ -136,6 +137,19 @@ func TestInheritContextValues(t *testing.T) {
 		gt.V(t, inheritedTime).Equal(fixedTime)
 	})
 
+	t.Run("inherit span context from source context", func(t *testing.T) {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{2},
+			TraceFlags: trace.FlagsSampled,
+		})
+		srcCtx := trace.ContextWithSpanContext(context.Background(), sc)
+
+		dstCtx := logging.InheritContextValues(context.Background(), srcCtx)
+		gt.V(t, trace.SpanContextFromContext(dstCtx).TraceID()).Equal(sc.TraceID())
+		gt.V(t, trace.SpanContextFromContext(dstCtx).SpanID()).Equal(sc.SpanID())
+	})
+
 	t.Run("handle empty source context", func(t *testing.T) {
 		srcCtx := context.Background()
 		dstCtx := context.Background()
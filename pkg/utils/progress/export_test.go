@@ -0,0 +1,3 @@
+package progress
+
+var FormatTaskForTest = formatTask
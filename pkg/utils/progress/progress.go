@@ -0,0 +1,222 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// Progress tracks tasks of a CLI command. It renders a progress bar of each task if a renderer is set, and records counts of the tasks for the summary of the command.
+type Progress struct {
+	mutex     sync.Mutex
+	renderer  *renderer
+	command   string
+	startedAt time.Time
+	tasks     []*Task
+	results   map[string]any
+}
+
+type Option func(*Progress)
+
+// WithTerminal renders progress bars to the file, typically os.Stderr. It is ignored if the file is not a terminal so that CI logs are not filled with control characters.
+func WithTerminal(f *os.File) Option {
+	return func(x *Progress) {
+		if isTerminal(f) {
+			x.renderer = newRenderer(f)
+		}
+	}
+}
+
+func New(command string, options ...Option) *Progress {
+	x := &Progress{
+		command:   command,
+		startedAt: time.Now(),
+		results:   make(map[string]any),
+	}
+	for _, opt := range options {
+		opt(x)
+	}
+	return x
+}
+
+type ctxProgressKey struct{}
+
+// With returns a new context with progress
+func With(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, ctxProgressKey{}, p)
+}
+
+// From returns progress from context. If progress is not set, return a new progress which renders nothing, so that callers do not need to check it
+func From(ctx context.Context) *Progress {
+	if p, ok := ctx.Value(ctxProgressKey{}).(*Progress); ok {
+		return p
+	}
+	return New("")
+}
+
+// Start starts a task with the number of items. Set total to 0 if it is unknown, then a spinner is rendered instead of a bar.
+func (x *Progress) Start(name string, total int) *Task {
+	task := &Task{
+		Name:      name,
+		Total:     total,
+		progress:  x,
+		startedAt: time.Now(),
+	}
+
+	x.mutex.Lock()
+	x.tasks = append(x.tasks, task)
+	x.mutex.Unlock()
+
+	if x.renderer != nil {
+		x.renderer.start(task)
+	}
+	return task
+}
+
+// Set records a result of the command, e.g. number of deleted records, to the summary
+func (x *Progress) Set(key string, value any) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.results[key] = value
+}
+
+// Summary is a machine readable result of a CLI command
+type Summary struct {
+	Command         string         `json:"command"`
+	Status          string         `json:"status"`
+	Error           string         `json:"error,omitempty"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Tasks           []TaskSummary  `json:"tasks"`
+	Results         map[string]any `json:"results,omitempty"`
+}
+
+type TaskSummary struct {
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped"`
+}
+
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Summary returns the summary of the command finished with cmdErr
+func (x *Progress) Summary(cmdErr error) *Summary {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	finishedAt := time.Now()
+	summary := &Summary{
+		Command:         x.command,
+		Status:          StatusSuccess,
+		StartedAt:       x.startedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: finishedAt.Sub(x.startedAt).Seconds(),
+		Tasks:           make([]TaskSummary, len(x.tasks)),
+	}
+	if cmdErr != nil {
+		summary.Status = StatusFailure
+		summary.Error = cmdErr.Error()
+	}
+	for i, task := range x.tasks {
+		summary.Tasks[i] = task.summary()
+	}
+	if len(x.results) > 0 {
+		summary.Results = make(map[string]any, len(x.results))
+		for k, v := range x.results {
+			summary.Results[k] = v
+		}
+	}
+	return summary
+}
+
+// WriteSummary writes the summary of the command finished with cmdErr as JSON to the file
+func (x *Progress) WriteSummary(path string, cmdErr error) error {
+	raw, err := json.MarshalIndent(x.Summary(cmdErr), "", "  ")
+	if err != nil {
+		return goerr.Wrap(err, "failed to encode summary")
+	}
+	if err := os.WriteFile(filepath.Clean(path), append(raw, '\n'), 0600); err != nil {
+		return goerr.Wrap(err, "failed to write summary", goerr.V("path", path))
+	}
+	return nil
+}
+
+// Task is a unit of work of a command consisting of items, e.g. scanning repositories of an owner
+type Task struct {
+	Name  string
+	Total int
+
+	progress  *Progress
+	startedAt time.Time
+	succeeded int
+	failed    int
+	skipped   int
+	current   string
+	done      bool
+}
+
+// Describe sets the item being processed, e.g. name of the repository
+func (x *Task) Describe(item string) {
+	x.update(func() { x.current = item })
+}
+
+// Succeed counts an item completed successfully
+func (x *Task) Succeed() {
+	x.update(func() { x.succeeded++ })
+}
+
+// Fail counts an item failed
+func (x *Task) Fail() {
+	x.update(func() { x.failed++ })
+}
+
+// Skip counts an item not processed
+func (x *Task) Skip() {
+	x.update(func() { x.skipped++ })
+}
+
+// Done finishes the task. It must be called once the task ends, including by an error.
+func (x *Task) Done() {
+	x.update(func() {
+		x.done = true
+		x.current = ""
+	})
+	if r := x.progress.renderer; r != nil {
+		r.stop(x)
+	}
+}
+
+func (x *Task) update(f func()) {
+	x.progress.mutex.Lock()
+	f()
+	x.progress.mutex.Unlock()
+
+	if r := x.progress.renderer; r != nil {
+		r.draw()
+	}
+}
+
+func (x *Task) summary() TaskSummary {
+	return TaskSummary{
+		Name:      x.Name,
+		Total:     x.Total,
+		Succeeded: x.succeeded,
+		Failed:    x.failed,
+		Skipped:   x.skipped,
+	}
+}
+
+func (x *Task) processed() int {
+	return x.succeeded + x.failed + x.skipped
+}
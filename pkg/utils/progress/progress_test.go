@@ -0,0 +1,110 @@
+package progress_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/utils/progress"
+)
+
+func TestSummary(t *testing.T) {
+	p := progress.New("octovy scan remote")
+	ctx := progress.With(context.Background(), p)
+
+	task := progress.From(ctx).Start("Scanning repositories", 3)
+	task.Describe("owner/repo-a")
+	task.Succeed()
+	task.Fail()
+	task.Skip()
+	task.Done()
+	progress.From(ctx).Set("failed_repos", []string{"owner/repo-b"})
+
+	summary := p.Summary(errors.New("some repositories failed to scan"))
+	gt.V(t, summary.Command).Equal("octovy scan remote")
+	gt.V(t, summary.Status).Equal(progress.StatusFailure)
+	gt.V(t, summary.Error).Equal("some repositories failed to scan")
+	gt.A(t, summary.Tasks).Length(1)
+	gt.V(t, summary.Tasks[0]).Equal(progress.TaskSummary{
+		Name:      "Scanning repositories",
+		Total:     3,
+		Succeeded: 1,
+		Failed:    1,
+		Skipped:   1,
+	})
+	gt.V(t, summary.Results["failed_repos"]).Equal([]string{"owner/repo-b"})
+
+	gt.V(t, p.Summary(nil).Status).Equal(progress.StatusSuccess)
+}
+
+func TestFromWithoutProgress(t *testing.T) {
+	// Usecases report progress without checking if the caller is a CLI command
+	task := progress.From(context.Background()).Start("Pruning scan history", 1)
+	task.Succeed()
+	task.Done()
+}
+
+func TestWriteSummary(t *testing.T) {
+	p := progress.New("octovy admin prune")
+	p.Set("deleted_scans", 2)
+	path := filepath.Join(t.TempDir(), "summary.json")
+	gt.NoError(t, p.WriteSummary(path, nil))
+
+	raw := gt.R1(os.ReadFile(path)).NoError(t)
+	var got map[string]any
+	gt.NoError(t, json.Unmarshal(raw, &got))
+	gt.V(t, got["command"]).Equal("octovy admin prune")
+	gt.V(t, got["status"]).Equal("success")
+	gt.V(t, got["results"]).Equal(map[string]any{"deleted_scans": float64(2)})
+	gt.V(t, got["tasks"]).Equal([]any{})
+}
+
+func TestWithTerminal(t *testing.T) {
+	// A regular file is not a terminal, so nothing is rendered
+	f := gt.R1(os.Create(filepath.Join(t.TempDir(), "stderr"))).NoError(t)
+	defer f.Close()
+
+	p := progress.New("octovy sync-alerts", progress.WithTerminal(f))
+	task := p.Start("Syncing code scanning alerts", 2)
+	task.Succeed()
+	task.Done()
+
+	info := gt.R1(f.Stat()).NoError(t)
+	gt.V(t, info.Size()).Equal(int64(0))
+}
+
+func TestFormatTask(t *testing.T) {
+	t.Run("bar with ETA", func(t *testing.T) {
+		p := progress.New("test")
+		task := p.Start("Scanning repositories", 4)
+		task.Succeed()
+		task.Describe("owner/repo-b")
+
+		// Time is taken after the task started, so the elapsed time is slightly longer than 10 seconds
+		line := progress.FormatTaskForTest(task, 0, time.Now().Add(10*time.Second))
+		gt.S(t, line).Contains("Scanning repositories [=======>")
+		gt.S(t, line).Contains("1/4  25%")
+		gt.S(t, line).Contains("ETA 30s")
+		gt.S(t, line).Contains("owner/repo-b")
+	})
+
+	t.Run("failed items", func(t *testing.T) {
+		p := progress.New("test")
+		task := p.Start("Scanning repositories", 2)
+		task.Fail()
+		gt.S(t, progress.FormatTaskForTest(task, 0, time.Now())).Contains("(1 failed)")
+	})
+
+	t.Run("spinner for unknown total", func(t *testing.T) {
+		p := progress.New("test")
+		task := p.Start("Exporting", 0)
+		task.Succeed()
+		line := progress.FormatTaskForTest(task, 1, time.Now())
+		gt.S(t, line).Contains("Exporting / 1 done")
+	})
+}
@@ -0,0 +1,145 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	barWidth       = 30
+	redrawInterval = 200 * time.Millisecond
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// renderer draws the running task on a single line of the terminal. The cursor is moved back to the beginning of the line after drawing, so that a log line written meanwhile overwrites the bar instead of being appended to it.
+type renderer struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	task   *Task
+	frame  int
+	stopCh chan struct{}
+}
+
+func newRenderer(w io.Writer) *renderer {
+	return &renderer{w: w}
+}
+
+func (x *renderer) start(task *Task) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.task = task
+	if x.stopCh == nil {
+		x.stopCh = make(chan struct{})
+		go x.tick(x.stopCh)
+	}
+	x.drawLocked("\r")
+}
+
+// stop draws the final state of the task and leaves it on the terminal
+func (x *renderer) stop(task *Task) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.task != task {
+		return
+	}
+	x.drawLocked("\n")
+	x.task = nil
+	close(x.stopCh)
+	x.stopCh = nil
+}
+
+func (x *renderer) draw() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.drawLocked("\r")
+}
+
+// tick redraws the task periodically to animate the spinner and to update the elapsed time while an item takes long
+func (x *renderer) tick(stopCh chan struct{}) {
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			x.draw()
+		}
+	}
+}
+
+func (x *renderer) drawLocked(end string) {
+	if x.task == nil {
+		return
+	}
+
+	x.task.progress.mutex.Lock()
+	line := formatTask(x.task, x.frame, time.Now())
+	x.task.progress.mutex.Unlock()
+	x.frame++
+
+	_, _ = fmt.Fprint(x.w, "\r\033[K"+line+end)
+}
+
+// formatTask renders a line such as "Scanning repositories [=====>    ] 12/40 30% ETA 3m12s owner/repo". ETA is estimated from the average time of processed items.
+func formatTask(task *Task, frame int, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(task.Name)
+
+	processed := task.processed()
+	elapsed := now.Sub(task.startedAt).Truncate(time.Second)
+
+	if task.Total > 0 {
+		ratio := float64(processed) / float64(task.Total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		filled := int(ratio * barWidth)
+		bar := strings.Repeat("=", filled)
+		if filled < barWidth {
+			bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+		}
+		fmt.Fprintf(&b, " [%s] %d/%d %3.0f%%", bar, processed, task.Total, ratio*100)
+
+		switch {
+		case task.done:
+			fmt.Fprintf(&b, " in %s", elapsed)
+		case processed > 0 && processed < task.Total:
+			eta := time.Duration(float64(now.Sub(task.startedAt)) / float64(processed) * float64(task.Total-processed))
+			fmt.Fprintf(&b, " ETA %s", eta.Truncate(time.Second))
+		}
+	} else {
+		if task.done {
+			fmt.Fprintf(&b, " %d done in %s", processed, elapsed)
+		} else {
+			fmt.Fprintf(&b, " %s %d done %s", spinnerFrames[frame%len(spinnerFrames)], processed, elapsed)
+		}
+	}
+
+	if task.failed > 0 {
+		fmt.Fprintf(&b, " (%d failed)", task.failed)
+	}
+	if task.current != "" {
+		b.WriteString(" " + task.current)
+	}
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package tenancy
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+)
+
+type ctxTenantKey struct{}
+
+// With returns a new context restricted to the tenant. A nil tenant is not restricted.
+func With(ctx context.Context, tenant *model.Tenant) context.Context {
+	return context.WithValue(ctx, ctxTenantKey{}, tenant)
+}
+
+// From returns the tenant of the context, or nil if the context is not restricted, e.g. for CLI commands and webhooks
+func From(ctx context.Context) *model.Tenant {
+	if tenant, ok := ctx.Value(ctxTenantKey{}).(*model.Tenant); ok {
+		return tenant
+	}
+	return nil
+}
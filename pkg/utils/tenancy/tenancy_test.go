@@ -0,0 +1,53 @@
+package tenancy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/domain/model"
+	"github.com/m-mizutani/octovy/pkg/utils/tenancy"
+)
+
+func TestTenancy(t *testing.T) {
+	t.Run("tenant is propagated to derived contexts", func(t *testing.T) {
+		tenant := &model.Tenant{Owners: []string{"myorg"}, InstallationIDs: []int64{1}}
+		ctx := tenancy.With(context.Background(), tenant)
+
+		derived, cancel := context.WithCancel(ctx)
+		defer cancel()
+		gt.V(t, tenancy.From(derived)).Equal(tenant)
+		gt.True(t, tenancy.From(derived).AllowsOwner("myorg"))
+		gt.False(t, tenancy.From(derived).AllowsOwner("other"))
+	})
+
+	t.Run("context without tenant is not restricted", func(t *testing.T) {
+		tenant := tenancy.From(context.Background())
+		gt.V(t, tenant).Nil()
+		gt.True(t, tenant.AllowsOwner("any"))
+		gt.True(t, tenant.AllowsRepository(&model.Repository{Owner: "any", InstallationID: 2}))
+	})
+
+	t.Run("nil tenant is not restricted", func(t *testing.T) {
+		ctx := tenancy.With(context.Background(), nil)
+		gt.V(t, tenancy.From(ctx)).Nil()
+		gt.True(t, tenancy.From(ctx).AllowsOwner("any"))
+	})
+
+	t.Run("empty tenant allows nothing", func(t *testing.T) {
+		ctx := tenancy.With(context.Background(), &model.Tenant{})
+		tenant := tenancy.From(ctx)
+		gt.V(t, tenant).NotNil()
+		gt.False(t, tenant.AllowsOwner("myorg"))
+		gt.False(t, tenant.AllowsInstallation(1))
+		gt.False(t, tenant.AllowsRepository(&model.Repository{Owner: "myorg", InstallationID: 1}))
+	})
+
+	t.Run("inner tenant overrides outer one", func(t *testing.T) {
+		outer := tenancy.With(context.Background(), &model.Tenant{Owners: []string{"myorg"}})
+		inner := tenancy.With(outer, &model.Tenant{Owners: []string{"partner"}})
+		gt.True(t, tenancy.From(inner).AllowsOwner("partner"))
+		gt.False(t, tenancy.From(inner).AllowsOwner("myorg"))
+		gt.True(t, tenancy.From(outer).AllowsOwner("myorg"))
+	})
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // GetEnvOrSkip returns the value of the environment variable. If not set, skip the test.
-func GetEnvOrSkip(t *testing.T, key string) string {
+func GetEnvOrSkip(t testing.TB, key string) string {
 	t.Helper()
 	value := os.Getenv(key)
 	if value == "" {
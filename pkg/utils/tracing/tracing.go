@@ -0,0 +1,36 @@
+// Package tracing starts OpenTelemetry spans of the scan pipeline. Spans are recorded by the tracer provider set by otel.SetTracerProvider, and are no-op if it is not set.
+package tracing
+
+import (
+	"context"
+
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/m-mizutani/octovy"
+
+	// AttrRequestID is the attribute of the request ID, which is also logged as request_id
+	AttrRequestID = attribute.Key("octovy.request_id")
+)
+
+// Start starts a span as a child of the span of ctx. The span has the request ID of ctx if set, so that spans can be correlated with logs.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if id, ok := logging.LookupRequestID(ctx); ok {
+		opts = append(opts, trace.WithAttributes(AttrRequestID.String(id.String())))
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// End records err to the span if not nil, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
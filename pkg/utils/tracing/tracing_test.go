@@ -0,0 +1,64 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/utils/logging"
+	"github.com/m-mizutani/octovy/pkg/utils/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+func TestStartAndEnd(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	reqID, ctx := logging.CtxRequestID(context.Background())
+	ctx, parent := tracing.Start(ctx, "parent")
+	_, child := tracing.Start(ctx, "child")
+	tracing.End(child, errors.New("failed"))
+	tracing.End(parent, nil)
+
+	spans := recorder.Ended()
+	gt.A(t, spans).Length(2)
+
+	gt.V(t, spans[0].Name()).Equal("child")
+	gt.V(t, spans[0].Parent().SpanID()).Equal(spans[1].SpanContext().SpanID())
+	gt.V(t, spans[0].Status().Code).Equal(codes.Error)
+	gt.A(t, spans[0].Events()).Length(1)
+
+	gt.V(t, spans[1].Name()).Equal("parent")
+	gt.V(t, spans[1].Status().Code).Equal(codes.Unset)
+	var found bool
+	for _, attr := range spans[1].Attributes() {
+		if attr.Key == tracing.AttrRequestID {
+			gt.V(t, attr.Value.AsString()).Equal(reqID.String())
+			found = true
+		}
+	}
+	gt.True(t, found)
+}
+
+func TestStartWithoutRequestID(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, span := tracing.Start(context.Background(), "span")
+	tracing.End(span, nil)
+
+	spans := recorder.Ended()
+	gt.A(t, spans).Length(1)
+	gt.A(t, spans[0].Attributes()).Length(0)
+}
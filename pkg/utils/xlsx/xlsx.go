@@ -0,0 +1,153 @@
+// Package xlsx writes a minimal Office Open XML workbook of a single sheet of strings, readable by Excel, LibreOffice and Google Sheets. Cells are inline strings, so values are never evaluated as formulas.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+// styles has the default cell format (0) and the bold format of the header row (1)
+const styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+
+// maxSheetNameLen is the limit of a sheet name of Excel
+const maxSheetNameLen = 31
+
+// Write writes a workbook with a sheet of the header and rows. The header row is bold, frozen and has an auto filter.
+func Write(w io.Writer, sheet string, header []string, rows [][]string) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content func(io.Writer) error
+	}{
+		{"[Content_Types].xml", writeString(contentTypes)},
+		{"_rels/.rels", writeString(rootRels)},
+		{"xl/_rels/workbook.xml.rels", writeString(workbookRels)},
+		{"xl/styles.xml", writeString(styles)},
+		{"xl/workbook.xml", writeString(workbook(sheet))},
+		{"xl/worksheets/sheet1.xml", func(w io.Writer) error { return writeSheet(w, header, rows) }},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return goerr.Wrap(err, "failed to create file in xlsx", goerr.V("name", f.name))
+		}
+		if err := f.content(fw); err != nil {
+			return goerr.Wrap(err, "failed to write file in xlsx", goerr.V("name", f.name))
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close xlsx")
+	}
+	return nil
+}
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+// workbook returns the workbook part with the sheet name. Characters not allowed in a sheet name are replaced with "_".
+func workbook(sheet string) string {
+	sheet = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, sheet)
+	if runes := []rune(sheet); len(runes) > maxSheetNameLen {
+		sheet = string(runes[:maxSheetNameLen])
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + escape(sheet) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+}
+
+func writeSheet(w io.Writer, header []string, rows [][]string) error {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(header) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	b.WriteString(`<sheetData>`)
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+
+	rowNum := 0
+	writeRow := func(values []string, style int) error {
+		rowNum++
+		var b strings.Builder
+		b.WriteString(`<row r="` + strconv.Itoa(rowNum) + `">`)
+		for i, v := range values {
+			b.WriteString(`<c r="` + CellRef(i, rowNum) + `" t="inlineStr"`)
+			if style != 0 {
+				b.WriteString(` s="` + strconv.Itoa(style) + `"`)
+			}
+			b.WriteString(`><is><t xml:space="preserve">` + escape(v) + `</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	if len(header) > 0 {
+		if err := writeRow(header, 1); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := writeRow(row, 0); err != nil {
+			return err
+		}
+	}
+
+	b.Reset()
+	b.WriteString(`</sheetData>`)
+	if len(header) > 0 {
+		b.WriteString(`<autoFilter ref="A1:` + CellRef(len(header)-1, rowNum) + `"/>`)
+	}
+	b.WriteString(`</worksheet>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// CellRef returns the A1 reference of the cell of the zero-based column and the one-based row, e.g. "AB12"
+func CellRef(col, row int) string {
+	var name []byte
+	for col >= 0 {
+		name = append([]byte{byte('A' + col%26)}, name...)
+		col = col/26 - 1
+	}
+	return string(name) + strconv.Itoa(row)
+}
+
+// escape escapes a text for XML. Characters not allowed in XML, e.g. control characters, are replaced with U+FFFD.
+func escape(s string) string {
+	var b strings.Builder
+	// EscapeText never fails on a strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/octovy/pkg/utils/xlsx"
+)
+
+func TestCellRef(t *testing.T) {
+	gt.V(t, xlsx.CellRef(0, 1)).Equal("A1")
+	gt.V(t, xlsx.CellRef(25, 2)).Equal("Z2")
+	gt.V(t, xlsx.CellRef(26, 3)).Equal("AA3")
+	gt.V(t, xlsx.CellRef(27, 10)).Equal("AB10")
+	gt.V(t, xlsx.CellRef(701, 1)).Equal("ZZ1")
+	gt.V(t, xlsx.CellRef(702, 1)).Equal("AAA1")
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	gt.NoError(t, xlsx.Write(&buf, "Findings: 2024/01", []string{"ID", "Title"}, [][]string{
+		{"CVE-2024-0001", "<script> & \"quoted\""},
+		{"=HYPERLINK(\"x\")", "line1\nline2"},
+	}))
+
+	zr := gt.R1(zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))).NoError(t)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc := gt.R1(f.Open()).NoError(t)
+		files[f.Name] = string(gt.R1(io.ReadAll(rc)).NoError(t))
+		gt.NoError(t, rc.Close())
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		content, ok := files[name]
+		gt.True(t, ok)
+		// Every part is well-formed XML
+		dec := xml.NewDecoder(bytes.NewReader([]byte(content)))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			gt.NoError(t, err)
+		}
+	}
+
+	gt.S(t, files["xl/workbook.xml"]).Contains(`name="Findings_ 2024_01"`)
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R     string `xml:"r,attr"`
+				Type  string `xml:"t,attr"`
+				Style string `xml:"s,attr"`
+				Text  string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+		AutoFilter struct {
+			Ref string `xml:"ref,attr"`
+		} `xml:"autoFilter"`
+	}
+	gt.NoError(t, xml.Unmarshal([]byte(files["xl/worksheets/sheet1.xml"]), &sheet))
+	gt.A(t, sheet.Rows).Length(3)
+	gt.V(t, sheet.Rows[0].Cells[0].Text).Equal("ID")
+	gt.V(t, sheet.Rows[0].Cells[0].Style).Equal("1")
+	gt.V(t, sheet.Rows[1].Cells[1].R).Equal("B2")
+	gt.V(t, sheet.Rows[1].Cells[1].Text).Equal("<script> & \"quoted\"")
+	gt.V(t, sheet.Rows[2].Cells[0].Type).Equal("inlineStr")
+	gt.V(t, sheet.Rows[2].Cells[0].Text).Equal("=HYPERLINK(\"x\")")
+	gt.V(t, sheet.Rows[2].Cells[1].Text).Equal("line1\nline2")
+	gt.V(t, sheet.AutoFilter.Ref).Equal("A1:B3")
+}